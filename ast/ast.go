@@ -16,8 +16,10 @@
 package ast
 
 import (
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/format"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -33,6 +35,9 @@ type Node interface {
 	// children should be skipped. Otherwise, call its children in particular order that
 	// later elements depends on former elements. Finally, return visitor.Leave.
 	Accept(v Visitor) (node Node, ok bool)
+	// Restore writes the canonical SQL text of the node to ctx,
+	// parsing the restored text again gets an equivalent node.
+	Restore(ctx *format.RestoreCtx) error
 	// Text returns the original text of the element.
 	Text() string
 	// SetText sets original text to the Node.
@@ -184,3 +189,16 @@ type Visitor interface {
 	// ok returns false to stop visiting.
 	Leave(n Node) (node Node, ok bool)
 }
+
+// restoreExprs restores the expressions separated by commas.
+func restoreExprs(ctx *format.RestoreCtx, exprs []ExprNode) error {
+	for i, expr := range exprs {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := expr.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
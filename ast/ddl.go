@@ -14,7 +14,11 @@
 package ast
 
 import (
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/format"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -26,6 +30,7 @@ var (
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
+	_ DDLNode = &DropViewStmt{}
 	_ DDLNode = &RenameTableStmt{}
 	_ DDLNode = &TruncateTableStmt{}
 
@@ -70,6 +75,27 @@ type CreateDatabaseStmt struct {
 	Options     []*DatabaseOption
 }
 
+// Restore implements Node interface.
+func (n *CreateDatabaseStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE DATABASE ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.Name)
+	for _, option := range n.Options {
+		switch option.Tp {
+		case DatabaseOptionCharset:
+			ctx.WriteKeyWord(" CHARACTER SET = ")
+		case DatabaseOptionCollate:
+			ctx.WriteKeyWord(" COLLATE = ")
+		default:
+			return errors.Errorf("invalid database option type %d during restoring CreateDatabaseStmt", option.Tp)
+		}
+		ctx.WritePlain(option.Value)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *CreateDatabaseStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -89,6 +115,16 @@ type DropDatabaseStmt struct {
 	Name     string
 }
 
+// Restore implements Node interface.
+func (n *DropDatabaseStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP DATABASE ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.Name)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *DropDatabaseStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -105,6 +141,21 @@ type IndexColName struct {
 
 	Column *ColumnName
 	Length int
+	Desc   bool
+}
+
+// Restore implements Node interface.
+func (n *IndexColName) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Column.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Length > 0 {
+		ctx.WritePlainf("(%d)", n.Length)
+	}
+	if n.Desc {
+		ctx.WriteKeyWord(" DESC")
+	}
+	return nil
 }
 
 // Accept implements Node Accept interface.
@@ -133,6 +184,45 @@ type ReferenceDef struct {
 	OnUpdate      *OnUpdateOpt
 }
 
+// Restore implements Node interface.
+func (n *ReferenceDef) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("REFERENCES ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain("(")
+	if err := restoreIndexColNames(ctx, n.IndexColNames); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	if n.OnDelete != nil && n.OnDelete.ReferOpt != ReferOptionNoOption {
+		ctx.WritePlain(" ")
+		if err := n.OnDelete.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.OnUpdate != nil && n.OnUpdate.ReferOpt != ReferOptionNoOption {
+		ctx.WritePlain(" ")
+		if err := n.OnUpdate.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// restoreIndexColNames restores the index columns separated by commas.
+func restoreIndexColNames(ctx *format.RestoreCtx, cols []*IndexColName) error {
+	for i, col := range cols {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := col.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ReferenceDef) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -198,6 +288,15 @@ type OnDeleteOpt struct {
 	ReferOpt ReferOptionType
 }
 
+// Restore implements Node interface.
+func (n *OnDeleteOpt) Restore(ctx *format.RestoreCtx) error {
+	if n.ReferOpt != ReferOptionNoOption {
+		ctx.WriteKeyWord("ON DELETE ")
+		ctx.WriteKeyWord(n.ReferOpt.String())
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *OnDeleteOpt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -214,6 +313,15 @@ type OnUpdateOpt struct {
 	ReferOpt ReferOptionType
 }
 
+// Restore implements Node interface.
+func (n *OnUpdateOpt) Restore(ctx *format.RestoreCtx) error {
+	if n.ReferOpt != ReferOptionNoOption {
+		ctx.WriteKeyWord("ON UPDATE ")
+		ctx.WriteKeyWord(n.ReferOpt.String())
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *OnUpdateOpt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -252,6 +360,66 @@ type ColumnOption struct {
 	Expr ExprNode
 	// Stored is only for ColumnOptionGenerated, default is false.
 	Stored bool
+	// DefaultIsExpr is only for ColumnOptionDefaultValue, it's true for DEFAULT (expr),
+	// the expression is evaluated for each row instead of being a constant.
+	DefaultIsExpr bool
+}
+
+// Restore implements Node interface.
+func (n *ColumnOption) Restore(ctx *format.RestoreCtx) error {
+	switch n.Tp {
+	case ColumnOptionNoOption:
+		// The CHECK clause is ignored by the parser, nothing to restore.
+		return nil
+	case ColumnOptionPrimaryKey:
+		ctx.WriteKeyWord("PRIMARY KEY")
+	case ColumnOptionNotNull:
+		ctx.WriteKeyWord("NOT NULL")
+	case ColumnOptionAutoIncrement:
+		ctx.WriteKeyWord("AUTO_INCREMENT")
+	case ColumnOptionDefaultValue:
+		ctx.WriteKeyWord("DEFAULT ")
+		return errors.Trace(restoreDefaultValue(ctx, n))
+	case ColumnOptionUniqKey:
+		ctx.WriteKeyWord("UNIQUE KEY")
+	case ColumnOptionNull:
+		ctx.WriteKeyWord("NULL")
+	case ColumnOptionOnUpdate:
+		ctx.WriteKeyWord("ON UPDATE ")
+		return errors.Trace(n.Expr.Restore(ctx))
+	case ColumnOptionComment:
+		ctx.WriteKeyWord("COMMENT ")
+		return errors.Trace(n.Expr.Restore(ctx))
+	case ColumnOptionGenerated:
+		ctx.WriteKeyWord("GENERATED ALWAYS AS ")
+		ctx.WritePlain("(")
+		if err := n.Expr.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		if n.Stored {
+			ctx.WriteKeyWord(" STORED")
+		} else {
+			ctx.WriteKeyWord(" VIRTUAL")
+		}
+	default:
+		return errors.Errorf("invalid column option type %d during restoring ColumnOption", n.Tp)
+	}
+	return nil
+}
+
+// restoreDefaultValue restores the value of the DEFAULT clause, the expression is
+// enclosed in parentheses if it's evaluated for each row.
+func restoreDefaultValue(ctx *format.RestoreCtx, n *ColumnOption) error {
+	if !n.DefaultIsExpr {
+		return errors.Trace(n.Expr.Restore(ctx))
+	}
+	ctx.WritePlain("(")
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
 }
 
 // Accept implements Node Accept interface.
@@ -285,6 +453,37 @@ type IndexOption struct {
 	Comment      string
 }
 
+// Restore implements Node interface.
+func (n *IndexOption) Restore(ctx *format.RestoreCtx) error {
+	hasPrevOption := false
+	if n.KeyBlockSize > 0 {
+		ctx.WriteKeyWord("KEY_BLOCK_SIZE")
+		ctx.WritePlainf("=%d", n.KeyBlockSize)
+		hasPrevOption = true
+	}
+	if n.Tp != model.IndexTypeInvalid {
+		if hasPrevOption {
+			ctx.WritePlain(" ")
+		}
+		ctx.WriteKeyWord("USING ")
+		ctx.WriteKeyWord(n.Tp.String())
+		hasPrevOption = true
+	}
+	if n.Comment != "" {
+		if hasPrevOption {
+			ctx.WritePlain(" ")
+		}
+		ctx.WriteKeyWord("COMMENT ")
+		ctx.WriteString(n.Comment)
+	}
+	return nil
+}
+
+// isEmpty returns whether the index option has nothing to restore.
+func (n *IndexOption) isEmpty() bool {
+	return n.KeyBlockSize == 0 && n.Tp == model.IndexTypeInvalid && n.Comment == ""
+}
+
 // Accept implements Node Accept interface.
 func (n *IndexOption) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -325,6 +524,51 @@ type Constraint struct {
 	Option *IndexOption // Index Options
 }
 
+// Restore implements Node interface.
+func (n *Constraint) Restore(ctx *format.RestoreCtx) error {
+	switch n.Tp {
+	case ConstraintPrimaryKey:
+		if n.Name != "" {
+			ctx.WriteKeyWord("CONSTRAINT ")
+			ctx.WriteName(n.Name)
+			ctx.WritePlain(" ")
+		}
+		ctx.WriteKeyWord("PRIMARY KEY")
+	case ConstraintKey, ConstraintIndex:
+		ctx.WriteKeyWord("KEY")
+	case ConstraintUniq, ConstraintUniqKey, ConstraintUniqIndex:
+		ctx.WriteKeyWord("UNIQUE KEY")
+	case ConstraintForeignKey:
+		ctx.WriteKeyWord("FOREIGN KEY")
+	case ConstraintFulltext:
+		ctx.WriteKeyWord("FULLTEXT KEY")
+	default:
+		return errors.Errorf("invalid constraint type %d during restoring Constraint", n.Tp)
+	}
+	if n.Tp != ConstraintPrimaryKey && n.Name != "" {
+		ctx.WritePlain(" ")
+		ctx.WriteName(n.Name)
+	}
+	ctx.WritePlain("(")
+	if err := restoreIndexColNames(ctx, n.Keys); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	if n.Refer != nil {
+		ctx.WritePlain(" ")
+		if err := n.Refer.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Option != nil && !n.Option.isEmpty() {
+		ctx.WritePlain(" ")
+		if err := n.Option.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *Constraint) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -365,6 +609,69 @@ type ColumnDef struct {
 	Options []*ColumnOption
 }
 
+// Restore implements Node interface.
+func (n *ColumnDef) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Name.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Tp != nil {
+		ctx.WritePlain(" ")
+		restoreColumnType(ctx, n.Tp)
+	}
+	for _, option := range n.Options {
+		if option.Tp == ColumnOptionNoOption {
+			continue
+		}
+		ctx.WritePlain(" ")
+		if err := option.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// restoreColumnType restores the type of the column definition.
+func restoreColumnType(ctx *format.RestoreCtx, tp *types.FieldType) {
+	switch tp.Tp {
+	case mysql.TypeEnum, mysql.TypeSet:
+		if tp.Tp == mysql.TypeEnum {
+			ctx.WriteKeyWord("ENUM")
+		} else {
+			ctx.WriteKeyWord("SET")
+		}
+		ctx.WritePlain("(")
+		for i, elem := range tp.Elems {
+			if i > 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WriteString(elem)
+		}
+		ctx.WritePlain(")")
+	default:
+		ctx.WriteKeyWord(tp.CompactStr())
+	}
+	if mysql.HasUnsignedFlag(tp.Flag) {
+		ctx.WriteKeyWord(" UNSIGNED")
+	}
+	if mysql.HasZerofillFlag(tp.Flag) {
+		ctx.WriteKeyWord(" ZEROFILL")
+	}
+	if !types.IsTypeChar(tp.Tp) && !types.IsTypeBlob(tp.Tp) || tp.Charset == charset.CharsetBin {
+		return
+	}
+	if mysql.HasBinaryFlag(tp.Flag) {
+		ctx.WriteKeyWord(" BINARY")
+	}
+	if tp.Charset != "" {
+		ctx.WriteKeyWord(" CHARACTER SET ")
+		ctx.WritePlain(tp.Charset)
+	}
+	if tp.Collate != "" {
+		ctx.WriteKeyWord(" COLLATE ")
+		ctx.WritePlain(tp.Collate)
+	}
+}
+
 // Accept implements Node Accept interface.
 func (n *ColumnDef) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -398,6 +705,55 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	// Select is the query of CREATE TABLE ... SELECT, the table is created with the columns of its result
+	// and filled with its rows.
+	Select ResultSetNode
+}
+
+// Restore implements Node interface.
+func (n *CreateTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE TABLE ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.ReferTable != nil {
+		ctx.WriteKeyWord(" LIKE ")
+		return errors.Trace(n.ReferTable.Restore(ctx))
+	}
+	if len(n.Cols) > 0 {
+		ctx.WritePlain(" (")
+		for i, col := range n.Cols {
+			if i > 0 {
+				ctx.WritePlain(", ")
+			}
+			if err := col.Restore(ctx); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		for _, constraint := range n.Constraints {
+			ctx.WritePlain(", ")
+			if err := constraint.Restore(ctx); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		ctx.WritePlain(")")
+	}
+	for _, option := range n.Options {
+		ctx.WritePlain(" ")
+		if err := restoreTableOption(ctx, option); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Select != nil {
+		ctx.WriteKeyWord(" AS ")
+		if err := n.Select.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
 }
 
 // Accept implements Node Accept interface.
@@ -433,6 +789,13 @@ func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Constraints[i] = node.(*Constraint)
 	}
+	if n.Select != nil {
+		node, ok = n.Select.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Select = node.(ResultSetNode)
+	}
 	return v.Leave(n)
 }
 
@@ -445,6 +808,15 @@ type DropTableStmt struct {
 	Tables   []*TableName
 }
 
+// Restore implements Node interface.
+func (n *DropTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP TABLE ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	return errors.Trace(restoreTableNames(ctx, n.Tables))
+}
+
 // Accept implements Node Accept interface.
 func (n *DropTableStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -462,6 +834,41 @@ func (n *DropTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// DropViewStmt is a statement to drop one or more views.
+// See https://dev.mysql.com/doc/refman/5.7/en/drop-view.html
+type DropViewStmt struct {
+	ddlNode
+
+	IfExists bool
+	Views    []*TableName
+}
+
+// Restore implements Node interface.
+func (n *DropViewStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP VIEW ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	return errors.Trace(restoreTableNames(ctx, n.Views))
+}
+
+// Accept implements Node Accept interface.
+func (n *DropViewStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropViewStmt)
+	for i, val := range n.Views {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Views[i] = node.(*TableName)
+	}
+	return v.Leave(n)
+}
+
 // RenameTableStmt is a statement to rename a table.
 // See http://dev.mysql.com/doc/refman/5.7/en/rename-table.html
 type RenameTableStmt struct {
@@ -474,6 +881,23 @@ type RenameTableStmt struct {
 	TableToTables []*TableToTable
 }
 
+// Restore implements Node interface.
+func (n *RenameTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("RENAME TABLE ")
+	if len(n.TableToTables) == 0 {
+		return errors.Trace((&TableToTable{OldTable: n.OldTable, NewTable: n.NewTable}).Restore(ctx))
+	}
+	for i, t := range n.TableToTables {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := t.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *RenameTableStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -510,6 +934,15 @@ type TableToTable struct {
 	NewTable *TableName
 }
 
+// Restore implements Node interface.
+func (n *TableToTable) Restore(ctx *format.RestoreCtx) error {
+	if err := n.OldTable.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" TO ")
+	return errors.Trace(n.NewTable.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *TableToTable) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -535,6 +968,7 @@ func (n *TableToTable) Accept(v Visitor) (Node, bool) {
 type CreateIndexStmt struct {
 	ddlNode
 
+	IfNotExists   bool
 	IndexName     string
 	Table         *TableName
 	Unique        bool
@@ -542,6 +976,35 @@ type CreateIndexStmt struct {
 	IndexOption   *IndexOption
 }
 
+// Restore implements Node interface.
+func (n *CreateIndexStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE ")
+	if n.Unique {
+		ctx.WriteKeyWord("UNIQUE ")
+	}
+	ctx.WriteKeyWord("INDEX ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.IndexName)
+	ctx.WriteKeyWord(" ON ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(" (")
+	if err := restoreIndexColNames(ctx, n.IndexColNames); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	if n.IndexOption != nil && !n.IndexOption.isEmpty() {
+		ctx.WritePlain(" ")
+		if err := n.IndexOption.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *CreateIndexStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -581,6 +1044,17 @@ type DropIndexStmt struct {
 	Table     *TableName
 }
 
+// Restore implements Node interface.
+func (n *DropIndexStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP INDEX ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.IndexName)
+	ctx.WriteKeyWord(" ON ")
+	return errors.Trace(n.Table.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *DropIndexStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -618,6 +1092,10 @@ const (
 	TableOptionDelayKeyWrite
 	TableOptionRowFormat
 	TableOptionStatsPersistent
+	TableOptionLocation
+	TableOptionFormat
+	TableOptionAutoIDCache
+	TableOptionPlacementPolicy
 )
 
 // RowFormat types
@@ -635,6 +1113,25 @@ type TableOption struct {
 	Tp        TableOptionType
 	StrValue  string
 	UintValue uint64
+	// PlacementOptions is the placement policy of TableOptionPlacementPolicy.
+	PlacementOptions []*PlacementOption
+}
+
+// PlacementOptionType is the type for PlacementOption.
+type PlacementOptionType int
+
+// PlacementOption types.
+const (
+	PlacementOptionReplicas PlacementOptionType = iota + 1
+	PlacementOptionConstraints
+	PlacementOptionLeaderConstraints
+)
+
+// PlacementOption is an option of the placement policy in CREATE TABLE and ALTER TABLE ... PLACEMENT POLICY.
+type PlacementOption struct {
+	Tp        PlacementOptionType
+	StrValue  string
+	UintValue uint64
 }
 
 // ColumnPositionType is the type for ColumnPosition.
@@ -656,6 +1153,22 @@ type ColumnPosition struct {
 	RelativeColumn *ColumnName
 }
 
+// Restore implements Node interface.
+func (n *ColumnPosition) Restore(ctx *format.RestoreCtx) error {
+	switch n.Tp {
+	case ColumnPositionNone:
+		// Nothing to restore.
+	case ColumnPositionFirst:
+		ctx.WriteKeyWord("FIRST")
+	case ColumnPositionAfter:
+		ctx.WriteKeyWord("AFTER ")
+		return errors.Trace(n.RelativeColumn.Restore(ctx))
+	default:
+		return errors.Errorf("invalid column position type %d during restoring ColumnPosition", n.Tp)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ColumnPosition) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -690,6 +1203,7 @@ const (
 	AlterTableRenameTable
 	AlterTableAlterColumn
 	AlterTableLock
+	AlterTablePlacementPolicy
 
 // TODO: Add more actions
 )
@@ -719,6 +1233,121 @@ type AlterTableSpec struct {
 	OldColumnName *ColumnName
 	Position      *ColumnPosition
 	LockType      LockType
+	// PlacementOptions is the placement policy of AlterTablePlacementPolicy, the policy is removed if it's empty.
+	PlacementOptions []*PlacementOption
+	// IfExists is set for DROP COLUMN and DROP INDEX, IfNotExists is set for ADD COLUMN.
+	IfExists    bool
+	IfNotExists bool
+}
+
+// Restore implements Node interface.
+func (n *AlterTableSpec) Restore(ctx *format.RestoreCtx) error {
+	switch n.Tp {
+	case AlterTableOption:
+		for i, option := range n.Options {
+			if i > 0 {
+				ctx.WritePlain(" ")
+			}
+			if err := restoreTableOption(ctx, option); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	case AlterTableAddColumn:
+		ctx.WriteKeyWord("ADD COLUMN ")
+		if n.IfNotExists {
+			ctx.WriteKeyWord("IF NOT EXISTS ")
+		}
+		if err := n.NewColumn.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(n.restorePosition(ctx))
+	case AlterTableAddConstraint:
+		ctx.WriteKeyWord("ADD ")
+		return errors.Trace(n.Constraint.Restore(ctx))
+	case AlterTableDropColumn:
+		ctx.WriteKeyWord("DROP COLUMN ")
+		if n.IfExists {
+			ctx.WriteKeyWord("IF EXISTS ")
+		}
+		return errors.Trace(n.OldColumnName.Restore(ctx))
+	case AlterTableDropPrimaryKey:
+		ctx.WriteKeyWord("DROP PRIMARY KEY")
+	case AlterTableDropIndex:
+		ctx.WriteKeyWord("DROP INDEX ")
+		if n.IfExists {
+			ctx.WriteKeyWord("IF EXISTS ")
+		}
+		ctx.WriteName(n.Name)
+	case AlterTableDropForeignKey:
+		ctx.WriteKeyWord("DROP FOREIGN KEY ")
+		ctx.WriteName(n.Name)
+	case AlterTableModifyColumn:
+		ctx.WriteKeyWord("MODIFY COLUMN ")
+		if err := n.NewColumn.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(n.restorePosition(ctx))
+	case AlterTableChangeColumn:
+		ctx.WriteKeyWord("CHANGE COLUMN ")
+		if err := n.OldColumnName.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(" ")
+		if err := n.NewColumn.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(n.restorePosition(ctx))
+	case AlterTableRenameTable:
+		ctx.WriteKeyWord("RENAME AS ")
+		return errors.Trace(n.NewTable.Restore(ctx))
+	case AlterTableAlterColumn:
+		ctx.WriteKeyWord("ALTER COLUMN ")
+		if err := n.NewColumn.Name.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		if len(n.NewColumn.Options) == 0 {
+			ctx.WriteKeyWord(" DROP DEFAULT")
+			return nil
+		}
+		ctx.WriteKeyWord(" SET DEFAULT ")
+		return errors.Trace(restoreDefaultValue(ctx, n.NewColumn.Options[0]))
+	case AlterTableLock:
+		ctx.WriteKeyWord("LOCK = ")
+		switch n.LockType {
+		case LockTypeNone:
+			ctx.WriteKeyWord("NONE")
+		case LockTypeDefault:
+			ctx.WriteKeyWord("DEFAULT")
+		case LockTypeShared:
+			ctx.WriteKeyWord("SHARED")
+		case LockTypeExclusive:
+			ctx.WriteKeyWord("EXCLUSIVE")
+		default:
+			return errors.Errorf("invalid lock type %d during restoring AlterTableSpec", n.LockType)
+		}
+	case AlterTablePlacementPolicy:
+		ctx.WriteKeyWord("PLACEMENT POLICY ")
+		if len(n.PlacementOptions) == 0 {
+			ctx.WriteKeyWord("DEFAULT")
+		}
+		if err := restorePlacementOptions(ctx, n.PlacementOptions); err != nil {
+			return errors.Trace(err)
+		}
+	case 0:
+		// DISABLE KEYS and ENABLE KEYS are parsed but ignored, they are restored to the same no-op.
+		ctx.WriteKeyWord("ENABLE KEYS")
+	default:
+		return errors.Errorf("invalid alter table type %d during restoring AlterTableSpec", n.Tp)
+	}
+	return nil
+}
+
+func (n *AlterTableSpec) restorePosition(ctx *format.RestoreCtx) error {
+	if n.Position == nil || n.Position.Tp == ColumnPositionNone {
+		return nil
+	}
+	ctx.WritePlain(" ")
+	return errors.Trace(n.Position.Restore(ctx))
 }
 
 // Accept implements Node Accept interface.
@@ -775,6 +1404,25 @@ type AlterTableStmt struct {
 	Specs []*AlterTableSpec
 }
 
+// Restore implements Node interface.
+func (n *AlterTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ALTER TABLE ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	for i, spec := range n.Specs {
+		if i == 0 {
+			ctx.WritePlain(" ")
+		} else {
+			ctx.WritePlain(", ")
+		}
+		if err := spec.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *AlterTableStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -805,6 +1453,12 @@ type TruncateTableStmt struct {
 	Table *TableName
 }
 
+// Restore implements Node interface.
+func (n *TruncateTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("TRUNCATE TABLE ")
+	return errors.Trace(n.Table.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *TruncateTableStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -819,3 +1473,107 @@ func (n *TruncateTableStmt) Accept(v Visitor) (Node, bool) {
 	n.Table = node.(*TableName)
 	return v.Leave(n)
 }
+
+var rowFormatNames = map[uint64]string{
+	RowFormatDefault:    "DEFAULT",
+	RowFormatDynamic:    "DYNAMIC",
+	RowFormatFixed:      "FIXED",
+	RowFormatCompressed: "COMPRESSED",
+	RowFormatRedundant:  "REDUNDANT",
+	RowFormatCompact:    "COMPACT",
+}
+
+// restoreTableOption restores the table option of CREATE TABLE and ALTER TABLE.
+func restoreTableOption(ctx *format.RestoreCtx, n *TableOption) error {
+	switch n.Tp {
+	case TableOptionEngine:
+		ctx.WriteKeyWord("ENGINE = ")
+		ctx.WriteName(n.StrValue)
+	case TableOptionCharset:
+		ctx.WriteKeyWord("DEFAULT CHARACTER SET = ")
+		ctx.WritePlain(n.StrValue)
+	case TableOptionCollate:
+		ctx.WriteKeyWord("DEFAULT COLLATE = ")
+		ctx.WritePlain(n.StrValue)
+	case TableOptionAutoIncrement:
+		ctx.WriteKeyWord("AUTO_INCREMENT = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionAutoIDCache:
+		ctx.WriteKeyWord("AUTO_ID_CACHE = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionComment:
+		ctx.WriteKeyWord("COMMENT = ")
+		ctx.WriteString(n.StrValue)
+	case TableOptionAvgRowLength:
+		ctx.WriteKeyWord("AVG_ROW_LENGTH = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionCheckSum:
+		ctx.WriteKeyWord("CHECKSUM = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionCompression:
+		ctx.WriteKeyWord("COMPRESSION = ")
+		ctx.WriteString(n.StrValue)
+	case TableOptionConnection:
+		ctx.WriteKeyWord("CONNECTION = ")
+		ctx.WriteString(n.StrValue)
+	case TableOptionPassword:
+		ctx.WriteKeyWord("PASSWORD = ")
+		ctx.WriteString(n.StrValue)
+	case TableOptionKeyBlockSize:
+		ctx.WriteKeyWord("KEY_BLOCK_SIZE = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionMaxRows:
+		ctx.WriteKeyWord("MAX_ROWS = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionMinRows:
+		ctx.WriteKeyWord("MIN_ROWS = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionDelayKeyWrite:
+		ctx.WriteKeyWord("DELAY_KEY_WRITE = ")
+		ctx.WritePlainf("%d", n.UintValue)
+	case TableOptionRowFormat:
+		name, ok := rowFormatNames[n.UintValue]
+		if !ok {
+			return errors.Errorf("invalid row format %d during restoring TableOption", n.UintValue)
+		}
+		ctx.WriteKeyWord("ROW_FORMAT = ")
+		ctx.WriteKeyWord(name)
+	case TableOptionStatsPersistent:
+		// The value of STATS_PERSISTENT is parsed but ignored.
+		ctx.WriteKeyWord("STATS_PERSISTENT = DEFAULT")
+	case TableOptionLocation:
+		ctx.WriteKeyWord("LOCATION = ")
+		ctx.WriteString(n.StrValue)
+	case TableOptionFormat:
+		ctx.WriteKeyWord("FORMAT = ")
+		ctx.WriteString(n.StrValue)
+	case TableOptionPlacementPolicy:
+		ctx.WriteKeyWord("PLACEMENT POLICY ")
+		return errors.Trace(restorePlacementOptions(ctx, n.PlacementOptions))
+	default:
+		return errors.Errorf("invalid table option type %d during restoring TableOption", n.Tp)
+	}
+	return nil
+}
+
+func restorePlacementOptions(ctx *format.RestoreCtx, options []*PlacementOption) error {
+	for i, option := range options {
+		if i > 0 {
+			ctx.WritePlain(" ")
+		}
+		switch option.Tp {
+		case PlacementOptionReplicas:
+			ctx.WriteKeyWord("REPLICAS = ")
+			ctx.WritePlainf("%d", option.UintValue)
+		case PlacementOptionConstraints:
+			ctx.WriteKeyWord("CONSTRAINTS = ")
+			ctx.WriteString(option.StrValue)
+		case PlacementOptionLeaderConstraints:
+			ctx.WriteKeyWord("LEADER_CONSTRAINTS = ")
+			ctx.WriteString(option.StrValue)
+		default:
+			return errors.Errorf("invalid placement option %d during restoring PlacementOption", option.Tp)
+		}
+	}
+	return nil
+}
@@ -38,6 +38,7 @@ func (ts *testDDLSuite) TestDDLVisitorCover(c *C) {
 		{&DropDatabaseStmt{}, 0, 0},
 		{&DropIndexStmt{Table: &TableName{}}, 0, 0},
 		{&DropTableStmt{Tables: []*TableName{{}, {}}}, 0, 0},
+		{&DropViewStmt{Views: []*TableName{{}, {}}}, 0, 0},
 		{&RenameTableStmt{OldTable: &TableName{}, NewTable: &TableName{}}, 0, 0},
 		{&TruncateTableStmt{Table: &TableName{}}, 0, 0},
 
@@ -62,3 +63,27 @@ func (ts *testDDLSuite) TestDDLVisitorCover(c *C) {
 		v.node.Accept(visitor1{})
 	}
 }
+
+func (ts *testDDLSuite) TestDDLRestore(c *C) {
+	cases := []restoreCase{
+		{"create database if not exists db default charset utf8 collate utf8_bin", "CREATE DATABASE IF NOT EXISTS `db` CHARACTER SET = utf8 COLLATE = utf8_bin"},
+		{"drop database if exists db", "DROP DATABASE IF EXISTS `db`"},
+		{"create table t (a int unsigned not null auto_increment primary key, b varchar(10) binary default 'x' comment 'c', c timestamp default current_timestamp on update current_timestamp)", "CREATE TABLE `t` (`a` INT(11) UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY, `b` VARCHAR(10) BINARY DEFAULT 'x' COMMENT 'c', `c` TIMESTAMP DEFAULT CURRENT_TIMESTAMP() ON UPDATE CURRENT_TIMESTAMP())"},
+		{"create table if not exists t (a enum('x', 'y'), b int as (a + 1) stored, index idx (a, b(10)), unique key (b), constraint fk foreign key (a) references t2 (b) on delete cascade) engine = innodb auto_increment = 10 default charset = utf8 comment = 'x'", "CREATE TABLE IF NOT EXISTS `t` (`a` ENUM('x', 'y'), `b` INT(11) GENERATED ALWAYS AS (`a` + 1) STORED, KEY `idx`(`a`, `b`(10)), UNIQUE KEY(`b`), FOREIGN KEY `fk`(`a`) REFERENCES `t2`(`b`) ON DELETE CASCADE) ENGINE = `innodb` AUTO_INCREMENT = 10 DEFAULT CHARACTER SET = utf8 COMMENT = 'x'"},
+		{"create table t like t2", "CREATE TABLE `t` LIKE `t2`"},
+		{"create table t (a int) comment 'x' placement policy replicas 5 constraints '+zone=sh'", "CREATE TABLE `t` (`a` INT(11)) COMMENT = 'x' PLACEMENT POLICY REPLICAS = 5 CONSTRAINTS = '+zone=sh'"},
+		{"drop table if exists t1, t2", "DROP TABLE IF EXISTS `t1`, `t2`"},
+		{"drop view if exists v1, test.v2", "DROP VIEW IF EXISTS `v1`, `test`.`v2`"},
+		{"rename table t1 to t2, t3 to t4", "RENAME TABLE `t1` TO `t2`, `t3` TO `t4`"},
+		{"create unique index idx on t (a, b) using btree comment 'x'", "CREATE UNIQUE INDEX `idx` ON `t` (`a`, `b`) USING BTREE COMMENT 'x'"},
+		{"create index idx on t (a asc, b(10) desc)", "CREATE INDEX `idx` ON `t` (`a`, `b`(10) DESC)"},
+		{"drop index idx on t", "DROP INDEX `idx` ON `t`"},
+		{"truncate table t", "TRUNCATE TABLE `t`"},
+		{"alter table t add column a int first, drop column b, add index idx (c), drop primary key, drop index idx2", "ALTER TABLE `t` ADD COLUMN `a` INT(11) FIRST, DROP COLUMN `b`, ADD KEY `idx`(`c`), DROP PRIMARY KEY, DROP INDEX `idx2`"},
+		{"alter table t modify column a bigint after b, change b c int, alter column d set default 1, alter column e drop default, rename to t2", "ALTER TABLE `t` MODIFY COLUMN `a` BIGINT(20) AFTER `b`, CHANGE COLUMN `b` `c` INT(11), ALTER COLUMN `d` SET DEFAULT 1, ALTER COLUMN `e` DROP DEFAULT, RENAME AS `t2`"},
+		{"alter table t add constraint pk primary key (a), drop foreign key fk, lock = none", "ALTER TABLE `t` ADD CONSTRAINT `pk` PRIMARY KEY(`a`), DROP FOREIGN KEY `fk`, LOCK = NONE"},
+		{"alter table t placement policy replicas 5 constraints = '+zone=sh' leader_constraints '+disk=ssd'", "ALTER TABLE `t` PLACEMENT POLICY REPLICAS = 5 CONSTRAINTS = '+zone=sh' LEADER_CONSTRAINTS = '+disk=ssd'"},
+		{"alter table t placement policy = default", "ALTER TABLE `t` PLACEMENT POLICY DEFAULT"},
+	}
+	runRestoreTest(c, cases)
+}
@@ -14,9 +14,11 @@
 package ast
 
 import (
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/format"
 )
 
 var (
@@ -76,6 +78,59 @@ type Join struct {
 	NaturalJoin bool
 }
 
+// Restore implements Node interface.
+func (n *Join) Restore(ctx *format.RestoreCtx) error {
+	if err := restoreJoinChild(ctx, n.Left); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Right == nil {
+		return nil
+	}
+	if n.NaturalJoin {
+		ctx.WriteKeyWord(" NATURAL")
+	}
+	switch n.Tp {
+	case LeftJoin:
+		ctx.WriteKeyWord(" LEFT")
+	case RightJoin:
+		ctx.WriteKeyWord(" RIGHT")
+	}
+	ctx.WriteKeyWord(" JOIN ")
+	if err := restoreJoinChild(ctx, n.Right); err != nil {
+		return errors.Trace(err)
+	}
+	if n.On != nil {
+		ctx.WriteKeyWord(" ON ")
+		if err := n.On.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(n.Using) > 0 {
+		ctx.WriteKeyWord(" USING ")
+		ctx.WritePlain("(")
+		if err := restoreColumnNames(ctx, n.Using); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
+// restoreJoinChild restores the child of the join, the nested join is enclosed in
+// parentheses to keep the structure of the join tree.
+func restoreJoinChild(ctx *format.RestoreCtx, child ResultSetNode) error {
+	join, ok := child.(*Join)
+	if !ok || join.Right == nil {
+		return errors.Trace(child.Restore(ctx))
+	}
+	ctx.WritePlain("(")
+	if err := join.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *Join) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -147,6 +202,76 @@ type IndexHint struct {
 	HintScope  IndexHintScope
 }
 
+// Restore implements Node interface.
+func (n *TableName) Restore(ctx *format.RestoreCtx) error {
+	n.restoreName(ctx)
+	n.restoreIndexHints(ctx)
+	return nil
+}
+
+func (n *TableName) restoreName(ctx *format.RestoreCtx) {
+	if n.Schema.O != "" {
+		ctx.WriteName(n.Schema.O)
+		ctx.WritePlain(".")
+	}
+	ctx.WriteName(n.Name.O)
+}
+
+func (n *TableName) restoreIndexHints(ctx *format.RestoreCtx) {
+	for _, hint := range n.IndexHints {
+		switch hint.HintType {
+		case HintUse:
+			ctx.WriteKeyWord(" USE INDEX")
+		case HintIgnore:
+			ctx.WriteKeyWord(" IGNORE INDEX")
+		case HintForce:
+			ctx.WriteKeyWord(" FORCE INDEX")
+		}
+		switch hint.HintScope {
+		case HintForJoin:
+			ctx.WriteKeyWord(" FOR JOIN")
+		case HintForOrderBy:
+			ctx.WriteKeyWord(" FOR ORDER BY")
+		case HintForGroupBy:
+			ctx.WriteKeyWord(" FOR GROUP BY")
+		}
+		ctx.WritePlain(" (")
+		for i, name := range hint.IndexNames {
+			if i > 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WriteName(name.O)
+		}
+		ctx.WritePlain(")")
+	}
+}
+
+// restoreTableNames restores the table names separated by commas.
+func restoreTableNames(ctx *format.RestoreCtx, tables []*TableName) error {
+	for i, table := range tables {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := table.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// restoreColumnNames restores the column names separated by commas.
+func restoreColumnNames(ctx *format.RestoreCtx, cols []*ColumnName) error {
+	for i, col := range cols {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := col.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *TableName) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -163,6 +288,11 @@ type DeleteTableList struct {
 	Tables []*TableName
 }
 
+// Restore implements Node interface.
+func (n *DeleteTableList) Restore(ctx *format.RestoreCtx) error {
+	return errors.Trace(restoreTableNames(ctx, n.Tables))
+}
+
 // Accept implements Node Accept interface.
 func (n *DeleteTableList) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -189,6 +319,11 @@ type OnCondition struct {
 	Expr ExprNode
 }
 
+// Restore implements Node interface.
+func (n *OnCondition) Restore(ctx *format.RestoreCtx) error {
+	return errors.Trace(n.Expr.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *OnCondition) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -216,6 +351,33 @@ type TableSource struct {
 	AsName model.CIStr
 }
 
+// Restore implements Node interface.
+func (n *TableSource) Restore(ctx *format.RestoreCtx) error {
+	switch source := n.Source.(type) {
+	case *TableName:
+		// The alias is between the table name and the index hints.
+		source.restoreName(ctx)
+		if n.AsName.O != "" {
+			ctx.WriteKeyWord(" AS ")
+			ctx.WriteName(n.AsName.O)
+		}
+		source.restoreIndexHints(ctx)
+	case *SelectStmt, *UnionStmt:
+		ctx.WritePlain("(")
+		if err := source.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		if n.AsName.O != "" {
+			ctx.WriteKeyWord(" AS ")
+			ctx.WriteName(n.AsName.O)
+		}
+	default:
+		return errors.Trace(restoreJoinChild(ctx, source))
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *TableSource) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -249,6 +411,8 @@ const (
 	SelectLockNone SelectLockType = iota
 	SelectLockForUpdate
 	SelectLockInShareMode
+	SelectLockForUpdateNoWait
+	SelectLockForUpdateSkipLocked
 )
 
 // String implements fmt.Stringer.
@@ -260,10 +424,19 @@ func (slt SelectLockType) String() string {
 		return "for update"
 	case SelectLockInShareMode:
 		return "in share mode"
+	case SelectLockForUpdateNoWait:
+		return "for update nowait"
+	case SelectLockForUpdateSkipLocked:
+		return "for update skip locked"
 	}
 	return "unsupported select lock type"
 }
 
+// IsForUpdate returns whether the rows are locked for update.
+func (slt SelectLockType) IsForUpdate() bool {
+	return slt == SelectLockForUpdate || slt == SelectLockForUpdateNoWait || slt == SelectLockForUpdateSkipLocked
+}
+
 // WildCardField is a special type of select field content.
 type WildCardField struct {
 	node
@@ -272,6 +445,20 @@ type WildCardField struct {
 	Schema model.CIStr
 }
 
+// Restore implements Node interface.
+func (n *WildCardField) Restore(ctx *format.RestoreCtx) error {
+	if n.Schema.O != "" {
+		ctx.WriteName(n.Schema.O)
+		ctx.WritePlain(".")
+	}
+	if n.Table.O != "" {
+		ctx.WriteName(n.Table.O)
+		ctx.WritePlain(".")
+	}
+	ctx.WritePlain("*")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *WildCardField) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -302,6 +489,21 @@ type SelectField struct {
 	Auxiliary bool
 }
 
+// Restore implements Node interface.
+func (n *SelectField) Restore(ctx *format.RestoreCtx) error {
+	if n.WildCard != nil {
+		return errors.Trace(n.WildCard.Restore(ctx))
+	}
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.AsName.O != "" {
+		ctx.WriteKeyWord(" AS ")
+		ctx.WriteName(n.AsName.O)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *SelectField) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -326,6 +528,19 @@ type FieldList struct {
 	Fields []*SelectField
 }
 
+// Restore implements Node interface.
+func (n *FieldList) Restore(ctx *format.RestoreCtx) error {
+	for i, field := range n.Fields {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := field.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *FieldList) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -350,6 +565,11 @@ type TableRefsClause struct {
 	TableRefs *Join
 }
 
+// Restore implements Node interface.
+func (n *TableRefsClause) Restore(ctx *format.RestoreCtx) error {
+	return errors.Trace(n.TableRefs.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *TableRefsClause) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -373,6 +593,30 @@ type ByItem struct {
 	Desc bool
 }
 
+// Restore implements Node interface.
+func (n *ByItem) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Desc {
+		ctx.WriteKeyWord(" DESC")
+	}
+	return nil
+}
+
+// restoreByItems restores the items of GROUP BY and ORDER BY separated by commas.
+func restoreByItems(ctx *format.RestoreCtx, items []*ByItem) error {
+	for i, item := range items {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := item.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ByItem) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -394,6 +638,12 @@ type GroupByClause struct {
 	Items []*ByItem
 }
 
+// Restore implements Node interface.
+func (n *GroupByClause) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("GROUP BY ")
+	return errors.Trace(restoreByItems(ctx, n.Items))
+}
+
 // Accept implements Node Accept interface.
 func (n *GroupByClause) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -417,6 +667,12 @@ type HavingClause struct {
 	Expr ExprNode
 }
 
+// Restore implements Node interface.
+func (n *HavingClause) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("HAVING ")
+	return errors.Trace(n.Expr.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *HavingClause) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -439,6 +695,12 @@ type OrderByClause struct {
 	ForUnion bool
 }
 
+// Restore implements Node interface.
+func (n *OrderByClause) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ORDER BY ")
+	return errors.Trace(restoreByItems(ctx, n.Items))
+}
+
 // Accept implements Node Accept interface.
 func (n *OrderByClause) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -486,6 +748,100 @@ type SelectStmt struct {
 	TableHints []*TableOptimizerHint
 }
 
+// Restore implements Node interface.
+func (n *SelectStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SELECT ")
+	hints := n.TableHints
+	if len(hints) == 0 && n.SelectStmtOpts != nil {
+		hints = n.SelectStmtOpts.TableHints
+	}
+	if len(hints) > 0 {
+		ctx.WritePlain("/*+ ")
+		for i, hint := range hints {
+			if i > 0 {
+				ctx.WritePlain(" ")
+			}
+			if err := hint.Restore(ctx); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		ctx.WritePlain(" */ ")
+	}
+	if n.Distinct {
+		ctx.WriteKeyWord("DISTINCT ")
+	}
+	if n.SelectStmtOpts != nil {
+		switch n.SelectStmtOpts.Priority {
+		case mysql.HighPriority:
+			ctx.WriteKeyWord("HIGH_PRIORITY ")
+		case mysql.LowPriority:
+			ctx.WriteKeyWord("LOW_PRIORITY ")
+		}
+		if n.SelectStmtOpts.SQLCache {
+			ctx.WriteKeyWord("SQL_CACHE ")
+		}
+		if n.SelectStmtOpts.CalcFoundRows {
+			ctx.WriteKeyWord("SQL_CALC_FOUND_ROWS ")
+		}
+	}
+	if err := n.Fields.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.From != nil {
+		ctx.WriteKeyWord(" FROM ")
+		if err := n.From.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	} else if n.Where != nil {
+		// The WHERE clause without a FROM clause is only allowed after FROM DUAL.
+		ctx.WriteKeyWord(" FROM DUAL")
+	}
+	if n.Where != nil {
+		ctx.WriteKeyWord(" WHERE ")
+		if err := n.Where.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.GroupBy != nil {
+		ctx.WritePlain(" ")
+		if err := n.GroupBy.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Having != nil {
+		ctx.WritePlain(" ")
+		if err := n.Having.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.OrderBy != nil {
+		ctx.WritePlain(" ")
+		if err := n.OrderBy.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Limit != nil {
+		ctx.WriteKeyWord(" LIMIT ")
+		if err := n.Limit.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	switch n.LockTp {
+	case SelectLockNone:
+	case SelectLockForUpdate:
+		ctx.WriteKeyWord(" FOR UPDATE")
+	case SelectLockForUpdateNoWait:
+		ctx.WriteKeyWord(" FOR UPDATE NOWAIT")
+	case SelectLockForUpdateSkipLocked:
+		ctx.WriteKeyWord(" FOR UPDATE SKIP LOCKED")
+	case SelectLockInShareMode:
+		ctx.WriteKeyWord(" LOCK IN SHARE MODE")
+	default:
+		return errors.Errorf("invalid select lock type %d during restoring SelectStmt", n.LockTp)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -572,6 +928,19 @@ type UnionSelectList struct {
 	Selects []*SelectStmt
 }
 
+// Restore implements Node interface.
+func (n *UnionSelectList) Restore(ctx *format.RestoreCtx) error {
+	for i, sel := range n.Selects {
+		if i > 0 {
+			ctx.WriteKeyWord(" UNION ")
+		}
+		if err := sel.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *UnionSelectList) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -601,6 +970,46 @@ type UnionStmt struct {
 	Limit      *Limit
 }
 
+// Restore implements Node interface.
+func (n *UnionStmt) Restore(ctx *format.RestoreCtx) error {
+	selects := n.SelectList.Selects
+	for i, sel := range selects {
+		if i > 0 {
+			if n.Distinct {
+				ctx.WriteKeyWord(" UNION ")
+			} else {
+				ctx.WriteKeyWord(" UNION ALL ")
+			}
+		}
+		// The ORDER BY and LIMIT of a select that is not the last one, or of the last select when
+		// the union has its own ORDER BY or LIMIT, need the parentheses.
+		isLast := i == len(selects)-1
+		needParen := !isLast && (sel.OrderBy != nil || sel.Limit != nil) || isLast && (n.OrderBy != nil || n.Limit != nil)
+		if needParen {
+			ctx.WritePlain("(")
+		}
+		if err := sel.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		if needParen {
+			ctx.WritePlain(")")
+		}
+	}
+	if n.OrderBy != nil {
+		ctx.WritePlain(" ")
+		if err := n.OrderBy.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Limit != nil {
+		ctx.WriteKeyWord(" LIMIT ")
+		if err := n.Limit.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -641,6 +1050,28 @@ type Assignment struct {
 	Expr ExprNode
 }
 
+// Restore implements Node interface.
+func (n *Assignment) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Column.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(" = ")
+	return errors.Trace(n.Expr.Restore(ctx))
+}
+
+// restoreAssignments restores the assignments separated by commas.
+func restoreAssignments(ctx *format.RestoreCtx, list []*Assignment) error {
+	for i, assignment := range list {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := assignment.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *Assignment) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -674,6 +1105,47 @@ type LoadDataStmt struct {
 	LinesInfo  *LinesClause
 }
 
+// Restore implements Node interface.
+func (n *LoadDataStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("LOAD DATA ")
+	if n.IsLocal {
+		ctx.WriteKeyWord("LOCAL ")
+	}
+	ctx.WriteKeyWord("INFILE ")
+	ctx.WriteString(n.Path)
+	ctx.WriteKeyWord(" INTO TABLE ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.FieldsInfo != nil {
+		ctx.WriteKeyWord(" FIELDS TERMINATED BY ")
+		ctx.WriteString(n.FieldsInfo.Terminated)
+		if n.FieldsInfo.Enclosed != 0 {
+			ctx.WriteKeyWord(" ENCLOSED BY ")
+			ctx.WriteString(string(n.FieldsInfo.Enclosed))
+		}
+		ctx.WriteKeyWord(" ESCAPED BY ")
+		ctx.WriteString(string(n.FieldsInfo.Escaped))
+	}
+	if n.LinesInfo != nil {
+		ctx.WriteKeyWord(" LINES")
+		if n.LinesInfo.Starting != "" {
+			ctx.WriteKeyWord(" STARTING BY ")
+			ctx.WriteString(n.LinesInfo.Starting)
+		}
+		ctx.WriteKeyWord(" TERMINATED BY ")
+		ctx.WriteString(n.LinesInfo.Terminated)
+	}
+	if len(n.Columns) > 0 {
+		ctx.WritePlain(" (")
+		if err := restoreColumnNames(ctx, n.Columns); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *LoadDataStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -725,6 +1197,70 @@ type InsertStmt struct {
 	Priority    mysql.PriorityEnum
 	OnDuplicate []*Assignment
 	Select      ResultSetNode
+	// Returning is the RETURNING clause, the inserted rows are returned as a result set if it's not nil.
+	Returning *FieldList
+}
+
+// Restore implements Node interface.
+func (n *InsertStmt) Restore(ctx *format.RestoreCtx) error {
+	if n.IsReplace {
+		ctx.WriteKeyWord("REPLACE ")
+	} else {
+		ctx.WriteKeyWord("INSERT ")
+	}
+	switch n.Priority {
+	case mysql.LowPriority:
+		ctx.WriteKeyWord("LOW_PRIORITY ")
+	case mysql.HighPriority:
+		ctx.WriteKeyWord("HIGH_PRIORITY ")
+	case mysql.DelayedPriority:
+		ctx.WriteKeyWord("DELAYED ")
+	}
+	if n.IgnoreErr {
+		ctx.WriteKeyWord("IGNORE ")
+	}
+	ctx.WriteKeyWord("INTO ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if len(n.Columns) > 0 {
+		ctx.WritePlain(" (")
+		if err := restoreColumnNames(ctx, n.Columns); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	}
+	switch {
+	case n.Lists != nil:
+		ctx.WriteKeyWord(" VALUES ")
+		for i, row := range n.Lists {
+			if i > 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WritePlain("(")
+			if err := restoreExprs(ctx, row); err != nil {
+				return errors.Trace(err)
+			}
+			ctx.WritePlain(")")
+		}
+	case n.Setlist != nil:
+		ctx.WriteKeyWord(" SET ")
+		if err := restoreAssignments(ctx, n.Setlist); err != nil {
+			return errors.Trace(err)
+		}
+	case n.Select != nil:
+		ctx.WritePlain(" ")
+		if err := n.Select.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if len(n.OnDuplicate) > 0 {
+		ctx.WriteKeyWord(" ON DUPLICATE KEY UPDATE ")
+		if err := restoreAssignments(ctx, n.OnDuplicate); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(restoreReturning(ctx, n.Returning))
 }
 
 // Accept implements Node Accept interface.
@@ -779,9 +1315,25 @@ func (n *InsertStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.OnDuplicate[i] = node.(*Assignment)
 	}
+	if n.Returning != nil {
+		node, ok := n.Returning.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Returning = node.(*FieldList)
+	}
 	return v.Leave(n)
 }
 
+// restoreReturning restores the RETURNING clause of INSERT, REPLACE, UPDATE and DELETE.
+func restoreReturning(ctx *format.RestoreCtx, returning *FieldList) error {
+	if returning == nil {
+		return nil
+	}
+	ctx.WriteKeyWord(" RETURNING ")
+	return errors.Trace(returning.Restore(ctx))
+}
+
 // DeleteStmt is a statement to delete rows from table.
 // See https://dev.mysql.com/doc/refman/5.7/en/delete.html
 type DeleteStmt struct {
@@ -799,6 +1351,65 @@ type DeleteStmt struct {
 	Quick        bool
 	IsMultiTable bool
 	BeforeFrom   bool
+	// Returning is the RETURNING clause, the deleted rows are returned as a result set if it's not nil.
+	Returning *FieldList
+}
+
+// Restore implements Node interface.
+func (n *DeleteStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DELETE ")
+	if n.LowPriority {
+		ctx.WriteKeyWord("LOW_PRIORITY ")
+	}
+	if n.Quick {
+		ctx.WriteKeyWord("QUICK ")
+	}
+	if n.Ignore {
+		ctx.WriteKeyWord("IGNORE ")
+	}
+	switch {
+	case !n.IsMultiTable:
+		ctx.WriteKeyWord("FROM ")
+		if err := n.TableRefs.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	case n.BeforeFrom:
+		if err := n.Tables.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteKeyWord(" FROM ")
+		if err := n.TableRefs.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	default:
+		ctx.WriteKeyWord("FROM ")
+		if err := n.Tables.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteKeyWord(" USING ")
+		if err := n.TableRefs.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Where != nil {
+		ctx.WriteKeyWord(" WHERE ")
+		if err := n.Where.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Order != nil {
+		ctx.WritePlain(" ")
+		if err := n.Order.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Limit != nil {
+		ctx.WriteKeyWord(" LIMIT ")
+		if err := n.Limit.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(restoreReturning(ctx, n.Returning))
 }
 
 // Accept implements Node Accept interface.
@@ -842,6 +1453,13 @@ func (n *DeleteStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Limit = node.(*Limit)
 	}
+	if n.Returning != nil {
+		node, ok = n.Returning.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Returning = node.(*FieldList)
+	}
 	return v.Leave(n)
 }
 
@@ -858,6 +1476,45 @@ type UpdateStmt struct {
 	LowPriority   bool
 	IgnoreErr     bool
 	MultipleTable bool
+	// Returning is the RETURNING clause, the updated rows are returned as a result set if it's not nil.
+	Returning *FieldList
+}
+
+// Restore implements Node interface.
+func (n *UpdateStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("UPDATE ")
+	if n.LowPriority {
+		ctx.WriteKeyWord("LOW_PRIORITY ")
+	}
+	if n.IgnoreErr {
+		ctx.WriteKeyWord("IGNORE ")
+	}
+	if err := n.TableRefs.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" SET ")
+	if err := restoreAssignments(ctx, n.List); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Where != nil {
+		ctx.WriteKeyWord(" WHERE ")
+		if err := n.Where.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Order != nil {
+		ctx.WritePlain(" ")
+		if err := n.Order.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.Limit != nil {
+		ctx.WriteKeyWord(" LIMIT ")
+		if err := n.Limit.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(restoreReturning(ctx, n.Returning))
 }
 
 // Accept implements Node Accept interface.
@@ -900,6 +1557,13 @@ func (n *UpdateStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Limit = node.(*Limit)
 	}
+	if n.Returning != nil {
+		node, ok = n.Returning.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Returning = node.(*FieldList)
+	}
 	return v.Leave(n)
 }
 
@@ -911,6 +1575,20 @@ type Limit struct {
 	Offset ExprNode
 }
 
+// Restore implements Node interface.
+func (n *Limit) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Count.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Offset != nil {
+		ctx.WriteKeyWord(" OFFSET ")
+		if err := n.Offset.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *Limit) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -964,6 +1642,9 @@ const (
 	ShowStatsHistograms
 	ShowStatsBuckets
 	ShowPlugins
+	ShowStatsJSON
+	ShowProgress
+	ShowSessionStates
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
@@ -979,6 +1660,8 @@ type ShowStmt struct {
 	Flag   int         // Some flag parsed from sql, such as FULL.
 	Full   bool
 	User   *auth.UserIdentity // Used for show grants.
+	// ConnectionID is used for show progress.
+	ConnectionID uint64
 
 	// GlobalScope is used by show variables
 	GlobalScope bool
@@ -986,6 +1669,124 @@ type ShowStmt struct {
 	Where       ExprNode
 }
 
+// Restore implements Node interface.
+func (n *ShowStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SHOW ")
+	switch n.Tp {
+	case ShowEngines:
+		ctx.WriteKeyWord("ENGINES")
+	case ShowDatabases:
+		ctx.WriteKeyWord("DATABASES")
+	case ShowCharset:
+		ctx.WriteKeyWord("CHARSET")
+	case ShowTables:
+		if n.Full {
+			ctx.WriteKeyWord("FULL ")
+		}
+		ctx.WriteKeyWord("TABLES")
+		n.restoreDBName(ctx)
+	case ShowTableStatus:
+		ctx.WriteKeyWord("TABLE STATUS")
+		n.restoreDBName(ctx)
+	case ShowIndex:
+		ctx.WriteKeyWord("INDEX IN ")
+		if err := n.Table.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	case ShowColumns:
+		if n.Full {
+			ctx.WriteKeyWord("FULL ")
+		}
+		ctx.WriteKeyWord("COLUMNS IN ")
+		if err := n.Table.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		n.restoreDBName(ctx)
+	case ShowWarnings:
+		ctx.WriteKeyWord("WARNINGS")
+	case ShowVariables, ShowStatus:
+		if n.GlobalScope {
+			ctx.WriteKeyWord("GLOBAL ")
+		}
+		if n.Tp == ShowVariables {
+			ctx.WriteKeyWord("VARIABLES")
+		} else {
+			ctx.WriteKeyWord("STATUS")
+		}
+	case ShowCollation:
+		ctx.WriteKeyWord("COLLATION")
+	case ShowTriggers:
+		ctx.WriteKeyWord("TRIGGERS")
+		n.restoreDBName(ctx)
+	case ShowProcedureStatus:
+		ctx.WriteKeyWord("PROCEDURE STATUS")
+	case ShowEvents:
+		ctx.WriteKeyWord("EVENTS")
+		n.restoreDBName(ctx)
+	case ShowPlugins:
+		ctx.WriteKeyWord("PLUGINS")
+	case ShowCreateTable:
+		ctx.WriteKeyWord("CREATE TABLE ")
+		return errors.Trace(n.Table.Restore(ctx))
+	case ShowCreateDatabase:
+		ctx.WriteKeyWord("CREATE DATABASE ")
+		ctx.WriteName(n.DBName)
+		return nil
+	case ShowGrants:
+		ctx.WriteKeyWord("GRANTS")
+		if n.User != nil {
+			ctx.WriteKeyWord(" FOR ")
+			restoreUserIdentity(ctx, n.User)
+		}
+		return nil
+	case ShowProcessList:
+		if n.Full {
+			ctx.WriteKeyWord("FULL ")
+		}
+		ctx.WriteKeyWord("PROCESSLIST")
+		return nil
+	case ShowProgress:
+		ctx.WriteKeyWord("PROGRESS FOR ")
+		ctx.WritePlainf("%d", n.ConnectionID)
+		return nil
+	case ShowSessionStates:
+		ctx.WriteKeyWord("SESSION_STATES")
+		return nil
+	case ShowStatsMeta:
+		ctx.WriteKeyWord("STATS_META")
+	case ShowStatsHistograms:
+		ctx.WriteKeyWord("STATS_HISTOGRAMS")
+	case ShowStatsBuckets:
+		ctx.WriteKeyWord("STATS_BUCKETS")
+	case ShowStatsJSON:
+		ctx.WriteKeyWord("STATS_JSON FROM ")
+		return errors.Trace(n.Table.Restore(ctx))
+	default:
+		return errors.Errorf("invalid show type %d during restoring ShowStmt", n.Tp)
+	}
+	if n.Pattern != nil {
+		// The parser also puts "WHERE a LIKE b" into Pattern, it has the left operand.
+		if n.Pattern.Expr != nil {
+			ctx.WriteKeyWord(" WHERE ")
+		} else {
+			ctx.WritePlain(" ")
+		}
+		return errors.Trace(n.Pattern.Restore(ctx))
+	}
+	if n.Where != nil {
+		ctx.WriteKeyWord(" WHERE ")
+		return errors.Trace(n.Where.Restore(ctx))
+	}
+	return nil
+}
+
+func (n *ShowStmt) restoreDBName(ctx *format.RestoreCtx) {
+	if n.DBName != "" {
+		ctx.WriteKeyWord(" IN ")
+		ctx.WriteName(n.DBName)
+	}
+}
+
 // Accept implements Node Accept interface.
 func (n *ShowStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -68,3 +68,35 @@ func (ts *testDMLSuite) TestDMLVisitorCover(c *C) {
 		v.node.Accept(visitor1{})
 	}
 }
+
+func (ts *testDMLSuite) TestDMLRestore(c *C) {
+	cases := []restoreCase{
+		{"select distinct a as x, t.* from t where a > 1 group by a having count(*) > 1 order by a desc limit 1, 2", "SELECT DISTINCT `a` AS `x`, `t`.* FROM `t` WHERE `a` > 1 GROUP BY `a` HAVING COUNT(1) > 1 ORDER BY `a` DESC LIMIT 2 OFFSET 1"},
+		{"select /*+ TIDB_SMJ(t1, t2) */ * from t1 join t2 on t1.a = t2.a left join t3 using (b)", "SELECT /*+ TIDB_SMJ(`t1`, `t2`) */ * FROM (`t1` JOIN `t2` ON `t1`.`a` = `t2`.`a`) LEFT JOIN `t3` USING (`b`)"},
+		{"select * from t1, (select a from t2) as s for update", "SELECT * FROM `t1` JOIN (SELECT `a` FROM `t2`) AS `s` FOR UPDATE"},
+		{"select * from t use index (a, b) ignore index (c) lock in share mode", "SELECT * FROM `t` USE INDEX (`a`, `b`) IGNORE INDEX (`c`) LOCK IN SHARE MODE"},
+		{"select 1 from dual where 1", "SELECT 1 FROM DUAL WHERE 1"},
+		{"select a from t union all (select b from t2 order by b limit 1) union select c from t3 order by a", "SELECT `a` FROM `t` UNION (SELECT `b` FROM `t2` ORDER BY `b` LIMIT 1) UNION SELECT `c` FROM `t3` ORDER BY `a`"},
+		{"insert ignore into t (a, b) values (1, 2), (3, 4)", "INSERT IGNORE INTO `t` (`a`, `b`) VALUES (1, 2), (3, 4)"},
+		{"insert into t set a = 1, b = default", "INSERT INTO `t` SET `a` = 1, `b` = DEFAULT"},
+		{"insert low_priority into t select * from t2", "INSERT LOW_PRIORITY INTO `t` SELECT * FROM `t2`"},
+		{"replace into t values (1)", "REPLACE INTO `t` VALUES (1)"},
+		{"update low_priority ignore t set a = a + 1 where b = 2 order by c limit 10", "UPDATE LOW_PRIORITY IGNORE `t` SET `a` = `a` + 1 WHERE `b` = 2 ORDER BY `c` LIMIT 10"},
+		{"update t1, t2 set t1.a = t2.a where t1.b = t2.b", "UPDATE `t1` JOIN `t2` SET `t1`.`a` = `t2`.`a` WHERE `t1`.`b` = `t2`.`b`"},
+		{"delete quick from t where a = 1 order by b limit 1", "DELETE QUICK FROM `t` WHERE `a` = 1 ORDER BY `b` LIMIT 1"},
+		{"delete t1, t2 from t1 join t2 where t1.a = t2.a", "DELETE `t1`, `t2` FROM `t1` JOIN `t2` WHERE `t1`.`a` = `t2`.`a`"},
+		{"delete from t1 using t1, t2 where t1.a = t2.a", "DELETE FROM `t1` USING `t1` JOIN `t2` WHERE `t1`.`a` = `t2`.`a`"},
+		{"insert into t values (1) on duplicate key update a = 2 returning *, a + 1 as b", "INSERT INTO `t` VALUES (1) ON DUPLICATE KEY UPDATE `a` = 2 RETURNING *, `a` + 1 AS `b`"},
+		{"replace into t select * from t2 returning t.*", "REPLACE INTO `t` SELECT * FROM `t2` RETURNING `t`.*"},
+		{"update t set a = 1 where b = 2 limit 1 returning a", "UPDATE `t` SET `a` = 1 WHERE `b` = 2 LIMIT 1 RETURNING `a`"},
+		{"delete from t where a = 1 returning a, b", "DELETE FROM `t` WHERE `a` = 1 RETURNING `a`, `b`"},
+		{"load data local infile '/tmp/a.csv' into table t fields terminated by ',' enclosed by '\"' lines terminated by '\\n' (a, b)", "LOAD DATA LOCAL INFILE '/tmp/a.csv' INTO TABLE `t` FIELDS TERMINATED BY ',' ENCLOSED BY '\"' ESCAPED BY '\\\\' LINES TERMINATED BY '\n' (`a`, `b`)"},
+		{"show full columns from t from db like 'a%'", "SHOW FULL COLUMNS IN `t` IN `db` LIKE 'a%'"},
+		{"show global variables where variable_name = 'a'", "SHOW GLOBAL VARIABLES WHERE `variable_name` = 'a'"},
+		{"show status where variable_name like 'a%'", "SHOW STATUS WHERE `variable_name` LIKE 'a%'"},
+		{"show create table t", "SHOW CREATE TABLE `t`"},
+		{"show grants for 'u'@'%'", "SHOW GRANTS FOR 'u'@'%'"},
+		{"show full processlist", "SHOW FULL PROCESSLIST"},
+	}
+	runRestoreTest(c, cases)
+}
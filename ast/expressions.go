@@ -14,11 +14,15 @@
 package ast
 
 import (
+	"encoding/hex"
 	"regexp"
+	"strconv"
 
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/util/format"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -77,6 +81,37 @@ func (n *ValueExpr) GetProjectionOffset() int {
 	return n.projectionOffset
 }
 
+// Restore implements Node interface.
+func (n *ValueExpr) Restore(ctx *format.RestoreCtx) error {
+	switch n.Kind() {
+	case types.KindNull:
+		ctx.WriteKeyWord("NULL")
+	case types.KindInt64:
+		ctx.WritePlain(strconv.FormatInt(n.GetInt64(), 10))
+	case types.KindUint64:
+		ctx.WritePlain(strconv.FormatUint(n.GetUint64(), 10))
+	case types.KindFloat32:
+		ctx.WritePlain(strconv.FormatFloat(n.GetFloat64(), 'e', -1, 32))
+	case types.KindFloat64:
+		ctx.WritePlain(strconv.FormatFloat(n.GetFloat64(), 'e', -1, 64))
+	case types.KindString, types.KindBytes:
+		ctx.WriteString(n.GetString())
+	case types.KindMysqlDecimal:
+		ctx.WritePlain(n.GetMysqlDecimal().String())
+	case types.KindBinaryLiteral, types.KindMysqlBit:
+		ctx.WritePlainf("X'%s'", hex.EncodeToString(n.GetBytes()))
+	case types.KindMysqlDuration, types.KindMysqlTime, types.KindMysqlEnum, types.KindMysqlSet, types.KindMysqlJSON:
+		str, err := n.ToString()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteString(str)
+	default:
+		return errors.Errorf("invalid datum kind %d during restoring ValueExpr", n.Kind())
+	}
+	return nil
+}
+
 // Accept implements Node interface.
 func (n *ValueExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -100,6 +135,23 @@ type BetweenExpr struct {
 	Not bool
 }
 
+// Restore implements Node interface.
+func (n *BetweenExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Not {
+		ctx.WriteKeyWord(" NOT BETWEEN ")
+	} else {
+		ctx.WriteKeyWord(" BETWEEN ")
+	}
+	if err := n.Left.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" AND ")
+	return errors.Trace(n.Right.Restore(ctx))
+}
+
 // Accept implements Node interface.
 func (n *BetweenExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -140,6 +192,19 @@ type BinaryOperationExpr struct {
 	R ExprNode
 }
 
+// Restore implements Node interface.
+func (n *BinaryOperationExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.L.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(" ")
+	if err := n.Op.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(" ")
+	return errors.Trace(n.R.Restore(ctx))
+}
+
 // Accept implements Node interface.
 func (n *BinaryOperationExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -172,6 +237,16 @@ type WhenClause struct {
 	Result ExprNode
 }
 
+// Restore implements Node interface.
+func (n *WhenClause) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("WHEN ")
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" THEN ")
+	return errors.Trace(n.Result.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *WhenClause) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -205,6 +280,31 @@ type CaseExpr struct {
 	ElseClause ExprNode
 }
 
+// Restore implements Node interface.
+func (n *CaseExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CASE")
+	if n.Value != nil {
+		ctx.WritePlain(" ")
+		if err := n.Value.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, clause := range n.WhenClauses {
+		ctx.WritePlain(" ")
+		if err := clause.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if n.ElseClause != nil {
+		ctx.WriteKeyWord(" ELSE ")
+		if err := n.ElseClause.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	ctx.WriteKeyWord(" END")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *CaseExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -263,6 +363,16 @@ type SubqueryExpr struct {
 	Exists       bool
 }
 
+// Restore implements Node interface.
+func (n *SubqueryExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WritePlain("(")
+	if err := n.Query.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *SubqueryExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -307,6 +417,23 @@ type CompareSubqueryExpr struct {
 	All bool
 }
 
+// Restore implements Node interface.
+func (n *CompareSubqueryExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.L.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(" ")
+	if err := n.Op.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.All {
+		ctx.WriteKeyWord(" ALL ")
+	} else {
+		ctx.WriteKeyWord(" ANY ")
+	}
+	return errors.Trace(n.R.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *CompareSubqueryExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -335,6 +462,20 @@ type ColumnName struct {
 	Name   model.CIStr
 }
 
+// Restore implements Node interface.
+func (n *ColumnName) Restore(ctx *format.RestoreCtx) error {
+	if n.Schema.O != "" {
+		ctx.WriteName(n.Schema.O)
+		ctx.WritePlain(".")
+	}
+	if n.Table.O != "" {
+		ctx.WriteName(n.Table.O)
+		ctx.WritePlain(".")
+	}
+	ctx.WriteName(n.Name.O)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ColumnName) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -357,6 +498,11 @@ type ColumnNameExpr struct {
 	Refer *ResultField
 }
 
+// Restore implements Node interface.
+func (n *ColumnNameExpr) Restore(ctx *format.RestoreCtx) error {
+	return errors.Trace(n.Name.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *ColumnNameExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -379,6 +525,19 @@ type DefaultExpr struct {
 	Name *ColumnName
 }
 
+// Restore implements Node interface.
+func (n *DefaultExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DEFAULT")
+	if n.Name != nil {
+		ctx.WritePlain("(")
+		if err := n.Name.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *DefaultExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -404,6 +563,12 @@ type ExistsSubqueryExpr struct {
 	Sel ExprNode
 }
 
+// Restore implements Node interface.
+func (n *ExistsSubqueryExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("EXISTS ")
+	return errors.Trace(n.Sel.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *ExistsSubqueryExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -432,6 +597,27 @@ type PatternInExpr struct {
 	Sel ExprNode
 }
 
+// Restore implements Node interface.
+func (n *PatternInExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Not {
+		ctx.WriteKeyWord(" NOT IN ")
+	} else {
+		ctx.WriteKeyWord(" IN ")
+	}
+	if n.Sel != nil {
+		return errors.Trace(n.Sel.Restore(ctx))
+	}
+	ctx.WritePlain("(")
+	if err := restoreExprs(ctx, n.List); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *PatternInExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -470,6 +656,19 @@ type IsNullExpr struct {
 	Not bool
 }
 
+// Restore implements Node interface.
+func (n *IsNullExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Not {
+		ctx.WriteKeyWord(" IS NOT NULL")
+	} else {
+		ctx.WriteKeyWord(" IS NULL")
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *IsNullExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -496,6 +695,23 @@ type IsTruthExpr struct {
 	True int64
 }
 
+// Restore implements Node interface.
+func (n *IsTruthExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" IS ")
+	if n.Not {
+		ctx.WriteKeyWord("NOT ")
+	}
+	if n.True > 0 {
+		ctx.WriteKeyWord("TRUE")
+	} else {
+		ctx.WriteKeyWord("FALSE")
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *IsTruthExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -527,6 +743,28 @@ type PatternLikeExpr struct {
 	PatTypes []byte
 }
 
+// Restore implements Node interface.
+func (n *PatternLikeExpr) Restore(ctx *format.RestoreCtx) error {
+	if n.Expr != nil {
+		if err := n.Expr.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(" ")
+	}
+	if n.Not {
+		ctx.WriteKeyWord("NOT ")
+	}
+	ctx.WriteKeyWord("LIKE ")
+	if err := n.Pattern.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Escape != '\\' {
+		ctx.WriteKeyWord(" ESCAPE ")
+		ctx.WriteString(string(n.Escape))
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *PatternLikeExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -558,6 +796,12 @@ type ParamMarkerExpr struct {
 	Offset int
 }
 
+// Restore implements Node interface.
+func (n *ParamMarkerExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WritePlain("?")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ParamMarkerExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -575,6 +819,16 @@ type ParenthesesExpr struct {
 	Expr ExprNode
 }
 
+// Restore implements Node interface.
+func (n *ParenthesesExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WritePlain("(")
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ParenthesesExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -603,6 +857,12 @@ type PositionExpr struct {
 	Refer *ResultField
 }
 
+// Restore implements Node interface.
+func (n *PositionExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WritePlainf("%d", n.N)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *PositionExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -629,6 +889,19 @@ type PatternRegexpExpr struct {
 	Sexpr *string
 }
 
+// Restore implements Node interface.
+func (n *PatternRegexpExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Expr.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	if n.Not {
+		ctx.WriteKeyWord(" NOT REGEXP ")
+	} else {
+		ctx.WriteKeyWord(" REGEXP ")
+	}
+	return errors.Trace(n.Pattern.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *PatternRegexpExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -657,6 +930,17 @@ type RowExpr struct {
 	Values []ExprNode
 }
 
+// Restore implements Node interface.
+func (n *RowExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ROW")
+	ctx.WritePlain("(")
+	if err := restoreExprs(ctx, n.Values); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *RowExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -683,6 +967,26 @@ type UnaryOperationExpr struct {
 	V ExprNode
 }
 
+// Restore implements Node interface.
+func (n *UnaryOperationExpr) Restore(ctx *format.RestoreCtx) error {
+	if err := n.Op.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	// NOT has lower precedence than the predicates while "!" has the highest one,
+	// so the operand of NOT like "NOT a = b" is parenthesized.
+	switch n.V.(type) {
+	case *BinaryOperationExpr, *BetweenExpr, *CompareSubqueryExpr, *PatternInExpr, *PatternLikeExpr,
+		*PatternRegexpExpr, *IsNullExpr, *IsTruthExpr:
+		ctx.WritePlain("(")
+		if err := n.V.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		return nil
+	}
+	return errors.Trace(n.V.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *UnaryOperationExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -705,6 +1009,17 @@ type ValuesExpr struct {
 	Column *ColumnNameExpr
 }
 
+// Restore implements Node interface.
+func (n *ValuesExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("VALUES")
+	ctx.WritePlain("(")
+	if err := n.Column.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ValuesExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -733,6 +1048,25 @@ type VariableExpr struct {
 	Value ExprNode
 }
 
+// Restore implements Node interface.
+func (n *VariableExpr) Restore(ctx *format.RestoreCtx) error {
+	if n.IsSystem {
+		ctx.WritePlain("@@")
+		if n.IsGlobal {
+			ctx.WriteKeyWord("GLOBAL")
+			ctx.WritePlain(".")
+		}
+	} else {
+		ctx.WritePlain("@")
+	}
+	ctx.WritePlain(n.Name)
+	if n.Value != nil {
+		ctx.WritePlain(" := ")
+		return errors.Trace(n.Value.Restore(ctx))
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *VariableExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
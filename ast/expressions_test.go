@@ -100,3 +100,23 @@ func (tc *testExpressionsSuite) TestExpresionsVisitorCover(c *C) {
 		v.node.Accept(visitor1{})
 	}
 }
+
+func (tc *testExpressionsSuite) TestExpresionsRestore(c *C) {
+	cases := []restoreCase{
+		{"select 1, -2, 1.5, 'a''b', null, x'1f', b'1'", "SELECT 1, -2, 1.5, 'a\\'b', NULL, X'1f', X'01'"},
+		{"select a between 1 and 2, a not between b and c", "SELECT `a` BETWEEN 1 AND 2, `a` NOT BETWEEN `b` AND `c`"},
+		{"select a+b*c, (a+b)*c, a div b, a % b, a xor b", "SELECT `a` + `b` * `c`, (`a` + `b`) * `c`, `a` DIV `b`, `a` % `b`, `a` XOR `b`"},
+		{"select case a when 1 then 'x' else 'y' end, case when a > 1 then b end", "SELECT CASE `a` WHEN 1 THEN 'x' ELSE 'y' END, CASE WHEN `a` > 1 THEN `b` END"},
+		{"select a from t where a > any (select b from t2) and exists (select 1)", "SELECT `a` FROM `t` WHERE `a` > ANY (SELECT `b` FROM `t2`) AND EXISTS (SELECT 1)"},
+		{"select t.a, db.t.b, default(c)", "SELECT `t`.`a`, `db`.`t`.`b`, DEFAULT(`c`)"},
+		{"select a in (1, 2), a not in (select b from t)", "SELECT `a` IN (1, 2), `a` NOT IN (SELECT `b` FROM `t`)"},
+		{"select a is null, a is not true, a is false", "SELECT `a` IS NULL, `a` IS NOT TRUE, `a` IS FALSE"},
+		{"select a like 'x%', a not like 'y' escape '|', a regexp 'z', a not rlike 'w'", "SELECT `a` LIKE 'x%', `a` NOT LIKE 'y' ESCAPE '|', `a` REGEXP 'z', `a` NOT REGEXP 'w'"},
+		{"select row(1, 2) = row(a, b), -a, ~a, !a, not a, - -1", "SELECT ROW(1, 2) = ROW(`a`, `b`), -`a`, ~`a`, !`a`, !`a`, --1"},
+		{"select not a = b, !a = b, not a between 1 and 2", "SELECT !(`a` = `b`), !`a` = `b`, !(`a` BETWEEN 1 AND 2)"},
+		{"select @a, @@global.autocommit, @b := 1, ?", "SELECT @a, @@GLOBAL.autocommit, @b := 1, ?"},
+		{"select a from t order by 1", "SELECT `a` FROM `t` ORDER BY 1"},
+		{"insert into t values (1) on duplicate key update a = values(a)", "INSERT INTO `t` VALUES (1) ON DUPLICATE KEY UPDATE `a` = VALUES(`a`)"},
+	}
+	runRestoreTest(c, cases)
+}
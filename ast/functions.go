@@ -14,7 +14,11 @@
 package ast
 
 import (
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/format"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -191,6 +195,10 @@ const (
 	Ord             = "ord"
 	Position        = "position"
 	Quote           = "quote"
+	RegexpInstr     = "regexp_instr"
+	RegexpLike      = "regexp_like"
+	RegexpReplace   = "regexp_replace"
+	RegexpSubstr    = "regexp_substr"
 	Repeat          = "repeat"
 	Replace         = "replace"
 	Reverse         = "reverse"
@@ -220,6 +228,7 @@ const (
 	Coercibility = "coercibility"
 	Collation    = "collation"
 	ConnectionID = "connection_id"
+	CurrentRole  = "current_role"
 	CurrentUser  = "current_user"
 	Database     = "database"
 	FoundRows    = "found_rows"
@@ -239,6 +248,7 @@ const (
 
 	// miscellaneous functions
 	AnyValue        = "any_value"
+	BinToUUID       = "bin_to_uuid"
 	DefaultFunc     = "default_func"
 	InetAton        = "inet_aton"
 	InetNtoa        = "inet_ntoa"
@@ -250,14 +260,15 @@ const (
 	IsIPv4Mapped    = "is_ipv4_mapped"
 	IsIPv6          = "is_ipv6"
 	IsUsedLock      = "is_used_lock"
+	IsUUID          = "is_uuid"
 	MasterPosWait   = "master_pos_wait"
 	NameConst       = "name_const"
 	ReleaseAllLocks = "release_all_locks"
 	Sleep           = "sleep"
 	UUID            = "uuid"
 	UUIDShort       = "uuid_short"
-	// get_lock() and release_lock() is parsed but do nothing.
-	// It is used for preventing error in Ruby's activerecord migrations.
+	UUIDToBin       = "uuid_to_bin"
+	// user-level lock functions
 	GetLock     = "get_lock"
 	ReleaseLock = "release_lock"
 
@@ -305,6 +316,140 @@ type FuncCallExpr struct {
 	Args []ExprNode
 }
 
+// Restore implements Node interface.
+func (n *FuncCallExpr) Restore(ctx *format.RestoreCtx) error {
+	switch n.FnName.L {
+	case DateAdd, DateSub, AddDate, SubDate:
+		ctx.WriteKeyWord(n.FnName.O)
+		ctx.WritePlain("(")
+		if err := n.Args[0].Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteKeyWord(", INTERVAL ")
+		if err := n.Args[1].Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(" ")
+		ctx.WriteKeyWord(n.Args[2].GetDatum().GetString())
+		ctx.WritePlain(")")
+		return nil
+	case Extract:
+		ctx.WriteKeyWord("EXTRACT")
+		ctx.WritePlain("(")
+		ctx.WriteKeyWord(n.Args[0].GetDatum().GetString())
+		ctx.WriteKeyWord(" FROM ")
+		if err := n.Args[1].Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		return nil
+	case TimestampAdd, TimestampDiff, GetFormat:
+		ctx.WriteKeyWord(n.FnName.O)
+		ctx.WritePlain("(")
+		ctx.WriteKeyWord(n.Args[0].GetDatum().GetString())
+		ctx.WritePlain(", ")
+		if err := restoreExprs(ctx, n.Args[1:]); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		return nil
+	case Position:
+		ctx.WriteKeyWord("POSITION")
+		ctx.WritePlain("(")
+		if err := n.Args[0].Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteKeyWord(" IN ")
+		if err := n.Args[1].Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		return nil
+	case Trim:
+		return errors.Trace(n.restoreTrim(ctx))
+	case Convert:
+		if len(n.Args) == 2 {
+			ctx.WriteKeyWord("CONVERT")
+			ctx.WritePlain("(")
+			if err := n.Args[0].Restore(ctx); err != nil {
+				return errors.Trace(err)
+			}
+			ctx.WriteKeyWord(" USING ")
+			ctx.WritePlain(n.Args[1].GetDatum().GetString())
+			ctx.WritePlain(")")
+			return nil
+		}
+	case CharFunc:
+		ctx.WriteKeyWord("CHAR")
+		ctx.WritePlain("(")
+		last := len(n.Args) - 1
+		if err := restoreExprs(ctx, n.Args[:last]); err != nil {
+			return errors.Trace(err)
+		}
+		if cs := n.Args[last].GetDatum(); !cs.IsNull() {
+			ctx.WriteKeyWord(" USING ")
+			ctx.WritePlain(cs.GetString())
+		}
+		ctx.WritePlain(")")
+		return nil
+	case InsertFunc:
+		ctx.WriteKeyWord("INSERT")
+		ctx.WritePlain("(")
+		if err := restoreExprs(ctx, n.Args); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		return nil
+	case PasswordFunc:
+		ctx.WriteKeyWord("PASSWORD")
+		ctx.WritePlain("(")
+		if err := restoreExprs(ctx, n.Args); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+		return nil
+	}
+	ctx.WriteKeyWord(n.FnName.O)
+	ctx.WritePlain("(")
+	if err := restoreExprs(ctx, n.Args); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// restoreTrim restores the forms of the TRIM function, the arguments are
+// the string, the remove string and the trim direction.
+func (n *FuncCallExpr) restoreTrim(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("TRIM")
+	ctx.WritePlain("(")
+	if len(n.Args) == 3 {
+		switch TrimDirectionType(n.Args[2].GetDatum().GetInt64()) {
+		case TrimBoth:
+			ctx.WriteKeyWord("BOTH ")
+		case TrimLeading:
+			ctx.WriteKeyWord("LEADING ")
+		case TrimTrailing:
+			ctx.WriteKeyWord("TRAILING ")
+		}
+	}
+	if len(n.Args) >= 2 {
+		// The remove string of "TRIM(LEADING FROM str)" is a NULL value.
+		if remStr := n.Args[1]; len(n.Args) == 2 || !remStr.GetDatum().IsNull() {
+			if err := remStr.Restore(ctx); err != nil {
+				return errors.Trace(err)
+			}
+			ctx.WritePlain(" ")
+		}
+		ctx.WriteKeyWord("FROM ")
+	}
+	if err := n.Args[0].Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node interface.
 func (n *FuncCallExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -344,6 +489,94 @@ type FuncCastExpr struct {
 	FunctionType CastFunctionType
 }
 
+// Restore implements Node interface.
+func (n *FuncCastExpr) Restore(ctx *format.RestoreCtx) error {
+	switch n.FunctionType {
+	case CastFunction:
+		ctx.WriteKeyWord("CAST")
+		ctx.WritePlain("(")
+		if err := n.Expr.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteKeyWord(" AS ")
+		if err := restoreCastType(ctx, n.Tp); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	case CastConvertFunction:
+		ctx.WriteKeyWord("CONVERT")
+		ctx.WritePlain("(")
+		if err := n.Expr.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(", ")
+		if err := restoreCastType(ctx, n.Tp); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	case CastBinaryOperator:
+		ctx.WriteKeyWord("BINARY ")
+		return errors.Trace(n.Expr.Restore(ctx))
+	default:
+		return errors.Errorf("invalid cast function type %d during restoring FuncCastExpr", n.FunctionType)
+	}
+	return nil
+}
+
+// restoreCastType restores the target type of CAST and CONVERT.
+func restoreCastType(ctx *format.RestoreCtx, tp *types.FieldType) error {
+	switch tp.Tp {
+	case mysql.TypeVarString:
+		if tp.Charset == charset.CharsetBin {
+			ctx.WriteKeyWord("BINARY")
+		} else {
+			ctx.WriteKeyWord("CHAR")
+		}
+		if tp.Flen != types.UnspecifiedLength {
+			ctx.WritePlainf("(%d)", tp.Flen)
+		}
+		if tp.Charset != charset.CharsetBin {
+			if mysql.HasBinaryFlag(tp.Flag) {
+				ctx.WriteKeyWord(" BINARY")
+			}
+			ctx.WriteKeyWord(" CHARSET ")
+			ctx.WritePlain(tp.Charset)
+		}
+	case mysql.TypeDate:
+		ctx.WriteKeyWord("DATE")
+	case mysql.TypeDatetime:
+		ctx.WriteKeyWord("DATETIME")
+		if tp.Decimal > 0 {
+			ctx.WritePlainf("(%d)", tp.Decimal)
+		}
+	case mysql.TypeNewDecimal:
+		ctx.WriteKeyWord("DECIMAL")
+		if tp.Flen != types.UnspecifiedLength {
+			ctx.WritePlainf("(%d", tp.Flen)
+			if tp.Decimal != types.UnspecifiedLength {
+				ctx.WritePlainf(", %d", tp.Decimal)
+			}
+			ctx.WritePlain(")")
+		}
+	case mysql.TypeDuration:
+		ctx.WriteKeyWord("TIME")
+		if tp.Decimal > 0 {
+			ctx.WritePlainf("(%d)", tp.Decimal)
+		}
+	case mysql.TypeLonglong:
+		if mysql.HasUnsignedFlag(tp.Flag) {
+			ctx.WriteKeyWord("UNSIGNED")
+		} else {
+			ctx.WriteKeyWord("SIGNED")
+		}
+	case mysql.TypeJSON:
+		ctx.WriteKeyWord("JSON")
+	default:
+		return errors.Errorf("invalid cast type %d during restoring", tp.Tp)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *FuncCastExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -417,6 +650,20 @@ type AggregateFuncExpr struct {
 	Distinct bool
 }
 
+// Restore implements Node interface.
+func (n *AggregateFuncExpr) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord(n.F)
+	ctx.WritePlain("(")
+	if n.Distinct {
+		ctx.WriteKeyWord("DISTINCT ")
+	}
+	if err := restoreExprs(ctx, n.Args); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *AggregateFuncExpr) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
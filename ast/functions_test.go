@@ -34,3 +34,17 @@ func (ts *testFunctionsSuite) TestFunctionsVisitorCover(c *C) {
 		stmt.Accept(visitor1{})
 	}
 }
+
+func (ts *testFunctionsSuite) TestFunctionsRestore(c *C) {
+	cases := []restoreCase{
+		{"select now(), ifnull(a, 1), concat('a', b)", "SELECT NOW(), IFNULL(`a`, 1), CONCAT('a', `b`)"},
+		{"select date_add(a, interval 1 day), subdate(a, 2), adddate(a, interval '1:1' minute_second)", "SELECT DATE_ADD(`a`, INTERVAL 1 DAY), SUBDATE(`a`, INTERVAL 2 DAY), ADDDATE(`a`, INTERVAL '1:1' MINUTE_SECOND)"},
+		{"select extract(year from a), timestampdiff(month, a, b), get_format(date, 'usa')", "SELECT EXTRACT(YEAR FROM `a`), TIMESTAMPDIFF(MONTH, `a`, `b`), GET_FORMAT(DATE, 'usa')"},
+		{"select position('a' in b), trim(a), trim(leading 'x' from a), trim(both from a)", "SELECT POSITION('a' IN `b`), TRIM(`a`), TRIM(LEADING 'x' FROM `a`), TRIM(BOTH FROM `a`)"},
+		{"select convert(a using utf8), char(65, 66 using latin1), insert('abc', 1, 1, 'x'), password('p')", "SELECT CONVERT(`a` USING utf8), CHAR(65, 66 USING latin1), INSERT('abc', 1, 1, 'x'), PASSWORD('p')"},
+		{"select cast(a as char(10)), cast(a as binary), convert(a, decimal(10, 2)), binary a", "SELECT CAST(`a` AS CHAR(10) CHARSET utf8), CAST(`a` AS BINARY), CONVERT(`a`, DECIMAL(10, 2)), BINARY `a`"},
+		{"select cast(a as datetime(3)), cast(a as unsigned), cast(a as signed integer), cast(a as json)", "SELECT CAST(`a` AS DATETIME(3)), CAST(`a` AS UNSIGNED), CAST(`a` AS SIGNED), CAST(`a` AS JSON)"},
+		{"select count(*), count(distinct a, b), sum(a), group_concat(a)", "SELECT COUNT(1), COUNT(DISTINCT `a`, `b`), SUM(`a`), GROUP_CONCAT(`a`)"},
+	}
+	runRestoreTest(c, cases)
+}
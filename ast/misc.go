@@ -16,10 +16,12 @@ package ast
 import (
 	"fmt"
 
+	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/format"
 )
 
 var (
@@ -34,9 +36,11 @@ var (
 	_ StmtNode = &ExecuteStmt{}
 	_ StmtNode = &ExplainStmt{}
 	_ StmtNode = &GrantStmt{}
+	_ StmtNode = &PlanReplayerStmt{}
 	_ StmtNode = &PrepareStmt{}
 	_ StmtNode = &RollbackStmt{}
 	_ StmtNode = &SetPwdStmt{}
+	_ StmtNode = &SetSessionStatesStmt{}
 	_ StmtNode = &SetStmt{}
 	_ StmtNode = &UseStmt{}
 	_ StmtNode = &FlushStmt{}
@@ -85,6 +89,23 @@ type ExplainStmt struct {
 	Stmt StmtNode
 }
 
+// Restore implements Node interface.
+func (n *ExplainStmt) Restore(ctx *format.RestoreCtx) error {
+	if show, ok := n.Stmt.(*ShowStmt); ok && show.Tp == ShowColumns && show.Table != nil {
+		ctx.WriteKeyWord("DESC ")
+		if err := show.Table.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		if show.Column != nil {
+			ctx.WritePlain(" ")
+			return errors.Trace(show.Column.Restore(ctx))
+		}
+		return nil
+	}
+	ctx.WriteKeyWord("EXPLAIN ")
+	return errors.Trace(n.Stmt.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *ExplainStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -100,6 +121,46 @@ func (n *ExplainStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// PlanReplayerStmt is a statement to dump the schema, statistics and session variables that the plan of a statement
+// depends on into a zip file, or to load a dumped file on another server to reproduce the plan.
+type PlanReplayerStmt struct {
+	stmtNode
+
+	// Stmt is the statement to dump, it's nil if Load is true.
+	Stmt StmtNode
+	Load bool
+	// File is the path of the file to load.
+	File string
+}
+
+// Restore implements Node interface.
+func (n *PlanReplayerStmt) Restore(ctx *format.RestoreCtx) error {
+	if n.Load {
+		ctx.WriteKeyWord("PLAN REPLAYER LOAD ")
+		ctx.WriteString(n.File)
+		return nil
+	}
+	ctx.WriteKeyWord("PLAN REPLAYER DUMP ")
+	return errors.Trace(n.Stmt.Restore(ctx))
+}
+
+// Accept implements Node Accept interface.
+func (n *PlanReplayerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*PlanReplayerStmt)
+	if n.Stmt != nil {
+		node, ok := n.Stmt.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Stmt = node.(DMLNode)
+	}
+	return v.Leave(n)
+}
+
 // PrepareStmt is a statement to prepares a SQL statement which contains placeholders,
 // and it is executed with ExecuteStmt and released with DeallocateStmt.
 // See https://dev.mysql.com/doc/refman/5.7/en/prepare.html
@@ -111,6 +172,18 @@ type PrepareStmt struct {
 	SQLVar  *VariableExpr
 }
 
+// Restore implements Node interface.
+func (n *PrepareStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("PREPARE ")
+	ctx.WriteName(n.Name)
+	ctx.WriteKeyWord(" FROM ")
+	if n.SQLVar != nil {
+		return errors.Trace(n.SQLVar.Restore(ctx))
+	}
+	ctx.WriteString(n.SQLText)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *PrepareStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -136,6 +209,13 @@ type DeallocateStmt struct {
 	Name string
 }
 
+// Restore implements Node interface.
+func (n *DeallocateStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DEALLOCATE PREPARE ")
+	ctx.WriteName(n.Name)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *DeallocateStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -155,6 +235,17 @@ type ExecuteStmt struct {
 	UsingVars []ExprNode
 }
 
+// Restore implements Node interface.
+func (n *ExecuteStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("EXECUTE ")
+	ctx.WriteName(n.Name)
+	if len(n.UsingVars) > 0 {
+		ctx.WriteKeyWord(" USING ")
+		return errors.Trace(restoreExprs(ctx, n.UsingVars))
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *ExecuteStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -178,6 +269,12 @@ type BeginStmt struct {
 	stmtNode
 }
 
+// Restore implements Node interface.
+func (n *BeginStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("BEGIN")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *BeginStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -196,6 +293,13 @@ type BinlogStmt struct {
 	Str string
 }
 
+// Restore implements Node interface.
+func (n *BinlogStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("BINLOG ")
+	ctx.WriteString(n.Str)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *BinlogStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -212,6 +316,12 @@ type CommitStmt struct {
 	stmtNode
 }
 
+// Restore implements Node interface.
+func (n *CommitStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("COMMIT")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *CommitStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -228,6 +338,12 @@ type RollbackStmt struct {
 	stmtNode
 }
 
+// Restore implements Node interface.
+func (n *RollbackStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ROLLBACK")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *RollbackStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -246,6 +362,13 @@ type UseStmt struct {
 	DBName string
 }
 
+// Restore implements Node interface.
+func (n *UseStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("USE ")
+	ctx.WriteName(n.DBName)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *UseStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -277,6 +400,31 @@ type VariableAssignment struct {
 	ExtendValue *ValueExpr
 }
 
+// Restore implements Node interface.
+func (n *VariableAssignment) Restore(ctx *format.RestoreCtx) error {
+	if n.Name == SetNames {
+		ctx.WriteKeyWord("NAMES ")
+		ctx.WritePlain(n.Value.GetDatum().GetString())
+		if n.ExtendValue != nil {
+			ctx.WriteKeyWord(" COLLATE ")
+			ctx.WritePlain(n.ExtendValue.GetString())
+		}
+		return nil
+	}
+	if n.IsSystem {
+		ctx.WritePlain("@@")
+		if n.IsGlobal {
+			ctx.WriteKeyWord("GLOBAL")
+			ctx.WritePlain(".")
+		}
+	} else {
+		ctx.WritePlain("@")
+	}
+	ctx.WritePlain(n.Name)
+	ctx.WritePlain(" = ")
+	return errors.Trace(n.Value.Restore(ctx))
+}
+
 // Accept implements Node interface.
 func (n *VariableAssignment) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -312,6 +460,32 @@ type FlushStmt struct {
 	ReadLock        bool
 }
 
+// Restore implements Node interface.
+func (n *FlushStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("FLUSH ")
+	if n.NoWriteToBinLog {
+		ctx.WriteKeyWord("NO_WRITE_TO_BINLOG ")
+	}
+	switch n.Tp {
+	case FlushPrivileges:
+		ctx.WriteKeyWord("PRIVILEGES")
+	case FlushTables:
+		ctx.WriteKeyWord("TABLES")
+		if len(n.Tables) > 0 {
+			ctx.WritePlain(" ")
+			if err := restoreTableNames(ctx, n.Tables); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if n.ReadLock {
+			ctx.WriteKeyWord(" WITH READ LOCK")
+		}
+	default:
+		return errors.Errorf("invalid flush type %d during restoring FlushStmt", n.Tp)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *FlushStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -342,6 +516,21 @@ type KillStmt struct {
 	TiDBExtension bool
 }
 
+// Restore implements Node interface.
+func (n *KillStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("KILL ")
+	if n.TiDBExtension {
+		ctx.WriteKeyWord("TIDB ")
+	}
+	if n.Query {
+		ctx.WriteKeyWord("QUERY ")
+	} else {
+		ctx.WriteKeyWord("CONNECTION ")
+	}
+	ctx.WritePlainf("%d", n.ConnectionID)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *KillStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -359,6 +548,20 @@ type SetStmt struct {
 	Variables []*VariableAssignment
 }
 
+// Restore implements Node interface.
+func (n *SetStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SET ")
+	for i, variable := range n.Variables {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := variable.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *SetStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -406,6 +609,50 @@ type SetPwdStmt struct {
 	Password string
 }
 
+// Restore implements Node interface.
+func (n *SetPwdStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SET PASSWORD")
+	if n.User != nil {
+		ctx.WriteKeyWord(" FOR ")
+		restoreUserIdentity(ctx, n.User)
+	}
+	ctx.WritePlain(" = ")
+	ctx.WriteString(n.Password)
+	return nil
+}
+
+// restoreUserIdentity restores the user identity in the form of 'user'@'host'.
+func restoreUserIdentity(ctx *format.RestoreCtx, user *auth.UserIdentity) {
+	ctx.WriteString(user.Username)
+	ctx.WritePlain("@")
+	ctx.WriteString(user.Hostname)
+}
+
+// restore restores the user and the authentication option of the user spec.
+func (u *UserSpec) restore(ctx *format.RestoreCtx) {
+	restoreUserIdentity(ctx, u.User)
+	if u.AuthOpt == nil {
+		return
+	}
+	if u.AuthOpt.ByAuthString {
+		ctx.WriteKeyWord(" IDENTIFIED BY ")
+		ctx.WriteString(u.AuthOpt.AuthString)
+	} else if u.AuthOpt.HashString != "" {
+		ctx.WriteKeyWord(" IDENTIFIED BY PASSWORD ")
+		ctx.WriteString(u.AuthOpt.HashString)
+	}
+}
+
+// restoreUserSpecs restores the user specs separated by commas.
+func restoreUserSpecs(ctx *format.RestoreCtx, specs []*UserSpec) {
+	for i, spec := range specs {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		spec.restore(ctx)
+	}
+}
+
 // Accept implements Node Accept interface.
 func (n *SetPwdStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -416,6 +663,31 @@ func (n *SetPwdStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// SetSessionStatesStmt is a statement to restore the session states dumped by SHOW SESSION_STATES,
+// it's used to migrate a session to another server.
+type SetSessionStatesStmt struct {
+	stmtNode
+
+	SessionStates string
+}
+
+// Restore implements Node interface.
+func (n *SetSessionStatesStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("SET SESSION_STATES ")
+	ctx.WriteString(n.SessionStates)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *SetSessionStatesStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*SetSessionStatesStmt)
+	return v.Leave(n)
+}
+
 // UserSpec is used for parsing create user statement.
 type UserSpec struct {
 	User    *auth.UserIdentity
@@ -445,6 +717,16 @@ type CreateUserStmt struct {
 	Specs       []*UserSpec
 }
 
+// Restore implements Node interface.
+func (n *CreateUserStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE USER ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	restoreUserSpecs(ctx, n.Specs)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *CreateUserStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -465,6 +747,23 @@ type AlterUserStmt struct {
 	Specs       []*UserSpec
 }
 
+// Restore implements Node interface.
+func (n *AlterUserStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ALTER USER ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	if n.CurrentAuth != nil {
+		ctx.WriteKeyWord("USER")
+		ctx.WritePlain("()")
+		ctx.WriteKeyWord(" IDENTIFIED BY ")
+		ctx.WriteString(n.CurrentAuth.AuthString)
+		return nil
+	}
+	restoreUserSpecs(ctx, n.Specs)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *AlterUserStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -484,6 +783,21 @@ type DropUserStmt struct {
 	UserList []*auth.UserIdentity
 }
 
+// Restore implements Node interface.
+func (n *DropUserStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP USER ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	for i, user := range n.UserList {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		restoreUserIdentity(ctx, user)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *DropUserStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -501,6 +815,12 @@ type DoStmt struct {
 	Exprs []ExprNode
 }
 
+// Restore implements Node interface.
+func (n *DoStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DO ")
+	return errors.Trace(restoreExprs(ctx, n.Exprs))
+}
+
 // Accept implements Node Accept interface.
 func (n *DoStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -526,14 +846,113 @@ const (
 	AdminShowDDL = iota + 1
 	AdminCheckTable
 	AdminShowDDLJobs
+	AdminReloadConfig
+	AdminCancelDDLJobs
+	AdminPauseDDLJobs
+	AdminResumeDDLJobs
+	AdminReloadRewriteRules
+	AdminSplitTable
+	AdminScatterTable
+	AdminPauseWrites
+	AdminResumeWrites
+	AdminPinSnapshot
+	AdminUnpinSnapshot
 )
 
+// SplitOption is the option of 'admin split table', the handles in [Lower, Upper) are split into Num regions evenly.
+type SplitOption struct {
+	Lower int64
+	Upper int64
+	Num   int64
+}
+
+// SnapshotPin is the option of 'admin pin snapshot' and 'admin unpin snapshot'. GC keeps the versions read by the
+// snapshot at TS for TTL seconds, TS is 0 to pin a snapshot at the current timestamp.
+type SnapshotPin struct {
+	TS  uint64
+	TTL uint64
+}
+
 // AdminStmt is the struct for Admin statement.
 type AdminStmt struct {
 	stmtNode
 
 	Tp     AdminStmtType
 	Tables []*TableName
+	JobIDs []int64
+	Split  *SplitOption
+	Pin    *SnapshotPin
+}
+
+// Restore implements Node interface.
+func (n *AdminStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ADMIN ")
+	switch n.Tp {
+	case AdminShowDDL:
+		ctx.WriteKeyWord("SHOW DDL")
+	case AdminShowDDLJobs:
+		ctx.WriteKeyWord("SHOW DDL JOBS")
+	case AdminCheckTable:
+		ctx.WriteKeyWord("CHECK TABLE ")
+		return errors.Trace(restoreTableNames(ctx, n.Tables))
+	case AdminReloadConfig:
+		ctx.WriteKeyWord("RELOAD CONFIG")
+	case AdminReloadRewriteRules:
+		ctx.WriteKeyWord("RELOAD REWRITE RULES")
+	case AdminSplitTable:
+		ctx.WriteKeyWord("SPLIT TABLE ")
+		if err := restoreTableNames(ctx, n.Tables); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WriteKeyWord(" BETWEEN ")
+		ctx.WritePlainf("%d", n.Split.Lower)
+		ctx.WriteKeyWord(" AND ")
+		ctx.WritePlainf("%d", n.Split.Upper)
+		ctx.WriteKeyWord(" REGIONS ")
+		ctx.WritePlainf("%d", n.Split.Num)
+	case AdminScatterTable:
+		ctx.WriteKeyWord("SCATTER TABLE ")
+		return errors.Trace(restoreTableNames(ctx, n.Tables))
+	case AdminPauseWrites, AdminResumeWrites:
+		if n.Tp == AdminPauseWrites {
+			ctx.WriteKeyWord("PAUSE WRITES")
+		} else {
+			ctx.WriteKeyWord("RESUME WRITES")
+		}
+		if len(n.Tables) > 0 {
+			ctx.WriteKeyWord(" ON TABLE ")
+			return errors.Trace(restoreTableNames(ctx, n.Tables))
+		}
+	case AdminPinSnapshot:
+		ctx.WriteKeyWord("PIN SNAPSHOT ")
+		if n.Pin.TS != 0 {
+			ctx.WritePlainf("%d ", n.Pin.TS)
+		}
+		ctx.WriteKeyWord("TTL ")
+		ctx.WritePlainf("%d", n.Pin.TTL)
+	case AdminUnpinSnapshot:
+		ctx.WriteKeyWord("UNPIN SNAPSHOT ")
+		ctx.WritePlainf("%d", n.Pin.TS)
+	case AdminCancelDDLJobs, AdminPauseDDLJobs, AdminResumeDDLJobs:
+		switch n.Tp {
+		case AdminCancelDDLJobs:
+			ctx.WriteKeyWord("CANCEL")
+		case AdminPauseDDLJobs:
+			ctx.WriteKeyWord("PAUSE")
+		default:
+			ctx.WriteKeyWord("RESUME")
+		}
+		ctx.WriteKeyWord(" DDL JOBS ")
+		for i, id := range n.JobIDs {
+			if i > 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WritePlainf("%d", id)
+		}
+	default:
+		return errors.Errorf("invalid admin type %d during restoring AdminStmt", n.Tp)
+	}
+	return nil
 }
 
 // Accept implements Node Accpet interface.
@@ -563,6 +982,63 @@ type PrivElem struct {
 	Cols []*ColumnName
 }
 
+// Restore implements Node interface.
+func (n *PrivElem) Restore(ctx *format.RestoreCtx) error {
+	if n.Priv == mysql.AllPriv {
+		ctx.WriteKeyWord("ALL")
+	} else {
+		str, ok := mysql.Priv2Str[n.Priv]
+		if !ok {
+			return errors.Errorf("invalid privilege type %d during restoring PrivElem", n.Priv)
+		}
+		ctx.WriteKeyWord(str)
+	}
+	if len(n.Cols) > 0 {
+		ctx.WritePlain(" (")
+		if err := restoreColumnNames(ctx, n.Cols); err != nil {
+			return errors.Trace(err)
+		}
+		ctx.WritePlain(")")
+	}
+	return nil
+}
+
+// restorePrivsAndLevel restores the privileges and the privilege level of GRANT and REVOKE.
+func restorePrivsAndLevel(ctx *format.RestoreCtx, privs []*PrivElem, objectType ObjectTypeType, level *GrantLevel) error {
+	for i, priv := range privs {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := priv.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	ctx.WriteKeyWord(" ON ")
+	if objectType == ObjectTypeTable {
+		ctx.WriteKeyWord("TABLE ")
+	}
+	switch level.Level {
+	case GrantLevelGlobal:
+		ctx.WritePlain("*.*")
+	case GrantLevelDB:
+		if level.DBName == "" {
+			ctx.WritePlain("*")
+		} else {
+			ctx.WriteName(level.DBName)
+			ctx.WritePlain(".*")
+		}
+	case GrantLevelTable:
+		if level.DBName != "" {
+			ctx.WriteName(level.DBName)
+			ctx.WritePlain(".")
+		}
+		ctx.WriteName(level.TableName)
+	default:
+		return errors.Errorf("invalid grant level %d during restoring", level.Level)
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *PrivElem) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -621,6 +1097,17 @@ type RevokeStmt struct {
 	Users      []*UserSpec
 }
 
+// Restore implements Node interface.
+func (n *RevokeStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("REVOKE ")
+	if err := restorePrivsAndLevel(ctx, n.Privs, n.ObjectType, n.Level); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" FROM ")
+	restoreUserSpecs(ctx, n.Users)
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *RevokeStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -649,6 +1136,20 @@ type GrantStmt struct {
 	WithGrant  bool
 }
 
+// Restore implements Node interface.
+func (n *GrantStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("GRANT ")
+	if err := restorePrivsAndLevel(ctx, n.Privs, n.ObjectType, n.Level); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WriteKeyWord(" TO ")
+	restoreUserSpecs(ctx, n.Users)
+	if n.WithGrant {
+		ctx.WriteKeyWord(" WITH GRANT OPTION")
+	}
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *GrantStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -710,6 +1211,20 @@ type TableOptimizerHint struct {
 	Tables   []model.CIStr
 }
 
+// Restore implements Node interface.
+func (n *TableOptimizerHint) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord(n.HintName.O)
+	ctx.WritePlain("(")
+	for i, table := range n.Tables {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		ctx.WriteName(table.O)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
 // Accept implements Node Accept interface.
 func (n *TableOptimizerHint) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
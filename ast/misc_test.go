@@ -55,9 +55,12 @@ func (ts *testMiscSuite) TestMiscVisitorCover(c *C) {
 		(&ExecuteStmt{UsingVars: []ExprNode{&ValueExpr{}}}),
 		(&ExplainStmt{Stmt: &ShowStmt{}}),
 		(&GrantStmt{}),
+		(&PlanReplayerStmt{Stmt: &SelectStmt{}}),
+		(&PlanReplayerStmt{Load: true}),
 		(&PrepareStmt{SQLVar: &VariableExpr{Value: &ValueExpr{}}}),
 		(&RollbackStmt{}),
 		(&SetPwdStmt{}),
+		(&SetSessionStatesStmt{}),
 		(&SetStmt{Variables: []*VariableAssignment{
 			{
 				Value: &ValueExpr{},
@@ -74,6 +77,11 @@ func (ts *testMiscSuite) TestMiscVisitorCover(c *C) {
 		(&VariableAssignment{Value: &ValueExpr{}}),
 		(&KillStmt{}),
 		(&DropStatsStmt{Table: &TableName{}}),
+		(&CreateStatisticsStmt{Table: &TableName{}, Columns: []*ColumnName{{}}}),
+		(&DropStatisticsStmt{Table: &TableName{}}),
+		(&LoadStatsStmt{}),
+		(&LockStatsStmt{Tables: []*TableName{{}}}),
+		(&UnlockStatsStmt{Tables: []*TableName{{}}}),
 	}
 
 	for _, v := range stmts {
@@ -124,3 +132,53 @@ load data infile '/tmp/t.csv' into table t fields terminated by 'ab' enclosed by
 		stmt.Accept(visitor1{})
 	}
 }
+
+func (ts *testMiscSuite) TestMiscRestore(c *C) {
+	cases := []restoreCase{
+		{"explain select * from t", "EXPLAIN SELECT * FROM `t`"},
+		{"desc t", "DESC `t`"},
+		{"prepare stmt from 'select ?'", "PREPARE `stmt` FROM 'select ?'"},
+		{"execute stmt using @a, @b", "EXECUTE `stmt` USING @a, @b"},
+		{"deallocate prepare stmt", "DEALLOCATE PREPARE `stmt`"},
+		{"begin", "BEGIN"},
+		{"commit", "COMMIT"},
+		{"rollback", "ROLLBACK"},
+		{"use db", "USE `db`"},
+		{"set @a = 1, @@global.autocommit = 0, session sql_mode = '', names utf8 collate utf8_bin", "SET @a = 1, @@GLOBAL.autocommit = 0, @@sql_mode = '', NAMES utf8 COLLATE utf8_bin"},
+		{"set password for 'u'@'%' = 'p'", "SET PASSWORD FOR 'u'@'%' = 'p'"},
+		{"create user if not exists 'u'@'localhost' identified by 'p'", "CREATE USER IF NOT EXISTS 'u'@'localhost' IDENTIFIED BY 'p'"},
+		{"alter user 'u'@'%' identified by 'p'", "ALTER USER 'u'@'%' IDENTIFIED BY 'p'"},
+		{"drop user if exists 'u'@'%', 'v'", "DROP USER IF EXISTS 'u'@'%', 'v'@'%'"},
+		{"grant select, insert (a, b) on db.* to 'u'@'%' with grant option", "GRANT SELECT, INSERT (`a`, `b`) ON `db`.* TO 'u'@'%' WITH GRANT OPTION"},
+		{"revoke all on *.* from 'u'@'%'", "REVOKE ALL ON *.* FROM 'u'@'%'"},
+		{"kill 1", "KILL CONNECTION 1"},
+		{"kill query 1", "KILL QUERY 1"},
+		{"flush tables", "FLUSH TABLES"},
+		{"do 1, sleep(1)", "DO 1, SLEEP(1)"},
+		{"admin show ddl", "ADMIN SHOW DDL"},
+		{"admin reload rewrite rules", "ADMIN RELOAD REWRITE RULES"},
+		{"admin check table t1, t2", "ADMIN CHECK TABLE `t1`, `t2`"},
+		{"admin split table t between -10 and 1000 regions 10", "ADMIN SPLIT TABLE `t` BETWEEN -10 AND 1000 REGIONS 10"},
+		{"admin scatter table test.t", "ADMIN SCATTER TABLE `test`.`t`"},
+		{"admin pause writes", "ADMIN PAUSE WRITES"},
+		{"admin resume writes on table test.t", "ADMIN RESUME WRITES ON TABLE `test`.`t`"},
+		{"admin pin snapshot ttl 3600", "ADMIN PIN SNAPSHOT TTL 3600"},
+		{"admin pin snapshot 400036290571534337 ttl 600", "ADMIN PIN SNAPSHOT 400036290571534337 TTL 600"},
+		{"admin unpin snapshot 400036290571534337", "ADMIN UNPIN SNAPSHOT 400036290571534337"},
+		{"analyze table t1, t2", "ANALYZE TABLE `t1`, `t2`"},
+		{"analyze table t1, t2 with 1000 samples", "ANALYZE TABLE `t1`, `t2` WITH 1000 SAMPLES"},
+		{"drop stats t", "DROP STATS `t`"},
+		{"create statistics if not exists s1 on test.t (a, b)", "CREATE STATISTICS IF NOT EXISTS `s1` ON `test`.`t` (`a`, `b`)"},
+		{"drop statistics s1 on t", "DROP STATISTICS `s1` ON `t`"},
+		{`load stats '{"table_name": "t"}'`, `LOAD STATS '{"table_name": "t"}'`},
+		{"lock stats t1, test.t2", "LOCK STATS `t1`, `test`.`t2`"},
+		{"unlock stats t1", "UNLOCK STATS `t1`"},
+		{"show stats_json from test.t", "SHOW STATS_JSON FROM `test`.`t`"},
+		{"show progress for 1", "SHOW PROGRESS FOR 1"},
+		{"show session_states", "SHOW SESSION_STATES"},
+		{`set session_states '{"current_db":"test"}'`, `SET SESSION_STATES '{"current_db":"test"}'`},
+		{"plan replayer dump select * from t where a > 1", "PLAN REPLAYER DUMP SELECT * FROM `t` WHERE `a` > 1"},
+		{"plan replayer load '/tmp/replayer.zip'", "PLAN REPLAYER LOAD '/tmp/replayer.zip'"},
+	}
+	runRestoreTest(c, cases)
+}
@@ -13,11 +13,20 @@
 
 package ast
 
-import "github.com/pingcap/tidb/model"
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/format"
+)
 
 var (
 	_ StmtNode = &AnalyzeTableStmt{}
 	_ StmtNode = &DropStatsStmt{}
+	_ StmtNode = &CreateStatisticsStmt{}
+	_ StmtNode = &DropStatisticsStmt{}
+	_ StmtNode = &LoadStatsStmt{}
+	_ StmtNode = &LockStatsStmt{}
+	_ StmtNode = &UnlockStatsStmt{}
 )
 
 // AnalyzeTableStmt is used to create table statistics.
@@ -26,6 +35,31 @@ type AnalyzeTableStmt struct {
 
 	TableNames []*TableName
 	IndexNames []model.CIStr
+	// NumSamples is the number of the rows sampled by the fast analyze, 0 means all the rows are analyzed.
+	NumSamples uint64
+}
+
+// Restore implements Node interface.
+func (n *AnalyzeTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("ANALYZE TABLE ")
+	if err := restoreTableNames(ctx, n.TableNames); err != nil {
+		return errors.Trace(err)
+	}
+	if len(n.IndexNames) > 0 {
+		ctx.WriteKeyWord(" INDEX ")
+		for i, name := range n.IndexNames {
+			if i > 0 {
+				ctx.WritePlain(", ")
+			}
+			ctx.WriteName(name.O)
+		}
+	}
+	if n.NumSamples > 0 {
+		ctx.WriteKeyWord(" WITH ")
+		ctx.WritePlainf("%d", n.NumSamples)
+		ctx.WriteKeyWord(" SAMPLES")
+	}
+	return nil
 }
 
 // Accept implements Node Accept interface.
@@ -52,6 +86,12 @@ type DropStatsStmt struct {
 	Table *TableName
 }
 
+// Restore implements Node interface.
+func (n *DropStatsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP STATS ")
+	return errors.Trace(n.Table.Restore(ctx))
+}
+
 // Accept implements Node Accept interface.
 func (n *DropStatsStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
@@ -66,3 +106,179 @@ func (n *DropStatsStmt) Accept(v Visitor) (Node, bool) {
 	n.Table = node.(*TableName)
 	return v.Leave(n)
 }
+
+// CreateStatisticsStmt is used to create the statistics of a group of columns, which is built by analyze and captures
+// the correlation between the columns.
+type CreateStatisticsStmt struct {
+	stmtNode
+
+	IfNotExists bool
+	StatsName   string
+	Table       *TableName
+	Columns     []*ColumnName
+}
+
+// Restore implements Node interface.
+func (n *CreateStatisticsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE STATISTICS ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.StatsName)
+	ctx.WriteKeyWord(" ON ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	ctx.WritePlain(" (")
+	for i, col := range n.Columns {
+		if i > 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := col.Restore(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateStatisticsStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateStatisticsStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	for i, val := range n.Columns {
+		node, ok = val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Columns[i] = node.(*ColumnName)
+	}
+	return v.Leave(n)
+}
+
+// DropStatisticsStmt is used to drop the statistics created by CreateStatisticsStmt.
+type DropStatisticsStmt struct {
+	stmtNode
+
+	IfExists  bool
+	StatsName string
+	Table     *TableName
+}
+
+// Restore implements Node interface.
+func (n *DropStatisticsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP STATISTICS ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.StatsName)
+	ctx.WriteKeyWord(" ON ")
+	return errors.Trace(n.Table.Restore(ctx))
+}
+
+// Accept implements Node Accept interface.
+func (n *DropStatisticsStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropStatisticsStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	return v.Leave(n)
+}
+
+// LoadStatsStmt is used to load the table statistics dumped by SHOW STATS_JSON.
+type LoadStatsStmt struct {
+	stmtNode
+
+	JSON string
+}
+
+// Restore implements Node interface.
+func (n *LoadStatsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("LOAD STATS ")
+	ctx.WriteString(n.JSON)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *LoadStatsStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*LoadStatsStmt)
+	return v.Leave(n)
+}
+
+// LockStatsStmt is used to lock the table statistics, so they're not changed by analyze.
+type LockStatsStmt struct {
+	stmtNode
+
+	Tables []*TableName
+}
+
+// Restore implements Node interface.
+func (n *LockStatsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("LOCK STATS ")
+	return errors.Trace(restoreTableNames(ctx, n.Tables))
+}
+
+// Accept implements Node Accept interface.
+func (n *LockStatsStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*LockStatsStmt)
+	for i, val := range n.Tables {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Tables[i] = node.(*TableName)
+	}
+	return v.Leave(n)
+}
+
+// UnlockStatsStmt is used to unlock the table statistics locked by LockStatsStmt.
+type UnlockStatsStmt struct {
+	stmtNode
+
+	Tables []*TableName
+}
+
+// Restore implements Node interface.
+func (n *UnlockStatsStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("UNLOCK STATS ")
+	return errors.Trace(restoreTableNames(ctx, n.Tables))
+}
+
+// Accept implements Node Accept interface.
+func (n *UnlockStatsStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*UnlockStatsStmt)
+	for i, val := range n.Tables {
+		node, ok := val.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.Tables[i] = node.(*TableName)
+	}
+	return v.Leave(n)
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast_test
+
+import (
+	"bytes"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/format"
+)
+
+// restoreCase is a statement and its restored SQL text.
+type restoreCase struct {
+	sql    string
+	expect string
+}
+
+// runRestoreTest parses the statements and checks the restored SQL texts, the restored texts
+// are parsed again to make sure they are restored to themselves.
+func runRestoreTest(c *C, cases []restoreCase) {
+	p := parser.New()
+	for _, ca := range cases {
+		restored := restoreSQL(c, p, ca.sql)
+		c.Assert(restored, Equals, ca.expect, Commentf("sql %s", ca.sql))
+		c.Assert(restoreSQL(c, p, restored), Equals, restored, Commentf("sql %s", ca.sql))
+	}
+}
+
+func restoreSQL(c *C, p *parser.Parser, sql string) string {
+	stmt, err := p.ParseOneStmt(sql, "", "")
+	c.Assert(err, IsNil, Commentf("sql %s", sql))
+	var sb bytes.Buffer
+	err = stmt.Restore(format.NewRestoreCtx(&sb))
+	c.Assert(err, IsNil, Commentf("sql %s", sql))
+	return sb.String()
+}
@@ -20,6 +20,7 @@ package tidb
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"runtime/debug"
 	"strconv"
 	"strings"
@@ -29,10 +30,16 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/perfschema"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -185,6 +192,49 @@ const (
 		UNIQUE KEY (element_id),
 		KEY (job_id, element_id)
 	);`
+
+	// CreateQueryRewriteRulesTable stores the query rewrite rules, see the queryrewrite package.
+	CreateQueryRewriteRulesTable = `CREATE TABLE IF NOT EXISTS mysql.query_rewrite_rules (
+		id BIGINT NOT NULL AUTO_INCREMENT,
+		pattern TEXT NOT NULL COMMENT "the statement to match",
+		pattern_db VARCHAR(64) NOT NULL DEFAULT "" COMMENT "the current database to match, empty means any database",
+		replacement TEXT NOT NULL COMMENT "the statement to rewrite to",
+		enabled TINYINT(1) NOT NULL DEFAULT 1,
+		PRIMARY KEY (id)
+	);`
+
+	// CreateStatsExtendedTable stores the statistics of the column groups created by CREATE STATISTICS.
+	CreateStatsExtendedTable = `CREATE TABLE IF NOT EXISTS mysql.stats_extended (
+		name varchar(64) NOT NULL,
+		table_id bigint(64) NOT NULL,
+		column_ids varchar(255) NOT NULL COMMENT "the comma separated ids of the columns",
+		distinct_count bigint(64) NOT NULL DEFAULT 0,
+		version bigint(64) unsigned NOT NULL DEFAULT 0,
+		unique index tbl(table_id, name)
+	);`
+
+	// CreateStatsLockedTable stores the tables whose statistics are locked by LOCK STATS.
+	CreateStatsLockedTable = `CREATE TABLE IF NOT EXISTS mysql.stats_locked (
+		table_id bigint(64) NOT NULL,
+		PRIMARY KEY (table_id)
+	);`
+
+	// CreateBootstrapHistoryTable stores the bootstrap versions which the system tables are bootstrapped or upgraded to,
+	// with the release version of the TiDB server which did it.
+	CreateBootstrapHistoryTable = `CREATE TABLE IF NOT EXISTS mysql.bootstrap_history (
+		version bigint(64) NOT NULL,
+		server_version varchar(64) NOT NULL DEFAULT '',
+		applied_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (version)
+	);`
+
+	// CreateGCSnapshotPinsTable stores the snapshots pinned by ADMIN PIN SNAPSHOT, GC doesn't collect the versions
+	// they read until the pins expire.
+	CreateGCSnapshotPinsTable = `CREATE TABLE IF NOT EXISTS mysql.gc_snapshot_pins (
+		ts bigint(64) unsigned NOT NULL COMMENT "the pinned snapshot timestamp",
+		expire_ts bigint(64) unsigned NOT NULL COMMENT "the timestamp the pin expires at",
+		PRIMARY KEY (ts)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -197,6 +247,8 @@ func bootstrap(s Session) {
 		upgrade(s)
 		return
 	}
+	// The new collation is enabled before the system tables are created, so their indexes are written with it.
+	collate.SetNewCollationEnabled(newCollationSupported(s))
 	doDDLWorks(s)
 	doDMLWorks(s)
 }
@@ -211,6 +263,11 @@ const (
 	// The variable name in mysql.TiDB table.
 	// It is used for getting the version of the TiDB server which bootstrapped the store.
 	tidbServerVersionVar = "tidb_server_version" //
+	// The variable name in mysql.TiDB table.
+	// It is "True" if the store is bootstrapped with the new collation or upgraded to it, see the collate package.
+	// The stores whose coprocessor doesn't compare the strings by the new collations keep the old index key
+	// encoding, the stores bootstrapped by the old versions are upgraded to it by upgradeToVer22.
+	tidbNewCollationEnabledVar = "new_collation_enabled"
 	// Const for TiDB server version 2.
 	version2  = 2
 	version3  = 3
@@ -226,8 +283,49 @@ const (
 	version13 = 13
 	version14 = 14
 	version15 = 15
+	version16 = 16
+	version17 = 17
+	version18 = 18
+	version19 = 19
+	version20 = 20
+	version21 = 21
+	version22 = 22
 )
 
+// bootstrapMigration upgrades the system tables of a store bootstrapped by an older TiDB server to its version.
+// The upgrade function must be reentrant, it runs again if the server crashes before the version is recorded.
+type bootstrapMigration struct {
+	version int64
+	upgrade func(Session)
+}
+
+// bootstrapMigrations are the migrations in the order of their versions, the last one is currentBootstrapVersion.
+// To change the system tables, change the statements of bootstrap, add a migration which does the same change on
+// the existing stores, and bump currentBootstrapVersion.
+var bootstrapMigrations = []bootstrapMigration{
+	{version2, upgradeToVer2},
+	{version3, upgradeToVer3},
+	{version4, upgradeToVer4},
+	{version5, upgradeToVer5},
+	{version6, upgradeToVer6},
+	{version7, upgradeToVer7},
+	{version8, upgradeToVer8},
+	{version9, upgradeToVer9},
+	{version10, upgradeToVer10},
+	{version11, upgradeToVer11},
+	{version12, upgradeToVer12},
+	{version13, upgradeToVer13},
+	{version14, upgradeToVer14},
+	{version15, upgradeToVer15},
+	{version16, upgradeToVer16},
+	{version17, upgradeToVer17},
+	{version18, upgradeToVer18},
+	{version19, upgradeToVer19},
+	{version20, upgradeToVer20},
+	{version21, upgradeToVer21},
+	{version22, upgradeToVer22},
+}
+
 func checkBootstrapped(s Session) (bool, error) {
 	//  Check if system db exists.
 	_, err := s.Execute(fmt.Sprintf("USE %s;", mysql.SystemDB))
@@ -280,82 +378,27 @@ func upgrade(s Session) {
 	if err != nil {
 		log.Fatal(errors.Trace(err))
 	}
-	if ver >= currentBootstrapVersion {
-		// It is already bootstrapped/upgraded by a higher version TiDB server.
+	if ver > currentBootstrapVersion {
+		warnDowngrade(ver)
 		return
 	}
-	// Do upgrade works then update bootstrap version.
-	if ver < version2 {
-		upgradeToVer2(s)
-		ver = version2
-	}
-	if ver < version3 {
-		upgradeToVer3(s)
-	}
-	if ver < version4 {
-		upgradeToVer4(s)
-	}
-
-	if ver < version5 {
-		upgradeToVer5(s)
-	}
-
-	if ver < version6 {
-		upgradeToVer6(s)
-	}
-
-	if ver < version7 {
-		upgradeToVer7(s)
-	}
-
-	if ver < version8 {
-		upgradeToVer8(s)
-	}
-
-	if ver < version9 {
-		upgradeToVer9(s)
-	}
-
-	if ver < version10 {
-		upgradeToVer10(s)
-	}
-
-	if ver < version11 {
-		upgradeToVer11(s)
-	}
-
-	if ver < version12 {
-		upgradeToVer12(s)
-	}
-
-	if ver < version13 {
-		upgradeToVer13(s)
-	}
-
-	if ver < version14 {
-		upgradeToVer14(s)
+	if ver == currentBootstrapVersion {
+		return
 	}
-
-	if ver < version15 {
-		upgradeToVer15(s)
+	// The system tables are updated with the index key encoding of the store.
+	if err = loadNewCollationEnabled(s); err != nil {
+		log.Fatal(errors.Trace(err))
 	}
-
-	updateBootstrapVer(s)
-	_, err = s.Execute("COMMIT")
-
-	if err != nil {
-		time.Sleep(1 * time.Second)
-		// Check if TiDB is already upgraded.
-		v, err1 := getBootstrapVersion(s)
-		if err1 != nil {
-			log.Fatal(err1)
+	// The history table is created first, so every migration applied by this upgrade is recorded in it.
+	mustExecute(s, CreateBootstrapHistoryTable)
+	for _, m := range bootstrapMigrations {
+		if m.version <= ver {
+			continue
 		}
-		if v >= currentBootstrapVersion {
-			// It is already bootstrapped/upgraded by a higher version TiDB server.
-			return
-		}
-		log.Errorf("[Upgrade] upgrade from %d to %d error", ver, currentBootstrapVersion)
-		log.Fatal(err)
+		log.Infof("[Upgrade] upgrade the system tables from version %d to %d", ver, m.version)
+		m.upgrade(s)
+		recordBootstrapVersion(s, m.version)
+		ver = m.version
 	}
 	return
 }
@@ -547,14 +590,281 @@ func upgradeToVer15(s Session) {
 	}
 }
 
-// updateBootstrapVer updates bootstrap version variable in mysql.TiDB table.
-func updateBootstrapVer(s Session) {
-	// Update bootstrap version.
-	sql := fmt.Sprintf(`INSERT INTO %s.%s VALUES ("%s", "%d", "TiDB bootstrap version.") ON DUPLICATE KEY UPDATE VARIABLE_VALUE="%d"`,
-		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, currentBootstrapVersion, currentBootstrapVersion)
+func upgradeToVer16(s Session) {
+	mustExecute(s, CreateQueryRewriteRulesTable)
+}
+
+// upgradeToVer17 keeps the upgraded cluster writing the rows of the format version 1,
+// the TiDB servers of the old version can't read the rows of the format version 2.
+func upgradeToVer17(s Session) {
+	sql := fmt.Sprintf(`INSERT IGNORE INTO %s.%s VALUES ("%s", "%d");`, mysql.SystemDB, mysql.GlobalVariablesTable,
+		variable.TiDBRowFormatVersion, tablecodec.RowFormatV1)
 	mustExecute(s, sql)
 }
 
+func upgradeToVer18(s Session) {
+	mustExecute(s, CreateStatsExtendedTable)
+}
+
+func upgradeToVer19(s Session) {
+	mustExecute(s, CreateStatsLockedTable)
+}
+
+func upgradeToVer20(s Session) {
+	mustExecute(s, CreateBootstrapHistoryTable)
+}
+
+func upgradeToVer21(s Session) {
+	mustExecute(s, CreateGCSnapshotPinsTable)
+}
+
+// upgradeToVer22 records that the stores bootstrapped by the old versions use the old collation. They're upgraded to
+// the new collation only by UpgradeToNewCollation, which rebuilds the indexes while no TiDB server uses the store.
+func upgradeToVer22(s Session) {
+	mustExecute(s, fmt.Sprintf(`INSERT IGNORE INTO %s.%s VALUES("%s", "%s", "If the new collations are enabled. Do not edit it.")`,
+		mysql.SystemDB, mysql.TiDBTable, tidbNewCollationEnabledVar, "False"))
+}
+
+// UpgradeToNewCollation upgrades the store bootstrapped by an old version to the new collation, if its coprocessor
+// compares the strings by it. The indexes whose keys depend on the collation are rebuilt with the new key encoding
+// outside of the DDL, so it must be run while no TiDB server uses the store. It returns an error and the store keeps
+// the old collation if a unique index has the values which are duplicated by the new collation.
+func UpgradeToNewCollation(store kv.Storage) error {
+	if _, err := BootstrapSession(store); err != nil {
+		return errors.Trace(err)
+	}
+	if collate.NewCollationEnabled() {
+		return nil
+	}
+	s, err := createSession(store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer s.Close()
+	if !newCollationSupported(s) {
+		return errors.New("the coprocessor of the store doesn't compare the strings by the new collation")
+	}
+	indices, err := collationDependentIndices(s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The duplicates are checked by the queries, which compare the strings by the new collation.
+	collate.SetNewCollationEnabled(true)
+	for _, idx := range indices {
+		dup, err := hasCollationDuplicates(s, idx)
+		if err == nil && dup {
+			err = errors.Errorf("unique index %s of %s.%s has duplicated values in the new collation",
+				idx.index.Meta().Name, idx.dbName, idx.table.Meta().Name)
+		}
+		if err != nil {
+			collate.SetNewCollationEnabled(false)
+			return errors.Trace(err)
+		}
+	}
+	// The entries of the indexes are deleted by their prefixes, so the old encoding isn't needed to delete them,
+	// and it's fine to run it again if it fails before the store is upgraded.
+	for _, idx := range indices {
+		if err = rebuildIndex(s, idx); err != nil {
+			collate.SetNewCollationEnabled(false)
+			return errors.Trace(err)
+		}
+	}
+	_, err = s.Execute(fmt.Sprintf(`INSERT INTO %s.%s VALUES("%s", "%s", "If the new collations are enabled. Do not edit it.")
+		ON DUPLICATE KEY UPDATE VARIABLE_VALUE="%s"`,
+		mysql.SystemDB, mysql.TiDBTable, tidbNewCollationEnabledVar, bootstrappedVarTrue, bootstrappedVarTrue))
+	if err != nil {
+		collate.SetNewCollationEnabled(false)
+		return errors.Trace(err)
+	}
+	log.Infof("[Upgrade] the store is upgraded to the new collation, %d indexes are rebuilt", len(indices))
+	return nil
+}
+
+// newCollationSupported returns if the coprocessor of the store compares the strings by the new collations,
+// or the predicates pushed down to it return wrong rows if the new collation is enabled.
+func newCollationSupported(s Session) bool {
+	client := s.GetClient()
+	return client != nil && client.IsRequestTypeSupported(kv.ReqTypeDAG, kv.ReqSubTypeNewCollation)
+}
+
+// collationIndex is an index whose key encoding depends on the collation.
+type collationIndex struct {
+	dbName string
+	table  table.Table
+	index  table.Index
+}
+
+// collationDependentIndices returns the indexes of the string or the decimal columns, the keys of the strings are
+// their sort keys, and the decimals are encoded with the precision and frac of the columns with the new collation.
+func collationDependentIndices(s Session) ([]collationIndex, error) {
+	dom := sessionctx.GetDomain(s)
+	if err := dom.Reload(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	is := dom.InfoSchema()
+	var indices []collationIndex
+	for _, db := range is.AllSchemas() {
+		if strings.EqualFold(db.Name.O, infoschema.Name) || strings.EqualFold(db.Name.O, perfschema.Name) {
+			continue
+		}
+		for _, t := range is.SchemaTables(db.Name) {
+			// The rows of the external engines aren't stored in the store.
+			if t.Meta().Engine != "" {
+				continue
+			}
+			for _, idx := range t.Indices() {
+				for _, ic := range idx.Meta().Columns {
+					ft := &t.Meta().Columns[ic.Offset].FieldType
+					if types.IsNonBinaryStr(ft) || ft.Tp == mysql.TypeNewDecimal {
+						indices = append(indices, collationIndex{dbName: db.Name.O, table: t, index: idx})
+						break
+					}
+				}
+			}
+		}
+	}
+	return indices, nil
+}
+
+// hasCollationDuplicates returns if the unique index has the values which are equal in the new collation.
+func hasCollationDuplicates(s Session, idx collationIndex) (bool, error) {
+	if !idx.index.Meta().Unique {
+		return false, nil
+	}
+	cols := make([]string, 0, len(idx.index.Meta().Columns))
+	conds := make([]string, 0, len(idx.index.Meta().Columns))
+	for _, ic := range idx.index.Meta().Columns {
+		col := fmt.Sprintf("`%s`", strings.Replace(ic.Name.O, "`", "``", -1))
+		// The NULL values aren't duplicated in the unique indexes.
+		conds = append(conds, col+" IS NOT NULL")
+		if ic.Length != types.UnspecifiedLength {
+			col = fmt.Sprintf("LEFT(%s, %d)", col, ic.Length)
+		}
+		cols = append(cols, col)
+	}
+	sql := fmt.Sprintf("SELECT 1 FROM `%s`.`%s` WHERE %s GROUP BY %s HAVING COUNT(*) > 1 LIMIT 1",
+		strings.Replace(idx.dbName, "`", "``", -1), strings.Replace(idx.table.Meta().Name.O, "`", "``", -1),
+		strings.Join(conds, " AND "), strings.Join(cols, ", "))
+	rss, err := s.Execute(sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	defer rss[0].Close()
+	row, err := rss[0].Next()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return row != nil, nil
+}
+
+// rebuildIndexBatchSize is the number of the index entries deleted or created in a transaction by rebuildIndex.
+const rebuildIndexBatchSize = 1024
+
+// rebuildIndex deletes the entries of the index, and creates them from the rows of the table with the current
+// key encoding, in the transactions of rebuildIndexBatchSize entries.
+func rebuildIndex(s Session, idx collationIndex) error {
+	prefix := tablecodec.EncodeTableIndexPrefix(idx.table.Meta().ID, idx.index.Meta().ID)
+	for {
+		if err := s.NewTxn(); err != nil {
+			return errors.Trace(err)
+		}
+		keys, err := scanIndexKeys(s.Txn(), prefix, rebuildIndexBatchSize)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, key := range keys {
+			if err = s.Txn().Delete(key); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if err = s.Txn().Commit(); err != nil {
+			return errors.Trace(err)
+		}
+		if len(keys) < rebuildIndexBatchSize {
+			break
+		}
+	}
+
+	startKey := idx.table.FirstKey()
+	for {
+		if err := s.NewTxn(); err != nil {
+			return errors.Trace(err)
+		}
+		var lastHandle int64
+		count := 0
+		err := idx.table.IterRecords(s, startKey, idx.table.Cols(), func(h int64, row []types.Datum, _ []*table.Column) (bool, error) {
+			vals, err := idx.index.FetchValues(row)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if _, err = idx.index.Create(s.Txn(), vals, h); err != nil {
+				return false, errors.Trace(err)
+			}
+			lastHandle = h
+			count++
+			return count < rebuildIndexBatchSize, nil
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = s.Txn().Commit(); err != nil {
+			return errors.Trace(err)
+		}
+		if count < rebuildIndexBatchSize || lastHandle == math.MaxInt64 {
+			return nil
+		}
+		startKey = tablecodec.EncodeRecordKey(idx.table.RecordPrefix(), lastHandle+1)
+	}
+}
+
+func scanIndexKeys(txn kv.Transaction, prefix kv.Key, limit int) ([]kv.Key, error) {
+	it, err := txn.Seek(prefix)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer it.Close()
+	var keys []kv.Key
+	for it.Valid() && it.Key().HasPrefix(prefix) && len(keys) < limit {
+		keys = append(keys, it.Key().Clone())
+		if err = it.Next(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return keys, nil
+}
+
+// recordBootstrapVersion records that the system tables are upgraded to ver, so the upgrade continues from the next
+// migration if the server crashes. The commit may fail if another TiDB server upgrades the store at the same time,
+// it's fine if the store is already upgraded to ver.
+func recordBootstrapVersion(s Session, ver int64) {
+	mustExecute(s, "BEGIN")
+	mustExecute(s, fmt.Sprintf(`INSERT INTO %s.%s VALUES ("%s", "%d", "TiDB bootstrap version.") ON DUPLICATE KEY UPDATE VARIABLE_VALUE="%d"`,
+		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, ver, ver))
+	mustExecute(s, fmt.Sprintf(`INSERT IGNORE INTO %s.%s (version, server_version) VALUES (%d, "%s")`,
+		mysql.SystemDB, mysql.BootstrapHistoryTable, ver, mysql.TiDBReleaseVersion))
+	_, err := s.Execute("COMMIT")
+	if err == nil {
+		return
+	}
+	time.Sleep(1 * time.Second)
+	v, err1 := getBootstrapVersion(s)
+	if err1 != nil {
+		log.Fatal(err1)
+	}
+	if v >= ver {
+		return
+	}
+	log.Errorf("[Upgrade] upgrade to %d error", ver)
+	log.Fatal(err)
+}
+
+// warnDowngrade warns that the store is upgraded by a newer TiDB server, the system tables may have the columns and
+// the rows which this server doesn't know, and the features which depend on them don't work.
+func warnDowngrade(ver int64) {
+	log.Warnf("[Upgrade] the store is bootstrapped with version %d by a newer TiDB server, but the version of this server is %d, downgrade isn't supported",
+		ver, currentBootstrapVersion)
+}
+
 // getBootstrapVersion gets bootstrap version from mysql.tidb table;
 func getBootstrapVersion(s Session) (int64, error) {
 	d, err := getTiDBVar(s, tidbServerVersionVar)
@@ -567,6 +877,16 @@ func getBootstrapVersion(s Session) (int64, error) {
 	return strconv.ParseInt(d.GetString(), 10, 64)
 }
 
+// loadNewCollationEnabled enables the new collation if the store is bootstrapped with it.
+func loadNewCollationEnabled(s Session) error {
+	d, err := getTiDBVar(s, tidbNewCollationEnabledVar)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	collate.SetNewCollationEnabled(!d.IsNull() && d.GetString() == bootstrappedVarTrue)
+	return nil
+}
+
 // doDDLWorks executes DDL statements in bootstrap stage.
 func doDDLWorks(s Session) {
 	// Create a test database.
@@ -593,6 +913,16 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateStatsBucketsTable)
 	// Create gc_delete_range table.
 	mustExecute(s, CreateGCDeleteRangeTable)
+	// Create query_rewrite_rules table.
+	mustExecute(s, CreateQueryRewriteRulesTable)
+	// Create stats_extended table.
+	mustExecute(s, CreateStatsExtendedTable)
+	// Create stats_locked table.
+	mustExecute(s, CreateStatsLockedTable)
+	// Create bootstrap_history table.
+	mustExecute(s, CreateBootstrapHistoryTable)
+	// Create gc_snapshot_pins table.
+	mustExecute(s, CreateGCSnapshotPinsTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
@@ -626,6 +956,18 @@ func doDMLWorks(s Session) {
 		mysql.SystemDB, mysql.TiDBTable, tidbServerVersionVar, currentBootstrapVersion)
 	mustExecute(s, sql)
 
+	newCollationEnabled := "False"
+	if collate.NewCollationEnabled() {
+		newCollationEnabled = bootstrappedVarTrue
+	}
+	sql = fmt.Sprintf(`INSERT INTO %s.%s VALUES("%s", "%s", "If the new collations are enabled. Do not edit it.")`,
+		mysql.SystemDB, mysql.TiDBTable, tidbNewCollationEnabledVar, newCollationEnabled)
+	mustExecute(s, sql)
+
+	sql = fmt.Sprintf(`INSERT INTO %s.%s (version, server_version) VALUES (%d, "%s")`,
+		mysql.SystemDB, mysql.BootstrapHistoryTable, currentBootstrapVersion, mysql.TiDBReleaseVersion)
+	mustExecute(s, sql)
+
 	_, err := s.Execute("COMMIT")
 	if err != nil {
 		time.Sleep(1 * time.Second)
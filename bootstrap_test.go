@@ -20,10 +20,12 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -198,6 +200,8 @@ func (s *testBootstrapSuite) TestUpgrade(c *C) {
 	mustExecSQL(c, se1, `delete from mysql.TiDB where VARIABLE_NAME="tidb_server_version";`)
 	mustExecSQL(c, se1, fmt.Sprintf(`delete from mysql.global_variables where VARIABLE_NAME="%s";`,
 		variable.TiDBDistSQLScanConcurrency))
+	mustExecSQL(c, se1, fmt.Sprintf(`delete from mysql.global_variables where VARIABLE_NAME="%s";`,
+		variable.TiDBRowFormatVersion))
 	mustExecSQL(c, se1, `commit;`)
 	delete(storeBootstrapped, store.UUID())
 	// Make sure the version is downgraded.
@@ -223,6 +227,93 @@ func (s *testBootstrapSuite) TestUpgrade(c *C) {
 	ver, err = getBootstrapVersion(se2)
 	c.Assert(err, IsNil)
 	c.Assert(ver, Equals, int64(currentBootstrapVersion))
+
+	// The upgraded cluster writes the rows of the format version 1.
+	mustExecMatch(c, se2, fmt.Sprintf(`SELECT VARIABLE_VALUE from mysql.global_variables where VARIABLE_NAME="%s";`,
+		variable.TiDBRowFormatVersion), [][]interface{}{{[]byte("1")}})
+
+	// Every applied migration is recorded.
+	mustExecMatch(c, se2, "SELECT count(*), min(version), max(version) from mysql.bootstrap_history",
+		[][]interface{}{{int64(len(bootstrapMigrations)), int64(version2), int64(currentBootstrapVersion)}})
+
+	// The store upgraded by a newer TiDB server isn't changed.
+	mustExecSQL(c, se2, fmt.Sprintf(`UPDATE mysql.TiDB SET VARIABLE_VALUE="%d" WHERE VARIABLE_NAME="tidb_server_version"`,
+		currentBootstrapVersion+1))
+	upgrade(se2)
+	ver, err = getBootstrapVersion(se2)
+	c.Assert(err, IsNil)
+	c.Assert(ver, Equals, int64(currentBootstrapVersion+1))
+}
+
+func (s *testBootstrapSuite) TestBootstrapMigrations(c *C) {
+	defer testleak.AfterTest(c)()
+	for i := 1; i < len(bootstrapMigrations); i++ {
+		c.Assert(bootstrapMigrations[i].version, Greater, bootstrapMigrations[i-1].version)
+	}
+	c.Assert(bootstrapMigrations[len(bootstrapMigrations)-1].version, Equals, int64(currentBootstrapVersion))
+
+	store := newStoreWithBootstrap(c, s.dbName)
+	defer store.Close()
+	se := newSession(c, store, s.dbName)
+	mustExecMatch(c, se, "SELECT version, server_version from mysql.bootstrap_history",
+		[][]interface{}{{int64(currentBootstrapVersion), []byte(mysql.TiDBReleaseVersion)}})
+}
+
+func (s *testBootstrapSuite) TestUpgradeToNewCollation(c *C) {
+	defer testleak.AfterTest(c)()
+	store := newStoreWithBootstrap(c, s.dbName+"_new_collation")
+	defer store.Close()
+	defer collate.SetNewCollationEnabled(true)
+	se := newSession(c, store, s.dbName)
+	newCollationEnabled := func(se Session) []interface{} {
+		r := mustExecSQL(c, se, fmt.Sprintf(`SELECT count(*) from mysql.TiDB where VARIABLE_NAME="%s" and VARIABLE_VALUE="True"`,
+			tidbNewCollationEnabledVar))
+		row, err := r.Next()
+		c.Assert(err, IsNil)
+		c.Assert(r.Close(), IsNil)
+		return []interface{}{row.Data[0].GetInt64() == 1, collate.NewCollationEnabled()}
+	}
+	c.Assert(newCollationEnabled(se), DeepEquals, []interface{}{true, true})
+	// Make the store look like it's bootstrapped by the old version without the new collation.
+	downgrade := func() {
+		mustExecSQL(c, se, fmt.Sprintf(`delete from mysql.TiDB where VARIABLE_NAME="%s"`, tidbNewCollationEnabledVar))
+		mustExecSQL(c, se, fmt.Sprintf(`update mysql.TiDB set VARIABLE_VALUE="%d" where VARIABLE_NAME="tidb_server_version"`, version21))
+		txn, err := store.Begin()
+		c.Assert(err, IsNil)
+		c.Assert(meta.NewMeta(txn).FinishBootstrap(version21), IsNil)
+		c.Assert(txn.Commit(), IsNil)
+		delete(storeBootstrapped, store.UUID())
+		collate.SetNewCollationEnabled(false)
+	}
+	downgrade()
+	mustExecSQL(c, se, `create table t (a int primary key, b varchar(10) collate utf8_general_ci, c char(10), d decimal(5, 2),
+		unique index idx_b (b), index idx_c (c), index idx_d (d))`)
+	mustExecSQL(c, se, "insert into t values (1, 'a', 'x', 1.5), (2, 'A', 'y', 10)")
+
+	// The upgrade of the bootstrap version keeps the old collation.
+	_, err := BootstrapSession(store)
+	c.Assert(err, IsNil)
+	se = newSession(c, store, s.dbName)
+	c.Assert(newCollationEnabled(se), DeepEquals, []interface{}{false, false})
+	mustExecMatch(c, se, "select a from t use index (idx_b) where b = 'A'", [][]interface{}{{2}})
+
+	// The store isn't upgraded if the values of a unique index are duplicated in the new collation.
+	c.Assert(UpgradeToNewCollation(store), NotNil)
+	c.Assert(newCollationEnabled(se), DeepEquals, []interface{}{false, false})
+	mustExecMatch(c, se, "select a from t use index (idx_b) where b = 'A'", [][]interface{}{{2}})
+
+	mustExecSQL(c, se, "update t set b = 'b' where a = 2")
+	c.Assert(UpgradeToNewCollation(store), IsNil)
+	c.Assert(newCollationEnabled(se), DeepEquals, []interface{}{true, true})
+	// The indexes are rebuilt with the new key encoding.
+	mustExecMatch(c, se, "select a from t use index (idx_b) where b = 'B'", [][]interface{}{{2}})
+	mustExecMatch(c, se, "select a from t use index (idx_c) where c = 'x  '", [][]interface{}{{1}})
+	mustExecMatch(c, se, "select d from t use index (idx_d) where d > 1 order by d", [][]interface{}{{"1.50"}, {"10.00"}})
+	mustExecFailed(c, se, "insert into t values (3, 'a ', 'z', 0)")
+	mustExecSQL(c, se, "admin check table t")
+	mustExecSQL(c, se, "drop table t")
+	// It's a no-op if the store is already upgraded.
+	c.Assert(UpgradeToNewCollation(store), IsNil)
 }
 
 func (s *testBootstrapSuite) TestOldPasswordUpgrade(c *C) {
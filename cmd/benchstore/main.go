@@ -0,0 +1,232 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// benchstore benchmarks the kv.Storage of a store URL with the commit, point get, seek and scan patterns
+// of TiDB, so different storages can be compared with the same workload.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/tikv"
+)
+
+var (
+	storePath = flag.String("store", "goleveldb:///tmp/benchstore", "URL of the store, like tidb-server -path with the engine")
+	keyCount  = flag.Int("N", 100000, "number of keys")
+	valueSize = flag.Int("V", 128, "value size in byte")
+	workerCnt = flag.Int("C", 16, "number of concurrent workers")
+	batchSize = flag.Int("batch", 100, "number of keys written in a transaction")
+	scanSize  = flag.Int("scan", 100, "number of keys read by a seek")
+	logLevel  = flag.String("L", "error", "log level")
+	runJobs   = flag.String("run", "insert|get|seek|update|scan", "jobs to run, in [insert, get, seek, update, scan]")
+)
+
+func main() {
+	flag.Parse()
+	logutil.InitLogger(&logutil.LogConfig{
+		Level: *logLevel,
+	})
+	tidb.RegisterStore("tikv", tikv.Driver{})
+	tidb.RegisterStore("mocktikv", tikv.MockDriver{})
+	store, err := tidb.NewStore(*storePath)
+	if err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
+	defer store.Close()
+
+	b := &bench{store: store, value: make([]byte, *valueSize)}
+	for _, job := range strings.Split(*runJobs, "|") {
+		job = strings.ToLower(strings.TrimSpace(job))
+		var r *result
+		switch job {
+		case "insert":
+			r = b.run(*keyCount / *batchSize, b.insert)
+		case "update":
+			r = b.run(*keyCount / *batchSize, b.update)
+		case "get":
+			r = b.run(*keyCount, b.get)
+		case "seek":
+			r = b.run(*keyCount / *scanSize, b.seek)
+		case "scan":
+			r = b.run(1, b.scan)
+		default:
+			log.Fatalf("unknown job %s", job)
+		}
+		r.print(job)
+	}
+}
+
+type bench struct {
+	store kv.Storage
+	value []byte
+}
+
+func encodeKey(i int) kv.Key {
+	return kv.Key(fmt.Sprintf("benchstore_%010d", i))
+}
+
+// insert writes a batch of sequential keys in a transaction.
+func (b *bench) insert(op int) (int, error) {
+	txn, err := b.store.Begin()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	for i := op * *batchSize; i < (op+1)**batchSize; i++ {
+		if err = txn.Set(encodeKey(i), b.value); err != nil {
+			txn.Rollback()
+			return 0, errors.Trace(err)
+		}
+	}
+	return *batchSize, errors.Trace(txn.Commit())
+}
+
+// update reads and writes a batch of random keys in a transaction, the conflicts are retried.
+func (b *bench) update(op int) (int, error) {
+	err := kv.RunInNewTxn(b.store, true, func(txn kv.Transaction) error {
+		for i := 0; i < *batchSize; i++ {
+			key := encodeKey(rand.Intn(*keyCount))
+			if _, err := txn.Get(key); err != nil && !kv.IsErrNotFound(err) {
+				return errors.Trace(err)
+			}
+			if err := txn.Set(key, b.value); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return *batchSize, errors.Trace(err)
+}
+
+// get reads a random key from a snapshot.
+func (b *bench) get(op int) (int, error) {
+	snapshot, err := b.store.GetSnapshot(kv.MaxVersion)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	_, err = snapshot.Get(encodeKey(rand.Intn(*keyCount)))
+	if kv.IsErrNotFound(err) {
+		err = nil
+	}
+	return 1, errors.Trace(err)
+}
+
+// seek reads the keys following a random key.
+func (b *bench) seek(op int) (int, error) {
+	snapshot, err := b.store.GetSnapshot(kv.MaxVersion)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	it, err := snapshot.Seek(encodeKey(rand.Intn(*keyCount)))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return readKeys(it, *scanSize)
+}
+
+// scan reads all the keys.
+func (b *bench) scan(op int) (int, error) {
+	snapshot, err := b.store.GetSnapshot(kv.MaxVersion)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	it, err := snapshot.Seek(encodeKey(0))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return readKeys(it, *keyCount)
+}
+
+func readKeys(it kv.Iterator, limit int) (int, error) {
+	defer it.Close()
+	prefix := kv.Key("benchstore_")
+	n := 0
+	for ; n < limit && it.Valid() && it.Key().HasPrefix(prefix); n++ {
+		if err := it.Next(); err != nil {
+			return n, errors.Trace(err)
+		}
+	}
+	return n, nil
+}
+
+type result struct {
+	ops       int
+	keys      int
+	failed    int
+	elapsed   time.Duration
+	latencies []time.Duration
+}
+
+// run runs the operations by the workers concurrently, an operation is given its sequence number and
+// returns the number of keys it read or wrote.
+func (b *bench) run(ops int, f func(op int) (int, error)) *result {
+	r := &result{ops: ops, latencies: make([]time.Duration, 0, ops)}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	opCh := make(chan int, *workerCnt)
+	start := time.Now()
+	for i := 0; i < *workerCnt; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range opCh {
+				opStart := time.Now()
+				n, err := f(op)
+				latency := time.Since(opStart)
+				mu.Lock()
+				r.keys += n
+				r.latencies = append(r.latencies, latency)
+				if err != nil {
+					r.failed++
+					log.Error(errors.ErrorStack(err))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for op := 0; op < ops; op++ {
+		opCh <- op
+	}
+	close(opCh)
+	wg.Wait()
+	r.elapsed = time.Since(start)
+	return r
+}
+
+func (r *result) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	return r.latencies[int(float64(len(r.latencies)-1)*p)]
+}
+
+func (r *result) print(job string) {
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	seconds := r.elapsed.Seconds()
+	fmt.Printf("%-8s ops:%d failed:%d keys:%d elapsed:%v ops/s:%.0f keys/s:%.0f p50:%v p99:%v max:%v\n",
+		job, r.ops, r.failed, r.keys, r.elapsed, float64(r.ops)/seconds, float64(r.keys)/seconds,
+		r.percentile(0.5), r.percentile(0.99), r.percentile(1))
+}
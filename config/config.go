@@ -13,12 +13,21 @@
 
 package config
 
-import "sync"
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+)
 
 // Config contains configuration options.
 type Config struct {
 	Addr           string `json:"addr" toml:"addr"`
 	LogLevel       string `json:"log_level" toml:"log_level"`
+	LogFormat      string `json:"log_format" toml:"log_format"`
+	ModuleLogLevel string `json:"module_log_level" toml:"module_log_level"`
 	SkipAuth       bool   `json:"skip_auth" toml:"skip_auth"`
 	StatusAddr     string `json:"status_addr" toml:"status_addr"`
 	Socket         string `json:"socket" toml:"socket"`
@@ -31,20 +40,95 @@ type Config struct {
 	SSLCAPath      string `json:"ssl_ca_path" toml:"ssl_ca_path"`
 	SSLCertPath    string `json:"ssl_cert_path" toml:"ssl_cert_path"`
 	SSLKeyPath     string `json:"ssl_key_path" toml:"ssl_key_path"`
+	// HTTPSQL enables the /sql endpoint on the status port to run SQL over HTTP with the basic authentication.
+	HTTPSQL bool `json:"http_sql" toml:"http_sql"`
+	// GracefulWaitBeforeShutdown is the seconds to wait before closing the server on exit, the readiness check
+	// fails during the wait so the load balancers route the new connections to the other servers.
+	GracefulWaitBeforeShutdown int `json:"graceful_wait_before_shutdown" toml:"graceful_wait_before_shutdown"`
+	// AdvertiseAddress is the host registered for the other tidb-servers to reach this server, the host of Addr
+	// is registered if it's empty, or the hostname if Addr listens on all the addresses.
+	AdvertiseAddress string `json:"advertise_address" toml:"advertise_address"`
 }
 
-var cfg *Config
-var once sync.Once
+// ReloadHook is called with the old and the new configuration when the configuration is reloaded.
+type ReloadHook func(oldCfg, newCfg *Config) error
+
+var (
+	globalConf atomic.Value
+	once       sync.Once
+
+	reloadMu    sync.Mutex
+	configFile  string
+	reloadHooks []ReloadHook
+)
 
 // GetGlobalConfig returns the global configuration for this server.
 // It should store configuration from command line and configuration file.
 // Other parts of the system can read the global configuration use this function.
+// The configuration may be replaced by Reload, so it shouldn't be cached.
 func GetGlobalConfig() *Config {
 	once.Do(func() {
-		cfg = &Config{
+		globalConf.Store(&Config{
 			SlowThreshold:  300,
 			QueryLogMaxlen: 2048,
-		}
+		})
 	})
-	return cfg
+	return globalConf.Load().(*Config)
+}
+
+// Load loads the options in the JSON config file to the configuration, and keeps the path of the file for Reload.
+func (c *Config) Load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = json.Unmarshal(data, c); err != nil {
+		return errors.Trace(err)
+	}
+	reloadMu.Lock()
+	configFile = path
+	reloadMu.Unlock()
+	return nil
+}
+
+// RegisterReloadHook registers a hook to apply the reloaded configuration.
+func RegisterReloadHook(hook ReloadHook) {
+	reloadMu.Lock()
+	reloadHooks = append(reloadHooks, hook)
+	reloadMu.Unlock()
+}
+
+// Reload reloads the config file loaded by Load, the reloadable options are the log levels, the slow query
+// threshold, the max length of the logged queries and the TLS certificates, the other options are kept until
+// the server restarts.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if configFile == "" {
+		return errors.New("the server is not started with a config file")
+	}
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	oldCfg := GetGlobalConfig()
+	loaded := *oldCfg
+	if err = json.Unmarshal(data, &loaded); err != nil {
+		return errors.Trace(err)
+	}
+	newCfg := *oldCfg
+	newCfg.LogLevel = loaded.LogLevel
+	newCfg.ModuleLogLevel = loaded.ModuleLogLevel
+	newCfg.SlowThreshold = loaded.SlowThreshold
+	newCfg.QueryLogMaxlen = loaded.QueryLogMaxlen
+	newCfg.SSLCAPath = loaded.SSLCAPath
+	newCfg.SSLCertPath = loaded.SSLCertPath
+	newCfg.SSLKeyPath = loaded.SSLKeyPath
+	for _, hook := range reloadHooks {
+		if err = hook(oldCfg, &newCfg); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	globalConf.Store(&newCfg)
+	return nil
 }
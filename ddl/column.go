@@ -16,7 +16,6 @@ package ddl
 import (
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
@@ -255,11 +254,18 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 	handles := make([]int64, 0, defaultBatchCnt)
 	// Get column default value.
 	var err error
-	if columnInfo.DefaultValue != nil {
+	if columnInfo.DefaultIsExpr {
+		// The expression is evaluated once when the column is added, the value is kept in the origin default value.
+		colMeta.defaultVal, err = table.GetColOriginDefaultValue(ctx, columnInfo)
+		if err != nil {
+			job.State = model.JobCancelled
+			return errors.Trace(err)
+		}
+	} else if columnInfo.DefaultValue != nil {
 		colMeta.defaultVal, err = table.GetColDefaultValue(ctx, columnInfo)
 		if err != nil {
 			job.State = model.JobCancelled
-			log.Errorf("[ddl] fatal: this case shouldn't happen, column %v err %v", columnInfo, err)
+			ddlLogger().Errorf("[ddl] fatal: this case shouldn't happen, column %v err %v", columnInfo, err)
 			return errors.Trace(err)
 		}
 	} else if mysql.HasNotNullFlag(columnInfo.Flag) {
@@ -291,13 +297,13 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 		sub := time.Since(startTime).Seconds()
 		err = d.backfillColumn(ctx, t, colMeta, handles, reorgInfo)
 		if err != nil {
-			log.Warnf("[ddl] added column for %v rows failed, take time %v", count, sub)
+			ddlLogger().Warnf("[ddl] added column for %v rows failed, take time %v", count, sub)
 			return errors.Trace(err)
 		}
 
 		d.setReorgRowCount(count)
 		batchHandleDataHistogram.WithLabelValues(batchAddCol).Observe(sub)
-		log.Infof("[ddl] added column for %v rows, take time %v", count, sub)
+		ddlLogger().Infof("[ddl] added column for %v rows, take time %v", count, sub)
 	}
 }
 
@@ -306,7 +312,7 @@ func (d *ddl) addTableColumn(t table.Table, columnInfo *model.ColumnInfo, reorgI
 func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []int64, txn kv.Transaction) (int64, error) {
 	nextHandle := handles[0]
 	for _, handle := range handles {
-		log.Debug("[ddl] backfill column...", handle)
+		ddlLogger().Debug("[ddl] backfill column...", handle)
 		rowKey := t.RecordKey(handle)
 		rowVal, err := txn.Get(rowKey)
 		if err != nil {
@@ -334,7 +340,7 @@ func (d *ddl) backfillColumnInTxn(t table.Table, colMeta *columnMeta, handles []
 		}
 		newColumnIDs = append(newColumnIDs, colMeta.colID)
 		newRow = append(newRow, colMeta.defaultVal)
-		newRowVal, err := tablecodec.EncodeRow(newRow, newColumnIDs, time.UTC)
+		newRowVal, err := tablecodec.EncodeRowWithVersion(tablecodec.GetRowFormatVersion(rowVal), newRow, newColumnIDs, time.UTC)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
@@ -407,6 +413,213 @@ func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	return d.doModifyColumn(t, job, newCol, oldColName, pos)
 }
 
+// onModifyColumnElems reorders or removes the elements of an enum or set column.
+// The column stores its values by names before the reorganization, so the values written in the old and the new
+// schema mean the same elements, and the values with the removed elements can't be written since then. Then the
+// reorganization checks the existing values against the new elements and rewrites them by names, the job is
+// cancelled if a value isn't in the new elements.
+func (d *ddl) onModifyColumnElems(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	newCol := &model.ColumnInfo{}
+	oldColName := &model.CIStr{}
+	pos := &ast.ColumnPosition{}
+	err := job.DecodeArgs(newCol, oldColName, pos)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	oldCol := findCol(tblInfo.Columns, oldColName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldColName, tblInfo.Name)
+	}
+
+	if job.IsCancelling() {
+		d.stopReorgJob()
+		ddlLogger().Infof("[ddl] cancel DDL job %v", job)
+		ver, err = cancelModifyColumnElems(t, job, tblInfo, oldCol)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		return ver, errCancelledDDLJob
+	}
+
+	originalState := job.SchemaState
+	switch job.SchemaState {
+	case model.StateNone:
+		// none -> write only
+		job.SchemaState = model.StateWriteOnly
+		oldCol.ElemsByName = true
+		oldCol.ChangingElems = newCol.Elems
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		// reorganization -> public
+		var reorgInfo *reorgInfo
+		reorgInfo, err = d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return ver, errors.Trace(err)
+		}
+
+		var tbl table.Table
+		tbl, err = d.getTable(job.SchemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		err = d.runReorgJob(job, func() error {
+			return d.rewriteColumnElems(tbl, oldCol, newCol, reorgInfo, job)
+		})
+		if err != nil {
+			if errWaitReorgTimeout.Equal(err) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if errDataTruncated.Equal(err) {
+				// A row still has a removed element.
+				var err1 error
+				if ver, err1 = cancelModifyColumnElems(t, job, tblInfo, oldCol); err1 != nil {
+					return ver, errors.Trace(err1)
+				}
+			}
+			return ver, errors.Trace(err)
+		}
+
+		newCol.ElemsByName = true
+		return d.doModifyColumn(t, job, newCol, oldColName, pos)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", job.SchemaState)
+	}
+
+	return ver, errors.Trace(err)
+}
+
+// cancelModifyColumnElems cancels the job of modifying the elements. The values stored by names are valid for
+// the old elements too, so only the new elements are removed from the column.
+func cancelModifyColumnElems(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, oldCol *model.ColumnInfo) (ver int64, err error) {
+	oldCol.ChangingElems = nil
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobCancelled
+	return ver, nil
+}
+
+// rewriteColumnElems checks the values of the column against the new elements and stores them by names.
+func (d *ddl) rewriteColumnElems(t table.Table, oldCol, newCol *model.ColumnInfo, reorgInfo *reorgInfo, job *model.Job) error {
+	seekHandle := reorgInfo.Handle
+	version := reorgInfo.SnapshotVer
+	count := job.GetRowCount()
+
+	colMap := make(map[int64]*types.FieldType, len(t.Meta().Columns))
+	for _, col := range t.Meta().Columns {
+		colMap[col.ID] = &col.FieldType
+	}
+	handles := make([]int64, 0, defaultBatchCnt)
+	for {
+		startTime := time.Now()
+		handles = handles[:0]
+		err := d.iterateSnapshotRows(t, version, seekHandle,
+			func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+				handles = append(handles, h)
+				if len(handles) == defaultBatchCnt {
+					return false, nil
+				}
+				return true, nil
+			})
+		if err != nil {
+			return errors.Trace(err)
+		} else if len(handles) == 0 {
+			return nil
+		}
+
+		count += int64(len(handles))
+		seekHandle = handles[len(handles)-1] + 1
+		for len(handles) > 0 {
+			endIdx := len(handles)
+			if endIdx > defaultSmallBatchCnt {
+				endIdx = defaultSmallBatchCnt
+			}
+			err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+				if err1 := d.isReorgRunnable(txn); err1 != nil {
+					return errors.Trace(err1)
+				}
+				if err1 := rewriteColumnElemsInTxn(t, oldCol, newCol, colMap, handles[:endIdx], txn); err1 != nil {
+					return errors.Trace(err1)
+				}
+				return errors.Trace(reorgInfo.UpdateHandle(txn, handles[endIdx-1]))
+			})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			handles = handles[endIdx:]
+		}
+
+		sub := time.Since(startTime).Seconds()
+		d.setReorgRowCount(count)
+		batchHandleDataHistogram.WithLabelValues(batchModifyElems).Observe(sub)
+		ddlLogger().Infof("[ddl] modified column elems for %v rows, take time %v", count, sub)
+	}
+}
+
+func rewriteColumnElemsInTxn(t table.Table, oldCol, newCol *model.ColumnInfo, colMap map[int64]*types.FieldType,
+	handles []int64, txn kv.Transaction) error {
+	for _, handle := range handles {
+		rowKey := t.RecordKey(handle)
+		rowVal, err := txn.Get(rowKey)
+		if err != nil {
+			if kv.ErrNotExist.Equal(err) {
+				// If row doesn't exist, skip it.
+				continue
+			}
+			return errors.Trace(err)
+		}
+
+		row, err := tablecodec.DecodeRow(rowVal, colMap, time.UTC)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		val, ok := row[oldCol.ID]
+		if !ok || val.IsNull() {
+			continue
+		}
+		if !tablecodec.ElemsIn(newCol.Elems, val) {
+			return errDataTruncated.GenByArgs(newCol.Name.O, handle)
+		}
+		row[oldCol.ID] = tablecodec.ElemsByName(val)
+
+		colIDs := make([]int64, 0, len(row))
+		values := make([]types.Datum, 0, len(row))
+		for colID, v := range row {
+			colIDs = append(colIDs, colID)
+			values = append(values, v)
+		}
+		newRowVal, err := tablecodec.EncodeRowWithVersion(tablecodec.GetRowFormatVersion(rowVal), values, colIDs, time.UTC)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = txn.Set(rowKey, newRowVal); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // doModifyColumn updates the column information and reorders all columns.
 func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
 	tblInfo, err := getTableInfo(t, job, job.SchemaID)
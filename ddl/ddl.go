@@ -37,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/logutil"
 	"github.com/twinj/uuid"
 	goctx "golang.org/x/net/context"
 )
@@ -65,6 +66,12 @@ var (
 	errRunMultiSchemaChanges = terror.ClassDDL.New(codeRunMultiSchemaChanges, "can't run multi schema change")
 	errWaitReorgTimeout      = terror.ClassDDL.New(codeWaitReorgTimeout, "wait for reorganization timeout")
 	errInvalidStoreVer       = terror.ClassDDL.New(codeInvalidStoreVer, "invalid storage current version")
+	errCancelledDDLJob       = terror.ClassDDL.New(codeCancelledDDLJob, "cancelled DDL job")
+	errReorgStopped          = terror.ClassDDL.New(codeReorgStopped, "reorganization is stopped")
+
+	errInvalidPlacementPolicy     = terror.ClassDDL.New(codeInvalidPlacementPolicy, "invalid placement policy: %s")
+	errUnsupportedPlacementPolicy = terror.ClassDDL.New(codeUnsupportedPlacementPolicy, "placement policies are not supported by the storage")
+	errUnsupportedDescIndex       = terror.ClassDDL.New(codeUnsupportedDescIndex, "descending index columns are not supported by the storage")
 
 	// We don't support dropping column with index covered now.
 	errCantDropColWithIndex    = terror.ClassDDL.New(codeCantDropColWithIndex, "can't drop column with index")
@@ -79,7 +86,6 @@ var (
 	errTooLongKey           = terror.ClassDDL.New(codeTooLongKey,
 		fmt.Sprintf("Specified key was too long; max key length is %d bytes", maxPrefixLength))
 	errKeyColumnDoesNotExits = terror.ClassDDL.New(codeKeyColumnDoesNotExits, "this key column doesn't exist in table")
-	errDupKeyName            = terror.ClassDDL.New(codeDupKeyName, "duplicate key name")
 	errUnknownTypeLength     = terror.ClassDDL.New(codeUnknownTypeLength, "Unknown length for type tp %d")
 	errUnknownFractionLength = terror.ClassDDL.New(codeUnknownFractionLength, "Unknown Length for type tp %d and fraction %d")
 	errInvalidJobVersion     = terror.ClassDDL.New(codeInvalidJobVersion, "DDL job with version %d greater than current %d")
@@ -101,6 +107,8 @@ var (
 	errJSONUsedAsKey = terror.ClassDDL.New(codeJSONUsedAsKey, mysql.MySQLErrName[mysql.ErrJSONUsedAsKey])
 	// errBlobCantHaveDefault forbiddens to give not null default value to TEXT/BLOB/JSON.
 	errBlobCantHaveDefault = terror.ClassDDL.New(codeBlobCantHaveDefault, mysql.MySQLErrName[mysql.ErrBlobCantHaveDefault])
+	// errDataTruncated returns for the existing value that isn't in the new elements of the enum or set column.
+	errDataTruncated = terror.ClassDDL.New(codeDataTruncated, "Data truncated for column '%s' at row %d")
 
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
@@ -120,6 +128,8 @@ var (
 	ErrColumnBadNull = terror.ClassDDL.New(codeBadNull, "column cann't be null")
 	// ErrCantRemoveAllFields returns for deleting all columns.
 	ErrCantRemoveAllFields = terror.ClassDDL.New(codeCantRemoveAllFields, "can't delete all columns with ALTER TABLE")
+	// ErrDupKeyName returns for a duplicate index name.
+	ErrDupKeyName = terror.ClassDDL.New(codeDupKeyName, "duplicate key name")
 	// ErrCantDropFieldOrKey returns for dropping a non-existent field or key.
 	ErrCantDropFieldOrKey = terror.ClassDDL.New(codeCantDropFieldOrKey, "can't drop field; check that column/key exists")
 	// ErrInvalidOnUpdate returns for invalid ON UPDATE clause.
@@ -134,6 +144,8 @@ var (
 	ErrWrongColumnName = terror.ClassDDL.New(codeWrongColumnName, mysql.MySQLErrName[mysql.ErrWrongColumnName])
 	// ErrWrongNameForIndex returns for wrong index name.
 	ErrWrongNameForIndex = terror.ClassDDL.New(codeWrongNameForIndex, mysql.MySQLErrName[mysql.ErrWrongNameForIndex])
+	// ErrWrongAutoKey returns for more than one auto increment columns or the auto increment column isn't a key.
+	ErrWrongAutoKey = terror.ClassDDL.New(codeWrongAutoKey, mysql.MySQLErrName[mysql.ErrWrongAutoKey])
 )
 
 // DDL is responsible for updating schema in data store and maintaining in-memory InfoSchema cache.
@@ -151,6 +163,10 @@ type DDL interface {
 	AlterTable(ctx context.Context, tableIdent ast.Ident, spec []*ast.AlterTableSpec) error
 	TruncateTable(ctx context.Context, tableIdent ast.Ident) error
 	RenameTable(ctx context.Context, oldTableIdent, newTableIdent ast.Ident) error
+	// RenameTables renames the tables in order in one schema change.
+	RenameTables(ctx context.Context, oldTableIdents, newTableIdents []ast.Ident) error
+	// PauseWrites pauses or resumes the writes to the table on all the tidb-servers.
+	PauseWrites(ctx context.Context, tableIdent ast.Ident, paused bool) error
 	// SetLease will reset the lease time for online DDL change,
 	// it's a very dangerous function and you must guarantee that all servers have the same lease time.
 	SetLease(ctx goctx.Context, lease time.Duration)
@@ -210,17 +226,21 @@ type ddl struct {
 	// lease is schema seconds.
 	lease        time.Duration
 	uuid         string
-	ddlJobCh     chan struct{}
 	ddlJobDoneCh chan struct{}
 	ddlEventCh   chan<- *Event
+	// workers run the jobs of the job queues in parallel, there is a worker for each queue.
+	workers map[workerType]*worker
 
 	// reorgDoneCh is for reorganization, if the reorganization job is done,
 	// we will use this channel to notify outer.
+	// The reorganization fields are only used by the add index worker, the jobs of the other worker don't reorganize.
 	// TODO: Now we use goroutine to simulate reorganization jobs, later we may
 	// use a persistent job list.
 	reorgDoneCh chan error
 	// reorgRowCount is for reorganization, it uses to simulate a job's row count.
 	reorgRowCount int64
+	// reorgStopped is set to stop the running reorganization when its job is paused or cancelled.
+	reorgStopped int32
 
 	quitCh chan struct{}
 	wait   sync.WaitGroup
@@ -228,6 +248,13 @@ type ddl struct {
 	workerVars *variable.SessionVars
 
 	delRangeManager delRangeManager
+	// ctxPool is used to read the global variables that control the reorganization, it's nil in the tests.
+	ctxPool *pools.ResourcePool
+}
+
+// ddlLogger returns the logger of the DDL module, its level can be set apart from the global log level.
+func ddlLogger() *log.Logger {
+	return logutil.Logger(logutil.ModuleDDL)
 }
 
 // RegisterEventCh registers passed channel for ddl Event.
@@ -252,7 +279,7 @@ func (d *ddl) asyncNotifyEvent(e *Event) {
 			case d.ddlEventCh <- e:
 				return
 			default:
-				log.Warnf("Fail to notify event %s.", e)
+				ddlLogger().Warnf("Fail to notify event %s.", e)
 				time.Sleep(time.Microsecond * 10)
 			}
 		}
@@ -290,25 +317,26 @@ func newDDL(ctx goctx.Context, etcdCli *clientv3.Client, store kv.Storage,
 		store:        store,
 		uuid:         id,
 		lease:        lease,
-		ddlJobCh:     make(chan struct{}, 1),
 		ddlJobDoneCh: make(chan struct{}, 1),
+		workers:      newWorkers(),
 		ownerManager: manager,
 		schemaSyncer: syncer,
 		workerVars:   variable.NewSessionVars(),
+		ctxPool:      ctxPool,
 	}
 	d.workerVars.BinlogClient = binloginfo.GetPumpClient()
 
 	if ctxPool != nil {
 		supportDelRange := store.SupportDeleteRange()
 		d.delRangeManager = newDelRangeManager(d, ctxPool, supportDelRange)
-		log.Infof("[ddl] start delRangeManager OK, with emulator: %t", !supportDelRange)
+		ddlLogger().Infof("[ddl] start delRangeManager OK, with emulator: %t", !supportDelRange)
 	} else {
 		d.delRangeManager = newMockDelRangeManager()
 	}
 
 	d.start(ctx)
 	variable.RegisterStatistics(d)
-	log.Infof("[ddl] start DDL:%s", d.uuid)
+	ddlLogger().Infof("[ddl] start DDL:%s", d.uuid)
 	return d
 }
 
@@ -317,7 +345,7 @@ func (d *ddl) Stop() error {
 	defer d.m.Unlock()
 
 	d.close()
-	log.Infof("stop DDL:%s", d.uuid)
+	ddlLogger().Infof("stop DDL:%s", d.uuid)
 
 	return nil
 }
@@ -326,12 +354,14 @@ func (d *ddl) start(ctx goctx.Context) {
 	d.quitCh = make(chan struct{})
 	d.ownerManager.CampaignOwner(ctx)
 
-	d.wait.Add(1)
-	go d.onDDLWorker()
+	for _, w := range d.workers {
+		d.wait.Add(1)
+		go d.onDDLWorker(w)
 
-	// For every start, we will send a fake job to let worker
-	// check owner firstly and try to find whether a job exists and run.
-	asyncNotify(d.ddlJobCh)
+		// For every start, we will send a fake job to let worker
+		// check owner firstly and try to find whether a job exists and run.
+		asyncNotify(w.ddlJobCh)
+	}
 
 	d.delRangeManager.start()
 }
@@ -344,11 +374,11 @@ func (d *ddl) close() {
 	d.ownerManager.Cancel()
 	err := d.schemaSyncer.RemoveSelfVersionPath()
 	if err != nil {
-		log.Errorf("[ddl] remove self version path failed %v", err)
+		ddlLogger().Errorf("[ddl] remove self version path failed %v", err)
 	}
 	d.wait.Wait()
 	d.delRangeManager.clear()
-	log.Infof("close DDL:%s", d.uuid)
+	ddlLogger().Infof("close DDL:%s", d.uuid)
 }
 
 func (d *ddl) isClosed() bool {
@@ -368,7 +398,7 @@ func (d *ddl) SetLease(ctx goctx.Context, lease time.Duration) {
 		return
 	}
 
-	log.Warnf("[ddl] change schema lease %s -> %s", d.lease, lease)
+	ddlLogger().Warnf("[ddl] change schema lease %s -> %s", d.lease, lease)
 
 	if d.isClosed() {
 		// If already closed, just set lease and return.
@@ -436,8 +466,14 @@ func (d *ddl) doDDLJob(ctx context.Context, job *model.Job) error {
 	}
 
 	// Notice worker that we push a new job and wait the job done.
-	asyncNotify(d.ddlJobCh)
-	log.Infof("[ddl] start DDL job %s, Query:\n%s", job, job.Query)
+	asyncNotify(d.workers[getWorkerType(job.Type)].ddlJobCh)
+	if !d.isOwner() {
+		// The owner is on another server.
+		if err1 := d.schemaSyncer.NotifyJobQueued(goctx.Background(), job.ID); err1 != nil {
+			ddlLogger().Warnf("[ddl] notify DDL job %d queued failed %v", job.ID, err1)
+		}
+	}
+	ddlLogger().Infof("[ddl] start DDL job %s, Query:\n%s", job, job.Query)
 
 	var historyJob *model.Job
 	jobID := job.ID
@@ -459,21 +495,22 @@ func (d *ddl) doDDLJob(ctx context.Context, job *model.Job) error {
 	for {
 		select {
 		case <-d.ddlJobDoneCh:
+		case <-d.schemaSyncer.JobDoneCh():
 		case <-ticker.C:
 		}
 
 		historyJob, err = d.getHistoryDDLJob(jobID)
 		if err != nil {
-			log.Errorf("[ddl] get history DDL job err %v, check again", err)
+			ddlLogger().Errorf("[ddl] get history DDL job err %v, check again", err)
 			continue
 		} else if historyJob == nil {
-			log.Debugf("[ddl] DDL job %d is not in history, maybe not run", jobID)
+			ddlLogger().Debugf("[ddl] DDL job %d is not in history, maybe not run", jobID)
 			continue
 		}
 
 		// If a job is a history job, the state must be JobSynced or JobCancel.
 		if historyJob.IsSynced() {
-			log.Infof("[ddl] DDL job %d is finished", jobID)
+			ddlLogger().Infof("[ddl] DDL job %d is finished", jobID)
 			return nil
 		}
 
@@ -520,6 +557,8 @@ const (
 	codeUnknownTypeLength                    = 9
 	codeUnknownFractionLength                = 10
 	codeInvalidJobVersion                    = 11
+	codeCancelledDDLJob                      = 12
+	codeReorgStopped                         = 13
 
 	codeInvalidDBState         = 100
 	codeInvalidTableState      = 101
@@ -533,6 +572,9 @@ const (
 	codeUnsupportedDropPKHandle     = 204
 	codeUnsupportedCharset          = 205
 	codeUnsupportedModifyPrimaryKey = 206
+	codeInvalidPlacementPolicy      = 207
+	codeUnsupportedPlacementPolicy  = 208
+	codeUnsupportedDescIndex        = 209
 
 	codeFileNotFound                 = 1017
 	codeErrorOnRename                = 1025
@@ -542,6 +584,7 @@ const (
 	codeDupKeyName                   = 1061
 	codeTooLongKey                   = 1071
 	codeKeyColumnDoesNotExits        = 1072
+	codeWrongAutoKey                 = 1075
 	codeIncorrectPrefixKey           = 1089
 	codeCantRemoveAllFields          = 1090
 	codeCantDropFieldOrKey           = 1091
@@ -553,6 +596,7 @@ const (
 	codeWrongColumnName              = 1166
 	codeWrongKeyColumn               = 1167
 	codeBlobKeyWithoutLength         = 1170
+	codeDataTruncated                = 1265
 	codeInvalidOnUpdate              = 1294
 	codeUnsupportedOnGeneratedColumn = 3106
 	codeGeneratedColumnNonPrior      = 3107
@@ -588,6 +632,8 @@ func init() {
 		codeWrongKeyColumn:               mysql.ErrWrongKeyColumn,
 		codeWrongNameForIndex:            mysql.ErrWrongNameForIndex,
 		codeTooManyFields:                mysql.ErrTooManyFields,
+		codeDataTruncated:                mysql.WarnDataTruncated,
+		codeWrongAutoKey:                 mysql.ErrWrongAutoKey,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
@@ -27,10 +27,12 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/types"
@@ -160,11 +162,18 @@ func buildColumnsAndConstraints(ctx context.Context, colDefs []*ast.ColumnDef,
 	constraints []*ast.Constraint) ([]*table.Column, []*ast.Constraint, error) {
 	var cols []*table.Column
 	colMap := map[string]*table.Column{}
+	hasTimestampCol := false
 	for i, colDef := range colDefs {
 		col, cts, err := buildColumnAndConstraint(ctx, i, colDef)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
+		if mysql.HasTimestampFlag(col.Flag) {
+			if hasTimestampCol {
+				removeImplicitTimestampAttrs(col, colDef)
+			}
+			hasTimestampCol = true
+		}
 		col.State = model.StatePublic
 		constraints = append(constraints, cts...)
 		cols = append(cols, col)
@@ -183,7 +192,16 @@ func setCharsetCollationFlenDecimal(tp *types.FieldType) error {
 	if len(tp.Charset) == 0 {
 		switch tp.Tp {
 		case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeEnum, mysql.TypeSet:
-			tp.Charset, tp.Collate = getDefaultCharsetAndCollate()
+			if len(tp.Collate) == 0 {
+				tp.Charset, tp.Collate = getDefaultCharsetAndCollate()
+				break
+			}
+			// Only the collation is specified, use its charset.
+			collation, err := charset.GetCollationByName(tp.Collate)
+			if err != nil {
+				return errUnsupportedCharset.GenByArgs(tp.Charset, tp.Collate)
+			}
+			tp.Charset = collation.CharsetName
 		default:
 			tp.Charset = charset.CharsetBin
 			tp.Collate = charset.CharsetBin
@@ -286,22 +304,15 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 				constraints = append(constraints, constraint)
 				col.Flag |= mysql.UniqueKeyFlag
 			case ast.ColumnOptionDefaultValue:
-				value, err := getDefaultValue(ctx, v, colDef.Tp.Tp, colDef.Tp.Decimal)
-				if err != nil {
-					return nil, nil, ErrColumnBadNull.Gen("invalid default value - %s", err)
-				}
-				if err = checkColumnCantHaveDefaultValue(col, value); err != nil {
+				if err := setDefaultValueWithOption(ctx, col, v); err != nil {
 					return nil, nil, errors.Trace(err)
 				}
-				col.DefaultValue = value
 				hasDefaultValue = true
 				removeOnUpdateNowFlag(col)
 			case ast.ColumnOptionOnUpdate:
-				// TODO: Support other time functions.
-				if !expression.IsCurrentTimeExpr(v.Expr) {
-					return nil, nil, ErrInvalidOnUpdate.Gen("invalid ON UPDATE for - %s", col.Name)
+				if err := checkOnUpdateOption(col, v); err != nil {
+					return nil, nil, errors.Trace(err)
 				}
-				col.Flag |= mysql.OnUpdateNowFlag
 				setOnUpdateNow = true
 			case ast.ColumnOptionComment:
 				err := setColumnComment(ctx, col, v)
@@ -319,6 +330,11 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 		}
 	}
 
+	// The explicit ON UPDATE is kept whatever the order of the options is,
+	// only the implicit one is removed by DEFAULT or NULL.
+	if setOnUpdateNow {
+		col.Flag |= mysql.OnUpdateNowFlag
+	}
 	setTimestampDefaultValue(col, hasDefaultValue, setOnUpdateNow)
 
 	// Set `NoDefaultValueFlag` if this field doesn't have a default value and
@@ -340,6 +356,35 @@ func columnDefToCol(ctx context.Context, offset int, colDef *ast.ColumnDef) (*ta
 	return col, constraints, nil
 }
 
+// setDefaultValueWithOption sets the default value of the column from the DEFAULT option.
+// The expression of DEFAULT (expr) is kept as its text, and it's evaluated for each row.
+func setDefaultValueWithOption(ctx context.Context, col *table.Column, option *ast.ColumnOption) error {
+	if option.DefaultIsExpr {
+		col.DefaultValue = option.Expr.Text()
+		col.DefaultIsExpr = true
+		return nil
+	}
+	value, err := getDefaultValue(ctx, option, col.Tp, col.Decimal)
+	if err != nil {
+		return ErrColumnBadNull.Gen("invalid default value - %s", err)
+	}
+	if err = checkColumnCantHaveDefaultValue(col, value); err != nil {
+		return errors.Trace(err)
+	}
+	col.DefaultValue = value
+	col.DefaultIsExpr = false
+	return nil
+}
+
+// checkOnUpdateOption checks the ON UPDATE option, which is only for TIMESTAMP and DATETIME columns.
+func checkOnUpdateOption(col *table.Column, option *ast.ColumnOption) error {
+	// TODO: Support other time functions.
+	if !expression.IsCurrentTimeExpr(option.Expr) || (col.Tp != mysql.TypeTimestamp && col.Tp != mysql.TypeDatetime) {
+		return ErrInvalidOnUpdate.Gen("invalid ON UPDATE for - %s", col.Name)
+	}
+	return nil
+}
+
 func getDefaultValue(ctx context.Context, c *ast.ColumnOption, tp byte, fsp int) (interface{}, error) {
 	if tp == mysql.TypeTimestamp || tp == mysql.TypeDatetime {
 		vd, err := expression.GetTimeValue(ctx, c.Expr, tp, fsp)
@@ -415,6 +460,20 @@ func setTimestampDefaultValue(c *table.Column, hasDefaultValue bool, setOnUpdate
 	}
 }
 
+// removeImplicitTimestampAttrs removes the DEFAULT CURRENT_TIMESTAMP and ON UPDATE CURRENT_TIMESTAMP given to
+// a TIMESTAMP column without NULL, DEFAULT or ON UPDATE. Like MySQL, only the first TIMESTAMP column in the table
+// gets them, the others get the zero timestamp as their default value.
+func removeImplicitTimestampAttrs(col *table.Column, colDef *ast.ColumnDef) {
+	for _, opt := range colDef.Options {
+		switch opt.Tp {
+		case ast.ColumnOptionNull, ast.ColumnOptionDefaultValue, ast.ColumnOptionOnUpdate:
+			return
+		}
+	}
+	col.Flag &= ^uint(mysql.OnUpdateNowFlag)
+	col.DefaultValue = expression.ZeroTimestamp
+}
+
 func setNoDefaultValueFlag(c *table.Column, hasDefaultValue bool) {
 	if hasDefaultValue {
 		return
@@ -514,7 +573,7 @@ func checkDuplicateConstraint(namesMap map[string]bool, name string, foreign boo
 		if foreign {
 			return infoschema.ErrCannotAddForeign
 		}
-		return errDupKeyName.Gen("duplicate key name %s", name)
+		return ErrDupKeyName.Gen("duplicate key name %s", name)
 	}
 	namesMap[nameLower] = true
 	return nil
@@ -642,6 +701,9 @@ func (d *ddl) buildTableInfo(tableName model.CIStr, cols []*table.Column, constr
 			}
 		}
 		// build index info.
+		if err = d.checkDescIndexSupported(constr.Keys); err != nil {
+			return nil, errors.Trace(err)
+		}
 		idxInfo, err := buildIndexInfo(tbInfo, model.NewCIStr(constr.Name), constr.Keys, model.StatePublic)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -692,10 +754,12 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 		return infoschema.ErrTableExists.GenByArgs(ident)
 	}
 
-	tblInfo := *referTbl.Meta()
+	if err = checkTooLongTable(ident.Name); err != nil {
+		return errors.Trace(err)
+	}
+
+	tblInfo := buildTableInfoWithLike(referTbl.Meta())
 	tblInfo.Name = ident.Name
-	tblInfo.AutoIncID = 0
-	tblInfo.ForeignKeys = nil
 	tblInfo.ID, err = d.genGlobalID()
 	if err != nil {
 		return errors.Trace(err)
@@ -713,6 +777,45 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	return errors.Trace(err)
 }
 
+// buildTableInfoWithLike copies the public columns and indices of the refer table, and the indices on the columns that
+// are being dropped are left out. The auto increment ID and the foreign keys aren't copied, like MySQL.
+func buildTableInfoWithLike(referTblInfo *model.TableInfo) *model.TableInfo {
+	tblInfo := referTblInfo.Clone()
+	tblInfo.AutoIncID = 0
+	tblInfo.ForeignKeys = nil
+
+	cols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.State != model.StatePublic {
+			continue
+		}
+		col.Offset = len(cols)
+		cols = append(cols, col)
+	}
+	tblInfo.Columns = cols
+
+	indices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+	for _, idx := range tblInfo.Indices {
+		if idx.State != model.StatePublic {
+			continue
+		}
+		public := true
+		for _, idxCol := range idx.Columns {
+			col := findCol(cols, idxCol.Name.L)
+			if col == nil {
+				public = false
+				break
+			}
+			idxCol.Offset = col.Offset
+		}
+		if public {
+			indices = append(indices, idx)
+		}
+	}
+	tblInfo.Indices = indices
+	return tblInfo
+}
+
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
 	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
 	is := d.GetInformationSchema()
@@ -762,7 +865,12 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 		Args:       []interface{}{tbInfo},
 	}
 
-	handleTableOptions(options, tbInfo)
+	if err = handleTableOptions(options, tbInfo); err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := d.store.(kv.PlacementStore); !ok && tbInfo.PlacementPolicy != nil {
+		return errors.Trace(errUnsupportedPlacementPolicy)
+	}
 	err = d.doDDLJob(ctx, job)
 	if err == nil {
 		if tbInfo.AutoIncID > 1 {
@@ -798,34 +906,89 @@ func (d *ddl) handleAutoIncID(tbInfo *model.TableInfo, schemaID int64) error {
 }
 
 // handleTableOptions updates tableInfo according to table options.
-func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
+func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) error {
+	engineOptions := make(map[string]string)
 	for _, op := range options {
 		switch op.Tp {
 		case ast.TableOptionAutoIncrement:
 			tbInfo.AutoIncID = int64(op.UintValue)
+		case ast.TableOptionAutoIDCache:
+			tbInfo.AutoIDCache = int64(op.UintValue)
 		case ast.TableOptionComment:
 			tbInfo.Comment = op.StrValue
 		case ast.TableOptionCharset:
 			tbInfo.Charset = op.StrValue
 		case ast.TableOptionCollate:
 			tbInfo.Collate = op.StrValue
+		case ast.TableOptionEngine:
+			// Engines that are not registered, like InnoDB, keep the rows in TiDB.
+			if e, ok := engine.Lookup(op.StrValue); ok {
+				tbInfo.Engine = e.Name()
+			}
+		case ast.TableOptionConnection:
+			engineOptions["connection"] = op.StrValue
+		case ast.TableOptionLocation:
+			engineOptions["location"] = op.StrValue
+		case ast.TableOptionFormat:
+			engineOptions["format"] = op.StrValue
+		case ast.TableOptionPlacementPolicy:
+			policy, err := buildPlacementPolicy(op.PlacementOptions)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			tbInfo.PlacementPolicy = policy
 		}
 	}
+	if !engine.IsExternal(tbInfo) {
+		return nil
+	}
+	if len(engineOptions) > 0 {
+		tbInfo.EngineOptions = engineOptions
+	}
+	return errors.Trace(checkEngineOptions(tbInfo))
+}
+
+// checkEngineOptions checks whether the external engine of the table accepts its options.
+func checkEngineOptions(tbInfo *model.TableInfo) error {
+	e, _ := engine.Lookup(tbInfo.Engine)
+	_, err := e.Open(tbInfo)
+	return errors.Trace(err)
 }
 
 func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.AlterTableSpec) (err error) {
+	var tblInfo *model.TableInfo
+	if t, err1 := d.GetInformationSchema().TableByName(ident.Schema, ident.Name); err1 == nil {
+		tblInfo = t.Meta()
+	}
 	// Only handle valid specs, AlterTableLock is ignored.
 	validSpecs := make([]*ast.AlterTableSpec, 0, len(specs))
+	skipped := false
 	for _, spec := range specs {
 		if spec.Tp == ast.AlterTableLock {
 			continue
 		}
+		if tblInfo != nil {
+			if warn := checkAlterSpecExistence(tblInfo, spec); warn != nil {
+				ctx.GetSessionVars().StmtCtx.AppendWarning(warn)
+				skipped = true
+				continue
+			}
+		}
 		validSpecs = append(validSpecs, spec)
 	}
+	if skipped && len(validSpecs) == 0 {
+		return nil
+	}
 
+	if len(validSpecs) > 1 {
+		// Only adding and dropping columns and indices can run in one schema change.
+		if !isMultiSchemaChangeSpecs(validSpecs) {
+			return errRunMultiSchemaChanges
+		}
+		return errors.Trace(d.multiSchemaChange(ctx, ident, validSpecs))
+	}
 	if len(validSpecs) != 1 {
 		// TODO: Hanlde len(validSpecs) == 0.
-		// Now we only allow one schema changing at the same time.
 		return errRunMultiSchemaChanges
 	}
 
@@ -864,6 +1027,8 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			err = d.RenameTable(ctx, ident, newIdent)
 		case ast.AlterTableDropPrimaryKey:
 			err = ErrUnsupportedModifyPrimaryKey.GenByArgs("drop")
+		case ast.AlterTablePlacementPolicy:
+			err = d.AlterPlacementPolicy(ctx, ident, spec.PlacementOptions)
 		default:
 			// Nothing to do now.
 		}
@@ -876,25 +1041,49 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 	return nil
 }
 
-func checkColumnConstraint(constraints []*ast.ColumnOption) error {
-	for _, constraint := range constraints {
-		switch constraint.Tp {
-		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey:
-			return errUnsupportedAddColumn.Gen("unsupported add column constraint - %v", constraint.Tp)
+// checkAlterSpecExistence returns the error of the spec with IF [NOT] EXISTS that has nothing to do, that is,
+// the added column exists, or the dropped column or index doesn't exist. The spec is skipped with the error as a warning.
+func checkAlterSpecExistence(tblInfo *model.TableInfo, spec *ast.AlterTableSpec) error {
+	switch spec.Tp {
+	case ast.AlterTableAddColumn:
+		colName := spec.NewColumn.Name.Name
+		if spec.IfNotExists && findCol(tblInfo.Columns, colName.L) != nil {
+			return infoschema.ErrColumnExists.GenByArgs(colName)
+		}
+	case ast.AlterTableDropColumn:
+		colName := spec.OldColumnName.Name
+		if spec.IfExists && findCol(tblInfo.Columns, colName.L) == nil {
+			return ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
+		}
+	case ast.AlterTableDropIndex:
+		indexName := model.NewCIStr(spec.Name)
+		if spec.IfExists && findIndexByName(indexName.L, tblInfo.Indices) == nil {
+			return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
 		}
 	}
-
 	return nil
 }
 
-// AddColumn will add a new column to the table.
-func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
-	// Check whether the added column constraints are supported.
-	err := checkColumnConstraint(spec.NewColumn.Options)
-	if err != nil {
-		return errors.Trace(err)
+func isMultiSchemaChangeSpecs(specs []*ast.AlterTableSpec) bool {
+	for _, spec := range specs {
+		switch spec.Tp {
+		case ast.AlterTableAddColumn, ast.AlterTableDropColumn, ast.AlterTableDropIndex:
+		case ast.AlterTableAddConstraint:
+			switch spec.Constraint.Tp {
+			case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqIndex, ast.ConstraintUniqKey:
+			default:
+				return false
+			}
+		default:
+			return false
+		}
 	}
+	return true
+}
 
+// multiSchemaChange adds and drops the columns and indices of the specs in one job.
+// The specs are checked against the table, so the conflicts between them are found here.
+func (d *ddl) multiSchemaChange(ctx context.Context, ti ast.Ident, specs []*ast.AlterTableSpec) error {
 	is := d.infoHandle.Get()
 	schema, ok := is.SchemaByName(ti.Schema)
 	if !ok {
@@ -905,11 +1094,120 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
+	addedCols := make(map[string]struct{})
+	droppedCols := make(map[string]struct{})
+	addedIndices := make(map[string]struct{})
+	droppedIndices := make(map[string]struct{})
+	subJobs := make([]*model.SubJob, 0, len(specs))
+	for _, spec := range specs {
+		var sub *model.SubJob
+		switch spec.Tp {
+		case ast.AlterTableAddColumn:
+			if err = checkColumnConstraint(spec.NewColumn.Options); err != nil {
+				return errors.Trace(err)
+			}
+			var col *table.Column
+			col, err = buildAddedColumn(ctx, t, spec)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if _, ok = addedCols[col.Name.L]; ok {
+				return infoschema.ErrColumnExists.GenByArgs(col.Name)
+			}
+			addedCols[col.Name.L] = struct{}{}
+			sub, err = model.NewSubJob(model.ActionAddColumn, col, spec.Position, 0)
+		case ast.AlterTableDropColumn:
+			colName := spec.OldColumnName.Name
+			if _, ok = droppedCols[colName.L]; ok {
+				return ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
+			}
+			if err = checkDropColumn(t, colName); err != nil {
+				return errors.Trace(err)
+			}
+			droppedCols[colName.L] = struct{}{}
+			sub, err = model.NewSubJob(model.ActionDropColumn, colName)
+		case ast.AlterTableDropIndex:
+			indexName := model.NewCIStr(spec.Name)
+			if _, ok = droppedIndices[indexName.L]; ok || findIndexByName(indexName.L, t.Meta().Indices) == nil {
+				return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", indexName)
+			}
+			droppedIndices[indexName.L] = struct{}{}
+			sub, err = model.NewSubJob(model.ActionDropIndex, indexName)
+		case ast.AlterTableAddConstraint:
+			constr := spec.Constraint
+			unique := constr.Tp == ast.ConstraintUniq || constr.Tp == ast.ConstraintUniqIndex ||
+				constr.Tp == ast.ConstraintUniqKey
+			indexName := model.NewCIStr(constr.Name)
+			if len(indexName.L) == 0 {
+				indexName = getAnonymousIndex(t, constr.Keys[0].Column.Name)
+			}
+			_, ok = addedIndices[indexName.L]
+			if ok || findIndexByName(indexName.L, t.Meta().Indices) != nil {
+				return ErrDupKeyName.Gen("index already exist %s", indexName)
+			}
+			addedIndices[indexName.L] = struct{}{}
+			sub, err = model.NewSubJob(model.ActionAddIndex, unique, indexName, constr.Keys, constr.Option)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if sub.Type == model.ActionDropColumn || sub.Type == model.ActionDropIndex {
+			// The dropped columns and indices are public until the added ones are public.
+			sub.SchemaState = model.StatePublic
+		}
+		subJobs = append(subJobs, sub)
+	}
+
+	if len(t.Cols())+len(addedCols) == len(droppedCols) {
+		return ErrCantRemoveAllFields.Gen("can't drop all columns in table %s", t.Meta().Name)
+	}
+	for _, spec := range specs {
+		if spec.Tp != ast.AlterTableAddConstraint {
+			continue
+		}
+		for _, key := range spec.Constraint.Keys {
+			colName := key.Column.Name
+			if _, ok = droppedCols[colName.L]; ok {
+				return errKeyColumnDoesNotExits.Gen("column does not exist: %s", colName)
+			}
+			// The index is backfilled with the values of the public columns.
+			if _, ok = addedCols[colName.L]; ok {
+				return errRunMultiSchemaChanges.Gen("can't add index on column %s added in the same statement", colName)
+			}
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionMultiSchemaChange,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{subJobs},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+func checkColumnConstraint(constraints []*ast.ColumnOption) error {
+	for _, constraint := range constraints {
+		switch constraint.Tp {
+		case ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey:
+			return errUnsupportedAddColumn.Gen("unsupported add column constraint - %v", constraint.Tp)
+		}
+	}
+
+	return nil
+}
+
+// buildAddedColumn checks the added column of the spec and builds it.
+func buildAddedColumn(ctx context.Context, t table.Table, spec *ast.AlterTableSpec) (*table.Column, error) {
 	// Check whether added column has existed.
 	colName := spec.NewColumn.Name.Name.O
 	col := table.FindCol(t.Cols(), colName)
 	if col != nil {
-		return infoschema.ErrColumnExists.GenByArgs(colName)
+		return nil, infoschema.ErrColumnExists.GenByArgs(colName)
 	}
 
 	// If new column is a generated column, do validation.
@@ -923,29 +1221,52 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 				referableColNames[col.Name.L] = struct{}{}
 			}
 			_, dependColNames := findDependedColumnNames(spec.NewColumn)
-			if err = columnNamesCover(referableColNames, dependColNames); err != nil {
-				return errors.Trace(err)
+			if err := columnNamesCover(referableColNames, dependColNames); err != nil {
+				return nil, errors.Trace(err)
 			}
 		}
 	}
 
 	if len(colName) > mysql.MaxColumnNameLength {
-		return ErrTooLongIdent.Gen("too long column %s", colName)
+		return nil, ErrTooLongIdent.Gen("too long column %s", colName)
 	}
 
 	// Ingore table constraints now, maybe return error later.
 	// We use length(t.Cols()) as the default offset firstly, we will change the
 	// column's offset later.
-	col, _, err = buildColumnAndConstraint(ctx, len(t.Cols()), spec.NewColumn)
+	col, _, err := buildColumnAndConstraint(ctx, len(t.Cols()), spec.NewColumn)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
+	}
+	if mysql.HasTimestampFlag(col.Flag) {
+		for _, c := range t.Cols() {
+			if mysql.HasTimestampFlag(c.Flag) {
+				removeImplicitTimestampAttrs(col, spec.NewColumn)
+				break
+			}
+		}
 	}
 	col.OriginDefaultValue = col.DefaultValue
+	if col.DefaultIsExpr {
+		// The rows in the table get the value of the expression when the column is added.
+		var value types.Datum
+		value, err = table.GetColDefaultValue(ctx, col.ToInfo())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		col.OriginDefaultValue = nil
+		if !value.IsNull() {
+			col.OriginDefaultValue, err = value.ToString()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+	}
 	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
 		zeroVal := table.GetZeroValue(col.ToInfo())
 		col.OriginDefaultValue, err = zeroVal.ToString()
 		if err != nil {
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
 		}
 	}
 
@@ -953,6 +1274,31 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 		(col.Tp == mysql.TypeTimestamp || col.Tp == mysql.TypeDatetime) {
 		col.OriginDefaultValue = time.Now().Format(types.TimeFormat)
 	}
+	return col, nil
+}
+
+// AddColumn will add a new column to the table.
+func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
+	// Check whether the added column constraints are supported.
+	err := checkColumnConstraint(spec.NewColumn.Options)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return errors.Trace(infoschema.ErrDatabaseNotExists)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
+	}
+
+	col, err := buildAddedColumn(ctx, t, spec)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -967,6 +1313,25 @@ func (d *ddl) AddColumn(ctx context.Context, ti ast.Ident, spec *ast.AlterTableS
 	return errors.Trace(err)
 }
 
+// checkDropColumn checks whether the column can be dropped from the table.
+func checkDropColumn(t table.Table, colName model.CIStr) error {
+	// Check whether dropped column has existed.
+	col := table.FindCol(t.Cols(), colName.L)
+	if col == nil {
+		return ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
+	}
+
+	tblInfo := t.Meta()
+	if err := isDroppableColumn(tblInfo, colName); err != nil {
+		return errors.Trace(err)
+	}
+	// We don't support dropping column with PK handle covered now.
+	if col.IsPKHandleColumn(tblInfo) {
+		return errUnsupportedPKHandle
+	}
+	return nil
+}
+
 // DropColumn will drop a column from the table, now we don't support drop the column with index covered.
 func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr) error {
 	is := d.infoHandle.Get()
@@ -979,20 +1344,9 @@ func (d *ddl) DropColumn(ctx context.Context, ti ast.Ident, colName model.CIStr)
 		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name))
 	}
 
-	// Check whether dropped column has existed.
-	col := table.FindCol(t.Cols(), colName.L)
-	if col == nil {
-		return ErrCantDropFieldOrKey.Gen("column %s doesn't exist", colName)
-	}
-
-	tblInfo := t.Meta()
-	if err = isDroppableColumn(tblInfo, colName); err != nil {
+	if err = checkDropColumn(t, colName); err != nil {
 		return errors.Trace(err)
 	}
-	// We don't support dropping column with PK handle covered now.
-	if col.IsPKHandleColumn(tblInfo) {
-		return errUnsupportedPKHandle
-	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -1047,8 +1401,6 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 		case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
 			return nil
 		}
-	case mysql.TypeEnum:
-		return errUnsupportedModifyColumn.GenByArgs("modify enum column is not supported")
 	default:
 		if origin.Tp == to.Tp {
 			return nil
@@ -1059,11 +1411,9 @@ func modifiable(origin *types.FieldType, to *types.FieldType) error {
 }
 
 func setDefaultValue(ctx context.Context, col *table.Column, option *ast.ColumnOption) error {
-	value, err := getDefaultValue(ctx, option, col.Tp, col.Decimal)
-	if err != nil {
-		return ErrColumnBadNull.Gen("invalid default value - %s", err)
+	if err := setDefaultValueWithOption(ctx, col, option); err != nil {
+		return errors.Trace(err)
 	}
-	col.DefaultValue = value
 	return errors.Trace(checkDefaultValue(ctx, col, true))
 }
 
@@ -1085,14 +1435,9 @@ func setDefaultAndComment(ctx context.Context, col *table.Column, options []*ast
 	for _, opt := range options {
 		switch opt.Tp {
 		case ast.ColumnOptionDefaultValue:
-			value, err := getDefaultValue(ctx, opt, col.Tp, col.Decimal)
-			if err != nil {
-				return ErrColumnBadNull.Gen("invalid default value - %s", err)
-			}
-			if err = checkColumnCantHaveDefaultValue(col, value); err != nil {
+			if err := setDefaultValueWithOption(ctx, col, opt); err != nil {
 				return errors.Trace(err)
 			}
-			col.DefaultValue = value
 			hasDefaultValue = true
 		case ast.ColumnOptionComment:
 			err := setColumnComment(ctx, col, opt)
@@ -1108,9 +1453,8 @@ func setDefaultAndComment(ctx context.Context, col *table.Column, options []*ast
 		case ast.ColumnOptionPrimaryKey, ast.ColumnOptionUniqKey:
 			return errUnsupportedModifyColumn.Gen("unsupported modify column constraint - %v", opt.Tp)
 		case ast.ColumnOptionOnUpdate:
-			// TODO: Support other time functions.
-			if !expression.IsCurrentTimeExpr(opt.Expr) {
-				return ErrInvalidOnUpdate.Gen("invalid ON UPDATE for - %s", col.Name)
+			if err := checkOnUpdateOption(col, opt); err != nil {
+				return errors.Trace(err)
 			}
 			col.Flag |= mysql.OnUpdateNowFlag
 			setOnUpdateNow = true
@@ -1171,6 +1515,7 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		OriginDefaultValue: col.OriginDefaultValue,
 		FieldType:          *spec.NewColumn.Tp,
 		Name:               spec.NewColumn.Name.Name,
+		ElemsByName:        col.ElemsByName,
 	})
 
 	err = setCharsetCollationFlenDecimal(&newCol.FieldType)
@@ -1181,6 +1526,10 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	rewriteElems, err := checkModifyElems(t.Meta(), col, newCol)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	if err = setDefaultAndComment(ctx, newCol, spec.NewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -1214,9 +1563,44 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		BinlogInfo: &model.HistoryInfo{},
 		Args:       []interface{}{&newCol, originalColName, spec.Position},
 	}
+	if rewriteElems {
+		// The existing values are checked and rewritten by the reorganization, they're stored by names which
+		// the coprocessor must decode.
+		client := d.store.GetClient()
+		if client == nil || !client.IsRequestTypeSupported(kv.ReqTypeDAG, kv.ReqSubTypeElemsByName) {
+			return nil, errUnsupportedModifyColumn.GenByArgs("elements, the coprocessor of the store can't decode the values stored by names")
+		}
+		job.Type = model.ActionModifyColumnElems
+	}
 	return job, nil
 }
 
+// checkModifyElems checks the new elements of the enum or set column, it returns true if the existing
+// values need to be rewritten. Appending elements keeps the stored values, reordering or removing
+// elements changes them, so the values are checked against the new elements and stored by their names.
+func checkModifyElems(tblInfo *model.TableInfo, origin, to *table.Column) (bool, error) {
+	if origin.Tp != mysql.TypeEnum && origin.Tp != mysql.TypeSet {
+		return false, nil
+	}
+	if len(to.Elems) >= len(origin.Elems) {
+		appended := true
+		for i, elem := range origin.Elems {
+			if elem != to.Elems[i] {
+				appended = false
+				break
+			}
+		}
+		if appended {
+			return false, nil
+		}
+	}
+	// The index values are encoded by the stored values, they can't be rewritten in place.
+	if isColumnWithIndex(origin.Name.L, tblInfo.Indices) {
+		return false, errUnsupportedModifyColumn.GenByArgs("reorder or remove the elements of an indexed column")
+	}
+	return true, nil
+}
+
 // ChangeColumn renames an existing column and modifies the column's definition,
 // currently we only support limited kind of changes
 // that do not need to change or check data on the table.
@@ -1287,6 +1671,7 @@ func (d *ddl) AlterColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterT
 	col.Flag &= ^uint(mysql.NoDefaultValueFlag)
 	if len(spec.NewColumn.Options) == 0 {
 		col.DefaultValue = nil
+		col.DefaultIsExpr = false
 		setNoDefaultValueFlag(col, false)
 	} else {
 		err = setDefaultValue(ctx, col, spec.NewColumn.Options[0])
@@ -1390,6 +1775,125 @@ func (d *ddl) RenameTable(ctx context.Context, oldIdent, newIdent ast.Ident) err
 	return errors.Trace(err)
 }
 
+// RenameTables renames the tables in order, so a name can be reused by a later pair,
+// like swapping two tables through a temporary name.
+func (d *ddl) RenameTables(ctx context.Context, oldIdents, newIdents []ast.Ident) error {
+	is := d.GetInformationSchema()
+	// renamed records the table names changed by the former pairs, the table ID is 0 if the table is renamed away.
+	renamed := make(map[string]int64)
+	tableKey := func(ident ast.Ident) string {
+		return ident.Schema.L + "." + ident.Name.L
+	}
+	oldSchemaIDs := make([]int64, 0, len(oldIdents))
+	newSchemaIDs := make([]int64, 0, len(oldIdents))
+	tableIDs := make([]int64, 0, len(oldIdents))
+	tableNames := make([]model.CIStr, 0, len(oldIdents))
+	for i, oldIdent := range oldIdents {
+		newIdent := newIdents[i]
+		oldSchema, ok := is.SchemaByName(oldIdent.Schema)
+		if !ok {
+			return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+		}
+		tableID, ok := renamed[tableKey(oldIdent)]
+		if !ok {
+			oldTbl, err := is.TableByName(oldIdent.Schema, oldIdent.Name)
+			if err != nil {
+				return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+			}
+			tableID = oldTbl.Meta().ID
+		}
+		if tableID == 0 {
+			return errFileNotFound.GenByArgs(oldIdent.Schema, oldIdent.Name)
+		}
+		newSchema, ok := is.SchemaByName(newIdent.Schema)
+		if !ok {
+			return errErrorOnRename.GenByArgs(oldIdent.Schema, oldIdent.Name, newIdent.Schema, newIdent.Name)
+		}
+		id, ok := renamed[tableKey(newIdent)]
+		if (ok && id != 0) || (!ok && is.TableExists(newIdent.Schema, newIdent.Name)) {
+			return infoschema.ErrTableExists.GenByArgs(newIdent)
+		}
+		renamed[tableKey(oldIdent)] = 0
+		renamed[tableKey(newIdent)] = tableID
+
+		oldSchemaIDs = append(oldSchemaIDs, oldSchema.ID)
+		newSchemaIDs = append(newSchemaIDs, newSchema.ID)
+		tableIDs = append(tableIDs, tableID)
+		tableNames = append(tableNames, newIdent.Name)
+	}
+
+	job := &model.Job{
+		SchemaID:   newSchemaIDs[0],
+		TableID:    tableIDs[0],
+		Type:       model.ActionRenameTables,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{oldSchemaIDs, newSchemaIDs, tableIDs, tableNames},
+	}
+
+	err := d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// PauseWrites pauses or resumes the writes to the table, the DML statements on a paused table fail
+// after the schema change is loaded by the tidb-servers.
+func (d *ddl) PauseWrites(ctx context.Context, ident ast.Ident, paused bool) error {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionPauseWrites,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{paused},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// AlterPlacementPolicy sets the placement policy of the table, the policy is translated into the placement rules
+// of the storage. The policy is removed if options is empty.
+func (d *ddl) AlterPlacementPolicy(ctx context.Context, ident ast.Ident, options []*ast.PlacementOption) error {
+	policy, err := buildPlacementPolicy(options)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := d.store.(kv.PlacementStore); !ok {
+		return errors.Trace(errUnsupportedPlacementPolicy)
+	}
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists.GenByArgs(ident.Schema, ident.Name))
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionAlterPlacementPolicy,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{policy},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func getAnonymousIndex(t table.Table, colName model.CIStr) model.CIStr {
 	id := 2
 	l := len(t.Indices())
@@ -1404,6 +1908,22 @@ func getAnonymousIndex(t table.Table, colName model.CIStr) model.CIStr {
 	return indexName
 }
 
+// checkDescIndexSupported returns an error if an index column is descending, but the coprocessor of the store can't
+// decode the descending keys.
+func (d *ddl) checkDescIndexSupported(idxColNames []*ast.IndexColName) error {
+	for _, ic := range idxColNames {
+		if !ic.Desc {
+			continue
+		}
+		client := d.store.GetClient()
+		if client == nil || !client.IsRequestTypeSupported(kv.ReqTypeDAG, kv.ReqSubTypeDescKey) {
+			return errUnsupportedDescIndex
+		}
+		return nil
+	}
+	return nil
+}
+
 func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexName model.CIStr,
 	idxColNames []*ast.IndexColName, indexOption *ast.IndexOption) error {
 	is := d.infoHandle.Get()
@@ -1422,7 +1942,10 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	}
 
 	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo != nil {
-		return errDupKeyName.Gen("index already exist %s", indexName)
+		return ErrDupKeyName.Gen("index already exist %s", indexName)
+	}
+	if err = d.checkDescIndexSupported(idxColNames); err != nil {
+		return errors.Trace(err)
 	}
 
 	job := &model.Job{
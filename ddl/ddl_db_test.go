@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
@@ -34,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	tmysql "github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -198,6 +200,143 @@ func (s *testDBSuite) TestAddIndexWithPK(c *C) {
 	s.tk.MustQuery("select * from test_add_index_with_pk2").Check(testkit.Rows("1 1 1 1", "2 2 2 2"))
 }
 
+func (s *testDBSuite) TestAddIndexWithReorgVars(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	defer s.tk.MustExec(fmt.Sprintf("set @@global.%s = %d", variable.TiDBDDLReorgWorkerCount, variable.DefDDLReorgWorkerCount))
+	defer s.tk.MustExec(fmt.Sprintf("set @@global.%s = %d", variable.TiDBDDLReorgBatchSize, variable.DefDDLReorgBatchSize))
+
+	_, err := s.tk.Exec(fmt.Sprintf("set @@global.%s = 0", variable.TiDBDDLReorgWorkerCount))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	_, err = s.tk.Exec(fmt.Sprintf("set @@global.%s = 1", variable.TiDBDDLReorgBatchSize))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	_, err = s.tk.Exec(fmt.Sprintf("set @@session.%s = 2", variable.TiDBDDLReorgWorkerCount))
+	c.Assert(err, NotNil)
+
+	s.tk.MustExec(fmt.Sprintf("set @@global.%s = 3", variable.TiDBDDLReorgWorkerCount))
+	s.tk.MustExec(fmt.Sprintf("set @@global.%s = 32", variable.TiDBDDLReorgBatchSize))
+	s.tk.MustExec("create table test_add_index_reorg_vars(a int, b int)")
+	count := 200
+	for i := 0; i < count; i++ {
+		s.tk.MustExec("insert into test_add_index_reorg_vars values(?, ?)", i, i)
+	}
+	s.tk.MustExec("alter table test_add_index_reorg_vars add index idx_b(b)")
+	s.tk.MustQuery("select count(b) from test_add_index_reorg_vars use index(idx_b)").Check(testkit.Rows(strconv.Itoa(count)))
+	s.tk.MustExec("admin check table test_add_index_reorg_vars")
+}
+
+func (s *testDBSuite) TestMultiSchemaChange(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+
+	s.tk.MustExec("create table test_multi_schema_change(a int, b int, c int, index idx_b(b))")
+	s.tk.MustExec("insert into test_multi_schema_change values(1, 1, 1),(2, 1, 2)")
+	s.tk.MustExec("alter table test_multi_schema_change add column d int default 5, add index idx_a(a), drop column c")
+	s.tk.MustQuery("select * from test_multi_schema_change").Check(testkit.Rows("1 1 5", "2 1 5"))
+	s.tk.MustQuery("select a from test_multi_schema_change use index(idx_a) where a = 2").Check(testkit.Rows("2"))
+	s.tk.MustExec("admin check table test_multi_schema_change")
+
+	// The added column and index are removed if the unique index meets duplicate keys.
+	sql := "alter table test_multi_schema_change add column e int, add unique index idx_ub(b), drop index idx_b"
+	s.testErrorCode(c, sql, tmysql.ErrDupEntry)
+	s.tk.MustQuery("select * from test_multi_schema_change").Check(testkit.Rows("1 1 5", "2 1 5"))
+	s.tk.MustExec("admin check table test_multi_schema_change")
+	s.tk.MustExec("alter table test_multi_schema_change add column e int, drop index idx_b")
+	s.tk.MustQuery("select * from test_multi_schema_change").Check(testkit.Rows("1 1 5 <nil>", "2 1 5 <nil>"))
+
+	sql = "alter table test_multi_schema_change add column f int, add index idx_d(d), drop column d"
+	s.testErrorCode(c, sql, tmysql.ErrKeyColumnDoesNotExits)
+	sql = "alter table test_multi_schema_change add index idx_a(b), drop column e"
+	s.testErrorCode(c, sql, tmysql.ErrDupKeyName)
+	sql = "alter table test_multi_schema_change add column f int, add index idx_f(f)"
+	_, err := s.tk.Exec(sql)
+	c.Assert(err, NotNil)
+	sql = "alter table test_multi_schema_change add column f int, rename to t_multi"
+	_, err = s.tk.Exec(sql)
+	c.Assert(err, NotNil)
+}
+
+func (s *testDBSuite) TestAdminDDLJobs(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("create table test_admin_ddl_jobs(a int, b int)")
+	for i := 0; i < 10; i++ {
+		s.tk.MustExec(fmt.Sprintf("insert into test_admin_ddl_jobs values(%d, %d)", i, i))
+	}
+
+	adminSe, err := tidb.CreateSession(s.store)
+	c.Assert(err, IsNil)
+	defer adminSe.Close()
+	adminExec := func(sql string) (string, error) {
+		rs, err1 := adminSe.Execute(sql)
+		if err1 != nil {
+			return "", errors.Trace(err1)
+		}
+		rows, err1 := tidb.GetRows(rs[0])
+		if err1 != nil {
+			return "", errors.Trace(err1)
+		}
+		return rows[0][1].GetString(), nil
+	}
+
+	// Cancel the add index job in write reorganization state, the index is rolled back.
+	var jobID int64
+	var cancelResult string
+	var checkErr error
+	callback := &ddl.TestDDLCallback{}
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if job.SchemaState != model.StateWriteReorganization || jobID != 0 {
+			return
+		}
+		jobID = job.ID
+		cancelResult, checkErr = adminExec(fmt.Sprintf("admin cancel ddl jobs %d", job.ID))
+	}
+	d := s.dom.DDL()
+	d.SetHook(callback)
+	defer d.SetHook(&ddl.BaseCallback{})
+	_, err = s.tk.Exec("alter table test_admin_ddl_jobs add index idx_b(b)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*cancelled DDL job.*")
+	c.Assert(checkErr, IsNil)
+	c.Assert(cancelResult, Equals, "successful")
+	t := s.testGetTable(c, "test_admin_ddl_jobs")
+	c.Assert(t.Meta().Indices, HasLen, 0)
+	s.tk.MustExec("admin check table test_admin_ddl_jobs")
+	// The job isn't in the queue now.
+	result, err := adminExec(fmt.Sprintf("admin cancel ddl jobs %d", jobID))
+	c.Assert(err, IsNil)
+	c.Assert(result, Matches, ".*not found")
+
+	// Pause the add index job in delete only state, it goes on after it's resumed.
+	jobID, checkErr = 0, nil
+	var pauseResult string
+	resumeCh := make(chan string, 1)
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if job.SchemaState != model.StateDeleteOnly || jobID != 0 {
+			return
+		}
+		jobID = job.ID
+		pauseResult, checkErr = adminExec(fmt.Sprintf("admin pause ddl jobs %d", job.ID))
+		go func() {
+			time.Sleep(s.lease)
+			result, err1 := adminExec(fmt.Sprintf("admin resume ddl jobs %d", job.ID))
+			if err1 != nil {
+				result = err1.Error()
+			}
+			resumeCh <- result
+		}()
+	}
+	s.tk.MustExec("alter table test_admin_ddl_jobs add index idx_b(b)")
+	c.Assert(checkErr, IsNil)
+	c.Assert(pauseResult, Equals, "successful")
+	c.Assert(<-resumeCh, Equals, "successful")
+	s.tk.MustQuery("select b from test_admin_ddl_jobs use index(idx_b) where b = 3").Check(testkit.Rows("3"))
+	s.tk.MustExec("admin check table test_admin_ddl_jobs")
+}
+
 func (s *testDBSuite) TestIndex(c *C) {
 	defer testleak.AfterTest(c)()
 	s.tk = testkit.NewTestKit(c, s.store)
@@ -887,8 +1026,80 @@ func (s *testDBSuite) TestChangeColumn(c *C) {
 	s.testErrorCode(c, sql, tmysql.ErrWrongTableName)
 	sql = "alter table t3 change aa a bigint not null"
 	s.testErrorCode(c, sql, tmysql.ErrUnknown)
-	sql = "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'"
+	sql = "alter table t3 modify en set('a', 'b', 'c') not null default 'a'"
+	s.testErrorCode(c, sql, tmysql.ErrUnknown)
+}
+
+func (s *testDBSuite) TestModifyColumnElems(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+
+	s.tk.MustExec("create table t_elems (a int, e enum('a', 'b', 'c'), s set('a', 'b', 'c'))")
+	defer s.tk.MustExec("drop table t_elems")
+	s.tk.MustExec("insert into t_elems values (1, 'a', 'a,c'), (2, 'b', 'b'), (3, 'c', ''), (4, null, null)")
+
+	// Appending elements keeps the stored values.
+	s.tk.MustExec("alter table t_elems modify e enum('a', 'b', 'c', 'd')")
+	s.tk.MustExec("alter table t_elems modify s set('a', 'b', 'c', 'd')")
+	tbl := s.testGetTable(c, "t_elems")
+	c.Assert(tbl.Meta().Columns[1].ElemsByName, IsFalse)
+	s.tk.MustExec("insert into t_elems values (5, 'd', 'b,d')")
+	s.tk.MustQuery("select e, s from t_elems order by a").Check(testkit.Rows("a a,c", "b b", "c ", "<nil> <nil>", "d b,d"))
+
+	// Reordering elements rewrites the stored values.
+	s.tk.MustExec("alter table t_elems modify e enum('d', 'c', 'b', 'a')")
+	s.tk.MustExec("alter table t_elems modify s set('d', 'c', 'b', 'a')")
+	tbl = s.testGetTable(c, "t_elems")
+	c.Assert(tbl.Meta().Columns[1].ElemsByName, IsTrue)
+	c.Assert(tbl.Meta().Columns[2].ElemsByName, IsTrue)
+	s.tk.MustQuery("select e, s from t_elems order by a").Check(testkit.Rows("a c,a", "b b", "c ", "<nil> <nil>", "d d,b"))
+	s.tk.MustQuery("select a from t_elems where e = 'd'").Check(testkit.Rows("5"))
+	s.tk.MustExec("update t_elems set e = 'c' where a = 1")
+	s.tk.MustQuery("select e from t_elems where a = 1").Check(testkit.Rows("c"))
+
+	// Removing an element that is used fails and keeps the values.
+	sql := "alter table t_elems modify e enum('a', 'b', 'c')"
+	s.testErrorCode(c, sql, tmysql.WarnDataTruncated)
+	sql = "alter table t_elems modify s set('a', 'b', 'c')"
+	s.testErrorCode(c, sql, tmysql.WarnDataTruncated)
+	s.tk.MustQuery("select e, s from t_elems order by a").Check(testkit.Rows("c c,a", "b b", "c ", "<nil> <nil>", "d d,b"))
+	tbl = s.testGetTable(c, "t_elems")
+	c.Assert(tbl.Meta().Columns[1].ChangingElems, IsNil)
+	s.tk.MustExec("insert into t_elems values (6, 'd', 'd')")
+	s.tk.MustExec("delete from t_elems where a = 6")
+
+	// Removing the elements that aren't used succeeds, the removed elements can't be written during it.
+	s.tk.MustExec("delete from t_elems where a = 5")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use " + s.schemaName)
+	var insertErr, updateErr, checkErr error
+	callback := &ddl.TestDDLCallback{}
+	callback.OnJobUpdatedExported = func(job *model.Job) {
+		if job.SchemaState != model.StateWriteReorganization || insertErr != nil {
+			return
+		}
+		_, insertErr = tk1.Exec("insert into t_elems values (7, 'd', '')")
+		_, updateErr = tk1.Exec("update t_elems set e = 'a' where a = 2")
+		_, checkErr = tk1.Exec("update t_elems set e = 'b', a = 2 where a = 2")
+	}
+	d := s.dom.DDL()
+	d.SetHook(callback)
+	s.tk.MustExec("alter table t_elems modify e enum('b', 'c')")
+	d.SetHook(&ddl.BaseCallback{})
+	c.Assert(table.ErrElemBeingRemoved.Equal(insertErr), IsTrue, Commentf("err %v", insertErr))
+	c.Assert(table.ErrElemBeingRemoved.Equal(updateErr), IsTrue, Commentf("err %v", updateErr))
+	c.Assert(checkErr, IsNil)
+	s.tk.MustExec("alter table t_elems modify s set('a', 'b', 'c')")
+	s.tk.MustQuery("select e, s from t_elems order by a").Check(testkit.Rows("c a,c", "b b", "c ", "<nil> <nil>"))
+	tbl = s.testGetTable(c, "t_elems")
+	c.Assert(tbl.Meta().Columns[1].Elems, DeepEquals, []string{"b", "c"})
+
+	// The elements of an indexed column can only be appended.
+	s.tk.MustExec("alter table t_elems add index idx_e (e)")
+	sql = "alter table t_elems modify e enum('c', 'b')"
 	s.testErrorCode(c, sql, tmysql.ErrUnknown)
+	s.tk.MustExec("alter table t_elems modify e enum('b', 'c', 'd')")
 }
 
 func (s *testDBSuite) TestAlterColumn(c *C) {
@@ -1115,6 +1326,7 @@ func (s *testDBSuite) TestCreateTableWithLike(c *C) {
 	s.tk.MustExec("use test")
 	s.tk.MustExec("create table tt(id int primary key)")
 	s.tk.MustExec("create table t (c1 int not null auto_increment, c2 int, constraint cc foreign key (c2) references tt(id), primary key(c1)) auto_increment = 10")
+	s.tk.MustExec("create index idx_c2 on t(c2)")
 	s.tk.MustExec("insert into t set c2=1")
 	s.tk.MustExec("create table t1 like test.t")
 	s.tk.MustExec("insert into t1 set c2=11")
@@ -1130,6 +1342,10 @@ func (s *testDBSuite) TestCreateTableWithLike(c *C) {
 	col := tblInfo.Columns[0]
 	hasNotNull := tmysql.HasNotNullFlag(col.Flag)
 	c.Assert(hasNotNull, IsTrue)
+	c.Assert(tblInfo.Indices, HasLen, 1)
+	c.Assert(tblInfo.Indices[0].Name.L, Equals, "idx_c2")
+	c.Assert(tblInfo.Indices[0].Columns[0].Offset, Equals, 1)
+	s.tk.MustExec("admin check table t1")
 	// for different databases
 	s.tk.MustExec("create database test1")
 	s.tk.MustExec("use test1")
@@ -1152,6 +1368,175 @@ func (s *testDBSuite) TestCreateTableWithLike(c *C) {
 	s.testErrorCode(c, failSQL, tmysql.ErrTableExists)
 }
 
+func (s *testDBSuite) TestCreateTableSelect(c *C) {
+	defer testleak.AfterTest(c)
+	store, err := tidb.NewStore("memory://create_table_select")
+	c.Assert(err, IsNil)
+	s.tk = testkit.NewTestKit(c, store)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+
+	s.tk.MustExec("use test")
+	s.tk.MustExec("create table t (c1 int unsigned not null, c2 varchar(10), c3 decimal(10, 2))")
+	s.tk.MustExec("insert into t values (1, 'a', 1.5), (2, 'b', 2.5)")
+	s.tk.MustExec("create table t1 as select * from t")
+	s.tk.MustQuery("select * from t1").Check(testkit.Rows("1 a 1.50", "2 b 2.50"))
+	ctx := s.tk.Se.(context.Context)
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t1"))
+	c.Assert(err, IsNil)
+	cols := tbl.Meta().Columns
+	c.Assert(cols, HasLen, 3)
+	c.Assert(cols[0].Tp, Equals, tmysql.TypeLong)
+	c.Assert(tmysql.HasUnsignedFlag(cols[0].Flag), IsTrue)
+	c.Assert(tmysql.HasNotNullFlag(cols[0].Flag), IsTrue)
+	c.Assert(cols[1].Tp, Equals, tmysql.TypeVarchar)
+	c.Assert(cols[1].Flen, Equals, 10)
+	c.Assert(cols[2].Tp, Equals, tmysql.TypeNewDecimal)
+
+	// The defined columns come first, the fields with the same names are inserted into them.
+	s.tk.MustExec("create table t2 (id int primary key auto_increment, c1 bigint) select c1, c2 as c from t where c1 > 1")
+	s.tk.MustQuery("select * from t2").Check(testkit.Rows("1 2 b"))
+	s.tk.MustExec("create table t3 select c1 + 1 as a, null as b from t")
+	s.tk.MustQuery("select * from t3").Check(testkit.Rows("2 <nil>", "3 <nil>"))
+	// Like MySQL, the NULL field is stored in a BINARY(0) column.
+	s.tk.MustQuery("show create table t3").Check(testkit.Rows("t3 CREATE TABLE `t3` (\n" +
+		"  `a` bigint(20) UNSIGNED DEFAULT NULL,\n" +
+		"  `b` binary(0) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	s.tk.MustExec("create table if not exists t3 select * from t")
+	s.tk.MustQuery("select count(*) from t3").Check(testkit.Rows("2"))
+
+	// The table is dropped if the rows can't be inserted.
+	failSQL := "create table t4 (c1 int unique) select 1 as c1 from t"
+	s.testErrorCode(c, failSQL, tmysql.ErrDupEntry)
+	is = sessionctx.GetDomain(ctx).InfoSchema()
+	c.Assert(is.TableExists(model.NewCIStr("test"), model.NewCIStr("t4")), IsFalse)
+	failSQL = "create table t1 select * from t"
+	s.testErrorCode(c, failSQL, tmysql.ErrTableExists)
+}
+
+func (s *testDBSuite) TestIfExistsDDL(c *C) {
+	defer testleak.AfterTest(c)
+	store, err := tidb.NewStore("memory://if_exists_ddl")
+	c.Assert(err, IsNil)
+	s.tk = testkit.NewTestKit(c, store)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	checkWarnings := func(sql string, cnt int) {
+		s.tk.MustExec(sql)
+		c.Assert(s.tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(cnt), Commentf("sql %s", sql))
+	}
+
+	s.tk.MustExec("use test")
+	checkWarnings("create database if not exists test", 1)
+	checkWarnings("create table t (c1 int, c2 int)", 0)
+	checkWarnings("create table if not exists t (c1 int)", 1)
+
+	checkWarnings("create index if not exists idx on t (c1)", 0)
+	checkWarnings("create index if not exists idx on t (c2)", 1)
+	s.testErrorCode(c, "create index idx on t (c2)", tmysql.ErrDupKeyName)
+	checkWarnings("drop index if exists idx on t", 0)
+	checkWarnings("drop index if exists idx on t", 1)
+	s.testErrorCode(c, "drop index idx on t", tmysql.ErrCantDropFieldOrKey)
+
+	checkWarnings("alter table t add column if not exists c3 int", 0)
+	checkWarnings("alter table t add column if not exists c3 int", 1)
+	s.testErrorCode(c, "alter table t add column c3 int", tmysql.ErrDupFieldName)
+	checkWarnings("alter table t drop column if exists c3", 0)
+	checkWarnings("alter table t drop column if exists c3", 1)
+	s.testErrorCode(c, "alter table t drop column c3", tmysql.ErrCantDropFieldOrKey)
+	checkWarnings("alter table t drop index if exists idx", 1)
+
+	// The specs that have nothing to do are skipped, the others run in one schema change.
+	checkWarnings("alter table t add column if not exists c1 int, add column if not exists c4 int, drop column if exists c5", 2)
+	s.tk.MustQuery("select count(*) from information_schema.columns where table_name = 't' and column_name = 'c4'").Check(testkit.Rows("1"))
+
+	checkWarnings("drop table if exists t, t_not_exist", 1)
+	checkWarnings("drop database if exists test_not_exist", 1)
+}
+
+func (s *testDBSuite) TestDefaultValueExpr(c *C) {
+	defer testleak.AfterTest(c)
+	store, err := tidb.NewStore("memory://default_value_expr")
+	c.Assert(err, IsNil)
+	s.tk = testkit.NewTestKit(c, store)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+
+	s.tk.MustExec("use test")
+	s.tk.MustExec("create table t (id int, a varchar(36) default (uuid()), b int default (1 + 2), c json default (json_array()))")
+	s.tk.MustExec("insert into t (id) values (1), (2)")
+	s.tk.MustQuery("select count(distinct a), sum(length(a)), sum(b) from t").Check(testkit.Rows("2 72 6"))
+	s.tk.MustQuery("select c from t where id = 1").Check(testkit.Rows("[]"))
+	s.tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` int(11) DEFAULT NULL,\n" +
+		"  `a` varchar(36) DEFAULT (uuid()),\n" +
+		"  `b` int(11) DEFAULT (1 + 2),\n" +
+		"  `c` json DEFAULT (json_array())\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+
+	// The existing rows get the value of the expression when the column is added.
+	s.testErrorCode(c, "alter table t add column d int default (b * 2)", tmysql.ErrInvalidDefault)
+	s.tk.MustExec("alter table t add column e int default (abs(-4))")
+	s.tk.MustQuery("select e from t").Check(testkit.Rows("4", "4"))
+	s.tk.MustExec("alter table t alter column e set default (5 + 5)")
+	s.tk.MustExec("insert into t (id) values (3)")
+	s.tk.MustQuery("select e from t where id = 3").Check(testkit.Rows("10"))
+	s.tk.MustExec("alter table t alter column e drop default")
+	s.tk.MustExec("insert into t (id) values (4)")
+	s.tk.MustQuery("select e from t where id = 4").Check(testkit.Rows("<nil>"))
+	// The null value isn't read as the original default value of the column.
+	s.tk.MustExec("insert into t (id, e) values (5, null)")
+	s.tk.MustQuery("select e from t where id = 5").Check(testkit.Rows("<nil>"))
+	s.tk.MustExec("alter table t add column f int default 7")
+	s.tk.MustExec("alter table t alter column f drop default")
+	s.tk.MustExec("insert into t (id, f) values (6, null)")
+	s.tk.MustQuery("select f from t where id in (1, 6) order by id").Check(testkit.Rows("7", "<nil>"))
+
+	_, err = s.tk.Exec("create table t1 (a int default (uuid()))")
+	c.Assert(err, NotNil)
+}
+
+func (s *testDBSuite) TestTimestampOnUpdate(c *C) {
+	defer testleak.AfterTest(c)
+	store, err := tidb.NewStore("memory://timestamp_on_update")
+	c.Assert(err, IsNil)
+	s.tk = testkit.NewTestKit(c, store)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+
+	s.tk.MustExec("use test")
+	// Only the first TIMESTAMP column gets DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP implicitly.
+	s.tk.MustExec("create table t (a int, b timestamp, c timestamp, " +
+		"d timestamp on update current_timestamp default '2000-01-01 00:00:00', " +
+		"e datetime default current_timestamp on update current_timestamp)")
+	ctx := s.tk.Se.(context.Context)
+	is := sessionctx.GetDomain(ctx).InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	cols := tbl.Meta().Columns
+	c.Assert(tmysql.HasOnUpdateNowFlag(cols[1].Flag), IsTrue)
+	c.Assert(cols[1].DefaultValue, Equals, expression.CurrentTimestamp)
+	c.Assert(tmysql.HasOnUpdateNowFlag(cols[2].Flag), IsFalse)
+	c.Assert(cols[2].DefaultValue, Equals, expression.ZeroTimestamp)
+	// The explicit ON UPDATE is kept when it's before DEFAULT.
+	c.Assert(tmysql.HasOnUpdateNowFlag(cols[3].Flag), IsTrue)
+	c.Assert(tmysql.HasOnUpdateNowFlag(cols[4].Flag), IsTrue)
+
+	s.tk.MustExec("insert into t (a) values (1)")
+	s.tk.MustExec("update t set a = 2")
+	s.tk.MustQuery("select c, d > '2000-01-01 00:00:00', e is not null from t").Check(testkit.Rows("0000-00-00 00:00:00 1 1"))
+
+	s.tk.MustExec("alter table t add column f timestamp")
+	is = sessionctx.GetDomain(ctx).InfoSchema()
+	tbl, err = is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	c.Assert(tmysql.HasOnUpdateNowFlag(tbl.Meta().Columns[5].Flag), IsFalse)
+
+	s.testErrorCode(c, "create table t1 (a int on update current_timestamp)", tmysql.ErrInvalidOnUpdate)
+}
+
 func (s *testDBSuite) TestCreateTable(c *C) {
 	defer testleak.AfterTest(c)
 	store, err := tidb.NewStore("memory://create_table")
@@ -1294,14 +1679,28 @@ func (s *testDBSuite) TestRenameMultiTables(c *C) {
 	c.Assert(err, IsNil)
 	s.tk = testkit.NewTestKit(c, store)
 	s.tk.MustExec("use test")
+	s.tk.MustExec("create database test1")
 	s.tk.MustExec("create table t1(id int)")
 	s.tk.MustExec("create table t2(id int)")
-	// Currently it will fail only.
-	sql := fmt.Sprintf("rename table t1 to t3, t2 to t4")
-	_, err = s.tk.Exec(sql)
+	s.tk.MustExec("insert t1 values (1)")
+	s.tk.MustExec("insert t2 values (2)")
+
+	// Swap two tables through a temporary name.
+	s.tk.MustExec("rename table t1 to tmp, t2 to t1, tmp to t2")
+	s.tk.MustQuery("select * from t1").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select * from t2").Check(testkit.Rows("1"))
+	s.tk.MustExec("rename table t1 to t3, t2 to test1.t4")
+	s.tk.MustQuery("select * from t3").Check(testkit.Rows("2"))
+	s.tk.MustQuery("select * from test1.t4").Check(testkit.Rows("1"))
+
+	// No table is renamed if one of the renamings fails.
+	s.tk.MustExec("create table t5(id int)")
+	_, err = s.tk.Exec("rename table t3 to t6, t5 to test1.t4")
+	c.Assert(infoschema.ErrTableExists.Equal(errors.Cause(err)), IsTrue, Commentf("err %v", err))
+	_, err = s.tk.Exec("rename table t3 to t6, t6 to t7, t100 to t8")
 	c.Assert(err, NotNil)
-	originErr := errors.Cause(err)
-	c.Assert(originErr.Error(), Equals, "can't run multi schema change")
+	s.tk.MustQuery("select * from t3").Check(testkit.Rows("2"))
+	s.tk.MustQuery("show tables").Check(testkit.Rows("t3", "t5"))
 }
 
 func (s *testDBSuite) TestAddNotNullColumn(c *C) {
@@ -0,0 +1,38 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build failpoint
+
+package ddl_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/failpoint"
+	"github.com/pingcap/tidb/util/testkit"
+)
+
+func (s *testDBSuite) TestFailRunDDLJob(c *C) {
+	defer failpoint.Disable("ddl/runJobError")
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_fail")
+
+	// The job is run again after the failures.
+	failpoint.EnableN("ddl/runJobError", true, 2)
+	s.tk.MustExec("create table t_fail (a int, b int)")
+	failpoint.EnableN("ddl/runJobError", true, 2)
+	s.tk.MustExec("alter table t_fail add index idx_b (b)")
+	s.tk.MustExec("insert into t_fail values (1, 2)")
+	s.tk.MustQuery("select a from t_fail use index (idx_b) where b = 2").Check(testkit.Rows("1"))
+	s.tk.MustExec("drop table t_fail")
+}
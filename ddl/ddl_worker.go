@@ -16,7 +16,6 @@ package ddl
 import (
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
@@ -24,15 +23,69 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/failpoint"
 	goctx "golang.org/x/net/context"
 )
 
 // RunWorker indicates if this TiDB server starts DDL worker and can run DDL job.
 var RunWorker = true
 
+// workerType is the type of a DDL worker, the workers of different types run the jobs of different queues.
+type workerType byte
+
+const (
+	// generalWorker runs the jobs in the default job queue.
+	generalWorker workerType = 0
+	// addIdxWorker runs the jobs that add indices, they may take a long time to backfill the indices.
+	addIdxWorker workerType = 1
+)
+
+// String implements fmt.Stringer interface.
+func (tp workerType) String() string {
+	switch tp {
+	case generalWorker:
+		return "general"
+	case addIdxWorker:
+		return "add index"
+	}
+	return "unknown"
+}
+
+// worker runs the jobs of a DDL job queue in order.
+// The jobs of different queues run in parallel, unless a job depends on a job in the other queue.
+type worker struct {
+	tp       workerType
+	ddlJobCh chan struct{}
+}
+
+func newWorkers() map[workerType]*worker {
+	workers := make(map[workerType]*worker, 2)
+	for _, tp := range []workerType{generalWorker, addIdxWorker} {
+		workers[tp] = &worker{tp: tp, ddlJobCh: make(chan struct{}, 1)}
+	}
+	return workers
+}
+
+// jobListKey returns the key of the job queue that the worker runs.
+func (w *worker) jobListKey() meta.JobListKeyType {
+	if w.tp == addIdxWorker {
+		return meta.AddIndexJobListKey
+	}
+	return meta.DefaultJobListKey
+}
+
+// getWorkerType returns the type of the worker that runs the job.
+func getWorkerType(tp model.ActionType) workerType {
+	switch tp {
+	case model.ActionAddIndex, model.ActionMultiSchemaChange, model.ActionModifyColumnElems:
+		return addIdxWorker
+	}
+	return generalWorker
+}
+
 // onDDLWorker is for async online schema changing, it will try to become the owner firstly,
 // then wait or pull the job queue to handle a schema change job.
-func (d *ddl) onDDLWorker() {
+func (d *ddl) onDDLWorker(w *worker) {
 	defer d.wait.Done()
 	if !RunWorker {
 		return
@@ -49,15 +102,18 @@ func (d *ddl) onDDLWorker() {
 	for {
 		select {
 		case <-ticker.C:
-			log.Debugf("[ddl] wait %s to check DDL status again", checkTime)
-		case <-d.ddlJobCh:
+			ddlLogger().Debugf("[ddl] %s worker waits %s to check DDL status again", w.tp, checkTime)
+		case <-w.ddlJobCh:
+		case <-d.schemaSyncer.JobQueuedCh():
+			// Another server puts a job in a queue, it may be the queue of the other worker.
+			d.notifyOtherWorkers(w)
 		case <-d.quitCh:
 			return
 		}
 
-		err := d.handleDDLJobQueue()
+		err := d.handleDDLJobQueue(w)
 		if err != nil {
-			log.Errorf("[ddl] handle ddl job err %v", errors.ErrorStack(err))
+			ddlLogger().Errorf("[ddl] %s worker handles ddl job err %v", w.tp, errors.ErrorStack(err))
 		}
 	}
 }
@@ -71,7 +127,7 @@ func asyncNotify(ch chan struct{}) {
 
 func (d *ddl) isOwner() bool {
 	isOwner := d.ownerManager.IsOwner()
-	log.Debugf("[ddl] it's the job owner %v, self id %s", isOwner, d.uuid)
+	ddlLogger().Debugf("[ddl] it's the job owner %v, self id %s", isOwner, d.uuid)
 	return isOwner
 }
 
@@ -80,17 +136,63 @@ func (d *ddl) addDDLJob(ctx context.Context, job *model.Job) error {
 	job.Version = currentVersion
 	job.Query, _ = ctx.Value(context.QueryString).(string)
 	return kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
-		t := meta.NewMeta(txn)
+		w := d.workers[getWorkerType(job.Type)]
+		t := meta.NewMeta(txn, w.jobListKey())
 		var err error
 		job.ID, err = t.GenGlobalID()
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if err = d.buildJobDependence(txn, w, job); err != nil {
+			return errors.Trace(err)
+		}
 		err = t.EnQueueDDLJob(job)
 		return errors.Trace(err)
 	})
 }
 
+// buildJobDependence sets the dependency of the job that is put in the queue of the worker w.
+// The jobs of a queue run in order, so the job only depends on the last job in the other queue
+// that changes the same table or schema.
+func (d *ddl) buildJobDependence(txn kv.Transaction, w *worker, job *model.Job) error {
+	for _, other := range d.workers {
+		if other == w {
+			continue
+		}
+		jobs, err := meta.NewMeta(txn, other.jobListKey()).GetAllDDLJobsInQueue()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := len(jobs) - 1; i >= 0; i-- {
+			isDependent, err := job.IsDependentOn(jobs[i])
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if isDependent {
+				ddlLogger().Infof("[ddl] DDL job %d depends on DDL job %d", job.ID, jobs[i].ID)
+				job.DependencyID = jobs[i].ID
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// isDependencyJobDone returns whether the job that the job depends on is finished.
+func isDependencyJobDone(t *meta.Meta, job *model.Job) (bool, error) {
+	if job.DependencyID == 0 {
+		return true, nil
+	}
+
+	historyJob, err := t.GetHistoryDDLJob(job.DependencyID)
+	if err != nil || historyJob == nil {
+		return false, errors.Trace(err)
+	}
+	ddlLogger().Infof("[ddl] the dependency job %d of DDL job %d is finished", job.DependencyID, job.ID)
+	job.DependencyID = 0
+	return true, nil
+}
+
 // getFirstDDLJob gets the first DDL job form DDL queue.
 func (d *ddl) getFirstDDLJob(t *meta.Meta) (*model.Job, error) {
 	job, err := t.GetDDLJob(0)
@@ -103,7 +205,7 @@ func (d *ddl) handleUpdateJobError(t *meta.Meta, job *model.Job, err error) erro
 		return nil
 	}
 	if kv.ErrEntryTooLarge.Equal(err) {
-		log.Warnf("[ddl] update DDL job %v failed %v", job, errors.ErrorStack(err))
+		ddlLogger().Warnf("[ddl] update DDL job %v failed %v", job, errors.ErrorStack(err))
 		// Reduce this txn entry size.
 		job.BinlogInfo.Clean()
 		job.Error = toTError(err)
@@ -118,15 +220,14 @@ func (d *ddl) handleUpdateJobError(t *meta.Meta, job *model.Job, err error) erro
 // Every time we enter another state except final state, we must call this function.
 func (d *ddl) updateDDLJob(t *meta.Meta, job *model.Job, updateTS uint64) error {
 	job.LastUpdateTS = int64(updateTS)
-	err := t.UpdateDDLJob(0, job)
+	err := t.UpdateDDLJob(0, job, true)
 	return errors.Trace(err)
 }
 
 // finishDDLJob deletes the finished DDL job in the ddl queue and puts it to history queue.
 // If the DDL job need to handle in background, it will prepare a background job.
 func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
-	switch job.Type {
-	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex:
+	if needDelRange(job) {
 		if job.Version <= currentVersion {
 			err = d.delRangeManager.addDelRangeJob(job)
 		} else {
@@ -142,11 +243,28 @@ func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
 		return errors.Trace(err)
 	}
 
-	log.Infof("[ddl] finish DDL job %v", job)
+	ddlLogger().Infof("[ddl] finish DDL job %v", job)
 	err = t.AddHistoryDDLJob(job)
 	return errors.Trace(err)
 }
 
+// needDelRange returns whether the finished job leaves the keys that are deleted by the delete-range worker.
+func needDelRange(job *model.Job) bool {
+	if job.State == model.JobCancelled {
+		// The job is cancelled before it changes the data.
+		return false
+	}
+	switch job.Type {
+	case model.ActionAddIndex:
+		// The keys of the index that is rolled back are left.
+		return job.State == model.JobRollbackDone
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable, model.ActionDropIndex,
+		model.ActionMultiSchemaChange:
+		return true
+	}
+	return false
+}
+
 // getHistoryDDLJob gets a DDL job with job's ID form history queue.
 func (d *ddl) getHistoryDDLJob(id int64) (*model.Job, error) {
 	var job *model.Job
@@ -161,7 +279,7 @@ func (d *ddl) getHistoryDDLJob(id int64) (*model.Job, error) {
 	return job, errors.Trace(err)
 }
 
-func (d *ddl) handleDDLJobQueue() error {
+func (d *ddl) handleDDLJobQueue(w *worker) error {
 	once := true
 	for {
 		if d.isClosed() {
@@ -178,7 +296,7 @@ func (d *ddl) handleDDLJobQueue() error {
 			}
 
 			var err error
-			t := meta.NewMeta(txn)
+			t := meta.NewMeta(txn, w.jobListKey())
 			// We become the owner. Get the first job and run it.
 			job, err = d.getFirstDDLJob(t)
 			if job == nil || err != nil {
@@ -191,6 +309,16 @@ func (d *ddl) handleDDLJobQueue() error {
 				return nil
 			}
 
+			if job.IsPaused() {
+				// The paused job blocks the queue until it's resumed.
+				ddlLogger().Infof("[ddl] DDL job %v is paused", job)
+				if w.tp == addIdxWorker {
+					d.stopReorgJob()
+				}
+				job = nil
+				return nil
+			}
+
 			if job.IsDone() {
 				binloginfo.SetDDLBinlog(d.workerVars.BinlogClient, txn, job.ID, job.Query)
 				job.State = model.JobSynced
@@ -198,6 +326,18 @@ func (d *ddl) handleDDLJobQueue() error {
 				return errors.Trace(err)
 			}
 
+			done, err := isDependencyJobDone(t, job)
+			if err != nil || !done {
+				// Wait for the dependency job in the other queue, the jobs after this job wait too.
+				job = nil
+				return errors.Trace(err)
+			}
+
+			if _, ok := failpoint.Eval("ddl/runJobError"); ok {
+				// The transaction is rolled back, and the job is run again in the next round.
+				return errors.Errorf("injected error running DDL job %d", job.ID)
+			}
+
 			d.hookMu.Lock()
 			d.hook.OnJobRunBefore(job)
 			d.hookMu.Unlock()
@@ -224,13 +364,29 @@ func (d *ddl) handleDDLJobQueue() error {
 		d.hookMu.Unlock()
 
 		// Here means the job enters another state (delete only, write only, public, etc...) or is cancelled.
-		// If the job is done or still running, we will wait 2 * lease time to guarantee other servers to update
-		// the newest schema.
-		if job.State == model.JobRunning || job.State == model.JobDone {
+		// If the job is done, still running or rolling back, we will wait 2 * lease time to guarantee other servers
+		// to update the newest schema.
+		if job.State == model.JobRunning || job.State == model.JobDone ||
+			job.State == model.JobRollback || job.State == model.JobRollbackDone {
 			d.waitSchemaChanged(nil, waitTime, schemaVer)
 		}
-		if job.IsSynced() {
+		if job.IsSynced() || job.IsCancelled() {
 			asyncNotify(d.ddlJobDoneCh)
+			// The job may be waited for on the other servers.
+			if err1 := d.schemaSyncer.NotifyJobDone(goctx.Background(), job.ID); err1 != nil {
+				ddlLogger().Warnf("[ddl] notify DDL job %d done failed %v", job.ID, err1)
+			}
+			// The job in the other queue may depend on this job.
+			d.notifyOtherWorkers(w)
+		}
+	}
+}
+
+// notifyOtherWorkers notifies the workers except w to check their job queues.
+func (d *ddl) notifyOtherWorkers(w *worker) {
+	for _, other := range d.workers {
+		if other != w {
+			asyncNotify(other.ddlJobCh)
 		}
 	}
 }
@@ -244,12 +400,20 @@ func chooseLeaseTime(t, max time.Duration) time.Duration {
 
 // runDDLJob runs a DDL job. It returns the current schema version in this transaction.
 func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
-	log.Infof("[ddl] run DDL job %s", job)
+	ddlLogger().Infof("[ddl] run DDL job %s", job)
 	if job.IsFinished() {
 		return
 	}
 
-	if job.State != model.JobRollback {
+	if job.IsCancelling() && job.SchemaState == model.StateNone {
+		// The job hasn't changed the schema, cancel it directly.
+		ddlLogger().Infof("[ddl] cancel DDL job %s", job)
+		job.State = model.JobCancelled
+		job.Error = toTError(errCancelledDDLJob)
+		return
+	}
+	// The cancelling job that has changed the schema is rolled back by its handler.
+	if job.State != model.JobRollback && !job.IsCancelling() {
 		job.State = model.JobRunning
 	}
 
@@ -269,6 +433,8 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onDropColumn(t, job)
 	case model.ActionModifyColumn:
 		ver, err = d.onModifyColumn(t, job)
+	case model.ActionModifyColumnElems:
+		ver, err = d.onModifyColumnElems(t, job)
 	case model.ActionAddIndex:
 		ver, err = d.onCreateIndex(t, job)
 	case model.ActionDropIndex:
@@ -283,6 +449,14 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onRenameTable(t, job)
 	case model.ActionSetDefaultValue:
 		ver, err = d.onSetDefaultValue(t, job)
+	case model.ActionMultiSchemaChange:
+		ver, err = d.onMultiSchemaChange(t, job)
+	case model.ActionRenameTables:
+		ver, err = d.onRenameTables(t, job)
+	case model.ActionPauseWrites:
+		ver, err = d.onPauseWrites(t, job)
+	case model.ActionAlterPlacementPolicy:
+		ver, err = d.onAlterPlacementPolicy(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobCancelled
@@ -293,9 +467,9 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 	if err != nil {
 		// If job is not cancelled, we should log this error.
 		if job.State != model.JobCancelled {
-			log.Errorf("[ddl] run DDL job err %v", errors.ErrorStack(err))
+			ddlLogger().Errorf("[ddl] run DDL job err %v", errors.ErrorStack(err))
 		} else {
-			log.Infof("[ddl] the DDL job is normal to cancel because %v", errors.ErrorStack(err))
+			ddlLogger().Infof("[ddl] the DDL job is normal to cancel because %v", errors.ErrorStack(err))
 		}
 
 		job.Error = toTError(err)
@@ -325,7 +499,7 @@ func (d *ddl) waitSchemaChanged(ctx goctx.Context, waitTime time.Duration, lates
 	timeStart := time.Now()
 	// TODO: Do we need to wait for a while?
 	if latestSchemaVersion == 0 {
-		log.Infof("[ddl] schema version doesn't change")
+		ddlLogger().Infof("[ddl] schema version doesn't change")
 		return
 	}
 
@@ -336,7 +510,7 @@ func (d *ddl) waitSchemaChanged(ctx goctx.Context, waitTime time.Duration, lates
 	}
 	err := d.schemaSyncer.OwnerUpdateGlobalVersion(ctx, latestSchemaVersion)
 	if err != nil {
-		log.Infof("[ddl] update latest schema version %d failed %v", latestSchemaVersion, err)
+		ddlLogger().Infof("[ddl] update latest schema version %d failed %v", latestSchemaVersion, err)
 		if terror.ErrorEqual(err, goctx.DeadlineExceeded) {
 			return
 		}
@@ -344,7 +518,7 @@ func (d *ddl) waitSchemaChanged(ctx goctx.Context, waitTime time.Duration, lates
 
 	err = d.schemaSyncer.OwnerCheckAllVersions(ctx, latestSchemaVersion)
 	if err != nil {
-		log.Infof("[ddl] wait latest schema version %d to deadline %v", latestSchemaVersion, err)
+		ddlLogger().Infof("[ddl] wait latest schema version %d to deadline %v", latestSchemaVersion, err)
 		if terror.ErrorEqual(err, goctx.DeadlineExceeded) {
 			return
 		}
@@ -353,7 +527,7 @@ func (d *ddl) waitSchemaChanged(ctx goctx.Context, waitTime time.Duration, lates
 			return
 		}
 	}
-	log.Infof("[ddl] wait latest schema version %v changed, take time %v", latestSchemaVersion, time.Since(timeStart))
+	ddlLogger().Infof("[ddl] wait latest schema version %v changed, take time %v", latestSchemaVersion, time.Since(timeStart))
 	return
 }
 
@@ -374,11 +548,11 @@ func (d *ddl) waitSchemaSynced(job *model.Job, waitTime time.Duration) {
 	startTime := time.Now()
 	latestSchemaVersion, err := d.schemaSyncer.MustGetGlobalVersion(ctx)
 	if err != nil {
-		log.Warnf("[ddl] handle exception take time %v", time.Since(startTime))
+		ddlLogger().Warnf("[ddl] handle exception take time %v", time.Since(startTime))
 		return
 	}
 	d.waitSchemaChanged(ctx, waitTime, latestSchemaVersion)
-	log.Infof("[ddl] the handle exception take time %v", time.Since(startTime))
+	ddlLogger().Infof("[ddl] the handle exception take time %v", time.Since(startTime))
 }
 
 // updateSchemaVersion increments the schema version by 1 and sets SchemaDiff.
@@ -405,6 +579,21 @@ func updateSchemaVersion(t *meta.Meta, job *model.Job) (int64, error) {
 			return 0, errors.Trace(err)
 		}
 		diff.TableID = job.TableID
+	} else if job.Type == model.ActionRenameTables {
+		var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+		err = job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableIDs)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		diff.AffectedOpts = make([]*model.AffectedOption, 0, len(tableIDs))
+		for i, tableID := range tableIDs {
+			diff.AffectedOpts = append(diff.AffectedOpts, &model.AffectedOption{
+				SchemaID:    newSchemaIDs[i],
+				TableID:     tableID,
+				OldSchemaID: oldSchemaIDs[i],
+			})
+		}
+		diff.TableID = job.TableID
 	} else {
 		diff.TableID = job.TableID
 	}
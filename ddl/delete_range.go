@@ -19,7 +19,6 @@ import (
 	"fmt"
 	"math"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/ngaut/pools"
 	"github.com/pingcap/tidb/context"
@@ -88,7 +87,7 @@ func (dr *delRange) addDelRangeJob(job *model.Job) error {
 	if !dr.storeSupport {
 		dr.emulatorCh <- struct{}{}
 	}
-	log.Infof("[ddl] add job (%d,%s) into delete-range table", job.ID, job.Type.String())
+	ddlLogger().Infof("[ddl] add job (%d,%s) into delete-range table", job.ID, job.Type.String())
 	return nil
 }
 
@@ -102,7 +101,7 @@ func (dr *delRange) start() {
 
 // clear implements delRangeManager interface.
 func (dr *delRange) clear() {
-	log.Infof("[ddl] closing delRange session pool")
+	ddlLogger().Infof("[ddl] closing delRange session pool")
 	dr.ctxPool.Close()
 }
 
@@ -111,7 +110,7 @@ func (dr *delRange) clear() {
 // deletes all keys in each DelRangeTask.
 func (dr *delRange) startEmulator() {
 	defer dr.d.wait.Done()
-	log.Infof("[ddl] start delRange emulator")
+	ddlLogger().Infof("[ddl] start delRange emulator")
 	for {
 		select {
 		case <-dr.emulatorCh:
@@ -125,7 +124,7 @@ func (dr *delRange) startEmulator() {
 func (dr *delRange) doDelRangeWork() error {
 	resource, err := dr.ctxPool.Get()
 	if err != nil {
-		log.Errorf("[ddl] delRange emulator get session fail: %s", err)
+		ddlLogger().Errorf("[ddl] delRange emulator get session fail: %s", err)
 		return errors.Trace(err)
 	}
 	defer dr.ctxPool.Put(resource)
@@ -135,13 +134,13 @@ func (dr *delRange) doDelRangeWork() error {
 
 	ranges, err := LoadDeleteRanges(ctx, math.MaxInt64)
 	if err != nil {
-		log.Errorf("[dd] delRange emulator load tasks fail: %s", err)
+		ddlLogger().Errorf("[dd] delRange emulator load tasks fail: %s", err)
 		return errors.Trace(err)
 	}
 
 	for _, r := range ranges {
 		if err := dr.doTask(ctx, r); err != nil {
-			log.Errorf("[ddl] delRange emulator do task fail: %s", err)
+			ddlLogger().Errorf("[ddl] delRange emulator do task fail: %s", err)
 			return errors.Trace(err)
 		}
 	}
@@ -190,14 +189,14 @@ func (dr *delRange) doTask(ctx context.Context, r DelRangeTask) error {
 		}
 		if finish {
 			if err := CompleteDeleteRange(ctx, r); err != nil {
-				log.Errorf("[ddl] delRange emulator complete task fail: %s", err)
+				ddlLogger().Errorf("[ddl] delRange emulator complete task fail: %s", err)
 				return errors.Trace(err)
 			}
-			log.Infof("[ddl] delRange emulator complete task: (%d, %d)", r.jobID, r.elementID)
+			ddlLogger().Infof("[ddl] delRange emulator complete task: (%d, %d)", r.jobID, r.elementID)
 			break
 		} else {
 			if err := updateDeleteRange(ctx, r, newStartKey, oldStartKey); err != nil {
-				log.Errorf("[ddl] delRange emulator update task fail: %s", err)
+				ddlLogger().Errorf("[ddl] delRange emulator update task fail: %s", err)
 			}
 			oldStartKey = newStartKey
 		}
@@ -233,7 +232,8 @@ func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 		startKey := tablecodec.EncodeTablePrefix(tableID)
 		endKey := tablecodec.EncodeTablePrefix(tableID + 1)
 		return doInsert(s, job.ID, tableID, startKey, endKey, now)
-	case model.ActionDropIndex:
+	case model.ActionDropIndex, model.ActionAddIndex:
+		// The add index job is rolled back, its args are the same as the drop index job.
 		tableID := job.TableID
 		var indexName interface{}
 		var indexID int64
@@ -243,12 +243,26 @@ func insertJobIntoDeleteRangeTable(ctx context.Context, job *model.Job) error {
 		startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
 		endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
 		return doInsert(s, job.ID, indexID, startKey, endKey, now)
+	case model.ActionMultiSchemaChange:
+		tableID := job.TableID
+		var subJobs []*model.SubJob
+		var indexIDs []int64
+		if err := job.DecodeArgs(&subJobs, &indexIDs); err != nil {
+			return errors.Trace(err)
+		}
+		for _, indexID := range indexIDs {
+			startKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID)
+			endKey := tablecodec.EncodeTableIndexPrefix(tableID, indexID+1)
+			if err := doInsert(s, job.ID, indexID, startKey, endKey, now); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 	return nil
 }
 
 func doInsert(s sqlexec.SQLExecutor, jobID int64, elementID int64, startKey, endKey kv.Key, ts int64) error {
-	log.Infof("[ddl] insert into delete-range table with key: (%d,%d)", jobID, elementID)
+	ddlLogger().Infof("[ddl] insert into delete-range table with key: (%d,%d)", jobID, elementID)
 	startKeyEncoded := hex.EncodeToString(startKey)
 	endKeyEncoded := hex.EncodeToString(endKey)
 	sql := fmt.Sprintf(insertDeleteRangeSQL, jobID, elementID, startKeyEncoded, endKeyEncoded, ts)
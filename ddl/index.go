@@ -18,7 +18,6 @@ import (
 	"sync"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/kv"
@@ -117,6 +116,7 @@ func buildIndexColumns(columns []*model.ColumnInfo, idxColNames []*ast.IndexColN
 			Name:   col.Name,
 			Offset: col.Offset,
 			Length: ic.Length,
+			Desc:   ic.Desc,
 		})
 	}
 
@@ -203,31 +203,22 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	indexInfo := findIndexByName(indexName.L, tblInfo.Indices)
 	if indexInfo != nil && indexInfo.State == model.StatePublic {
 		job.State = model.JobCancelled
-		return ver, errDupKeyName.Gen("index already exist %s", indexName)
+		return ver, ErrDupKeyName.Gen("index already exist %s", indexName)
 	}
 
 	if indexInfo == nil {
-		indexInfo, err = buildIndexInfo(tblInfo, indexName, idxColNames, model.StateNone)
+		indexInfo, err = createIndexInfo(tblInfo, unique, indexName, idxColNames, indexOption)
 		if err != nil {
 			job.State = model.JobCancelled
 			return ver, errors.Trace(err)
 		}
-		if indexOption != nil {
-			indexInfo.Comment = indexOption.Comment
-			if indexOption.Tp == model.IndexTypeInvalid {
-				// Use btree as default index type.
-				indexInfo.Tp = model.IndexTypeBtree
-			} else {
-				indexInfo.Tp = indexOption.Tp
-			}
-		} else {
-			// Use btree as default index type.
-			indexInfo.Tp = model.IndexTypeBtree
-		}
-		indexInfo.Primary = false
-		indexInfo.Unique = unique
-		indexInfo.ID = allocateIndexID(tblInfo)
-		tblInfo.Indices = append(tblInfo.Indices, indexInfo)
+	}
+
+	if job.IsCancelling() {
+		d.stopReorgJob()
+		ddlLogger().Infof("[ddl] cancel DDL job %v, convert job to rollback job", job)
+		ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo, errCancelledDDLJob)
+		return ver, errors.Trace(err)
 	}
 
 	originalState := indexInfo.State
@@ -274,8 +265,9 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 				return ver, nil
 			}
 			if kv.ErrKeyExists.Equal(err) {
-				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
-				ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo)
+				ddlLogger().Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+				ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo,
+					kv.ErrKeyExists.Gen("Duplicate for key %s", indexInfo.Name.O))
 			}
 			return ver, errors.Trace(err)
 		}
@@ -300,21 +292,50 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	return ver, errors.Trace(err)
 }
 
-func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo) (ver int64, _ error) {
+// createIndexInfo builds the info of a new index in none state and appends it to the table.
+func createIndexInfo(tblInfo *model.TableInfo, unique bool, indexName model.CIStr, idxColNames []*ast.IndexColName,
+	indexOption *ast.IndexOption) (*model.IndexInfo, error) {
+	indexInfo, err := buildIndexInfo(tblInfo, indexName, idxColNames, model.StateNone)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if indexOption != nil {
+		indexInfo.Comment = indexOption.Comment
+		if indexOption.Tp == model.IndexTypeInvalid {
+			// Use btree as default index type.
+			indexInfo.Tp = model.IndexTypeBtree
+		} else {
+			indexInfo.Tp = indexOption.Tp
+		}
+	} else {
+		// Use btree as default index type.
+		indexInfo.Tp = model.IndexTypeBtree
+	}
+	indexInfo.Primary = false
+	indexInfo.Unique = unique
+	indexInfo.ID = allocateIndexID(tblInfo)
+	tblInfo.Indices = append(tblInfo.Indices, indexInfo)
+	return indexInfo, nil
+}
+
+// convert2RollbackJob converts the add index job to a rollback job, it returns the error that causes the rollback.
+func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo,
+	rollbackErr error) (ver int64, _ error) {
 	job.State = model.JobRollback
 	job.Args = []interface{}{indexInfo.Name}
 	// If add index job rollbacks in write reorganization state, its need to delete all keys which has been added.
 	// Its work is the same as drop index job do.
 	// The write reorganization state in add index job that likes write only state in drop index job.
 	// So the next state is delete only state.
+	// The job may be cancelled in delete only or write only state too, they are handled in the same way.
+	originalState := job.SchemaState
 	indexInfo.State = model.StateDeleteOnly
-	originalState := indexInfo.State
 	job.SchemaState = model.StateDeleteOnly
-	_, err := updateTableInfo(t, job, tblInfo, originalState)
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
 	if err != nil {
 		return ver, errors.Trace(err)
 	}
-	return ver, kv.ErrKeyExists.Gen("Duplicate for key %s", indexInfo.Name.O)
+	return ver, errors.Trace(rollbackErr)
 }
 
 func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
@@ -389,7 +410,7 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *indexTaskOpInfo, handleInfo *handleInfo) (
 	[]*indexRecord, *taskResult) {
 	startTime := time.Now()
-	handleCnt := defaultTaskHandleCnt
+	handleCnt := taskOpInfo.batchCnt
 	rawRecords := make([][]byte, 0, handleCnt)
 	idxRecords := make([]*indexRecord, 0, handleCnt)
 	ret := &taskResult{doneHandle: handleInfo.startHandle}
@@ -422,7 +443,7 @@ func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *ind
 		handleInfo.endHandle = ret.doneHandle
 		handleInfo.isSent = true
 	}
-	log.Debugf("[ddl] txn %v fetches handle info %v takes time %v", txn.StartTS(), handleInfo, time.Since(startTime))
+	ddlLogger().Debugf("[ddl] txn %v fetches handle info %v takes time %v", txn.StartTS(), handleInfo, time.Since(startTime))
 	if ret.count == 0 {
 		return nil, ret
 	}
@@ -474,8 +495,6 @@ func (d *ddl) getIndexRecords(t table.Table, taskOpInfo *indexTaskOpInfo, rawRec
 const (
 	defaultBatchCnt      = 1024
 	defaultSmallBatchCnt = 128
-	defaultTaskHandleCnt = 128
-	defaultTaskCnt       = 16
 )
 
 // taskResult is the result of the task.
@@ -504,13 +523,14 @@ type indexTaskOpInfo struct {
 	colMap    map[int64]*types.FieldType // It's the index columns map.
 	taskRetCh chan *taskResult           // Get the results of all tasks.
 	nextCh    chan int64                 // It notifies to start the next task.
+	batchCnt  int                        // It's the number of the rows that a task handles.
 }
 
 // addTableIndex adds index into table.
 // TODO: Move this to doc or wiki.
 // How to add index in reorganization state?
-// Concurrently process the defaultTaskHandleCnt tasks. Each task deals with a handle range of the index record.
-// The handle range size is defaultTaskHandleCnt.
+// Concurrently process the tidb_ddl_reorg_worker_cnt tasks. Each task deals with a handle range of the index record.
+// The handle range size is tidb_ddl_reorg_batch_size. Both variables are reloaded before each round of tasks.
 // Because each handle range depends on the previous one, it's necessary to obtain the handle range serially.
 // Real concurrent processing needs to perform after the handle range has been acquired.
 // The operation flow of the each task of data is as follows:
@@ -525,6 +545,7 @@ type indexTaskOpInfo struct {
 // task results, get the total number of rows in the concurrent task and update the processed handle value. If
 // an error message is displayed, exit the traversal.
 // Finally, update the concurrent processing of the total number of rows, and store the completed handle value.
+// The stored handle is the checkpoint of the reorganization, the new owner goes on from it after the owner changes.
 func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
 	cols := t.Cols()
 	colMap := make(map[int64]*types.FieldType)
@@ -532,18 +553,19 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 		col := cols[v.Offset]
 		colMap[col.ID] = &col.FieldType
 	}
-	taskCnt := defaultTaskCnt
 	taskOpInfo := &indexTaskOpInfo{
-		tblIndex:  tables.NewIndex(t.Meta(), indexInfo),
-		colMap:    colMap,
-		nextCh:    make(chan int64, 1),
-		taskRetCh: make(chan *taskResult, taskCnt),
+		tblIndex: tables.NewIndex(t.Meta(), indexInfo),
+		colMap:   colMap,
+		nextCh:   make(chan int64, 1),
 	}
 
 	addedCount := job.GetRowCount()
 	taskStartHandle := reorgInfo.Handle
 
 	for {
+		taskCnt, batchCnt := d.loadReorgVars()
+		taskOpInfo.batchCnt = batchCnt
+		taskOpInfo.taskRetCh = make(chan *taskResult, taskCnt)
 		startTime := time.Now()
 		wg := sync.WaitGroup{}
 		for i := 0; i < taskCnt; i++ {
@@ -569,7 +591,7 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 				if err == nil {
 					err = err1
 				} else {
-					log.Warnf("[ddl] add index failed when update handle %d, err %v", doneHandle, err)
+					ddlLogger().Warnf("[ddl] add index failed when update handle %d, err %v", doneHandle, err)
 				}
 			}
 		}
@@ -577,14 +599,14 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 		addedCount += int64(taskAddedCount)
 		sub := time.Since(startTime).Seconds()
 		if err != nil {
-			log.Warnf("[ddl] total added index for %d rows, this task add index for %d failed, take time %v",
+			ddlLogger().Warnf("[ddl] total added index for %d rows, this task add index for %d failed, take time %v",
 				addedCount, taskAddedCount, sub)
 			return errors.Trace(err)
 		}
 		d.setReorgRowCount(addedCount)
 		batchHandleDataHistogram.WithLabelValues(batchAddIdx).Observe(sub)
-		log.Infof("[ddl] total added index for %d rows, this task added index for %d rows, take time %v",
-			addedCount, taskAddedCount, sub)
+		ddlLogger().Infof("[ddl] total added index for %d rows, this task added index for %d rows with %d workers in batches of %d rows, take time %v",
+			addedCount, taskAddedCount, taskCnt, batchCnt, sub)
 
 		if retCnt < taskCnt {
 			return nil
@@ -655,12 +677,12 @@ func (d *ddl) doBackfillIndexTask(t table.Table, taskOpInfo *indexTaskOpInfo, st
 	}
 
 	taskOpInfo.taskRetCh <- ret
-	log.Debugf("[ddl] add index completes backfill index task %v takes time %v",
+	ddlLogger().Debugf("[ddl] add index completes backfill index task %v takes time %v",
 		handleInfo, time.Since(startTime))
 }
 
 // doBackfillIndexTaskInTxn deals with a part of backfilling index data in a Transaction.
-// This part of the index data rows is defaultTaskHandleCnt.
+// This part of the index data rows is taskOpInfo.batchCnt.
 func (d *ddl) doBackfillIndexTaskInTxn(t table.Table, txn kv.Transaction, taskOpInfo *indexTaskOpInfo,
 	handleInfo *handleInfo) *taskResult {
 	idxRecords, taskRet := d.fetchRowColVals(txn, t, taskOpInfo, handleInfo)
@@ -670,7 +692,7 @@ func (d *ddl) doBackfillIndexTaskInTxn(t table.Table, txn kv.Transaction, taskOp
 	}
 
 	for _, idxRecord := range idxRecords {
-		log.Debugf("[ddl] txn %v backfill index handle...%v", txn.StartTS(), idxRecord.handle)
+		ddlLogger().Debugf("[ddl] txn %v backfill index handle...%v", txn.StartTS(), idxRecord.handle)
 		err := txn.LockKeys(idxRecord.key)
 		if err != nil {
 			taskRet.err = errors.Trace(err)
@@ -40,6 +40,7 @@ var (
 	batchAddCol              = "batch_add_col"
 	batchAddIdx              = "batch_add_idx"
 	batchDelData             = "batch_del_data"
+	batchModifyElems         = "batch_modify_elems"
 	batchHandleDataHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
@@ -17,7 +17,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/coreos/etcd/clientv3"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/model"
 	goctx "golang.org/x/net/context"
@@ -29,7 +28,7 @@ const mockCheckVersInterval = 2 * time.Millisecond
 
 type mockSchemaSyncer struct {
 	selfSchemaVersion int64
-	globalVerCh       chan clientv3.WatchResponse
+	globalVerCh       chan struct{}
 }
 
 // NewMockSchemaSyncer creates a new mock SchemaSyncer.
@@ -39,15 +38,28 @@ func NewMockSchemaSyncer() SchemaSyncer {
 
 // Init implements SchemaSyncer.Init interface.
 func (s *mockSchemaSyncer) Init(ctx goctx.Context) error {
-	s.globalVerCh = make(chan clientv3.WatchResponse, 1)
+	s.globalVerCh = make(chan struct{}, 1)
 	return nil
 }
 
 // GlobalVersionCh implements SchemaSyncer.GlobalVersionCh interface.
-func (s *mockSchemaSyncer) GlobalVersionCh() clientv3.WatchChan {
+func (s *mockSchemaSyncer) GlobalVersionCh() <-chan struct{} {
 	return s.globalVerCh
 }
 
+// NotifyJobQueued implements SchemaSyncer.NotifyJobQueued interface.
+func (s *mockSchemaSyncer) NotifyJobQueued(ctx goctx.Context, jobID int64) error { return nil }
+
+// JobQueuedCh implements SchemaSyncer.JobQueuedCh interface.
+// There is only one server, it's notified by the DDL itself, so it returns nil.
+func (s *mockSchemaSyncer) JobQueuedCh() <-chan struct{} { return nil }
+
+// NotifyJobDone implements SchemaSyncer.NotifyJobDone interface.
+func (s *mockSchemaSyncer) NotifyJobDone(ctx goctx.Context, jobID int64) error { return nil }
+
+// JobDoneCh implements SchemaSyncer.JobDoneCh interface.
+func (s *mockSchemaSyncer) JobDoneCh() <-chan struct{} { return nil }
+
 // UpdateSelfVersion implements SchemaSyncer.UpdateSelfVersion interface.
 func (s *mockSchemaSyncer) UpdateSelfVersion(ctx goctx.Context, version int64) error {
 	atomic.StoreInt64(&s.selfSchemaVersion, version)
@@ -68,7 +80,7 @@ func (s *mockSchemaSyncer) RemoveSelfVersionPath() error { return nil }
 // OwnerUpdateGlobalVersion implements SchemaSyncer.OwnerUpdateGlobalVersion interface.
 func (s *mockSchemaSyncer) OwnerUpdateGlobalVersion(ctx goctx.Context, version int64) error {
 	select {
-	case s.globalVerCh <- clientv3.WatchResponse{}:
+	case s.globalVerCh <- struct{}{}:
 	default:
 	}
 	return nil
@@ -0,0 +1,516 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+)
+
+// onMultiSchemaChange runs the sub-jobs of an ALTER TABLE statement with several specs as one job.
+// How to make the schema change atomic?
+//  1. The added columns and indices go through none -> delete only -> write only -> write reorganization together,
+//     then the added indices are backfilled one by one. Nothing is public yet, so the job can be rolled back.
+//  2. In one schema version, the added columns and indices become public and the dropped ones become write only.
+//  3. The dropped columns and indices go through write only -> delete only -> delete reorganization -> absent together.
+//
+// If an added unique index meets duplicate keys while backfilling, or the job is cancelled before step 2,
+// all the added columns and indices are removed.
+func (d *ddl) onMultiSchemaChange(t *meta.Meta, job *model.Job) (ver int64, err error) {
+	schemaID := job.SchemaID
+	tblInfo, err := getTableInfo(t, job, schemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	var subJobs []*model.SubJob
+	if err = job.DecodeArgs(&subJobs); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	if job.State == model.JobRollback {
+		ver, err = d.rollbackMultiSchemaChange(t, job, tblInfo, subJobs)
+		return ver, errors.Trace(err)
+	}
+	if job.IsCancelling() {
+		d.stopReorgJob()
+		ddlLogger().Infof("[ddl] cancel DDL job %v, convert job to rollback job", job)
+		ver, err = convertMultiSchemaChange2Rollback(t, job, tblInfo, subJobs, errCancelledDDLJob)
+		return ver, errors.Trace(err)
+	}
+
+	// addState is the state of the added elements, it's public if all of them are public.
+	addState := model.StatePublic
+	for _, sub := range subJobs {
+		if isAddSubJob(sub) && sub.SchemaState < addState {
+			addState = sub.SchemaState
+		}
+	}
+
+	originalState := job.SchemaState
+	switch addState {
+	case model.StateNone:
+		// none -> delete only
+		if err = d.createMultiSchemaElements(tblInfo, subJobs); err != nil {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(err)
+		}
+		job.SchemaState = model.StateDeleteOnly
+		err = setAddedElementsState(tblInfo, subJobs, model.StateDeleteOnly)
+	case model.StateDeleteOnly:
+		// delete only -> write only
+		job.SchemaState = model.StateWriteOnly
+		err = setAddedElementsState(tblInfo, subJobs, model.StateWriteOnly)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		err = setAddedElementsState(tblInfo, subJobs, model.StateWriteReorganization)
+	case model.StateWriteReorganization:
+		// reorganization -> public
+		var done bool
+		done, err = d.backfillMultiSchemaIndices(t, job, tblInfo, subJobs)
+		if err != nil {
+			if errWaitReorgTimeout.Equal(err) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if kv.ErrKeyExists.Equal(err) {
+				ddlLogger().Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+				ver, err = convertMultiSchemaChange2Rollback(t, job, tblInfo, subJobs,
+					kv.ErrKeyExists.Gen("Duplicate for key %s", duplicateIndexName(subJobs)))
+			}
+			return ver, errors.Trace(err)
+		}
+		if !done {
+			return ver, nil
+		}
+		err = d.publishMultiSchemaElements(job, tblInfo, subJobs)
+	case model.StatePublic:
+		if isDropStarted(subJobs) {
+			err = d.dropMultiSchemaElements(job, tblInfo, subJobs)
+		} else {
+			// There are only dropped elements, public -> write only
+			err = d.publishMultiSchemaElements(job, tblInfo, subJobs)
+		}
+	default:
+		err = ErrInvalidTableState.Gen("invalid multi schema change state %v", addState)
+	}
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if isMultiSchemaChangeDone(subJobs) {
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	}
+	return ver, nil
+}
+
+func isAddSubJob(sub *model.SubJob) bool {
+	return sub.Type == model.ActionAddColumn || sub.Type == model.ActionAddIndex
+}
+
+// isDropStarted returns whether the dropped elements are not public.
+func isDropStarted(subJobs []*model.SubJob) bool {
+	for _, sub := range subJobs {
+		if !isAddSubJob(sub) {
+			return sub.SchemaState != model.StatePublic
+		}
+	}
+	return false
+}
+
+// isMultiSchemaChangeDone returns whether all the added elements are public and all the dropped ones are absent.
+func isMultiSchemaChangeDone(subJobs []*model.SubJob) bool {
+	for _, sub := range subJobs {
+		if isAddSubJob(sub) && sub.SchemaState != model.StatePublic {
+			return false
+		}
+		if !isAddSubJob(sub) && sub.SchemaState != model.StateNone {
+			return false
+		}
+	}
+	return true
+}
+
+// subJobElementName returns the name of the column or the index of the sub-job.
+func subJobElementName(sub *model.SubJob) (model.CIStr, error) {
+	var name model.CIStr
+	var err error
+	switch sub.Type {
+	case model.ActionAddColumn:
+		col := &model.ColumnInfo{}
+		err = sub.DecodeArgs(col)
+		name = col.Name
+	case model.ActionAddIndex:
+		var unique bool
+		err = sub.DecodeArgs(&unique, &name)
+	case model.ActionDropColumn, model.ActionDropIndex:
+		err = sub.DecodeArgs(&name)
+	default:
+		err = errInvalidDDLJob.Gen("invalid sub-job type %v", sub.Type)
+	}
+	return name, errors.Trace(err)
+}
+
+// createMultiSchemaElements creates the added columns and indices in none state.
+func (d *ddl) createMultiSchemaElements(tblInfo *model.TableInfo, subJobs []*model.SubJob) error {
+	for _, sub := range subJobs {
+		switch sub.Type {
+		case model.ActionAddColumn:
+			col := &model.ColumnInfo{}
+			pos := &ast.ColumnPosition{}
+			offset := 0
+			if err := sub.DecodeArgs(col, pos, &offset); err != nil {
+				return errors.Trace(err)
+			}
+			if findCol(tblInfo.Columns, col.Name.L) != nil {
+				return infoschema.ErrColumnExists.GenByArgs(col.Name)
+			}
+			if _, _, err := d.createColumnInfo(tblInfo, col, pos); err != nil {
+				return errors.Trace(err)
+			}
+		case model.ActionAddIndex:
+			var (
+				unique      bool
+				indexName   model.CIStr
+				idxColNames []*ast.IndexColName
+				indexOption *ast.IndexOption
+			)
+			if err := sub.DecodeArgs(&unique, &indexName, &idxColNames, &indexOption); err != nil {
+				return errors.Trace(err)
+			}
+			if findIndexByName(indexName.L, tblInfo.Indices) != nil {
+				return ErrDupKeyName.Gen("index already exist %s", indexName)
+			}
+			if _, err := createIndexInfo(tblInfo, unique, indexName, idxColNames, indexOption); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+// setAddedElementsState sets the state of the added columns and indices.
+func setAddedElementsState(tblInfo *model.TableInfo, subJobs []*model.SubJob, state model.SchemaState) error {
+	for _, sub := range subJobs {
+		if !isAddSubJob(sub) {
+			continue
+		}
+		if err := setElementState(tblInfo, sub, state); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// setElementState sets the state of the column or the index of the sub-job.
+func setElementState(tblInfo *model.TableInfo, sub *model.SubJob, state model.SchemaState) error {
+	name, err := subJobElementName(sub)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	switch sub.Type {
+	case model.ActionAddColumn, model.ActionDropColumn:
+		colInfo := findCol(tblInfo.Columns, name.L)
+		if colInfo == nil {
+			return ErrInvalidColumnState.Gen("column %s doesn't exist", name)
+		}
+		colInfo.State = state
+	case model.ActionAddIndex, model.ActionDropIndex:
+		indexInfo := findIndexByName(name.L, tblInfo.Indices)
+		if indexInfo == nil {
+			return ErrInvalidIndexState.Gen("index %s doesn't exist", name)
+		}
+		indexInfo.State = state
+	}
+	sub.SchemaState = state
+	return nil
+}
+
+// backfillMultiSchemaIndices backfills the added indices one by one, every index starts from a new snapshot.
+// It returns true when all the added indices are backfilled.
+func (d *ddl) backfillMultiSchemaIndices(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo,
+	subJobs []*model.SubJob) (bool, error) {
+	for _, sub := range subJobs {
+		if sub.Type != model.ActionAddIndex || sub.ReorgDone {
+			continue
+		}
+		name, err := subJobElementName(sub)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		indexInfo := findIndexByName(name.L, tblInfo.Indices)
+		if indexInfo == nil {
+			return false, ErrInvalidIndexState.Gen("index %s doesn't exist", name)
+		}
+
+		reorgInfo, err := d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return false, errors.Trace(err)
+		}
+		tbl, err := d.getTable(job.SchemaID, tblInfo)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		err = d.runReorgJob(job, func() error {
+			return d.addTableIndex(tbl, indexInfo, reorgInfo, job)
+		})
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+
+		sub.ReorgDone = true
+		job.SnapshotVer = 0
+		err = t.RemoveDDLReorgHandle(job)
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// publishMultiSchemaElements makes the added columns and indices public and the dropped ones write only.
+func (d *ddl) publishMultiSchemaElements(job *model.Job, tblInfo *model.TableInfo, subJobs []*model.SubJob) error {
+	job.SchemaState = model.StatePublic
+	for _, sub := range subJobs {
+		state := model.StatePublic
+		if !isAddSubJob(sub) {
+			state = model.StateWriteOnly
+			job.SchemaState = model.StateWriteOnly
+		}
+		if err := setElementState(tblInfo, sub, state); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	adjustColumnOffsets(tblInfo)
+
+	for _, sub := range subJobs {
+		name, err := subJobElementName(sub)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		switch sub.Type {
+		case model.ActionAddColumn:
+			colInfo := findCol(tblInfo.Columns, name.L)
+			d.asyncNotifyEvent(&Event{Tp: model.ActionAddColumn, TableInfo: tblInfo, ColumnInfo: colInfo})
+		case model.ActionAddIndex:
+			// Set column index flag.
+			addIndexColumnFlag(tblInfo, findIndexByName(name.L, tblInfo.Indices))
+		}
+	}
+	return nil
+}
+
+// dropMultiSchemaElements moves the dropped columns and indices to the next state, and removes them in the end.
+func (d *ddl) dropMultiSchemaElements(job *model.Job, tblInfo *model.TableInfo, subJobs []*model.SubJob) error {
+	var indexIDs []int64
+	for _, sub := range subJobs {
+		if isAddSubJob(sub) {
+			continue
+		}
+		switch sub.SchemaState {
+		case model.StateWriteOnly:
+			// write only -> delete only
+			job.SchemaState = model.StateDeleteOnly
+			if err := setElementState(tblInfo, sub, model.StateDeleteOnly); err != nil {
+				return errors.Trace(err)
+			}
+		case model.StateDeleteOnly:
+			// delete only -> reorganization
+			job.SchemaState = model.StateDeleteReorganization
+			if err := setElementState(tblInfo, sub, model.StateDeleteReorganization); err != nil {
+				return errors.Trace(err)
+			}
+		case model.StateDeleteReorganization:
+			// reorganization -> absent
+			job.SchemaState = model.StateNone
+			id, err := d.removeElement(tblInfo, sub)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if sub.Type == model.ActionDropIndex {
+				indexIDs = append(indexIDs, id)
+			}
+			sub.SchemaState = model.StateNone
+		}
+	}
+	if len(indexIDs) > 0 {
+		// The dropped indices are deleted by the delete-range worker.
+		job.Args = append(job.Args, indexIDs)
+	}
+	return nil
+}
+
+// removeElement removes the column or the index of the sub-job from the table, it returns the ID of the element.
+func (d *ddl) removeElement(tblInfo *model.TableInfo, sub *model.SubJob) (int64, error) {
+	name, err := subJobElementName(sub)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	switch sub.Type {
+	case model.ActionAddColumn, model.ActionDropColumn:
+		colInfo := findCol(tblInfo.Columns, name.L)
+		if colInfo == nil {
+			return 0, ErrInvalidColumnState.Gen("column %s doesn't exist", name)
+		}
+		newColumns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+		for _, col := range tblInfo.Columns {
+			if col.Name.L != name.L {
+				newColumns = append(newColumns, col)
+			}
+		}
+		tblInfo.Columns = newColumns
+		if sub.Type == model.ActionDropColumn {
+			d.asyncNotifyEvent(&Event{Tp: model.ActionDropColumn, TableInfo: tblInfo, ColumnInfo: colInfo})
+		}
+		return colInfo.ID, nil
+	default:
+		indexInfo := findIndexByName(name.L, tblInfo.Indices)
+		if indexInfo == nil {
+			return 0, ErrInvalidIndexState.Gen("index %s doesn't exist", name)
+		}
+		newIndices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+		for _, idx := range tblInfo.Indices {
+			if idx.Name.L != name.L {
+				newIndices = append(newIndices, idx)
+			}
+		}
+		tblInfo.Indices = newIndices
+		if sub.Type == model.ActionDropIndex {
+			// Set column index flag.
+			dropIndexColumnFlag(tblInfo, indexInfo)
+			d.asyncNotifyEvent(&Event{Tp: model.ActionDropIndex, TableInfo: tblInfo, IndexInfo: indexInfo})
+		}
+		return indexInfo.ID, nil
+	}
+}
+
+// adjustColumnOffsets puts the public columns before the others, and sets the offsets of all the columns
+// and the index columns by the new order.
+func adjustColumnOffsets(tblInfo *model.TableInfo) {
+	columns := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.State == model.StatePublic {
+			columns = append(columns, col)
+		}
+	}
+	for _, col := range tblInfo.Columns {
+		if col.State != model.StatePublic {
+			columns = append(columns, col)
+		}
+	}
+	for i, col := range columns {
+		col.Offset = i
+	}
+	tblInfo.Columns = columns
+
+	for _, idx := range tblInfo.Indices {
+		for _, idxCol := range idx.Columns {
+			if col := findCol(columns, idxCol.Name.L); col != nil {
+				idxCol.Offset = col.Offset
+			}
+		}
+	}
+}
+
+// convertMultiSchemaChange2Rollback converts the job to a rollback job, it returns the error that causes the rollback.
+func convertMultiSchemaChange2Rollback(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo,
+	subJobs []*model.SubJob, rollbackErr error) (ver int64, _ error) {
+	job.State = model.JobRollback
+	// The added elements in write reorganization state are like the dropped ones in write only state,
+	// so the next state is delete only.
+	if err := setAddedElementsState(tblInfo, subJobs, model.StateDeleteOnly); err != nil {
+		return ver, errors.Trace(err)
+	}
+	originalState := job.SchemaState
+	job.SchemaState = model.StateDeleteOnly
+	ver, err := updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	return ver, errors.Trace(rollbackErr)
+}
+
+// duplicateIndexName returns the name of the index that was being backfilled.
+func duplicateIndexName(subJobs []*model.SubJob) string {
+	for _, sub := range subJobs {
+		if sub.Type == model.ActionAddIndex && !sub.ReorgDone {
+			name, err := subJobElementName(sub)
+			if err == nil {
+				return name.O
+			}
+		}
+	}
+	return ""
+}
+
+// rollbackMultiSchemaChange removes the added columns and indices, the dropped ones are still public.
+func (d *ddl) rollbackMultiSchemaChange(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo,
+	subJobs []*model.SubJob) (ver int64, err error) {
+	originalState := job.SchemaState
+	switch job.SchemaState {
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		err = setAddedElementsState(tblInfo, subJobs, model.StateDeleteReorganization)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		job.SchemaState = model.StateNone
+		var indexIDs []int64
+		for _, sub := range subJobs {
+			if !isAddSubJob(sub) {
+				continue
+			}
+			var id int64
+			id, err = d.removeElement(tblInfo, sub)
+			if err != nil {
+				return ver, errors.Trace(err)
+			}
+			if sub.Type == model.ActionAddIndex {
+				indexIDs = append(indexIDs, id)
+			}
+			sub.SchemaState = model.StateNone
+		}
+		// The keys of the added indices are deleted by the delete-range worker.
+		job.Args = append(job.Args, indexIDs)
+	default:
+		err = ErrInvalidTableState.Gen("invalid multi schema change rollback state %v", job.SchemaState)
+	}
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if job.SchemaState == model.StateNone {
+		job.State = model.JobRollbackDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	}
+	return ver, nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/tablecodec"
+)
+
+// defaultPlacementReplicas is the number of the replicas of a placement policy without REPLICAS.
+const defaultPlacementReplicas = 3
+
+// buildPlacementPolicy builds the placement policy from the options of ALTER TABLE ... PLACEMENT POLICY, the
+// policy is nil for PLACEMENT POLICY DEFAULT.
+func buildPlacementPolicy(options []*ast.PlacementOption) (*model.PlacementPolicy, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	policy := &model.PlacementPolicy{}
+	var err error
+	for _, op := range options {
+		switch op.Tp {
+		case ast.PlacementOptionReplicas:
+			if op.UintValue == 0 {
+				return nil, errInvalidPlacementPolicy.GenByArgs("REPLICAS must be positive")
+			}
+			policy.Replicas = op.UintValue
+		case ast.PlacementOptionConstraints:
+			if policy.Constraints, err = parseLabelConstraints(op.StrValue); err != nil {
+				return nil, errors.Trace(err)
+			}
+		case ast.PlacementOptionLeaderConstraints:
+			if policy.LeaderConstraints, err = parseLabelConstraints(op.StrValue); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// parseLabelConstraints parses the comma separated label constraints, like "+zone=sh,-disk=hdd". A label with
+// "+" or without a prefix is required, a label with "-" is excluded.
+func parseLabelConstraints(s string) ([]*model.LabelConstraint, error) {
+	var constraints []*model.LabelConstraint
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		c := &model.LabelConstraint{}
+		switch item[0] {
+		case '-':
+			c.Exclude = true
+			item = item[1:]
+		case '+':
+			item = item[1:]
+		}
+		pair := strings.SplitN(item, "=", 2)
+		if len(pair) != 2 {
+			return nil, errInvalidPlacementPolicy.GenByArgs(fmt.Sprintf("label constraint %q isn't in the form of key=value", item))
+		}
+		c.Key, c.Value = strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+		if c.Key == "" || c.Value == "" {
+			return nil, errInvalidPlacementPolicy.GenByArgs(fmt.Sprintf("label constraint %q has an empty key or value", item))
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// placementRuleGroup returns the group of the placement rules of the table.
+func placementRuleGroup(tableID int64) string {
+	return fmt.Sprintf("tidb_table_%d", tableID)
+}
+
+func toPlacementLabelConstraints(constraints []*model.LabelConstraint) []kv.PlacementLabelConstraint {
+	res := make([]kv.PlacementLabelConstraint, 0, len(constraints))
+	for _, c := range constraints {
+		res = append(res, kv.PlacementLabelConstraint{Key: c.Key, Value: c.Value, Exclude: c.Exclude})
+	}
+	return res
+}
+
+// buildPlacementRules translates the placement policy of the table into the placement rules on the range of
+// the table's rows and indices. With the leader constraints, a leader replica is placed by its own rule, and
+// the other replicas are the voters.
+func buildPlacementRules(tableID int64, policy *model.PlacementPolicy) []*kv.PlacementRule {
+	if policy == nil {
+		return nil
+	}
+	startKey := tablecodec.EncodeTablePrefix(tableID)
+	endKey := tablecodec.EncodeTablePrefix(tableID + 1)
+	count := int(policy.Replicas)
+	if count == 0 {
+		count = defaultPlacementReplicas
+	}
+	var rules []*kv.PlacementRule
+	if len(policy.LeaderConstraints) > 0 {
+		rules = append(rules, &kv.PlacementRule{
+			ID:               "leader",
+			StartKey:         startKey,
+			EndKey:           endKey,
+			Role:             "leader",
+			Count:            1,
+			LabelConstraints: toPlacementLabelConstraints(append(policy.Constraints, policy.LeaderConstraints...)),
+		})
+		count--
+	}
+	if count > 0 {
+		rules = append(rules, &kv.PlacementRule{
+			ID:               "voter",
+			StartKey:         startKey,
+			EndKey:           endKey,
+			Role:             "voter",
+			Count:            count,
+			LabelConstraints: toPlacementLabelConstraints(policy.Constraints),
+		})
+	}
+	return rules
+}
+
+// setPlacementRules sets the placement rules of the table in the storage, the rules are removed if the policy
+// is nil.
+func (d *ddl) setPlacementRules(tableID int64, policy *model.PlacementPolicy) error {
+	store, ok := d.store.(kv.PlacementStore)
+	if !ok {
+		if policy == nil {
+			return nil
+		}
+		return errors.Trace(errUnsupportedPlacementPolicy)
+	}
+	err := store.SetPlacementRules(placementRuleGroup(tableID), buildPlacementRules(tableID, policy))
+	return errors.Trace(err)
+}
+
+// removePlacementRules removes the placement rules of a dropped table with the placement policy. The table is
+// dropped anyway, so the error is only logged, the rules on the range without data can be removed later by hand.
+func (d *ddl) removePlacementRules(tableID int64, policy *model.PlacementPolicy) {
+	if policy == nil {
+		return
+	}
+	if err := d.setPlacementRules(tableID, nil); err != nil {
+		log.Warnf("[ddl] remove the placement rules of table %d error %v", tableID, errors.ErrorStack(err))
+	}
+}
+
+func (d *ddl) onAlterPlacementPolicy(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var policy *model.PlacementPolicy
+	if err := job.DecodeArgs(&policy); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	// The rules are set before the table info, so the policy of the table is in effect if the job is done.
+	if err = d.setPlacementRules(tblInfo.ID, policy); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo.PlacementPolicy = policy
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testPlacementSuite{})
+
+type testPlacementSuite struct{}
+
+func (s *testPlacementSuite) TestBuildPlacementPolicy(c *C) {
+	defer testleak.AfterTest(c)()
+	policy, err := buildPlacementPolicy(nil)
+	c.Assert(err, IsNil)
+	c.Assert(policy, IsNil)
+
+	policy, err = buildPlacementPolicy([]*ast.PlacementOption{
+		{Tp: ast.PlacementOptionReplicas, UintValue: 5},
+		{Tp: ast.PlacementOptionConstraints, StrValue: " +zone=sh, -disk = hdd,,rack=r1"},
+		{Tp: ast.PlacementOptionLeaderConstraints, StrValue: "host=h1"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(policy, DeepEquals, &model.PlacementPolicy{
+		Replicas: 5,
+		Constraints: []*model.LabelConstraint{
+			{Key: "zone", Value: "sh"},
+			{Key: "disk", Value: "hdd", Exclude: true},
+			{Key: "rack", Value: "r1"},
+		},
+		LeaderConstraints: []*model.LabelConstraint{{Key: "host", Value: "h1"}},
+	})
+	c.Assert(policy.Constraints[1].String(), Equals, "-disk=hdd")
+
+	for _, option := range []*ast.PlacementOption{
+		{Tp: ast.PlacementOptionReplicas, UintValue: 0},
+		{Tp: ast.PlacementOptionConstraints, StrValue: "zone"},
+		{Tp: ast.PlacementOptionConstraints, StrValue: "+=sh"},
+		{Tp: ast.PlacementOptionLeaderConstraints, StrValue: "zone="},
+	} {
+		_, err = buildPlacementPolicy([]*ast.PlacementOption{option})
+		c.Assert(terror.ErrorEqual(err, errInvalidPlacementPolicy), IsTrue, Commentf("%v", option))
+	}
+}
+
+func (s *testPlacementSuite) TestBuildPlacementRules(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(buildPlacementRules(1, nil), HasLen, 0)
+
+	startKey, endKey := kv.Key(tablecodec.EncodeTablePrefix(1)), kv.Key(tablecodec.EncodeTablePrefix(2))
+	zone := &model.LabelConstraint{Key: "zone", Value: "sh"}
+	rules := buildPlacementRules(1, &model.PlacementPolicy{Constraints: []*model.LabelConstraint{zone}})
+	c.Assert(rules, DeepEquals, []*kv.PlacementRule{{
+		ID:               "voter",
+		StartKey:         startKey,
+		EndKey:           endKey,
+		Role:             "voter",
+		Count:            defaultPlacementReplicas,
+		LabelConstraints: []kv.PlacementLabelConstraint{{Key: "zone", Value: "sh"}},
+	}})
+
+	// The leader is placed by its own rule with the leader constraints.
+	rules = buildPlacementRules(1, &model.PlacementPolicy{
+		Replicas:          2,
+		Constraints:       []*model.LabelConstraint{zone},
+		LeaderConstraints: []*model.LabelConstraint{{Key: "disk", Value: "hdd", Exclude: true}},
+	})
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].Role, Equals, "leader")
+	c.Assert(rules[0].Count, Equals, 1)
+	c.Assert(rules[0].LabelConstraints, DeepEquals, []kv.PlacementLabelConstraint{
+		{Key: "zone", Value: "sh"},
+		{Key: "disk", Value: "hdd", Exclude: true},
+	})
+	c.Assert(rules[1].Role, Equals, "voter")
+	c.Assert(rules[1].Count, Equals, 1)
+	rules = buildPlacementRules(1, &model.PlacementPolicy{
+		Replicas:          1,
+		LeaderConstraints: []*model.LabelConstraint{zone},
+	})
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Role, Equals, "leader")
+}
@@ -14,16 +14,17 @@
 package ddl
 
 import (
+	"strconv"
 	"sync/atomic"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/mock"
 )
 
@@ -69,19 +70,19 @@ func (d *ddl) runReorgJob(job *model.Job, f func() error) error {
 	// wait reorganization job done or timeout
 	select {
 	case err := <-d.reorgDoneCh:
-		log.Info("[ddl] run reorg job done")
+		ddlLogger().Info("[ddl] run reorg job done")
 		d.reorgDoneCh = nil
 		// Update a job's RowCount.
 		job.SetRowCount(d.getReorgRowCount())
 		d.setReorgRowCount(0)
 		return errors.Trace(err)
 	case <-d.quitCh:
-		log.Info("[ddl] run reorg job ddl quit")
+		ddlLogger().Info("[ddl] run reorg job ddl quit")
 		d.setReorgRowCount(0)
 		// We return errWaitReorgTimeout here too, so that outer loop will break.
 		return errWaitReorgTimeout
 	case <-time.After(waitTimeout):
-		log.Infof("[ddl] run reorg job wait timeout %v", waitTimeout)
+		ddlLogger().Infof("[ddl] run reorg job wait timeout %v", waitTimeout)
 		// Update a job's RowCount.
 		job.SetRowCount(d.getReorgRowCount())
 		// If timeout, we will return, check the owner and retry to wait job done again.
@@ -89,20 +90,79 @@ func (d *ddl) runReorgJob(job *model.Job, f func() error) error {
 	}
 }
 
+// stopReorgJob stops the running reorganization and waits for it to exit.
+// The handled rows are recorded by the reorganization handle, so the job can go on from there later.
+func (d *ddl) stopReorgJob() {
+	if d.reorgDoneCh == nil {
+		return
+	}
+
+	atomic.StoreInt32(&d.reorgStopped, 1)
+	err := <-d.reorgDoneCh
+	atomic.StoreInt32(&d.reorgStopped, 0)
+	d.reorgDoneCh = nil
+	d.setReorgRowCount(0)
+	ddlLogger().Infof("[ddl] stop reorg job, err %v", err)
+}
+
 func (d *ddl) isReorgRunnable(txn kv.Transaction) error {
 	if d.isClosed() {
 		// worker is closed, can't run reorganization.
 		return errInvalidWorker.Gen("worker is closed")
 	}
 
+	if atomic.LoadInt32(&d.reorgStopped) == 1 {
+		// The job is paused or cancelled.
+		return errors.Trace(errReorgStopped)
+	}
+
 	if !d.isOwner() {
 		// If it's not the owner, we will try later, so here just returns an error.
-		log.Infof("[ddl] the %s not the job owner, txnTS:%d", d.uuid, txn.StartTS())
+		ddlLogger().Infof("[ddl] the %s not the job owner, txnTS:%d", d.uuid, txn.StartTS())
 		return errors.Trace(errNotOwner)
 	}
 	return nil
 }
 
+// loadReorgVars loads the number of the concurrent backfill tasks in a round and the number of rows
+// that a task handles. They are global variables, so they can be changed while the reorganization runs,
+// the default values are used if they can't be loaded.
+func (d *ddl) loadReorgVars() (workerCnt int, batchCnt int) {
+	workerCnt, batchCnt = variable.DefDDLReorgWorkerCount, variable.DefDDLReorgBatchSize
+	if d.ctxPool == nil {
+		return
+	}
+	resource, err := d.ctxPool.Get()
+	if err != nil {
+		ddlLogger().Warnf("[ddl] load reorg variables failed %v, use the default values", err)
+		return
+	}
+	defer d.ctxPool.Put(resource)
+	ctx := resource.(context.Context)
+	ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusAutocommit, true)
+	ctx.GetSessionVars().InRestrictedSQL = true
+	accessor := ctx.GetSessionVars().GlobalVarsAccessor
+	workerCnt = loadReorgVar(accessor, variable.TiDBDDLReorgWorkerCount, workerCnt)
+	batchCnt = loadReorgVar(accessor, variable.TiDBDDLReorgBatchSize, batchCnt)
+	return
+}
+
+func loadReorgVar(accessor variable.GlobalVarAccessor, name string, defaultVal int) int {
+	if accessor == nil {
+		return defaultVal
+	}
+	str, err := accessor.GetGlobalSysVar(name)
+	if err != nil {
+		ddlLogger().Warnf("[ddl] load reorg variable %s failed %v, use the default value", name, err)
+		return defaultVal
+	}
+	val, err := strconv.Atoi(str)
+	if err != nil || val <= 0 {
+		return defaultVal
+	}
+	return val
+}
+
 type reorgInfo struct {
 	*model.Job
 	Handle int64
@@ -121,6 +121,9 @@ func (d *ddl) onDropSchema(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		}
 		job.State = model.JobDone
 		job.SchemaState = model.StateNone
+		for _, tblInfo := range tables {
+			d.removePlacementRules(tblInfo.ID, tblInfo.PlacementPolicy)
+		}
 		for _, tblInfo := range dbInfo.Tables {
 			d.asyncNotifyEvent(&Event{Tp: model.ActionDropTable, TableInfo: tblInfo})
 		}
@@ -65,23 +65,25 @@ func (d *ddl) Stats(vars *variable.SessionVars) (map[string]interface{}, error)
 
 	m[ddlSchemaVersion] = ddlInfo.SchemaVer
 	// TODO: Get the owner information.
-	if ddlInfo.Job != nil {
-		m[ddlJobID] = ddlInfo.Job.ID
-		m[ddlJobAction] = ddlInfo.Job.Type.String()
-		m[ddlJobLastUpdateTS] = ddlInfo.Job.LastUpdateTS / 1e9
-		m[ddlJobState] = ddlInfo.Job.State.String()
-		m[ddlJobRows] = ddlInfo.Job.RowCount
-		if ddlInfo.Job.Error == nil {
+	// TODO: Show the jobs of all the job queues.
+	if len(ddlInfo.Jobs) != 0 {
+		job := ddlInfo.Jobs[0]
+		m[ddlJobID] = job.ID
+		m[ddlJobAction] = job.Type.String()
+		m[ddlJobLastUpdateTS] = job.LastUpdateTS / 1e9
+		m[ddlJobState] = job.State.String()
+		m[ddlJobRows] = job.RowCount
+		if job.Error == nil {
 			m[ddlJobError] = ""
 		} else {
-			m[ddlJobError] = ddlInfo.Job.Error.Error()
+			m[ddlJobError] = job.Error.Error()
 		}
-		m[ddlJobSchemaState] = ddlInfo.Job.SchemaState.String()
-		m[ddlJobSchemaID] = ddlInfo.Job.SchemaID
-		m[ddlJobTableID] = ddlInfo.Job.TableID
-		m[ddlJobSnapshotVer] = ddlInfo.Job.SnapshotVer
+		m[ddlJobSchemaState] = job.SchemaState.String()
+		m[ddlJobSchemaID] = job.SchemaID
+		m[ddlJobTableID] = job.TableID
+		m[ddlJobSnapshotVer] = job.SnapshotVer
 		m[ddlJobReorgHandle] = ddlInfo.ReorgHandle
-		m[ddlJobArgs] = ddlInfo.Job.Args
+		m[ddlJobArgs] = job.Args
 	}
 	return m, nil
 }
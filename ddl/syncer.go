@@ -19,7 +19,6 @@ import (
 	"strconv"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/coreos/etcd/clientv3"
 	"github.com/coreos/etcd/clientv3/concurrency"
 	"github.com/juju/errors"
@@ -34,6 +33,10 @@ const (
 	// DDLGlobalSchemaVersion is the path on etcd that is used to store the latest schema versions.
 	// It's exported for testing.
 	DDLGlobalSchemaVersion = "/tidb/ddl/global_schema_version"
+	// ddlJobQueuedPath is the path on etcd that is used to notify the owner that a job is put in a job queue.
+	ddlJobQueuedPath = "/tidb/ddl/job_queued"
+	// ddlJobDonePath is the path on etcd that is used to notify the servers that a job is finished.
+	ddlJobDonePath = "/tidb/ddl/job_done"
 	// InitialVersion is the initial schema version for every server.
 	// It's exported for testing.
 	InitialVersion       = "0"
@@ -42,13 +45,9 @@ const (
 	putKeyRetryUnlimited = math.MaxInt64
 	keyOpDefaultTimeout  = 2 * time.Second
 	keyOpRetryInterval   = 30 * time.Millisecond
-	checkVersInterval    = 20 * time.Millisecond
 )
 
 var (
-	// CheckVersFirstWaitTime is a waitting time before the owner checks all the servers of the schema version,
-	// and it's an exported variable for testing.
-	CheckVersFirstWaitTime = 50 * time.Millisecond
 	// SyncerSessionTTL is the etcd session's TTL in seconds.
 	// and it's an exported variable for testing.
 	SyncerSessionTTL = 10 * 60
@@ -66,7 +65,15 @@ type SchemaSyncer interface {
 	// OwnerUpdateGlobalVersion updates the latest version to the global path on etcd until updating is successful or the ctx is done.
 	OwnerUpdateGlobalVersion(ctx goctx.Context, version int64) error
 	// GlobalVersionCh gets the chan for watching global version.
-	GlobalVersionCh() clientv3.WatchChan
+	GlobalVersionCh() <-chan struct{}
+	// NotifyJobQueued notifies the owner that the job is put in a job queue.
+	NotifyJobQueued(ctx goctx.Context, jobID int64) error
+	// JobQueuedCh gets the chan for watching the jobs that are put in the job queues.
+	JobQueuedCh() <-chan struct{}
+	// NotifyJobDone notifies the servers that the job is finished.
+	NotifyJobDone(ctx goctx.Context, jobID int64) error
+	// JobDoneCh gets the chan for watching the finished jobs.
+	JobDoneCh() <-chan struct{}
 	// MustGetGlobalVersion gets the global version. The only reason it fails is that ctx is done.
 	MustGetGlobalVersion(ctx goctx.Context) (int64, error)
 	// Done() returns a channel that closes when the syncer is no longer being refreshed.
@@ -74,7 +81,7 @@ type SchemaSyncer interface {
 	// Restart restarts the syncer when it's on longer being refreshed.
 	Restart(ctx goctx.Context) error
 	// OwnerCheckAllVersions checks whether all followers' schema version are equal to
-	// the latest schema version. If the result is false, wait for a while and check again util the processing time reach 2 * lease.
+	// the latest schema version. If the result is false, wait for a follower to update its version and check again.
 	// It returns until all servers' versions are equal to the latest version or the ctx is done.
 	OwnerCheckAllVersions(ctx goctx.Context, latestVer int64) error
 }
@@ -83,7 +90,11 @@ type schemaVersionSyncer struct {
 	selfSchemaVerPath string
 	etcdCli           *clientv3.Client
 	session           *concurrency.Session
-	globalVerCh       clientv3.WatchChan
+	// The chans are notified when the watched paths change, they are created before Init,
+	// so they can be used before the watches start.
+	globalVerCh chan struct{}
+	jobQueuedCh chan struct{}
+	jobDoneCh   chan struct{}
 }
 
 // NewSchemaSyncer creates a new SchemaSyncer.
@@ -91,6 +102,9 @@ func NewSchemaSyncer(etcdCli *clientv3.Client, id string) SchemaSyncer {
 	return &schemaVersionSyncer{
 		etcdCli:           etcdCli,
 		selfSchemaVerPath: fmt.Sprintf("%s/%s", DDLAllSchemaVersions, id),
+		globalVerCh:       make(chan struct{}, 1),
+		jobQueuedCh:       make(chan struct{}, 1),
+		jobDoneCh:         make(chan struct{}, 1),
 	}
 }
 
@@ -108,7 +122,7 @@ func (s *schemaVersionSyncer) putKV(ctx goctx.Context, retryCnt int, key, val st
 		if err == nil {
 			return nil
 		}
-		log.Warnf("[syncer] put schema version %s failed %v no.%d", val, err, i)
+		ddlLogger().Warnf("[syncer] put schema version %s failed %v no.%d", val, err, i)
 		time.Sleep(keyOpRetryInterval)
 	}
 	return errors.Trace(err)
@@ -128,7 +142,9 @@ func (s *schemaVersionSyncer) Init(ctx goctx.Context) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	s.globalVerCh = s.etcdCli.Watch(ctx, DDLGlobalSchemaVersion)
+	go s.watchPath(ctx, DDLGlobalSchemaVersion, s.globalVerCh)
+	go s.watchPath(ctx, ddlJobQueuedPath, s.jobQueuedCh)
+	go s.watchPath(ctx, ddlJobDonePath, s.jobDoneCh)
 	return s.putKV(ctx, keyOpDefaultRetryCnt, s.selfSchemaVerPath, InitialVersion,
 		clientv3.WithLease(s.session.Lease()))
 }
@@ -150,11 +166,53 @@ func (s *schemaVersionSyncer) Restart(ctx goctx.Context) error {
 		clientv3.WithLease(s.session.Lease()))
 }
 
+// watchPath watches the path and notifies ch when the path changes.
+// The watch is created again if it's closed, until the ctx is done or the etcd client is closed.
+func (s *schemaVersionSyncer) watchPath(ctx goctx.Context, path string, ch chan struct{}) {
+	for {
+		for resp := range s.etcdCli.Watch(ctx, path) {
+			if resp.Err() != nil {
+				ddlLogger().Infof("[syncer] watch %s failed %v", path, resp.Err())
+				continue
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+		if isContextDone(ctx) || isContextDone(s.etcdCli.Ctx()) {
+			return
+		}
+		ddlLogger().Infof("[syncer] watch %s is closed, watch it again", path)
+		time.Sleep(keyOpRetryInterval)
+	}
+}
+
 // GlobalVersionCh implements SchemaSyncer.GlobalVersionCh interface.
-func (s *schemaVersionSyncer) GlobalVersionCh() clientv3.WatchChan {
+func (s *schemaVersionSyncer) GlobalVersionCh() <-chan struct{} {
 	return s.globalVerCh
 }
 
+// NotifyJobQueued implements SchemaSyncer.NotifyJobQueued interface.
+func (s *schemaVersionSyncer) NotifyJobQueued(ctx goctx.Context, jobID int64) error {
+	return s.putKV(ctx, putKeyNoRetry, ddlJobQueuedPath, strconv.FormatInt(jobID, 10))
+}
+
+// JobQueuedCh implements SchemaSyncer.JobQueuedCh interface.
+func (s *schemaVersionSyncer) JobQueuedCh() <-chan struct{} {
+	return s.jobQueuedCh
+}
+
+// NotifyJobDone implements SchemaSyncer.NotifyJobDone interface.
+func (s *schemaVersionSyncer) NotifyJobDone(ctx goctx.Context, jobID int64) error {
+	return s.putKV(ctx, putKeyNoRetry, ddlJobDonePath, strconv.FormatInt(jobID, 10))
+}
+
+// JobDoneCh implements SchemaSyncer.JobDoneCh interface.
+func (s *schemaVersionSyncer) JobDoneCh() <-chan struct{} {
+	return s.jobDoneCh
+}
+
 // UpdateSelfVersion implements SchemaSyncer.UpdateSelfVersion interface.
 func (s *schemaVersionSyncer) UpdateSelfVersion(ctx goctx.Context, version int64) error {
 	ver := strconv.FormatInt(version, 10)
@@ -179,7 +237,7 @@ func (s *schemaVersionSyncer) RemoveSelfVersionPath() error {
 		if err == nil {
 			return nil
 		}
-		log.Warnf("remove schema version path %s failed %v no.%d", s.selfSchemaVerPath, err, i)
+		ddlLogger().Warnf("remove schema version path %s failed %v no.%d", s.selfSchemaVerPath, err, i)
 	}
 	return errors.Trace(err)
 }
@@ -193,7 +251,7 @@ func (s *schemaVersionSyncer) MustGetGlobalVersion(ctx goctx.Context) (int64, er
 	for {
 		if err != nil {
 			if failedCnt%intervalCnt == 0 {
-				log.Infof("[syncer] get global version failed %v", err)
+				ddlLogger().Infof("[syncer] get global version failed %v", err)
 			}
 			time.Sleep(keyOpRetryInterval)
 			failedCnt++
@@ -228,9 +286,11 @@ func isContextDone(ctx goctx.Context) bool {
 
 // OwnerCheckAllVersions implements SchemaSyncer.OwnerCheckAllVersions interface.
 func (s *schemaVersionSyncer) OwnerCheckAllVersions(ctx goctx.Context, latestVer int64) error {
-	time.Sleep(CheckVersFirstWaitTime)
+	// Watch the versions before getting them, so the updates after getting them aren't missed.
+	watchCtx, cancel := goctx.WithCancel(ctx)
+	defer cancel()
+	watchCh := s.etcdCli.Watch(watchCtx, DDLAllSchemaVersions, clientv3.WithPrefix())
 	notMatchVerCnt := 0
-	intervalCnt := int(time.Second / checkVersInterval)
 	updatedMap := make(map[string]struct{})
 	for {
 		if isContextDone(ctx) {
@@ -239,7 +299,8 @@ func (s *schemaVersionSyncer) OwnerCheckAllVersions(ctx goctx.Context, latestVer
 
 		resp, err := s.etcdCli.Get(ctx, DDLAllSchemaVersions, clientv3.WithPrefix())
 		if err != nil {
-			log.Infof("[syncer] check all versions failed %v", err)
+			ddlLogger().Infof("[syncer] check all versions failed %v", err)
+			time.Sleep(keyOpRetryInterval)
 			continue
 		}
 
@@ -251,13 +312,13 @@ func (s *schemaVersionSyncer) OwnerCheckAllVersions(ctx goctx.Context, latestVer
 
 			ver, err := strconv.Atoi(string(kv.Value))
 			if err != nil {
-				log.Infof("[syncer] check all versions, ddl %s convert %v to int failed %v", kv.Key, kv.Value, err)
+				ddlLogger().Infof("[syncer] check all versions, ddl %s convert %v to int failed %v", kv.Key, kv.Value, err)
 				succ = false
 				break
 			}
 			if int64(ver) != latestVer {
-				if notMatchVerCnt%intervalCnt == 0 {
-					log.Infof("[syncer] check all versions, ddl %s current ver %v, latest version %v",
+				if notMatchVerCnt == 0 {
+					ddlLogger().Infof("[syncer] check all versions, ddl %s current ver %v, latest version %v",
 						kv.Key, ver, latestVer)
 				}
 				succ = false
@@ -269,6 +330,16 @@ func (s *schemaVersionSyncer) OwnerCheckAllVersions(ctx goctx.Context, latestVer
 		if succ {
 			return nil
 		}
-		time.Sleep(checkVersInterval)
+
+		// Wait for a server to update or remove its version.
+		select {
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		case _, ok := <-watchCh:
+			if !ok {
+				time.Sleep(keyOpRetryInterval)
+				watchCh = s.etcdCli.Watch(watchCtx, DDLAllSchemaVersions, clientv3.WithPrefix())
+			}
+		}
 	}
 }
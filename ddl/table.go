@@ -50,6 +50,12 @@ func (d *ddl) onCreateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		// none -> public
 		job.SchemaState = model.StatePublic
 		tbInfo.State = model.StatePublic
+		if tbInfo.PlacementPolicy != nil {
+			if err = d.setPlacementRules(tbInfo.ID, tbInfo.PlacementPolicy); err != nil {
+				job.State = model.JobCancelled
+				return ver, errors.Trace(err)
+			}
+		}
 		err = t.CreateTable(schemaID, tbInfo)
 		if err != nil {
 			return ver, errors.Trace(err)
@@ -116,6 +122,7 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
 		startKey := tablecodec.EncodeTablePrefix(tableID)
 		job.Args = append(job.Args, startKey)
+		d.removePlacementRules(tableID, tblInfo.PlacementPolicy)
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropTable, TableInfo: tblInfo})
 	default:
 		err = ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
@@ -180,6 +187,13 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 		return ver, errors.Trace(err)
 	}
 
+	// The placement policy is kept by the new table.
+	if tblInfo.PlacementPolicy != nil {
+		if err = d.setPlacementRules(newTableID, tblInfo.PlacementPolicy); err != nil {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(err)
+		}
+	}
 	err = t.DropTable(schemaID, tableID, true)
 	if err != nil {
 		job.State = model.JobCancelled
@@ -198,6 +212,7 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	}
 	job.State = model.JobDone
 	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	d.removePlacementRules(tableID, tblInfo.PlacementPolicy)
 	startKey := tablecodec.EncodeTablePrefix(tableID)
 	job.Args = []interface{}{startKey}
 	return ver, nil
@@ -249,6 +264,128 @@ func (d *ddl) onRenameTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	return ver, nil
 }
 
+func (d *ddl) onPauseWrites(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var paused bool
+	if err := job.DecodeArgs(&paused); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	tblInfo.WritesPaused = paused
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+// onRenameTables renames the tables in one schema version. All the renamings are checked
+// before any table is changed, so the job is cancelled without a partial renaming.
+func (d *ddl) onRenameTables(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var oldSchemaIDs, newSchemaIDs, tableIDs []int64
+	var tableNames []model.CIStr
+	if err := job.DecodeArgs(&oldSchemaIDs, &newSchemaIDs, &tableIDs, &tableNames); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	// schemaTables are the tables of the schemas after the former renamings.
+	schemaTables := make(map[int64]map[string]*model.TableInfo)
+	getTables := func(schemaID int64) (map[string]*model.TableInfo, error) {
+		if tables, ok := schemaTables[schemaID]; ok {
+			return tables, nil
+		}
+		tblInfos, err := t.ListTables(schemaID)
+		if err != nil {
+			if meta.ErrDBNotExists.Equal(err) {
+				job.State = model.JobCancelled
+				return nil, infoschema.ErrDatabaseNotExists.GenByArgs(fmt.Sprintf("(Schema ID %d)", schemaID))
+			}
+			return nil, errors.Trace(err)
+		}
+		tables := make(map[string]*model.TableInfo, len(tblInfos))
+		for _, tblInfo := range tblInfos {
+			tables[tblInfo.Name.L] = tblInfo
+		}
+		schemaTables[schemaID] = tables
+		return tables, nil
+	}
+
+	tblInfos := make([]*model.TableInfo, 0, len(tableIDs))
+	for i, tableID := range tableIDs {
+		oldTables, err := getTables(oldSchemaIDs[i])
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		var tblInfo *model.TableInfo
+		for name, info := range oldTables {
+			if info.ID == tableID {
+				tblInfo = info
+				delete(oldTables, name)
+				break
+			}
+		}
+		if tblInfo == nil {
+			job.State = model.JobCancelled
+			return ver, infoschema.ErrTableNotExists.GenByArgs(
+				fmt.Sprintf("(Schema ID %d)", oldSchemaIDs[i]),
+				fmt.Sprintf("(Table ID %d)", tableID),
+			)
+		}
+		if tblInfo.State != model.StatePublic {
+			job.State = model.JobCancelled
+			return ver, ErrInvalidTableState.Gen("table %s is not in public, but %s", tblInfo.Name, tblInfo.State)
+		}
+		newTables, err := getTables(newSchemaIDs[i])
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		if _, ok := newTables[tableNames[i].L]; ok {
+			job.State = model.JobCancelled
+			return ver, infoschema.ErrTableExists.GenByArgs(tableNames[i])
+		}
+		newTables[tableNames[i].L] = tblInfo
+		tblInfos = append(tblInfos, tblInfo)
+	}
+
+	for i, tblInfo := range tblInfos {
+		oldSchemaID, newSchemaID := oldSchemaIDs[i], newSchemaIDs[i]
+		if newSchemaID != oldSchemaID && tblInfo.OldSchemaID == 0 {
+			tblInfo.OldSchemaID = oldSchemaID
+		}
+		err := t.DropTable(oldSchemaID, tblInfo.ID, false)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		tblInfo.Name = tableNames[i]
+		err = t.CreateTable(newSchemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+	}
+
+	ver, err := updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfos[len(tblInfos)-1])
+	return ver, nil
+}
+
 func checkTableNotExists(t *meta.Meta, job *model.Job, schemaID int64, tableName string) error {
 	// Check this table's database.
 	tables, err := t.ListTables(schemaID)
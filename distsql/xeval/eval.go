@@ -54,6 +54,8 @@ const (
 // Evaluator evaluates tipb.Expr.
 type Evaluator struct {
 	Row map[int64]types.Datum // TODO: Remove this field after refactor cop_handler.
+	// RowFieldTypes is the field types of the columns in Row.
+	RowFieldTypes map[int64]*types.FieldType
 
 	ColVals      []types.Datum
 	ColIDs       map[int64]int
@@ -67,10 +69,11 @@ type Evaluator struct {
 // NewEvaluator creates a new Evaluator instance.
 func NewEvaluator(sc *variable.StatementContext, timeZone *time.Location) *Evaluator {
 	return &Evaluator{
-		Row:          make(map[int64]types.Datum),
-		ColIDs:       make(map[int64]int),
-		StatementCtx: sc,
-		TimeZone:     timeZone,
+		Row:           make(map[int64]types.Datum),
+		RowFieldTypes: make(map[int64]*types.FieldType),
+		ColIDs:        make(map[int64]int),
+		StatementCtx:  sc,
+		TimeZone:      timeZone,
 	}
 }
 
@@ -19,6 +19,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -47,6 +48,36 @@ func (e *Evaluator) evalTwoBoolChildren(expr *tipb.Expr) (leftBool, rightBool in
 	return
 }
 
+// ColumnCollator returns the collator of the column if the expr is a reference of a string column,
+// or nil if the values are not compared by the collation.
+func (e *Evaluator) ColumnCollator(expr *tipb.Expr) collate.Collator {
+	if expr.GetTp() != tipb.ExprType_ColumnRef {
+		return nil
+	}
+	_, id, err := codec.DecodeInt(expr.Val)
+	if err != nil {
+		return nil
+	}
+	var ft *types.FieldType
+	if e.ColVals == nil {
+		ft = e.RowFieldTypes[id]
+	} else if offset, ok := e.ColIDs[id]; ok {
+		ft = e.fieldTps[offset]
+	}
+	if ft == nil {
+		return nil
+	}
+	return collate.GetCollatorByFieldType(ft)
+}
+
+// childrenCollator returns the collator to compare the two children of the expr.
+func (e *Evaluator) childrenCollator(expr *tipb.Expr) collate.Collator {
+	if collator := e.ColumnCollator(expr.Children[0]); collator != nil {
+		return collator
+	}
+	return e.ColumnCollator(expr.Children[1])
+}
+
 func (e *Evaluator) evalCompareOps(expr *tipb.Expr) (types.Datum, error) {
 	switch op := expr.GetTp(); op {
 	case tipb.ExprType_NullEQ:
@@ -90,7 +121,7 @@ func (e *Evaluator) compareTwoChildren(expr *tipb.Expr) (int, error) {
 	if left.IsNull() || right.IsNull() {
 		return compareResultNull, nil
 	}
-	return left.CompareDatum(e.StatementCtx, right)
+	return collate.CompareDatum(e.StatementCtx, left, right, e.childrenCollator(expr))
 }
 
 func (e *Evaluator) evalLT(cmp int) (types.Datum, error) {
@@ -140,7 +171,7 @@ func (e *Evaluator) evalNullEQ(expr *tipb.Expr) (types.Datum, error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	cmp, err := left.CompareDatum(e.StatementCtx, right)
+	cmp, err := collate.CompareDatum(e.StatementCtx, left, right, e.childrenCollator(expr))
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
@@ -249,7 +280,7 @@ func (e *Evaluator) evalIn(expr *tipb.Expr) (types.Datum, error) {
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
-	in, err := e.checkIn(target, decoded.values)
+	in, err := e.checkIn(target, decoded.values, e.ColumnCollator(expr.Children[0]))
 	if err != nil {
 		return types.Datum{}, errors.Trace(err)
 	}
@@ -263,7 +294,20 @@ func (e *Evaluator) evalIn(expr *tipb.Expr) (types.Datum, error) {
 }
 
 // The value list is in sorted order so we can do a binary search.
-func (e *Evaluator) checkIn(target types.Datum, list []types.Datum) (bool, error) {
+// The list isn't sorted by the collation, so the values are compared one by one if the collator isn't nil.
+func (e *Evaluator) checkIn(target types.Datum, list []types.Datum, collator collate.Collator) (bool, error) {
+	if collator != nil {
+		for _, val := range list {
+			cmp, err := collate.CompareDatum(e.StatementCtx, val, target, collator)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			if cmp == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
 	var outerErr error
 	n := sort.Search(len(list), func(i int) bool {
 		val := list[i]
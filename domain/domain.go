@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/owner"
 	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/queryrewrite"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/terror"
@@ -44,6 +45,7 @@ type Domain struct {
 	store           kv.Storage
 	infoHandle      *infoschema.Handle
 	privHandle      *privileges.Handle
+	rewriteHandle   *queryrewrite.Handle
 	statsHandle     unsafe.Pointer
 	statsLease      time.Duration
 	ddl             ddl.DDL
@@ -522,6 +524,60 @@ func (do *Domain) PrivilegeHandle() *privileges.Handle {
 	return do.privHandle
 }
 
+// LoadQueryRewriteRulesLoop creates a goroutine loads the query rewrite rules in a loop, it
+// should be called only once in BootstrapSession.
+func (do *Domain) LoadQueryRewriteRulesLoop(ctx context.Context) error {
+	ctx.GetSessionVars().InRestrictedSQL = true
+	do.rewriteHandle = queryrewrite.NewHandle()
+	err := do.rewriteHandle.Update(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	var watchCh clientv3.WatchChan
+	duration := 5 * time.Minute
+	if do.etcdClient != nil {
+		watchCh = do.etcdClient.Watch(goctx.Background(), queryRewriteRulesKey)
+		duration = 10 * time.Minute
+	}
+
+	go func() {
+		var count int
+		for {
+			ok := true
+			select {
+			case <-do.exit:
+				return
+			case _, ok = <-watchCh:
+			case <-time.After(duration):
+			}
+			if !ok {
+				log.Error("[domain] load query rewrite rules loop watch channel closed.")
+				watchCh = do.etcdClient.Watch(goctx.Background(), queryRewriteRulesKey)
+				count++
+				if count > 10 {
+					time.Sleep(time.Duration(count) * time.Second)
+				}
+				continue
+			}
+
+			count = 0
+			err := do.rewriteHandle.Update(ctx)
+			if err != nil {
+				log.Error("[domain] load query rewrite rules fail:", errors.ErrorStack(err))
+			} else {
+				log.Infof("[domain] reload %d query rewrite rules success.", do.rewriteHandle.Get().Len())
+			}
+		}
+	}()
+	return nil
+}
+
+// QueryRewriteHandle returns the query rewrite rules handle, it is nil before the domain is bootstrapped.
+func (do *Domain) QueryRewriteHandle() *queryrewrite.Handle {
+	return do.rewriteHandle
+}
+
 // StatsHandle returns the statistic handle.
 func (do *Domain) StatsHandle() *statistics.Handle {
 	return (*statistics.Handle)(atomic.LoadPointer(&do.statsHandle))
@@ -581,8 +637,15 @@ func (do *Domain) updateStatsWorker(ctx context.Context, lease time.Duration) {
 					log.Error("[stats] save histogram to storage fail: ", errors.ErrorStack(err))
 				}
 			}
+			for _, stats := range t.ExtendedStats {
+				err := stats.SaveToStorage(ctx, t.TableID)
+				if err != nil {
+					log.Error("[stats] save extended stats to storage fail: ", errors.ErrorStack(err))
+				}
+			}
 		case <-deltaUpdateTicker.C:
 			statsHandle.DumpStatsDeltaToKV()
+			statsHandle.DumpStatsFeedbackToKV()
 		}
 	}
 }
@@ -627,6 +690,20 @@ func (do *Domain) NotifyUpdatePrivilege(ctx context.Context) {
 	}
 }
 
+const queryRewriteRulesKey = "/tidb/query_rewrite_rules"
+
+// NotifyUpdateQueryRewriteRules updates the query rewrite rules key in etcd, TiDB client that watches
+// the key will get notification.
+func (do *Domain) NotifyUpdateQueryRewriteRules() {
+	if do.etcdClient != nil {
+		kv := do.etcdClient.KV
+		_, err := kv.Put(goctx.Background(), queryRewriteRulesKey, "")
+		if err != nil {
+			log.Warn("notify update query rewrite rules failed:", err)
+		}
+	}
+}
+
 // Domain error codes.
 const (
 	codeInfoSchemaExpired terror.ErrCode = 1
@@ -0,0 +1,375 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// DriverName is the name of the database/sql driver of embedded TiDB.
+const DriverName = "tidb"
+
+// Open opens an embedded TiDB, it is the same as sql.Open(DriverName, dsn).
+//
+// The dsn is the path of the store followed by the name of the database,
+// like 'engine://path/dbname?params'. The store is opened and bootstrapped
+// in the program when the first connection is made, no server is started.
+// The database is created if it doesn't exist.
+// Examples:
+//
+//	memory:///test
+//	goleveldb:///absolute/path/test
+//
+// The engine should be registered before opening the connections.
+// A store is kept open until the program exits, the connections of all the
+// DSNs with the same store path share it.
+func Open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(DriverName, dsn)
+	return db, errors.Trace(err)
+}
+
+var embeddedStores = struct {
+	sync.Mutex
+	stores map[string]kv.Storage
+}{stores: make(map[string]kv.Storage)}
+
+// openEmbeddedStore returns the bootstrapped store of the path.
+func openEmbeddedStore(path string) (kv.Storage, error) {
+	embeddedStores.Lock()
+	defer embeddedStores.Unlock()
+	if store, ok := embeddedStores.stores[path]; ok {
+		return store, nil
+	}
+	store, err := NewStore(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err = BootstrapSession(store); err != nil {
+		return nil, errors.Trace(err)
+	}
+	embeddedStores.stores[path] = store
+	return store, nil
+}
+
+// parseDSN splits a DSN into the path of the store and the name of the database.
+func parseDSN(dsn string) (storePath, dbName string, err error) {
+	path, params := dsn, ""
+	if i := strings.Index(dsn, "?"); i >= 0 {
+		path, params = dsn[:i], dsn[i:]
+	}
+	schemeEnd := strings.Index(path, "://")
+	i := strings.LastIndex(path, "/")
+	if schemeEnd < 0 || i < schemeEnd+len("://") || i == len(path)-1 {
+		return "", "", errors.Errorf("invalid DSN %s, it should be 'engine://path/dbname?params'", dsn)
+	}
+	return path[:i] + params, path[i+1:], nil
+}
+
+type embeddedDriver struct{}
+
+// Open implements driver.Driver Open interface.
+func (d embeddedDriver) Open(dsn string) (driver.Conn, error) {
+	storePath, dbName, err := parseDSN(dsn)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store, err := openEmbeddedStore(storePath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	se, err := CreateSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	name := "`" + strings.Replace(dbName, "`", "``", -1) + "`"
+	for _, sql := range []string{"CREATE DATABASE IF NOT EXISTS " + name, "USE " + name} {
+		if _, err = se.Execute(sql); err != nil {
+			se.Close()
+			return nil, errors.Trace(err)
+		}
+	}
+	return &embeddedConn{se: se}, nil
+}
+
+// embeddedConn is a connection of embedded TiDB, it runs the statements in its session.
+type embeddedConn struct {
+	se Session
+}
+
+// Prepare implements driver.Conn Prepare interface.
+func (c *embeddedConn) Prepare(query string) (driver.Stmt, error) {
+	stmtID, paramCount, _, err := c.se.PrepareStmt(query)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &embeddedStmt{conn: c, stmtID: stmtID, paramCount: paramCount}, nil
+}
+
+// Close implements driver.Conn Close interface.
+func (c *embeddedConn) Close() error {
+	c.se.Close()
+	return nil
+}
+
+// Begin implements driver.Conn Begin interface.
+func (c *embeddedConn) Begin() (driver.Tx, error) {
+	if _, err := c.se.Execute("BEGIN"); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &embeddedTx{conn: c}, nil
+}
+
+// Exec implements driver.Execer Exec interface.
+// The statements with arguments are run as prepared statements.
+func (c *embeddedConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	rss, err := c.se.Execute(query)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, rs := range rss {
+		if err = drain(rs); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return c.result(), nil
+}
+
+// Query implements driver.Queryer Query interface.
+// If the query has several statements, the rows of the first one are returned.
+func (c *embeddedConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	rss, err := c.se.Execute(query)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(rss) == 0 {
+		return &embeddedRows{}, nil
+	}
+	for _, rs := range rss[1:] {
+		if err = drain(rs); err != nil {
+			rss[0].Close()
+			return nil, errors.Trace(err)
+		}
+	}
+	return newEmbeddedRows(rss[0])
+}
+
+func (c *embeddedConn) result() driver.Result {
+	return embeddedResult{affectedRows: int64(c.se.AffectedRows()), lastInsertID: int64(c.se.LastInsertID())}
+}
+
+// drain reads all the rows of a record set and closes it, so the statement is done.
+func drain(rs ast.RecordSet) error {
+	if rs == nil {
+		return nil
+	}
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			rs.Close()
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return errors.Trace(rs.Close())
+		}
+	}
+}
+
+type embeddedTx struct {
+	conn *embeddedConn
+}
+
+// Commit implements driver.Tx Commit interface.
+func (tx *embeddedTx) Commit() error {
+	_, err := tx.conn.se.Execute("COMMIT")
+	return errors.Trace(err)
+}
+
+// Rollback implements driver.Tx Rollback interface.
+func (tx *embeddedTx) Rollback() error {
+	_, err := tx.conn.se.Execute("ROLLBACK")
+	return errors.Trace(err)
+}
+
+type embeddedStmt struct {
+	conn       *embeddedConn
+	stmtID     uint32
+	paramCount int
+}
+
+// Close implements driver.Stmt Close interface.
+func (s *embeddedStmt) Close() error {
+	return errors.Trace(s.conn.se.DropPreparedStmt(s.stmtID))
+}
+
+// NumInput implements driver.Stmt NumInput interface.
+func (s *embeddedStmt) NumInput() int {
+	return s.paramCount
+}
+
+// Exec implements driver.Stmt Exec interface.
+func (s *embeddedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	rs, err := s.execute(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = drain(rs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s.conn.result(), nil
+}
+
+// Query implements driver.Stmt Query interface.
+func (s *embeddedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rs, err := s.execute(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if rs == nil {
+		return &embeddedRows{}, nil
+	}
+	return newEmbeddedRows(rs)
+}
+
+func (s *embeddedStmt) execute(args []driver.Value) (ast.RecordSet, error) {
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		params[i] = arg
+	}
+	rs, err := s.conn.se.ExecutePreparedStmt(s.stmtID, params...)
+	return rs, errors.Trace(err)
+}
+
+type embeddedResult struct {
+	affectedRows int64
+	lastInsertID int64
+}
+
+// LastInsertId implements driver.Result LastInsertId interface.
+func (r embeddedResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+// RowsAffected implements driver.Result RowsAffected interface.
+func (r embeddedResult) RowsAffected() (int64, error) {
+	return r.affectedRows, nil
+}
+
+// embeddedRows returns the rows of a record set, rs is nil for a statement without result.
+type embeddedRows struct {
+	rs      ast.RecordSet
+	columns []string
+}
+
+func newEmbeddedRows(rs ast.RecordSet) (*embeddedRows, error) {
+	fields, err := rs.Fields()
+	if err != nil {
+		rs.Close()
+		return nil, errors.Trace(err)
+	}
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, field.ColumnAsName.O)
+	}
+	return &embeddedRows{rs: rs, columns: columns}, nil
+}
+
+// Columns implements driver.Rows Columns interface.
+func (r *embeddedRows) Columns() []string {
+	return r.columns
+}
+
+// Close implements driver.Rows Close interface.
+func (r *embeddedRows) Close() error {
+	if r.rs == nil {
+		return nil
+	}
+	err := r.rs.Close()
+	r.rs = nil
+	return errors.Trace(err)
+}
+
+// Next implements driver.Rows Next interface.
+func (r *embeddedRows) Next(dest []driver.Value) error {
+	if r.rs == nil {
+		return io.EOF
+	}
+	row, err := r.rs.Next()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if row == nil {
+		return io.EOF
+	}
+	for i, d := range row.Data {
+		if dest[i], err = datumToValue(d); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// datumToValue converts a datum to a driver value. Numbers are returned as int64 or float64, and valid
+// dates and times as time.Time in the local time zone. Other values, like decimals, are returned as the
+// bytes of their strings, which can be scanned to numbers by database/sql.
+func datumToValue(d types.Datum) (driver.Value, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return nil, nil
+	case types.KindInt64:
+		return d.GetInt64(), nil
+	case types.KindUint64:
+		if d.GetUint64() <= math.MaxInt64 {
+			return int64(d.GetUint64()), nil
+		}
+		return []byte(fmt.Sprintf("%d", d.GetUint64())), nil
+	case types.KindFloat32, types.KindFloat64:
+		return d.GetFloat64(), nil
+	case types.KindString, types.KindBytes:
+		return []byte(d.GetString()), nil
+	case types.KindMysqlTime:
+		t := d.GetMysqlTime()
+		if !t.IsZero() {
+			if gt, err := t.Time.GoTime(time.Local); err == nil {
+				return gt, nil
+			}
+		}
+	}
+	str, err := d.ToString()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return []byte(str), nil
+}
+
+func init() {
+	sql.Register(DriverName, embeddedDriver{})
+}
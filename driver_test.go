@@ -0,0 +1,167 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidb
+
+import (
+	"database/sql"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testDriverSuite{})
+
+type testDriverSuite struct{}
+
+const testDriverStore = "memory://driver_test"
+
+func (s *testDriverSuite) TearDownSuite(c *C) {
+	embeddedStores.Lock()
+	defer embeddedStores.Unlock()
+	store, ok := embeddedStores.stores[testDriverStore]
+	if !ok {
+		return
+	}
+	delete(embeddedStores.stores, testDriverStore)
+	dom, err := domap.Get(store)
+	c.Assert(err, IsNil)
+	dom.Close()
+	domap.Delete(store)
+	c.Assert(store.Close(), IsNil)
+}
+
+func (s *testDriverSuite) TestParseDSN(c *C) {
+	tests := []struct {
+		dsn       string
+		storePath string
+		dbName    string
+	}{
+		{"memory:///test", "memory://", "test"},
+		{"goleveldb:///tmp/tidb/test", "goleveldb:///tmp/tidb", "test"},
+		{"boltdb://relative/path/db?sync=false", "boltdb://relative/path?sync=false", "db"},
+		{"memory://test", "", ""},
+		{"memory:///", "", ""},
+		{"/tmp/tidb/test", "", ""},
+	}
+	for _, t := range tests {
+		storePath, dbName, err := parseDSN(t.dsn)
+		if t.dbName == "" {
+			c.Assert(err, NotNil, Commentf("%s", t.dsn))
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(storePath, Equals, t.storePath)
+		c.Assert(dbName, Equals, t.dbName)
+	}
+}
+
+func (s *testDriverSuite) TestDriver(c *C) {
+	db, err := Open(testDriverStore + "/driver_db")
+	c.Assert(err, IsNil)
+	defer db.Close()
+
+	_, err = db.Exec("create table t (id int primary key auto_increment, name varchar(10), price decimal(10,2), created datetime, d date, u bigint unsigned, f double)")
+	c.Assert(err, IsNil)
+	res, err := db.Exec("insert t (name, price, created, d, u, f) values ('a', 1.5, '2017-01-02 03:04:05', '2017-01-02', 18446744073709551615, 0.25), ('b', null, null, '0000-00-00', 1, null)")
+	c.Assert(err, IsNil)
+	n, err := res.RowsAffected()
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(2))
+	id, err := res.LastInsertId()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(1))
+
+	created := time.Date(2017, 5, 6, 7, 8, 9, 0, time.Local)
+	res, err = db.Exec("insert t (name, price, created, u, f) values (?, ?, ?, ?, ?)", "c", "-2.25", created, 3, 1.5)
+	c.Assert(err, IsNil)
+	id, err = res.LastInsertId()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(3))
+
+	rows, err := db.Query("select id, name, price, created, d, u, f from t where id >= ? order by id", 1)
+	c.Assert(err, IsNil)
+	columns, err := rows.Columns()
+	c.Assert(err, IsNil)
+	c.Assert(columns, DeepEquals, []string{"id", "name", "price", "created", "d", "u", "f"})
+	type row struct {
+		id      int64
+		name    string
+		price   sql.NullString
+		created interface{}
+		d       interface{}
+		u       uint64
+		f       sql.NullFloat64
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		c.Assert(rows.Scan(&r.id, &r.name, &r.price, &r.created, &r.d, &r.u, &r.f), IsNil)
+		got = append(got, r)
+	}
+	c.Assert(rows.Err(), IsNil)
+	c.Assert(rows.Close(), IsNil)
+	c.Assert(got, DeepEquals, []row{
+		{1, "a", sql.NullString{String: "1.50", Valid: true}, time.Date(2017, 1, 2, 3, 4, 5, 0, time.Local),
+			time.Date(2017, 1, 2, 0, 0, 0, 0, time.Local), 18446744073709551615, sql.NullFloat64{Float64: 0.25, Valid: true}},
+		{2, "b", sql.NullString{}, nil, []byte("0000-00-00"), 1, sql.NullFloat64{}},
+		{3, "c", sql.NullString{String: "-2.25", Valid: true}, created, nil, 3, sql.NullFloat64{Float64: 1.5, Valid: true}},
+	})
+
+	// A prepared statement is run several times.
+	stmt, err := db.Prepare("update t set name = ? where id = ?")
+	c.Assert(err, IsNil)
+	for i, name := range []string{"x", "y"} {
+		res, err = stmt.Exec(name, i+1)
+		c.Assert(err, IsNil)
+		n, err = res.RowsAffected()
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, int64(1))
+	}
+	c.Assert(stmt.Close(), IsNil)
+
+	// Transactions.
+	tx, err := db.Begin()
+	c.Assert(err, IsNil)
+	_, err = tx.Exec("delete from t where id = 1")
+	c.Assert(err, IsNil)
+	c.Assert(tx.Rollback(), IsNil)
+	tx, err = db.Begin()
+	c.Assert(err, IsNil)
+	_, err = tx.Exec("delete from t where id = ?", 2)
+	c.Assert(err, IsNil)
+	c.Assert(tx.Commit(), IsNil)
+	var names string
+	c.Assert(db.QueryRow("select group_concat(name) from t").Scan(&names), IsNil)
+	c.Assert(names, Equals, "x,c")
+
+	_, err = db.Exec("insert t (id) values (1)")
+	c.Assert(err, NotNil)
+	_, err = db.Query("select * from missing")
+	c.Assert(err, NotNil)
+
+	// The databases of a store share the data.
+	other, err := Open(testDriverStore + "/other_db")
+	c.Assert(err, IsNil)
+	defer other.Close()
+	var count int
+	c.Assert(other.QueryRow("select count(*) from driver_db.t").Scan(&count), IsNil)
+	c.Assert(count, Equals, 2)
+	c.Assert(other.QueryRow("select database()").Scan(&names), IsNil)
+	c.Assert(names, Equals, "other_db")
+
+	invalid, err := Open("memory://driver_test")
+	c.Assert(err, IsNil)
+	c.Assert(invalid.Ping(), NotNil)
+	invalid.Close()
+}
@@ -18,7 +18,6 @@ import (
 	"math"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/config"
@@ -29,6 +28,8 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/slowlog"
 )
 
 type processinfoSetter interface {
@@ -139,6 +140,17 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 		return nil, errors.Trace(err)
 	}
 
+	// Check if "tidb_snapshot" is set for the write executors.
+	// In history read mode, we can not do write operations.
+	// It's checked before Open because the ReturningExec writes in Open.
+	switch e.(type) {
+	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec, *LoadData, *DDLExec, *ReturningExec:
+		snapshotTS := ctx.GetSessionVars().SnapshotTS
+		if snapshotTS != 0 {
+			return nil, errors.New("can not execute write statement when 'tidb_snapshot' is set")
+		}
+	}
+
 	if err := e.Open(); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -162,16 +174,6 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 }
 
 func (a *statement) handleNoDelayExecutor(e Executor, ctx context.Context, pi processinfoSetter) (ast.RecordSet, error) {
-	// Check if "tidb_snapshot" is set for the write executors.
-	// In history read mode, we can not do write operations.
-	switch e.(type) {
-	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec, *LoadData, *DDLExec:
-		snapshotTS := ctx.GetSessionVars().SnapshotTS
-		if snapshotTS != 0 {
-			return nil, errors.New("can not execute write statement when 'tidb_snapshot' is set")
-		}
-	}
-
 	defer func() {
 		if pi != nil {
 			pi.SetProcessInfo("")
@@ -203,10 +205,10 @@ func (a *statement) buildExecutor(ctx context.Context) (Executor, error) {
 		var err error
 		isPointGet := IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx, a.plan)
 		if isPointGet {
-			log.Debugf("[%d][InitTxnWithStartTS] %s", ctx.GetSessionVars().ConnectionID, a.text)
+			logutil.QueryLogger(logutil.ModuleExecutor, ctx.GetSessionVars()).Debugf("[InitTxnWithStartTS] %s", a.text)
 			err = ctx.InitTxnWithStartTS(math.MaxUint64)
 		} else {
-			log.Debugf("[%d][ActivePendingTxn] %s", ctx.GetSessionVars().ConnectionID, a.text)
+			logutil.QueryLogger(logutil.ModuleExecutor, ctx.GetSessionVars()).Debugf("[ActivePendingTxn] %s", a.text)
 			err = ctx.ActivePendingTxn()
 		}
 		if err != nil {
@@ -255,11 +257,27 @@ func (a *statement) logSlowQuery() {
 	if len(sql) > cfg.QueryLogMaxlen {
 		sql = sql[:cfg.QueryLogMaxlen] + fmt.Sprintf("(len:%d)", len(sql))
 	}
-	connID := a.ctx.GetSessionVars().ConnectionID
+	logger := logutil.QueryLogger(logutil.ModuleExecutor, a.ctx.GetSessionVars())
 	if costTime < time.Duration(cfg.SlowThreshold)*time.Millisecond {
-		log.Debugf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+		logger.Debugf("[TIME_QUERY] %v %s", costTime, sql)
 	} else {
-		log.Warnf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+		// The plan digest tells whether a slow query runs with a different plan from before.
+		planDigest := plan.Digest(a.plan)
+		logger.WithField(logutil.FieldPlanDigest, planDigest).Warnf("[TIME_QUERY] %v %s", costTime, sql)
+		vars := a.ctx.GetSessionVars()
+		var user string
+		if vars.User != nil {
+			user = vars.User.String()
+		}
+		slowlog.Record(&slowlog.Entry{
+			Time:       a.startTime,
+			ConnID:     vars.ConnectionID,
+			User:       user,
+			DB:         vars.CurrentDB,
+			QueryTime:  costTime,
+			Query:      sql,
+			PlanDigest: planDigest,
+		})
 	}
 }
 
@@ -293,8 +311,8 @@ func IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx context.Context, p plan.Plan) b
 		indexScan := v.IndexPlans[0].(*plan.PhysicalIndexScan)
 		return indexScan.IsPointGetByUniqueKey(ctx.GetSessionVars().StmtCtx)
 	case *plan.PhysicalIndexLookUpReader:
-		indexScan := v.IndexPlans[0].(*plan.PhysicalIndexScan)
-		return indexScan.IsPointGetByUniqueKey(ctx.GetSessionVars().StmtCtx)
+		indexScan, ok := v.IndexPlans[0].(*plan.PhysicalIndexScan)
+		return ok && indexScan.IsPointGetByUniqueKey(ctx.GetSessionVars().StmtCtx)
 	case *plan.PhysicalTableScan:
 		return len(v.Ranges) == 1 && v.Ranges[0].IsPoint()
 	case *plan.PhysicalTableReader:
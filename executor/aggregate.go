@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/mvmap"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -101,13 +102,19 @@ func (e *HashAggExec) getGroupKey(row Row) ([]byte, error) {
 	if !e.hasGby {
 		return []byte{}, nil
 	}
-	vals := make([]types.Datum, 0, len(e.GroupByItems))
-	for _, item := range e.GroupByItems {
+	return encodeGroupKey(row, e.GroupByItems)
+}
+
+// encodeGroupKey evaluates the group by items on the row and encodes them to the key of the group.
+func encodeGroupKey(row Row, groupByItems []expression.Expression) ([]byte, error) {
+	vals := make([]types.Datum, 0, len(groupByItems))
+	for _, item := range groupByItems {
 		v, err := item.Eval(row)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		vals = append(vals, v)
+		// The strings that are equal in their collations are in the same group.
+		vals = append(vals, collate.KeyDatum(v, collate.GetCollatorByFieldType(item.GetType())))
 	}
 	bs, err := codec.EncodeValue([]byte{}, vals...)
 	if err != nil {
@@ -228,7 +235,7 @@ func (e *StreamAggExec) meetNewGroup(row Row) (bool, error) {
 			return false, errors.Trace(err)
 		}
 		if matched {
-			c, err := v.CompareDatum(e.StmtCtx, e.curGroupKey[i])
+			c, err := collate.CompareDatum(e.StmtCtx, v, e.curGroupKey[i], collate.GetCollatorByFieldType(item.GetType()))
 			if err != nil {
 				return false, errors.Trace(err)
 			}
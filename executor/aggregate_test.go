@@ -286,7 +286,7 @@ func (s *testSuite) TestAggregation(c *C) {
 
 	result = tk.MustQuery("select count(*) from information_schema.columns")
 	// When adding new memory table in information_schema, please update this variable.
-	columnCountOfAllInformationSchemaTables := "738"
+	columnCountOfAllInformationSchemaTables := "813"
 	result.Check(testkit.Rows(columnCountOfAllInformationSchemaTables))
 
 	tk.MustExec("drop table if exists t1")
@@ -352,6 +352,28 @@ func (s *testSuite) TestSelectDistinct(c *C) {
 
 }
 
+func (s *testSuite) TestHashDistinct(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int primary key, b int, c varchar(10), d datetime, e decimal(5, 2))")
+	tk.MustExec(`insert into t values(1, 1, 'a', '2017-01-01 10:00:00', 1.1), (2, 2, 'b', null, 2.2), (3, 1, 'a', '2017-01-01 10:00:00', 1.1),
+		(4, 3, 'c', '2017-03-01 00:00:00', null), (5, 2, 'b', null, 2.2), (6, 4, 'A', '2017-01-01 10:00:00', 1.1), (7, 3, 'c', '2017-03-01 00:00:00', null)`)
+	tk.MustQuery("select distinct b, c, d, e from t order by b").Check(testkit.Rows(
+		"1 a 2017-01-01 10:00:00 1.10", "2 b <nil> 2.20", "3 c 2017-03-01 00:00:00 <nil>", "4 A 2017-01-01 10:00:00 1.10"))
+
+	// The rows of the keys that aren't kept in memory are spilled and deduplicated later.
+	tk.MustExec("set @@tidb_hash_distinct_spill_size = 1")
+	tk.MustQuery("select distinct b, c, d, e from t order by b").Check(testkit.Rows(
+		"1 a 2017-01-01 10:00:00 1.10", "2 b <nil> 2.20", "3 c 2017-03-01 00:00:00 <nil>", "4 A 2017-01-01 10:00:00 1.10"))
+	tk.MustQuery("select distinct d from t order by d").Check(testkit.Rows("<nil>", "2017-01-01 10:00:00", "2017-03-01 00:00:00"))
+	tk.MustQuery("select count(*) from (select distinct c, e from t) k").Check(testkit.Rows("4"))
+}
+
 func (s *testSuite) TestAggPushDown(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -14,6 +14,9 @@
 package executor
 
 import (
+	"math"
+	"math/rand"
+	"sort"
 	"strconv"
 	"time"
 
@@ -21,11 +24,17 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
 )
 
 var _ Executor = &AnalyzeExec{}
@@ -64,7 +73,7 @@ func (e *AnalyzeExec) Next() (Row, error) {
 		return nil, errors.Trace(err)
 	}
 	taskCh := make(chan *analyzeTask, len(e.tasks))
-	resultCh := make(chan statistics.AnalyzeResult, len(e.tasks))
+	resultCh := make(chan []statistics.AnalyzeResult, len(e.tasks))
 	for i := 0; i < concurrency; i++ {
 		go e.analyzeWorker(taskCh, resultCh)
 	}
@@ -77,13 +86,15 @@ func (e *AnalyzeExec) Next() (Row, error) {
 	if lease > 0 {
 		var err1 error
 		for i := 0; i < len(e.tasks); i++ {
-			result := <-resultCh
-			if result.Err != nil {
-				err1 = err
-				log.Error(errors.ErrorStack(err))
-				continue
+			taskResults := <-resultCh
+			for j := range taskResults {
+				if taskResults[j].Err != nil {
+					err1 = err
+					log.Error(errors.ErrorStack(err))
+					continue
+				}
+				dom.StatsHandle().AnalyzeResultCh() <- &taskResults[j]
 			}
-			dom.StatsHandle().AnalyzeResultCh() <- &result
 		}
 		// We sleep two lease to make sure other tidb node has updated this node.
 		time.Sleep(lease * 2)
@@ -92,13 +103,14 @@ func (e *AnalyzeExec) Next() (Row, error) {
 	results := make([]statistics.AnalyzeResult, 0, len(e.tasks))
 	var err1 error
 	for i := 0; i < len(e.tasks); i++ {
-		result := <-resultCh
-		if result.Err != nil {
-			err1 = err
-			log.Error(errors.ErrorStack(err))
-			continue
+		for _, result := range <-resultCh {
+			if result.Err != nil {
+				err1 = err
+				log.Error(errors.ErrorStack(err))
+				continue
+			}
+			results = append(results, result)
 		}
-		results = append(results, result)
 	}
 	if err1 != nil {
 		return nil, errors.Trace(err1)
@@ -110,6 +122,12 @@ func (e *AnalyzeExec) Next() (Row, error) {
 				return nil, errors.Trace(err)
 			}
 		}
+		for _, stats := range result.ExtendedStats {
+			err = stats.SaveToStorage(e.ctx, result.TableID)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
 	}
 	err = dom.StatsHandle().Update(GetInfoSchema(e.ctx))
 	if err != nil {
@@ -133,6 +151,7 @@ type taskType int
 const (
 	colTask taskType = iota
 	idxTask
+	fastTask
 )
 
 type analyzeTask struct {
@@ -142,15 +161,27 @@ type analyzeTask struct {
 	Columns   []*model.ColumnInfo
 	PKInfo    *model.ColumnInfo
 	src       Executor
+	// scanColLen is the number of the scanned columns except the handle, the columns only in the column groups are
+	// scanned after Columns.
+	scanColLen int
+	// colGroups are the offsets of the columns of the extended stats in the scanned rows.
+	extendedStats []*statistics.ExtendedStats
+	colGroups     [][]int
+	// The fields below are only used by the fast analyze, the rows are sampled from the snapshot of startTS.
+	indicesInfo []*model.IndexInfo
+	numSamples  uint64
+	startTS     uint64
 }
 
-func (e *AnalyzeExec) analyzeWorker(taskCh <-chan *analyzeTask, resultCh chan<- statistics.AnalyzeResult) {
+func (e *AnalyzeExec) analyzeWorker(taskCh <-chan *analyzeTask, resultCh chan<- []statistics.AnalyzeResult) {
 	for task := range taskCh {
 		switch task.taskType {
 		case colTask:
-			resultCh <- e.analyzeColumns(task)
+			resultCh <- []statistics.AnalyzeResult{e.analyzeColumns(task)}
 		case idxTask:
-			resultCh <- e.analyzeIndex(task)
+			resultCh <- []statistics.AnalyzeResult{e.analyzeIndex(task)}
+		case fastTask:
+			resultCh <- e.analyzeFast(task)
 		}
 	}
 }
@@ -166,11 +197,12 @@ func (e *AnalyzeExec) analyzeColumns(task *analyzeTask) statistics.AnalyzeResult
 	builder := statistics.SampleBuilder{
 		Sc:            e.ctx.GetSessionVars().StmtCtx,
 		RecordSet:     &recordSet{executor: task.src},
-		ColLen:        len(task.Columns),
+		ColLen:        task.scanColLen,
 		PkID:          pkID,
 		MaxBucketSize: maxBucketSize,
 		MaxSketchSize: maxSketchSize,
 		MaxSampleSize: maxSampleSize,
+		ColGroups:     task.colGroups,
 	}
 	collectors, pkBuilder, err := builder.CollectSamplesAndEstimateNDVs()
 	if e := task.src.Close(); e != nil {
@@ -193,6 +225,13 @@ func (e *AnalyzeExec) analyzeColumns(task *analyzeTask) statistics.AnalyzeResult
 			result.Err = err
 		}
 	}
+	for i, stats := range task.extendedStats {
+		result.ExtendedStats = append(result.ExtendedStats, &statistics.ExtendedStats{
+			Name:   stats.Name,
+			ColIDs: stats.ColIDs,
+			NDV:    collectors[task.scanColLen+i].Sketch.NDV(),
+		})
+	}
 	return result
 }
 
@@ -200,9 +239,347 @@ func (e *AnalyzeExec) analyzeIndex(task *analyzeTask) statistics.AnalyzeResult {
 	if e := task.src.Open(); e != nil {
 		return statistics.AnalyzeResult{Err: e}
 	}
-	count, hg, err := statistics.BuildIndex(e.ctx, maxBucketSize, task.indexInfo.ID, &recordSet{executor: task.src})
+	count, hg, err := statistics.BuildIndex(e.ctx, maxBucketSize, task.indexInfo, &recordSet{executor: task.src})
 	if e := task.src.Close(); e != nil {
 		return statistics.AnalyzeResult{Err: e}
 	}
 	return statistics.AnalyzeResult{TableID: task.tableInfo.ID, Hist: []*statistics.Histogram{hg}, Count: count, IsIndex: 1, Err: err}
 }
+
+// analyzeFast analyzes the columns, the indices and the column groups of the table by the sampled rows. It returns a
+// result for the columns and one for each index.
+func (e *AnalyzeExec) analyzeFast(task *analyzeTask) []statistics.AnalyzeResult {
+	snapshot, err := e.ctx.GetStore().GetSnapshot(kv.Version{Ver: task.startTS})
+	if err != nil {
+		return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+	}
+	sampler := &fastSampler{
+		snapshot:   snapshot,
+		prefix:     tablecodec.GenTableRecordPrefix(task.tableInfo.ID),
+		numSamples: task.numSamples,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	statsTbl := sessionctx.GetDomain(e.ctx).StatsHandle().GetTableStats(task.tableInfo.ID)
+	if !statsTbl.Pseudo {
+		sampler.fallbackCount = statsTbl.Count
+	}
+	handles, values, count, err := sampler.sample()
+	if err != nil {
+		return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+	}
+	rows, err := e.decodeSampledRows(task.tableInfo, handles, values)
+	if err != nil {
+		return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+	}
+	if len(rows) > 0 {
+		sampleRate := float64(len(rows)) / float64(count)
+		errorBound := math.Sqrt(1 / sampleRate)
+		fastAnalyzeSampleRate.Observe(sampleRate)
+		fastAnalyzeNDVErrorBound.Observe(errorBound)
+		log.Infof("[stats] fast analyze table %s with %d samples of %d estimated rows, sample rate %.6f, NDV ratio error bound %.2f",
+			task.tableInfo.Name.O, len(rows), count, sampleRate, errorBound)
+	}
+
+	colResult := statistics.AnalyzeResult{TableID: task.tableInfo.ID, Count: count, IsIndex: 0}
+	cols := task.Columns
+	if task.PKInfo != nil {
+		cols = append([]*model.ColumnInfo{task.PKInfo}, cols...)
+	}
+	for _, col := range cols {
+		samples := make([]types.Datum, 0, len(rows))
+		for _, row := range rows {
+			if !row[col.Offset].IsNull() {
+				samples = append(samples, row[col.Offset])
+			}
+		}
+		hg, err := e.buildHistogramBySamples(col.ID, samples, len(rows), count)
+		if err != nil {
+			return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+		}
+		colResult.Hist = append(colResult.Hist, hg)
+	}
+	for _, stats := range task.extendedStats {
+		samples, err := encodeSampledColumns(task.tableInfo, rows, stats.ColIDs)
+		if err != nil {
+			return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+		}
+		ndv, err := statistics.EstimateNDVBySamples(samples, count)
+		if err != nil {
+			return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+		}
+		colResult.ExtendedStats = append(colResult.ExtendedStats, &statistics.ExtendedStats{Name: stats.Name, ColIDs: stats.ColIDs, NDV: ndv})
+	}
+	results := []statistics.AnalyzeResult{colResult}
+	for _, idx := range task.indicesInfo {
+		colIDs := make([]int64, 0, len(idx.Columns))
+		for _, idxCol := range idx.Columns {
+			colIDs = append(colIDs, task.tableInfo.Columns[idxCol.Offset].ID)
+		}
+		samples, err := encodeSampledColumns(task.tableInfo, rows, colIDs)
+		if err != nil {
+			return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+		}
+		hg, err := e.buildHistogramBySamples(idx.ID, samples, len(rows), count)
+		if err != nil {
+			return []statistics.AnalyzeResult{{Err: errors.Trace(err)}}
+		}
+		results = append(results, statistics.AnalyzeResult{TableID: task.tableInfo.ID, Hist: []*statistics.Histogram{hg}, Count: count, IsIndex: 1})
+	}
+	return results
+}
+
+// buildHistogramBySamples builds the histogram by the non-null samples of the sampleCount sampled rows, the null count
+// and the number of distinct values are scaled to the count rows.
+func (e *AnalyzeExec) buildHistogramBySamples(id int64, samples []types.Datum, sampleCount int, count int64) (*statistics.Histogram, error) {
+	if sampleCount == 0 {
+		return &statistics.Histogram{ID: id}, nil
+	}
+	notNullCount := int64(float64(count) * float64(len(samples)) / float64(sampleCount))
+	ndv, err := statistics.EstimateNDVBySamples(samples, notNullCount)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return statistics.BuildColumn(e.ctx, maxBucketSize, id, ndv, notNullCount, count-notNullCount, samples)
+}
+
+// encodeSampledColumns encodes the values of the columns of each sampled row into a key, it's used to sample the
+// indices and the column groups.
+func encodeSampledColumns(tblInfo *model.TableInfo, rows [][]types.Datum, colIDs []int64) ([]types.Datum, error) {
+	offsets := make([]int, 0, len(colIDs))
+	for _, id := range colIDs {
+		for _, col := range tblInfo.Columns {
+			if col.ID == id {
+				offsets = append(offsets, col.Offset)
+				break
+			}
+		}
+	}
+	samples := make([]types.Datum, 0, len(rows))
+	vals := make([]types.Datum, len(offsets))
+	for _, row := range rows {
+		for i, offset := range offsets {
+			vals[i] = row[offset]
+		}
+		key, err := codec.EncodeKey(nil, vals...)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		samples = append(samples, types.NewBytesDatum(key))
+	}
+	return samples, nil
+}
+
+// decodeSampledRows decodes the sampled rows, the datums of each row are in the order of the table columns.
+func (e *AnalyzeExec) decodeSampledRows(tblInfo *model.TableInfo, handles []int64, values [][]byte) ([][]types.Datum, error) {
+	colTps := make(map[int64]*types.FieldType, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		colTps[col.ID] = &col.FieldType
+	}
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	rows := make([][]types.Datum, 0, len(handles))
+	for i, handle := range handles {
+		rowMap, err := tablecodec.DecodeRow(values[i], colTps, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row := make([]types.Datum, len(tblInfo.Columns))
+		for _, col := range tblInfo.Columns {
+			if tblInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
+				if mysql.HasUnsignedFlag(col.Flag) {
+					row[col.Offset].SetUint64(uint64(handle))
+				} else {
+					row[col.Offset].SetInt64(handle)
+				}
+				continue
+			}
+			d, ok := rowMap[col.ID]
+			if !ok {
+				// The column is added after the row is written.
+				d, err = table.GetColOriginDefaultValue(e.ctx, col)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+			row[col.Offset] = d
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// fastSampler samples the rows of a table from the storage by reading the random handles, so only about numSamples
+// rows are read however large the table is.
+type fastSampler struct {
+	snapshot   kv.Snapshot
+	prefix     kv.Key
+	numSamples uint64
+	rng        *rand.Rand
+	// fallbackCount is the row count used when it can't be estimated by the samples, like when the handles are sparse.
+	fallbackCount int64
+}
+
+// seek returns the first row whose handle isn't less than h, ok is false if there is no such row.
+func (s *fastSampler) seek(h int64) (handle int64, value []byte, ok bool, err error) {
+	it, err := s.snapshot.Seek(tablecodec.EncodeRecordKey(s.prefix, h))
+	if err != nil {
+		return 0, nil, false, errors.Trace(err)
+	}
+	defer it.Close()
+	if !it.Valid() || !it.Key().HasPrefix(s.prefix) {
+		return 0, nil, false, nil
+	}
+	handle, err = tablecodec.DecodeRowKey(it.Key())
+	if err != nil {
+		return 0, nil, false, errors.Trace(err)
+	}
+	return handle, append([]byte(nil), it.Value()...), true, nil
+}
+
+// maxHandle finds the maximum handle by binary search, minHandle is the minimum one.
+func (s *fastSampler) maxHandle(minHandle int64) (int64, error) {
+	lo, hi := minHandle, int64(math.MaxInt64)
+	for lo < hi {
+		d := uint64(hi) - uint64(lo)
+		mid := lo + int64(d/2+d%2)
+		handle, _, ok, err := s.seek(mid)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if ok {
+			lo = handle
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, nil
+}
+
+// scanAll reads all the rows from minHandle.
+func (s *fastSampler) scanAll(minHandle int64) (handles []int64, values [][]byte, err error) {
+	it, err := s.snapshot.Seek(tablecodec.EncodeRecordKey(s.prefix, minHandle))
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	defer it.Close()
+	for it.Valid() && it.Key().HasPrefix(s.prefix) {
+		handle, err := tablecodec.DecodeRowKey(it.Key())
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		handles, values = append(handles, handle), append(values, append([]byte(nil), it.Value()...))
+		err = it.Next()
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	return handles, values, nil
+}
+
+// fastSampleBatchSize is the number of the random handles read by a BatchGet, the storage reads the handles of a
+// region in one request.
+const fastSampleBatchSize = 1024
+
+// sample returns the handles and the values of the sampled rows, and the estimated row count. numSamples distinct
+// random handles between the minimum and the maximum handle are read by batches, and the existing ones are sampled, so
+// every row has the same chance to be sampled. The row count is estimated by the proportion of the existing handles.
+// All the rows are read if the handle range isn't much larger than numSamples. If the handles are so sparse that none
+// of the random handles exists, the first row at or after each of them is sampled instead.
+func (s *fastSampler) sample() (handles []int64, values [][]byte, count int64, err error) {
+	minHandle, _, ok, err := s.seek(math.MinInt64)
+	if err != nil || !ok {
+		return nil, nil, 0, errors.Trace(err)
+	}
+	maxHandle, err := s.maxHandle(minHandle)
+	if err != nil {
+		return nil, nil, 0, errors.Trace(err)
+	}
+	span := uint64(maxHandle) - uint64(minHandle)
+	if span/2 < s.numSamples {
+		handles, values, err = s.scanAll(minHandle)
+		return handles, values, int64(len(handles)), errors.Trace(err)
+	}
+	targets := s.randomHandles(minHandle, span)
+	handles, values, err = s.batchGet(targets)
+	if err != nil {
+		return nil, nil, 0, errors.Trace(err)
+	}
+	if len(handles) > 0 {
+		count = int64(float64(len(handles)) / float64(s.numSamples) * (float64(span) + 1))
+		return handles, values, count, nil
+	}
+	handles, values, err = s.seekAll(targets)
+	if err != nil {
+		return nil, nil, 0, errors.Trace(err)
+	}
+	count = s.fallbackCount
+	if count < int64(len(handles)) {
+		count = int64(len(handles))
+	}
+	return handles, values, count, nil
+}
+
+// randomHandles returns numSamples distinct random handles between minHandle and minHandle+span in order, span must
+// be larger than numSamples.
+func (s *fastSampler) randomHandles(minHandle int64, span uint64) []int64 {
+	picked := make(map[int64]struct{}, s.numSamples)
+	targets := make([]int64, 0, s.numSamples)
+	for uint64(len(targets)) < s.numSamples {
+		offset := s.rng.Uint64()
+		if span < math.MaxUint64 {
+			offset %= span + 1
+		}
+		h := minHandle + int64(offset)
+		if _, ok := picked[h]; ok {
+			continue
+		}
+		picked[h] = struct{}{}
+		targets = append(targets, h)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	return targets
+}
+
+// batchGet returns the rows of the existing handles in targets.
+func (s *fastSampler) batchGet(targets []int64) (handles []int64, values [][]byte, err error) {
+	keys := make([]kv.Key, 0, fastSampleBatchSize)
+	for len(targets) > 0 {
+		batch := targets
+		if len(batch) > fastSampleBatchSize {
+			batch = batch[:fastSampleBatchSize]
+		}
+		targets = targets[len(batch):]
+		keys = keys[:0]
+		for _, h := range batch {
+			keys = append(keys, tablecodec.EncodeRecordKey(s.prefix, h))
+		}
+		rows, err := s.snapshot.BatchGet(keys)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		for i, h := range batch {
+			if value, ok := rows[string(keys[i])]; ok {
+				handles, values = append(handles, h), append(values, value)
+			}
+		}
+	}
+	return handles, values, nil
+}
+
+// seekAll returns the first row at or after each of the handles in targets, a row is returned once.
+func (s *fastSampler) seekAll(targets []int64) (handles []int64, values [][]byte, err error) {
+	for _, target := range targets {
+		if len(handles) > 0 && target <= handles[len(handles)-1] {
+			// The row at or after the target is sampled already.
+			continue
+		}
+		handle, value, ok, err := s.seek(target)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if !ok {
+			break
+		}
+		handles, values = append(handles, handle), append(values, value)
+	}
+	return handles, values, nil
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
@@ -29,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
@@ -83,6 +85,40 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildShowDDL(v)
 	case *plan.ShowDDLJobs:
 		return b.buildShowDDLJobs(v)
+	case *plan.CancelDDLJobs:
+		return b.buildUpdateDDLJobs(v.Schema(), v.JobIDs, inspectkv.CancelJobs)
+	case *plan.PauseDDLJobs:
+		return b.buildUpdateDDLJobs(v.Schema(), v.JobIDs, inspectkv.PauseJobs)
+	case *plan.ResumeDDLJobs:
+		return b.buildUpdateDDLJobs(v.Schema(), v.JobIDs, inspectkv.ResumeJobs)
+	case *plan.ReloadConfig:
+		return &ReloadConfigExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx)}
+	case *plan.ReloadRewriteRules:
+		return &ReloadRewriteRulesExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx)}
+	case *plan.SplitTable:
+		return &SplitTableExec{
+			baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+			tableInfo:    v.TableInfo,
+			lower:        v.Lower,
+			upper:        v.Upper,
+			num:          v.Num,
+		}
+	case *plan.ScatterTable:
+		return &ScatterTableExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx), tableInfo: v.TableInfo}
+	case *plan.PauseWrites:
+		return &PauseWritesExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx), table: v.Table, paused: v.Paused}
+	case *plan.PinSnapshot:
+		return &PinSnapshotExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx), ts: v.TS, ttl: v.TTL}
+	case *plan.UnpinSnapshot:
+		return &UnpinSnapshotExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx), ts: v.TS}
+	case *plan.PlanReplayerDump:
+		return &PlanReplayerDumpExec{
+			baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+			stmt:         v.Stmt,
+			tables:       v.Tables,
+			explain:      v.Explain,
+			is:           b.is,
+		}
 	case *plan.Show:
 		return b.buildShow(v)
 	case *plan.Simple:
@@ -115,6 +151,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildProjection(v)
 	case *plan.PhysicalMemTable:
 		return b.buildMemTable(v)
+	case *plan.PhysicalExternalScan:
+		return b.buildExternalScan(v)
 	case *plan.PhysicalTableScan:
 		return b.buildTableScan(v)
 	case *plan.PhysicalIndexScan:
@@ -191,6 +229,23 @@ func (b *executorBuilder) buildShowDDLJobs(v *plan.ShowDDLJobs) Executor {
 	return e
 }
 
+// buildUpdateDDLJobs updates the DDL jobs here, the transaction is committed before the results are read.
+func (b *executorBuilder) buildUpdateDDLJobs(schema *expression.Schema, jobIDs []int64,
+	update func(kv.Transaction, []int64) ([]error, error)) Executor {
+	e := &UpdateDDLJobsExec{
+		baseExecutor: newBaseExecutor(schema, b.ctx),
+		jobIDs:       jobIDs,
+	}
+
+	var err error
+	e.errs, err = update(e.ctx.Txn(), jobIDs)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	return e
+}
+
 func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	return &CheckTableExec{
 		tables: v.Tables,
@@ -224,9 +279,10 @@ func (b *executorBuilder) buildSelectLock(v *plan.SelectLock) Executor {
 
 func (b *executorBuilder) buildLimit(v *plan.Limit) Executor {
 	e := &LimitExec{
-		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
-		Offset:       v.Offset,
-		Count:        v.Count,
+		baseExecutor:  newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+		Offset:        v.Offset,
+		Count:         v.Count,
+		CalcFoundRows: v.CalcFoundRows,
 	}
 	return e
 }
@@ -261,6 +317,7 @@ func (b *executorBuilder) buildShow(v *plan.Show) Executor {
 		Flag:         v.Flag,
 		Full:         v.Full,
 		GlobalScope:  v.GlobalScope,
+		ConnectionID: v.ConnectionID,
 		is:           b.is,
 	}
 	if e.Tp == ast.ShowGrants && e.User == nil {
@@ -292,19 +349,21 @@ func (b *executorBuilder) buildSet(v *plan.Set) Executor {
 
 func (b *executorBuilder) buildInsert(v *plan.Insert) Executor {
 	ivs := &InsertValues{
-		ctx:        b.ctx,
-		Columns:    v.Columns,
-		Lists:      v.Lists,
-		Setlist:    v.Setlist,
-		GenColumns: v.GenCols.Columns,
-		GenExprs:   v.GenCols.Exprs,
+		ctx:             b.ctx,
+		Columns:         v.Columns,
+		Lists:           v.Lists,
+		Setlist:         v.Setlist,
+		GenColumns:      v.GenCols.Columns,
+		GenExprs:        v.GenCols.Exprs,
+		SelectFromTable: v.SelectFromTable,
+		returning:       b.buildReturning(v.Returning, v.ReturningSchema),
 	}
 	if len(v.Children()) > 0 {
 		ivs.SelectExec = b.build(v.Children()[0])
 	}
 	ivs.Table = v.Table
 	if v.IsReplace {
-		return b.buildReplace(ivs)
+		return wrapReturning(b.buildReplace(ivs), ivs.returning)
 	}
 	insert := &InsertExec{
 		InsertValues: ivs,
@@ -312,7 +371,28 @@ func (b *executorBuilder) buildInsert(v *plan.Insert) Executor {
 		Priority:     v.Priority,
 		IgnoreErr:    v.IgnoreErr,
 	}
-	return insert
+	return wrapReturning(insert, ivs.returning)
+}
+
+// buildReturning builds the ReturningExec for the RETURNING clause of a write statement, it returns nil if there
+// isn't a RETURNING clause.
+func (b *executorBuilder) buildReturning(exprs []expression.Expression, schema *expression.Schema) *ReturningExec {
+	if exprs == nil {
+		return nil
+	}
+	return &ReturningExec{
+		baseExecutor: newBaseExecutor(schema, b.ctx),
+		Exprs:        exprs,
+	}
+}
+
+// wrapReturning makes the write executor the child of the ReturningExec if there is a RETURNING clause.
+func wrapReturning(e Executor, returning *ReturningExec) Executor {
+	if returning == nil {
+		return e
+	}
+	returning.children = []Executor{e}
+	return returning
 }
 
 func (b *executorBuilder) buildLoadData(v *plan.LoadData) Executor {
@@ -431,6 +511,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			for i, col := range x.schema.Columns {
 				if col.ColName.L == ic.Name.L {
 					us.usedIndex = append(us.usedIndex, i)
+					us.usedIndexDesc = append(us.usedIndexDesc, ic.Desc)
 					break
 				}
 			}
@@ -445,6 +526,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			for i, col := range x.schema.Columns {
 				if col.ColName.L == ic.Name.L {
 					us.usedIndex = append(us.usedIndex, i)
+					us.usedIndexDesc = append(us.usedIndexDesc, ic.Desc)
 					break
 				}
 			}
@@ -459,6 +541,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 			for i, col := range x.schema.Columns {
 				if col.ColName.L == ic.Name.L {
 					us.usedIndex = append(us.usedIndex, i)
+					us.usedIndexDesc = append(us.usedIndexDesc, ic.Desc)
 					break
 				}
 			}
@@ -564,6 +647,31 @@ func (b *executorBuilder) buildSemiJoin(v *plan.PhysicalHashSemiJoin) *HashSemiJ
 		leftHashKey = append(leftHashKey, ln)
 		rightHashKey = append(rightHashKey, rn)
 	}
+	// The null-aware conditions comparing the columns of both sides are used as the keys to hash the rows of a
+	// group, they're still evaluated to know whether the result is NULL.
+	var leftNullAwareKey, rightNullAwareKey []*expression.Column
+	lLen := v.Children()[0].Schema().Len()
+	for _, cond := range v.NullAwareConditions {
+		f, ok := cond.(*expression.ScalarFunction)
+		if !ok || f.FuncName.L != ast.EQ {
+			continue
+		}
+		ln, lOK := f.GetArgs()[0].(*expression.Column)
+		rn, rOK := f.GetArgs()[1].(*expression.Column)
+		if !lOK || !rOK {
+			continue
+		}
+		if ln.Index >= lLen {
+			ln, rn = rn, ln
+		}
+		if ln.Index < lLen && rn.Index >= lLen {
+			// The conditions are resolved by the joined row, the key of the small row is evaluated by itself.
+			rn = rn.Clone().(*expression.Column)
+			rn.Index -= lLen
+			leftNullAwareKey = append(leftNullAwareKey, ln)
+			rightNullAwareKey = append(rightNullAwareKey, rn)
+		}
+	}
 	e := &HashSemiJoinExec{
 		schema:       v.Schema(),
 		otherFilter:  v.OtherConditions,
@@ -577,6 +685,10 @@ func (b *executorBuilder) buildSemiJoin(v *plan.PhysicalHashSemiJoin) *HashSemiJ
 		smallHashKey: rightHashKey,
 		auxMode:      v.WithAux,
 		anti:         v.Anti,
+
+		nullAwareFilter:   v.NullAwareConditions,
+		bigNullAwareKey:   leftNullAwareKey,
+		smallNullAwareKey: rightNullAwareKey,
 	}
 	return e
 }
@@ -590,6 +702,18 @@ func (b *executorBuilder) buildAggregation(v *plan.PhysicalAggregation) Executor
 			GroupByItems: v.GroupByItems,
 		}
 	}
+	if v.HasGby && plan.UseDAGPlanBuilder(b.ctx) && onlyFirstRow(v.AggFuncs) {
+		exprs := make([]expression.Expression, 0, len(v.AggFuncs))
+		for _, aggFunc := range v.AggFuncs {
+			exprs = append(exprs, aggFunc.GetArgs()[0])
+		}
+		return &HashDistinctExec{
+			baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+			GroupByItems: v.GroupByItems,
+			Exprs:        exprs,
+			spillSize:    b.ctx.GetSessionVars().HashDistinctSpillSize,
+		}
+	}
 	return &HashAggExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
 		sc:           b.ctx.GetSessionVars().StmtCtx,
@@ -600,6 +724,17 @@ func (b *executorBuilder) buildAggregation(v *plan.PhysicalAggregation) Executor
 	}
 }
 
+// onlyFirstRow checks if the aggregate functions are all firstrow, so the aggregation only removes the duplicated
+// rows, e.g. the one built for DISTINCT.
+func onlyFirstRow(aggFuncs []expression.AggregationFunction) bool {
+	for _, aggFunc := range aggFuncs {
+		if aggFunc.GetName() != ast.AggFuncFirstRow || aggFunc.IsDistinct() || len(aggFunc.GetArgs()) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
 	exec := &SelectionExec{
 		baseExecutor:   newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -624,7 +759,11 @@ func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
 }
 
 func (b *executorBuilder) getStartTS() uint64 {
-	startTS := b.ctx.GetSessionVars().SnapshotTS
+	sessVars := b.ctx.GetSessionVars()
+	startTS := sessVars.SnapshotTS
+	if startTS == 0 {
+		startTS = sessVars.TxnCtx.ReadTS
+	}
 	if startTS == 0 {
 		startTS = b.ctx.Txn().StartTS()
 	}
@@ -645,6 +784,14 @@ func (b *executorBuilder) buildMemTable(v *plan.PhysicalMemTable) Executor {
 	return ts
 }
 
+func (b *executorBuilder) buildExternalScan(v *plan.PhysicalExternalScan) Executor {
+	return &ExternalScanExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		source:       v.Source,
+		req:          v.ScanRequest(),
+	}
+}
+
 func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 	startTS := b.getStartTS()
 	if b.err != nil {
@@ -855,13 +1002,15 @@ func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
 	for id := range v.Schema().TblID2Handle {
 		tblID2table[id], _ = b.is.TableByID(id)
 	}
-	return &UpdateExec{
+	update := &UpdateExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
 		SelectExec:   b.build(v.Children()[0]),
 		OrderedList:  v.OrderedList,
 		tblID2table:  tblID2table,
 		IgnoreErr:    v.IgnoreErr,
+		returning:    b.buildReturning(v.Returning, v.ReturningSchema),
 	}
+	return wrapReturning(update, update.returning)
 }
 
 func (b *executorBuilder) buildDelete(v *plan.Delete) Executor {
@@ -869,13 +1018,15 @@ func (b *executorBuilder) buildDelete(v *plan.Delete) Executor {
 	for id := range v.Schema().TblID2Handle {
 		tblID2table[id], _ = b.is.TableByID(id)
 	}
-	return &DeleteExec{
+	del := &DeleteExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
 		SelectExec:   b.build(v.Children()[0]),
 		Tables:       v.Tables,
 		IsMultiTable: v.IsMultiTable,
 		tblID2Table:  tblID2table,
+		returning:    b.buildReturning(v.Returning, v.ReturningSchema),
 	}
+	return wrapReturning(del, del.returning)
 }
 
 func (b *executorBuilder) buildCache(v *plan.Cache) Executor {
@@ -1006,16 +1157,23 @@ func (b *executorBuilder) buildAnalyze(v *plan.Analyze) Executor {
 		ctx:   b.ctx,
 		tasks: make([]*analyzeTask, 0, len(v.Children())),
 	}
+	locked := make(map[int64]bool)
 	for _, task := range v.ColTasks {
-		e.tasks = append(e.tasks, &analyzeTask{
-			taskType:  colTask,
-			src:       b.buildTableScanForAnalyze(task.TableInfo, task.PKInfo, task.ColsInfo),
-			tableInfo: task.TableInfo,
-			Columns:   task.ColsInfo,
-			PKInfo:    task.PKInfo,
-		})
+		if b.isStatsLocked(locked, task.TableInfo) {
+			continue
+		}
+		colTask := b.buildAnalyzeColumnsTask(task)
+		if b.err != nil {
+			return nil
+		}
+		if colTask != nil {
+			e.tasks = append(e.tasks, colTask)
+		}
 	}
 	for _, task := range v.IdxTasks {
+		if b.isStatsLocked(locked, task.TableInfo) {
+			continue
+		}
 		e.tasks = append(e.tasks, &analyzeTask{
 			taskType:  idxTask,
 			src:       b.buildIndexScanForAnalyze(task.TableInfo, task.IndexInfo),
@@ -1023,9 +1181,104 @@ func (b *executorBuilder) buildAnalyze(v *plan.Analyze) Executor {
 			tableInfo: task.TableInfo,
 		})
 	}
+	for _, task := range v.FastTasks {
+		if b.isStatsLocked(locked, task.TableInfo) {
+			continue
+		}
+		extendedStats, err := statistics.ExtendedStatsFromStorage(b.ctx, task.TableInfo)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		e.tasks = append(e.tasks, &analyzeTask{
+			taskType:      fastTask,
+			tableInfo:     task.TableInfo,
+			Columns:       task.ColsInfo,
+			PKInfo:        task.PKInfo,
+			extendedStats: extendedStats,
+			indicesInfo:   task.IndicesInfo,
+			numSamples:    task.NumSamples,
+			startTS:       b.getStartTS(),
+		})
+	}
+	if b.err != nil {
+		return nil
+	}
 	return e
 }
 
+// isStatsLocked checks if the statistics of the table are locked by LOCK STATS, the locked tables are skipped by
+// analyze with a warning. locked caches the result of the tables checked before.
+func (b *executorBuilder) isStatsLocked(locked map[int64]bool, tblInfo *model.TableInfo) bool {
+	if isLocked, ok := locked[tblInfo.ID]; ok {
+		return isLocked
+	}
+	isLocked, err := statistics.IsStatsLocked(b.ctx, tblInfo.ID)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return true
+	}
+	locked[tblInfo.ID] = isLocked
+	if isLocked {
+		b.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrStatsLocked.GenByArgs(tblInfo.Name.O))
+	}
+	return isLocked
+}
+
+// buildAnalyzeColumnsTask builds the task analyzing the columns and the column groups created by CREATE STATISTICS.
+// The columns of the groups which aren't analyzed, like the index columns, are scanned after the analyzed ones. It
+// returns nil if there is nothing to analyze.
+func (b *executorBuilder) buildAnalyzeColumnsTask(task plan.AnalyzeColumnsTask) *analyzeTask {
+	extendedStats, err := statistics.ExtendedStatsFromStorage(b.ctx, task.TableInfo)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	if len(task.ColsInfo) == 0 && task.PKInfo == nil && len(extendedStats) == 0 {
+		return nil
+	}
+	cols := append([]*model.ColumnInfo(nil), task.ColsInfo...)
+	// offsets are the offsets of the columns in the scanned rows, the handle is the first column.
+	offsets := make(map[int64]int)
+	base := 0
+	if task.PKInfo != nil {
+		offsets[task.PKInfo.ID] = 0
+		base = 1
+	}
+	for i, col := range cols {
+		offsets[col.ID] = i + base
+	}
+	colGroups := make([][]int, 0, len(extendedStats))
+	for _, stats := range extendedStats {
+		group := make([]int, 0, len(stats.ColIDs))
+		for _, id := range stats.ColIDs {
+			offset, ok := offsets[id]
+			if !ok {
+				for _, col := range task.TableInfo.Columns {
+					if col.ID == id {
+						cols = append(cols, col)
+						break
+					}
+				}
+				offset = len(cols) - 1 + base
+				offsets[id] = offset
+			}
+			group = append(group, offset)
+		}
+		colGroups = append(colGroups, group)
+	}
+	return &analyzeTask{
+		taskType:      colTask,
+		src:           b.buildTableScanForAnalyze(task.TableInfo, task.PKInfo, cols),
+		tableInfo:     task.TableInfo,
+		Columns:       task.ColsInfo,
+		PKInfo:        task.PKInfo,
+		scanColLen:    len(cols),
+		extendedStats: extendedStats,
+		colGroups:     colGroups,
+	}
+}
+
 func (b *executorBuilder) constructDAGReq(plans []plan.PhysicalPlan) *tipb.DAGRequest {
 	dagReq := &tipb.DAGRequest{}
 	dagReq.StartTs = b.getStartTS()
@@ -1085,6 +1338,8 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 		columns:   ts.Columns,
 		handleCol: handleCol,
 		priority:  b.priority,
+		feedback:  b.tableScanFeedback(ts, v.TablePlans),
+		estRows:   int64(v.TablePlans[len(v.TablePlans)-1].StatsCount()),
 	}
 
 	for i := range v.Schema().Columns {
@@ -1121,6 +1376,8 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		columns:   is.Columns,
 		handleCol: handleCol,
 		priority:  b.priority,
+		feedback:  b.indexScanFeedback(is, v.IndexPlans),
+		estRows:   int64(v.IndexPlans[len(v.IndexPlans)-1].StatsCount()),
 	}
 
 	for _, col := range v.OutputColumns {
@@ -1143,13 +1400,14 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 	if b.err != nil {
 		return nil
 	}
-	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
-	table, _ := b.is.TableByID(is.Table.ID)
+	ts := v.TablePlans[0].(*plan.PhysicalTableScan)
+	table, _ := b.is.TableByID(ts.Table.ID)
 	var handleCol *expression.Column
 	if v.NeedColHandle {
-		handleCol = v.Schema().TblID2Handle[is.Table.ID][0]
+		handleCol = v.Schema().TblID2Handle[ts.Table.ID][0]
 	}
 
+	estRows := int64(v.IndexPlans[len(v.IndexPlans)-1].StatsCount())
 	len := v.Schema().Len()
 	if handleIsExtra(handleCol) {
 		len--
@@ -1159,6 +1417,26 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 		tableReq.OutputOffsets = append(tableReq.OutputOffsets, uint32(i))
 	}
 
+	if filterScan, ok := v.IndexPlans[0].(*plan.PhysicalTableScan); ok {
+		// The handles are read by a table scan of the filter columns.
+		for i := range filterScan.Columns {
+			indexReq.OutputOffsets = append(indexReq.OutputOffsets, uint32(i))
+		}
+		return &IndexLookUpExecutor{
+			ctx:          b.ctx,
+			schema:       v.Schema(),
+			dagPB:        indexReq,
+			tableID:      ts.Table.ID,
+			table:        table,
+			tableRanges:  filterScan.Ranges,
+			tableRequest: tableReq,
+			columns:      ts.Columns,
+			handleCol:    handleCol,
+			priority:     b.priority,
+			estRows:      estRows,
+		}
+	}
+	is := v.IndexPlans[0].(*plan.PhysicalIndexScan)
 	e := &IndexLookUpExecutor{
 		ctx:          b.ctx,
 		schema:       v.Schema(),
@@ -1173,6 +1451,52 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 		columns:      is.Columns,
 		handleCol:    handleCol,
 		priority:     b.priority,
+		feedback:     b.indexScanFeedback(is, v.IndexPlans),
+		estRows:      estRows,
 	}
 	return e
 }
+
+// tableScanFeedback returns the feedback of the table scan. It's nil if the rows read by the reader aren't all the
+// rows in the ranges, i.e. there are other operators pushed down with the scan.
+func (b *executorBuilder) tableScanFeedback(ts *plan.PhysicalTableScan, copPlans []plan.PhysicalPlan) *statistics.QueryFeedback {
+	h := b.statsHandle()
+	if h == nil || len(copPlans) > 1 || !ts.Table.PKIsHandle {
+		return nil
+	}
+	pkColInfo := ts.Table.GetPkColInfo()
+	if pkColInfo == nil {
+		return nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	q, err := statistics.NewQueryFeedbackByIntRanges(sc, h.GetTableStats(ts.Table.ID), pkColInfo.ID, ts.Ranges)
+	if err != nil {
+		log.Warnf("[stats] create feedback of table scan fail: %v", errors.ErrorStack(err))
+		return nil
+	}
+	return q
+}
+
+// indexScanFeedback returns the feedback of the index scan, it's nil if there are other operators pushed down with
+// the scan.
+func (b *executorBuilder) indexScanFeedback(is *plan.PhysicalIndexScan, copPlans []plan.PhysicalPlan) *statistics.QueryFeedback {
+	h := b.statsHandle()
+	if h == nil || len(copPlans) > 1 {
+		return nil
+	}
+	sc := b.ctx.GetSessionVars().StmtCtx
+	q, err := statistics.NewQueryFeedbackByIndexRanges(sc, h.GetTableStats(is.Table.ID), is.Index.ID, is.Ranges)
+	if err != nil {
+		log.Warnf("[stats] create feedback of index scan fail: %v", errors.ErrorStack(err))
+		return nil
+	}
+	return q
+}
+
+func (b *executorBuilder) statsHandle() *statistics.Handle {
+	dom := sessionctx.GetDomain(b.ctx)
+	if dom == nil {
+		return nil
+	}
+	return dom.StatsHandle()
+}
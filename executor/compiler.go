@@ -35,7 +35,7 @@ func (c *Compiler) Compile(ctx context.Context, node ast.StmtNode) (ast.Statemen
 		return nil, errors.Trace(err)
 	}
 	// Validate should be after NameResolve.
-	if err := plan.Validate(node, false); err != nil {
+	if err := plan.Validate(node, false, ctx); err != nil {
 		return nil, errors.Trace(err)
 	}
 	p, err := plan.Optimize(ctx, node, is)
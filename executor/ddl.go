@@ -16,16 +16,21 @@ package executor
 import (
 	"strings"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -64,6 +69,8 @@ func (e *DDLExec) Next() (Row, error) {
 		err = e.executeDropTable(x)
 	case *ast.DropIndexStmt:
 		err = e.executeDropIndex(x)
+	case *ast.DropViewStmt:
+		// Views aren't supported, so DROP VIEW IF EXISTS has nothing to drop.
 	case *ast.AlterTableStmt:
 		err = e.executeAlterTable(x)
 	case *ast.RenameTableStmt:
@@ -102,9 +109,15 @@ func (e *DDLExec) executeTruncateTable(s *ast.TruncateTableStmt) error {
 }
 
 func (e *DDLExec) executeRenameTable(s *ast.RenameTableStmt) error {
-	if len(s.TableToTables) != 1 {
-		// Now we only allow one schema changing at the same time.
-		return errors.Errorf("can't run multi schema change")
+	if len(s.TableToTables) > 1 {
+		oldIdents := make([]ast.Ident, 0, len(s.TableToTables))
+		newIdents := make([]ast.Ident, 0, len(s.TableToTables))
+		for _, tt := range s.TableToTables {
+			oldIdents = append(oldIdents, ast.Ident{Schema: tt.OldTable.Schema, Name: tt.OldTable.Name})
+			newIdents = append(newIdents, ast.Ident{Schema: tt.NewTable.Schema, Name: tt.NewTable.Name})
+		}
+		err := sessionctx.GetDomain(e.ctx).DDL().RenameTables(e.ctx, oldIdents, newIdents)
+		return errors.Trace(err)
 	}
 	oldIdent := ast.Ident{Schema: s.OldTable.Schema, Name: s.OldTable.Name}
 	newIdent := ast.Ident{Schema: s.NewTable.Schema, Name: s.NewTable.Name}
@@ -126,10 +139,9 @@ func (e *DDLExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
 		}
 	}
 	err := sessionctx.GetDomain(e.ctx).DDL().CreateSchema(e.ctx, model.NewCIStr(s.Name), opt)
-	if err != nil {
-		if infoschema.ErrDatabaseExists.Equal(err) && s.IfNotExists {
-			err = nil
-		}
+	if infoschema.ErrDatabaseExists.Equal(err) && s.IfNotExists {
+		e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+		err = nil
 	}
 	return errors.Trace(err)
 }
@@ -137,7 +149,9 @@ func (e *DDLExec) executeCreateDatabase(s *ast.CreateDatabaseStmt) error {
 func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	var err error
-	if s.ReferTable == nil {
+	if s.Select != nil {
+		err = e.executeCreateTableSelect(ident, s)
+	} else if s.ReferTable == nil {
 		err = sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
 	} else {
 		referIdent := ast.Ident{Schema: s.ReferTable.Schema, Name: s.ReferTable.Name}
@@ -145,6 +159,7 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	}
 	if infoschema.ErrTableExists.Equal(err) {
 		if s.IfNotExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 			return nil
 		}
 		return err
@@ -152,9 +167,143 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	return errors.Trace(err)
 }
 
+// executeCreateTableSelect creates the table with the columns of the query result, then inserts the rows of the query.
+// The table is dropped if the rows can't be inserted.
+func (e *DDLExec) executeCreateTableSelect(ident ast.Ident, s *ast.CreateTableStmt) error {
+	selPlan, err := plan.Optimize(e.ctx, s.Select, e.is)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	colDefs, insertCols := buildCreateTableSelectCols(s.Cols, selPlan.Schema())
+	d := sessionctx.GetDomain(e.ctx).DDL()
+	err = d.CreateTable(e.ctx, ident, colDefs, s.Constraints, s.Options)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	err = e.insertSelectRows(ident, insertCols, s.Select)
+	if err != nil {
+		if txn := e.ctx.Txn(); txn != nil && txn.Valid() {
+			if err1 := txn.Rollback(); err1 != nil {
+				log.Warnf("[ddl] rollback the rows of %s failed %v", ident, err1)
+			}
+		}
+		if err1 := d.DropTable(e.ctx, ident); err1 != nil {
+			log.Errorf("[ddl] drop %s after inserting the rows failed %v", ident, err1)
+		}
+	}
+	return errors.Trace(err)
+}
+
+// insertSelectRows inserts the rows of the query into the table created by CREATE TABLE ... SELECT.
+// The rows are inserted in batches, so a large query result doesn't exceed the size limit of a transaction.
+func (e *DDLExec) insertSelectRows(ident ast.Ident, cols []*ast.ColumnName, sel ast.ResultSetNode) error {
+	// Insert the rows in a new transaction with the information schema that has the created table,
+	// so it will pass schema check.
+	if err := e.ctx.NewTxn(); err != nil {
+		return errors.Trace(err)
+	}
+	is := sessionctx.GetDomain(e.ctx).InfoSchema()
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	txnCtx.InfoSchema = is
+	txnCtx.SchemaVersion = is.SchemaMetaVersion()
+
+	stmt := &ast.InsertStmt{
+		Table: &ast.TableRefsClause{
+			TableRefs: &ast.Join{
+				Left: &ast.TableSource{Source: &ast.TableName{Schema: ident.Schema, Name: ident.Name}},
+			},
+		},
+		Columns: cols,
+		Select:  sel,
+	}
+	if err := plan.Preprocess(stmt, is, e.ctx); err != nil {
+		return errors.Trace(err)
+	}
+	p, err := plan.Optimize(e.ctx, stmt, is)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	b := newExecutorBuilder(e.ctx, is, kv.PriorityNormal)
+	exec := b.build(p)
+	if b.err != nil {
+		return errors.Trace(b.err)
+	}
+	if err = exec.Open(); err != nil {
+		return errors.Trace(err)
+	}
+
+	vars := e.ctx.GetSessionVars()
+	batchInsert := vars.BatchInsert
+	vars.BatchInsert = true
+	_, err = exec.Next()
+	vars.BatchInsert = batchInsert
+	if err1 := exec.Close(); err == nil {
+		err = err1
+	}
+	return errors.Trace(err)
+}
+
+// buildCreateTableSelectCols returns the definitions of the columns of CREATE TABLE ... SELECT, and the columns that
+// the fields of the query are inserted into. A field is inserted into the defined column that has the same name,
+// or a new column that is created after the defined columns with the type of the field.
+func buildCreateTableSelectCols(defs []*ast.ColumnDef, schema *expression.Schema) ([]*ast.ColumnDef, []*ast.ColumnName) {
+	colDefs := make([]*ast.ColumnDef, 0, len(defs)+schema.Len())
+	colDefs = append(colDefs, defs...)
+	insertCols := make([]*ast.ColumnName, 0, schema.Len())
+	for _, col := range schema.Columns {
+		insertCols = append(insertCols, &ast.ColumnName{Name: col.ColName})
+		defined := false
+		for _, def := range defs {
+			if def.Name.Name.L == col.ColName.L {
+				defined = true
+				break
+			}
+		}
+		if defined {
+			continue
+		}
+		colDefs = append(colDefs, &ast.ColumnDef{
+			Name: &ast.ColumnName{Name: col.ColName},
+			Tp:   createTableSelectFieldType(col.RetType),
+		})
+	}
+	return colDefs, insertCols
+}
+
+// createTableSelectFieldType returns the type of the column that is created for a field of the query.
+// It keeps the type and the NOT NULL, UNSIGNED, ZEROFILL and BINARY attributes of the field,
+// the strings that can't be stored in CHAR or VARCHAR columns are stored in TEXT columns.
+func createTableSelectFieldType(ft *types.FieldType) *types.FieldType {
+	tp := *ft
+	tp.Elems = append([]string(nil), ft.Elems...)
+	tp.Flag &= mysql.NotNullFlag | mysql.UnsignedFlag | mysql.ZerofillFlag | mysql.BinaryFlag
+	switch tp.Tp {
+	case mysql.TypeNull:
+		// Like MySQL, NULL is stored in a BINARY(0) column.
+		tp.Tp, tp.Flen, tp.Decimal = mysql.TypeString, 0, 0
+		tp.Charset, tp.Collate = charset.CharsetBin, charset.CollationBin
+		tp.Flag |= mysql.BinaryFlag
+	case mysql.TypeString:
+		if tp.Flen == types.UnspecifiedLength || tp.Flen > mysql.MaxFieldCharLength {
+			tp.Tp, tp.Flen = mysql.TypeLongBlob, types.UnspecifiedLength
+		}
+	case mysql.TypeVarchar, mysql.TypeVarString:
+		tp.Tp = mysql.TypeVarchar
+		if tp.Flen == types.UnspecifiedLength || tp.Flen > mysql.MaxFieldVarCharLength {
+			tp.Tp, tp.Flen = mysql.TypeLongBlob, types.UnspecifiedLength
+		}
+	}
+	return &tp
+}
+
 func (e *DDLExec) executeCreateIndex(s *ast.CreateIndexStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().CreateIndex(e.ctx, ident, s.Unique, model.NewCIStr(s.IndexName), s.IndexColNames, s.IndexOption)
+	if ddl.ErrDupKeyName.Equal(err) && s.IfNotExists {
+		e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
+		err = nil
+	}
 	return errors.Trace(err)
 }
 
@@ -162,10 +311,10 @@ func (e *DDLExec) executeDropDatabase(s *ast.DropDatabaseStmt) error {
 	dbName := model.NewCIStr(s.Name)
 	err := sessionctx.GetDomain(e.ctx).DDL().DropSchema(e.ctx, dbName)
 	if infoschema.ErrDatabaseNotExists.Equal(err) {
+		err = infoschema.ErrDatabaseDropExists.GenByArgs(s.Name)
 		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 			err = nil
-		} else {
-			err = infoschema.ErrDatabaseDropExists.GenByArgs(s.Name)
 		}
 	}
 	sessionVars := e.ctx.GetSessionVars()
@@ -209,8 +358,12 @@ func (e *DDLExec) executeDropTable(s *ast.DropTableStmt) error {
 			return errors.Trace(err)
 		}
 	}
-	if len(notExistTables) > 0 && !s.IfExists {
-		return infoschema.ErrTableDropExists.GenByArgs(strings.Join(notExistTables, ","))
+	if len(notExistTables) > 0 {
+		err := infoschema.ErrTableDropExists.GenByArgs(strings.Join(notExistTables, ","))
+		if !s.IfExists {
+			return err
+		}
+		e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 	}
 	return nil
 }
@@ -218,7 +371,10 @@ func (e *DDLExec) executeDropTable(s *ast.DropTableStmt) error {
 func (e *DDLExec) executeDropIndex(s *ast.DropIndexStmt) error {
 	ti := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	err := sessionctx.GetDomain(e.ctx).DDL().DropIndex(e.ctx, ti, model.NewCIStr(s.IndexName))
-	if (infoschema.ErrDatabaseNotExists.Equal(err) || infoschema.ErrTableNotExists.Equal(err)) && s.IfExists {
+	isNotExists := infoschema.ErrDatabaseNotExists.Equal(err) || infoschema.ErrTableNotExists.Equal(err) ||
+		ddl.ErrCantDropFieldOrKey.Equal(err)
+	if isNotExists && s.IfExists {
+		e.ctx.GetSessionVars().StmtCtx.AppendWarning(err)
 		err = nil
 	}
 	return errors.Trace(err)
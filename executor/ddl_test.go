@@ -18,6 +18,7 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
@@ -131,6 +132,8 @@ func (s *testSuite) TestCreateDropTable(c *C) {
 	tk.MustExec("drop table if exists drop_test")
 	tk.MustExec("create table drop_test (a int)")
 	tk.MustExec("drop table drop_test")
+	// Views aren't supported, but dropping one that may exist succeeds.
+	tk.MustExec("drop view if exists drop_test, test.v")
 }
 
 func (s *testSuite) TestCreateDropIndex(c *C) {
@@ -278,3 +281,66 @@ func (s *testSuite) TestUnsupportedCharset(c *C) {
 	}
 	tk.MustExec("drop database " + dbName)
 }
+
+func (s *testSuite) TestAlterPlacementPolicy(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	if s.cluster == nil {
+		c.Skip("the placement rules are only set in the tikv store")
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+	tableID := func() int64 {
+		tbl, err := sessionctx.GetDomain(tk.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+		c.Assert(err, IsNil)
+		return tbl.Meta().ID
+	}
+	rules := func(id int64) []*kv.PlacementRule {
+		return s.cluster.GetPlacementRules(fmt.Sprintf("tidb_table_%d", id))
+	}
+
+	tk.MustExec("alter table t placement policy replicas = 5 constraints = '+zone=sh' leader_constraints = '-disk=hdd'")
+	id := tableID()
+	c.Assert(rules(id), HasLen, 2)
+	c.Assert(rules(id)[0].Role, Equals, "leader")
+	c.Assert(rules(id)[1].Count, Equals, 4)
+	createSQL := "CREATE TABLE `t` (\n" +
+		"  `a` int(11) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin" +
+		" /*T! PLACEMENT POLICY REPLICAS=5 CONSTRAINTS='+zone=sh' LEADER_CONSTRAINTS='-disk=hdd' */"
+	tk.MustQuery("show create table t").Check(testkit.Rows("t " + createSQL))
+
+	// The output of SHOW CREATE TABLE creates the table with the same policy.
+	tk.MustExec("drop table t")
+	c.Assert(rules(id), HasLen, 0)
+	tk.MustExec(createSQL)
+	id = tableID()
+	c.Assert(rules(id), HasLen, 2)
+	c.Assert(rules(id)[1].Count, Equals, 4)
+	tk.MustQuery("show create table t").Check(testkit.Rows("t " + createSQL))
+
+	// The rules follow the new table ID of the truncated table.
+	tk.MustExec("truncate table t")
+	newID := tableID()
+	c.Assert(rules(id), HasLen, 0)
+	c.Assert(rules(newID), HasLen, 2)
+
+	tk.MustExec("alter table t placement policy default")
+	c.Assert(rules(newID), HasLen, 0)
+	tk.MustExec("alter table t placement policy constraints '+zone=bj'")
+	c.Assert(rules(newID), HasLen, 1)
+	c.Assert(rules(newID)[0].Count, Equals, 3)
+	tk.MustExec("drop table t")
+	c.Assert(rules(newID), HasLen, 0)
+
+	tk.MustExec("create table t (a int)")
+	_, err := tk.Exec("alter table t placement policy constraints 'zone'")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("alter table t placement policy replicas 0")
+	c.Assert(err, NotNil)
+	c.Assert(rules(tableID()), HasLen, 0)
+}
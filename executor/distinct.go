@@ -0,0 +1,247 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// distinctPartitionCount is the number of the temporary files the spilled rows are partitioned to.
+const distinctPartitionCount = 16
+
+// HashDistinctExec removes the duplicated rows, it's built from a hash aggregation whose aggregate functions are all
+// firstrow, like the one of a DISTINCT. Unlike HashAggExec, a row is returned as soon as its group key is met for the
+// first time, so the rows are streamed to the parent without waiting for the child to be drained.
+// When spillSize keys are kept in memory, the rows of the keys that aren't in memory are spilled to temporary files
+// partitioned by the hash of the key, and each partition is deduplicated after the child is drained.
+type HashDistinctExec struct {
+	baseExecutor
+
+	GroupByItems []expression.Expression
+	// Exprs are the arguments of the firstrow functions, they're evaluated to build the returned row.
+	Exprs []expression.Expression
+
+	spillSize  int
+	keys       map[string]struct{}
+	partitions []*distinctPartition
+	// partIdx is the partition being deduplicated, it's -1 while the rows are read from the child.
+	partIdx int
+}
+
+// distinctPartition is a temporary file of the spilled rows, every record is a length prefixed group key followed
+// by a length prefixed row.
+type distinctPartition struct {
+	file   *os.File
+	writer *bufio.Writer
+	reader *bufio.Reader
+}
+
+// Open implements the Executor Open interface.
+func (e *HashDistinctExec) Open() error {
+	e.keys = make(map[string]struct{})
+	e.partitions = make([]*distinctPartition, distinctPartitionCount)
+	e.partIdx = -1
+	return errors.Trace(e.children[0].Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *HashDistinctExec) Close() error {
+	e.keys = nil
+	e.removePartitions()
+	return errors.Trace(e.children[0].Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *HashDistinctExec) Next() (Row, error) {
+	for e.partIdx < 0 {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			err = e.finishSpill()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			break
+		}
+		key, err := encodeGroupKey(row, e.GroupByItems)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, ok := e.keys[string(key)]; ok {
+			continue
+		}
+		retRow := make([]types.Datum, 0, len(e.Exprs))
+		for _, expr := range e.Exprs {
+			v, err := expr.Eval(row)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			retRow = append(retRow, v)
+		}
+		if len(e.keys) >= e.spillSize {
+			err = e.spill(key, retRow)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
+		e.keys[string(key)] = struct{}{}
+		return retRow, nil
+	}
+	for e.partIdx < len(e.partitions) {
+		part := e.partitions[e.partIdx]
+		if part == nil {
+			e.partIdx++
+			continue
+		}
+		key, data, err := part.read()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if key == nil {
+			// The keys of different partitions never equal, so the keys of the drained partition are released.
+			e.keys = make(map[string]struct{})
+			e.partIdx++
+			continue
+		}
+		if _, ok := e.keys[string(key)]; ok {
+			continue
+		}
+		e.keys[string(key)] = struct{}{}
+		retRow := make([]types.Datum, e.schema.Len())
+		err = codec.SetRawValues(data, retRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		err = decodeRawValues(retRow, e.schema, e.ctx.GetSessionVars().GetTimeZone())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return retRow, nil
+	}
+	return nil, nil
+}
+
+// spill writes the row to the partition of its key.
+func (e *HashDistinctExec) spill(key []byte, row []types.Datum) error {
+	h := fnv.New32a()
+	h.Write(key)
+	idx := h.Sum32() % distinctPartitionCount
+	if e.partitions[idx] == nil {
+		file, err := ioutil.TempFile("", "tidb_distinct_")
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.partitions[idx] = &distinctPartition{file: file, writer: bufio.NewWriter(file)}
+	}
+	var data []byte
+	loc := e.ctx.GetSessionVars().GetTimeZone()
+	for _, d := range row {
+		b, err := tablecodec.EncodeValue(d, loc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		data = append(data, b...)
+	}
+	return errors.Trace(e.partitions[idx].write(key, data))
+}
+
+// finishSpill flushes the partitions and rewinds them to be read.
+func (e *HashDistinctExec) finishSpill() error {
+	e.partIdx = 0
+	for _, part := range e.partitions {
+		if part == nil {
+			continue
+		}
+		err := part.writer.Flush()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = part.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		part.reader = bufio.NewReader(part.file)
+	}
+	return nil
+}
+
+func (e *HashDistinctExec) removePartitions() {
+	for _, part := range e.partitions {
+		if part == nil {
+			continue
+		}
+		if err := part.file.Close(); err != nil {
+			log.Errorf("[hash distinct] close file %s error: %v", part.file.Name(), err)
+		}
+		if err := os.Remove(part.file.Name()); err != nil {
+			log.Errorf("[hash distinct] remove file %s error: %v", part.file.Name(), err)
+		}
+	}
+	e.partitions = nil
+}
+
+func (p *distinctPartition) write(key, data []byte) error {
+	var buf [binary.MaxVarintLen64]byte
+	for _, b := range [][]byte{key, data} {
+		n := binary.PutUvarint(buf[:], uint64(len(b)))
+		if _, err := p.writer.Write(buf[:n]); err != nil {
+			return errors.Trace(err)
+		}
+		if _, err := p.writer.Write(b); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// read reads the next record of the partition, the key is nil if the partition is drained.
+func (p *distinctPartition) read() (key, data []byte, err error) {
+	key, err = p.readBytes()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	data, err = p.readBytes()
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return key, data, nil
+}
+
+func (p *distinctPartition) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(p.reader)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(p.reader, b)
+	return b, errors.Trace(err)
+}
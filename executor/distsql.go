@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -148,23 +149,37 @@ func tableHandlesToKVRanges(tid int64, handles []int64) []kv.KeyRange {
 }
 
 // indexValuesToKVRanges will convert the index datums to kv ranges.
-func indexValuesToKVRanges(tid, idxID int64, values [][]types.Datum) ([]kv.KeyRange, error) {
+func indexValuesToKVRanges(tid int64, index *model.IndexInfo, values [][]types.Datum, fieldTypes []*types.FieldType) ([]kv.KeyRange, error) {
 	krs := make([]kv.KeyRange, 0, len(values))
+	collators := make([]collate.Collator, len(fieldTypes))
+	for i, ft := range fieldTypes {
+		collators[i] = collate.GetCollatorByFieldType(ft)
+	}
+	keyVals := make([]types.Datum, 0, len(fieldTypes))
 	for _, vals := range values {
+		// The strings in the index keys are the sort keys of their collations.
+		keyVals = keyVals[:0]
+		for i, v := range vals {
+			keyVals = append(keyVals, collate.KeyDatum(v, collators[i]))
+		}
 		// TODO: We don't process the case that equal key has different types.
-		valKey, err := codec.EncodeKey(nil, vals...)
+		valKey, err := tablecodec.EncodeIndexValues(nil, index.Columns, keyVals)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 		valKeyNext := []byte(kv.Key(valKey).PrefixNext())
-		rangeBeginKey := tablecodec.EncodeIndexSeekKey(tid, idxID, valKey)
-		rangeEndKey := tablecodec.EncodeIndexSeekKey(tid, idxID, valKeyNext)
+		rangeBeginKey := tablecodec.EncodeIndexSeekKey(tid, index.ID, valKey)
+		rangeEndKey := tablecodec.EncodeIndexSeekKey(tid, index.ID, valKeyNext)
 		krs = append(krs, kv.KeyRange{StartKey: rangeBeginKey, EndKey: rangeEndKey})
 	}
+	if index.HasDescColumn() {
+		// The keys of the descending columns are in the reverse order of the values.
+		sort.Sort(kvRangeSlice(krs))
+	}
 	return krs, nil
 }
 
-func indexRangesToKVRanges(sc *variable.StatementContext, tid, idxID int64, ranges []*types.IndexRange, fieldTypes []*types.FieldType) ([]kv.KeyRange, error) {
+func indexRangesToKVRanges(sc *variable.StatementContext, tid int64, index *model.IndexInfo, ranges []*types.IndexRange, fieldTypes []*types.FieldType) ([]kv.KeyRange, error) {
 	krs := make([]kv.KeyRange, 0, len(ranges))
 	for _, ran := range ranges {
 		err := convertIndexRangeTypes(sc, ran, fieldTypes)
@@ -172,24 +187,18 @@ func indexRangesToKVRanges(sc *variable.StatementContext, tid, idxID int64, rang
 			return nil, errors.Trace(err)
 		}
 
-		low, err := codec.EncodeKey(nil, ran.LowVal...)
+		low, high, err := tablecodec.EncodeIndexRange(ran, index.Columns)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		if ran.LowExclude {
-			low = []byte(kv.Key(low).PrefixNext())
-		}
-		high, err := codec.EncodeKey(nil, ran.HighVal...)
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-		if !ran.HighExclude {
-			high = []byte(kv.Key(high).PrefixNext())
-		}
-		startKey := tablecodec.EncodeIndexSeekKey(tid, idxID, low)
-		endKey := tablecodec.EncodeIndexSeekKey(tid, idxID, high)
+		startKey := tablecodec.EncodeIndexSeekKey(tid, index.ID, low)
+		endKey := tablecodec.EncodeIndexSeekKey(tid, index.ID, high)
 		krs = append(krs, kv.KeyRange{StartKey: startKey, EndKey: endKey})
 	}
+	if index.HasDescColumn() {
+		// The keys of the descending columns are in the reverse order of the values.
+		sort.Sort(kvRangeSlice(krs))
+	}
 	return krs, nil
 }
 
@@ -293,6 +302,12 @@ func (p int64Slice) Len() int           { return len(p) }
 func (p int64Slice) Less(i, j int) bool { return p[i] < p[j] }
 func (p int64Slice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 
+type kvRangeSlice []kv.KeyRange
+
+func (p kvRangeSlice) Len() int           { return len(p) }
+func (p kvRangeSlice) Less(i, j int) bool { return p[i].StartKey.Cmp(p[j].StartKey) < 0 }
+func (p kvRangeSlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
 // Closeable is a interface for closeable structures.
 type Closeable interface {
 	// Close closes the object.
@@ -664,7 +679,7 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 	}
 	sv := e.ctx.GetSessionVars()
 	sc := sv.StmtCtx
-	keyRanges, err := indexRangesToKVRanges(sc, e.table.Meta().ID, e.index.ID, e.ranges, fieldTypes)
+	keyRanges, err := indexRangesToKVRanges(sc, e.table.Meta().ID, e.index, e.ranges, fieldTypes)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -672,7 +687,7 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 }
 
 func getIsolationLevel(sv *variable.SessionVars) kv.IsoLevel {
-	if sv.Systems[variable.TxnIsolation] == ast.ReadCommitted {
+	if sv.TxnIsolationLevel() == ast.ReadCommitted {
 		return kv.RC
 	}
 	return kv.SI
@@ -22,11 +22,16 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 )
 
@@ -133,3 +138,135 @@ func (s *testSuite) TestCopClientSend(c *C) {
 	keyword := "(*copIterator).work"
 	c.Check(checkGoroutineExists(keyword), IsFalse)
 }
+
+// TestIndexLookUpBatches checks that the handles of an index double read are looked up in the right order when the
+// lookup tasks are built from several regions.
+func (s *testSuite) TestIndexLookUpBatches(c *C) {
+	if _, ok := s.store.GetClient().(*tikv.CopClient); !ok {
+		// Make sure the store is tikv store.
+		return
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("set @@tidb_index_lookup_size = '10'")
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists batch")
+	tk.MustExec("create table batch (id int primary key, c_idx int, c_col int, index idx (c_idx))")
+
+	// Insert 100 rows, the index order is the reverse of the handle order.
+	var values []string
+	for i := 0; i < 100; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, %d)", i, 100-i, i))
+	}
+	tk.MustExec("insert batch values " + strings.Join(values, ","))
+
+	// Split the table and the index, so the index regions have fewer rows than a lookup task.
+	dom := sessionctx.GetDomain(tk.Se)
+	tbl, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("batch"))
+	c.Assert(err, IsNil)
+	tblInfo := tbl.Meta()
+	s.cluster.SplitTable(s.mvccStore, tblInfo.ID, 7)
+	s.cluster.SplitIndex(s.mvccStore, tblInfo.ID, tblInfo.Indices[0].ID, 30)
+
+	var expected []string
+	for i := 99; i >= 0; i-- {
+		expected = append(expected, fmt.Sprintf("%d %d", i, i))
+	}
+	tk.MustQuery("select id, c_col from batch use index (idx) where c_idx > 0 order by c_idx").Check(testkit.Rows(expected...))
+	tk.MustQuery("select id, c_col from batch use index (idx) where c_idx > 0 order by c_idx limit 15").Check(testkit.Rows(expected[:15]...))
+	tk.MustQuery("select count(c_col), sum(c_col) from batch use index (idx) where c_idx > 50").Check(testkit.Rows("50 1225"))
+	tk.MustQuery("select c_col from batch use index (idx) where c_idx in (1, 50, 99) order by c_idx").Check(testkit.Rows("99", "50", "1"))
+}
+
+// TestLateMaterialization checks that the columns of a filtered table scan read by the handles of the filtered rows
+// are the right ones.
+func (s *testSuite) TestLateMaterialization(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("set @@tidb_opt_late_materialization = 1")
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists wide")
+	tk.MustExec("create table wide (id int primary key, a int, b varchar(20), c double, d datetime)")
+	var values []string
+	for i := 0; i < 20; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d, 'b%d', %d.5, '2017-01-%02d 00:00:00')", i, i%5, i, i, i+1))
+	}
+	tk.MustExec("insert wide values " + strings.Join(values, ","))
+
+	rows := tk.MustQuery("explain select * from wide where a = 1").Rows()
+	c.Assert(strings.HasPrefix(rows[len(rows)-1][0].(string), "IndexLookUp"), IsTrue)
+	tk.MustQuery("select * from wide where a = 1").Sort().Check(testkit.Rows(
+		"1 1 b1 1.5 2017-01-02 00:00:00",
+		"11 1 b11 11.5 2017-01-12 00:00:00",
+		"16 1 b16 16.5 2017-01-17 00:00:00",
+		"6 1 b6 6.5 2017-01-07 00:00:00",
+	))
+	tk.MustQuery("select id, b, d from wide where a = 1 and c > 10 order by id").Check(testkit.Rows(
+		"11 b11 2017-01-12 00:00:00",
+		"16 b16 2017-01-17 00:00:00",
+	))
+	tk.MustQuery("select count(*), sum(c) from wide where a > 3").Check(testkit.Rows("4 48"))
+	tk.MustQuery("select * from wide where a = 5").Check(testkit.Rows())
+}
+
+func (s *testSuite) TestAdminSplitTable(c *C) {
+	if _, ok := s.store.GetClient().(*tikv.CopClient); !ok {
+		// Make sure the store is tikv store.
+		return
+	}
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists split")
+	tk.MustExec("create table split (id int primary key, v int)")
+	tk.MustExec("insert split values (-10, 1), (0, 2), (30, 3), (99, 4), (200, 5)")
+	tbl, err := sessionctx.GetDomain(tk.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("split"))
+	c.Assert(err, IsNil)
+	tblID := tbl.Meta().ID
+
+	regionOf := func(h int64) *metapb.Region {
+		region, _ := s.cluster.GetRegionByKey(mocktikv.NewMvccKey(tablecodec.EncodeRowKeyWithHandle(tblID, h)))
+		return region
+	}
+	c.Assert(regionOf(0).GetId(), Equals, regionOf(99).GetId())
+	tk.MustExec("admin split table split between 0 and 100 regions 4")
+	// The regions are [0, 25), [25, 50), [50, 75), [75, 100).
+	for _, h := range []int64{0, 25, 50, 75, 100} {
+		c.Assert(regionOf(h).GetStartKey(), BytesEquals, []byte(mocktikv.NewMvccKey(tablecodec.EncodeRowKeyWithHandle(tblID, h))))
+	}
+	c.Assert(regionOf(-10).GetId(), Not(Equals), regionOf(0).GetId())
+	c.Assert(regionOf(24).GetId(), Equals, regionOf(0).GetId())
+	c.Assert(regionOf(99).GetId(), Equals, regionOf(75).GetId())
+	c.Assert(regionOf(200).GetId(), Equals, regionOf(100).GetId())
+	// Splitting the split regions again does nothing.
+	regionsCount := len(s.cluster.GetAllRegions())
+	tk.MustExec("admin split table split between 0 and 100 regions 2")
+	c.Assert(s.cluster.GetAllRegions(), HasLen, regionsCount)
+	tk.MustExec("admin scatter table split")
+
+	tk.MustQuery("select * from split").Check(testkit.Rows("-10 1", "0 2", "30 3", "99 4", "200 5"))
+	tk.MustExec("update split set v = v + 1")
+	tk.MustQuery("select sum(v) from split").Check(testkit.Rows("20"))
+
+	_, err = tk.Exec("admin split table split between 0 and 100 regions 0")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidSplitOption), IsTrue)
+	_, err = tk.Exec("admin split table split between 100 and 0 regions 4")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidSplitOption), IsTrue)
+	_, err = tk.Exec("admin split table split between 0 and 3 regions 4")
+	c.Assert(terror.ErrorEqual(err, plan.ErrInvalidSplitOption), IsTrue)
+	_, err = tk.Exec("admin split table split_not_exists between 0 and 100 regions 4")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestAdminSplitTableNotSupported(c *C) {
+	// The store isn't closed, the status variables of the DDL of its domain are read by SHOW STATUS.
+	store, err := tidb.NewStore("memory://split_not_supported")
+	c.Assert(err, IsNil)
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	tk := testkit.NewTestKit(c, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table split (id int primary key)")
+	// The store doesn't store the data in regions, the statements fail instead of doing nothing.
+	_, err = tk.Exec("admin split table split between 0 and 100 regions 4")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSplitNotSupported), IsTrue, Commentf("err %v", err))
+	_, err = tk.Exec("admin scatter table split")
+	c.Assert(terror.ErrorEqual(err, executor.ErrSplitNotSupported), IsTrue, Commentf("err %v", err))
+}
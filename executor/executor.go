@@ -14,11 +14,14 @@
 package executor
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
@@ -27,10 +30,14 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/rowlock"
+	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -38,9 +45,16 @@ var (
 	_ Executor = &CheckTableExec{}
 	_ Executor = &ExistsExec{}
 	_ Executor = &HashAggExec{}
+	_ Executor = &HashDistinctExec{}
 	_ Executor = &LimitExec{}
 	_ Executor = &MaxOneRowExec{}
 	_ Executor = &ProjectionExec{}
+	_ Executor = &ReloadConfigExec{}
+	_ Executor = &ReloadRewriteRulesExec{}
+	_ Executor = &SplitTableExec{}
+	_ Executor = &ScatterTableExec{}
+	_ Executor = &PauseWritesExec{}
+	_ Executor = &PinSnapshotExec{}
 	_ Executor = &SelectionExec{}
 	_ Executor = &SelectLockExec{}
 	_ Executor = &ShowDDLExec{}
@@ -51,6 +65,8 @@ var (
 	_ Executor = &TableScanExec{}
 	_ Executor = &TopNExec{}
 	_ Executor = &UnionExec{}
+	_ Executor = &UnpinSnapshotExec{}
+	_ Executor = &UpdateDDLJobsExec{}
 )
 
 // Error instances.
@@ -67,6 +83,18 @@ var (
 	ErrBuildExecutor        = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
 	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
 	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrLockNowait           = terror.ClassExecutor.New(codeLockNowait, mysql.MySQLErrName[mysql.ErrLockNowait])
+	ErrStatsExists          = terror.ClassExecutor.New(codeStatsExists, "Statistics '%s' already exists")
+	ErrStatsNotExists       = terror.ClassExecutor.New(codeStatsNotExists, "Statistics '%s' doesn't exist")
+	ErrStatsColumns         = terror.ClassExecutor.New(codeStatsColumns, "Statistics need at least two different columns")
+	ErrStatsLocked          = terror.ClassExecutor.New(codeStatsLocked, "Statistics of table '%s' are locked, skip analyzing it")
+	ErrStatsJSON            = terror.ClassExecutor.New(codeStatsJSON, "Invalid statistics JSON: %s")
+	ErrSessionStatesInTxn   = terror.ClassExecutor.New(codeSessionStatesInTxn, "Can't %s the session states in a transaction")
+	ErrSessionStatesJSON    = terror.ClassExecutor.New(codeSessionStatesJSON, "Invalid session states JSON: %s")
+	ErrSnapshotNotPinned    = terror.ClassExecutor.New(codeSnapshotNotPinned, "Snapshot %d isn't pinned or the pin has expired")
+	ErrPlanReplayerFile     = terror.ClassExecutor.New(codePlanReplayerFile, "Invalid plan replayer file: %s")
+	ErrCantChangeTxChars    = terror.ClassExecutor.New(codeCantChangeTxChars, mysql.MySQLErrName[mysql.ErrCantChangeTxCharacteristics])
+	ErrSplitNotSupported    = terror.ClassExecutor.New(codeSplitNotSupported, "The storage doesn't store the data in regions, can't %s the regions")
 )
 
 // Error codes.
@@ -81,9 +109,21 @@ const (
 	codeResultIsEmpty        terror.ErrCode = 8
 	codeErrBuildExec         terror.ErrCode = 9
 	codeBatchInsertFail      terror.ErrCode = 10
+	codeStatsExists          terror.ErrCode = 11
+	codeStatsNotExists       terror.ErrCode = 12
+	codeStatsColumns         terror.ErrCode = 13
+	codeStatsLocked          terror.ErrCode = 14
+	codeStatsJSON            terror.ErrCode = 15
+	codeSessionStatesInTxn   terror.ErrCode = 16
+	codeSessionStatesJSON    terror.ErrCode = 17
+	codeSnapshotNotPinned    terror.ErrCode = 18
+	codePlanReplayerFile     terror.ErrCode = 19
+	codeSplitNotSupported    terror.ErrCode = 20
 	CodePasswordNoMatch      terror.ErrCode = 1133 // MySQL error code
 	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
 	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
+	codeLockNowait           terror.ErrCode = 3572 // MySQL error code
+	codeCantChangeTxChars    terror.ErrCode = 1568 // MySQL error code
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -166,8 +206,11 @@ func (e *ShowDDLExec) Next() (Row, error) {
 	}
 
 	var ddlJob string
-	if e.ddlInfo.Job != nil {
-		ddlJob = e.ddlInfo.Job.String()
+	for i, job := range e.ddlInfo.Jobs {
+		if i != 0 {
+			ddlJob += "\n"
+		}
+		ddlJob += job.String()
 	}
 
 	row := types.MakeDatums(
@@ -202,6 +245,248 @@ func (e *ShowDDLJobsExec) Next() (Row, error) {
 	return row, nil
 }
 
+// UpdateDDLJobsExec represents a cancel, pause or resume DDL jobs executor.
+// It returns the result of each job, the jobs are updated when it's built.
+type UpdateDDLJobsExec struct {
+	baseExecutor
+
+	cursor int
+	jobIDs []int64
+	errs   []error
+}
+
+// Next implements the Executor Next interface.
+func (e *UpdateDDLJobsExec) Next() (Row, error) {
+	if e.cursor >= len(e.jobIDs) {
+		return nil, nil
+	}
+
+	result := "successful"
+	if err := e.errs[e.cursor]; err != nil {
+		result = err.Error()
+	}
+	row := types.MakeDatums(e.jobIDs[e.cursor], result)
+	e.cursor++
+
+	return row, nil
+}
+
+// ReloadConfigExec represents a reload config executor.
+// It is built from the "admin reload config" statement, and it reloads the config file of the server.
+type ReloadConfigExec struct {
+	baseExecutor
+
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ReloadConfigExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return nil, errors.Trace(config.Reload())
+}
+
+// ReloadRewriteRulesExec represents a reload rewrite rules executor.
+// It is built from the "admin reload rewrite rules" statement, it reloads the query rewrite rules and
+// notifies the other servers to reload them.
+type ReloadRewriteRulesExec struct {
+	baseExecutor
+
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ReloadRewriteRulesExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	dom := sessionctx.GetDomain(e.ctx)
+	sysSessionPool := dom.SysSessionPool()
+	ctx, err := sysSessionPool.Get()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer sysSessionPool.Put(ctx)
+	err = dom.QueryRewriteHandle().Update(ctx.(context.Context))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dom.NotifyUpdateQueryRewriteRules()
+	return nil, nil
+}
+
+// SplitTableExec represents a split table executor.
+// It is built from the "admin split table" statement, it splits the regions of the table at the handles which
+// divide [lower, upper) into num ranges evenly, so the writes of a hot table can be distributed to the regions.
+type SplitTableExec struct {
+	baseExecutor
+
+	tableInfo *model.TableInfo
+	lower     int64
+	upper     int64
+	num       int64
+	done      bool
+}
+
+// Next implements the Executor Next interface.
+func (e *SplitTableExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	// The data of the store which isn't splitable isn't stored in regions.
+	store, ok := e.ctx.GetStore().(kv.SplitableStore)
+	if !ok {
+		return nil, ErrSplitNotSupported.GenByArgs("split")
+	}
+	step := (uint64(e.upper) - uint64(e.lower)) / uint64(e.num)
+	handles := make([]int64, 0, e.num+1)
+	for i := int64(0); i < e.num; i++ {
+		handles = append(handles, e.lower+int64(uint64(i)*step))
+	}
+	handles = append(handles, e.upper)
+	for _, h := range handles {
+		if err := store.SplitRegion(tablecodec.EncodeRowKeyWithHandle(e.tableInfo.ID, h)); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return nil, nil
+}
+
+// ScatterTableExec represents a scatter table executor.
+// It is built from the "admin scatter table" statement, it scatters the regions of the table to the stores.
+type ScatterTableExec struct {
+	baseExecutor
+
+	tableInfo *model.TableInfo
+	done      bool
+}
+
+// Next implements the Executor Next interface.
+func (e *ScatterTableExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	store, ok := e.ctx.GetStore().(kv.SplitableStore)
+	if !ok {
+		return nil, ErrSplitNotSupported.GenByArgs("scatter")
+	}
+	start, end := tablecodec.EncodeTablePrefix(e.tableInfo.ID), tablecodec.EncodeTablePrefix(e.tableInfo.ID+1)
+	return nil, errors.Trace(store.ScatterRange(start, end))
+}
+
+// PauseWritesExec represents a pause writes executor.
+// It is built from the "admin pause writes" and the "admin resume writes" statements. The writes to a table are paused
+// by a schema change on all the tidb-servers, and the writes to all the user tables are paused on this tidb-server.
+type PauseWritesExec struct {
+	baseExecutor
+
+	table  *ast.TableName
+	paused bool
+	done   bool
+}
+
+// Next implements the Executor Next interface.
+func (e *PauseWritesExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	if e.table == nil {
+		plan.PauseInstanceWrites(e.paused)
+		return nil, nil
+	}
+	ident := ast.Ident{Schema: e.table.DBInfo.Name, Name: e.table.Name}
+	dom := sessionctx.GetDomain(e.ctx)
+	if err := dom.DDL().PauseWrites(e.ctx, ident, e.paused); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The current transaction is committed by the schema change like DDL, update InfoSchema in TxnCtx for the next
+	// statements.
+	is := dom.InfoSchema()
+	txnCtx := e.ctx.GetSessionVars().TxnCtx
+	txnCtx.InfoSchema = is
+	txnCtx.SchemaVersion = is.SchemaMetaVersion()
+	e.ctx.GetSessionVars().SetStatusFlag(mysql.ServerStatusInTrans, false)
+	return nil, nil
+}
+
+// PinSnapshotExec represents a pin snapshot executor.
+// It is built from the "admin pin snapshot" statement. It pins a snapshot at the current timestamp, or renews the pin
+// of a snapshot, and returns the timestamp of the snapshot for the backup tools to read at. The GC leader doesn't
+// advance the safe point past the pinned snapshots until the pins expire.
+type PinSnapshotExec struct {
+	baseExecutor
+
+	ts   uint64
+	ttl  uint64
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *PinSnapshotExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	ver, err := e.ctx.GetStore().CurrentVersion()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	expirePhysical := oracle.ExtractPhysical(ver.Ver) + int64(e.ttl)*int64(time.Second/time.Millisecond)
+	expireTS := oracle.ComposeTS(expirePhysical, 0)
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	ts := e.ts
+	if ts == 0 {
+		ts = ver.Ver
+		sql := fmt.Sprintf("insert into %s.%s values (%d, %d)", mysql.SystemDB, mysql.GCSnapshotPinsTable, ts, expireTS)
+		if _, _, err = exec.ExecRestrictedSQL(e.ctx, sql); err != nil {
+			return nil, errors.Trace(err)
+		}
+	} else {
+		// The versions of an expired pin may be collected already, so it can't be renewed.
+		sql := fmt.Sprintf("select expire_ts from %s.%s where ts = %d", mysql.SystemDB, mysql.GCSnapshotPinsTable, ts)
+		rows, _, err := exec.ExecRestrictedSQL(e.ctx, sql)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(rows) == 0 || rows[0].Data[0].GetUint64() <= ver.Ver {
+			return nil, ErrSnapshotNotPinned.GenByArgs(ts)
+		}
+		sql = fmt.Sprintf("update %s.%s set expire_ts = %d where ts = %d", mysql.SystemDB, mysql.GCSnapshotPinsTable,
+			expireTS, ts)
+		if _, _, err = exec.ExecRestrictedSQL(e.ctx, sql); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	expireTime := time.Unix(0, expirePhysical*int64(time.Millisecond))
+	return types.MakeDatums(ts, expireTime.Format(types.TimeFormat)), nil
+}
+
+// UnpinSnapshotExec represents an unpin snapshot executor.
+// It is built from the "admin unpin snapshot" statement, it releases a snapshot pinned by "admin pin snapshot".
+type UnpinSnapshotExec struct {
+	baseExecutor
+
+	ts   uint64
+	done bool
+}
+
+// Next implements the Executor Next interface.
+func (e *UnpinSnapshotExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	sql := fmt.Sprintf("delete from %s.%s where ts = %d", mysql.SystemDB, mysql.GCSnapshotPinsTable, e.ts)
+	_, _, err := e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+	return nil, errors.Trace(err)
+}
+
 // CheckTableExec represents a check table executor.
 // It is built from the "admin check table" statement, and it checks if the
 // index matches the records in the table.
@@ -251,6 +536,8 @@ func (e *CheckTableExec) Close() error {
 // After the execution, the keys are buffered in transaction, and will be sent to KV
 // when doing commit. If there is any key already locked by another transaction,
 // the transaction will rollback and retry.
+// The keys are also locked in the rowlock table of the tidb-server, so "FOR UPDATE NOWAIT"
+// fails and "FOR UPDATE SKIP LOCKED" skips the rows locked by another transaction of the server.
 type SelectLockExec struct {
 	baseExecutor
 
@@ -259,33 +546,61 @@ type SelectLockExec struct {
 
 // Next implements the Executor Next interface.
 func (e *SelectLockExec) Next() (Row, error) {
-	row, err := e.children[0].Next()
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	if row == nil {
-		return nil, nil
-	}
-	// If there's no handle or it isn't a `select for update`.
-	if len(e.Schema().TblID2Handle) == 0 || e.Lock != ast.SelectLockForUpdate {
-		return row, nil
+	for {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			return nil, nil
+		}
+		// If there's no handle or it isn't a `select for update`.
+		if len(e.Schema().TblID2Handle) == 0 || !e.Lock.IsForUpdate() {
+			return row, nil
+		}
+		locked, err := e.lockRow(row)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if locked {
+			return row, nil
+		}
 	}
+}
+
+// lockRow locks the row keys of a row, it returns false if the row is skipped because
+// it is locked by another transaction.
+func (e *SelectLockExec) lockRow(row Row) (bool, error) {
 	txn := e.ctx.Txn()
-	txnCtx := e.ctx.GetSessionVars().TxnCtx
-	txnCtx.ForUpdate = true
+	var lockKeys []kv.Key
 	for id, cols := range e.Schema().TblID2Handle {
 		for _, col := range cols {
-			handle := row[col.Index].GetInt64()
-			lockKey := tablecodec.EncodeRowKeyWithHandle(id, handle)
-			err = txn.LockKeys(lockKey)
-			if err != nil {
-				return nil, errors.Trace(err)
-			}
-			// This operation is only for schema validator check.
-			txnCtx.UpdateDeltaForTable(id, 0, 0)
+			lockKeys = append(lockKeys, tablecodec.EncodeRowKeyWithHandle(id, row[col.Index].GetInt64()))
 		}
 	}
-	return row, nil
+	sessVars := e.ctx.GetSessionVars()
+	owner := rowlock.Owner{
+		StartTS:   txn.StartTS(),
+		SessionID: sessVars.ConnectionID,
+		SQLDigest: sessVars.StmtCtx.SQLDigest(),
+	}
+	if !rowlock.TryLock(e.ctx.GetStore(), txn, lockKeys, owner, e.Lock == ast.SelectLockForUpdate) {
+		if e.Lock == ast.SelectLockForUpdateSkipLocked {
+			return false, nil
+		}
+		return false, ErrLockNowait
+	}
+	txnCtx := sessVars.TxnCtx
+	txnCtx.ForUpdate = true
+	err := txn.LockKeys(lockKeys...)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for id := range e.Schema().TblID2Handle {
+		// This operation is only for schema validator check.
+		txnCtx.UpdateDeltaForTable(id, 0, 0)
+	}
+	return true, nil
 }
 
 // LimitExec represents limit executor
@@ -296,6 +611,9 @@ type LimitExec struct {
 	Offset uint64
 	Count  uint64
 	Idx    uint64
+	// CalcFoundRows is true if the rows skipped by the limit are counted in the found rows.
+	CalcFoundRows bool
+	drained       bool
 }
 
 // Next implements the Executor Next interface.
@@ -309,8 +627,13 @@ func (e *LimitExec) Next() (Row, error) {
 			return nil, nil
 		}
 		e.Idx++
+		e.addFoundRow()
 	}
 	if e.Idx >= e.Count+e.Offset {
+		if e.CalcFoundRows && !e.drained {
+			e.drained = true
+			return nil, errors.Trace(e.countRemainingRows())
+		}
 		return nil, nil
 	}
 	srcRow, err := e.children[0].Next()
@@ -324,9 +647,32 @@ func (e *LimitExec) Next() (Row, error) {
 	return srcRow, nil
 }
 
+// addFoundRow counts a row which is read but not returned for "SQL_CALC_FOUND_ROWS", the returned
+// rows are counted by the record set.
+func (e *LimitExec) addFoundRow() {
+	if e.CalcFoundRows {
+		e.ctx.GetSessionVars().StmtCtx.AddFoundRows(1)
+	}
+}
+
+// countRemainingRows reads the rows after the limit to count them in the found rows.
+func (e *LimitExec) countRemainingRows() error {
+	for {
+		srcRow, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if srcRow == nil {
+			return nil
+		}
+		e.addFoundRow()
+	}
+}
+
 // Open implements the Executor Open interface.
 func (e *LimitExec) Open() error {
 	e.Idx = 0
+	e.drained = false
 	return errors.Trace(e.children[0].Open())
 }
 
@@ -363,6 +709,8 @@ func init() {
 		CodeCannotUser:           mysql.ErrCannotUser,
 		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
 		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
+		codeLockNowait:           mysql.ErrLockNowait,
+		codeCantChangeTxChars:    mysql.ErrCantChangeTxCharacteristics,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -14,6 +14,8 @@
 package executor
 
 import (
+	"math/rand"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/tablecodec"
@@ -61,3 +63,82 @@ func (s *testExecSuite) TestMergeHandles(c *C) {
 		c.Assert(kr.EndKey, DeepEquals, ekr.EndKey)
 	}
 }
+
+// sampleSnapshot is a snapshot of the rows in a memory buffer, it counts the reads.
+type sampleSnapshot struct {
+	kv.MemBuffer
+	seeks      int
+	batchGets  int
+	batchSizes int
+}
+
+func (s *sampleSnapshot) Seek(k kv.Key) (kv.Iterator, error) {
+	s.seeks++
+	return s.MemBuffer.Seek(k)
+}
+
+func (s *sampleSnapshot) BatchGet(keys []kv.Key) (map[string][]byte, error) {
+	s.batchGets++
+	s.batchSizes += len(keys)
+	m := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		v, err := s.MemBuffer.Get(k)
+		if kv.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[string(k)] = v
+	}
+	return m, nil
+}
+
+func (s *testExecSuite) TestFastSampler(c *C) {
+	prefix := tablecodec.GenTableRecordPrefix(1)
+	snapshot := &sampleSnapshot{MemBuffer: kv.NewMemDbBuffer()}
+	// The handles 0-99999 and 200000-200009, the rows after the gap are no more likely to be sampled.
+	for i := int64(0); i < 100000; i++ {
+		c.Assert(snapshot.Set(tablecodec.EncodeRecordKey(prefix, i), []byte{1}), IsNil)
+	}
+	for i := int64(200000); i < 200010; i++ {
+		c.Assert(snapshot.Set(tablecodec.EncodeRecordKey(prefix, i), []byte{2}), IsNil)
+	}
+	sampler := &fastSampler{
+		snapshot:   snapshot,
+		prefix:     prefix,
+		numSamples: 2000,
+		rng:        rand.New(rand.NewSource(1)),
+	}
+	handles, values, count, err := sampler.sample()
+	c.Assert(err, IsNil)
+	// The random handles are distinct, they are read by batches.
+	c.Assert(snapshot.batchSizes, Equals, 2000)
+	c.Assert(snapshot.batchGets, Equals, 2)
+	for i := 1; i < len(handles); i++ {
+		c.Assert(handles[i] > handles[i-1], IsTrue)
+	}
+	afterGap := 0
+	for i, h := range handles {
+		if h >= 200000 {
+			afterGap++
+			c.Assert(values[i], DeepEquals, []byte{2})
+		} else {
+			c.Assert(values[i], DeepEquals, []byte{1})
+		}
+	}
+	c.Assert(len(handles) > 0, IsTrue)
+	c.Assert(afterGap < 10, IsTrue)
+	c.Assert(count, Equals, int64(float64(len(handles))/2000*200010))
+
+	// None of the random handles exists, the rows after them are sampled once.
+	snapshot = &sampleSnapshot{MemBuffer: kv.NewMemDbBuffer()}
+	for _, h := range []int64{0, 1 << 40, 1 << 50} {
+		c.Assert(snapshot.Set(tablecodec.EncodeRecordKey(prefix, h), []byte{1}), IsNil)
+	}
+	sampler.snapshot, sampler.numSamples, sampler.fallbackCount = snapshot, 10, 100
+	handles, _, count, err = sampler.sample()
+	c.Assert(err, IsNil)
+	c.Assert(len(handles) > 0 && len(handles) <= 3, IsTrue)
+	c.Assert(count, Equals, int64(100))
+}
@@ -14,9 +14,13 @@
 package executor_test
 
 import (
+	"archive/zip"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -27,10 +31,12 @@ import (
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/pd/pkg/logutil"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
@@ -42,7 +48,11 @@ import (
 	"github.com/pingcap/tidb/store/tikv"
 	mocktikv "github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/serverinfo"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -180,6 +190,210 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, IsNil)
 	r, err = tk.Exec("admin check table admin_test")
 	c.Assert(err, NotNil)
+
+	// reload config test, the test server is not started with a config file.
+	_, err = tk.Exec("admin reload config")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestAdminPauseWrites(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (a int primary key, b int)")
+	tk.MustExec("create table t2 (a int primary key, b int)")
+	tk.MustExec("insert t1 values (1, 1), (2, 2)")
+	tk.MustExec("insert t2 values (1, 1)")
+
+	tk.MustExec("admin pause writes on table t1")
+	for _, sql := range []string{
+		"insert t1 values (3, 3)",
+		"replace t1 values (1, 10)",
+		"update t1 set b = 10",
+		"delete from t1 where a = 1",
+		"delete t1, t2 from t1 join t2 on t1.a = t2.a",
+		"update t1, t2 set t1.b = t2.b where t1.a = t2.a",
+	} {
+		_, err := tk.Exec(sql)
+		c.Assert(terror.ErrorEqual(err, plan.ErrWritesPaused), IsTrue, Commentf("sql: %s, err: %v", sql, err))
+	}
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 1", "2 2"))
+	// The paused table is only read.
+	tk.MustExec("update t1, t2 set t2.b = t1.b + 10 where t1.a = t2.a")
+	tk.MustExec("insert t2 select a + 10, b from t1")
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 11", "11 1", "12 2"))
+	// The pause is in the schema, it applies to the other sessions.
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	_, err := tk1.Exec("insert t1 values (3, 3)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWritesPaused), IsTrue)
+	c.Assert(terror.ToSQLError(err).Code, Equals, uint16(mysql.ErrOptionPreventsStatement))
+	tk.MustExec("admin resume writes on table t1")
+	tk1.MustExec("insert t1 values (3, 3)")
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 1", "2 2", "3 3"))
+
+	// Pause the writes to all the user tables of the tidb-server.
+	tk.MustExec("admin pause writes")
+	defer plan.PauseInstanceWrites(false)
+	_, err = tk.Exec("insert t2 values (4, 4)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWritesPaused), IsTrue)
+	_, err = tk1.Exec("delete from t1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWritesPaused), IsTrue)
+	tk.MustExec("delete from mysql.tidb where variable_name = 'not_exist'")
+	tk.MustQuery("select count(*) from t1").Check(testkit.Rows("3"))
+	tk.MustExec("admin resume writes")
+	tk.MustExec("insert t2 values (4, 4)")
+
+	_, err = tk.Exec("admin pause writes on table not_exist")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestAdminPinSnapshot(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	rows := tk.MustQuery("admin pin snapshot ttl 3600").Rows()
+	c.Assert(rows, HasLen, 1)
+	ts := rows[0][0].(string)
+	tk.MustQuery("select count(*) from mysql.gc_snapshot_pins where ts = " + ts).Check(testkit.Rows("1"))
+
+	// Renew the pin.
+	rows = tk.MustQuery(fmt.Sprintf("admin pin snapshot %s ttl 10", ts)).Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][0], Equals, ts)
+
+	tk.MustExec("admin unpin snapshot " + ts)
+	tk.MustQuery("select count(*) from mysql.gc_snapshot_pins").Check(testkit.Rows("0"))
+	// The released snapshot can't be renewed.
+	rs, err := tk.Exec(fmt.Sprintf("admin pin snapshot %s ttl 10", ts))
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(terror.ErrorEqual(err, executor.ErrSnapshotNotPinned), IsTrue, Commentf("err: %v", err))
+}
+
+func (s *testSuite) TestPlanReplayer(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("create database replayer")
+	tk.MustExec("use replayer")
+	tk.MustExec("create table t (a int, b int, index idx(a))")
+	tk.MustExec("create table t1 (a int primary key, b int)")
+	tk.MustExec("insert t values (1, 1), (2, 2), (3, 3), (3, 4), (3, 5)")
+	tk.MustExec("insert t1 values (1, 1), (2, 2)")
+	tk.MustExec("analyze table t, t1")
+	tk.MustExec("set @@tidb_index_lookup_size = 100")
+	sql := "select * from t join t1 on t.b = t1.b where t.a = 3"
+	plan := tk.MustQuery("explain " + sql).Rows()
+
+	rows := tk.MustQuery("plan replayer dump " + sql).Rows()
+	c.Assert(rows, HasLen, 1)
+	file := rows[0][0].(string)
+	defer os.Remove(file)
+	r, err := zip.OpenReader(file)
+	c.Assert(err, IsNil)
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	r.Close()
+	c.Assert(names, DeepEquals, []string{"sql/sql.sql", "schema/replayer.t.sql", "stats/replayer.t.json",
+		"schema/replayer.t1.sql", "stats/replayer.t1.json", "meta.json", "explain.txt"})
+
+	// Load the file in a new session as if it's another server.
+	tk.MustExec("drop database replayer")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec(fmt.Sprintf("plan replayer load '%s'", file))
+	tk1.MustQuery("select database(), @@tidb_index_lookup_size").Check(testkit.Rows("replayer 100"))
+	tk1.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+	tk1.MustQuery("explain " + sql).Check(plan)
+	// The file is refused if a table exists, its statistics aren't overwritten.
+	tk1.MustExec("drop table t")
+	_, err = tk1.Exec(fmt.Sprintf("plan replayer load '%s'", file))
+	c.Assert(infoschema.ErrTableExists.Equal(err), IsTrue, Commentf("err %v", err))
+	tk1.MustQuery("show tables").Check(testkit.Rows("t1"))
+	tk1.MustExec("drop database replayer")
+
+	// The expired files are removed by the next dump.
+	expired := time.Now().Add(-25 * time.Hour)
+	c.Assert(os.Chtimes(file, expired, expired), IsNil)
+	tk.MustExec("create database replayer")
+	tk.MustExec("use replayer")
+	tk.MustExec("create table t (a int)")
+	rows = tk.MustQuery("plan replayer dump select * from t").Rows()
+	defer os.Remove(rows[0][0].(string))
+	_, err = os.Stat(file)
+	c.Assert(os.IsNotExist(err), IsTrue)
+	_, err = os.Stat(rows[0][0].(string))
+	c.Assert(err, IsNil)
+
+	_, err = tk1.Exec("plan replayer load '/non-existent/replayer.zip'")
+	c.Assert(err, NotNil)
+}
+
+func (s *testSuite) TestPipelinedPrewrite(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, index idx(b))")
+	tk.MustExec("insert t values (1, 1)")
+	tk.MustExec("set @@tidb_pipelined_prewrite = 1")
+
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (2, 2)")
+	tk.MustExec("insert t values (3, 3)")
+	// The transaction isn't blocked by its own locks.
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 2", "3 3"))
+	tk.MustQuery("select a from t use index(idx) where b >= 2").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select b from t where a = 2").Check(testkit.Rows("2"))
+	tk.MustExec("commit")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 1", "2 2", "3 3"))
+
+	// The commit fails as a prewritten row is written again, the explicit transaction isn't retried.
+	tk.MustExec("begin")
+	tk.MustExec("update t set b = 10 where a = 1")
+	tk.MustExec("update t set b = b + 1 where a = 1")
+	_, err := tk.Exec("commit")
+	c.Assert(err, NotNil)
+	tk1.MustQuery("select b from t where a = 1").Check(testkit.Rows("1"))
+
+	// The read committed transactions aren't prewritten ahead, as their statements read the newer snapshots.
+	tk.MustExec("set session transaction isolation level read committed")
+	tk.MustExec("begin")
+	tk.MustExec("update t set b = 10 where a = 1")
+	c.Assert(tk.Se.GetSessionVars().TxnCtx.PrewrittenAhead, IsFalse)
+	tk.MustExec("update t set b = b + 1 where a = 1")
+	tk.MustExec("commit")
+	tk1.MustQuery("select b from t where a = 1").Check(testkit.Rows("11"))
+	tk.MustExec("set session transaction isolation level repeatable read")
+
+	// The prewritten rows are rolled back.
+	tk.MustExec("begin")
+	tk.MustExec("insert t values (4, 4)")
+	tk.MustExec("rollback")
+	tk1.MustExec("insert t values (4, 40)")
+	tk.MustQuery("select * from t where a = 4").Check(testkit.Rows("4 40"))
+
+	// The rows locked by SELECT ... FOR UPDATE can be written later.
+	tk.MustExec("begin")
+	tk.MustQuery("select * from t where a = 2 for update").Check(testkit.Rows("2 2"))
+	tk.MustExec("update t set b = 20 where a = 2")
+	tk.MustExec("commit")
+	tk1.MustQuery("select b from t where a = 2").Check(testkit.Rows("20"))
 }
 
 func (s *testSuite) fillData(tk *testkit.TestKit, table string) {
@@ -585,6 +799,16 @@ func (s *testSuite) TestSelectLimit(c *C) {
 
 	_, err := tk.Exec("select * from select_limit limit 18446744073709551616 offset 3;")
 	c.Assert(err, NotNil)
+
+	// The rows skipped by the limit are counted with SQL_CALC_FOUND_ROWS.
+	r = tk.MustQuery("select sql_calc_found_rows * from select_limit order by id desc limit 1, 2;")
+	r.Check(testkit.Rows("3 hello", "2 hello"))
+	tk.MustQuery("select found_rows();").Check(testkit.Rows("4"))
+	r = tk.MustQuery("select sql_calc_found_rows id from select_limit where id > 1 limit 1;")
+	r.Check(testkit.Rows("2"))
+	tk.MustQuery("select found_rows();").Check(testkit.Rows("3"))
+	tk.MustQuery("select id from select_limit limit 1;")
+	tk.MustQuery("select found_rows();").Check(testkit.Rows("1"))
 }
 
 func (s *testSuite) TestSelectOrderBy(c *C) {
@@ -1579,6 +1803,49 @@ func (s *testSuite) TestTableScan(c *C) {
 	result.Check(testkit.Rows(rowStr1, rowStr2))
 }
 
+func (s *testSuite) TestMemDBWrite(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int, table_name varchar(64))")
+	for _, sql := range []string{
+		"create database information_schema",
+		"drop database performance_schema",
+		"create table information_schema.t (a int)",
+		"drop table information_schema.tables",
+		"alter table information_schema.tables add column c int",
+		"alter table t rename information_schema.t",
+		"create index idx on information_schema.tables (table_name)",
+		"truncate table performance_schema.setup_actors",
+		"rename table information_schema.tables to test.t1",
+		"insert into information_schema.tables (table_name) values ('a')",
+		"delete from performance_schema.events_statements_current",
+		"update information_schema.tables set table_name = 'a'",
+		"update t join information_schema.tables s on t.a = 1 set s.table_name = 'a'",
+		"delete from information_schema.tables",
+		"delete s from t join information_schema.tables s on t.a = 1",
+		"load data local infile '/tmp/nonexistence.csv' into table information_schema.tables",
+	} {
+		_, err := tk.Exec(sql)
+		c.Assert(plan.ErrDBaccessDenied.Equal(err), IsTrue, Commentf("%s: %v", sql, err))
+	}
+	tk.MustExec("use information_schema")
+	_, err := tk.Exec("create table t (a int)")
+	c.Assert(err.Error(), Equals, "[plan:1044]Access denied for user ''@'' to database 'information_schema'")
+
+	// The memory tables can be read by the statements which write the other tables.
+	tk.MustExec("use test")
+	tk.MustExec("insert into t select 1, table_name from information_schema.tables where table_schema = 'test'")
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 t"))
+	tk.MustExec("update t join information_schema.tables s on t.table_name = s.table_name set t.a = 2 where s.table_schema = 'test'")
+	tk.MustExec("delete t from t join information_schema.tables s on t.table_name = s.table_name where s.table_schema = 'test' and t.a = 3")
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 t"))
+	tk.MustExec("create table t1 like information_schema.schemata")
+	tk.MustExec("replace into performance_schema.setup_actors values ('%', 'u', '%', 'YES', 'YES')")
+	tk.MustExec("drop table t, t1")
+}
+
 func (s *testSuite) TestAdapterStatement(c *C) {
 	defer testleak.AfterTest(c)()
 	se, err := tidb.CreateSession(s.store)
@@ -1619,13 +1886,46 @@ func (s *testSuite) TestPointGet(c *C) {
 		err = plan.Preprocess(stmtNode, infoSchema, ctx)
 		c.Check(err, IsNil)
 		// Validate should be after NameResolve.
-		err = plan.Validate(stmtNode, false)
+		err = plan.Validate(stmtNode, false, ctx)
 		c.Check(err, IsNil)
 		p, err := plan.Optimize(ctx, stmtNode, infoSchema)
 		c.Check(err, IsNil)
 		ret := executor.IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx, p)
 		c.Assert(ret, Equals, result)
 	}
+
+	// The lookups of the UUIDs by BIN_TO_UUID on a unique binary column are point gets.
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t_uuid")
+	tk.MustExec("create table t_uuid(id binary(16) primary key, name varchar(10))")
+	tests = map[string]bool{
+		"select * from t_uuid where id = UUID_TO_BIN('6ccd780c-baba-1026-9564-5b8c656024db')":                     true,
+		"select * from t_uuid where BIN_TO_UUID(id) = '6ccd780c-baba-1026-9564-5b8c656024db'":                     true,
+		"select * from t_uuid where '6ccd780c-baba-1026-9564-5b8c656024db' = BIN_TO_UUID(id, 1)":                  true,
+		"select * from t_uuid where BIN_TO_UUID(id, true) <=> '6ccd780c-baba-1026-9564-5b8c656024db'":             true,
+		"select * from t_uuid where BIN_TO_UUID(id) = '6CCD780C-BABA-1026-9564-5B8C656024DB'":                     false,
+		"select * from t_uuid where BIN_TO_UUID(id) = '6ccd780cbaba102695645b8c656024db'":                         false,
+		"select * from t_uuid where BIN_TO_UUID(id, name) = '6ccd780c-baba-1026-9564-5b8c656024db'":               false,
+		"select * from t_uuid where BIN_TO_UUID(id) in ('6ccd780c-baba-1026-9564-5b8c656024db', 'abc')":           false,
+		"select * from t_uuid where BIN_TO_UUID(UUID_TO_BIN(name)) = '6ccd780c-baba-1026-9564-5b8c656024db'":      false,
+		"select * from t_uuid where BIN_TO_UUID(id) = '6ccd780c-baba-1026-9564-5b8c656024db' and name = 'a'":      true,
+		"select name from t_uuid where BIN_TO_UUID(id, 0) = '6ccd780c-baba-1026-9564-5b8c656024db'":               true,
+		"select * from t_uuid where BIN_TO_UUID(id) in ('6ccd780c-baba-1026-9564-5b8c656024db')":                  true,
+		"select * from t_uuid where BIN_TO_UUID(id) = '6ccd780c-baba-1026-9564-5b8c656024db' or name is not null": false,
+	}
+	infoSchema = executor.GetInfoSchema(ctx)
+	for sqlStr, result := range tests {
+		stmtNode, err := s.ParseOneStmt(sqlStr, "", "")
+		c.Check(err, IsNil)
+		err = plan.Preprocess(stmtNode, infoSchema, ctx)
+		c.Check(err, IsNil)
+		err = plan.Validate(stmtNode, false, ctx)
+		c.Check(err, IsNil)
+		p, err := plan.Optimize(ctx, stmtNode, infoSchema)
+		c.Check(err, IsNil)
+		ret := executor.IsPointGetWithPKOrUniqueKeyByAutoCommit(ctx, p)
+		c.Assert(ret, Equals, result, Commentf("%s", sqlStr))
+	}
 }
 
 func (s *testSuite) TestRow(c *C) {
@@ -1992,6 +2292,159 @@ func (s *testSuite) TestSelectForUpdate(c *C) {
 
 }
 
+func (s *testSuite) TestSelectForUpdateNowaitSkipLocked(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+
+	tk1.MustExec("drop table if exists t")
+	tk1.MustExec("create table t (id int primary key, c int)")
+	tk1.MustExec("insert t values (1, 1), (2, 2), (3, 3)")
+
+	tk1.MustExec("begin")
+	tk1.MustQuery("select * from t where id = 1 for update").Check(testkit.Rows("1 1"))
+
+	tk2.MustExec("begin")
+	rs, err := tk2.Exec("select * from t where id = 1 for update nowait")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(terror.ErrorEqual(err, executor.ErrLockNowait), IsTrue)
+	c.Assert(rs.Close(), IsNil)
+	tk2.MustQuery("select * from t where id = 2 for update nowait").Check(testkit.Rows("2 2"))
+	// The rows locked by the other transaction are skipped, the own locks are not.
+	tk2.MustQuery("select * from t order by id limit 1 for update skip locked").Check(testkit.Rows("2 2"))
+	tk2.MustQuery("select * from t for update skip locked").Check(testkit.Rows("2 2", "3 3"))
+	tk1.MustQuery("select * from t for update skip locked").Check(testkit.Rows("1 1"))
+	tk2.MustExec("commit")
+
+	// The locks are released when the transaction ends.
+	tk1.MustQuery("select * from t for update skip locked").Check(testkit.Rows("1 1", "2 2", "3 3"))
+	tk1.MustExec("rollback")
+	tk2.MustExec("begin")
+	tk2.MustQuery("select * from t where id = 1 for update nowait").Check(testkit.Rows("1 1"))
+	tk2.MustExec("commit")
+}
+
+func (s *testSuite) TestReadCommitted(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+
+	tk1.MustExec("drop table if exists t")
+	tk1.MustExec("create table t (id int primary key, c int, index c(c))")
+	tk1.MustExec("insert t values (1, 1)")
+
+	// Each statement of a read committed transaction reads the data committed before it.
+	tk1.MustExec("set session transaction isolation level read committed")
+	tk1.MustExec("begin")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 1"))
+	tk2.MustExec("insert t values (2, 2)")
+	tk2.MustExec("update t set c = 10 where id = 1")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 10", "2 2"))
+	tk1.MustQuery("select id from t where c = 2").Check(testkit.Rows("2"))
+	tk1.MustQuery("select c from t where id = 1").Check(testkit.Rows("10"))
+	tk1.MustExec("insert t values (3, 3)")
+	tk2.MustExec("delete from t where id = 2")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 10", "3 3"))
+	tk1.MustExec("commit")
+
+	// The default isolation level reads the snapshot of the start of the transaction.
+	tk1.MustExec("set session transaction isolation level repeatable read")
+	tk1.MustExec("begin")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 10", "3 3"))
+	tk2.MustExec("delete from t where id = 3")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 10", "3 3"))
+	tk1.MustExec("commit")
+	tk1.MustQuery("select * from t").Check(testkit.Rows("1 10"))
+}
+
+func (s *testSuite) TestDataLocks(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+
+	tk1.MustExec("drop table if exists t")
+	tk1.MustExec("create table t (id int primary key, c int)")
+	tk1.MustExec("insert t values (1, 1), (2, 2)")
+	tbl, err := sessionctx.GetDomain(tk1.Se).InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	key1 := hex.EncodeToString(tablecodec.EncodeRowKeyWithHandle(tbl.Meta().ID, 1))
+	key2 := hex.EncodeToString(tablecodec.EncodeRowKeyWithHandle(tbl.Meta().ID, 2))
+	tableID := strconv.FormatInt(tbl.Meta().ID, 10)
+
+	tk1.MustExec("begin")
+	tk1.MustQuery("select * from t where id = 1 for update")
+	startTS1 := strconv.FormatUint(tk1.Se.Txn().StartTS(), 10)
+	tk2.MustExec("begin")
+	tk2.MustQuery("SELECT * FROM t  FOR UPDATE")
+	startTS2 := strconv.FormatUint(tk2.Se.Txn().StartTS(), 10)
+
+	tk1.MustQuery("select `key`, table_id, trx_id, session_id from information_schema.data_locks").Check(testkit.Rows(
+		key1+" "+tableID+" "+startTS1+" "+strconv.FormatUint(tk1.Se.GetSessionVars().ConnectionID, 10),
+		key2+" "+tableID+" "+startTS2+" "+strconv.FormatUint(tk2.Se.GetSessionVars().ConnectionID, 10)))
+	tk1.MustQuery("select `key`, trx_id, current_holding_trx_id from information_schema.data_lock_waits").Check(testkit.Rows(
+		key1 + " " + startTS2 + " " + startTS1))
+	// The digest is of the normalized statement.
+	tk1.MustQuery("select count(*) from information_schema.data_lock_waits where sql_digest = sha2('select * from t for update', 256)").Check(testkit.Rows("1"))
+
+	// The waits end when the holding transaction ends.
+	tk1.MustExec("commit")
+	tk1.MustQuery("select count(*) from information_schema.data_lock_waits").Check(testkit.Rows("0"))
+	tk1.MustQuery("select `key` from information_schema.data_locks").Check(testkit.Rows(key2))
+	tk2.MustExec("rollback")
+	tk1.MustQuery("select count(*) from information_schema.data_locks").Check(testkit.Rows("0"))
+}
+
+func (s *testSuite) TestSlowQuery(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int)")
+
+	slowlog.Reset()
+	defer slowlog.Reset()
+	cfg := config.GetGlobalConfig()
+	oldThreshold := cfg.SlowThreshold
+	cfg.SlowThreshold = 0
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+	cfg.SlowThreshold = oldThreshold
+	tk.MustQuery("select connection_id, db, query_time > 0 from information_schema.slow_query where `query` = 'select count(*) from t' limit 1").Check(
+		testkit.Rows(fmt.Sprintf("%d test 1", tk.Se.GetSessionVars().ConnectionID)))
+
+	// The session isn't of a registered tidb-server, so the cluster tables are empty.
+	tk.MustQuery("select count(*) from information_schema.cluster_slow_query").Check(testkit.Rows("0"))
+	// The rows of this tidb-server are read locally, a tidb-server which can't be read is skipped with a warning.
+	r1, err := serverinfo.Register(s.store, &model.ServerInfo{ID: util.ServerIDOfConn(tk.Se.GetSessionVars().ConnectionID), Address: "local"})
+	c.Assert(err, IsNil)
+	defer r1.Close()
+	r2, err := serverinfo.Register(s.store, &model.ServerInfo{ID: util.MaxServerID, Address: "remote"})
+	c.Assert(err, IsNil)
+	defer r2.Close()
+	tk.MustQuery("select instance, db from information_schema.cluster_slow_query where `query` = 'select count(*) from t' limit 1").Check(
+		testkit.Rows("local test"))
+	c.Assert(tk.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	tk.MustQuery("select count(*) from information_schema.cluster_processlist where instance = 'remote'").Check(testkit.Rows("0"))
+}
+
 func (s *testSuite) TestEmptyEnum(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	defer func() {
@@ -2016,6 +2469,27 @@ func (s *testSuite) TestEmptyEnum(c *C) {
 	tk.MustQuery("select * from t").Check(testkit.Rows("", "", "<nil>"))
 }
 
+func (s *testSuite) TestSQLModeANSI(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("set sql_mode='ANSI'")
+	tk.MustExec(`create table t ("a" varchar(10), "b" real)`)
+	tk.MustExec(`insert into t values ('x', 1.5)`)
+	tk.MustQuery(`select "a" || 'y' || "b" from t`).Check(testkit.Rows("xy1.5"))
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `a` varchar(10) DEFAULT NULL,\n" +
+		"  `b` float DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+
+	tk.MustExec("set sql_mode=''")
+	tk.MustQuery(`select "a" || 0 from t`).Check(testkit.Rows("0"))
+}
+
 // This tests https://github.com/pingcap/tidb/issues/4024
 func (s *testSuite) TestIssue4024(c *C) {
 	defer func() {
@@ -2179,3 +2653,212 @@ func (s testPrioritySuite) TestCoprocessorPriority(c *C) {
 	cli.priority = pb.CommandPri_Low
 	tk.MustQuery("select LOW_PRIORITY id from t where id = 1")
 }
+
+func (s *testSuite) TestIndexCollation(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b char(10), c varchar(10) collate utf8_general_ci, unique index idx_b (b), index idx_c (c), index idx_cp (c(1)))")
+	tk.MustExec("insert into t values (1, 'a', 'x'), (2, 'b', 'X '), (3, 'c', 'y'), (4, 'd', 'Yz')")
+	_, err := tk.Exec("insert into t values (5, 'a ', 'z')")
+	c.Assert(err, NotNil)
+
+	for _, hint := range []string{"use index (idx_c)", "use index (idx_cp)", "ignore index (idx_c, idx_cp)"} {
+		tk.MustQuery("select a from t " + hint + " where c = 'X' order by a").Check(testkit.Rows("1", "2"))
+		tk.MustQuery("select a from t " + hint + " where c in ('x', 'YZ') order by a").Check(testkit.Rows("1", "2", "4"))
+		tk.MustQuery("select a from t " + hint + " where c > 'x' and c <= 'Y' order by a").Check(testkit.Rows("3"))
+		tk.MustQuery("select a from t " + hint + " where c like 'y%' order by a").Check(testkit.Rows("3", "4"))
+	}
+	tk.MustQuery("select a from t use index (idx_b) where b = 'b  '").Check(testkit.Rows("2"))
+	tk.MustQuery("select c from t use index (idx_c) where c >= 'y' order by c desc limit 2").Check(testkit.Rows("Yz", "y"))
+	tk.MustQuery("select c from t use index (idx_c) where c > 'x' order by c").Check(testkit.Rows("y", "Yz"))
+	tk.MustQuery("select count(*) from t group by c order by count(*)").Check(testkit.Rows("1", "1", "2"))
+	tk.MustQuery("select a from t order by c, a").Check(testkit.Rows("1", "2", "3", "4"))
+	tk.MustQuery("select a from t order by c desc, a limit 2").Check(testkit.Rows("4", "3"))
+}
+
+func (s *testSuite) TestPrefixCoveringIndex(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b varchar(4), c varbinary(3), d varchar(3) charset ascii, index idx_b (b(4)), index idx_c (c(3)), index idx_cd (c(2), d(3)))")
+	tk.MustExec("insert into t values (1, '中文字符', 'abc', 'x'), (2, 'ab', 'ab', 'yy'), (3, 'b', null, 'zzz')")
+
+	planOf := func(sql string) string {
+		rows := tk.MustQuery("explain " + sql).Rows()
+		for _, row := range rows {
+			id := row[0].(string)
+			if strings.HasPrefix(id, "IndexReader") || strings.HasPrefix(id, "IndexLookUp") {
+				return id[:strings.Index(id, "_")]
+			}
+		}
+		return ""
+	}
+	// The prefixes of idx_c and d in idx_cd keep the whole values, so the table rows aren't read.
+	sql := "select a, c from t use index (idx_c) where c > 'a'"
+	c.Assert(planOf(sql), Equals, "IndexReader")
+	tk.MustQuery(sql + " order by a").Check(testkit.Rows("1 abc", "2 ab"))
+	sql = "select a, d from t use index (idx_cd) where d >= 'y'"
+	c.Assert(planOf(sql), Equals, "IndexReader")
+	tk.MustQuery(sql + " order by d").Check(testkit.Rows("2 yy", "3 zzz"))
+	// The prefixes of idx_b and c in idx_cd are shorter than the values.
+	sql = "select b from t use index (idx_b) where b > 'a'"
+	c.Assert(planOf(sql), Equals, "IndexLookUp")
+	tk.MustQuery(sql + " order by a").Check(testkit.Rows("中文字符", "ab", "b"))
+	sql = "select c, d from t use index (idx_cd) where c = 'ab'"
+	c.Assert(planOf(sql), Equals, "IndexLookUp")
+	tk.MustQuery(sql).Check(testkit.Rows("ab yy"))
+}
+
+func (s *testSuite) TestDescIndex(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, b int, c int, index idx_b (b desc), index idx_bc (b, c desc))")
+	tk.MustExec("insert into t values (1, 1, 3), (2, 1, 1), (3, 2, null), (4, null, 2), (5, 3, 2), (6, 2, 5)")
+	tk.MustQuery("show index from t where Key_name = 'idx_bc'").Check(testkit.Rows(
+		"t 1 idx_bc 1 b A 0 <nil> <nil> YES BTREE  ",
+		"t 1 idx_bc 2 c D 0 <nil> <nil> YES BTREE  "))
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `a` int(11) NOT NULL,\n  `b` int(11) DEFAULT NULL,\n  `c` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`a`),\n  KEY `idx_b` (`b` DESC),\n  KEY `idx_bc` (`b`,`c` DESC)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+
+	hasSort := func(sql string) bool {
+		rows := tk.MustQuery("explain " + sql).Rows()
+		for _, row := range rows {
+			if strings.HasPrefix(row[0].(string), "Sort") {
+				return true
+			}
+		}
+		return false
+	}
+	// The orders of the index columns are served by the forward or the reverse index scans,
+	// the reverse scans are chosen with the limits.
+	tests := []struct {
+		sql  string
+		rows []string
+	}{
+		{"select b from t use index (idx_b) order by b desc", []string{"3", "2", "2", "1", "1", "<nil>"}},
+		{"select b from t use index (idx_b) order by b limit 10", []string{"<nil>", "1", "1", "2", "2", "3"}},
+		{"select b from t use index (idx_b) where b > 1 order by b desc", []string{"3", "2", "2"}},
+		{"select b from t use index (idx_b) where b >= 1 and b < 3 order by b limit 10", []string{"1", "1", "2", "2"}},
+		{"select b from t use index (idx_b) where b is not null order by b desc limit 2", []string{"3", "2"}},
+		{"select a, b from t use index (idx_b) where b in (1, 3) order by b desc", []string{"5 3", "1 1", "2 1"}},
+		{"select a from t use index (idx_b) where b is null", []string{"4"}},
+		{"select b, c from t use index (idx_bc) where b = 2 order by c desc", []string{"2 5", "2 <nil>"}},
+		{"select b, c from t use index (idx_bc) where b = 1 and c > 1 order by c limit 10", []string{"1 3"}},
+		{"select b, c from t use index (idx_bc) where b = 1 and c between 1 and 3 order by c desc", []string{"1 3", "1 1"}},
+		{"select b, c from t use index (idx_bc) where b >= 2 order by b", []string{"2 5", "2 <nil>", "3 2"}},
+		{"select b, c from t use index (idx_bc) where b > 0 order by b, c desc", []string{"1 3", "1 1", "2 5", "2 <nil>", "3 2"}},
+		{"select b, c from t use index (idx_bc) where b > 0 order by b desc, c limit 10", []string{"3 2", "2 <nil>", "2 5", "1 1", "1 3"}},
+	}
+	for _, t := range tests {
+		c.Assert(hasSort(t.sql), IsFalse, Commentf("%s", t.sql))
+		tk.MustQuery(t.sql).Check(testkit.Rows(t.rows...))
+	}
+	// The mixed orders of idx_bc are sorted.
+	sql := "select b, c from t use index (idx_bc) where b > 0 order by b, c"
+	c.Assert(hasSort(sql), IsTrue)
+	tk.MustQuery(sql).Check(testkit.Rows("1 1", "1 3", "2 <nil>", "2 5", "3 2"))
+	// The double reads keep the order of the index.
+	tk.MustQuery("select a, c from t use index (idx_b) where b < 3 order by b desc, a").Check(testkit.Rows("3 <nil>", "6 5", "1 3", "2 1"))
+
+	// The uncommitted rows are merged in the order of the index.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (7, 2, 4), (8, 4, 0)")
+	tk.MustExec("delete from t where a = 5")
+	tk.MustQuery("select b from t use index (idx_b) where b > 1 order by b desc").Check(testkit.Rows("4", "2", "2", "2"))
+	tk.MustQuery("select b, c from t use index (idx_bc) where b = 2 order by c desc").Check(testkit.Rows("2 5", "2 4", "2 <nil>"))
+	tk.MustExec("commit")
+	tk.MustExec("admin check table t")
+
+	tk.MustExec("update t set c = 6 where c is null")
+	tk.MustExec("create unique index idx_c on t (c desc)")
+	_, err := tk.Exec("insert into t values (9, 9, 5)")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select c from t use index (idx_c) where c >= 3 order by c desc").Check(testkit.Rows("6", "5", "4", "3"))
+	tk.MustExec("admin check table t")
+	tk.MustExec("analyze table t")
+	tk.MustQuery("select count(*) from t use index (idx_b) where b > 1").Check(testkit.Rows("4"))
+}
+
+func (s *testSuite) TestIndexRangeDNF(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b int, c varchar(10), d int, index idx_abc (a, b, c(2)), index idx_bd (b, d desc))")
+	tk.MustExec(`insert into t values (1, 1, 1, 'a', 1), (2, 1, 2, 'abc', 2), (3, 1, 3, null, 3), (4, 2, 1, 'b', 4),
+		(5, 2, null, 'bcd', 5), (6, 3, 2, 'c', null), (7, null, 1, 'd', 7), (8, 3, 3, 'cde', 8)`)
+
+	rangeOf := func(sql string) string {
+		rows := tk.MustQuery("explain " + sql).Rows()
+		for _, row := range rows {
+			if strings.HasPrefix(row[0].(string), "IndexScan") {
+				info := row[4].(string)
+				return info[strings.Index(info, "range:"):strings.Index(info, ", out of order")]
+			}
+		}
+		return ""
+	}
+	tests := []struct {
+		where  string
+		idx    string
+		ranges string
+	}{
+		{"(a = 1 and b > 1) or (a = 3 and b = 2)", "idx_abc", "range:(1 1,1 +inf], [3 2,3 2]"},
+		{"(a, b) in ((1, 2), (3, 3), (2, 1))", "idx_abc", "range:[1 2,1 2], [2 1,2 1], [3 3,3 3]"},
+		{"a = 1 and (b = 3 or (b = 2 and c = 'abc'))", "idx_abc", "range:[1 2 [97 98],1 2 [97 98]], [1 3,1 3]"},
+		{"(a = 1 and b >= 2) or (a = 1 and b < 3) or a <=> null", "idx_abc", "range:[<nil>,<nil>], [1 -inf,1 +inf]"},
+		{"(a = 2 and b <=> null) or (a = 3 and c like 'c%')", "idx_abc", "range:[2 <nil>,2 <nil>], [3,3]"},
+		{"a <=> null", "idx_abc", "range:[<nil>,<nil>]"},
+		{"(b = 1 and d > 1) or (b = 2 and d <= 2) or (b = 3 and d = 8)", "idx_bd", "range:(1 1,1 +inf], [2 -inf,2 2], [3 8,3 8]"},
+	}
+	for _, t := range tests {
+		sql := fmt.Sprintf("select id from t use index (%s) where %s", t.idx, t.where)
+		c.Assert(rangeOf(sql), Equals, t.ranges, Commentf("%s", sql))
+		expected := tk.MustQuery(fmt.Sprintf("select id from t ignore index (%s) where %s order by id", t.idx, t.where)).Rows()
+		tk.MustQuery(sql + " order by id").Check(expected)
+	}
+}
+
+func (s *testSuite) TestTimeFuncPushDown(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int primary key, d datetime)")
+	tk.MustExec("insert into t values (1, '1987-01-01'), (2, '2008-02-20 10:00:00'), (3, null), (4, '2008-12-31 23:59:59')")
+
+	// The time functions are evaluated by the coprocessor.
+	rows := tk.MustQuery("explain select a from t where yearweek(d) = 198652").Rows()
+	pushed := false
+	for _, row := range rows {
+		if strings.HasPrefix(row[0].(string), "Selection") && row[3] == "cop" {
+			pushed = true
+		}
+	}
+	c.Assert(pushed, IsTrue)
+	tk.MustQuery("select a from t where yearweek(d) = 198652 or weekofyear(d) = 8 or dayofmonth(d) = 31 order by a").Check(testkit.Rows("1", "2", "4"))
+	tk.MustQuery("select a from t where hour(d) = 10 and month(d) = 2").Check(testkit.Rows("2"))
+	tk.MustQuery("select a from t where yearweek(d, 3) = 200901").Check(testkit.Rows("4"))
+}
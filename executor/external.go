@@ -0,0 +1,60 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/failpoint"
+)
+
+// ExternalScanExec reads the rows of a table stored by an external engine.
+type ExternalScanExec struct {
+	baseExecutor
+
+	source engine.Source
+	req    *engine.ScanRequest
+	iter   engine.RowIter
+}
+
+// Open implements the Executor Open interface.
+func (e *ExternalScanExec) Open() error {
+	return errors.Trace(e.Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *ExternalScanExec) Next() (Row, error) {
+	if _, ok := failpoint.Eval("executor/externalScanTimeout"); ok {
+		return nil, errors.Errorf("injected timeout scanning the external table")
+	}
+	if e.iter == nil {
+		iter, err := e.source.Scan(e.ctx, e.req)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		e.iter = iter
+	}
+	row, err := e.iter.Next()
+	return row, errors.Trace(err)
+}
+
+// Close implements the Executor Close interface.
+func (e *ExternalScanExec) Close() error {
+	if e.iter == nil {
+		return nil
+	}
+	err := e.iter.Close()
+	e.iter = nil
+	return errors.Trace(err)
+}
@@ -0,0 +1,44 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build failpoint
+
+package executor_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/util/failpoint"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestFailExternalScan(c *C) {
+	defer func() {
+		failpoint.Disable("executor/externalScanTimeout")
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists ext")
+	tk.MustExec("create table ext (a int) engine=MockExternal connection='fail'")
+	tk.MustExec("insert into ext values (1), (2)")
+
+	failpoint.EnableN("executor/externalScanTimeout", true, 1)
+	rs, err := tk.Exec("select * from ext")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(err, NotNil)
+	tk.MustQuery("select * from ext").Check(testkit.Rows("1", "2"))
+}
@@ -0,0 +1,272 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// mockEngine keeps the rows of its tables in memory, keyed by the "connection" option.
+type mockEngine struct {
+	mu   sync.Mutex
+	rows map[string][][]types.Datum
+}
+
+var testMockEngine = &mockEngine{rows: make(map[string][][]types.Datum)}
+
+func init() {
+	engine.Register(testMockEngine)
+}
+
+func (e *mockEngine) Name() string { return "MockExternal" }
+
+func (e *mockEngine) Open(tblInfo *model.TableInfo) (engine.Source, error) {
+	for name := range tblInfo.EngineOptions {
+		if name != "connection" {
+			return nil, engine.ErrUnknownOption.GenByArgs(name, e.Name())
+		}
+	}
+	key := tblInfo.EngineOptions["connection"]
+	if key == "" {
+		return nil, engine.ErrInvalidOption.GenByArgs("", "connection", e.Name())
+	}
+	return &mockSource{engine: e, key: key, tblInfo: tblInfo}, nil
+}
+
+type mockSource struct {
+	engine  *mockEngine
+	key     string
+	tblInfo *model.TableInfo
+}
+
+func (s *mockSource) Capabilities() engine.Capability {
+	return engine.CapInsert | engine.CapPushDownFilter | engine.CapPushDownLimit | engine.CapPushDownAgg
+}
+
+// PushDown accepts equal conditions between a column and a constant.
+func (s *mockSource) PushDown(conds []expression.Expression) (pushed, remained []expression.Expression) {
+	for _, cond := range conds {
+		if f, ok := cond.(*expression.ScalarFunction); ok && f.FuncName.L == ast.EQ {
+			_, isCol := f.GetArgs()[0].(*expression.Column)
+			_, isConst := f.GetArgs()[1].(*expression.Constant)
+			if isCol && isConst {
+				pushed = append(pushed, cond)
+				continue
+			}
+		}
+		remained = append(remained, cond)
+	}
+	return
+}
+
+// CanPushDownAgg accepts COUNT and FIRSTROW grouped by columns.
+func (s *mockSource) CanPushDownAgg(aggFuncs []expression.AggregationFunction, groupBy []expression.Expression) bool {
+	for _, aggFunc := range aggFuncs {
+		if aggFunc.GetName() != ast.AggFuncCount && aggFunc.GetName() != ast.AggFuncFirstRow {
+			return false
+		}
+	}
+	for _, item := range groupBy {
+		if _, ok := item.(*expression.Column); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *mockSource) Scan(ctx context.Context, req *engine.ScanRequest) (engine.RowIter, error) {
+	s.engine.mu.Lock()
+	defer s.engine.mu.Unlock()
+	var rows [][]types.Datum
+	for _, stored := range s.engine.rows[s.key] {
+		row := make([]types.Datum, 0, len(req.Columns))
+		for _, col := range req.Columns {
+			row = append(row, stored[col.Offset])
+		}
+		match, err := expression.EvalBool(req.Conditions, row, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		rows = append(rows, row)
+		if req.Limit > 0 && uint64(len(rows)) >= req.Limit {
+			break
+		}
+	}
+	if len(req.AggFuncs) > 0 {
+		return aggregateMockRows(rows, req), nil
+	}
+	return &mockRowIter{rows: rows}, nil
+}
+
+// aggregateMockRows returns one partial result for each group.
+func aggregateMockRows(rows [][]types.Datum, req *engine.ScanRequest) engine.RowIter {
+	var keys []string
+	groups := make(map[string][]types.Datum)
+	for _, row := range rows {
+		var gbyVals []types.Datum
+		for _, item := range req.GroupBy {
+			gbyVals = append(gbyVals, row[item.(*expression.Column).Index])
+		}
+		key := fmt.Sprint(gbyVals)
+		result, ok := groups[key]
+		if !ok {
+			result = make([]types.Datum, len(req.AggFuncs), len(req.AggFuncs)+len(gbyVals))
+			for i, aggFunc := range req.AggFuncs {
+				if aggFunc.GetName() == ast.AggFuncCount {
+					result[i].SetInt64(0)
+				} else {
+					result[i] = row[aggFunc.GetArgs()[0].(*expression.Column).Index]
+				}
+			}
+			groups[key] = append(result, gbyVals...)
+			keys = append(keys, key)
+			result = groups[key]
+		}
+		for i, aggFunc := range req.AggFuncs {
+			if aggFunc.GetName() != ast.AggFuncCount {
+				continue
+			}
+			if col, ok := aggFunc.GetArgs()[0].(*expression.Column); !ok || !row[col.Index].IsNull() {
+				result[i].SetInt64(result[i].GetInt64() + 1)
+			}
+		}
+	}
+	it := &mockRowIter{}
+	for _, key := range keys {
+		it.rows = append(it.rows, groups[key])
+	}
+	return it
+}
+
+func (s *mockSource) Insert(ctx context.Context, row []types.Datum) error {
+	s.engine.mu.Lock()
+	defer s.engine.mu.Unlock()
+	s.engine.rows[s.key] = append(s.engine.rows[s.key], row)
+	return nil
+}
+
+func (s *mockSource) Describe(req *engine.ScanRequest) string {
+	desc := fmt.Sprintf("scan %s filter:%s limit:%d", s.key, expression.ExplainExpressionList(req.Conditions), req.Limit)
+	if len(req.AggFuncs) > 0 {
+		desc += " funcs:"
+		for _, aggFunc := range req.AggFuncs {
+			desc += expression.ExplainAggFunc(aggFunc) + " "
+		}
+		desc += fmt.Sprintf("group by:%s", expression.ExplainExpressionList(req.GroupBy))
+	}
+	return desc
+}
+
+type mockRowIter struct {
+	rows [][]types.Datum
+}
+
+func (it *mockRowIter) Next() ([]types.Datum, error) {
+	if len(it.rows) == 0 {
+		return nil, nil
+	}
+	row := it.rows[0]
+	it.rows = it.rows[1:]
+	return row, nil
+}
+
+func (it *mockRowIter) Close() error { return nil }
+
+func (s *testSuite) TestExternalEngine(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists ext")
+
+	_, err := tk.Exec("create table ext (a int, b varchar(10)) engine=MockExternal")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table ext (a int, b varchar(10)) engine=MockExternal connection=''")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("create table ext (a int, b varchar(10)) engine=MockExternal connection='ext' location='/tmp'")
+	c.Assert(engine.ErrUnknownOption.Equal(err), IsTrue)
+
+	tk.MustExec("create table ext (a int, b varchar(10)) engine=MockExternal connection='ext'")
+	tk.MustQuery("show create table ext").Check(testkit.Rows(
+		"ext CREATE TABLE `ext` (\n" +
+			"  `a` int(11) DEFAULT NULL,\n" +
+			"  `b` varchar(10) DEFAULT NULL\n" +
+			") ENGINE=MockExternal DEFAULT CHARSET=utf8 COLLATE=utf8_bin CONNECTION='ext'"))
+	tk.MustQuery("select engine from information_schema.tables where table_name = 'ext'").Check(testkit.Rows("MockExternal"))
+
+	tk.MustExec("insert into ext values (1, 'a'), (2, 'b'), (3, 'c'), (2, 'd')")
+	tk.MustQuery("select * from ext").Check(testkit.Rows("1 a", "2 b", "3 c", "2 d"))
+	tk.MustQuery("select b from ext where a = 2").Check(testkit.Rows("b", "d"))
+	tk.MustQuery("select b from ext where a = 2 and b > 'b'").Check(testkit.Rows("d"))
+	tk.MustQuery("select a from ext order by a desc limit 2").Check(testkit.Rows("3", "2"))
+	tk.MustQuery("select a from ext limit 1, 1").Check(testkit.Rows("2"))
+	tk.MustQuery("select count(*) from ext where a = 2").Check(testkit.Rows("2"))
+	tk.MustQuery("select a, count(*) from ext group by a order by a").Check(testkit.Rows("1 1", "2 2", "3 1"))
+	tk.MustQuery("select count(b), count(*) from ext where a = 1").Check(testkit.Rows("1 1"))
+	tk.MustQuery("select count(distinct a) from ext").Check(testkit.Rows("3"))
+	tk.MustQuery("select sum(a) from ext group by b > 'b' order by 1").Check(testkit.Rows("3", "5"))
+
+	// Rows written to the engine are visible in the same transaction.
+	tk.MustExec("begin")
+	tk.MustExec("insert into ext values (4, 'e')")
+	tk.MustQuery("select b from ext where a = 4").Check(testkit.Rows("e"))
+	tk.MustExec("commit")
+
+	_, err = tk.Exec("update ext set a = 1")
+	c.Assert(engine.ErrUnsupportedOp.Equal(err), IsTrue)
+	_, err = tk.Exec("delete from ext")
+	c.Assert(engine.ErrUnsupportedOp.Equal(err), IsTrue)
+}
+
+func (s *testSuite) TestExplainExternalEngine(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists ext")
+	tk.MustExec("create table ext (a int, b varchar(10)) engine=MockExternal connection='explain'")
+
+	tk.MustQuery("explain select * from ext where a = 1 and b > 'a' limit 3").Check(testkit.Rows(
+		"ExternalScan_7 Selection_8  root table:ext, engine:MockExternal, request:scan explain filter:eq(test.ext.a, 1) limit:0 10",
+		"Selection_8 Limit_6 ExternalScan_7 root gt(test.ext.b, a) 10",
+		"Limit_6  Selection_8 root offset:0, count:3 3",
+	))
+	tk.MustQuery("explain select * from ext where a = 1 limit 3").Check(testkit.Rows(
+		"ExternalScan_7 Limit_6  root table:ext, engine:MockExternal, request:scan explain filter:eq(test.ext.a, 1) limit:3 10",
+		"Limit_6  ExternalScan_7 root offset:0, count:3 3",
+	))
+	tk.MustQuery("explain select b, count(*) from ext where a = 1 group by b").Check(testkit.Rows(
+		"ExternalScan_6 HashAgg_7  root table:ext, engine:MockExternal, request:scan explain filter:eq(test.ext.a, 1) limit:0 funcs:count(1) firstrow(test.ext.b) group by:test.ext.b 10",
+		"HashAgg_7 Projection_4 ExternalScan_6 root type:final, group by:, funcs:count(col_0), firstrow(col_1) 8",
+		"Projection_4  HashAgg_7 root test.ext.b, 3_col_0 8",
+	))
+}
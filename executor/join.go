@@ -613,7 +613,8 @@ func (e *NestedLoopJoinExec) Next() (Row, error) {
 
 // HashSemiJoinExec implements the hash join algorithm for semi join.
 type HashSemiJoinExec struct {
-	hashTable    map[string][]Row
+	// hashTable maps the join keys to the rows of the small table.
+	hashTable    map[string]*semiJoinGroup
 	smallHashKey []*expression.Column
 	bigHashKey   []*expression.Column
 	smallExec    Executor
@@ -625,14 +626,27 @@ type HashSemiJoinExec struct {
 	otherFilter  expression.CNFExprs
 	schema       *expression.Schema
 	resultRows   []Row
+	// nullAwareFilter is the conditions of the IN subquery which are evaluated in the three-valued logic.
+	// The columns compared by nullAwareFilter are smallNullAwareKey and bigNullAwareKey, the rows in a group
+	// are hashed by them again, the rows with NULL keys are kept apart.
+	nullAwareFilter   expression.CNFExprs
+	smallNullAwareKey []*expression.Column
+	bigNullAwareKey   []*expression.Column
 	// auxMode is a mode that the result row always returns with an extra column which stores a boolean
 	// or NULL value to indicate if this row is matched.
-	auxMode           bool
-	smallTableHasNull bool
+	auxMode bool
 	// anti is true, semi join only output the unmatched row.
 	anti bool
 }
 
+// semiJoinGroup is the rows of the small table which have the same join key.
+type semiJoinGroup struct {
+	// rows maps the null-aware keys to the rows.
+	rows map[string][]Row
+	// nullRows are the rows which have NULL in the null-aware keys.
+	nullRows []Row
+}
+
 // Close implements the Executor Close interface.
 func (e *HashSemiJoinExec) Close() error {
 	e.hashTable = nil
@@ -643,8 +657,7 @@ func (e *HashSemiJoinExec) Close() error {
 // Open implements the Executor Open interface.
 func (e *HashSemiJoinExec) Open() error {
 	e.prepared = false
-	e.smallTableHasNull = false
-	e.hashTable = make(map[string][]Row)
+	e.hashTable = make(map[string]*semiJoinGroup)
 	e.resultRows = make([]Row, 1)
 	return errors.Trace(e.bigExec.Open())
 }
@@ -662,7 +675,7 @@ func (e *HashSemiJoinExec) prepare() error {
 		return errors.Trace(err)
 	}
 	defer e.smallExec.Close()
-	e.hashTable = make(map[string][]Row)
+	e.hashTable = make(map[string]*semiJoinGroup)
 	e.resultRows = make([]Row, 1)
 	e.prepared = true
 	for {
@@ -685,42 +698,104 @@ func (e *HashSemiJoinExec) prepare() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		// The row whose join key is NULL can't match any row.
 		if hasNull {
-			e.smallTableHasNull = true
 			continue
 		}
-		if rows, ok := e.hashTable[string(hashcode)]; !ok {
-			e.hashTable[string(hashcode)] = []Row{row}
+		group, ok := e.hashTable[string(hashcode)]
+		if !ok {
+			group = &semiJoinGroup{rows: make(map[string][]Row)}
+			e.hashTable[string(hashcode)] = group
+		}
+		hasNull, hashcode, err = getJoinKey(e.smallNullAwareKey, row, make([]types.Datum, len(e.smallNullAwareKey)), nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if hasNull {
+			group.nullRows = append(group.nullRows, row)
 		} else {
-			e.hashTable[string(hashcode)] = append(rows, row)
+			group.rows[string(hashcode)] = append(group.rows[string(hashcode)], row)
 		}
 	}
 }
 
-func (e *HashSemiJoinExec) rowIsMatched(bigRow Row) (matched bool, hasNull bool, err error) {
-	hasNull, hashcode, err := getJoinKey(e.bigHashKey, bigRow, make([]types.Datum, len(e.smallHashKey)), nil)
+// rowIsMatched checks if the big row matches any row of the small table. isNull is true if it doesn't match
+// any row but the null-aware conditions are NULL for some rows.
+func (e *HashSemiJoinExec) rowIsMatched(bigRow Row) (matched bool, isNull bool, err error) {
+	hasNull, hashcode, err := getJoinKey(e.bigHashKey, bigRow, make([]types.Datum, len(e.bigHashKey)), nil)
+	if err != nil || hasNull {
+		return false, false, errors.Trace(err)
+	}
+	group, ok := e.hashTable[string(hashcode)]
+	if !ok {
+		return false, false, nil
+	}
+	hasNull, hashcode, err = getJoinKey(e.bigNullAwareKey, bigRow, make([]types.Datum, len(e.bigNullAwareKey)), nil)
 	if err != nil {
 		return false, false, errors.Trace(err)
 	}
 	if hasNull {
-		return false, true, nil
-	}
-	rows, ok := e.hashTable[string(hashcode)]
-	if !ok {
-		return
+		// The null-aware keys are compared as NULL with all the rows, only the other conditions can
+		// make the result FALSE.
+		for _, rows := range group.rows {
+			matched, isNull, err = e.matchRows(bigRow, rows, isNull)
+			if matched || err != nil {
+				return matched, false, errors.Trace(err)
+			}
+		}
+	} else {
+		matched, isNull, err = e.matchRows(bigRow, group.rows[string(hashcode)], false)
+		if matched || err != nil {
+			return matched, false, errors.Trace(err)
+		}
 	}
-	// match eq condition
-	for _, smallRow := range rows {
+	return e.matchRows(bigRow, group.nullRows, isNull)
+}
+
+// matchRows matches the big row with the small rows, isNull is the result of the rows matched before.
+func (e *HashSemiJoinExec) matchRows(bigRow Row, smallRows []Row, isNull bool) (bool, bool, error) {
+	for _, smallRow := range smallRows {
 		matchedRow := makeJoinRow(bigRow, smallRow)
-		matched, err = expression.EvalBool(e.otherFilter, matchedRow, e.ctx)
+		matched, err := expression.EvalBool(e.otherFilter, matchedRow, e.ctx)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if !matched {
+			continue
+		}
+		matched, null, err := evalNullAware(e.nullAwareFilter, matchedRow, e.ctx)
 		if err != nil {
 			return false, false, errors.Trace(err)
 		}
 		if matched {
-			return
+			return true, false, nil
 		}
+		isNull = isNull || null
 	}
-	return
+	return false, isNull, nil
+}
+
+// evalNullAware evaluates the CNF conditions in the three-valued logic, the result is NULL if none of them is
+// FALSE and some of them are NULL.
+func evalNullAware(exprList expression.CNFExprs, row Row, ctx context.Context) (matched bool, isNull bool, err error) {
+	for _, expr := range exprList {
+		data, err := expr.Eval(row)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if data.IsNull() {
+			isNull = true
+			continue
+		}
+		i, err := data.ToBool(ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return false, false, errors.Trace(err)
+		}
+		if i == 0 {
+			return false, false, nil
+		}
+	}
+	return !isNull, isNull, nil
 }
 
 func (e *HashSemiJoinExec) fetchBigRow() (Row, bool, error) {
@@ -737,26 +812,26 @@ func (e *HashSemiJoinExec) fetchBigRow() (Row, bool, error) {
 		if err != nil {
 			return nil, false, errors.Trace(err)
 		}
+		// The row which doesn't satisfy the big filter doesn't match any row, it's an output of the anti
+		// semi join.
 		if matched {
 			return bigRow, true, nil
-		} else if e.auxMode {
+		} else if e.auxMode || e.anti {
 			return bigRow, false, nil
 		}
 	}
 }
 
 func (e *HashSemiJoinExec) doJoin(bigRow Row, match bool) ([]Row, error) {
-	if e.auxMode && !match {
-		bigRow = append(bigRow, types.NewDatum(false))
-		e.resultRows[0] = bigRow
-		return e.resultRows, nil
-	}
-	matched, isNull, err := e.rowIsMatched(bigRow)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
-	if !matched && e.smallTableHasNull {
-		isNull = true
+	var (
+		matched, isNull bool
+		err             error
+	)
+	if match {
+		matched, isNull, err = e.rowIsMatched(bigRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	if e.anti && !isNull {
 		matched = !matched
@@ -245,6 +245,19 @@ func (s *testSuite) TestJoin(c *C) {
 	tk.MustExec("insert into t values(1,2), (5,3), (6,4)")
 	tk.MustExec("insert into t1 values(1), (2), (3)")
 	tk.MustQuery("select /*+ TIDB_INLJ(t1) */ t1.a from t1, t where t.a = 5 and t.b = t1.a").Check(testkit.Rows("3"))
+
+	// the conditions on the outer side are propagated to the inner side through the join keys
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("create table t1(a int, b int, key k(a))")
+	tk.MustExec("insert into t values(1, 1), (2, 2), (3, 3), (null, 4)")
+	tk.MustExec("insert into t1 values(1, 10), (2, 20), (2, 21), (null, 30)")
+	tk.MustQuery("select * from t left join t1 on t.a = t1.a where t.a = 2 order by t1.b").Check(testkit.Rows("2 2 2 20", "2 2 2 21"))
+	tk.MustQuery("select * from t left join t1 on t.a = t1.a and t.a = 1 order by t.b").Check(testkit.Rows("1 1 1 10", "2 2 <nil> <nil>", "3 3 <nil> <nil>", "<nil> 4 <nil> <nil>"))
+	tk.MustQuery("select * from t left join t1 on t.a = t1.a where t.a > 1 order by t.a, t1.b").Check(testkit.Rows("2 2 2 20", "2 2 2 21", "3 3 <nil> <nil>"))
+	tk.MustQuery("select * from t1 right join t on t.a = t1.a where t.a < 2").Check(testkit.Rows("1 10 1 1"))
+	tk.MustQuery("select * from t where t.a in (select a from t1) and t.a = 2").Check(testkit.Rows("2 2"))
+	tk.MustQuery("select * from t where t.a not in (select a from t1 where a is not null) and t.a = 3").Check(testkit.Rows("3 3"))
 }
 
 func (s *testSuite) TestJoinCast(c *C) {
@@ -698,6 +711,30 @@ func (s *testSuite) TestInSubquery(c *C) {
 	result.Check(testkit.Rows("2", "2", "1"))
 	result = tk.MustQuery("select * from t1 where a in (select count(*) from t2 where t1.a = t2.a) order by a desc")
 	result.Check(testkit.Rows("2", "2", "1"))
+
+	// NOT IN and the IN subquery used as a scalar are NULL if the value isn't found but is compared with NULL.
+	tk.MustExec("drop table if exists t, s, e")
+	tk.MustExec("create table t (a int, c int)")
+	tk.MustExec("create table s (b int, c int)")
+	tk.MustExec("create table e (b int, c int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (null, 3), (4, 4)")
+	tk.MustExec("insert into s values (1, 1), (null, 2), (4, 3)")
+	tk.MustQuery("select a from t where a not in (select b from s)").Check(testkit.Rows())
+	tk.MustQuery("select a, a not in (select b from s), a in (select b from s) from t").Check(testkit.Rows("1 0 1", "2 <nil> <nil>", "<nil> <nil> <nil>", "4 0 1"))
+	tk.MustQuery("select a from t where a not in (select b from s where b is not null)").Check(testkit.Rows("2"))
+	tk.MustQuery("select a from t where a not in (select b from e)").Check(testkit.Rows("1", "2", "<nil>", "4"))
+	tk.MustQuery("select a, a not in (select b from e), a in (select b from e) from t").Check(testkit.Rows("1 1 0", "2 1 0", "<nil> 1 0", "4 1 0"))
+	tk.MustQuery("select a from t where a not in (select 1 from s)").Check(testkit.Rows("2", "4"))
+	tk.MustQuery("select a from t where (a, c) not in (select b, c from s)").Check(testkit.Rows("4"))
+	tk.MustQuery("select a, c from t where a not in (select b from s) or c = 3").Check(testkit.Rows("<nil> 3"))
+	// The rows of the subquery are different for every row of the outer table.
+	tk.MustQuery("select a from t where a not in (select b from s where s.c = t.c)").Check(testkit.Rows("4"))
+	tk.MustQuery("select a, a not in (select b from s where s.c = t.c), a in (select b from s where s.c = t.c) from t").Check(testkit.Rows("1 0 1", "2 <nil> <nil>", "<nil> <nil> <nil>", "4 1 0"))
+	tk.MustQuery("select a from t where a not in (select b from s where s.c > t.c)").Check(testkit.Rows("2", "<nil>", "4"))
+	tk.MustQuery("select a from t where a not in (select b from s where t.a > 1)").Check(testkit.Rows("1", "<nil>"))
+	// NOT EXISTS isn't affected by NULL.
+	tk.MustQuery("select a from t where not exists (select 1 from s where s.b = t.a)").Check(testkit.Rows("2", "<nil>"))
+	tk.MustQuery("select a from t where not exists (select 1 from s where t.a > 1)").Check(testkit.Rows("1", "<nil>"))
 }
 
 func (s *testSuite) TestJoinLeak(c *C) {
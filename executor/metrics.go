@@ -40,11 +40,29 @@ var (
 			Name:      "expensive_query_total",
 			Help:      "Counter of expensive query.",
 		}, []string{"type"})
+	fastAnalyzeSampleRate = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "fast_analyze_sample_rate",
+			Help:      "Bucketed histogram of the proportion of the rows sampled by fast analyze.",
+			Buckets:   prometheus.ExponentialBuckets(0.000001, 10, 7),
+		})
+	fastAnalyzeNDVErrorBound = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "executor",
+			Name:      "fast_analyze_ndv_error_bound",
+			Help:      "Bucketed histogram of the ratio error bound of the NDVs estimated by fast analyze.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		})
 )
 
 func init() {
 	prometheus.MustRegister(stmtNodeCounter)
 	prometheus.MustRegister(expensiveQueryCounter)
+	prometheus.MustRegister(fastAnalyzeSampleRate)
+	prometheus.MustRegister(fastAnalyzeNDVErrorBound)
 }
 
 func stmtCount(node ast.StmtNode, p plan.Plan, inRestrictedSQL bool) bool {
@@ -149,7 +167,7 @@ func StatementLabel(node ast.StmtNode, p plan.Plan, isExpensive *bool) string {
 		return RollBack
 	case *ast.SelectStmt:
 		return getSelectStmtLabel(x, p, isExpensive)
-	case *ast.SetStmt, *ast.SetPwdStmt:
+	case *ast.SetStmt, *ast.SetPwdStmt, *ast.SetSessionStatesStmt:
 		return Set
 	case *ast.ShowStmt:
 		return Show
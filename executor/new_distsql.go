@@ -25,6 +25,9 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
@@ -53,6 +56,24 @@ func handleIsExtra(col *expression.Column) bool {
 	return false
 }
 
+// storeFeedback queues the feedback of a scan which has read all the rows in its ranges.
+func storeFeedback(ctx context.Context, q *statistics.QueryFeedback) {
+	if q == nil {
+		return
+	}
+	if dom := sessionctx.GetDomain(ctx); dom != nil && dom.StatsHandle() != nil {
+		dom.StatsHandle().StoreQueryFeedback(q)
+	}
+}
+
+// registerProgress registers a reader to the progress of the statement. The readers used by the joins with the
+// requests built from the outer rows aren't registered, because they're not opened.
+func registerProgress(ctx context.Context, estRows int64) *variable.QueryProgress {
+	progress := &ctx.GetSessionVars().StmtCtx.Progress
+	progress.AddOperator(estRows)
+	return progress
+}
+
 // TableReaderExecutor sends dag request and reads table data from kv layer.
 type TableReaderExecutor struct {
 	table     table.Table
@@ -72,6 +93,11 @@ type TableReaderExecutor struct {
 	result        distsql.SelectResult
 	partialResult distsql.PartialResult
 	priority      int
+	// feedback is the actual row count of the ranges, it's nil if the rows aren't read by Open.
+	feedback *statistics.QueryFeedback
+	// estRows is the estimated row count returned by the coprocessor, progress is counted by it.
+	estRows  int64
+	progress *variable.QueryProgress
 }
 
 // Schema implements the Executor Schema interface.
@@ -84,6 +110,8 @@ func (e *TableReaderExecutor) Close() error {
 	err := closeAll(e.result, e.partialResult)
 	e.result = nil
 	e.partialResult = nil
+	// The scan isn't finished, its feedback is dropped.
+	e.feedback = nil
 	return errors.Trace(err)
 }
 
@@ -99,6 +127,12 @@ func (e *TableReaderExecutor) Next() (Row, error) {
 			}
 			if e.partialResult == nil {
 				// Finished.
+				storeFeedback(e.ctx, e.feedback)
+				e.feedback = nil
+				if e.progress != nil {
+					e.progress.FinishOperator()
+					e.progress = nil
+				}
 				return nil, nil
 			}
 		}
@@ -113,6 +147,12 @@ func (e *TableReaderExecutor) Next() (Row, error) {
 			e.partialResult = nil
 			continue
 		}
+		if e.feedback != nil {
+			e.feedback.Update(1)
+		}
+		if e.progress != nil {
+			e.progress.AddScannedRows(1)
+		}
 		values := make([]types.Datum, e.schema.Len())
 		if handleIsExtra(e.handleCol) {
 			err = codec.SetRawValues(rowData, values[:len(values)-1])
@@ -140,11 +180,13 @@ func (e *TableReaderExecutor) Open() error {
 		return errors.Trace(err)
 	}
 	e.result.Fetch(e.ctx.GoCtx())
+	e.progress = registerProgress(e.ctx, e.estRows)
 	return nil
 }
 
 // doRequestForHandles constructs kv ranges by handles. It is used by index look up executor.
 func (e *TableReaderExecutor) doRequestForHandles(handles []int64, goCtx goctx.Context) error {
+	e.feedback = nil
 	sort.Sort(int64Slice(handles))
 	kvRanges := tableHandlesToKVRanges(e.tableID, handles)
 	var err error
@@ -186,6 +228,11 @@ type IndexReaderExecutor struct {
 	// columns are only required by union scan.
 	columns  []*model.ColumnInfo
 	priority int
+	// feedback is the actual row count of the ranges, it's nil if the rows aren't read by Open.
+	feedback *statistics.QueryFeedback
+	// estRows is the estimated row count returned by the coprocessor, progress is counted by it.
+	estRows  int64
+	progress *variable.QueryProgress
 }
 
 // Schema implements the Executor Schema interface.
@@ -198,6 +245,7 @@ func (e *IndexReaderExecutor) Close() error {
 	err := closeAll(e.result, e.partialResult)
 	e.result = nil
 	e.partialResult = nil
+	e.feedback = nil
 	return errors.Trace(err)
 }
 
@@ -213,6 +261,12 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 			}
 			if e.partialResult == nil {
 				// Finished.
+				storeFeedback(e.ctx, e.feedback)
+				e.feedback = nil
+				if e.progress != nil {
+					e.progress.FinishOperator()
+					e.progress = nil
+				}
 				return nil, nil
 			}
 		}
@@ -227,6 +281,12 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 			e.partialResult = nil
 			continue
 		}
+		if e.feedback != nil {
+			e.feedback.Update(1)
+		}
+		if e.progress != nil {
+			e.progress.AddScannedRows(1)
+		}
 		values := make([]types.Datum, e.schema.Len())
 		if handleIsExtra(e.handleCol) {
 			err = codec.SetRawValues(rowData, values[:len(values)-1])
@@ -247,11 +307,7 @@ func (e *IndexReaderExecutor) Next() (Row, error) {
 
 // Open implements the Executor Open interface.
 func (e *IndexReaderExecutor) Open() error {
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
-	kvRanges, err := indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index.ID, e.ranges, fieldTypes)
+	kvRanges, err := indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index, e.ranges, e.indexFieldTypes())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -260,12 +316,27 @@ func (e *IndexReaderExecutor) Open() error {
 		return errors.Trace(err)
 	}
 	e.result.Fetch(e.ctx.GoCtx())
+	e.progress = registerProgress(e.ctx, e.estRows)
 	return nil
 }
 
+// indexFieldTypes returns the field types of the index columns.
+func (e *IndexReaderExecutor) indexFieldTypes() []*types.FieldType {
+	return indexFieldTypes(e.table, e.index)
+}
+
+func indexFieldTypes(t table.Table, index *model.IndexInfo) []*types.FieldType {
+	fieldTypes := make([]*types.FieldType, len(index.Columns))
+	for i, v := range index.Columns {
+		fieldTypes[i] = &(t.Cols()[v.Offset].FieldType)
+	}
+	return fieldTypes
+}
+
 // doRequestForDatums constructs kv ranges by datums. It is used by index look up executor.
 func (e *IndexReaderExecutor) doRequestForDatums(values [][]types.Datum, goCtx goctx.Context) error {
-	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index.ID, values)
+	e.feedback = nil
+	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index, values, e.indexFieldTypes())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -285,15 +356,23 @@ type IndexLookUpExecutor struct {
 	keepOrder bool
 	desc      bool
 	ranges    []*types.IndexRange
-	dagPB     *tipb.DAGRequest
-	ctx       context.Context
-	schema    *expression.Schema
+	// tableRanges are the ranges of the table scan that reads the handles when index is nil, the scan reads only the
+	// columns of the filter conditions, and the other columns are read by the handles of the rows that pass.
+	tableRanges []types.IntColumnRange
+	dagPB       *tipb.DAGRequest
+	ctx         context.Context
+	schema      *expression.Schema
 	// This is the column that represent the handle, we can use handleCol.Index to know its position.
 	handleCol    *expression.Column
 	tableRequest *tipb.DAGRequest
 	// columns are only required by union scan.
 	columns  []*model.ColumnInfo
 	priority int
+	// feedback is the actual row count of the ranges, it's nil if the handles aren't read by Open.
+	feedback *statistics.QueryFeedback
+	// estRows is the estimated handle count returned by the index request, progress is counted by it.
+	estRows  int64
+	progress *variable.QueryProgress
 	// All fields above is immutable.
 
 	indexWorker
@@ -304,9 +383,19 @@ type IndexLookUpExecutor struct {
 	resultCurr *lookupTableTask
 }
 
+// lookupTaskInitBatchSize is the handle count of the first table lookup task of an IndexLookUpExecutor.
+const lookupTaskInitBatchSize = 32
+
 // indexWorker is used by IndexLookUpExecutor to maintain index lookup background goroutines.
 type indexWorker struct {
 	wg sync.WaitGroup
+	// batchSize is the handle count of the next table lookup task. It starts from lookupTaskInitBatchSize
+	// and doubles after each task until maxBatchSize, so the first rows are returned soon, and a large
+	// index scan is looked up by a few large tasks.
+	batchSize    int
+	maxBatchSize int
+	// partial is the partial result that the handles of the next task are read from.
+	partial distsql.PartialResult
 }
 
 // startIndexWorker launch a background goroutine to fetch handles, send the results to workCh.
@@ -323,6 +412,11 @@ func (e *IndexLookUpExecutor) startIndexWorker(kvRanges []kv.KeyRange, workCh ch
 		ctx, cancel := goctx.WithCancel(e.ctx.GoCtx())
 		worker.fetchHandles(e, result, workCh, ctx, finished)
 		cancel()
+		if worker.partial != nil {
+			if err := worker.partial.Close(); err != nil {
+				log.Error("close partial result failed:", errors.ErrorStack(err))
+			}
+		}
 		if err := result.Close(); err != nil {
 			log.Error("close SelectDAG result failed:", errors.ErrorStack(err))
 		}
@@ -333,12 +427,12 @@ func (e *IndexLookUpExecutor) startIndexWorker(kvRanges []kv.KeyRange, workCh ch
 	return nil
 }
 
-// fetchHandles fetches a batch of handles from index data and builds the index lookup tasks.
+// fetchHandles fetches the handles from index data and builds the index lookup tasks.
 // The tasks are sent to workCh to be further processed by tableWorker, and sent to e.resultCh
 // at the same time to keep data ordered.
 func (worker *indexWorker) fetchHandles(e *IndexLookUpExecutor, result distsql.SelectResult, workCh chan<- *lookupTableTask, ctx goctx.Context, finished <-chan struct{}) {
 	for {
-		handles, finish, err := extractHandlesFromIndexResult(result)
+		handles, err := worker.extractTaskHandles(result)
 		if err != nil {
 			doneCh := make(chan error, 1)
 			doneCh <- errors.Trace(err)
@@ -347,21 +441,67 @@ func (worker *indexWorker) fetchHandles(e *IndexLookUpExecutor, result distsql.S
 			}
 			return
 		}
-		if finish {
+		if len(handles) == 0 {
+			storeFeedback(e.ctx, e.feedback)
+			e.feedback = nil
+			if e.progress != nil {
+				e.progress.FinishOperator()
+			}
 			return
 		}
-		tasks := e.buildTableTasks(handles)
-		for _, task := range tasks {
-			select {
-			case <-ctx.Done():
-				return
-			case <-finished:
-				return
-			case workCh <- task:
-				e.resultCh <- task
+		if e.feedback != nil {
+			e.feedback.Update(int64(len(handles)))
+		}
+		if e.progress != nil {
+			e.progress.AddScannedRows(int64(len(handles)))
+		}
+		task := e.buildTableTask(handles)
+		select {
+		case <-ctx.Done():
+			return
+		case <-finished:
+			return
+		case workCh <- task:
+			e.resultCh <- task
+		}
+	}
+}
+
+// extractTaskHandles reads the handles of the next table lookup task from the index result. The handles
+// may come from several partial results, so the regions that have a few rows don't make many small tasks.
+// It returns no handles if all the handles are read.
+func (worker *indexWorker) extractTaskHandles(result distsql.SelectResult) ([]int64, error) {
+	handles := make([]int64, 0, worker.batchSize)
+	for len(handles) < worker.batchSize {
+		if worker.partial == nil {
+			partial, err := result.Next()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if partial == nil {
+				break
+			}
+			worker.partial = partial
+		}
+		h, data, err := worker.partial.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if data == nil {
+			err = worker.partial.Close()
+			worker.partial = nil
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
+			continue
 		}
+		handles = append(handles, h)
+	}
+	worker.batchSize *= 2
+	if worker.batchSize > worker.maxBatchSize {
+		worker.batchSize = worker.maxBatchSize
 	}
+	return handles, nil
 }
 
 func (worker *indexWorker) close() {
@@ -412,16 +552,27 @@ func (worker *tableWorker) close() {
 
 // Open implements the Executor Open interface.
 func (e *IndexLookUpExecutor) Open() error {
-	kvRanges, err := e.indexRangesToKVRanges()
-	if err != nil {
-		return errors.Trace(err)
+	var kvRanges []kv.KeyRange
+	if e.index == nil {
+		kvRanges = tableRangesToKVRanges(e.tableID, e.tableRanges)
+	} else {
+		var err error
+		kvRanges, err = e.indexRangesToKVRanges()
+		if err != nil {
+			return errors.Trace(err)
+		}
 	}
+	e.progress = registerProgress(e.ctx, e.estRows)
 	return e.open(kvRanges)
 }
 
 func (e *IndexLookUpExecutor) open(kvRanges []kv.KeyRange) error {
 	e.finished = make(chan struct{})
-	e.indexWorker = indexWorker{}
+	e.indexWorker = indexWorker{maxBatchSize: e.ctx.GetSessionVars().IndexLookupSize}
+	e.indexWorker.batchSize = lookupTaskInitBatchSize
+	if e.indexWorker.batchSize > e.indexWorker.maxBatchSize {
+		e.indexWorker.batchSize = e.indexWorker.maxBatchSize
+	}
 	e.tableWorker = tableWorker{}
 	e.resultCh = make(chan *lookupTableTask, atomic.LoadInt32(&LookupTableTaskChannelSize))
 
@@ -437,16 +588,18 @@ func (e *IndexLookUpExecutor) open(kvRanges []kv.KeyRange) error {
 }
 
 func (e *IndexLookUpExecutor) indexRangesToKVRanges() ([]kv.KeyRange, error) {
-	fieldTypes := make([]*types.FieldType, len(e.index.Columns))
-	for i, v := range e.index.Columns {
-		fieldTypes[i] = &(e.table.Cols()[v.Offset].FieldType)
-	}
-	return indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index.ID, e.ranges, fieldTypes)
+	return indexRangesToKVRanges(e.ctx.GetSessionVars().StmtCtx, e.tableID, e.index, e.ranges, e.indexFieldTypes())
+}
+
+// indexFieldTypes returns the field types of the index columns.
+func (e *IndexLookUpExecutor) indexFieldTypes() []*types.FieldType {
+	return indexFieldTypes(e.table, e.index)
 }
 
 // doRequestForDatums constructs kv ranges by datums. It is used by index look up join.
 func (e *IndexLookUpExecutor) doRequestForDatums(values [][]types.Datum, goCtx goctx.Context) error {
-	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index.ID, values)
+	e.feedback = nil
+	kvRanges, err := indexValuesToKVRanges(e.tableID, e.index, values, e.indexFieldTypes())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -506,19 +659,8 @@ func (e *IndexLookUpExecutor) executeTask(task *lookupTableTask, goCtx goctx.Con
 	}
 }
 
-func (e *IndexLookUpExecutor) buildTableTasks(handles []int64) []*lookupTableTask {
-	// Build tasks with increasing batch size.
-	var taskSizes []int
-	total := len(handles)
-	batchSize := e.ctx.GetSessionVars().IndexLookupSize
-	for total > 0 {
-		if batchSize > total {
-			batchSize = total
-		}
-		taskSizes = append(taskSizes, batchSize)
-		total -= batchSize
-	}
-
+// buildTableTask builds the table lookup task of the handles.
+func (e *IndexLookUpExecutor) buildTableTask(handles []int64) *lookupTableTask {
 	var indexOrder map[int64]int
 	if e.keepOrder {
 		// Save the index order.
@@ -527,18 +669,11 @@ func (e *IndexLookUpExecutor) buildTableTasks(handles []int64) []*lookupTableTas
 			indexOrder[h] = i
 		}
 	}
-
-	tasks := make([]*lookupTableTask, len(taskSizes))
-	for i, size := range taskSizes {
-		task := &lookupTableTask{
-			handles:    handles[:size],
-			indexOrder: indexOrder,
-		}
-		task.doneCh = make(chan error, 1)
-		handles = handles[size:]
-		tasks[i] = task
+	return &lookupTableTask{
+		handles:    handles,
+		indexOrder: indexOrder,
+		doneCh:     make(chan error, 1),
 	}
-	return tasks
 }
 
 // Schema implements Exec Schema interface.
@@ -558,6 +693,7 @@ func (e *IndexLookUpExecutor) Close() error {
 		e.tableWorker.close()
 		e.finished = nil
 	}
+	e.feedback = nil
 	return nil
 }
 
@@ -0,0 +1,330 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/util/format"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The files in the zip file dumped by PLAN REPLAYER DUMP. The schema and the statistics of each table are in
+// schema/<db>.<table>.sql and stats/<db>.<table>.json.
+const (
+	planReplayerDir         = "tidb_plan_replayer"
+	planReplayerMetaFile    = "meta.json"
+	planReplayerSQLFile     = "sql/sql.sql"
+	planReplayerExplainFile = "explain.txt"
+	// planReplayerFileLease is how long a dumped file is kept in the directory, the expired files are removed
+	// by the next dump.
+	planReplayerFileLease = 24 * time.Hour
+)
+
+// planReplayerMeta is the session of the dumped statement and the tables it reads or writes.
+type planReplayerMeta struct {
+	CurrentDB  string               `json:"current_db,omitempty"`
+	SystemVars map[string]string    `json:"system_vars,omitempty"`
+	Tables     []*planReplayerTable `json:"tables"`
+}
+
+type planReplayerTable struct {
+	DB    string `json:"db"`
+	Table string `json:"table"`
+}
+
+func (t *planReplayerTable) schemaFile() string {
+	return fmt.Sprintf("schema/%s.%s.sql", t.DB, t.Table)
+}
+
+func (t *planReplayerTable) statsFile() string {
+	return fmt.Sprintf("stats/%s.%s.json", t.DB, t.Table)
+}
+
+// PlanReplayerDumpExec represents a plan replayer dump executor.
+// It is built from the "plan replayer dump" statement. It dumps the schema and statistics of the tables, the session
+// variables, the statement and its plan into a zip file on the server, and returns the path of the file. The file
+// can be loaded by "plan replayer load" on another server to reproduce the plan.
+type PlanReplayerDumpExec struct {
+	baseExecutor
+
+	stmt    ast.StmtNode
+	tables  []*ast.TableName
+	explain *plan.Explain
+	is      infoschema.InfoSchema
+	done    bool
+}
+
+// Next implements the Executor Next interface.
+func (e *PlanReplayerDumpExec) Next() (Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	dir := filepath.Join(os.TempDir(), planReplayerDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Trace(err)
+	}
+	removeExpiredReplayerFiles(dir)
+	connID := e.ctx.GetSessionVars().ConnectionID
+	path := filepath.Join(dir, fmt.Sprintf("replayer_%d_%d.zip", connID, time.Now().UnixNano()))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = e.dump(zip.NewWriter(f))
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, errors.Trace(err)
+	}
+	return types.MakeDatums(path), nil
+}
+
+// removeExpiredReplayerFiles removes the dumped files older than planReplayerFileLease in dir.
+func removeExpiredReplayerFiles(dir string) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.Warnf("[plan replayer] read %s failed: %v", dir, err)
+		return
+	}
+	expired := time.Now().Add(-planReplayerFileLease)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), "replayer_") || f.ModTime().After(expired) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, f.Name())); err != nil {
+			log.Warnf("[plan replayer] remove expired file %s failed: %v", f.Name(), err)
+		}
+	}
+}
+
+func (e *PlanReplayerDumpExec) dump(zw *zip.Writer) error {
+	var sql bytes.Buffer
+	if err := e.stmt.Restore(format.NewRestoreCtx(&sql)); err != nil {
+		return errors.Trace(err)
+	}
+	if err := writeZipFile(zw, planReplayerSQLFile, sql.Bytes()); err != nil {
+		return errors.Trace(err)
+	}
+
+	vars := e.ctx.GetSessionVars()
+	meta := &planReplayerMeta{
+		CurrentDB:  vars.CurrentDB,
+		SystemVars: make(map[string]string, len(vars.Systems)),
+	}
+	// The snapshot of the dumping cluster doesn't exist in the other clusters.
+	for name, value := range vars.Systems {
+		if name != variable.TiDBSnapshot {
+			meta.SystemVars[name] = value
+		}
+	}
+	h := sessionctx.GetDomain(e.ctx).StatsHandle()
+	for _, tn := range e.tables {
+		t := &planReplayerTable{DB: tn.Schema.O, Table: tn.TableInfo.Name.O}
+		show := &ShowExec{
+			baseExecutor: newBaseExecutor(nil, e.ctx),
+			Tp:           ast.ShowCreateTable,
+			Table:        tn,
+			is:           e.is,
+		}
+		if err := show.fetchShowCreateTable(); err != nil {
+			return errors.Trace(err)
+		}
+		if err := writeZipFile(zw, t.schemaFile(), []byte(show.rows[0][1].GetString())); err != nil {
+			return errors.Trace(err)
+		}
+		jsonTbl, err := h.DumpStatsToJSON(vars.StmtCtx, t.DB, tn.TableInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		data, err := json.Marshal(jsonTbl)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = writeZipFile(zw, t.statsFile(), data); err != nil {
+			return errors.Trace(err)
+		}
+		meta.Tables = append(meta.Tables, t)
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = writeZipFile(zw, planReplayerMetaFile, data); err != nil {
+		return errors.Trace(err)
+	}
+
+	// The plan is dumped to be compared with the reproduced one.
+	var explain bytes.Buffer
+	fields := make([]string, 0, e.explain.Schema().Len())
+	for _, col := range e.explain.Schema().Columns {
+		fields = append(fields, col.ColName.O)
+	}
+	explain.WriteString(strings.Join(fields, "\t") + "\n")
+	for _, row := range e.explain.Rows {
+		fields = fields[:0]
+		for _, d := range row {
+			s, err := d.ToString()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			fields = append(fields, s)
+		}
+		explain.WriteString(strings.Join(fields, "\t") + "\n")
+	}
+	if err = writeZipFile(zw, planReplayerExplainFile, explain.Bytes()); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(zw.Close())
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = w.Write(data)
+	return errors.Trace(err)
+}
+
+func readZipFile(files map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, ErrPlanReplayerFile.GenByArgs(fmt.Sprintf("%s isn't found", name))
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	return data, errors.Trace(err)
+}
+
+// executePlanReplayerLoad loads the file dumped by PLAN REPLAYER DUMP. The databases are created if they don't exist,
+// and the tables are created, then their statistics are loaded, and the session variables and the default database
+// of the dumping session are set, so the dumped statement gets the same plan in this session. The file is refused if
+// any of the tables exists, its statistics would be overwritten.
+func (e *SimpleExec) executePlanReplayerLoad(s *ast.PlanReplayerStmt) error {
+	r, err := zip.OpenReader(s.File)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer r.Close()
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+	data, err := readZipFile(files, planReplayerMetaFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var meta planReplayerMeta
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return ErrPlanReplayerFile.GenByArgs(err)
+	}
+
+	dom := sessionctx.GetDomain(e.ctx)
+	is := dom.InfoSchema()
+	for _, t := range meta.Tables {
+		if is.TableExists(model.NewCIStr(t.DB), model.NewCIStr(t.Table)) {
+			return infoschema.ErrTableExists.GenByArgs(fmt.Sprintf("%s.%s", t.DB, t.Table))
+		}
+	}
+	for _, t := range meta.Tables {
+		if err = e.createReplayerTable(dom, files, t); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	// The created tables aren't in the schema of the statement.
+	e.is = dom.InfoSchema()
+	for _, t := range meta.Tables {
+		data, err = readZipFile(files, t.statsFile())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		jsonTbl := &statistics.JSONTable{}
+		if err = json.Unmarshal(data, jsonTbl); err != nil {
+			return ErrStatsJSON.GenByArgs(err.Error())
+		}
+		tbl, err := e.is.TableByName(model.NewCIStr(t.DB), model.NewCIStr(t.Table))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err = statistics.LoadStatsFromJSON(e.ctx, tbl.Meta(), jsonTbl); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if err = e.updateStats(); err != nil {
+		return errors.Trace(err)
+	}
+
+	vars := e.ctx.GetSessionVars()
+	if err = restoreSystemVars(vars, meta.SystemVars); err != nil {
+		return errors.Trace(err)
+	}
+	if meta.CurrentDB != "" {
+		return errors.Trace(e.executeUse(&ast.UseStmt{DBName: meta.CurrentDB}))
+	}
+	return nil
+}
+
+// createReplayerTable creates the table and its database, the database is kept if it exists.
+func (e *SimpleExec) createReplayerTable(dom *domain.Domain, files map[string]*zip.File, t *planReplayerTable) error {
+	data, err := readZipFile(files, t.schemaFile())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	stmt, err := parser.New().ParseOneStmt(string(data), "", "")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	create, ok := stmt.(*ast.CreateTableStmt)
+	if !ok {
+		return ErrPlanReplayerFile.GenByArgs(fmt.Sprintf("%s isn't a CREATE TABLE statement", t.schemaFile()))
+	}
+	dbName := model.NewCIStr(t.DB)
+	err = dom.DDL().CreateSchema(e.ctx, dbName, nil)
+	if infoschema.ErrDatabaseExists.Equal(err) {
+		err = nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ident := ast.Ident{Schema: dbName, Name: create.Table.Name}
+	err = dom.DDL().CreateTable(e.ctx, ident, create.Cols, create.Constraints, create.Options)
+	return errors.Trace(err)
+}
@@ -71,6 +71,9 @@ type Prepared struct {
 	Stmt          ast.StmtNode
 	Params        []*ast.ParamMarkerExpr
 	SchemaVersion int64
+	// CurrentDB is the default database when the statement is prepared, the table names in the statement are
+	// resolved with it.
+	CurrentDB string
 }
 
 // PrepareExec represents a PREPARE executor.
@@ -128,7 +131,9 @@ func (e *PrepareExec) DoPrepare() {
 	if sqlParser, ok := e.Ctx.(sqlexec.SQLParser); ok {
 		stmts, err = sqlParser.ParseSQL(e.SQLText, charset, collation)
 	} else {
-		stmts, err = parser.New().Parse(e.SQLText, charset, collation)
+		p := parser.New()
+		p.SetSQLMode(vars.SQLMode)
+		stmts, err = p.Parse(e.SQLText, charset, collation)
 	}
 	if err != nil {
 		e.Err = errors.Trace(err)
@@ -164,6 +169,7 @@ func (e *PrepareExec) DoPrepare() {
 		Stmt:          stmt,
 		Params:        sorter.markers,
 		SchemaVersion: e.IS.SchemaMetaVersion(),
+		CurrentDB:     vars.CurrentDB,
 	}
 
 	err = plan.PrepareStmt(e.IS, e.Ctx, stmt)
@@ -334,6 +340,7 @@ func ResetStmtCtx(ctx context.Context, s ast.StmtNode) {
 	sessVars := ctx.GetSessionVars()
 	sc := new(variable.StatementContext)
 	sc.TimeZone = sessVars.GetTimeZone()
+	sc.OriginalSQL = s.Text()
 
 	switch stmt := s.(type) {
 	case *ast.UpdateStmt, *ast.DeleteStmt:
@@ -350,6 +357,10 @@ func ResetStmtCtx(ctx context.Context, s ast.StmtNode) {
 		sc.IgnoreTruncate = false
 		sc.OverflowAsWarning = false
 		sc.TruncateAsWarning = false
+	case *ast.ExecuteStmt:
+		// The statement context is reset again for the prepared statement.
+		sc.InExecuteStmt = true
+		sc.IgnoreTruncate = true
 	case *ast.LoadDataStmt:
 		sc.IgnoreTruncate = false
 		sc.OverflowAsWarning = false
@@ -384,5 +395,13 @@ func ResetStmtCtx(ctx context.Context, s ast.StmtNode) {
 		sessVars.LastInsertID = 0
 	}
 	sessVars.InsertID = 0
+	// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_row-count
+	if prevSC := sessVars.StmtCtx; !prevSC.InExecuteStmt {
+		if prevSC.InSelectStmt {
+			sessVars.PrevAffectedRows = -1
+		} else {
+			sessVars.PrevAffectedRows = int64(prevSC.AffectedRows())
+		}
+	}
 	sessVars.StmtCtx = sc
 }
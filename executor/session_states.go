@@ -0,0 +1,159 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// sessionStates is the state of a session that is dumped by SHOW SESSION_STATES and restored by SET SESSION_STATES.
+// A proxy uses them to migrate a connection to another server, e.g. when the server is going to be scaled in.
+type sessionStates struct {
+	UserVars           map[string]string     `json:"user_vars,omitempty"`
+	SystemVars         map[string]string     `json:"system_vars,omitempty"`
+	CurrentDB          string                `json:"current_db,omitempty"`
+	LastInsertID       uint64                `json:"last_insert_id,omitempty"`
+	PreparedStmts      map[uint32]*stmtState `json:"prepared_stmts,omitempty"`
+	LastPreparedStmtID uint32                `json:"last_prepared_stmt_id,omitempty"`
+}
+
+// stmtState is the state of a prepared statement, the statement is prepared again when it's restored.
+type stmtState struct {
+	Name      string `json:"name,omitempty"`
+	SQLText   string `json:"sql_text"`
+	CurrentDB string `json:"current_db,omitempty"`
+}
+
+func (e *ShowExec) fetchShowSessionStates() error {
+	vars := e.ctx.GetSessionVars()
+	if vars.InTxn() {
+		return ErrSessionStatesInTxn.GenByArgs("dump")
+	}
+	states := &sessionStates{
+		UserVars:           make(map[string]string),
+		SystemVars:         make(map[string]string, len(vars.Systems)),
+		CurrentDB:          vars.CurrentDB,
+		LastInsertID:       vars.PrevLastInsertID,
+		PreparedStmts:      make(map[uint32]*stmtState, len(vars.PreparedStmts)),
+		LastPreparedStmtID: vars.GetLastPreparedStmtID(),
+	}
+	vars.UsersLock.RLock()
+	for name, value := range vars.Users {
+		states.UserVars[name] = value
+	}
+	vars.UsersLock.RUnlock()
+	for name, value := range vars.Systems {
+		if name != variable.TiDBSnapshot {
+			states.SystemVars[name] = value
+		}
+	}
+	for id, v := range vars.PreparedStmts {
+		prepared := v.(*Prepared)
+		states.PreparedStmts[id] = &stmtState{
+			SQLText:   prepared.Stmt.Text(),
+			CurrentDB: prepared.CurrentDB,
+		}
+	}
+	for name, id := range vars.PreparedStmtNameToID {
+		if stmt, ok := states.PreparedStmts[id]; ok {
+			stmt.Name = name
+		}
+	}
+	data, err := json.Marshal(states)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.rows = append(e.rows, types.MakeDatums(string(data)))
+	return nil
+}
+
+// executeSetSessionStates restores the session states dumped from another session, it's expected to be executed
+// in a new session.
+func (e *SimpleExec) executeSetSessionStates(s *ast.SetSessionStatesStmt) error {
+	vars := e.ctx.GetSessionVars()
+	if vars.InTxn() {
+		return ErrSessionStatesInTxn.GenByArgs("restore")
+	}
+	var states sessionStates
+	if err := json.Unmarshal([]byte(s.SessionStates), &states); err != nil {
+		return ErrSessionStatesJSON.GenByArgs(err)
+	}
+	if err := restoreSystemVars(vars, states.SystemVars); err != nil {
+		return errors.Trace(err)
+	}
+	vars.UsersLock.Lock()
+	for name, value := range states.UserVars {
+		vars.Users[name] = value
+	}
+	vars.UsersLock.Unlock()
+	vars.PrevLastInsertID = states.LastInsertID
+
+	// The statements are prepared in the order they were prepared originally, each with its default database.
+	ids := make([]int, 0, len(states.PreparedStmts))
+	for id := range states.PreparedStmts {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		stmt := states.PreparedStmts[uint32(id)]
+		delete(vars.PreparedStmts, uint32(id))
+		vars.CurrentDB = stmt.CurrentDB
+		prepareExec := &PrepareExec{
+			IS:      e.is,
+			Ctx:     e.ctx,
+			Name:    stmt.Name,
+			SQLText: stmt.SQLText,
+			ID:      uint32(id),
+		}
+		prepareExec.DoPrepare()
+		if prepareExec.Err != nil {
+			return errors.Trace(prepareExec.Err)
+		}
+	}
+	if states.LastPreparedStmtID > vars.GetLastPreparedStmtID() {
+		vars.SetLastPreparedStmtID(states.LastPreparedStmtID)
+	}
+
+	vars.CurrentDB = ""
+	if states.CurrentDB != "" {
+		return errors.Trace(e.executeUse(&ast.UseStmt{DBName: states.CurrentDB}))
+	}
+	return nil
+}
+
+// restoreSystemVars sets the session system variables dumped from another session, the read-only ones are skipped.
+// The snapshot is skipped too, it's checked against the GC safe point and its schema is loaded only by the SET statement.
+func restoreSystemVars(vars *variable.SessionVars, systemVars map[string]string) error {
+	for name, value := range systemVars {
+		sysVar := variable.GetSysVar(name)
+		if sysVar == nil || sysVar.Scope == variable.ScopeNone {
+			continue
+		}
+		if variable.ResolveSysVarAlias(strings.ToLower(name)) == variable.TiDBSnapshot {
+			continue
+		}
+		if err := varsutil.SetSessionSystemVar(vars, name, types.NewStringDatum(value)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -114,7 +114,11 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
-			err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(name, svalue)
+			svalue, err = varsutil.ValidateSetSystemVar(name, svalue)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(variable.ResolveSysVarAlias(name), svalue)
 			if err != nil {
 				return errors.Trace(err)
 			}
@@ -123,6 +127,9 @@ func (e *SetExecutor) executeSet() error {
 			if sysVar.Scope&variable.ScopeSession == 0 {
 				return errors.Errorf("Variable '%s' is a GLOBAL variable and should be set with SET GLOBAL", name)
 			}
+			if name == variable.TxnIsolationOneShot && sessionVars.TxnCtx.Histroy != nil {
+				return ErrCantChangeTxChars
+			}
 			value, err := e.getVarValue(v, nil)
 			if err != nil {
 				return errors.Trace(err)
@@ -145,8 +152,13 @@ func (e *SetExecutor) executeSet() error {
 			log.Infof("[%d] set system variable %s = %s", sessionVars.ConnectionID, name, valStr)
 		}
 
-		if name == variable.TxnIsolation {
-			if sessionVars.Systems[variable.TxnIsolation] == ast.ReadCommitted {
+		if name == variable.TxnIsolationOneShot && sessionVars.InTxn() {
+			// No statement has run in the transaction, so it's the next transaction.
+			sessionVars.TxnCtx.Isolation = sessionVars.TxnIsolationOneShot
+			sessionVars.TxnIsolationOneShot = ""
+		}
+		if name == variable.TxnIsolationOneShot || variable.ResolveSysVarAlias(name) == variable.TxnIsolation {
+			if sessionVars.TxnIsolationLevel() == ast.ReadCommitted {
 				e.ctx.Txn().SetOption(kv.IsolationLevel, kv.RC)
 			}
 		}
@@ -177,13 +189,22 @@ func validateSnapshot(ctx context.Context, snapshotTS uint64) error {
 	return nil
 }
 
-func (e *SetExecutor) setCharset(cs, co string) error {
-	var err error
+func (e *SetExecutor) setCharset(name, co string) error {
+	cs, defaultCollation, err := charset.GetCharsetInfo(name)
+	if err != nil {
+		return variable.ErrUnknownCharacterSet.GenByArgs(name)
+	}
 	if len(co) == 0 {
-		co, err = charset.GetDefaultCollation(cs)
+		co = defaultCollation
+	} else {
+		collation, err := charset.GetCollationByName(co)
 		if err != nil {
-			return errors.Trace(err)
+			return variable.ErrUnknownCollation.GenByArgs(co)
+		}
+		if collation.CharsetName != cs {
+			return variable.ErrCollationCharsetMismatch.GenByArgs(collation.Name, cs)
 		}
+		co = collation.Name
 	}
 	sessionVars := e.ctx.GetSessionVars()
 	for _, v := range variable.SetNamesVariables {
@@ -18,6 +18,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -138,6 +139,30 @@ func (s *testSuite) TestSetVar(c *C) {
 	tk.MustQuery("select @@session.tx_isolation").Check(testkit.Rows("READ-UNCOMMITTED"))
 	tk.MustExec("SET GLOBAL TRANSACTION ISOLATION LEVEL SERIALIZABLE")
 	tk.MustQuery("select @@global.tx_isolation").Check(testkit.Rows("SERIALIZABLE"))
+	tk.MustExec("set @@session.tx_isolation = 'read-committed'")
+	tk.MustQuery("select @@session.tx_isolation").Check(testkit.Rows("READ-COMMITTED"))
+	_, err = tk.Exec("set @@session.tx_isolation = 'snapshot'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = tk.Exec("set @@global.tx_isolation = 'snapshot'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	tk.MustQuery("select @@global.tx_isolation").Check(testkit.Rows("SERIALIZABLE"))
+
+	// The variables of MySQL 8 share the values of the old ones.
+	tk.MustQuery("select @@session.transaction_isolation, @@global.transaction_isolation").Check(
+		testkit.Rows("READ-COMMITTED SERIALIZABLE"))
+	tk.MustExec("set @@session.transaction_isolation = 'repeatable-read'")
+	tk.MustQuery("select @@session.tx_isolation").Check(testkit.Rows("REPEATABLE-READ"))
+	tk.MustExec("set @@global.transaction_isolation = 'read-committed'")
+	tk.MustQuery("select @@global.tx_isolation").Check(testkit.Rows("READ-COMMITTED"))
+	_, err = tk.Exec("set @@session.transaction_isolation = 'snapshot'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	tk.MustExec("set @@session.tx_read_only = 1")
+	tk.MustQuery("select @@session.transaction_read_only").Check(testkit.Rows("1"))
+	tk.MustExec("set @@session.transaction_read_only = 0")
+	tk.MustQuery("select @@session.tx_read_only").Check(testkit.Rows("0"))
+	tk.MustExec("SET GLOBAL TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	tk.MustExec("SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED")
+	tk.MustQuery("select @@default_collation_for_utf8mb4, @@require_secure_transport").Check(testkit.Rows("utf8mb4_bin OFF"))
 
 	// Even the transaction fail, set session variable would success.
 	tk.MustExec("BEGIN")
@@ -183,4 +208,16 @@ func (s *testSuite) TestSetCharset(c *C) {
 
 	// Issue 1523
 	tk.MustExec(`SET NAMES binary`)
+
+	tk.MustExec(`SET NAMES LATIN1 COLLATE LATIN1_SWEDISH_CI`)
+	tk.MustQuery("select @@character_set_client, @@character_set_results, @@collation_connection").Check(
+		testkit.Rows("latin1 latin1 latin1_swedish_ci"))
+	_, err = tk.Exec(`SET NAMES utf16`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCharacterSet), IsTrue)
+	_, err = tk.Exec(`SET NAMES utf8 COLLATE latin1_bin`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrCollationCharsetMismatch), IsTrue)
+	_, err = tk.Exec(`SET NAMES utf8 COLLATE utf8_invalid_ci`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCollation), IsTrue)
+	_, err = tk.Exec(`SET character_set_results = 'utf16'`)
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCharacterSet), IsTrue)
 }
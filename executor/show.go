@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/charset"
@@ -47,6 +48,8 @@ type ShowExec struct {
 	Flag   int             // Some flag parsed from sql, such as FULL.
 	Full   bool
 	User   *auth.UserIdentity // Used for show grants.
+	// ConnectionID is used for show progress.
+	ConnectionID uint64
 
 	// GlobalScope is used by show variables
 	GlobalScope bool
@@ -110,6 +113,10 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowWarnings()
 	case ast.ShowProcessList:
 		return e.fetchShowProcessList()
+	case ast.ShowProgress:
+		return e.fetchShowProgress()
+	case ast.ShowSessionStates:
+		return e.fetchShowSessionStates()
 	case ast.ShowEvents:
 		// empty result
 	case ast.ShowStatsMeta:
@@ -118,6 +125,8 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowStatsHistogram()
 	case ast.ShowStatsBuckets:
 		return e.fetchShowStatsBuckets()
+	case ast.ShowStatsJSON:
+		return e.fetchShowStatsJSON()
 	case ast.ShowPlugins:
 		return e.fetchShowPlugins()
 	}
@@ -134,6 +143,9 @@ func (e *ShowExec) fetchShowEngines() error {
 		"YES",
 	)
 	e.rows = append(e.rows, row)
+	for _, name := range engine.Names() {
+		e.rows = append(e.rows, types.MakeDatums(name, "YES", "External storage engine", "NO", "NO", "NO"))
+	}
 	return nil
 }
 
@@ -151,6 +163,9 @@ func (e *ShowExec) fetchShowDatabases() error {
 	return nil
 }
 
+// processListInfoLen is the length of the statements shown by SHOW PROCESSLIST.
+const processListInfoLen = 100
+
 func (e *ShowExec) fetchShowProcessList() error {
 	sm := e.ctx.GetSessionManager()
 	if sm == nil {
@@ -163,6 +178,11 @@ func (e *ShowExec) fetchShowProcessList() error {
 		if len(pi.Info) != 0 {
 			t = uint64(time.Since(pi.Time) / time.Second)
 		}
+		info := pi.Info
+		// Like MySQL, only the first 100 characters of the statements are shown without FULL.
+		if r := []rune(info); !e.Full && len(r) > processListInfoLen {
+			info = string(r[:processListInfoLen])
+		}
 		row := []types.Datum{
 			types.NewUintDatum(pi.ID),
 			types.NewStringDatum(pi.User),
@@ -171,7 +191,31 @@ func (e *ShowExec) fetchShowProcessList() error {
 			types.NewStringDatum(pi.Command),
 			types.NewUintDatum(t),
 			types.NewStringDatum(fmt.Sprintf("%d", pi.State)),
+			types.NewStringDatum(info),
+		}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
+func (e *ShowExec) fetchShowProgress() error {
+	sm := e.ctx.GetSessionManager()
+	if sm == nil {
+		return nil
+	}
+
+	for _, pi := range sm.ShowProcessList() {
+		if pi.ID != e.ConnectionID || pi.Progress == nil {
+			continue
+		}
+		row := []types.Datum{
+			types.NewUintDatum(pi.ID),
 			types.NewStringDatum(pi.Info),
+			types.NewFloat64Datum(pi.Progress.Percent()),
+			types.NewIntDatum(pi.Progress.EstimatedRows()),
+			types.NewIntDatum(pi.Progress.ScannedRows()),
+			types.NewIntDatum(pi.Progress.Operators()),
+			types.NewIntDatum(pi.Progress.FinishedOperators()),
 		}
 		e.rows = append(e.rows, row)
 	}
@@ -217,7 +261,7 @@ func (e *ShowExec) fetchShowTableStatus() error {
 
 	for _, t := range tables {
 		now := types.CurrentTime(mysql.TypeDatetime)
-		data := types.MakeDatums(t.Meta().Name.O, "InnoDB", "10", "Compact", 100, 100, 100, 100, 100, 100, 100,
+		data := types.MakeDatums(t.Meta().Name.O, engine.NameOf(t.Meta()), "10", "Compact", 100, 100, 100, 100, 100, 100, 100,
 			now, now, now, "utf8_general_ci", "", "", t.Meta().Comment)
 		e.rows = append(e.rows, data)
 	}
@@ -309,13 +353,17 @@ func (e *ShowExec) fetchShowIndex() error {
 			if col.Length != types.UnspecifiedLength {
 				subPart = col.Length
 			}
+			collation := "A"
+			if col.Desc {
+				collation = "D"
+			}
 			data := types.MakeDatums(
 				tb.Meta().Name.O,  // Table
 				nonUniq,           // Non_unique
 				idx.Meta().Name.O, // Key_name
 				i+1,               // Seq_in_index
 				col.Name.O,        // Column_name
-				"A",               // Collation
+				collation,         // Collation
 				0,                 // Cardinality
 				subPart,           // Sub_part
 				nil,               // Packed
@@ -417,7 +465,9 @@ func (e *ShowExec) fetchShowCreateTable() error {
 			if mysql.HasNotNullFlag(col.Flag) {
 				buf.WriteString(" NOT NULL")
 			}
-			if !mysql.HasNoDefaultValueFlag(col.Flag) {
+			if col.DefaultIsExpr {
+				buf.WriteString(fmt.Sprintf(" DEFAULT (%s)", col.DefaultValue))
+			} else if !mysql.HasNoDefaultValueFlag(col.Flag) {
 				switch col.DefaultValue {
 				case nil:
 					if !mysql.HasNotNullFlag(col.Flag) {
@@ -475,9 +525,13 @@ func (e *ShowExec) fetchShowCreateTable() error {
 
 		cols := make([]string, 0, len(idxInfo.Columns))
 		for _, c := range idxInfo.Columns {
-			cols = append(cols, c.Name.O)
+			colName := fmt.Sprintf("`%s`", c.Name.O)
+			if c.Desc {
+				colName += " DESC"
+			}
+			cols = append(cols, colName)
 		}
-		buf.WriteString(fmt.Sprintf("(`%s`)", strings.Join(cols, "`,`")))
+		buf.WriteString(fmt.Sprintf("(%s)", strings.Join(cols, ",")))
 		if i != len(tb.Indices())-1 {
 			buf.WriteString(",\n")
 		}
@@ -519,7 +573,7 @@ func (e *ShowExec) fetchShowCreateTable() error {
 	}
 	buf.WriteString("\n")
 
-	buf.WriteString(") ENGINE=InnoDB")
+	buf.WriteString(fmt.Sprintf(") ENGINE=%s", engine.NameOf(tb.Meta())))
 	charsetName := tb.Meta().Charset
 	if len(charsetName) == 0 {
 		charsetName = charset.CharsetUTF8
@@ -536,15 +590,51 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		buf.WriteString(fmt.Sprintf(" AUTO_INCREMENT=%d", tb.Meta().AutoIncID))
 	}
 
+	if tb.Meta().AutoIDCache > 0 {
+		buf.WriteString(fmt.Sprintf(" AUTO_ID_CACHE=%d", tb.Meta().AutoIDCache))
+	}
+
 	if len(tb.Meta().Comment) > 0 {
 		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", format.OutputFormat(tb.Meta().Comment)))
 	}
 
+	engineOptions := make([]string, 0, len(tb.Meta().EngineOptions))
+	for name := range tb.Meta().EngineOptions {
+		engineOptions = append(engineOptions, name)
+	}
+	sort.Strings(engineOptions)
+	for _, name := range engineOptions {
+		buf.WriteString(fmt.Sprintf(" %s='%s'", strings.ToUpper(name), format.OutputFormat(tb.Meta().EngineOptions[name])))
+	}
+
+	// The placement policy is in a TiDB specific comment, so the statement is still accepted by MySQL.
+	if policy := tb.Meta().PlacementPolicy; policy != nil {
+		buf.WriteString(" /*T! PLACEMENT POLICY")
+		if policy.Replicas > 0 {
+			buf.WriteString(fmt.Sprintf(" REPLICAS=%d", policy.Replicas))
+		}
+		if len(policy.Constraints) > 0 {
+			buf.WriteString(fmt.Sprintf(" CONSTRAINTS='%s'", joinLabelConstraints(policy.Constraints)))
+		}
+		if len(policy.LeaderConstraints) > 0 {
+			buf.WriteString(fmt.Sprintf(" LEADER_CONSTRAINTS='%s'", joinLabelConstraints(policy.LeaderConstraints)))
+		}
+		buf.WriteString(" */")
+	}
+
 	data := types.MakeDatums(tb.Meta().Name.O, buf.String())
 	e.rows = append(e.rows, data)
 	return nil
 }
 
+func joinLabelConstraints(constraints []*model.LabelConstraint) string {
+	strs := make([]string, 0, len(constraints))
+	for _, c := range constraints {
+		strs = append(strs, format.OutputFormat(c.String()))
+	}
+	return strings.Join(strs, ",")
+}
+
 // fetchShowCreateDatabase composes show create database result.
 func (e *ShowExec) fetchShowCreateDatabase() error {
 	db, ok := e.is.SchemaByName(e.DBName)
@@ -617,16 +707,9 @@ func (e *ShowExec) fetchShowWarnings() error {
 	for _, warn := range warns {
 		datums := make([]types.Datum, 3)
 		datums[0] = types.NewStringDatum("Warning")
-		warn = errors.Cause(warn)
-		switch x := warn.(type) {
-		case *terror.Error:
-			sqlErr := x.ToSQLError()
-			datums[1] = types.NewIntDatum(int64(sqlErr.Code))
-			datums[2] = types.NewStringDatum(sqlErr.Message)
-		default:
-			datums[1] = types.NewIntDatum(int64(mysql.ErrUnknown))
-			datums[2] = types.NewStringDatum(warn.Error())
-		}
+		sqlErr := terror.ToSQLError(warn)
+		datums[1] = types.NewIntDatum(int64(sqlErr.Code))
+		datums[2] = types.NewStringDatum(sqlErr.Message)
 		e.rows = append(e.rows, datums)
 	}
 	return nil
@@ -14,6 +14,7 @@
 package executor
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/juju/errors"
@@ -112,6 +113,20 @@ func (e *ShowExec) fetchShowStatsBuckets() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowStatsJSON() error {
+	h := sessionctx.GetDomain(e.ctx).StatsHandle()
+	jsonTbl, err := h.DumpStatsToJSON(e.ctx.GetSessionVars().StmtCtx, e.Table.Schema.O, e.Table.TableInfo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	data, err := json.Marshal(jsonTbl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.rows = append(e.rows, types.MakeDatums(e.Table.Schema.O, e.Table.Name.O, string(data)))
+	return nil
+}
+
 // bucketsToRows converts histogram buckets to rows. If the histogram is built from index, then numOfCols equals to number
 // of index columns, else numOfCols is 0.
 func (e *ShowExec) bucketsToRows(dbName, tblName, colName string, numOfCols int, hist statistics.Histogram) ([]Row, error) {
@@ -18,9 +18,11 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
@@ -149,6 +151,14 @@ func (s *testSuite) TestShow(c *C) {
 			"  `id` int(11) DEFAULT NULL\n"+
 			") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_INCREMENT=4",
 	))
+	tk.MustExec(`drop table if exists show_auto_id_cache`)
+	tk.MustExec(`create table show_auto_id_cache (id int) auto_id_cache=1`)
+	tk.MustQuery(`show create table show_auto_id_cache`).Check(testutil.RowsWithSep("|",
+		""+
+			"show_auto_id_cache CREATE TABLE `show_auto_id_cache` (\n"+
+			"  `id` int(11) DEFAULT NULL\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin AUTO_ID_CACHE=1",
+	))
 
 	// Test show table with column's comment contain escape character
 	// for issue https://github.com/pingcap/tidb/issues/4411
@@ -366,3 +376,162 @@ func (s *testSuite) TestIssue3641(c *C) {
 	_, err = tk.Exec("show table status;")
 	c.Assert(err.Error(), Equals, plan.ErrNoDB.Error())
 }
+
+type mockSessionManager struct {
+	sessions []tidb.Session
+}
+
+func (sm *mockSessionManager) ShowProcessList() []util.ProcessInfo {
+	pl := make([]util.ProcessInfo, 0, len(sm.sessions))
+	for _, se := range sm.sessions {
+		pl = append(pl, se.ShowProcess())
+	}
+	return pl
+}
+
+func (sm *mockSessionManager) Kill(connectionID uint64, query bool) error {
+	return nil
+}
+
+func (s *testSuite) TestShowProgress(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int primary key, b int, index idx(b))")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3), (4, 4)")
+
+	// The progress of the last statement is kept in the statement context.
+	tk.MustQuery("select * from t use index(idx) where b > 1")
+	progress := &tk.Se.GetSessionVars().StmtCtx.Progress
+	c.Assert(progress.ScannedRows(), Equals, int64(3))
+	c.Assert(progress.Operators(), Equals, int64(1))
+	c.Assert(progress.FinishedOperators(), Equals, int64(1))
+	c.Assert(progress.Percent(), Equals, float64(100))
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.Se.GetSessionVars().ConnectionID = 2
+	sm := &mockSessionManager{sessions: []tidb.Session{tk1.Se}}
+	tk.Se.SetSessionManager(sm)
+
+	rs, err := tk1.Exec("select * from t")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, IsNil)
+	tk.MustQuery("show progress for 2").Check(testkit.Rows("2 select * from t 0.0125 8000 1 1 0"))
+	tk.MustQuery("select id, progress, rows_scanned, operators_finished from information_schema.processlist").Check(
+		testkit.Rows("2 0.0125 1 0"))
+	for {
+		row, err := rs.Next()
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+	}
+	tk.MustQuery("show progress for 2").Check(testkit.Rows("2 select * from t 100 8000 4 1 1"))
+	c.Assert(rs.Close(), IsNil)
+
+	// The session is idle.
+	tk.MustQuery("show progress for 2").Check(testkit.Rows())
+	tk.MustQuery("show progress for 3").Check(testkit.Rows())
+	tk.MustQuery("select id, progress, info from information_schema.processlist").Check(testkit.Rows("2 <nil> "))
+}
+
+func (s *testSuite) TestShowForMySQL8Clients(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int)")
+	tk.MustExec("insert into t values (1), (2)")
+
+	// The statements are truncated to 100 characters without FULL.
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+	tk1.Se.GetSessionVars().ConnectionID = 2
+	sm := &mockSessionManager{sessions: []tidb.Session{tk1.Se}}
+	tk.Se.SetSessionManager(sm)
+	sql := "select * from t where a in (" + strings.Repeat("1, ", 50) + "2)"
+	rs, err := tk1.Exec(sql)
+	c.Assert(err, IsNil)
+	rows := tk.MustQuery("show processlist").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][7], Equals, sql[:100])
+	rows = tk.MustQuery("show full processlist").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][7], Equals, sql)
+	c.Assert(rs.Close(), IsNil)
+
+	c.Assert(tk.MustQuery("show storage engines").Rows(), DeepEquals, tk.MustQuery("show engines").Rows())
+	c.Assert(tk.Se.Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil), IsTrue)
+	tk.MustQuery("show grants for current_user").Check(testkit.Rows(`GRANT ALL PRIVILEGES ON *.* TO 'root'@'%'`))
+	tk.MustQuery("show grants for current_user()").Check(testkit.Rows(`GRANT ALL PRIVILEGES ON *.* TO 'root'@'%'`))
+
+	// The information schema has the same charsets and collations as SHOW CHARACTER SET and SHOW COLLATION.
+	tk.MustQuery("select * from information_schema.character_sets where character_set_name = 'utf8mb4'").Check(
+		testkit.Rows("utf8mb4 utf8mb4_bin UTF-8 Unicode 4"))
+	tk.MustQuery("show character set where charset = 'utf8mb4'").Check(testkit.Rows("utf8mb4 UTF-8 Unicode utf8mb4_bin 4"))
+	c.Assert(tk.MustQuery("select * from information_schema.collations").Rows(), DeepEquals,
+		tk.MustQuery("show collation").Rows())
+	tk.MustQuery("select count(*) from information_schema.plugins").Check(testkit.Rows("0"))
+}
+
+func (s *testSuite) TestSessionStates(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int auto_increment primary key, b int)")
+	tk.MustExec("insert into t (b) values (10), (20)")
+	tk.MustExec("set @a = 2, @@sql_mode = 'NO_ZERO_DATE', @@tidb_distsql_scan_concurrency = 3")
+	tk.MustExec("prepare stmt from 'select b from t where a = ?'")
+	stmtID, _, _, err := tk.Se.PrepareStmt("select b from t where a > ? order by a")
+	c.Assert(err, IsNil)
+	tk.MustExec("use mysql")
+
+	tk.MustExec("begin")
+	rs, err := tk.Exec("show session_states")
+	c.Assert(err, IsNil)
+	_, err = tidb.GetRows(rs)
+	c.Assert(executor.ErrSessionStatesInTxn.Equal(err), IsTrue)
+	c.Assert(rs.Close(), IsNil)
+	tk.MustExec("commit")
+	states := tk.MustQuery("show session_states").Rows()[0][0].(string)
+	states = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(states)
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("set session_states '" + states + "'")
+	tk1.MustQuery("select database(), @a, @@sql_mode, @@tidb_distsql_scan_concurrency, last_insert_id()").Check(
+		testkit.Rows("mysql 2 NO_ZERO_DATE 3 1"))
+	// The statements are prepared in the database they were prepared originally.
+	tk1.MustQuery("execute stmt using @a").Check(testkit.Rows("20"))
+	rs, err = tk1.Se.ExecutePreparedStmt(stmtID, 1)
+	c.Assert(err, IsNil)
+	rows, err := tidb.GetRows(rs)
+	c.Assert(err, IsNil)
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][0].GetInt64(), Equals, int64(20))
+	newID, _, _, err := tk1.Se.PrepareStmt("select 1")
+	c.Assert(err, IsNil)
+	c.Assert(newID, Equals, stmtID+1)
+
+	// The snapshot isn't dumped nor restored.
+	tk.Se.GetSessionVars().Systems[variable.TiDBSnapshot] = "2006-01-01 15:04:05.999999"
+	states = tk.MustQuery("show session_states").Rows()[0][0].(string)
+	delete(tk.Se.GetSessionVars().Systems, variable.TiDBSnapshot)
+	c.Assert(strings.Contains(states, "tidb_snapshot"), IsFalse)
+	tk1.MustExec(`set session_states '{"system_vars": {"tidb_snapshot": "2006-01-01 15:04:05.999999"}}'`)
+	tk1.MustQuery("select @@tidb_snapshot").Check(testkit.Rows(""))
+	c.Assert(tk1.Se.GetSessionVars().SnapshotTS, Equals, uint64(0))
+
+	_, err = tk1.Exec("set session_states 'invalid'")
+	c.Assert(executor.ErrSessionStatesJSON.Equal(err), IsTrue)
+}
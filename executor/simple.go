@@ -14,6 +14,7 @@
 package executor
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -25,10 +26,13 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/rowlock"
 	"github.com/pingcap/tidb/util/sqlexec"
 )
 
@@ -70,6 +74,8 @@ func (e *SimpleExec) Next() (Row, error) {
 		err = e.executeDropUser(x)
 	case *ast.SetPwdStmt:
 		err = e.executeSetPwd(x)
+	case *ast.SetSessionStatesStmt:
+		err = e.executeSetSessionStates(x)
 	case *ast.KillStmt:
 		err = e.executeKillStmt(x)
 	case *ast.BinlogStmt:
@@ -77,6 +83,18 @@ func (e *SimpleExec) Next() (Row, error) {
 		return nil, nil
 	case *ast.DropStatsStmt:
 		err = e.executeDropStats(x)
+	case *ast.CreateStatisticsStmt:
+		err = e.executeCreateStatistics(x)
+	case *ast.DropStatisticsStmt:
+		err = e.executeDropStatistics(x)
+	case *ast.LoadStatsStmt:
+		err = e.executeLoadStats(x)
+	case *ast.LockStatsStmt:
+		err = e.executeLockStats(x)
+	case *ast.UnlockStatsStmt:
+		err = e.executeUnlockStats(x)
+	case *ast.PlanReplayerStmt:
+		err = e.executePlanReplayerLoad(x)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -126,8 +144,12 @@ func (e *SimpleExec) executeRollback(s *ast.RollbackStmt) error {
 	sessVars := e.ctx.GetSessionVars()
 	log.Infof("[%d] execute rollback statement", sessVars.ConnectionID)
 	sessVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
-	if e.ctx.Txn().Valid() {
-		return e.ctx.Txn().Rollback()
+	txn := e.ctx.Txn()
+	if sessVars.TxnCtx.ForUpdate {
+		rowlock.Release(txn)
+	}
+	if txn.Valid() {
+		return txn.Rollback()
 	}
 	return nil
 }
@@ -284,15 +306,14 @@ func (e *SimpleExec) executeSetPwd(s *ast.SetPwdStmt) error {
 	return errors.Trace(err)
 }
 
+// executeKillStmt kills the connection or its running query. The connection ID identifies the server, so KILL sent
+// to another server behind a load balancer fails instead of killing a wrong connection.
 func (e *SimpleExec) executeKillStmt(s *ast.KillStmt) error {
-	if s.TiDBExtension {
-		sm := e.ctx.GetSessionManager()
-		if sm == nil {
-			return nil
-		}
-		sm.Kill(s.ConnectionID, s.Query)
+	sm := e.ctx.GetSessionManager()
+	if sm == nil {
+		return nil
 	}
-	return nil
+	return errors.Trace(sm.Kill(s.ConnectionID, s.Query))
 }
 
 func (e *SimpleExec) executeFlush(s *ast.FlushStmt) error {
@@ -320,8 +341,108 @@ func (e *SimpleExec) executeDropStats(s *ast.DropStatsStmt) error {
 		if err != nil {
 			return errors.Trace(err)
 		}
-		return errors.Trace(h.Update(GetInfoSchema(e.ctx)))
+		return errors.Trace(h.Update(e.is))
 	}
 	h.DDLEventCh() <- &ddl.Event{Tp: model.ActionDropTable, TableInfo: s.Table.TableInfo}
 	return nil
 }
+
+func (e *SimpleExec) executeCreateStatistics(s *ast.CreateStatisticsStmt) error {
+	tblInfo := s.Table.TableInfo
+	stats := &statistics.ExtendedStats{Name: s.StatsName, ColIDs: make([]int64, 0, len(s.Columns))}
+	for _, colName := range s.Columns {
+		var col *model.ColumnInfo
+		for _, c := range tblInfo.Columns {
+			if c.Name.L == colName.Name.L {
+				col = c
+				break
+			}
+		}
+		if col == nil {
+			return plan.ErrUnknownColumn.GenByArgs(colName.Name.O, "statistics")
+		}
+		for _, id := range stats.ColIDs {
+			if id == col.ID {
+				return ErrStatsColumns
+			}
+		}
+		stats.ColIDs = append(stats.ColIDs, col.ID)
+	}
+	if len(stats.ColIDs) < 2 {
+		return ErrStatsColumns
+	}
+	created, err := statistics.CreateExtendedStats(e.ctx, tblInfo.ID, stats)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !created {
+		if s.IfNotExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrStatsExists.GenByArgs(s.StatsName))
+			return nil
+		}
+		return ErrStatsExists.GenByArgs(s.StatsName)
+	}
+	return errors.Trace(e.updateStats())
+}
+
+func (e *SimpleExec) executeDropStatistics(s *ast.DropStatisticsStmt) error {
+	dropped, err := statistics.DropExtendedStats(e.ctx, s.Table.TableInfo.ID, s.StatsName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !dropped {
+		if s.IfExists {
+			e.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrStatsNotExists.GenByArgs(s.StatsName))
+			return nil
+		}
+		return ErrStatsNotExists.GenByArgs(s.StatsName)
+	}
+	return errors.Trace(e.updateStats())
+}
+
+func (e *SimpleExec) executeLoadStats(s *ast.LoadStatsStmt) error {
+	jsonTbl := &statistics.JSONTable{}
+	err := json.Unmarshal([]byte(s.JSON), jsonTbl)
+	if err != nil {
+		return ErrStatsJSON.GenByArgs(err.Error())
+	}
+	tbl, err := e.is.TableByName(model.NewCIStr(jsonTbl.DatabaseName), model.NewCIStr(jsonTbl.TableName))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = statistics.LoadStatsFromJSON(e.ctx, tbl.Meta(), jsonTbl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(e.updateStats())
+}
+
+func (e *SimpleExec) executeLockStats(s *ast.LockStatsStmt) error {
+	for _, tbl := range s.Tables {
+		err := statistics.LockStats(e.ctx, tbl.TableInfo.ID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(e.updateStats())
+}
+
+func (e *SimpleExec) executeUnlockStats(s *ast.UnlockStatsStmt) error {
+	for _, tbl := range s.Tables {
+		err := statistics.UnlockStats(e.ctx, tbl.TableInfo.ID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(e.updateStats())
+}
+
+// updateStats loads the changed statistics at once when the stats lease is 0, otherwise they're loaded by the stats
+// worker of the domain.
+func (e *SimpleExec) updateStats() error {
+	h := sessionctx.GetDomain(e.ctx).StatsHandle()
+	if h.Lease <= 0 {
+		return errors.Trace(h.Update(e.is))
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -39,6 +40,8 @@ type SortExec struct {
 	fetched bool
 	err     error
 	schema  *expression.Schema
+
+	collators []collate.Collator
 }
 
 // Close implements the Executor Close interface.
@@ -92,6 +95,29 @@ func (e *SortExec) Less(i, j int) bool {
 	return false
 }
 
+// buildOrderByRow evaluates the order values of the row, the strings are replaced by their sort keys
+// of the collations, so they are compared as the collations.
+func (e *SortExec) buildOrderByRow(srcRow Row) (*orderByRow, error) {
+	if e.collators == nil {
+		e.collators = make([]collate.Collator, len(e.ByItems))
+		for i, byItem := range e.ByItems {
+			e.collators[i] = collate.GetCollatorByFieldType(byItem.Expr.GetType())
+		}
+	}
+	orderRow := &orderByRow{
+		row: srcRow,
+		key: make([]types.Datum, len(e.ByItems)),
+	}
+	for i, byItem := range e.ByItems {
+		v, err := byItem.Expr.Eval(srcRow)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		orderRow.key[i] = collate.KeyDatum(v, e.collators[i])
+	}
+	return orderRow, nil
+}
+
 // Next implements the Executor Next interface.
 func (e *SortExec) Next() (Row, error) {
 	if !e.fetched {
@@ -103,15 +129,9 @@ func (e *SortExec) Next() (Row, error) {
 			if srcRow == nil {
 				break
 			}
-			orderRow := &orderByRow{
-				row: srcRow,
-				key: make([]types.Datum, len(e.ByItems)),
-			}
-			for i, byItem := range e.ByItems {
-				orderRow.key[i], err = byItem.Expr.Eval(srcRow)
-				if err != nil {
-					return nil, errors.Trace(err)
-				}
+			orderRow, err := e.buildOrderByRow(srcRow)
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
 			e.Rows = append(e.Rows, orderRow)
 		}
@@ -202,15 +222,9 @@ func (e *TopNExec) Next() (Row, error) {
 				break
 			}
 			// build orderRow from srcRow.
-			orderRow := &orderByRow{
-				row: srcRow,
-				key: make([]types.Datum, len(e.ByItems)),
-			}
-			for i, byItem := range e.ByItems {
-				orderRow.key[i], err = byItem.Expr.Eval(srcRow)
-				if err != nil {
-					return nil, errors.Trace(err)
-				}
+			orderRow, err := e.buildOrderByRow(srcRow)
+			if err != nil {
+				return nil, errors.Trace(err)
 			}
 			if e.totalCount == e.heapSize {
 				// An equivalent of Push and Pop. We don't use the standard Push and Pop
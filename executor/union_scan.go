@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -91,10 +92,12 @@ type UnionScanExec struct {
 
 	dirty *dirtyTable
 	// usedIndex is the column offsets of the index which Src executor has used.
-	usedIndex  []int
-	desc       bool
-	conditions []expression.Expression
-	columns    []*model.ColumnInfo
+	usedIndex []int
+	// usedIndexDesc is whether the columns of usedIndex are descending index columns.
+	usedIndexDesc []bool
+	desc          bool
+	conditions    []expression.Expression
+	columns       []*model.ColumnInfo
 
 	// belowHandleIndex is the handle's position of the below scan plan.
 	belowHandleIndex int
@@ -224,14 +227,19 @@ func (us *UnionScanExec) pickRow(a, b Row) (Row, error) {
 
 func (us *UnionScanExec) compare(a, b Row) (int, error) {
 	sc := us.ctx.GetSessionVars().StmtCtx
-	for _, colOff := range us.usedIndex {
+	for i, colOff := range us.usedIndex {
 		aColumn := a[colOff]
 		bColumn := b[colOff]
-		cmp, err := aColumn.CompareDatum(sc, bColumn)
+		// The rows are in the order of the index, whose strings are sorted by their collations.
+		collator := collate.GetCollatorByFieldType(us.children[0].Schema().Columns[colOff].RetType)
+		cmp, err := collate.CompareDatum(sc, aColumn, bColumn, collator)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
 		if cmp != 0 {
+			if us.usedIndexDesc[i] {
+				return -cmp, nil
+			}
 			return cmp, nil
 		}
 	}
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"unsafe"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -113,35 +115,59 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		}
 	}
 
+	newHandle := h
+	affectedRows := uint64(1)
+	if onDup {
+		affectedRows = 2
+	}
 	if handleChanged {
-		_, err = t.AddRecord(ctx, newData)
+		// The old row is removed before the new row is added, or the new row conflicts with it on the unique
+		// indices, so the rows can be updated in the proper order like "update t set pk = pk + 1 order by pk desc".
+		err = t.RemoveRecord(ctx, h, oldData)
 		if err != nil {
 			return false, errors.Trace(err)
 		}
-		err = t.RemoveRecord(ctx, h, oldData)
+		newHandle, err = t.AddRecord(ctx, newData)
+		if err != nil {
+			// Put the old row back, the error may be ignored and the statement goes on.
+			if err1 := restoreRecord(ctx, t, oldData); err1 != nil {
+				return false, errors.Trace(err1)
+			}
+			return false, errors.Trace(err)
+		}
+		// AddRecord has counted the row as affected.
+		affectedRows--
 	} else {
 		// Update record to new value and update index.
 		err = t.UpdateRecord(ctx, h, oldData, newData, modified)
-	}
-	if err != nil {
-		return false, errors.Trace(err)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
 	}
 
 	dirtyDB := getDirtyDB(ctx)
 	tid := t.Meta().ID
 	dirtyDB.deleteRow(tid, h)
-	dirtyDB.addRow(tid, h, newData)
+	dirtyDB.addRow(tid, newHandle, newData)
 
-	if onDup {
-		sc.AddAffectedRows(2)
-	} else {
-		sc.AddAffectedRows(1)
-	}
+	sc.AddAffectedRows(affectedRows)
 
 	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.Meta().ID, 0, 1)
 	return true, nil
 }
 
+// restoreRecord adds the removed row back, the row isn't counted as an affected row or a changed row of the table.
+func restoreRecord(ctx context.Context, t table.Table, oldData []types.Datum) error {
+	sc := ctx.GetSessionVars().StmtCtx
+	affectedRows := sc.AffectedRows()
+	if _, err := t.AddRecord(ctx, oldData); err != nil {
+		return errors.Trace(err)
+	}
+	sc.SetAffectedRows(affectedRows)
+	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.Meta().ID, -1, -1)
+	return nil
+}
+
 // DeleteExec represents a delete executor.
 // See https://dev.mysql.com/doc/refman/5.7/en/delete.html
 type DeleteExec struct {
@@ -153,6 +179,9 @@ type DeleteExec struct {
 	IsMultiTable bool
 	tblID2Table  map[int64]table.Table
 
+	// returning collects the RETURNING list of the deleted rows, it's nil if there isn't a RETURNING clause.
+	returning *ReturningExec
+
 	finished bool
 }
 
@@ -208,6 +237,10 @@ func (e *DeleteExec) deleteMultiTables() error {
 				if tblRowMap[id] == nil {
 					tblRowMap[id] = make(map[int64][]types.Datum)
 				}
+				if joinedRow[col.Index].IsNull() {
+					// The table is the inner side of an outer join and there isn't a matched row.
+					continue
+				}
 				offset := getTableOffset(e.SelectExec.Schema(), col)
 				end := offset + len(tbl.Cols())
 				data := joinedRow[offset:end]
@@ -275,6 +308,11 @@ func (e *DeleteExec) deleteSingleTable() error {
 		if err != nil {
 			return errors.Trace(err)
 		}
+		if e.returning != nil {
+			if err = e.returning.appendRow(row); err != nil {
+				return errors.Trace(err)
+			}
+		}
 		rowCount++
 	}
 	return nil
@@ -651,6 +689,12 @@ type InsertValues struct {
 
 	GenColumns []*ast.ColumnName
 	GenExprs   []expression.Expression
+
+	// SelectFromTable indicates the SelectExec reads the inserted table.
+	SelectFromTable bool
+
+	// returning collects the RETURNING list of the written rows, it's nil if there isn't a RETURNING clause.
+	returning *ReturningExec
 }
 
 // InsertExec represents an insert executor.
@@ -662,6 +706,8 @@ type InsertExec struct {
 	Priority  mysql.PriorityEnum
 	IgnoreErr bool
 
+	// rowCount is the number of rows written in the current transaction, it's used by the BatchInsert mode.
+	rowCount int
 	finished bool
 }
 
@@ -674,6 +720,13 @@ func (e *InsertExec) Schema() *expression.Schema {
 // This will be used when tidb_batch_insert is set to ON.
 var BatchInsertSize = 20000
 
+// InsertSelectBatchSize is the max number of rows inserted in a batch for `insert|replace into ... select ...`.
+var InsertSelectBatchSize = 1024
+
+// InsertSelectBatchMemory is the max memory in bytes used by the rows inserted in a batch for
+// `insert|replace into ... select ...`.
+var InsertSelectBatchMemory int64 = 32 << 20
+
 // BatchDeleteSize is the batch size of auto-splitted delete data.
 // This will be used when tidb_batch_delete is set to ON.
 var BatchDeleteSize = 20000
@@ -688,29 +741,46 @@ func (e *InsertExec) Next() (Row, error) {
 		return nil, errors.Trace(err)
 	}
 
-	var rows [][]types.Datum
 	if e.SelectExec != nil {
-		rows, err = e.getRowsSelect(cols, e.IgnoreErr)
+		if err = e.insertRowsFromSelect(cols, e.IgnoreErr, e.insertRows); err != nil {
+			return nil, errors.Trace(err)
+		}
 	} else {
-		rows, err = e.getRows(cols, e.IgnoreErr)
+		rows, err := e.getRows(cols, e.IgnoreErr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = e.insertRows(rows); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
-	if err != nil {
-		return nil, errors.Trace(err)
+
+	if e.lastInsertID != 0 {
+		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
 	}
+	e.finished = true
+	return nil, nil
+}
 
+func (e *InsertExec) insertRows(rows [][]types.Datum) error {
 	// If tidb_batch_insert is ON and not in a transaction, we could use BatchInsert mode.
 	batchInsert := e.ctx.GetSessionVars().BatchInsert && !e.ctx.GetSessionVars().InTxn()
 
 	txn := e.ctx.Txn()
-	rowCount := 0
+	if len(e.OnDuplicate) > 0 || e.IgnoreErr {
+		// Otherwise the keys are presumed not to exist, and they are checked together when committing.
+		if err := prefetchUniqueKeys(e.ctx, e.Table, rows); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	for _, row := range rows {
-		if batchInsert && rowCount >= BatchInsertSize {
+		if batchInsert && e.rowCount >= BatchInsertSize {
 			if err := e.ctx.NewTxn(); err != nil {
 				// We should return a special error for batch insert.
-				return nil, ErrBatchInsertFail.Gen("BatchInsert failed with error: %v", err)
+				return ErrBatchInsertFail.Gen("BatchInsert failed with error: %v", err)
 			}
 			txn = e.ctx.Txn()
-			rowCount = 0
+			e.rowCount = 0
 		}
 		if len(e.OnDuplicate) == 0 && !e.IgnoreErr {
 			txn.SetOption(kv.PresumeKeyNotExists, nil)
@@ -719,7 +789,12 @@ func (e *InsertExec) Next() (Row, error) {
 		txn.DelOption(kv.PresumeKeyNotExists)
 		if err == nil {
 			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
-			rowCount++
+			if e.returning != nil {
+				if err = e.returning.appendRow(row); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			e.rowCount++
 			continue
 		}
 
@@ -733,20 +808,66 @@ func (e *InsertExec) Next() (Row, error) {
 			}
 			if len(e.OnDuplicate) > 0 {
 				if err = e.onDuplicateUpdate(row, h, e.OnDuplicate); err != nil {
-					return nil, errors.Trace(err)
+					return errors.Trace(err)
 				}
-				rowCount++
+				e.rowCount++
 				continue
 			}
 		}
-		return nil, errors.Trace(err)
+		return errors.Trace(err)
 	}
+	return nil
+}
 
-	if e.lastInsertID != 0 {
-		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
+// prefetchUniqueKeys reads the keys of the primary key and the unique indices of the rows, which are checked for the
+// duplicate keys when the rows are added, so the keys aren't read one by one. The keys are read by a BatchGet for
+// the primary key and one for each unique index.
+func prefetchUniqueKeys(ctx context.Context, t table.Table, rows [][]types.Datum) error {
+	if len(rows) <= 1 || ctx.GetSessionVars().SkipConstraintCheck {
+		return nil
 	}
-	e.finished = true
-	return nil, nil
+	txn := ctx.Txn()
+	if t.Meta().PKIsHandle {
+		for _, col := range t.Cols() {
+			if !col.IsPKHandleColumn(t.Meta()) {
+				continue
+			}
+			keys := make([]kv.Key, 0, len(rows))
+			for _, row := range rows {
+				if !row[col.Offset].IsNull() {
+					keys = append(keys, t.RecordKey(row[col.Offset].GetInt64()))
+				}
+			}
+			if err := txn.BatchPrefetch(keys); err != nil {
+				return errors.Trace(err)
+			}
+			break
+		}
+	}
+	for _, idx := range t.WritableIndices() {
+		if !idx.Meta().Unique && !idx.Meta().Primary {
+			continue
+		}
+		keys := make([]kv.Key, 0, len(rows))
+		for _, row := range rows {
+			vals, err := idx.FetchValues(row)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			key, distinct, err := idx.GenIndexKey(vals, 0)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			// The keys of the unique index which has NULL values are not distinct, they never conflict.
+			if distinct {
+				keys = append(keys, key)
+			}
+		}
+		if err := txn.BatchPrefetch(keys); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
 }
 
 // Close implements the Executor Close interface.
@@ -887,36 +1008,75 @@ func (e *InsertValues) getRow(cols []*table.Column, list []expression.Expression
 	vals := make([]types.Datum, len(list))
 	for i, expr := range list {
 		val, err := expr.Eval(nil)
-		vals[i] = val
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		vals[i] = assignedValue(&cols[i].FieldType, expr, val)
 	}
 	return e.fillRowData(cols, vals, ignoreErr)
 }
 
-func (e *InsertValues) getRowsSelect(cols []*table.Column, ignoreErr bool) ([][]types.Datum, error) {
-	// process `insert|replace into ... select ... from ...`
+// assignedValue returns the value of expr assigned to a column of the field type ft. The string literals in the
+// connection charset are decoded to UTF-8 by the parser, a binary column gets their bytes in the connection charset.
+func assignedValue(ft *types.FieldType, expr expression.Expression, val types.Datum) types.Datum {
+	if val.Kind() != types.KindString && val.Kind() != types.KindBytes {
+		return val
+	}
+	cs := expr.GetType().Charset
+	if !types.IsBinaryStr(ft) || !charset.NeedConvert(cs) {
+		return val
+	}
+	b, _ := charset.EncodeString(cs, val.GetBytes())
+	val.SetBytes(b)
+	return val
+}
+
+// insertRowsFromSelect processes `insert|replace into ... select ... from ...`. The rows of the select executor are
+// inserted by insertRows in batches, a batch is inserted when it has InsertSelectBatchSize rows or its rows use
+// InsertSelectBatchMemory bytes of memory, so the result of the select isn't materialized. But if the select reads
+// the inserted table, all of its rows are fetched before inserting, the same as MySQL, otherwise it may read the
+// rows written by the statement itself.
+func (e *InsertValues) insertRowsFromSelect(cols []*table.Column, ignoreErr bool, insertRows func(rows [][]types.Datum) error) error {
 	if e.SelectExec.Schema().Len() != len(cols) {
-		return nil, ErrWrongValueCountOnRow.GenByArgs(1)
+		return ErrWrongValueCountOnRow.GenByArgs(1)
 	}
-	var rows [][]types.Datum
+	var (
+		rows     [][]types.Datum
+		memUsage int64
+	)
 	for {
 		innerRow, err := e.SelectExec.Next()
 		if err != nil {
-			return nil, errors.Trace(err)
+			return errors.Trace(err)
 		}
 		if innerRow == nil {
 			break
 		}
-		e.currRow = int64(len(rows))
 		row, err := e.fillRowData(cols, innerRow, ignoreErr)
 		if err != nil {
-			return nil, errors.Trace(err)
+			return errors.Trace(err)
 		}
+		e.currRow++
 		rows = append(rows, row)
+		memUsage += rowMemUsage(row)
+		if e.SelectFromTable || (len(rows) < InsertSelectBatchSize && memUsage < InsertSelectBatchMemory) {
+			continue
+		}
+		if err = insertRows(rows); err != nil {
+			return errors.Trace(err)
+		}
+		rows, memUsage = rows[:0], 0
 	}
-	return rows, nil
+	return errors.Trace(insertRows(rows))
+}
+
+// rowMemUsage estimates the memory used by a row.
+func rowMemUsage(row []types.Datum) int64 {
+	usage := int64(len(row)) * int64(unsafe.Sizeof(types.Datum{}))
+	for i := range row {
+		usage += int64(cap(row[i].GetBytes()))
+	}
+	return usage
 }
 
 func (e *InsertValues) fillRowData(cols []*table.Column, vals []types.Datum, ignoreErr bool) ([]types.Datum, error) {
@@ -981,7 +1141,7 @@ func (e *InsertValues) initDefaultValues(row []types.Datum, hasValue []bool, ign
 		}
 		if needDefaultValue {
 			var err error
-			row[i], err = table.GetColDefaultValue(e.ctx, c.ToInfo())
+			row[i], err = c.GetDefaultValue(e.ctx)
 			if e.filterErr(err, ignoreErr) != nil {
 				return errors.Trace(err)
 			}
@@ -1037,8 +1197,8 @@ func (e *InsertValues) adjustAutoIncrementDatum(row []types.Datum, i int, c *tab
 		if e.filterErr(errors.Trace(err), ignoreErr) != nil {
 			return errors.Trace(err)
 		}
-		// It's compatible with mysql. So it sets last insert id to the first row.
-		if e.currRow == 0 {
+		// It's compatible with mysql. So it sets last insert id to the first generated id of the statement.
+		if e.lastInsertID == 0 {
 			e.lastInsertID = uint64(recordID)
 		}
 	}
@@ -1068,12 +1228,15 @@ func (e *InsertExec) onDuplicateUpdate(row []types.Datum, h int64, cols []*expre
 		if err1 != nil {
 			return errors.Trace(err1)
 		}
-		newData[col.Col.Index] = val
+		newData[col.Col.Index] = assignedValue(col.Col.RetType, col.Expr, val)
 		assignFlag[col.Col.Index] = true
 	}
 	if _, err = updateRecord(e.ctx, h, data, newData, assignFlag, e.Table, true); err != nil {
 		return errors.Trace(err)
 	}
+	if e.returning != nil {
+		return errors.Trace(e.returning.appendRow(newData))
+	}
 	return nil
 }
 
@@ -1127,16 +1290,28 @@ func (e *ReplaceExec) Next() (Row, error) {
 		return nil, errors.Trace(err)
 	}
 
-	var rows [][]types.Datum
 	if e.SelectExec != nil {
-		rows, err = e.getRowsSelect(cols, false)
+		if err = e.insertRowsFromSelect(cols, false, e.replaceRows); err != nil {
+			return nil, errors.Trace(err)
+		}
 	} else {
-		rows, err = e.getRows(cols, false)
+		rows, err := e.getRows(cols, false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err = e.replaceRows(rows); err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
-	if err != nil {
-		return nil, errors.Trace(err)
+
+	if e.lastInsertID != 0 {
+		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
 	}
+	e.finished = true
+	return nil, nil
+}
 
+func (e *ReplaceExec) replaceRows(rows [][]types.Datum) error {
 	/*
 	 * MySQL uses the following algorithm for REPLACE (and LOAD DATA ... REPLACE):
 	 *  1. Try to insert the new row into the table
@@ -1149,6 +1324,9 @@ func (e *ReplaceExec) Next() (Row, error) {
 	 * because in this case, one row was inserted after the duplicate was deleted.
 	 * See http://dev.mysql.com/doc/refman/5.7/en/mysql-affected-rows.html
 	 */
+	if err := prefetchUniqueKeys(e.ctx, e.Table, rows); err != nil {
+		return errors.Trace(err)
+	}
 	idx := 0
 	rowsLen := len(rows)
 	sc := e.ctx.GetSessionVars().StmtCtx
@@ -1160,40 +1338,45 @@ func (e *ReplaceExec) Next() (Row, error) {
 		h, err1 := e.Table.AddRecord(e.ctx, row)
 		if err1 == nil {
 			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
+			if e.returning != nil {
+				if err1 = e.returning.appendRow(row); err1 != nil {
+					return errors.Trace(err1)
+				}
+			}
 			idx++
 			continue
 		}
 		if err1 != nil && !kv.ErrKeyExists.Equal(err1) {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		oldRow, err1 := e.Table.Row(e.ctx, h)
 		if err1 != nil {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		rowUnchanged, err1 := types.EqualDatums(sc, oldRow, row)
 		if err1 != nil {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		if rowUnchanged {
 			// If row unchanged, we do not need to do insert.
 			e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+			if e.returning != nil {
+				if err1 = e.returning.appendRow(row); err1 != nil {
+					return errors.Trace(err1)
+				}
+			}
 			idx++
 			continue
 		}
 		// Remove current row and try replace again.
 		err1 = e.Table.RemoveRecord(e.ctx, h, oldRow)
 		if err1 != nil {
-			return nil, errors.Trace(err1)
+			return errors.Trace(err1)
 		}
 		getDirtyDB(e.ctx).deleteRow(e.Table.Meta().ID, h)
 		e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
 	}
-
-	if e.lastInsertID != 0 {
-		e.ctx.GetSessionVars().SetLastInsertID(e.lastInsertID)
-	}
-	e.finished = true
-	return nil, nil
+	return nil
 }
 
 // UpdateExec represents a new update executor.
@@ -1204,6 +1387,9 @@ type UpdateExec struct {
 	OrderedList []*expression.Assignment
 	IgnoreErr   bool
 
+	// returning collects the RETURNING list of the updated rows, it's nil if there isn't a RETURNING clause.
+	returning *ReturningExec
+
 	// updatedRowKeys is a map for unique (Table, handle) pair.
 	updatedRowKeys map[int64]map[int64]struct{}
 	tblID2table    map[int64]table.Table
@@ -1244,20 +1430,31 @@ func (e *UpdateExec) Next() (Row, error) {
 		for _, col := range cols {
 			offset := getTableOffset(e.SelectExec.Schema(), col)
 			end := offset + len(tbl.WritableCols())
+			flags := assignFlag[offset:end]
+			if !hasAssignment(flags) {
+				// The table isn't in the set list, it's only read.
+				continue
+			}
+			if row[col.Index].IsNull() {
+				// The table is the inner side of an outer join and there isn't a matched row.
+				continue
+			}
 			handle := row[col.Index].GetInt64()
 			oldData := row[offset:end]
 			newTableData := newData[offset:end]
-			flags := assignFlag[offset:end]
 			_, ok := e.updatedRowKeys[id][handle]
 			if ok {
 				// Each matched row is updated once, even if it matches the conditions multiple times.
 				continue
 			}
 			// Update row
-			changed, err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, false)
+			_, err1 := updateRecord(e.ctx, handle, oldData, newTableData, flags, tbl, false)
 			if err1 == nil {
-				if changed {
-					e.updatedRowKeys[id][handle] = struct{}{}
+				e.updatedRowKeys[id][handle] = struct{}{}
+				if e.returning != nil {
+					if err1 = e.returning.appendRow(newData); err1 != nil {
+						return nil, errors.Trace(err1)
+					}
 				}
 				continue
 			}
@@ -1284,6 +1481,15 @@ func getUpdateColumns(assignList []*expression.Assignment, schemaLen int) ([]boo
 	return assignFlag, nil
 }
 
+func hasAssignment(flags []bool) bool {
+	for _, flag := range flags {
+		if flag {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *UpdateExec) fetchRows() error {
 	for {
 		row, err := e.SelectExec.Next()
@@ -1300,7 +1506,7 @@ func (e *UpdateExec) fetchRows() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
-			newRowData[assign.Col.Index] = val
+			newRowData[assign.Col.Index] = assignedValue(assign.Col.RetType, assign.Expr, val)
 		}
 		e.rows = append(e.rows, row)
 		e.newRowsData = append(e.newRowsData, newRowData)
@@ -1325,3 +1531,57 @@ func (e *UpdateExec) Close() error {
 func (e *UpdateExec) Open() error {
 	return e.SelectExec.Open()
 }
+
+// ReturningExec represents a write executor with a RETURNING clause, it returns the RETURNING list of the rows
+// written by its child.
+type ReturningExec struct {
+	baseExecutor
+
+	Exprs []expression.Expression
+
+	rows   []Row
+	cursor int
+}
+
+// Open implements the Executor Open interface.
+// The write executor is executed to the end here, because the statement may be committed as soon as the result
+// set is returned.
+func (e *ReturningExec) Open() error {
+	e.rows, e.cursor = nil, 0
+	if err := e.baseExecutor.Open(); err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+	}
+}
+
+// Next implements the Executor Next interface.
+func (e *ReturningExec) Next() (Row, error) {
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+// appendRow evaluates the RETURNING list on a written row.
+func (e *ReturningExec) appendRow(data []types.Datum) error {
+	row := make(Row, 0, len(e.Exprs))
+	for _, expr := range e.Exprs {
+		d, err := expr.Eval(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		row = append(row, d)
+	}
+	e.rows = append(e.rows, row)
+	return nil
+}
@@ -24,8 +24,11 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -326,6 +329,42 @@ func (s *testSuite) TestInsertAutoInc(c *C) {
 	r.Check(testkit.Rows(rowStr4, rowStr1, rowStr2, rowStr3, rowStr5, rowStr6, rowStr7, rowStr8))
 }
 
+func (s *testSuite) TestInsertLastInsertID(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (id int auto_increment primary key, c int)")
+
+	// The first generated id of a multi-row insert is the last insert id, even if it's not the first row.
+	tk.MustExec("insert into t values (10, 1), (null, 2), (null, 3)")
+	tk.CheckExecResult(3, 11)
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("11"))
+	tk.MustExec("insert into t(c) values (4), (5)")
+	tk.CheckExecResult(2, 13)
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("13"))
+
+	tk.MustExec("create table t1 (c int)")
+	tk.MustExec("insert into t1 values (6), (7)")
+	tk.MustExec("insert into t(c) select c from t1")
+	tk.CheckExecResult(2, 15)
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("15"))
+
+	// An explicit id doesn't change the last insert id.
+	tk.MustExec("insert into t values (100, 8)")
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("15"))
+
+	// LAST_INSERT_ID(expr) sets the last insert id returned by the next LAST_INSERT_ID() and the OK packet.
+	tk.MustExec("update t set c = last_insert_id(c + 100) where id = 100")
+	tk.CheckExecResult(1, 108)
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("108"))
+	tk.MustQuery("select last_insert_id(3), last_insert_id()").Check(testkit.Rows("3 108"))
+	tk.MustQuery("select last_insert_id()").Check(testkit.Rows("3"))
+}
+
 func (s *testSuite) TestInsertIgnore(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -578,10 +617,18 @@ func (s *testSuite) TestUpdate(c *C) {
 	tk.MustExec("create table t(a bigint, primary key (a));")
 	tk.MustExec("insert into t values (1)")
 	tk.MustExec("insert into t values (2)")
+	tk.MustExec("begin")
 	_, err = tk.Exec("update ignore t set a = 1 where a = 2;")
 	c.Assert(err, IsNil)
+	// The row put back isn't counted.
+	c.Assert(tk.Se.AffectedRows(), Equals, uint64(0))
 	r = tk.MustQuery("SHOW WARNINGS;")
 	r.Check(testkit.Rows("Warning 1062 Duplicate entry '1' for key 'PRIMARY'"))
+	deltas := tk.Se.GetSessionVars().TxnCtx.TableDeltaMap
+	for _, delta := range deltas {
+		c.Assert(delta, Equals, variable.TableDelta{})
+	}
+	tk.MustExec("commit")
 	tk.MustQuery("select * from t").Check(testkit.Rows("1", "2"))
 
 	// test update ignore for unique key
@@ -594,6 +641,33 @@ func (s *testSuite) TestUpdate(c *C) {
 	r = tk.MustQuery("SHOW WARNINGS;")
 	r.Check(testkit.Rows("Warning 1062 key already exist"))
 	tk.MustQuery("select * from t").Check(testkit.Rows("1", "2"))
+
+	// The rows are updated in the order of ORDER BY, the ones after LIMIT are not updated.
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b int, unique index ia(a))")
+	tk.MustExec("insert into t values (1, 5, 0), (2, 4, 0), (3, 3, 0), (4, 2, 0), (5, 1, 0)")
+	tk.MustExec("update t set id = id + 1 order by id desc")
+	tk.CheckExecResult(5, 0)
+	tk.MustExec("update t set a = a + 1 order by a desc")
+	tk.CheckExecResult(5, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 6 0", "3 5 0", "4 4 0", "5 3 0", "6 2 0"))
+	_, err = tk.Exec("update t set id = id + 1")
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue)
+	tk.MustExec("update t set b = 1 where b = 0 order by a limit 2")
+	tk.CheckExecResult(2, 0)
+	tk.MustExec("update t set b = 2 where b = 0 order by a limit 1")
+	tk.CheckExecResult(1, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 6 0", "3 5 0", "4 4 2", "5 3 1", "6 2 1"))
+	tk.MustExec("update t set b = 3 order by id limit 0")
+	tk.CheckExecResult(0, 0)
+
+	// ORDER BY and LIMIT can't be used by the multiple-table UPDATE.
+	_, err = tk.Exec("update t, t t1 set t.b = 1 where t.id = t1.id order by t.id limit 1")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("update t join t t1 on t.id = t1.id set t.b = 1 order by t.id")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWrongUsage), IsTrue)
+	_, err = tk.Exec("update t join t t1 on t.id = t1.id set t.b = 1 limit 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWrongUsage), IsTrue)
 }
 
 func (s *testSuite) fillMultiTableForUpdate(tk *testkit.TestKit) {
@@ -666,6 +740,39 @@ func (s *testSuite) TestMultipleTableUpdate(c *C) {
 	tk.MustQuery("select * from t").Check(testkit.Rows("2 1", "3 2", "4 3"))
 	tk.MustExec("update t m, t n set n.a = n.a - 1, n.b = n.b + 1")
 	tk.MustQuery("select * from t").Check(testkit.Rows("1 2", "2 3", "3 4"))
+
+	// Each matched row is updated once, and only the tables in the set list are updated.
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (id int, a int)")
+	tk.MustExec("create table t2 (id int, b int)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("insert into t2 values (1, 10), (1, 10), (2, 20)")
+	tk.MustExec("update t1, t2 set t1.a = t2.b where t1.id = t2.id")
+	tk.CheckExecResult(2, 0)
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 10", "2 20", "3 3"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 10", "1 10", "2 20"))
+
+	// The missing rows of the inner side of an outer join are not updated.
+	tk.MustExec("update t1 left join t2 on t1.id = t2.id set t1.a = 0, t2.b = 0")
+	tk.CheckExecResult(6, 0)
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 0", "2 0", "3 0"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 0", "1 0", "2 0"))
+
+	_, err := tk.Exec("update t1, (select * from t2) as x set x.b = 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUpdatableTable), IsTrue)
+
+	// The tables of the same name in different databases are told apart.
+	tk.MustExec("drop database if exists multi_update")
+	tk.MustExec("create database multi_update")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int as (a + 1))")
+	tk.MustExec("create table multi_update.t (a int, b int as (a + 1))")
+	tk.MustExec("insert into t (a) values (1)")
+	tk.MustExec("insert into multi_update.t (a) values (1)")
+	tk.MustExec("update test.t, multi_update.t set test.t.a = 10")
+	tk.MustQuery("select * from t").Check(testkit.Rows("10 11"))
+	tk.MustQuery("select * from multi_update.t").Check(testkit.Rows("1 2"))
+	tk.MustExec("drop database multi_update")
 }
 
 func (s *testSuite) TestDelete(c *C) {
@@ -698,6 +805,14 @@ func (s *testSuite) TestDelete(c *C) {
 
 	tk.MustExec(`delete from delete_test ;`)
 	tk.CheckExecResult(1, 0)
+
+	// The rows are deleted in the order of ORDER BY, the ones after LIMIT are kept.
+	tk.MustExec("insert into delete_test values (1, 'a'), (2, 'c'), (3, 'b'), (4, 'c')")
+	tk.MustExec("delete from delete_test order by name desc, id limit 1")
+	tk.CheckExecResult(1, 0)
+	tk.MustExec("delete from delete_test where id > 1 order by name limit 1")
+	tk.CheckExecResult(1, 0)
+	tk.MustQuery("select * from delete_test").Check(testkit.Rows("1 a", "4 c"))
 }
 
 func (s *testSuite) fillDataMultiTable(tk *testkit.TestKit) {
@@ -731,6 +846,17 @@ func (s *testSuite) TestMultiTableDelete(c *C) {
 	// Select data
 	r := tk.MustQuery("select * from t3")
 	c.Assert(r.Rows(), HasLen, 3)
+
+	// The missing rows of the inner side of an outer join are not deleted.
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (id int)")
+	tk.MustExec("create table t2 (id int)")
+	tk.MustExec("insert into t1 values (1), (2), (3)")
+	tk.MustExec("insert into t2 values (1), (1), (4)")
+	tk.MustExec("delete t1, t2 from t1 left join t2 on t1.id = t2.id where t1.id < 3")
+	tk.CheckExecResult(4, 0)
+	tk.MustQuery("select * from t1").Check(testkit.Rows("3"))
+	tk.MustQuery("select * from t2").Check(testkit.Rows("4"))
 }
 
 func (s *testSuite) TestQualifiedDelete(c *C) {
@@ -1193,3 +1319,116 @@ func (s *testSuite) TestIssue4067(c *C) {
 	tk.MustExec("delete from t1 where id in (select id from t2)")
 	tk.MustQuery("select * from t1").Check(nil)
 }
+
+func (s *testSuite) TestReturning(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(id int primary key auto_increment, a int, b varchar(10))")
+
+	tk.MustQuery("insert into t(a, b) values (1, 'a'), (2, 'b') returning *").Check(testkit.Rows("1 1 a", "2 2 b"))
+	tk.MustQuery("insert into t set a = 3 returning id, a + 1 as c").Check(testkit.Rows("3 4"))
+	tk.MustQuery("insert into t(a) select a from t where id = 1 returning id, b").Check(testkit.Rows("4 <nil>"))
+	tk.MustQuery("insert into t values (1, 10, 'x') on duplicate key update a = values(a) returning t.id, a, b").Check(testkit.Rows("1 10 a"))
+	tk.MustQuery("insert ignore into t values (1, 11, 'x') returning id").Check(nil)
+	tk.MustQuery("replace into t values (2, 20, 'c'), (5, 5, 'e') returning *").Check(testkit.Rows("2 20 c", "5 5 e"))
+	tk.MustQuery("update t set a = a * 2 where id > 3 order by id returning id, a").Check(testkit.Rows("4 2", "5 10"))
+	tk.MustQuery("update t set a = a where id = 100 returning id").Check(nil)
+	tk.MustQuery("delete from t where id < 3 returning concat(id, b)").Check(testkit.Rows("1a", "2c"))
+	tk.MustQuery("select * from t").Check(testkit.Rows("3 3 <nil>", "4 2 <nil>", "5 10 e"))
+
+	// The result fields are named like the select fields.
+	rs, err := tk.Exec("delete from t where id = 3 returning id as c, a, b is null")
+	c.Assert(err, IsNil)
+	fields, err := rs.Fields()
+	c.Assert(err, IsNil)
+	c.Assert(fields, HasLen, 3)
+	c.Assert(fields[0].Column.Name.O, Equals, "c")
+	c.Assert(fields[1].Column.Name.O, Equals, "a")
+	c.Assert(fields[2].Column.Name.O, Equals, "b is null")
+	c.Assert(rs.Close(), IsNil)
+	tk.MustQuery("select id from t").Check(testkit.Rows("4", "5"))
+
+	// The rows are written and committed even if the result set isn't read.
+	rs, err = tk.Exec("update t set b = 'f' where id = 4 returning id")
+	c.Assert(err, IsNil)
+	c.Assert(rs.Close(), IsNil)
+	tk.MustQuery("select b from t").Check(testkit.Rows("f", "e"))
+
+	_, err = tk.Exec("delete from t returning (select 1 from t as t1 where t1.id = t.id)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrReturningSubquery), IsTrue)
+	_, err = tk.Exec("update t set a = 1 returning count(*)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("insert into t(a) values (1) returning c")
+	c.Assert(err, NotNil)
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("2"))
+}
+
+func (s *testSuite) TestInsertSelectBatch(c *C) {
+	originSize, originMemory := executor.InsertSelectBatchSize, executor.InsertSelectBatchMemory
+	defer func() {
+		executor.InsertSelectBatchSize, executor.InsertSelectBatchMemory = originSize, originMemory
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	executor.InsertSelectBatchSize = 2
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (id int primary key, a varchar(10))")
+	tk.MustExec("create table t2 (id int primary key, a varchar(10))")
+	tk.MustExec("insert into t1 values (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e')")
+
+	tk.MustExec("insert into t2 select * from t1 where id < 4")
+	tk.CheckExecResult(3, 0)
+	tk.MustExec("insert into t2 select id, upper(a) from t1 on duplicate key update a = values(a)")
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 A", "2 B", "3 C", "4 D", "5 E"))
+	tk.MustExec("replace into t2 select id, concat(a, a) from t1 where id > 2")
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 A", "2 B", "3 cc", "4 dd", "5 ee"))
+
+	// The batch is inserted once its rows use too much memory.
+	executor.InsertSelectBatchSize, executor.InsertSelectBatchMemory = 1024, 1
+	tk.MustExec("delete from t2")
+	tk.MustExec("insert into t2 select * from t1")
+	tk.CheckExecResult(5, 0)
+	tk.MustQuery("select * from t2").Check(testkit.Rows("1 a", "2 b", "3 c", "4 d", "5 e"))
+
+	// The rows of the inserted table are fetched before inserting, even if they are changed in the transaction.
+	executor.InsertSelectBatchSize = 2
+	tk.MustExec("begin")
+	tk.MustExec("replace into t1 values (5, 'f')")
+	tk.MustExec("replace into t1 select id + 1, a from t1")
+	tk.MustQuery("select * from t1").Check(testkit.Rows("1 a", "2 a", "3 b", "4 c", "5 d", "6 f"))
+	tk.MustExec("commit")
+}
+
+func (s *testSuite) TestInsertCheckUniqueKeysInBatch(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, a int, b varchar(10), unique index ua(a), unique index ub(b))")
+	tk.MustExec("insert into t values (1, 1, 'a'), (2, 2, 'b')")
+
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (3, 3, 'c')")
+	// The rows conflict with the committed rows, the rows of the transaction and the rows before them.
+	tk.MustExec("insert ignore into t values (1, 10, 'x'), (4, 3, 'y'), (5, 5, 'b'), (6, null, null), (7, null, null), (6, 8, 'z'), (8, 8, 'w')")
+	tk.CheckExecResult(3, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1 a", "2 2 b", "3 3 c", "6 <nil> <nil>", "7 <nil> <nil>", "8 8 w"))
+	tk.MustExec("insert into t values (9, 1, 'd'), (10, 10, 'c'), (11, 11, 'e') on duplicate key update b = concat(t.b, values(b))")
+	tk.CheckExecResult(5, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("1 1 ad", "2 2 b", "3 3 cc", "6 <nil> <nil>", "7 <nil> <nil>", "8 8 w", "11 11 e"))
+	tk.MustExec("replace into t values (2, 20, 'f'), (12, 8, 'ad'), (13, 13, 'g')")
+	tk.CheckExecResult(6, 0)
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 20 f", "3 3 cc", "6 <nil> <nil>", "7 <nil> <nil>", "11 11 e", "12 8 ad", "13 13 g"))
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 20 f", "3 3 cc", "6 <nil> <nil>", "7 <nil> <nil>", "11 11 e", "12 8 ad", "13 13 g"))
+}
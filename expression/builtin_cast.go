@@ -687,7 +687,12 @@ func (b *builtinCastRealAsStringSig) evalString(row []types.Datum) (res string,
 	if isNull || err != nil {
 		return res, isNull, errors.Trace(err)
 	}
-	res, err = types.ProduceStrWithSpecifiedTp(strconv.FormatFloat(val, 'f', -1, 64), b.tp, sc)
+	bitSize := 64
+	if b.args[0].GetType().Tp == mysql.TypeFloat {
+		// The FLOAT is formatted in the digits of a single precision float.
+		bitSize = 32
+	}
+	res, err = types.ProduceStrWithSpecifiedTp(types.FormatFloat(val, bitSize), b.tp, sc)
 	return res, isNull, errors.Trace(err)
 }
 
@@ -1354,6 +1359,13 @@ func WrapWithCastAsString(expr Expression, ctx context.Context) Expression {
 	tp := types.NewFieldType(mysql.TypeVarString)
 	tp.Charset, tp.Collate = charset.CharsetUTF8, charset.CollationUTF8
 	tp.Flen, tp.Decimal = expr.GetType().Flen, types.UnspecifiedLength
+	// The text of a float can be longer than its display width, e.g. 2.2250738585072014e-308 of a DOUBLE.
+	switch expr.GetType().Tp {
+	case mysql.TypeFloat:
+		tp.Flen = types.MaxFloatStrLen
+	case mysql.TypeDouble:
+		tp.Flen = types.MaxDoubleStrLen
+	}
 	return buildCastFunction(expr, tp, ctx)
 }
 
@@ -22,6 +22,8 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
 	"github.com/pingcap/tipb/go-tipb"
@@ -508,32 +510,6 @@ type compareFunctionClass struct {
 	op opcode.Op
 }
 
-// getCmpType gets the ClassType that the two args will be treated as when comparing.
-func getCmpType(a types.TypeClass, b types.TypeClass) types.TypeClass {
-	if a == types.ClassString && b == types.ClassString {
-		return types.ClassString
-	} else if a == types.ClassInt && b == types.ClassInt {
-		return types.ClassInt
-	} else if (a == types.ClassInt || a == types.ClassDecimal) &&
-		(b == types.ClassInt || b == types.ClassDecimal) {
-		return types.ClassDecimal
-	}
-	return types.ClassReal
-}
-
-// isTemporalColumn checks if a expression is a temporal column,
-// temporal column indicates time column or duration column.
-func isTemporalColumn(expr Expression) bool {
-	ft := expr.GetType()
-	if _, isCol := expr.(*Column); !isCol {
-		return false
-	}
-	if !types.IsTypeTime(ft.Tp) && ft.Tp != mysql.TypeDuration {
-		return false
-	}
-	return true
-}
-
 // tryToConvertConstantInt tries to convert a constant with other type to a int constant.
 func tryToConvertConstantInt(con *Constant, ctx context.Context) *Constant {
 	if con.GetTypeClass() == types.ClassInt {
@@ -552,6 +528,13 @@ func tryToConvertConstantInt(con *Constant, ctx context.Context) *Constant {
 
 // refineConstantArg changes the constant argument to it's ceiling or flooring result by the given op.
 func refineConstantArg(con *Constant, op opcode.Op, ctx context.Context) *Constant {
+	if con.Value.IsNull() {
+		// The NULL is compared as an int, so the int argument isn't casted.
+		return &Constant{
+			Value:   con.Value,
+			RetType: types.NewFieldType(mysql.TypeLonglong),
+		}
+	}
 	sc := ctx.GetSessionVars().StmtCtx
 	i64, err := con.Value.ToInt64(sc)
 	if err != nil {
@@ -610,76 +593,10 @@ func (c *compareFunctionClass) getFunction(ctx context.Context, rawArgs []Expres
 		return nil, errors.Trace(err)
 	}
 	args := c.refineArgs(rawArgs, ctx)
-	ft0, ft1 := args[0].GetType(), args[1].GetType()
-	tc0, tc1 := ft0.ToClass(), ft1.ToClass()
-	cmpType := getCmpType(tc0, tc1)
-	if (tc0 == types.ClassString && ft1.Tp == mysql.TypeJSON) ||
-		(ft0.Tp == mysql.TypeJSON && tc1 == types.ClassString) {
-		sig, err = c.generateCmpSigs(args, tpJSON, ctx)
-	} else if cmpType == types.ClassString && (types.IsTypeTime(ft0.Tp) || types.IsTypeTime(ft1.Tp)) {
-		// date[time] <cmp> date[time]
-		// string <cmp> date[time]
-		// compare as time
-		if ft0.Tp == ft1.Tp {
-			sig, err = c.generateCmpSigs(args, fieldTp2EvalTp(ft0), ctx)
-		} else {
-			sig, err = c.generateCmpSigs(args, tpDatetime, ctx)
-		}
-	} else if ft0.Tp == mysql.TypeDuration && ft1.Tp == mysql.TypeDuration {
-		// duration <cmp> duration
-		// compare as duration
-		sig, err = c.generateCmpSigs(args, tpDuration, ctx)
-	} else if cmpType == types.ClassReal || cmpType == types.ClassString {
-		_, isConst0 := args[0].(*Constant)
-		_, isConst1 := args[1].(*Constant)
-		if (tc0 == types.ClassDecimal && !isConst0 && tc1 == types.ClassString && isConst1) ||
-			(tc1 == types.ClassDecimal && !isConst1 && tc0 == types.ClassString && isConst0) {
-			/*
-				<non-const decimal expression> <cmp> <const string expression>
-				or
-				<const string expression> <cmp> <non-const decimal expression>
-
-				Do comparison as decimal rather than float, in order not to lose precision.
-			)*/
-			cmpType = types.ClassDecimal
-		} else if isTemporalColumn(args[0]) && isConst1 ||
-			isTemporalColumn(args[1]) && isConst0 {
-			/*
-				<temporal column> <cmp> <non-temporal constant>
-				or
-				<non-temporal constant> <cmp> <temporal column>
-
-				Convert the constant to temporal type.
-			*/
-			col, isColumn0 := args[0].(*Column)
-			if !isColumn0 {
-				col = args[1].(*Column)
-			}
-			if col.GetType().Tp == mysql.TypeDuration {
-				sig, err = c.generateCmpSigs(args, tpDuration, ctx)
-			} else {
-				sig, err = c.generateCmpSigs(args, tpDatetime, ctx)
-			}
-		}
-	}
+	sig, err = c.generateCmpSigs(args, getCmpTp(args[0], args[1]), ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	if sig == nil {
-		switch cmpType {
-		case types.ClassString:
-			sig, err = c.generateCmpSigs(args, tpString, ctx)
-		case types.ClassInt:
-			sig, err = c.generateCmpSigs(args, tpInt, ctx)
-		case types.ClassDecimal:
-			sig, err = c.generateCmpSigs(args, tpDecimal, ctx)
-		case types.ClassReal:
-			sig, err = c.generateCmpSigs(args, tpReal, ctx)
-		}
-		if err != nil {
-			return nil, errors.Trace(err)
-		}
-	}
 	return sig.setSelf(sig), nil
 }
 
@@ -762,27 +679,28 @@ func (c *compareFunctionClass) generateCmpSigs(args []Expression, tp evalTp, ctx
 			sig.setPbCode(tipb.ScalarFuncSig_NullEQDecimal)
 		}
 	case tpString:
+		collator := collatorOfArgs(args)
 		switch c.op {
 		case opcode.LT:
-			sig = &builtinLTStringSig{intBf}
+			sig = &builtinLTStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_LTString)
 		case opcode.LE:
-			sig = &builtinLEStringSig{intBf}
+			sig = &builtinLEStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_LEString)
 		case opcode.GT:
-			sig = &builtinGTStringSig{intBf}
+			sig = &builtinGTStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_GTString)
 		case opcode.GE:
-			sig = &builtinGEStringSig{intBf}
+			sig = &builtinGEStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_GEString)
 		case opcode.EQ:
-			sig = &builtinEQStringSig{intBf}
+			sig = &builtinEQStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_EQString)
 		case opcode.NE:
-			sig = &builtinNEStringSig{intBf}
+			sig = &builtinNEStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_NEString)
 		case opcode.NullEQ:
-			sig = &builtinNullEQStringSig{intBf}
+			sig = &builtinNullEQStringSig{intBf, collator}
 			sig.setPbCode(tipb.ScalarFuncSig_NullEQString)
 		}
 	case tpDuration:
@@ -887,10 +805,11 @@ func (s *builtinLTDecimalSig) evalInt(row []types.Datum) (val int64, isNull bool
 
 type builtinLTStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinLTStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfLT(compareString(s.args, row, s.ctx))
+	return resOfLT(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinLTDurationSig struct {
@@ -943,10 +862,11 @@ func (s *builtinLEDecimalSig) evalInt(row []types.Datum) (val int64, isNull bool
 
 type builtinLEStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinLEStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfLE(compareString(s.args, row, s.ctx))
+	return resOfLE(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinLEDurationSig struct {
@@ -999,10 +919,11 @@ func (s *builtinGTDecimalSig) evalInt(row []types.Datum) (val int64, isNull bool
 
 type builtinGTStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinGTStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfGT(compareString(s.args, row, s.ctx))
+	return resOfGT(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinGTDurationSig struct {
@@ -1055,10 +976,11 @@ func (s *builtinGEDecimalSig) evalInt(row []types.Datum) (val int64, isNull bool
 
 type builtinGEStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinGEStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfGE(compareString(s.args, row, s.ctx))
+	return resOfGE(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinGEDurationSig struct {
@@ -1111,10 +1033,11 @@ func (s *builtinEQDecimalSig) evalInt(row []types.Datum) (val int64, isNull bool
 
 type builtinEQStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinEQStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfEQ(compareString(s.args, row, s.ctx))
+	return resOfEQ(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinEQDurationSig struct {
@@ -1167,10 +1090,11 @@ func (s *builtinNEDecimalSig) evalInt(row []types.Datum) (val int64, isNull bool
 
 type builtinNEStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinNEStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
-	return resOfNE(compareString(s.args, row, s.ctx))
+	return resOfNE(compareString(s.args, row, s.ctx, s.collator))
 }
 
 type builtinNEDurationSig struct {
@@ -1294,6 +1218,7 @@ func (s *builtinNullEQDecimalSig) evalInt(row []types.Datum) (val int64, isNull
 
 type builtinNullEQStringSig struct {
 	baseIntBuiltinFunc
+	collator collate.Collator
 }
 
 func (s *builtinNullEQStringSig) evalInt(row []types.Datum) (val int64, isNull bool, err error) {
@@ -1312,7 +1237,7 @@ func (s *builtinNullEQStringSig) evalInt(row []types.Datum) (val int64, isNull b
 		res = 1
 	case isNull0 != isNull1:
 		break
-	case types.CompareString(arg0, arg1) == 0:
+	case s.collator.Compare(arg0, arg1) == 0:
 		res = 1
 	}
 	return res, false, nil
@@ -1507,7 +1432,27 @@ func compareInt(args []Expression, row []types.Datum, ctx context.Context) (val
 	return int64(res), false, nil
 }
 
-func compareString(args []Expression, row []types.Datum, ctx context.Context) (val int64, isNull bool, err error) {
+// collatorOfArgs returns the collator to compare the string arguments, the collation of a column argument
+// takes precedence over the others.
+func collatorOfArgs(args []Expression) collate.Collator {
+	for _, arg := range args {
+		if _, ok := arg.(*Column); ok {
+			return collate.GetCollator(collationOfStr(arg.GetType()))
+		}
+	}
+	return collate.GetCollator(collationOfStr(args[0].GetType()))
+}
+
+// collationOfStr returns the collation of the string field type, the strings that are not non-binary are
+// compared as binary.
+func collationOfStr(ft *types.FieldType) string {
+	if !types.IsNonBinaryStr(ft) {
+		return charset.CollationBin
+	}
+	return ft.Collate
+}
+
+func compareString(args []Expression, row []types.Datum, ctx context.Context, collator collate.Collator) (val int64, isNull bool, err error) {
 	sc := ctx.GetSessionVars().StmtCtx
 	arg0, isNull0, err := args[0].EvalString(row, sc)
 	if isNull0 || err != nil {
@@ -1517,7 +1462,7 @@ func compareString(args []Expression, row []types.Datum, ctx context.Context) (v
 	if isNull1 || err != nil {
 		return zeroI64, isNull1, errors.Trace(err)
 	}
-	return int64(types.CompareString(arg0, arg1)), false, nil
+	return int64(collator.Compare(arg0, arg1)), false, nil
 }
 
 func compareReal(args []Expression, row []types.Datum, ctx context.Context) (val int64, isNull bool, err error) {
@@ -1550,31 +1495,47 @@ func compareDecimal(args []Expression, row []types.Datum, ctx context.Context) (
 }
 
 func compareTime(args []Expression, row []types.Datum, ctx context.Context) (int64, bool, error) {
-	sc := ctx.GetSessionVars().StmtCtx
-	arg0, isNull0, err := args[0].EvalTime(row, sc)
+	arg0, isNull0, err := evalCmpTime(args[0], row, ctx)
 	if isNull0 || err != nil {
 		return zeroI64, isNull0, errors.Trace(err)
 	}
-	arg1, isNull1, err := args[1].EvalTime(row, sc)
+	arg1, isNull1, err := evalCmpTime(args[1], row, ctx)
 	if isNull1 || err != nil {
 		return zeroI64, isNull1, errors.Trace(err)
 	}
 	return int64(arg0.Compare(arg1)), false, nil
 }
 
+// evalCmpTime evaluates the argument of a comparison as datetime, the invalid value is compared as the zero datetime.
+func evalCmpTime(arg Expression, row []types.Datum, ctx context.Context) (types.Time, bool, error) {
+	t, isNull, err := arg.EvalTime(row, ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return types.ZeroDatetime, false, errors.Trace(handleCmpTemporalError(ctx, arg, row, "datetime", err))
+	}
+	return t, isNull, nil
+}
+
 func compareDuration(args []Expression, row []types.Datum, ctx context.Context) (int64, bool, error) {
-	sc := ctx.GetSessionVars().StmtCtx
-	arg0, isNull0, err := args[0].EvalDuration(row, sc)
+	arg0, isNull0, err := evalCmpDuration(args[0], row, ctx)
 	if isNull0 || err != nil {
 		return zeroI64, isNull0, errors.Trace(err)
 	}
-	arg1, isNull1, err := args[1].EvalDuration(row, sc)
+	arg1, isNull1, err := evalCmpDuration(args[1], row, ctx)
 	if isNull1 || err != nil {
 		return zeroI64, isNull1, errors.Trace(err)
 	}
 	return int64(arg0.Compare(arg1)), false, nil
 }
 
+// evalCmpDuration evaluates the argument of a comparison as time, the invalid value is compared as the zero time.
+func evalCmpDuration(arg Expression, row []types.Datum, ctx context.Context) (types.Duration, bool, error) {
+	d, isNull, err := arg.EvalDuration(row, ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return types.ZeroDuration, false, errors.Trace(handleCmpTemporalError(ctx, arg, row, "time", err))
+	}
+	return d, isNull, nil
+}
+
 func compareJSON(args []Expression, row []types.Datum, ctx context.Context) (int64, bool, error) {
 	sc := ctx.GetSessionVars().StmtCtx
 	arg0, isNull0, err := args[0].EvalJSON(row, sc)
@@ -16,6 +16,7 @@ package expression
 import (
 	"bytes"
 	"compress/zlib"
+	"crypto/aes"
 	"crypto/md5"
 	"crypto/rand"
 	"crypto/sha1"
@@ -25,10 +26,15 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/encrypt"
 	"github.com/pingcap/tidb/util/types"
@@ -64,14 +70,90 @@ var (
 	_ builtinFunc = &builtinSHA1Sig{}
 	_ builtinFunc = &builtinSHA2Sig{}
 	_ builtinFunc = &builtinUncompressSig{}
+	_ builtinFunc = &builtinValidatePasswordStrengthSig{}
 	_ builtinFunc = &builtinUncompressedLengthSig{}
 )
 
-// TODO: support other mode
-const (
-	aes128ecb          string = "aes-128-ecb"
-	aes128ecbBlobkSize int    = 16
-)
+// aesModeAttr is the attribute of a block encryption mode.
+type aesModeAttr struct {
+	modeName   string
+	keySize    int
+	ivRequired bool
+}
+
+// aesModes are the supported values of the block_encryption_mode variable.
+var aesModes = map[string]*aesModeAttr{
+	"aes-128-ecb":    {"ecb", 16, false},
+	"aes-192-ecb":    {"ecb", 24, false},
+	"aes-256-ecb":    {"ecb", 32, false},
+	"aes-128-cbc":    {"cbc", 16, true},
+	"aes-192-cbc":    {"cbc", 24, true},
+	"aes-256-cbc":    {"cbc", 32, true},
+	"aes-128-cfb1":   {"cfb1", 16, true},
+	"aes-192-cfb1":   {"cfb1", 24, true},
+	"aes-256-cfb1":   {"cfb1", 32, true},
+	"aes-128-cfb8":   {"cfb8", 16, true},
+	"aes-192-cfb8":   {"cfb8", 24, true},
+	"aes-256-cfb8":   {"cfb8", 32, true},
+	"aes-128-cfb128": {"cfb128", 16, true},
+	"aes-192-cfb128": {"cfb128", 24, true},
+	"aes-256-cfb128": {"cfb128", 32, true},
+	"aes-128-ofb":    {"ofb", 16, true},
+	"aes-192-ofb":    {"ofb", 24, true},
+	"aes-256-ofb":    {"ofb", 32, true},
+}
+
+// defaultBlockEncryptionMode is the default value of the block_encryption_mode variable.
+const defaultBlockEncryptionMode = "aes-128-ecb"
+
+// getAESMode returns the block encryption mode of AES_ENCRYPT and AES_DECRYPT by the block_encryption_mode variable.
+// It checks the number of the arguments, the initialization vector is required by the modes except ECB,
+// and is ignored with a warning by ECB.
+func getAESMode(ctx context.Context, funcName string, args []Expression) (*aesModeAttr, error) {
+	modeStr, ok := ctx.GetSessionVars().Systems[variable.BlockEncryptionMode]
+	if !ok {
+		modeStr = defaultBlockEncryptionMode
+	}
+	mode, ok := aesModes[strings.ToLower(modeStr)]
+	if !ok {
+		return nil, errIncorrectArgs.GenByArgs(funcName)
+	}
+	if mode.ivRequired && len(args) != 3 {
+		return nil, ErrIncorrectParameterCount.GenByArgs(funcName)
+	}
+	if !mode.ivRequired && len(args) == 3 {
+		ctx.GetSessionVars().StmtCtx.AppendWarning(errWarnOptionIgnored.GenByArgs("IV"))
+	}
+	return mode, nil
+}
+
+// evalAESArgs evaluates the string, the key and the initialization vector of AES_ENCRYPT and AES_DECRYPT,
+// the key is derived by the key size of the mode. The initialization vector must be at least 16 bytes,
+// the bytes in excess of 16 are ignored.
+func evalAESArgs(b *baseBuiltinFunc, row []types.Datum, mode *aesModeAttr, funcName string) (str string, key []byte, iv []byte, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	// According to doc: If either function argument is NULL, the function returns NULL.
+	str, isNull, err = b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", nil, nil, true, errors.Trace(err)
+	}
+	keyStr, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", nil, nil, true, errors.Trace(err)
+	}
+	key = encrypt.DeriveKeyMySQL([]byte(keyStr), mode.keySize)
+	if !mode.ivRequired {
+		return str, key, nil, false, nil
+	}
+	ivStr, isNull, err := b.args[2].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", nil, nil, true, errors.Trace(err)
+	}
+	if len(ivStr) < aes.BlockSize {
+		return "", nil, nil, true, errIncorrectArgs.GenByArgs(funcName)
+	}
+	return str, key, []byte(ivStr[:aes.BlockSize]), false, nil
+}
 
 type aesDecryptFunctionClass struct {
 	baseFunctionClass
@@ -81,34 +163,51 @@ func (c *aesDecryptFunctionClass) getFunction(ctx context.Context, args []Expres
 	if err := c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(c.verifyArgs(args))
 	}
-	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString, tpString)
+	mode, err := getAESMode(ctx, c.funcName, args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]evalTp, 0, len(args))
+	for range args {
+		argTps = append(argTps, tpString)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
 	bf.tp.Flen = args[0].GetType().Flen // At most.
 	types.SetBinChsClnFlag(bf.tp)
-	sig := &builtinAesDecryptSig{baseStringBuiltinFunc{bf}}
+	sig := &builtinAesDecryptSig{baseStringBuiltinFunc{bf}, mode, c.funcName}
 	return sig.setSelf(sig), nil
 }
 
 type builtinAesDecryptSig struct {
 	baseStringBuiltinFunc
+	mode     *aesModeAttr
+	funcName string
 }
 
-// evalString evals AES_DECRYPT(crypt_str, key_key).
+// evalString evals AES_DECRYPT(crypt_str, key_key[, init_vector]).
 // See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_aes-decrypt
 func (b *builtinAesDecryptSig) evalString(row []types.Datum) (string, bool, error) {
-	// According to doc: If either function argument is NULL, the function returns NULL.
-	cryptStr, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	cryptStr, key, iv, isNull, err := evalAESArgs(&b.baseBuiltinFunc, row, b.mode, b.funcName)
 	if isNull || err != nil {
 		return "", true, errors.Trace(err)
 	}
 
-	keyStr, isNull, err := b.args[1].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
-	if isNull || err != nil {
-		return "", true, errors.Trace(err)
-	}
-
-	// TODO: Support other modes.
-	key := encrypt.DeriveKeyMySQL([]byte(keyStr), aes128ecbBlobkSize)
-	plainText, err := encrypt.AESDecryptWithECB([]byte(cryptStr), key)
+	var plainText []byte
+	switch b.mode.modeName {
+	case "ecb":
+		plainText, err = encrypt.AESDecryptWithECB([]byte(cryptStr), key)
+	case "cbc":
+		plainText, err = encrypt.AESDecryptWithCBC([]byte(cryptStr), key, iv)
+	case "cfb1":
+		plainText, err = encrypt.AESDecryptWithCFB([]byte(cryptStr), key, iv, 1)
+	case "cfb8":
+		plainText, err = encrypt.AESDecryptWithCFB([]byte(cryptStr), key, iv, 8)
+	case "cfb128":
+		plainText, err = encrypt.AESDecryptWithCFB([]byte(cryptStr), key, iv, 128)
+	case "ofb":
+		plainText, err = encrypt.AESDecryptWithOFB([]byte(cryptStr), key, iv)
+	}
+	// The invalid data is decrypted to NULL.
 	if err != nil {
 		return "", true, nil
 	}
@@ -123,34 +222,56 @@ func (c *aesEncryptFunctionClass) getFunction(ctx context.Context, args []Expres
 	if err := c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(c.verifyArgs(args))
 	}
-	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString, tpString)
-	bf.tp.Flen = aes128ecbBlobkSize * (args[0].GetType().Flen/aes128ecbBlobkSize + 1) // At most.
+	mode, err := getAESMode(ctx, c.funcName, args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := make([]evalTp, 0, len(args))
+	for range args {
+		argTps = append(argTps, tpString)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
+	if mode.modeName == "ecb" || mode.modeName == "cbc" {
+		bf.tp.Flen = aes.BlockSize * (args[0].GetType().Flen/aes.BlockSize + 1) // At most.
+	} else {
+		bf.tp.Flen = args[0].GetType().Flen
+	}
 	types.SetBinChsClnFlag(bf.tp)
-	sig := &builtinAesEncryptSig{baseStringBuiltinFunc{bf}}
+	sig := &builtinAesEncryptSig{baseStringBuiltinFunc{bf}, mode, c.funcName}
 	return sig.setSelf(sig), nil
 }
 
 type builtinAesEncryptSig struct {
 	baseStringBuiltinFunc
+	mode     *aesModeAttr
+	funcName string
 }
 
-// evalString evals AES_ENCRYPT(str, key_str).
-// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_aes-decrypt
+// evalString evals AES_ENCRYPT(str, key_str[, init_vector]).
+// The block encryption mode is specified by the block_encryption_mode variable, the data is padded by
+// PKCS7 in the ECB and CBC modes, and the result has the same length as the data in the other modes.
+// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_aes-encrypt
 func (b *builtinAesEncryptSig) evalString(row []types.Datum) (string, bool, error) {
-	// According to doc: If either function argument is NULL, the function returns NULL.
-	str, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	str, key, iv, isNull, err := evalAESArgs(&b.baseBuiltinFunc, row, b.mode, b.funcName)
 	if isNull || err != nil {
 		return "", true, errors.Trace(err)
 	}
 
-	keyStr, isNull, err := b.args[1].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
-	if isNull || err != nil {
-		return "", true, errors.Trace(err)
+	var cipherText []byte
+	switch b.mode.modeName {
+	case "ecb":
+		cipherText, err = encrypt.AESEncryptWithECB([]byte(str), key)
+	case "cbc":
+		cipherText, err = encrypt.AESEncryptWithCBC([]byte(str), key, iv)
+	case "cfb1":
+		cipherText, err = encrypt.AESEncryptWithCFB([]byte(str), key, iv, 1)
+	case "cfb8":
+		cipherText, err = encrypt.AESEncryptWithCFB([]byte(str), key, iv, 8)
+	case "cfb128":
+		cipherText, err = encrypt.AESEncryptWithCFB([]byte(str), key, iv, 128)
+	case "ofb":
+		cipherText, err = encrypt.AESEncryptWithOFB([]byte(str), key, iv)
 	}
-
-	// TODO: Support other modes.
-	key := encrypt.DeriveKeyMySQL([]byte(keyStr), aes128ecbBlobkSize)
-	cipherText, err := encrypt.AESEncryptWithECB([]byte(str), key)
 	if err != nil {
 		return "", true, nil
 	}
@@ -570,5 +691,68 @@ type validatePasswordStrengthFunctionClass struct {
 }
 
 func (c *validatePasswordStrengthFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("VALIDATE_PASSWORD_STRENGTH")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 21
+	sig := &builtinValidatePasswordStrengthSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinValidatePasswordStrengthSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals VALIDATE_PASSWORD_STRENGTH(str).
+// The strength is 0 if the password is shorter than 4 characters, 25 if it's shorter than validate_password_length,
+// 50 if it only satisfies the length, 75 if it satisfies the MEDIUM policy, and 100 if it satisfies the STRONG policy.
+// The dictionary file is not supported, so a password that satisfies the MEDIUM policy satisfies the STRONG policy.
+// See https://dev.mysql.com/doc/refman/5.7/en/encryption-functions.html#function_validate-password-strength
+func (b *builtinValidatePasswordStrengthSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	password, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	length := int64(utf8.RuneCountInString(password))
+	if length < 4 {
+		return 0, false, nil
+	}
+	if length < b.getVarInt(variable.ValidatePasswordLength, 8) {
+		return 25, false, nil
+	}
+	var numberCount, lowerCount, upperCount, specialCount int64
+	for _, r := range password {
+		switch {
+		case unicode.IsDigit(r):
+			numberCount++
+		case unicode.IsLower(r):
+			lowerCount++
+		case unicode.IsUpper(r):
+			upperCount++
+		default:
+			specialCount++
+		}
+	}
+	mixedCaseCount := b.getVarInt(variable.ValidatePasswordMixedCaseCount, 1)
+	if numberCount < b.getVarInt(variable.ValidatePasswordNumberCount, 1) ||
+		lowerCount < mixedCaseCount || upperCount < mixedCaseCount ||
+		specialCount < b.getVarInt(variable.ValidatePasswordSpecialCharCount, 1) {
+		return 50, false, nil
+	}
+	return 100, false, nil
+}
+
+// getVarInt returns the integer value of a password validation variable, or the default value if it's not set.
+func (b *builtinValidatePasswordStrengthSig) getVarInt(name string, defaultValue int64) int64 {
+	val, ok := b.ctx.GetSessionVars().Systems[name]
+	if !ok {
+		return defaultValue
+	}
+	i, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return i
 }
@@ -14,11 +14,13 @@
 package expression
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"strings"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -65,6 +67,85 @@ func (s *testEvaluatorSuite) TestAESDecrypt(c *C) {
 	s.testNullInput(c, ast.AesDecrypt)
 }
 
+var aesIVTests = []struct {
+	mode   string
+	origin interface{}
+	key    interface{}
+	iv     interface{}
+	crypt  interface{}
+}{
+	{"aes-128-cbc", "pingcap", "key", "1234567890123456", "CE514E10182B213E18A11240400F0600"},
+	{"aes-256-cbc", "pingcap", "key", "1234567890123456", "5AA9283E4136E74EAE7F413B4B1B1DAC"},
+	{"aes-192-ofb", "pingcap", "key", "1234567890123456", "09106212197F25"},
+	{"aes-128-cfb1", "pingcap", "key", "1234567890123456", "793BE1403CA05A"},
+	{"aes-192-cfb8", "pingcap", "key", "1234567890123456", "09F075507D8C50"},
+	{"AES-256-CFB128", "pingcap", "key", "1234567890123456", "54965E9B870C72"},
+	// The bytes of the initialization vector in excess of 16 are ignored.
+	{"aes-128-cbc", "pingcap", "key", "1234567890123456789", "CE514E10182B213E18A11240400F0600"},
+	{"aes-128-cbc", nil, "key", "1234567890123456", nil},
+	{"aes-128-cbc", "pingcap", nil, "1234567890123456", nil},
+	{"aes-128-cbc", "pingcap", "key", nil, nil},
+}
+
+func (s *testEvaluatorSuite) TestAESEncryptWithIV(c *C) {
+	defer testleak.AfterTest(c)()
+	defer delete(s.ctx.GetSessionVars().Systems, variable.BlockEncryptionMode)
+	fc := funcs[ast.AesEncrypt]
+	for _, tt := range aesIVTests {
+		s.ctx.GetSessionVars().Systems[variable.BlockEncryptionMode] = tt.mode
+		args := datumsToConstants(types.MakeDatums(tt.origin, tt.key, tt.iv))
+		f, err := fc.getFunction(s.ctx, args)
+		c.Assert(err, IsNil)
+		crypt, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(toHex(crypt), DeepEquals, types.NewDatum(tt.crypt), Commentf("%v", tt))
+	}
+
+	// The initialization vector is required by the modes except ECB.
+	s.ctx.GetSessionVars().Systems[variable.BlockEncryptionMode] = "aes-128-cbc"
+	_, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums("pingcap", "key")))
+	c.Assert(err, NotNil)
+	// The initialization vector must be at least 16 bytes.
+	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums("pingcap", "key", "123")))
+	c.Assert(err, IsNil)
+	_, err = f.eval(nil)
+	c.Assert(err, NotNil)
+	// The initialization vector is ignored by ECB.
+	s.ctx.GetSessionVars().Systems[variable.BlockEncryptionMode] = "aes-128-ecb"
+	f, err = fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums("pingcap", "1234567890123456", "123")))
+	c.Assert(err, IsNil)
+	crypt, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(toHex(crypt), DeepEquals, types.NewDatum("697BFE9B3F8C2F289DD82C88C7BC95C4"))
+}
+
+func (s *testEvaluatorSuite) TestAESDecryptWithIV(c *C) {
+	defer testleak.AfterTest(c)()
+	defer delete(s.ctx.GetSessionVars().Systems, variable.BlockEncryptionMode)
+	fc := funcs[ast.AesDecrypt]
+	for _, tt := range aesIVTests {
+		s.ctx.GetSessionVars().Systems[variable.BlockEncryptionMode] = tt.mode
+		args := datumsToConstants([]types.Datum{fromHex(tt.crypt), types.NewDatum(tt.key), types.NewDatum(tt.iv)})
+		f, err := fc.getFunction(s.ctx, args)
+		c.Assert(err, IsNil)
+		str, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		if tt.crypt == nil {
+			c.Assert(str.IsNull(), IsTrue, Commentf("%v", tt))
+			continue
+		}
+		c.Assert(str, DeepEquals, types.NewDatum(tt.origin), Commentf("%v", tt))
+	}
+
+	// The invalid data is decrypted to NULL.
+	s.ctx.GetSessionVars().Systems[variable.BlockEncryptionMode] = "aes-128-cbc"
+	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums("pingcap", "key", "1234567890123456")))
+	c.Assert(err, IsNil)
+	str, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(str.IsNull(), IsTrue)
+}
+
 func (s *testEvaluatorSuite) testNullInput(c *C, fnName string) {
 	fc := funcs[fnName]
 	arg := types.NewStringDatum("str")
@@ -254,7 +335,8 @@ func (s *testEvaluatorSuite) TestCompress(c *C) {
 		in     interface{}
 		expect interface{}
 	}{
-		{"hello world", string(decodeHex("0B000000789CCA48CDC9C95728CF2FCA4901040000FFFF1A0B045D"))},
+		{"hello world", nil},
+		{strings.Repeat("pingcap", 100), nil},
 		{"", ""},
 		{nil, nil},
 	}
@@ -266,7 +348,18 @@ func (s *testEvaluatorSuite) TestCompress(c *C) {
 		c.Assert(err, IsNil, Commentf("%v", test))
 		out, err := f.eval(nil)
 		c.Assert(err, IsNil, Commentf("%v", test))
-		c.Assert(out, DeepEquals, types.NewDatum(test.expect), Commentf("%v", test))
+		if test.in == nil || test.in == "" {
+			c.Assert(out, DeepEquals, types.NewDatum(test.expect), Commentf("%v", test))
+			continue
+		}
+		// The compressed bytes depend on the zlib implementation, so the result is checked by its
+		// length header and its uncompressed data.
+		compressed := out.GetString()
+		str := test.in.(string)
+		c.Assert(binary.LittleEndian.Uint32([]byte(compressed)), Equals, uint32(len(str)), Commentf("%v", test))
+		uncompressed, err := inflate([]byte(compressed[4:]))
+		c.Assert(err, IsNil, Commentf("%v", test))
+		c.Assert(string(uncompressed), Equals, str, Commentf("%v", test))
 	}
 }
 
@@ -362,3 +455,49 @@ func (s *testEvaluatorSuite) TestPassword(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(f.canBeFolded(), IsTrue)
 }
+
+func (s *testEvaluatorSuite) TestValidatePasswordStrength(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		in     interface{}
+		expect interface{}
+	}{
+		{"abc", int64(0)},
+		{"你好世界", int64(25)},
+		{"abcdefg", int64(25)},
+		{"abcdefgh", int64(50)},
+		{"Abcdefg1", int64(50)},
+		{"Abcdefg!", int64(50)},
+		{"Abcdef1!", int64(100)},
+		{"你好世界Ab1!", int64(100)},
+		{nil, nil},
+	}
+
+	fc := funcs[ast.ValidatePasswordStrength]
+	for _, test := range tests {
+		f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(test.in)))
+		c.Assert(err, IsNil, Commentf("%v", test))
+		out, err := f.eval(nil)
+		c.Assert(err, IsNil, Commentf("%v", test))
+		c.Assert(out, DeepEquals, types.NewDatum(test.expect), Commentf("%v", test))
+	}
+
+	// The policy is specified by the password validation variables.
+	vars := s.ctx.GetSessionVars().Systems
+	vars[variable.ValidatePasswordLength] = "4"
+	vars[variable.ValidatePasswordSpecialCharCount] = "0"
+	defer func() {
+		delete(vars, variable.ValidatePasswordLength)
+		delete(vars, variable.ValidatePasswordSpecialCharCount)
+	}()
+	for _, test := range []struct {
+		in     string
+		expect int64
+	}{{"abcd", 50}, {"Abc1", 100}} {
+		f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(test.in)))
+		c.Assert(err, IsNil)
+		out, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(out.GetInt64(), Equals, test.expect, Commentf("%v", test))
+	}
+}
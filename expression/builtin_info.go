@@ -34,6 +34,7 @@ var (
 	_ functionClass = &lastInsertIDFunctionClass{}
 	_ functionClass = &versionFunctionClass{}
 	_ functionClass = &benchmarkFunctionClass{}
+	_ functionClass = &currentRoleFunctionClass{}
 	_ functionClass = &charsetFunctionClass{}
 	_ functionClass = &coercibilityFunctionClass{}
 	_ functionClass = &collationFunctionClass{}
@@ -50,7 +51,10 @@ var (
 	_ builtinFunc = &builtinLastInsertIDSig{}
 	_ builtinFunc = &builtinLastInsertIDWithIDSig{}
 	_ builtinFunc = &builtinVersionSig{}
+	_ builtinFunc = &builtinRowCountSig{}
 	_ builtinFunc = &builtinTiDBVersionSig{}
+	_ builtinFunc = &builtinBenchmarkSig{}
+	_ builtinFunc = &builtinCurrentRoleSig{}
 )
 
 type databaseFunctionClass struct {
@@ -100,7 +104,6 @@ type builtinFoundRowsSig struct {
 
 // evalInt evals a builtinFoundRowsSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_found-rows
-// TODO: SQL_CALC_FOUND_ROWS and LIMIT not support for now, We will finish in another PR.
 func (b *builtinFoundRowsSig) evalInt(row []types.Datum) (int64, bool, error) {
 	data := b.ctx.GetSessionVars()
 	if data == nil {
@@ -304,7 +307,73 @@ type benchmarkFunctionClass struct {
 }
 
 func (c *benchmarkFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("BENCHMARK")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpInt, fieldTp2EvalTp(args[1].GetType()))
+	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinBenchmarkSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+// benchmarkCheckInterval is the number of evaluations between the checks whether BENCHMARK is killed.
+const benchmarkCheckInterval = 1024
+
+type builtinBenchmarkSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals BENCHMARK(count, expr), it evaluates expr count times and returns 0.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_benchmark
+func (b *builtinBenchmarkSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	count, isNull, err := b.args[0].EvalInt(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if count < 0 {
+		return 0, true, nil
+	}
+	done := b.ctx.GoCtx().Done()
+	for i := int64(0); i < count; i++ {
+		// Check whether the query is killed every benchmarkCheckInterval evaluations.
+		if i%benchmarkCheckInterval == 0 {
+			select {
+			case <-done:
+				return 0, true, errQueryInterrupted.GenByArgs()
+			default:
+			}
+		}
+		if _, err = b.args[1].Eval(row); err != nil {
+			return 0, true, errors.Trace(err)
+		}
+	}
+	return 0, false, nil
+}
+
+type currentRoleFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *currentRoleFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString)
+	bf.tp.Flen = 64
+	sig := &builtinCurrentRoleSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinCurrentRoleSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals CURRENT_ROLE(). TiDB doesn't support roles, so no role is active.
+// See https://dev.mysql.com/doc/refman/8.0/en/information-functions.html#function_current-role
+func (b *builtinCurrentRoleSig) evalString(_ []types.Datum) (string, bool, error) {
+	return "NONE", false, nil
 }
 
 type charsetFunctionClass struct {
@@ -336,5 +405,21 @@ type rowCountFunctionClass struct {
 }
 
 func (c *rowCountFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("ROW_COUNT")
+	if err := errors.Trace(c.verifyArgs(args)); err != nil {
+		return nil, err
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt)
+	bf.foldable = false
+	sig := &builtinRowCountSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRowCountSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals a builtinRowCountSig.
+// See https://dev.mysql.com/doc/refman/5.7/en/information-functions.html#function_row-count
+func (b *builtinRowCountSig) evalInt(row []types.Datum) (int64, bool, error) {
+	return b.ctx.GetSessionVars().PrevAffectedRows, false, nil
 }
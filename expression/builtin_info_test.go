@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -126,9 +127,34 @@ func (s *testEvaluatorSuite) TestVersion(c *C) {
 func (s *testEvaluatorSuite) TestBenchMark(c *C) {
 	defer testleak.AfterTest(c)()
 	fc := funcs[ast.Benchmark]
-	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil, nil)))
-	c.Assert(f, IsNil)
-	c.Assert(err, ErrorMatches, "*FUNCTION BENCHMARK does not exist")
+	tbl := []struct {
+		count  interface{}
+		expr   interface{}
+		result interface{}
+	}{
+		{3, "abc", 0},
+		{0, 1.5, 0},
+		{-1, 1, nil},
+		{nil, 1, nil},
+	}
+	for _, t := range tbl {
+		f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums(t.count, t.expr)))
+		c.Assert(err, IsNil)
+		c.Assert(f.canBeFolded(), IsFalse)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.result))
+	}
+}
+
+func (s *testEvaluatorSuite) TestCurrentRole(c *C) {
+	defer testleak.AfterTest(c)()
+	fc := funcs[ast.CurrentRole]
+	f, err := fc.getFunction(s.ctx, nil)
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "NONE")
 }
 
 func (s *testEvaluatorSuite) TestCharset(c *C) {
@@ -157,10 +183,18 @@ func (s *testEvaluatorSuite) TestCollation(c *C) {
 
 func (s *testEvaluatorSuite) TestRowCount(c *C) {
 	defer testleak.AfterTest(c)()
+	sessionVars := s.ctx.GetSessionVars()
+	sessionVars.PrevAffectedRows = 10
+	defer func() {
+		sessionVars.PrevAffectedRows = 0
+	}()
 	fc := funcs[ast.RowCount]
 	f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums()))
-	c.Assert(f, IsNil)
-	c.Assert(err, ErrorMatches, "*FUNCTION ROW_COUNT does not exist")
+	c.Assert(err, IsNil)
+	c.Assert(f.canBeFolded(), IsFalse)
+	v, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetInt64(), Equals, int64(10))
 }
 
 // Test case for tidb_server().
@@ -14,8 +14,6 @@
 package expression
 
 import (
-	"regexp"
-
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/util/stringutil"
@@ -120,8 +118,7 @@ func (b *builtinRegexpBinarySig) evalInt(row []types.Datum) (int64, bool, error)
 		return 0, true, errors.Trace(err)
 	}
 
-	// TODO: We don't need to compile pattern if it has been compiled or it is static.
-	re, err := regexp.Compile(pat)
+	re, err := regexpPool.compile(pat, "")
 	if err != nil {
 		return 0, true, errors.Trace(err)
 	}
@@ -147,8 +144,7 @@ func (b *builtinRegexpSig) evalInt(row []types.Datum) (int64, bool, error) {
 		return 0, true, errors.Trace(err)
 	}
 
-	// TODO: We don't need to compile pattern if it has been compiled or it is static.
-	re, err := regexp.Compile("(?i)" + pat)
+	re, err := regexpPool.compile(pat, "i")
 	if err != nil {
 		return 0, true, errors.Trace(err)
 	}
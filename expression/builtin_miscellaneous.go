@@ -15,11 +15,13 @@ package expression
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"net"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -27,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tidb/util/types/json"
+	"github.com/pingcap/tidb/util/userlock"
 	"github.com/twinj/uuid"
 )
 
@@ -51,12 +54,18 @@ var (
 	_ functionClass = &releaseAllLocksFunctionClass{}
 	_ functionClass = &uuidFunctionClass{}
 	_ functionClass = &uuidShortFunctionClass{}
+	_ functionClass = &uuidToBinFunctionClass{}
+	_ functionClass = &binToUUIDFunctionClass{}
+	_ functionClass = &isUUIDFunctionClass{}
 )
 
 var (
 	_ builtinFunc = &builtinSleepSig{}
 	_ builtinFunc = &builtinLockSig{}
 	_ builtinFunc = &builtinReleaseLockSig{}
+	_ builtinFunc = &builtinReleaseAllLocksSig{}
+	_ builtinFunc = &builtinIsFreeLockSig{}
+	_ builtinFunc = &builtinIsUsedLockSig{}
 	_ builtinFunc = &builtinDecimalAnyValueSig{}
 	_ builtinFunc = &builtinDurationAnyValueSig{}
 	_ builtinFunc = &builtinIntAnyValueSig{}
@@ -73,6 +82,9 @@ var (
 	_ builtinFunc = &builtinIsIPv4MappedSig{}
 	_ builtinFunc = &builtinIsIPv6Sig{}
 	_ builtinFunc = &builtinUUIDSig{}
+	_ builtinFunc = &builtinUUIDToBinSig{}
+	_ builtinFunc = &builtinBinToUUIDSig{}
+	_ builtinFunc = &builtinIsUUIDSig{}
 )
 
 type sleepFunctionClass struct {
@@ -135,20 +147,58 @@ func (c *lockFunctionClass) getFunction(ctx context.Context, args []Expression)
 		return nil, errors.Trace(err)
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString, tpInt)
-	sig := &builtinLockSig{baseIntBuiltinFunc{bf}}
 	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinLockSig{baseIntBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
 
+// maxUserLockNameLen is the max length of the names of the user-level locks.
+const maxUserLockNameLen = 64
+
+// evalUserLockName evaluates the name of a user-level lock, the names are case-insensitive.
+func evalUserLockName(b *baseBuiltinFunc, row []types.Datum) (string, error) {
+	name, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if isNull {
+		return "", errUserLockWrongName.GenByArgs("NULL")
+	}
+	if len(name) == 0 || utf8.RuneCountInString(name) > maxUserLockNameLen {
+		return "", errUserLockWrongName.GenByArgs(name)
+	}
+	return strings.ToLower(name), nil
+}
+
 type builtinLockSig struct {
 	baseIntBuiltinFunc
 }
 
-// evalInt evals a builtinLockSig.
+// evalInt evals GET_LOCK(str, timeout).
+// It waits for the lock until the timeout in seconds if it's held by another connection, and waits forever
+// if the timeout is negative. It returns 1 if the lock is acquired, and 0 if the lock is timed out.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_get-lock
-// The lock function will do nothing.
-// Warning: get_lock() function is parsed but ignored.
-func (b *builtinLockSig) evalInt(_ []types.Datum) (int64, bool, error) {
+func (b *builtinLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(&b.baseBuiltinFunc, row)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	timeout, isNull, err := b.args[1].EvalInt(row, b.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if isNull {
+		timeout = 0
+	}
+	manager := userlock.GetManager(b.ctx.GetStore())
+	ok, err := manager.GetLock(name, b.ctx.GetSessionVars().ConnectionID, time.Duration(timeout)*time.Second)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if !ok {
+		return 0, false, nil
+	}
 	return 1, false, nil
 }
 
@@ -161,8 +211,9 @@ func (c *releaseLockFunctionClass) getFunction(ctx context.Context, args []Expre
 		return nil, errors.Trace(err)
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
-	sig := &builtinReleaseLockSig{baseIntBuiltinFunc{bf}}
 	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinReleaseLockSig{baseIntBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
 
@@ -170,11 +221,23 @@ type builtinReleaseLockSig struct {
 	baseIntBuiltinFunc
 }
 
-// evalInt evals a builtinReleaseLockSig.
+// evalInt evals RELEASE_LOCK(str).
+// It returns 1 if the lock is released, 0 if the lock is held by another connection, and NULL if the lock
+// isn't held by any connection.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-lock
-// The release lock function will do nothing.
-// Warning: release_lock() function is parsed but ignored.
-func (b *builtinReleaseLockSig) evalInt(_ []types.Datum) (int64, bool, error) {
+func (b *builtinReleaseLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(&b.baseBuiltinFunc, row)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	manager := userlock.GetManager(b.ctx.GetStore())
+	released, isNull, err := manager.ReleaseLock(name, b.ctx.GetSessionVars().ConnectionID)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if !released {
+		return 0, false, nil
+	}
 	return 1, false, nil
 }
 
@@ -507,7 +570,35 @@ type isFreeLockFunctionClass struct {
 }
 
 func (c *isFreeLockFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("IS_FREE_LOCK")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 1
+	bf.foldable = false
+	sig := &builtinIsFreeLockSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsFreeLockSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals IS_FREE_LOCK(str).
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-free-lock
+func (b *builtinIsFreeLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(&b.baseBuiltinFunc, row)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	_, used, err := userlock.GetManager(b.ctx.GetStore()).IsUsedLock(name)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if used {
+		return 0, false, nil
+	}
+	return 1, false, nil
 }
 
 type isIPv4FunctionClass struct {
@@ -683,7 +774,34 @@ type isUsedLockFunctionClass struct {
 }
 
 func (c *isUsedLockFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("IS_USED_LOCK")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 21
+	bf.tp.Flag |= mysql.UnsignedFlag
+	bf.foldable = false
+	sig := &builtinIsUsedLockSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsUsedLockSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals IS_USED_LOCK(str).
+// It returns the connection ID holding the lock, or NULL if the lock is free.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-used-lock
+func (b *builtinIsUsedLockSig) evalInt(row []types.Datum) (int64, bool, error) {
+	name, err := evalUserLockName(&b.baseBuiltinFunc, row)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	connID, used, err := userlock.GetManager(b.ctx.GetStore()).IsUsedLock(name)
+	if !used || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return int64(connID), false, nil
 }
 
 type masterPosWaitFunctionClass struct {
@@ -707,7 +825,29 @@ type releaseAllLocksFunctionClass struct {
 }
 
 func (c *releaseAllLocksFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
-	return nil, errFunctionNotExists.GenByArgs("RELEASE_ALL_LOCKS")
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt)
+	bf.tp.Flen = 21
+	bf.foldable = false
+	sig := &builtinReleaseAllLocksSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinReleaseAllLocksSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals RELEASE_ALL_LOCKS().
+// It returns the number of the released locks, a lock acquired several times is counted as many times.
+// See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-all-locks
+func (b *builtinReleaseAllLocksSig) evalInt(_ []types.Datum) (int64, bool, error) {
+	count, err := userlock.GetManager(b.ctx.GetStore()).ReleaseAll(b.ctx.GetSessionVars().ConnectionID)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return count, false, nil
 }
 
 type uuidFunctionClass struct {
@@ -742,3 +882,175 @@ type uuidShortFunctionClass struct {
 func (c *uuidShortFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
 	return nil, errFunctionNotExists.GenByArgs("UUID_SHORT")
 }
+
+// UUIDToBin converts the string form of a UUID to its 16 bytes binary form, the string is 32 hexadecimal digits,
+// which may be grouped by hyphens as 8-4-4-4-12 and enclosed in braces. It returns false if the string is not a
+// valid UUID. If swap is true, the time-low and time-high parts are swapped, so the binary forms of the version 1
+// UUIDs, which are generated by UUID(), are ordered by their generated time.
+func UUIDToBin(str string, swap bool) ([]byte, bool) {
+	switch len(str) {
+	case 32:
+	case 36, 38:
+		if len(str) == 38 {
+			if str[0] != '{' || str[37] != '}' {
+				return nil, false
+			}
+			str = str[1:37]
+		}
+		if str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+			return nil, false
+		}
+		str = str[:8] + str[9:13] + str[14:18] + str[19:23] + str[24:]
+	default:
+		return nil, false
+	}
+	bin, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, false
+	}
+	if swap {
+		swapped := make([]byte, 0, 16)
+		swapped = append(swapped, bin[6:8]...)
+		swapped = append(swapped, bin[4:6]...)
+		swapped = append(swapped, bin[0:4]...)
+		bin = append(swapped, bin[8:]...)
+	}
+	return bin, true
+}
+
+// BinToUUID converts the 16 bytes binary form of a UUID to its string form, which is 32 lower case hexadecimal
+// digits grouped by hyphens as 8-4-4-4-12. If swap is true, the binary form is the one swapped by UUIDToBin.
+func BinToUUID(bin []byte, swap bool) string {
+	if swap {
+		swapped := make([]byte, 0, 16)
+		swapped = append(swapped, bin[4:8]...)
+		swapped = append(swapped, bin[2:4]...)
+		swapped = append(swapped, bin[0:2]...)
+		bin = append(swapped, bin[8:]...)
+	}
+	str := hex.EncodeToString(bin)
+	return str[:8] + "-" + str[8:12] + "-" + str[12:16] + "-" + str[16:20] + "-" + str[20:]
+}
+
+// evalUUIDSwapFlag evaluates the optional swap flag of UUID_TO_BIN and BIN_TO_UUID, a NULL flag is false.
+func evalUUIDSwapFlag(b *baseBuiltinFunc, row []types.Datum) (bool, error) {
+	if len(b.args) < 2 {
+		return false, nil
+	}
+	swap, isNull, err := b.args[1].EvalInt(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return false, errors.Trace(err)
+	}
+	return swap != 0, nil
+}
+
+type uuidToBinFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *uuidToBinFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString}
+	if len(args) == 2 {
+		argTps = append(argTps, tpInt)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
+	bf.tp.Flen = 16
+	types.SetBinChsClnFlag(bf.tp)
+	sig := &builtinUUIDToBinSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinUUIDToBinSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals UUID_TO_BIN(string_uuid[, swap_flag]).
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_uuid-to-bin
+func (b *builtinUUIDToBinSig) evalString(row []types.Datum) (string, bool, error) {
+	val, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	swap, err := evalUUIDSwapFlag(&b.baseBuiltinFunc, row)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	bin, ok := UUIDToBin(val, swap)
+	if !ok {
+		return "", true, errWrongValueForType.GenByArgs("string", val, "uuid_to_bin")
+	}
+	return string(bin), false, nil
+}
+
+type binToUUIDFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *binToUUIDFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString}
+	if len(args) == 2 {
+		argTps = append(argTps, tpInt)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps...)
+	bf.tp.Flen = 36
+	sig := &builtinBinToUUIDSig{baseStringBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinBinToUUIDSig struct {
+	baseStringBuiltinFunc
+}
+
+// evalString evals BIN_TO_UUID(binary_uuid[, swap_flag]).
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_bin-to-uuid
+func (b *builtinBinToUUIDSig) evalString(row []types.Datum) (string, bool, error) {
+	val, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	swap, err := evalUUIDSwapFlag(&b.baseBuiltinFunc, row)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	if len(val) != 16 {
+		return "", true, errWrongValueForType.GenByArgs("string", fmt.Sprintf("%X", val), "bin_to_uuid")
+	}
+	return BinToUUID([]byte(val), swap), false, nil
+}
+
+type isUUIDFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *isUUIDFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, tpString)
+	bf.tp.Flen = 1
+	sig := &builtinIsUUIDSig{baseIntBuiltinFunc{bf}}
+	return sig.setSelf(sig), nil
+}
+
+type builtinIsUUIDSig struct {
+	baseIntBuiltinFunc
+}
+
+// evalInt evals IS_UUID(string_uuid).
+// See https://dev.mysql.com/doc/refman/8.0/en/miscellaneous-functions.html#function_is-uuid
+func (b *builtinIsUUIDSig) evalInt(row []types.Datum) (int64, bool, error) {
+	val, isNull, err := b.args[0].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if _, ok := UUIDToBin(val, false); ok {
+		return 1, false, nil
+	}
+	return 0, false, nil
+}
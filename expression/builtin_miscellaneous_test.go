@@ -117,6 +117,67 @@ func (s *testEvaluatorSuite) TestUUID(c *C) {
 	c.Assert(bf.canBeFolded(), IsFalse)
 }
 
+func (s *testEvaluatorSuite) TestUUIDToBinAndBinToUUID(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		uuid   interface{}
+		swap   interface{}
+		bin    interface{}
+		isUUID interface{}
+	}{
+		{"6ccd780c-baba-1026-9564-5b8c656024db", 0, "6CCD780CBABA102695645B8C656024DB", 1},
+		{"6ccd780c-baba-1026-9564-5b8c656024db", 1, "1026BABA6CCD780C95645B8C656024DB", 1},
+		{"6ccd780c-baba-1026-9564-5b8c656024db", nil, "6CCD780CBABA102695645B8C656024DB", 1},
+		{"6CCD780CBABA102695645B8C656024DB", 0, "6CCD780CBABA102695645B8C656024DB", 1},
+		{"{6ccd780c-baba-1026-9564-5B8C656024DB}", 1, "1026BABA6CCD780C95645B8C656024DB", 1},
+		{"6ccd780cbaba-1026-9564-5b8c656024db", 0, nil, 0},
+		{"{6ccd780cbaba102695645b8c656024db}", 0, nil, 0},
+		{"6ccd780c-baba-1026-9564-5b8c656024dx", 0, nil, 0},
+		{"6ccd780c-baba-1026-9564", 0, nil, 0},
+		{nil, 0, nil, nil},
+	}
+	for _, t := range tests {
+		comment := Commentf("%v", t)
+		f, err := funcs[ast.UUIDToBin].getFunction(s.ctx, datumsToConstants(types.MakeDatums(t.uuid, t.swap)))
+		c.Assert(err, IsNil, comment)
+		bin, err := f.eval(nil)
+		if t.bin == nil && t.uuid != nil {
+			c.Assert(err, NotNil, comment)
+		} else {
+			c.Assert(err, IsNil, comment)
+			c.Assert(toHex(bin), testutil.DatumEquals, types.NewDatum(t.bin), comment)
+		}
+
+		f, err = funcs[ast.IsUUID].getFunction(s.ctx, datumsToConstants(types.MakeDatums(t.uuid)))
+		c.Assert(err, IsNil, comment)
+		isUUID, err := f.eval(nil)
+		c.Assert(err, IsNil, comment)
+		c.Assert(isUUID, testutil.DatumEquals, types.NewDatum(t.isUUID), comment)
+
+		if t.bin == nil {
+			continue
+		}
+		// BIN_TO_UUID returns the UUID in lower case with hyphens.
+		f, err = funcs[ast.BinToUUID].getFunction(s.ctx, []Expression{datumsToConstants([]types.Datum{fromHex(t.bin)})[0], datumsToConstants(types.MakeDatums(t.swap))[0]})
+		c.Assert(err, IsNil, comment)
+		uuid, err := f.eval(nil)
+		c.Assert(err, IsNil, comment)
+		c.Assert(uuid.GetString(), Equals, "6ccd780c-baba-1026-9564-5b8c656024db", comment)
+	}
+
+	f, err := funcs[ast.BinToUUID].getFunction(s.ctx, datumsToConstants(types.MakeDatums("123")))
+	c.Assert(err, IsNil)
+	_, err = f.eval(nil)
+	c.Assert(err, NotNil)
+	f, err = funcs[ast.BinToUUID].getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil)))
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
+	_, err = funcs[ast.UUIDToBin].getFunction(s.ctx, datumsToConstants(types.MakeDatums("a", 1, 2)))
+	c.Assert(err, NotNil)
+}
+
 func (s *testEvaluatorSuite) TestAnyValue(c *C) {
 	defer testleak.AfterTest(c)()
 
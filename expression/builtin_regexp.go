@@ -0,0 +1,484 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	_ functionClass = &regexpLikeFunctionClass{}
+	_ functionClass = &regexpInstrFunctionClass{}
+	_ functionClass = &regexpSubstrFunctionClass{}
+	_ functionClass = &regexpReplaceFunctionClass{}
+)
+
+var (
+	_ builtinFunc = &builtinRegexpLikeSig{}
+	_ builtinFunc = &builtinRegexpInstrSig{}
+	_ builtinFunc = &builtinRegexpSubstrSig{}
+	_ builtinFunc = &builtinRegexpReplaceSig{}
+)
+
+// regexpCacheCapacity is the max number of the compiled regexps kept by the regexp pool.
+const regexpCacheCapacity = 256
+
+type regexpCacheKey struct {
+	pattern string
+	flags   string
+}
+
+type regexpCacheEntry struct {
+	key regexpCacheKey
+	re  *regexp.Regexp
+}
+
+// regexpCache is a pool of the compiled regexps, the least recently used one is evicted when it's full.
+// The patterns are mostly constants, so the regexps are compiled once for all the rows and statements.
+type regexpCache struct {
+	sync.Mutex
+	capacity int
+	entries  map[regexpCacheKey]*list.Element
+	lru      *list.List
+}
+
+func newRegexpCache(capacity int) *regexpCache {
+	return &regexpCache{
+		capacity: capacity,
+		entries:  make(map[regexpCacheKey]*list.Element, capacity),
+		lru:      list.New(),
+	}
+}
+
+var regexpPool = newRegexpCache(regexpCacheCapacity)
+
+// compile returns the compiled regexp of the pattern with the Go regexp flags, such as "i" or "ms".
+func (c *regexpCache) compile(pattern, flags string) (*regexp.Regexp, error) {
+	key := regexpCacheKey{pattern: pattern, flags: flags}
+	c.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.Unlock()
+		return e.Value.(*regexpCacheEntry).re, nil
+	}
+	c.Unlock()
+
+	expr := pattern
+	if flags != "" {
+		expr = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errRegexp.GenByArgs(err.Error())
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*regexpCacheEntry).re, nil
+	}
+	c.entries[key] = c.lru.PushFront(&regexpCacheEntry{key: key, re: re})
+	for c.lru.Len() > c.capacity {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*regexpCacheEntry).key)
+	}
+	return re, nil
+}
+
+// regexpFuncBase has the common logics of the REGEXP_XXX functions.
+// The positions are counted by characters, or by bytes if the expression or the pattern is a binary string,
+// and so is the case sensitivity, the non-binary strings are matched case-insensitively by default.
+type regexpFuncBase struct {
+	binary bool
+}
+
+func newRegexpFuncBase(args []Expression) regexpFuncBase {
+	return regexpFuncBase{binary: types.IsBinaryStr(args[0].GetType()) || types.IsBinaryStr(args[1].GetType())}
+}
+
+// compile returns the compiled regexp of the pattern by the match type,
+// which consists of the characters:
+//
+//	c: case sensitive matching.
+//	i: case insensitive matching.
+//	m: multiple-line mode, ^ and $ match at the beginning and end of each line.
+//	n: the . character matches line terminators.
+//	u: Unix-only line endings, it's the only line ending of the Go regexp.
+//
+// The rightmost one wins if the characters contradict each other.
+func (r *regexpFuncBase) compile(fnName, pattern, matchType string) (*regexp.Regexp, error) {
+	caseSensitive, multiLine, dotAll := r.binary, false, false
+	for _, ch := range matchType {
+		switch ch {
+		case 'c':
+			caseSensitive = true
+		case 'i':
+			caseSensitive = false
+		case 'm':
+			multiLine = true
+		case 'n':
+			dotAll = true
+		case 'u':
+		default:
+			return nil, errIncorrectArgs.GenByArgs(fnName)
+		}
+	}
+	flags := ""
+	if !caseSensitive {
+		flags += "i"
+	}
+	if multiLine {
+		flags += "m"
+	}
+	if dotAll {
+		flags += "s"
+	}
+	return regexpPool.compile(pattern, flags)
+}
+
+// byteOffset returns the byte offset of the 1-based position in str, the position may be just
+// after the end of str. It returns an error if the position is out of the range.
+func (r *regexpFuncBase) byteOffset(str string, pos int64) (int, error) {
+	if pos < 1 {
+		return 0, errRegexp.GenByArgs("index out of bounds")
+	}
+	if r.binary {
+		if pos > int64(len(str))+1 {
+			return 0, errRegexp.GenByArgs("index out of bounds")
+		}
+		return int(pos - 1), nil
+	}
+	offset := 0
+	for i := int64(1); i < pos; i++ {
+		if offset >= len(str) {
+			return 0, errRegexp.GenByArgs("index out of bounds")
+		}
+		_, size := utf8.DecodeRuneInString(str[offset:])
+		offset += size
+	}
+	return offset, nil
+}
+
+// position returns the 1-based position of the byte offset in str.
+func (r *regexpFuncBase) position(str string, offset int) int64 {
+	if r.binary {
+		return int64(offset) + 1
+	}
+	return int64(utf8.RuneCountInString(str[:offset])) + 1
+}
+
+// findMatch returns the byte indexes of the submatches of the occurrence-th match in str from the offset,
+// it returns nil if there are not so many matches. The occurrence less than 1 is treated as 1.
+func (r *regexpFuncBase) findMatch(re *regexp.Regexp, str string, offset int, occurrence int64) []int {
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	matches := re.FindAllStringSubmatchIndex(str[offset:], int(occurrence))
+	if int64(len(matches)) < occurrence {
+		return nil
+	}
+	loc := matches[occurrence-1]
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += offset
+		}
+	}
+	return loc
+}
+
+// evalOptionalInt evaluates the i-th argument if it exists, or returns the default value.
+func evalOptionalInt(b *baseBuiltinFunc, row []types.Datum, i int, defaultVal int64) (int64, bool, error) {
+	if i >= len(b.args) {
+		return defaultVal, false, nil
+	}
+	return b.args[i].EvalInt(row, b.ctx.GetSessionVars().StmtCtx)
+}
+
+// evalOptionalString evaluates the i-th argument if it exists, or returns the default value.
+func evalOptionalString(b *baseBuiltinFunc, row []types.Datum, i int, defaultVal string) (string, bool, error) {
+	if i >= len(b.args) {
+		return defaultVal, false, nil
+	}
+	return b.args[i].EvalString(row, b.ctx.GetSessionVars().StmtCtx)
+}
+
+type regexpLikeFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpLikeFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString, tpString}
+	if len(args) == 3 {
+		argTps = append(argTps, tpString)
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, argTps...)
+	bf.tp.Flen = 1
+	sig := &builtinRegexpLikeSig{baseIntBuiltinFunc{bf}, newRegexpFuncBase(args)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRegexpLikeSig struct {
+	baseIntBuiltinFunc
+	regexpFuncBase
+}
+
+// evalInt evals REGEXP_LIKE(expr, pat[, match_type]).
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-like
+func (b *builtinRegexpLikeSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	pat, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	matchType, isNull, err := evalOptionalString(&b.baseBuiltinFunc, row, 2, "")
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	re, err := b.compile(ast.RegexpLike, pat, matchType)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	return boolToInt64(re.MatchString(expr)), false, nil
+}
+
+type regexpInstrFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpInstrFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString, tpString, tpInt, tpInt, tpInt, tpString}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpInt, argTps[:len(args)]...)
+	sig := &builtinRegexpInstrSig{baseIntBuiltinFunc{bf}, newRegexpFuncBase(args)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRegexpInstrSig struct {
+	baseIntBuiltinFunc
+	regexpFuncBase
+}
+
+// evalInt evals REGEXP_INSTR(expr, pat[, pos[, occurrence[, return_option[, match_type]]]]).
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-instr
+func (b *builtinRegexpInstrSig) evalInt(row []types.Datum) (int64, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	pat, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	pos, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 2, 1)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	occurrence, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 3, 1)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	returnOption, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 4, 0)
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	if returnOption != 0 && returnOption != 1 {
+		return 0, true, errIncorrectArgs.GenByArgs(ast.RegexpInstr)
+	}
+	matchType, isNull, err := evalOptionalString(&b.baseBuiltinFunc, row, 5, "")
+	if isNull || err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	re, err := b.compile(ast.RegexpInstr, pat, matchType)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	offset, err := b.byteOffset(expr, pos)
+	if err != nil {
+		return 0, true, errors.Trace(err)
+	}
+	loc := b.findMatch(re, expr, offset, occurrence)
+	if loc == nil {
+		return 0, false, nil
+	}
+	return b.position(expr, loc[returnOption]), false, nil
+}
+
+type regexpSubstrFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpSubstrFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString, tpString, tpInt, tpInt, tpString}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps[:len(args)]...)
+	bf.tp.Flen = args[0].GetType().Flen
+	SetBinFlagOrBinStr(args[0].GetType(), bf.tp)
+	sig := &builtinRegexpSubstrSig{baseStringBuiltinFunc{bf}, newRegexpFuncBase(args)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRegexpSubstrSig struct {
+	baseStringBuiltinFunc
+	regexpFuncBase
+}
+
+// evalString evals REGEXP_SUBSTR(expr, pat[, pos[, occurrence[, match_type]]]).
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-substr
+func (b *builtinRegexpSubstrSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	pat, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	pos, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 2, 1)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	occurrence, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 3, 1)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	matchType, isNull, err := evalOptionalString(&b.baseBuiltinFunc, row, 4, "")
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	re, err := b.compile(ast.RegexpSubstr, pat, matchType)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	offset, err := b.byteOffset(expr, pos)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	loc := b.findMatch(re, expr, offset, occurrence)
+	if loc == nil {
+		return "", true, nil
+	}
+	return expr[loc[0]:loc[1]], false, nil
+}
+
+type regexpReplaceFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *regexpReplaceFunctionClass) getFunction(ctx context.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, errors.Trace(err)
+	}
+	argTps := []evalTp{tpString, tpString, tpString, tpInt, tpInt, tpString}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, argTps[:len(args)]...)
+	bf.tp.Flen = mysql.MaxBlobWidth
+	SetBinFlagOrBinStr(args[0].GetType(), bf.tp)
+	sig := &builtinRegexpReplaceSig{baseStringBuiltinFunc{bf}, newRegexpFuncBase(args)}
+	return sig.setSelf(sig), nil
+}
+
+type builtinRegexpReplaceSig struct {
+	baseStringBuiltinFunc
+	regexpFuncBase
+}
+
+// evalString evals REGEXP_REPLACE(expr, pat, repl[, pos[, occurrence[, match_type]]]).
+// The occurrence 0 replaces all the matches, and the repl may refer to the submatches by $1 or ${1}.
+// See https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-replace
+func (b *builtinRegexpReplaceSig) evalString(row []types.Datum) (string, bool, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	expr, isNull, err := b.args[0].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	pat, isNull, err := b.args[1].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	repl, isNull, err := b.args[2].EvalString(row, sc)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	pos, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 3, 1)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	occurrence, isNull, err := evalOptionalInt(&b.baseBuiltinFunc, row, 4, 0)
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	matchType, isNull, err := evalOptionalString(&b.baseBuiltinFunc, row, 5, "")
+	if isNull || err != nil {
+		return "", true, errors.Trace(err)
+	}
+	re, err := b.compile(ast.RegexpReplace, pat, matchType)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+	offset, err := b.byteOffset(expr, pos)
+	if err != nil {
+		return "", true, errors.Trace(err)
+	}
+
+	var matches [][]int
+	if occurrence > 0 {
+		if loc := b.findMatch(re, expr, offset, occurrence); loc != nil {
+			matches = [][]int{loc}
+		}
+	} else {
+		matches = re.FindAllStringSubmatchIndex(expr[offset:], -1)
+		for _, loc := range matches {
+			for i := range loc {
+				if loc[i] >= 0 {
+					loc[i] += offset
+				}
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return expr, false, nil
+	}
+	result := make([]byte, 0, len(expr))
+	last := 0
+	for _, loc := range matches {
+		result = append(result, expr[last:loc[0]]...)
+		result = re.ExpandString(result, repl, expr, loc)
+		last = loc[1]
+	}
+	result = append(result, expr[last:]...)
+	return string(result), false, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testEvaluatorSuite) TestRegexpCache(c *C) {
+	defer testleak.AfterTest(c)()
+	cache := newRegexpCache(2)
+	re1, err := cache.compile("a.c", "")
+	c.Assert(err, IsNil)
+	c.Assert(re1.MatchString("ABC"), IsFalse)
+	re2, err := cache.compile("a.c", "i")
+	c.Assert(err, IsNil)
+	c.Assert(re2.MatchString("ABC"), IsTrue)
+	re, err := cache.compile("a.c", "")
+	c.Assert(err, IsNil)
+	c.Assert(re, Equals, re1)
+
+	// The least recently used one is evicted.
+	_, err = cache.compile("b", "")
+	c.Assert(err, IsNil)
+	c.Assert(cache.lru.Len(), Equals, 2)
+	re, err = cache.compile("a.c", "i")
+	c.Assert(err, IsNil)
+	c.Assert(re, Not(Equals), re2)
+	re, err = cache.compile("a.c", "")
+	c.Assert(err, IsNil)
+	c.Assert(re, Not(Equals), re1)
+
+	_, err = cache.compile("a(", "")
+	c.Assert(err, NotNil)
+	c.Assert(cache.lru.Len(), Equals, 2)
+}
+
+func (s *testEvaluatorSuite) TestRegexpFuncs(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		fn     string
+		args   []interface{}
+		expect interface{}
+		err    bool
+	}{
+		{ast.RegexpLike, []interface{}{"Michael!", ".*"}, int64(1), false},
+		{ast.RegexpLike, []interface{}{"abc", "ABC"}, int64(1), false},
+		{ast.RegexpLike, []interface{}{"abc", "ABC", "c"}, int64(0), false},
+		{ast.RegexpLike, []interface{}{"abc", "ABC", "ci"}, int64(1), false},
+		{ast.RegexpLike, []interface{}{"abc", "ABC", "ic"}, int64(0), false},
+		{ast.RegexpLike, []interface{}{"a\nb", "^b$"}, int64(0), false},
+		{ast.RegexpLike, []interface{}{"a\nb", "^b$", "m"}, int64(1), false},
+		{ast.RegexpLike, []interface{}{"a\nb", "a.b"}, int64(0), false},
+		{ast.RegexpLike, []interface{}{"a\nb", "a.b", "n"}, int64(1), false},
+		{ast.RegexpLike, []interface{}{nil, "a"}, nil, false},
+		{ast.RegexpLike, []interface{}{"a", nil}, nil, false},
+		{ast.RegexpLike, []interface{}{"a", "a", nil}, nil, false},
+		{ast.RegexpLike, []interface{}{"a", "a", "x"}, nil, true},
+		{ast.RegexpLike, []interface{}{"a", "a("}, nil, true},
+
+		{ast.RegexpInstr, []interface{}{"dog cat dog", "dog"}, int64(1), false},
+		{ast.RegexpInstr, []interface{}{"dog cat dog", "dog", 2}, int64(9), false},
+		{ast.RegexpInstr, []interface{}{"dog cat dog", "dog", 1, 2}, int64(9), false},
+		{ast.RegexpInstr, []interface{}{"dog cat dog", "dog", 1, 3}, int64(0), false},
+		{ast.RegexpInstr, []interface{}{"dog cat dog", "dog", 1, 2, 1}, int64(12), false},
+		{ast.RegexpInstr, []interface{}{"aa aaa aaaa", "a{4}"}, int64(8), false},
+		{ast.RegexpInstr, []interface{}{"你好世界", "世"}, int64(3), false},
+		{ast.RegexpInstr, []interface{}{"你好世界", "界", 1, 1, 1}, int64(5), false},
+		{ast.RegexpInstr, []interface{}{"abc", "b", 4}, int64(0), false},
+		{ast.RegexpInstr, []interface{}{"abc", "B", 1, 1, 0, "c"}, int64(0), false},
+		{ast.RegexpInstr, []interface{}{"abc", "b", nil}, nil, false},
+		{ast.RegexpInstr, []interface{}{"abc", "b", 5}, nil, true},
+		{ast.RegexpInstr, []interface{}{"abc", "b", 0}, nil, true},
+		{ast.RegexpInstr, []interface{}{"abc", "b", 1, 1, 2}, nil, true},
+
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+"}, "abc", false},
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+", 1, 3}, "ghi", false},
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+", 6}, "ef", false},
+		{ast.RegexpSubstr, []interface{}{"abc def ghi", "[a-z]+", 1, 4}, nil, false},
+		{ast.RegexpSubstr, []interface{}{"你好世界", ".", 3}, "世", false},
+		{ast.RegexpSubstr, []interface{}{"abc", "B", 1, 1, "c"}, nil, false},
+		{ast.RegexpSubstr, []interface{}{"abc", "B", 1, 1, "i"}, "b", false},
+		{ast.RegexpSubstr, []interface{}{"abc", "b", 1, nil}, nil, false},
+		{ast.RegexpSubstr, []interface{}{"abc", "b", -1}, nil, true},
+
+		{ast.RegexpReplace, []interface{}{"a b c", "b", "X"}, "a X c", false},
+		{ast.RegexpReplace, []interface{}{"abc def ghi", "[a-z]+", "X", 1, 3}, "abc def X", false},
+		{ast.RegexpReplace, []interface{}{"abc def ghi", "[a-z]+", "X", 2}, "aX X X", false},
+		{ast.RegexpReplace, []interface{}{"abc def ghi", "[a-z]+", "X", 2, 2}, "abc X ghi", false},
+		{ast.RegexpReplace, []interface{}{"abc def ghi", "[a-z]+", "X", 1, 4}, "abc def ghi", false},
+		{ast.RegexpReplace, []interface{}{"abc def", "([a-z])([a-z]+)", "$2$1"}, "bca efd", false},
+		{ast.RegexpReplace, []interface{}{"你好世界", "好|界", "们"}, "你们世们", false},
+		{ast.RegexpReplace, []interface{}{"ABC", "b", "x", 1, 0, "c"}, "ABC", false},
+		{ast.RegexpReplace, []interface{}{"ABC", "b", "x"}, "AxC", false},
+		{ast.RegexpReplace, []interface{}{"abc", "b", nil}, nil, false},
+		{ast.RegexpReplace, []interface{}{"abc", "b", "x", 5}, nil, true},
+	}
+	for _, t := range tests {
+		comment := Commentf("%s%v", t.fn, t.args)
+		f, err := funcs[t.fn].getFunction(s.ctx, datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil, comment)
+		d, err := f.eval(nil)
+		if t.err {
+			c.Assert(err, NotNil, comment)
+			continue
+		}
+		c.Assert(err, IsNil, comment)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expect), comment)
+	}
+
+	// The argument count is checked.
+	_, err := funcs[ast.RegexpLike].getFunction(s.ctx, datumsToConstants(types.MakeDatums("a")))
+	c.Assert(err, NotNil)
+	_, err = funcs[ast.RegexpReplace].getFunction(s.ctx, datumsToConstants(types.MakeDatums("a", "b")))
+	c.Assert(err, NotNil)
+}
@@ -1271,7 +1271,9 @@ func (b *builtinHexStrArgSig) evalString(row []types.Datum) (string, bool, error
 	if isNull || err != nil {
 		return d, isNull, errors.Trace(err)
 	}
-	return strings.ToUpper(hex.EncodeToString(hack.Slice(d))), false, nil
+	// The string literals in the connection charset are shown by their bytes in the charset.
+	data, _ := charset.EncodeString(b.args[0].GetType().Charset, hack.Slice(d))
+	return strings.ToUpper(hex.EncodeToString(data)), false, nil
 }
 
 type builtinHexIntArgSig struct {
@@ -2598,11 +2600,20 @@ func (b *builtinFormatWithLocaleSig) evalString(row []types.Datum) (string, bool
 	}
 
 	locale, isNull, err := b.args[2].EvalString(row, sc)
-	if isNull || err != nil {
+	if err != nil {
 		return "", true, errors.Trace(err)
 	}
+	if isNull {
+		locale = "NULL"
+	}
 
-	formatString, err := mysql.GetLocaleFormatFunction(locale)(x, d)
+	// The unknown locale is treated as en_US with a warning.
+	formatFunc := mysql.GetLocaleFormatFunction(locale)
+	if formatFunc == nil {
+		sc.AppendWarning(errUnknownLocale.GenByArgs(locale))
+		formatFunc = mysql.GetLocaleFormatFunction("en_US")
+	}
+	formatString, err := formatFunc(x, d)
 	return formatString, err != nil, errors.Trace(err)
 }
 
@@ -2674,7 +2685,14 @@ func (c *toBase64FunctionClass) getFunction(ctx context.Context, args []Expressi
 		return nil, errors.Trace(err)
 	}
 	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString)
-	bf.tp.Flen = base64NeededEncodedLength(bf.args[0].GetType().Flen)
+	if argLen := bf.args[0].GetType().Flen; argLen == types.UnspecifiedLength {
+		bf.tp.Flen = mysql.MaxBlobWidth
+	} else {
+		bf.tp.Flen = base64NeededEncodedLength(argLen)
+	}
+	if bf.tp.Flen == -1 || bf.tp.Flen > mysql.MaxBlobWidth {
+		bf.tp.Flen = mysql.MaxBlobWidth
+	}
 	sig := &builtinToBase64Sig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
 }
@@ -2715,7 +2733,8 @@ func (b *builtinToBase64Sig) evalString(row []types.Datum) (d string, isNull boo
 		return "", isNull, errors.Trace(err)
 	}
 
-	if b.tp.Flen == -1 || b.tp.Flen > mysql.MaxBlobWidth {
+	// The result is NULL if it's too long.
+	if needEncodeLen := base64NeededEncodedLength(len(str)); needEncodeLen == -1 || needEncodeLen > mysql.MaxBlobWidth {
 		return "", true, nil
 	}
 
@@ -23,6 +23,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
@@ -1432,33 +1433,38 @@ func (s *testEvaluatorSuite) TestFormat(c *C) {
 	formatTests := []struct {
 		number    interface{}
 		precision interface{}
-		locale    string
+		locale    interface{}
 		ret       interface{}
 	}{
-		{12332.1234561111111111111111111111111111111111111, 4, "en_US", "12,332.1234"},
+		{12332.1234561111111111111111111111111111111111111, 4, "en_US", "12,332.1235"},
 		{nil, 22, "en_US", nil},
+		{-12332.123456, -4, "zh_CN", "-12,332"},
+		{12332.2, 2, "de_DE", "12.332,20"},
+		{-1234567.891, 1, "DE_de", "-1.234.567,9"},
+		{12332.2, 2, "EN_us", "12,332.20"},
+		{-1234567.891, 2, "fr_FR", "-1234567,89"},
 	}
 	formatTests1 := []struct {
 		number    interface{}
 		precision interface{}
 		ret       interface{}
 	}{
-		{12332.123456, 4, "12,332.1234"},
+		{12332.123456, 4, "12,332.1235"},
 		{12332.123456, 0, "12,332"},
 		{12332.123456, -4, "12,332"},
-		{-12332.123456, 4, "-12,332.1234"},
+		{-12332.123456, 4, "-12,332.1235"},
 		{-12332.123456, 0, "-12,332"},
 		{-12332.123456, -4, "-12,332"},
-		{"12332.123456", "4", "12,332.1234"},
-		{"12332.123456A", "4", "12,332.1234"},
-		{"-12332.123456", "4", "-12,332.1234"},
-		{"-12332.123456A", "4", "-12,332.1234"},
+		{"12332.123456", "4", "12,332.1235"},
+		{"12332.123456A", "4", "12,332.1235"},
+		{"-12332.123456", "4", "-12,332.1235"},
+		{"-12332.123456A", "4", "-12,332.1235"},
 		{"A123345", "4", "0.0000"},
 		{"-A123345", "4", "0.0000"},
 		{"-12332.123456", "A", "-12,332"},
 		{"12332.123456", "A", "12,332"},
-		{"-12332.123456", "4A", "-12,332.1234"},
-		{"12332.123456", "4A", "12,332.1234"},
+		{"-12332.123456", "4A", "-12,332.1235"},
+		{"12332.123456", "4A", "12,332.1235"},
 		{"-A12332.123456", "A", "0"},
 		{"A12332.123456", "A", "0"},
 		{"-A12332.123456", "4A", "0.0000"},
@@ -1466,20 +1472,17 @@ func (s *testEvaluatorSuite) TestFormat(c *C) {
 		{"-.12332.123456", "4A", "-0.1233"},
 		{".12332.123456", "4A", "0.1233"},
 		{"12332.1234567890123456789012345678901", 22, "12,332.1234567890123456789012"},
+		{"0.1", 40, "0.100000000000000000000000000000"},
+		{0.5, 0, "1"},
+		{-0.5, 0, "-1"},
+		{-0.001, 2, "0.00"},
+		{999.999, 2, "1,000.00"},
+		{"1.5e3", 1, "1,500.0"},
+		{"-1.23456e-2", 3, "-0.012"},
+		{"", 2, "0.00"},
+		{123, "", "123"},
 		{nil, 22, nil},
 	}
-	formatTests2 := struct {
-		number    interface{}
-		precision interface{}
-		locale    string
-		ret       interface{}
-	}{-12332.123456, -4, "zh_CN", nil}
-	formatTests3 := struct {
-		number    interface{}
-		precision interface{}
-		locale    string
-		ret       interface{}
-	}{"-12332.123456", "4", "de_GE", nil}
 
 	for _, tt := range formatTests {
 		fc := funcs[ast.Format]
@@ -1489,7 +1492,7 @@ func (s *testEvaluatorSuite) TestFormat(c *C) {
 		c.Assert(f.canBeFolded(), IsTrue)
 		r, err := f.eval(nil)
 		c.Assert(err, IsNil)
-		c.Assert(r, testutil.DatumEquals, types.NewDatum(tt.ret))
+		c.Assert(r, testutil.DatumEquals, types.NewDatum(tt.ret), Commentf("%v", tt))
 	}
 
 	for _, tt := range formatTests1 {
@@ -1500,26 +1503,22 @@ func (s *testEvaluatorSuite) TestFormat(c *C) {
 		c.Assert(f.canBeFolded(), IsTrue)
 		r, err := f.eval(nil)
 		c.Assert(err, IsNil)
-		c.Assert(r, testutil.DatumEquals, types.NewDatum(tt.ret))
+		c.Assert(r, testutil.DatumEquals, types.NewDatum(tt.ret), Commentf("%v", tt))
 	}
 
-	fc2 := funcs[ast.Format]
-	f2, err := fc2.getFunction(s.ctx, datumsToConstants(types.MakeDatums(formatTests2.number, formatTests2.precision, formatTests2.locale)))
-	c.Assert(err, IsNil)
-	c.Assert(f2, NotNil)
-	c.Assert(f2.canBeFolded(), IsTrue)
-	r2, err := f2.eval(nil)
-	c.Assert(types.NewDatum(err), testutil.DatumEquals, types.NewDatum(errors.New("not implemented")))
-	c.Assert(r2, testutil.DatumEquals, types.NewDatum(formatTests2.ret))
-
-	fc3 := funcs[ast.Format]
-	f3, err := fc3.getFunction(s.ctx, datumsToConstants(types.MakeDatums(formatTests3.number, formatTests3.precision, formatTests3.locale)))
-	c.Assert(err, IsNil)
-	c.Assert(f3, NotNil)
-	c.Assert(f3.canBeFolded(), IsTrue)
-	r3, err := f3.eval(nil)
-	c.Assert(types.NewDatum(err), testutil.DatumEquals, types.NewDatum(errors.New("not support for the specific locale")))
-	c.Assert(r3, testutil.DatumEquals, types.NewDatum(formatTests3.ret))
+	// The unknown locale is treated as en_US with a warning.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	for _, locale := range []interface{}{"de_GE", nil} {
+		warnCnt := len(sc.GetWarnings())
+		f, err := funcs[ast.Format].getFunction(s.ctx, datumsToConstants(types.MakeDatums("-12332.123456", "4", locale)))
+		c.Assert(err, IsNil)
+		r, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(r, testutil.DatumEquals, types.NewDatum("-12,332.1235"))
+		warnings := sc.GetWarnings()
+		c.Assert(len(warnings), Equals, warnCnt+1)
+		c.Assert(terror.ErrorEqual(errUnknownLocale, warnings[len(warnings)-1]), IsTrue)
+	}
 }
 
 func (s *testEvaluatorSuite) TestFromBase64(c *C) {
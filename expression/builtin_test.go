@@ -15,6 +15,7 @@ package expression
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
@@ -22,8 +23,13 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -150,20 +156,54 @@ func (s *testEvaluatorSuite) TestIsNullFunc(c *C) {
 
 func (s *testEvaluatorSuite) TestLock(c *C) {
 	defer testleak.AfterTest(c)()
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://user_lock_test")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	ctx1, ctx2 := mock.NewContext(), mock.NewContext()
+	ctx1.Store, ctx2.Store = store, store
+	ctx1.GetSessionVars().ConnectionID, ctx2.GetSessionVars().ConnectionID = 1, 2
+
+	tests := []struct {
+		ctx    *mock.Context
+		fn     string
+		args   []interface{}
+		expect interface{}
+	}{
+		{ctx1, ast.GetLock, []interface{}{"lock1", 1}, int64(1)},
+		{ctx1, ast.GetLock, []interface{}{"LOCK1", nil}, int64(1)},
+		{ctx2, ast.GetLock, []interface{}{"lock1", 0}, int64(0)},
+		{ctx2, ast.IsFreeLock, []interface{}{"lock1"}, int64(0)},
+		{ctx2, ast.IsUsedLock, []interface{}{"lock1"}, uint64(1)},
+		{ctx2, ast.ReleaseLock, []interface{}{"lock1"}, int64(0)},
+		{ctx2, ast.ReleaseLock, []interface{}{"lock2"}, nil},
+		{ctx2, ast.GetLock, []interface{}{"lock2", 1}, int64(1)},
+		{ctx1, ast.ReleaseLock, []interface{}{"lock1"}, int64(1)},
+		{ctx2, ast.GetLock, []interface{}{"lock1", 0}, int64(0)},
+		{ctx1, ast.ReleaseLock, []interface{}{"lock1"}, int64(1)},
+		{ctx1, ast.ReleaseLock, []interface{}{"lock1"}, nil},
+		{ctx2, ast.IsFreeLock, []interface{}{"lock1"}, int64(1)},
+		{ctx2, ast.IsUsedLock, []interface{}{"lock1"}, nil},
+		{ctx2, ast.GetLock, []interface{}{"lock1", 0}, int64(1)},
+		{ctx1, ast.ReleaseAllLocks, nil, int64(0)},
+		{ctx2, ast.ReleaseAllLocks, nil, int64(2)},
+		{ctx1, ast.IsFreeLock, []interface{}{"lock2"}, int64(1)},
+	}
+	for i, t := range tests {
+		comment := Commentf("%d %s%v", i, t.fn, t.args)
+		f, err := funcs[t.fn].getFunction(t.ctx, datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil, comment)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil, comment)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expect), comment)
+	}
 
-	lock := funcs[ast.GetLock]
-	f, err := lock.getFunction(s.ctx, datumsToConstants(types.MakeDatums(nil, 1)))
-	c.Assert(err, IsNil)
-	v, err := f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
-
-	releaseLock := funcs[ast.ReleaseLock]
-	f, err = releaseLock.getFunction(s.ctx, datumsToConstants(types.MakeDatums(1)))
-	c.Assert(err, IsNil)
-	v, err = f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	// The lock name must be a non-empty string of at most 64 characters.
+	for _, name := range []interface{}{nil, "", strings.Repeat("a", 65)} {
+		f, err := funcs[ast.GetLock].getFunction(ctx1, datumsToConstants(types.MakeDatums(name, 0)))
+		c.Assert(err, IsNil)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, errUserLockWrongName), IsTrue, Commentf("%v", name))
+	}
 }
 
 // newFunctionForTest creates a new ScalarFunction using funcName and arguments,
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tipb/go-tipb"
 )
 
 const ( // GET_FORMAT first argument.
@@ -50,6 +52,26 @@ const ( // GET_FORMAT location.
 	internalLocation = "INTERNAL"
 )
 
+// timeFunctionNameToPB is for pushdown time functions to storage engine.
+var timeFunctionNameToPB = map[string]tipb.ExprType{
+	ast.Date:        tipb.ExprType_Date,
+	ast.Year:        tipb.ExprType_Year,
+	ast.YearWeek:    tipb.ExprType_YearWeek,
+	ast.Month:       tipb.ExprType_Month,
+	ast.Week:        tipb.ExprType_Week,
+	ast.Weekday:     tipb.ExprType_Weekday,
+	ast.WeekOfYear:  tipb.ExprType_WeekOfYear,
+	ast.Day:         tipb.ExprType_Day,
+	ast.DayName:     tipb.ExprType_DayName,
+	ast.DayOfYear:   tipb.ExprType_DayOfYear,
+	ast.DayOfMonth:  tipb.ExprType_DayOfMonth,
+	ast.DayOfWeek:   tipb.ExprType_DayOfWeek,
+	ast.Hour:        tipb.ExprType_Hour,
+	ast.Minute:      tipb.ExprType_Minute,
+	ast.Second:      tipb.ExprType_Second,
+	ast.MicroSecond: tipb.ExprType_Microsecond,
+}
+
 // DurationPattern determine whether to match the format of duration.
 var DurationPattern = regexp.MustCompile(`^(|[-]?)(|\d{1,2}\s)(\d{2,3}:\d{2}:\d{2}|\d{1,2}:\d{2}|\d{1,6})(|\.\d*)$`)
 
@@ -447,10 +469,23 @@ func (b *builtinDateFormatSig) evalString(row []types.Datum) (string, bool, erro
 		return "", isNull, errors.Trace(err)
 	}
 
-	res, err := t.DateFormat(formatMask)
+	res, err := t.DateFormatWithLocale(formatMask, getLcTimeNames(b.ctx))
 	return res, isNull, errors.Trace(err)
 }
 
+// getLcTimeNames returns the locale of lc_time_names, which is used to format the names of the months and the days.
+func getLcTimeNames(ctx context.Context) *mysql.Locale {
+	name, ok := ctx.GetSessionVars().Systems[variable.LcTimeNames]
+	if !ok {
+		return mysql.LocaleENUS
+	}
+	loc := mysql.GetLocale(name)
+	if loc == nil {
+		return mysql.LocaleENUS
+	}
+	return loc
+}
+
 // builtinDateFormat ...
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_date-format
 func builtinDateFormat(ctx context.Context, args []types.Datum) (d types.Datum, err error) {
@@ -704,7 +739,7 @@ func (b *builtinMonthNameSig) evalString(row []types.Datum) (string, bool, error
 	} else if mon == 0 {
 		return "", true, nil
 	}
-	return types.MonthNames[mon-1], false, nil
+	return getLcTimeNames(b.ctx).MonthNames[mon-1], false, nil
 }
 
 type dayNameFunctionClass struct {
@@ -739,7 +774,7 @@ func (b *builtinDayNameSig) evalString(row []types.Datum) (string, bool, error)
 	// but in go, Sunday is 0, ... Saturday is 6
 	// w will do a conversion.
 	res := (int64(arg.Time.Weekday()) + 6) % 7
-	return types.WeekdayNames[res], false, nil
+	return getLcTimeNames(b.ctx).DayNames[res], false, nil
 }
 
 type dayOfMonthFunctionClass struct {
@@ -879,7 +914,7 @@ func (b *builtinWeekWithModeSig) evalInt(row []types.Datum) (int64, bool, error)
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, err))
 	}
 
-	if date.IsZero() {
+	if date.InvalidZero() {
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
 
@@ -906,7 +941,7 @@ func (b *builtinWeekWithoutModeSig) evalInt(row []types.Datum) (int64, bool, err
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, err))
 	}
 
-	if date.IsZero() {
+	if date.InvalidZero() {
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
 
@@ -978,7 +1013,7 @@ func (b *builtinWeekOfYearSig) evalInt(row []types.Datum) (int64, bool, error) {
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, err))
 	}
 
-	if date.IsZero() {
+	if date.InvalidZero() {
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
 
@@ -1060,7 +1095,7 @@ func (b *builtinYearWeekWithModeSig) evalInt(row []types.Datum) (int64, bool, er
 	if isNull || err != nil {
 		return 0, isNull, errors.Trace(handleInvalidTimeError(b.ctx, err))
 	}
-	if date.IsZero() {
+	if date.InvalidZero() {
 		return 0, true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
 
@@ -1227,7 +1262,7 @@ func (b *builtinFromUnixTime2ArgSig) evalString(row []types.Datum) (res string,
 	if isNull || err != nil {
 		return "", isNull, errors.Trace(err)
 	}
-	res, err = t.DateFormat(format)
+	res, err = t.DateFormatWithLocale(format, getLcTimeNames(b.ctx))
 	return res, err != nil, errors.Trace(err)
 }
 
@@ -2027,15 +2062,40 @@ func (b *builtinDateArithSig) eval(row []types.Datum) (d types.Datum, err error)
 		return d, errors.Trace(err)
 	}
 	t = t.Add(dur)
-	t = t.AddDate(int(year), int(month), int(day))
+	t, err = addDate(t, year, month, day)
+	if err != nil {
+		return d, errors.Trace(handleInvalidTimeError(b.ctx, err))
+	}
 	if t.Nanosecond() == 0 {
 		result.Fsp = 0
 	}
 	result.Time = types.FromGoTime(t)
+	if err = result.Check(); err != nil {
+		return d, errors.Trace(handleInvalidTimeError(b.ctx, err))
+	}
 	d.SetMysqlTime(result)
 	return
 }
 
+// addDate adds the years, months and days to t. The day is clamped to the last day of the month if the month
+// that the years and months are added to has fewer days, as MySQL does, e.g. 2003-01-31 plus one month is 2003-02-28.
+func addDate(t time.Time, years, months, days int64) (time.Time, error) {
+	// The intervals out of these ranges always overflow the years that MySQL supports.
+	if years > 10000 || years < -10000 || months > 120000 || months < -120000 ||
+		days > 3660000 || days < -3660000 {
+		return t, errors.Trace(types.ErrInvalidTimeFormat)
+	}
+	if years != 0 || months != 0 {
+		year, month, day := t.Date()
+		first := time.Date(year+int(years), month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+			day = lastDay
+		}
+		t = first.AddDate(0, 0, day-1)
+	}
+	return t.AddDate(0, 0, int(days)), nil
+}
+
 var reg = regexp.MustCompile(`[\d]+`)
 
 func parseDayInterval(sc *variable.StatementContext, value types.Datum) (int64, error) {
@@ -2344,86 +2404,125 @@ func isDuration(str string) bool {
 	return DurationPattern.MatchString(str)
 }
 
-// strDatetimeAddDuration adds duration to datetime string, returns a datum value.
-func strDatetimeAddDuration(d string, arg1 types.Duration) (result types.Datum, err error) {
-	arg0, err := types.ParseTime(d, mysql.TypeDatetime, getFsp(d))
-	if err != nil {
-		return result, errors.Trace(err)
-	}
-	tmp, err1 := arg0.Add(arg1)
-	if err1 != nil {
-		err = errors.Trace(err)
-		return
-	}
-	if getFsp(d) != 0 {
-		tmp.Fsp = types.MaxFsp
-	} else {
-		tmp.Fsp = types.MinFsp
-	}
-	result.SetString(tmp.String())
-	return
+// isDatetimeStr returns a boolean indicating whether the str has a date part, so it's a datetime rather than a time.
+func isDatetimeStr(str string) bool {
+	return strings.Contains(strings.TrimPrefix(strings.TrimSpace(str), "-"), "-")
 }
 
-// strDurationAddDuration adds duration to duration string, returns a datum value.
-func strDurationAddDuration(d string, arg1 types.Duration) (result types.Datum, err error) {
-	arg0, err := types.ParseDuration(d, getFsp(d))
+// convertArgToAddSubDuration converts the second argument of ADDTIME and SUBTIME to a duration,
+// it returns isNull if the argument is a date or datetime, or it isn't a valid time.
+func convertArgToAddSubDuration(arg types.Datum) (dur types.Duration, isNull bool, err error) {
+	switch arg.Kind() {
+	case types.KindMysqlDuration:
+		return arg.GetMysqlDuration(), false, nil
+	case types.KindMysqlTime:
+		return dur, true, nil
+	}
+	s, err := arg.ToString()
 	if err != nil {
-		return result, errors.Trace(err)
+		return dur, true, errors.Trace(err)
+	}
+	if isDatetimeStr(s) {
+		return dur, true, nil
+	}
+	fsp := types.MinFsp
+	if getFsp(s) != 0 {
+		fsp = types.MaxFsp
 	}
-	tmpDuration, err := arg0.Add(arg1)
+	dur, err = types.ParseDuration(s, fsp)
 	if err != nil {
-		return result, errors.Trace(err)
+		return dur, true, errors.Trace(types.ErrInvalidTimeFormat)
 	}
-	if getFsp(d) != 0 {
-		tmpDuration.Fsp = types.MaxFsp
-	} else {
-		tmpDuration.Fsp = types.MinFsp
+	return dur, false, nil
+}
+
+// truncateAddSubDuration truncates the duration result of ADDTIME and SUBTIME to the range of the TIME type.
+func truncateAddSubDuration(sc *variable.StatementContext, dur types.Duration) (types.Duration, error) {
+	ret, truncated := types.TruncateOverflowMySQLTime(dur.Duration)
+	if !truncated {
+		return dur, nil
 	}
-	result.SetString(tmpDuration.String())
-	return
+	err := sc.HandleTruncate(types.ErrTruncatedWrongVal.GenByArgs("time", dur.String()))
+	dur.Duration = ret
+	return dur, errors.Trace(err)
 }
 
-// strDatetimeSubDuration subtracts duration from datetime string, returns a datum value.
-func strDatetimeSubDuration(d string, arg1 types.Duration) (result types.Datum, err error) {
-	arg0, err := types.ParseTime(d, mysql.TypeDatetime, getFsp(d))
-	if err != nil {
-		return result, errors.Trace(err)
+// addSubTime evaluates ADDTIME(expr1, expr2), or SUBTIME(expr1, expr2) if sub is true.
+// The result is a datetime if expr1 is a date or datetime, a time if expr1 is a time, otherwise it's the string of
+// the datetime or the time that expr1 is parsed to. The time results are truncated to the range of the TIME type.
+// It returns NULL if expr2 is a date or datetime, or any argument is invalid.
+func addSubTime(ctx context.Context, args []types.Datum, sub bool) (d types.Datum, err error) {
+	if args[0].IsNull() || args[1].IsNull() {
+		return
 	}
-	arg1time, err := arg1.ConvertToTime(uint8(getFsp(arg1.String())))
-	if err != nil {
-		return result, errors.Trace(err)
+	sc := ctx.GetSessionVars().StmtCtx
+	arg1, isNull, err := convertArgToAddSubDuration(args[1])
+	if isNull || err != nil {
+		return d, errors.Trace(handleInvalidTimeError(ctx, err))
 	}
-	tmpDuration := arg0.Sub(&arg1time)
-	resultDuration, err := tmpDuration.ConvertToTime(mysql.TypeDatetime)
-	if err != nil {
-		return result, errors.Trace(err)
+	if sub {
+		arg1.Duration = -arg1.Duration
 	}
-	if getFsp(d) != 0 {
-		tmpDuration.Fsp = types.MaxFsp
-	} else {
-		tmpDuration.Fsp = types.MinFsp
+	switch args[0].Kind() {
+	case types.KindMysqlTime:
+		arg0 := args[0].GetMysqlTime()
+		if arg0.Type == mysql.TypeDate {
+			arg0.Type = mysql.TypeDatetime
+		}
+		result, err := arg0.Add(arg1)
+		if err != nil {
+			return d, errors.Trace(handleInvalidTimeError(ctx, err))
+		}
+		d.SetMysqlTime(result)
+		return d, nil
+	case types.KindMysqlDuration:
+		result, err := args[0].GetMysqlDuration().Add(arg1)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		result, err = truncateAddSubDuration(sc, result)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetMysqlDuration(result)
+		return d, nil
 	}
-	result.SetString(resultDuration.String())
-	return
-}
-
-// strDurationSubDuration subtracts duration from duration string, returns a datum value.
-func strDurationSubDuration(d string, arg1 types.Duration) (result types.Datum, err error) {
-	arg0, err := types.ParseDuration(d, getFsp(d))
+	s, err := args[0].ToString()
 	if err != nil {
-		return result, errors.Trace(err)
+		return d, errors.Trace(err)
 	}
-	tmpDuration, err := arg0.Sub(arg1)
+	fsp := types.MinFsp
+	if getFsp(s) != 0 || arg1.Fsp != 0 {
+		fsp = types.MaxFsp
+	}
+	if isDuration(s) {
+		arg0, err := types.ParseDuration(s, getFsp(s))
+		if err != nil {
+			return d, errors.Trace(handleInvalidTimeError(ctx, types.ErrInvalidTimeFormat))
+		}
+		result, err := arg0.Add(arg1)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		result, err = truncateAddSubDuration(sc, result)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		result.Fsp = fsp
+		d.SetString(result.String())
+		return d, nil
+	}
+	arg0, err := types.ParseTime(s, mysql.TypeDatetime, getFsp(s))
 	if err != nil {
-		return result, errors.Trace(err)
+		return d, errors.Trace(handleInvalidTimeError(ctx, types.ErrInvalidTimeFormat))
 	}
-	if getFsp(d) != 0 {
-		tmpDuration.Fsp = types.MaxFsp
-	} else {
-		tmpDuration.Fsp = types.MinFsp
+	result, err := arg0.Add(arg1)
+	if err != nil {
+		return d, errors.Trace(handleInvalidTimeError(ctx, err))
 	}
-	result.SetString(tmpDuration.String())
-	return
+	result.Fsp = fsp
+	d.SetString(result.String())
+	return d, nil
 }
 
 type addTimeFunctionClass struct {
@@ -2449,54 +2548,7 @@ func (b *builtinAddTimeSig) eval(row []types.Datum) (d types.Datum, err error) {
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	if args[0].IsNull() || args[1].IsNull() {
-		return
-	}
-	var arg1 types.Duration
-	switch tp := args[1].Kind(); tp {
-	case types.KindMysqlDuration:
-		arg1 = args[1].GetMysqlDuration()
-	default:
-		s, err := args[1].ToString()
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		if getFsp(s) == 0 {
-			arg1, err = types.ParseDuration(s, 0)
-		} else {
-			arg1, err = types.ParseDuration(s, types.MaxFsp)
-		}
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-
-	}
-	switch tp := args[0].Kind(); tp {
-	case types.KindMysqlTime:
-		arg0 := args[0].GetMysqlTime()
-		result, err := arg0.Add(arg1)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		d.SetMysqlTime(result)
-	case types.KindMysqlDuration:
-		arg0 := args[0].GetMysqlDuration()
-		result, err := arg0.Add(arg1)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		d.SetMysqlDuration(result)
-	default:
-		ss, err := args[0].ToString()
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		if isDuration(ss) {
-			return strDurationAddDuration(ss, arg1)
-		}
-		return strDatetimeAddDuration(ss, arg1)
-	}
-	return
+	return addSubTime(b.ctx, args, false)
 }
 
 type convertTzFunctionClass struct {
@@ -2943,9 +2995,14 @@ func (b *builtinSecToTimeSig) evalDuration(row []types.Datum) (types.Duration, b
 
 	hour = seconds / 3600
 	if hour > 838 {
+		err = ctx.HandleTruncate(types.ErrTruncatedWrongVal.GenByArgs("time", negative+strconv.FormatFloat(secondsFloat, 'f', -1, 64)))
+		if err != nil {
+			return types.Duration{}, true, errors.Trace(err)
+		}
 		hour = 838
 		minute = 59
 		second = 59
+		demical = 0
 	} else {
 		minute = seconds % 3600 / 60
 		second = seconds % 60
@@ -2983,59 +3040,7 @@ func (b *builtinSubTimeSig) eval(row []types.Datum) (d types.Datum, err error) {
 	if err != nil {
 		return d, errors.Trace(err)
 	}
-	if args[0].IsNull() || args[1].IsNull() {
-		return
-	}
-	var arg1 types.Duration
-	switch args[1].Kind() {
-	case types.KindMysqlDuration:
-		arg1 = args[1].GetMysqlDuration()
-	default:
-		s, err := args[1].ToString()
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		if getFsp(s) == 0 {
-			arg1, err = types.ParseDuration(s, 0)
-		} else {
-			arg1, err = types.ParseDuration(s, types.MaxFsp)
-		}
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-
-	}
-	switch args[0].Kind() {
-	case types.KindMysqlTime:
-		arg0 := args[0].GetMysqlTime()
-		arg1time, err := arg1.ConvertToTime(uint8(getFsp(arg1.String())))
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		tmpDuration := arg0.Sub(&arg1time)
-		result, err := tmpDuration.ConvertToTime(arg0.Type)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		d.SetMysqlTime(result)
-	case types.KindMysqlDuration:
-		arg0 := args[0].GetMysqlDuration()
-		result, err := arg0.Sub(arg1)
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		d.SetMysqlDuration(result)
-	default:
-		ss, err := args[0].ToString()
-		if err != nil {
-			return d, errors.Trace(err)
-		}
-		if isDuration(ss) {
-			return strDurationSubDuration(ss, arg1)
-		}
-		return strDatetimeSubDuration(ss, arg1)
-	}
-	return
+	return addSubTime(b.ctx, args, true)
 }
 
 type timeFormatFunctionClass struct {
@@ -3129,7 +3134,13 @@ func (c *timestampAddFunctionClass) getFunction(ctx context.Context, args []Expr
 	if err := c.verifyArgs(args); err != nil {
 		return nil, errors.Trace(err)
 	}
-	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString, tpInt, tpDatetime)
+	// The date argument that isn't a temporal value is evaluated as a string, so a date without the time part is
+	// known, the result of adding a date unit to it is a date, as MySQL does.
+	dateTp := tpString
+	if types.IsTypeTemporal(args[2].GetType().Tp) {
+		dateTp = tpDatetime
+	}
+	bf := newBaseBuiltinFuncWithTp(args, ctx, tpString, tpString, tpInt, dateTp)
 	bf.tp = &types.FieldType{Tp: mysql.TypeString, Flen: mysql.MaxDatetimeWidthNoFsp, Decimal: types.UnspecifiedLength}
 	sig := &builtinTimestampAddSig{baseStringBuiltinFunc{bf}}
 	return sig.setSelf(sig), nil
@@ -3140,6 +3151,28 @@ type builtinTimestampAddSig struct {
 	baseStringBuiltinFunc
 }
 
+// evalDate evaluates the date argument, isDate is true if it has no time part.
+func (b *builtinTimestampAddSig) evalDate(row []types.Datum) (date types.Time, isDate bool, isNull bool, err error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	if types.IsTypeTemporal(b.args[2].GetType().Tp) {
+		date, isNull, err = b.args[2].EvalTime(row, sc)
+		return date, date.Type == mysql.TypeDate, isNull, errors.Trace(err)
+	}
+	s, isNull, err := b.args[2].EvalString(row, sc)
+	if isNull || err != nil {
+		return date, false, isNull, errors.Trace(err)
+	}
+	fsp := types.MinFsp
+	if getFsp(s) != 0 {
+		fsp = types.MaxFsp
+	}
+	date, err = types.ParseTime(s, mysql.TypeDatetime, fsp)
+	if err != nil {
+		return date, false, true, errors.Trace(types.ErrInvalidTimeFormat)
+	}
+	return date, types.IsDateFormat(s), false, nil
+}
+
 // evalString evals a builtinTimestampAddSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_timestampadd
 func (b *builtinTimestampAddSig) evalString(row []types.Datum) (string, bool, error) {
@@ -3152,40 +3185,67 @@ func (b *builtinTimestampAddSig) evalString(row []types.Datum) (string, bool, er
 	if isNull || err != nil {
 		return "", isNull, errors.Trace(err)
 	}
-	arg, isNull, err := b.args[2].EvalTime(row, ctx)
+	arg, isDate, isNull, err := b.evalDate(row)
 	if isNull || err != nil {
-		return "", isNull, errors.Trace(err)
+		return "", true, errors.Trace(handleInvalidTimeError(b.ctx, err))
+	}
+	if arg.InvalidZero() {
+		return "", true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
-	tm1, err := arg.Time.GoTime(time.Local)
+	// The time is calculated in UTC, which has no daylight saving time.
+	tm1, err := arg.Time.GoTime(time.UTC)
 	if err != nil {
-		return "", isNull, errors.Trace(err)
+		return "", true, errors.Trace(handleInvalidTimeError(b.ctx, err))
+	}
+	// The date intervals out of the range of int32 always overflow, they are checked before they are multiplied.
+	if !types.IsClockUnit(unit) && (v > math.MaxInt32 || v < math.MinInt32) {
+		return "", true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
 	var tb time.Time
-	fsp := types.DefaultFsp
+	fsp := arg.Fsp
+	if isDate {
+		// A DATE has no fractional seconds, whatever the fsp of its type is, e.g. DATE '2020-01-01'.
+		fsp = types.MinFsp
+	}
 	switch unit {
-	case "MICROSECOND":
-		tb = tm1.Add(time.Duration(v) * time.Microsecond)
-		fsp = types.MaxFsp
-	case "SECOND":
-		tb = tm1.Add(time.Duration(v) * time.Second)
-	case "MINUTE":
-		tb = tm1.Add(time.Duration(v) * time.Minute)
-	case "HOUR":
-		tb = tm1.Add(time.Duration(v) * time.Hour)
+	case "MICROSECOND", "SECOND", "MINUTE", "HOUR":
+		var d time.Duration
+		switch unit {
+		case "MICROSECOND":
+			d = time.Microsecond
+			fsp = types.MaxFsp
+		case "SECOND":
+			d = time.Second
+		case "MINUTE":
+			d = time.Minute
+		case "HOUR":
+			d = time.Hour
+		}
+		if v > math.MaxInt64/int64(d) || v < math.MinInt64/int64(d) {
+			return "", true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
+		}
+		tb = tm1.Add(time.Duration(v) * d)
+		isDate = false
 	case "DAY":
-		tb = tm1.AddDate(0, 0, int(v))
+		tb, err = addDate(tm1, 0, 0, v)
 	case "WEEK":
-		tb = tm1.AddDate(0, 0, 7*int(v))
+		tb, err = addDate(tm1, 0, 0, 7*v)
 	case "MONTH":
-		tb = tm1.AddDate(0, int(v), 0)
+		tb, err = addDate(tm1, 0, v, 0)
 	case "QUARTER":
-		tb = tm1.AddDate(0, 3*int(v), 0)
+		tb, err = addDate(tm1, 0, 3*v, 0)
 	case "YEAR":
-		tb = tm1.AddDate(int(v), 0, 0)
+		tb, err = addDate(tm1, v, 0, 0)
 	default:
 		return "", true, errors.Trace(types.ErrInvalidTimeFormat)
 	}
+	if err != nil {
+		return "", true, errors.Trace(handleInvalidTimeError(b.ctx, err))
+	}
 	r := types.Time{Time: types.FromGoTime(tb), Type: mysql.TypeDatetime, Fsp: fsp}
+	if isDate {
+		r.Type = mysql.TypeDate
+	}
 	if err = r.Check(); err != nil {
 		return "", true, errors.Trace(handleInvalidTimeError(b.ctx, err))
 	}
@@ -3365,7 +3425,7 @@ func (b *builtinLastDaySig) evalTime(row []types.Datum) (types.Time, bool, error
 	}
 	tm := arg.Time
 	year, month, day := tm.Year(), tm.Month(), 30
-	if year == 0 && month == 0 && tm.Day() == 0 {
+	if month == 0 {
 		return types.Time{}, true, errors.Trace(handleInvalidTimeError(b.ctx, types.ErrInvalidTimeFormat))
 	}
 	if month == 1 || month == 3 || month == 5 ||
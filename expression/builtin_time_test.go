@@ -23,6 +23,7 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
@@ -299,6 +300,14 @@ func (s *testEvaluatorSuite) TestMonthName(c *C) {
 	f, err := funcs[ast.MonthName].getFunction(s.ctx, []Expression{Zero})
 	c.Assert(err, IsNil)
 	c.Assert(f.canBeFolded(), IsTrue)
+
+	s.ctx.GetSessionVars().Systems[variable.LcTimeNames] = "fr_FR"
+	defer delete(s.ctx.GetSessionVars().Systems, variable.LcTimeNames)
+	f, err = funcs[ast.MonthName].getFunction(s.ctx, datumsToConstants(types.MakeDatums("2017-08-01")))
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "août")
 }
 
 func (s *testEvaluatorSuite) TestDayName(c *C) {
@@ -336,6 +345,14 @@ func (s *testEvaluatorSuite) TestDayName(c *C) {
 	f, err := funcs[ast.DayName].getFunction(s.ctx, []Expression{Zero})
 	c.Assert(err, IsNil)
 	c.Assert(f.canBeFolded(), IsTrue)
+
+	s.ctx.GetSessionVars().Systems[variable.LcTimeNames] = "ja_JP"
+	defer delete(s.ctx.GetSessionVars().Systems, variable.LcTimeNames)
+	f, err = funcs[ast.DayName].getFunction(s.ctx, datumsToConstants(types.MakeDatums("2017-12-01")))
+	c.Assert(err, IsNil)
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetString(), Equals, "金曜日")
 }
 
 func (s *testEvaluatorSuite) TestDayOfWeek(c *C) {
@@ -488,6 +505,27 @@ func (s *testEvaluatorSuite) TestDateFormat(c *C) {
 		c.Assert(v, testutil.DatumEquals, t["Expect"][0], Commentf("no.%d \nobtain:%v \nexpect:%v\n", i,
 			v.GetValue(), t["Expect"][0].GetValue()))
 	}
+
+	// The names of the months and the days are in the locale of lc_time_names.
+	defer delete(s.ctx.GetSessionVars().Systems, variable.LcTimeNames)
+	tblLocale := []struct {
+		locale string
+		expect string
+	}{
+		{"en_US", "Thu Thursday Jan January 7th PM"},
+		{"de_DE", "Do Donnerstag Jan Januar 7th PM"},
+		{"es_ES", "jue jueves ene enero 7th PM"},
+		{"zh_CN", "四 星期四  1月 一月 7th PM"},
+		{"unknown", "Thu Thursday Jan January 7th PM"},
+	}
+	for _, t := range tblLocale {
+		s.ctx.GetSessionVars().Systems[variable.LcTimeNames] = t.locale
+		f, err := fc.getFunction(s.ctx, datumsToConstants(types.MakeDatums("2010-01-07 23:12:34", "%a %W %b %M %D %p")))
+		c.Assert(err, IsNil)
+		v, err := f.eval(nil)
+		c.Assert(err, IsNil)
+		c.Assert(v.GetString(), Equals, t.expect, Commentf("%s", t.locale))
+	}
 }
 
 func (s *testEvaluatorSuite) TestClock(c *C) {
@@ -832,6 +870,29 @@ func (s *testEvaluatorSuite) TestAddTimeSig(c *C) {
 		c.Assert(result, Equals, t.expect)
 	}
 
+	// The time result is truncated to the range of the TIME type.
+	sc := s.ctx.GetSessionVars().StmtCtx
+	origin := sc.TruncateAsWarning
+	sc.TruncateAsWarning = true
+	defer func() {
+		sc.TruncateAsWarning = origin
+	}()
+	dur, err := types.ParseDuration("838:00:00", 0)
+	c.Assert(err, IsNil)
+	f, err := fc.getFunction(s.ctx, datumsToConstants([]types.Datum{types.NewDurationDatum(dur), types.NewStringDatum("1:00:00")}))
+	c.Assert(err, IsNil)
+	warningCount := len(sc.GetWarnings())
+	d, err := f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.GetMysqlDuration().String(), Equals, "838:59:59")
+	c.Assert(sc.GetWarnings(), HasLen, warningCount+1)
+
+	// The result is NULL if the second argument is a datetime.
+	f, err = fc.getFunction(s.ctx, datumsToConstants([]types.Datum{types.NewStringDatum("2007-12-31 23:59:59"), types.NewStringDatum("2007-12-31 23:59:59")}))
+	c.Assert(err, IsNil)
+	d, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	c.Assert(d.IsNull(), IsTrue)
 }
 
 func (s *testEvaluatorSuite) TestSubTimeSig(c *C) {
@@ -1843,6 +1904,13 @@ func (s *testEvaluatorSuite) TestTimestampAdd(c *C) {
 		{"MINUTE", 1, "2003-01-02", "2003-01-02 00:01:00"},
 		{"WEEK", 1, "2003-01-02 23:59:59", "2003-01-09 23:59:59"},
 		{"MICROSECOND", 1, 950501, "1995-05-01 00:00:00.000001"},
+		{"DAY", 1, "2003-01-02", "2003-01-03"},
+		{"MONTH", 1, "2003-01-31 12:00:00", "2003-02-28 12:00:00"},
+		{"QUARTER", -1, "2004-05-31", "2004-02-29"},
+		{"YEAR", 1, "2004-02-29", "2005-02-28"},
+		{"SECOND", -1, "2003-01-01 00:00:00.5", "2002-12-31 23:59:59.500000"},
+		{"HOUR", 1, types.Time{Time: types.FromDate(2020, 1, 1, 0, 0, 0, 0), Type: mysql.TypeDate, Fsp: types.MaxFsp}, "2020-01-01 01:00:00"},
+		{"MICROSECOND", 1, types.Time{Time: types.FromDate(2020, 1, 1, 0, 0, 0, 0), Type: mysql.TypeDate, Fsp: types.MaxFsp}, "2020-01-01 00:00:00.000001"},
 	}
 
 	fc := funcs[ast.TimestampAdd]
@@ -2137,6 +2205,7 @@ func (s *testEvaluatorSuite) TestLastDay(c *C) {
 		{"2004-02-05", "2004-02-29"},
 		{"2004-01-01 01:01:01", "2004-01-31"},
 		{950501, "1995-05-31"},
+		{"2003-02-00", "2003-02-28"},
 	}
 
 	fc := funcs[ast.LastDay]
@@ -2153,6 +2222,7 @@ func (s *testEvaluatorSuite) TestLastDay(c *C) {
 
 	testsNull := []interface{}{
 		"0000-00-00",
+		"2003-00-05",
 		"1992-13-00",
 		"2007-10-07 23:59:61",
 		123456789}
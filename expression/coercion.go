@@ -0,0 +1,136 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// getCmpType gets the ClassType that the two args will be treated as when comparing.
+func getCmpType(a types.TypeClass, b types.TypeClass) types.TypeClass {
+	if a == types.ClassString && b == types.ClassString {
+		return types.ClassString
+	} else if a == types.ClassInt && b == types.ClassInt {
+		return types.ClassInt
+	} else if (a == types.ClassInt || a == types.ClassDecimal) &&
+		(b == types.ClassInt || b == types.ClassDecimal) {
+		return types.ClassDecimal
+	}
+	return types.ClassReal
+}
+
+// isTemporalColumn checks if a expression is a temporal column,
+// temporal column indicates time column or duration column.
+func isTemporalColumn(expr Expression) bool {
+	ft := expr.GetType()
+	if _, isCol := expr.(*Column); !isCol {
+		return false
+	}
+	if !types.IsTypeTime(ft.Tp) && ft.Tp != mysql.TypeDuration {
+		return false
+	}
+	return true
+}
+
+func isConst(expr Expression) bool {
+	_, ok := expr.(*Constant)
+	return ok
+}
+
+// getCmpTp gets the type that the two args are compared as, it implements the implicit conversion rules of MySQL.
+// All the comparisons, including the ones rewritten from IN, BETWEEN, CASE and the join conditions by the planner,
+// are built by it, so a pair of values is always compared in the same way.
+// See https://dev.mysql.com/doc/refman/5.7/en/type-conversion.html
+//
+//	lhs \ rhs  | int      decimal  real  string    datetime  time
+//	-----------+-------------------------------------------------------
+//	int        | int      decimal  real  real      real*     real*
+//	decimal    | decimal  decimal  real  real**    real*     real*
+//	real       | real     real     real  real      real*     real*
+//	string     | real     real**   real  string    datetime  string*
+//	datetime   | real*    real*    real* datetime  datetime  datetime
+//	time       | real*    real*    real* string*   datetime  time
+//
+// *  A temporal column is compared with a constant as the type of the column.
+// ** A decimal expression is compared with a string constant as decimal in order not to lose precision.
+//
+// A string compared as a temporal value which isn't valid is compared as the zero value with a warning, see
+// handleCmpTemporalError. JSON compared with a string is compared as JSON.
+func getCmpTp(lhs, rhs Expression) evalTp {
+	lft, rft := lhs.GetType(), rhs.GetType()
+	ltc, rtc := lft.ToClass(), rft.ToClass()
+	if (ltc == types.ClassString && rft.Tp == mysql.TypeJSON) || (lft.Tp == mysql.TypeJSON && rtc == types.ClassString) {
+		return tpJSON
+	}
+	cmpType := getCmpType(ltc, rtc)
+	if cmpType == types.ClassString {
+		// The temporal types and the strings are all in ClassString.
+		switch {
+		case lft.Tp == rft.Tp && (types.IsTypeTime(lft.Tp) || lft.Tp == mysql.TypeDuration):
+			return fieldTp2EvalTp(lft)
+		case types.IsTypeTime(lft.Tp) || types.IsTypeTime(rft.Tp):
+			// date[time] <cmp> date[time], time or string
+			return tpDatetime
+		case isTemporalColumn(lhs) && isConst(rhs) || isTemporalColumn(rhs) && isConst(lhs):
+			// <time column> <cmp> <string constant>
+			return tpDuration
+		}
+		return tpString
+	}
+	isConst0, isConst1 := isConst(lhs), isConst(rhs)
+	if cmpType == types.ClassReal {
+		if (ltc == types.ClassDecimal && !isConst0 && rtc == types.ClassString && isConst1) ||
+			(rtc == types.ClassDecimal && !isConst1 && ltc == types.ClassString && isConst0) {
+			return tpDecimal
+		}
+		if isTemporalColumn(lhs) && isConst1 || isTemporalColumn(rhs) && isConst0 {
+			// <temporal column> <cmp> <non-temporal constant>
+			// The constant is converted to the type of the column.
+			col, ok := lhs.(*Column)
+			if !ok {
+				col = rhs.(*Column)
+			}
+			if col.GetType().Tp == mysql.TypeDuration {
+				return tpDuration
+			}
+			return tpDatetime
+		}
+		return tpReal
+	}
+	if cmpType == types.ClassInt {
+		return tpInt
+	}
+	return tpDecimal
+}
+
+// handleCmpTemporalError handles the error of converting the argument of a comparison into a temporal value.
+// The value which isn't a valid temporal value is compared as the zero value with a warning like MySQL, it's an
+// error only when changing data in the strict sql mode.
+func handleCmpTemporalError(ctx context.Context, arg Expression, row []types.Datum, tpName string, err error) error {
+	// The argument which isn't temporal is wrapped in a cast, the other errors are returned as they are.
+	f, ok := arg.(*ScalarFunction)
+	if !ok || f.FuncName.L != ast.Cast {
+		return errors.Trace(err)
+	}
+	sc := ctx.GetSessionVars().StmtCtx
+	str, isNull, err1 := f.GetArgs()[0].EvalString(row, sc)
+	if isNull || err1 != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(sc.HandleTruncate(types.ErrTruncatedWrongVal.GenByArgs(tpName, str)))
+}
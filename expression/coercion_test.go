@@ -0,0 +1,117 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expression
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/testutil"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testEvaluatorSuite) TestGetCmpTp(c *C) {
+	defer testleak.AfterTest(c)()
+	col := func(tp byte) Expression {
+		return &Column{ColName: model.NewCIStr("c"), RetType: types.NewFieldType(tp)}
+	}
+	con := func(tp byte) Expression {
+		return &Constant{Value: types.NewStringDatum("1"), RetType: types.NewFieldType(tp)}
+	}
+	tests := []struct {
+		lhs    Expression
+		rhs    Expression
+		expect evalTp
+	}{
+		{col(mysql.TypeLonglong), col(mysql.TypeLong), tpInt},
+		{col(mysql.TypeLonglong), col(mysql.TypeNewDecimal), tpDecimal},
+		{col(mysql.TypeNewDecimal), col(mysql.TypeDouble), tpReal},
+		{col(mysql.TypeLonglong), col(mysql.TypeVarchar), tpReal},
+		{col(mysql.TypeVarchar), col(mysql.TypeBlob), tpString},
+		{col(mysql.TypeNewDecimal), con(mysql.TypeVarchar), tpDecimal},
+		{con(mysql.TypeVarchar), col(mysql.TypeNewDecimal), tpDecimal},
+		{col(mysql.TypeNewDecimal), col(mysql.TypeVarchar), tpReal},
+		{col(mysql.TypeDate), col(mysql.TypeDate), tpDatetime},
+		{col(mysql.TypeTimestamp), col(mysql.TypeTimestamp), tpTimestamp},
+		{col(mysql.TypeDate), col(mysql.TypeDatetime), tpDatetime},
+		{col(mysql.TypeDate), col(mysql.TypeDuration), tpDatetime},
+		{col(mysql.TypeDate), con(mysql.TypeVarchar), tpDatetime},
+		{con(mysql.TypeVarchar), col(mysql.TypeDatetime), tpDatetime},
+		{col(mysql.TypeDuration), col(mysql.TypeDuration), tpDuration},
+		{col(mysql.TypeDuration), con(mysql.TypeVarchar), tpDuration},
+		{con(mysql.TypeVarchar), col(mysql.TypeDuration), tpDuration},
+		{col(mysql.TypeDuration), col(mysql.TypeVarchar), tpString},
+		{con(mysql.TypeDuration), con(mysql.TypeVarchar), tpString},
+		{col(mysql.TypeDate), con(mysql.TypeLonglong), tpDatetime},
+		{con(mysql.TypeNewDecimal), col(mysql.TypeDuration), tpDuration},
+		{col(mysql.TypeDate), col(mysql.TypeLonglong), tpReal},
+		{con(mysql.TypeDate), con(mysql.TypeLonglong), tpReal},
+		{col(mysql.TypeJSON), con(mysql.TypeVarchar), tpJSON},
+		{col(mysql.TypeJSON), col(mysql.TypeLonglong), tpReal},
+	}
+	for _, t := range tests {
+		c.Assert(getCmpTp(t.lhs, t.rhs), Equals, t.expect, Commentf("%s, %s", t.lhs.GetType(), t.rhs.GetType()))
+	}
+}
+
+func (s *testEvaluatorSuite) TestCmpInvalidTemporal(c *C) {
+	defer testleak.AfterTest(c)()
+	sc := s.ctx.GetSessionVars().StmtCtx
+	origin := sc.TruncateAsWarning
+	defer func() {
+		sc.TruncateAsWarning = origin
+	}()
+	sc.TruncateAsWarning = true
+
+	date := types.Time{Time: types.FromDate(2007, 1, 1, 0, 0, 0, 0), Type: mysql.TypeDate}
+	tests := []struct {
+		fn     string
+		args   []interface{}
+		expect int64
+	}{
+		{ast.EQ, []interface{}{date, "abc"}, 0},
+		{ast.NE, []interface{}{date, "abc"}, 1},
+		{ast.GT, []interface{}{date, "abc"}, 1},
+		{ast.LT, []interface{}{"2007-13-45", date}, 1},
+		{ast.EQ, []interface{}{date, "2007-01-01 00:00:00"}, 1},
+		{ast.GE, []interface{}{"2007-01-01 10:00:00", date}, 1},
+	}
+	for _, t := range tests {
+		comment := Commentf("%s%v", t.fn, t.args)
+		f, err := funcs[t.fn].getFunction(s.ctx, datumsToConstants(types.MakeDatums(t.args...)))
+		c.Assert(err, IsNil, comment)
+		d, err := f.eval(nil)
+		c.Assert(err, IsNil, comment)
+		c.Assert(d, testutil.DatumEquals, types.NewDatum(t.expect), comment)
+	}
+
+	// The invalid string is compared as the zero value with a warning.
+	sc.SetWarnings(nil)
+	f, err := funcs[ast.EQ].getFunction(s.ctx, datumsToConstants(types.MakeDatums(date, "abc")))
+	c.Assert(err, IsNil)
+	_, err = f.eval(nil)
+	c.Assert(err, IsNil)
+	warnings := sc.GetWarnings()
+	c.Assert(warnings, HasLen, 1)
+	c.Assert(terror.ErrorEqual(warnings[0], types.ErrTruncatedWrongVal), IsTrue)
+	c.Assert(warnings[0].Error(), Equals, "[types:1292]Truncated incorrect datetime value: 'abc'")
+
+	// It's an error if the truncation isn't a warning, like changing data in the strict sql mode.
+	sc.TruncateAsWarning = false
+	_, err = f.eval(nil)
+	c.Assert(terror.ErrorEqual(err, types.ErrTruncatedWrongVal), IsTrue)
+}
@@ -84,6 +84,24 @@ var distFuncs = map[tipb.ExprType]string{
 	tipb.ExprType_JsonRemove:  ast.JSONRemove,
 	tipb.ExprType_JsonArray:   ast.JSONArray,
 	tipb.ExprType_JsonObject:  ast.JSONObject,
+
+	// for time functions.
+	tipb.ExprType_Date:        ast.Date,
+	tipb.ExprType_Year:        ast.Year,
+	tipb.ExprType_YearWeek:    ast.YearWeek,
+	tipb.ExprType_Month:       ast.Month,
+	tipb.ExprType_Week:        ast.Week,
+	tipb.ExprType_Weekday:     ast.Weekday,
+	tipb.ExprType_WeekOfYear:  ast.WeekOfYear,
+	tipb.ExprType_Day:         ast.Day,
+	tipb.ExprType_DayName:     ast.DayName,
+	tipb.ExprType_DayOfYear:   ast.DayOfYear,
+	tipb.ExprType_DayOfMonth:  ast.DayOfMonth,
+	tipb.ExprType_DayOfWeek:   ast.DayOfWeek,
+	tipb.ExprType_Hour:        ast.Hour,
+	tipb.ExprType_Minute:      ast.Minute,
+	tipb.ExprType_Second:      ast.Second,
+	tipb.ExprType_Microsecond: ast.MicroSecond,
 }
 
 func pbTypeToFieldType(tp *tipb.FieldType) *types.FieldType {
@@ -283,19 +301,19 @@ func getSignatureByPB(ctx context.Context, sigCode tipb.ScalarFuncSig, tp *tipb.
 		f = &builtinNullEQDurationSig{baseIntBuiltinFunc{base}}
 
 	case tipb.ScalarFuncSig_GTString:
-		f = &builtinGTStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinGTStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 	case tipb.ScalarFuncSig_GEString:
-		f = &builtinGEStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinGEStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 	case tipb.ScalarFuncSig_LTString:
-		f = &builtinLTStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinLTStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 	case tipb.ScalarFuncSig_LEString:
-		f = &builtinLEStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinLEStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 	case tipb.ScalarFuncSig_EQString:
-		f = &builtinEQStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinEQStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 	case tipb.ScalarFuncSig_NEString:
-		f = &builtinNEStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinNEStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 	case tipb.ScalarFuncSig_NullEQString:
-		f = &builtinNullEQStringSig{baseIntBuiltinFunc{base}}
+		f = &builtinNullEQStringSig{baseIntBuiltinFunc{base}, collatorOfArgs(args)}
 
 	case tipb.ScalarFuncSig_GTJson:
 		f = &builtinGTJSONSig{baseIntBuiltinFunc{base}}
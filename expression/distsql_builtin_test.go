@@ -272,6 +272,19 @@ func (s *testEvalSuite) TestEval(c *C) {
 			buildExpr(tipb.ExprType_Mod, types.NewFloat64Datum(3.0), types.NewFloat64Datum(1.9)),
 			types.NewFloat64Datum(1.1),
 		},
+		// Time functions.
+		{
+			buildExpr(tipb.ExprType_YearWeek, types.NewStringDatum("1987-01-01")),
+			types.NewIntDatum(198652),
+		},
+		{
+			buildExpr(tipb.ExprType_WeekOfYear, types.NewStringDatum("2008-02-20")),
+			types.NewIntDatum(8),
+		},
+		{
+			buildExpr(tipb.ExprType_DayOfMonth, types.NewStringDatum("2008-02-20")),
+			types.NewIntDatum(20),
+		},
 	}
 	sc := new(variable.StatementContext)
 	for _, tt := range tests {
@@ -197,6 +197,10 @@ func (pc pbConverter) scalarFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
 		ast.JSONObject, ast.JSONArray, ast.JSONMerge, ast.JSONSet,
 		ast.JSONInsert, ast.JSONReplace, ast.JSONRemove, ast.JSONContains:
 		return pc.jsonFuncToPBExpr(expr)
+	case ast.Date, ast.Year, ast.YearWeek, ast.Month, ast.Week, ast.Weekday, ast.WeekOfYear,
+		ast.Day, ast.DayName, ast.DayOfYear, ast.DayOfMonth, ast.DayOfWeek,
+		ast.Hour, ast.Minute, ast.Second, ast.MicroSecond:
+		return pc.timeFuncToPBExpr(expr)
 	default:
 		return nil
 	}
@@ -321,6 +325,11 @@ func (pc pbConverter) jsonFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
 	return pc.convertToPBExpr(expr, tp)
 }
 
+func (pc pbConverter) timeFuncToPBExpr(expr *ScalarFunction) *tipb.Expr {
+	var tp = timeFunctionNameToPB[expr.FuncName.L]
+	return pc.convertToPBExpr(expr, tp)
+}
+
 func (pc pbConverter) inToPBExpr(expr *ScalarFunction) *tipb.Expr {
 	if !pc.client.IsRequestTypeSupported(kv.ReqTypeSelect, int64(tipb.ExprType_In)) {
 		return nil
@@ -72,6 +72,8 @@ func (c *mockKvClient) supportExpr(exprType tipb.ExprType) bool {
 		tipb.ExprType_JsonObject, tipb.ExprType_JsonArray, tipb.ExprType_JsonMerge, tipb.ExprType_JsonSet,
 		tipb.ExprType_JsonInsert, tipb.ExprType_JsonReplace, tipb.ExprType_JsonRemove, tipb.ExprType_JsonContains:
 		return false
+	case tipb.ExprType_YearWeek, tipb.ExprType_WeekOfYear, tipb.ExprType_DayOfMonth:
+		return true
 	case kv.ReqSubTypeDesc:
 		return true
 	default:
@@ -518,6 +520,38 @@ func (s *testEvaluatorSuite) TestOtherFunc2Pb(c *C) {
 	}
 }
 
+func (s *testEvaluatorSuite) TestTimeFunc2Pb(c *C) {
+	sc := new(variable.StatementContext)
+	client := new(mockKvClient)
+	dg := new(dataGen4Expr2PbTest)
+
+	funcNames := []string{ast.YearWeek, ast.WeekOfYear, ast.DayOfMonth, ast.Hour}
+	var timeFuncs []Expression
+	for _, funcName := range funcNames {
+		fc, err := NewFunction(
+			mock.NewContext(),
+			funcName,
+			types.NewFieldType(mysql.TypeUnspecified),
+			dg.genColumn(mysql.TypeDatetime, 1),
+		)
+		c.Assert(err, IsNil)
+		timeFuncs = append(timeFuncs, fc)
+	}
+
+	pbExprs := ExpressionsToPBList(sc, timeFuncs, client)
+	for i, pbExpr := range pbExprs {
+		// HOUR isn't supported by the mock client.
+		if funcNames[i] == ast.Hour {
+			c.Assert(pbExpr, IsNil)
+			continue
+		}
+		c.Assert(pbExpr, NotNil)
+		c.Assert(pbExpr.Tp, Equals, timeFunctionNameToPB[funcNames[i]])
+		c.Assert(pbExpr.Children, HasLen, 1)
+		c.Assert(pbExpr.Children[0].Tp, Equals, tipb.ExprType_ColumnRef)
+	}
+}
+
 func (s *testEvaluatorSuite) TestGroupByItem2Pb(c *C) {
 	sc := new(variable.StatementContext)
 	client := new(mockKvClient)
@@ -38,7 +38,13 @@ var (
 	errFunctionNotExists   = terror.ClassExpression.New(codeFunctionNotExists, "FUNCTION %s does not exist")
 	errZlibZData           = terror.ClassTypes.New(codeZlibZData, "ZLIB: Input data corrupted")
 	errIncorrectArgs       = terror.ClassExpression.New(codeIncorrectArgs, mysql.MySQLErrName[mysql.ErrWrongArguments])
+	errRegexp              = terror.ClassExpression.New(codeRegexp, mysql.MySQLErrName[mysql.ErrRegexp])
 	errUnknownCharacterSet = terror.ClassExpression.New(mysql.ErrUnknownCharacterSet, mysql.MySQLErrName[mysql.ErrUnknownCharacterSet])
+	errUnknownLocale       = terror.ClassExpression.New(mysql.ErrUnknownLocale, mysql.MySQLErrName[mysql.ErrUnknownLocale])
+	errWarnOptionIgnored   = terror.ClassExpression.New(mysql.WarnOptionIgnored, mysql.MySQLErrName[mysql.WarnOptionIgnored])
+	errWrongValueForType   = terror.ClassExpression.New(mysql.ErrWrongValueForType, mysql.MySQLErrName[mysql.ErrWrongValueForType])
+	errUserLockWrongName   = terror.ClassExpression.New(mysql.ErrUserLockWrongName, mysql.MySQLErrName[mysql.ErrUserLockWrongName])
+	errQueryInterrupted    = terror.ClassExpression.New(mysql.ErrQueryInterrupted, mysql.MySQLErrName[mysql.ErrQueryInterrupted])
 )
 
 // Error codes.
@@ -48,6 +54,7 @@ const (
 	codeFunctionNotExists                      = 1305
 	codeZlibZData                              = mysql.ErrZlibZData
 	codeIncorrectArgs                          = mysql.ErrWrongArguments
+	codeRegexp                                 = mysql.ErrRegexp
 )
 
 func init() {
@@ -56,6 +63,12 @@ func init() {
 		codeFunctionNotExists:       mysql.ErrSpDoesNotExist,
 		codeZlibZData:               mysql.ErrZlibZData,
 		codeIncorrectArgs:           mysql.ErrWrongArguments,
+		codeRegexp:                  mysql.ErrRegexp,
+		mysql.ErrUnknownLocale:      mysql.ErrUnknownLocale,
+		mysql.WarnOptionIgnored:     mysql.WarnOptionIgnored,
+		mysql.ErrWrongValueForType:  mysql.ErrWrongValueForType,
+		mysql.ErrUserLockWrongName:  mysql.ErrUserLockWrongName,
+		mysql.ErrQueryInterrupted:   mysql.ErrQueryInterrupted,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
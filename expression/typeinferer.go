@@ -333,12 +333,13 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		tp = types.NewFieldType(mysql.TypeDouble)
 	case ast.MicroSecond, ast.Second, ast.Minute, ast.Hour, ast.Day, ast.Week, ast.Month, ast.Year,
 		ast.DayOfWeek, ast.DayOfMonth, ast.DayOfYear, ast.Weekday, ast.WeekOfYear, ast.YearWeek, ast.DateDiff,
-		ast.FoundRows, ast.Length, ast.ASCII, ast.Extract, ast.Locate, ast.UnixTimestamp, ast.Quarter, ast.IsIPv4, ast.ToDays,
+		ast.FoundRows, ast.RowCount, ast.Length, ast.ASCII, ast.Extract, ast.Locate, ast.UnixTimestamp, ast.Quarter, ast.IsIPv4, ast.ToDays,
 		ast.ToSeconds, ast.Strcmp, ast.IsNull, ast.BitLength, ast.CharLength, ast.CRC32, ast.TimestampDiff,
 		ast.Sign, ast.IsIPv6, ast.Ord, ast.Instr, ast.BitCount, ast.FindInSet, ast.Field,
-		ast.GetLock, ast.ReleaseLock, ast.Interval, ast.Position, ast.PeriodAdd, ast.PeriodDiff, ast.IsIPv4Mapped, ast.IsIPv4Compat, ast.UncompressedLength:
+		ast.GetLock, ast.ReleaseLock, ast.Interval, ast.Position, ast.PeriodAdd, ast.PeriodDiff, ast.IsIPv4Mapped, ast.IsIPv4Compat, ast.UncompressedLength,
+		ast.RegexpLike, ast.RegexpInstr, ast.IsUUID, ast.IsFreeLock, ast.ReleaseAllLocks:
 		tp = types.NewFieldType(mysql.TypeLonglong)
-	case ast.ConnectionID, ast.InetAton:
+	case ast.ConnectionID, ast.InetAton, ast.IsUsedLock:
 		tp = types.NewFieldType(mysql.TypeLonglong)
 		tp.Flag |= mysql.UnsignedFlag
 	// time related
@@ -387,10 +388,10 @@ func (v *typeInferrer) handleFuncCallExpr(x *ast.FuncCallExpr) {
 		ast.DateFormat, ast.Rpad, ast.Lpad, ast.CharFunc, ast.Conv, ast.MakeSet, ast.Oct, ast.UUID,
 		ast.InsertFunc, ast.Bin, ast.Quote, ast.Format, ast.FromBase64, ast.ToBase64,
 		ast.ExportSet, ast.AesEncrypt, ast.AesDecrypt, ast.SHA2, ast.InetNtoa, ast.Inet6Aton,
-		ast.Inet6Ntoa, ast.PasswordFunc, ast.TiDBVersion:
+		ast.Inet6Ntoa, ast.PasswordFunc, ast.TiDBVersion, ast.RegexpSubstr, ast.RegexpReplace, ast.BinToUUID:
 		tp = types.NewFieldType(mysql.TypeVarString)
 		chs = v.defaultCharset
-	case ast.RandomBytes:
+	case ast.RandomBytes, ast.UUIDToBin:
 		tp = types.NewFieldType(mysql.TypeVarString)
 	case ast.If:
 		// TODO: fix this
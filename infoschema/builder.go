@@ -40,6 +40,9 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	} else if diff.Type == model.ActionDropSchema {
 		tblIDs := b.applyDropSchema(diff.SchemaID)
 		return tblIDs, nil
+	} else if diff.Type == model.ActionRenameTables {
+		tblIDs, err := b.applyRenameTables(m, diff)
+		return tblIDs, errors.Trace(err)
 	}
 
 	roDBInfo, ok := b.is.SchemaByID(diff.SchemaID)
@@ -97,6 +100,52 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 	return tblIDs, nil
 }
 
+// applyRenameTables drops all the renamed tables before creating them,
+// because a table may take the old name of another renamed table.
+func (b *Builder) applyRenameTables(m *meta.Meta, diff *model.SchemaDiff) ([]int64, error) {
+	tblIDs := make([]int64, 0, len(diff.AffectedOpts))
+	allocs := make(map[int64]autoid.Allocator, len(diff.AffectedOpts))
+	for _, opt := range diff.AffectedOpts {
+		oldRoDBInfo, ok := b.is.SchemaByID(opt.OldSchemaID)
+		if !ok {
+			return nil, ErrDatabaseNotExists.GenByArgs(
+				fmt.Sprintf("(Schema ID %d)", opt.OldSchemaID),
+			)
+		}
+		if _, ok = allocs[opt.TableID]; ok {
+			// The table is renamed more than once, it has been dropped.
+			continue
+		}
+		// We try to reuse the old allocator, so the cached auto ID can be reused.
+		allocs[opt.TableID], _ = b.is.AllocByID(opt.TableID)
+		b.copySchemaTables(oldRoDBInfo.Name.L)
+		b.copySortedTables(opt.TableID, opt.TableID)
+		b.applyDropTable(oldRoDBInfo, opt.TableID)
+		tblIDs = append(tblIDs, opt.TableID)
+	}
+	created := make(map[int64]bool, len(diff.AffectedOpts))
+	for i := len(diff.AffectedOpts) - 1; i >= 0; i-- {
+		// The last renaming of a table decides its schema and name.
+		opt := diff.AffectedOpts[i]
+		if created[opt.TableID] {
+			continue
+		}
+		created[opt.TableID] = true
+		roDBInfo, ok := b.is.SchemaByID(opt.SchemaID)
+		if !ok {
+			return nil, ErrDatabaseNotExists.GenByArgs(
+				fmt.Sprintf("(Schema ID %d)", opt.SchemaID),
+			)
+		}
+		b.copySchemaTables(roDBInfo.Name.L)
+		err := b.applyCreateTable(m, roDBInfo, opt.TableID, allocs[opt.TableID])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return tblIDs, nil
+}
+
 // copySortedTables copies sortedTables for old table and new table for later modification.
 func (b *Builder) copySortedTables(oldTableID, newTableID int64) {
 	buckets := b.is.sortedTablesBuckets
@@ -164,7 +213,7 @@ func (b *Builder) applyCreateTable(m *meta.Meta, roDBInfo *model.DBInfo, tableID
 		if tblInfo.OldSchemaID != 0 {
 			schemaID = tblInfo.OldSchemaID
 		}
-		alloc = autoid.NewAllocator(b.handle.store, schemaID)
+		alloc = autoid.NewAllocatorWithStep(b.handle.store, schemaID, tblInfo.AutoIDCache)
 	}
 	tbl, err := tables.TableFromMeta(alloc, tblInfo)
 	if err != nil {
@@ -269,7 +318,7 @@ func (b *Builder) createSchemaTablesForDB(di *model.DBInfo) error {
 		if t.OldSchemaID != 0 {
 			schemaID = t.OldSchemaID
 		}
-		alloc := autoid.NewAllocator(b.handle.store, schemaID)
+		alloc := autoid.NewAllocatorWithStep(b.handle.store, schemaID, t.AutoIDCache)
 		var tbl table.Table
 		tbl, err := tables.TableFromMeta(alloc, t)
 		if err != nil {
@@ -0,0 +1,134 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infoschema
+
+import (
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/serverinfo"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// clusterTables maps the cluster tables to the tables they aggregate from all the tidb-servers.
+var clusterTables = map[string]string{
+	tableClusterProcesslist: tableProcesslist,
+	tableClusterSlowQuery:   tableSlowQuery,
+}
+
+// clusterTableCols returns the columns of a cluster table, they're the address of the tidb-server followed
+// by the columns of the aggregated table.
+func clusterTableCols(cols []columnInfo) []columnInfo {
+	return append([]columnInfo{{"INSTANCE", mysql.TypeVarchar, 64, 0, nil, nil}}, cols...)
+}
+
+// localTableRows returns the rows of a table aggregated by the cluster tables on this tidb-server.
+func localTableRows(name string, sm util.SessionManager) ([][]types.Datum, error) {
+	switch name {
+	case tableProcesslist:
+		return dataForProcesslist(sm), nil
+	case tableSlowQuery:
+		return dataForSlowQuery(), nil
+	}
+	return nil, errors.Errorf("%s isn't aggregated by the cluster tables", name)
+}
+
+// ClusterTableRowsPath returns the path of the status HTTP API to read the rows of a table on a tidb-server.
+func ClusterTableRowsPath(name string) string {
+	return "/info/tables/" + name
+}
+
+// EncodeLocalTableRows returns the encoded rows of a table aggregated by the cluster tables on this
+// tidb-server, it serves the cluster tables read on the other tidb-servers.
+func EncodeLocalTableRows(name string, sm util.SessionManager) ([][][]byte, error) {
+	rows, err := localTableRows(strings.ToUpper(name), sm)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	res := make([][][]byte, 0, len(rows))
+	for _, row := range rows {
+		values := make([][]byte, 0, len(row))
+		for _, d := range row {
+			b, err := tablecodec.EncodeValue(d, time.UTC)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			values = append(values, b)
+		}
+		res = append(res, values)
+	}
+	return res, nil
+}
+
+// fetchTableRows reads the rows of a table on another tidb-server.
+func fetchTableRows(info *model.ServerInfo, name string) ([][]types.Datum, error) {
+	var encoded [][][]byte
+	if err := serverinfo.Fetch(info, ClusterTableRowsPath(name), &encoded); err != nil {
+		return nil, errors.Trace(err)
+	}
+	cols := tableNameToColumns[name]
+	rows := make([][]types.Datum, 0, len(encoded))
+	for _, values := range encoded {
+		if len(values) != len(cols) {
+			return nil, errors.Errorf("%s of tidb-server %d has %d columns, expected %d", name, info.ID, len(values), len(cols))
+		}
+		row := make([]types.Datum, 0, len(values))
+		for i, b := range values {
+			ft := buildColumnInfo(name, cols[i]).FieldType
+			d, err := tablecodec.DecodeColumnValue(b, &ft, time.UTC)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			row = append(row, d)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// dataForClusterTable aggregates the rows of the table from all the registered tidb-servers. The rows of this
+// tidb-server are read locally, the others are read by their status HTTP API. A tidb-server which can't be
+// read doesn't fail the query, its rows are missing with a warning.
+func dataForClusterTable(ctx context.Context, clusterTable string) (records [][]types.Datum, err error) {
+	name := clusterTables[clusterTable]
+	infos, err := serverinfo.ListServers(ctx.GetStore())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	vars := ctx.GetSessionVars()
+	serverID := util.ServerIDOfConn(vars.ConnectionID)
+	for _, info := range infos {
+		var rows [][]types.Datum
+		if info.ID == serverID {
+			rows, err = localTableRows(name, ctx.GetSessionManager())
+		} else {
+			rows, err = fetchTableRows(info, name)
+		}
+		if err != nil {
+			vars.StmtCtx.AppendWarning(errors.Errorf("read %s of tidb-server %s error: %v", name, info.Address, err))
+			continue
+		}
+		instance := types.NewStringDatum(info.Address)
+		for _, row := range rows {
+			records = append(records, append([]types.Datum{instance}, row...))
+		}
+	}
+	return records, nil
+}
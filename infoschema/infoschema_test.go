@@ -270,6 +270,13 @@ func (*testSuite) TestInfoTables(c *C) {
 		"OPTIMIZER_TRACE",
 		"TABLESPACES",
 		"COLLATION_CHARACTER_SET_APPLICABILITY",
+		"DATA_LOCKS",
+		"DATA_LOCK_WAITS",
+		"PROCESSLIST",
+		"CLUSTER_INFO",
+		"SLOW_QUERY",
+		"CLUSTER_PROCESSLIST",
+		"CLUSTER_SLOW_QUERY",
 	}
 	for _, t := range info_tables {
 		tb, err1 := is.TableByName(model.NewCIStr(infoschema.Name), model.NewCIStr(t))
@@ -14,8 +14,10 @@
 package infoschema
 
 import (
+	"encoding/hex"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -27,7 +29,13 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/rowlock"
+	"github.com/pingcap/tidb/util/serverinfo"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -63,6 +71,13 @@ const (
 	tableOptimizerTrace                     = "OPTIMIZER_TRACE"
 	tableTableSpaces                        = "TABLESPACES"
 	tableCollationCharacterSetApplicability = "COLLATION_CHARACTER_SET_APPLICABILITY"
+	tableDataLocks                          = "DATA_LOCKS"
+	tableDataLockWaits                      = "DATA_LOCK_WAITS"
+	tableProcesslist                        = "PROCESSLIST"
+	tableClusterInfo                        = "CLUSTER_INFO"
+	tableSlowQuery                          = "SLOW_QUERY"
+	tableClusterProcesslist                 = "CLUSTER_PROCESSLIST"
+	tableClusterSlowQuery                   = "CLUSTER_SLOW_QUERY"
 )
 
 type columnInfo struct {
@@ -520,25 +535,23 @@ var tableCollationCharacterSetApplicabilityCols = []columnInfo{
 	{"TABLESPACE_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
 }
 
+// dataForCharacterSets returns the same charsets as SHOW CHARACTER SET, the clients check them on connect.
 func dataForCharacterSets() (records [][]types.Datum) {
-	records = append(records,
-		types.MakeDatums("ascii", "ascii_general_ci", "US ASCII", 1),
-		types.MakeDatums("binary", "binary", "Binary pseudo charset", 1),
-		types.MakeDatums("latin1", "latin1_swedish_ci", "cp1252 West European", 1),
-		types.MakeDatums("utf8", "utf8_general_ci", "UTF-8 Unicode", 3),
-		types.MakeDatums("utf8mb4", "utf8mb4_general_ci", "UTF-8 Unicode", 4),
-	)
+	for _, desc := range charset.GetAllCharsets() {
+		records = append(records, types.MakeDatums(desc.Name, desc.DefaultCollation, desc.Desc, desc.Maxlen))
+	}
 	return records
 }
 
+// dataForColltions returns the same collations as SHOW COLLATION.
 func dataForColltions() (records [][]types.Datum) {
-	records = append(records,
-		types.MakeDatums("ascii_general_ci", "ascii", 1, "Yes", "Yes", 1),
-		types.MakeDatums("binary", "binary", 2, "Yes", "Yes", 1),
-		types.MakeDatums("latin1_swedish_ci", "latin1", 3, "Yes", "Yes", 1),
-		types.MakeDatums("utf8_general_ci", "utf8", 4, "Yes", "Yes", 1),
-		types.MakeDatums("utf8mb4_general_ci", "utf8mb4", 5, "Yes", "Yes", 1),
-	)
+	for _, v := range charset.GetCollations() {
+		isDefault := ""
+		if v.IsDefault {
+			isDefault = "Yes"
+		}
+		records = append(records, types.MakeDatums(v.Name, v.CharsetName, v.ID, isDefault, "Yes", 1))
+	}
 	return records
 }
 
@@ -556,6 +569,173 @@ func dataForSessionVar(ctx context.Context) (records [][]types.Datum, err error)
 	return
 }
 
+var tableDataLocksCols = []columnInfo{
+	{"KEY", mysql.TypeVarchar, 512, 0, nil, nil},
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"TRX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"SESSION_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"SQL_DIGEST", mysql.TypeVarchar, 64, 0, nil, nil},
+}
+
+var tableDataLockWaitsCols = []columnInfo{
+	{"KEY", mysql.TypeVarchar, 512, 0, nil, nil},
+	{"TABLE_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"TRX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"CURRENT_HOLDING_TRX_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"SESSION_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"SQL_DIGEST", mysql.TypeVarchar, 64, 0, nil, nil},
+}
+
+var tableProcesslistCols = []columnInfo{
+	{"ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"HOST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"COMMAND", mysql.TypeVarchar, 16, 0, nil, nil},
+	{"TIME", mysql.TypeLong, 7, 0, nil, nil},
+	{"STATE", mysql.TypeVarchar, 7, 0, nil, nil},
+	{"INFO", mysql.TypeLongBlob, types.UnspecifiedLength, 0, nil, nil},
+	{"PROGRESS", mysql.TypeDouble, 22, 0, nil, nil},
+	{"ROWS_ESTIMATED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ROWS_SCANNED", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"OPERATORS", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"OPERATORS_FINISHED", mysql.TypeLonglong, 21, 0, nil, nil},
+}
+
+var tableClusterInfoCols = []columnInfo{
+	{"ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"ADDRESS", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"STATUS_ADDRESS", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"VERSION", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"GIT_HASH", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"START_TIME", mysql.TypeDatetime, 19, 0, nil, nil},
+}
+
+var tableSlowQueryCols = []columnInfo{
+	{"TIME", mysql.TypeDatetime, 19, 0, nil, nil},
+	{"CONNECTION_ID", mysql.TypeLonglong, 21, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"QUERY_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"QUERY", mysql.TypeLongBlob, types.UnspecifiedLength, 0, nil, nil},
+	{"PLAN_DIGEST", mysql.TypeVarchar, 64, 0, nil, nil},
+}
+
+// lockKeyDatums returns the hex encoded key and the table ID of a row key.
+func lockKeyDatums(key kv.Key) (types.Datum, types.Datum) {
+	tableID, _, err := tablecodec.DecodeRecordKey(key)
+	if err != nil {
+		return types.NewStringDatum(hex.EncodeToString(key)), types.Datum{}
+	}
+	return types.NewStringDatum(hex.EncodeToString(key)), types.NewIntDatum(tableID)
+}
+
+func dataForDataLocks(ctx context.Context) (records [][]types.Datum) {
+	for _, lock := range rowlock.Locks(ctx.GetStore()) {
+		key, tableID := lockKeyDatums(lock.Key)
+		records = append(records, []types.Datum{
+			key,
+			tableID,
+			types.NewUintDatum(lock.StartTS),
+			types.NewUintDatum(lock.SessionID),
+			types.NewStringDatum(lock.SQLDigest),
+		})
+	}
+	return records
+}
+
+func dataForDataLockWaits(ctx context.Context) (records [][]types.Datum) {
+	for _, wait := range rowlock.LockWaits(ctx.GetStore()) {
+		key, tableID := lockKeyDatums(wait.Key)
+		records = append(records, []types.Datum{
+			key,
+			tableID,
+			types.NewUintDatum(wait.StartTS),
+			types.NewUintDatum(wait.HoldingStartTS),
+			types.NewUintDatum(wait.SessionID),
+			types.NewStringDatum(wait.SQLDigest),
+		})
+	}
+	return records
+}
+
+func dataForProcesslist(sm util.SessionManager) (records [][]types.Datum) {
+	if sm == nil {
+		return nil
+	}
+	for _, pi := range sm.ShowProcessList() {
+		var t uint64
+		if len(pi.Info) != 0 {
+			t = uint64(time.Since(pi.Time) / time.Second)
+		}
+		row := []types.Datum{
+			types.NewUintDatum(pi.ID),
+			types.NewStringDatum(pi.User),
+			types.NewStringDatum(pi.Host),
+			types.NewStringDatum(pi.DB),
+			types.NewStringDatum(pi.Command),
+			types.NewUintDatum(t),
+			types.NewStringDatum(fmt.Sprintf("%d", pi.State)),
+			types.NewStringDatum(pi.Info),
+		}
+		if pi.Progress != nil {
+			row = append(row,
+				types.NewFloat64Datum(pi.Progress.Percent()),
+				types.NewIntDatum(pi.Progress.EstimatedRows()),
+				types.NewIntDatum(pi.Progress.ScannedRows()),
+				types.NewIntDatum(pi.Progress.Operators()),
+				types.NewIntDatum(pi.Progress.FinishedOperators()),
+			)
+		} else {
+			// The session is idle.
+			row = append(row, types.Datum{}, types.Datum{}, types.Datum{}, types.Datum{}, types.Datum{})
+		}
+		records = append(records, row)
+	}
+	return records
+}
+
+func dataForSlowQuery() (records [][]types.Datum) {
+	for _, e := range slowlog.Entries() {
+		t := types.Time{
+			Time: types.FromGoTime(e.Time),
+			Type: mysql.TypeDatetime,
+		}
+		records = append(records, []types.Datum{
+			types.NewTimeDatum(t),
+			types.NewUintDatum(e.ConnID),
+			types.NewStringDatum(e.User),
+			types.NewStringDatum(e.DB),
+			types.NewFloat64Datum(e.QueryTime.Seconds()),
+			types.NewStringDatum(e.Query),
+			types.NewStringDatum(e.PlanDigest),
+		})
+	}
+	return records
+}
+
+func dataForClusterInfo(ctx context.Context) (records [][]types.Datum, err error) {
+	infos, err := serverinfo.ListServers(ctx.GetStore())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, info := range infos {
+		startTime := types.Time{
+			Time: types.FromGoTime(time.Unix(info.StartTime, 0)),
+			Type: mysql.TypeDatetime,
+		}
+		records = append(records, []types.Datum{
+			types.NewUintDatum(uint64(info.ID)),
+			types.NewStringDatum(info.Address),
+			types.NewStringDatum(info.StatusAddress),
+			types.NewStringDatum(info.Version),
+			types.NewStringDatum(info.GitHash),
+			types.NewTimeDatum(startTime),
+		})
+	}
+	return records, nil
+}
+
 func dataForUserPrivileges(ctx context.Context) [][]types.Datum {
 	pm := privilege.GetPrivilegeManager(ctx)
 	return pm.UserPrivilegesTable()
@@ -573,6 +753,9 @@ func dataForEngines() (records [][]types.Datum) {
 		types.MakeDatums("FEDERATED", "NO", "Federated MySQL storage engine", nil, nil, nil),
 		types.MakeDatums("PERFORMANCE_SCHEMA", "YES", "Performance Schema", "NO", "NO", "NO"),
 	)
+	for _, name := range engine.Names() {
+		records = append(records, types.MakeDatums(name, "YES", "External storage engine", "NO", "NO", "NO"))
+	}
 	return records
 }
 
@@ -634,27 +817,27 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	for _, schema := range schemas {
 		for _, table := range schema.Tables {
 			record := types.MakeDatums(
-				catalogVal,      // TABLE_CATALOG
-				schema.Name.O,   // TABLE_SCHEMA
-				table.Name.O,    // TABLE_NAME
-				"BASE TABLE",    // TABLE_TYPE
-				"InnoDB",        // ENGINE
-				uint64(10),      // VERSION
-				"Compact",       // ROW_FORMAT
-				uint64(0),       // TABLE_ROWS
-				uint64(0),       // AVG_ROW_LENGTH
-				uint64(16384),   // DATA_LENGTH
-				uint64(0),       // MAX_DATA_LENGTH
-				uint64(0),       // INDEX_LENGTH
-				uint64(0),       // DATA_FREE
-				table.AutoIncID, // AUTO_INCREMENT
-				nil,             // CREATE_TIME
-				nil,             // UPDATE_TIME
-				nil,             // CHECK_TIME
-				table.Collate,   // TABLE_COLLATION
-				nil,             // CHECKSUM
-				"",              // CREATE_OPTIONS
-				table.Comment,   // TABLE_COMMENT
+				catalogVal,           // TABLE_CATALOG
+				schema.Name.O,        // TABLE_SCHEMA
+				table.Name.O,         // TABLE_NAME
+				"BASE TABLE",         // TABLE_TYPE
+				engine.NameOf(table), // ENGINE
+				uint64(10),           // VERSION
+				"Compact",            // ROW_FORMAT
+				uint64(0),            // TABLE_ROWS
+				uint64(0),            // AVG_ROW_LENGTH
+				uint64(16384),        // DATA_LENGTH
+				uint64(0),            // MAX_DATA_LENGTH
+				uint64(0),            // INDEX_LENGTH
+				uint64(0),            // DATA_FREE
+				table.AutoIncID,      // AUTO_INCREMENT
+				nil,                  // CREATE_TIME
+				nil,                  // UPDATE_TIME
+				nil,                  // CHECK_TIME
+				table.Collate,        // TABLE_COLLATION
+				nil,                  // CHECKSUM
+				"",                   // CREATE_OPTIONS
+				table.Comment,        // TABLE_COMMENT
 			)
 			rows = append(rows, record)
 		}
@@ -699,18 +882,18 @@ func dataForColumnsInTable(schema *model.DBInfo, tbl *model.TableInfo) [][]types
 			columnDefault,                        // COLUMN_DEFAULT
 			columnDesc.Null,                      // IS_NULLABLE
 			types.TypeToStr(col.Tp, col.Charset), // DATA_TYPE
-			colLen,                            // CHARACTER_MAXIMUM_LENGTH
-			colLen,                            // CHARACTER_OCTET_LENGTH
-			decimal,                           // NUMERIC_PRECISION
-			0,                                 // NUMERIC_SCALE
-			0,                                 // DATETIME_PRECISION
-			col.Charset,                       // CHARACTER_SET_NAME
-			col.Collate,                       // COLLATION_NAME
-			columnType,                        // COLUMN_TYPE
-			columnDesc.Key,                    // COLUMN_KEY
-			columnDesc.Extra,                  // EXTRA
-			"select,insert,update,references", // PRIVILEGES
-			columnDesc.Comment,                // COLUMN_COMMENT
+			colLen,                               // CHARACTER_MAXIMUM_LENGTH
+			colLen,                               // CHARACTER_OCTET_LENGTH
+			decimal,                              // NUMERIC_PRECISION
+			0,                                    // NUMERIC_SCALE
+			0,                                    // DATETIME_PRECISION
+			col.Charset,                          // CHARACTER_SET_NAME
+			col.Collate,                          // COLLATION_NAME
+			columnType,                           // COLUMN_TYPE
+			columnDesc.Key,                       // COLUMN_KEY
+			columnDesc.Extra,                     // EXTRA
+			"select,insert,update,references",    // PRIVILEGES
+			columnDesc.Comment,                   // COLUMN_COMMENT
 		)
 		rows = append(rows, record)
 	}
@@ -770,6 +953,10 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 			if mysql.HasNotNullFlag(col.Flag) {
 				nullable = ""
 			}
+			collation := "A"
+			if key.Desc {
+				collation = "D"
+			}
 			record := types.MakeDatums(
 				catalogVal,    // TABLE_CATALOG
 				schema.Name.O, // TABLE_SCHEMA
@@ -779,7 +966,7 @@ func dataForStatisticsInTable(schema *model.DBInfo, table *model.TableInfo) [][]
 				index.Name.O,  // INDEX_NAME
 				i+1,           // SEQ_IN_INDEX
 				key.Name.O,    // COLUMN_NAME
-				"A",           // COLLATION
+				collation,     // COLLATION
 				0,             // CARDINALITY
 				nil,           // SUB_PART
 				nil,           // PACKED
@@ -970,6 +1157,13 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableOptimizerTrace:                     tableOptimizerTraceCols,
 	tableTableSpaces:                        tableTableSpacesCols,
 	tableCollationCharacterSetApplicability: tableCollationCharacterSetApplicabilityCols,
+	tableDataLocks:                          tableDataLocksCols,
+	tableDataLockWaits:                      tableDataLockWaitsCols,
+	tableProcesslist:                        tableProcesslistCols,
+	tableClusterInfo:                        tableClusterInfoCols,
+	tableSlowQuery:                          tableSlowQueryCols,
+	tableClusterProcesslist:                 clusterTableCols(tableProcesslistCols),
+	tableClusterSlowQuery:                   clusterTableCols(tableSlowQueryCols),
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -1038,6 +1232,18 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 		fullRows = dataForUserPrivileges(ctx)
 	case tableEngines:
 		fullRows = dataForEngines()
+	case tableDataLocks:
+		fullRows = dataForDataLocks(ctx)
+	case tableDataLockWaits:
+		fullRows = dataForDataLockWaits(ctx)
+	case tableProcesslist:
+		fullRows = dataForProcesslist(ctx.GetSessionManager())
+	case tableSlowQuery:
+		fullRows = dataForSlowQuery()
+	case tableClusterProcesslist, tableClusterSlowQuery:
+		fullRows, err = dataForClusterTable(ctx, it.meta.Name.O)
+	case tableClusterInfo:
+		fullRows, err = dataForClusterInfo(ctx)
 	case tableViews:
 	case tableRoutines:
 	// TODO: Fill the following tables.
@@ -14,6 +14,7 @@
 package inspectkv
 
 import (
+	"encoding/json"
 	"io"
 	"reflect"
 	"time"
@@ -34,29 +35,41 @@ import (
 // DDLInfo is for DDL information.
 type DDLInfo struct {
 	SchemaVer   int64
-	ReorgHandle int64 // it's only used for DDL information.
-	Job         *model.Job
+	ReorgHandle int64        // it's only used for DDL information.
+	Jobs        []*model.Job // It's the currently running jobs, one job for each job queue.
 }
 
+// jobListKeys are the keys of the DDL job queues.
+var jobListKeys = []meta.JobListKeyType{meta.DefaultJobListKey, meta.AddIndexJobListKey}
+
 // GetDDLInfo returns DDL information.
 func GetDDLInfo(txn kv.Transaction) (*DDLInfo, error) {
 	var err error
 	info := &DDLInfo{}
 	t := meta.NewMeta(txn)
 
-	info.Job, err = t.GetDDLJob(0)
+	info.SchemaVer, err = t.GetSchemaVersion()
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	info.SchemaVer, err = t.GetSchemaVersion()
+	generalJob, err := t.GetDDLJob(0)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	if info.Job == nil {
-		return info, nil
+	if generalJob != nil {
+		info.Jobs = append(info.Jobs, generalJob)
 	}
 
-	info.ReorgHandle, err = t.GetDDLReorgHandle(info.Job)
+	addIdxJob, err := meta.NewMeta(txn, meta.AddIndexJobListKey).GetDDLJob(0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if addIdxJob == nil {
+		return info, nil
+	}
+	info.Jobs = append(info.Jobs, addIdxJob)
+	// Only the jobs that add indices reorganize the data.
+	info.ReorgHandle, err = t.GetDDLReorgHandle(addIdxJob)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -64,22 +77,122 @@ func GetDDLInfo(txn kv.Transaction) (*DDLInfo, error) {
 	return info, nil
 }
 
-// GetDDLJobs returns the DDL jobs and an error.
+// GetDDLJobs returns the DDL jobs of all the job queues and an error.
 func GetDDLJobs(txn kv.Transaction) ([]*model.Job, error) {
-	t := meta.NewMeta(txn)
-	cnt, err := t.DDLJobQueueLen()
-	if err != nil {
-		return nil, errors.Trace(err)
+	var jobs []*model.Job
+	for _, key := range jobListKeys {
+		queueJobs, err := meta.NewMeta(txn, key).GetAllDDLJobsInQueue()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, queueJobs...)
 	}
+	return jobs, nil
+}
+
+// CancelJobs cancels the DDL jobs in the queue, it returns the errors of the jobs in order, nil for the cancelled job.
+// The job that hasn't changed the schema is cancelled, the job that adds indices is rolled back until they are public.
+func CancelJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	return updateJobs(txn, ids, func(job *model.Job) error {
+		if !isJobCancellable(job) {
+			return errCannotCancelDDLJob.GenByArgs(job.ID)
+		}
+		job.State = model.JobCancelling
+		return nil
+	})
+}
 
-	jobs := make([]*model.Job, cnt)
-	for i := range jobs {
-		jobs[i], err = t.GetDDLJob(int64(i))
+// PauseJobs pauses the DDL jobs in the queue, it returns the errors of the jobs in order, nil for the paused job.
+// The DDL worker stops the reorganization of a paused job, and doesn't run the jobs after it until it's resumed.
+func PauseJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	return updateJobs(txn, ids, func(job *model.Job) error {
+		if job.State != model.JobNone && job.State != model.JobRunning {
+			return errCannotPauseDDLJob.GenByArgs(job.ID, job.State)
+		}
+		job.State = model.JobPaused
+		return nil
+	})
+}
+
+// ResumeJobs resumes the paused DDL jobs, it returns the errors of the jobs in order, nil for the resumed job.
+func ResumeJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	return updateJobs(txn, ids, func(job *model.Job) error {
+		if !job.IsPaused() {
+			return errCannotResumeDDLJob.GenByArgs(job.ID, job.State)
+		}
+		job.State = model.JobRunning
+		return nil
+	})
+}
+
+func updateJobs(txn kv.Transaction, ids []int64, update func(job *model.Job) error) ([]error, error) {
+	errs := make([]error, len(ids))
+	found := make([]bool, len(ids))
+	for _, key := range jobListKeys {
+		t := meta.NewMeta(txn, key)
+		jobs, err := t.GetAllDDLJobsInQueue()
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		for i, job := range jobs {
+			for j, id := range ids {
+				if id != job.ID {
+					continue
+				}
+				found[j] = true
+				errs[j] = update(job)
+				if errs[j] != nil {
+					continue
+				}
+				// The args of the job aren't decoded, keep its raw args.
+				if err = t.UpdateDDLJob(int64(i), job, false); err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+		}
 	}
-	return jobs, nil
+	for i, id := range ids {
+		if !found[i] {
+			errs[i] = errDDLJobNotFound.GenByArgs(id)
+		}
+	}
+	return errs, nil
+}
+
+// isJobCancellable returns whether the job can be cancelled. It can if it hasn't changed the schema,
+// or it's an add index job, a modify column elems job or a multi schema change job that can be rolled back.
+func isJobCancellable(job *model.Job) bool {
+	if job.State != model.JobNone && job.State != model.JobRunning && job.State != model.JobPaused {
+		return false
+	}
+	if job.SchemaState == model.StateNone {
+		return true
+	}
+
+	switch job.Type {
+	case model.ActionAddIndex, model.ActionModifyColumnElems:
+		return job.SchemaState != model.StatePublic
+	case model.ActionMultiSchemaChange:
+		// It can be rolled back until the added columns and indices are public.
+		var subJobs []*model.SubJob
+		args := []interface{}{&subJobs}
+		if err := json.Unmarshal(job.RawArgs, &args); err != nil {
+			log.Warnf("[inspectkv] decode args of DDL job %d failed %v", job.ID, err)
+			return false
+		}
+		hasAdded := false
+		for _, sub := range subJobs {
+			if sub.Type != model.ActionAddColumn && sub.Type != model.ActionAddIndex {
+				continue
+			}
+			if sub.SchemaState == model.StatePublic {
+				return false
+			}
+			hasAdded = true
+		}
+		return hasAdded
+	}
+	return false
 }
 
 const maxHistoryJobs = 10
@@ -504,10 +617,18 @@ const (
 	codeDataNotEqual       terror.ErrCode = 1
 	codeRepeatHandle                      = 2
 	codeInvalidColumnState                = 3
+	codeDDLJobNotFound                    = 4
+	codeCannotCancelDDLJob                = 5
+	codeCannotPauseDDLJob                 = 6
+	codeCannotResumeDDLJob                = 7
 )
 
 var (
 	errDateNotEqual       = terror.ClassInspectkv.New(codeDataNotEqual, "data isn't equal")
 	errRepeatHandle       = terror.ClassInspectkv.New(codeRepeatHandle, "handle is repeated")
 	errInvalidColumnState = terror.ClassInspectkv.New(codeInvalidColumnState, "invalid column state")
+	errDDLJobNotFound     = terror.ClassInspectkv.New(codeDDLJobNotFound, "DDL job %d not found")
+	errCannotCancelDDLJob = terror.ClassInspectkv.New(codeCannotCancelDDLJob, "DDL job %d can't be cancelled now")
+	errCannotPauseDDLJob  = terror.ClassInspectkv.New(codeCannotPauseDDLJob, "DDL job %d in state %s can't be paused")
+	errCannotResumeDDLJob = terror.ClassInspectkv.New(codeCannotResumeDDLJob, "DDL job %d in state %s isn't paused")
 )
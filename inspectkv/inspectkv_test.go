@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
@@ -155,8 +156,28 @@ func (s *testSuite) TestGetDDLInfo(c *C) {
 	c.Assert(err, IsNil)
 	info, err := GetDDLInfo(txn)
 	c.Assert(err, IsNil)
-	c.Assert(info.Job, DeepEquals, job)
+	c.Assert(info.Jobs, HasLen, 1)
+	c.Assert(info.Jobs[0], DeepEquals, job)
 	c.Assert(info.ReorgHandle, Equals, int64(0))
+
+	// Add a job to the add index job queue.
+	addIdxJob := &model.Job{
+		ID:       2,
+		SchemaID: dbInfo2.ID,
+		TableID:  3,
+		Type:     model.ActionAddIndex,
+	}
+	err = meta.NewMeta(txn, meta.AddIndexJobListKey).EnQueueDDLJob(addIdxJob)
+	c.Assert(err, IsNil)
+	c.Assert(t.UpdateDDLReorgHandle(addIdxJob, 10), IsNil)
+	info, err = GetDDLInfo(txn)
+	c.Assert(err, IsNil)
+	c.Assert(info.Jobs, HasLen, 2)
+	c.Assert(info.Jobs[1], DeepEquals, addIdxJob)
+	c.Assert(info.ReorgHandle, Equals, int64(10))
+	jobs, err := GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	c.Assert(jobs, HasLen, 2)
 	err = txn.Rollback()
 	c.Assert(err, IsNil)
 }
@@ -194,6 +215,55 @@ func (s *testSuite) TestGetDDLJobs(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *testSuite) TestUpdateDDLJobs(c *C) {
+	defer testleak.AfterTest(c)()
+
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	t := meta.NewMeta(txn)
+	jobs := []*model.Job{
+		{ID: 1, Type: model.ActionCreateTable, Args: []interface{}{model.NewCIStr("t")}},
+		{ID: 2, Type: model.ActionAddColumn, SchemaState: model.StateWriteOnly, State: model.JobRunning},
+		{ID: 3, Type: model.ActionAddIndex, SchemaState: model.StateWriteReorganization, State: model.JobRunning},
+		{ID: 4, Type: model.ActionAddIndex, SchemaState: model.StateDeleteOnly, State: model.JobRollback},
+	}
+	for _, job := range jobs {
+		c.Assert(t.EnQueueDDLJob(job), IsNil)
+	}
+
+	errs, err := PauseJobs(txn, []int64{1, 4})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0], IsNil)
+	c.Assert(terror.ErrorEqual(errs[1], errCannotPauseDDLJob), IsTrue)
+	errs, err = ResumeJobs(txn, []int64{1, 2})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0], IsNil)
+	c.Assert(terror.ErrorEqual(errs[1], errCannotResumeDDLJob), IsTrue)
+
+	errs, err = CancelJobs(txn, []int64{1, 2, 3, 4, 5})
+	c.Assert(err, IsNil)
+	c.Assert(errs, HasLen, 5)
+	c.Assert(errs[0], IsNil)
+	c.Assert(terror.ErrorEqual(errs[1], errCannotCancelDDLJob), IsTrue)
+	c.Assert(errs[2], IsNil)
+	c.Assert(terror.ErrorEqual(errs[3], errCannotCancelDDLJob), IsTrue)
+	c.Assert(terror.ErrorEqual(errs[4], errDDLJobNotFound), IsTrue)
+
+	currJobs, err := GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	c.Assert(currJobs[0].State, Equals, model.JobCancelling)
+	c.Assert(currJobs[1].State, Equals, model.JobRunning)
+	c.Assert(currJobs[2].State, Equals, model.JobCancelling)
+	c.Assert(currJobs[3].State, Equals, model.JobRollback)
+	// The args of the updated jobs are kept.
+	var name model.CIStr
+	c.Assert(currJobs[0].DecodeArgs(&name), IsNil)
+	c.Assert(name.L, Equals, "t")
+
+	err = txn.Rollback()
+	c.Assert(err, IsNil)
+}
+
 func (s *testSuite) TestGetHistoryDDLJobs(c *C) {
 	defer testleak.AfterTest(c)()
 
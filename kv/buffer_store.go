@@ -78,6 +78,11 @@ func (s *BufferStore) SeekReverse(k Key) (Iterator, error) {
 	return newUnionIter(bufferIt, retrieverIt, true), nil
 }
 
+// GetMemBuffer returns the buffered kv pairs, the value of a deleted key is empty.
+func (s *BufferStore) GetMemBuffer() MemBuffer {
+	return s.MemBuffer
+}
+
 // WalkBuffer iterates all buffered kv pairs.
 func (s *BufferStore) WalkBuffer(f func(k Key, v []byte) error) error {
 	iter, err := s.MemBuffer.Seek(nil)
@@ -38,6 +38,10 @@ const (
 	IsolationLevel
 	// Priority marks the priority of this transaction.
 	Priority
+	// SnapshotTS sets the timestamp the transaction reads at, the read committed transactions read the data
+	// committed before each statement with it. The writes are still checked for conflicts from the start
+	// timestamp when the transaction commits.
+	SnapshotTS
 )
 
 // Priority value for transaction priority.
@@ -131,6 +135,9 @@ type Transaction interface {
 	// Valid returns if the transaction is valid.
 	// A transaction become invalid after commit or rollback.
 	Valid() bool
+	// BatchPrefetch reads the values of the keys from the snapshot by a BatchGet and caches them in the
+	// transaction, so the later Get of the keys doesn't read the store one by one.
+	BatchPrefetch(keys []Key) error
 }
 
 // Client is used to send request to KV layer.
@@ -154,6 +161,17 @@ const (
 	ReqSubTypeGroupBy   = 10001
 	ReqSubTypeTopN      = 10002
 	ReqSubTypeSignature = 10003
+	// ReqSubTypeRowFormatV2 is supported if the coprocessor decodes the rows of the format version 2.
+	ReqSubTypeRowFormatV2 = 10004
+	// ReqSubTypeNewCollation is supported if the coprocessor compares the strings by the new collations,
+	// see the collate package.
+	ReqSubTypeNewCollation = 10005
+	// ReqSubTypeElemsByName is supported if the coprocessor decodes the enum and set values stored as the names of
+	// the elements.
+	ReqSubTypeElemsByName = 10006
+	// ReqSubTypeDescKey is supported if the coprocessor decodes the descending index columns encoded by
+	// codec.EncodeKeyDesc.
+	ReqSubTypeDescKey = 10007
 )
 
 // Request represents a kv request.
@@ -224,6 +242,51 @@ type Storage interface {
 	SupportDeleteRange() (supported bool)
 }
 
+// SplitableStore is the storage which stores the data in regions, and the regions can be split and scattered by
+// the 'admin split table' and 'admin scatter table' statements, so the hot data can be distributed to the stores.
+type SplitableStore interface {
+	// SplitRegion splits the region which contains the key at the key.
+	SplitRegion(splitKey Key) error
+	// ScatterRange scatters the leaders of the regions in the range [startKey, endKey) to the stores.
+	ScatterRange(startKey, endKey Key) error
+}
+
+// PlacementRule places the replicas of the regions in the range [StartKey, EndKey) to the stores matching the
+// label constraints.
+type PlacementRule struct {
+	ID       string
+	StartKey Key
+	EndKey   Key
+	// Role is the role of the replicas placed by the rule, it's "voter", "leader", "follower" or "learner".
+	Role  string
+	Count int
+	// LabelConstraints are the labels the stores must have, or must not have if Exclude is set.
+	LabelConstraints []PlacementLabelConstraint
+}
+
+// PlacementLabelConstraint is a label constraint of a placement rule.
+type PlacementLabelConstraint struct {
+	Key     string
+	Value   string
+	Exclude bool
+}
+
+// PlacementStore is the storage which places the replicas of the data by the placement rules, the placement
+// policies of the tables are translated into the rules.
+type PlacementStore interface {
+	// SetPlacementRules replaces the rules of the group, the group is removed if rules is empty.
+	SetPlacementRules(group string, rules []*PlacementRule) error
+}
+
+// PipelinedTransaction is the transaction which prewrites its mutations in the background while it's executing,
+// so the commit of a large transaction overlaps with its execution.
+type PipelinedTransaction interface {
+	// PrewriteAhead starts prewriting the mutations buffered so far. If a prewritten key is changed afterwards,
+	// the commit fails with a retryable error. The transaction should read at StartTS()-1 after it's called to
+	// skip its own locks. The errors of the prewrite are returned by the commit.
+	PrewriteAhead()
+}
+
 // FnKeyCmp is the function for iterator the keys
 type FnKeyCmp func(key Key) bool
 
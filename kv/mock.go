@@ -86,6 +86,10 @@ func (t *mockTxn) Valid() bool {
 	return t.valid
 }
 
+func (t *mockTxn) BatchPrefetch(keys []Key) error {
+	return nil
+}
+
 func (t *mockTxn) Len() int {
 	return 0
 }
@@ -28,6 +28,8 @@ type UnionStore interface {
 	CheckLazyConditionPairs() error
 	// WalkBuffer iterates all buffered kv pairs.
 	WalkBuffer(f func(k Key, v []byte) error) error
+	// GetMemBuffer returns the buffered kv pairs, the value of a deleted key is empty.
+	GetMemBuffer() MemBuffer
 	// SetOption sets an option with a value, when val is nil, uses the default
 	// value of this option.
 	SetOption(opt Option, val interface{})
@@ -35,6 +37,9 @@ type UnionStore interface {
 	DelOption(opt Option)
 	// GetOption gets an option.
 	GetOption(opt Option) interface{}
+	// BatchPrefetch reads the keys which are not in the buffer from the snapshot by a BatchGet and caches
+	// their values, so the later Get of the keys doesn't read the snapshot one by one.
+	BatchPrefetch(keys []Key) error
 }
 
 // Option is used for customizing kv store's behaviors during a transaction.
@@ -61,6 +66,8 @@ type unionStore struct {
 	snapshot           Snapshot                    // for read
 	lazyConditionPairs map[string](*conditionPair) // for delay check
 	opts               options
+	// prefetched caches the values read by BatchPrefetch, the value of a key which doesn't exist is nil.
+	prefetched map[string][]byte
 }
 
 // NewUnionStore builds a new UnionStore.
@@ -155,7 +162,9 @@ func (lmb *lazyMemBuffer) Len() int {
 func (us *unionStore) Get(k Key) ([]byte, error) {
 	v, err := us.MemBuffer.Get(k)
 	if IsErrNotFound(err) {
-		if _, ok := us.opts.Get(PresumeKeyNotExists); ok {
+		if cached, ok := us.prefetched[string(k)]; ok {
+			v, err = cached, nil
+		} else if _, ok := us.opts.Get(PresumeKeyNotExists); ok {
 			e, ok := us.opts.Get(PresumeKeyNotExistsError)
 			if ok && e != nil {
 				us.markLazyConditionPair(k, nil, e.(error))
@@ -177,6 +186,34 @@ func (us *unionStore) Get(k Key) ([]byte, error) {
 	return v, nil
 }
 
+// BatchPrefetch implements the UnionStore BatchPrefetch interface.
+func (us *unionStore) BatchPrefetch(keys []Key) error {
+	fetchKeys := make([]Key, 0, len(keys))
+	for _, k := range keys {
+		if _, ok := us.prefetched[string(k)]; ok {
+			continue
+		}
+		if _, err := us.MemBuffer.Get(k); !IsErrNotFound(err) {
+			continue
+		}
+		fetchKeys = append(fetchKeys, k)
+	}
+	if len(fetchKeys) == 0 {
+		return nil
+	}
+	values, err := us.snapshot.BatchGet(fetchKeys)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if us.prefetched == nil {
+		us.prefetched = make(map[string][]byte, len(fetchKeys))
+	}
+	for _, k := range fetchKeys {
+		us.prefetched[string(k)] = values[string(k)]
+	}
+	return nil
+}
+
 // markLazyConditionPair marks a kv pair for later check.
 // If condition not match, should return e as error.
 func (us *unionStore) markLazyConditionPair(k Key, v []byte, e error) {
@@ -125,6 +125,42 @@ func (s *testUnionStoreSuite) TestLazyConditionCheck(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testUnionStoreSuite) TestBatchPrefetch(c *C) {
+	defer testleak.AfterTest(c)()
+	s.store.Set([]byte("1"), []byte("1"))
+	s.store.Set([]byte("2"), []byte("2"))
+	s.us.Set([]byte("3"), []byte("3"))
+
+	err := s.us.BatchPrefetch([]Key{[]byte("1"), []byte("3"), []byte("4")})
+	c.Assert(err, IsNil)
+	// The prefetched values are read from the cache instead of the snapshot.
+	s.store.Set([]byte("1"), []byte("5"))
+	s.store.Set([]byte("2"), []byte("6"))
+	s.store.Set([]byte("4"), []byte("4"))
+	v, err := s.us.Get([]byte("1"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("1"))
+	v, err = s.us.Get([]byte("2"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("6"))
+	v, err = s.us.Get([]byte("3"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("3"))
+	_, err = s.us.Get([]byte("4"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+
+	// The buffer is read before the cache.
+	s.us.Delete([]byte("1"))
+	_, err = s.us.Get([]byte("1"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+
+	// The prefetched keys are not presumed not to exist.
+	s.us.SetOption(PresumeKeyNotExists, nil)
+	_, err = s.us.Get([]byte("4"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+	c.Assert(s.us.CheckLazyConditionPairs(), IsNil)
+}
+
 func checkIterator(c *C, iter Iterator, keys [][]byte, values [][]byte) {
 	defer iter.Close()
 	c.Assert(len(keys), Equals, len(values))
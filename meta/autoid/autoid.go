@@ -48,6 +48,15 @@ type allocator struct {
 	end   int64
 	store kv.Storage
 	dbID  int64
+	// step is the number of IDs allocated from the store at a time, 0 means the default step.
+	step int64
+}
+
+func (alloc *allocator) getStep() int64 {
+	if alloc.step > 0 {
+		return alloc.step
+	}
+	return step
 }
 
 // GetStep is only used by tests
@@ -81,9 +90,10 @@ func (alloc *allocator) Rebase(tableID, newBase int64, allocIDs bool) error {
 		if newBase < end {
 			newBase = end
 		}
-		newStep := newBase - end + step
-		if !allocIDs {
-			newStep = newBase - end
+		newStep := newBase - end
+		// Nothing is cached over the new base with the step of 1, so the IDs stay contiguous.
+		if allocIDs && alloc.getStep() > 1 {
+			newStep += alloc.getStep()
 		}
 		end, err = m.GenAutoTableID(alloc.dbID, tableID, newStep)
 		if err != nil {
@@ -107,22 +117,23 @@ func (alloc *allocator) Alloc(tableID int64) (int64, error) {
 	alloc.mu.Lock()
 	defer alloc.mu.Unlock()
 	if alloc.base == alloc.end { // step
+		allocStep := alloc.getStep()
 		err := kv.RunInNewTxn(alloc.store, true, func(txn kv.Transaction) error {
 			m := meta.NewMeta(txn)
 			base, err1 := m.GetAutoTableID(alloc.dbID, tableID)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
-			end, err1 := m.GenAutoTableID(alloc.dbID, tableID, step)
+			end, err1 := m.GenAutoTableID(alloc.dbID, tableID, allocStep)
 			if err1 != nil {
 				return errors.Trace(err1)
 			}
 
 			alloc.end = end
-			if end == step {
+			if end == allocStep {
 				alloc.base = base
 			} else {
-				alloc.base = end - step
+				alloc.base = end - allocStep
 			}
 			return nil
 		})
@@ -181,6 +192,17 @@ func NewAllocator(store kv.Storage, dbID int64) Allocator {
 	}
 }
 
+// NewAllocatorWithStep returns a new auto increment id generator on the store, which allocates step IDs
+// from the store at a time. With the step of 1 every ID is allocated from the store, so the IDs of all
+// the tidb-servers are contiguous.
+func NewAllocatorWithStep(store kv.Storage, dbID int64, step int64) Allocator {
+	return &allocator{
+		store: store,
+		dbID:  dbID,
+		step:  step,
+	}
+}
+
 // NewMemoryAllocator returns a new auto increment id generator in memory.
 func NewMemoryAllocator(dbID int64) Allocator {
 	return &memoryAllocator{
@@ -125,6 +125,51 @@ func (*testSuite) TestT(c *C) {
 
 // TestConcurrentAlloc is used for the test that
 // multiple alloctors allocate ID with the same table ID concurrently.
+func (*testSuite) TestAllocWithStep(c *C) {
+	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
+	store, err := driver.Open("memory")
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		err = m.CreateDatabase(&model.DBInfo{ID: 1, Name: model.NewCIStr("a")})
+		c.Assert(err, IsNil)
+		err = m.CreateTable(1, &model.TableInfo{ID: 1, Name: model.NewCIStr("t")})
+		c.Assert(err, IsNil)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	// The allocators of different servers allocate contiguous IDs with the step of 1.
+	alloc1 := NewAllocatorWithStep(store, 1, 1)
+	alloc2 := NewAllocatorWithStep(store, 1, 1)
+	for i := int64(1); i <= 6; i += 2 {
+		id, err1 := alloc1.Alloc(1)
+		c.Assert(err1, IsNil)
+		c.Assert(id, Equals, i)
+		id, err1 = alloc2.Alloc(1)
+		c.Assert(err1, IsNil)
+		c.Assert(id, Equals, i+1)
+	}
+	c.Assert(alloc1.Rebase(1, 10, true), IsNil)
+	id, err := alloc2.Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(11))
+	id, err = alloc1.Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(12))
+
+	// The default step caches the IDs in each allocator.
+	alloc3 := NewAllocatorWithStep(store, 1, 0)
+	id, err = alloc3.Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, int64(13))
+	id, err = alloc1.Alloc(1)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, GetStep()+13)
+}
+
 func (*testSuite) TestConcurrentAlloc(c *C) {
 	driver := localstore.Driver{Driver: goleveldb.MemoryDriver{}}
 	store, err := driver.Open("memory")
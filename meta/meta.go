@@ -60,6 +60,10 @@ var (
 	mBootstrapKey     = []byte("BootstrapKey")
 	mTableStatsPrefix = "TStats"
 	mSchemaDiffPrefix = "Diff"
+	mUserLockPrefix   = "UserLock"
+	mNextServerIDKey  = []byte("NextServerID")
+	mServerInfos      = []byte("ServerInfos")
+	mLocalTSOKey      = []byte("LocalTSO")
 )
 
 var (
@@ -78,20 +82,26 @@ var (
 
 // Meta is for handling meta information in a transaction.
 type Meta struct {
-	txn *structure.TxStructure
+	txn        *structure.TxStructure
+	jobListKey JobListKeyType
 }
 
 // NewMeta creates a Meta in transaction txn.
-func NewMeta(txn kv.Transaction) *Meta {
+// If the current Meta needs to handle a job, jobListKey is the type of the job's list.
+func NewMeta(txn kv.Transaction, jobListKeys ...JobListKeyType) *Meta {
 	txn.SetOption(kv.Priority, kv.PriorityHigh)
 	t := structure.NewStructure(txn, txn, mMetaPrefix)
-	return &Meta{txn: t}
+	listKey := DefaultJobListKey
+	if len(jobListKeys) != 0 {
+		listKey = jobListKeys[0]
+	}
+	return &Meta{txn: t, jobListKey: listKey}
 }
 
 // NewSnapshotMeta creates a Meta with snapshot.
 func NewSnapshotMeta(snapshot kv.Snapshot) *Meta {
 	t := structure.NewStructure(snapshot, nil, mMetaPrefix)
-	return &Meta{txn: t}
+	return &Meta{txn: t, jobListKey: DefaultJobListKey}
 }
 
 // GenGlobalID generates next id globally.
@@ -102,6 +112,11 @@ func (m *Meta) GenGlobalID() (int64, error) {
 	return m.txn.Inc(mNextGlobalIDKey, 1)
 }
 
+// GenServerID generates the ID of a new tidb-server instance, the first ID is 1.
+func (m *Meta) GenServerID() (int64, error) {
+	return m.txn.Inc(mNextServerIDKey, 1)
+}
+
 // GetGlobalID gets current global id.
 func (m *Meta) GetGlobalID() (int64, error) {
 	return m.txn.GetInt64(mNextGlobalIDKey)
@@ -435,10 +450,21 @@ func (m *Meta) GetTable(dbID int64, tableID int64) (*model.TableInfo, error) {
 
 var (
 	mDDLJobListKey    = []byte("DDLJobList")
+	mDDLJobAddIdxList = []byte("DDLJobAddIdxList")
 	mDDLJobHistoryKey = []byte("DDLJobHistory")
 	mDDLJobReorgKey   = []byte("DDLJobReorg")
 )
 
+// JobListKeyType is a key type of the DDL job queue.
+type JobListKeyType []byte
+
+var (
+	// DefaultJobListKey keeps all actions of DDL jobs except the ones that add indices.
+	DefaultJobListKey JobListKeyType = mDDLJobListKey
+	// AddIndexJobListKey only keeps the jobs that add indices, they may run for a long time to backfill the indices.
+	AddIndexJobListKey JobListKeyType = mDDLJobAddIdxList
+)
+
 func (m *Meta) enQueueDDLJob(key []byte, job *model.Job, updateRawArgs bool) error {
 	b, err := job.Encode(updateRawArgs)
 	if err != nil {
@@ -449,7 +475,7 @@ func (m *Meta) enQueueDDLJob(key []byte, job *model.Job, updateRawArgs bool) err
 
 // EnQueueDDLJob adds a DDL job to the list.
 func (m *Meta) EnQueueDDLJob(job *model.Job) error {
-	return m.enQueueDDLJob(mDDLJobListKey, job, true)
+	return m.enQueueDDLJob(m.jobListKey, job, true)
 }
 
 func (m *Meta) deQueueDDLJob(key []byte) (*model.Job, error) {
@@ -465,7 +491,7 @@ func (m *Meta) deQueueDDLJob(key []byte) (*model.Job, error) {
 
 // DeQueueDDLJob pops a DDL job from the list.
 func (m *Meta) DeQueueDDLJob() (*model.Job, error) {
-	return m.deQueueDDLJob(mDDLJobListKey)
+	return m.deQueueDDLJob(m.jobListKey)
 }
 
 func (m *Meta) getDDLJob(key []byte, index int64) (*model.Job, error) {
@@ -481,12 +507,12 @@ func (m *Meta) getDDLJob(key []byte, index int64) (*model.Job, error) {
 
 // GetDDLJob returns the DDL job with index.
 func (m *Meta) GetDDLJob(index int64) (*model.Job, error) {
-	job, err := m.getDDLJob(mDDLJobListKey, index)
+	job, err := m.getDDLJob(m.jobListKey, index)
 	return job, errors.Trace(err)
 }
 
-func (m *Meta) updateDDLJob(index int64, job *model.Job, key []byte) error {
-	b, err := job.Encode(true)
+func (m *Meta) updateDDLJob(index int64, job *model.Job, key []byte, updateRawArgs bool) error {
+	b, err := job.Encode(updateRawArgs)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -494,13 +520,31 @@ func (m *Meta) updateDDLJob(index int64, job *model.Job, key []byte) error {
 }
 
 // UpdateDDLJob updates the DDL job with index.
-func (m *Meta) UpdateDDLJob(index int64, job *model.Job) error {
-	return m.updateDDLJob(index, job, mDDLJobListKey)
+// updateRawArgs is false if the job args aren't decoded, then the raw args are kept.
+func (m *Meta) UpdateDDLJob(index int64, job *model.Job, updateRawArgs bool) error {
+	return m.updateDDLJob(index, job, m.jobListKey, updateRawArgs)
 }
 
 // DDLJobQueueLen returns the DDL job queue length.
 func (m *Meta) DDLJobQueueLen() (int64, error) {
-	return m.txn.LLen(mDDLJobListKey)
+	return m.txn.LLen(m.jobListKey)
+}
+
+// GetAllDDLJobsInQueue gets all the DDL jobs in the queue in order.
+func (m *Meta) GetAllDDLJobsInQueue() ([]*model.Job, error) {
+	cnt, err := m.DDLJobQueueLen()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	jobs := make([]*model.Job, cnt)
+	for i := range jobs {
+		jobs[i], err = m.GetDDLJob(int64(i))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return jobs, nil
 }
 
 func (m *Meta) jobIDKey(id int64) []byte {
@@ -641,6 +685,108 @@ func (m *Meta) SetSchemaDiff(diff *model.SchemaDiff) error {
 	return errors.Trace(err)
 }
 
+func (m *Meta) userLockKey(name string) []byte {
+	return []byte(fmt.Sprintf("%s:%s", mUserLockPrefix, name))
+}
+
+// GetUserLock gets the user-level lock by its name, it returns nil if the lock isn't held.
+func (m *Meta) GetUserLock(name string) (*model.UserLock, error) {
+	data, err := m.txn.Get(m.userLockKey(name))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	lock := &model.UserLock{}
+	err = json.Unmarshal(data, lock)
+	return lock, errors.Trace(err)
+}
+
+// SetUserLock sets the holder of the user-level lock.
+func (m *Meta) SetUserLock(lock *model.UserLock) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = m.txn.Set(m.userLockKey(lock.Name), data)
+	return errors.Trace(err)
+}
+
+// RemoveUserLock removes the user-level lock.
+func (m *Meta) RemoveUserLock(name string) error {
+	err := m.txn.Clear(m.userLockKey(name))
+	return errors.Trace(err)
+}
+
+func (m *Meta) serverInfoField(id uint32) []byte {
+	return []byte(strconv.FormatUint(uint64(id), 10))
+}
+
+// SetServerInfo registers the tidb-server or updates its registration.
+func (m *Meta) SetServerInfo(info *model.ServerInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = m.txn.HSet(mServerInfos, m.serverInfoField(info.ID), data)
+	return errors.Trace(err)
+}
+
+// RemoveServerInfo removes the registration of the tidb-server.
+func (m *Meta) RemoveServerInfo(id uint32) error {
+	err := m.txn.HDel(mServerInfos, m.serverInfoField(id))
+	return errors.Trace(err)
+}
+
+// ListServerInfos lists the registered tidb-servers, including the expired ones.
+func (m *Meta) ListServerInfos() ([]*model.ServerInfo, error) {
+	res, err := m.txn.HGetAll(mServerInfos)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	infos := make([]*model.ServerInfo, 0, len(res))
+	for _, r := range res {
+		info := &model.ServerInfo{}
+		if err = json.Unmarshal(r.Value, info); err != nil {
+			return nil, errors.Trace(err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetLocalTSO gets the record of the tidb-server which allocates the timestamps locally, it returns nil if there
+// is none.
+func (m *Meta) GetLocalTSO() (*model.LocalTSOInfo, error) {
+	data, err := m.txn.Get(mLocalTSOKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	info := &model.LocalTSOInfo{}
+	err = json.Unmarshal(data, info)
+	return info, errors.Trace(err)
+}
+
+// SetLocalTSO sets the record of the tidb-server which allocates the timestamps locally.
+func (m *Meta) SetLocalTSO(info *model.LocalTSOInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = m.txn.Set(mLocalTSOKey, data)
+	return errors.Trace(err)
+}
+
+// RemoveLocalTSO removes the record of the tidb-server which allocates the timestamps locally.
+func (m *Meta) RemoveLocalTSO() error {
+	err := m.txn.Clear(mLocalTSOKey)
+	return errors.Trace(err)
+}
+
 // meta error codes.
 const (
 	codeInvalidTableKey terror.ErrCode = 1
@@ -57,6 +57,22 @@ func (s *testSuite) TestMeta(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(n, Equals, int64(1))
 
+	n, err = t.GenServerID()
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, int64(1))
+
+	err = t.SetServerInfo(&model.ServerInfo{ID: 1, Address: "127.0.0.1:4000"})
+	c.Assert(err, IsNil)
+	infos, err := t.ListServerInfos()
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 1)
+	c.Assert(infos[0].Address, Equals, "127.0.0.1:4000")
+	err = t.RemoveServerInfo(1)
+	c.Assert(err, IsNil)
+	infos, err = t.ListServerInfos()
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 0)
+
 	n, err = t.GetSchemaVersion()
 	c.Assert(err, IsNil)
 	c.Assert(n, Equals, int64(0))
@@ -217,6 +233,38 @@ func (s *testSuite) TestMeta(c *C) {
 	readDiff, err := t.GetSchemaDiff(schemaDiff.Version)
 	c.Assert(readDiff, DeepEquals, schemaDiff)
 
+	// Test case for UserLock.
+	userLock, err := t.GetUserLock("lock1")
+	c.Assert(err, IsNil)
+	c.Assert(userLock, IsNil)
+	userLock = &model.UserLock{Name: "lock1", ServerID: "server1", ConnectionID: 1, Expire: 100}
+	err = t.SetUserLock(userLock)
+	c.Assert(err, IsNil)
+	readLock, err := t.GetUserLock("lock1")
+	c.Assert(err, IsNil)
+	c.Assert(readLock, DeepEquals, userLock)
+	err = t.RemoveUserLock("lock1")
+	c.Assert(err, IsNil)
+	readLock, err = t.GetUserLock("lock1")
+	c.Assert(err, IsNil)
+	c.Assert(readLock, IsNil)
+
+	// Test case for LocalTSO.
+	localTSO, err := t.GetLocalTSO()
+	c.Assert(err, IsNil)
+	c.Assert(localTSO, IsNil)
+	localTSO = &model.LocalTSOInfo{Owner: "owner1", MaxTS: 100}
+	err = t.SetLocalTSO(localTSO)
+	c.Assert(err, IsNil)
+	readTSO, err := t.GetLocalTSO()
+	c.Assert(err, IsNil)
+	c.Assert(readTSO, DeepEquals, localTSO)
+	err = t.RemoveLocalTSO()
+	c.Assert(err, IsNil)
+	readTSO, err = t.GetLocalTSO()
+	c.Assert(err, IsNil)
+	c.Assert(readTSO, IsNil)
+
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
@@ -280,7 +328,7 @@ func (s *testSuite) TestDDL(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v, IsNil)
 	job.ID = 2
-	err = t.UpdateDDLJob(0, job)
+	err = t.UpdateDDLJob(0, job, true)
 	c.Assert(err, IsNil)
 
 	err = t.UpdateDDLReorgHandle(job, 1)
@@ -311,6 +359,19 @@ func (s *testSuite) TestDDL(c *C) {
 		lastID = job.ID
 	}
 
+	// The jobs in the add index queue are apart from the ones in the default queue.
+	addIdxJob := &model.Job{ID: 3, Type: model.ActionAddIndex}
+	addIdxMeta := meta.NewMeta(txn, meta.AddIndexJobListKey)
+	c.Assert(addIdxMeta.EnQueueDDLJob(addIdxJob), IsNil)
+	c.Assert(t.EnQueueDDLJob(&model.Job{ID: 4}), IsNil)
+	jobs, err := addIdxMeta.GetAllDDLJobsInQueue()
+	c.Assert(err, IsNil)
+	c.Assert(jobs, DeepEquals, []*model.Job{addIdxJob})
+	jobs, err = t.GetAllDDLJobsInQueue()
+	c.Assert(err, IsNil)
+	c.Assert(jobs, HasLen, 1)
+	c.Assert(jobs[0].ID, Equals, int64(4))
+
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
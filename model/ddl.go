@@ -42,6 +42,11 @@ const (
 	ActionModifyColumn
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionMultiSchemaChange
+	ActionRenameTables
+	ActionModifyColumnElems
+	ActionPauseWrites
+	ActionAlterPlacementPolicy
 )
 
 func (action ActionType) String() string {
@@ -74,6 +79,16 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionMultiSchemaChange:
+		return "multi schema change"
+	case ActionRenameTables:
+		return "rename tables"
+	case ActionModifyColumnElems:
+		return "modify column elems"
+	case ActionPauseWrites:
+		return "pause writes"
+	case ActionAlterPlacementPolicy:
+		return "alter placement policy"
 	default:
 		return "none"
 	}
@@ -135,6 +150,35 @@ type Job struct {
 
 	// Version indicates the DDL job version. For old jobs, it will be 0.
 	Version int64 `json:"version"`
+
+	// DependencyID is the ID of the job in the other DDL job queue that must be finished before this job runs.
+	// It's 0 if the job doesn't depend on any job.
+	DependencyID int64 `json:"dependency_id"`
+}
+
+// SubJob is a schema change of a multi-schema change job.
+type SubJob struct {
+	Type ActionType `json:"type"`
+	// RawArgs are the encoded args, they are the same as the args of the job with the same type.
+	RawArgs     json.RawMessage `json:"raw_args"`
+	SchemaState SchemaState     `json:"schema_state"`
+	// ReorgDone is set when the reorganization of the sub-job is finished.
+	ReorgDone bool `json:"reorg_done"`
+}
+
+// NewSubJob creates a sub-job with the encoded args.
+func NewSubJob(tp ActionType, args ...interface{}) (*SubJob, error) {
+	rawArgs, err := json.Marshal(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &SubJob{Type: tp, RawArgs: rawArgs}, nil
+}
+
+// DecodeArgs decodes the sub-job args.
+func (sub *SubJob) DecodeArgs(args ...interface{}) error {
+	err := json.Unmarshal(sub.RawArgs, &args)
+	return errors.Trace(err)
 }
 
 // SetRowCount sets the number of rows. Make sure it can pass `make race`.
@@ -193,6 +237,78 @@ func (job *Job) String() string {
 		job.ID, job.Type, job.State, job.SchemaState, job.SchemaID, job.TableID, rowCount, len(job.Args))
 }
 
+// IsDependentOn returns whether the job depends on the other job, that is, they change the same table,
+// or one of them creates or drops the schema that the other one changes.
+func (job *Job) IsDependentOn(other *Job) (bool, error) {
+	jobSchemaIDs, jobTableIDs, err := job.affectedIDs()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	otherSchemaIDs, otherTableIDs, err := other.affectedIDs()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+
+	if job.isSchemaJob() && containsID(otherSchemaIDs, job.SchemaID) {
+		return true, nil
+	}
+	if other.isSchemaJob() && containsID(jobSchemaIDs, other.SchemaID) {
+		return true, nil
+	}
+	for _, id := range jobTableIDs {
+		if id != 0 && containsID(otherTableIDs, id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (job *Job) isSchemaJob() bool {
+	return job.Type == ActionCreateSchema || job.Type == ActionDropSchema
+}
+
+// affectedIDs returns the IDs of the schemas and the tables that the job changes.
+func (job *Job) affectedIDs() (schemaIDs, tableIDs []int64, err error) {
+	switch job.Type {
+	case ActionRenameTable:
+		var oldSchemaID int64
+		if err = job.decodeArgsCopy(&oldSchemaID); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		return []int64{oldSchemaID, job.SchemaID}, []int64{job.TableID}, nil
+	case ActionRenameTables:
+		var oldSchemaIDs, newSchemaIDs []int64
+		if err = job.decodeArgsCopy(&oldSchemaIDs, &newSchemaIDs, &tableIDs); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		return append(oldSchemaIDs, newSchemaIDs...), tableIDs, nil
+	}
+	return []int64{job.SchemaID}, []int64{job.TableID}, nil
+}
+
+// decodeArgsCopy decodes the args like DecodeArgs, but it doesn't change the job.
+// The args of a job that isn't in the queue yet aren't encoded, they are encoded here.
+func (job *Job) decodeArgsCopy(args ...interface{}) error {
+	raw := job.RawArgs
+	if len(job.Args) != 0 {
+		var err error
+		raw, err = json.Marshal(job.Args)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(json.Unmarshal(raw, &args))
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
 // IsFinished returns whether job is finished or not.
 // If the job state is Done or Cancelled, it is finished.
 func (job *Job) IsFinished() bool {
@@ -219,6 +335,16 @@ func (job *Job) IsRunning() bool {
 	return job.State == JobRunning
 }
 
+// IsCancelling returns whether the job is requested to be cancelled but not cancelled yet.
+func (job *Job) IsCancelling() bool {
+	return job.State == JobCancelling
+}
+
+// IsPaused returns whether the job is paused.
+func (job *Job) IsPaused() bool {
+	return job.State == JobPaused
+}
+
 // JobState is for job state.
 type JobState byte
 
@@ -236,6 +362,12 @@ const (
 	// JobSynced is used to mark the information about the completion of this job
 	// has been synchronized to all servers.
 	JobSynced
+	// JobCancelling is the state of a job that is requested to be cancelled by the admin statement,
+	// the DDL worker cancels it or rolls it back.
+	JobCancelling
+	// JobPaused is the state of a job that is paused by the admin statement,
+	// the DDL worker doesn't run it until it's resumed.
+	JobPaused
 )
 
 // String implements fmt.Stringer interface.
@@ -253,6 +385,10 @@ func (s JobState) String() string {
 		return "cancelled"
 	case JobSynced:
 		return "synced"
+	case JobCancelling:
+		return "cancelling"
+	case JobPaused:
+		return "paused"
 	default:
 		return "none"
 	}
@@ -270,4 +406,13 @@ type SchemaDiff struct {
 	OldTableID int64 `json:"old_table_id"`
 	// OldSchemaID is the schema ID before rename table, only used by rename table DDL.
 	OldSchemaID int64 `json:"old_schema_id"`
+	// AffectedOpts are the renamed tables, only used by rename tables DDL.
+	AffectedOpts []*AffectedOption `json:"affected_options"`
+}
+
+// AffectedOption is a table affected by a schema change with several tables.
+type AffectedOption struct {
+	SchemaID    int64 `json:"schema_id"`
+	TableID     int64 `json:"table_id"`
+	OldSchemaID int64 `json:"old_schema_id"`
 }
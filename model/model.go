@@ -64,12 +64,18 @@ type ColumnInfo struct {
 	Offset              int                 `json:"offset"`
 	OriginDefaultValue  interface{}         `json:"origin_default"`
 	DefaultValue        interface{}         `json:"default"`
+	DefaultIsExpr       bool                `json:"default_is_expr"`
 	GeneratedExprString string              `json:"generated_expr_string"`
 	GeneratedStored     bool                `json:"generated_stored"`
 	Dependences         map[string]struct{} `json:"dependences"`
 	types.FieldType     `json:"type"`
 	State               SchemaState `json:"state"`
 	Comment             string      `json:"comment"`
+	// ElemsByName is true if the enum and set values of the column are stored as the names of the elements.
+	ElemsByName bool `json:"elems_by_name"`
+	// ChangingElems is the new elements of the enum or set column whose elements are being modified,
+	// the values with the other elements can't be written.
+	ChangingElems []string `json:"changing_elems,omitempty"`
 }
 
 // Clone clones ColumnInfo.
@@ -103,11 +109,52 @@ type TableInfo struct {
 	AutoIncID   int64         `json:"auto_inc_id"`
 	MaxColumnID int64         `json:"max_col_id"`
 	MaxIndexID  int64         `json:"max_idx_id"`
+	// AutoIDCache is the number of the auto-increment IDs each tidb-server allocates at a time,
+	// 0 means the default. With 1 the IDs are allocated one by one from the store, so they are
+	// contiguous in the order of the allocations of all the servers.
+	AutoIDCache int64 `json:"auto_id_cache,omitempty"`
 	// OldSchemaID :
 	// Because auto increment ID has schemaID as prefix,
 	// We need to save original schemaID to keep autoID unchanged
 	// while renaming a table from one database to another.
 	OldSchemaID int64 `json:"old_schema_id,omitempty"`
+
+	// Engine is the name of the external storage engine that holds the rows of the table.
+	// It is empty for tables stored in TiDB itself.
+	Engine string `json:"engine,omitempty"`
+	// EngineOptions are the table options interpreted by the external engine, keyed by lower case option name.
+	EngineOptions map[string]string `json:"engine_options,omitempty"`
+	// WritesPaused is set by ADMIN PAUSE WRITES ON TABLE, the DML statements on the table fail until the
+	// writes are resumed.
+	WritesPaused bool `json:"writes_paused,omitempty"`
+	// PlacementPolicy is set by ALTER TABLE ... PLACEMENT POLICY, the replicas of the table are placed by the
+	// default rules of the storage if it's nil.
+	PlacementPolicy *PlacementPolicy `json:"placement_policy,omitempty"`
+}
+
+// PlacementPolicy is the placement of the replicas of a table's data in the storage.
+type PlacementPolicy struct {
+	// Replicas is the number of the replicas, 0 means the default of the storage.
+	Replicas uint64 `json:"replicas,omitempty"`
+	// Constraints are the labels of the stores to place the replicas.
+	Constraints []*LabelConstraint `json:"constraints,omitempty"`
+	// LeaderConstraints are the labels of the stores to place the leaders.
+	LeaderConstraints []*LabelConstraint `json:"leader_constraints,omitempty"`
+}
+
+// LabelConstraint requires the store to have the label, or not to have it if Exclude is set.
+type LabelConstraint struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Exclude bool   `json:"exclude,omitempty"`
+}
+
+// String implements fmt.Stringer interface, it's in the form of "+key=value" or "-key=value".
+func (c *LabelConstraint) String() string {
+	if c.Exclude {
+		return "-" + c.Key + "=" + c.Value
+	}
+	return "+" + c.Key + "=" + c.Value
 }
 
 // Clone clones TableInfo.
@@ -129,6 +176,13 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	if t.EngineOptions != nil {
+		nt.EngineOptions = make(map[string]string, len(t.EngineOptions))
+		for k, v := range t.EngineOptions {
+			nt.EngineOptions[k] = v
+		}
+	}
+
 	return &nt
 }
 
@@ -175,6 +229,8 @@ type IndexColumn struct {
 	// for indexing;
 	// UnspecifedLength if not using prefix indexing
 	Length int `json:"length"`
+	// Desc is true if the values of the column are stored in descending order.
+	Desc bool `json:"desc"`
 }
 
 // Clone clones IndexColumn.
@@ -220,6 +276,16 @@ type IndexInfo struct {
 	Tp      IndexType      `json:"index_type"` // Index type: Btree or Hash
 }
 
+// HasDescColumn checks whether the index has a column stored in descending order.
+func (index *IndexInfo) HasDescColumn() bool {
+	for _, col := range index.Columns {
+		if col.Desc {
+			return true
+		}
+	}
+	return false
+}
+
 // Clone clones IndexInfo.
 func (index *IndexInfo) Clone() *IndexInfo {
 	ni := *index
@@ -284,6 +350,44 @@ func (db *DBInfo) Clone() *DBInfo {
 	return &newInfo
 }
 
+// UserLock is a user-level lock acquired by GET_LOCK. It's held by a connection of a tidb-server until it's
+// released, or until it's expired if the tidb-server doesn't renew it.
+type UserLock struct {
+	Name         string `json:"name"`
+	ServerID     string `json:"server_id"`
+	ConnectionID uint64 `json:"conn_id"`
+	// Expire is the physical part of the timestamp when the lock is expired, in milliseconds.
+	Expire int64 `json:"expire"`
+}
+
+// ServerInfo is a tidb-server instance registered in the store. It's removed when the tidb-server is closed,
+// or it's expired if the tidb-server doesn't renew it.
+type ServerInfo struct {
+	ID            uint32 `json:"id"`
+	Address       string `json:"address"`
+	StatusAddress string `json:"status_address"`
+	Version       string `json:"version"`
+	GitHash       string `json:"git_hash"`
+	// StartTime is the Unix time when the tidb-server is started, in seconds.
+	StartTime int64 `json:"start_time"`
+	// Expire is the physical part of the timestamp when the registration is expired, in milliseconds.
+	Expire int64 `json:"expire"`
+	// Token authenticates the requests of the other tidb-servers to the status HTTP API of the tidb-server,
+	// only the processes which can read the store know it.
+	Token string `json:"token"`
+}
+
+// LocalTSOInfo is the record of the tidb-server which allocates the timestamps by its local clock instead of PD.
+type LocalTSOInfo struct {
+	// Owner identifies the store which allocates the timestamps.
+	Owner string `json:"owner"`
+	// MaxTS is greater than the timestamps allocated locally, it's renewed while the timestamps are allocated.
+	// The record is expired when the timestamps of PD are greater than it.
+	MaxTS uint64 `json:"max_ts"`
+	// Closed is set when the store is closed, the timestamps aren't allocated by it anymore.
+	Closed bool `json:"closed"`
+}
+
 // CIStr is case insensitive string.
 type CIStr struct {
 	O string `json:"O"` // Original string.
@@ -180,8 +180,29 @@ func (*testModelSuite) TestJobCodec(c *C) {
 	c.Assert(job.IsFinished(), IsTrue)
 	c.Assert(job.IsRunning(), IsFalse)
 	c.Assert(job.IsSynced(), IsFalse)
+	job.State = JobPaused
+	c.Assert(job.IsPaused(), IsTrue)
+	c.Assert(job.IsFinished(), IsFalse)
+	job.State = JobCancelling
+	c.Assert(job.IsCancelling(), IsTrue)
+	c.Assert(job.IsCancelled(), IsFalse)
 	job.SetRowCount(3)
 	c.Assert(job.GetRowCount(), Equals, int64(3))
+
+	sub, err := NewSubJob(ActionDropColumn, NewCIStr("b"))
+	c.Assert(err, IsNil)
+	job.Args = []interface{}{[]*SubJob{sub}}
+	b, err = job.Encode(true)
+	c.Assert(err, IsNil)
+	newJob = &Job{}
+	c.Assert(newJob.Decode(b), IsNil)
+	var subJobs []*SubJob
+	c.Assert(newJob.DecodeArgs(&subJobs), IsNil)
+	c.Assert(subJobs, HasLen, 1)
+	c.Assert(subJobs[0].Type, Equals, ActionDropColumn)
+	name = CIStr{}
+	c.Assert(subJobs[0].DecodeArgs(&name), IsNil)
+	c.Assert(name, DeepEquals, NewCIStr("b"))
 }
 
 func (testModelSuite) TestState(c *C) {
@@ -204,6 +225,8 @@ func (testModelSuite) TestState(c *C) {
 		JobRollback,
 		JobRollbackDone,
 		JobSynced,
+		JobCancelling,
+		JobPaused,
 	}
 
 	for _, state := range jobTbl {
@@ -231,6 +254,9 @@ func (testModelSuite) TestString(c *C) {
 		{ActionDropIndex, "drop index"},
 		{ActionAddColumn, "add column"},
 		{ActionDropColumn, "drop column"},
+		{ActionMultiSchemaChange, "multi schema change"},
+		{ActionRenameTables, "rename tables"},
+		{ActionPauseWrites, "pause writes"},
 	}
 
 	for _, v := range acts {
@@ -238,3 +264,39 @@ func (testModelSuite) TestString(c *C) {
 		c.Assert(str, Equals, v.result)
 	}
 }
+
+func (testModelSuite) TestJobDependence(c *C) {
+	addIdxJob := &Job{ID: 1, Type: ActionAddIndex, SchemaID: 1, TableID: 2}
+	tests := []struct {
+		job         *Job
+		isDependent bool
+	}{
+		{&Job{ID: 2, Type: ActionAddColumn, SchemaID: 1, TableID: 2}, true},
+		{&Job{ID: 3, Type: ActionAddColumn, SchemaID: 1, TableID: 3}, false},
+		{&Job{ID: 4, Type: ActionCreateSchema, SchemaID: 4}, false},
+		{&Job{ID: 5, Type: ActionDropSchema, SchemaID: 1}, true},
+		{&Job{ID: 6, Type: ActionRenameTable, SchemaID: 4, TableID: 2, Args: []interface{}{int64(1), NewCIStr("t")}}, true},
+		{&Job{ID: 7, Type: ActionRenameTables, Args: []interface{}{[]int64{1, 1}, []int64{4, 4}, []int64{3, 2},
+			[]CIStr{NewCIStr("t1"), NewCIStr("t2")}}}, true},
+		{&Job{ID: 8, Type: ActionRenameTables, Args: []interface{}{[]int64{1}, []int64{4}, []int64{3},
+			[]CIStr{NewCIStr("t1")}}}, false},
+	}
+	for _, t := range tests {
+		isDependent, err := t.job.IsDependentOn(addIdxJob)
+		c.Assert(err, IsNil)
+		c.Assert(isDependent, Equals, t.isDependent, Commentf("job %v", t.job))
+		isDependent, err = addIdxJob.IsDependentOn(t.job)
+		c.Assert(err, IsNil)
+		c.Assert(isDependent, Equals, t.isDependent, Commentf("job %v", t.job))
+	}
+
+	// The encoded args of the job in the queue are decoded too.
+	b, err := tests[5].job.Encode(true)
+	c.Assert(err, IsNil)
+	job := &Job{}
+	c.Assert(job.Decode(b), IsNil)
+	isDependent, err := job.IsDependentOn(addIdxJob)
+	c.Assert(err, IsNil)
+	c.Assert(isDependent, IsTrue)
+	c.Assert(job.Args, IsNil)
+}
@@ -152,6 +152,12 @@ const (
 	GlobalStatusTable = "GLOBAL_STATUS"
 	// TiDBTable is the table contains tidb info.
 	TiDBTable = "tidb"
+	// QueryRewriteRulesTable is the table contains the query rewrite rules.
+	QueryRewriteRulesTable = "query_rewrite_rules"
+	// BootstrapHistoryTable is the table contains the bootstrap versions the system tables are upgraded to.
+	BootstrapHistoryTable = "bootstrap_history"
+	// GCSnapshotPinsTable is the table contains the snapshots pinned by ADMIN PIN SNAPSHOT, GC keeps them.
+	GCSnapshotPinsTable = "gc_snapshot_pins"
 )
 
 // PrivilegeType  privilege
@@ -220,6 +226,9 @@ const (
 const (
 	MaxFieldCharLength    = 255
 	MaxFieldVarCharLength = 65535
+	// The max lengths in bytes of TEXT and MEDIUMTEXT.
+	MaxTextLength       = 65535
+	MaxMediumTextLength = 16777215
 )
 
 // MySQL precision.
@@ -444,7 +453,7 @@ var Str2SQLMode = map[string]SQLMode{
 	"NO_FIELD_OPTIONS":           ModeNoFieldOptions,
 	"MYSQL323":                   ModeMySQL323,
 	"MYSQL40":                    ModeMySQL40,
-	"ANSI":                       ModeANSI,
+	"ANSI":                       ModeANSI | ModeRealAsFloat | ModePipesAsConcat | ModeANSIQuotes | ModeIgnoreSpace | ModeOnlyFullGroupBy,
 	"NO_AUTO_VALUE_ON_ZERO":      ModeNoAutoValueOnZero,
 	"NO_BACKSLASH_ESCAPES":       ModeNoBackslashEscapes,
 	"STRICT_TRANS_TABLES":        ModeStrictTransTables,
@@ -463,19 +472,16 @@ var Str2SQLMode = map[string]SQLMode{
 // FormatFunc is the locale format function signature.
 type FormatFunc func(string, string) (string, error)
 
-// GetLocaleFormatFunction get the format function for sepcific locale.
+// GetLocaleFormatFunction gets the format function for the specific locale, the locale name is case-insensitive.
+// It returns nil if the locale is unknown.
 func GetLocaleFormatFunction(loc string) FormatFunc {
-	locale, exist := locale2FormatFunction[loc]
-	if !exist {
-		return formatNotSupport
+	locale := GetLocale(loc)
+	if locale == nil {
+		return nil
+	}
+	return func(number string, precision string) (string, error) {
+		return formatNumber(number, precision, locale.ThousandsSep, locale.DecimalPoint), nil
 	}
-	return locale
-}
-
-// locale2FormatFunction is the string represent of locale format function.
-var locale2FormatFunction = map[string]FormatFunc{
-	"en_US": formatENUS,
-	"zh_CN": formatZHCN,
 }
 
 // PriorityEnum is defined for Priority const values.
@@ -879,6 +879,7 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+	ErrUserLockWrongName:                                     "Incorrect user-level lock name '%-.192s'.",
 	ErrBadGeneratedColumn:                                    "The value specified for generated column '%s' in table '%s' is not allowed.",
 	ErrUnsupportedOnGeneratedColumn:                          "'%s' is not supported for generated columns.",
 	ErrGeneratedColumnNonPrior:                               "Generated column can refer only to generated columns defined prior to it.",
@@ -887,4 +888,5 @@ var MySQLErrName = map[uint16]string{
 	ErrInvalidJSONPath:                                       "Invalid JSON path expression %s.",
 	ErrInvalidJSONData:                                       "Invalid data type for JSON data",
 	ErrJSONUsedAsKey:                                         "JSON column '%-.192s' cannot be used in key specification.",
+	ErrLockNowait:                                            "Statement aborted because lock(s) could not be acquired immediately and NOWAIT is set.",
 }
@@ -0,0 +1,151 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import "strings"
+
+// Locale is a MySQL locale. The names of the months and the days are used by DATE_FORMAT, MONTHNAME and
+// DAYNAME according to lc_time_names, and the separators are used by FORMAT.
+// See https://dev.mysql.com/doc/refman/5.7/en/locale-support.html
+type Locale struct {
+	Name string
+	// MonthNames and AbbrevMonthNames start from January.
+	MonthNames       []string
+	AbbrevMonthNames []string
+	// DayNames and AbbrevDayNames start from Monday.
+	DayNames       []string
+	AbbrevDayNames []string
+	DecimalPoint   byte
+	// ThousandsSep is 0 if the integer digits are not grouped.
+	ThousandsSep byte
+}
+
+var (
+	enMonthNames       = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	enAbbrevMonthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	enDayNames         = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	enAbbrevDayNames   = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+	cjkAbbrevMonthNames = []string{" 1月", " 2月", " 3月", " 4月", " 5月", " 6月", " 7月", " 8月", " 9月", "10月", "11月", "12月"}
+	zhMonthNames        = []string{"一月", "二月", "三月", "四月", "五月", "六月", "七月", "八月", "九月", "十月", "十一月", "十二月"}
+	zhAbbrevDayNames    = []string{"一", "二", "三", "四", "五", "六", "日"}
+)
+
+// LocaleENUS is the default locale en_US.
+var LocaleENUS = &Locale{
+	Name:             "en_US",
+	MonthNames:       enMonthNames,
+	AbbrevMonthNames: enAbbrevMonthNames,
+	DayNames:         enDayNames,
+	AbbrevDayNames:   enAbbrevDayNames,
+	DecimalPoint:     '.',
+	ThousandsSep:     ',',
+}
+
+var locales = []*Locale{
+	LocaleENUS,
+	{
+		Name:             "en_GB",
+		MonthNames:       enMonthNames,
+		AbbrevMonthNames: enAbbrevMonthNames,
+		DayNames:         enDayNames,
+		AbbrevDayNames:   enAbbrevDayNames,
+		DecimalPoint:     '.',
+		ThousandsSep:     ',',
+	},
+	{
+		Name:             "de_DE",
+		MonthNames:       []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		AbbrevMonthNames: []string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		DayNames:         []string{"Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag", "Sonntag"},
+		AbbrevDayNames:   []string{"Mo", "Di", "Mi", "Do", "Fr", "Sa", "So"},
+		DecimalPoint:     ',',
+		ThousandsSep:     '.',
+	},
+	{
+		Name:             "es_ES",
+		MonthNames:       []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		AbbrevMonthNames: []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+		DayNames:         []string{"lunes", "martes", "miércoles", "jueves", "viernes", "sábado", "domingo"},
+		AbbrevDayNames:   []string{"lun", "mar", "mié", "jue", "vie", "sáb", "dom"},
+		DecimalPoint:     ',',
+		ThousandsSep:     '.',
+	},
+	{
+		Name:             "fr_FR",
+		MonthNames:       []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		AbbrevMonthNames: []string{"jan", "fév", "mar", "avr", "mai", "jun", "jui", "aoû", "sep", "oct", "nov", "déc"},
+		DayNames:         []string{"lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi", "dimanche"},
+		AbbrevDayNames:   []string{"lun", "mar", "mer", "jeu", "ven", "sam", "dim"},
+		DecimalPoint:     ',',
+	},
+	{
+		Name:             "pt_BR",
+		MonthNames:       []string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		AbbrevMonthNames: []string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		DayNames:         []string{"segunda", "terça", "quarta", "quinta", "sexta", "sábado", "domingo"},
+		AbbrevDayNames:   []string{"seg", "ter", "qua", "qui", "sex", "sáb", "dom"},
+		DecimalPoint:     ',',
+		ThousandsSep:     '.',
+	},
+	{
+		Name:             "ja_JP",
+		MonthNames:       []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		AbbrevMonthNames: cjkAbbrevMonthNames,
+		DayNames:         []string{"月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日", "日曜日"},
+		AbbrevDayNames:   []string{"月", "火", "水", "木", "金", "土", "日"},
+		DecimalPoint:     '.',
+		ThousandsSep:     ',',
+	},
+	{
+		Name:             "ko_KR",
+		MonthNames:       []string{"일월", "이월", "삼월", "사월", "오월", "유월", "칠월", "팔월", "구월", "시월", "십일월", "십이월"},
+		AbbrevMonthNames: []string{" 1월", " 2월", " 3월", " 4월", " 5월", " 6월", " 7월", " 8월", " 9월", "10월", "11월", "12월"},
+		DayNames:         []string{"월요일", "화요일", "수요일", "목요일", "금요일", "토요일", "일요일"},
+		AbbrevDayNames:   []string{"월", "화", "수", "목", "금", "토", "일"},
+		DecimalPoint:     '.',
+		ThousandsSep:     ',',
+	},
+	{
+		Name:             "zh_CN",
+		MonthNames:       zhMonthNames,
+		AbbrevMonthNames: cjkAbbrevMonthNames,
+		DayNames:         []string{"星期一", "星期二", "星期三", "星期四", "星期五", "星期六", "星期日"},
+		AbbrevDayNames:   zhAbbrevDayNames,
+		DecimalPoint:     '.',
+		ThousandsSep:     ',',
+	},
+	{
+		Name:             "zh_TW",
+		MonthNames:       zhMonthNames,
+		AbbrevMonthNames: cjkAbbrevMonthNames,
+		DayNames:         []string{"週一", "週二", "週三", "週四", "週五", "週六", "週日"},
+		AbbrevDayNames:   zhAbbrevDayNames,
+		DecimalPoint:     '.',
+		ThousandsSep:     ',',
+	},
+}
+
+var name2Locale = make(map[string]*Locale, len(locales))
+
+func init() {
+	for _, loc := range locales {
+		name2Locale[strings.ToLower(loc.Name)] = loc
+	}
+}
+
+// GetLocale gets the locale by its name, the name is case-insensitive. It returns nil if the locale is unknown.
+func GetLocale(name string) *Locale {
+	return name2Locale[strings.ToLower(name)]
+}
@@ -2,98 +2,113 @@ package mysql
 
 import (
 	"bytes"
+	"math"
 	"strconv"
 	"strings"
 	"unicode"
-
-	"github.com/juju/errors"
 )
 
-func formatENUS(number string, precision string) (string, error) {
-	var buffer bytes.Buffer
-	if unicode.IsDigit(rune(precision[0])) {
-		for i, v := range precision {
-			if unicode.IsDigit(v) {
-				continue
+// formatMaxDecimals is the max number of the decimals of FORMAT().
+const formatMaxDecimals = 30
+
+// formatNumber rounds the number to the precision decimals half away from zero, and groups the integer digits
+// by thousands if the thousands separator isn't 0. The invalid number is treated as 0 and the invalid precision
+// as 0, the precision is at most 30.
+func formatNumber(number string, precision string, thousandsSep, decimalPoint byte) string {
+	prec := 0
+	for i := 0; i < len(precision) && unicode.IsDigit(rune(precision[i])); i++ {
+		prec = prec*10 + int(precision[i]-'0')
+		if prec > formatMaxDecimals {
+			prec = formatMaxDecimals
+			break
+		}
+	}
+
+	neg, intPart, fracPart := splitNumber(number)
+	if len(fracPart) > prec {
+		roundUp := fracPart[prec] >= '5'
+		digits := []byte(intPart + fracPart[:prec])
+		if roundUp {
+			i := len(digits) - 1
+			for ; i >= 0 && digits[i] == '9'; i-- {
+				digits[i] = '0'
+			}
+			if i >= 0 {
+				digits[i]++
 			} else {
-				precision = precision[:i]
-				break
+				digits = append([]byte{'1'}, digits...)
 			}
 		}
+		intPart, fracPart = string(digits[:len(digits)-prec]), string(digits[len(digits)-prec:])
 	} else {
-		precision = "0"
+		fracPart += strings.Repeat("0", prec-len(fracPart))
 	}
-	if number[0] == '-' && number[1] == '.' {
-		number = strings.Replace(number, "-", "-0", 1)
-	} else if number[0] == '.' {
-		number = strings.Replace(number, ".", "0.", 1)
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
 	}
 
-	if (number[:1] == "-" && !unicode.IsDigit(rune(number[1]))) ||
-		(!unicode.IsDigit(rune(number[0])) && number[:1] != "-") {
-		buffer.Write([]byte{'0'})
-		position, err := strconv.ParseUint(precision, 10, 64)
-		if err == nil && position > 0 {
-			buffer.Write([]byte{'.'})
-			buffer.WriteString(strings.Repeat("0", int(position)))
-		}
-		return buffer.String(), nil
-	} else if number[:1] == "-" {
-		buffer.Write([]byte{'-'})
-		number = number[1:]
+	var buffer bytes.Buffer
+	if neg && strings.Trim(intPart+fracPart, "0") != "" {
+		buffer.WriteByte('-')
 	}
-
-	for i, v := range number {
-		if unicode.IsDigit(v) {
-			continue
-		} else if i == 1 && number[1] == '.' {
-			continue
-		} else if v == '.' && number[1] != '.' {
-			continue
-		} else {
-			number = number[:i]
-			break
+	for i := 0; i < len(intPart); i++ {
+		if thousandsSep != 0 && i > 0 && (len(intPart)-i)%3 == 0 {
+			buffer.WriteByte(thousandsSep)
 		}
+		buffer.WriteByte(intPart[i])
+	}
+	if prec > 0 {
+		buffer.WriteByte(decimalPoint)
+		buffer.WriteString(fracPart)
 	}
+	return buffer.String()
+}
 
-	comma := []byte{','}
-	parts := strings.Split(number, ".")
-	pos := 0
-	if len(parts[0])%3 != 0 {
-		pos += len(parts[0]) % 3
-		buffer.WriteString(parts[0][:pos])
-		buffer.Write(comma)
+// splitNumber splits the longest valid number prefix of the string into the sign, the integer digits and
+// the fraction digits, the number in scientific notation is expanded.
+func splitNumber(number string) (neg bool, intPart, fracPart string) {
+	number = strings.TrimSpace(number)
+	i := 0
+	if i < len(number) && (number[i] == '-' || number[i] == '+') {
+		neg = number[i] == '-'
+		i++
 	}
-	for ; pos < len(parts[0]); pos += 3 {
-		buffer.WriteString(parts[0][pos : pos+3])
-		buffer.Write(comma)
+	start := i
+	for i < len(number) && unicode.IsDigit(rune(number[i])) {
+		i++
 	}
-	buffer.Truncate(buffer.Len() - 1)
-
-	position, err := strconv.ParseUint(precision, 10, 64)
-	if err == nil {
-		if position > 0 {
-			buffer.Write([]byte{'.'})
-			if len(parts) == 2 {
-				if uint64(len(parts[1])) >= position {
-					buffer.WriteString(parts[1][:position])
-				} else {
-					buffer.WriteString(parts[1])
-					buffer.WriteString(strings.Repeat("0", int(position)-len(parts[1])))
+	intPart = number[start:i]
+	if i < len(number) && number[i] == '.' {
+		i++
+		start = i
+		for i < len(number) && unicode.IsDigit(rune(number[i])) {
+			i++
+		}
+		fracPart = number[start:i]
+	}
+	if intPart == "" && fracPart == "" {
+		return neg, "", ""
+	}
+	if i+1 < len(number) && (number[i] == 'e' || number[i] == 'E') {
+		j := i + 1
+		if number[j] == '-' || number[j] == '+' {
+			j++
+		}
+		if j < len(number) && unicode.IsDigit(rune(number[j])) {
+			for j < len(number) && unicode.IsDigit(rune(number[j])) {
+				j++
+			}
+			f, err := strconv.ParseFloat(number[:j], 64)
+			if err == nil {
+				parts := strings.SplitN(strconv.FormatFloat(math.Abs(f), 'f', -1, 64), ".", 2)
+				intPart = parts[0]
+				fracPart = ""
+				if len(parts) == 2 {
+					fracPart = parts[1]
 				}
-			} else {
-				buffer.WriteString(strings.Repeat("0", int(position)))
 			}
 		}
 	}
-
-	return buffer.String(), nil
-}
-
-func formatZHCN(number string, precision string) (string, error) {
-	return "", errors.New("not implemented")
-}
-
-func formatNotSupport(number string, precision string) (string, error) {
-	return "", errors.New("not support for the specific locale")
+	return neg, intPart, fracPart
 }
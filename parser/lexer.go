@@ -39,6 +39,8 @@ type Scanner struct {
 
 	errs         []error
 	stmtStartPos int
+	// lastPos is the position of the last scanned token, the errors are reported at it.
+	lastPos Pos
 
 	// For scanning such kind of comment: /*! MySQL-specific code */ or /*+ optimizer hint */
 	specialComment specialCommentScanner
@@ -94,6 +96,7 @@ func (s *Scanner) reset(sql string) {
 	s.buf.Reset()
 	s.errs = s.errs[:0]
 	s.stmtStartPos = 0
+	s.lastPos = Pos{}
 }
 
 func (s *Scanner) stmtText() string {
@@ -113,13 +116,22 @@ func (s *Scanner) stmtText() string {
 
 // Errorf tells scanner something is wrong.
 // Scanner satisfies yyLexer interface which need this function.
+// Like MySQL, the error has the text near the last scanned token and its line, the column is added to locate
+// the token in a long statement. An empty message means a syntax error.
 func (s *Scanner) Errorf(format string, a ...interface{}) {
-	str := fmt.Sprintf(format, a...)
-	val := s.r.s[s.r.pos().Offset:]
-	if len(val) > 2048 {
-		val = val[:2048]
-	}
-	err := fmt.Errorf("line %d column %d near \"%s\"%s (total length %d)", s.r.p.Line, s.r.p.Col, val, str, len(s.r.s))
+	msg := fmt.Sprintf(format, a...)
+	if msg == "" {
+		msg = mysql.MySQLErrName[mysql.ErrSyntax]
+	}
+	offset := s.lastPos.Offset
+	near := ""
+	if offset < len(s.r.s) {
+		near = s.r.s[offset:]
+	}
+	// The line of the reader starts from 0, the column is counted from the line start here,
+	// because the reader counts it from 1 only after the first line.
+	col := offset - strings.LastIndexByte(s.r.s[:offset], '\n')
+	err := ErrParse.GenByArgs(msg, near, s.lastPos.Line+1, col)
 	s.errs = append(s.errs, err)
 }
 
@@ -130,6 +142,7 @@ func (s *Scanner) Errorf(format string, a ...interface{}) {
 // return invalid tells parser that scanner meets illegal character.
 func (s *Scanner) Lex(v *yySymType) int {
 	tok, pos, lit := s.scan()
+	s.lastPos = pos
 	v.offset = pos.Offset
 	v.ident = lit
 	if tok == identifier {
@@ -145,6 +158,10 @@ func (s *Scanner) Lex(v *yySymType) int {
 		s.r.s[v.offset] == '"' {
 		tok = identifier
 	}
+	if (s.sqlMode&mysql.ModePipesAsConcat) > 0 && tok == oror {
+		// "||" is the string concatenation operator.
+		tok = pipes
+	}
 
 	switch tok {
 	case intLit:
@@ -353,8 +370,9 @@ func startWithSlash(s *Scanner) (tok int, pos Pos, lit string) {
 		}
 
 		// See http://dev.mysql.com/doc/refman/5.7/en/comments.html
-		// Convert "/*!VersionNumber MySQL-specific-code */" to "MySQL-specific-code".
-		if strings.HasPrefix(comment, "/*!") {
+		// Convert "/*!VersionNumber MySQL-specific-code */" to "MySQL-specific-code",
+		// and "/*T! TiDB-specific-code */" to "TiDB-specific-code", MySQL ignores the latter.
+		if strings.HasPrefix(comment, "/*!") || strings.HasPrefix(comment, "/*T!") {
 			sql := specCodePattern.ReplaceAllStringFunc(comment, TrimComment)
 			s.specialComment = &mysqlSpecificCodeScanner{
 				Scanner: NewScanner(sql),
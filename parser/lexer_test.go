@@ -250,6 +250,12 @@ func (s *testLexerSuite) TestSpecialComment(c *C) {
 	c.Assert(tok, Equals, intLit)
 	c.Assert(lit, Equals, "5")
 	c.Assert(pos, Equals, Pos{1, 1, 16})
+
+	l = NewScanner("/*T! placement */")
+	tok, pos, lit = l.scan()
+	c.Assert(tok, Equals, identifier)
+	c.Assert(lit, Equals, "placement")
+	c.Assert(pos, Equals, Pos{0, 0, 5})
 }
 
 func (s *testLexerSuite) TestOptimizerHint(c *C) {
@@ -338,6 +344,16 @@ func (s *testLexerSuite) TestSQLModeANSIQuotes(c *C) {
 	c.Assert(v.ident, Equals, "string")
 }
 
+func (s *testLexerSuite) TestSQLModePipesAsConcat(c *C) {
+	scanner := NewScanner("||")
+	var v yySymType
+	c.Assert(scanner.Lex(&v), Equals, oror)
+
+	scanner.SetSQLMode(mysql.ModePipesAsConcat)
+	scanner.reset("||")
+	c.Assert(scanner.Lex(&v), Equals, pipes)
+}
+
 func (s *testLexerSuite) TestIllegal(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCaseItem{
@@ -157,6 +157,7 @@ var tokenMap = map[string]int{
 	"ASCII":                      ascii,
 	"ATAN":                       atan,
 	"ATAN2":                      atan2,
+	"AUTO_ID_CACHE":              autoIdCache,
 	"AUTO_INCREMENT":             autoIncrement,
 	"AVG":                        avg,
 	"AVG_ROW_LENGTH":             avgRowLength,
@@ -168,6 +169,7 @@ var tokenMap = map[string]int{
 	"BTREE":                      btree,
 	"BY":                         by,
 	"BYTE":                       byteType,
+	"CANCEL":                     cancel,
 	"CASE":                       caseKwd,
 	"CAST":                       cast,
 	"CEIL":                       ceil,
@@ -192,8 +194,10 @@ var tokenMap = map[string]int{
 	"CONCAT_WS":                  concatWs,
 	"CONVERT_TZ":                 convertTz,
 	"CONNECTION":                 connection,
+	"CONFIG":                     config,
 	"CONNECTION_ID":              connectionID,
 	"CONSTRAINT":                 constraint,
+	"CONSTRAINTS":                constraints,
 	"CONSISTENT":                 consistent,
 	"CONVERT":                    convert,
 	"COS":                        cos,
@@ -208,6 +212,7 @@ var tokenMap = map[string]int{
 	"CURTIME":                    curTime,
 	"CURRENT_TIME":               currentTime,
 	"CURRENT_USER":               currentUser,
+	"CURRENT_ROLE":               currentRole,
 	"DATA":                       data,
 	"DATABASE":                   database,
 	"DATABASES":                  databases,
@@ -239,6 +244,7 @@ var tokenMap = map[string]int{
 	"DO":                         do,
 	"DROP":                       drop,
 	"DUAL":                       dual,
+	"DUMP":                       dump,
 	"DUPLICATE":                  duplicate,
 	"DYNAMIC":                    dynamic,
 	"FROM_DAYS":                  fromDays,
@@ -316,6 +322,7 @@ var tokenMap = map[string]int{
 	"KEY_BLOCK_SIZE":             keyBlockSize,
 	"KEYS":                       keys,
 	"LAST_INSERT_ID":             lastInsertID,
+	"LEADER_CONSTRAINTS":         leaderConstraints,
 	"LEADING":                    leading,
 	"LEAST":                      least,
 	"LEFT":                       left,
@@ -329,6 +336,8 @@ var tokenMap = map[string]int{
 	"LOAD":                       load,
 	"LOAD_FILE":                  loadFile,
 	"LOCAL":                      local,
+	"LOCATION":                   location,
+	"LOCKED":                     locked,
 	"LOCATE":                     locate,
 	"LOCK":                       lock,
 	"LOG":                        log,
@@ -358,6 +367,7 @@ var tokenMap = map[string]int{
 	"NAMES":                      names,
 	"NATIONAL":                   national,
 	"NONE":                       none,
+	"NOWAIT":                     nowait,
 	"NOT":                        not,
 	"NO_WRITE_TO_BINLOG":         noWriteToBinLog,
 	"NULL":                       null,
@@ -373,13 +383,18 @@ var tokenMap = map[string]int{
 	"ORDER":                      order,
 	"OUTER":                      outer,
 	"PASSWORD":                   password,
+	"PAUSE":                      pause,
 	"PERIOD_ADD":                 periodAdd,
 	"PERIOD_DIFF":                periodDiff,
 	"PI":                         pi,
+	"PIN":                        pin,
 	"POSITION":                   position,
 	"POW":                        pow,
 	"POWER":                      power,
+	"PLACEMENT":                  placement,
+	"PLAN":                       plan,
 	"PLUGINS":                    plugins,
+	"POLICY":                     policy,
 	"PREPARE":                    prepare,
 	"PRIMARY":                    primary,
 	"PRIVILEGES":                 privileges,
@@ -396,13 +411,23 @@ var tokenMap = map[string]int{
 	"READ":                       read,
 	"REDUNDANT":                  redundant,
 	"REFERENCES":                 references,
+	"REGIONS":                    regions,
+	"REPLAYER":                   replayer,
+	"REPLICAS":                   replicas,
 	"REGEXP":                     regexpKwd,
+	"REGEXP_INSTR":               regexpInstr,
+	"REGEXP_LIKE":                regexpLike,
+	"REGEXP_REPLACE":             regexpReplace,
+	"REGEXP_SUBSTR":              regexpSubstr,
 	"RELEASE_LOCK":               releaseLock,
+	"RELOAD":                     reload,
 	"RENAME":                     rename,
 	"REPEAT":                     repeat,
 	"REPEATABLE":                 repeatable,
 	"REPLACE":                    replace,
+	"RESUME":                     resume,
 	"REVOKE":                     revoke,
+	"REWRITE":                    rewrite,
 	"RIGHT":                      right,
 	"RLIKE":                      rlike,
 	"ROLLBACK":                   rollback,
@@ -410,7 +435,9 @@ var tokenMap = map[string]int{
 	"ROW":                        row,
 	"ROW_FORMAT":                 rowFormat,
 	"RTRIM":                      rtrim,
+	"RULES":                      rules,
 	"REVERSE":                    reverse,
+	"SCATTER":                    scatter,
 	"SCHEMA":                     schema,
 	"SCHEMAS":                    schemas,
 	"SEC_TO_TIME":                secToTime,
@@ -418,6 +445,7 @@ var tokenMap = map[string]int{
 	"SELECT":                     selectKwd,
 	"SERIALIZABLE":               serializable,
 	"SESSION":                    session,
+	"SESSION_STATES":             sessionStates,
 	"SET":                        set,
 	"SHARE":                      share,
 	"SHARED":                     shared,
@@ -425,19 +453,26 @@ var tokenMap = map[string]int{
 	"SLEEP":                      sleep,
 	"SIGN":                       sign,
 	"SIGNED":                     signed,
+	"SKIP":                       skip,
 	"SIN":                        sin,
 	"SNAPSHOT":                   snapshot,
 	"SOME":                       some,
 	"SPACE":                      space,
+	"SPLIT":                      split,
 	"SQRT":                       sqrt,
 	"START":                      start,
 	"STARTING":                   starting,
+	"STATISTICS":                 statistics,
+	"SAMPLES":                    samples,
+	"PROGRESS":                   progress,
 	"STATS":                      stats,
 	"STATS_BUCKETS":              statsBuckets,
 	"STATS_HISTOGRAMS":           statsHistograms,
 	"STATS_META":                 statsMeta,
+	"STATS_JSON":                 statsJSON,
 	"STATS_PERSISTENT":           statsPersistent,
 	"STATUS":                     status,
+	"STORAGE":                    storage,
 	"STORED":                     stored,
 	"SUBDATE":                    subDate,
 	"SUBTIME":                    subTime,
@@ -473,11 +508,13 @@ var tokenMap = map[string]int{
 	"TRIM":                       trim,
 	"TRUE":                       trueKwd,
 	"TRUNCATE":                   truncate,
+	"TTL":                        ttl,
 	"UNCOMMITTED":                uncommitted,
 	"UNKNOWN":                    unknown,
 	"UNION":                      union,
 	"UNIQUE":                     unique,
 	"UNLOCK":                     unlock,
+	"UNPIN":                      unpin,
 	"UNSIGNED":                   unsigned,
 	"UNIX_TIMESTAMP":             unixTimestamp,
 	"UPDATE":                     update,
@@ -501,6 +538,7 @@ var tokenMap = map[string]int{
 	"WHERE":                      where,
 	"WITH":                       with,
 	"WRITE":                      write,
+	"WRITES":                     writes,
 	"XOR":                        xor,
 	"YEARWEEK":                   yearweek,
 	"ZEROFILL":                   zerofill,
@@ -568,6 +606,7 @@ var tokenMap = map[string]int{
 	"DAY_HOUR":                   dayHour,
 	"YEAR_MONTH":                 yearMonth,
 	"RESTRICT":                   restrict,
+	"RETURNING":                  returning,
 	"CASCADE":                    cascade,
 	"NO":                         no,
 	"ACTION":                     action,
@@ -603,6 +642,7 @@ var tokenMap = map[string]int{
 	"UNCOMPRESSED_LENGTH":        uncompressedLength,
 	"VALIDATE_PASSWORD_STRENGTH": validatePasswordStrength,
 	"ANY_VALUE":                  anyValue,
+	"BIN_TO_UUID":                binToUUID,
 	"INET_ATON":                  inetAton,
 	"INET_NTOA":                  inetNtoa,
 	"INET6_ATON":                 inet6Aton,
@@ -613,11 +653,13 @@ var tokenMap = map[string]int{
 	"IS_IPV4_MAPPED":             isIPv4Mapped,
 	"IS_IPV6":                    isIPv6,
 	"IS_USED_LOCK":               isUsedLock,
+	"IS_UUID":                    isUUID,
 	"MASTER_POS_WAIT":            masterPosWait,
 	"NAME_CONST":                 nameConst,
 	"RELEASE_ALL_LOCKS":          releaseAllLocks,
 	"UUID":                       uuid,
 	"UUID_SHORT":                 uuidShort,
+	"UUID_TO_BIN":                uuidToBin,
 	"KILL":                       kill,
 	"NATURAL":                    natural,
 }
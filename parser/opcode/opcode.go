@@ -13,7 +13,11 @@
 
 package opcode
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb/util/format"
+)
 
 // Op is opcode type.
 type Op int
@@ -95,3 +99,39 @@ func (o Op) String() string {
 
 	return str
 }
+
+var opsLiteral = map[Op]string{
+	LogicAnd:   "AND",
+	LogicOr:    "OR",
+	LogicXor:   "XOR",
+	LeftShift:  "<<",
+	RightShift: ">>",
+	GE:         ">=",
+	LE:         "<=",
+	EQ:         "=",
+	NE:         "!=",
+	LT:         "<",
+	GT:         ">",
+	Plus:       "+",
+	Minus:      "-",
+	And:        "&",
+	Or:         "|",
+	Mod:        "%",
+	Xor:        "^",
+	Div:        "/",
+	Mul:        "*",
+	Not:        "!",
+	BitNeg:     "~",
+	IntDiv:     "DIV",
+	NullEQ:     "<=>",
+}
+
+// Restore writes the SQL literal of the operator.
+func (o Op) Restore(ctx *format.RestoreCtx) error {
+	str, ok := opsLiteral[o]
+	if !ok {
+		return fmt.Errorf("invalid opcode type %d during restoring", o)
+	}
+	ctx.WritePlain(str)
+	return nil
+}
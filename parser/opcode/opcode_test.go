@@ -13,7 +13,12 @@
 
 package opcode
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pingcap/tidb/util/format"
+)
 
 func TestT(t *testing.T) {
 	op := Plus
@@ -32,3 +37,16 @@ func TestT(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestRestore(t *testing.T) {
+	var sb bytes.Buffer
+	for op, literal := range map[Op]string{LogicAnd: "AND", GE: ">=", Not: "!", NullEQ: "<=>"} {
+		sb.Reset()
+		if err := op.Restore(format.NewRestoreCtx(&sb)); err != nil || sb.String() != literal {
+			t.Fatalf("invalid literal %s of op %s", sb.String(), op)
+		}
+	}
+	if err := In.Restore(format.NewRestoreCtx(&sb)); err == nil {
+		t.Fatalf("restoring op in should fail")
+	}
+}
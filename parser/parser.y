@@ -55,6 +55,7 @@ import (
 	hintEnd					"hintEnd is a virtual token for optimizer hint grammar"
 	andand					"&&"
 	oror					"||"
+	pipes					"a virtual token for the string concatenation operator || in PIPES_AS_CONCAT sql mode"
 
 	/* the following tokens belong to ReservedKeyword*/
 	add			"ADD"
@@ -191,6 +192,7 @@ import (
 	repeat			"REPEAT"
 	replace			"REPLACE"
 	restrict		"RESTRICT"
+	returning		"RETURNING"
 	revoke			"REVOKE"
 	right			"RIGHT"
 	rlike			"RLIKE"
@@ -254,9 +256,11 @@ import (
 	coercibility			"COERCIBILITY"
 	concat				"CONCAT"
 	concatWs			"CONCAT_WS"
+	config				"CONFIG"
 	connectionID			"CONNECTION_ID"
 	convertTz			"CONVERT_TZ"
 	curTime                         "CURTIME"
+	currentRole			"CURRENT_ROLE"
 	cos				"COS"
 	cot				"COT"
 	count				"COUNT"
@@ -294,6 +298,9 @@ import (
 	instr				"INSTR"
 	isNull				"ISNULL"
 	jobs				"JOBS"
+	cancel				"CANCEL"
+	pause				"PAUSE"
+	resume				"RESUME"
 	jsonExtract			"JSON_EXTRACT"
 	jsonUnquote			"JSON_UNQUOTE"
 	jsonTypeFunc			"JSON_TYPE"
@@ -371,12 +378,38 @@ import (
 	yearweek			"YEARWEEK"
 	round				"ROUND"
 	statsPersistent			"STATS_PERSISTENT"
+	regexpInstr			"REGEXP_INSTR"
+	regexpLike			"REGEXP_LIKE"
+	regexpReplace			"REGEXP_REPLACE"
+	regexpSubstr			"REGEXP_SUBSTR"
 	toBase64			"TO_BASE64"
 	toDays				"TO_DAYS"
 	toSeconds			"TO_SECONDS"
 	lastDay			    "LAST_DAY"
 	getLock				"GET_LOCK"
 	releaseLock			"RELEASE_LOCK"
+	reload				"RELOAD"
+	rewrite				"REWRITE"
+	rules				"RULES"
+	split				"SPLIT"
+	scatter				"SCATTER"
+	regions				"REGIONS"
+	writes				"WRITES"
+	statistics			"STATISTICS"
+	samples				"SAMPLES"
+	progress			"PROGRESS"
+	placement			"PLACEMENT"
+	policy				"POLICY"
+	replicas			"REPLICAS"
+	constraints			"CONSTRAINTS"
+	leaderConstraints		"LEADER_CONSTRAINTS"
+	sessionStates			"SESSION_STATES"
+	pin				"PIN"
+	unpin				"UNPIN"
+	ttl				"TTL"
+	plan				"PLAN"
+	replayer			"REPLAYER"
+	dump				"DUMP"
 	rpad				"RPAD"
 	bitCount			"BIT_COUNT"
 	bitLength			"BIT_LENGTH"
@@ -412,11 +445,14 @@ import (
 	isIPv4Mapped			"IS_IPV4_MAPPED"
 	isIPv6				"IS_IPV6"
 	isUsedLock			"IS_USED_LOCK"
+	isUUID				"IS_UUID"
 	masterPosWait			"MASTER_POS_WAIT"
 	nameConst			"NAME_CONST"
 	releaseAllLocks			"RELEASE_ALL_LOCKS"
 	uuid				"UUID"
 	uuidShort			"UUID_SHORT"
+	uuidToBin			"UUID_TO_BIN"
+	binToUUID			"BIN_TO_UUID"
 	underscoreCS			"UNDERSCORE_CHARSET"
 
 	/* the following tokens belong to UnReservedKeyword*/
@@ -426,6 +462,7 @@ import (
 	any 		"ANY"
 	ascii		"ASCII"
 	at		"AT"
+	autoIdCache	"AUTO_ID_CACHE"
 	autoIncrement	"AUTO_INCREMENT"
 	avgRowLength	"AVG_ROW_LENGTH"
 	avg		"AVG"
@@ -477,6 +514,8 @@ import (
 	jsonType	"JSON"
 	keyBlockSize	"KEY_BLOCK_SIZE"
 	local		"LOCAL"
+	location	"LOCATION"
+	locked		"LOCKED"
 	less		"LESS"
 	level		"LEVEL"
 	mode		"MODE"
@@ -487,6 +526,7 @@ import (
 	national	"NATIONAL"
 	no		"NO"
 	none		"NONE"
+	nowait		"NOWAIT"
 	offset		"OFFSET"
 	only		"ONLY"
 	password	"PASSWORD"
@@ -507,6 +547,7 @@ import (
 	share		"SHARE"
 	shared       	"SHARED"
 	signed		"SIGNED"
+	skip		"SKIP"
 	snapshot	"SNAPSHOT"
 	space 		"SPACE"
 	sqlCache	"SQL_CACHE"
@@ -516,7 +557,9 @@ import (
 	statsBuckets	"STATS_BUCKETS"
 	statsHistograms	"STATS_HISTOGRAMS"
 	statsMeta	"STATS_META"
+	statsJSON	"STATS_JSON"
 	status		"STATUS"
+	storage		"STORAGE"
 	super		"SUPER"
 	some 		"SOME"
 	global		"GLOBAL"
@@ -604,11 +647,14 @@ import (
 	ConstraintKeywordOpt		"Constraint Keyword or empty"
 	CreateDatabaseStmt		"Create Database Statement"
 	CreateIndexStmt			"CREATE INDEX statement"
+	CreateStatisticsStmt		"CREATE STATISTICS statement"
 	CreateIndexStmtUnique		"CREATE INDEX optional UNIQUE clause"
 	DatabaseOption			"CREATE Database specification"
 	DatabaseOptionList		"CREATE Database specification list"
 	DatabaseOptionListOpt		"CREATE Database specification list opt"
 	CreateTableStmt			"CREATE TABLE statement"
+	CreateTableSelectOpt		"CREATE TABLE ... SELECT statement opt"
+	AsOpt				"AS or empty"
 	CreateUserStmt			"CREATE User statement"
 	DBName				"Database Name"
 	DeallocateStmt			"Deallocate prepared statement"
@@ -622,6 +668,11 @@ import (
 	DropDatabaseStmt		"DROP DATABASE statement"
 	DropIndexStmt			"DROP INDEX statement"
 	DropStatsStmt			"DROP STATS statement"
+	DropStatisticsStmt		"DROP STATISTICS statement"
+	LoadStatsStmt			"LOAD STATS statement"
+	LockStatsStmt			"LOCK STATS statement"
+	PlanReplayerStmt		"PLAN REPLAYER statement"
+	UnlockStatsStmt			"UNLOCK STATS statement"
 	DropTableStmt			"DROP TABLE statement"
 	DropUserStmt			"DROP USER"
 	DropViewStmt			"DROP VIEW statement"
@@ -711,6 +762,9 @@ import (
 	PartitionDefinitionList 	"Partition definition list"
 	PartitionDefinitionListOpt	"Partition definition list option"
 	PartitionOpt			"Partition option"
+	PlacementOption			"Placement policy option"
+	PlacementPolicyOpt		"Placement policy of CREATE TABLE"
+	PlacementOptionList		"Placement policy option list"
 	PartitionNumOpt			"PARTITION NUM option"
 	PartDefValuesOpt		"VALUES {LESS THAN {(expr | value_list) | MAXVALUE} | IN {value_list}"
 	PartDefStorageOpt		"ENGINE = xxx or empty"
@@ -732,6 +786,7 @@ import (
 	RenameTableStmt         	"rename table statement"
 	ReplaceIntoStmt			"REPLACE INTO statement"
 	ReplacePriority			"replace statement priority"
+	ReturningOptional		"RETURNING clause of INSERT, REPLACE, UPDATE or DELETE"
 	RevokeStmt			"Revoke statement"
 	RollbackStmt			"ROLLBACK statement"
 	RowFormat			"Row format option"
@@ -837,6 +892,8 @@ import (
 	OptCollate		"Optional Collate setting"
 	NUM			"numbers"
 	LengthNum		"Field length num(uint64)"
+	NumList			"Num list"
+	SignedNum		"Signed num"
 	HintTableList		"Table list in optimizer hint"
 	TableOptimizerHintOpt	"Table level optimizer hint"
 	TableOptimizerHints	"Table level optimizer hints"
@@ -927,6 +984,7 @@ import (
 %left 	'-' '+'
 %left 	'*' '/' '%' div mod
 %left 	'^'
+%left 	pipes
 %left 	'~' neg
 %right 	not
 %right	collate
@@ -976,12 +1034,13 @@ AlterTableSpec:
 			Options:$1.([]*ast.TableOption),
 		}
 	}
-|	"ADD" ColumnKeywordOpt ColumnDef ColumnPosition
+|	"ADD" ColumnKeywordOpt IfNotExists ColumnDef ColumnPosition
 	{
 		$$ = &ast.AlterTableSpec{
 			Tp: 		ast.AlterTableAddColumn,
-			NewColumn:	$3.(*ast.ColumnDef),
-			Position:	$4.(*ast.ColumnPosition),
+			IfNotExists:	$3.(bool),
+			NewColumn:	$4.(*ast.ColumnDef),
+			Position:	$5.(*ast.ColumnPosition),
 		}
 	}
 |	"ADD" Constraint
@@ -992,22 +1051,24 @@ AlterTableSpec:
 			Constraint: constraint,
 		}
 	}
-|	"DROP" ColumnKeywordOpt ColumnName
+|	"DROP" ColumnKeywordOpt IfExists ColumnName
 	{
 		$$ = &ast.AlterTableSpec{
 			Tp: ast.AlterTableDropColumn,
-			OldColumnName: $3.(*ast.ColumnName),
+			IfExists: $3.(bool),
+			OldColumnName: $4.(*ast.ColumnName),
 		}
 	}
 |	"DROP" "PRIMARY" "KEY"
 	{
 		$$ = &ast.AlterTableSpec{Tp: ast.AlterTableDropPrimaryKey}
 	}
-|	"DROP" KeyOrIndex IndexName
+|	"DROP" KeyOrIndex IfExists IndexName
 	{
 		$$ = &ast.AlterTableSpec{
 			Tp: ast.AlterTableDropIndex,
-			Name: $3.(string),
+			IfExists: $3.(bool),
+			Name: $4.(string),
 		}
 	}
 |	"DROP" "FOREIGN" "KEY" Symbol
@@ -1053,6 +1114,21 @@ AlterTableSpec:
 			},
 		}
 	}
+|	"ALTER" ColumnKeywordOpt ColumnName "SET" "DEFAULT" '(' Expression ')'
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $7.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		option := &ast.ColumnOption{Expr: expr, DefaultIsExpr: true}
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableAlterColumn,
+			NewColumn:	&ast.ColumnDef{
+						Name: 	 $3.(*ast.ColumnName),
+						Options: []*ast.ColumnOption{option},
+			},
+		}
+	}
 |	"ALTER" ColumnKeywordOpt ColumnName "DROP" "DEFAULT"
 	{
 		$$ = &ast.AlterTableSpec{
@@ -1090,6 +1166,50 @@ AlterTableSpec:
 			LockType:   $1.(ast.LockType),
 		}
 	}
+|	"PLACEMENT" "POLICY" EqOpt "DEFAULT"
+	{
+		$$ = &ast.AlterTableSpec{Tp: ast.AlterTablePlacementPolicy}
+	}
+|	"PLACEMENT" "POLICY" EqOpt PlacementOptionList
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:			ast.AlterTablePlacementPolicy,
+			PlacementOptions:	$4.([]*ast.PlacementOption),
+		}
+	}
+
+PlacementPolicyOpt:
+	{
+		$$ = nil
+	}
+|	"PLACEMENT" "POLICY" EqOpt PlacementOptionList
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionPlacementPolicy, PlacementOptions: $4.([]*ast.PlacementOption)}
+	}
+
+PlacementOptionList:
+	PlacementOption
+	{
+		$$ = []*ast.PlacementOption{$1.(*ast.PlacementOption)}
+	}
+|	PlacementOptionList PlacementOption
+	{
+		$$ = append($1.([]*ast.PlacementOption), $2.(*ast.PlacementOption))
+	}
+
+PlacementOption:
+	"REPLICAS" EqOpt LengthNum
+	{
+		$$ = &ast.PlacementOption{Tp: ast.PlacementOptionReplicas, UintValue: $3.(uint64)}
+	}
+|	"CONSTRAINTS" EqOpt stringLit
+	{
+		$$ = &ast.PlacementOption{Tp: ast.PlacementOptionConstraints, StrValue: $3}
+	}
+|	"LEADER_CONSTRAINTS" EqOpt stringLit
+	{
+		$$ = &ast.PlacementOption{Tp: ast.PlacementOptionLeaderConstraints, StrValue: $3}
+	}
 
 LockClause:
 	"LOCK" eq "NONE"
@@ -1208,6 +1328,10 @@ AnalyzeTableStmt:
 	 {
 		$$ = &ast.AnalyzeTableStmt{TableNames: $3.([]*ast.TableName)}
 	 }
+|	"ANALYZE" "TABLE" TableNameList "WITH" NUM "SAMPLES"
+	{
+		$$ = &ast.AnalyzeTableStmt{TableNames: $3.([]*ast.TableName), NumSamples: getUint64FromNUM($5)}
+	}
 |   "ANALYZE" "TABLE" TableName "INDEX" IndexNameList
     {
         $$ = &ast.AnalyzeTableStmt{TableNames: []*ast.TableName{$3.(*ast.TableName)}, IndexNames: $5.([]model.CIStr)}
@@ -1349,6 +1473,19 @@ ColumnOption:
 	{
 		$$ = &ast.ColumnOption{Tp: ast.ColumnOptionDefaultValue, Expr: $2.(ast.ExprNode)}
 	}
+|	"DEFAULT" '(' Expression ')'
+	{
+		startOffset := parser.startOffset(&yyS[yypt-1])
+		endOffset := parser.endOffset(&yyS[yypt])
+		expr := $3.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+
+		$$ = &ast.ColumnOption{
+			Tp: ast.ColumnOptionDefaultValue,
+			Expr: expr,
+			DefaultIsExpr: true,
+		}
+	}
 |	"ON" "UPDATE" NowSymOptionFraction
 	{
 		nowFunc := &ast.FuncCallExpr{FnName: model.NewCIStr("CURRENT_TIMESTAMP")}
@@ -1602,31 +1739,43 @@ NumLiteral:
 
 
 CreateIndexStmt:
-	"CREATE" CreateIndexStmtUnique "INDEX" Identifier IndexTypeOpt "ON" TableName '(' IndexColNameList ')' IndexOptionList
+	"CREATE" CreateIndexStmtUnique "INDEX" IfNotExists Identifier IndexTypeOpt "ON" TableName '(' IndexColNameList ')' IndexOptionList
 	{
 		var indexOption *ast.IndexOption
-		if $11 != nil {
-			indexOption = $11.(*ast.IndexOption)
+		if $12 != nil {
+			indexOption = $12.(*ast.IndexOption)
 			if indexOption.Tp == model.IndexTypeInvalid {
-				if $5 != nil {
-					indexOption.Tp = $5.(model.IndexType)
+				if $6 != nil {
+					indexOption.Tp = $6.(model.IndexType)
 				}
 			}
 		} else {
 			indexOption = &ast.IndexOption{}
-			if $5 != nil {
-				indexOption.Tp = $5.(model.IndexType)
+			if $6 != nil {
+				indexOption.Tp = $6.(model.IndexType)
 			}
 		}
 		$$ = &ast.CreateIndexStmt{
+			IfNotExists:   $4.(bool),
 			Unique:        $2.(bool),
-			IndexName:     $4,
-			Table:         $7.(*ast.TableName),
-			IndexColNames: $9.([]*ast.IndexColName),
+			IndexName:     $5,
+			Table:         $8.(*ast.TableName),
+			IndexColNames: $10.([]*ast.IndexColName),
 			IndexOption:   indexOption,
 		}
 	}
 
+CreateStatisticsStmt:
+	"CREATE" "STATISTICS" IfNotExists Identifier "ON" TableName '(' ColumnNameList ')'
+	{
+		$$ = &ast.CreateStatisticsStmt{
+			IfNotExists: $3.(bool),
+			StatsName:   $4,
+			Table:       $6.(*ast.TableName),
+			Columns:     $8.([]*ast.ColumnName),
+		}
+	}
+
 CreateIndexStmtUnique:
 	{
 		$$ = false
@@ -1639,8 +1788,7 @@ CreateIndexStmtUnique:
 IndexColName:
 	ColumnName OptFieldLen Order
 	{
-		//Order is parsed but just ignored as MySQL did
-		$$ = &ast.IndexColName{Column: $1.(*ast.ColumnName), Length: $2.(int)}
+		$$ = &ast.IndexColName{Column: $1.(*ast.ColumnName), Length: $2.(int), Desc: $3.(bool)}
 	}
 
 IndexColNameList:
@@ -1723,7 +1871,7 @@ DatabaseOptionList:
  *      )
  *******************************************************************/
 CreateTableStmt:
-	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' TableOptionListOpt PartitionOpt
+	"CREATE" "TABLE" IfNotExists TableName '(' TableElementList ')' TableOptionListOpt PlacementPolicyOpt PartitionOpt CreateTableSelectOpt
 	{
 		tes := $6.([]interface {})
 		var columnDefs []*ast.ColumnDef
@@ -1740,13 +1888,29 @@ CreateTableStmt:
 			yylex.Errorf("Column Definition List can't be empty.")
 			return 1
 		}
-		$$ = &ast.CreateTableStmt{
+		stmt := &ast.CreateTableStmt{
 			Table:          $4.(*ast.TableName),
 			IfNotExists:    $3.(bool),
 			Cols:           columnDefs,
 			Constraints:    constraints,
 			Options:        $8.([]*ast.TableOption),
 		}
+		if $9 != nil {
+			stmt.Options = append(stmt.Options, $9.(*ast.TableOption))
+		}
+		if $11 != nil {
+			stmt.Select = $11.(ast.ResultSetNode)
+		}
+		$$ = stmt
+	}
+|	"CREATE" "TABLE" IfNotExists TableName TableOptionListOpt AsOpt SelectStmt
+	{
+		$$ = &ast.CreateTableStmt{
+			Table:          $4.(*ast.TableName),
+			IfNotExists:    $3.(bool),
+			Options:        $5.([]*ast.TableOption),
+			Select:         $7.(*ast.SelectStmt),
+		}
 	}
 |	"CREATE" "TABLE" IfNotExists TableName "LIKE" TableName
 	{
@@ -1757,6 +1921,20 @@ CreateTableStmt:
 		}
 	}
 
+CreateTableSelectOpt:
+	{
+		$$ = nil
+	}
+|	AsOpt SelectStmt
+	{
+		$$ = $2.(*ast.SelectStmt)
+	}
+
+AsOpt:
+	{}
+|	"AS"
+	{}
+
 DefaultKwdOpt:
 	{}
 |	"DEFAULT"
@@ -1818,7 +1996,7 @@ DoStmt:
  *
  *******************************************************************/
 DeleteFromStmt:
-	"DELETE" LowPriorityOptional QuickOptional IgnoreOptional "FROM" TableName WhereClauseOptional OrderByOptional LimitClause
+	"DELETE" LowPriorityOptional QuickOptional IgnoreOptional "FROM" TableName WhereClauseOptional OrderByOptional LimitClause ReturningOptional
 	{
 		// Single Table
 		join := &ast.Join{Left: &ast.TableSource{Source: $6.(ast.ResultSetNode)}, Right: nil}
@@ -1837,6 +2015,9 @@ DeleteFromStmt:
 		if $9 != nil {
 			x.Limit = $9.(*ast.Limit)
 		}
+		if $10 != nil {
+			x.Returning = $10.(*ast.FieldList)
+		}
 
 		$$ = x
 	}
@@ -1902,7 +2083,7 @@ DropTableStmt:
 DropViewStmt:
 	"DROP" "VIEW" "IF" "EXISTS" TableNameList
 	{
-		$$ = &ast.DoStmt{}
+		$$ = &ast.DropViewStmt{IfExists: true, Views: $5.([]*ast.TableName)}
 	}
 
 DropUserStmt:
@@ -1921,6 +2102,30 @@ DropStatsStmt:
 		$$ = &ast.DropStatsStmt{Table: $3.(*ast.TableName)}
 	}
 
+LoadStatsStmt:
+	"LOAD" "STATS" stringLit
+	{
+		$$ = &ast.LoadStatsStmt{JSON: $3}
+	}
+
+LockStatsStmt:
+	"LOCK" "STATS" TableNameList
+	{
+		$$ = &ast.LockStatsStmt{Tables: $3.([]*ast.TableName)}
+	}
+
+UnlockStatsStmt:
+	"UNLOCK" "STATS" TableNameList
+	{
+		$$ = &ast.UnlockStatsStmt{Tables: $3.([]*ast.TableName)}
+	}
+
+DropStatisticsStmt:
+	"DROP" "STATISTICS" IfExists Identifier "ON" TableName
+	{
+		$$ = &ast.DropStatisticsStmt{IfExists: $3.(bool), StatsName: $4, Table: $6.(*ast.TableName)}
+	}
+
 TableOrTables:
 	"TABLE"
 |	"TABLES"
@@ -1938,6 +2143,16 @@ EmptyStmt:
 ExplainSym:
 "EXPLAIN" | "DESCRIBE" | "DESC"
 
+PlanReplayerStmt:
+	"PLAN" "REPLAYER" "DUMP" ExplainableStmt
+	{
+		$$ = &ast.PlanReplayerStmt{Stmt: $4.(ast.StmtNode)}
+	}
+|	"PLAN" "REPLAYER" "LOAD" stringLit
+	{
+		$$ = &ast.PlanReplayerStmt{Load: true, File: $4}
+	}
+
 ExplainStmt:
 	ExplainSym TableName
 	{
@@ -1972,6 +2187,26 @@ LengthNum:
 NUM:
 	intLit
 
+NumList:
+	NUM
+	{
+		$$ = []int64{int64(getUint64FromNUM($1))}
+	}
+|	NumList ',' NUM
+	{
+		$$ = append($1.([]int64), int64(getUint64FromNUM($3)))
+	}
+
+SignedNum:
+	NUM
+	{
+		$$ = int64(getUint64FromNUM($1))
+	}
+|	'-' NUM
+	{
+		$$ = -int64(getUint64FromNUM($2))
+	}
+
 Expression:
 	singleAtIdentifier assignmentEq Expression %prec assignmentEq
 	{
@@ -2433,17 +2668,18 @@ IdentifierOrReservedKeyword:
 Identifier | ReservedKeyword
 
 UnReservedKeyword:
- "ACTION" | "ASCII" | "AUTO_INCREMENT" | "AFTER" | "ALWAYS" | "AT" | "AVG" | "BEGIN" | "BIT" | "BOOL" | "BOOLEAN" | "BTREE" | "CHARSET"
+ "ACTION" | "ASCII" | "AUTO_ID_CACHE" | "AUTO_INCREMENT" | "AFTER" | "ALWAYS" | "AT" | "AVG" | "BEGIN" | "BIT" | "BOOL" | "BOOLEAN" | "BTREE" | "CHARSET"
 | "COLUMNS" | "COMMIT" | "COMPACT" | "COMPRESSED" | "CONSISTENT" | "DATA" | "DATE" %prec lowerThanStringLitToken| "DATETIME" | "DEALLOCATE" | "DO"
 | "DYNAMIC"| "END" | "ENGINE" | "ENGINES" | "ESCAPE" | "EXECUTE" | "FIELDS" | "FIRST" | "FIXED" | "FORMAT" | "FULL" |"GLOBAL"
-| "HASH" | "LESS" | "LOCAL" | "NAMES" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT"
-| "ROLLBACK" | "SESSION" | "SIGNED" | "SNAPSHOT" | "START" | "STATUS" | "TABLES" | "TEXT" | "THAN" | "TIDB" | "TIME" | "TIMESTAMP"
+| "HASH" | "LESS" | "LOCAL" | "LOCATION" | "LOCKED" | "NAMES" | "NOWAIT" | "OFFSET" | "PASSWORD" %prec lowerThanEq | "PREPARE" | "QUICK" | "REDUNDANT"
+| "ROLLBACK" | "SESSION" | "SIGNED" | "SKIP" | "SNAPSHOT" | "START" | "STATUS" | "TABLES" | "TEXT" | "THAN" | "TIDB" | "TIME" | "TIMESTAMP"
 | "TRANSACTION" | "TRUNCATE" | "UNKNOWN" | "VALUE" | "WARNINGS" | "YEAR" | "MODE"  | "WEEK"  | "ANY" | "SOME" | "USER" | "IDENTIFIED"
 | "COLLATION" | "COMMENT" | "AVG_ROW_LENGTH" | "CONNECTION" | "CHECKSUM" | "COMPRESSION" | "KEY_BLOCK_SIZE" | "MAX_ROWS"
 | "MIN_ROWS" | "NATIONAL" | "ROW" | "ROW_FORMAT" | "QUARTER" | "GRANTS" | "TRIGGERS" | "DELAY_KEY_WRITE" | "ISOLATION" | "JSON"
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
-| "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS"
+| "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS" | "STATS_JSON"
+| "PLUGINS" | "STORAGE"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -2459,7 +2695,7 @@ ReservedKeyword:
 | "LOCALTIME" | "LOCALTIMESTAMP" | "LOCK" | "LONGBLOB" | "LONGTEXT" | "MAXVALUE" | "MEDIUMBLOB" | "MEDIUMINT" | "MEDIUMTEXT"
 | "MINUTE_MICROSECOND" | "MINUTE_SECOND" | "MOD" | "NOT" | "NO_WRITE_TO_BINLOG" | "NULL" | "NUMERIC"
 | "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ"
-| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
+| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "RETURNING" | "REVOKE" | "RIGHT" | "RLIKE"
 | "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT"
 | "STARTING" | "TABLE" | "STORED" | "TERMINATED" | "THEN" | "TINYBLOB" | "TINYINT" | "TINYTEXT" | "TO"
 | "TRAILING" | "TRIGGER" | "TRUE" | "UNION" | "UNIQUE" | "UNLOCK" | "UNSIGNED"
@@ -2472,17 +2708,19 @@ ReservedKeyword:
 
 NotKeywordToken:
 	"ABS" | "ACOS" | "ADDTIME" | "ADDDATE" | "ADMIN" | "ASIN" | "ATAN" | "ATAN2" | "BENCHMARK" | "BIN" | "BIT_COUNT"
-	| "BIT_LENGTH" | "COALESCE" | "COERCIBILITY" | "CONCAT" | "CONCAT_WS" | "CONNECTION_ID" | "CONVERT_TZ" | "CURTIME" | "COS" | "COT" | "COUNT" | "DAY"
+	| "BIT_LENGTH" | "COALESCE" | "COERCIBILITY" | "CONCAT" | "CONCAT_WS" | "CONNECTION_ID" | "CONVERT_TZ" | "CURTIME" | "CURRENT_ROLE" | "COS" | "COT" | "COUNT" | "DAY"
 |	"DATEDIFF" | "DATE_ADD" | "DATE_FORMAT" | "DATE_SUB" | "DAYNAME" | "DAYOFMONTH" | "DAYOFWEEK" | "DAYOFYEAR" | "DEGREES" | "ELT" | "EXP" | "EXPORT_SET" | "FROM_DAYS" | "FROM_BASE64" | "FIND_IN_SET" | "FOUND_ROWS"
 |	"GET_FORMAT" | "GROUP_CONCAT" | "GREATEST" | "LEAST" | "HOUR" | "HEX" | "UNHEX" | "IFNULL" | "INSTR" | "ISNULL" | "LAST_INSERT_ID" | "LCASE" | "LENGTH" | "LOAD_FILE" | "LOCATE" | "LOWER" | "LPAD" | "LTRIM"
 |	"MAKE_SET" | "MAX" | "MAKEDATE" | "MAKETIME" | "MICROSECOND" | "MID" | "MIN" |	"MINUTE" | "NULLIF" | "MONTH" | "MONTHNAME" | "NOW" |  "OCT" | "OCTET_LENGTH" | "ORD" | "POSITION" | "PERIOD_ADD" | "PERIOD_DIFF" | "PI" | "POW" | "POWER" | "RAND" | "RADIANS" | "ROW_COUNT"
 	"QUOTE" | "SEC_TO_TIME" | "SECOND" | "SIGN" | "SIN" | "SLEEP" | "SQRT" | "SQL_CALC_FOUND_ROWS" | "STR_TO_DATE" | "SUBTIME" | "SUBDATE" | "SUBSTRING" %prec lowerThanLeftParen |
 	"SESSION_USER" | "SUBSTRING_INDEX" | "SUM" | "SYSTEM_USER" | "TAN" | "TIME_FORMAT" | "TIME_TO_SEC" | "TIMESTAMPADD" | "TO_BASE64" | "TO_DAYS" | "TO_SECONDS" | "TRIM" | "RTRIM" | "UCASE" | "UPPER" | "VERSION" | "WEEKDAY" | "WEEKOFYEAR" | "YEARWEEK" | "ROUND"
 |	"STATS_PERSISTENT" | "GET_LOCK" | "RELEASE_LOCK" | "CEIL" | "CEILING" | "FLOOR" | "FROM_UNIXTIME" | "TIMEDIFF" | "LN" | "LOG" | "LOG2" | "LOG10" | "FIELD_KWD"
-|	"AES_DECRYPT" | "AES_ENCRYPT" | "QUOTE" | "LAST_DAY"
+|	"AES_DECRYPT" | "AES_ENCRYPT" | "QUOTE" | "LAST_DAY" | "REGEXP_INSTR" | "REGEXP_LIKE" | "REGEXP_REPLACE" | "REGEXP_SUBSTR"
 |	"ANY_VALUE" | "INET_ATON" | "INET_NTOA" | "INET6_ATON" | "INET6_NTOA" | "IS_FREE_LOCK" | "IS_IPV4" | "IS_IPV4_COMPAT" | "IS_IPV4_MAPPED" | "IS_IPV6" | "IS_USED_LOCK" | "MASTER_POS_WAIT" | "NAME_CONST" | "RELEASE_ALL_LOCKS" | "UUID" | "UUID_SHORT"
+|	"UUID_TO_BIN" | "BIN_TO_UUID" | "IS_UUID"
 |	"COMPRESS" | "DECODE" | "DES_DECRYPT" | "DES_ENCRYPT" | "ENCODE" | "ENCRYPT" | "MD5" | "OLD_PASSWORD" | "RANDOM_BYTES" | "SHA1" | "SHA" | "SHA2" | "UNCOMPRESS" | "UNCOMPRESSED_LENGTH" | "VALIDATE_PASSWORD_STRENGTH"
-|	"JSON_EXTRACT" | "JSON_UNQUOTE" | "JSON_TYPE" | "JSON_MERGE" | "JSON_SET" | "JSON_INSERT" | "JSON_REPLACE" | "JSON_REMOVE" | "JSON_OBJECT" | "JSON_ARRAY" | "TIDB_VERSION" | "JOBS"
+|	"JSON_EXTRACT" | "JSON_UNQUOTE" | "JSON_TYPE" | "JSON_MERGE" | "JSON_SET" | "JSON_INSERT" | "JSON_REPLACE" | "JSON_REMOVE" | "JSON_OBJECT" | "JSON_ARRAY" | "TIDB_VERSION" | "JOBS" | "RELOAD" | "CONFIG" | "CANCEL" | "PAUSE" | "RESUME" | "REWRITE" | "RULES" | "SPLIT" | "SCATTER" | "REGIONS" | "WRITES" | "STATISTICS" | "SAMPLES" | "PROGRESS"
+|	"PLACEMENT" | "POLICY" | "REPLICAS" | "CONSTRAINTS" | "LEADER_CONSTRAINTS" | "SESSION_STATES" | "PIN" | "UNPIN" | "TTL" | "PLAN" | "REPLAYER" | "DUMP"
 
 /************************************************************************************
  *
@@ -2491,7 +2729,7 @@ NotKeywordToken:
  *  TODO: support PARTITION
  **********************************************************************************/
 InsertIntoStmt:
-	"INSERT" Priority IgnoreOptional IntoOpt TableName InsertValues OnDuplicateKeyUpdate
+	"INSERT" Priority IgnoreOptional IntoOpt TableName InsertValues OnDuplicateKeyUpdate ReturningOptional
 	{
 		x := $6.(*ast.InsertStmt)
 		x.Priority = $2.(mysql.PriorityEnum)
@@ -2502,6 +2740,9 @@ InsertIntoStmt:
 		if $7 != nil {
 			x.OnDuplicate = $7.([]*ast.Assignment)
 		}
+		if $8 != nil {
+			x.Returning = $8.(*ast.FieldList)
+		}
 		$$ = x
 	}
 
@@ -2597,6 +2838,29 @@ OnDuplicateKeyUpdate:
 		$$ = $5
 	}
 
+/*
+ * RETURNING select_expr [, select_expr] ...
+ * It returns the rows written by INSERT, REPLACE, UPDATE or DELETE as a result set.
+ */
+ReturningOptional:
+	{
+		$$ = nil
+	}
+|	"RETURNING" FieldList
+	{
+		fields := $2.([]*ast.SelectField)
+		lastField := fields[len(fields)-1]
+		if lastField.Expr != nil && lastField.AsName.O == "" {
+			src := parser.src
+			lastEnd := len(src)
+			if src[lastEnd-1] == ';' {
+				lastEnd--
+			}
+			lastField.SetText(src[lastField.Offset:lastEnd])
+		}
+		$$ = &ast.FieldList{Fields: fields}
+	}
+
 /***********************************Insert Statements END************************************/
 
 /************************************************************************************
@@ -2606,13 +2870,16 @@ OnDuplicateKeyUpdate:
  *  TODO: support PARTITION
  **********************************************************************************/
 ReplaceIntoStmt:
-	"REPLACE" ReplacePriority IntoOpt TableName InsertValues
+	"REPLACE" ReplacePriority IntoOpt TableName InsertValues ReturningOptional
 	{
 		x := $5.(*ast.InsertStmt)
 		x.IsReplace = true
 		x.Priority = $2.(mysql.PriorityEnum)
 		ts := &ast.TableSource{Source: $4.(*ast.TableName)}
 		x.Table = &ast.TableRefsClause{TableRefs: &ast.Join{Left: ts}}
+		if $6 != nil {
+			x.Returning = $6.(*ast.FieldList)
+		}
 		$$ = x
 	}
 
@@ -2659,7 +2926,7 @@ Literal:
 			yylex.Errorf("Get collation error for charset: %s", $1)
 			return 1
 		}
-		expr := ast.NewValueExpr($2)
+		expr := ast.NewValueExpr(decodeLiteral($1, $2))
 		tp := expr.GetType()
 		tp.Charset = $1
 		tp.Collate = co
@@ -2674,14 +2941,14 @@ Literal:
 StringLiteral:
 	stringLit
 	{
-		expr := ast.NewValueExpr($1)
+		expr := parser.newStringLiteral(decodeLiteral(parser.charset, $1))
 		$$ = expr
 	}
 |	StringLiteral stringLit
 	{
 		valExpr := $1.(*ast.ValueExpr)
 		strLit := valExpr.GetString()
-		expr := ast.NewValueExpr(strLit+$2)
+		expr := parser.newStringLiteral(strLit+decodeLiteral(parser.charset, $2))
 		// Fix #4239, use first string literal as projection name.
 		if valExpr.GetProjectionOffset() >= 0 {
 			expr.SetProjectionOffset(valExpr.GetProjectionOffset())
@@ -3571,6 +3838,22 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"REGEXP_INSTR" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"REGEXP_LIKE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"REGEXP_REPLACE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"REGEXP_SUBSTR" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"TO_BASE64" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3670,6 +3953,10 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"CURRENT_ROLE" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"ROUND" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3840,6 +4127,18 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
+|	"UUID_TO_BIN" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"BIN_TO_UUID" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
+|	"IS_UUID" '(' ExpressionListOpt ')'
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
+	}
 |	"UNCOMPRESS" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
@@ -3884,11 +4183,11 @@ FunctionCallNonKeyword:
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
-|	"JSON_OBJECT" '(' ExpressionList ')'
+|	"JSON_OBJECT" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
-|	"JSON_ARRAY" '(' ExpressionList ')'
+|	"JSON_ARRAY" '(' ExpressionListOpt ')'
 	{
 		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr($1), Args: $3.([]ast.ExprNode)}
 	}
@@ -4299,6 +4598,10 @@ PrimaryFactor:
 	{
 		$$ = &ast.BinaryOperationExpr{Op: opcode.Xor, L: $1.(ast.ExprNode), R: $3.(ast.ExprNode)}
 	}
+|	PrimaryFactor pipes PrimaryFactor %prec pipes
+	{
+		$$ = &ast.FuncCallExpr{FnName: model.NewCIStr(ast.Concat), Args: []ast.ExprNode{$1.(ast.ExprNode), $3.(ast.ExprNode)}}
+	}
 |	PrimaryExpression
 
 
@@ -4932,6 +5235,14 @@ SelectLockOpt:
 	{
 		$$ = ast.SelectLockForUpdate
 	}
+|	"FOR" "UPDATE" "NOWAIT"
+	{
+		$$ = ast.SelectLockForUpdateNoWait
+	}
+|	"FOR" "UPDATE" "SKIP" "LOCKED"
+	{
+		$$ = ast.SelectLockForUpdateSkipLocked
+	}
 |	"LOCK" "IN" "SHARE" "MODE"
 	{
 		$$ = ast.SelectLockInShareMode
@@ -5016,6 +5327,10 @@ SetStmt:
 	{
 		$$ = &ast.SetPwdStmt{User: $4.(*auth.UserIdentity), Password: $6.(string)}
 	}
+|	"SET" "SESSION_STATES" stringLit
+	{
+		$$ = &ast.SetSessionStatesStmt{SessionStates: $3}
+	}
 |	"SET" "GLOBAL" "TRANSACTION" TransactionChars
 	{
 		vars := $4.([]*ast.VariableAssignment)
@@ -5028,6 +5343,15 @@ SetStmt:
 	{
 		$$ = &ast.SetStmt{Variables: $4.([]*ast.VariableAssignment)}
 	}
+|	"SET" "TRANSACTION" TransactionChars
+	{
+		// The characteristics apply to the next transaction only.
+		vars := $3.([]*ast.VariableAssignment)
+		for _, v := range vars {
+			v.Name = "tx_isolation_one_shot"
+		}
+		$$ = &ast.SetStmt{Variables: vars}
+	}
 
 TransactionChars:
 	TransactionChar
@@ -5266,6 +5590,97 @@ AdminStmt:
 			Tables: $4.([]*ast.TableName),
 		}
 	}
+|	"ADMIN" "RELOAD" "CONFIG"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminReloadConfig}
+	}
+|	"ADMIN" "RELOAD" "REWRITE" "RULES"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminReloadRewriteRules}
+	}
+|	"ADMIN" "CANCEL" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCancelDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
+|	"ADMIN" "PAUSE" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminPauseDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
+|	"ADMIN" "RESUME" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminResumeDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
+|	"ADMIN" "SPLIT" "TABLE" TableName "BETWEEN" SignedNum "AND" SignedNum "REGIONS" NUM
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminSplitTable,
+			Tables:	[]*ast.TableName{$4.(*ast.TableName)},
+			Split:	&ast.SplitOption{
+				Lower:	$6.(int64),
+				Upper:	$8.(int64),
+				Num:	int64(getUint64FromNUM($10)),
+			},
+		}
+	}
+|	"ADMIN" "SCATTER" "TABLE" TableName
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminScatterTable,
+			Tables:	[]*ast.TableName{$4.(*ast.TableName)},
+		}
+	}
+|	"ADMIN" "PAUSE" "WRITES"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminPauseWrites}
+	}
+|	"ADMIN" "PAUSE" "WRITES" "ON" "TABLE" TableName
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminPauseWrites,
+			Tables:	[]*ast.TableName{$6.(*ast.TableName)},
+		}
+	}
+|	"ADMIN" "RESUME" "WRITES"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminResumeWrites}
+	}
+|	"ADMIN" "RESUME" "WRITES" "ON" "TABLE" TableName
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminResumeWrites,
+			Tables:	[]*ast.TableName{$6.(*ast.TableName)},
+		}
+	}
+|	"ADMIN" "PIN" "SNAPSHOT" "TTL" NUM
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminPinSnapshot,
+			Pin:	&ast.SnapshotPin{TTL: getUint64FromNUM($5)},
+		}
+	}
+|	"ADMIN" "PIN" "SNAPSHOT" NUM "TTL" NUM
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminPinSnapshot,
+			Pin:	&ast.SnapshotPin{TS: getUint64FromNUM($4), TTL: getUint64FromNUM($6)},
+		}
+	}
+|	"ADMIN" "UNPIN" "SNAPSHOT" NUM
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminUnpinSnapshot,
+			Pin:	&ast.SnapshotPin{TS: getUint64FromNUM($4)},
+		}
+	}
 
 /****************************Show Statement*******************************/
 ShowStmt:
@@ -5308,10 +5723,32 @@ ShowStmt:
 			User:	$4.(*auth.UserIdentity),
 		}
 	}
-|	"SHOW" "PROCESSLIST"
+|	"SHOW" "GRANTS" "FOR" "CURRENT_USER"
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowGrants}
+	}
+|	"SHOW" "GRANTS" "FOR" "CURRENT_USER" '(' ')'
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowGrants}
+	}
+|	"SHOW" OptFull "PROCESSLIST"
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowProcessList,
+			Full:	$2.(bool),
+		}
+	}
+|	"SHOW" "PROGRESS" "FOR" NUM
+	{
+		$$ = &ast.ShowStmt{
+			Tp:		ast.ShowProgress,
+			ConnectionID:	getUint64FromNUM($4),
+		}
+	}
+|	"SHOW" "SESSION_STATES"
 	{
 		$$ = &ast.ShowStmt{
-			Tp: ast.ShowProcessList,
+			Tp: ast.ShowSessionStates,
 		}
 	}
 |	"SHOW" "STATS_META" ShowLikeOrWhereOpt
@@ -5342,6 +5779,13 @@ ShowStmt:
 		}
 		$$ = stmt
 	}
+|	"SHOW" "STATS_JSON" "FROM" TableName
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowStatsJSON,
+			Table:	$4.(*ast.TableName),
+		}
+	}
 |	"SHOW" "STATS_BUCKETS" ShowLikeOrWhereOpt
 	{
 		stmt := &ast.ShowStmt{
@@ -5370,6 +5814,10 @@ ShowTargetFilterable:
 	{
 		$$ = &ast.ShowStmt{Tp: ast.ShowEngines}
 	}
+|	"STORAGE" "ENGINES"
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowEngines}
+	}
 |	"DATABASES"
 	{
 		$$ = &ast.ShowStmt{Tp: ast.ShowDatabases}
@@ -5622,6 +6070,7 @@ Statement:
 |	ExplainStmt
 |	CreateDatabaseStmt
 |	CreateIndexStmt
+|	CreateStatisticsStmt
 |	CreateTableStmt
 |	CreateUserStmt
 |	DoStmt
@@ -5631,11 +6080,16 @@ Statement:
 |	DropViewStmt
 |	DropUserStmt
 |	DropStatsStmt
+|	DropStatisticsStmt
+|	LoadStatsStmt
+|	LockStatsStmt
+|	UnlockStatsStmt
 |	FlushStmt
 |	GrantStmt
 |	InsertIntoStmt
 |	KillStmt
 |	LoadDataStmt
+|	PlanReplayerStmt
 |	PreparedStmt
 |	RollbackStmt
 |	RenameTableStmt
@@ -5751,6 +6205,10 @@ TableOption:
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionAutoIncrement, UintValue: $3.(uint64)}
 	}
+|	"AUTO_ID_CACHE" EqOpt LengthNum
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionAutoIDCache, UintValue: $3.(uint64)}
+	}
 |	"COMMENT" EqOpt stringLit
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionComment, StrValue: $3}
@@ -5763,6 +6221,14 @@ TableOption:
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionConnection, StrValue: $3}
 	}
+|	"LOCATION" EqOpt stringLit
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionLocation, StrValue: $3}
+	}
+|	"FORMAT" EqOpt stringLit
+	{
+		$$ = &ast.TableOption{Tp: ast.TableOptionFormat, StrValue: $3}
+	}
 |	"CHECKSUM" EqOpt LengthNum
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionCheckSum, UintValue: $3.(uint64)}
@@ -5999,7 +6465,11 @@ FloatingPointType:
 	}
 |	"REAL"
 	{
-		$$ = mysql.TypeDouble
+		if parser.lexer.sqlMode&mysql.ModeRealAsFloat != 0 {
+			$$ = mysql.TypeFloat
+		} else {
+			$$ = mysql.TypeDouble
+		}
 	}
 |	"DOUBLE"
 	{
@@ -6318,7 +6788,7 @@ StringName:
  * See https://dev.mysql.com/doc/refman/5.7/en/update.html
  ***********************************************************************************/
 UpdateStmt:
-	"UPDATE" LowPriorityOptional IgnoreOptional TableRef "SET" AssignmentList WhereClauseOptional OrderByOptional LimitClause
+	"UPDATE" LowPriorityOptional IgnoreOptional TableRef "SET" AssignmentList WhereClauseOptional OrderByOptional LimitClause ReturningOptional
 	{
 		var refs *ast.Join
 		if x, ok := $4.(*ast.Join); ok {
@@ -6331,6 +6801,7 @@ UpdateStmt:
 			TableRefs:	&ast.TableRefsClause{TableRefs: refs},
 			List:		$6.([]*ast.Assignment),
 			IgnoreErr:		$3.(bool),
+			MultipleTable:	refs.Right != nil,
 		}
 		if $7 != nil {
 			st.Where = $7.(ast.ExprNode)
@@ -6341,15 +6812,20 @@ UpdateStmt:
 		if $9 != nil {
 			st.Limit = $9.(*ast.Limit)
 		}
+		if $10 != nil {
+			st.Returning = $10.(*ast.FieldList)
+		}
 		$$ = st
 	}
 |	"UPDATE" LowPriorityOptional IgnoreOptional TableRefs "SET" AssignmentList WhereClauseOptional
 	{
+		refs := $4.(*ast.Join)
 		st := &ast.UpdateStmt{
 			LowPriority:	$2.(bool),
-			TableRefs:	&ast.TableRefsClause{TableRefs: $4.(*ast.Join)},
+			TableRefs:	&ast.TableRefsClause{TableRefs: refs},
 			List:		$6.([]*ast.Assignment),
 			IgnoreErr:		$3.(bool),
+			MultipleTable:	refs.Right != nil,
 		}
 		if $7 != nil {
 			st.Where = $7.(ast.ExprNode)
@@ -14,6 +14,7 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"runtime"
 	"strings"
@@ -22,7 +23,10 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
+	sqlformat "github.com/pingcap/tidb/util/format"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -80,7 +84,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 
 	// Testcase for unreserved keywords
 	unreservedKws := []string{
-		"auto_increment", "after", "begin", "bit", "bool", "boolean", "charset", "columns", "commit",
+		"auto_increment", "auto_id_cache", "after", "begin", "bit", "bool", "boolean", "charset", "columns", "commit",
 		"date", "datediff", "datetime", "deallocate", "do", "from_days", "end", "engine", "engines", "execute", "first", "full",
 		"local", "names", "offset", "password", "prepare", "quick", "rollback", "session", "signed",
 		"start", "global", "tables", "text", "time", "timestamp", "tidb", "transaction", "truncate", "unknown",
@@ -93,7 +97,7 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "default", "shared", "exclusive",
-		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version",
+		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "stats_json", "tidb_version", "reload", "config", "cancel", "pause", "resume", "rewrite", "rules", "split", "scatter", "regions", "writes", "statistics", "samples", "progress", "placement", "policy", "replicas", "constraints", "leader_constraints", "session_states", "pin", "unpin", "ttl", "plan", "replayer", "dump", "plugins", "storage",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -123,6 +127,16 @@ func (s *testParserSuite) TestSimple(c *C) {
 	_, ok := stmt.(*ast.SetStmt)
 	c.Assert(ok, IsTrue)
 
+	// The TiDB specific code in /*T! xx */ is parsed, and the placement policy is the last table option.
+	src = "create table t (a int) comment 'x' /*T! placement policy replicas=5 */"
+	stmt, err = parser.ParseOneStmt(src, "", "")
+	c.Assert(err, IsNil)
+	ct, ok := stmt.(*ast.CreateTableStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(ct.Options, HasLen, 2)
+	c.Assert(ct.Options[1].Tp, Equals, ast.TableOptionPlacementPolicy)
+	c.Assert(ct.Options[1].PlacementOptions[0].UintValue, Equals, uint64(5))
+
 	// for issue #2017
 	src = "insert into blobtable (a) values ('/*! truncated */');"
 	stmt, err = parser.ParseOneStmt(src, "", "")
@@ -196,16 +210,34 @@ type testCase struct {
 func (s *testParserSuite) RunTest(c *C, table []testCase) {
 	parser := New()
 	for _, t := range table {
-		_, err := parser.Parse(t.src, "", "")
+		stmts, err := parser.Parse(t.src, "", "")
 		comment := Commentf("source %v", t.src)
 		if t.ok {
 			c.Assert(err, IsNil, comment)
+			for _, stmt := range stmts {
+				s.checkRestore(c, parser, stmt, t.src)
+			}
 		} else {
 			c.Assert(err, NotNil, comment)
 		}
 	}
 }
 
+// checkRestore checks the statement is restored to the SQL text which is parsed to the same statement, which is
+// restored to the same text again.
+func (s *testParserSuite) checkRestore(c *C, parser *Parser, stmt ast.StmtNode, src string) {
+	var sb bytes.Buffer
+	err := stmt.Restore(sqlformat.NewRestoreCtx(&sb))
+	comment := Commentf("source %v", src)
+	c.Assert(err, IsNil, comment)
+	restored := sb.String()
+	stmt2, err := parser.ParseOneStmt(restored, "", "")
+	c.Assert(err, IsNil, Commentf("source %v, restored %v", src, restored))
+	sb.Reset()
+	c.Assert(stmt2.Restore(sqlformat.NewRestoreCtx(&sb)), IsNil, comment)
+	c.Assert(sb.String(), Equals, restored, comment)
+}
+
 func (s *testParserSuite) TestDMLStmt(c *C) {
 	defer testleak.AfterTest(c)()
 	table := []testCase{
@@ -320,6 +352,12 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		// select for update
 		{"SELECT * from t for update", true},
 		{"SELECT * from t lock in share mode", true},
+		{"SELECT * from t for update nowait", true},
+		{"SELECT * from t for update skip locked", true},
+		{"SELECT * from t limit 1 for update skip locked", true},
+		{"SELECT * from t for update skip", false},
+		{"SELECT * from t lock in share mode nowait", false},
+		{"SELECT skip, locked, nowait from t", true},
 
 		// from join
 		{"SELECT * from t1, t2, t3", true},
@@ -339,6 +377,38 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"admin show ddl;", true},
 		{"admin show ddl jobs;", true},
 		{"admin check table t1, t2;", true},
+		{"admin reload config;", true},
+		{"admin reload rewrite rules;", true},
+		{"admin reload rules;", false},
+		{"admin reload;", false},
+		{"admin cancel ddl jobs 1;", true},
+		{"admin cancel ddl jobs 1, 2;", true},
+		{"admin pause ddl jobs 1, 2;", true},
+		{"admin split table t between 0 and 1000 regions 10;", true},
+		{"admin split table test.t between -100 and 100 regions 4;", true},
+		{"admin split table t between 0 and 1000;", false},
+		{"admin split table t1, t2 between 0 and 1000 regions 10;", false},
+		{"admin scatter table t;", true},
+		{"admin scatter table t1, t2;", false},
+		{"admin pause writes;", true},
+		{"admin pause writes on table t;", true},
+		{"admin pause writes on table t1, t2;", false},
+		{"admin resume writes;", true},
+		{"admin resume writes on table test.t;", true},
+		{"admin pin snapshot ttl 3600;", true},
+		{"admin pin snapshot 400036290571534337 ttl 600;", true},
+		{"admin pin snapshot;", false},
+		{"admin unpin snapshot 400036290571534337;", true},
+		{"admin unpin snapshot;", false},
+		{"plan replayer dump select * from t where a > 1;", true},
+		{"plan replayer dump update t set a = 1;", true},
+		{"plan replayer dump create table t (a int);", false},
+		{"plan replayer load '/tmp/replayer.zip';", true},
+		{"plan replayer load;", false},
+		{"create table plan (replayer int, dump int);", true},
+		{"admin resume ddl jobs 1;", true},
+		{"admin cancel ddl jobs;", false},
+		{"admin cancel ddl jobs 'a';", false},
 
 		// for on duplicate key update
 		{"INSERT INTO t (a,b,c) VALUES (1,2,3),(4,5,6) ON DUPLICATE KEY UPDATE c=VALUES(a)+VALUES(b);", true},
@@ -355,6 +425,21 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"UPDATE items,month SET items.price=month.price WHERE items.id=month.id LIMIT 10;", false},
 		{"UPDATE user T0 LEFT OUTER JOIN user_profile T1 ON T1.id = T0.profile_id SET T0.profile_id = 1 WHERE T0.profile_id IN (1);", true},
 
+		// for returning
+		{"INSERT INTO t VALUES (1, 2) RETURNING *;", true},
+		{"INSERT INTO t (a) SELECT b FROM t2 RETURNING a, b + 1 AS c;", true},
+		{"INSERT INTO t VALUES (1) ON DUPLICATE KEY UPDATE a = a + 1 RETURNING t.*, a;", true},
+		{"REPLACE INTO t SET a = 1 RETURNING a;", true},
+		{"UPDATE t SET a = a + 1 WHERE b = 1 ORDER BY c LIMIT 1 RETURNING a, b;", true},
+		{"DELETE FROM t WHERE a = 1 RETURNING *", true},
+		{"DELETE FROM t RETURNING", false},
+		{"DELETE t1 FROM t1, t2 WHERE t1.a = t2.a RETURNING t1.a;", false},
+		{"UPDATE t1, t2 SET t1.a = t2.a RETURNING t1.a;", false},
+		{"SELECT 1 RETURNING 1;", false},
+		{"CREATE TABLE returning (a int)", false},
+		{"CREATE TABLE t (returning int)", false},
+		{"SELECT t.returning FROM t", true},
+
 		// for select with where clause
 		{"SELECT * FROM t WHERE 1 = 1", true},
 
@@ -403,6 +488,8 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		{`SHOW FULL TABLES WHERE Table_Type != 'VIEW'`, true},
 		{`SHOW GRANTS`, true},
 		{`SHOW GRANTS FOR 'test'@'localhost'`, true},
+		{`SHOW GRANTS FOR CURRENT_USER`, true},
+		{`SHOW GRANTS FOR CURRENT_USER()`, true},
 		{`SHOW COLUMNS FROM City;`, true},
 		{`SHOW COLUMNS FROM tv189.1_t_1_x;`, true},
 		{`SHOW FIELDS FROM City;`, true},
@@ -418,6 +505,9 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		{`SHOW KEYS FROM t FROM test where true;`, true},
 		{`SHOW EVENTS FROM test_db WHERE definer = 'current_user'`, true},
 		{`SHOW PLUGINS`, true},
+		{`SHOW STORAGE ENGINES`, true},
+		{`SHOW STORAGE ENGINES LIKE 'Inno%'`, true},
+		{`SELECT * FROM information_schema.plugins, information_schema.storage`, true},
 		// for show character set
 		{"show character set;", true},
 		{"show charset", true},
@@ -440,6 +530,10 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		// for show stats_buckets
 		{"show stats_buckets", true},
 		{"show stats_buckets where col_name = 'a'", true},
+		// for show stats_json
+		{"show stats_json from t", true},
+		{"show stats_json from test.t", true},
+		{"show stats_json", false},
 
 		// set
 		// user defined
@@ -467,6 +561,9 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		// set password
 		{"SET PASSWORD = 'password';", true},
 		{"SET PASSWORD FOR 'root'@'localhost' = 'password';", true},
+		// set session states
+		{`SET SESSION_STATES '{"current_db":"test"}'`, true},
+		{"SET SESSION_STATES", false},
 		// SET TRANSACTION Syntax
 		{"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ", true},
 		{"SET GLOBAL TRANSACTION ISOLATION LEVEL REPEATABLE READ", true},
@@ -475,6 +572,9 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		{"SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED", true},
 		{"SET SESSION TRANSACTION ISOLATION LEVEL READ UNCOMMITTED", true},
 		{"SET SESSION TRANSACTION ISOLATION LEVEL SERIALIZABLE", true},
+		{"SET TRANSACTION ISOLATION LEVEL READ COMMITTED", true},
+		{"SET TRANSACTION READ ONLY", true},
+		{"SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ WRITE", true},
 		// for set names
 		{"set names utf8", true},
 		{"set names utf8 collate utf8_unicode_ci", true},
@@ -641,6 +741,7 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{"SELECT CURRENT_USER();", true},
 		{"SELECT CURRENT_USER;", true},
 		{"SELECT CONNECTION_ID();", true},
+		{"SELECT CURRENT_ROLE();", true},
 		{"SELECT VERSION();", true},
 		{"SELECT BENCHMARK(1000000, AES_ENCRYPT('text',UNHEX('F3229A0B371ED2D9441B830D21A390C3')));", true},
 		{"SELECT BENCHMARK(AES_ENCRYPT('text',UNHEX('F3229A0B371ED2D9441B830D21A390C3')));", true},
@@ -904,6 +1005,10 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT FORMAT(), FORMAT(12332.2,2,'de_DE'), FORMAT(12332.123456, 4)`, true},
 		{`SELECT FROM_BASE64('abc')`, true},
 		{`SELECT TO_BASE64('abc')`, true},
+		{`SELECT REGEXP_LIKE('abc', 'B'), REGEXP_LIKE('abc', 'B', 'c')`, true},
+		{`SELECT REGEXP_INSTR('abc', 'b'), REGEXP_SUBSTR('abc', 'b', 1, 1, 'i')`, true},
+		{`SELECT REGEXP_REPLACE('abc', 'b', 'x'), REGEXP_REPLACE('abcb', 'b', 'x', 1, 2, 'i')`, true},
+		{`SELECT regexp_like FROM t`, true},
 		{`SELECT INSERT(), INSERT('Quadratic', 3, 4, 'What'), INSTR('foobarbar', 'bar')`, true},
 		{`SELECT LOAD_FILE('/tmp/picture')`, true},
 		{`SELECT LPAD('hi',4,'??')`, true},
@@ -949,6 +1054,8 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT RELEASE_ALL_LOCKS(1);`, true},
 		{`SELECT UUID(1);`, true},
 		{`SELECT UUID_SHORT(1)`, true},
+		{`SELECT UUID_TO_BIN(UUID()), UUID_TO_BIN(UUID(), 1), BIN_TO_UUID(0x6CCD780CBABA102695645B8C656024DB, 1)`, true},
+		{`SELECT IS_UUID('6ccd780c-baba-1026-9564-5b8c656024db'), is_uuid FROM t`, true},
 
 		// for date_add
 		{`select date_add("2011-11-11 10:10:10.123456", interval 10 microsecond)`, true},
@@ -1130,6 +1237,8 @@ func (s *testParserSuite) TestBuiltin(c *C) {
 		{`SELECT JSON_UNQUOTE();`, true},
 		{`SELECT JSON_TYPE('[123]');`, true},
 		{`SELECT JSON_TYPE();`, true},
+		{`SELECT JSON_ARRAY(), JSON_OBJECT();`, true},
+		{`SELECT JSON_ARRAY(1, 'a'), JSON_OBJECT('a', 1);`, true},
 
 		// For two json grammar sugar.
 		{`SELECT a->'$.a' FROM t`, true},
@@ -1279,6 +1388,20 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"drop table if not exists xxx", false},
 		{"drop view if exists xxx", true},
 		{"drop stats t", true},
+		{"create statistics s1 on t (a, b)", true},
+		{"create statistics if not exists s1 on test.t (a, b, c)", true},
+		{"create statistics s1 on t", false},
+		{"create statistics s1 on t ()", false},
+		{"drop statistics s1 on t", true},
+		{"drop statistics if exists s1 on test.t", true},
+		{"drop statistics s1", false},
+		// for load/lock/unlock stats
+		{`load stats '{"table_name": "t"}'`, true},
+		{"load stats", false},
+		{"lock stats t", true},
+		{"lock stats t1, test.t2", true},
+		{"unlock stats t1, t2", true},
+		{"unlock stats", false},
 		// for issue 974
 		{`CREATE TABLE address (
 		id bigint(20) NOT NULL AUTO_INCREMENT,
@@ -1298,6 +1421,9 @@ func (s *testParserSuite) TestDDL(c *C) {
 		CONSTRAINT FK_7rod8a71yep5vxasb0ms3osbg FOREIGN KEY (user_id) REFERENCES waimaiqa.user (id),
 		INDEX FK_7rod8a71yep5vxasb0ms3osbg (user_id) comment ''
 		) ENGINE=InnoDB AUTO_INCREMENT=30 DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci ROW_FORMAT=COMPACT COMMENT='' CHECKSUM=0 DELAY_KEY_WRITE=0;`, true},
+		{"create table t (id int auto_increment primary key) auto_id_cache 1", true},
+		{"create table t (id int auto_increment primary key) auto_id_cache=1 auto_increment=10", true},
+		{"create table t (id int auto_increment primary key) auto_id_cache='1'", false},
 		// for issue 975
 		{`CREATE TABLE test_data (
 		id bigint(20) NOT NULL AUTO_INCREMENT,
@@ -1373,17 +1499,34 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"CREATE TABLE sbtest (id INTEGER UNSIGNED NOT NULL AUTO_INCREMENT, k integer UNSIGNED DEFAULT '0' NOT NULL, c char(120) DEFAULT '' NOT NULL, pad char(60) DEFAULT '' NOT NULL, PRIMARY KEY  (id) )", true},
 		{"create table test (create_date TIMESTAMP NOT NULL COMMENT '创建日期 create date' DEFAULT now());", true},
 		{"create table ts (t int, v timestamp(3) default CURRENT_TIMESTAMP(3));", true},
+		{"create table t (c varchar(36) default (uuid()))", true},
+		{"create table t (c int default (1 + 2), d json default (json_array()))", true},
+		{"create table t (c int default ())", false},
+		{"alter table t add column c double default (rand())", true},
 		// Create table with primary key name.
 		{"create table if not exists `t` (`id` int not null auto_increment comment '消息ID', primary key `pk_id` (`id`) );", true},
 		// Create table with like.
 		{"create table a like b", true},
 		{"create table if not exists a like b", true},
+		// Create table with select.
+		{"create table a select * from b", true},
+		{"create table a as select * from b", true},
+		{"create table if not exists a (c int) as select * from b", true},
+		{"create table a (c int, index idx(c)) engine=innodb select c from b where c > 1", true},
+		{"create table a engine=innodb as select 1", true},
+		{"create table a like b as select 1", false},
+		{"create table a as", false},
 		{"create table t (a timestamp default now)", false},
 		{"create table t (a timestamp default now())", true},
 		{"create table t (a timestamp default now() on update now)", false},
 		{"create table t (a timestamp default now() on update now())", true},
 		// Create table with ON UPDATE CURRENT_TIMESTAMP(6), specify fraction part.
 		{"CREATE TABLE IF NOT EXISTS `general_log` (`event_time` timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),`user_host` mediumtext NOT NULL,`thread_id` bigint(20) unsigned NOT NULL,`server_id` int(10) unsigned NOT NULL,`command_type` varchar(64) NOT NULL,`argument` mediumblob NOT NULL) ENGINE=CSV DEFAULT CHARSET=utf8 COMMENT='General log'", true},
+		// for external table options
+		{"create table t (a int) engine=File location='/data/t.csv' format='csv'", true},
+		{"create table t (a int) engine=File location 's3://bucket/t/' format 'parquet'", true},
+		{"create table t (a int) engine=File location=/data", false},
+		{"create table location (format int, location int)", true},
 
 		// for alter table
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED", true},
@@ -1402,6 +1545,16 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT CURRENT_TIMESTAMP", false},
 		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT NOW()", false},
 		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT 1+1", false},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT (1+1)", true},
+		{"ALTER TABLE t ALTER COLUMN a SET DEFAULT (uuid())", true},
+		{"ALTER TABLE t PLACEMENT POLICY REPLICAS = 3", true},
+		{"ALTER TABLE t PLACEMENT POLICY = REPLICAS 5 CONSTRAINTS '+zone=sh,-disk=hdd' LEADER_CONSTRAINTS = '+zone=sh'", true},
+		{"ALTER TABLE t PLACEMENT POLICY DEFAULT", true},
+		{"ALTER TABLE t PLACEMENT POLICY", false},
+		{"ALTER TABLE t PLACEMENT POLICY REPLICAS = 'a'", false},
+		{"CREATE TABLE t (a int) PLACEMENT POLICY REPLICAS = 3 CONSTRAINTS = '+zone=sh'", true},
+		{"CREATE TABLE t (a int) ENGINE = InnoDB /*T! PLACEMENT POLICY REPLICAS=5 LEADER_CONSTRAINTS='+disk=ssd' */", true},
+		{"CREATE TABLE t (a int) PLACEMENT POLICY DEFAULT", false},
 		{"ALTER TABLE t ALTER COLUMN a DROP DEFAULT", true},
 		{"ALTER TABLE t ALTER a DROP DEFAULT", true},
 		{"ALTER TABLE t ADD COLUMN a SMALLINT UNSIGNED, lock=none", true},
@@ -1420,6 +1573,12 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"ALTER TABLE t ADD UNIQUE (a) COMMENT 'a'", true},
 		{"ALTER TABLE t ADD UNIQUE KEY (a) COMMENT 'a'", true},
 		{"ALTER TABLE t ADD UNIQUE INDEX (a) COMMENT 'a'", true},
+		{"ALTER TABLE t ADD COLUMN IF NOT EXISTS a SMALLINT UNSIGNED", true},
+		{"ALTER TABLE t ADD IF NOT EXISTS a SMALLINT UNSIGNED FIRST", true},
+		{"ALTER TABLE t DROP COLUMN IF EXISTS a", true},
+		{"ALTER TABLE t DROP IF EXISTS a, DROP INDEX IF EXISTS idx", true},
+		{"ALTER TABLE t DROP KEY IF EXISTS idx", true},
+		{"ALTER TABLE t DROP COLUMN IF NOT EXISTS a", false},
 
 		// For create index statement
 		{"CREATE INDEX idx ON t (a)", true},
@@ -1428,6 +1587,10 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"CREATE INDEX idx ON t (a) USING HASH COMMENT 'foo'", true},
 		{"CREATE INDEX idx USING BTREE ON t (a) USING HASH COMMENT 'foo'", true},
 		{"CREATE INDEX idx USING BTREE ON t (a)", true},
+		{"CREATE INDEX idx ON t (a ASC, b DESC)", true},
+		{"CREATE INDEX IF NOT EXISTS idx ON t (a)", true},
+		{"CREATE UNIQUE INDEX IF NOT EXISTS idx USING BTREE ON t (a)", true},
+		{"CREATE INDEX IF EXISTS idx ON t (a)", false},
 
 		// for rename table statement
 		{"RENAME TABLE t TO t1", true},
@@ -1814,6 +1977,10 @@ func (s *testParserSuite) TestSessionManage(c *C) {
 		{"kill tidb connection 23123", true},
 		{"kill tidb query 23123", true},
 		{"show processlist", true},
+		{"show full processlist", true},
+		{"show progress for 1", true},
+		{"show progress for", false},
+		{"show session_states", true},
 	}
 	s.RunTest(c, table)
 }
@@ -1831,6 +1998,46 @@ func (s *testParserSuite) TestSQLModeANSIQuotes(c *C) {
 	}
 }
 
+func (s *testParserSuite) TestSQLModePipesAsConcat(c *C) {
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select a || b && c", "", "")
+	c.Assert(err, IsNil)
+	expr := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.BinaryOperationExpr)
+	c.Assert(expr.Op, Equals, opcode.LogicOr)
+
+	parser.SetSQLMode(mysql.ModePipesAsConcat)
+	stmt, err = parser.ParseOneStmt("select a || b && c", "", "")
+	c.Assert(err, IsNil)
+	expr = stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.BinaryOperationExpr)
+	c.Assert(expr.Op, Equals, opcode.LogicAnd)
+	concat := expr.L.(*ast.FuncCallExpr)
+	c.Assert(concat.FnName.L, Equals, ast.Concat)
+	c.Assert(concat.Args, HasLen, 2)
+}
+
+func (s *testParserSuite) TestSQLModeRealAsFloat(c *C) {
+	parser := New()
+	stmt, err := parser.ParseOneStmt("create table t (a real)", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.CreateTableStmt).Cols[0].Tp.Tp, Equals, mysql.TypeDouble)
+
+	parser.SetSQLMode(mysql.ModeRealAsFloat)
+	stmt, err = parser.ParseOneStmt("create table t (a real)", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(stmt.(*ast.CreateTableStmt).Cols[0].Tp.Tp, Equals, mysql.TypeFloat)
+}
+
+func (s *testParserSuite) TestErrorMsg(c *C) {
+	parser := New()
+	_, err := parser.Parse("select *\nfrom t\nwhere a = = 1", "", "")
+	c.Assert(terror.ErrorEqual(err, ErrParse), IsTrue)
+	c.Assert(err.Error(), Matches, ".*syntax.* near '= 1' at line 3 column 11")
+	c.Assert(terror.ToSQLError(err).Code, Equals, uint16(mysql.ErrParse))
+
+	_, err = parser.Parse("select 1 from", "", "")
+	c.Assert(err.Error(), Matches, ".* near '' at line 1 column 14")
+}
+
 func (s *testParserSuite) TestDDLStatements(c *C) {
 	parser := New()
 	// Tests that whatever the charset it is define, we always assign utf8 charset and utf8_bin collate.
@@ -1874,6 +2081,10 @@ func (s *testParserSuite) TestAnalyze(c *C) {
 		{"analyze table t,t1", true},
 		{"analyze table t1 index a", true},
 		{"analyze table t1 index a,b", true},
+		{"analyze table t1 with 10000 samples", true},
+		{"analyze table t, t1 with 100 samples", true},
+		{"analyze table t1 index a with 100 samples", false},
+		{"analyze table t1 with samples", false},
 	}
 	s.RunTest(c, table)
 }
@@ -1909,24 +2120,90 @@ func (s *testParserSuite) TestGeneratedColumn(c *C) {
 
 }
 
+func (s *testParserSuite) TestDefaultValueExpr(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input  string
+		isExpr bool
+		expr   string
+	}{
+		{"create table t (c varchar(36) default (  uuid()  ))", true, "uuid()"},
+		{"create table t (c int default (1 + 2))", true, "1 + 2"},
+		{"create table t (c int default 1)", false, ""},
+	}
+	parser := New()
+	for _, tt := range tests {
+		stmtNodes, err := parser.Parse(tt.input, "", "")
+		c.Assert(err, IsNil)
+		opt := stmtNodes[0].(*ast.CreateTableStmt).Cols[0].Options[0]
+		c.Assert(opt.Tp, Equals, ast.ColumnOptionDefaultValue)
+		c.Assert(opt.DefaultIsExpr, Equals, tt.isExpr)
+		if tt.isExpr {
+			c.Assert(opt.Expr.Text(), Equals, tt.expr)
+		}
+	}
+}
+
+func (s *testParserSuite) TestConnectionCharsetLiteral(c *C) {
+	defer testleak.AfterTest(c)()
+	// The string literals are decoded from the connection charset, the binary strings keep their bytes.
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select 'caf\xe9', 'a' '\xe9', _latin1'\xe9', _binary'\xe9', x'e9'", "latin1", "latin1_swedish_ci")
+	c.Assert(err, IsNil)
+	fields := stmt.(*ast.SelectStmt).Fields.Fields
+	expected := []struct {
+		value   string
+		charset string
+	}{
+		{"café", "latin1"},
+		{"aé", "latin1"},
+		{"é", "latin1"},
+		{"\xe9", "binary"},
+		{"\xe9", ""},
+	}
+	for i, tt := range expected {
+		expr := fields[i].Expr.(*ast.ValueExpr)
+		c.Assert(string(expr.GetBytes()), Equals, tt.value, Commentf("field %d", i))
+		if tt.charset != "" {
+			c.Assert(expr.GetType().Charset, Equals, tt.charset)
+		}
+	}
+	// The literals are kept as they're sent if the connection charset is UTF-8.
+	stmt, err = parser.ParseOneStmt("select 'caf\xe9'", "utf8", "utf8_bin")
+	c.Assert(err, IsNil)
+	expr := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.ValueExpr)
+	c.Assert(expr.GetString(), Equals, "caf\xe9")
+	c.Assert(expr.GetType().Charset, Equals, "utf8")
+}
+
 func (s *testParserSuite) TestSetTransaction(c *C) {
 	defer testleak.AfterTest(c)()
 	// Set transaction is equivalent to setting the global or session value of tx_isolation.
 	// For example:
 	// SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED
 	// SET SESSION tx_isolation='READ-COMMITTED'
+	// Without GLOBAL or SESSION it sets tx_isolation_one_shot, which is for the next transaction only.
 	tests := []struct {
 		input    string
+		name     string
 		isGlobal bool
 		value    string
 	}{
 		{
 			"SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED",
-			false, "READ-COMMITTED",
+			"tx_isolation", false, "READ-COMMITTED",
 		},
 		{
 			"SET GLOBAL TRANSACTION ISOLATION LEVEL REPEATABLE READ",
-			true, "REPEATABLE-READ",
+			"tx_isolation", true, "REPEATABLE-READ",
+		},
+		{
+			"SET TRANSACTION ISOLATION LEVEL SERIALIZABLE",
+			"tx_isolation_one_shot", false, "SERIALIZABLE",
+		},
+		{
+			"SET TRANSACTION READ WRITE, ISOLATION LEVEL READ COMMITTED",
+			"tx_isolation_one_shot", false, "READ-COMMITTED",
 		},
 	}
 	parser := New()
@@ -1935,7 +2212,7 @@ func (s *testParserSuite) TestSetTransaction(c *C) {
 		c.Assert(err, IsNil)
 		setStmt := stmt1.(*ast.SetStmt)
 		vars := setStmt.Variables[0]
-		c.Assert(vars.Name, Equals, "tx_isolation")
+		c.Assert(vars.Name, Equals, t.name)
 		c.Assert(vars.IsGlobal, Equals, t.isGlobal)
 		c.Assert(vars.IsSystem, Equals, true)
 		c.Assert(vars.Value.GetValue(), Equals, t.value)
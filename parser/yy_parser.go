@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -30,22 +31,32 @@ import (
 // Error instances.
 var (
 	ErrSyntax = terror.ClassParser.New(CodeSyntaxErr, "syntax error")
+	// ErrParse returns for the errors found in parsing, with the text near the error and its line and column.
+	ErrParse = terror.ClassParser.New(CodeParseErr, "%s near '%-.80s' at line %d column %d")
 )
 
 // Error codes.
 const (
 	CodeSyntaxErr terror.ErrCode = 1
+	CodeParseErr  terror.ErrCode = terror.ErrCode(mysql.ErrParse)
 )
 
+func init() {
+	parserMySQLErrCodes := map[terror.ErrCode]uint16{
+		CodeParseErr: mysql.ErrParse,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassParser] = parserMySQLErrCodes
+}
+
 var (
 	// SpecFieldPattern special result field pattern
 	SpecFieldPattern = regexp.MustCompile(`(\/\*!(M?[0-9]{5,6})?|\*\/)`)
-	specCodePattern  = regexp.MustCompile(`\/\*!(M?[0-9]{5,6})?([^*]|\*+[^*/])*\*+\/`)
-	specCodeStart    = regexp.MustCompile(`^\/\*!(M?[0-9]{5,6})?[ \t]*`)
+	specCodePattern  = regexp.MustCompile(`\/\*(!(M?[0-9]{5,6})?|T!)([^*]|\*+[^*/])*\*+\/`)
+	specCodeStart    = regexp.MustCompile(`^\/\*(!(M?[0-9]{5,6})?|T!)[ \t]*`)
 	specCodeEnd      = regexp.MustCompile(`[ \t]*\*\/$`)
 )
 
-// TrimComment trim comment for special comment code of MySQL.
+// TrimComment trim comment for special comment code of MySQL and TiDB.
 func TrimComment(txt string) string {
 	txt = specCodeStart.ReplaceAllString(txt, "")
 	return specCodeEnd.ReplaceAllString(txt, "")
@@ -104,6 +115,27 @@ func (parser *Parser) Parse(sql, charset, collation string) ([]ast.StmtNode, err
 	return parser.result, nil
 }
 
+// newStringLiteral returns the value of a string literal without a charset introducer, which is in the connection
+// charset. lit is already decoded from the connection charset.
+func (parser *Parser) newStringLiteral(lit string) *ast.ValueExpr {
+	expr := ast.NewValueExpr(lit)
+	if charset.NeedConvert(parser.charset) {
+		tp := expr.GetType()
+		tp.Charset, tp.Collate = parser.charset, parser.collation
+	}
+	return expr
+}
+
+// decodeLiteral decodes the bytes of a string literal in the charset cs to UTF-8, the charset of the other strings.
+// The bytes of the literals in the other charsets are kept as they're sent.
+func decodeLiteral(cs string, lit string) string {
+	if !charset.NeedConvert(cs) {
+		return lit
+	}
+	s, _ := charset.DecodeString(cs, hack.Slice(lit))
+	return s
+}
+
 // ParseOneStmt parses a query and returns an ast.StmtNode.
 // The query must have one statement, otherwise ErrSyntax is returned.
 func (parser *Parser) ParseOneStmt(sql, charset, collation string) (ast.StmtNode, error) {
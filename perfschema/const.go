@@ -112,7 +112,7 @@ var ColumnSetupTimers = []string{"NAME", "TIMER_NAME"}
 // 		TIMER_WAIT		BIGINT(20) UNSIGNED,
 // 		LOCK_TIME		BIGINT(20) UNSIGNED NOT NULL,
 // 		SQL_TEXT		LONGTEXT,
-// 		DIGEST			VARCHAR(32),
+// 		DIGEST			VARCHAR(64),
 // 		DIGEST_TEXT		LONGTEXT,
 // 		CURRENT_SCHEMA	VARCHAR(64),
 // 		OBJECT_TYPE		VARCHAR(64),
@@ -200,7 +200,7 @@ var ColumnStmtsCurrent = []string{
 // 		TIMER_WAIT		BIGINT(20) UNSIGNED,
 // 		LOCK_TIME		BIGINT(20) UNSIGNED NOT NULL,
 // 		SQL_TEXT		LONGTEXT,
-// 		DIGEST			VARCHAR(32),
+// 		DIGEST			VARCHAR(64),
 // 		DIGEST_TEXT		LONGTEXT,
 // 		CURRENT_SCHEMA	VARCHAR(64),
 // 		OBJECT_TYPE		VARCHAR(64),
@@ -288,7 +288,7 @@ var ColumnStmtsHistory = []string{
 // 		TIMER_WAIT		BIGINT(20) UNSIGNED,
 // 		LOCK_TIME		BIGINT(20) UNSIGNED NOT NULL,
 // 		SQL_TEXT		LONGTEXT,
-// 		DIGEST			VARCHAR(32),
+// 		DIGEST			VARCHAR(64),
 // 		DIGEST_TEXT		LONGTEXT,
 // 		CURRENT_SCHEMA	VARCHAR(64),
 // 		OBJECT_TYPE		VARCHAR(64),
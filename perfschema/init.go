@@ -84,7 +84,7 @@ var stmtsCurrentCols = []columnInfo{
 	{mysql.TypeLonglong, 20, mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
 	{mysql.TypeLongBlob, -1, 0, nil, nil},
-	{mysql.TypeVarchar, 32, 0, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
 	{mysql.TypeLongBlob, -1, 0, nil, nil},
 	{mysql.TypeVarchar, 64, 0, nil, nil},
 	{mysql.TypeVarchar, 64, 0, nil, nil},
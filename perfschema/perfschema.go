@@ -15,6 +15,7 @@ package perfschema
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
@@ -65,6 +66,16 @@ var (
 	enablePerfSchema = false
 )
 
+// IsSetupTable returns true if the table is a setup table, which configures the instrumentation like MySQL,
+// so it can be changed by the DML statements.
+func IsSetupTable(name string) bool {
+	switch strings.ToUpper(name) {
+	case TableSetupActors, TableSetupObjects, TableSetupInstruments, TableSetupConsumers, TableSetupTimers:
+		return true
+	}
+	return false
+}
+
 // EnablePerfSchema enables perfschema.
 func EnablePerfSchema() {
 	enablePerfSchema = true
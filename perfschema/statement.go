@@ -23,6 +23,7 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/util/sqldigest"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -35,9 +36,7 @@ type statementInfo struct {
 }
 
 // StatementState provides temporary storage to a statement runtime statistics.
-// TODO:
-// 1. support statement digest.
-// 2. support prepared statement.
+// TODO: support prepared statement.
 type StatementState struct {
 	// connID means connection identifier
 	connID uint64
@@ -211,6 +210,7 @@ func (ps *perfSchema) EndStatement(state *StatementState) {
 }
 
 func state2Record(state *StatementState) []types.Datum {
+	digestText, digest := sqldigest.NormalizeDigest(state.sqlText)
 	return types.MakeDatums(
 		state.connID,             // THREAD_ID
 		state.info.key,           // EVENT_ID
@@ -222,8 +222,8 @@ func state2Record(state *StatementState) []types.Datum {
 		nil, // TIMER_WAIT
 		uint64(state.lockTime),             // LOCK_TIME
 		state.sqlText,                      // SQL_TEXT
-		nil,                                // DIGEST
-		nil,                                // DIGEST_TEXT
+		digest,                             // DIGEST
+		digestText,                         // DIGEST_TEXT
 		state.schemaName,                   // CURRENT_SCHEMA
 		nil,                                // OBJECT_TYPE
 		nil,                                // OBJECT_SCHEMA
@@ -321,8 +321,12 @@ func (a *aggregationOptimizer) optimize(p LogicalPlan, ctx context.Context, allo
 	}
 	a.ctx = ctx
 	a.allocator = alloc
-	a.aggPushDown(p)
-	return p, nil
+	return a.aggPushDown(p), nil
+}
+
+// name implements logicalOptRule interface.
+func (a *aggregationOptimizer) name() string {
+	return "aggregation_push_down"
 }
 
 // aggPushDown tries to push down aggregate functions to join paths.
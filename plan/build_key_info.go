@@ -27,6 +27,11 @@ func (s *buildKeySolver) optimize(lp LogicalPlan, _ context.Context, _ *idAlloca
 	return lp, nil
 }
 
+// name implements logicalOptRule interface.
+func (s *buildKeySolver) name() string {
+	return "build_keys"
+}
+
 func (p *LogicalAggregation) buildKeyInfo() {
 	p.baseLogicalPlan.buildKeyInfo()
 	for _, key := range p.Children()[0].Schema().Keys {
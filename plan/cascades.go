@@ -0,0 +1,327 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The cascades optimizer searches the physical plan by rules, it's used instead of dagPhysicalOptimize when
+// tidb_enable_cascades_planner is on.
+//
+// The logical plan is put into a memo first, see memo.go. In the exploration phase, the transformation rules add
+// the logically equivalent expressions to the groups, like the join with the swapped children. In the
+// implementation phase, the implementation rules implement the expressions by the physical operators for the
+// required properties, and the cheapest task of a group is chosen among all its expressions.
+//
+// A new operator plugs in by registering its rules for its operand, the search doesn't change.
+
+// operand is the kind of the logical operators, the rules are registered for the operands they are applied to.
+type operand int
+
+const (
+	// operandAny is the operand of the operators without their own rules.
+	operandAny operand = iota
+	operandJoin
+	operandSort
+)
+
+func getOperand(p LogicalPlan) operand {
+	switch p.(type) {
+	case *LogicalJoin:
+		return operandJoin
+	case *Sort:
+		return operandSort
+	}
+	return operandAny
+}
+
+// transformationRule generates the logically equivalent expressions of an expression.
+type transformationRule interface {
+	// match checks if the rule is applied to the expression.
+	match(e *groupExpr) bool
+	// onTransform returns the new expressions of the group of the expression. The new expressions of the other
+	// groups, like the children of the returned expressions, are added to the memo by memo.groupOf.
+	onTransform(m *memo, e *groupExpr) []*groupExpr
+}
+
+// implementationRule implements an expression by the physical operators.
+type implementationRule interface {
+	// match checks if the rule is applied to the expression for the required property.
+	match(e *groupExpr, prop *requiredProp) bool
+	// onImplement returns the cheapest task of the expression for the required property, the children groups of
+	// the expression are implemented by cascadesOptimizer.implementGroup.
+	onImplement(o *cascadesOptimizer, e *groupExpr, prop *requiredProp) (task, error)
+}
+
+var defaultTransformationRules = map[operand][]transformationRule{
+	operandJoin: {&joinCommuteRule{}},
+}
+
+var defaultImplementationRules = map[operand][]implementationRule{
+	operandAny:  {&physicalPlansImpl{}},
+	operandSort: {&sortImpl{}},
+}
+
+type cascadesOptimizer struct {
+	memo                *memo
+	transformationRules map[operand][]transformationRule
+	implementationRules map[operand][]implementationRule
+}
+
+func newCascadesOptimizer() *cascadesOptimizer {
+	return &cascadesOptimizer{
+		memo:                newMemo(),
+		transformationRules: defaultTransformationRules,
+		implementationRules: defaultImplementationRules,
+	}
+}
+
+// findBestPlan finds the cheapest physical plan of the logical plan.
+func (o *cascadesOptimizer) findBestPlan(logic LogicalPlan) (PhysicalPlan, error) {
+	logic.preparePossibleProperties()
+	logic.prepareStatsProfile()
+	root := o.memo.convert2Group(logic)
+	o.exploreGroup(root)
+	t, err := o.implementGroup(root, &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	p := t.plan()
+	if p == nil {
+		return nil, errors.New("no physical plan is found by the cascades optimizer")
+	}
+	rebuildSchema(p)
+	p.ResolveIndices()
+	return p, nil
+}
+
+// exploreGroup applies the transformation rules to the expressions of the group and its descendant groups until no
+// new expression is added. The group is marked explored at first, so a group referencing back to it doesn't explore
+// it again.
+func (o *cascadesOptimizer) exploreGroup(g *group) {
+	if g.explored {
+		return
+	}
+	g.explored = true
+	// The new expressions are appended to the group, they are explored in the loop too.
+	for i := 0; i < len(g.equivalents); i++ {
+		e := g.equivalents[i]
+		for _, child := range e.children {
+			o.exploreGroup(child)
+		}
+		if e.explored {
+			continue
+		}
+		e.explored = true
+		for _, rule := range o.transformationRules[getOperand(e.node)] {
+			if !rule.match(e) {
+				continue
+			}
+			for _, newExpr := range rule.onTransform(o.memo, e) {
+				if _, ok := o.memo.exprs[newExpr.fingerprint()]; ok {
+					continue
+				}
+				prepareGroupExpr(newExpr)
+				o.memo.insert(g, newExpr)
+			}
+		}
+	}
+}
+
+// implementGroup returns the cheapest task of the group for the required property.
+func (o *cascadesOptimizer) implementGroup(g *group, prop *requiredProp) (task, error) {
+	// The inner child of an index join isn't implemented, its required property is nil.
+	if prop == nil {
+		return nil, nil
+	}
+	key := string(prop.hashCode())
+	if t, ok := g.implMap[key]; ok {
+		return t, nil
+	}
+	if g.implementing[key] {
+		return invalidTask, nil
+	}
+	g.implementing[key] = true
+	var best task = invalidTask
+	for _, e := range g.equivalents {
+		rules, ok := o.implementationRules[getOperand(e.node)]
+		if !ok {
+			rules = o.implementationRules[operandAny]
+		}
+		for _, rule := range rules {
+			if !rule.match(e, prop) {
+				continue
+			}
+			t, err := rule.onImplement(o, e, prop)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if t.cost() < best.cost() {
+				best = t
+			}
+		}
+	}
+	delete(g.implementing, key)
+	g.implMap[key] = best
+	return best, nil
+}
+
+// getBestTask attaches the physical plan to the cheapest tasks of the children groups for the required properties
+// of the children, and returns it if it's cheaper than the best task. If enforced is true, the children are
+// implemented without the required property and the property is enforced on the result by a sort.
+func (o *cascadesOptimizer) getBestTask(bestTask task, e *groupExpr, prop *requiredProp, pp PhysicalPlan, enforced bool) (task, error) {
+	var newProps [][]*requiredProp
+	if enforced {
+		newProps = pp.getChildrenPossibleProps(&requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64})
+	} else {
+		newProps = pp.getChildrenPossibleProps(prop)
+	}
+	for _, newProp := range newProps {
+		tasks := make([]task, 0, len(e.children))
+		for i, child := range e.children {
+			childTask, err := o.implementGroup(child, newProp[i])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			tasks = append(tasks, childTask)
+		}
+		resultTask := pp.attach2Task(tasks...)
+		if enforced {
+			resultTask = prop.enforceProperty(resultTask, e.node.context(), e.node.Allocator())
+		}
+		if resultTask.cost() < bestTask.cost() {
+			bestTask = resultTask
+		}
+	}
+	return bestTask, nil
+}
+
+// joinCommuteRule swaps the children of an inner join. The schema of the swapped join is in the different order,
+// so it's under a projection which keeps the schema of the original join.
+type joinCommuteRule struct {
+}
+
+func (r *joinCommuteRule) match(e *groupExpr) bool {
+	join := e.node.(*LogicalJoin)
+	return join.JoinType == InnerJoin
+}
+
+func (r *joinCommuteRule) onTransform(m *memo, e *groupExpr) []*groupExpr {
+	join := e.node.(*LogicalJoin)
+	newJoin := LogicalJoin{
+		JoinType:        InnerJoin,
+		reordered:       join.reordered,
+		cartesianJoin:   join.cartesianJoin,
+		preferMergeJoin: join.preferMergeJoin,
+		LeftConditions:  join.RightConditions,
+		RightConditions: join.LeftConditions,
+		OtherConditions: join.OtherConditions,
+		LeftJoinKeys:    join.RightJoinKeys,
+		RightJoinKeys:   join.LeftJoinKeys,
+	}.init(join.allocator, join.ctx)
+	if join.preferINLJ&preferLeftAsOuter > 0 {
+		newJoin.preferINLJ |= preferRightAsOuter
+	}
+	if join.preferINLJ&preferRightAsOuter > 0 {
+		newJoin.preferINLJ |= preferLeftAsOuter
+	}
+	newJoin.EqualConditions = make([]*expression.ScalarFunction, 0, len(join.EqualConditions))
+	for _, cond := range join.EqualConditions {
+		args := cond.GetArgs()
+		newCond, _ := expression.NewFunction(join.ctx, ast.EQ, types.NewFieldType(mysql.TypeTiny), args[1], args[0])
+		newJoin.EqualConditions = append(newJoin.EqualConditions, newCond.(*expression.ScalarFunction))
+	}
+	newJoin.SetSchema(expression.MergeSchema(e.children[1].representative().Schema(), e.children[0].representative().Schema()))
+	joinGroup := m.groupOf(newJoin, []*group{e.children[1], e.children[0]})
+
+	proj := Projection{Exprs: make([]expression.Expression, 0, join.Schema().Len())}.init(join.allocator, join.ctx)
+	for _, col := range join.Schema().Columns {
+		proj.Exprs = append(proj.Exprs, col)
+	}
+	proj.SetSchema(join.Schema().Clone())
+	return []*groupExpr{{node: proj, children: []*group{joinGroup}}}
+}
+
+// physicalPlansImpl implements an expression by the physical plans generated by generatePhysicalPlans, it's the
+// counterpart of baseLogicalPlan.convert2NewPhysicalPlan.
+type physicalPlansImpl struct {
+}
+
+func (r *physicalPlansImpl) match(e *groupExpr, prop *requiredProp) bool {
+	return true
+}
+
+func (r *physicalPlansImpl) onImplement(o *cascadesOptimizer, e *groupExpr, prop *requiredProp) (task, error) {
+	// The leaves, like the data sources, are implemented as they are in the plan tree.
+	if len(e.children) == 0 {
+		t, err := e.node.convert2NewPhysicalPlan(prop)
+		return t, errors.Trace(err)
+	}
+	var t task = invalidTask
+	// Currently no plan with children can be pushed down.
+	if prop.taskTp != rootTaskType {
+		return t, nil
+	}
+	var err error
+	for _, pp := range e.node.generatePhysicalPlans() {
+		t, err = o.getBestTask(t, e, prop, pp, true)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if prop.isEmpty() {
+			continue
+		}
+		t, err = o.getBestTask(t, e, prop, pp, false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return t, nil
+}
+
+// sortImpl implements a sort, it's the counterpart of Sort.convert2NewPhysicalPlan.
+type sortImpl struct {
+}
+
+func (r *sortImpl) match(e *groupExpr, prop *requiredProp) bool {
+	return prop.taskTp == rootTaskType
+}
+
+func (r *sortImpl) onImplement(o *cascadesOptimizer, e *groupExpr, prop *requiredProp) (task, error) {
+	p := e.node.(*Sort)
+	t, err := o.implementGroup(e.children[0], &requiredProp{taskTp: rootTaskType, expectedCnt: math.MaxFloat64})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t = p.attach2Task(t)
+	newProp, canPassProp := getPropByOrderByItems(p.ByItems)
+	if canPassProp {
+		newProp.expectedCnt = prop.expectedCnt
+		orderedTask, err := o.implementGroup(e.children[0], newProp)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if orderedTask.cost() < t.cost() {
+			t = orderedTask
+		}
+	}
+	return prop.enforceProperty(t, p.ctx, p.allocator), nil
+}
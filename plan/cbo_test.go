@@ -14,7 +14,9 @@
 package plan_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
@@ -271,6 +273,70 @@ func (s *testAnalyzeSuite) TestAnalyze(c *C) {
 	}
 }
 
+func (s *testAnalyzeSuite) TestOptimizerTrace(c *C) {
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	testKit := testkit.NewTestKit(c, store)
+	defer func() {
+		store.Close()
+	}()
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec("create table t (a int primary key, b int, c varchar(200))")
+	testKit.MustExec("create index b on t (b)")
+	for i := 0; i < 10; i++ {
+		testKit.MustExec(constructInsertSQL(i, 100))
+	}
+	testKit.MustExec("analyze table t")
+	testKit.MustQuery("select @@tidb_last_optimizer_trace").Check(testkit.Rows(""))
+
+	testKit.MustExec("set @@tidb_trace_optimizer = 1")
+	testKit.MustQuery("select count(c) from t where t.b <= 2").Check(testkit.Rows("300"))
+	// The trace only applies to the next statement.
+	testKit.MustQuery("select @@tidb_trace_optimizer").Check(testkit.Rows("0"))
+	rows := testKit.MustQuery("select @@tidb_last_optimizer_trace").Rows()
+	var trace struct {
+		LogicalPlan string `json:"logical_plan"`
+		Rules       []struct {
+			Rule string `json:"rule"`
+			Plan string `json:"plan"`
+		} `json:"rules"`
+		Candidates []struct {
+			Operator string  `json:"operator"`
+			Plan     string  `json:"plan"`
+			Cost     float64 `json:"cost"`
+		} `json:"candidates"`
+		FinalPlan string  `json:"final_plan"`
+		FinalCost float64 `json:"final_cost"`
+	}
+	err = json.Unmarshal([]byte(rows[0][0].(string)), &trace)
+	c.Assert(err, IsNil)
+	c.Assert(trace.LogicalPlan, Equals, "DataScan(t)->Sel([le(test.t.b, 2)])->Aggr(count(test.t.c),firstrow(test.t.a),firstrow(test.t.b),firstrow(test.t.c))->Projection")
+	c.Assert(len(trace.Rules), Greater, 0)
+	c.Assert(trace.Rules[0].Rule, Equals, "column_prune")
+	// Both the table scan and the index scan are considered for the data source.
+	var dsPlans []string
+	for _, candidate := range trace.Candidates {
+		c.Assert(candidate.Cost, Greater, 0.0)
+		if strings.HasPrefix(candidate.Operator, "TableScan") {
+			dsPlans = append(dsPlans, candidate.Plan)
+		}
+	}
+	c.Assert(dsPlans, DeepEquals, []string{"Table(t)->Sel([le(test.t.b, 2)])", "Index(t.b)[[-inf,2]]",
+		"TableReader(Table(t)->Sel([le(test.t.b, 2)]))", "IndexLookUp(Index(t.b)[[-inf,2]], Table(t))"})
+	c.Assert(trace.FinalPlan, Equals, "IndexLookUp(Index(t.b)[[-inf,2]], Table(t)->HashAgg)->HashAgg")
+	c.Assert(trace.FinalCost, Greater, 0.0)
+
+	// The trace of the last traced statement is kept.
+	testKit.MustExec("select * from t where a = 1")
+	testKit.MustQuery("select @@tidb_last_optimizer_trace").Check(rows)
+	_, err = testKit.Exec("set @@tidb_last_optimizer_trace = ''")
+	c.Assert(err, NotNil)
+}
+
 func newStoreWithBootstrap() (kv.Storage, error) {
 	store, err := tikv.NewMockTikvStore()
 	if err != nil {
@@ -28,6 +28,11 @@ func (s *columnPruner) optimize(lp LogicalPlan, _ context.Context, _ *idAllocato
 	return lp, nil
 }
 
+// name implements logicalOptRule interface.
+func (s *columnPruner) name() string {
+	return "column_prune"
+}
+
 func getUsedList(usedCols []*expression.Column, schema *expression.Schema) []bool {
 	used := make([]bool, schema.Len())
 	for _, col := range usedCols {
@@ -220,6 +225,9 @@ func (p *LogicalJoin) extractUsedCols(parentUsedCols []*expression.Column) (left
 	for _, otherCond := range p.OtherConditions {
 		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(otherCond)...)
 	}
+	for _, naCond := range p.NullAwareConditions {
+		parentUsedCols = append(parentUsedCols, expression.ExtractColumns(naCond)...)
+	}
 	lChild := p.children[0].(LogicalPlan)
 	rChild := p.children[1].(LogicalPlan)
 	for _, col := range parentUsedCols {
@@ -273,7 +281,7 @@ func (p *LogicalApply) PruneColumns(parentUsedCols []*expression.Column) {
 
 // PruneColumns implements LogicalPlan interface.
 func (p *SelectLock) PruneColumns(parentUsedCols []*expression.Column) {
-	if p.Lock != ast.SelectLockForUpdate {
+	if !p.Lock.IsForUpdate() {
 		p.baseLogicalPlan.PruneColumns(parentUsedCols)
 	} else {
 		used := getUsedList(parentUsedCols, p.schema)
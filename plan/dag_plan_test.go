@@ -184,6 +184,50 @@ func (s *testPlanSuite) TestDAGPlanBuilderSimpleCase(c *C) {
 	}
 }
 
+func (s *testPlanSuite) TestPlanDigest(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	digest := func(sql string) string {
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil)
+		c.Assert(se.NewTxn(), IsNil)
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		return plan.Digest(p)
+	}
+	tests := []struct {
+		sql1 string
+		sql2 string
+		same bool
+	}{
+		// The constants are not in the digest.
+		{"select * from t where t.c = 1", "select * from t where t.c = 10", true},
+		{"select c from t where c = 1 limit 1", "select c from t where c = 2 limit 10", true},
+		{"select * from t where a between 1 and 2 order by c", "select * from t where a between 5 and 20 order by c", true},
+		// The access paths are in the digest.
+		{"select * from t t1 use index(c_d_e) where c = 1", "select * from t t1 ignore index(c_d_e) where c = 1", false},
+		{"select c from t where c = 1", "select c from t where e = 1", false},
+		{"select * from t t1 join t t2 on t1.a = t2.a where t1.c = 1", "select * from t t1 join t t2 on t1.a = t2.a where t1.c = 5", true},
+		// The operators are in the digest.
+		{"select c from t where c = 1", "select c from t where c = 1 limit 1", false},
+		{"select * from t t1 join t t2 on t1.a = t2.a", "select * from t t1 left join t t2 on t1.a = t2.a", false},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s and %s", tt.sql1, tt.sql2)
+		c.Assert(digest(tt.sql1) == digest(tt.sql2), Equals, tt.same, comment)
+	}
+	c.Assert(digest("select * from t"), HasLen, 64)
+}
+
 func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 	store, err := newStoreWithBootstrap()
 	c.Assert(err, IsNil)
@@ -248,7 +292,7 @@ func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 		},
 		{
 			sql:  "select t.c in (select b from t s where s.a = t.a) from t",
-			best: "SemiJoinWithAux{TableReader(Table(t))->TableReader(Table(t))}(test.t.a,s.a)(test.t.c,s.b)->Projection",
+			best: "SemiJoinWithAux{TableReader(Table(t))->TableReader(Table(t))}(test.t.a,s.a)->Projection",
 		},
 		// Test Single Merge Join.
 		// Merge Join will no longer enforce a sort. If a hint doesn't take effect, we will choose other types of join.
@@ -706,6 +750,24 @@ func (s *testPlanSuite) TestDAGPlanBuilderAgg(c *C) {
 			sql:  "select sum(to_base64(e)) from t where c = 1",
 			best: "IndexReader(Index(t.c_d_e)[[1,1]])->HashAgg",
 		},
+		// Test distinct on the unique key.
+		{
+			sql:  "select distinct a from t",
+			best: "TableReader(Table(t))->Projection",
+		},
+		// Test distinct on the index order.
+		{
+			sql:  "select distinct d, c from t limit 10",
+			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])->StreamAgg->Limit",
+		},
+		{
+			sql:  "select distinct c, d from t where c > 1",
+			best: "IndexReader(Index(t.c_d_e)[(1 +inf,+inf +inf]]->HashAgg)->HashAgg",
+		},
+		{
+			sql:  "select distinct d from t limit 10",
+			best: "TableReader(Table(t)->HashAgg)->HashAgg->Limit",
+		},
 	}
 	for _, tt := range tests {
 		comment := Commentf("for %s", tt.sql)
@@ -844,11 +906,11 @@ func (s *testPlanSuite) TestRefine(c *C) {
 		},
 		{
 			sql:  "select a from t where c_str like ''",
-			best: "IndexReader(Index(t.c_d_e_str)[[,]])->Projection",
+			best: "IndexReader(Index(t.c_d_e_str)[[,]]->Sel([like(test.t.c_str, , 92)]))->Projection",
 		},
 		{
 			sql:  "select a from t where c_str like 'abc'",
-			best: "IndexReader(Index(t.c_d_e_str)[[abc,abc]])->Projection",
+			best: "IndexReader(Index(t.c_d_e_str)[[abc,abc]]->Sel([like(test.t.c_str, abc, 92)]))->Projection",
 		},
 		{
 			sql:  "select a from t where c_str not like 'abc'",
@@ -864,7 +926,7 @@ func (s *testPlanSuite) TestRefine(c *C) {
 		},
 		{
 			sql:  "select a from t where c_str like 'abc%'",
-			best: "IndexReader(Index(t.c_d_e_str)[[abc,abd)])->Projection",
+			best: "IndexReader(Index(t.c_d_e_str)[[abc,abd)]->Sel([like(test.t.c_str, abc%, 92)]))->Projection",
 		},
 		{
 			// FIXME: Should use index reader.
@@ -956,3 +1018,97 @@ func (s *testPlanSuite) TestRefine(c *C) {
 		c.Assert(plan.ToString(p), Equals, tt.best, Commentf("for %s", tt.sql))
 	}
 }
+
+func (s *testPlanSuite) TestCascadesPlanner(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	bestPlan := func(sql string, cascades bool) string {
+		se.GetSessionVars().EnableCascadesPlanner = cascades
+		stmt, err := s.ParseOneStmt(sql, "", "")
+		c.Assert(err, IsNil)
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		return plan.ToString(p)
+	}
+	// The commuted joins are under the projections, they are chosen only if they are cheaper, so the cascades
+	// optimizer finds the same plans as the old one by the same cost model.
+	tests := []string{
+		"select * from t where t.c = 1 and t.e = 1",
+		"select * from t use index(c_d_e) order by c, d, e",
+		"select c from t where c = 1 order by d desc limit 1",
+		"select count(*) from t group by b",
+		"select * from t t1 join t t2 on t1.a = t2.b",
+		"select * from t t1 join t t2 on t1.a = t2.c where t1.b = 1 order by t1.a",
+		"select * from t t1 join t t2 on t1.a = t2.a join t t3 on t1.b = t3.c",
+		"select /*+ TIDB_INLJ(t1) */ * from t t1 join t t2 on t1.a = t2.c",
+		"select /*+ TIDB_INLJ(t2) */ * from t t1 join t t2 on t1.a = t2.c",
+		"select /*+ TIDB_SMJ(t1, t2) */ * from t t1 join t t2 on t1.a = t2.a",
+		"select * from t t1 left join t t2 on t1.a = t2.b",
+		"select * from t where exists (select * from t s where s.c = t.c)",
+		"select a from t union all select b from t",
+	}
+	for _, sql := range tests {
+		c.Assert(bestPlan(sql, true), Equals, bestPlan(sql, false), Commentf("for %s", sql))
+	}
+}
+
+func (s *testPlanSuite) TestLateMaterialization(c *C) {
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	se.GetSessionVars().AllowLateMaterialization = true
+
+	defer func() {
+		testleak.AfterTest(c)()
+	}()
+	tests := []struct {
+		sql  string
+		best string
+	}{
+		// Only the filter column is scanned first, the other columns are read by the handles of the filtered rows.
+		{
+			sql:  "select * from t where t.b <= 50",
+			best: "IndexLookUp(Table(t)->Sel([le(test.t.b, 50)]), Table(t))",
+		},
+		{
+			sql:  "select * from t where t.b = 1",
+			best: "IndexLookUp(Table(t)->Sel([eq(test.t.b, 1)]), Table(t))",
+		},
+		// The index is cheaper.
+		{
+			sql:  "select * from t where t.b = 1 and t.c_str = 'x'",
+			best: "IndexLookUp(Index(t.c_d_e_str)[[x,x]], Table(t)->Sel([eq(test.t.b, 1)]))",
+		},
+		// The filter needs all the columns.
+		{
+			sql:  "select b from t where t.b = 1",
+			best: "TableReader(Table(t)->Sel([eq(test.t.b, 1)]))",
+		},
+		{
+			sql:  "select * from t where t.b = 1 order by a",
+			best: "IndexLookUp(Table(t)->Sel([eq(test.t.b, 1)]), Table(t))->Sort",
+		},
+	}
+	for _, tt := range tests {
+		comment := Commentf("for %s", tt.sql)
+		stmt, err := s.ParseOneStmt(tt.sql, "", "")
+		c.Assert(err, IsNil, comment)
+
+		is, err := plan.MockResolve(stmt)
+		c.Assert(err, IsNil)
+		p, err := plan.Optimize(se, stmt, is)
+		c.Assert(err, IsNil)
+		c.Assert(plan.ToString(p), Equals, tt.best, Commentf("for %s", tt.sql))
+	}
+}
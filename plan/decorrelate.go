@@ -57,7 +57,7 @@ func (a *LogicalApply) canPullUpAgg() bool {
 	if a.JoinType != InnerJoin && a.JoinType != LeftOuterJoin {
 		return false
 	}
-	if len(a.EqualConditions)+len(a.LeftConditions)+len(a.RightConditions)+len(a.OtherConditions) > 0 {
+	if len(a.EqualConditions)+len(a.LeftConditions)+len(a.RightConditions)+len(a.OtherConditions)+len(a.NullAwareConditions) > 0 {
 		return false
 	}
 	return len(a.children[0].Schema().Keys) > 0
@@ -176,6 +176,11 @@ func (s *decorrelateSolver) optimize(p LogicalPlan, _ context.Context, _ *idAllo
 	return p, nil
 }
 
+// name implements logicalOptRule interface.
+func (s *decorrelateSolver) name() string {
+	return "decorrelate"
+}
+
 func (p *Selection) checkScanController() int {
 	var (
 		corColConds []expression.Expression
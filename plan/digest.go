@@ -0,0 +1,82 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// Digest returns the hex encoded SHA-256 of the shape of the plan. The shape is the operators of the plan tree with
+// the tables and the indexes they access, the join types and the join orders. The constants, the ranges and the
+// conditions are not in the shape, so the executions of a statement with different arguments have the same digest
+// until the plan changes, and a plan regression can be found by comparing the digests over time.
+func Digest(p Plan) string {
+	var buf bytes.Buffer
+	writePlanShape(&buf, p)
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+func writePlanShape(buf *bytes.Buffer, p Plan) {
+	buf.WriteString(reflect.Indirect(reflect.ValueOf(p)).Type().Name())
+	switch x := p.(type) {
+	case *PhysicalTableScan:
+		fmt.Fprintf(buf, "(%s,%v)", x.Table.Name.L, x.Desc)
+	case *PhysicalIndexScan:
+		fmt.Fprintf(buf, "(%s.%s,%v)", x.Table.Name.L, x.Index.Name.L, x.Desc)
+	case *PhysicalMemTable:
+		fmt.Fprintf(buf, "(%s.%s)", x.DBName.L, x.Table.Name.L)
+	case *PhysicalHashJoin:
+		fmt.Fprintf(buf, "(%s,%d)", x.JoinType, x.SmallTable)
+	case *PhysicalMergeJoin:
+		fmt.Fprintf(buf, "(%s)", x.JoinType)
+	case *PhysicalIndexJoin:
+		fmt.Fprintf(buf, "(%v,%d)", x.Outer, x.outerIndex)
+	case *PhysicalHashSemiJoin:
+		fmt.Fprintf(buf, "(%v,%v)", x.WithAux, x.Anti)
+	case *PhysicalAggregation:
+		fmt.Fprintf(buf, "(%s)", x.AggType)
+	case *PhysicalTableReader:
+		writeCopPlanShape(buf, x.tablePlan)
+	case *PhysicalIndexReader:
+		writeCopPlanShape(buf, x.indexPlan)
+	case *PhysicalIndexLookUpReader:
+		writeCopPlanShape(buf, x.indexPlan)
+		writeCopPlanShape(buf, x.tablePlan)
+	}
+	if children := p.Children(); len(children) > 0 {
+		buf.WriteByte('{')
+		for i, child := range children {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writePlanShape(buf, child)
+		}
+		buf.WriteByte('}')
+	}
+}
+
+// writeCopPlanShape writes the shape of the plan pushed down to the storage.
+func writeCopPlanShape(buf *bytes.Buffer, p PhysicalPlan) {
+	if p == nil {
+		return
+	}
+	buf.WriteByte('[')
+	writePlanShape(buf, p)
+	buf.WriteByte(']')
+}
@@ -107,6 +107,11 @@ func (pe *projectionEliminater) optimize(lp LogicalPlan, _ context.Context, _ *i
 	return root.(LogicalPlan), nil
 }
 
+// name implements logicalOptRule interface.
+func (pe *projectionEliminater) name() string {
+	return "projection_eliminate"
+}
+
 // eliminate eliminates the redundant projection in a logical plan.
 func (pe *projectionEliminater) eliminate(p LogicalPlan, replace map[string]*expression.Column, canEliminate bool) LogicalPlan {
 	proj, isProj := p.(*Projection)
@@ -176,6 +181,9 @@ func (p *LogicalJoin) replaceExprColumns(replace map[string]*expression.Column)
 	for _, otherExpr := range p.OtherConditions {
 		resolveExprAndReplace(otherExpr, replace)
 	}
+	for _, naExpr := range p.NullAwareConditions {
+		resolveExprAndReplace(naExpr, replace)
+	}
 }
 
 func (p *Projection) replaceExprColumns(replace map[string]*expression.Column) {
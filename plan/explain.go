@@ -121,6 +121,15 @@ func (p *PhysicalTableScan) ExplainInfo() string {
 	return buffer.String()
 }
 
+// ExplainInfo implements PhysicalPlan interface.
+func (p *PhysicalExternalScan) ExplainInfo() string {
+	tblName := p.Table.Name.O
+	if p.TableAsName != nil && p.TableAsName.O != "" {
+		tblName = p.TableAsName.O
+	}
+	return fmt.Sprintf("table:%s, engine:%s, request:%s", tblName, p.Table.Engine, p.Source.Describe(p.ScanRequest()))
+}
+
 // ExplainInfo implements PhysicalPlan interface.
 func (p *PhysicalTableReader) ExplainInfo() string {
 	return fmt.Sprintf("data:%s", p.tablePlan.ExplainID())
@@ -272,6 +281,10 @@ func (p *PhysicalHashSemiJoin) ExplainInfo() string {
 		buffer.WriteString(fmt.Sprintf(", other cond:%s",
 			expression.ExplainExpressionList(p.OtherConditions)))
 	}
+	if len(p.NullAwareConditions) > 0 {
+		buffer.WriteString(fmt.Sprintf(", null aware cond:%s",
+			expression.ExplainExpressionList(p.NullAwareConditions)))
+	}
 	return buffer.String()
 }
 
@@ -32,11 +32,12 @@ func (s *testExplainSuite) TestExplain(c *C) {
 		testleak.AfterTest(c)()
 	}()
 	tk.MustExec("use test")
-	tk.MustExec("drop table if exists t1, t2, t3")
+	tk.MustExec("drop table if exists t1, t2, t3, t4")
 	tk.MustExec("create table t1 (c1 int primary key, c2 int, c3 int, index c2 (c2))")
 	tk.MustExec("create table t2 (c1 int unique, c2 int)")
 	tk.MustExec("insert into t2 values(1, 0), (2, 1)")
 	tk.MustExec("create table t3 (a bigint, b bigint, c bigint, d bigint)")
+	tk.MustExec("create table t4 (a int primary key, b int, c int, index bc (b desc, c))")
 
 	tests := []struct {
 		sql    string
@@ -220,6 +221,31 @@ func (s *testExplainSuite) TestExplain(c *C) {
 				"Limit_6  TableReader_15 root offset:0, count:1 1",
 			},
 		},
+		{
+			"select b, c from t4 order by b desc, c",
+			[]string{
+				"IndexScan_10   cop table:t4, index:b, c, range:[<nil>,+inf], out of order:false 8000",
+				"IndexReader_11   root index:IndexScan_10 8000",
+			},
+		},
+		{
+			"select b, c from t4 order by b, c desc limit 1",
+			[]string{
+				"IndexScan_14 Limit_15  cop table:t4, index:b, c, range:[<nil>,+inf], out of order:false, desc 1.25",
+				"Limit_15  IndexScan_14 cop offset:0, count:1 1",
+				"IndexReader_16 Limit_6  root index:Limit_15 1",
+				"Limit_6  IndexReader_16 root offset:0, count:1 1",
+			},
+		},
+		{
+			"select b, c from t4 order by b, c limit 1",
+			[]string{
+				"TableScan_7 TopN_5  cop table:t4, range:(-inf,+inf), keep order:false 8000",
+				"TopN_5  TableScan_7 cop  1",
+				"TableReader_8 TopN_5  root data:TopN_5 1",
+				"TopN_5  TableReader_8 root  1",
+			},
+		},
 	}
 	tk.MustExec("set @@session.tidb_opt_insubquery_unfold = 1")
 	for _, tt := range tests {
@@ -238,7 +264,7 @@ func (s *testExplainSuite) TestExplain(c *C) {
 				"TableReader_11 HashSemiJoin_9  root data:TableScan_10 8000",
 				"TableScan_12   cop table:t2, range:(-inf,+inf), keep order:false 8000",
 				"TableReader_13 HashSemiJoin_9  root data:TableScan_12 8000",
-				"HashSemiJoin_9 HashAgg_8 TableReader_11,TableReader_13 root right:TableReader_13, aux, equal:[eq(test.t1.c1, test.t2.c1)] 8000",
+				"HashSemiJoin_9 HashAgg_8 TableReader_11,TableReader_13 root right:TableReader_13, aux, null aware cond:eq(test.t1.c1, test.t2.c1) 8000",
 				"HashAgg_8  HashSemiJoin_9 root type:complete, funcs:sum(5_aux_0) 1",
 			},
 		},
@@ -247,10 +273,9 @@ func (s *testExplainSuite) TestExplain(c *C) {
 			[]string{
 				"TableScan_8   cop table:t1, range:(-inf,+inf), keep order:false 8000",
 				"TableReader_9 HashSemiJoin_7  root data:TableScan_8 8000",
-				"TableScan_10 Selection_11  cop table:t2, range:(-inf,+inf), keep order:false 10",
-				"Selection_11  TableScan_10 cop eq(1, test.t2.c2) 10",
-				"TableReader_12 HashSemiJoin_7  root data:Selection_11 10",
-				"HashSemiJoin_7  TableReader_9,TableReader_12 root right:TableReader_12, aux 8000",
+				"TableScan_10   cop table:t2, range:(-inf,+inf), keep order:false 8000",
+				"TableReader_11 HashSemiJoin_7  root data:TableScan_10 8000",
+				"HashSemiJoin_7  TableReader_9,TableReader_11 root right:TableReader_11, aux, null aware cond:eq(1, test.t2.c2) 8000",
 			},
 		},
 		{
@@ -258,10 +283,9 @@ func (s *testExplainSuite) TestExplain(c *C) {
 			[]string{
 				"TableScan_10   cop table:t1, range:(-inf,+inf), keep order:false 8000",
 				"TableReader_11 HashSemiJoin_9  root data:TableScan_10 8000",
-				"TableScan_12 Selection_13  cop table:t2, range:(-inf,+inf), keep order:false 10",
-				"Selection_13  TableScan_12 cop eq(6, test.t2.c2) 10",
-				"TableReader_14 HashSemiJoin_9  root data:Selection_13 10",
-				"HashSemiJoin_9 HashAgg_8 TableReader_11,TableReader_14 root right:TableReader_14, aux 8000",
+				"TableScan_12   cop table:t2, range:(-inf,+inf), keep order:false 8000",
+				"TableReader_13 HashSemiJoin_9  root data:TableScan_12 8000",
+				"HashSemiJoin_9 HashAgg_8 TableReader_11,TableReader_13 root right:TableReader_13, aux, null aware cond:eq(6, test.t2.c2) 8000",
 				"HashAgg_8  HashSemiJoin_9 root type:complete, funcs:sum(5_aux_0) 1",
 			},
 		},
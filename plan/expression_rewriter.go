@@ -165,6 +165,9 @@ func popRowArg(ctx context.Context, e expression.Expression) (ret expression.Exp
 func (er *expressionRewriter) constructBinaryOpFunction(l expression.Expression, r expression.Expression, op string) (expression.Expression, error) {
 	lLen, rLen := getRowLen(l), getRowLen(r)
 	if lLen == 1 && rLen == 1 {
+		if op == ast.EQ || op == ast.NullEQ {
+			l, r = er.rewriteBinToUUIDCompare(l, r)
+		}
 		return expression.NewFunction(er.ctx, op, types.NewFieldType(mysql.TypeTiny), l, r)
 	} else if rLen != lLen {
 		return nil, ErrOperandColumns.GenByArgs(lLen)
@@ -208,6 +211,59 @@ func (er *expressionRewriter) constructBinaryOpFunction(l expression.Expression,
 	}
 }
 
+// rewriteBinToUUIDCompare rewrites `BIN_TO_UUID(col[, swap_flag]) = 'uuid'` to `col = UUID_TO_BIN('uuid'[, swap_flag])`,
+// so the condition on the binary column can be used to build the ranges of the primary key or the indexes, and
+// a lookup of a UUID by a unique binary column is a point get. It's only rewritten if the string is in the form
+// returned by BIN_TO_UUID, so the two conditions are true for the same rows.
+func (er *expressionRewriter) rewriteBinToUUIDCompare(l, r expression.Expression) (expression.Expression, expression.Expression) {
+	if col, bin := er.binToUUIDCompareToBin(l, r); col != nil {
+		return col, bin
+	}
+	if col, bin := er.binToUUIDCompareToBin(r, l); col != nil {
+		return bin, col
+	}
+	return l, r
+}
+
+// binToUUIDCompareToBin returns the binary column and the binary UUID if the comparison of fn and con can be
+// rewritten by rewriteBinToUUIDCompare, otherwise it returns nil.
+func (er *expressionRewriter) binToUUIDCompareToBin(fn, con expression.Expression) (*expression.Column, *expression.Constant) {
+	sf, ok := fn.(*expression.ScalarFunction)
+	if !ok || sf.FuncName.L != ast.BinToUUID {
+		return nil, nil
+	}
+	args := sf.GetArgs()
+	col, ok := args[0].(*expression.Column)
+	if !ok || !types.IsBinaryStr(col.RetType) {
+		return nil, nil
+	}
+	c, ok := con.(*expression.Constant)
+	if !ok || (c.Value.Kind() != types.KindString && c.Value.Kind() != types.KindBytes) {
+		return nil, nil
+	}
+	swap := false
+	if len(args) == 2 {
+		swapFlag, ok := args[1].(*expression.Constant)
+		if !ok {
+			return nil, nil
+		}
+		val, isNull, err := swapFlag.EvalInt(nil, er.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return nil, nil
+		}
+		swap = !isNull && val != 0
+	}
+	str := c.Value.GetString()
+	bin, ok := expression.UUIDToBin(str, swap)
+	if !ok || expression.BinToUUID(bin, swap) != str {
+		return nil, nil
+	}
+	tp := types.NewFieldType(mysql.TypeVarString)
+	tp.Flen = len(bin)
+	types.SetBinChsClnFlag(tp)
+	return col, &expression.Constant{Value: types.NewBytesDatum(bin), RetType: tp}
+}
+
 func (er *expressionRewriter) buildSubquery(subq *ast.SubqueryExpr) LogicalPlan {
 	outerSchema := er.schema.Clone()
 	er.b.outerSchemas = append(er.b.outerSchemas, outerSchema)
@@ -43,6 +43,8 @@ const (
 	TypeTableScan = "TableScan"
 	// TypeMemTableScan is the type of TableScan.
 	TypeMemTableScan = "MemTableScan"
+	// TypeExternalScan is the type of ExternalScan.
+	TypeExternalScan = "ExternalScan"
 	// TypeUnionScan is the type of UnionScan.
 	TypeUnionScan = "UnionScan"
 	// TypeIdxScan is the type of IndexScan.
@@ -227,6 +229,12 @@ func (p PhysicalMemTable) init(allocator *idAllocator, ctx context.Context) *Phy
 	return &p
 }
 
+func (p PhysicalExternalScan) init(allocator *idAllocator, ctx context.Context) *PhysicalExternalScan {
+	p.basePlan = newBasePlan(TypeExternalScan, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p PhysicalHashJoin) init(allocator *idAllocator, ctx context.Context) *PhysicalHashJoin {
 	tp := TypeHashRightJoin
 	if p.SmallTable == 1 {
@@ -282,7 +290,13 @@ func (p PhysicalIndexLookUpReader) init(allocator *idAllocator, ctx context.Cont
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
 	p.TablePlans = flattenPushDownPlan(p.tablePlan)
 	p.IndexPlans = flattenPushDownPlan(p.indexPlan)
-	p.NeedColHandle = p.IndexPlans[0].(*PhysicalIndexScan).NeedColHandle
+	switch x := p.IndexPlans[0].(type) {
+	case *PhysicalIndexScan:
+		p.NeedColHandle = x.NeedColHandle
+	case *PhysicalTableScan:
+		// The handles are read by a table scan of the filter columns, see DataSource.lateMaterialize.
+		p.NeedColHandle = x.NeedColHandle
+	}
 	p.schema = p.tablePlan.Schema()
 	return &p
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -550,7 +551,7 @@ func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
 	firstSchema := u.children[0].Schema().Clone()
 	for i, sel := range u.children {
 		if firstSchema.Len() != sel.Schema().Len() {
-			b.err = errors.New("The used SELECT statements have a different number of columns")
+			b.err = ErrWrongUnionColumns
 			return nil
 		}
 		if _, ok := sel.(*Projection); !ok {
@@ -1068,7 +1069,7 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		}
 	}
 
-	if sel.LockTp == ast.SelectLockForUpdate {
+	if sel.LockTp.IsForUpdate() {
 		b.needColHandle++
 	}
 
@@ -1153,9 +1154,12 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		if b.err != nil {
 			return nil
 		}
+		if sel.SelectStmtOpts != nil && sel.SelectStmtOpts.CalcFoundRows {
+			p.(*Limit).CalcFoundRows = true
+		}
 	}
 	sel.Fields.Fields = originalFields
-	if sel.LockTp == ast.SelectLockForUpdate {
+	if sel.LockTp.IsForUpdate() {
 		b.needColHandle--
 	}
 	if oldLen != p.Schema().Len() {
@@ -1208,6 +1212,14 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		NeedColHandle:  b.needColHandle > 0,
 	}.init(b.allocator, b.ctx)
 	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, schemaName.L, tableInfo.Name.L, "")
+	if et, ok := tbl.(*engine.Table); ok {
+		// Rows of external tables have no handles.
+		if b.needColHandle > 0 {
+			b.err = engine.ErrUnsupportedOp.GenByArgs(et.Engine().Name(), "UPDATE, DELETE or SELECT FOR UPDATE")
+			return nil
+		}
+		p.externalSource = et.Source()
+	}
 
 	var columns []*table.Column
 	if b.inUpdateStmt {
@@ -1232,7 +1244,8 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 			pkCol = schema.Columns[schema.Len()-1]
 		}
 	}
-	needUnionScan := b.ctx.Txn() != nil && !b.ctx.Txn().IsReadOnly()
+	// Writes to external tables don't go through the transaction, so there is nothing to union.
+	needUnionScan := p.externalSource == nil && b.ctx.Txn() != nil && !b.ctx.Txn().IsReadOnly()
 	if b.needColHandle == 0 && !needUnionScan {
 		p.SetSchema(schema)
 		return b.projectVirtualColumns(p, columns)
@@ -1397,7 +1410,13 @@ func (b *planBuilder) buildSemiJoin(outerPlan, innerPlan LogicalPlan, onConditio
 	joinPlan.SetChildren(outerPlan, innerPlan)
 	outerPlan.SetParents(joinPlan)
 	innerPlan.SetParents(joinPlan)
-	joinPlan.attachOnConds(onCondition)
+	if asScalar || not {
+		// The result of `NOT IN` and the IN subquery used as a scalar differs if the conditions are NULL, they
+		// can't be treated as the ordinary join conditions, whose NULL results are the same as FALSE.
+		joinPlan.NullAwareConditions = onCondition
+	} else {
+		joinPlan.attachOnConds(onCondition)
+	}
 	if asScalar {
 		newSchema := outerPlan.Schema().Clone()
 		newSchema.Append(&expression.Column{
@@ -1427,13 +1446,6 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 
 	var tableList []*ast.TableName
 	tableList = extractTableList(sel.From.TableRefs, tableList)
-	for _, t := range tableList {
-		dbName := t.Schema.L
-		if dbName == "" {
-			dbName = b.ctx.GetSessionVars().CurrentDB
-		}
-		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.UpdatePriv, dbName, t.Name.L, "")
-	}
 
 	if sel.Where != nil {
 		p = b.buildSelection(p, sel.Where, nil)
@@ -1459,10 +1471,33 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	}
 	p = np
 
+	// Only the tables in the SET list need the UPDATE privilege, the others are only read.
+	updatedIDs := extractUpdatedTableIDs(p.Schema(), orderedList)
+	for _, t := range tableList {
+		if _, ok := updatedIDs[t.TableInfo.ID]; !ok {
+			continue
+		}
+		dbName := t.Schema.L
+		if dbName == "" {
+			dbName = b.ctx.GetSessionVars().CurrentDB
+		}
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.UpdatePriv, dbName, t.Name.L, "")
+		b.checkWritesPaused(t)
+		if b.err != nil {
+			return nil
+		}
+	}
+
 	updt := Update{
 		OrderedList: orderedList,
 		IgnoreErr:   update.IgnoreErr,
 	}.init(b.allocator, b.ctx)
+	if update.Returning != nil {
+		updt.Returning, updt.ReturningSchema = b.buildReturning(update.Returning, p.Schema())
+		if b.err != nil {
+			return nil
+		}
+	}
 	addChild(updt, p)
 	updt.SetSchema(p.Schema())
 	return updt
@@ -1470,20 +1505,26 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 
 func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.Assignment, p LogicalPlan) ([]*expression.Assignment, LogicalPlan) {
 	modifyColumns := make(map[string]struct{}, p.Schema().Len()) // Which columns are in set list.
+	modifyTables := make(map[string]struct{}, len(tableList))    // Which tables or aliases are in set list, db.table.
 	for _, assign := range list {
 		col, _, err := p.findColumn(assign.Column)
 		if err != nil {
 			b.err = errors.Trace(err)
 			return nil, nil
 		}
+		if !isBaseTableColumn(p.Schema(), col) {
+			b.err = ErrNonUpdatableTable.GenByArgs(col.TblName.O, "UPDATE")
+			return nil, nil
+		}
 		columnFullName := fmt.Sprintf("%s.%s.%s", col.DBName.L, col.TblName.L, col.ColName.L)
 		modifyColumns[columnFullName] = struct{}{}
+		modifyTables[fmt.Sprintf("%s.%s", col.DBName.L, col.TblName.L)] = struct{}{}
 	}
 
 	// If columns in set list contains generated columns, raise error.
 	// And, fill virtualAssignments here; that's for generated columns.
 	virtualAssignments := make([]*ast.Assignment, 0)
-	tableAsName := make(map[*model.TableInfo][]*model.CIStr)
+	tableAsName := make(map[*model.TableInfo][]*ast.TableName)
 	extractTableAsNameForUpdate(p, tableAsName)
 
 	for _, tn := range tableList {
@@ -1503,8 +1544,12 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 				return nil, nil
 			}
 			for _, asName := range tableAsName[tableInfo] {
+				// The generated columns of the tables which are not updated are kept.
+				if _, ok := modifyTables[fmt.Sprintf("%s.%s", asName.Schema.L, asName.Name.L)]; !ok {
+					continue
+				}
 				virtualAssignments = append(virtualAssignments, &ast.Assignment{
-					Column: &ast.ColumnName{Table: *asName, Name: colInfo.Name},
+					Column: &ast.ColumnName{Schema: asName.Schema, Table: asName.Name, Name: colInfo.Name},
 					Expr:   table.Cols()[i].GeneratedExpr,
 				})
 			}
@@ -1548,26 +1593,46 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 	return newList, p
 }
 
-// extractTableAsNameForUpdate extracts tables' alias names for update.
-func extractTableAsNameForUpdate(p Plan, asNames map[*model.TableInfo][]*model.CIStr) {
+// isBaseTableColumn checks if the column belongs to a base table, the columns of the derived tables can't be updated.
+func isBaseTableColumn(schema *expression.Schema, col *expression.Column) bool {
+	for _, handleCols := range schema.TblID2Handle {
+		for _, handleCol := range handleCols {
+			if col.DBName.L == handleCol.DBName.L && col.TblName.L == handleCol.TblName.L {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractUpdatedTableIDs returns the IDs of the tables updated by the assignments of an UPDATE statement.
+func extractUpdatedTableIDs(schema *expression.Schema, list []*expression.Assignment) map[int64]struct{} {
+	ids := make(map[int64]struct{}, len(schema.TblID2Handle))
+	for id, handleCols := range schema.TblID2Handle {
+		for _, handleCol := range handleCols {
+			for _, assign := range list {
+				if assign.Col.DBName.L == handleCol.DBName.L && assign.Col.TblName.L == handleCol.TblName.L {
+					ids[id] = struct{}{}
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// extractTableAsNameForUpdate extracts tables' alias names for update, the database names are kept as they are in the
+// columns, i.e. they're empty for the aliases.
+func extractTableAsNameForUpdate(p Plan, asNames map[*model.TableInfo][]*ast.TableName) {
 	switch x := p.(type) {
 	case *DataSource:
-		alias := extractTableAlias(p.(LogicalPlan))
-		if alias != nil {
-			if _, ok := asNames[x.tableInfo]; !ok {
-				asNames[x.tableInfo] = make([]*model.CIStr, 0, 1)
-			}
-			asNames[x.tableInfo] = append(asNames[x.tableInfo], alias)
+		if asName := extractTableAsNameWithDB(x); asName != nil {
+			asNames[x.tableInfo] = append(asNames[x.tableInfo], asName)
 		}
 	case *Projection:
 		if x.calculateGenCols {
 			ds := x.Children()[0].(*DataSource)
-			alias := extractTableAlias(x)
-			if alias != nil {
-				if _, ok := asNames[ds.tableInfo]; !ok {
-					asNames[ds.tableInfo] = make([]*model.CIStr, 0, 1)
-				}
-				asNames[ds.tableInfo] = append(asNames[ds.tableInfo], alias)
+			if asName := extractTableAsNameWithDB(x); asName != nil {
+				asNames[ds.tableInfo] = append(asNames[ds.tableInfo], asName)
 			}
 		}
 	default:
@@ -1577,6 +1642,15 @@ func extractTableAsNameForUpdate(p Plan, asNames map[*model.TableInfo][]*model.C
 	}
 }
 
+// extractTableAsNameWithDB returns the alias of the table like extractTableAlias, with the database name of its columns.
+func extractTableAsNameWithDB(p LogicalPlan) *ast.TableName {
+	alias := extractTableAlias(p)
+	if alias == nil || p.Schema().Len() == 0 {
+		return nil
+	}
+	return &ast.TableName{Schema: p.Schema().Columns[0].DBName, Name: *alias}
+}
+
 func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	b.needColHandle++
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: delete.TableRefs, Where: delete.Where, OrderBy: delete.Order, Limit: delete.Limit}
@@ -1613,6 +1687,12 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 		Tables:       tables,
 		IsMultiTable: delete.IsMultiTable,
 	}.init(b.allocator, b.ctx)
+	if delete.Returning != nil {
+		del.Returning, del.ReturningSchema = b.buildReturning(delete.Returning, p.Schema())
+		if b.err != nil {
+			return nil
+		}
+	}
 	addChild(del, p)
 	del.SetSchema(expression.NewSchema())
 
@@ -1621,6 +1701,10 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 		// Delete a, b from a, b, c, d... add a and b.
 		for _, table := range delete.Tables.Tables {
 			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DeletePriv, table.Schema.L, table.TableInfo.Name.L, "")
+			b.checkWritesPaused(table)
+			if b.err != nil {
+				return nil
+			}
 		}
 	} else {
 		// Delete from a, b, c, d.
@@ -1632,12 +1716,44 @@ func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 				dbName = b.ctx.GetSessionVars().CurrentDB
 			}
 			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DeletePriv, dbName, v.Name.L, "")
+			b.checkWritesPaused(v)
+			if b.err != nil {
+				return nil
+			}
 		}
 	}
 
 	return del
 }
 
+// buildReturning builds the RETURNING list of an INSERT, REPLACE, UPDATE or DELETE statement, the expressions
+// are evaluated on the written rows, whose schema is rowSchema.
+func (b *planBuilder) buildReturning(returning *ast.FieldList, rowSchema *expression.Schema) ([]expression.Expression, *expression.Schema) {
+	mockTablePlan := TableDual{}.init(b.allocator, b.ctx)
+	mockTablePlan.SetSchema(rowSchema)
+	fields := b.unfoldWildStar(mockTablePlan, returning.Fields)
+	if b.err != nil {
+		return nil, nil
+	}
+	exprs := make([]expression.Expression, 0, len(fields))
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(fields))...)
+	for _, field := range fields {
+		expr, np, err := b.rewrite(field.Expr, mockTablePlan, nil, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil, nil
+		}
+		// The written rows are not a plan, so the correlated subqueries can't be applied on them.
+		if np != mockTablePlan {
+			b.err = ErrReturningSubquery
+			return nil, nil
+		}
+		exprs = append(exprs, expr)
+		schema.Append(b.buildProjectionField(mockTablePlan.id, schema.Len()+1, field, expr))
+	}
+	return exprs, schema
+}
+
 func extractTableList(node ast.ResultSetNode, input []*ast.TableName) []*ast.TableName {
 	switch x := node.(type) {
 	case *ast.Join:
@@ -1651,6 +1767,16 @@ func extractTableList(node ast.ResultSetNode, input []*ast.TableName) []*ast.Tab
 	return input
 }
 
+// checkWritesPaused sets the error if the writes to the table are paused by ADMIN PAUSE WRITES, so the DML statements
+// fail before reading any row. The pause of the tidb-server doesn't apply to the tables in the system database, which
+// are written by the internal sessions.
+func (b *planBuilder) checkWritesPaused(tn *ast.TableName) {
+	dbName := tn.DBInfo.Name
+	if tn.TableInfo.WritesPaused || (InstanceWritesPaused() && dbName.L != mysql.SystemDB) {
+		b.err = ErrWritesPaused.GenByArgs(dbName.O, tn.TableInfo.Name.O)
+	}
+}
+
 func appendVisitInfo(vi []visitInfo, priv mysql.PrivilegeType, db, tbl, col string) []visitInfo {
 	return append(vi, visitInfo{
 		privilege: priv,
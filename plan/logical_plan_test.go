@@ -468,7 +468,7 @@ func (s *testPlanSuite) TestPredicatePushDown(c *C) {
 		},
 		{
 			sql:  "select * from t ta left outer join t tb on ta.d = tb.d and ta.a > 1 where ta.d = 0",
-			best: "Join{DataScan(ta)->Selection->DataScan(tb)}(ta.d,tb.d)->Projection",
+			best: "Join{DataScan(ta)->Selection->DataScan(tb)->Selection}(ta.d,tb.d)->Projection",
 		},
 		{
 			sql:  "select * from t ta left outer join t tb on ta.d = tb.d and ta.a > 1 where tb.d = 0",
@@ -557,8 +557,33 @@ func (s *testPlanSuite) TestPredicatePushDown(c *C) {
 		// issue #3873
 		{
 			sql:  "select t1.a, t2.a from t as t1 left join t as t2 on t1.a = t2.a where t1.a < 1.0",
+			best: "Join{DataScan(t1)->Selection->DataScan(t2)->Selection}(t1.a,t2.a)->Projection",
+		},
+		// The conditions on the outer side are propagated to the inner side through the join keys.
+		{
+			sql:  "select * from t t1 left join t t2 on t1.a = t2.a and t1.b = 1 where t1.c = 2",
 			best: "Join{DataScan(t1)->Selection->DataScan(t2)}(t1.a,t2.a)->Projection",
 		},
+		{
+			sql:  "select * from t t1 left join t t2 on t1.a = t2.a and t1.a = 1",
+			best: "Join{DataScan(t1)->DataScan(t2)->Selection}(t1.a,t2.a)->Projection",
+		},
+		{
+			sql:  "select * from t t1 right join t t2 on t1.a = t2.a where t2.a > 1",
+			best: "Join{DataScan(t1)->Selection->DataScan(t2)->Selection}(t1.a,t2.a)->Projection",
+		},
+		{
+			sql:  "select * from t t1 left join t t2 on t1.a = t2.a and t2.a = 1",
+			best: "Join{DataScan(t1)->DataScan(t2)->Selection}(t1.a,t2.a)->Projection",
+		},
+		{
+			sql:  "select a from t where a in (select a from t x) and a = 1",
+			best: "Join{DataScan(t)->Selection->DataScan(x)->Selection->Projection}(test.t.a,a)->Projection",
+		},
+		{
+			sql:  "select a from t where a not in (select a from t x) and a = 1",
+			best: "Join{DataScan(t)->Selection->DataScan(x)->Projection}->Projection",
+		},
 	}
 	for _, ca := range tests {
 		comment := Commentf("for %s", ca.sql)
@@ -1420,6 +1445,25 @@ func (s *testPlanSuite) TestVisitInfo(c *C) {
 				{mysql.IndexPriv, "test", "", ""},
 			},
 		},
+		{
+			sql: `rename table t to t1, t1 to t2`,
+			ans: []visitInfo{
+				{mysql.AlterPriv, "test", "t", ""},
+				{mysql.DropPriv, "test", "t", ""},
+				{mysql.CreatePriv, "test", "t1", ""},
+				{mysql.InsertPriv, "test", "t1", ""},
+				{mysql.AlterPriv, "test", "t1", ""},
+				{mysql.DropPriv, "test", "t1", ""},
+				{mysql.CreatePriv, "test", "t2", ""},
+				{mysql.InsertPriv, "test", "t2", ""},
+			},
+		},
+		{
+			sql: `admin cancel ddl jobs 1, 2`,
+			ans: []visitInfo{
+				{mysql.SuperPriv, "", "", ""},
+			},
+		},
 		{
 			sql: `grant select on test.ttt to 'test'@'%'`,
 			ans: []visitInfo{
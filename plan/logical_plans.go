@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -96,6 +97,11 @@ type LogicalJoin struct {
 	LeftConditions  expression.CNFExprs
 	RightConditions expression.CNFExprs
 	OtherConditions expression.CNFExprs
+	// NullAwareConditions are the conditions of an IN subquery whose result may be NULL, they're only used by
+	// `NOT IN` and the IN subquery used as a scalar, e.g. `select a in (select b from t) from s`. They're
+	// evaluated in the three-valued logic, so a row is NULL rather than FALSE if it doesn't match any row but
+	// the conditions are NULL for some rows.
+	NullAwareConditions expression.CNFExprs
 
 	LeftJoinKeys    []*expression.Column
 	RightJoinKeys   []*expression.Column
@@ -124,6 +130,9 @@ func (p *LogicalJoin) columnSubstitute(schema *expression.Schema, exprs []expres
 	for i, fun := range p.OtherConditions {
 		p.OtherConditions[i] = expression.ColumnSubstitute(fun, schema, exprs)
 	}
+	for i, fun := range p.NullAwareConditions {
+		p.NullAwareConditions[i] = expression.ColumnSubstitute(fun, schema, exprs)
+	}
 }
 
 func (p *LogicalJoin) attachOnConds(onConds []expression.Expression) {
@@ -148,6 +157,9 @@ func (p *LogicalJoin) extractCorrelatedCols() []*expression.CorrelatedColumn {
 	for _, fun := range p.OtherConditions {
 		corCols = append(corCols, extractCorColumns(fun)...)
 	}
+	for _, fun := range p.NullAwareConditions {
+		corCols = append(corCols, extractCorColumns(fun)...)
+	}
 	return corCols
 }
 
@@ -184,6 +196,8 @@ type LogicalAggregation struct {
 	groupByCols []*expression.Column
 
 	possibleProperties [][]*expression.Column
+	// inputCount is the estimated row count of the child.
+	inputCount float64
 }
 
 func (p *LogicalAggregation) extractCorrelatedCols() []*expression.CorrelatedColumn {
@@ -294,6 +308,9 @@ type DataSource struct {
 
 	// This is schema the PhysicalUnionScan should be.
 	unionScanSchema *expression.Schema
+
+	// externalSource is set if the table is stored by an external engine.
+	externalSource engine.Source
 }
 
 func (p *DataSource) getPKIsHandleCol() *expression.Column {
@@ -376,6 +393,9 @@ type Limit struct {
 
 	// partial is true if this topn is generated by push-down optimization.
 	partial bool
+	// CalcFoundRows is true if the rows found without the limit are counted for "SQL_CALC_FOUND_ROWS",
+	// then the limit can't be pushed down.
+	CalcFoundRows bool
 
 	expectedProp *requiredProp
 }
@@ -388,6 +408,10 @@ type Update struct {
 
 	OrderedList []*expression.Assignment
 	IgnoreErr   bool
+
+	// Returning is the RETURNING list evaluated on the updated rows, ReturningSchema is the schema of its result.
+	Returning       []expression.Expression
+	ReturningSchema *expression.Schema
 }
 
 // Delete represents a delete plan.
@@ -398,6 +422,10 @@ type Delete struct {
 
 	Tables       []*ast.TableName
 	IsMultiTable bool
+
+	// Returning is the RETURNING list evaluated on the deleted rows, ReturningSchema is the schema of its result.
+	Returning       []expression.Expression
+	ReturningSchema *expression.Schema
 }
 
 // AddChild for parent.
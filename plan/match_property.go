@@ -123,21 +123,25 @@ func (is *PhysicalIndexScan) matchProperty(prop *requiredProperty, infos ...*phy
 	}
 	matchedIdx := 0
 	matchedList := make([]bool, len(prop.props))
+	// matchedDesc is whether the index columns matched by the properties are descending.
+	matchedDesc := make([]bool, len(prop.props))
 	for i, idxCol := range is.Index.Columns {
 		if idxCol.Length != types.UnspecifiedLength {
 			break
 		}
 		if idx := matchPropColumn(prop, matchedIdx, idxCol); idx >= 0 {
 			matchedList[idx] = true
+			matchedDesc[idx] = idxCol.Desc
 			matchedIdx++
 		} else if i >= is.accessEqualCount {
 			break
 		}
 	}
 	if allMatch(matchedList) {
+		// allDesc and allAsc are whether the index is scanned in the reverse or the forward order.
 		allDesc, allAsc := true, true
 		for i := 0; i < prop.sortKeyLen; i++ {
-			if prop.props[i].desc {
+			if prop.props[i].desc != matchedDesc[i] {
 				allAsc = false
 			} else {
 				allDesc = false
@@ -0,0 +1,138 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// memo holds the search space of the cascades optimizer. The logically equivalent expressions are in a group,
+// an expression is a logical operator whose children are groups, so a group of n expressions with children
+// groups of m expressions each represents n*m plan trees without enumerating them.
+type memo struct {
+	groups []*group
+	// exprs maps the fingerprints of the expressions to themselves, an expression is only added once.
+	exprs map[string]*groupExpr
+}
+
+func newMemo() *memo {
+	return &memo{exprs: make(map[string]*groupExpr)}
+}
+
+// group is a set of the logically equivalent expressions, they have the same output schema.
+type group struct {
+	id          int
+	equivalents []*groupExpr
+	explored    bool
+
+	// implMap caches the best tasks of the group for the required properties.
+	implMap map[string]task
+	// implementing marks the required properties being implemented. The groups may reference each other, like a
+	// projection over the commuted join is in the group of the join, the reference back to a group being
+	// implemented is skipped.
+	implementing map[string]bool
+}
+
+// representative returns the first expression of the group, it's the child of the expressions of the parent
+// groups in the plan tree.
+func (g *group) representative() LogicalPlan {
+	return g.equivalents[0].node
+}
+
+// groupExpr is a logical operator in the memo. The children of the operator in the plan tree are the representatives
+// of its children groups, so the methods of the logical plans, like generatePhysicalPlans and prepareStatsProfile,
+// work on it like on a plan tree.
+type groupExpr struct {
+	node     LogicalPlan
+	children []*group
+	group    *group
+	explored bool
+}
+
+// fingerprint identifies the expression in the memo. The joins are identified by their conditions, so a join
+// commuted twice is the same expression as the original one. The other operators are only created from the plan
+// tree, they are identified by their ids.
+func (e *groupExpr) fingerprint() string {
+	var buf bytes.Buffer
+	switch x := e.node.(type) {
+	case *LogicalJoin:
+		fmt.Fprintf(&buf, "Join(%s,%d,%v,%s,%s,%s,%s)", x.JoinType, x.preferINLJ, x.anti, x.EqualConditions,
+			x.LeftConditions, x.RightConditions, x.OtherConditions)
+	default:
+		buf.WriteString(e.node.ExplainID())
+	}
+	for _, child := range e.children {
+		fmt.Fprintf(&buf, ",%d", child.id)
+	}
+	return buf.String()
+}
+
+func (m *memo) newGroup() *group {
+	g := &group{
+		id:           len(m.groups),
+		implMap:      make(map[string]task),
+		implementing: make(map[string]bool),
+	}
+	m.groups = append(m.groups, g)
+	return g
+}
+
+// convert2Group puts the plan tree into the memo, every operator is in a new group.
+func (m *memo) convert2Group(p LogicalPlan) *group {
+	children := make([]*group, 0, len(p.Children()))
+	for _, child := range p.Children() {
+		children = append(children, m.convert2Group(child.(LogicalPlan)))
+	}
+	g := m.newGroup()
+	m.insert(g, &groupExpr{node: p, children: children})
+	return g
+}
+
+// insert adds the expression to the group, it returns false if the expression is already in the memo.
+func (m *memo) insert(g *group, e *groupExpr) bool {
+	key := e.fingerprint()
+	if _, ok := m.exprs[key]; ok {
+		return false
+	}
+	m.exprs[key] = e
+	e.group = g
+	g.equivalents = append(g.equivalents, e)
+	return true
+}
+
+// groupOf returns the group of the new operator created by a transformation rule. The operator is added to a new
+// group if it isn't in the memo, its children in the plan tree are set to the representatives of the groups and
+// its properties and stats are prepared.
+func (m *memo) groupOf(p LogicalPlan, children []*group) *group {
+	e := &groupExpr{node: p, children: children}
+	if old, ok := m.exprs[e.fingerprint()]; ok {
+		return old.group
+	}
+	prepareGroupExpr(e)
+	g := m.newGroup()
+	m.insert(g, e)
+	return g
+}
+
+// prepareGroupExpr sets the children of the operator of a new expression and prepares its properties and stats.
+func prepareGroupExpr(e *groupExpr) {
+	children := make([]Plan, 0, len(e.children))
+	for _, child := range e.children {
+		children = append(children, child.representative())
+	}
+	e.node.SetChildren(children...)
+	e.node.preparePossibleProperties()
+	e.node.prepareStatsProfile()
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -45,8 +46,8 @@ func (p *requiredProp) enforceProperty(task task, ctx context.Context, allocator
 	}
 	task = finishCopTask(task, ctx, allocator)
 	sort := Sort{ByItems: make([]*ByItems, 0, len(p.cols))}.init(allocator, ctx)
-	for _, col := range p.cols {
-		sort.ByItems = append(sort.ByItems, &ByItems{col, p.desc})
+	for i, col := range p.cols {
+		sort.ByItems = append(sort.ByItems, &ByItems{col, p.isDesc(i)})
 	}
 	sort.SetSchema(task.plan().Schema())
 	sort.profile = task.plan().statsProfile()
@@ -60,7 +61,8 @@ func (p *Projection) getChildrenPossibleProps(prop *requiredProp) [][]*requiredP
 	p.expectedCnt = prop.expectedCnt
 	newProp := &requiredProp{taskTp: rootTaskType, expectedCnt: prop.expectedCnt}
 	newCols := make([]*expression.Column, 0, len(prop.cols))
-	for _, col := range prop.cols {
+	newDesc := make([]bool, 0, len(prop.cols))
+	for i, col := range prop.cols {
 		idx := p.schema.ColumnIndex(col)
 		if idx == -1 {
 			return nil
@@ -68,12 +70,13 @@ func (p *Projection) getChildrenPossibleProps(prop *requiredProp) [][]*requiredP
 		switch expr := p.Exprs[idx].(type) {
 		case *expression.Column:
 			newCols = append(newCols, expr)
+			newDesc = append(newDesc, prop.isDesc(i))
 		case *expression.ScalarFunction:
 			return nil
 		}
 	}
 	newProp.cols = newCols
-	newProp.desc = prop.desc
+	newProp.desc = newDesc
 	return [][]*requiredProp{{newProp}}
 }
 
@@ -209,9 +212,6 @@ func (p *PhysicalMergeJoin) getChildrenPossibleProps(prop *requiredProp) [][]*re
 	lProp := &requiredProp{taskTp: rootTaskType, cols: p.leftKeys, expectedCnt: math.MaxFloat64}
 	rProp := &requiredProp{taskTp: rootTaskType, cols: p.rightKeys, expectedCnt: math.MaxFloat64}
 	if !prop.isEmpty() {
-		if prop.desc {
-			return nil
-		}
 		if !prop.equal(lProp) && !prop.equal(rProp) {
 			return nil
 		}
@@ -383,13 +383,14 @@ func (p *LogicalJoin) getMergeJoin() []PhysicalPlan {
 
 func (p *LogicalJoin) getSemiJoin() PhysicalPlan {
 	semiJoin := PhysicalHashSemiJoin{
-		WithAux:         LeftOuterSemiJoin == p.JoinType,
-		EqualConditions: p.EqualConditions,
-		LeftConditions:  p.LeftConditions,
-		RightConditions: p.RightConditions,
-		OtherConditions: p.OtherConditions,
-		Anti:            p.anti,
-		rightChOffset:   p.children[0].Schema().Len(),
+		WithAux:             LeftOuterSemiJoin == p.JoinType,
+		EqualConditions:     p.EqualConditions,
+		LeftConditions:      p.LeftConditions,
+		RightConditions:     p.RightConditions,
+		OtherConditions:     p.OtherConditions,
+		NullAwareConditions: p.NullAwareConditions,
+		Anti:                p.anti,
+		rightChOffset:       p.children[0].Schema().Len(),
 	}.init(p.allocator, p.ctx)
 	semiJoin.SetSchema(p.schema)
 	semiJoin.profile = p.profile
@@ -413,20 +414,17 @@ func (p *LogicalJoin) getHashJoin(smallTable int) PhysicalPlan {
 }
 
 // getPropByOrderByItems will check if this sort property can be pushed or not. In order to simplify the problem, we only
-// consider the case that all expression are columns and all of them are asc or desc.
+// consider the case that all expression are columns.
 func getPropByOrderByItems(items []*ByItems) (*requiredProp, bool) {
-	desc := false
 	cols := make([]*expression.Column, 0, len(items))
-	for i, item := range items {
+	desc := make([]bool, 0, len(items))
+	for _, item := range items {
 		col, ok := item.Expr.(*expression.Column)
 		if !ok {
 			return nil, false
 		}
 		cols = append(cols, col)
-		desc = item.Desc
-		if i > 0 && item.Desc != items[i-1].Desc {
-			return nil, false
-		}
+		desc = append(desc, item.Desc)
 	}
 	return &requiredProp{cols: cols, desc: desc}, true
 }
@@ -469,6 +467,10 @@ func (p *Sort) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 		return nil, errors.Trace(err)
 	}
 	t = p.attach2Task(t)
+	trace := getOptimizerTrace(p.ctx)
+	if trace != nil {
+		trace.appendCandidate(p, prop, t)
+	}
 	newProp, canPassProp := getPropByOrderByItems(p.ByItems)
 	if canPassProp {
 		newProp.expectedCnt = prop.expectedCnt
@@ -476,6 +478,9 @@ func (p *Sort) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if trace != nil {
+			trace.appendCandidate(p, prop, orderedTask)
+		}
 		if orderedTask.cost() < t.cost() {
 			t = orderedTask
 		}
@@ -513,7 +518,8 @@ func (p *baseLogicalPlan) convert2NewPhysicalPlan(prop *requiredProp) (t task, e
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		if prop.isEmpty() {
+		// An empty prop needs no enforcer, but its expected count may still let the children stop early.
+		if prop.isEmpty() && prop.expectedCnt == math.MaxFloat64 {
 			continue
 		}
 		t, err = p.getBestTask(t, prop, pp, false)
@@ -545,6 +551,9 @@ func (p *baseLogicalPlan) getBestTask(bestTask task, prop *requiredProp, pp Phys
 		if enforced {
 			resultTask = prop.enforceProperty(resultTask, p.basePlan.ctx, p.basePlan.allocator)
 		}
+		if trace := getOptimizerTrace(p.basePlan.ctx); trace != nil {
+			trace.appendCandidate(p.basePlan.self, prop, resultTask)
+		}
 		if resultTask.cost() < bestTask.cost() {
 			bestTask = resultTask
 		}
@@ -595,6 +604,49 @@ func (p *DataSource) tryToGetMemTask(prop *requiredProp) (task task, err error)
 	return task, nil
 }
 
+// tryToGetExternalTask will check if this table is stored by an external engine. If it is, the conditions
+// accepted by the engine are pushed down to the scan and the others are evaluated by a selection on top of it.
+func (p *DataSource) tryToGetExternalTask(prop *requiredProp) (task, error) {
+	if p.externalSource == nil {
+		return nil, nil
+	}
+	scan, remained := p.buildExternalScan(p.pushedDownConds)
+	scan.profile = p.profile
+	var retPlan PhysicalPlan = scan
+	if len(remained) > 0 {
+		sel := Selection{
+			Conditions: remained,
+		}.init(p.allocator, p.ctx)
+		sel.SetSchema(p.schema)
+		sel.SetChildren(scan)
+		sel.profile = p.profile
+		retPlan = sel
+	}
+	var t task = &rootTask{p: retPlan}
+	t = prop.enforceProperty(t, p.ctx, p.allocator)
+	return t, nil
+}
+
+// buildExternalScan builds the scan of an external table and pushes down the conditions that the engine accepts.
+// It returns the remaining conditions.
+func (p *DataSource) buildExternalScan(conds []expression.Expression) (*PhysicalExternalScan, []expression.Expression) {
+	scan := PhysicalExternalScan{
+		DBName:      p.DBName,
+		Table:       p.tableInfo,
+		Columns:     p.Columns,
+		TableAsName: p.TableAsName,
+		Source:      p.externalSource,
+	}.init(p.allocator, p.ctx)
+	scan.SetSchema(p.schema)
+	scan.columnSchema = p.schema
+	if len(conds) == 0 || !p.externalSource.Capabilities().Has(engine.CapPushDownFilter) {
+		return scan, conds
+	}
+	var remained []expression.Expression
+	scan.Conditions, remained = p.externalSource.PushDown(conds)
+	return scan, remained
+}
+
 // tryToGetDualTask will check if the push down predicate has false constant. If so, it will return table dual.
 func (p *DataSource) tryToGetDualTask() (task, error) {
 	for _, cond := range p.pushedDownConds {
@@ -634,6 +686,14 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 		p.storeTask(prop, t)
 		return t, nil
 	}
+	t, err = p.tryToGetExternalTask(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if t != nil {
+		p.storeTask(prop, t)
+		return t, nil
+	}
 	t, err = p.tryToGetMemTask(prop)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -644,12 +704,16 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 	}
 	// TODO: We have not checked if this table has a predicate. If not, we can only consider table scan.
 	indices, includeTableScan := availableIndices(p.indexHints, p.tableInfo)
+	trace := getOptimizerTrace(p.ctx)
 	t = invalidTask
 	if includeTableScan {
 		t, err = p.convertToTableScan(prop)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if trace != nil {
+			trace.appendCandidate(p, prop, t)
+		}
 	}
 	if !includeTableScan || len(p.pushedDownConds) > 0 || len(prop.cols) > 0 {
 		for _, idx := range indices {
@@ -657,6 +721,9 @@ func (p *DataSource) convert2NewPhysicalPlan(prop *requiredProp) (task, error) {
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
+			if trace != nil {
+				trace.appendCandidate(p, prop, idxTask)
+			}
 			if idxTask.cost() < t.cost() {
 				t = idxTask
 			}
@@ -772,11 +839,13 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	is.SetSchema(expression.NewSchema(indexCols...))
 	// Check if this plan matches the property.
 	matchProperty := false
+	// reverse is whether the index is scanned in the reverse order to match the property.
+	reverse := false
 	if !prop.isEmpty() {
 		for i, col := range idx.Columns {
 			// not matched
 			if col.Name.L == prop.cols[0].ColName.L {
-				matchProperty = matchIndicesProp(idx.Columns[i:], prop.cols)
+				matchProperty, reverse = matchIndicesProp(idx.Columns[i:], prop)
 				break
 			} else if i >= len(is.AccessCondition) {
 				break
@@ -797,7 +866,7 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	cop.cst = rowCount * scanFactor
 	task = cop
 	if matchProperty {
-		if prop.desc {
+		if reverse {
 			is.Desc = true
 			cop.cst = rowCount * descScanFactor
 		}
@@ -858,16 +927,25 @@ func (is *PhysicalIndexScan) addPushedDownSelection(copTask *copTask, p *DataSou
 	}
 }
 
-func matchIndicesProp(idxCols []*model.IndexColumn, propCols []*expression.Column) bool {
-	if len(idxCols) < len(propCols) {
-		return false
-	}
-	for i, col := range propCols {
+// matchIndicesProp checks if the index columns are in the order of the property, and returns whether the index is
+// scanned in the reverse order for it. The direction of each column must be the same as the property's, or all of
+// them must be the opposite, e.g. INDEX(b DESC, c) serves ORDER BY b DESC, c and ORDER BY b, c DESC.
+func matchIndicesProp(idxCols []*model.IndexColumn, prop *requiredProp) (matched bool, reverse bool) {
+	if len(idxCols) < len(prop.cols) {
+		return false, false
+	}
+	for i, col := range prop.cols {
 		if idxCols[i].Length != types.UnspecifiedLength || col.ColName.L != idxCols[i].Name.L {
-			return false
+			return false, false
+		}
+		colReverse := prop.isDesc(i) != idxCols[i].Desc
+		if i == 0 {
+			reverse = colReverse
+		} else if colReverse != reverse {
+			return false, false
 		}
 	}
-	return true
+	return true, reverse
 }
 
 func (p *DataSource) forceToTableScan() PhysicalPlan {
@@ -955,7 +1033,7 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task task, err erro
 	ts.expectedCnt = rowCount
 	copTask.cst = rowCount * scanFactor
 	if matchProperty {
-		if prop.desc {
+		if prop.isDesc(0) {
 			ts.Desc = true
 			copTask.cst = rowCount * descScanFactor
 		}
@@ -973,6 +1051,10 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task task, err erro
 		ts.addPushedDownSelection(copTask, p.profile, expectedCnt)
 		if p.unionScanSchema != nil {
 			task = addUnionScan(copTask, p)
+		} else if p.ctx.GetSessionVars().AllowLateMaterialization && prop.isEmpty() && prop.taskTp != copSingleReadTaskType {
+			if lateTask := p.lateMaterialize(ts, rowCount, expectedCnt, copTask.cost()); lateTask != nil {
+				task = lateTask
+			}
 		}
 		task = prop.enforceProperty(task, p.ctx, p.allocator)
 	}
@@ -984,6 +1066,68 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task task, err erro
 	return task, nil
 }
 
+// lateMaterialize converts the table scan with filter conditions to a double read, the first read scans only the
+// columns of the filter conditions and returns the handles of the rows that pass the filter, the second read fetches
+// the other columns by the handles. It saves reading the wide rows that are filtered out, so it's cheaper when the
+// filter is selective and needs few columns. It returns nil if it isn't cheaper than the table scan of cost cst.
+func (p *DataSource) lateMaterialize(ts *PhysicalTableScan, rowCount, expectedCnt, cst float64) *copTask {
+	if len(ts.filterCondition) == 0 {
+		return nil
+	}
+	var filterCols []*expression.Column
+	for _, cond := range ts.filterCondition {
+		filterCols = append(filterCols, expression.ExtractColumns(cond)...)
+	}
+	var (
+		columns []*model.ColumnInfo
+		cols    []*expression.Column
+	)
+	for i, col := range ts.Columns {
+		schemaCol := ts.schema.Columns[i]
+		for _, filterCol := range filterCols {
+			if filterCol.Equal(schemaCol, nil) {
+				columns = append(columns, col)
+				cols = append(cols, schemaCol)
+				break
+			}
+		}
+	}
+	if len(columns) == 0 || len(columns) >= len(ts.Columns) {
+		return nil
+	}
+	// The scan cost is in proportion to the columns read, the handle is counted as a column. The rows that pass the
+	// filter are read twice.
+	widthRatio := float64(len(columns)+1) / float64(len(ts.Columns)+1)
+	selCount := math.Min(p.profile.count, expectedCnt)
+	lateCst := rowCount*scanFactor*widthRatio + selCount*cpuFactor
+	if lateCst+selCount*(netWorkFactor+scanFactor) >= cst {
+		return nil
+	}
+	filterScan := PhysicalTableScan{
+		Table:               ts.Table,
+		Columns:             columns,
+		TableAsName:         ts.TableAsName,
+		DBName:              ts.DBName,
+		Ranges:              ts.Ranges,
+		physicalTableSource: physicalTableSource{NeedColHandle: ts.NeedColHandle, AccessCondition: ts.AccessCondition},
+	}.init(p.allocator, p.ctx)
+	filterScan.SetSchema(expression.NewSchema(cols...))
+	filterScan.profile = ts.profile
+	filterScan.expectedCnt = ts.expectedCnt
+	sel := Selection{Conditions: ts.filterCondition}.init(p.allocator, p.ctx)
+	sel.SetSchema(filterScan.schema)
+	sel.SetChildren(filterScan)
+	sel.profile = p.profile
+	sel.expectedCnt = expectedCnt
+	tableScan := PhysicalTableScan{Columns: ts.Columns, Table: ts.Table}.init(p.allocator, p.ctx)
+	tableScan.SetSchema(ts.schema)
+	return &copTask{
+		indexPlan: sel,
+		tablePlan: tableScan,
+		cst:       lateCst,
+	}
+}
+
 func (ts *PhysicalTableScan) addPushedDownSelection(copTask *copTask, profile *statsProfile, expectedCnt float64) {
 	// Add filter condition to table plan now.
 	if len(ts.filterCondition) > 0 {
@@ -1091,6 +1235,7 @@ func (p *TopN) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 }
 
 func (p *LogicalAggregation) generatePhysicalPlans() []PhysicalPlan {
+	aggs := p.getStreamAggs()
 	ha := PhysicalAggregation{
 		GroupByItems: p.GroupByItems,
 		AggFuncs:     p.AggFuncs,
@@ -1099,7 +1244,41 @@ func (p *LogicalAggregation) generatePhysicalPlans() []PhysicalPlan {
 	}.init(p.allocator, p.ctx)
 	ha.SetSchema(p.schema)
 	ha.profile = p.profile
-	return []PhysicalPlan{ha}
+	return append(aggs, ha)
+}
+
+// getStreamAggs generates a stream aggregation for every order the child can provide without sorting, e.g. the order
+// of an index, whose prefix covers all the group by columns. The rows of a group are adjacent in such an order, so
+// the duplicates of a DISTINCT are removed without building a hash table.
+func (p *LogicalAggregation) getStreamAggs() []PhysicalPlan {
+	if len(p.groupByCols) == 0 || len(p.groupByCols) != len(p.GroupByItems) {
+		// group by a + b is not interested in any order.
+		return nil
+	}
+	for _, aggFunc := range p.AggFuncs {
+		if aggFunc.GetMode() == expression.FinalMode {
+			return nil
+		}
+	}
+	aggs := make([]PhysicalPlan, 0, len(p.possibleProperties)+1)
+	for _, cols := range p.possibleProperties {
+		_, keys := getPermutation(cols, p.groupByCols)
+		if len(keys) != len(p.groupByCols) {
+			continue
+		}
+		sa := PhysicalAggregation{
+			GroupByItems: p.GroupByItems,
+			AggFuncs:     p.AggFuncs,
+			HasGby:       true,
+			AggType:      StreamedAgg,
+			propKeys:     keys,
+			inputCount:   p.inputCount,
+		}.init(p.allocator, p.ctx)
+		sa.SetSchema(p.schema)
+		sa.profile = p.profile
+		aggs = append(aggs, sa)
+	}
+	return aggs
 }
 
 func (p *PhysicalAggregation) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
@@ -1107,6 +1286,14 @@ func (p *PhysicalAggregation) getChildrenPossibleProps(prop *requiredProp) [][]*
 	if !prop.isEmpty() {
 		return nil
 	}
+	if p.AggType == StreamedAgg {
+		// The stream aggregation can stop reading when enough groups are produced, e.g. SELECT DISTINCT ... LIMIT.
+		expectedCnt := math.MaxFloat64
+		if prop.expectedCnt < math.MaxFloat64 && p.profile.count > 0 {
+			expectedCnt = prop.expectedCnt * p.inputCount / p.profile.count
+		}
+		return [][]*requiredProp{{{taskTp: rootTaskType, cols: p.propKeys, expectedCnt: expectedCnt}}}
+	}
 	props := make([][]*requiredProp, 0, len(wholeTaskTypes))
 	for _, tp := range wholeTaskTypes {
 		props = append(props, []*requiredProp{{taskTp: tp, expectedCnt: math.MaxFloat64}})
@@ -15,6 +15,7 @@ package plan
 
 import (
 	"math"
+	"sync/atomic"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -29,6 +30,23 @@ import (
 // AllowCartesianProduct means whether tidb allows cartesian join without equal conditions.
 var AllowCartesianProduct = true
 
+// instanceWritesPaused is set by ADMIN PAUSE WRITES, the DML statements on the user tables fail on the tidb-server.
+var instanceWritesPaused int32
+
+// PauseInstanceWrites pauses or resumes the writes to all the user tables on the tidb-server.
+func PauseInstanceWrites(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&instanceWritesPaused, v)
+}
+
+// InstanceWritesPaused returns whether the writes to the user tables on the tidb-server are paused.
+func InstanceWritesPaused() bool {
+	return atomic.LoadInt32(&instanceWritesPaused) == 1
+}
+
 const (
 	flagPrunColumns uint64 = 1 << iota
 	flagEliminateProjection
@@ -52,11 +70,17 @@ var optRuleList = []logicalOptRule{
 // logicalOptRule means a logical optimizing rule, which contains decorrelate, ppd, column pruning, etc.
 type logicalOptRule interface {
 	optimize(LogicalPlan, context.Context, *idAllocator) (LogicalPlan, error)
+	// name is the name of the rule in the optimizer trace.
+	name() string
 }
 
 // Optimize does optimization and creates a Plan.
 // The node must be prepared first.
 func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, error) {
+	// The EXECUTE statement is traced when the prepared statement is optimized.
+	if _, ok := node.(*ast.ExecuteStmt); !ok {
+		defer startOptimizerTrace(ctx)()
+	}
 	// We have to infer type again because after parameter is set, the expression type may change.
 	if err := expression.InferType(ctx.GetSessionVars().StmtCtx, node); err != nil {
 		return nil, errors.Trace(err)
@@ -116,6 +140,10 @@ func checkPrivilege(pm privilege.Manager, vs []visitInfo) bool {
 }
 
 func doOptimize(flag uint64, logic LogicalPlan, ctx context.Context, allocator *idAllocator) (PhysicalPlan, error) {
+	trace := getOptimizerTrace(ctx)
+	if trace != nil {
+		trace.LogicalPlan = ToString(logic)
+	}
 	logic, err := logicalOptimize(flag, logic, ctx, allocator)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -124,7 +152,9 @@ func doOptimize(flag uint64, logic LogicalPlan, ctx context.Context, allocator *
 		return nil, errors.Trace(ErrCartesianProductUnsupported)
 	}
 	var physical PhysicalPlan
-	if UseDAGPlanBuilder(ctx) {
+	if UseDAGPlanBuilder(ctx) && ctx.GetSessionVars().EnableCascadesPlanner {
+		physical, err = newCascadesOptimizer().findBestPlan(logic)
+	} else if UseDAGPlanBuilder(ctx) {
 		physical, err = dagPhysicalOptimize(logic)
 	} else {
 		physical, err = physicalOptimize(flag, logic, allocator)
@@ -133,11 +163,15 @@ func doOptimize(flag uint64, logic LogicalPlan, ctx context.Context, allocator *
 		return nil, errors.Trace(err)
 	}
 	finalPlan := eliminatePhysicalProjection(physical)
+	if trace != nil {
+		trace.FinalPlan = ToString(finalPlan)
+	}
 	return finalPlan, nil
 }
 
 func logicalOptimize(flag uint64, logic LogicalPlan, ctx context.Context, alloc *idAllocator) (LogicalPlan, error) {
 	var err error
+	trace := getOptimizerTrace(ctx)
 	for i, rule := range optRuleList {
 		// The order of flags is same as the order of optRule in the list.
 		// We use a bitmask to record which opt rules should be used. If the i-th bit is 1, it means we should
@@ -149,6 +183,9 @@ func logicalOptimize(flag uint64, logic LogicalPlan, ctx context.Context, alloc
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if trace != nil {
+			trace.appendRule(rule.name(), logic)
+		}
 	}
 	return logic, errors.Trace(err)
 }
@@ -160,6 +197,9 @@ func dagPhysicalOptimize(logic LogicalPlan) (PhysicalPlan, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if trace := getOptimizerTrace(logic.context()); trace != nil {
+		trace.FinalCost = t.cost()
+	}
 	p := t.plan()
 	rebuildSchema(p)
 	p.ResolveIndices()
@@ -198,7 +238,7 @@ func PrepareStmt(is infoschema.InfoSchema, ctx context.Context, node ast.Node) e
 	if err := Preprocess(node, is, ctx); err != nil {
 		return errors.Trace(err)
 	}
-	if err := Validate(node, true); err != nil {
+	if err := Validate(node, true, ctx); err != nil {
 		return errors.Trace(err)
 	}
 	return nil
@@ -221,6 +261,7 @@ var (
 	ErrOperandColumns              = terror.ClassOptimizer.New(CodeOperandColumns, "Operand should contain %d column(s)")
 	ErrInvalidWildCard             = terror.ClassOptimizer.New(CodeInvalidWildCard, "Wildcard fields without any table name appears in wrong place")
 	ErrCartesianProductUnsupported = terror.ClassOptimizer.New(CodeUnsupported, "Cartesian product is unsupported")
+	ErrReturningSubquery           = terror.ClassOptimizer.New(CodeUnsupported, "Subquery in the RETURNING clause is unsupported")
 	ErrInvalidGroupFuncUse         = terror.ClassOptimizer.New(CodeInvalidGroupFuncUse, "Invalid use of group function")
 	ErrIllegalReference            = terror.ClassOptimizer.New(CodeIllegalReference, "Illegal reference")
 	ErrNoDB                        = terror.ClassOptimizer.New(CodeNoDB, "No database selected")
@@ -230,6 +271,7 @@ func init() {
 	mySQLErrCodes := map[terror.ErrCode]uint16{
 		CodeOperandColumns:      mysql.ErrOperandColumns,
 		CodeInvalidWildCard:     mysql.ErrParse,
+		CodeUnsupported:         mysql.ErrNotSupportedYet,
 		CodeInvalidGroupFuncUse: mysql.ErrInvalidGroupFuncUse,
 		CodeIllegalReference:    mysql.ErrIllegalReference,
 		CodeNoDB:                mysql.ErrNoDB,
@@ -0,0 +1,104 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"encoding/json"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pingcap/tidb/context"
+)
+
+// optimizerTrace records how a statement is optimized, it's enabled for the next statement by tidb_trace_optimizer,
+// and read from tidb_last_optimizer_trace as JSON. The optimizations of the subqueries and the explained statement
+// are recorded in the trace of the statement.
+type optimizerTrace struct {
+	// LogicalPlan is the logical plan built from the statement before the rules are applied.
+	LogicalPlan string `json:"logical_plan,omitempty"`
+	// Rules are the applied logical rules and the plans after each rule.
+	Rules []*ruleTrace `json:"rules,omitempty"`
+	// Candidates are the physical plans considered for the operators by the cost-based optimizer.
+	Candidates []*candidateTrace `json:"candidates,omitempty"`
+	FinalPlan  string            `json:"final_plan,omitempty"`
+	FinalCost  float64           `json:"final_cost,omitempty"`
+}
+
+type ruleTrace struct {
+	Rule string `json:"rule"`
+	Plan string `json:"plan"`
+}
+
+type candidateTrace struct {
+	// Operator is the logical operator the candidate is built for.
+	Operator string `json:"operator"`
+	// Prop is the property required by the parent, e.g. the order and the task type.
+	Prop  string  `json:"prop"`
+	Plan  string  `json:"plan"`
+	Count float64 `json:"count"`
+	Cost  float64 `json:"cost"`
+}
+
+// optimizerTraceKeyType is a dummy type to avoid naming collision in context.
+type optimizerTraceKeyType int
+
+// String defines a Stringer function for debugging and pretty printing.
+func (k optimizerTraceKeyType) String() string {
+	return "optimizer_trace"
+}
+
+const optimizerTraceKey optimizerTraceKeyType = 0
+
+// getOptimizerTrace returns the trace of the statement being optimized, it returns nil if it isn't traced.
+func getOptimizerTrace(ctx context.Context) *optimizerTrace {
+	trace, _ := ctx.Value(optimizerTraceKey).(*optimizerTrace)
+	return trace
+}
+
+// startOptimizerTrace starts tracing the optimization if tidb_trace_optimizer is set, it only applies to one
+// statement. The returned function saves the trace to the session when the optimization is done.
+func startOptimizerTrace(ctx context.Context) func() {
+	vars := ctx.GetSessionVars()
+	if !vars.TraceOptimizer {
+		return func() {}
+	}
+	vars.TraceOptimizer = false
+	trace := &optimizerTrace{}
+	ctx.SetValue(optimizerTraceKey, trace)
+	return func() {
+		ctx.ClearValue(optimizerTraceKey)
+		data, err := json.Marshal(trace)
+		if err != nil {
+			log.Warnf("[%d] encode the optimizer trace error: %v", vars.ConnectionID, err)
+			return
+		}
+		vars.LastOptimizerTrace = string(data)
+	}
+}
+
+func (t *optimizerTrace) appendRule(rule string, p LogicalPlan) {
+	t.Rules = append(t.Rules, &ruleTrace{Rule: rule, Plan: ToString(p)})
+}
+
+func (t *optimizerTrace) appendCandidate(operator Plan, prop *requiredProp, candidate task) {
+	if candidate.invalid() {
+		return
+	}
+	t.Candidates = append(t.Candidates, &candidateTrace{
+		Operator: operator.ExplainID(),
+		Prop:     prop.String(),
+		Plan:     ToString(candidate.plan()),
+		Count:    candidate.count(),
+		Cost:     candidate.cost(),
+	})
+}
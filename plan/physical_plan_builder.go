@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -187,7 +188,7 @@ func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexCol
 		}
 		isIndexColumn := false
 		for _, indexCol := range indexColumns {
-			if colInfo.Name.L == indexCol.Name.L && indexCol.Length == types.UnspecifiedLength {
+			if colInfo.Name.L == indexCol.Name.L && (indexCol.Length == types.UnspecifiedLength || isFullLengthPrefix(colInfo, indexCol.Length)) {
 				isIndexColumn = true
 				break
 			}
@@ -199,6 +200,24 @@ func isCoveringIndex(columns []*model.ColumnInfo, indexColumns []*model.IndexCol
 	return true
 }
 
+// isFullLengthPrefix checks if the index prefix of prefixLen bytes is long enough to keep every value of the column,
+// then the values read from the index are not truncated.
+func isFullLengthPrefix(colInfo *model.ColumnInfo, prefixLen int) bool {
+	switch colInfo.Tp {
+	case mysql.TypeString, mysql.TypeVarchar, mysql.TypeVarString:
+	default:
+		return false
+	}
+	if colInfo.Flen == types.UnspecifiedLength {
+		return false
+	}
+	desc, err := charset.GetCharsetDesc(colInfo.Charset)
+	if err != nil {
+		return false
+	}
+	return prefixLen >= colInfo.Flen*desc.Maxlen
+}
+
 func (p *DataSource) need2ConsiderIndex(prop *requiredProperty) bool {
 	if len(p.parents) == 0 {
 		return len(prop.props) > 0
@@ -226,6 +245,14 @@ func (p *DataSource) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlan
 	if info != nil || err != nil {
 		return info, errors.Trace(err)
 	}
+	if p.externalSource != nil {
+		// The selection above is kept, so no conditions are pushed down to the engine here.
+		scan, _ := p.buildExternalScan(nil)
+		info = &physicalPlanInfo{p: scan}
+		info = enforceProperty(prop, info)
+		p.storePlanInfo(prop, info)
+		return info, nil
+	}
 	client := p.ctx.GetClient()
 	memDB := infoschema.IsMemoryDB(p.DBName.L)
 	isDistReq := !memDB && client != nil && client.IsRequestTypeSupported(kv.ReqTypeSelect, 0)
@@ -391,9 +418,20 @@ func (p *Limit) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo,
 	if info != nil {
 		return info, nil
 	}
-	info, err = p.children[0].(LogicalPlan).convert2PhysicalPlan(limitProperty(&Limit{Offset: p.Offset, Count: p.Count}))
-	if err != nil {
-		return nil, errors.Trace(err)
+	if p.CalcFoundRows {
+		// All the rows should be read to count the found rows, so the limit isn't pushed down as a property.
+		info, err = p.children[0].(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		limit := Limit{Offset: p.Offset, Count: p.Count, CalcFoundRows: true}.init(p.allocator, p.ctx)
+		limit.SetSchema(info.p.Schema())
+		info = addPlanToResponse(limit, info)
+	} else {
+		info, err = p.children[0].(LogicalPlan).convert2PhysicalPlan(limitProperty(&Limit{Offset: p.Offset, Count: p.Count}))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 	}
 	info = enforceProperty(prop, info)
 	p.storePlanInfo(prop, info)
@@ -411,12 +449,13 @@ func (p *LogicalJoin) convert2PhysicalPlanSemi(prop *requiredProperty) (*physica
 		}
 	}
 	join := PhysicalHashSemiJoin{
-		WithAux:         LeftOuterSemiJoin == p.JoinType,
-		EqualConditions: p.EqualConditions,
-		LeftConditions:  p.LeftConditions,
-		RightConditions: p.RightConditions,
-		OtherConditions: p.OtherConditions,
-		Anti:            p.anti,
+		WithAux:             LeftOuterSemiJoin == p.JoinType,
+		EqualConditions:     p.EqualConditions,
+		LeftConditions:      p.LeftConditions,
+		RightConditions:     p.RightConditions,
+		OtherConditions:     p.OtherConditions,
+		NullAwareConditions: p.NullAwareConditions,
+		Anti:                p.anti,
 	}.init(p.allocator, p.ctx)
 	join.SetSchema(p.schema)
 	lProp := prop
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/testleak"
@@ -704,15 +705,27 @@ func (s *testPlanSuite) TestCoveringIndex(c *C) {
 		{[]string{"a", "b"}, []string{"a", "b"}, []int{50, -1}, false},
 		{[]string{"a", "b"}, []string{"a", "c"}, []int{-1, -1}, false},
 		{[]string{"id", "a"}, []string{"a", "b"}, []int{-1, -1}, true},
+		// The column "c" is varchar(10) of utf8, and "d" is varbinary(10).
+		{[]string{"c"}, []string{"c"}, []int{30}, true},
+		{[]string{"c"}, []string{"c"}, []int{29}, false},
+		{[]string{"d"}, []string{"d"}, []int{10}, true},
+		{[]string{"d"}, []string{"d"}, []int{9}, false},
 	}
 	for _, tt := range tests {
 		var columns []*model.ColumnInfo
 		var pkIsHandle bool
 		for _, cn := range tt.columnNames {
 			col := &model.ColumnInfo{Name: model.NewCIStr(cn)}
-			if cn == "id" {
+			switch cn {
+			case "id":
 				pkIsHandle = true
 				col.Flag = mysql.PriKeyFlag
+			case "c":
+				col.FieldType = *types.NewFieldType(mysql.TypeVarchar)
+				col.Flen, col.Charset = 10, charset.CharsetUTF8
+			case "d":
+				col.FieldType = *types.NewFieldType(mysql.TypeVarchar)
+				col.Flen, col.Charset = 10, charset.CharsetBin
 			}
 			columns = append(columns, col)
 		}
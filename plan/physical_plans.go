@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
@@ -190,6 +191,50 @@ func (p *PhysicalMemTable) Copy() PhysicalPlan {
 	return &np
 }
 
+// PhysicalExternalScan reads a table stored by an external engine.
+type PhysicalExternalScan struct {
+	*basePlan
+	basePhysicalPlan
+
+	DBName      model.CIStr
+	Table       *model.TableInfo
+	Columns     []*model.ColumnInfo
+	TableAsName *model.CIStr
+
+	// Source is where the rows come from.
+	Source engine.Source
+	// Conditions are the filters pushed down to the source.
+	Conditions []expression.Expression
+	// Limit is the row limit pushed down to the source, zero means no limit.
+	Limit uint64
+	// AggFuncs and GroupBy are the aggregation pushed down to the source.
+	// The schema of the scan is the one of the partial aggregation if they are set.
+	AggFuncs []expression.AggregationFunction
+	GroupBy  []expression.Expression
+
+	// columnSchema is the schema of the table columns, which the pushed down expressions refer to.
+	columnSchema *expression.Schema
+}
+
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalExternalScan) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// ScanRequest returns the request sent to the source.
+func (p *PhysicalExternalScan) ScanRequest() *engine.ScanRequest {
+	return &engine.ScanRequest{
+		Columns:    p.Columns,
+		Conditions: p.Conditions,
+		Limit:      p.Limit,
+		AggFuncs:   p.AggFuncs,
+		GroupBy:    p.GroupBy,
+	}
+}
+
 // physicalDistSQLPlan means the plan that can be executed distributively.
 // We can push down other plan like selection, limit, aggregation, topN into this plan.
 type physicalDistSQLPlan interface {
@@ -541,6 +586,8 @@ type PhysicalHashSemiJoin struct {
 	LeftConditions  []expression.Expression
 	RightConditions []expression.Expression
 	OtherConditions []expression.Expression
+	// NullAwareConditions are evaluated in the three-valued logic, see LogicalJoin.NullAwareConditions.
+	NullAwareConditions []expression.Expression
 
 	rightChOffset int
 }
@@ -579,6 +626,11 @@ type PhysicalAggregation struct {
 	AggType      AggregationType
 	AggFuncs     []expression.AggregationFunction
 	GroupByItems []expression.Expression
+
+	// propKeys are the columns the child of a stream aggregation is required to be sorted by.
+	propKeys []*expression.Column
+	// inputCount is the estimated row count of the child.
+	inputCount float64
 }
 
 // PhysicalUnionScan represents a union scan operator.
@@ -665,6 +717,9 @@ func (p *PhysicalHashSemiJoin) extractCorrelatedCols() []*expression.CorrelatedC
 	for _, fun := range p.OtherConditions {
 		corCols = append(corCols, extractCorColumns(fun)...)
 	}
+	for _, fun := range p.NullAwareConditions {
+		corCols = append(corCols, extractCorColumns(fun)...)
+	}
 	return corCols
 }
 
@@ -762,6 +817,15 @@ func (p *PhysicalMemTable) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalExternalScan) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	buffer.WriteString(fmt.Sprintf(
+		" \"db\": \"%s\",\n \"table\": \"%s\",\n \"engine\": \"%s\",\n \"request\": %q}",
+		p.DBName.O, p.Table.Name.O, p.Table.Engine, p.Source.Describe(p.ScanRequest())))
+	return buffer.Bytes(), nil
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *PhysicalApply) Copy() PhysicalPlan {
 	np := *p
@@ -827,6 +891,10 @@ func (p *PhysicalHashSemiJoin) MarshalJSON() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	nullAwareConds, err := json.Marshal(p.NullAwareConditions)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	buffer := bytes.NewBufferString("{")
 	buffer.WriteString(fmt.Sprintf(
 		"\"with aux\": %v,"+
@@ -835,10 +903,11 @@ func (p *PhysicalHashSemiJoin) MarshalJSON() ([]byte, error) {
 			"\"leftCond\": %s,\n "+
 			"\"rightCond\": %s,\n "+
 			"\"otherCond\": %s,\n"+
+			"\"nullAwareCond\": %s,\n"+
 			"\"leftPlan\": \"%s\",\n "+
 			"\"rightPlan\": \"%s\""+
 			"}",
-		p.WithAux, p.Anti, eqConds, leftConds, rightConds, otherConds, leftChild.ExplainID(), rightChild.ExplainID()))
+		p.WithAux, p.Anti, eqConds, leftConds, rightConds, otherConds, nullAwareConds, leftChild.ExplainID(), rightChild.ExplainID()))
 	return buffer.Bytes(), nil
 }
 
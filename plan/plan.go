@@ -110,10 +110,11 @@ func (t taskType) String() string {
 }
 
 // requiredProp stands for the required physical property by parents.
-// It contains the orders, if the order is desc and the task types.
+// It contains the orders, if the order of each column is desc and the task types.
 type requiredProp struct {
 	cols []*expression.Column
-	desc bool
+	// desc is whether each column of cols is in the descending order, the columns are ascending if it's nil.
+	desc []bool
 	// taskTp means the type of task that an operator requires.
 	// It needs to be specified because two different tasks can't be compared with cost directly.
 	// e.g. If a copTask takes less cost than a rootTask, we can't sure that we must choose the former one. Because the copTask
@@ -127,20 +128,25 @@ type requiredProp struct {
 }
 
 func (p *requiredProp) equal(prop *requiredProp) bool {
-	if len(p.cols) != len(prop.cols) || p.desc != prop.desc {
+	if len(p.cols) != len(prop.cols) {
 		return false
 	}
 	if p.taskTp != prop.taskTp {
 		return false
 	}
 	for i := range p.cols {
-		if !p.cols[i].Equal(prop.cols[i], nil) {
+		if !p.cols[i].Equal(prop.cols[i], nil) || p.isDesc(i) != prop.isDesc(i) {
 			return false
 		}
 	}
 	return true
 }
 
+// isDesc returns whether the ith column of the property is in the descending order.
+func (p *requiredProp) isDesc(i int) bool {
+	return i < len(p.desc) && p.desc[i]
+}
+
 func (p *requiredProp) isEmpty() bool {
 	return len(p.cols) == 0
 }
@@ -150,16 +156,16 @@ func (p *requiredProp) hashCode() []byte {
 	if p.hashcode != nil {
 		return p.hashcode
 	}
-	hashcodeSize := 8 + 8 + 8 + 16*len(p.cols)
+	hashcodeSize := 8 + 8 + 24*len(p.cols)
 	p.hashcode = make([]byte, 0, hashcodeSize)
-	if p.desc {
-		p.hashcode = codec.EncodeInt(p.hashcode, 1)
-	} else {
-		p.hashcode = codec.EncodeInt(p.hashcode, 0)
-	}
 	p.hashcode = codec.EncodeInt(p.hashcode, int64(p.taskTp))
 	p.hashcode = codec.EncodeFloat(p.hashcode, p.expectedCnt)
 	for i, length := 0, len(p.cols); i < length; i++ {
+		if p.isDesc(i) {
+			p.hashcode = codec.EncodeInt(p.hashcode, 1)
+		} else {
+			p.hashcode = codec.EncodeInt(p.hashcode, 0)
+		}
 		p.hashcode = append(p.hashcode, p.cols[i].HashCode()...)
 	}
 	return p.hashcode
@@ -286,6 +292,9 @@ type PhysicalPlan interface {
 
 	// statsProfile will return the stats for this plan.
 	statsProfile() *statsProfile
+
+	// StatsCount returns the estimated row count of this plan.
+	StatsCount() float64
 }
 
 type baseLogicalPlan struct {
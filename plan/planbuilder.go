@@ -40,6 +40,13 @@ var (
 	ErrAnalyzeMissIndex     = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
 	ErrAlterAutoID          = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
 	ErrBadGeneratedColumn   = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrNonUniqTable         = terror.ClassOptimizerPlan.New(CodeNonUniqTable, mysql.MySQLErrName[mysql.ErrNonuniqTable])
+	ErrWrongUnionColumns    = terror.ClassOptimizerPlan.New(CodeWrongUnionColumns, mysql.MySQLErrName[mysql.ErrWrongNumberOfColumnsInSelect])
+	ErrNonUpdatableTable    = terror.ClassOptimizerPlan.New(CodeNonUpdatableTable, mysql.MySQLErrName[mysql.ErrNonUpdatableTable])
+	ErrWrongUsage           = terror.ClassOptimizerPlan.New(CodeWrongUsage, mysql.MySQLErrName[mysql.ErrWrongUsage])
+	ErrInvalidSplitOption   = terror.ClassOptimizerPlan.New(CodeInvalidSplitOption, "The lower bound must be less than the upper bound, and the number of regions must be between 1 and the number of handles between them")
+	ErrWritesPaused         = terror.ClassOptimizerPlan.New(CodeWritesPaused, "Writes to table '%s.%s' are paused")
+	ErrDBaccessDenied       = terror.ClassOptimizerPlan.New(CodeDBaccessDenied, mysql.MySQLErrName[mysql.ErrDBaccessDenied])
 )
 
 // Error codes.
@@ -48,11 +55,18 @@ const (
 	SystemInternalError                   = 2
 	CodeAlterAutoID                       = 3
 	CodeAnalyzeMissIndex                  = 4
+	CodeInvalidSplitOption                = 5
+	CodeWritesPaused                      = 6
 	CodeAmbiguous                         = 1052
 	CodeUnknownColumn                     = mysql.ErrBadField
 	CodeUnknownTable                      = mysql.ErrBadTable
 	CodeWrongArguments                    = 1210
 	CodeBadGeneratedColumn                = mysql.ErrBadGeneratedColumn
+	CodeNonUniqTable                      = mysql.ErrNonuniqTable
+	CodeWrongUnionColumns                 = mysql.ErrWrongNumberOfColumnsInSelect
+	CodeNonUpdatableTable                 = mysql.ErrNonUpdatableTable
+	CodeWrongUsage                        = mysql.ErrWrongUsage
+	CodeDBaccessDenied                    = mysql.ErrDBaccessDenied
 )
 
 func init() {
@@ -62,6 +76,12 @@ func init() {
 		CodeAmbiguous:          mysql.ErrNonUniq,
 		CodeWrongArguments:     mysql.ErrWrongArguments,
 		CodeBadGeneratedColumn: mysql.ErrBadGeneratedColumn,
+		CodeNonUniqTable:       mysql.ErrNonuniqTable,
+		CodeWrongUnionColumns:  mysql.ErrWrongNumberOfColumnsInSelect,
+		CodeNonUpdatableTable:  mysql.ErrNonUpdatableTable,
+		CodeWrongUsage:         mysql.ErrWrongUsage,
+		CodeDBaccessDenied:     mysql.ErrDBaccessDenied,
+		CodeWritesPaused:       mysql.ErrOptionPreventsStatement,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -146,6 +166,8 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildExecute(x)
 	case *ast.ExplainStmt:
 		return b.buildExplain(x)
+	case *ast.PlanReplayerStmt:
+		return b.buildPlanReplayer(x)
 	case *ast.InsertStmt:
 		return b.buildInsert(x)
 	case *ast.LoadDataStmt:
@@ -168,7 +190,9 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildAnalyze(x)
 	case *ast.BinlogStmt, *ast.FlushStmt, *ast.UseStmt,
 		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt,
-		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt:
+		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt,
+		*ast.CreateStatisticsStmt, *ast.DropStatisticsStmt, *ast.LoadStatsStmt, *ast.LockStatsStmt, *ast.UnlockStatsStmt,
+		*ast.SetSessionStatesStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(x)
@@ -371,6 +395,56 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 	case ast.AdminShowDDLJobs:
 		p = &ShowDDLJobs{}
 		p.SetSchema(buildShowDDLJobsFields())
+	case ast.AdminReloadConfig:
+		p = &ReloadConfig{}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminReloadRewriteRules:
+		p = &ReloadRewriteRules{}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminCancelDDLJobs:
+		p = &CancelDDLJobs{JobIDs: as.JobIDs}
+		p.SetSchema(buildUpdateDDLJobsFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminPauseDDLJobs:
+		p = &PauseDDLJobs{JobIDs: as.JobIDs}
+		p.SetSchema(buildUpdateDDLJobsFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminResumeDDLJobs:
+		p = &ResumeDDLJobs{JobIDs: as.JobIDs}
+		p.SetSchema(buildUpdateDDLJobsFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminSplitTable:
+		opt := as.Split
+		// The distance is computed in uint64 so it doesn't overflow.
+		if opt.Num < 1 || opt.Lower >= opt.Upper || uint64(opt.Upper)-uint64(opt.Lower) < uint64(opt.Num) {
+			b.err = ErrInvalidSplitOption
+			return nil
+		}
+		p = &SplitTable{TableInfo: as.Tables[0].TableInfo, Lower: opt.Lower, Upper: opt.Upper, Num: opt.Num}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminScatterTable:
+		p = &ScatterTable{TableInfo: as.Tables[0].TableInfo}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminPauseWrites, ast.AdminResumeWrites:
+		pw := &PauseWrites{Paused: as.Tp == ast.AdminPauseWrites}
+		if len(as.Tables) > 0 {
+			pw.Table = as.Tables[0]
+		}
+		p = pw
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminPinSnapshot:
+		p = &PinSnapshot{TS: as.Pin.TS, TTL: as.Pin.TTL}
+		p.SetSchema(buildPinSnapshotFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminUnpinSnapshot:
+		p = &UnpinSnapshot{TS: as.Pin.TS}
+		p.SetSchema(expression.NewSchema())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
 	default:
 		b.err = ErrUnsupportedType.Gen("Unsupported type %T", as)
 	}
@@ -422,12 +496,22 @@ func (b *planBuilder) buildAnalyzeTable(as *ast.AnalyzeTableStmt) Plan {
 	p := &Analyze{}
 	for _, tbl := range as.TableNames {
 		idxInfo, colInfo, pkInfo := getColsInfo(tbl)
+		if as.NumSamples > 0 {
+			p.FastTasks = append(p.FastTasks, AnalyzeFastTask{
+				TableInfo:   tbl.TableInfo,
+				PKInfo:      pkInfo,
+				ColsInfo:    colInfo,
+				IndicesInfo: idxInfo,
+				NumSamples:  as.NumSamples,
+			})
+			continue
+		}
 		for _, idx := range idxInfo {
 			p.IdxTasks = append(p.IdxTasks, AnalyzeIndexTask{TableInfo: tbl.TableInfo, IndexInfo: idx})
 		}
-		if len(colInfo) > 0 || pkInfo != nil {
-			p.ColTasks = append(p.ColTasks, AnalyzeColumnsTask{TableInfo: tbl.TableInfo, PKInfo: pkInfo, ColsInfo: colInfo})
-		}
+		// The column task is added even if there is no column to analyze, it also analyzes the column groups created
+		// by CREATE STATISTICS. The executor builder drops it if there is nothing to analyze.
+		p.ColTasks = append(p.ColTasks, AnalyzeColumnsTask{TableInfo: tbl.TableInfo, PKInfo: pkInfo, ColsInfo: colInfo})
 	}
 	p.SetSchema(&expression.Schema{})
 	return p
@@ -473,6 +557,23 @@ func buildShowDDLJobsFields() *expression.Schema {
 	return schema
 }
 
+// buildUpdateDDLJobsFields builds the result fields of cancelling, pausing or resuming DDL jobs.
+func buildUpdateDDLJobsFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema.Append(buildColumn("", "JOB_ID", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "RESULT", mysql.TypeVarchar, 128))
+
+	return schema
+}
+
+func buildPinSnapshotFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema.Append(buildColumn("", "SNAPSHOT_TS", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "EXPIRE_TIME", mysql.TypeVarchar, 64))
+
+	return schema
+}
+
 func buildColumn(tableName, name string, tp byte, size int) *expression.Column {
 	cs, cl := types.DefaultCharsetForType(tp)
 	flag := mysql.UnsignedFlag
@@ -520,13 +621,14 @@ func splitWhere(where ast.ExprNode) []ast.ExprNode {
 func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 	var resultPlan Plan
 	p := Show{
-		Tp:     show.Tp,
-		DBName: show.DBName,
-		Table:  show.Table,
-		Column: show.Column,
-		Flag:   show.Flag,
-		Full:   show.Full,
-		User:   show.User,
+		Tp:           show.Tp,
+		DBName:       show.DBName,
+		Table:        show.Table,
+		Column:       show.Column,
+		Flag:         show.Flag,
+		Full:         show.Full,
+		User:         show.User,
+		ConnectionID: show.ConnectionID,
 	}.init(b.allocator, b.ctx)
 	resultPlan = p
 	switch show.Tp {
@@ -621,7 +723,7 @@ func collectVisitInfoFromGrantStmt(vi []visitInfo, stmt *ast.GrantStmt) []visitI
 }
 
 func (b *planBuilder) getDefaultValue(col *table.Column) (*expression.Constant, error) {
-	value, err := table.GetColDefaultValue(b.ctx, col.ToInfo())
+	value, err := col.GetDefaultValue(b.ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -705,6 +807,10 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		db:        tn.DBInfo.Name.L,
 		table:     tableInfo.Name.L,
 	})
+	b.checkWritesPaused(tn)
+	if b.err != nil {
+		return nil
+	}
 
 	columnByName := make(map[string]*table.Column, len(insertPlan.Table.Cols()))
 	for _, col := range insertPlan.Table.Cols() {
@@ -852,6 +958,7 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 			}
 		}
 		addChild(insertPlan, selectPlan)
+		insertPlan.SelectFromTable = readsTable(selectPlan, tableInfo.ID)
 	}
 
 	// Calculate generated columns.
@@ -859,10 +966,29 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 	if b.err != nil {
 		return nil
 	}
+	if insert.Returning != nil {
+		insertPlan.Returning, insertPlan.ReturningSchema = b.buildReturning(insert.Returning, schema)
+		if b.err != nil {
+			return nil
+		}
+	}
 	insertPlan.SetSchema(expression.NewSchema())
 	return insertPlan
 }
 
+// readsTable checks if the plan reads the table.
+func readsTable(p Plan, tableID int64) bool {
+	if ds, ok := p.(*DataSource); ok && ds.tableInfo.ID == tableID {
+		return true
+	}
+	for _, child := range p.Children() {
+		if readsTable(child, tableID) {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 	p := &LoadData{
 		IsLocal:    ld.IsLocal,
@@ -879,6 +1005,10 @@ func (b *planBuilder) buildLoadData(ld *ast.LoadDataStmt) Plan {
 		b.err = infoschema.ErrTableNotExists.GenByArgs(db, tableInfo.Name.O)
 		return nil
 	}
+	b.checkWritesPaused(p.Table)
+	if b.err != nil {
+		return nil
+	}
 	schema := expression.TableInfo2Schema(tableInfo)
 	mockTablePlan := TableDual{}.init(b.allocator, b.ctx)
 	mockTablePlan.SetSchema(schema)
@@ -919,6 +1049,14 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 				table:     v.ReferTable.Name.L,
 			})
 		}
+		if v.Select != nil {
+			// The privileges of the query are checked when it's planned.
+			b.visitInfo = append(b.visitInfo, visitInfo{
+				privilege: mysql.InsertPriv,
+				db:        v.Table.Schema.L,
+				table:     v.Table.Name.L,
+			})
+		}
 	case *ast.DropDatabaseStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.DropPriv,
@@ -945,16 +1083,14 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 			table:     v.Table.Name.L,
 		})
 	case *ast.RenameTableStmt:
-		b.visitInfo = append(b.visitInfo, visitInfo{
-			privilege: mysql.AlterPriv,
-			db:        v.OldTable.Schema.L,
-			table:     v.OldTable.Name.L,
-		})
-		b.visitInfo = append(b.visitInfo, visitInfo{
-			privilege: mysql.AlterPriv,
-			db:        v.NewTable.Schema.L,
-			table:     v.NewTable.Name.L,
-		})
+		// Renaming needs the ALTER and DROP privileges on the old table,
+		// and the CREATE and INSERT privileges on the new table.
+		for _, tt := range v.TableToTables {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.AlterPriv, tt.OldTable.Schema.L, tt.OldTable.Name.L, "")
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DropPriv, tt.OldTable.Schema.L, tt.OldTable.Name.L, "")
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreatePriv, tt.NewTable.Schema.L, tt.NewTable.Name.L, "")
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.InsertPriv, tt.NewTable.Schema.L, tt.NewTable.Name.L, "")
+		}
 	}
 
 	p := &DDL{Statement: node}
@@ -962,6 +1098,28 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	return p
 }
 
+func (b *planBuilder) buildPlanReplayer(pr *ast.PlanReplayerStmt) Plan {
+	// The dumped file is written to and loaded from the local file system of the server.
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	if pr.Load {
+		return b.buildSimple(pr)
+	}
+	explain, ok := b.buildExplain(&ast.ExplainStmt{Stmt: pr.Stmt}).(*Explain)
+	if !ok {
+		return nil
+	}
+	extractor := &tableNameExtractor{tables: make(map[string]*ast.TableName)}
+	pr.Stmt.Accept(extractor)
+	p := &PlanReplayerDump{Stmt: pr.Stmt, Explain: explain}
+	for _, name := range extractor.names {
+		p.Tables = append(p.Tables, extractor.tables[name])
+	}
+	schema := expression.NewSchema(make([]*expression.Column, 0, 1)...)
+	schema.Append(buildColumn("", "File", mysql.TypeVarchar, 1024))
+	p.SetSchema(schema)
+	return p
+}
+
 func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(show)
@@ -1150,6 +1308,10 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
 		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString}
+	case ast.ShowProgress:
+		names = []string{"Id", "Info", "Progress", "Rows_estimated", "Rows_scanned", "Operators", "Operators_finished"}
+		ftypes = []byte{mysql.TypeLonglong, mysql.TypeString, mysql.TypeDouble, mysql.TypeLonglong, mysql.TypeLonglong,
+			mysql.TypeLonglong, mysql.TypeLonglong}
 	case ast.ShowStatsMeta:
 		names = []string{"Db_name", "Table_name", "Update_time", "Modify_count", "Row_count"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeDatetime, mysql.TypeLonglong, mysql.TypeLonglong}
@@ -1162,6 +1324,12 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 			"Repeats", "Lower_Bound", "Upper_Bound"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeTiny, mysql.TypeLonglong,
 			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar}
+	case ast.ShowStatsJSON:
+		names = []string{"Db_name", "Table_name", "Stats"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLongBlob}
+	case ast.ShowSessionStates:
+		names = []string{"Session_states"}
+		ftypes = []byte{mysql.TypeLongBlob}
 	}
 	return composeShowSchema(names, ftypes)
 }
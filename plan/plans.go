@@ -37,6 +37,91 @@ type ShowDDLJobs struct {
 	basePlan
 }
 
+// CancelDDLJobs is used for cancelling DDL jobs, built from the 'admin cancel ddl jobs' statement.
+type CancelDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
+// PauseDDLJobs is used for pausing DDL jobs, built from the 'admin pause ddl jobs' statement.
+type PauseDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
+// ResumeDDLJobs is used for resuming the paused DDL jobs, built from the 'admin resume ddl jobs' statement.
+type ResumeDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
+// ReloadConfig is used for reloading the config file, built from the 'admin reload config' statement.
+type ReloadConfig struct {
+	basePlan
+}
+
+// ReloadRewriteRules is used for reloading the query rewrite rules, built from the 'admin reload rewrite rules' statement.
+type ReloadRewriteRules struct {
+	basePlan
+}
+
+// SplitTable is used for splitting the regions of a table, built from the 'admin split table' statement.
+// The handles in [Lower, Upper) are split into Num regions evenly.
+type SplitTable struct {
+	basePlan
+
+	TableInfo *model.TableInfo
+	Lower     int64
+	Upper     int64
+	Num       int64
+}
+
+// ScatterTable is used for scattering the regions of a table to the stores, built from the 'admin scatter table'
+// statement.
+type ScatterTable struct {
+	basePlan
+
+	TableInfo *model.TableInfo
+}
+
+// PauseWrites is used for pausing or resuming the writes, built from the 'admin pause writes' and the
+// 'admin resume writes' statements. The writes to all the user tables on the tidb-server are paused if Table is nil.
+type PauseWrites struct {
+	basePlan
+
+	Table  *ast.TableName
+	Paused bool
+}
+
+// PinSnapshot is used for pinning a snapshot so GC keeps the versions it reads, built from the 'admin pin snapshot'
+// statement. A snapshot at the current timestamp is pinned if TS is 0, otherwise the pin of TS is renewed.
+type PinSnapshot struct {
+	basePlan
+
+	TS  uint64
+	TTL uint64
+}
+
+// UnpinSnapshot is used for releasing a pinned snapshot, built from the 'admin unpin snapshot' statement.
+type UnpinSnapshot struct {
+	basePlan
+
+	TS uint64
+}
+
+// PlanReplayerDump is used for dumping the schema and statistics of the tables a statement reads or writes, the
+// session variables and the plan into a zip file, built from the 'plan replayer dump' statement.
+type PlanReplayerDump struct {
+	basePlan
+
+	Stmt    ast.StmtNode
+	Tables  []*ast.TableName
+	Explain *Explain
+}
+
 // CheckTable is used for checking table data, built from the 'admin check table' statement.
 type CheckTable struct {
 	basePlan
@@ -90,6 +175,8 @@ type Show struct {
 	Flag   int             // Some flag parsed from sql, such as FULL.
 	Full   bool
 	User   *auth.UserIdentity // Used for show grants.
+	// ConnectionID is used for show progress.
+	ConnectionID uint64
 
 	// Used by show variables
 	GlobalScope bool
@@ -133,8 +220,14 @@ type Insert struct {
 	IsReplace bool
 	Priority  mysql.PriorityEnum
 	IgnoreErr bool
+	// SelectFromTable indicates the select of `insert ... select` reads the inserted table.
+	SelectFromTable bool
 
 	GenCols InsertGeneratedColumns
+
+	// Returning is the RETURNING list evaluated on the inserted rows, ReturningSchema is the schema of its result.
+	Returning       []expression.Expression
+	ReturningSchema *expression.Schema
 }
 
 // AnalyzeColumnsTask is used for analyze columns.
@@ -150,12 +243,23 @@ type AnalyzeIndexTask struct {
 	IndexInfo *model.IndexInfo
 }
 
+// AnalyzeFastTask is used for analyze table with n samples, the columns and indices of the table are analyzed by the
+// sampled rows.
+type AnalyzeFastTask struct {
+	TableInfo   *model.TableInfo
+	PKInfo      *model.ColumnInfo
+	ColsInfo    []*model.ColumnInfo
+	IndicesInfo []*model.IndexInfo
+	NumSamples  uint64
+}
+
 // Analyze represents an analyze plan
 type Analyze struct {
 	basePlan
 
-	ColTasks []AnalyzeColumnsTask
-	IdxTasks []AnalyzeIndexTask
+	ColTasks  []AnalyzeColumnsTask
+	IdxTasks  []AnalyzeIndexTask
+	FastTasks []AnalyzeFastTask
 }
 
 // LoadData represents a loaddata plan.
@@ -29,6 +29,11 @@ func (s *ppdSolver) optimize(lp LogicalPlan, _ context.Context, _ *idAllocator)
 	return p, errors.Trace(err)
 }
 
+// name implements logicalOptRule interface.
+func (s *ppdSolver) name() string {
+	return "predicate_push_down"
+}
+
 func addSelection(p Plan, child LogicalPlan, conditions []expression.Expression, allocator *idAllocator) error {
 	conditions = expression.PropagateConstant(p.context(), conditions)
 	selection := Selection{Conditions: conditions}.init(allocator, p.context())
@@ -106,22 +111,32 @@ func (p *LogicalJoin) PredicatePushDown(predicates []expression.Expression) (ret
 	switch p.JoinType {
 	case LeftOuterJoin, LeftOuterSemiJoin:
 		rightCond = p.RightConditions
+		if p.JoinType == LeftOuterJoin {
+			rightCond = append(rightCond, deriveInnerConds(p.ctx, p.EqualConditions, rightPlan.Schema(), leftPushCond, p.LeftConditions)...)
+		}
 		p.RightConditions = nil
 		leftCond = leftPushCond
 		ret = append(expression.ScalarFuncs2Exprs(equalCond), otherCond...)
 		ret = append(ret, rightPushCond...)
 	case RightOuterJoin:
-		leftCond = p.LeftConditions
+		leftCond = append(p.LeftConditions, deriveInnerConds(p.ctx, p.EqualConditions, leftPlan.Schema(), rightPushCond, p.RightConditions)...)
 		p.LeftConditions = nil
 		rightCond = rightPushCond
 		ret = append(expression.ScalarFuncs2Exprs(equalCond), otherCond...)
 		ret = append(ret, leftPushCond...)
 	case SemiJoin:
 		equalCond, leftPushCond, rightPushCond, otherCond = extractOnCondition(predicates, leftPlan, rightPlan)
-		leftCond = append(p.LeftConditions, leftPushCond...)
 		rightCond = append(p.RightConditions, rightPushCond...)
-		p.LeftConditions = nil
 		p.RightConditions = nil
+		if p.anti {
+			// The rows which don't satisfy the left conditions are the output of the anti semi join, so the
+			// left conditions are kept in the join.
+			leftCond = leftPushCond
+		} else {
+			leftCond = append(p.LeftConditions, leftPushCond...)
+			p.LeftConditions = nil
+			rightCond = append(rightCond, deriveInnerConds(p.ctx, p.EqualConditions, rightPlan.Schema(), leftCond)...)
+		}
 	case InnerJoin:
 		p.LeftConditions = nil
 		p.RightConditions = nil
@@ -160,6 +175,32 @@ func (p *LogicalJoin) PredicatePushDown(predicates []expression.Expression) (ret
 	return
 }
 
+// deriveInnerConds derives the conditions on the inner side of an outer join or a semi join from the equal conditions
+// and the conditions on the outer side, e.g. t2.a = 5 is derived from `t1 left join t2 on t1.a = t2.a where t1.a = 5`,
+// then it's pushed down to t2 and an index of t2.a can be used. The inner rows which don't satisfy the derived
+// conditions can't match any outer row. The conditions on the inner side can't be propagated to the outer side,
+// because the outer rows are kept even if they don't match any inner row.
+func deriveInnerConds(ctx context.Context, eqConds []*expression.ScalarFunction, innerSchema *expression.Schema, outerConds ...[]expression.Expression) []expression.Expression {
+	if len(eqConds) == 0 {
+		return nil
+	}
+	conds := expression.ScalarFuncs2Exprs(eqConds)
+	eqLen := len(conds)
+	for _, cs := range outerConds {
+		conds = append(conds, cs...)
+	}
+	if len(conds) == eqLen {
+		return nil
+	}
+	var innerConds []expression.Expression
+	for _, cond := range expression.PropagateConstant(ctx, conds) {
+		if expression.ExprFromSchema(cond, innerSchema) {
+			innerConds = append(innerConds, cond)
+		}
+	}
+	return innerConds
+}
+
 // updateEQCond will extract the arguments of a equal condition that connect two expressions.
 func (p *LogicalJoin) updateEQCond() {
 	lChild, rChild := p.children[0], p.children[1]
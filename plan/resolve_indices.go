@@ -84,6 +84,9 @@ func (p *PhysicalHashSemiJoin) ResolveIndices() {
 	for _, expr := range p.OtherConditions {
 		expr.ResolveIndices(expression.MergeSchema(lSchema, rSchema))
 	}
+	for _, expr := range p.NullAwareConditions {
+		expr.ResolveIndices(expression.MergeSchema(lSchema, rSchema))
+	}
 }
 
 // ResolveIndices implements Plan interface.
@@ -134,6 +137,21 @@ func (p *PhysicalUnionScan) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *PhysicalExternalScan) ResolveIndices() {
+	for _, expr := range p.Conditions {
+		expr.ResolveIndices(p.columnSchema)
+	}
+	for _, aggFun := range p.AggFuncs {
+		for _, arg := range aggFun.GetArgs() {
+			arg.ResolveIndices(p.columnSchema)
+		}
+	}
+	for _, item := range p.GroupBy {
+		item.ResolveIndices(p.columnSchema)
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *PhysicalTableReader) ResolveIndices() {
 	p.tablePlan.ResolveIndices()
@@ -233,6 +251,17 @@ func (p *Update) ResolveIndices() {
 		assign.Col.ResolveIndices(schema)
 		assign.Expr.ResolveIndices(schema)
 	}
+	for _, expr := range p.Returning {
+		expr.ResolveIndices(schema)
+	}
+}
+
+// ResolveIndices implements Plan interface.
+func (p *Delete) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	for _, expr := range p.Returning {
+		expr.ResolveIndices(p.children[0].Schema())
+	}
 }
 
 // ResolveIndices implements Plan interface.
@@ -253,6 +282,9 @@ func (p *Insert) ResolveIndices() {
 		asgn.Col.ResolveIndices(p.tableSchema)
 		asgn.Expr.ResolveIndices(p.tableSchema)
 	}
+	for _, expr := range p.Returning {
+		expr.ResolveIndices(p.tableSchema)
+	}
 }
 
 // ResolveIndices implements Plan interface.
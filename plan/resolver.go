@@ -175,7 +175,7 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		}
 	case *ast.AnalyzeTableStmt:
 		nr.pushContext()
-	case *ast.DropStatsStmt:
+	case *ast.DropStatsStmt, *ast.CreateStatisticsStmt, *ast.DropStatisticsStmt, *ast.LockStatsStmt, *ast.UnlockStatsStmt:
 		nr.pushContext()
 	case *ast.ByItem:
 		if _, ok := v.Expr.(*ast.ColumnNameExpr); !ok {
@@ -203,7 +203,7 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		nr.currentContext().inDeleteTableList = true
 	case *ast.DoStmt:
 		nr.pushContext()
-	case *ast.DropTableStmt:
+	case *ast.DropTableStmt, *ast.DropViewStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
 	case *ast.DropIndexStmt:
@@ -267,7 +267,7 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.AnalyzeTableStmt:
 		nr.popContext()
-	case *ast.DropStatsStmt:
+	case *ast.DropStatsStmt, *ast.CreateStatisticsStmt, *ast.DropStatisticsStmt, *ast.LockStatsStmt, *ast.UnlockStatsStmt:
 		nr.popContext()
 	case *ast.TableName:
 		nr.handleTableName(v)
@@ -285,7 +285,7 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.DropIndexStmt:
 		nr.popContext()
-	case *ast.DropTableStmt:
+	case *ast.DropTableStmt, *ast.DropViewStmt:
 		nr.popContext()
 	case *ast.TableSource:
 		nr.handleTableSource(v)
@@ -452,14 +452,14 @@ func (nr *nameResolver) handleTableSource(ts *ast.TableSource) {
 			name = nr.tableUniqueName(tableName.Schema, tableName.Name)
 		}
 		if _, ok := ctx.tableMap[name]; ok {
-			nr.Err = errors.Errorf("duplicated table/alias name %s", name)
+			nr.Err = ErrNonUniqTable.GenByArgs(name)
 			return
 		}
 		ctx.tableMap[name] = len(ctx.tables)
 	case *ast.SelectStmt:
 		name := ts.AsName.L
 		if _, ok := ctx.derivedTableMap[name]; ok {
-			nr.Err = errors.Errorf("duplicated table/alias name %s", name)
+			nr.Err = ErrNonUniqTable.GenByArgs(name)
 			return
 		}
 		ctx.derivedTableMap[name] = len(ctx.tables)
@@ -473,7 +473,7 @@ func (nr *nameResolver) handleTableSource(ts *ast.TableSource) {
 			name = f.Column.Name.L
 		}
 		if _, ok := dupNames[name]; ok {
-			nr.Err = errors.Errorf("Duplicate column name '%s'", name)
+			nr.Err = infoschema.ErrColumnExists.GenByArgs(name)
 			return
 		}
 		dupNames[name] = struct{}{}
@@ -985,6 +985,10 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 		names = []string{"Id", "User", "Host", "db", "Command", "Time", "State", "Info"}
 		ftypes = []byte{mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar,
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLong, mysql.TypeVarchar, mysql.TypeString}
+	case ast.ShowProgress:
+		names = []string{"Id", "Info", "Progress", "Rows_estimated", "Rows_scanned", "Operators", "Operators_finished"}
+		ftypes = []byte{mysql.TypeLonglong, mysql.TypeString, mysql.TypeDouble, mysql.TypeLonglong, mysql.TypeLonglong,
+			mysql.TypeLonglong, mysql.TypeLonglong}
 	case ast.ShowStatsMeta:
 		names = []string{"Db_name", "Table_name", "Update_time", "Modify_count", "Row_count"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeDatetime, mysql.TypeLonglong, mysql.TypeLonglong}
@@ -997,6 +1001,12 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 			"Repeats", "Lower_Bound", "Upper_Bound"}
 		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeTiny, mysql.TypeLonglong,
 			mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeVarchar}
+	case ast.ShowStatsJSON:
+		names = []string{"Db_name", "Table_name", "Stats"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLongBlob}
+	case ast.ShowSessionStates:
+		names = []string{"Session_states"}
+		ftypes = []byte{mysql.TypeLongBlob}
 	}
 	for i, name := range names {
 		f := &ast.ResultField{
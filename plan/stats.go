@@ -50,6 +50,14 @@ func (p *basePhysicalPlan) statsProfile() *statsProfile {
 	return profile
 }
 
+// StatsCount implements the PhysicalPlan StatsCount interface, it's 0 if the plan isn't estimated.
+func (p *basePhysicalPlan) StatsCount() float64 {
+	if p.basePlan.profile == nil {
+		return 0
+	}
+	return p.statsProfile().count
+}
+
 func (p *baseLogicalPlan) prepareStatsProfile() *statsProfile {
 	if len(p.basePlan.children) == 0 {
 		profile := &statsProfile{
@@ -187,6 +195,7 @@ func (p *LogicalAggregation) prepareStatsProfile() *statsProfile {
 		gbyCols = append(gbyCols, cols...)
 	}
 	count := getCardinality(gbyCols, p.children[0].Schema(), childProfile)
+	p.inputCount = childProfile.count
 	p.profile = &statsProfile{
 		count:       count,
 		cardinality: make([]float64, p.schema.Len()),
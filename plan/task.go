@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -277,7 +278,9 @@ func (p *Limit) attach2Task(tasks ...task) task {
 		return tasks[0]
 	}
 	t := tasks[0].copy()
-	if cop, ok := t.(*copTask); ok {
+	if cop, ok := t.(*copTask); ok && p.CalcFoundRows {
+		t = finishCopTask(cop, p.ctx, p.allocator)
+	} else if ok {
 		// If the table/index scans data by order and applies a double read, the limit cannot be pushed to the table side.
 		if !cop.keepOrder || !cop.indexPlanFinished || cop.indexPlan == nil {
 			// When limit be pushed down, it should remove its offset.
@@ -292,6 +295,13 @@ func (p *Limit) attach2Task(tasks ...task) task {
 		}
 		t = finishCopTask(cop, p.ctx, p.allocator)
 	}
+	if root, ok := t.(*rootTask); ok && !p.CalcFoundRows {
+		if scan, ok := root.p.(*PhysicalExternalScan); ok && scan.Source.Capabilities().Has(engine.CapPushDownLimit) {
+			scan = scan.Copy().(*PhysicalExternalScan)
+			scan.Limit = p.Offset + p.Count
+			root.p = scan
+		}
+	}
 	if !p.partial {
 		t = attachPlan2Task(p.Copy(), t)
 	}
@@ -424,6 +434,12 @@ func (p *PhysicalAggregation) newPartialAggregate() (partialAgg, finalAgg *Physi
 	if len(remained) > 0 {
 		return
 	}
+	return p.splitAggregate()
+}
+
+// splitAggregate splits the aggregation into a partial one and a final one, the final aggregation merges the
+// partial results produced by the partial one.
+func (p *PhysicalAggregation) splitAggregate() (partialAgg, finalAgg *PhysicalAggregation) {
 	partialAgg = p.Copy().(*PhysicalAggregation)
 	// TODO: It's toooooo ugly here. Refactor in the future !!
 	gkType := types.NewFieldType(mysql.TypeBlob)
@@ -432,7 +448,7 @@ func (p *PhysicalAggregation) newPartialAggregate() (partialAgg, finalAgg *Physi
 	partialSchema := expression.NewSchema()
 	partialAgg.SetSchema(partialSchema)
 	cursor := 0
-	finalAggFuncs := make([]expression.AggregationFunction, len(finalAgg.AggFuncs))
+	finalAggFuncs := make([]expression.AggregationFunction, len(p.AggFuncs))
 	for i, aggFun := range p.AggFuncs {
 		fun := expression.NewAggFunction(aggFun.GetName(), nil, false)
 		var args []expression.Expression
@@ -499,6 +515,9 @@ func (p *PhysicalAggregation) attach2Task(tasks ...task) task {
 		}
 		task = finishCopTask(cop, p.ctx, p.allocator)
 		attachPlan2Task(finalAgg, task)
+	} else if finalAgg := p.pushDownAgg2External(task.(*rootTask)); finalAgg != nil {
+		attachPlan2Task(finalAgg, task)
+		task.addCost(task.count() * cpuFactor)
 	} else {
 		np := p.Copy()
 		attachPlan2Task(np, task)
@@ -506,3 +525,27 @@ func (p *PhysicalAggregation) attach2Task(tasks ...task) task {
 	}
 	return task
 }
+
+// pushDownAgg2External pushes the aggregation down to the scan of an external table if its engine can compute
+// the partial results. It returns the final aggregation, or nil if the aggregation can't be pushed down.
+func (p *PhysicalAggregation) pushDownAgg2External(t *rootTask) *PhysicalAggregation {
+	scan, ok := t.p.(*PhysicalExternalScan)
+	if !ok || p.AggType == StreamedAgg || scan.Limit > 0 || len(scan.AggFuncs) > 0 || !scan.Source.Capabilities().Has(engine.CapPushDownAgg) {
+		return nil
+	}
+	for _, aggFunc := range p.AggFuncs {
+		if aggFunc.IsDistinct() {
+			return nil
+		}
+	}
+	if !scan.Source.CanPushDownAgg(p.AggFuncs, p.GroupByItems) {
+		return nil
+	}
+	partialAgg, finalAgg := p.splitAggregate()
+	scan = scan.Copy().(*PhysicalExternalScan)
+	scan.AggFuncs = p.AggFuncs
+	scan.GroupBy = p.GroupByItems
+	scan.SetSchema(partialAgg.Schema())
+	t.p = scan
+	return finalAgg
+}
@@ -26,6 +26,11 @@ func (s *pushDownTopNOptimizer) optimize(p LogicalPlan, ctx context.Context, all
 	return p.pushDownTopN(nil), nil
 }
 
+// name implements logicalOptRule interface.
+func (s *pushDownTopNOptimizer) name() string {
+	return "topn_push_down"
+}
+
 func (s *baseLogicalPlan) pushDownTopN(topN *TopN) LogicalPlan {
 	p := s.basePlan.self.(LogicalPlan)
 	for i, child := range p.Children() {
@@ -78,6 +83,10 @@ func (p *Limit) convertToTopN() *TopN {
 }
 
 func (p *Limit) pushDownTopN(topN *TopN) LogicalPlan {
+	if p.CalcFoundRows {
+		// All the rows should be read to count the found rows, so the limit is kept.
+		return p.baseLogicalPlan.pushDownTopN(topN)
+	}
 	child := p.children[0].(LogicalPlan).pushDownTopN(p.convertToTopN())
 	if topN != nil {
 		return topN.setChild(child, false)
@@ -15,6 +15,7 @@ package plan
 
 import (
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/infoschema"
 )
 
 // AggregateFuncExtractor visits Expr tree.
@@ -45,3 +46,27 @@ func (a *AggregateFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
 	}
 	return n, true
 }
+
+// tableNameExtractor collects the tables in a statement, each table is collected once, the memory tables are skipped.
+type tableNameExtractor struct {
+	tables map[string]*ast.TableName
+	// names are the unique names of the tables in the order they appear.
+	names []string
+}
+
+// Enter implements Visitor interface.
+func (e *tableNameExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (e *tableNameExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	if t, ok := n.(*ast.TableName); ok && t.TableInfo != nil && !infoschema.IsMemoryDB(t.Schema.L) {
+		name := t.Schema.L + "." + t.Name.L
+		if _, ok := e.tables[name]; !ok {
+			e.tables[name] = t
+			e.names = append(e.names, name)
+		}
+	}
+	return n, true
+}
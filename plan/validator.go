@@ -19,17 +19,19 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
 // Validate checkes whether the node is valid.
-func Validate(node ast.Node, inPrepare bool) error {
-	v := validator{inPrepare: inPrepare}
+func Validate(node ast.Node, inPrepare bool, ctx context.Context) error {
+	v := validator{inPrepare: inPrepare, ctx: ctx}
 	node.Accept(&v)
 	return v.err
 }
@@ -41,9 +43,13 @@ type validator struct {
 	wildCardCount int
 	inPrepare     bool
 	inAggregate   bool
+	ctx           context.Context
 }
 
 func (v *validator) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
+	if v.err = v.checkMemDBWrite(in); v.err != nil {
+		return in, true
+	}
 	switch node := in.(type) {
 	case *ast.AggregateFuncExpr:
 		if v.inAggregate {
@@ -82,6 +88,11 @@ func (v *validator) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 		if v.err != nil {
 			return in, true
 		}
+	case *ast.UpdateStmt:
+		v.checkUpdateGrammar(node)
+		if v.err != nil {
+			return in, true
+		}
 	}
 	return in, false
 }
@@ -130,7 +141,7 @@ func checkAutoIncrementOp(colDef *ast.ColumnDef, num int) (bool, error) {
 		}
 		for _, op := range colDef.Options[num+1:] {
 			if op.Tp == ast.ColumnOptionDefaultValue && !op.Expr.GetDatum().IsNull() {
-				return hasAutoIncrement, errors.Errorf("Invalid default value for '%s'", colDef.Name.Name.O)
+				return hasAutoIncrement, types.ErrInvalidDefault.GenByArgs(colDef.Name.Name.O)
 			}
 		}
 	}
@@ -140,7 +151,7 @@ func checkAutoIncrementOp(colDef *ast.ColumnDef, num int) (bool, error) {
 		}
 		for _, op := range colDef.Options[num+1:] {
 			if op.Tp == ast.ColumnOptionAutoIncrement {
-				return hasAutoIncrement, errors.Errorf("Invalid default value for '%s'", colDef.Name.Name.O)
+				return hasAutoIncrement, types.ErrInvalidDefault.GenByArgs(colDef.Name.Name.O)
 			}
 		}
 	}
@@ -209,14 +220,14 @@ func (v *validator) checkAutoIncrement(stmt *ast.CreateTableStmt) {
 		}
 	}
 	if (autoIncrementMustBeKey && !isKey) || count > 1 {
-		v.err = errors.New("Incorrect table definition; there can be only one auto column and it must be defined as a key")
+		v.err = ddl.ErrWrongAutoKey
 	}
 
 	switch autoIncrementCol.Tp.Tp {
 	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeLong,
 		mysql.TypeFloat, mysql.TypeDouble, mysql.TypeLonglong, mysql.TypeInt24:
 	default:
-		v.err = errors.Errorf("Incorrect column specifier for column '%s'", autoIncrementCol.Name.Name.O)
+		v.err = types.ErrWrongFieldSpec.Gen("Incorrect column specifier for column '%s'", autoIncrementCol.Name.Name.O)
 	}
 }
 
@@ -234,6 +245,147 @@ func (v *validator) checkDropDatabaseGrammar(stmt *ast.DropDatabaseStmt) {
 	return
 }
 
+// checkMemDBWrite rejects the DDL and the DML statements which change the memory databases, e.g. information_schema,
+// whose tables are generated by TiDB. The statements can still read the memory tables, e.g. INSERT ... SELECT, and
+// like MySQL, the setup tables of performance_schema can be changed by the DML statements.
+func (v *validator) checkMemDBWrite(in ast.Node) error {
+	var tables []*ast.TableName
+	_, isDML := in.(ast.DMLNode)
+	switch node := in.(type) {
+	case *ast.CreateDatabaseStmt:
+		return v.checkMemDB(node.Name)
+	case *ast.DropDatabaseStmt:
+		return v.checkMemDB(node.Name)
+	case *ast.CreateTableStmt:
+		tables = append(tables, node.Table)
+	case *ast.DropTableStmt:
+		tables = node.Tables
+	case *ast.CreateIndexStmt:
+		tables = append(tables, node.Table)
+	case *ast.DropIndexStmt:
+		tables = append(tables, node.Table)
+	case *ast.AlterTableStmt:
+		tables = append(tables, node.Table)
+		for _, spec := range node.Specs {
+			tables = append(tables, spec.NewTable)
+		}
+	case *ast.TruncateTableStmt:
+		tables = append(tables, node.Table)
+	case *ast.RenameTableStmt:
+		tables = append(tables, node.OldTable, node.NewTable)
+		for _, t := range node.TableToTables {
+			tables = append(tables, t.OldTable, t.NewTable)
+		}
+	case *ast.InsertStmt:
+		tables = extractTableList(node.Table.TableRefs, tables)
+	case *ast.LoadDataStmt:
+		tables = append(tables, node.Table)
+	case *ast.DeleteStmt:
+		if node.IsMultiTable {
+			tables = node.Tables.Tables
+		} else {
+			tables = extractTableList(node.TableRefs.TableRefs, tables)
+		}
+	case *ast.UpdateStmt:
+		tables = updatedTables(node)
+	}
+	for _, t := range tables {
+		if t == nil {
+			continue
+		}
+		dbName := t.Schema.O
+		if t.DBInfo != nil {
+			// The table in the table list of a multiple-table DELETE may be an alias.
+			dbName = t.DBInfo.Name.O
+		}
+		tblName := t.Name.O
+		if t.TableInfo != nil {
+			tblName = t.TableInfo.Name.O
+		}
+		if isDML && strings.EqualFold(dbName, perfschema.Name) && perfschema.IsSetupTable(tblName) {
+			continue
+		}
+		if err := v.checkMemDB(dbName); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// checkMemDB returns ErrDBaccessDenied if dbName, or the current database if it's empty, is a memory database.
+func (v *validator) checkMemDB(dbName string) error {
+	sessVars := v.ctx.GetSessionVars()
+	if dbName == "" {
+		dbName = sessVars.CurrentDB
+	}
+	if !infoschema.IsMemoryDB(strings.ToLower(dbName)) {
+		return nil
+	}
+	var user, host string
+	if sessVars.User != nil {
+		user, host = sessVars.User.Username, sessVars.User.Hostname
+	}
+	return ErrDBaccessDenied.GenByArgs(user, host, dbName)
+}
+
+// updatedTables returns the tables whose columns are assigned by the UPDATE statement. A table in the multiple-table
+// syntax which is only read isn't returned.
+func updatedTables(stmt *ast.UpdateStmt) []*ast.TableName {
+	sources := appendTableSources(nil, stmt.TableRefs.TableRefs)
+	var tables []*ast.TableName
+	for _, ts := range sources {
+		tn, ok := ts.Source.(*ast.TableName)
+		if !ok {
+			continue
+		}
+		if !stmt.MultipleTable {
+			tables = append(tables, tn)
+			continue
+		}
+		for _, assign := range stmt.List {
+			if isAssignedTable(assign.Column, ts, tn) {
+				tables = append(tables, tn)
+				break
+			}
+		}
+	}
+	return tables
+}
+
+// isAssignedTable checks if the assigned column may be a column of the table source. An unqualified column is a
+// column of the table if the table has it, or of any table if the table isn't resolved.
+func isAssignedTable(col *ast.ColumnName, ts *ast.TableSource, tn *ast.TableName) bool {
+	if col.Table.L == "" {
+		if tn.TableInfo == nil {
+			return true
+		}
+		for _, colInfo := range tn.TableInfo.Columns {
+			if colInfo.Name.L == col.Name.L {
+				return true
+			}
+		}
+		return false
+	}
+	if ts.AsName.L != "" {
+		return col.Schema.L == "" && col.Table.L == ts.AsName.L
+	}
+	return (col.Schema.L == "" || col.Schema.L == tn.Schema.L) && col.Table.L == tn.Name.L
+}
+
+// checkUpdateGrammar checks the clauses which are only allowed in the single-table syntax of UPDATE.
+func (v *validator) checkUpdateGrammar(stmt *ast.UpdateStmt) {
+	if !stmt.MultipleTable {
+		return
+	}
+	if stmt.Order != nil {
+		v.err = ErrWrongUsage.GenByArgs("UPDATE", "ORDER BY")
+	} else if stmt.Limit != nil {
+		v.err = ErrWrongUsage.GenByArgs("UPDATE", "LIMIT")
+	} else if stmt.Returning != nil {
+		v.err = ErrWrongUsage.GenByArgs("UPDATE", "RETURNING")
+	}
+}
+
 func (v *validator) checkCreateTableGrammar(stmt *ast.CreateTableStmt) {
 	if stmt.Table == nil {
 		v.err = ddl.ErrWrongTableName.GenByArgs("")
@@ -245,10 +397,26 @@ func (v *validator) checkCreateTableGrammar(stmt *ast.CreateTableStmt) {
 		v.err = ddl.ErrWrongTableName.GenByArgs(tName)
 		return
 	}
+	if stmt.ReferTable != nil {
+		referName := stmt.ReferTable.Name.String()
+		if isIncorrectName(referName) {
+			v.err = ddl.ErrWrongTableName.GenByArgs(referName)
+			return
+		}
+	}
+	if sel, ok := stmt.Select.(*ast.SelectStmt); ok {
+		// The names of the fields are the names of the created columns.
+		for _, field := range sel.Fields.Fields {
+			if field.AsName.L != "" && isIncorrectName(field.AsName.O) {
+				v.err = ddl.ErrWrongColumnName.GenByArgs(field.AsName.O)
+				return
+			}
+		}
+	}
 
 	countPrimaryKey := 0
 	for _, colDef := range stmt.Cols {
-		if err := checkColumn(colDef); err != nil {
+		if err := v.checkColumn(colDef); err != nil {
 			v.err = errors.Trace(err)
 			return
 		}
@@ -335,7 +503,7 @@ func (v *validator) checkAlterTableGrammar(stmt *ast.AlterTableStmt) {
 			}
 		}
 		if spec.NewColumn != nil {
-			if err := checkColumn(spec.NewColumn); err != nil {
+			if err := v.checkColumn(spec.NewColumn); err != nil {
 				v.err = err
 				return
 			}
@@ -363,6 +531,54 @@ func (v *validator) checkAlterTableGrammar(stmt *ast.AlterTableStmt) {
 			// Nothing to do now.
 		}
 	}
+	v.err = checkConflictingSpecs(specs)
+}
+
+// checkConflictingSpecs checks if the specs of an ALTER TABLE statement add or drop a column or an index twice,
+// or add and drop the same one.
+func checkConflictingSpecs(specs []*ast.AlterTableSpec) error {
+	addedCols := make(map[string]struct{})
+	droppedCols := make(map[string]struct{})
+	addedIndices := make(map[string]struct{})
+	droppedIndices := make(map[string]struct{})
+	for _, spec := range specs {
+		switch spec.Tp {
+		case ast.AlterTableAddColumn:
+			name := spec.NewColumn.Name.Name
+			if _, ok := addedCols[name.L]; ok {
+				return infoschema.ErrColumnExists.GenByArgs(name)
+			}
+			addedCols[name.L] = struct{}{}
+		case ast.AlterTableDropColumn:
+			name := spec.OldColumnName.Name
+			_, added := addedCols[name.L]
+			if _, ok := droppedCols[name.L]; ok || added {
+				return ddl.ErrCantDropFieldOrKey.Gen("column %s doesn't exist", name)
+			}
+			droppedCols[name.L] = struct{}{}
+		case ast.AlterTableAddConstraint:
+			switch spec.Constraint.Tp {
+			case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqIndex,
+				ast.ConstraintUniqKey:
+				name := strings.ToLower(spec.Constraint.Name)
+				if name == "" {
+					continue
+				}
+				if _, ok := addedIndices[name]; ok {
+					return ddl.ErrDupKeyName.Gen("duplicate key name %s", spec.Constraint.Name)
+				}
+				addedIndices[name] = struct{}{}
+			}
+		case ast.AlterTableDropIndex:
+			name := strings.ToLower(spec.Name)
+			_, added := addedIndices[name]
+			if _, ok := droppedIndices[name]; ok || added {
+				return ddl.ErrCantDropFieldOrKey.Gen("index %s doesn't exist", spec.Name)
+			}
+			droppedIndices[name] = struct{}{}
+		}
+	}
+	return nil
 }
 
 // checkDuplicateColumnName checks if index exists duplicated columns.
@@ -392,14 +608,14 @@ func checkIndexInfo(indexName string, indexColNames []*ast.IndexColName) error {
 
 // checkColumn checks if the column definition is valid.
 // See https://dev.mysql.com/doc/refman/5.7/en/storage-requirements.html
-func checkColumn(colDef *ast.ColumnDef) error {
+func (v *validator) checkColumn(colDef *ast.ColumnDef) error {
 	// Check column name.
 	cName := colDef.Name.Name.String()
 	if isIncorrectName(cName) {
 		return ddl.ErrWrongColumnName.GenByArgs(cName)
 	}
 
-	if isInvalidDefaultValue(colDef) {
+	if isInvalidDefaultValue(colDef) || isInvalidDefaultValueExpr(colDef) {
 		return types.ErrInvalidDefault.GenByArgs(colDef.Name.Name.O)
 	}
 
@@ -413,27 +629,10 @@ func checkColumn(colDef *ast.ColumnDef) error {
 	}
 
 	switch tp.Tp {
-	case mysql.TypeString:
-		if tp.Flen != types.UnspecifiedLength && tp.Flen > mysql.MaxFieldCharLength {
-			return types.ErrTooBigFieldLength.Gen("Column length too big for column '%s' (max = %d); use BLOB or TEXT instead", colDef.Name.Name.O, mysql.MaxFieldCharLength)
-		}
-	case mysql.TypeVarchar:
-		maxFlen := mysql.MaxFieldVarCharLength
-		cs := tp.Charset
-		// TODO: TableDefaultCharset-->DatabaseDefaultCharset-->SystemDefaultCharset.
-		// TODO: Change TableOption parser to parse collate.
-		// Reference https://github.com/pingcap/tidb/blob/b091e828cfa1d506b014345fb8337e424a4ab905/ddl/ddl_api.go#L185-L204
-		if len(tp.Charset) == 0 {
-			cs = mysql.DefaultCharset
-		}
-		desc, err := charset.GetCharsetDesc(cs)
-		if err != nil {
+	case mysql.TypeString, mysql.TypeVarchar:
+		if err := v.checkStringLength(colDef); err != nil {
 			return errors.Trace(err)
 		}
-		maxFlen /= desc.Maxlen
-		if tp.Flen != types.UnspecifiedLength && tp.Flen > maxFlen {
-			return types.ErrTooBigFieldLength.Gen("Column length too big for column '%s' (max = %d); use BLOB or TEXT instead", colDef.Name.Name.O, maxFlen)
-		}
 	case mysql.TypeDouble:
 		if tp.Flen != types.UnspecifiedLength && tp.Flen > mysql.PrecisionForDouble {
 			return types.ErrWrongFieldSpec.Gen("Incorrect column specifier for column '%s'", colDef.Name.Name.O)
@@ -454,6 +653,58 @@ func checkColumn(colDef *ast.ColumnDef) error {
 	return nil
 }
 
+// checkStringLength checks the length of the CHAR or VARCHAR column. The error of a too long column suggests
+// the smallest TEXT or BLOB type that can store it. Like MySQL, a too long VARCHAR column is converted to the
+// type in non-strict mode, and both CHAR and VARCHAR columns are converted if tidb_auto_convert_long_string is on.
+func (v *validator) checkStringLength(colDef *ast.ColumnDef) error {
+	tp := colDef.Tp
+	if tp.Flen == types.UnspecifiedLength {
+		return nil
+	}
+	cs := tp.Charset
+	// TODO: TableDefaultCharset-->DatabaseDefaultCharset-->SystemDefaultCharset.
+	// TODO: Change TableOption parser to parse collate.
+	// Reference https://github.com/pingcap/tidb/blob/b091e828cfa1d506b014345fb8337e424a4ab905/ddl/ddl_api.go#L185-L204
+	if len(tp.Charset) == 0 {
+		cs = mysql.DefaultCharset
+	}
+	desc, err := charset.GetCharsetDesc(cs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	maxFlen := mysql.MaxFieldCharLength
+	if tp.Tp == mysql.TypeVarchar {
+		maxFlen = mysql.MaxFieldVarCharLength / desc.Maxlen
+	}
+	if tp.Flen <= maxFlen {
+		return nil
+	}
+
+	textTp := textTypeForLength(tp.Flen * desc.Maxlen)
+	sessVars := v.ctx.GetSessionVars()
+	if !sessVars.AutoConvertLongString && (tp.Tp == mysql.TypeString || sessVars.StrictSQLMode) {
+		return types.ErrTooBigFieldLength.Gen("Column length too big for column '%s' (max = %d); use BLOB or TEXT instead, e.g. %s",
+			colDef.Name.Name.O, maxFlen, strings.ToUpper(types.TypeToStr(textTp, cs)))
+	}
+	sessVars.StmtCtx.AppendWarning(types.ErrAutoConvert.GenByArgs(colDef.Name.Name.O,
+		strings.ToUpper(types.TypeToStr(tp.Tp, cs)), strings.ToUpper(types.TypeToStr(textTp, cs))))
+	tp.Tp = textTp
+	tp.Flen = types.UnspecifiedLength
+	return nil
+}
+
+// textTypeForLength returns the smallest TEXT type which can store the length in bytes.
+func textTypeForLength(length int) byte {
+	switch {
+	case length <= mysql.MaxTextLength:
+		return mysql.TypeBlob
+	case length <= mysql.MaxMediumTextLength:
+		return mysql.TypeMediumBlob
+	default:
+		return mysql.TypeLongBlob
+	}
+}
+
 // isNowSymFunc checks whether defaul value is a NOW() builtin function.
 func isDefaultValNowSymFunc(expr ast.ExprNode) bool {
 	if funcCall, ok := expr.(*ast.FuncCallExpr); ok {
@@ -471,6 +722,9 @@ func isInvalidDefaultValue(colDef *ast.ColumnDef) bool {
 	for i := len(colDef.Options) - 1; i >= 0; i-- {
 		columnOpt := colDef.Options[i]
 		if columnOpt.Tp == ast.ColumnOptionDefaultValue {
+			if columnOpt.DefaultIsExpr {
+				break
+			}
 			if !(tp.Tp == mysql.TypeTimestamp || tp.Tp == mysql.TypeDatetime) && isDefaultValNowSymFunc(columnOpt.Expr) {
 				return true
 			}
@@ -481,6 +735,43 @@ func isInvalidDefaultValue(colDef *ast.ColumnDef) bool {
 	return false
 }
 
+// isInvalidDefaultValueExpr checks whether the expression of DEFAULT (expr) refers to the columns, the variables,
+// the subqueries or the aggregate functions, it can only be computed from the constants and the builtin functions.
+func isInvalidDefaultValueExpr(colDef *ast.ColumnDef) bool {
+	for _, columnOpt := range colDef.Options {
+		if !columnOpt.DefaultIsExpr {
+			continue
+		}
+		checker := &defaultValueExprChecker{}
+		columnOpt.Expr.Accept(checker)
+		if checker.invalid {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValueExprChecker is an ast.Visitor that finds the nodes which can't be in the expression of DEFAULT (expr).
+type defaultValueExprChecker struct {
+	invalid bool
+}
+
+// Enter implements ast.Visitor interface.
+func (c *defaultValueExprChecker) Enter(in ast.Node) (ast.Node, bool) {
+	switch in.(type) {
+	case *ast.ColumnNameExpr, *ast.VariableExpr, *ast.SubqueryExpr, *ast.AggregateFuncExpr,
+		*ast.DefaultExpr, *ast.ValuesExpr, *ast.ParamMarkerExpr:
+		c.invalid = true
+		return in, true
+	}
+	return in, false
+}
+
+// Leave implements ast.Visitor interface.
+func (c *defaultValueExprChecker) Leave(in ast.Node) (ast.Node, bool) {
+	return in, !c.invalid
+}
+
 // See https://dev.mysql.com/doc/refman/5.7/en/identifiers.html
 func isIncorrectName(name string) bool {
 	if len(name) == 0 {
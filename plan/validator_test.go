@@ -17,9 +17,12 @@ import (
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -40,25 +43,25 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"select ?", false, parser.ErrSyntax},
 		{"select ?", true, nil},
 		{"create table t(id int not null auto_increment default 2, key (id))", true,
-			errors.New("Invalid default value for 'id'")},
+			errors.New("[types:1067]Invalid default value for 'id'")},
 		{"create table t(id int not null default 2 auto_increment, key (id))", true,
-			errors.New("Invalid default value for 'id'")},
+			errors.New("[types:1067]Invalid default value for 'id'")},
 		// Default value can be null when the column is primary key in MySQL 5.6.
 		// But it can't be null in MySQL 5.7.
 		{"create table t(id int auto_increment default null, primary key (id))", true, nil},
 		{"create table t(id int default null auto_increment, primary key (id))", true, nil},
 		{"create table t(id int not null auto_increment)", true,
-			errors.New("Incorrect table definition; there can be only one auto column and it must be defined as a key")},
+			errors.New("[ddl:1075]Incorrect table definition; there can be only one auto column and it must be defined as a key")},
 		{"create table t(id int not null auto_increment, c int auto_increment, key (id, c))", true,
-			errors.New("Incorrect table definition; there can be only one auto column and it must be defined as a key")},
+			errors.New("[ddl:1075]Incorrect table definition; there can be only one auto column and it must be defined as a key")},
 		{"create table t(id int not null auto_increment, c int, key (c, id))", true,
-			errors.New("Incorrect table definition; there can be only one auto column and it must be defined as a key")},
+			errors.New("[ddl:1075]Incorrect table definition; there can be only one auto column and it must be defined as a key")},
 		{"create table t(id decimal auto_increment, key (id))", true,
-			errors.New("Incorrect column specifier for column 'id'")},
+			errors.New("[types:1063]Incorrect column specifier for column 'id'")},
 		{"create table t(id float auto_increment, key (id))", true, nil},
 		{"create table t(id int auto_increment) ENGINE=MYISAM", true, nil},
 		{"create table t(a int primary key, b int, c varchar(10), d char(256));", true,
-			errors.New("[types:1074]Column length too big for column 'd' (max = 255); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'd' (max = 255); use BLOB or TEXT instead, e.g. TEXT")},
 		{"create index ib on t(b,a,b);", true, errors.New("[schema:1060]Duplicate column name 'b'")},
 		{"alter table t add index idx(a, b, A)", true, errors.New("[schema:1060]Duplicate column name 'A'")},
 		{"create table t (a int, b int, index(a, b, A))", true, errors.New("[schema:1060]Duplicate column name 'A'")},
@@ -76,6 +79,12 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"alter table t add column c int auto_increment key, auto_increment=10", true,
 			errors.New("[autoid:3]No support for setting auto_increment using alter_table")},
 		{"alter table t add column c int auto_increment key", true, nil},
+		{"alter table t add column c int, add index idx(a), drop column d, drop index idx2", true, nil},
+		{"alter table t add column c int, add column C int", true, errors.New("[schema:1060]Duplicate column name 'C'")},
+		{"alter table t add column c int, drop column c", true, errors.New("[ddl:1091]column c doesn't exist")},
+		{"alter table t drop column c, drop column c", true, errors.New("[ddl:1091]column c doesn't exist")},
+		{"alter table t add index idx(a), add unique idx(b)", true, errors.New("[ddl:1061]duplicate key name idx")},
+		{"alter table t add index idx(a), drop index idx", true, errors.New("[ddl:1091]index idx doesn't exist")},
 		{"alter table t add column char4294967295 char(255)", true, nil},
 		{"create table t (c float(53))", true, nil},
 		{"alter table t add column c float(53)", true, nil},
@@ -88,7 +97,7 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"alter table t add column c varchar(16383) CHARACTER SET utf8mb4", true, nil},
 		{"alter table t add column c varchar(65535) CHARACTER SET ascii", true, nil},
 		{"alter table t add column char4294967295 char(4294967295)", true,
-			errors.New("[types:1074]Column length too big for column 'char4294967295' (max = 255); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'char4294967295' (max = 255); use BLOB or TEXT instead, e.g. LONGTEXT")},
 		{"alter table t add column char4294967296 char(4294967296)", true,
 			errors.New("[types:1439]Display width out of range for column 'char4294967296' (max = 4294967295)")},
 		{"create table t (c float(4294967296))", true,
@@ -104,17 +113,17 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"alter table t add column set65 set ('1','2','3','4','5','6','7','8','9','10','11','12','13','14','15','16','17','18','19','20','21','22','23','24','25','26','27','28','29','30','31','32','33','34','35','36','37','38','39','40','41','42','43','44','45','46','47','48','49','50','51','52','53','54','55','56','57','58','59','60','61','62','63','64','65')", true,
 			errors.New("[types:1097]Too many strings for column set65 and SET")},
 		{"create table t (c varchar(4294967295) CHARACTER SET utf8)", true,
-			errors.New("[types:1074]Column length too big for column 'c' (max = 21845); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'c' (max = 21845); use BLOB or TEXT instead, e.g. LONGTEXT")},
 		{"create table t (c varchar(4294967295) CHARACTER SET utf8mb4)", true,
-			errors.New("[types:1074]Column length too big for column 'c' (max = 16383); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'c' (max = 16383); use BLOB or TEXT instead, e.g. LONGTEXT")},
 		{"create table t (c varchar(4294967295) CHARACTER SET ascii)", true,
-			errors.New("[types:1074]Column length too big for column 'c' (max = 65535); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'c' (max = 65535); use BLOB or TEXT instead, e.g. LONGTEXT")},
 		{"alter table t add column c varchar(4294967295) CHARACTER SET utf8", true,
-			errors.New("[types:1074]Column length too big for column 'c' (max = 21845); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'c' (max = 21845); use BLOB or TEXT instead, e.g. LONGTEXT")},
 		{"alter table t add column c varchar(4294967295) CHARACTER SET utf8mb4;", true,
-			errors.New("[types:1074]Column length too big for column 'c' (max = 16383); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'c' (max = 16383); use BLOB or TEXT instead, e.g. LONGTEXT")},
 		{"alter table t add column c varchar(4294967295) CHARACTER SET ascii", true,
-			errors.New("[types:1074]Column length too big for column 'c' (max = 65535); use BLOB or TEXT instead")},
+			errors.New("[types:1074]Column length too big for column 'c' (max = 65535); use BLOB or TEXT instead, e.g. LONGTEXT")},
 
 		{"create table `t ` (a int)", true, errors.New("[ddl:1103]Incorrect table name 't '")},
 		{"create table `` (a int)", true, errors.New("[ddl:1103]Incorrect table name ''")},
@@ -136,6 +145,9 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"alter table t change column a `a ` int", true, errors.New("[ddl:1166]Incorrect column name 'a '")},
 		{"create index idx on `t ` (a)", true, errors.New("[ddl:1103]Incorrect table name 't '")},
 		{"create index idx on  `` (a)", true, errors.New("[ddl:1103]Incorrect table name ''")},
+		{"create table t like `t `", true, errors.New("[ddl:1103]Incorrect table name 't '")},
+		{"create table t as select a as `b ` from t1", true, errors.New("[ddl:1166]Incorrect column name 'b '")},
+		{"create table t as select a as b from t1", true, nil},
 
 		// issue 3844
 		{`create table t (a set("a, b", "c, d"))`, true, errors.New("[types:1367]Illegal set 'a, b' value found during parsing")},
@@ -155,6 +167,30 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		{"CREATE TABLE `t` (`a` float DEFAULT now());", false, types.ErrInvalidDefault},
 		{"CREATE TABLE `t` (`a` varchar(10) DEFAULT now());", false, types.ErrInvalidDefault},
 		{"CREATE TABLE `t` (`a` double DEFAULT 1.0 DEFAULT now() DEFAULT 2.0 );", false, nil},
+
+		// The expression of DEFAULT (expr) can't refer to columns, variables or subqueries.
+		{"CREATE TABLE `t` (`a` varchar(36) DEFAULT (uuid()));", false, nil},
+		{"CREATE TABLE `t` (`a` int DEFAULT (now() + 1));", false, nil},
+		{"CREATE TABLE `t` (`a` int, `b` int DEFAULT (a + 1));", false, types.ErrInvalidDefault},
+		{"CREATE TABLE `t` (`a` int DEFAULT (@x));", false, types.ErrInvalidDefault},
+		{"CREATE TABLE `t` (`a` int DEFAULT ((select 1)));", false, types.ErrInvalidDefault},
+		{"ALTER TABLE `t` ALTER `a` SET DEFAULT (count(1));", false, types.ErrInvalidDefault},
+
+		// ORDER BY and LIMIT are only allowed in the single-table syntax of UPDATE.
+		{"update t set a = 1 order by b limit 1", false, nil},
+		{"update t1 join t2 on t1.a = t2.a set t1.b = 1 order by t1.a", false, plan.ErrWrongUsage},
+		{"update t1 join t2 on t1.a = t2.a set t1.b = 1 limit 1", false, plan.ErrWrongUsage},
+		{"update t1 join t2 on t1.a = t2.a set t1.b = 1 returning t1.a", false, plan.ErrWrongUsage},
+
+		// The memory databases can't be changed, but their tables can be read.
+		{"create database INFORMATION_SCHEMA", false, plan.ErrDBaccessDenied},
+		{"drop table performance_schema.setup_actors", false, plan.ErrDBaccessDenied},
+		{"alter table t rename information_schema.t", false, plan.ErrDBaccessDenied},
+		{"insert into information_schema.tables (table_name) values ('t')", false, plan.ErrDBaccessDenied},
+		{"update t join information_schema.tables s set s.table_name = 't'", false, plan.ErrDBaccessDenied},
+		{"insert into t select table_name from information_schema.tables", false, nil},
+		{"update t join information_schema.tables s set t.a = s.table_name", false, nil},
+		{"create table t like information_schema.tables", false, nil},
 	}
 
 	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
@@ -167,7 +203,98 @@ func (s *testValidatorSuite) TestValidator(c *C) {
 		c.Assert(err1, IsNil)
 		c.Assert(stmts, HasLen, 1)
 		stmt := stmts[0]
-		err = plan.Validate(stmt, tt.inPrepare)
+		err = plan.Validate(stmt, tt.inPrepare, se.(context.Context))
 		c.Assert(terror.ErrorEqual(err, tt.err), IsTrue)
 	}
 }
+
+func (s *testValidatorSuite) TestValidatorNonStrictMode(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory)
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	ctx := se.(context.Context)
+	sessVars := ctx.GetSessionVars()
+	sessVars.StrictSQLMode = false
+
+	tests := []struct {
+		sql  string
+		tp   byte
+		warn string
+	}{
+		{"create table t (c varchar(30000))", mysql.TypeMediumBlob, "[types:1246]Converting column 'c' from VARCHAR to MEDIUMTEXT"},
+		{"alter table t add column c varchar(65536) CHARACTER SET ascii", mysql.TypeMediumBlob, "[types:1246]Converting column 'c' from VARCHAR to MEDIUMTEXT"},
+		{"create table t (c varbinary(16777216))", mysql.TypeLongBlob, "[types:1246]Converting column 'c' from VARBINARY to LONGBLOB"},
+	}
+	for _, tt := range tests {
+		sessVars.StmtCtx = new(variable.StatementContext)
+		stmts, err1 := tidb.Parse(ctx, tt.sql)
+		c.Assert(err1, IsNil)
+		c.Assert(stmts, HasLen, 1)
+		err = plan.Validate(stmts[0], false, ctx)
+		c.Assert(err, IsNil)
+		var colDef *ast.ColumnDef
+		switch x := stmts[0].(type) {
+		case *ast.CreateTableStmt:
+			colDef = x.Cols[0]
+		case *ast.AlterTableStmt:
+			colDef = x.Specs[0].NewColumn
+		}
+		c.Assert(colDef.Tp.Tp, Equals, tt.tp, Commentf("%s", tt.sql))
+		warns := sessVars.StmtCtx.GetWarnings()
+		c.Assert(warns, HasLen, 1)
+		c.Assert(warns[0].Error(), Equals, tt.warn)
+	}
+
+	// The length of CHAR is checked in non-strict mode too.
+	stmts, err := tidb.Parse(ctx, "create table t (c char(256))")
+	c.Assert(err, IsNil)
+	err = plan.Validate(stmts[0], false, ctx)
+	c.Assert(types.ErrTooBigFieldLength.Equal(err), IsTrue)
+}
+
+func (s *testValidatorSuite) TestValidatorAutoConvertLongString(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	se, err := tidb.CreateSession(store)
+	c.Assert(err, IsNil)
+	ctx := se.(context.Context)
+	sessVars := ctx.GetSessionVars()
+	c.Assert(sessVars.StrictSQLMode, IsTrue)
+
+	_, err = se.Execute("set @@tidb_auto_convert_long_string = 1")
+	c.Assert(err, IsNil)
+	c.Assert(sessVars.AutoConvertLongString, IsTrue)
+	tests := []struct {
+		sql  string
+		tp   byte
+		warn string
+	}{
+		{"create table t (c varchar(30000))", mysql.TypeMediumBlob, "[types:1246]Converting column 'c' from VARCHAR to MEDIUMTEXT"},
+		{"create table t (c char(256))", mysql.TypeBlob, "[types:1246]Converting column 'c' from CHAR to TEXT"},
+		{"alter table t add column c binary(256)", mysql.TypeBlob, "[types:1246]Converting column 'c' from BINARY to BLOB"},
+	}
+	for _, tt := range tests {
+		sessVars.StmtCtx = new(variable.StatementContext)
+		stmts, err1 := tidb.Parse(ctx, tt.sql)
+		c.Assert(err1, IsNil)
+		c.Assert(stmts, HasLen, 1)
+		err = plan.Validate(stmts[0], false, ctx)
+		c.Assert(err, IsNil)
+		var colDef *ast.ColumnDef
+		switch x := stmts[0].(type) {
+		case *ast.CreateTableStmt:
+			colDef = x.Cols[0]
+		case *ast.AlterTableStmt:
+			colDef = x.Specs[0].NewColumn
+		}
+		c.Assert(colDef.Tp.Tp, Equals, tt.tp, Commentf("%s", tt.sql))
+		warns := sessVars.StmtCtx.GetWarnings()
+		c.Assert(warns, HasLen, 1)
+		c.Assert(warns[0].Error(), Equals, tt.warn)
+	}
+}
@@ -245,6 +245,23 @@ func (s *testPrivilegeSuite) TestDropTablePriv(c *C) {
 	mustExec(c, se, `DROP TABLE todrop;`)
 }
 
+func (s *testPrivilegeSuite) TestMultiTableUpdatePriv(c *C) {
+	defer testleak.AfterTest(c)()
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE TABLE upd1(id int, a int);`)
+	mustExec(c, se, `CREATE TABLE upd2(id int, b int);`)
+	mustExec(c, se, `CREATE USER 'upd'@'localhost';`)
+	mustExec(c, se, `GRANT Select, Update ON test.upd1 TO 'upd'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.upd2 TO 'upd'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+
+	// Only the updated tables need the UPDATE privilege.
+	c.Assert(se.Auth(&auth.UserIdentity{Username: "upd", Hostname: "localhost"}, nil, nil), IsTrue)
+	mustExec(c, se, `UPDATE upd1, upd2 SET upd1.a = upd2.b WHERE upd1.id = upd2.id;`)
+	_, err := se.Execute(`UPDATE upd1, upd2 SET upd2.b = upd1.a WHERE upd1.id = upd2.id;`)
+	c.Assert(err, NotNil)
+}
+
 func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	defer testleak.AfterTest(c)()
 
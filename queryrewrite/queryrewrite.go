@@ -0,0 +1,190 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package queryrewrite rewrites the statements by the rules defined in the mysql.query_rewrite_rules table.
+//
+// A rule has a pattern and a replacement. A statement matches the rule when it has the same digest as the
+// pattern, see the sqldigest package, so the literals of the statement may differ from the pattern. The "?"
+// markers of the replacement are replaced by the literals of the statement in order, a list of the literals
+// like the IN list is a single literal without the parentheses. For example, the rule
+//
+//	pattern:     SELECT * FROM t WHERE a IN (1) AND b = 'x'
+//	replacement: SELECT * FROM t USE INDEX (idx_b) WHERE a IN (?) AND b = ? LIMIT 1000
+//
+// rewrites "select * from t where a in (1, 2, 3) and b = 'y'" to
+// "SELECT * FROM t USE INDEX (idx_b) WHERE a IN (1, 2, 3) AND b = 'y' LIMIT 1000".
+//
+// The rules are reloaded by the "ADMIN RELOAD REWRITE RULES" statement.
+package queryrewrite
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/sqldigest"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// Rule is a query rewrite rule.
+type Rule struct {
+	// Pattern is the statement to match.
+	Pattern string
+	// PatternDB is the current database the statement is matched in, empty means any database.
+	PatternDB string
+	// Replacement is the statement to rewrite to, its "?" markers are replaced by the literals of the statement.
+	Replacement string
+
+	digest  string
+	markers []int
+}
+
+// Rules is a set of query rewrite rules.
+type Rules struct {
+	// rules maps the digest of the patterns to the rules.
+	rules map[string][]*Rule
+}
+
+// NewRules returns an empty Rules.
+func NewRules() *Rules {
+	return &Rules{rules: make(map[string][]*Rule)}
+}
+
+// Add adds a rule, it returns an error if the replacement has more "?" markers than the literals of the pattern,
+// or a rule of the same pattern and database exists.
+func (r *Rules) Add(rule *Rule) error {
+	normalized, params := sqldigest.NormalizeParams(rule.Pattern)
+	rule.digest = sqldigest.Digest(normalized)
+	rule.markers = sqldigest.ParamMarkers(rule.Replacement)
+	if len(rule.markers) > len(params) {
+		return errors.Errorf("the replacement %q has %d markers but the pattern %q has %d literals",
+			rule.Replacement, len(rule.markers), rule.Pattern, len(params))
+	}
+	for _, other := range r.rules[rule.digest] {
+		if strings.EqualFold(other.PatternDB, rule.PatternDB) {
+			return errors.Errorf("duplicate rule of the pattern %q", rule.Pattern)
+		}
+	}
+	r.rules[rule.digest] = append(r.rules[rule.digest], rule)
+	return nil
+}
+
+// Len returns the number of the rules.
+func (r *Rules) Len() int {
+	var n int
+	for _, rules := range r.rules {
+		n += len(rules)
+	}
+	return n
+}
+
+// Rewrite rewrites the statement executed in the current database db, the rules of the database take precedence
+// over the rules of any database. It returns false if no rule matches.
+func (r *Rules) Rewrite(sql, db string) (string, bool) {
+	if len(r.rules) == 0 {
+		return "", false
+	}
+	normalized, params := sqldigest.NormalizeParams(sql)
+	var matched *Rule
+	for _, rule := range r.rules[sqldigest.Digest(normalized)] {
+		if strings.EqualFold(rule.PatternDB, db) {
+			matched = rule
+			break
+		}
+		if rule.PatternDB == "" {
+			matched = rule
+		}
+	}
+	if matched == nil {
+		return "", false
+	}
+	return matched.apply(params), true
+}
+
+func (rule *Rule) apply(params []string) string {
+	var buf bytes.Buffer
+	last := 0
+	for i, offset := range rule.markers {
+		buf.WriteString(rule.Replacement[last:offset])
+		buf.WriteString(params[i])
+		last = offset + 1
+	}
+	buf.WriteString(rule.Replacement[last:])
+	return buf.String()
+}
+
+var loadRulesSQL = fmt.Sprintf("SELECT pattern, pattern_db, replacement FROM %s.%s WHERE enabled = 1 ORDER BY id",
+	mysql.SystemDB, mysql.QueryRewriteRulesTable)
+
+// LoadAll loads the enabled rules from the mysql.query_rewrite_rules table. The invalid rules are skipped with
+// a warning log, so they don't disable the other rules.
+func (r *Rules) LoadAll(ctx context.Context) error {
+	tmp, err := ctx.(sqlexec.SQLExecutor).Execute(loadRulesSQL)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rs := tmp[0]
+	defer rs.Close()
+
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		rule := &Rule{
+			Pattern:     row.Data[0].GetString(),
+			PatternDB:   row.Data[1].GetString(),
+			Replacement: row.Data[2].GetString(),
+		}
+		if err = r.Add(rule); err != nil {
+			log.Warnf("[queryrewrite] skip the invalid rule: %v", err)
+		}
+	}
+	return nil
+}
+
+// Handle wraps Rules providing thread safe access.
+type Handle struct {
+	rules atomic.Value
+}
+
+// NewHandle returns a Handle of no rules.
+func NewHandle() *Handle {
+	h := &Handle{}
+	h.rules.Store(NewRules())
+	return h
+}
+
+// Get returns the Rules for read.
+func (h *Handle) Get() *Rules {
+	return h.rules.Load().(*Rules)
+}
+
+// Update loads all the rules from kv storage.
+func (h *Handle) Update(ctx context.Context) error {
+	rules := NewRules()
+	err := rules.LoadAll(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	h.rules.Store(rules)
+	return nil
+}
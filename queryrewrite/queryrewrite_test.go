@@ -0,0 +1,75 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryrewrite
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testQueryRewriteSuite{})
+
+type testQueryRewriteSuite struct{}
+
+func (s *testQueryRewriteSuite) TestAdd(c *C) {
+	defer testleak.AfterTest(c)()
+	rules := NewRules()
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a = 1", Replacement: "select * from t where a = ? limit 1"}), IsNil)
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a = 2", PatternDB: "test", Replacement: "select * from t"}), IsNil)
+	// The same pattern of the same database.
+	c.Assert(rules.Add(&Rule{Pattern: "SELECT * FROM t WHERE a = 3", Replacement: "select 1"}), NotNil)
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a = 3", PatternDB: "TEST", Replacement: "select 1"}), NotNil)
+	// More markers than the literals.
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t", Replacement: "select * from t limit ?"}), NotNil)
+	// The markers in the strings are not markers.
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t", Replacement: "select '?' from t"}), IsNil)
+	c.Assert(rules.Len(), Equals, 3)
+}
+
+func (s *testQueryRewriteSuite) TestRewrite(c *C) {
+	defer testleak.AfterTest(c)()
+	rules := NewRules()
+	_, ok := rules.Rewrite("select * from t", "test")
+	c.Assert(ok, IsFalse)
+
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a in (1) and b = 'x'", Replacement: "select * from t use index (b) where a in (?) and b = ?"}), IsNil)
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a = 1", Replacement: "select * from t where a = ? limit 1"}), IsNil)
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a = 1", PatternDB: "test", Replacement: "select * from t where a = ? limit 2"}), IsNil)
+	c.Assert(rules.Add(&Rule{Pattern: "insert into t values (1, 2)", Replacement: "insert ignore into t values (?)"}), IsNil)
+	c.Assert(rules.Add(&Rule{Pattern: "select * from t where a = 1 and b = 2", Replacement: "select * from t where b = ? and a = ?"}), IsNil)
+	tests := []struct {
+		sql       string
+		db        string
+		rewritten string
+	}{
+		{"SELECT * FROM t WHERE a IN (1, 2, 3) AND b = 'y'", "", "select * from t use index (b) where a in (1, 2, 3) and b = 'y'"},
+		{"select * from t where a = 5", "", "select * from t where a = 5 limit 1"},
+		{"select * from t where a = 5", "Test", "select * from t where a = 5 limit 2"},
+		{"select * from t where a = 5", "test1", "select * from t where a = 5 limit 1"},
+		{"insert into t values (1, 'a'), (2, 'b')", "", "insert ignore into t values (1, 'a'), (2, 'b')"},
+		{"select * from t where a = 1 and b = 'x'", "", "select * from t where b = 1 and a = 'x'"},
+		{"select * from t where b = 1", "", ""},
+	}
+	for _, t := range tests {
+		rewritten, ok := rules.Rewrite(t.sql, t.db)
+		c.Assert(ok, Equals, t.rewritten != "", Commentf("sql %s", t.sql))
+		c.Assert(rewritten, Equals, t.rewritten, Commentf("sql %s", t.sql))
+	}
+}
@@ -16,6 +16,9 @@ package server
 import (
 	"bufio"
 	"net"
+	"time"
+
+	"github.com/juju/errors"
 )
 
 const defaultReaderSize = 16 * 1024
@@ -23,16 +26,65 @@ const defaultReaderSize = 16 * 1024
 // bufferedReadConn is a net.Conn compatible structure that reads from bufio.Reader.
 type bufferedReadConn struct {
 	net.Conn
-	rb *bufio.Reader
+	rb       *bufio.Reader
+	timeouts *timeoutConn
 }
 
 func (conn bufferedReadConn) Read(b []byte) (n int, err error) {
 	return conn.rb.Read(b)
 }
 
+// buffered returns the number of bytes received but not read yet.
+func (conn bufferedReadConn) buffered() int {
+	return conn.rb.Buffered()
+}
+
+// setTimeouts sets the timeouts of the following reads and writes of the connection, 0 means no timeout.
+func (conn bufferedReadConn) setTimeouts(readTimeout, writeTimeout time.Duration) {
+	conn.timeouts.readTimeout = readTimeout
+	conn.timeouts.writeTimeout = writeTimeout
+}
+
+// waitReadable waits for the data to read with the read timeout, the data isn't consumed. The deadline is cleared
+// after it, so the following reads without the timeout are not affected.
+func (conn bufferedReadConn) waitReadable() error {
+	_, err := conn.rb.Peek(1)
+	if err1 := conn.Conn.SetReadDeadline(time.Time{}); err1 != nil && err == nil {
+		err = errors.Trace(err1)
+	}
+	return err
+}
+
 func newBufferedReadConn(conn net.Conn) *bufferedReadConn {
+	tc := &timeoutConn{Conn: conn}
 	return &bufferedReadConn{
-		Conn: conn,
-		rb:   bufio.NewReaderSize(conn, defaultReaderSize),
+		Conn:     tc,
+		rb:       bufio.NewReaderSize(tc, defaultReaderSize),
+		timeouts: tc,
+	}
+}
+
+// timeoutConn sets the deadline before each read and write of the connection.
+type timeoutConn struct {
+	net.Conn
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func (conn *timeoutConn) Read(b []byte) (int, error) {
+	if conn.readTimeout > 0 {
+		if err := conn.Conn.SetReadDeadline(time.Now().Add(conn.readTimeout)); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	return conn.Conn.Read(b)
+}
+
+func (conn *timeoutConn) Write(b []byte) (int, error) {
+	if conn.writeTimeout > 0 {
+		if err := conn.Conn.SetWriteDeadline(time.Now().Add(conn.writeTimeout)); err != nil {
+			return 0, errors.Trace(err)
+		}
 	}
+	return conn.Conn.Write(b)
 }
@@ -38,12 +38,14 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -51,12 +53,24 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/types"
 )
 
+// SessionVarsAttr is the connection attribute to set session variables at the connection, it saves the round trips
+// of the SET statements for the stateless proxies which restore the sessions of their clients on new connections.
+// The value is a JSON object of the names and the string values of the variables, for example
+// {"sql_mode": "ANSI_QUOTES", "time_zone": "+08:00"}. The variables are set after the authentication, and none of
+// them is set and the connection is refused if any of them can't be set. tidb_snapshot can't be set by it.
+const SessionVarsAttr = "tidb_session_vars"
+
 // clientConn represents a connection between server and client, it maintains connection specific state,
 // handles client query.
 type clientConn struct {
@@ -65,7 +79,7 @@ type clientConn struct {
 	tlsConn      *tls.Conn         // TLS connection, nil if not TLS.
 	server       *Server           // a reference of server instance.
 	capability   uint32            // client capability affects the way server handles client request.
-	connectionID uint32            // atomically allocated by a global variable, unique in process scope.
+	connectionID uint64            // the server ID followed by the ID allocated in the server, unique in the cluster.
 	collation    uint8             // collation used by client, may be different from the collation used by database.
 	user         string            // user of the client.
 	dbname       string            // default database name.
@@ -73,8 +87,10 @@ type clientConn struct {
 	alloc        arena.Allocator   // an memory allocator for reducing memory allocation.
 	lastCmd      string            // latest sql query string, currently used for logging error.
 	ctx          QueryCtx          // an interface to execute sql statements.
-	attrs        map[string]string // attributes parsed from client handshake response, not used for now.
+	attrs        map[string]string // attributes parsed from client handshake response.
 	killed       bool
+	// idleTxnTimeout is set when the idle transaction is rolled back, the next statement fails to report it.
+	idleTxnTimeout time.Duration
 }
 
 func (cc *clientConn) String() string {
@@ -84,6 +100,15 @@ func (cc *clientConn) String() string {
 	)
 }
 
+// logger returns the log entry of the server module with the connection id and the user of the connection.
+func (cc *clientConn) logger() *log.Entry {
+	fields := log.Fields{logutil.FieldConnID: cc.connectionID}
+	if cc.user != "" {
+		fields[logutil.FieldUser] = cc.user
+	}
+	return logutil.Logger(logutil.ModuleServer).WithFields(fields)
+}
+
 // handshake works like TCP handshake, but in a higher level, it first writes initial packet to client,
 // during handshake, client and server negotiate compatible features and do authentication.
 // After handshake, client can send sql query to server.
@@ -315,9 +340,9 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse() error {
 		return errors.Trace(err)
 	}
 
-	if (resp.Capability&mysql.ClientSSL > 0) && cc.server.tlsConfig != nil {
+	if tlsConfig := cc.server.getTLSConfig(); (resp.Capability&mysql.ClientSSL > 0) && tlsConfig != nil {
 		// The packet is a SSLRequest, let's switch to TLS.
-		if err = cc.upgradeToTLS(cc.server.tlsConfig); err != nil {
+		if err = cc.upgradeToTLS(tlsConfig); err != nil {
 			return errors.Trace(err)
 		}
 		// Read the following HandshakeResponse packet.
@@ -348,7 +373,7 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse() error {
 		tlsState := cc.tlsConn.ConnectionState()
 		tlsStatePtr = &tlsState
 	}
-	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), cc.dbname, tlsStatePtr)
+	cc.ctx, err = cc.server.driver.OpenCtx(cc.connectionID, cc.capability, uint8(cc.collation), cc.dbname, tlsStatePtr)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -369,10 +394,34 @@ func (cc *clientConn) readOptionalSSLRequestAndHandshakeResponse() error {
 			return errors.Trace(err)
 		}
 	}
+	if blob, ok := cc.attrs[SessionVarsAttr]; ok {
+		if err = cc.setSessionVars(blob); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	cc.ctx.SetSessionManager(cc.server)
 	return nil
 }
 
+// setSessionVars sets the session variables in the value of SessionVarsAttr.
+func (cc *clientConn) setSessionVars(blob string) error {
+	var values map[string]string
+	if err := json.Unmarshal([]byte(blob), &values); err != nil {
+		return errors.Errorf("invalid %s attribute: %v", SessionVarsAttr, err)
+	}
+	for name := range values {
+		// The snapshot is checked against the GC safe point and its schema is loaded by the SET statement.
+		if variable.ResolveSysVarAlias(strings.ToLower(name)) == variable.TiDBSnapshot {
+			return errors.Errorf("variable '%s' can't be set by the %s attribute", name, SessionVarsAttr)
+		}
+	}
+	if err := varsutil.SetSessionSystemVars(cc.ctx.GetSessionVars(), values); err != nil {
+		return errors.Trace(err)
+	}
+	cc.logger().Infof("set %d session variables by the %s attribute", len(values), SessionVarsAttr)
+	return nil
+}
+
 // Run reads client query and writes query result to client in for loop, if there is a panic during query handling,
 // it will be recovered and log the panic error.
 // This function returns and the connection is closed if there is an IO error or there is a panic.
@@ -391,39 +440,63 @@ func (cc *clientConn) Run() {
 
 	for !cc.killed {
 		cc.alloc.Reset()
+		// Write the deferred responses of the pipelined commands before waiting for the next command.
+		if err := cc.flush(); err != nil {
+			if isTimeoutError(err) {
+				atomic.AddInt64(&timedOutConns, 1)
+			}
+			cc.logger().Errorf("write packet error, close this connection %s", errors.ErrorStack(err))
+			return
+		}
+		waitTimeout, readTimeout, writeTimeout := cc.timeouts()
+		waitTimeout, err := cc.rollbackIdleTxn(waitTimeout, writeTimeout)
+		if err != nil {
+			cc.logger().Errorf("rollback the idle transaction error, close this connection %s", errors.ErrorStack(err))
+			return
+		}
+		cc.bufReadConn.setTimeouts(waitTimeout, writeTimeout)
+		cc.pkt.maxAllowedPacket = cc.ctx.GetSessionVars().MaxAllowedPacket
 		data, err := cc.readPacket()
 		if err != nil || cc.killed {
-			if terror.ErrorNotEqual(err, io.EOF) {
-				log.Errorf("[%d] read packet error, close this connection %s",
-					cc.connectionID, errors.ErrorStack(err))
+			if errNetPacketTooLarge.Equal(err) {
+				// The rest of the packet isn't read, so the connection can't be used any more.
+				cc.logger().Warnf("%v, close this connection", err)
+				cc.writeError(err)
+				cc.pkt.flush()
+			} else if isTimeoutError(err) {
+				atomic.AddInt64(&timedOutConns, 1)
+				cc.logger().Infof("no command is received in %v, close this connection", waitTimeout)
+			} else if terror.ErrorNotEqual(err, io.EOF) {
+				cc.logger().Errorf("read packet error, close this connection %s", errors.ErrorStack(err))
 			}
 			if cc.killed {
-				log.Warnf("[%d] session is killed.", cc.connectionID)
+				cc.logger().Warn("session is killed.")
 			}
 			return
 		}
 
+		cc.bufReadConn.setTimeouts(readTimeout, writeTimeout)
 		startTime := time.Now()
 		if err = cc.dispatch(data); err != nil {
 			if terror.ErrorEqual(err, io.EOF) {
 				cc.addMetrics(data[0], startTime, nil)
+				// The client quits, write the responses of the commands before it.
+				cc.pkt.flush()
 				return
 			} else if terror.ErrResultUndetermined.Equal(err) {
-				log.Errorf("[%d] result undetermined error, close this connection %s",
-					cc.connectionID, errors.ErrorStack(err))
+				cc.logger().Errorf("result undetermined error, close this connection %s", errors.ErrorStack(err))
 				return
 			} else if terror.ErrCritical.Equal(err) {
-				log.Errorf("[%d] critical error, stop the server listener %s",
-					cc.connectionID, errors.ErrorStack(err))
+				cc.logger().Errorf("critical error, stop the server listener %s", errors.ErrorStack(err))
 				criticalErrorCounter.Add(1)
+				atomic.StoreInt32(&cc.server.criticalErr, 1)
 				select {
 				case cc.server.stopListenerCh <- struct{}{}:
 				default:
 				}
 				return
 			}
-			log.Warnf("[%d] dispatch error:\n%s\n%s\n%s",
-				cc.connectionID, cc, queryStrForLog(string(data[1:])), errStrForLog(err))
+			cc.logger().Warnf("dispatch error:\n%s\n%s\n%s", cc, queryStrForLog(string(data[1:])), errStrForLog(err))
 			cc.writeError(err)
 		}
 		cc.addMetrics(data[0], startTime, err)
@@ -431,6 +504,41 @@ func (cc *clientConn) Run() {
 	}
 }
 
+// rollbackIdleTxn waits for the next command of a connection in a transaction for tidb_idle_transaction_timeout,
+// the transaction is rolled back if no command is received, and the rest of the wait timeout is returned.
+func (cc *clientConn) rollbackIdleTxn(waitTimeout, writeTimeout time.Duration) (time.Duration, error) {
+	idleTimeout := time.Duration(cc.ctx.GetSessionVars().IdleTransactionTimeout) * time.Second
+	if idleTimeout == 0 || cc.ctx.Status()&mysql.ServerStatusInTrans == 0 {
+		return waitTimeout, nil
+	}
+	if waitTimeout > 0 && waitTimeout <= idleTimeout {
+		return waitTimeout, nil
+	}
+	cc.bufReadConn.setTimeouts(idleTimeout, writeTimeout)
+	// The other errors are returned by the following read.
+	if err := cc.bufReadConn.waitReadable(); err == nil || !isTimeoutError(err) {
+		return waitTimeout, nil
+	}
+	cc.logger().Infof("the transaction is idle for %v, roll it back", idleTimeout)
+	cc.idleTxnTimeout = idleTimeout
+	if waitTimeout > 0 {
+		waitTimeout -= idleTimeout
+	}
+	return waitTimeout, errors.Trace(cc.ctx.RollbackTxn())
+}
+
+// timeouts returns the wait_timeout, net_read_timeout and net_write_timeout of the session.
+func (cc *clientConn) timeouts() (waitTimeout, readTimeout, writeTimeout time.Duration) {
+	vars := cc.ctx.GetSessionVars()
+	return time.Duration(vars.WaitTimeout) * time.Second, time.Duration(vars.NetReadTimeout) * time.Second,
+		time.Duration(vars.NetWriteTimeout) * time.Second
+}
+
+func isTimeoutError(err error) bool {
+	netErr, ok := errors.Cause(err).(net.Error)
+	return ok && netErr.Timeout()
+}
+
 func queryStrForLog(query string) string {
 	const size = 4096
 	if len(query) > size {
@@ -497,6 +605,27 @@ func (cc *clientConn) dispatch(data []byte) error {
 		cc.server.releaseToken(token)
 	}()
 
+	switch cmd {
+	case mysql.ComInitDB, mysql.ComFieldList:
+		text, err := cc.clientString(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		data = hack.Slice(text)
+	case mysql.ComQuery, mysql.ComStmtPrepare:
+		// The statements keep the bytes sent by the client, so the binary strings in them aren't changed. The
+		// parser decodes the string literals from character_set_connection.
+		if err := cc.checkClientText(data); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if cc.idleTxnTimeout > 0 && (cmd == mysql.ComQuery || cmd == mysql.ComStmtExecute) {
+		idleTimeout := cc.idleTxnTimeout
+		cc.idleTxnTimeout = 0
+		return errIdleTxnRolledBack.GenByArgs(int64(idleTimeout / time.Second))
+	}
+
 	switch cmd {
 	case mysql.ComSleep:
 		// TODO: According to mysql document, this command is supposed to be used only internally.
@@ -542,8 +671,8 @@ func (cc *clientConn) dispatch(data []byte) error {
 
 func (cc *clientConn) useDB(db string) (err error) {
 	// if input is "use `SELECT`", mysql client just send "SELECT"
-	// so we add `` around db.
-	_, err = cc.ctx.Execute("use `" + db + "`")
+	// so we add `` around db, the backticks in the name are doubled.
+	_, err = cc.ctx.Execute("use `" + strings.Replace(db, "`", "``", -1) + "`")
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -551,7 +680,13 @@ func (cc *clientConn) useDB(db string) (err error) {
 	return
 }
 
+// flush writes the buffered packets to the client. If the next commands of the client are already
+// received, the flush is deferred, so the responses of the pipelined commands are written together,
+// the Run loop flushes them before waiting for the next command.
 func (cc *clientConn) flush() error {
+	if cc.bufReadConn != nil && cc.bufReadConn.buffered() > 0 {
+		return nil
+	}
 	return cc.pkt.flush()
 }
 
@@ -574,18 +709,7 @@ func (cc *clientConn) writeOK() error {
 }
 
 func (cc *clientConn) writeError(e error) error {
-	var (
-		m  *mysql.SQLError
-		te *terror.Error
-		ok bool
-	)
-	originErr := errors.Cause(e)
-	if te, ok = originErr.(*terror.Error); ok {
-		m = te.ToSQLError()
-	} else {
-		m = mysql.NewErrf(mysql.ErrUnknown, "%s", e.Error())
-	}
-
+	m := terror.ToSQLError(e)
 	data := cc.alloc.AllocWithLen(4, 16+len(m.Message))
 	data = append(data, mysql.ErrHeader)
 	data = append(data, byte(m.Code), byte(m.Code>>8))
@@ -756,8 +880,12 @@ func (cc *clientConn) handleFieldList(sql string) (err error) {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	resultsCharset := cc.resultsCharset()
 	data := make([]byte, 4, 1024)
 	for _, v := range columns {
+		if resultsCharset != "" {
+			v = convertColumn(v, resultsCharset)
+		}
 		data = data[0:4]
 		data = append(data, v.Dump(cc.alloc)...)
 		if err := cc.writePacket(data); err != nil {
@@ -770,6 +898,79 @@ func (cc *clientConn) handleFieldList(sql string) (err error) {
 	return errors.Trace(cc.flush())
 }
 
+// clientString converts the text sent by the client from character_set_client to UTF-8. The bytes that are invalid
+// in character_set_client are replaced by '?', it's an error in the strict SQL mode.
+func (cc *clientConn) clientString(data []byte) (string, error) {
+	vars := cc.ctx.GetSessionVars()
+	cs := vars.Systems[variable.CharacterSetClient]
+	if !charset.NeedConvert(cs) {
+		return hack.String(data), nil
+	}
+	text, ok := charset.DecodeString(cs, data)
+	if !ok && vars.StrictSQLMode {
+		return "", errInvalidCharacterString.GenByArgs(cs, text)
+	}
+	return text, nil
+}
+
+// checkClientText checks the text sent by the client is valid in character_set_client in the strict SQL mode.
+func (cc *clientConn) checkClientText(data []byte) error {
+	vars := cc.ctx.GetSessionVars()
+	cs := vars.Systems[variable.CharacterSetClient]
+	if !vars.StrictSQLMode || charset.IsValid(cs, data) {
+		return nil
+	}
+	text, _ := charset.DecodeString(cs, data)
+	return errInvalidCharacterString.GenByArgs(cs, text)
+}
+
+// resultsCharset returns the character_set_results of the session if the results are converted to it, otherwise
+// it returns "". A NULL character_set_results means the results aren't converted.
+func (cc *clientConn) resultsCharset() string {
+	cs := cc.ctx.GetSessionVars().Systems[variable.CharacterSetResults]
+	if !charset.NeedConvert(cs) {
+		return ""
+	}
+	return cs
+}
+
+// convertColumn returns the column whose names are converted to the charset cs, the charset of a non-binary column
+// is cs too.
+func convertColumn(column *ColumnInfo, cs string) *ColumnInfo {
+	ci := *column
+	ci.Schema = encodeString(cs, ci.Schema)
+	ci.Table = encodeString(cs, ci.Table)
+	ci.OrgTable = encodeString(cs, ci.OrgTable)
+	ci.Name = encodeString(cs, ci.Name)
+	ci.OrgName = encodeString(cs, ci.OrgName)
+	if ci.Charset != uint16(mysql.CharsetIDs[charset.CharsetBin]) {
+		ci.Charset = uint16(mysql.CharsetIDs[cs])
+	}
+	return &ci
+}
+
+// convertRow converts the strings of the non-binary columns of the row to the charset cs.
+func convertRow(columns []*ColumnInfo, row []types.Datum, cs string) []types.Datum {
+	converted := make([]types.Datum, len(row))
+	for i, value := range row {
+		converted[i] = value
+		if value.Kind() != types.KindString && value.Kind() != types.KindBytes {
+			continue
+		}
+		if columns[i].Charset == uint16(mysql.CharsetIDs[charset.CharsetBin]) {
+			continue
+		}
+		b, _ := charset.EncodeString(cs, value.GetBytes())
+		converted[i].SetBytes(b)
+	}
+	return converted
+}
+
+func encodeString(cs string, s string) string {
+	b, _ := charset.EncodeString(cs, hack.Slice(s))
+	return string(b)
+}
+
 // writeResultset writes a resultset.
 // If binary is true, the data would be encoded in BINARY format.
 // If more is true, a flag bit would be set to indicate there are more
@@ -787,6 +988,7 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	if err != nil {
 		return errors.Trace(err)
 	}
+	resultsCharset := cc.resultsCharset()
 
 	columnLen := dumpLengthEncodedInt(uint64(len(columns)))
 	data := cc.alloc.AllocWithLen(4, 1024)
@@ -796,6 +998,9 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 	}
 
 	for _, v := range columns {
+		if resultsCharset != "" {
+			v = convertColumn(v, resultsCharset)
+		}
 		data = data[0:4]
 		data = append(data, v.Dump(cc.alloc)...)
 		if err = cc.writePacket(data); err != nil {
@@ -814,6 +1019,9 @@ func (cc *clientConn) writeResultset(rs ResultSet, binary bool, more bool) error
 		if row == nil {
 			break
 		}
+		if resultsCharset != "" {
+			row = convertRow(columns, row, resultsCharset)
+		}
 		data = data[0:4]
 		if binary {
 			var rowData []byte
@@ -17,9 +17,11 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"net"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util"
 )
 
 type ConnTestSuite struct{}
@@ -119,7 +121,7 @@ func (ts ConnTestSuite) TestInitialHandshake(c *C) {
 	c.Parallel()
 	var outBuffer bytes.Buffer
 	cc := &clientConn{
-		connectionID: 1,
+		connectionID: 1<<util.LocalConnIDBits | 1,
 		salt:         []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10, 0x11, 0x12, 0x13, 0x14},
 		server: &Server{
 			capability: defaultCapability,
@@ -135,7 +137,7 @@ func (ts ConnTestSuite) TestInitialHandshake(c *C) {
 	expected.WriteByte(0x0a)                                                                             // Protocol
 	expected.WriteString(mysql.ServerVersion)                                                            // Version
 	expected.WriteByte(0x00)                                                                             // NULL
-	binary.Write(expected, binary.LittleEndian, uint32(1))                                               // Connection ID
+	binary.Write(expected, binary.LittleEndian, uint32(1<<util.LocalConnIDBits|1))                       // Connection ID
 	expected.Write([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x00})                         // Salt
 	binary.Write(expected, binary.LittleEndian, uint16(defaultCapability&0xFFFF))                        // Server Capability
 	expected.WriteByte(uint8(mysql.DefaultCollationID))                                                  // Server Language
@@ -149,6 +151,96 @@ func (ts ConnTestSuite) TestInitialHandshake(c *C) {
 	c.Assert(outBuffer.Bytes()[4:], DeepEquals, expected.Bytes())
 }
 
+// bytesConn is a net.Conn reading from a buffer and recording the writes.
+type bytesConn struct {
+	net.Conn
+	in     *bytes.Buffer
+	writes [][]byte
+}
+
+func (c *bytesConn) Read(b []byte) (int, error) {
+	return c.in.Read(b)
+}
+
+func (c *bytesConn) Write(b []byte) (int, error) {
+	c.writes = append(c.writes, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (ts ConnTestSuite) TestPipelinedFlush(c *C) {
+	c.Parallel()
+	in := new(bytes.Buffer)
+	// Two COM_PING packets sent together.
+	for i := 0; i < 2; i++ {
+		in.Write([]byte{0x01, 0x00, 0x00, 0x00, mysql.ComPing})
+	}
+	conn := &bytesConn{in: in}
+	cc := &clientConn{}
+	cc.setConn(conn)
+
+	data, err := cc.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, []byte{mysql.ComPing})
+	c.Assert(cc.writePacket([]byte{0x00, 0x00, 0x00, 0x00, mysql.OKHeader}), IsNil)
+	// The next command is received, so the response is not flushed.
+	c.Assert(cc.flush(), IsNil)
+	c.Assert(conn.writes, HasLen, 0)
+
+	cc.pkt.sequence = 0
+	data, err = cc.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, []byte{mysql.ComPing})
+	c.Assert(cc.writePacket([]byte{0x00, 0x00, 0x00, 0x00, mysql.OKHeader}), IsNil)
+	c.Assert(cc.flush(), IsNil)
+	// Both responses are written at once.
+	c.Assert(conn.writes, HasLen, 1)
+	c.Assert(conn.writes[0], DeepEquals, []byte{0x01, 0x00, 0x00, 0x01, mysql.OKHeader, 0x01, 0x00, 0x00, 0x01, mysql.OKHeader})
+}
+
+func (ts ConnTestSuite) TestMaxAllowedPacket(c *C) {
+	c.Parallel()
+	// A payload of MaxPayloadLen+1 bytes is split into two packets.
+	in := new(bytes.Buffer)
+	in.Write([]byte{0xff, 0xff, 0xff, 0x00})
+	in.Write(bytes.Repeat([]byte{'a'}, mysql.MaxPayloadLen))
+	in.Write([]byte{0x01, 0x00, 0x00, 0x01, 'b'})
+	conn := &bytesConn{in: in}
+	cc := &clientConn{}
+	cc.setConn(conn)
+	cc.pkt.maxAllowedPacket = mysql.MaxPayloadLen + 1
+	data, err := cc.readPacket()
+	c.Assert(err, IsNil)
+	c.Assert(data, HasLen, mysql.MaxPayloadLen+1)
+	c.Assert(data[len(data)-1], Equals, byte('b'))
+
+	// The written payload is split in the same way.
+	cc.pkt.sequence = 0
+	c.Assert(cc.writePacket(append(make([]byte, 4), data...)), IsNil)
+	c.Assert(cc.pkt.flush(), IsNil)
+	written := bytes.Join(conn.writes, nil)
+	c.Assert(written[:4], DeepEquals, []byte{0xff, 0xff, 0xff, 0x00})
+	c.Assert(written[4+mysql.MaxPayloadLen:], DeepEquals, []byte{0x01, 0x00, 0x00, 0x01, 'b'})
+
+	// The payload of a multi-packet is counted as a whole.
+	in.Write([]byte{0xff, 0xff, 0xff, 0x00})
+	in.Write(bytes.Repeat([]byte{'a'}, mysql.MaxPayloadLen))
+	in.Write([]byte{0x02, 0x00, 0x00, 0x01, 'b', 'c'})
+	cc.pkt.sequence = 0
+	_, err = cc.readPacket()
+	c.Assert(errNetPacketTooLarge.Equal(err), IsTrue)
+
+	cc.pkt.maxAllowedPacket = 1024
+	in.Reset()
+	in.Write([]byte{0x01, 0x04, 0x00, 0x00})
+	in.Write(make([]byte, 1025))
+	cc.pkt.sequence = 0
+	_, err = cc.readPacket()
+	c.Assert(errNetPacketTooLarge.Equal(err), IsTrue)
+	err = cc.writePacket(make([]byte, 4+1025))
+	c.Assert(errNetPacketTooLarge.Equal(err), IsTrue)
+	c.Assert(cc.writePacket(make([]byte, 4+1024)), IsNil)
+}
+
 func mapIdentical(m1, m2 map[string]string) bool {
 	return mapBelong(m1, m2) && mapBelong(m2, m1)
 }
@@ -16,7 +16,10 @@ package server
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/types"
@@ -26,6 +29,14 @@ import (
 type IDriver interface {
 	// OpenCtx opens an IContext with connection id, client capability, collation, dbname and optionally the tls state.
 	OpenCtx(connID uint64, capability uint32, collation uint8, dbname string, tlsState *tls.ConnectionState) (QueryCtx, error)
+
+	// AllocServerID allocates the ID of the server instance, it's unique among the live servers in the cluster,
+	// not 0 and not greater than util.MaxServerID.
+	AllocServerID() (uint32, error)
+
+	// RegisterServer registers the server instance in the cluster, the registration is removed when the returned
+	// Closer is closed.
+	RegisterServer(info *model.ServerInfo) (io.Closer, error)
 }
 
 // QueryCtx is the interface to execute command.
@@ -85,6 +96,9 @@ type QueryCtx interface {
 
 	// Cancel the execution of current transaction.
 	Cancel()
+
+	// GetSessionVars returns the session variables.
+	GetSessionVars() *variable.SessionVars
 }
 
 // PreparedStatement is the interface to use a prepared statement.
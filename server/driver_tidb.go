@@ -16,14 +16,20 @@ package server
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/serverinfo"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -123,6 +129,46 @@ func (ts *TiDBStatement) Close() error {
 	return nil
 }
 
+// AllocServerID implements IDriver.
+// The generated IDs wrap around at util.MaxServerID, the IDs of the live servers in the cluster are skipped.
+func (qd *TiDBDriver) AllocServerID() (uint32, error) {
+	servers, err := serverinfo.ListServers(qd.store)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(servers) >= util.MaxServerID {
+		return 0, errTooManyServers
+	}
+	live := make(map[uint32]bool, len(servers))
+	for _, info := range servers {
+		live[info.ID] = true
+	}
+	for {
+		var id int64
+		err = kv.RunInNewTxn(qd.store, true, func(txn kv.Transaction) error {
+			var err1 error
+			id, err1 = meta.NewMeta(txn).GenServerID()
+			return errors.Trace(err1)
+		})
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		serverID := uint32((id-1)%util.MaxServerID + 1)
+		if !live[serverID] {
+			return serverID, nil
+		}
+	}
+}
+
+// RegisterServer implements IDriver.
+func (qd *TiDBDriver) RegisterServer(info *model.ServerInfo) (io.Closer, error) {
+	r, err := serverinfo.Register(qd.store, info)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
 // OpenCtx implements IDriver.
 func (qd *TiDBDriver) OpenCtx(connID uint64, capability uint32, collation uint8, dbname string, tlsState *tls.ConnectionState) (QueryCtx, error) {
 	session, err := tidb.CreateSession(qd.store)
@@ -247,7 +293,19 @@ func (tc *TiDBContext) GetStatement(stmtID int) PreparedStatement {
 	if tcStmt != nil {
 		return tcStmt
 	}
-	return nil
+	// The statement may be restored by SET SESSION_STATES after the connection is migrated from another server.
+	prepared, ok := tc.session.GetSessionVars().PreparedStmts[uint32(stmtID)].(*executor.Prepared)
+	if !ok {
+		return nil
+	}
+	tcStmt = &TiDBStatement{
+		id:          uint32(stmtID),
+		numParams:   len(prepared.Params),
+		boundParams: make([][]byte, len(prepared.Params)),
+		ctx:         tc,
+	}
+	tc.stmts[stmtID] = tcStmt
+	return tcStmt
 }
 
 // Prepare implements QueryCtx Prepare method.
@@ -287,6 +345,11 @@ func (tc *TiDBContext) Cancel() {
 	tc.session.Cancel()
 }
 
+// GetSessionVars implements QueryCtx GetSessionVars method.
+func (tc *TiDBContext) GetSessionVars() *variable.SessionVars {
+	return tc.session.GetSessionVars()
+}
+
 type tidbResultSet struct {
 	recordSet ast.RecordSet
 }
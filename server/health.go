@@ -0,0 +1,150 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/meta"
+)
+
+// healthCheckTimeout is the max time to wait for the store in a readiness check.
+const healthCheckTimeout = 3 * time.Second
+
+// health is the result of a liveness or readiness check.
+type health struct {
+	Live           bool   `json:"live"`
+	Ready          bool   `json:"ready"`
+	Draining       bool   `json:"draining"`
+	SchemaValid    bool   `json:"schema_valid"`
+	StoreConnected bool   `json:"store_connected"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Drain marks the server as draining, the readiness check fails so the load balancers stop routing new
+// connections to it, while the existing connections are still served until the server is closed.
+func (s *Server) Drain() {
+	if atomic.CompareAndSwapInt32(&s.draining, 0, 1) {
+		log.Info("the server is draining")
+	}
+}
+
+func (s *Server) isLive() bool {
+	return atomic.LoadInt32(&s.criticalErr) == 0
+}
+
+func (s *Server) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
+
+// handleLiveness reports whether the server is alive, it fails after a critical error stops the listener,
+// then the server should be restarted.
+func (s *Server) handleLiveness(w http.ResponseWriter, req *http.Request) {
+	h := health{Live: s.isLive()}
+	writeHealth(w, h, h.Live)
+}
+
+// handleReadiness reports whether the server is ready to serve new connections, that's the server isn't draining,
+// the store is connected, and the schema is loaded and not expired.
+func (s *Server) handleReadiness(w http.ResponseWriter, req *http.Request) {
+	h := health{
+		Live:     s.isLive(),
+		Draining: s.isDraining(),
+	}
+	if err := s.checkStore(); err != nil {
+		h.Error = err.Error()
+	} else {
+		h.StoreConnected = true
+		if err = s.checkSchema(); err != nil {
+			h.Error = err.Error()
+		} else {
+			h.SchemaValid = true
+		}
+	}
+	h.Ready = h.Live && !h.Draining && h.StoreConnected && h.SchemaValid
+	writeHealth(w, h, h.Ready)
+}
+
+// checkStore reads the schema version from the store to make sure the store can be read.
+func (s *Server) checkStore() error {
+	driver, ok := s.driver.(*TiDBDriver)
+	if !ok {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		ver, err := driver.store.CurrentVersion()
+		if err != nil {
+			done <- errors.Trace(err)
+			return
+		}
+		snapshot, err := driver.store.GetSnapshot(ver)
+		if err != nil {
+			done <- errors.Trace(err)
+			return
+		}
+		_, err = meta.NewSnapshotMeta(snapshot).GetSchemaVersion()
+		done <- errors.Trace(err)
+	}()
+	select {
+	case err := <-done:
+		return errors.Trace(err)
+	case <-time.After(healthCheckTimeout):
+		return errors.Errorf("reading the store timed out after %v", healthCheckTimeout)
+	}
+}
+
+// checkSchema checks the schema is loaded and not expired, the transactions fail on an expired schema.
+func (s *Server) checkSchema() error {
+	driver, ok := s.driver.(*TiDBDriver)
+	if !ok {
+		return nil
+	}
+	dom, err := tidb.GetDomain(driver.store)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	is := dom.InfoSchema()
+	if is == nil {
+		return errors.New("the schema isn't loaded")
+	}
+	ver, err := driver.store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !dom.SchemaValidator.Check(ver.Ver, is.SchemaMetaVersion()) {
+		return errors.Errorf("the schema version %d is expired", is.SchemaMetaVersion())
+	}
+	return nil
+}
+
+func writeHealth(w http.ResponseWriter, h health, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	js, err := json.Marshal(h)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+		return
+	}
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(js)
+}
@@ -0,0 +1,235 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// httpSQLDefaultMaxRows is the max number of the rows returned for a result set when the request doesn't set it.
+	httpSQLDefaultMaxRows = 1000
+	// httpSQLMaxRows is the upper limit of the max number of the rows set by the requests.
+	httpSQLMaxRows = 100000
+	// httpSQLMaxBodySize is the max size of the request bodies.
+	httpSQLMaxBodySize = 16 << 20
+)
+
+// httpSQLRequest is the body of a request to the SQL endpoint. The statement is run with the arguments bound to
+// its placeholders if Args isn't empty, otherwise SQL may have several statements.
+type httpSQLRequest struct {
+	SQL     string        `json:"sql"`
+	Args    []interface{} `json:"args"`
+	DB      string        `json:"db"`
+	MaxRows int           `json:"max_rows"`
+}
+
+// httpSQLColumn is a column of a result set.
+type httpSQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// httpSQLResultSet is a result set, the values are in the text format of the MySQL protocol, they're null for NULL.
+// Truncated is set if the result set has more than the max number of the rows.
+type httpSQLResultSet struct {
+	Columns   []httpSQLColumn `json:"columns"`
+	Rows      [][]*string     `json:"rows"`
+	Truncated bool            `json:"truncated"`
+}
+
+// httpSQLResponse is the body of a response of the SQL endpoint.
+type httpSQLResponse struct {
+	Results      []*httpSQLResultSet `json:"results"`
+	AffectedRows uint64              `json:"affected_rows"`
+	LastInsertID uint64              `json:"last_insert_id"`
+	Warnings     uint16              `json:"warnings"`
+}
+
+// httpSQLError is the body of a response of the SQL endpoint when the request fails.
+type httpSQLError struct {
+	Code    uint16 `json:"code"`
+	State   string `json:"state"`
+	Message string `json:"message"`
+}
+
+// handleSQL runs the SQL in a POST request for the clients which can't use the MySQL protocol, like the browsers
+// and the serverless functions. The user is authenticated by the HTTP basic authentication with the MySQL accounts,
+// the password is sent in plaintext, so the status port should be behind a TLS proxy. Each request runs in a new
+// session in the autocommit mode, which is in the process list until the request is done.
+func (s *Server) handleSQL(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeSQLError(w, http.StatusMethodNotAllowed, errors.New("the SQL should be sent by POST"))
+		return
+	}
+	var sqlReq httpSQLRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, req.Body, httpSQLMaxBodySize))
+	decoder.UseNumber()
+	if err := decoder.Decode(&sqlReq); err != nil {
+		writeSQLError(w, http.StatusBadRequest, errors.Errorf("invalid request: %v", err))
+		return
+	}
+	args, err := httpSQLArgs(sqlReq.Args)
+	if err != nil {
+		writeSQLError(w, http.StatusBadRequest, err)
+		return
+	}
+	maxRows := sqlReq.MaxRows
+	if maxRows <= 0 {
+		maxRows = httpSQLDefaultMaxRows
+	} else if maxRows > httpSQLMaxRows {
+		maxRows = httpSQLMaxRows
+	}
+
+	user, password, _ := req.BasicAuth()
+	cc, err := s.newSessionConn(user, password, req.RemoteAddr, sqlReq.DB)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errAccessDenied.Equal(err) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="tidb"`)
+			code = http.StatusUnauthorized
+		}
+		writeSQLError(w, code, err)
+		return
+	}
+	defer s.closeSessionConn(cc)
+
+	token := s.getToken()
+	resp, err := runHTTPSQL(cc.ctx, &sqlReq, args, maxRows)
+	s.releaseToken(token)
+	if err != nil {
+		executeErrorCounter.WithLabelValues(executeErrorToLabel(err)).Inc()
+		cc.logger().Debugf("HTTP SQL error %s", errors.ErrorStack(err))
+		writeSQLError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	js, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+	} else {
+		w.Write(js)
+	}
+}
+
+// httpSQLArgs converts the JSON values of the arguments to the values of the placeholders, the numbers without
+// fraction and exponent are integers.
+func httpSQLArgs(values []interface{}) ([]interface{}, error) {
+	args := make([]interface{}, 0, len(values))
+	for i, v := range values {
+		switch x := v.(type) {
+		case nil, string:
+			args = append(args, x)
+		case bool:
+			if x {
+				args = append(args, int64(1))
+			} else {
+				args = append(args, int64(0))
+			}
+		case json.Number:
+			if n, err := x.Int64(); err == nil {
+				args = append(args, n)
+			} else if f, err := x.Float64(); err == nil {
+				args = append(args, f)
+			} else {
+				return nil, errors.Errorf("invalid number %s of argument %d", x, i+1)
+			}
+		default:
+			return nil, errors.Errorf("argument %d should be a string, a number, a boolean or null", i+1)
+		}
+	}
+	return args, nil
+}
+
+func runHTTPSQL(ctx QueryCtx, sqlReq *httpSQLRequest, args []interface{}, maxRows int) (*httpSQLResponse, error) {
+	resp := &httpSQLResponse{Results: make([]*httpSQLResultSet, 0, 1)}
+	err := executeSQL(ctx, sqlReq.SQL, args, func(rs ResultSet) error {
+		result, err := readHTTPSQLResultSet(rs, maxRows)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		resp.Results = append(resp.Results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resp.AffectedRows = ctx.AffectedRows()
+	resp.LastInsertID = ctx.LastInsertID()
+	resp.Warnings = ctx.WarningCount()
+	return resp, nil
+}
+
+// readHTTPSQLResultSet reads at most maxRows rows of the result set.
+func readHTTPSQLResultSet(rs ResultSet, maxRows int) (*httpSQLResultSet, error) {
+	columns, err := rs.Columns()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := &httpSQLResultSet{
+		Columns: make([]httpSQLColumn, 0, len(columns)),
+		Rows:    make([][]*string, 0),
+	}
+	for _, col := range columns {
+		result.Columns = append(result.Columns, httpSQLColumn{Name: col.Name, Type: types.TypeStr(col.Type)})
+	}
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if len(result.Rows) == maxRows {
+			result.Truncated = true
+			break
+		}
+		values := make([]*string, len(row))
+		for i, d := range row {
+			if d.IsNull() {
+				continue
+			}
+			b, err := dumpTextValue(columns[i], d)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			str := string(b)
+			values[i] = &str
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, nil
+}
+
+func writeSQLError(w http.ResponseWriter, code int, err error) {
+	m := terror.ToSQLError(err)
+	js, err := json.Marshal(httpSQLError{Code: m.Code, State: m.State, Message: m.Message})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(js)
+}
@@ -20,8 +20,10 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/gorilla/mux"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/serverinfo"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -35,9 +37,25 @@ func (s *Server) startStatusHTTP() {
 	})
 }
 
+func (s *Server) statusAddr() string {
+	if len(s.cfg.StatusAddr) == 0 {
+		return defaultStatusAddr
+	}
+	return s.cfg.StatusAddr
+}
+
 func (s *Server) startHTTPServer() {
 	router := mux.NewRouter()
 	router.HandleFunc("/status", s.handleStatus)
+	// HTTP path for the health checks of the load balancers and the orchestrators.
+	router.HandleFunc("/health/live", s.handleLiveness)
+	router.HandleFunc("/health/ready", s.handleReadiness)
+	// HTTP path for the cluster tables of information_schema.
+	router.HandleFunc(infoschema.ClusterTableRowsPath("{table}"), s.handleClusterTableRows)
+	// HTTP path for the clients which run SQL over HTTP.
+	if s.cfg.HTTPSQL {
+		router.HandleFunc("/sql", s.handleSQL)
+	}
 	// HTTP path for prometheus.
 	router.Handle("/metrics", prometheus.Handler())
 
@@ -50,10 +68,7 @@ func (s *Server) startHTTPServer() {
 		router.Handle("/mvcc/txn/{startTS}/{db}/{table}", mvccTxnHandler{tikvHandler, opMvccGetByTxn})
 		router.Handle("/mvcc/txn/{startTS}", mvccTxnHandler{tikvHandler, opMvccGetByTxn})
 	}
-	addr := s.cfg.StatusAddr
-	if len(addr) == 0 {
-		addr = defaultStatusAddr
-	}
+	addr := s.statusAddr()
 	log.Infof("Listening on %v for status and metrics report.", addr)
 	http.Handle("/", router)
 	err := http.ListenAndServe(addr, nil)
@@ -85,3 +100,28 @@ func (s *Server) handleStatus(w http.ResponseWriter, req *http.Request) {
 		w.Write(js)
 	}
 }
+
+// handleClusterTableRows returns the rows of an information_schema table on this server, they're aggregated
+// by the cluster tables read on the other servers. Only the servers of the cluster can read them.
+func (s *Server) handleClusterTableRows(w http.ResponseWriter, req *http.Request) {
+	if !serverinfo.Authenticated(req, s.token) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("the table rows are only readable by the tidb-servers of the cluster"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	rows, err := infoschema.EncodeLocalTableRows(mux.Vars(req)["table"], s)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	js, err := json.Marshal(rows)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Error("Encode json error", err)
+	} else {
+		w.Write(js)
+	}
+}
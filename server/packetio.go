@@ -40,6 +40,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 )
 
 const defaultWriterSize = 16 * 1024
@@ -49,10 +50,13 @@ type packetIO struct {
 	bufReadConn *bufferedReadConn
 	bufWriter   *bufio.Writer
 	sequence    uint8
+	// maxAllowedPacket is the max length of the payload read or written, the payload of a multi-packet is counted
+	// as a whole.
+	maxAllowedPacket int
 }
 
 func newPacketIO(bufReadConn *bufferedReadConn) *packetIO {
-	p := &packetIO{sequence: 0}
+	p := &packetIO{sequence: 0, maxAllowedPacket: variable.DefMaxAllowedPacket}
 	p.setBufferedReadConn(bufReadConn)
 	return p
 }
@@ -91,6 +95,10 @@ func (p *packetIO) readPacket() ([]byte, error) {
 		return nil, errors.Trace(err)
 	}
 
+	if err = p.checkPacketLen(len(data)); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	if len(data) < mysql.MaxPayloadLen {
 		return data, nil
 	}
@@ -103,6 +111,9 @@ func (p *packetIO) readPacket() ([]byte, error) {
 		}
 
 		data = append(data, buf...)
+		if err = p.checkPacketLen(len(data)); err != nil {
+			return nil, errors.Trace(err)
+		}
 
 		if len(buf) < mysql.MaxPayloadLen {
 			break
@@ -112,9 +123,20 @@ func (p *packetIO) readPacket() ([]byte, error) {
 	return data, nil
 }
 
+// checkPacketLen returns errNetPacketTooLarge if the payload is longer than max_allowed_packet.
+func (p *packetIO) checkPacketLen(length int) error {
+	if p.maxAllowedPacket > 0 && length > p.maxAllowedPacket {
+		return errNetPacketTooLarge
+	}
+	return nil
+}
+
 // writePacket writes data that already have header
 func (p *packetIO) writePacket(data []byte) error {
 	length := len(data) - 4
+	if err := p.checkPacketLen(length); err != nil {
+		return errors.Trace(err)
+	}
 
 	for length >= mysql.MaxPayloadLen {
 		data[0] = 0xff
@@ -0,0 +1,99 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawkv
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Client is a client of the raw kv service.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient connects to the raw kv service at the address.
+func NewClient(addr string) (*Client, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return errors.Trace(c.conn.Close())
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	return errors.Trace(grpc.Invoke(ctx, "/"+serviceName+"/"+method, req, resp, c.conn))
+}
+
+// Get returns the value of a key, the value is nil if the key doesn't exist.
+func (c *Client) Get(ctx context.Context, key []byte) ([]byte, error) {
+	resp := new(kvrpcpb.RawGetResponse)
+	if err := c.invoke(ctx, "RawGet", &kvrpcpb.RawGetRequest{Key: key}, resp); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if len(resp.Value) == 0 {
+		return nil, nil
+	}
+	return resp.Value, nil
+}
+
+// Put sets the value of a key.
+func (c *Client) Put(ctx context.Context, key, value []byte) error {
+	resp := new(kvrpcpb.RawPutResponse)
+	if err := c.invoke(ctx, "RawPut", &kvrpcpb.RawPutRequest{Key: key, Value: value}, resp); err != nil {
+		return errors.Trace(err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Delete deletes a key.
+func (c *Client) Delete(ctx context.Context, key []byte) error {
+	resp := new(kvrpcpb.RawDeleteResponse)
+	if err := c.invoke(ctx, "RawDelete", &kvrpcpb.RawDeleteRequest{Key: key}, resp); err != nil {
+		return errors.Trace(err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Scan returns at most limit keys and their values from the start key.
+func (c *Client) Scan(ctx context.Context, startKey []byte, limit int) (keys [][]byte, values [][]byte, err error) {
+	resp := new(kvrpcpb.RawScanResponse)
+	if err = c.invoke(ctx, "RawScan", &kvrpcpb.RawScanRequest{StartKey: startKey, Limit: uint32(limit)}, resp); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	for _, pair := range resp.Kvs {
+		if pair.Error != nil {
+			return nil, nil, errors.New(pair.Error.Abort)
+		}
+		keys = append(keys, pair.Key)
+		values = append(values, pair.Value)
+	}
+	return keys, values, nil
+}
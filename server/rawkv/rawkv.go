@@ -0,0 +1,205 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rawkv implements a gRPC service of raw get, put, delete and scan operations, so the applications
+// can read and write the keys of a store of TiDB without SQL.
+//
+// The keys of the service are kept in a dedicated keyspace of the store, every key is prefixed with
+// KeyPrefix, which doesn't overlap with the keys of the tables and the meta data. The operations are run
+// in the transactions of the store, so they work with all the storages and never conflict with the raw
+// mode of TiKV. The messages are the Raw requests and responses of kvrpcpb, the context of the requests
+// is ignored.
+package rawkv
+
+import (
+	"net"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/kv"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// KeyPrefix is the prefix of the keys of the service in the store.
+var KeyPrefix = kv.Key("raw_")
+
+// MaxScanLimit is the maximum number of the pairs returned by a scan.
+const MaxScanLimit = 10240
+
+const serviceName = "rawkv.RawKV"
+
+// Server serves the raw kv operations of a store.
+type Server struct {
+	store kv.Storage
+	grpc  *grpc.Server
+}
+
+// NewServer creates a server of the store.
+func NewServer(store kv.Storage) *Server {
+	s := &Server{store: store, grpc: grpc.NewServer()}
+	s.grpc.RegisterService(&serviceDesc, s)
+	return s
+}
+
+// Serve accepts the connections of the listener, it returns when the server is closed.
+func (s *Server) Serve(l net.Listener) error {
+	log.Infof("[rawkv] server is running on %s", l.Addr())
+	return errors.Trace(s.grpc.Serve(l))
+}
+
+// Close stops the server and closes the connections.
+func (s *Server) Close() {
+	s.grpc.Stop()
+}
+
+func encodeKey(key []byte) kv.Key {
+	return append(append(kv.Key(nil), KeyPrefix...), key...)
+}
+
+// RawGet returns the value of a key, the value is empty if the key doesn't exist.
+func (s *Server) RawGet(ctx context.Context, req *kvrpcpb.RawGetRequest) (*kvrpcpb.RawGetResponse, error) {
+	if len(req.Key) == 0 {
+		return &kvrpcpb.RawGetResponse{Error: "key is empty"}, nil
+	}
+	snapshot, err := s.store.GetSnapshot(kv.MaxVersion)
+	if err != nil {
+		return &kvrpcpb.RawGetResponse{Error: err.Error()}, nil
+	}
+	val, err := snapshot.Get(encodeKey(req.Key))
+	if err != nil && !kv.IsErrNotFound(err) {
+		return &kvrpcpb.RawGetResponse{Error: err.Error()}, nil
+	}
+	return &kvrpcpb.RawGetResponse{Value: val}, nil
+}
+
+// RawPut sets the value of a key.
+func (s *Server) RawPut(ctx context.Context, req *kvrpcpb.RawPutRequest) (*kvrpcpb.RawPutResponse, error) {
+	if len(req.Key) == 0 {
+		return &kvrpcpb.RawPutResponse{Error: "key is empty"}, nil
+	}
+	err := kv.RunInNewTxn(s.store, true, func(txn kv.Transaction) error {
+		return txn.Set(encodeKey(req.Key), req.Value)
+	})
+	if err != nil {
+		return &kvrpcpb.RawPutResponse{Error: err.Error()}, nil
+	}
+	return &kvrpcpb.RawPutResponse{}, nil
+}
+
+// RawDelete deletes a key.
+func (s *Server) RawDelete(ctx context.Context, req *kvrpcpb.RawDeleteRequest) (*kvrpcpb.RawDeleteResponse, error) {
+	if len(req.Key) == 0 {
+		return &kvrpcpb.RawDeleteResponse{Error: "key is empty"}, nil
+	}
+	err := kv.RunInNewTxn(s.store, true, func(txn kv.Transaction) error {
+		return txn.Delete(encodeKey(req.Key))
+	})
+	if err != nil {
+		return &kvrpcpb.RawDeleteResponse{Error: err.Error()}, nil
+	}
+	return &kvrpcpb.RawDeleteResponse{}, nil
+}
+
+// RawScan returns the pairs from the start key in the order of the keys, at most the limit or
+// MaxScanLimit of them.
+func (s *Server) RawScan(ctx context.Context, req *kvrpcpb.RawScanRequest) (*kvrpcpb.RawScanResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > MaxScanLimit {
+		limit = MaxScanLimit
+	}
+	snapshot, err := s.store.GetSnapshot(kv.MaxVersion)
+	if err != nil {
+		return &kvrpcpb.RawScanResponse{Kvs: []*kvrpcpb.KvPair{{Error: keyError(err)}}}, nil
+	}
+	it, err := snapshot.Seek(encodeKey(req.StartKey))
+	if err != nil {
+		return &kvrpcpb.RawScanResponse{Kvs: []*kvrpcpb.KvPair{{Error: keyError(err)}}}, nil
+	}
+	defer it.Close()
+	var pairs []*kvrpcpb.KvPair
+	for len(pairs) < limit && it.Valid() && it.Key().HasPrefix(KeyPrefix) {
+		pairs = append(pairs, &kvrpcpb.KvPair{
+			Key:   append([]byte(nil), it.Key()[len(KeyPrefix):]...),
+			Value: append([]byte(nil), it.Value()...),
+		})
+		if err = it.Next(); err != nil {
+			pairs = append(pairs, &kvrpcpb.KvPair{Error: keyError(err)})
+			break
+		}
+	}
+	return &kvrpcpb.RawScanResponse{Kvs: pairs}, nil
+}
+
+// keyError returns the error of a scan, which is reported as the last pair.
+func keyError(err error) *kvrpcpb.KeyError {
+	return &kvrpcpb.KeyError{Abort: err.Error()}
+}
+
+// server is the interface of the service for the type check of grpc.
+type server interface {
+	RawGet(context.Context, *kvrpcpb.RawGetRequest) (*kvrpcpb.RawGetResponse, error)
+	RawPut(context.Context, *kvrpcpb.RawPutRequest) (*kvrpcpb.RawPutResponse, error)
+	RawDelete(context.Context, *kvrpcpb.RawDeleteRequest) (*kvrpcpb.RawDeleteResponse, error)
+	RawScan(context.Context, *kvrpcpb.RawScanRequest) (*kvrpcpb.RawScanResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*server)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RawGet",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(kvrpcpb.RawGetRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(server).RawGet(ctx, in)
+			},
+		},
+		{
+			MethodName: "RawPut",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(kvrpcpb.RawPutRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(server).RawPut(ctx, in)
+			},
+		},
+		{
+			MethodName: "RawDelete",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(kvrpcpb.RawDeleteRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(server).RawDelete(ctx, in)
+			},
+		},
+		{
+			MethodName: "RawScan",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(kvrpcpb.RawScanRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(server).RawScan(ctx, in)
+			},
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rawkv.proto",
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rawkv
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"golang.org/x/net/context"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testRawKVSuite{})
+
+type testRawKVSuite struct {
+	store  kv.Storage
+	server *Server
+	client *Client
+}
+
+func (s *testRawKVSuite) SetUpSuite(c *C) {
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://rawkv_test")
+	c.Assert(err, IsNil)
+	s.store = store
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	s.server = NewServer(store)
+	go s.server.Serve(l)
+	s.client, err = NewClient(l.Addr().String())
+	c.Assert(err, IsNil)
+}
+
+func (s *testRawKVSuite) TearDownSuite(c *C) {
+	c.Assert(s.client.Close(), IsNil)
+	s.server.Close()
+	c.Assert(s.store.Close(), IsNil)
+}
+
+func (s *testRawKVSuite) TestRawKV(c *C) {
+	ctx := context.Background()
+	val, err := s.client.Get(ctx, []byte("k1"))
+	c.Assert(err, IsNil)
+	c.Assert(val, IsNil)
+	c.Assert(s.client.Put(ctx, []byte("k1"), []byte("v1")), IsNil)
+	val, err = s.client.Get(ctx, []byte("k1"))
+	c.Assert(err, IsNil)
+	c.Assert(val, BytesEquals, []byte("v1"))
+	c.Assert(s.client.Put(ctx, []byte("k1"), []byte("v2")), IsNil)
+	val, err = s.client.Get(ctx, []byte("k1"))
+	c.Assert(err, IsNil)
+	c.Assert(val, BytesEquals, []byte("v2"))
+	c.Assert(s.client.Delete(ctx, []byte("k1")), IsNil)
+	val, err = s.client.Get(ctx, []byte("k1"))
+	c.Assert(err, IsNil)
+	c.Assert(val, IsNil)
+
+	_, err = s.client.Get(ctx, nil)
+	c.Assert(err, ErrorMatches, "key is empty")
+	c.Assert(s.client.Put(ctx, nil, []byte("v")), ErrorMatches, "key is empty")
+	c.Assert(s.client.Put(ctx, []byte("k"), nil), NotNil)
+	c.Assert(s.client.Delete(ctx, nil), ErrorMatches, "key is empty")
+
+	// The keys are stored in the keyspace of the service.
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set(kv.Key("k3"), []byte("outside")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	c.Assert(s.client.Put(ctx, []byte("k3"), []byte("v3")), IsNil)
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	got, err := txn.Get(kv.Key("raw_k3"))
+	c.Assert(err, IsNil)
+	c.Assert(got, BytesEquals, []byte("v3"))
+	got, err = txn.Get(kv.Key("k3"))
+	c.Assert(err, IsNil)
+	c.Assert(got, BytesEquals, []byte("outside"))
+	c.Assert(txn.Rollback(), IsNil)
+	c.Assert(s.client.Delete(ctx, []byte("k3")), IsNil)
+}
+
+func (s *testRawKVSuite) TestRawScan(c *C) {
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		c.Assert(s.client.Put(ctx, []byte(fmt.Sprintf("s%d", i)), []byte(fmt.Sprintf("v%d", i))), IsNil)
+	}
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(txn.Set(kv.Key("raw`"), []byte("after the keyspace")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+
+	keys, values, err := s.client.Scan(ctx, []byte("s3"), 4)
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, [][]byte{[]byte("s3"), []byte("s4"), []byte("s5"), []byte("s6")})
+	c.Assert(values, DeepEquals, [][]byte{[]byte("v3"), []byte("v4"), []byte("v5"), []byte("v6")})
+
+	keys, _, err = s.client.Scan(ctx, []byte("s8"), 0)
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, [][]byte{[]byte("s8"), []byte("s9")})
+	keys, _, err = s.client.Scan(ctx, []byte("t"), 10)
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 0)
+}
@@ -31,9 +31,12 @@ package server
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"io"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"net"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -43,24 +46,36 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
+	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/serverinfo"
 )
 
 var (
 	baseConnID uint32
+	// timedOutConns is the number of the connections closed for the timeouts.
+	timedOutConns int64
 )
 
 var (
-	errUnknownFieldType  = terror.ClassServer.New(codeUnknownFieldType, "unknown field type")
-	errInvalidPayloadLen = terror.ClassServer.New(codeInvalidPayloadLen, "invalid payload length")
-	errInvalidSequence   = terror.ClassServer.New(codeInvalidSequence, "invalid sequence")
-	errInvalidType       = terror.ClassServer.New(codeInvalidType, "invalid type")
-	errNotAllowedCommand = terror.ClassServer.New(codeNotAllowedCommand, "the used command is not allowed with this TiDB version")
-	errAccessDenied      = terror.ClassServer.New(codeAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDenied])
+	errUnknownFieldType       = terror.ClassServer.New(codeUnknownFieldType, "unknown field type")
+	errInvalidPayloadLen      = terror.ClassServer.New(codeInvalidPayloadLen, "invalid payload length")
+	errInvalidSequence        = terror.ClassServer.New(codeInvalidSequence, "invalid sequence")
+	errInvalidType            = terror.ClassServer.New(codeInvalidType, "invalid type")
+	errNotAllowedCommand      = terror.ClassServer.New(codeNotAllowedCommand, "the used command is not allowed with this TiDB version")
+	errAccessDenied           = terror.ClassServer.New(codeAccessDenied, mysql.MySQLErrName[mysql.ErrAccessDenied])
+	errNetPacketTooLarge      = terror.ClassServer.New(codeNetPacketTooLarge, "Got a packet bigger than 'max_allowed_packet' bytes")
+	errInvalidCharacterString = terror.ClassServer.New(codeInvalidCharacterString, mysql.MySQLErrName[mysql.ErrInvalidCharacterString])
+	errNoSuchThread           = terror.ClassServer.New(codeNoSuchThread, "Unknown thread id: %d")
+	errKillOtherServer        = terror.ClassServer.New(codeKillOtherServer, "Connection %d is on another TiDB server, kill it on that server")
+	errTooManyServers         = terror.ClassServer.New(codeTooManyServers, "There are too many TiDB servers in the cluster")
+	errIdleTxnRolledBack      = terror.ClassServer.New(codeIdleTxnRolledBack, "The transaction was rolled back as it was idle for more than %d seconds (tidb_idle_transaction_timeout)")
 )
 
 // DefaultCapability is the capability of the server when it is created using the default configuration.
@@ -69,23 +84,34 @@ const defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
 	mysql.ClientConnectWithDB | mysql.ClientProtocol41 |
 	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows |
 	mysql.ClientMultiStatements | mysql.ClientMultiResults | mysql.ClientLocalFiles |
-	mysql.ClientConnectAtts | mysql.ClientPluginAuth
+	mysql.ClientConnectAtts | mysql.ClientPluginAuth | mysql.ClientInteractive
 
 // Server is the MySQL protocol server
 type Server struct {
 	cfg               *config.Config
 	tlsConfig         *tls.Config
+	tlsMu             sync.RWMutex
 	driver            IDriver
 	listener          net.Listener
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
-	clients           map[uint32]*clientConn
+	clients           map[uint64]*clientConn
 	capability        uint32
+	// serverID is the high util.ServerIDBits bits of the connection IDs, so a connection ID identifies the server in the cluster.
+	serverID uint32
+	// registry is the registration of the server in the cluster, it's removed when the server is closed.
+	registry io.Closer
+	// token authenticates the requests of the other servers in the cluster to the status HTTP API.
+	token string
 
 	// When a critical error occurred, we don't want to exit the process, because there may be
 	// a supervisor automatically restart it, then new client connection will be created, but we can't server it.
 	// So we just stop the listener and store to force clients to chose other TiDB servers.
 	stopListenerCh chan struct{}
+	// criticalErr is set to 1 when a critical error occurred, the liveness check fails after it.
+	criticalErr int32
+	// draining is set to 1 when the server is going to be closed, the readiness check fails after it.
+	draining int32
 }
 
 // ConnectionCount gets current connection count.
@@ -105,16 +131,33 @@ func (s *Server) releaseToken(token *Token) {
 	s.concurrentLimiter.Put(token)
 }
 
+// allocConnID allocates the ID of a new connection, the IDs of the live connections are skipped when the local IDs
+// wrap around.
+func (s *Server) allocConnID() uint64 {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	for {
+		localID := atomic.AddUint32(&baseConnID, 1) & util.MaxLocalConnID
+		if localID == 0 {
+			continue
+		}
+		connectionID := uint64(s.serverID)<<util.LocalConnIDBits | uint64(localID)
+		if _, ok := s.clients[connectionID]; !ok {
+			return connectionID
+		}
+	}
+}
+
 // newConn creates a new *clientConn from a net.Conn.
 // It allocates a connection ID and random salt data for authentication.
 func (s *Server) newConn(conn net.Conn) *clientConn {
 	cc := &clientConn{
 		server:       s,
-		connectionID: atomic.AddUint32(&baseConnID, 1),
+		connectionID: s.allocConnID(),
 		collation:    mysql.DefaultCollationID,
 		alloc:        arena.NewAllocator(32 * 1024),
 	}
-	log.Infof("[%d] new connection %s", cc.connectionID, conn.RemoteAddr().String())
+	cc.logger().Infof("new connection %s", conn.RemoteAddr().String())
 	if s.cfg.TCPKeepAlive {
 		if tcpConn, ok := conn.(*net.TCPConn); ok {
 			if err := tcpConn.SetKeepAlive(true); err != nil {
@@ -127,6 +170,101 @@ func (s *Server) newConn(conn net.Conn) *clientConn {
 	return cc
 }
 
+// newSessionConn opens a session for a client which doesn't use the MySQL protocol, like the HTTP and the gRPC
+// clients, the user is authenticated by the plaintext password and the address of the client. The session is
+// in the process list and can be killed like the MySQL connections, it's closed by closeSessionConn.
+func (s *Server) newSessionConn(user, password, addr, dbname string) (*clientConn, error) {
+	cc := &clientConn{
+		server:       s,
+		connectionID: s.allocConnID(),
+		capability:   s.capability,
+		collation:    mysql.DefaultCollationID,
+		user:         user,
+		salt:         util.RandomBuf(20),
+	}
+	ctx, err := s.driver.OpenCtx(cc.connectionID, cc.capability, cc.collation, dbname, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cc.ctx = ctx
+	if !s.skipAuth() {
+		host, _, err1 := net.SplitHostPort(addr)
+		if err1 != nil || !ctx.Auth(&auth.UserIdentity{Username: user, Hostname: host}, auth.ScramblePassword(cc.salt, password), cc.salt) {
+			ctx.Close()
+			usingPassword := "NO"
+			if password != "" {
+				usingPassword = "YES"
+			}
+			return nil, errors.Trace(errAccessDenied.GenByArgs(user, host, usingPassword))
+		}
+	}
+	if dbname != "" {
+		if err = cc.useDB(dbname); err != nil {
+			ctx.Close()
+			return nil, errors.Trace(err)
+		}
+	}
+	ctx.SetSessionManager(s)
+
+	s.rwlock.Lock()
+	s.clients[cc.connectionID] = cc
+	connections := len(s.clients)
+	s.rwlock.Unlock()
+	connGauge.Set(float64(connections))
+	return cc, nil
+}
+
+// closeSessionConn closes a session opened by newSessionConn.
+func (s *Server) closeSessionConn(cc *clientConn) {
+	s.rwlock.Lock()
+	delete(s.clients, cc.connectionID)
+	connections := len(s.clients)
+	s.rwlock.Unlock()
+	connGauge.Set(float64(connections))
+	cc.ctx.Close()
+}
+
+// executeSQL runs the SQL of a session opened by newSessionConn, the statement is prepared and executed with the
+// arguments if there are any. fn is called with each result set, which is closed after it.
+func executeSQL(ctx QueryCtx, sql string, args []interface{}, fn func(ResultSet) error) error {
+	var rss []ResultSet
+	if len(args) > 0 {
+		stmt, _, _, err := ctx.Prepare(sql)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer stmt.Close()
+		if stmt.NumParams() != len(args) {
+			return mysql.NewErr(mysql.ErrWrongArguments, "mysqld_stmt_execute")
+		}
+		rs, err := stmt.Execute(args...)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if rs != nil {
+			rss = append(rss, rs)
+		}
+	} else {
+		var err error
+		if rss, err = ctx.Execute(sql); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for i, rs := range rss {
+		err := fn(rs)
+		if err1 := rs.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			for _, rs1 := range rss[i+1:] {
+				rs1.Close()
+			}
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 func (s *Server) skipAuth() bool {
 	return s.cfg.SkipAuth
 }
@@ -140,17 +278,21 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		driver:            driver,
 		concurrentLimiter: NewTokenLimiter(tokenLimit),
 		rwlock:            &sync.RWMutex{},
-		clients:           make(map[uint32]*clientConn),
+		clients:           make(map[uint64]*clientConn),
 		stopListenerCh:    make(chan struct{}, 1),
 	}
 	s.loadTLSCertificates()
 
+	var err error
+	if s.serverID, err = driver.AllocServerID(); err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	s.capability = defaultCapability
 	if s.tlsConfig != nil {
 		s.capability |= mysql.ClientSSL
 	}
 
-	var err error
 	if cfg.Socket != "" {
 		cfg.SkipAuth = true
 		if s.listener, err = net.Listen("unix", cfg.Socket); err == nil {
@@ -165,11 +307,50 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		return nil, errors.Trace(err)
 	}
 
+	if s.token, err = serverinfo.NewToken(); err != nil {
+		s.listener.Close()
+		return nil, errors.Trace(err)
+	}
+	var statusAddr string
+	if s.cfg.ReportStatus {
+		statusAddr = s.advertiseAddr(s.statusAddr())
+	}
+	s.registry, err = driver.RegisterServer(&model.ServerInfo{
+		ID:            s.serverID,
+		Address:       s.advertiseAddr(s.cfg.Addr),
+		StatusAddress: statusAddr,
+		Version:       mysql.ServerVersion,
+		GitHash:       printer.TiDBGitHash,
+		StartTime:     time.Now().Unix(),
+		Token:         s.token,
+	})
+	if err != nil {
+		s.listener.Close()
+		return nil, errors.Trace(err)
+	}
+
 	// Init rand seed for randomBuf()
 	rand.Seed(time.Now().UTC().UnixNano())
 	return s, nil
 }
 
+// advertiseAddr returns the address by which the other tidb-servers reach the listening address addr. Its host is
+// replaced by the advertise address, or by the hostname if addr listens on all the addresses.
+func (s *Server) advertiseAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if s.cfg.AdvertiseAddress != "" {
+		host = s.cfg.AdvertiseAddress
+	} else if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		if hostname, err := os.Hostname(); err == nil {
+			host = hostname
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
 func (s *Server) loadTLSCertificates() {
 	defer func() {
 		if s.tlsConfig != nil {
@@ -188,18 +369,26 @@ func (s *Server) loadTLSCertificates() {
 		return
 	}
 
-	tlsCert, err := tls.LoadX509KeyPair(s.cfg.SSLCertPath, s.cfg.SSLKeyPath)
+	tlsConfig, err := newTLSConfig(s.cfg)
 	if err != nil {
 		log.Warn(errors.ErrorStack(err))
 		s.tlsConfig = nil
 		return
 	}
+	s.tlsConfig = tlsConfig
+}
+
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsCert, err := tls.LoadX509KeyPair(cfg.SSLCertPath, cfg.SSLKeyPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	// Try loading CA cert.
 	clientAuthPolicy := tls.NoClientCert
 	var certPool *x509.CertPool
-	if len(s.cfg.SSLCAPath) > 0 {
-		caCert, err := ioutil.ReadFile(s.cfg.SSLCAPath)
+	if len(cfg.SSLCAPath) > 0 {
+		caCert, err := ioutil.ReadFile(cfg.SSLCAPath)
 		if err != nil {
 			log.Warn(errors.ErrorStack(err))
 		} else {
@@ -207,15 +396,44 @@ func (s *Server) loadTLSCertificates() {
 			if certPool.AppendCertsFromPEM(caCert) {
 				clientAuthPolicy = tls.VerifyClientCertIfGiven
 			}
-			variable.SysVars["ssl_ca"].Value = s.cfg.SSLCAPath
+			variable.SysVars["ssl_ca"].Value = cfg.SSLCAPath
 		}
 	}
-	s.tlsConfig = &tls.Config{
+	return &tls.Config{
 		Certificates: []tls.Certificate{tlsCert},
 		ClientCAs:    certPool,
 		ClientAuth:   clientAuthPolicy,
 		MinVersion:   0,
+	}, nil
+}
+
+// ReloadTLSCertificates implements config.ReloadHook, it loads the TLS certificates of the new config for the
+// new connections. TLS can't be enabled by reloading if it is not enabled when the server starts.
+func (s *Server) ReloadTLSCertificates(oldCfg, newCfg *config.Config) error {
+	if len(newCfg.SSLCertPath) == 0 || len(newCfg.SSLKeyPath) == 0 {
+		return nil
+	}
+	if s.getTLSConfig() == nil {
+		log.Warn("Secure connection is NOT ENABLED when the server starts, the certificates are not loaded")
+		return nil
+	}
+	tlsConfig, err := newTLSConfig(newCfg)
+	if err != nil {
+		return errors.Trace(err)
 	}
+	s.tlsMu.Lock()
+	s.tlsConfig = tlsConfig
+	s.tlsMu.Unlock()
+	variable.SysVars["ssl_cert"].Value = newCfg.SSLCertPath
+	variable.SysVars["ssl_key"].Value = newCfg.SSLKeyPath
+	log.Infof("TLS certificates are reloaded from %s", newCfg.SSLCertPath)
+	return nil
+}
+
+func (s *Server) getTLSConfig() *tls.Config {
+	s.tlsMu.RLock()
+	defer s.tlsMu.RUnlock()
+	return s.tlsConfig
 }
 
 // Run runs the server.
@@ -267,13 +485,19 @@ func (s *Server) Close() {
 		s.listener.Close()
 		s.listener = nil
 	}
+	if s.registry != nil {
+		if err := s.registry.Close(); err != nil {
+			log.Errorf("unregister the server error %s", errors.ErrorStack(err))
+		}
+		s.registry = nil
+	}
 }
 
 // onConn runs in its own goroutine, handles queries from this connection.
 func (s *Server) onConn(c net.Conn) {
 	conn := s.newConn(c)
 	defer func() {
-		log.Infof("[%d] close connection", conn.connectionID)
+		conn.logger().Info("close connection")
 	}()
 
 	if err := conn.handshake(); err != nil {
@@ -308,19 +532,42 @@ func (s *Server) ShowProcessList() []util.ProcessInfo {
 }
 
 // Kill implements the SessionManager interface.
-func (s *Server) Kill(connectionID uint64, query bool) {
+func (s *Server) Kill(connectionID uint64, query bool) error {
+	if connectionID > math.MaxUint32 {
+		return errNoSuchThread.GenByArgs(connectionID)
+	}
+	if util.ServerIDOfConn(connectionID) != s.serverID {
+		return errKillOtherServer.GenByArgs(connectionID)
+	}
+
 	s.rwlock.Lock()
 	defer s.rwlock.Unlock()
 
-	conn, ok := s.clients[uint32(connectionID)]
+	conn, ok := s.clients[connectionID]
 	if !ok {
-		return
+		return errNoSuchThread.GenByArgs(connectionID)
 	}
 
 	conn.ctx.Cancel()
 	if !query {
 		conn.killed = true
 	}
+	return nil
+}
+
+// connStats implements variable.Statistics interface, it returns the status variables of the connections.
+type connStats struct{}
+
+// GetScope implements variable.Statistics GetScope interface.
+func (s connStats) GetScope(status string) variable.ScopeFlag {
+	return variable.ScopeGlobal
+}
+
+// Stats implements variable.Statistics Stats interface.
+func (s connStats) Stats(vars *variable.SessionVars) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"Tidb_connection_timeouts": atomic.LoadInt64(&timedOutConns),
+	}, nil
 }
 
 // Server error codes.
@@ -329,15 +576,25 @@ const (
 	codeInvalidPayloadLen = 2
 	codeInvalidSequence   = 3
 	codeInvalidType       = 4
-
-	codeNotAllowedCommand = 1148
-	codeAccessDenied      = mysql.ErrAccessDenied
+	codeIdleTxnRolledBack = 5
+	codeKillOtherServer   = 6
+	codeTooManyServers    = 7
+
+	codeNotAllowedCommand      = 1148
+	codeAccessDenied           = mysql.ErrAccessDenied
+	codeNetPacketTooLarge      = mysql.ErrNetPacketTooLarge
+	codeInvalidCharacterString = mysql.ErrInvalidCharacterString
+	codeNoSuchThread           = mysql.ErrNoSuchThread
 )
 
 func init() {
 	serverMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeNotAllowedCommand: mysql.ErrNotAllowedCommand,
-		codeAccessDenied:      mysql.ErrAccessDenied,
+		codeNotAllowedCommand:      mysql.ErrNotAllowedCommand,
+		codeAccessDenied:           mysql.ErrAccessDenied,
+		codeNetPacketTooLarge:      mysql.ErrNetPacketTooLarge,
+		codeInvalidCharacterString: mysql.ErrInvalidCharacterString,
+		codeNoSuchThread:           mysql.ErrNoSuchThread,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassServer] = serverMySQLErrCodes
+	variable.RegisterStatistics(connStats{})
 }
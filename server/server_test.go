@@ -17,20 +17,37 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/go-sql-driver/mysql"
+	"github.com/gorilla/mux"
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	tmysql "github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server/sqlrpc"
+	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/serverinfo"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 func TestT(t *testing.T) {
@@ -76,6 +93,21 @@ func runTests(c *C, overrider configOverrider, tests ...func(dbt *DBTest)) {
 	}
 }
 
+// ignoreSkippedWarning ignores the warning of a skipped IF [NOT] EXISTS statement, which is returned as an error
+// by the driver in strict mode.
+func ignoreSkippedWarning(query string, err error) error {
+	if _, ok := err.(mysql.MySQLWarnings); ok && strings.Contains(strings.ToUpper(query), " EXISTS") {
+		return nil
+	}
+	return err
+}
+
+func dropDatabaseIfExists(db *sql.DB, dbName string) error {
+	query := fmt.Sprintf("DROP DATABASE IF EXISTS `%s`;", dbName)
+	_, err := db.Exec(query)
+	return ignoreSkippedWarning(query, err)
+}
+
 // runTestsOnNewDB runs tests using a specified database which will be created before the test and destroyed after the test.
 func runTestsOnNewDB(c *C, overrider configOverrider, dbName string, tests ...func(dbt *DBTest)) {
 	dsn := getDSN(overrider, func(config *mysql.Config) {
@@ -85,14 +117,14 @@ func runTestsOnNewDB(c *C, overrider configOverrider, dbName string, tests ...fu
 	c.Assert(err, IsNil, Commentf("Error connecting"))
 	defer db.Close()
 
-	_, err = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`;", dbName))
+	err = dropDatabaseIfExists(db, dbName)
 	c.Assert(err, IsNil, Commentf("Error drop database %s: %s", dbName, err))
 
 	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE `%s`;", dbName))
 	c.Assert(err, IsNil, Commentf("Error create database %s: %s", dbName, err))
 
 	defer func() {
-		_, err = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`;", dbName))
+		err = dropDatabaseIfExists(db, dbName)
 		c.Assert(err, IsNil, Commentf("Error drop database %s: %s", dbName, err))
 	}()
 
@@ -120,6 +152,7 @@ func (dbt *DBTest) fail(method, query string, err error) {
 
 func (dbt *DBTest) mustExec(query string, args ...interface{}) (res sql.Result) {
 	res, err := dbt.db.Exec(query, args...)
+	err = ignoreSkippedWarning(query, err)
 	dbt.Assert(err, IsNil, Commentf("Exec %s", query))
 	return res
 }
@@ -281,6 +314,143 @@ func runTestClientWithCollation(t *C) {
 	})
 }
 
+func runTestCharsetConversion(c *C) {
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.db.SetMaxIdleConns(1)
+		dbt.db.SetMaxOpenConns(1)
+		queryString := func(query string) string {
+			var s string
+			rows := dbt.mustQuery(query)
+			c.Assert(rows.Next(), IsTrue)
+			c.Assert(rows.Scan(&s), IsNil)
+			c.Assert(rows.Close(), IsNil)
+			return s
+		}
+		// The string literals are in latin1, and the result is encoded to latin1.
+		dbt.mustExec("set names latin1")
+		c.Assert(queryString("select concat('caf\xe9', char_length('caf\xe9'))"), Equals, "caf\xe94")
+		c.Assert(queryString("select hex('\xe9')"), Equals, "E9")
+		// The binary strings keep the bytes sent by the client.
+		dbt.mustExec("create table charset_conversion (a varchar(10), b blob, c varbinary(10))")
+		dbt.mustExec("insert charset_conversion values ('\xe9', '\xe9', _binary'\xe9\xff')")
+		c.Assert(queryString("select hex(concat(a, b, c)) from charset_conversion"), Equals, "C3A9E9E9FF")
+		dbt.mustExec("update charset_conversion set c = 'caf\xe9'")
+		c.Assert(queryString("select hex(c) from charset_conversion"), Equals, "636166E9")
+		dbt.mustExec("drop table charset_conversion")
+		// The characters that can't be represented in character_set_results are replaced by '?'.
+		dbt.mustExec("set character_set_client = utf8, character_set_connection = utf8")
+		c.Assert(queryString("select '中é'"), Equals, "?\xe9")
+		dbt.mustExec("set character_set_results = NULL")
+		c.Assert(queryString("select '中é'"), Equals, "中é")
+		// The bytes that are invalid in character_set_client are an error in the strict SQL mode.
+		dbt.mustExec("set names ascii")
+		_, err := dbt.db.Query("select '\xe9'")
+		checkErrorCode(c, err, tmysql.ErrInvalidCharacterString)
+		dbt.mustExec("set sql_mode = ''")
+		c.Assert(queryString("select '\xe9'"), Equals, "?")
+		dbt.mustExec("set names utf8")
+	})
+}
+
+func runTestKill(c *C) {
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.db.SetMaxOpenConns(1)
+		victim, err := sql.Open("mysql", getDSN())
+		c.Assert(err, IsNil)
+		defer victim.Close()
+		victim.SetMaxOpenConns(1)
+		connectionID := func() uint64 {
+			var id uint64
+			c.Assert(victim.QueryRow("select connection_id()").Scan(&id), IsNil)
+			return id
+		}
+
+		// The connection ID is 32 bits like the thread ID in the handshake, the high bits are the server ID.
+		id := connectionID()
+		c.Assert(id>>util.LocalConnIDBits, Not(Equals), uint64(0))
+		c.Assert(id <= math.MaxUint32, IsTrue)
+		_, err = dbt.db.Exec(fmt.Sprintf("kill %d", id+1<<util.LocalConnIDBits))
+		checkErrorCode(c, err, tmysql.ErrUnknown)
+		_, err = dbt.db.Exec(fmt.Sprintf("kill tidb %d", id|1<<32))
+		checkErrorCode(c, err, tmysql.ErrNoSuchThread)
+
+		// KILL QUERY interrupts the running BENCHMARK and keeps the connection.
+		done := make(chan error, 1)
+		go func() {
+			_, err1 := victim.Exec("select benchmark(100000000000, 1)")
+			done <- err1
+		}()
+		for {
+			var count int
+			err = dbt.db.QueryRow("select count(*) from information_schema.processlist where info like 'select benchmark%'").Scan(&count)
+			c.Assert(err, IsNil)
+			if count > 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		dbt.mustExec(fmt.Sprintf("kill query %d", id))
+		checkErrorCode(c, <-done, tmysql.ErrQueryInterrupted)
+		c.Assert(connectionID(), Equals, id)
+
+		dbt.mustExec(fmt.Sprintf("kill %d", id))
+		var newID uint64
+		if err = victim.QueryRow("select connection_id()").Scan(&newID); err == nil {
+			c.Assert(newID, Not(Equals), id)
+		}
+	})
+}
+
+func runTestClusterInfo(c *C) {
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.db.SetMaxOpenConns(1)
+		var id uint64
+		c.Assert(dbt.db.QueryRow("select connection_id()").Scan(&id), IsNil)
+		var address, statusAddress, version string
+		row := dbt.db.QueryRow("select address, status_address, version from information_schema.cluster_info where id = ?", id>>util.LocalConnIDBits)
+		c.Assert(row.Scan(&address, &statusAddress, &version), IsNil)
+		c.Assert(address, Equals, "127.0.0.1:4001")
+		c.Assert(statusAddress, Equals, "127.0.0.1:10090")
+		c.Assert(version, Equals, tmysql.ServerVersion)
+	})
+}
+
+func runTestClusterTables(c *C, server *Server, store kv.Storage) {
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.db.SetMaxOpenConns(1)
+		var id uint64
+		c.Assert(dbt.db.QueryRow("select connection_id()").Scan(&id), IsNil)
+		var count int
+		row := dbt.db.QueryRow("select count(*) from information_schema.cluster_processlist where instance = '127.0.0.1:4001' and id = ?", id)
+		c.Assert(row.Scan(&count), IsNil)
+		c.Assert(count, Equals, 1)
+
+		// Register another server with a status HTTP API of this server, its rows are read by the API.
+		router := mux.NewRouter()
+		router.HandleFunc(infoschema.ClusterTableRowsPath("{table}"), server.handleClusterTableRows)
+		statusServer := httptest.NewServer(router)
+		defer statusServer.Close()
+		statusAddr := strings.TrimPrefix(statusServer.URL, "http://")
+		r, err := serverinfo.Register(store, &model.ServerInfo{ID: util.MaxServerID, Address: "remote", StatusAddress: statusAddr, Token: server.token})
+		c.Assert(err, IsNil)
+		defer r.Close()
+		var instance, user string
+		row = dbt.db.QueryRow("select instance, user from information_schema.cluster_processlist where instance = 'remote' and id = ?", id)
+		c.Assert(row.Scan(&instance, &user), IsNil)
+		c.Assert(instance, Equals, "remote")
+		c.Assert(user, Equals, "root")
+
+		var rows [][][]byte
+		err = serverinfo.Fetch(&model.ServerInfo{StatusAddress: statusAddr, Token: server.token}, infoschema.ClusterTableRowsPath("no_such_table"), &rows)
+		c.Assert(err, NotNil)
+		// The rows aren't readable without the token of the server.
+		err = serverinfo.Fetch(&model.ServerInfo{StatusAddress: statusAddr}, infoschema.ClusterTableRowsPath("PROCESSLIST"), &rows)
+		c.Assert(err, ErrorMatches, ".*403 Forbidden.*")
+		err = serverinfo.Fetch(&model.ServerInfo{StatusAddress: statusAddr, Token: "wrong"}, infoschema.ClusterTableRowsPath("PROCESSLIST"), &rows)
+		c.Assert(err, ErrorMatches, ".*403 Forbidden.*")
+	})
+}
+
 func runTestPreparedString(t *C) {
 	runTestsOnNewDB(t, nil, "PreparedString", func(dbt *DBTest) {
 		dbt.mustExec("create table test (a char(10), b char(10))")
@@ -643,6 +813,274 @@ func runTestStatusAPI(c *C) {
 	c.Assert(data.GitHash, Equals, printer.TiDBGitHash)
 }
 
+func runTestHealthAPI(c *C, server *Server) {
+	// The status server on the status address may belong to another server of the tests.
+	router := mux.NewRouter()
+	router.HandleFunc("/health/live", server.handleLiveness)
+	router.HandleFunc("/health/ready", server.handleReadiness)
+	statusServer := httptest.NewServer(router)
+	defer statusServer.Close()
+	getHealth := func(path string) (int, health) {
+		resp, err := http.Get(statusServer.URL + path)
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		var h health
+		c.Assert(json.NewDecoder(resp.Body).Decode(&h), IsNil)
+		return resp.StatusCode, h
+	}
+	code, h := getHealth("/health/live")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(h.Live, IsTrue)
+	code, h = getHealth("/health/ready")
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(h, Equals, health{Live: true, Ready: true, SchemaValid: true, StoreConnected: true})
+
+	// The server isn't ready once it's draining, but it's still alive.
+	server.Drain()
+	defer atomic.StoreInt32(&server.draining, 0)
+	code, h = getHealth("/health/ready")
+	c.Assert(code, Equals, http.StatusServiceUnavailable)
+	c.Assert(h, Equals, health{Live: true, Draining: true, SchemaValid: true, StoreConnected: true})
+	code, _ = getHealth("/health/live")
+	c.Assert(code, Equals, http.StatusOK)
+}
+
+func runTestHTTPSQL(c *C, server *Server) {
+	router := mux.NewRouter()
+	router.HandleFunc("/sql", server.handleSQL)
+	statusServer := httptest.NewServer(router)
+	defer statusServer.Close()
+	post := func(user, password string, sqlReq httpSQLRequest, resp interface{}) int {
+		body, err := json.Marshal(sqlReq)
+		c.Assert(err, IsNil)
+		req, err := http.NewRequest(http.MethodPost, statusServer.URL+"/sql", strings.NewReader(string(body)))
+		c.Assert(err, IsNil)
+		req.SetBasicAuth(user, password)
+		r, err := http.DefaultClient.Do(req)
+		c.Assert(err, IsNil)
+		defer r.Body.Close()
+		c.Assert(json.NewDecoder(r.Body).Decode(resp), IsNil)
+		return r.StatusCode
+	}
+	var resp httpSQLResponse
+	var sqlErr httpSQLError
+	strs := func(values ...string) []*string {
+		ptrs := make([]*string, len(values))
+		for i := range values {
+			if values[i] != "NULL" {
+				ptrs[i] = &values[i]
+			}
+		}
+		return ptrs
+	}
+
+	r, err := http.Get(statusServer.URL + "/sql")
+	c.Assert(err, IsNil)
+	r.Body.Close()
+	c.Assert(r.StatusCode, Equals, http.StatusMethodNotAllowed)
+
+	code := post("root", "", httpSQLRequest{SQL: "create database if not exists http_sql; create table http_sql.t (a int primary key auto_increment, b varchar(10))"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(resp.Results, HasLen, 0)
+	code = post("root", "", httpSQLRequest{SQL: "insert into t (b) values (?), (?)", Args: []interface{}{"x", nil}, DB: "http_sql"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(resp.AffectedRows, Equals, uint64(2))
+	c.Assert(resp.LastInsertID, Equals, uint64(1))
+
+	// The rows are limited by max_rows.
+	code = post("root", "", httpSQLRequest{SQL: "select a, b from http_sql.t where a > ? order by a", Args: []interface{}{0}, MaxRows: 1}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(resp.Results, HasLen, 1)
+	c.Assert(resp.Results[0].Columns, DeepEquals, []httpSQLColumn{{"a", "int"}, {"b", "var_string"}})
+	c.Assert(resp.Results[0].Rows, DeepEquals, [][]*string{strs("1", "x")})
+	c.Assert(resp.Results[0].Truncated, IsTrue)
+	code = post("root", "", httpSQLRequest{SQL: "select a, b from t order by a", DB: "http_sql"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(resp.Results[0].Rows, DeepEquals, [][]*string{strs("1", "x"), strs("2", "NULL")})
+	c.Assert(resp.Results[0].Truncated, IsFalse)
+
+	// The backticks in the name of the database are escaped.
+	code = post("root", "", httpSQLRequest{SQL: "create database `http``sql`"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	code = post("root", "", httpSQLRequest{SQL: "select database()", DB: "http`sql"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(resp.Results[0].Rows, DeepEquals, [][]*string{strs("http`sql")})
+	code = post("root", "", httpSQLRequest{SQL: "select 1", DB: "http_sql`; drop database `http_sql"}, &sqlErr)
+	c.Assert(code, Equals, http.StatusBadRequest)
+	c.Assert(sqlErr.Code, Equals, uint16(tmysql.ErrBadDB))
+	code = post("root", "", httpSQLRequest{SQL: "drop database `http``sql`"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+
+	code = post("root", "", httpSQLRequest{SQL: "select * from http_sql.no_such_table"}, &sqlErr)
+	c.Assert(code, Equals, http.StatusBadRequest)
+	c.Assert(sqlErr.Code, Equals, uint16(tmysql.ErrNoSuchTable))
+	code = post("root", "", httpSQLRequest{SQL: "select ?", Args: []interface{}{1, 2}}, &sqlErr)
+	c.Assert(code, Equals, http.StatusBadRequest)
+	c.Assert(sqlErr.Code, Equals, uint16(tmysql.ErrWrongArguments))
+	code = post("root", "", httpSQLRequest{SQL: "select ?", Args: []interface{}{[]int{1}}}, &sqlErr)
+	c.Assert(code, Equals, http.StatusBadRequest)
+
+	// The users are authenticated with their passwords.
+	code = post("root", "", httpSQLRequest{SQL: "create user 'http_sql'@'%' identified by 'secret'; flush privileges"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	code = post("http_sql", "secret", httpSQLRequest{SQL: "select current_user()"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(*resp.Results[0].Rows[0][0], Equals, "http_sql@127.0.0.1")
+	code = post("http_sql", "wrong", httpSQLRequest{SQL: "select 1"}, &sqlErr)
+	c.Assert(code, Equals, http.StatusUnauthorized)
+	c.Assert(sqlErr.Code, Equals, uint16(tmysql.ErrAccessDenied))
+	code = post("root", "secret", httpSQLRequest{SQL: "select 1"}, &sqlErr)
+	c.Assert(code, Equals, http.StatusUnauthorized)
+
+	code = post("root", "", httpSQLRequest{SQL: "drop user 'http_sql'@'%'; drop database http_sql"}, &resp)
+	c.Assert(code, Equals, http.StatusOK)
+}
+
+func runTestSQLRPC(c *C, server *Server) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	// The service is secured by TLS unless it's allowed to be insecure.
+	_, err = NewSQLRPCServer(server, false)
+	c.Assert(err, NotNil)
+	rpcServer, err := NewSQLRPCServer(server, true)
+	c.Assert(err, IsNil)
+	go rpcServer.Serve(l)
+	defer rpcServer.Close()
+	client, err := sqlrpc.NewClient(l.Addr().String(), "root", "", nil)
+	c.Assert(err, IsNil)
+	defer client.Close()
+	ctx := context.Background()
+	query := func(req *sqlrpc.QueryRequest) []*sqlrpc.QueryResponse {
+		stream, err := client.ExecuteQuery(ctx, req)
+		c.Assert(err, IsNil)
+		var resps []*sqlrpc.QueryResponse
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return resps
+			}
+			c.Assert(err, IsNil)
+			resps = append(resps, resp)
+		}
+	}
+	bytesArg := func(s string) *sqlrpc.Arg {
+		return &sqlrpc.Arg{Type: sqlrpc.ArgType_BYTES, Bytes: []byte(s)}
+	}
+
+	batchResp, err := client.ExecuteBatch(ctx, &sqlrpc.BatchRequest{Statements: []*sqlrpc.Statement{
+		{Sql: "create database if not exists sql_rpc"},
+		{Sql: "create table sql_rpc.t (a int primary key auto_increment, b varchar(10))"},
+	}})
+	c.Assert(err, IsNil)
+	c.Assert(batchResp.Error, IsNil)
+	c.Assert(batchResp.Results, HasLen, 2)
+	batchResp, err = client.ExecuteBatch(ctx, &sqlrpc.BatchRequest{Db: "sql_rpc", Statements: []*sqlrpc.Statement{
+		{Sql: "insert into t (b) values (?), (?)", Args: []*sqlrpc.Arg{bytesArg("x"), {Type: sqlrpc.ArgType_NULL}}},
+		{Sql: "update t set b = ? where a = ?", Args: []*sqlrpc.Arg{bytesArg("y"), {Type: sqlrpc.ArgType_INT, Int: 2}}},
+	}})
+	c.Assert(err, IsNil)
+	c.Assert(batchResp.Error, IsNil)
+	c.Assert(batchResp.Results, DeepEquals, []*sqlrpc.StatementResult{{AffectedRows: 2, LastInsertId: 1}, {AffectedRows: 1}})
+
+	// The transaction of the batch is rolled back on the error.
+	batchResp, err = client.ExecuteBatch(ctx, &sqlrpc.BatchRequest{Db: "sql_rpc", Statements: []*sqlrpc.Statement{
+		{Sql: "insert into t (b) values ('z')"},
+		{Sql: "insert into no_such_table values (1)"},
+	}})
+	c.Assert(err, IsNil)
+	c.Assert(batchResp.Results, HasLen, 1)
+	c.Assert(batchResp.Error.Code, Equals, uint32(tmysql.ErrNoSuchTable))
+
+	// The rows are streamed and limited by max_rows.
+	resps := query(&sqlrpc.QueryRequest{Sql: "select a, b from sql_rpc.t where a > ? order by a", Args: []*sqlrpc.Arg{{Type: sqlrpc.ArgType_UINT, Uint: 0}}, MaxRows: 1})
+	c.Assert(resps, HasLen, 2)
+	c.Assert(resps[0].Columns, HasLen, 2)
+	c.Assert(resps[0].Columns[1].Name, Equals, "b")
+	c.Assert(resps[0].Columns[1].Type, Equals, uint32(tmysql.TypeVarString))
+	c.Assert(resps[0].Rows, DeepEquals, []*sqlrpc.Row{{Values: []*sqlrpc.Value{{Data: []byte("1")}, {Data: []byte("x")}}}})
+	c.Assert(resps[0].Truncated, IsTrue)
+	c.Assert(resps[1].Done, IsTrue)
+	resps = query(&sqlrpc.QueryRequest{Sql: "select b from t where a = 2; select count(*) from t", Db: "sql_rpc"})
+	c.Assert(resps, HasLen, 3)
+	c.Assert(resps[0].Rows, DeepEquals, []*sqlrpc.Row{{Values: []*sqlrpc.Value{{Data: []byte("y")}}}})
+	c.Assert(resps[1].Rows, DeepEquals, []*sqlrpc.Row{{Values: []*sqlrpc.Value{{Data: []byte("2")}}}})
+	c.Assert(resps[2].Done, IsTrue)
+	resps = query(&sqlrpc.QueryRequest{Sql: "select b from sql_rpc.t where b is null"})
+	c.Assert(resps[0].Rows, HasLen, 0)
+
+	resps = query(&sqlrpc.QueryRequest{Sql: "select * from sql_rpc.no_such_table"})
+	c.Assert(resps, HasLen, 1)
+	c.Assert(resps[0].Error.Code, Equals, uint32(tmysql.ErrNoSuchTable))
+	resps = query(&sqlrpc.QueryRequest{Sql: "select ?", Args: []*sqlrpc.Arg{{Type: 10}}})
+	c.Assert(resps[0].Error, NotNil)
+
+	// The users are authenticated with their passwords.
+	badClient, err := sqlrpc.NewClient(l.Addr().String(), "root", "wrong", nil)
+	c.Assert(err, IsNil)
+	defer badClient.Close()
+	_, err = badClient.ExecuteBatch(ctx, &sqlrpc.BatchRequest{Statements: []*sqlrpc.Statement{{Sql: "select 1"}}})
+	c.Assert(grpc.Code(errors.Cause(err)), Equals, codes.Unauthenticated)
+	stream, err := badClient.ExecuteQuery(ctx, &sqlrpc.QueryRequest{Sql: "select 1"})
+	c.Assert(err, IsNil)
+	_, err = stream.Recv()
+	c.Assert(grpc.Code(err), Equals, codes.Unauthenticated)
+
+	// The statement is killed when the call is canceled.
+	cancelCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	stream, err = client.ExecuteQuery(cancelCtx, &sqlrpc.QueryRequest{Sql: "select benchmark(100000000000, 1)"})
+	c.Assert(err, IsNil)
+	_, err = stream.Recv()
+	c.Assert(grpc.Code(err), Equals, codes.DeadlineExceeded)
+	for i := 0; ; i++ {
+		resps = query(&sqlrpc.QueryRequest{Sql: "select count(*) from information_schema.processlist where info like 'select benchmark%'"})
+		if string(resps[0].Rows[0].Values[0].Data) == "0" {
+			break
+		}
+		c.Assert(i, Less, 100)
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	resps = query(&sqlrpc.QueryRequest{Sql: "drop database sql_rpc"})
+	c.Assert(resps[0].Done, IsTrue)
+}
+
+func runTestSessionVarsAttr(c *C, server *Server) {
+	cc, err := server.newSessionConn("root", "", "127.0.0.1:0", "")
+	c.Assert(err, IsNil)
+	defer server.closeSessionConn(cc)
+	query := func(sql string) []string {
+		var values []string
+		err := executeSQL(cc.ctx, sql, nil, func(rs ResultSet) error {
+			columns, err := rs.Columns()
+			c.Assert(err, IsNil)
+			row, err := rs.Next()
+			c.Assert(err, IsNil)
+			for i, d := range row {
+				b, err := dumpTextValue(columns[i], d)
+				c.Assert(err, IsNil)
+				values = append(values, string(b))
+			}
+			return nil
+		})
+		c.Assert(err, IsNil)
+		return values
+	}
+
+	err = cc.setSessionVars(`{"time_zone": "+08:00", "SQL_MODE": "ANSI_QUOTES", "transaction_isolation": "READ-COMMITTED"}`)
+	c.Assert(err, IsNil)
+	c.Assert(query("select @@time_zone, @@sql_mode, @@tx_isolation"), DeepEquals, []string{"+08:00", "ANSI_QUOTES", "READ-COMMITTED"})
+
+	// None of the variables is set if any of them can't be set.
+	err = cc.setSessionVars(`{"time_zone": "+01:00", "version": "1"}`)
+	c.Assert(err, NotNil)
+	err = cc.setSessionVars(`{"time_zone": "+01:00", "tidb_snapshot": "2017-01-01 00:00:00"}`)
+	c.Assert(err, ErrorMatches, ".*can't be set by the tidb_session_vars attribute")
+	err = cc.setSessionVars(`{"time_zone": 1}`)
+	c.Assert(err, ErrorMatches, "invalid tidb_session_vars attribute.*")
+	c.Assert(query("select @@time_zone"), DeepEquals, []string{"+08:00"})
+}
+
 func runTestMultiStatements(c *C) {
 	runTestsOnNewDB(c, nil, "MultiStatements", func(dbt *DBTest) {
 		// Create Table
@@ -676,6 +1114,62 @@ func runTestMultiStatements(c *C) {
 	})
 }
 
+func runTestWaitTimeout(c *C) {
+	runTests(c, nil, func(dbt *DBTest) {
+		dbt.db.SetMaxIdleConns(1)
+		dbt.db.SetMaxOpenConns(1)
+		getTimeouts := func() int {
+			var name string
+			var count int
+			rows := dbt.mustQuery("show status like 'Tidb_connection_timeouts'")
+			c.Assert(rows.Next(), IsTrue)
+			c.Assert(rows.Scan(&name, &count), IsNil)
+			c.Assert(rows.Close(), IsNil)
+			return count
+		}
+		timeouts := getTimeouts()
+		dbt.mustExec("set @@session.wait_timeout = 1")
+		time.Sleep(2 * time.Second)
+		// The connection is closed by the server, the driver may fail the first query on it.
+		dbt.db.Exec("select 1")
+		c.Assert(getTimeouts() > timeouts, IsTrue)
+	})
+}
+
+func runTestIdleTransactionTimeout(c *C) {
+	runTestsOnNewDB(c, nil, "IdleTransactionTimeout", func(dbt *DBTest) {
+		dbt.db.SetMaxIdleConns(1)
+		dbt.db.SetMaxOpenConns(1)
+		dbt.mustExec("create table test (a int)")
+		dbt.mustExec("set @@session.tidb_idle_transaction_timeout = 1")
+		dbt.mustExec("begin")
+		dbt.mustExec("insert test values (1)")
+		time.Sleep(2 * time.Second)
+		// The transaction is rolled back, the next statement reports it.
+		_, err := dbt.db.Exec("insert test values (2)")
+		c.Assert(err, NotNil)
+		c.Assert(err.Error(), Matches, ".*rolled back as it was idle for more than 1 seconds.*")
+		rows := dbt.mustQuery("select count(*) from test")
+		c.Assert(rows.Next(), IsTrue)
+		var count int
+		c.Assert(rows.Scan(&count), IsNil)
+		c.Assert(count, Equals, 0)
+		c.Assert(rows.Close(), IsNil)
+
+		// The connection isn't closed, and the transactions within the timeout are not affected.
+		dbt.mustExec("begin")
+		dbt.mustExec("insert test values (3)")
+		dbt.mustExec("commit")
+		// The connections out of the transactions are not affected.
+		time.Sleep(2 * time.Second)
+		rows = dbt.mustQuery("select a from test")
+		c.Assert(rows.Next(), IsTrue)
+		c.Assert(rows.Scan(&count), IsNil)
+		c.Assert(count, Equals, 3)
+		c.Assert(rows.Close(), IsNil)
+	})
+}
+
 func runTestStmtCount(t *C) {
 	runTestsOnNewDB(t, nil, "StatementCount", func(dbt *DBTest) {
 		originStmtCnt := getStmtCnt(string(getMetrics(t)))
@@ -0,0 +1,298 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+	"net"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/server/sqlrpc"
+	"github.com/pingcap/tidb/terror"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// rpcRowsPerResponse is the max number of the rows in a response of ExecuteQuery.
+	rpcRowsPerResponse = 256
+	// rpcBytesPerResponse is the size of the values after which the rows are sent.
+	rpcBytesPerResponse = 1 << 20
+)
+
+// SQLRPCServer serves the SQL service of sqlrpc, the calls are run in the sessions of the server, so they're
+// authenticated with the MySQL accounts, and they're in the process list and can be killed like the connections.
+type SQLRPCServer struct {
+	server *Server
+	grpc   *grpc.Server
+}
+
+// NewSQLRPCServer creates a SQL service of the server. The passwords are in the metadata of the calls, so the
+// connections are secured by TLS with the certificates of the server, they can only be insecure if insecure is true.
+func NewSQLRPCServer(s *Server, insecure bool) (*SQLRPCServer, error) {
+	var opts []grpc.ServerOption
+	if s.getTLSConfig() != nil {
+		// The config is got for every connection, so the certificates are reloaded like the MySQL protocol.
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				cfg := s.getTLSConfig().Clone()
+				cfg.NextProtos = []string{"h2"}
+				return cfg, nil
+			},
+		})))
+	} else if !insecure {
+		return nil, errors.New("the SQL service requires TLS, set ssl-cert and ssl-key or allow it to be insecure")
+	} else {
+		log.Warn("[sqlrpc] the SQL service is insecure, the passwords are sent in plaintext")
+	}
+	rs := &SQLRPCServer{server: s, grpc: grpc.NewServer(opts...)}
+	sqlrpc.RegisterSQLServer(rs.grpc, rs)
+	return rs, nil
+}
+
+// Serve accepts the connections of the listener, it returns when the server is closed.
+func (rs *SQLRPCServer) Serve(l net.Listener) error {
+	log.Infof("[sqlrpc] server is running on %s", l.Addr())
+	return errors.Trace(rs.grpc.Serve(l))
+}
+
+// Close stops the server and closes the connections.
+func (rs *SQLRPCServer) Close() {
+	rs.grpc.Stop()
+}
+
+// openSession opens a session for a call, the user and the password are in the metadata of the call. The error
+// of the call is returned if the user isn't authenticated, otherwise the error of the session is returned.
+func (rs *SQLRPCServer) openSession(ctx context.Context, db string) (*clientConn, *sqlrpc.Error, error) {
+	var user, password, addr string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md[sqlrpc.UserKey]; len(v) > 0 {
+			user = v[0]
+		}
+		if v := md[sqlrpc.PasswordKey]; len(v) > 0 {
+			password = v[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	cc, err := rs.server.newSessionConn(user, password, addr, db)
+	if errAccessDenied.Equal(err) {
+		return nil, nil, grpc.Errorf(codes.Unauthenticated, "%s", errors.Cause(err).Error())
+	} else if err != nil {
+		return nil, rpcError(err), nil
+	}
+	return cc, nil, nil
+}
+
+// ExecuteQuery implements the SQLServer ExecuteQuery interface.
+func (rs *SQLRPCServer) ExecuteQuery(req *sqlrpc.QueryRequest, stream sqlrpc.SQL_ExecuteQueryServer) error {
+	cc, sessionErr, err := rs.openSession(stream.Context(), req.Db)
+	if err != nil {
+		return err
+	} else if sessionErr != nil {
+		return stream.Send(&sqlrpc.QueryResponse{Error: sessionErr})
+	}
+	defer rs.server.closeSessionConn(cc)
+	defer cancelOnDone(stream.Context(), cc)()
+	token := rs.server.getToken()
+	defer rs.server.releaseToken(token)
+
+	args, err := rpcArgs(req.Args)
+	if err == nil {
+		err = executeSQL(cc.ctx, req.Sql, args, func(r ResultSet) error {
+			return sendRPCResultSet(stream, r, req.MaxRows)
+		})
+	}
+	if err != nil {
+		executeErrorCounter.WithLabelValues(executeErrorToLabel(err)).Inc()
+		return stream.Send(&sqlrpc.QueryResponse{Error: rpcError(err)})
+	}
+	return stream.Send(&sqlrpc.QueryResponse{
+		Done:         true,
+		AffectedRows: cc.ctx.AffectedRows(),
+		LastInsertId: cc.ctx.LastInsertID(),
+		Warnings:     uint32(cc.ctx.WarningCount()),
+	})
+}
+
+// cancelOnDone cancels the running statement of the session when the call is done before it, e.g. the call is
+// canceled or the client is gone. The returned function stops watching the call, it must be called before the
+// session is closed.
+func cancelOnDone(ctx context.Context, cc *clientConn) func() {
+	finished := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			cc.ctx.Cancel()
+		case <-finished:
+		}
+	}()
+	return func() {
+		close(finished)
+		<-stopped
+	}
+}
+
+// sendRPCResultSet sends the columns and the rows of the result set, the rows after maxRows are truncated if
+// it's not 0.
+func sendRPCResultSet(stream sqlrpc.SQL_ExecuteQueryServer, rs ResultSet, maxRows uint64) error {
+	columns, err := rs.Columns()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	resp := &sqlrpc.QueryResponse{Columns: make([]*sqlrpc.Column, 0, len(columns))}
+	for _, col := range columns {
+		resp.Columns = append(resp.Columns, &sqlrpc.Column{
+			Schema:  col.Schema,
+			Table:   col.Table,
+			Name:    col.Name,
+			Type:    uint32(col.Type),
+			Flag:    uint32(col.Flag),
+			Decimal: uint32(col.Decimal),
+			Length:  col.ColumnLength,
+		})
+	}
+	var sentRows uint64
+	var size int
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if maxRows > 0 && sentRows == maxRows {
+			resp.Truncated = true
+			break
+		}
+		values := make([]*sqlrpc.Value, len(row))
+		for i, d := range row {
+			if d.IsNull() {
+				values[i] = &sqlrpc.Value{Null: true}
+				continue
+			}
+			b, err := dumpTextValue(columns[i], d)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			values[i] = &sqlrpc.Value{Data: append([]byte(nil), b...)}
+			size += len(b)
+		}
+		resp.Rows = append(resp.Rows, &sqlrpc.Row{Values: values})
+		sentRows++
+		if len(resp.Rows) == rpcRowsPerResponse || size >= rpcBytesPerResponse {
+			if err = stream.Send(resp); err != nil {
+				return errors.Trace(err)
+			}
+			resp = &sqlrpc.QueryResponse{}
+			size = 0
+		}
+	}
+	if len(resp.Columns) > 0 || len(resp.Rows) > 0 || resp.Truncated {
+		return errors.Trace(stream.Send(resp))
+	}
+	return nil
+}
+
+// ExecuteBatch implements the SQLServer ExecuteBatch interface.
+func (rs *SQLRPCServer) ExecuteBatch(ctx context.Context, req *sqlrpc.BatchRequest) (*sqlrpc.BatchResponse, error) {
+	cc, sessionErr, err := rs.openSession(ctx, req.Db)
+	if err != nil {
+		return nil, err
+	} else if sessionErr != nil {
+		return &sqlrpc.BatchResponse{Error: sessionErr}, nil
+	}
+	defer rs.server.closeSessionConn(cc)
+	defer cancelOnDone(ctx, cc)()
+	token := rs.server.getToken()
+	defer rs.server.releaseToken(token)
+
+	resp := &sqlrpc.BatchResponse{Results: make([]*sqlrpc.StatementResult, 0, len(req.Statements))}
+	err = executeRPCBatch(cc.ctx, req.Statements, resp)
+	if err != nil {
+		executeErrorCounter.WithLabelValues(executeErrorToLabel(err)).Inc()
+		resp.Error = rpcError(err)
+		if _, err1 := cc.ctx.Execute("rollback"); err1 != nil {
+			cc.logger().Errorf("rollback the batch error %s", errors.ErrorStack(err1))
+		}
+	}
+	return resp, nil
+}
+
+func executeRPCBatch(ctx QueryCtx, stmts []*sqlrpc.Statement, resp *sqlrpc.BatchResponse) error {
+	if _, err := ctx.Execute("begin"); err != nil {
+		return errors.Trace(err)
+	}
+	for _, stmt := range stmts {
+		args, err := rpcArgs(stmt.Args)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// The rows of the queries are ignored.
+		err = executeSQL(ctx, stmt.Sql, args, func(r ResultSet) error {
+			for {
+				row, err := r.Next()
+				if row == nil || err != nil {
+					return errors.Trace(err)
+				}
+			}
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		resp.Results = append(resp.Results, &sqlrpc.StatementResult{
+			AffectedRows: ctx.AffectedRows(),
+			LastInsertId: ctx.LastInsertID(),
+			Warnings:     uint32(ctx.WarningCount()),
+		})
+	}
+	_, err := ctx.Execute("commit")
+	return errors.Trace(err)
+}
+
+// rpcArgs converts the arguments of a call to the values of the placeholders.
+func rpcArgs(args []*sqlrpc.Arg) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(args))
+	for i, arg := range args {
+		switch arg.Type {
+		case sqlrpc.ArgType_NULL:
+			values = append(values, nil)
+		case sqlrpc.ArgType_INT:
+			values = append(values, arg.Int)
+		case sqlrpc.ArgType_UINT:
+			values = append(values, arg.Uint)
+		case sqlrpc.ArgType_FLOAT:
+			values = append(values, arg.Float)
+		case sqlrpc.ArgType_BYTES:
+			values = append(values, string(arg.Bytes))
+		default:
+			return nil, errors.Errorf("unknown type %d of argument %d", arg.Type, i+1)
+		}
+	}
+	return values, nil
+}
+
+func rpcError(err error) *sqlrpc.Error {
+	m := terror.ToSQLError(err)
+	return &sqlrpc.Error{Code: uint32(m.Code), State: m.State, Message: m.Message}
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlrpc
+
+import (
+	"crypto/tls"
+
+	"github.com/juju/errors"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// Client is a client of the SQL service.
+type Client struct {
+	conn     *grpc.ClientConn
+	client   SQLClient
+	user     string
+	password string
+}
+
+// NewClient connects to the SQL service at the address, the calls are run by the user. The connection is secured
+// by TLS with tlsConfig, it's insecure if tlsConfig is nil, then the password is sent in plaintext.
+func NewClient(addr, user, password string, tlsConfig *tls.Config) (*Client, error) {
+	opt := grpc.WithInsecure()
+	if tlsConfig != nil {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	conn, err := grpc.Dial(addr, opt)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Client{conn: conn, client: NewSQLClient(conn), user: user, password: password}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return errors.Trace(c.conn.Close())
+}
+
+func (c *Client) withCredentials(ctx context.Context) context.Context {
+	return metadata.NewOutgoingContext(ctx, metadata.Pairs(UserKey, c.user, PasswordKey, c.password))
+}
+
+// ExecuteQuery runs the query, the responses are received from the returned stream until io.EOF.
+func (c *Client) ExecuteQuery(ctx context.Context, req *QueryRequest) (SQL_ExecuteQueryClient, error) {
+	stream, err := c.client.ExecuteQuery(c.withCredentials(ctx), req)
+	return stream, errors.Trace(err)
+}
+
+// ExecuteBatch runs the statements in a transaction.
+func (c *Client) ExecuteBatch(ctx context.Context, req *BatchRequest) (*BatchResponse, error) {
+	resp, err := c.client.ExecuteBatch(c.withCredentials(ctx), req)
+	return resp, errors.Trace(err)
+}
@@ -0,0 +1,29 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlrpc defines a gRPC service to run SQL, it's an alternative to the MySQL protocol for the internal
+// services. The service is defined in sqlrpc.proto for the clients in the other languages, sqlrpc.pb.go is
+// generated from it by protoc-gen-go.
+//
+// ExecuteQuery streams the rows of the result sets, so a large result set isn't buffered in the server or the
+// client, and ExecuteBatch runs the statements in a transaction. The user and the password are sent in the
+// metadata of the calls, so the connections are secured by TLS, every call is run in a new session.
+package sqlrpc
+
+//go:generate protoc --go_out=plugins=grpc:. sqlrpc.proto
+
+// The keys of the credentials in the metadata of the calls.
+const (
+	UserKey     = "user"
+	PasswordKey = "password"
+)
@@ -0,0 +1,686 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: sqlrpc.proto
+
+/*
+Package sqlrpc is a generated protocol buffer package.
+
+It is generated from these files:
+
+	sqlrpc.proto
+
+It has these top-level messages:
+
+	Arg
+	QueryRequest
+	Column
+	Value
+	Row
+	Error
+	QueryResponse
+	Statement
+	BatchRequest
+	StatementResult
+	BatchResponse
+*/
+package sqlrpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// The type of an argument bound to a placeholder.
+type ArgType int32
+
+const (
+	ArgType_NULL  ArgType = 0
+	ArgType_INT   ArgType = 1
+	ArgType_UINT  ArgType = 2
+	ArgType_FLOAT ArgType = 3
+	ArgType_BYTES ArgType = 4
+)
+
+var ArgType_name = map[int32]string{
+	0: "NULL",
+	1: "INT",
+	2: "UINT",
+	3: "FLOAT",
+	4: "BYTES",
+}
+var ArgType_value = map[string]int32{
+	"NULL":  0,
+	"INT":   1,
+	"UINT":  2,
+	"FLOAT": 3,
+	"BYTES": 4,
+}
+
+func (x ArgType) String() string {
+	return proto.EnumName(ArgType_name, int32(x))
+}
+func (ArgType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type Arg struct {
+	Type  ArgType `protobuf:"varint,1,opt,name=type,enum=sqlrpc.ArgType" json:"type,omitempty"`
+	Int   int64   `protobuf:"zigzag64,2,opt,name=int" json:"int,omitempty"`
+	Uint  uint64  `protobuf:"varint,3,opt,name=uint" json:"uint,omitempty"`
+	Float float64 `protobuf:"fixed64,4,opt,name=float" json:"float,omitempty"`
+	Bytes []byte  `protobuf:"bytes,5,opt,name=bytes,proto3" json:"bytes,omitempty"`
+}
+
+func (m *Arg) Reset()                    { *m = Arg{} }
+func (m *Arg) String() string            { return proto.CompactTextString(m) }
+func (*Arg) ProtoMessage()               {}
+func (*Arg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *Arg) GetType() ArgType {
+	if m != nil {
+		return m.Type
+	}
+	return ArgType_NULL
+}
+
+func (m *Arg) GetInt() int64 {
+	if m != nil {
+		return m.Int
+	}
+	return 0
+}
+
+func (m *Arg) GetUint() uint64 {
+	if m != nil {
+		return m.Uint
+	}
+	return 0
+}
+
+func (m *Arg) GetFloat() float64 {
+	if m != nil {
+		return m.Float
+	}
+	return 0
+}
+
+func (m *Arg) GetBytes() []byte {
+	if m != nil {
+		return m.Bytes
+	}
+	return nil
+}
+
+// The sql may have several statements if there are no args.
+type QueryRequest struct {
+	Db   string `protobuf:"bytes,1,opt,name=db" json:"db,omitempty"`
+	Sql  string `protobuf:"bytes,2,opt,name=sql" json:"sql,omitempty"`
+	Args []*Arg `protobuf:"bytes,3,rep,name=args" json:"args,omitempty"`
+	// The max number of the rows of a result set, the rows are truncated after it, 0 means unlimited.
+	MaxRows uint64 `protobuf:"varint,4,opt,name=max_rows,json=maxRows" json:"max_rows,omitempty"`
+}
+
+func (m *QueryRequest) Reset()                    { *m = QueryRequest{} }
+func (m *QueryRequest) String() string            { return proto.CompactTextString(m) }
+func (*QueryRequest) ProtoMessage()               {}
+func (*QueryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *QueryRequest) GetDb() string {
+	if m != nil {
+		return m.Db
+	}
+	return ""
+}
+
+func (m *QueryRequest) GetSql() string {
+	if m != nil {
+		return m.Sql
+	}
+	return ""
+}
+
+func (m *QueryRequest) GetArgs() []*Arg {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *QueryRequest) GetMaxRows() uint64 {
+	if m != nil {
+		return m.MaxRows
+	}
+	return 0
+}
+
+type Column struct {
+	Schema string `protobuf:"bytes,1,opt,name=schema" json:"schema,omitempty"`
+	Table  string `protobuf:"bytes,2,opt,name=table" json:"table,omitempty"`
+	Name   string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	// The type, flag and decimal of the column in the MySQL protocol.
+	Type    uint32 `protobuf:"varint,4,opt,name=type" json:"type,omitempty"`
+	Flag    uint32 `protobuf:"varint,5,opt,name=flag" json:"flag,omitempty"`
+	Decimal uint32 `protobuf:"varint,6,opt,name=decimal" json:"decimal,omitempty"`
+	Length  uint32 `protobuf:"varint,7,opt,name=length" json:"length,omitempty"`
+}
+
+func (m *Column) Reset()                    { *m = Column{} }
+func (m *Column) String() string            { return proto.CompactTextString(m) }
+func (*Column) ProtoMessage()               {}
+func (*Column) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Column) GetSchema() string {
+	if m != nil {
+		return m.Schema
+	}
+	return ""
+}
+
+func (m *Column) GetTable() string {
+	if m != nil {
+		return m.Table
+	}
+	return ""
+}
+
+func (m *Column) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Column) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *Column) GetFlag() uint32 {
+	if m != nil {
+		return m.Flag
+	}
+	return 0
+}
+
+func (m *Column) GetDecimal() uint32 {
+	if m != nil {
+		return m.Decimal
+	}
+	return 0
+}
+
+func (m *Column) GetLength() uint32 {
+	if m != nil {
+		return m.Length
+	}
+	return 0
+}
+
+// A value in the text format of the MySQL protocol.
+type Value struct {
+	Null bool   `protobuf:"varint,1,opt,name=null" json:"null,omitempty"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *Value) Reset()                    { *m = Value{} }
+func (m *Value) String() string            { return proto.CompactTextString(m) }
+func (*Value) ProtoMessage()               {}
+func (*Value) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *Value) GetNull() bool {
+	if m != nil {
+		return m.Null
+	}
+	return false
+}
+
+func (m *Value) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type Row struct {
+	Values []*Value `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+}
+
+func (m *Row) Reset()                    { *m = Row{} }
+func (m *Row) String() string            { return proto.CompactTextString(m) }
+func (*Row) ProtoMessage()               {}
+func (*Row) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Row) GetValues() []*Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type Error struct {
+	Code    uint32 `protobuf:"varint,1,opt,name=code" json:"code,omitempty"`
+	State   string `protobuf:"bytes,2,opt,name=state" json:"state,omitempty"`
+	Message string `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *Error) Reset()                    { *m = Error{} }
+func (m *Error) String() string            { return proto.CompactTextString(m) }
+func (*Error) ProtoMessage()               {}
+func (*Error) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *Error) GetCode() uint32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *Error) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Error) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// The first response of a result set has the columns, the last one of the query is done and has the status of
+// the statements, the query is stopped by the error.
+type QueryResponse struct {
+	Columns      []*Column `protobuf:"bytes,1,rep,name=columns" json:"columns,omitempty"`
+	Rows         []*Row    `protobuf:"bytes,2,rep,name=rows" json:"rows,omitempty"`
+	Truncated    bool      `protobuf:"varint,3,opt,name=truncated" json:"truncated,omitempty"`
+	Done         bool      `protobuf:"varint,4,opt,name=done" json:"done,omitempty"`
+	AffectedRows uint64    `protobuf:"varint,5,opt,name=affected_rows,json=affectedRows" json:"affected_rows,omitempty"`
+	LastInsertId uint64    `protobuf:"varint,6,opt,name=last_insert_id,json=lastInsertId" json:"last_insert_id,omitempty"`
+	Warnings     uint32    `protobuf:"varint,7,opt,name=warnings" json:"warnings,omitempty"`
+	Error        *Error    `protobuf:"bytes,8,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *QueryResponse) Reset()                    { *m = QueryResponse{} }
+func (m *QueryResponse) String() string            { return proto.CompactTextString(m) }
+func (*QueryResponse) ProtoMessage()               {}
+func (*QueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *QueryResponse) GetColumns() []*Column {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func (m *QueryResponse) GetRows() []*Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+func (m *QueryResponse) GetTruncated() bool {
+	if m != nil {
+		return m.Truncated
+	}
+	return false
+}
+
+func (m *QueryResponse) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+func (m *QueryResponse) GetAffectedRows() uint64 {
+	if m != nil {
+		return m.AffectedRows
+	}
+	return 0
+}
+
+func (m *QueryResponse) GetLastInsertId() uint64 {
+	if m != nil {
+		return m.LastInsertId
+	}
+	return 0
+}
+
+func (m *QueryResponse) GetWarnings() uint32 {
+	if m != nil {
+		return m.Warnings
+	}
+	return 0
+}
+
+func (m *QueryResponse) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type Statement struct {
+	Sql  string `protobuf:"bytes,1,opt,name=sql" json:"sql,omitempty"`
+	Args []*Arg `protobuf:"bytes,2,rep,name=args" json:"args,omitempty"`
+}
+
+func (m *Statement) Reset()                    { *m = Statement{} }
+func (m *Statement) String() string            { return proto.CompactTextString(m) }
+func (*Statement) ProtoMessage()               {}
+func (*Statement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *Statement) GetSql() string {
+	if m != nil {
+		return m.Sql
+	}
+	return ""
+}
+
+func (m *Statement) GetArgs() []*Arg {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+type BatchRequest struct {
+	Db         string       `protobuf:"bytes,1,opt,name=db" json:"db,omitempty"`
+	Statements []*Statement `protobuf:"bytes,2,rep,name=statements" json:"statements,omitempty"`
+}
+
+func (m *BatchRequest) Reset()                    { *m = BatchRequest{} }
+func (m *BatchRequest) String() string            { return proto.CompactTextString(m) }
+func (*BatchRequest) ProtoMessage()               {}
+func (*BatchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *BatchRequest) GetDb() string {
+	if m != nil {
+		return m.Db
+	}
+	return ""
+}
+
+func (m *BatchRequest) GetStatements() []*Statement {
+	if m != nil {
+		return m.Statements
+	}
+	return nil
+}
+
+type StatementResult struct {
+	AffectedRows uint64 `protobuf:"varint,1,opt,name=affected_rows,json=affectedRows" json:"affected_rows,omitempty"`
+	LastInsertId uint64 `protobuf:"varint,2,opt,name=last_insert_id,json=lastInsertId" json:"last_insert_id,omitempty"`
+	Warnings     uint32 `protobuf:"varint,3,opt,name=warnings" json:"warnings,omitempty"`
+}
+
+func (m *StatementResult) Reset()                    { *m = StatementResult{} }
+func (m *StatementResult) String() string            { return proto.CompactTextString(m) }
+func (*StatementResult) ProtoMessage()               {}
+func (*StatementResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *StatementResult) GetAffectedRows() uint64 {
+	if m != nil {
+		return m.AffectedRows
+	}
+	return 0
+}
+
+func (m *StatementResult) GetLastInsertId() uint64 {
+	if m != nil {
+		return m.LastInsertId
+	}
+	return 0
+}
+
+func (m *StatementResult) GetWarnings() uint32 {
+	if m != nil {
+		return m.Warnings
+	}
+	return 0
+}
+
+// The results are of the statements run before the error, the transaction is rolled back on the error.
+type BatchResponse struct {
+	Results []*StatementResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	Error   *Error             `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *BatchResponse) Reset()                    { *m = BatchResponse{} }
+func (m *BatchResponse) String() string            { return proto.CompactTextString(m) }
+func (*BatchResponse) ProtoMessage()               {}
+func (*BatchResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *BatchResponse) GetResults() []*StatementResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *BatchResponse) GetError() *Error {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Arg)(nil), "sqlrpc.Arg")
+	proto.RegisterType((*QueryRequest)(nil), "sqlrpc.QueryRequest")
+	proto.RegisterType((*Column)(nil), "sqlrpc.Column")
+	proto.RegisterType((*Value)(nil), "sqlrpc.Value")
+	proto.RegisterType((*Row)(nil), "sqlrpc.Row")
+	proto.RegisterType((*Error)(nil), "sqlrpc.Error")
+	proto.RegisterType((*QueryResponse)(nil), "sqlrpc.QueryResponse")
+	proto.RegisterType((*Statement)(nil), "sqlrpc.Statement")
+	proto.RegisterType((*BatchRequest)(nil), "sqlrpc.BatchRequest")
+	proto.RegisterType((*StatementResult)(nil), "sqlrpc.StatementResult")
+	proto.RegisterType((*BatchResponse)(nil), "sqlrpc.BatchResponse")
+	proto.RegisterEnum("sqlrpc.ArgType", ArgType_name, ArgType_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for SQL service
+
+type SQLClient interface {
+	// ExecuteQuery runs the statements and streams the rows of the result sets.
+	ExecuteQuery(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (SQL_ExecuteQueryClient, error)
+	// ExecuteBatch runs the statements in a transaction.
+	ExecuteBatch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+}
+
+type sQLClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSQLClient(cc *grpc.ClientConn) SQLClient {
+	return &sQLClient{cc}
+}
+
+func (c *sQLClient) ExecuteQuery(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (SQL_ExecuteQueryClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_SQL_serviceDesc.Streams[0], c.cc, "/sqlrpc.SQL/ExecuteQuery", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sQLExecuteQueryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SQL_ExecuteQueryClient interface {
+	Recv() (*QueryResponse, error)
+	grpc.ClientStream
+}
+
+type sQLExecuteQueryClient struct {
+	grpc.ClientStream
+}
+
+func (x *sQLExecuteQueryClient) Recv() (*QueryResponse, error) {
+	m := new(QueryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sQLClient) ExecuteBatch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := grpc.Invoke(ctx, "/sqlrpc.SQL/ExecuteBatch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for SQL service
+
+type SQLServer interface {
+	// ExecuteQuery runs the statements and streams the rows of the result sets.
+	ExecuteQuery(*QueryRequest, SQL_ExecuteQueryServer) error
+	// ExecuteBatch runs the statements in a transaction.
+	ExecuteBatch(context.Context, *BatchRequest) (*BatchResponse, error)
+}
+
+func RegisterSQLServer(s *grpc.Server, srv SQLServer) {
+	s.RegisterService(&_SQL_serviceDesc, srv)
+}
+
+func _SQL_ExecuteQuery_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SQLServer).ExecuteQuery(m, &sQLExecuteQueryServer{stream})
+}
+
+type SQL_ExecuteQueryServer interface {
+	Send(*QueryResponse) error
+	grpc.ServerStream
+}
+
+type sQLExecuteQueryServer struct {
+	grpc.ServerStream
+}
+
+func (x *sQLExecuteQueryServer) Send(m *QueryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SQL_ExecuteBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SQLServer).ExecuteBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sqlrpc.SQL/ExecuteBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SQLServer).ExecuteBatch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SQL_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "sqlrpc.SQL",
+	HandlerType: (*SQLServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExecuteBatch",
+			Handler:    _SQL_ExecuteBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteQuery",
+			Handler:       _SQL_ExecuteQuery_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sqlrpc.proto",
+}
+
+func init() { proto.RegisterFile("sqlrpc.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 714 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xdd, 0x6a, 0xdb, 0x4c,
+	0x10, 0xcd, 0x4a, 0xf2, 0xdf, 0x44, 0x76, 0xfc, 0x2d, 0xc9, 0x57, 0x35, 0x14, 0x6a, 0x94, 0x16,
+	0x4c, 0x29, 0x69, 0x93, 0xde, 0xf6, 0x87, 0xa4, 0xa4, 0x10, 0x6a, 0x52, 0xb2, 0x71, 0x0a, 0xbd,
+	0x0a, 0x6b, 0x69, 0xad, 0x18, 0xf4, 0xe3, 0x68, 0x57, 0xb5, 0x0d, 0xbd, 0xed, 0x3b, 0xf4, 0x01,
+	0xfa, 0xa0, 0x65, 0x47, 0x5a, 0xdb, 0x49, 0x08, 0xed, 0xdd, 0x99, 0x33, 0xf2, 0xcc, 0x99, 0x33,
+	0xe3, 0x05, 0x57, 0xde, 0xc4, 0xf9, 0x34, 0xd8, 0x9f, 0xe6, 0x99, 0xca, 0x68, 0xbd, 0x8c, 0xfc,
+	0x1f, 0x60, 0x1f, 0xe5, 0x11, 0xdd, 0x03, 0x47, 0x2d, 0xa6, 0xc2, 0x23, 0x3d, 0xd2, 0xef, 0x1c,
+	0x6e, 0xed, 0x57, 0xdf, 0x1e, 0xe5, 0xd1, 0x70, 0x31, 0x15, 0x0c, 0x93, 0xb4, 0x0b, 0xf6, 0x24,
+	0x55, 0x9e, 0xd5, 0x23, 0x7d, 0xca, 0x34, 0xa4, 0x14, 0x9c, 0x42, 0x53, 0x76, 0x8f, 0xf4, 0x1d,
+	0x86, 0x98, 0x6e, 0x43, 0x6d, 0x1c, 0x67, 0x5c, 0x79, 0x4e, 0x8f, 0xf4, 0x09, 0x2b, 0x03, 0xcd,
+	0x8e, 0x16, 0x4a, 0x48, 0xaf, 0xd6, 0x23, 0x7d, 0x97, 0x95, 0x81, 0x1f, 0x83, 0x7b, 0x5e, 0x88,
+	0x7c, 0xc1, 0xc4, 0x4d, 0x21, 0xa4, 0xa2, 0x1d, 0xb0, 0xc2, 0x11, 0x8a, 0x68, 0x31, 0x2b, 0x1c,
+	0xe9, 0x8e, 0xf2, 0x26, 0xc6, 0x8e, 0x2d, 0xa6, 0x21, 0x7d, 0x0a, 0x0e, 0xcf, 0x23, 0xe9, 0xd9,
+	0x3d, 0xbb, 0xbf, 0x79, 0xb8, 0xb9, 0x26, 0x94, 0x61, 0x82, 0x3e, 0x86, 0x66, 0xc2, 0xe7, 0x57,
+	0x79, 0x36, 0x93, 0xa8, 0xc0, 0x61, 0x8d, 0x84, 0xcf, 0x59, 0x36, 0x93, 0xfe, 0x6f, 0x02, 0xf5,
+	0x8f, 0x59, 0x5c, 0x24, 0x29, 0xfd, 0x1f, 0xea, 0x32, 0xb8, 0x16, 0x09, 0xaf, 0x9a, 0x55, 0x91,
+	0x96, 0xa9, 0xf8, 0x28, 0x16, 0x55, 0xcb, 0x32, 0xd0, 0x63, 0xa6, 0x3c, 0x11, 0x38, 0x66, 0x8b,
+	0x21, 0xd6, 0x1c, 0x3a, 0xa6, 0x7b, 0xb4, 0x2b, 0x83, 0x28, 0x38, 0xe3, 0x98, 0x47, 0x38, 0x63,
+	0x9b, 0x21, 0xa6, 0x1e, 0x34, 0x42, 0x11, 0x4c, 0x12, 0x1e, 0x7b, 0x75, 0xa4, 0x4d, 0xa8, 0x35,
+	0xc4, 0x22, 0x8d, 0xd4, 0xb5, 0xd7, 0xc0, 0x44, 0x15, 0xf9, 0xaf, 0xa0, 0xf6, 0x95, 0xc7, 0x45,
+	0xd9, 0xb6, 0x88, 0x63, 0x94, 0xd8, 0x64, 0x88, 0x35, 0x17, 0x72, 0xc5, 0x51, 0x9f, 0xcb, 0x10,
+	0xfb, 0x2f, 0xc1, 0x66, 0xd9, 0x8c, 0x3e, 0x87, 0xfa, 0x77, 0xfd, 0x3b, 0xe9, 0x11, 0x34, 0xa7,
+	0x6d, 0xcc, 0xc1, 0x6a, 0xac, 0x4a, 0xfa, 0x9f, 0xa1, 0x76, 0x92, 0xe7, 0x59, 0xae, 0x4b, 0x05,
+	0x59, 0x58, 0xee, 0xbc, 0xcd, 0x10, 0xeb, 0xf9, 0xa5, 0xe2, 0x6a, 0x39, 0x3f, 0x06, 0x7a, 0x86,
+	0x44, 0x48, 0xc9, 0x23, 0x63, 0x81, 0x09, 0xfd, 0x5f, 0x16, 0xb4, 0xab, 0x0d, 0xca, 0x69, 0x96,
+	0x4a, 0x41, 0xfb, 0xd0, 0x08, 0xd0, 0x63, 0x23, 0xa3, 0x63, 0x64, 0x94, 0xd6, 0x33, 0x93, 0xd6,
+	0xab, 0xc4, 0x2d, 0x59, 0xb7, 0x57, 0xc9, 0xb2, 0x19, 0xc3, 0x04, 0x7d, 0x02, 0x2d, 0x95, 0x17,
+	0x69, 0xc0, 0x95, 0x08, 0xb1, 0x71, 0x93, 0xad, 0x08, 0x74, 0x22, 0x4b, 0xcb, 0x05, 0x34, 0x19,
+	0x62, 0xba, 0x07, 0x6d, 0x3e, 0x1e, 0x8b, 0x40, 0x89, 0xb0, 0xbc, 0x80, 0x1a, 0x5e, 0x80, 0x6b,
+	0x48, 0x7d, 0x06, 0xf4, 0x19, 0x74, 0x62, 0x2e, 0xd5, 0xd5, 0x24, 0x95, 0x22, 0x57, 0x57, 0x93,
+	0x10, 0x17, 0xe3, 0x30, 0x57, 0xb3, 0xa7, 0x48, 0x9e, 0x86, 0x74, 0x17, 0x9a, 0x33, 0x9e, 0xa7,
+	0x93, 0x34, 0x92, 0xd5, 0x7e, 0x96, 0x31, 0xdd, 0x83, 0x9a, 0xd0, 0x16, 0x7a, 0xcd, 0x1e, 0x59,
+	0x37, 0x1a, 0x7d, 0x65, 0x65, 0xce, 0x7f, 0x0f, 0xad, 0x0b, 0xed, 0x5e, 0x22, 0x52, 0x65, 0x0e,
+	0x99, 0xdc, 0x3f, 0x64, 0xeb, 0x81, 0x43, 0xf6, 0xcf, 0xc1, 0x3d, 0xe6, 0x2a, 0xb8, 0x7e, 0xe8,
+	0xbf, 0x71, 0x00, 0x20, 0x4d, 0x7d, 0x53, 0xe6, 0x3f, 0x53, 0x66, 0xd9, 0x99, 0xad, 0x7d, 0xe4,
+	0xcf, 0x61, 0x6b, 0x95, 0x10, 0xb2, 0x88, 0xd5, 0x7d, 0xc7, 0xc8, 0x3f, 0x39, 0x66, 0xfd, 0xc5,
+	0x31, 0xfb, 0xb6, 0x63, 0x7e, 0x04, 0xed, 0x6a, 0x98, 0xea, 0x4c, 0x0e, 0xa0, 0x91, 0xa3, 0x02,
+	0x73, 0x26, 0x8f, 0xee, 0x4b, 0xc7, 0x3c, 0x33, 0xdf, 0xad, 0x5c, 0xb7, 0x1e, 0x76, 0xfd, 0xc5,
+	0x5b, 0x68, 0x54, 0x8f, 0x16, 0x6d, 0x82, 0x73, 0x76, 0x39, 0x18, 0x74, 0x37, 0x68, 0x03, 0xec,
+	0xd3, 0xb3, 0x61, 0x97, 0x68, 0xea, 0x52, 0x23, 0x8b, 0xb6, 0xa0, 0xf6, 0x69, 0xf0, 0xe5, 0x68,
+	0xd8, 0xb5, 0x35, 0x3c, 0xfe, 0x36, 0x3c, 0xb9, 0xe8, 0x3a, 0x87, 0x3f, 0x09, 0xd8, 0x17, 0xe7,
+	0x03, 0xfa, 0x01, 0xdc, 0x93, 0xb9, 0x08, 0x0a, 0x25, 0xf0, 0xb8, 0xe9, 0xb6, 0xe9, 0xb5, 0xfe,
+	0x5a, 0xed, 0xee, 0xdc, 0x61, 0xcb, 0xd1, 0xfc, 0x8d, 0xd7, 0x84, 0xbe, 0x5b, 0x16, 0xc0, 0xb1,
+	0x57, 0x05, 0xd6, 0x57, 0xba, 0xbb, 0x73, 0x87, 0x35, 0x05, 0x46, 0x75, 0x7c, 0xa4, 0xdf, 0xfc,
+	0x19, 0x00, 0xdd, 0xab, 0xee, 0xa7, 0xb4, 0x05, 0x00, 0x00,
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlrpc
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	. "github.com/pingcap/check"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testSQLRPCSuite{})
+
+type testSQLRPCSuite struct{}
+
+func (s *testSQLRPCSuite) TestEncoding(c *C) {
+	req := &QueryRequest{
+		Db:      "test",
+		Sql:     "select ?, ?, ?",
+		Args:    []*Arg{{Type: ArgType_INT, Int: -1}, {Type: ArgType_BYTES, Bytes: []byte("x")}, {Type: ArgType_NULL}},
+		MaxRows: 10,
+	}
+	b, err := proto.Marshal(req)
+	c.Assert(err, IsNil)
+	// The fields are encoded as they're numbered in sqlrpc.proto, the int is sint64.
+	c.Assert(b[:6], DeepEquals, []byte{0x0a, 4, 't', 'e', 's', 't'})
+	c.Assert(b[len(b)-2:], DeepEquals, []byte{0x20, 10})
+	argBytes, err := proto.Marshal(&Arg{Type: ArgType_INT, Int: -1})
+	c.Assert(err, IsNil)
+	c.Assert(argBytes, DeepEquals, []byte{0x08, 1, 0x10, 1})
+	got := new(QueryRequest)
+	c.Assert(proto.Unmarshal(b, got), IsNil)
+	c.Assert(got, DeepEquals, req)
+
+	resp := &QueryResponse{
+		Rows:  []*Row{{Values: []*Value{{Null: true}, {Data: []byte("1")}}}},
+		Error: &Error{Code: 1146, State: "42S02", Message: "table doesn't exist"},
+	}
+	b, err = proto.Marshal(resp)
+	c.Assert(err, IsNil)
+	gotResp := new(QueryResponse)
+	c.Assert(proto.Unmarshal(b, gotResp), IsNil)
+	c.Assert(gotResp, DeepEquals, resp)
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/pem"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"os"
 	"time"
 
@@ -32,6 +33,8 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/server/sqlrpc"
+	"golang.org/x/net/context"
 )
 
 type TidbTestSuite struct {
@@ -50,11 +53,12 @@ func (ts *TidbTestSuite) SetUpSuite(c *C) {
 	c.Assert(err, IsNil)
 	ts.tidbdrv = NewTiDBDriver(store)
 	cfg := &config.Config{
-		Addr:         ":4001",
-		LogLevel:     "debug",
-		StatusAddr:   ":10090",
-		ReportStatus: true,
-		TCPKeepAlive: true,
+		Addr:             ":4001",
+		LogLevel:         "debug",
+		StatusAddr:       ":10090",
+		ReportStatus:     true,
+		TCPKeepAlive:     true,
+		AdvertiseAddress: "127.0.0.1",
 	}
 
 	server, err := NewServer(cfg, ts.tidbdrv)
@@ -137,11 +141,37 @@ func (ts *TidbTestSuite) TestStatusAPI(c *C) {
 	runTestStatusAPI(c)
 }
 
+func (ts *TidbTestSuite) TestHealthAPI(c *C) {
+	runTestHealthAPI(c, ts.server)
+}
+
+func (ts *TidbTestSuite) TestHTTPSQL(c *C) {
+	runTestHTTPSQL(c, ts.server)
+}
+
+func (ts *TidbTestSuite) TestSQLRPC(c *C) {
+	runTestSQLRPC(c, ts.server)
+}
+
+func (ts *TidbTestSuite) TestSessionVarsAttr(c *C) {
+	runTestSessionVarsAttr(c, ts.server)
+}
+
 func (ts *TidbTestSuite) TestMultiStatements(c *C) {
 	c.Parallel()
 	runTestMultiStatements(c)
 }
 
+func (ts *TidbTestSuite) TestWaitTimeout(c *C) {
+	c.Parallel()
+	runTestWaitTimeout(c)
+}
+
+func (ts *TidbTestSuite) TestIdleTransactionTimeout(c *C) {
+	c.Parallel()
+	runTestIdleTransactionTimeout(c)
+}
+
 func (ts *TidbTestSuite) TestSocket(c *C) {
 	cfg := &config.Config{
 		LogLevel:   "debug",
@@ -168,7 +198,7 @@ func (ts *TidbTestSuite) TestSocket(c *C) {
 // If parentCert and parentCertKey is specified, the new certificate will be signed by the parentCert.
 // Otherwise, the new certificate will be self-signed and is a CA.
 func generateCert(sn int, commonName string, parentCert *x509.Certificate, parentCertKey *rsa.PrivateKey, outKeyFile string, outCertFile string) (*x509.Certificate, *rsa.PrivateKey, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 512)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
@@ -358,6 +388,80 @@ func (ts *TidbTestSuite) TestTLS(c *C) {
 	server.Close()
 }
 
+func (ts *TidbTestSuite) TestSQLRPCTLS(c *C) {
+	_, _, err := generateCert(0, "TiDB Server Certificate", nil, nil, "/tmp/rpc-server-key.pem", "/tmp/rpc-server-cert.pem")
+	c.Assert(err, IsNil)
+	defer func() {
+		os.Remove("/tmp/rpc-server-key.pem")
+		os.Remove("/tmp/rpc-server-cert.pem")
+	}()
+
+	cfg := &config.Config{
+		Addr:        ":4005",
+		LogLevel:    "debug",
+		SSLCertPath: "/tmp/rpc-server-cert.pem",
+		SSLKeyPath:  "/tmp/rpc-server-key.pem",
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	defer server.Close()
+	rpcServer, err := NewSQLRPCServer(server, false)
+	c.Assert(err, IsNil)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	go rpcServer.Serve(l)
+	defer rpcServer.Close()
+
+	ctx := context.Background()
+	req := &sqlrpc.BatchRequest{Statements: []*sqlrpc.Statement{{Sql: "select 1"}}}
+	client, err := sqlrpc.NewClient(l.Addr().String(), "root", "", &tls.Config{InsecureSkipVerify: true})
+	c.Assert(err, IsNil)
+	defer client.Close()
+	resp, err := client.ExecuteBatch(ctx, req)
+	c.Assert(err, IsNil)
+	c.Assert(resp.Error, IsNil)
+
+	// The insecure clients can't connect to the service.
+	insecureClient, err := sqlrpc.NewClient(l.Addr().String(), "root", "", nil)
+	c.Assert(err, IsNil)
+	defer insecureClient.Close()
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = insecureClient.ExecuteBatch(ctx, req)
+	c.Assert(err, NotNil)
+}
+
+func (ts *TidbTestSuite) TestCharsetConversion(c *C) {
+	c.Parallel()
+	runTestCharsetConversion(c)
+}
+
+func (ts *TidbTestSuite) TestKill(c *C) {
+	c.Parallel()
+	runTestKill(c)
+}
+
+func (ts *TidbTestSuite) TestClusterInfo(c *C) {
+	c.Parallel()
+	runTestClusterInfo(c)
+}
+
+func (ts *TidbTestSuite) TestAdvertiseAddr(c *C) {
+	hostname, err := os.Hostname()
+	c.Assert(err, IsNil)
+	s := &Server{cfg: &config.Config{}}
+	c.Assert(s.advertiseAddr(":4000"), Equals, net.JoinHostPort(hostname, "4000"))
+	c.Assert(s.advertiseAddr("0.0.0.0:4000"), Equals, net.JoinHostPort(hostname, "4000"))
+	c.Assert(s.advertiseAddr("10.0.1.2:4000"), Equals, "10.0.1.2:4000")
+	s.cfg.AdvertiseAddress = "tidb-0.tidb"
+	c.Assert(s.advertiseAddr("0.0.0.0:10080"), Equals, "tidb-0.tidb:10080")
+}
+
+func (ts *TidbTestSuite) TestClusterTables(c *C) {
+	c.Parallel()
+	runTestClusterTables(c, ts.server, ts.tidbdrv.store)
+}
+
 func (ts *TidbTestSuite) TestClientWithCollation(c *C) {
 	c.Parallel()
 	runTestClientWithCollation(c)
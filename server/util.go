@@ -327,13 +327,13 @@ func dumpTextValue(colInfo *ColumnInfo, value types.Datum) ([]byte, error) {
 		if colInfo.Decimal > 0 && int(colInfo.Decimal) != mysql.NotFixedDec {
 			prec = int(colInfo.Decimal)
 		}
-		return strconv.AppendFloat(nil, value.GetFloat64(), 'f', prec, 32), nil
+		return types.AppendFormatFloat(nil, value.GetFloat64(), prec, 32), nil
 	case types.KindFloat64:
 		prec := -1
 		if colInfo.Decimal > 0 && int(colInfo.Decimal) != mysql.NotFixedDec {
 			prec = int(colInfo.Decimal)
 		}
-		return strconv.AppendFloat(nil, value.GetFloat64(), 'f', prec, 64), nil
+		return types.AppendFormatFloat(nil, value.GetFloat64(), prec, 64), nil
 	case types.KindString, types.KindBytes:
 		return value.GetBytes(), nil
 	case types.KindMysqlTime:
@@ -89,6 +89,23 @@ func (s *testUtilSuite) TestDumpTextValue(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(string(bs), Equals, "2.20")
 
+	// The floats without the fixed decimals are formatted in the shortest representation like MySQL.
+	colInfo.Decimal = mysql.NotFixedDec
+	for _, t := range []struct {
+		d      types.Datum
+		expect string
+	}{
+		{types.NewFloat64Datum(0.1), "0.1"},
+		{types.NewFloat64Datum(1e15), "1e15"},
+		{types.NewFloat64Datum(-2.5e-20), "-2.5e-20"},
+		{types.NewFloat32Datum(123456789), "123457000"},
+		{types.NewFloat32Datum(0.7), "0.7"},
+	} {
+		bs, err = dumpTextValue(colInfo, t.d)
+		c.Assert(err, IsNil)
+		c.Assert(string(bs), Equals, t.expect)
+	}
+
 	colInfo.Type = mysql.TypeBlob
 	bs, err = dumpTextValue(colInfo, types.NewBytesDatum([]byte("foo")))
 	c.Assert(err, IsNil)
@@ -51,7 +51,10 @@ import (
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/rowlock"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/userlock"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -265,6 +268,7 @@ func (s *session) doCommit() error {
 		return nil
 	}
 	defer func() {
+		s.releaseRowLocks()
 		s.txn = nil
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
 	}()
@@ -312,17 +316,20 @@ func (s *session) doCommitWithRetry() error {
 	err := s.doCommit()
 	if err != nil {
 		if s.isRetryableError(err) {
-			log.Warnf("[%d] retryable error: %v, txn: %v", s.sessionVars.ConnectionID, err, s.txn)
-			// Transactions will retry 2 ~ commitRetryLimit times.
+			logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("retryable error: %v, txn: %v", err, s.txn)
+			// Transactions will retry 1 ~ tidb_retry_limit times.
 			// We make larger transactions retry less times to prevent cluster resource outage.
-			txnSizeRate := float64(txnSize) / float64(kv.TxnTotalSizeLimit)
-			maxRetryCount := commitRetryLimit - int(float64(commitRetryLimit-1)*txnSizeRate)
-			err = s.retry(maxRetryCount, domain.ErrInfoSchemaChanged.Equal(err))
+			retryLimit := s.sessionVars.RetryLimit
+			if retryLimit > 0 {
+				txnSizeRate := float64(txnSize) / float64(kv.TxnTotalSizeLimit)
+				maxRetryCount := retryLimit - int(float64(retryLimit-1)*txnSizeRate)
+				err = s.retry(maxRetryCount, domain.ErrInfoSchemaChanged.Equal(err))
+			}
 		}
 	}
 	s.cleanRetryInfo()
 	if err != nil {
-		log.Warnf("[%d] finished txn:%v, %v", s.sessionVars.ConnectionID, s.txn, err)
+		logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("finished txn:%v, %v", s.txn, err)
 		return errors.Trace(err)
 	}
 	mapper := s.GetSessionVars().TxnCtx.TableDeltaMap
@@ -343,6 +350,7 @@ func (s *session) RollbackTxn() error {
 	if s.txn != nil && s.txn.Valid() {
 		err = s.txn.Rollback()
 	}
+	s.releaseRowLocks()
 	s.cleanRetryInfo()
 	s.txn = nil
 	s.txnFuture = nil
@@ -350,6 +358,13 @@ func (s *session) RollbackTxn() error {
 	return errors.Trace(err)
 }
 
+// releaseRowLocks releases the row locks of "SELECT .. FOR UPDATE" when the transaction ends.
+func (s *session) releaseRowLocks() {
+	if s.txn != nil && s.sessionVars.TxnCtx.ForUpdate {
+		rowlock.Release(s.txn)
+	}
+}
+
 func (s *session) GetClient() kv.Client {
 	return s.store.GetClient()
 }
@@ -393,7 +408,7 @@ func (s *session) isRetryableError(err error) bool {
 	return kv.IsRetryableError(err) || domain.ErrInfoSchemaChanged.Equal(err)
 }
 
-func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
+func (s *session) retry(maxCnt int, infoSchemaChanged bool) (err error) {
 	connID := s.sessionVars.ConnectionID
 	if s.sessionVars.TxnCtx.ForUpdate {
 		return errors.Errorf("[%d] can not retry select for update statement", connID)
@@ -403,12 +418,15 @@ func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
 	defer func() {
 		s.sessionVars.RetryInfo.Retrying = false
 		sessionRetry.Observe(float64(retryCnt))
+		if err != nil {
+			atomic.AddInt64(&retryStatus.failures, 1)
+		}
 		s.txn = nil
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
 	}()
 	nh := getHistory(s)
-	var err error
 	for {
+		atomic.AddInt64(&retryStatus.retries, 1)
 		s.PrepareTxnCtx()
 		s.sessionVars.RetryInfo.ResetOffset()
 		for i, sr := range nh.history {
@@ -424,9 +442,9 @@ func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
 			if retryCnt == 0 {
 				// We do not have to log the query every time.
 				// We print the queries at the first try only.
-				log.Warnf("[%d] Retry [%d] query [%d] %s", connID, retryCnt, i, sqlForLog(txt))
+				logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("Retry [%d] query [%d] %s", retryCnt, i, sqlForLog(txt))
 			} else {
-				log.Warnf("[%d] Retry [%d] query [%d]", connID, retryCnt, i)
+				logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("Retry [%d] query [%d]", retryCnt, i)
 			}
 			s.sessionVars.StmtCtx = sr.stmtCtx
 			s.sessionVars.StmtCtx.ResetForRetry()
@@ -442,16 +460,16 @@ func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
 			}
 		}
 		if !s.isRetryableError(err) {
-			log.Warnf("[%d] session:%v, err:%v", connID, s, err)
+			logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("session:%v, err:%v", s, err)
 			return errors.Trace(err)
 		}
 		retryCnt++
 		infoSchemaChanged = domain.ErrInfoSchemaChanged.Equal(err)
 		if !s.unlimitedRetryCount && (retryCnt >= maxCnt) {
-			log.Warnf("[%d] Retry reached max count %d", connID, retryCnt)
+			logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("Retry reached max count %d", retryCnt)
 			return errors.Trace(err)
 		}
-		log.Warnf("[%d] retryable error: %v, txn: %v", connID, err, s.txn)
+		logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("retryable error: %v, txn: %v", err, s.txn)
 		kv.BackOff(retryCnt)
 		s.txn = nil
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, false)
@@ -459,6 +477,29 @@ func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
 	return err
 }
 
+var retryStatus struct {
+	// retries is the number of the times the transactions are replayed.
+	retries int64
+	// failures is the number of the retried transactions which failed.
+	failures int64
+}
+
+// retryStats implements variable.Statistics interface, it returns the status variables of the retries.
+type retryStats struct{}
+
+// GetScope implements variable.Statistics GetScope interface.
+func (s retryStats) GetScope(status string) variable.ScopeFlag {
+	return variable.ScopeGlobal
+}
+
+// Stats implements variable.Statistics Stats interface.
+func (s retryStats) Stats(vars *variable.SessionVars) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"Tidb_txn_retries":        atomic.LoadInt64(&retryStatus.retries),
+		"Tidb_txn_retry_failures": atomic.LoadInt64(&retryStatus.failures),
+	}, nil
+}
+
 func updateStatement(st ast.Statement, s *session, txt string) (ast.Statement, error) {
 	// statement maybe stale because of infoschema changed, this function will return the updated one.
 	if st.IsPrepared() {
@@ -466,6 +507,7 @@ func updateStatement(st ast.Statement, s *session, txt string) (ast.Statement, e
 	} else {
 		// Rebuild plan if infoschema changed, reuse the statement otherwise.
 		charset, collation := s.sessionVars.GetCharsetInfo()
+		s.parser.SetSQLMode(s.sessionVars.SQLMode)
 		stmt, err := s.parser.ParseOneStmt(txt, charset, collation)
 		if err != nil {
 			return st, errors.Trace(err)
@@ -641,6 +683,9 @@ func (s *session) SetProcessInfo(sql string) {
 		pi.User = s.sessionVars.User.Username
 		pi.Host = s.sessionVars.User.Hostname
 	}
+	if sql != "" {
+		pi.Progress = &s.sessionVars.StmtCtx.Progress
+	}
 	s.processInfo.Store(pi)
 }
 
@@ -652,7 +697,7 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	connID := s.sessionVars.ConnectionID
 	rawStmts, err := s.ParseSQL(sql, charset, collation)
 	if err != nil {
-		log.Warnf("[%d] parse error:\n%v\n%s", connID, err, sql)
+		logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("parse error:\n%v\n%s", err, sql)
 		return nil, errors.Trace(err)
 	}
 	sessionExecuteParseDuration.Observe(time.Since(startTS).Seconds())
@@ -662,11 +707,12 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	for i, rst := range rawStmts {
 		s.PrepareTxnCtx()
 		startTS := time.Now()
+		rst = s.rewriteStmt(rst, charset, collation)
 		// Some executions are done in compile stage, so we reset them before compile.
 		executor.ResetStmtCtx(s, rst)
 		st, err1 := Compile(s, rst)
 		if err1 != nil {
-			log.Warnf("[%d] compile error:\n%v\n%s", connID, err1, sql)
+			logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("compile error:\n%v\n%s", err1, sql)
 			s.RollbackTxn()
 			return nil, errors.Trace(err1)
 		}
@@ -680,7 +726,7 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 		ph.EndStatement(s.stmtState)
 		if err != nil {
 			if !kv.ErrKeyExists.Equal(err) {
-				log.Warnf("[%d] session error:\n%v\n%s", connID, errors.ErrorStack(err), s)
+				logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("session error:\n%v\n%s", errors.ErrorStack(err), s)
 			}
 			return nil, errors.Trace(err)
 		}
@@ -699,6 +745,26 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 	return rs, nil
 }
 
+// rewriteStmt rewrites the statement by the query rewrite rules. The restricted SQL is not rewritten, and the
+// statement is kept if the rewritten text can't be parsed.
+func (s *session) rewriteStmt(stmt ast.StmtNode, charset, collation string) ast.StmtNode {
+	h := sessionctx.GetDomain(s).QueryRewriteHandle()
+	if h == nil || s.sessionVars.InRestrictedSQL {
+		return stmt
+	}
+	sql, ok := h.Get().Rewrite(stmt.Text(), s.sessionVars.CurrentDB)
+	if !ok {
+		return stmt
+	}
+	newStmt, err := s.parser.ParseOneStmt(sql, charset, collation)
+	if err != nil {
+		logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Warnf("parse rewritten statement error:\n%v\n%s", err, sql)
+		return stmt
+	}
+	logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Debugf("rewrite statement %s to %s", stmt.Text(), sql)
+	return newStmt
+}
+
 // PrepareStmt is used for executing prepare statement in binary protocol
 func (s *session) PrepareStmt(sql string) (stmtID uint32, paramCount int, fields []*ast.ResultField, err error) {
 	if s.sessionVars.TxnCtx.InfoSchema == nil {
@@ -813,7 +879,7 @@ func (s *session) GetTxn(forceNew bool) (kv.Transaction, error) {
 	if !ac {
 		s.sessionVars.SetStatusFlag(mysql.ServerStatusInTrans, true)
 	}
-	log.Infof("[%d] %s new txn:%s", s.sessionVars.ConnectionID, force, s.txn)
+	logutil.QueryLogger(logutil.ModuleSession, s.sessionVars).Infof("%s new txn:%s", force, s.txn)
 	return s.txn, nil
 }
 
@@ -833,6 +899,7 @@ func (s *session) NewTxn() error {
 		return errors.Trace(err)
 	}
 	s.txn = txn
+	s.initTxnIsolation(txn)
 	return nil
 }
 
@@ -863,6 +930,9 @@ func (s *session) Close() {
 	if err := s.RollbackTxn(); err != nil {
 		log.Error("session Close error:", errors.ErrorStack(err))
 	}
+	if _, err := userlock.GetManager(s.store).ReleaseAll(s.sessionVars.ConnectionID); err != nil {
+		log.Error("session Close release user-level locks error:", errors.ErrorStack(err))
+	}
 	return
 }
 
@@ -962,12 +1032,18 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 		runInBootstrapSession(store, bootstrap)
 	} else if ver < currentBootstrapVersion {
 		runInBootstrapSession(store, upgrade)
+	} else if ver > currentBootstrapVersion {
+		warnDowngrade(ver)
 	}
 
 	se, err := createSession(store)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	err = loadNewCollationEnabled(se)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	dom := sessionctx.GetDomain(se)
 	err = dom.LoadPrivilegeLoop(se)
 	if err != nil {
@@ -981,6 +1057,14 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	se2, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = dom.LoadQueryRewriteRulesLoop(se2)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	if raw, ok := store.(domain.EtcdBackend); ok {
 		err = raw.StartGCWorker()
@@ -1028,6 +1112,7 @@ func createSession(store kv.Storage) (*session, error) {
 		parser:      parser.New(),
 		sessionVars: variable.NewSessionVars(),
 	}
+	s.sessionVars.RetryLimit = commitRetryLimit
 	s.mu.values = make(map[fmt.Stringer]interface{})
 	sessionctx.BindDomain(s, domain)
 	// session implements variable.GlobalVarAccessor. Bind it to ctx.
@@ -1046,6 +1131,7 @@ func createSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 		parser:      parser.New(),
 		sessionVars: variable.NewSessionVars(),
 	}
+	s.sessionVars.RetryLimit = commitRetryLimit
 	s.mu.values = make(map[fmt.Stringer]interface{})
 	sessionctx.BindDomain(s, dom)
 	// session implements variable.GlobalVarAccessor. Bind it to ctx.
@@ -1055,7 +1141,7 @@ func createSessionWithDomain(store kv.Storage, dom *domain.Domain) (*session, er
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 15
+	currentBootstrapVersion = 22
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -1104,6 +1190,16 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.AutocommitVar + quoteCommaQuote +
 	variable.SQLModeVar + quoteCommaQuote +
 	variable.MaxAllowedPacket + quoteCommaQuote +
+	variable.TxnIsolation + quoteCommaQuote +
+	variable.WaitTimeout + quoteCommaQuote +
+	variable.InteractiveTimeout + quoteCommaQuote +
+	variable.NetReadTimeout + quoteCommaQuote +
+	variable.NetWriteTimeout + quoteCommaQuote +
+	variable.BlockEncryptionMode + quoteCommaQuote +
+	variable.ValidatePasswordLength + quoteCommaQuote +
+	variable.ValidatePasswordMixedCaseCount + quoteCommaQuote +
+	variable.ValidatePasswordNumberCount + quoteCommaQuote +
+	variable.ValidatePasswordSpecialCharCount + quoteCommaQuote +
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check + quoteCommaQuote +
 	variable.TiDBIndexJoinBatchSize + quoteCommaQuote +
@@ -1112,6 +1208,8 @@ const loadCommonGlobalVarsSQL = "select HIGH_PRIORITY * from mysql.global_variab
 	variable.TiDBIndexSerialScanConcurrency + quoteCommaQuote +
 	variable.TiDBMaxRowCountForINLJ + quoteCommaQuote +
 	variable.TiDBCBO + quoteCommaQuote +
+	variable.TiDBRowFormatVersion + quoteCommaQuote +
+	variable.TiDBIdleTransactionTimeout + quoteCommaQuote +
 	variable.TiDBDistSQLScanConcurrency + "')"
 
 // loadCommonGlobalVariablesIfNeeded loads and applies commonly used global variables for the session.
@@ -1132,12 +1230,19 @@ func (s *session) loadCommonGlobalVariablesIfNeeded() error {
 		log.Errorf("Failed to load common global variables.")
 		return errors.Trace(err)
 	}
+	_, waitTimeoutSet := vars.Systems[variable.WaitTimeout]
 	for _, row := range rows {
 		varName := row.Data[0].GetString()
 		if _, ok := vars.Systems[varName]; !ok {
 			varsutil.SetSessionSystemVar(s.sessionVars, varName, row.Data[1])
 		}
 	}
+	// The wait_timeout of an interactive client is initialized from the interactive_timeout.
+	// See https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html#sysvar_wait_timeout
+	if interactiveTimeout, ok := vars.Systems[variable.InteractiveTimeout]; ok && !waitTimeoutSet &&
+		vars.ClientCapability&mysql.ClientInteractive > 0 {
+		varsutil.SetSessionSystemVar(s.sessionVars, variable.WaitTimeout, types.NewStringDatum(interactiveTimeout))
+	}
 	vars.CommonGlobalLoaded = true
 	return nil
 }
@@ -1215,12 +1320,75 @@ func (s *session) ActivePendingTxn() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if s.sessionVars.Systems[variable.TxnIsolation] == ast.ReadCommitted {
+	s.initTxnIsolation(txn)
+	return nil
+}
+
+// initTxnIsolation sets the isolation level of a new transaction, the level set by SET TRANSACTION is taken by
+// this transaction and isn't used after it.
+func (s *session) initTxnIsolation(txn kv.Transaction) {
+	s.sessionVars.TxnCtx.Isolation = s.sessionVars.TxnIsolationOneShot
+	s.sessionVars.TxnIsolationOneShot = ""
+	if s.sessionVars.TxnIsolationLevel() == ast.ReadCommitted {
 		txn.SetOption(kv.IsolationLevel, kv.RC)
 	}
+}
+
+// refreshReadTS gets a new read timestamp for each statement of a read committed transaction,
+// so the statement reads the data committed before it starts.
+func (s *session) refreshReadTS() error {
+	txnCtx := s.sessionVars.TxnCtx
+	if s.txn == nil || !s.txn.Valid() {
+		txnCtx.ReadTS = 0
+		return nil
+	}
+	// A transaction prewritten ahead keeps reading its snapshot, a newer read timestamp can't skip its own locks.
+	if txnCtx.PrewrittenAhead || s.sessionVars.TxnIsolationLevel() != ast.ReadCommitted {
+		// The keys prewritten ahead are locked at the start timestamp, the transaction reads the same snapshot at
+		// the timestamp before it to skip its own locks, as no transaction commits at the start timestamp.
+		var readTS uint64
+		if txnCtx.PrewrittenAhead {
+			readTS = s.txn.StartTS() - 1
+		}
+		if txnCtx.ReadTS != readTS {
+			txnCtx.ReadTS = readTS
+			if readTS == 0 {
+				readTS = s.txn.StartTS()
+			}
+			s.txn.SetOption(kv.SnapshotTS, readTS)
+		}
+		return nil
+	}
+	ver, err := s.store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	txnCtx.ReadTS = ver.Ver
+	s.txn.SetOption(kv.SnapshotTS, ver.Ver)
 	return nil
 }
 
+// prewriteAhead prewrites the mutations of the executed statements of an explicit transaction in the background
+// if tidb_pipelined_prewrite is on. Only the snapshot isolation transactions are prewritten ahead, as they read
+// their snapshot before their own locks, and the retries aren't prewritten ahead.
+func (s *session) prewriteAhead() {
+	if !s.sessionVars.PipelinedPrewrite || s.sessionVars.RetryInfo.Retrying {
+		return
+	}
+	if s.sessionVars.TxnIsolationLevel() == ast.ReadCommitted {
+		return
+	}
+	if s.txn == nil || !s.txn.Valid() || s.txn.IsReadOnly() {
+		return
+	}
+	txn, ok := s.txn.(kv.PipelinedTransaction)
+	if !ok {
+		return
+	}
+	txn.PrewriteAhead()
+	s.sessionVars.TxnCtx.PrewrittenAhead = true
+}
+
 // InitTxnWithStartTS create a transaction with startTS.
 func (s *session) InitTxnWithStartTS(startTS uint64) error {
 	if s.txn != nil && s.txn.Valid() {
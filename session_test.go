@@ -2739,6 +2739,38 @@ func (s *testSessionSuite) TestRetryResetStmtCtx(c *C) {
 	c.Assert(se.AffectedRows(), Equals, uint64(1))
 }
 
+func (s *testSessionSuite) TestRetryLimit(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_retry_limit"
+	se := newSession(c, s.store, dbName).(*session)
+	mustExecSQL(c, se, "create table retrylimit (a int unique, b int)")
+	mustExecSQL(c, se, "insert retrylimit values (1, 1)")
+	mustExecMatch(c, se, "select @@tidb_retry_limit", [][]interface{}{{commitRetryLimit}})
+	se2 := newSession(c, s.store, dbName)
+
+	retries, failures := retryStatus.retries, retryStatus.failures
+	mustExecSQL(c, se, "begin")
+	mustExecSQL(c, se, "update retrylimit set b = b + 1 where a = 1")
+	mustExecSQL(c, se2, "update retrylimit set b = b + 1 where a = 1")
+	mustExecSQL(c, se, "commit")
+	mustExecMatch(c, se, "select b from retrylimit", [][]interface{}{{3}})
+	c.Assert(retryStatus.retries, Equals, retries+1)
+	c.Assert(retryStatus.failures, Equals, failures)
+	status, err := retryStats{}.Stats(nil)
+	c.Assert(err, IsNil)
+	c.Assert(status["Tidb_txn_retries"], Equals, retries+1)
+
+	// The transaction isn't retried if tidb_retry_limit is 0.
+	mustExecSQL(c, se, "set @@tidb_retry_limit = 0")
+	mustExecSQL(c, se, "begin")
+	mustExecSQL(c, se, "update retrylimit set b = b + 1 where a = 1")
+	mustExecSQL(c, se2, "update retrylimit set b = b + 1 where a = 1")
+	_, err = se.Execute("commit")
+	c.Assert(kv.IsRetryableError(err), IsTrue)
+	mustExecMatch(c, se, "select b from retrylimit", [][]interface{}{{4}})
+	c.Assert(retryStatus.retries, Equals, retries+1)
+}
+
 func (s *testSessionSuite) TestCommitWhenSchemaChanged(c *C) {
 	c.Skip("skip localstore when lease is 0")
 	defer testleak.AfterTest(c)()
@@ -2782,3 +2814,153 @@ func (s *testSessionSuite) TestPrepareStmtCommitWhenSchemaChanged(c *C) {
 	// _, err = s2.Execute("commit")
 	// c.Assert(terror.ErrorEqual(err, executor.ErrWrongValueCountOnRow), IsTrue)
 }
+
+func (s *testSessionSuite) TestQueryRewriteRules(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_query_rewrite_rules"
+	se := newSession(c, s.store, dbName)
+	mustExecSQL(c, se, "create table t (a int, b int)")
+	mustExecSQL(c, se, "insert into t values (1, 1), (2, 2), (3, 3)")
+	mustExecSQL(c, se, `insert into mysql.query_rewrite_rules (pattern, pattern_db, replacement) values
+		("select a from t where b > 0", "test_query_rewrite_rules", "select a from t where b > ? order by a desc limit 1"),
+		("select a from t where a in (1)", "", "select a + 10 from t where a in (?) order by a"),
+		("select a from t where a = 1", "", "select a from t where a = ? and b = ?"),
+		("select b from t where a = 1", "", "select b frm t where a = ?")`)
+	// The rules are loaded after reloading.
+	mustExecMatch(c, se, "select a from t where b > 1", [][]interface{}{{2}, {3}})
+	mustExecSQL(c, se, "admin reload rewrite rules")
+	mustExecMatch(c, se, "SELECT a FROM t WHERE b > 1", [][]interface{}{{3}})
+	mustExecMatch(c, se, "select a from t where a in (3, 1)", [][]interface{}{{11}, {13}})
+	// The rule has more markers than the literals of the pattern is skipped.
+	mustExecMatch(c, se, "select a from t where a = 2", [][]interface{}{{2}})
+	// The statement is kept if the rewritten text can't be parsed.
+	mustExecMatch(c, se, "select b from t where a = 2", [][]interface{}{{2}})
+	// The rule of another database doesn't match.
+	mustExecSQL(c, se, "create database test_query_rewrite_rules1")
+	mustExecSQL(c, se, "use test_query_rewrite_rules1")
+	mustExecSQL(c, se, "create table t (a int, b int)")
+	mustExecSQL(c, se, "insert into t values (1, 1), (2, 2)")
+	mustExecMatch(c, se, "select a from t where b > 0", [][]interface{}{{1}, {2}})
+	mustExecMatch(c, se, "select a from t where a in (1, 2)", [][]interface{}{{11}, {12}})
+
+	mustExecSQL(c, se, "update mysql.query_rewrite_rules set enabled = 0")
+	mustExecSQL(c, se, "admin reload rewrite rules")
+	mustExecMatch(c, se, "select a from t where a in (1, 2)", [][]interface{}{{1}, {2}})
+	mustExecSQL(c, se, "delete from mysql.query_rewrite_rules")
+	mustExecSQL(c, se, "admin reload rewrite rules")
+	mustExecSQL(c, se, "drop database test_query_rewrite_rules1")
+	mustExecSQL(c, se, "drop database "+dbName)
+}
+
+func (s *testSessionSuite) TestRowFormatVersion(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_row_format_version"
+	se := newSession(c, s.store, dbName)
+	mustExecMatch(c, se, "select @@global.tidb_row_format_version, @@tidb_row_format_version", [][]interface{}{{"1", "1"}})
+	mustExecSQL(c, se, "create table t (a int primary key, b varchar(10), c int, d int)")
+	mustExecSQL(c, se, "insert into t values (1, 'a', 1, null), (2, 'b', 2, 2)")
+	mustExecSQL(c, se, "set @@tidb_row_format_version = 2")
+	mustExecSQL(c, se, "insert into t values (3, 'c', 3, null), (4, 'd', 4, 4)")
+	mustExecSQL(c, se, "update t set c = c + 10 where a in (1, 3)")
+	mustExecFailed(c, se, "set @@tidb_row_format_version = 3")
+
+	// The tables have the rows of both versions.
+	mustExecSQL(c, se, "alter table t add column e int default 5")
+	mustExecSQL(c, se, "alter table t add index idx_c (c)")
+	mustExecMatch(c, se, "select * from t", [][]interface{}{
+		{1, []byte("a"), 11, nil, 5}, {2, []byte("b"), 2, 2, 5}, {3, []byte("c"), 13, nil, 5}, {4, []byte("d"), 4, 4, 5}})
+	mustExecMatch(c, se, "select a, d from t use index (idx_c) where c > 3 order by c", [][]interface{}{{4, 4}, {1, nil}, {3, nil}})
+	mustExecSQL(c, se, "set @@tidb_row_format_version = 1")
+	mustExecSQL(c, se, "update t set d = a where d is null")
+	mustExecMatch(c, se, "select a, d, e from t where d > 0", [][]interface{}{{1, 1, 5}, {2, 2, 5}, {3, 3, 5}, {4, 4, 5}})
+	mustExecSQL(c, se, "drop database "+dbName)
+}
+
+func (s *testSessionSuite) TestSetTransaction(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_set_transaction"
+	se := newSession(c, s.store, dbName)
+	se1 := newSession(c, s.store, dbName)
+	mustExecSQL(c, se, "create table t (a int primary key, b int)")
+	mustExecSQL(c, se, "insert into t values (1, 1)")
+
+	// SET TRANSACTION sets the isolation level of the next transaction only.
+	mustExecSQL(c, se, "set transaction isolation level read committed")
+	mustExecSQL(c, se, "begin")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{1}})
+	mustExecSQL(c, se1, "update t set b = 2")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{2}})
+	mustExecSQL(c, se, "commit")
+	mustExecMatch(c, se, "select @@tx_isolation", [][]interface{}{{"REPEATABLE-READ"}})
+	mustExecSQL(c, se, "begin")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{2}})
+	mustExecSQL(c, se1, "update t set b = 3")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{2}})
+
+	// It can't be set after a statement of the transaction.
+	_, err := exec(se, "set transaction isolation level read committed")
+	c.Assert(terror.ErrorEqual(err, executor.ErrCantChangeTxChars), IsTrue, Commentf("err %v", err))
+	mustExecSQL(c, se, "commit")
+
+	// Without autocommit, it's set by the first statement of the transaction.
+	mustExecSQL(c, se, "set autocommit = 0")
+	mustExecSQL(c, se, "set transaction isolation level read committed")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{3}})
+	mustExecSQL(c, se1, "update t set b = 4")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{4}})
+	mustExecSQL(c, se, "commit")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{4}})
+	mustExecSQL(c, se1, "update t set b = 5")
+	mustExecMatch(c, se, "select b from t", [][]interface{}{{4}})
+	mustExecSQL(c, se, "commit")
+	mustExecSQL(c, se, "set autocommit = 1")
+	mustExecSQL(c, se, "drop database "+dbName)
+}
+
+func (s *testSessionSuite) TestNewCollation(c *C) {
+	defer testleak.AfterTest(c)()
+	dbName := "test_new_collation"
+	se := newSession(c, s.store, dbName)
+	mustExecMatch(c, se, "select variable_value from mysql.tidb where variable_name = 'new_collation_enabled'", [][]interface{}{{[]byte("True")}})
+	mustExecSQL(c, se, "create table t (a int primary key, b char(10), c varchar(10) collate utf8_general_ci, d decimal(5, 2), unique index idx_b (b), unique index idx_c (c), index idx_d (d), index idx_cp (c(2)))")
+	mustExecSQL(c, se, "insert into t values (1, 'a', 'a', 1.5), (2, 'b', 'B', 10), (3, 'c', 'c ', -1.25)")
+
+	// The padded and case-insensitive strings are duplicated.
+	mustExecFailed(c, se, "insert into t values (4, 'a  ', 'x', 0)")
+	mustExecFailed(c, se, "insert into t values (4, 'x', 'A', 0)")
+	mustExecFailed(c, se, "insert into t values (4, 'x', 'b  ', 0)")
+
+	// The index scans return the same rows as the table scans.
+	for _, hint := range []string{"use index (idx_c)", "use index (idx_cp)", "ignore index (idx_c, idx_cp)"} {
+		mustExecMatch(c, se, "select a from t "+hint+" where c = 'C'", [][]interface{}{{3}})
+		mustExecMatch(c, se, "select a from t "+hint+" where c in ('A', 'b') order by a", [][]interface{}{{1}, {2}})
+		mustExecMatch(c, se, "select a from t "+hint+" where c > 'a' and c < 'C' order by a", [][]interface{}{{2}})
+		mustExecMatch(c, se, "select a from t "+hint+" where c like 'b%'", [][]interface{}{{2}})
+		mustExecMatch(c, se, "select a from t "+hint+" where c like 'c'", nil)
+	}
+	mustExecMatch(c, se, "select a from t use index (idx_b) where b = 'a  '", [][]interface{}{{1}})
+
+	// The indexed values are restored from the index values.
+	mustExecMatch(c, se, "select c from t use index (idx_c) order by c", [][]interface{}{{[]byte("a")}, {[]byte("B")}, {[]byte("c ")}})
+	mustExecMatch(c, se, "select c from t use index (idx_c) where c >= 'B' order by c desc", [][]interface{}{{[]byte("c ")}, {[]byte("B")}})
+	mustExecMatch(c, se, "select d from t use index (idx_d) where d > 1 order by d", [][]interface{}{{"1.50"}, {"10.00"}})
+	mustExecMatch(c, se, "select a from t where d < 0", [][]interface{}{{3}})
+
+	// The strings are sorted and grouped by the collations.
+	mustExecSQL(c, se, "insert into t values (4, 'd', 'C2', 2)")
+	mustExecMatch(c, se, "select a from t order by c, a", [][]interface{}{{1}, {2}, {3}, {4}})
+	mustExecSQL(c, se, "create table t1 (a int, c varchar(10) collate utf8_general_ci)")
+	mustExecSQL(c, se, "insert into t1 values (1, 'x'), (2, 'X '), (3, 'y')")
+	mustExecMatch(c, se, "select count(*) from t1 group by c order by count(*)", [][]interface{}{{1}, {2}})
+	mustExecMatch(c, se, "select a from t1 where c = 'x' order by a", [][]interface{}{{1}, {2}})
+
+	// The index is updated and checked.
+	mustExecSQL(c, se, "update t set c = 'CC' where a = 3")
+	mustExecMatch(c, se, "select a from t use index (idx_c) where c = 'cc'", [][]interface{}{{3}})
+	mustExecSQL(c, se, "delete from t where c = 'B'")
+	mustExecMatch(c, se, "select a from t use index (idx_c) where c = 'b'", nil)
+	mustExecSQL(c, se, "alter table t1 add index idx_c (c)")
+	mustExecMatch(c, se, "select a from t1 use index (idx_c) where c = 'X' order by a", [][]interface{}{{1}, {2}})
+	mustExecSQL(c, se, "admin check table t, t1")
+	mustExecSQL(c, se, "drop database "+dbName)
+}
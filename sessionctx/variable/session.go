@@ -17,11 +17,13 @@ import (
 	"crypto/tls"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/sqldigest"
 )
 
 const (
@@ -86,7 +88,13 @@ type TransactionContext struct {
 	Histroy       interface{}
 	SchemaVersion int64
 	StartTS       uint64
-	TableDeltaMap map[int64]TableDelta
+	// ReadTS is the timestamp the statement reads at in a read committed transaction.
+	ReadTS uint64
+	// Isolation is the isolation level set by SET TRANSACTION for the transaction, tx_isolation is used if it's empty.
+	Isolation string
+	// PrewrittenAhead is set if the transaction has prewritten the mutations of its executed statements.
+	PrewrittenAhead bool
+	TableDeltaMap   map[int64]TableDelta
 }
 
 // UpdateDeltaForTable updates the delta info for some table.
@@ -153,6 +161,9 @@ type SessionVars struct {
 	// SnapshotTS is used for reading history data. For simplicity, SnapshotTS only supports distsql request.
 	SnapshotTS uint64
 
+	// TxnIsolationOneShot is the isolation level set by SET TRANSACTION, it's used by the next transaction only.
+	TxnIsolationOneShot string
+
 	// SnapshotInfoschema is used with SnapshotTS, when the schema version at snapshotTS less than current schema
 	// version, we load an old version schema for query.
 	SnapshotInfoschema interface{}
@@ -166,6 +177,10 @@ type SessionVars struct {
 	// LastFoundRows is the number of found rows of last query statement
 	LastFoundRows uint64
 
+	// PrevAffectedRows is the number of affected rows of the previous statement, it's -1 if the
+	// previous statement is a query.
+	PrevAffectedRows int64
+
 	// StmtCtx holds variables for current executing statement.
 	StmtCtx *StatementContext
 
@@ -175,6 +190,18 @@ type SessionVars struct {
 	// AllowInSubqueryUnFolding can be set to true to fold in subquery
 	AllowInSubqueryUnFolding bool
 
+	// EnableCascadesPlanner can be set to true to search the physical plan by the cascades optimizer.
+	EnableCascadesPlanner bool
+
+	// AllowLateMaterialization can be set to true to late materialize the columns of the filtered table scans.
+	AllowLateMaterialization bool
+
+	// TraceOptimizer is set by tidb_trace_optimizer, the optimization of the next statement is traced, then it's reset.
+	TraceOptimizer bool
+
+	// LastOptimizerTrace is the JSON of the optimizer trace of the last traced statement.
+	LastOptimizerTrace string
+
 	// CurrInsertValues is used to record current ValuesExpr's values.
 	// See http://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_values
 	CurrInsertValues interface{}
@@ -222,6 +249,34 @@ type SessionVars struct {
 
 	// CBO indicates if we use new planner with cbo.
 	CBO bool
+
+	// RetryLimit is the maximum number of retries of a transaction.
+	RetryLimit int
+
+	// AutoConvertLongString indicates if a too long CHAR or VARCHAR column is converted to a TEXT or BLOB type.
+	AutoConvertLongString bool
+
+	// RowFormatVersion is the format version of the rows written by the session.
+	RowFormatVersion int
+
+	// HashDistinctSpillSize is the number of the distinct keys a hash distinct executor keeps in memory.
+	HashDistinctSpillSize int
+
+	// PipelinedPrewrite indicates if the mutations of an explicit transaction are prewritten statement by statement.
+	PipelinedPrewrite bool
+
+	// IdleTransactionTimeout is the number of seconds a transaction can be idle between its statements.
+	IdleTransactionTimeout int
+
+	// WaitTimeout is the number of seconds the server waits for the next command of the connection.
+	WaitTimeout int
+	// NetReadTimeout is the number of seconds the server waits for more data from the connection in a command.
+	NetReadTimeout int
+	// NetWriteTimeout is the number of seconds the server waits for a write to the connection.
+	NetWriteTimeout int
+
+	// MaxAllowedPacket is the max length of a packet read from or written to the connection.
+	MaxAllowedPacket int
 }
 
 // NewSessionVars creates a session vars object.
@@ -245,14 +300,16 @@ func NewSessionVars() *SessionVars {
 		DistSQLScanConcurrency:     DefDistSQLScanConcurrency,
 		MaxRowCountForINLJ:         DefMaxRowCountForINLJ,
 		CBO:                        true,
+		RetryLimit:                 DefRetryLimit,
+		RowFormatVersion:           DefRowFormatVersion,
+		HashDistinctSpillSize:      DefHashDistinctSpillSize,
+		WaitTimeout:                DefWaitTimeout,
+		NetReadTimeout:             DefNetReadTimeout,
+		NetWriteTimeout:            DefNetWriteTimeout,
+		MaxAllowedPacket:           DefMaxAllowedPacket,
 	}
 }
 
-const (
-	characterSetConnection = "character_set_connection"
-	collationConnection    = "collation_connection"
-)
-
 // GetCharsetInfo gets charset and collation for current context.
 // What character set should the server translate a statement to after receiving it?
 // For this, the server uses the character_set_connection and collation_connection system variables.
@@ -263,8 +320,8 @@ const (
 // have their own collation, which has a higher collation precedence.
 // See https://dev.mysql.com/doc/refman/5.7/en/charset-connection.html
 func (s *SessionVars) GetCharsetInfo() (charset, collation string) {
-	charset = s.Systems[characterSetConnection]
-	collation = s.Systems[collationConnection]
+	charset = s.Systems[CharacterSetConnection]
+	collation = s.Systems[CollationConnection]
 	return
 }
 
@@ -295,6 +352,14 @@ func (s *SessionVars) InTxn() bool {
 	return s.GetStatusFlag(mysql.ServerStatusInTrans)
 }
 
+// TxnIsolationLevel returns the isolation level of the current transaction.
+func (s *SessionVars) TxnIsolationLevel() string {
+	if s.TxnCtx != nil && s.TxnCtx.Isolation != "" {
+		return s.TxnCtx.Isolation
+	}
+	return s.Systems[TxnIsolation]
+}
+
 // IsAutocommit returns if the session is set to autocommit.
 func (s *SessionVars) IsAutocommit() bool {
 	return s.GetStatusFlag(mysql.ServerStatusAutocommit)
@@ -306,6 +371,16 @@ func (s *SessionVars) GetNextPreparedStmtID() uint32 {
 	return s.preparedStmtID
 }
 
+// GetLastPreparedStmtID returns the id of the last prepared statement.
+func (s *SessionVars) GetLastPreparedStmtID() uint32 {
+	return s.preparedStmtID
+}
+
+// SetLastPreparedStmtID sets the id of the last prepared statement, the next prepared statement gets the id after it.
+func (s *SessionVars) SetLastPreparedStmtID(id uint32) {
+	s.preparedStmtID = id
+}
+
 // GetTimeZone returns the value of time_zone session variable.
 func (s *SessionVars) GetTimeZone() *time.Location {
 	loc := s.TimeZone
@@ -317,12 +392,43 @@ func (s *SessionVars) GetTimeZone() *time.Location {
 
 // special session variables.
 const (
-	SQLModeVar          = "sql_mode"
-	AutocommitVar       = "autocommit"
-	CharacterSetResults = "character_set_results"
-	MaxAllowedPacket    = "max_allowed_packet"
-	TimeZone            = "time_zone"
-	TxnIsolation        = "tx_isolation"
+	SQLModeVar             = "sql_mode"
+	AutocommitVar          = "autocommit"
+	CharacterSetClient     = "character_set_client"
+	CharacterSetConnection = "character_set_connection"
+	CharacterSetResults    = "character_set_results"
+	MaxAllowedPacket       = "max_allowed_packet"
+	TimeZone               = "time_zone"
+	TxnIsolation           = "tx_isolation"
+	TxnIsolationOneShot    = "tx_isolation_one_shot"
+	TransactionIsolation   = "transaction_isolation"
+	TxReadOnly             = "tx_read_only"
+	TransactionReadOnly    = "transaction_read_only"
+	WaitTimeout            = "wait_timeout"
+	InteractiveTimeout     = "interactive_timeout"
+	NetReadTimeout         = "net_read_timeout"
+	NetWriteTimeout        = "net_write_timeout"
+	BlockEncryptionMode    = "block_encryption_mode"
+	LcTimeNames            = "lc_time_names"
+
+	ValidatePasswordLength           = "validate_password_length"
+	ValidatePasswordMixedCaseCount   = "validate_password_mixed_case_count"
+	ValidatePasswordNumberCount      = "validate_password_number_count"
+	ValidatePasswordSpecialCharCount = "validate_password_special_char_count"
+)
+
+// Default values of the connection timeouts in seconds.
+const (
+	DefWaitTimeout     = 28800
+	DefNetReadTimeout  = 30
+	DefNetWriteTimeout = 60
+)
+
+// The default value and the limits of max_allowed_packet in bytes.
+const (
+	DefMaxAllowedPacket = 67108864
+	MinMaxAllowedPacket = 1024
+	MaxMaxAllowedPacket = 1073741824
 )
 
 // TableDelta stands for the changed count for one table.
@@ -339,6 +445,7 @@ type StatementContext struct {
 	InInsertStmt         bool
 	InUpdateOrDeleteStmt bool
 	InSelectStmt         bool
+	InExecuteStmt        bool
 	IgnoreTruncate       bool
 	TruncateAsWarning    bool
 	OverflowAsWarning    bool
@@ -350,11 +457,18 @@ type StatementContext struct {
 		affectedRows uint64
 		foundRows    uint64
 		warnings     []error
+		// sqlDigest is the digest of digestSQL, it's computed once for a statement.
+		sqlDigest string
+		digestSQL string
 	}
 
 	// Copied from SessionVars.TimeZone.
 	TimeZone *time.Location
 	Priority mysql.PriorityEnum
+	// OriginalSQL is the text of the statement.
+	OriginalSQL string
+	// Progress is updated by the readers of the statement, it's shown to the other sessions.
+	Progress QueryProgress
 }
 
 // AddAffectedRows adds affected rows.
@@ -364,6 +478,13 @@ func (sc *StatementContext) AddAffectedRows(rows uint64) {
 	sc.mu.Unlock()
 }
 
+// SetAffectedRows sets affected rows.
+func (sc *StatementContext) SetAffectedRows(rows uint64) {
+	sc.mu.Lock()
+	sc.mu.affectedRows = rows
+	sc.mu.Unlock()
+}
+
 // AffectedRows gets affected rows.
 func (sc *StatementContext) AffectedRows() uint64 {
 	sc.mu.Lock()
@@ -387,6 +508,17 @@ func (sc *StatementContext) AddFoundRows(rows uint64) {
 	sc.mu.Unlock()
 }
 
+// SQLDigest gets the digest of the normalized OriginalSQL, it's computed only the first time it's called.
+func (sc *StatementContext) SQLDigest() string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.mu.sqlDigest == "" || sc.mu.digestSQL != sc.OriginalSQL {
+		_, sc.mu.sqlDigest = sqldigest.NormalizeDigest(sc.OriginalSQL)
+		sc.mu.digestSQL = sc.OriginalSQL
+	}
+	return sc.mu.sqlDigest
+}
+
 // GetWarnings gets warnings.
 func (sc *StatementContext) GetWarnings() []error {
 	sc.mu.Lock()
@@ -460,6 +592,73 @@ func (sc *StatementContext) ResetForRetry() {
 	sc.mu.foundRows = 0
 	sc.mu.warnings = nil
 	sc.mu.Unlock()
+	sc.Progress.reset()
+}
+
+// QueryProgress is the progress of a statement, it's counted by the readers which read rows from the storage. It's read
+// by SHOW PROGRESS and information_schema.PROCESSLIST in other sessions, so the counters are accessed atomically.
+type QueryProgress struct {
+	estimatedRows     int64
+	scannedRows       int64
+	operators         int64
+	finishedOperators int64
+}
+
+// AddOperator registers a reader which is estimated to read estRows rows.
+func (p *QueryProgress) AddOperator(estRows int64) {
+	atomic.AddInt64(&p.operators, 1)
+	atomic.AddInt64(&p.estimatedRows, estRows)
+}
+
+// FinishOperator marks a reader as having read all its rows.
+func (p *QueryProgress) FinishOperator() {
+	atomic.AddInt64(&p.finishedOperators, 1)
+}
+
+// AddScannedRows adds the rows read by a reader.
+func (p *QueryProgress) AddScannedRows(rows int64) {
+	atomic.AddInt64(&p.scannedRows, rows)
+}
+
+// EstimatedRows returns the estimated row count of all the registered readers.
+func (p *QueryProgress) EstimatedRows() int64 {
+	return atomic.LoadInt64(&p.estimatedRows)
+}
+
+// ScannedRows returns the rows read so far.
+func (p *QueryProgress) ScannedRows() int64 {
+	return atomic.LoadInt64(&p.scannedRows)
+}
+
+// Operators returns the number of the registered readers.
+func (p *QueryProgress) Operators() int64 {
+	return atomic.LoadInt64(&p.operators)
+}
+
+// FinishedOperators returns the number of the readers which have read all their rows.
+func (p *QueryProgress) FinishedOperators() int64 {
+	return atomic.LoadInt64(&p.finishedOperators)
+}
+
+// Percent returns the estimated percentage of the rows scanned. The estimation may be lower than the actual row count,
+// so it's capped at 99 until all the readers are finished.
+func (p *QueryProgress) Percent() float64 {
+	operators, finished := p.Operators(), p.FinishedOperators()
+	if operators > 0 && finished >= operators {
+		return 100
+	}
+	estRows := p.EstimatedRows()
+	if estRows <= 0 {
+		return 0
+	}
+	return math.Min(float64(p.ScannedRows())*100/float64(estRows), 99)
+}
+
+func (p *QueryProgress) reset() {
+	atomic.StoreInt64(&p.estimatedRows, 0)
+	atomic.StoreInt64(&p.scannedRows, 0)
+	atomic.StoreInt64(&p.operators, 0)
+	atomic.StoreInt64(&p.finishedOperators, 0)
 }
 
 // MostRestrictStateContext gets a most restrict StatementContext.
@@ -16,6 +16,7 @@ package variable_test
 import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/sqldigest"
 )
 
 var _ = Suite(&testSessionSuite{})
@@ -50,3 +51,42 @@ func (*testSessionSuite) TestSession(c *C) {
 	c.Assert(ss.FoundRows(), Equals, uint64(0))
 	c.Assert(ss.WarningCount(), Equals, uint16(0))
 }
+
+func (*testSessionSuite) TestQueryProgress(c *C) {
+	ctx := mock.NewContext()
+	p := &ctx.GetSessionVars().StmtCtx.Progress
+	c.Assert(p.Percent(), Equals, float64(0))
+
+	p.AddOperator(100)
+	p.AddOperator(300)
+	c.Assert(p.EstimatedRows(), Equals, int64(400))
+	c.Assert(p.Operators(), Equals, int64(2))
+	p.AddScannedRows(100)
+	c.Assert(p.Percent(), Equals, float64(25))
+
+	// The estimation is lower than the actual row count.
+	p.AddScannedRows(400)
+	p.FinishOperator()
+	c.Assert(p.FinishedOperators(), Equals, int64(1))
+	c.Assert(p.Percent(), Equals, float64(99))
+	p.FinishOperator()
+	c.Assert(p.Percent(), Equals, float64(100))
+
+	ctx.GetSessionVars().StmtCtx.ResetForRetry()
+	c.Assert(p.ScannedRows(), Equals, int64(0))
+	c.Assert(p.Operators(), Equals, int64(0))
+}
+
+func (*testSessionSuite) TestSQLDigest(c *C) {
+	ctx := mock.NewContext()
+	sc := ctx.GetSessionVars().StmtCtx
+	sc.OriginalSQL = "select * from t where a = 1"
+	_, digest := sqldigest.NormalizeDigest(sc.OriginalSQL)
+	c.Assert(sc.SQLDigest(), Equals, digest)
+	c.Assert(sc.SQLDigest(), Equals, digest)
+
+	// The digest is computed again if the statement text is changed.
+	sc.OriginalSQL = "select * from t where b = 1"
+	_, digest = sqldigest.NormalizeDigest(sc.OriginalSQL)
+	c.Assert(sc.SQLDigest(), Equals, digest)
+}
@@ -48,6 +48,21 @@ type SysVar struct {
 // SysVars is global sys vars map.
 var SysVars map[string]*SysVar
 
+// sysVarAliases maps the names MySQL 8 gives to some variables to the old names, an alias shares the value of the
+// variable it stands for.
+var sysVarAliases = map[string]string{
+	TransactionIsolation: TxnIsolation,
+	TransactionReadOnly:  TxReadOnly,
+}
+
+// ResolveSysVarAlias returns the name of the variable which holds the value of the lower case variable name.
+func ResolveSysVarAlias(name string) string {
+	if alias, ok := sysVarAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
 // GetSysVar returns sys var info for name as key.
 func GetSysVar(name string) *SysVar {
 	name = strings.ToLower(name)
@@ -56,20 +71,31 @@ func GetSysVar(name string) *SysVar {
 
 // Variable error codes.
 const (
-	CodeUnknownStatusVar terror.ErrCode = 1
-	CodeUnknownSystemVar terror.ErrCode = 1193
-	CodeIncorrectScope   terror.ErrCode = 1238
-	CodeUnknownTimeZone  terror.ErrCode = 1298
-	CodeReadOnly         terror.ErrCode = 1621
+	CodeUnknownStatusVar         terror.ErrCode = 1
+	CodeUnknownCharacterSet      terror.ErrCode = 1115
+	CodeUnknownSystemVar         terror.ErrCode = 1193
+	CodeWrongValueForVar         terror.ErrCode = 1231
+	CodeIncorrectScope           terror.ErrCode = 1238
+	CodeCollationCharsetMismatch terror.ErrCode = 1253
+	CodeUnknownCollation         terror.ErrCode = 1273
+	CodeUnknownTimeZone          terror.ErrCode = 1298
+	CodeReadOnly                 terror.ErrCode = 1621
+	CodeUnknownLocale            terror.ErrCode = 1649
 )
 
 // Variable errors
 var (
-	UnknownStatusVar   = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
-	UnknownSystemVar   = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
-	ErrIncorrectScope  = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
-	ErrUnknownTimeZone = terror.ClassVariable.New(CodeUnknownTimeZone, "unknown or incorrect time zone: %s")
-	ErrReadOnly        = terror.ClassVariable.New(CodeReadOnly, "variable is read only")
+	UnknownStatusVar    = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
+	UnknownSystemVar    = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
+	ErrIncorrectScope   = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
+	ErrUnknownTimeZone  = terror.ClassVariable.New(CodeUnknownTimeZone, "unknown or incorrect time zone: %s")
+	ErrReadOnly         = terror.ClassVariable.New(CodeReadOnly, "variable is read only")
+	ErrWrongValueForVar = terror.ClassVariable.New(CodeWrongValueForVar, "Variable '%s' can't be set to the value of '%s'")
+	ErrUnknownLocale    = terror.ClassVariable.New(CodeUnknownLocale, mysql.MySQLErrName[mysql.ErrUnknownLocale])
+
+	ErrUnknownCharacterSet      = terror.ClassVariable.New(CodeUnknownCharacterSet, mysql.MySQLErrName[mysql.ErrUnknownCharacterSet])
+	ErrUnknownCollation         = terror.ClassVariable.New(CodeUnknownCollation, mysql.MySQLErrName[mysql.ErrUnknownCollation])
+	ErrCollationCharsetMismatch = terror.ClassVariable.New(CodeCollationCharsetMismatch, mysql.MySQLErrName[mysql.ErrCollationCharsetMismatch])
 )
 
 func init() {
@@ -84,6 +110,12 @@ func init() {
 		CodeIncorrectScope:   mysql.ErrIncorrectGlobalLocalVar,
 		CodeUnknownTimeZone:  mysql.ErrUnknownTimeZone,
 		CodeReadOnly:         mysql.ErrVariableIsReadonly,
+		CodeWrongValueForVar: mysql.ErrWrongValueForVar,
+		CodeUnknownLocale:    mysql.ErrUnknownLocale,
+
+		CodeUnknownCharacterSet:      mysql.ErrUnknownCharacterSet,
+		CodeUnknownCollation:         mysql.ErrUnknownCollation,
+		CodeCollationCharsetMismatch: mysql.ErrCollationCharsetMismatch,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassVariable] = mySQLErrCodes
 }
@@ -236,7 +268,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "block_encryption_mode", "aes-128-ecb"},
 	{ScopeGlobal | ScopeSession, "max_length_for_sort_data", "1024"},
 	{ScopeNone, "character_set_system", "utf8"},
-	{ScopeGlobal | ScopeSession, "interactive_timeout", "28800"},
+	{ScopeGlobal | ScopeSession, InteractiveTimeout, "28800"},
 	{ScopeGlobal, "innodb_optimize_fulltext_only", "OFF"},
 	{ScopeNone, "character_sets_dir", "/usr/local/mysql-5.6.25-osx10.8-x86_64/share/charsets/"},
 	{ScopeGlobal | ScopeSession, "query_cache_type", "OFF"},
@@ -304,7 +336,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "innodb_purge_batch_size", "300"},
 	{ScopeNone, "have_profiling", "YES"},
 	{ScopeGlobal, "slave_checkpoint_group", "512"},
-	{ScopeGlobal | ScopeSession, "character_set_client", "latin1"},
+	{ScopeGlobal | ScopeSession, CharacterSetClient, "latin1"},
 	{ScopeNone, "slave_load_tmpdir", "/var/tmp/"},
 	{ScopeGlobal, "innodb_buffer_pool_dump_now", "OFF"},
 	{ScopeGlobal, "relay_log_purge", "ON"},
@@ -388,9 +420,11 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "optimizer_trace", "enabled=off,one_line=off"},
 	{ScopeGlobal | ScopeSession, "read_rnd_buffer_size", "262144"},
 	{ScopeNone, "version_comment", "MySQL Community Server (Apache License 2.0)"},
-	{ScopeGlobal | ScopeSession, "net_write_timeout", "60"},
+	{ScopeGlobal | ScopeSession, NetWriteTimeout, "60"},
 	{ScopeGlobal, "innodb_buffer_pool_load_abort", "OFF"},
-	{ScopeGlobal | ScopeSession, "tx_isolation", "REPEATABLE-READ"},
+	{ScopeGlobal | ScopeSession, TxnIsolation, "REPEATABLE-READ"},
+	{ScopeSession, TxnIsolationOneShot, ""},
+	{ScopeGlobal | ScopeSession, TransactionIsolation, "REPEATABLE-READ"},
 	{ScopeGlobal | ScopeSession, "collation_connection", "latin1_swedish_ci"},
 	{ScopeGlobal, "rpl_semi_sync_master_timeout", ""},
 	{ScopeGlobal | ScopeSession, "transaction_prealloc_size", "4096"},
@@ -493,7 +527,8 @@ var defaultSysVars = []*SysVar{
 	{ScopeNone, "explicit_defaults_for_timestamp", "OFF"},
 	{ScopeNone, "performance_schema_events_waits_history_size", "10"},
 	{ScopeGlobal, "log_syslog_tag", ""},
-	{ScopeGlobal | ScopeSession, "tx_read_only", "0"},
+	{ScopeGlobal | ScopeSession, TxReadOnly, "0"},
+	{ScopeGlobal | ScopeSession, TransactionReadOnly, "0"},
 	{ScopeGlobal, "rpl_semi_sync_master_wait_point", ""},
 	{ScopeGlobal, "innodb_undo_log_truncate", ""},
 	{ScopeNone, "simplified_binlog_gtid_recovery", "OFF"},
@@ -520,7 +555,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "innodb_buffer_pool_size", "134217728"},
 	{ScopeGlobal, "innodb_adaptive_flushing", "ON"},
 	{ScopeNone, "datadir", "/usr/local/mysql/data/"},
-	{ScopeGlobal | ScopeSession, "wait_timeout", "28800"},
+	{ScopeGlobal | ScopeSession, WaitTimeout, "28800"},
 	{ScopeGlobal, "innodb_monitor_enable", ""},
 	{ScopeNone, "date_format", "%Y-%m-%d"},
 	{ScopeGlobal, "innodb_buffer_pool_filename", "ib_buffer_pool"},
@@ -564,7 +599,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal, "flush", "OFF"},
 	{ScopeGlobal | ScopeSession, "eq_range_index_dive_limit", "10"},
 	{ScopeNone, "performance_schema_events_stages_history_size", "10"},
-	{ScopeGlobal | ScopeSession, "character_set_connection", "latin1"},
+	{ScopeGlobal | ScopeSession, CharacterSetConnection, "latin1"},
 	{ScopeGlobal, "myisam_use_mmap", "OFF"},
 	{ScopeGlobal | ScopeSession, "ndb_join_pushdown", ""},
 	{ScopeGlobal | ScopeSession, "character_set_server", "latin1"},
@@ -572,7 +607,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeNone, "performance_schema_max_thread_instances", "402"},
 	{ScopeGlobal, "slave_rows_search_algorithms", "TABLE_SCAN,INDEX_SCAN"},
 	{ScopeGlobal | ScopeSession, "ndbinfo_show_hidden", ""},
-	{ScopeGlobal | ScopeSession, "net_read_timeout", "30"},
+	{ScopeGlobal | ScopeSession, NetReadTimeout, "30"},
 	{ScopeNone, "innodb_page_size", "16384"},
 	{ScopeGlobal, MaxAllowedPacket, "67108864"},
 	{ScopeNone, "innodb_log_file_size", "50331648"},
@@ -601,11 +636,23 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "min_examined_row_limit", "0"},
 	{ScopeGlobal, "sync_frm", "ON"},
 	{ScopeGlobal, "innodb_online_alter_log_max_size", "134217728"},
+	/* MySQL 8 variables queried by the clients */
+	{ScopeGlobal | ScopeSession, "sql_require_primary_key", "OFF"},
+	{ScopeGlobal, "default_authentication_plugin", "mysql_native_password"},
+	{ScopeGlobal, "require_secure_transport", "OFF"},
+	{ScopeGlobal | ScopeSession, "information_schema_stats_expiry", "86400"},
+	{ScopeGlobal | ScopeSession, "default_collation_for_utf8mb4", "utf8mb4_bin"},
+	{ScopeGlobal, "activate_all_roles_on_login", "OFF"},
+	{ScopeGlobal | ScopeSession, "resultset_metadata", "FULL"},
+	{ScopeGlobal | ScopeSession, "windowing_use_high_precision", "ON"},
+	{ScopeGlobal | ScopeSession, "cte_max_recursion_depth", "1000"},
 	/* TiDB specific variables */
 	{ScopeSession, TiDBSnapshot, ""},
 	{ScopeSession, TiDBSkipConstraintCheck, "0"},
 	{ScopeSession, TiDBOptAggPushDown, boolToIntStr(DefOptAggPushDown)},
 	{ScopeSession, TiDBOptInSubqUnFolding, boolToIntStr(DefOptInSubqUnfolding)},
+	{ScopeSession, TiDBEnableCascadesPlanner, boolToIntStr(DefEnableCascadesPlanner)},
+	{ScopeSession, TiDBOptLateMaterialization, boolToIntStr(DefOptLateMaterialization)},
 	{ScopeSession, TiDBBuildStatsConcurrency, strconv.Itoa(DefBuildStatsConcurrency)},
 	{ScopeGlobal | ScopeSession, TiDBDistSQLScanConcurrency, strconv.Itoa(DefDistSQLScanConcurrency)},
 	{ScopeGlobal | ScopeSession, TiDBIndexJoinBatchSize, strconv.Itoa(DefIndexJoinBatchSize)},
@@ -618,12 +665,22 @@ var defaultSysVars = []*SysVar{
 	{ScopeSession, TiDBBatchInsert, boolToIntStr(DefBatchInsert)},
 	{ScopeSession, TiDBBatchDelete, boolToIntStr(DefBatchDelete)},
 	{ScopeSession, TiDBCurrentTS, strconv.Itoa(DefCurretTS)},
+	{ScopeSession, TiDBTraceOptimizer, "0"},
+	{ScopeSession, TiDBLastOptimizerTrace, ""},
+	{ScopeSession, TiDBRetryLimit, strconv.Itoa(DefRetryLimit)},
+	{ScopeSession, TiDBAutoConvertLongString, boolToIntStr(DefAutoConvertLongString)},
+	{ScopeGlobal | ScopeSession, TiDBRowFormatVersion, strconv.Itoa(DefRowFormatVersion)},
+	{ScopeSession, TiDBHashDistinctSpillSize, strconv.Itoa(DefHashDistinctSpillSize)},
+	{ScopeSession, TiDBPipelinedPrewrite, boolToIntStr(DefPipelinedPrewrite)},
+	{ScopeGlobal | ScopeSession, TiDBIdleTransactionTimeout, strconv.Itoa(DefIdleTransactionTimeout)},
+	{ScopeGlobal, TiDBDDLReorgWorkerCount, strconv.Itoa(DefDDLReorgWorkerCount)},
+	{ScopeGlobal, TiDBDDLReorgBatchSize, strconv.Itoa(DefDDLReorgBatchSize)},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
 var SetNamesVariables = []string{
-	"character_set_client",
-	"character_set_connection",
+	CharacterSetClient,
+	CharacterSetConnection,
 	"character_set_results",
 }
 
@@ -44,6 +44,14 @@ const (
 	// tidb_opt_insubquery_unfold is used to enable/disable the optimizer rule of in subquery unfold.
 	TiDBOptInSubqUnFolding = "tidb_opt_insubquery_unfold"
 
+	// tidb_enable_cascades_planner is used to enable/disable the cascades optimizer, which searches the physical plan
+	// by the transformation and implementation rules. It's experimental.
+	TiDBEnableCascadesPlanner = "tidb_enable_cascades_planner"
+
+	// tidb_opt_late_materialization is used to enable/disable the late materialization of the table scans, which
+	// reads only the columns of the filter first and the other columns of the rows that pass the filter later.
+	TiDBOptLateMaterialization = "tidb_opt_late_materialization"
+
 	// tidb_build_stats_concurrency is used to speed up the ANALYZE statement, when a table has multiple indices,
 	// those indices can be scanned concurrently, with the cost of higher system performance impact.
 	TiDBBuildStatsConcurrency = "tidb_build_stats_concurrency"
@@ -52,6 +60,14 @@ const (
 	// It is read-only.
 	TiDBCurrentTS = "tidb_current_ts"
 
+	// tidb_trace_optimizer is used to trace the optimization of the next statement, the applied rules, the considered
+	// candidates and their costs are recorded.
+	TiDBTraceOptimizer = "tidb_trace_optimizer"
+
+	// TiDBLastOptimizerTrace is used to get the optimizer trace of the last traced statement as JSON.
+	// It is read-only.
+	TiDBLastOptimizerTrace = "tidb_last_optimizer_trace"
+
 	/* Session and global */
 
 	// tidb_distsql_scan_concurrency is used to set the concurrency of a distsql scan task.
@@ -96,6 +112,11 @@ const (
 	// split data into multiple batches and use a single txn for each batch. This will be helpful when deleting large data.
 	TiDBBatchDelete = "tidb_batch_delete"
 
+	// tidb_retry_limit is the maximum number of times a transaction is retried, when its commit fails with a
+	// retryable error like a write conflict. The statements of the transaction are replayed for a retry,
+	// 0 disables the retry. The default value is set by the retry-limit flag of tidb-server.
+	TiDBRetryLimit = "tidb_retry_limit"
+
 	// tidb_max_row_count_for_inlj is used when do index nested loop join.
 	// It controls the max row count of outer table when do index nested loop join without hint.
 	// After the row count of the inner table is accurate, this variable will be removed.
@@ -103,6 +124,44 @@ const (
 
 	// tidb_cbo uses new planner with cost based optimizer.
 	TiDBCBO = "tidb_cbo"
+
+	// tidb_auto_convert_long_string converts a CHAR or VARCHAR column whose length is too long to the smallest
+	// TEXT or BLOB type that can store it, with a warning instead of an error, even in strict SQL mode.
+	TiDBAutoConvertLongString = "tidb_auto_convert_long_string"
+
+	// tidb_row_format_version is the format version of the rows written by the session, 1 or 2.
+	// The version 2 rows have a directory of the columns, so a few columns of a wide row are decoded faster.
+	// It's 1 by default, so the TiDB servers of the old version can read the rows during the rolling upgrade.
+	// The version 1 rows are still written if it's 2 but the coprocessor of the store can't decode the version 2
+	// rows, TiKV can't decode them now.
+	TiDBRowFormatVersion = "tidb_row_format_version"
+
+	// tidb_hash_distinct_spill_size is the number of the distinct keys a hash distinct executor keeps in memory.
+	// When it's reached, the rows of the new keys are spilled to temporary files and deduplicated after the input
+	// is drained, so a DISTINCT on a large number of keys doesn't run out of memory.
+	TiDBHashDistinctSpillSize = "tidb_hash_distinct_spill_size"
+
+	// tidb_pipelined_prewrite prewrites the mutations of the executed statements of an explicit transaction in
+	// the background while the next statements run, so the commit of a large transaction is faster. It only applies
+	// to the REPEATABLE READ transactions. A row written again after it's prewritten makes the commit fail, the
+	// transaction isn't retried, and the rows are locked longer.
+	TiDBPipelinedPrewrite = "tidb_pipelined_prewrite"
+
+	// tidb_idle_transaction_timeout is the number of seconds a transaction can be idle between its statements,
+	// 0 means no limit. The transaction is rolled back when it's reached, so it doesn't hold its locks or block
+	// the GC with its old snapshot any more, and the next statement of the connection fails with an error.
+	TiDBIdleTransactionTimeout = "tidb_idle_transaction_timeout"
+
+	/* Global only */
+
+	// tidb_ddl_reorg_worker_cnt is the number of the concurrent tasks that backfill an index in a round.
+	// Each task backfills a range of rows in its own transaction, the DDL owner reloads this value before each round,
+	// so it can be changed while an index is being added.
+	TiDBDDLReorgWorkerCount = "tidb_ddl_reorg_worker_cnt"
+
+	// tidb_ddl_reorg_batch_size is the number of rows that a backfill task handles in a transaction.
+	// Large value backfills faster but the transactions are bigger and more likely to conflict with the user's writes.
+	TiDBDDLReorgBatchSize = "tidb_ddl_reorg_batch_size"
 )
 
 // Default TiDB system variable values.
@@ -117,7 +176,24 @@ const (
 	DefSkipUTF8Check              = false
 	DefOptAggPushDown             = true
 	DefOptInSubqUnfolding         = false
+	DefEnableCascadesPlanner      = false
+	DefOptLateMaterialization     = false
 	DefBatchInsert                = false
 	DefBatchDelete                = false
+	DefAutoConvertLongString      = false
 	DefCurretTS                   = 0
+	DefRetryLimit                 = 10
+	DefRowFormatVersion           = 1
+	DefHashDistinctSpillSize      = 1000000
+	DefPipelinedPrewrite          = false
+	DefIdleTransactionTimeout     = 0
+	DefDDLReorgWorkerCount        = 16
+	DefDDLReorgBatchSize          = 128
+)
+
+// The limits of the DDL reorganization variables.
+const (
+	MaxDDLReorgWorkerCount = 128
+	MinDDLReorgBatchSize   = 32
+	MaxDDLReorgBatchSize   = 10240
 )
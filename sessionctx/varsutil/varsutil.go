@@ -15,13 +15,16 @@ package varsutil
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -29,7 +32,7 @@ import (
 // If it is a session only variable, use the default value defined in code.
 // Returns error if there is no such variable.
 func GetSessionSystemVar(s *variable.SessionVars, key string) (string, error) {
-	key = strings.ToLower(key)
+	key = variable.ResolveSysVarAlias(strings.ToLower(key))
 	sysVar := variable.SysVars[key]
 	if sysVar == nil {
 		return "", variable.UnknownSystemVar.GenByArgs(key)
@@ -38,6 +41,13 @@ func GetSessionSystemVar(s *variable.SessionVars, key string) (string, error) {
 	switch sysVar.Name {
 	case variable.TiDBCurrentTS:
 		return fmt.Sprintf("%d", s.TxnCtx.StartTS), nil
+	case variable.TiDBTraceOptimizer:
+		if s.TraceOptimizer {
+			return "1", nil
+		}
+		return "0", nil
+	case variable.TiDBLastOptimizerTrace:
+		return s.LastOptimizerTrace, nil
 	}
 
 	sVal, ok := s.Systems[key]
@@ -58,7 +68,7 @@ func GetSessionSystemVar(s *variable.SessionVars, key string) (string, error) {
 
 // GetGlobalSystemVar gets a global system variable.
 func GetGlobalSystemVar(s *variable.SessionVars, key string) (string, error) {
-	key = strings.ToLower(key)
+	key = variable.ResolveSysVarAlias(strings.ToLower(key))
 	sysVar := variable.SysVars[key]
 	if sysVar == nil {
 		return "", variable.UnknownSystemVar.GenByArgs(key)
@@ -76,7 +86,7 @@ const epochShiftBits = 18
 
 // SetSessionSystemVar sets system variable and updates SessionVars states.
 func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Datum) error {
-	name = strings.ToLower(name)
+	name = variable.ResolveSysVarAlias(strings.ToLower(name))
 	sysVar := variable.SysVars[name]
 	if sysVar == nil {
 		return variable.UnknownSystemVar
@@ -92,6 +102,10 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 	if err != nil {
 		return errors.Trace(err)
 	}
+	sVal, err = ValidateSetSystemVar(name, sVal)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	switch name {
 	case variable.TimeZone:
 		vars.TimeZone, err = parseTimeZone(sVal)
@@ -132,6 +146,10 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.AllowAggPushDown = tidbOptOn(sVal)
 	case variable.TiDBOptInSubqUnFolding:
 		vars.AllowInSubqueryUnFolding = tidbOptOn(sVal)
+	case variable.TiDBEnableCascadesPlanner:
+		vars.EnableCascadesPlanner = tidbOptOn(sVal)
+	case variable.TiDBOptLateMaterialization:
+		vars.AllowLateMaterialization = tidbOptOn(sVal)
 	case variable.TiDBIndexLookupConcurrency:
 		vars.IndexLookupConcurrency = tidbOptPositiveInt(sVal, variable.DefIndexLookupConcurrency)
 	case variable.TiDBIndexJoinBatchSize:
@@ -150,13 +168,144 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.MaxRowCountForINLJ = tidbOptPositiveInt(sVal, variable.DefMaxRowCountForINLJ)
 	case variable.TiDBCBO:
 		vars.CBO = tidbOptOn(sVal)
-	case variable.TiDBCurrentTS:
+	case variable.TiDBCurrentTS, variable.TiDBLastOptimizerTrace:
 		return variable.ErrReadOnly
+	case variable.TiDBTraceOptimizer:
+		vars.TraceOptimizer = tidbOptOn(sVal)
+	case variable.TiDBRetryLimit:
+		vars.RetryLimit = tidbOptNonNegativeInt(sVal, variable.DefRetryLimit)
+	case variable.TiDBAutoConvertLongString:
+		vars.AutoConvertLongString = tidbOptOn(sVal)
+	case variable.TiDBRowFormatVersion:
+		vars.RowFormatVersion = tidbOptPositiveInt(sVal, variable.DefRowFormatVersion)
+	case variable.TiDBHashDistinctSpillSize:
+		vars.HashDistinctSpillSize = tidbOptPositiveInt(sVal, variable.DefHashDistinctSpillSize)
+	case variable.TiDBPipelinedPrewrite:
+		vars.PipelinedPrewrite = tidbOptOn(sVal)
+	case variable.TiDBIdleTransactionTimeout:
+		vars.IdleTransactionTimeout = tidbOptNonNegativeInt(sVal, variable.DefIdleTransactionTimeout)
+	case variable.WaitTimeout:
+		vars.WaitTimeout = tidbOptPositiveInt(sVal, variable.DefWaitTimeout)
+	case variable.NetReadTimeout:
+		vars.NetReadTimeout = tidbOptPositiveInt(sVal, variable.DefNetReadTimeout)
+	case variable.NetWriteTimeout:
+		vars.NetWriteTimeout = tidbOptPositiveInt(sVal, variable.DefNetWriteTimeout)
+	case variable.MaxAllowedPacket:
+		vars.MaxAllowedPacket = tidbOptPositiveInt(sVal, variable.DefMaxAllowedPacket)
+	case variable.TxnIsolationOneShot:
+		// It isn't kept in the system variables, the next transaction takes it.
+		vars.TxnIsolationOneShot = sVal
+		return nil
 	}
 	vars.Systems[name] = sVal
 	return nil
 }
 
+// SetSessionSystemVars sets the session scope system variables in the map atomically, none of them is set if
+// any of them can't be set.
+func SetSessionSystemVars(vars *variable.SessionVars, values map[string]string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	// Set them in order, so the error is the same for the same variables.
+	sort.Strings(names)
+	for _, name := range names {
+		sysVar := variable.SysVars[variable.ResolveSysVarAlias(strings.ToLower(name))]
+		if sysVar == nil {
+			return variable.UnknownSystemVar.GenByArgs(name)
+		}
+		if sysVar.Scope == variable.ScopeNone {
+			return errors.Errorf("Variable '%s' is a read only variable", name)
+		}
+		if sysVar.Scope&variable.ScopeSession == 0 {
+			return errors.Errorf("Variable '%s' is a GLOBAL variable and should be set with SET GLOBAL", name)
+		}
+	}
+	// Try them on new session variables first, setting a variable only fails on its own value.
+	tryVars := variable.NewSessionVars()
+	for _, name := range names {
+		if err := SetSessionSystemVar(tryVars, name, types.NewStringDatum(values[name])); err != nil {
+			return errors.Annotatef(err, "set variable %s", name)
+		}
+	}
+	for _, name := range names {
+		if err := SetSessionSystemVar(vars, name, types.NewStringDatum(values[name])); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// ValidateSetSystemVar checks the value to set to a system variable, and returns the value to store.
+func ValidateSetSystemVar(name string, value string) (string, error) {
+	switch variable.ResolveSysVarAlias(name) {
+	case variable.TxnIsolation, variable.TxnIsolationOneShot:
+		// READ-COMMITTED reads the data committed before each statement, the others are snapshot isolation.
+		upVal := strings.ToUpper(value)
+		switch upVal {
+		case ast.ReadCommitted, ast.ReadUncommitted, ast.RepeatableRead, ast.Serializable:
+			return upVal, nil
+		}
+		return value, variable.ErrWrongValueForVar.GenByArgs(name, value)
+	case variable.TiDBDDLReorgWorkerCount:
+		return checkIntRange(name, value, 1, variable.MaxDDLReorgWorkerCount)
+	case variable.TiDBDDLReorgBatchSize:
+		return checkIntRange(name, value, variable.MinDDLReorgBatchSize, variable.MaxDDLReorgBatchSize)
+	case variable.TiDBRowFormatVersion:
+		return checkIntRange(name, value, 1, 2)
+	case variable.MaxAllowedPacket:
+		if _, err := checkIntRange(name, value, variable.MinMaxAllowedPacket, variable.MaxMaxAllowedPacket); err != nil {
+			return value, errors.Trace(err)
+		}
+		// Like MySQL, the value is rounded down to a multiple of 1024.
+		val, _ := strconv.Atoi(value)
+		return strconv.Itoa(val / 1024 * 1024), nil
+	case variable.BlockEncryptionMode:
+		// The mode is aes-keylen-mode, the keylen is 128, 192 or 256, and the mode is ecb, cbc, cfb1, cfb8, cfb128 or ofb.
+		lowVal := strings.ToLower(value)
+		parts := strings.Split(lowVal, "-")
+		if len(parts) == 3 && parts[0] == "aes" {
+			switch parts[1] {
+			case "128", "192", "256":
+				switch parts[2] {
+				case "ecb", "cbc", "cfb1", "cfb8", "cfb128", "ofb":
+					return lowVal, nil
+				}
+			}
+		}
+		return value, variable.ErrWrongValueForVar.GenByArgs(name, value)
+	case variable.CharacterSetClient, variable.CharacterSetConnection, variable.CharacterSetResults:
+		cs, _, err := charset.GetCharsetInfo(value)
+		if err != nil {
+			return value, variable.ErrUnknownCharacterSet.GenByArgs(value)
+		}
+		return cs, nil
+	case variable.CollationConnection:
+		co, err := charset.GetCollationByName(value)
+		if err != nil {
+			return value, variable.ErrUnknownCollation.GenByArgs(value)
+		}
+		return co.Name, nil
+	case variable.LcTimeNames:
+		loc := mysql.GetLocale(value)
+		if loc == nil {
+			return value, variable.ErrUnknownLocale.GenByArgs(value)
+		}
+		return loc.Name, nil
+	}
+	return value, nil
+}
+
+// checkIntRange checks that the value is an integer in the range [min, max].
+func checkIntRange(name string, value string, min, max int) (string, error) {
+	val, err := strconv.Atoi(value)
+	if err != nil || val < min || val > max {
+		return value, variable.ErrWrongValueForVar.GenByArgs(name, value)
+	}
+	return value, nil
+}
+
 // tidbOptOn could be used for all tidb session variable options, we use "ON"/1 to turn on those options.
 func tidbOptOn(opt string) bool {
 	return strings.EqualFold(opt, "ON") || opt == "1"
@@ -170,6 +319,14 @@ func tidbOptPositiveInt(opt string, defaultVal int) int {
 	return val
 }
 
+func tidbOptNonNegativeInt(opt string, defaultVal int) int {
+	val, err := strconv.Atoi(opt)
+	if err != nil || val < 0 {
+		return defaultVal
+	}
+	return val
+}
+
 func parseTimeZone(s string) (*time.Location, error) {
 	if s == "SYSTEM" {
 		// TODO: Support global time_zone variable, it should be set to global time_zone value.
@@ -156,6 +156,154 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	c.Assert(v.MaxRowCountForINLJ, Equals, 128)
 	SetSessionSystemVar(v, variable.TiDBMaxRowCountForINLJ, types.NewStringDatum("127"))
 	c.Assert(v.MaxRowCountForINLJ, Equals, 127)
+
+	// Test case for the connection timeouts.
+	c.Assert(v.WaitTimeout, Equals, variable.DefWaitTimeout)
+	SetSessionSystemVar(v, variable.WaitTimeout, types.NewStringDatum("10"))
+	c.Assert(v.WaitTimeout, Equals, 10)
+	SetSessionSystemVar(v, variable.NetReadTimeout, types.NewStringDatum("0"))
+	c.Assert(v.NetReadTimeout, Equals, variable.DefNetReadTimeout)
+	SetSessionSystemVar(v, variable.NetWriteTimeout, types.NewStringDatum("5"))
+	c.Assert(v.NetWriteTimeout, Equals, 5)
+
+	// Test case for tidb_row_format_version.
+	c.Assert(v.RowFormatVersion, Equals, variable.DefRowFormatVersion)
+	err = SetSessionSystemVar(v, variable.TiDBRowFormatVersion, types.NewStringDatum("2"))
+	c.Assert(err, IsNil)
+	c.Assert(v.RowFormatVersion, Equals, 2)
+	err = SetSessionSystemVar(v, variable.TiDBRowFormatVersion, types.NewStringDatum("3"))
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	c.Assert(v.RowFormatVersion, Equals, 2)
+
+	// Test case for tidb_hash_distinct_spill_size.
+	c.Assert(v.HashDistinctSpillSize, Equals, variable.DefHashDistinctSpillSize)
+	SetSessionSystemVar(v, variable.TiDBHashDistinctSpillSize, types.NewStringDatum("100"))
+	c.Assert(v.HashDistinctSpillSize, Equals, 100)
+	SetSessionSystemVar(v, variable.TiDBHashDistinctSpillSize, types.NewStringDatum("0"))
+	c.Assert(v.HashDistinctSpillSize, Equals, variable.DefHashDistinctSpillSize)
+
+	// Test case for tidb_idle_transaction_timeout.
+	c.Assert(v.IdleTransactionTimeout, Equals, 0)
+	SetSessionSystemVar(v, variable.TiDBIdleTransactionTimeout, types.NewStringDatum("60"))
+	c.Assert(v.IdleTransactionTimeout, Equals, 60)
+	SetSessionSystemVar(v, variable.TiDBIdleTransactionTimeout, types.NewStringDatum("0"))
+	c.Assert(v.IdleTransactionTimeout, Equals, 0)
+
+	// Test case for max_allowed_packet.
+	c.Assert(v.MaxAllowedPacket, Equals, variable.DefMaxAllowedPacket)
+	SetSessionSystemVar(v, variable.MaxAllowedPacket, types.NewStringDatum("4096"))
+	c.Assert(v.MaxAllowedPacket, Equals, 4096)
+
+	// Test case for tidb_opt_late_materialization.
+	c.Assert(v.AllowLateMaterialization, IsFalse)
+	SetSessionSystemVar(v, variable.TiDBOptLateMaterialization, types.NewStringDatum("1"))
+	c.Assert(v.AllowLateMaterialization, IsTrue)
+}
+
+func (s *testVarsutilSuite) TestValidateIntRangeVars(c *C) {
+	defer testleak.AfterTest(c)()
+	tbl := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{variable.TiDBDDLReorgWorkerCount, "1", true},
+		{variable.TiDBDDLReorgWorkerCount, "128", true},
+		{variable.TiDBDDLReorgWorkerCount, "0", false},
+		{variable.TiDBDDLReorgWorkerCount, "129", false},
+		{variable.TiDBDDLReorgWorkerCount, "a", false},
+		{variable.TiDBDDLReorgBatchSize, "32", true},
+		{variable.TiDBDDLReorgBatchSize, "10240", true},
+		{variable.TiDBDDLReorgBatchSize, "31", false},
+		{variable.TiDBDDLReorgBatchSize, "10241", false},
+		{variable.TiDBRowFormatVersion, "1", true},
+		{variable.TiDBRowFormatVersion, "2", true},
+		{variable.TiDBRowFormatVersion, "0", false},
+		{variable.BlockEncryptionMode, "aes-256-cbc", true},
+		{variable.BlockEncryptionMode, "AES-192-CFB8", true},
+		{variable.BlockEncryptionMode, "aes-128-cfb16", false},
+		{variable.BlockEncryptionMode, "aes-512-ecb", false},
+		{variable.BlockEncryptionMode, "des-128-ecb", false},
+		{variable.MaxAllowedPacket, "1024", true},
+		{variable.MaxAllowedPacket, "1073741824", true},
+		{variable.MaxAllowedPacket, "1023", false},
+		{variable.MaxAllowedPacket, "1073741825", false},
+	}
+	for _, t := range tbl {
+		_, err := ValidateSetSystemVar(t.name, t.value)
+		if t.valid {
+			c.Assert(err, IsNil, Commentf("%s = %s", t.name, t.value))
+		} else {
+			c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("%s = %s", t.name, t.value))
+		}
+	}
+
+	// The locale name is case-insensitive.
+	val, err := ValidateSetSystemVar(variable.LcTimeNames, "de_de")
+	c.Assert(err, IsNil)
+	c.Assert(val, Equals, "de_DE")
+	_, err = ValidateSetSystemVar(variable.LcTimeNames, "xx_YY")
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownLocale), IsTrue)
+
+	// The charset and collation names are checked and normalized.
+	val, err = ValidateSetSystemVar(variable.CharacterSetResults, "LATIN1")
+	c.Assert(err, IsNil)
+	c.Assert(val, Equals, "latin1")
+	_, err = ValidateSetSystemVar(variable.CharacterSetClient, "utf16")
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCharacterSet), IsTrue)
+	val, err = ValidateSetSystemVar(variable.CollationConnection, "UTF8_General_CI")
+	c.Assert(err, IsNil)
+	c.Assert(val, Equals, "utf8_general_ci")
+	_, err = ValidateSetSystemVar(variable.CollationConnection, "utf8_invalid_ci")
+	c.Assert(terror.ErrorEqual(err, variable.ErrUnknownCollation), IsTrue)
+
+	// max_allowed_packet is rounded down to a multiple of 1024.
+	val, err = ValidateSetSystemVar(variable.MaxAllowedPacket, "2047")
+	c.Assert(err, IsNil)
+	c.Assert(val, Equals, "1024")
+}
+
+func (s *testVarsutilSuite) TestSetSessionSystemVars(c *C) {
+	defer testleak.AfterTest(c)()
+	v := variable.NewSessionVars()
+	err := SetSessionSystemVars(v, map[string]string{
+		"SQL_MODE":                    "ANSI_QUOTES,STRICT_TRANS_TABLES",
+		variable.TimeZone:             "+08:00",
+		variable.TransactionIsolation: "READ-COMMITTED",
+		variable.TiDBIndexLookupSize:  "100",
+		variable.CharacterSetResults:  "latin1",
+		variable.TiDBTraceOptimizer:   "1",
+	})
+	c.Assert(err, IsNil)
+	offset := func() int {
+		_, offset := time.Date(2000, 1, 1, 0, 0, 0, 0, v.TimeZone).Zone()
+		return offset
+	}
+	c.Assert(v.SQLMode, Equals, mysql.ModeANSIQuotes|mysql.ModeStrictTransTables)
+	c.Assert(v.StrictSQLMode, IsTrue)
+	c.Assert(offset(), Equals, 8*3600)
+	c.Assert(v.Systems[variable.TxnIsolation], Equals, "READ-COMMITTED")
+	c.Assert(v.IndexLookupSize, Equals, 100)
+	c.Assert(v.Systems[variable.CharacterSetResults], Equals, "latin1")
+	c.Assert(v.TraceOptimizer, IsTrue)
+
+	// None of the variables is set if any of them can't be set.
+	tbl := []map[string]string{
+		{variable.TimeZone: "+01:00", "no_such_var": "1"},
+		{variable.TimeZone: "+01:00", variable.TiDBCurrentTS: "1"},
+		{variable.TimeZone: "+01:00", "version": "1"},
+		{variable.TimeZone: "+01:00", variable.TiDBDDLReorgBatchSize: "1024"},
+		{variable.TimeZone: "+01:00", variable.MaxAllowedPacket: "1"},
+		{variable.TimeZone: "+01:00", variable.CharacterSetClient: "utf16"},
+		{variable.TimeZone: "+01:00", variable.TiDBSnapshot: "not a time"},
+		{variable.TimeZone: "x"},
+	}
+	for _, t := range tbl {
+		err = SetSessionSystemVars(v, t)
+		c.Assert(err, NotNil, Commentf("%v", t))
+		c.Assert(offset(), Equals, 8*3600)
+		c.Assert(v.Systems[variable.TimeZone], Equals, "+08:00")
+	}
 }
 
 type mockGlobalAccessor struct {
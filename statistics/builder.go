@@ -17,8 +17,9 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/sessionctx/variable"
-	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -108,9 +109,10 @@ func (b *SortedBuilder) Iterate(data types.Datum) error {
 	return nil
 }
 
-// BuildIndex builds histogram for index.
-func BuildIndex(ctx context.Context, numBuckets, id int64, records ast.RecordSet) (int64, *Histogram, error) {
-	b := NewSortedBuilder(ctx.GetSessionVars().StmtCtx, numBuckets, id)
+// BuildIndex builds histogram for index. The records are the values of the index in the order of the index,
+// and the buckets are the encoded values of the index columns, so they are in the same order.
+func BuildIndex(ctx context.Context, numBuckets int64, idxInfo *model.IndexInfo, records ast.RecordSet) (int64, *Histogram, error) {
+	b := NewSortedBuilder(ctx.GetSessionVars().StmtCtx, numBuckets, idxInfo.ID)
 	for {
 		row, err := records.Next()
 		if err != nil {
@@ -119,7 +121,7 @@ func BuildIndex(ctx context.Context, numBuckets, id int64, records ast.RecordSet
 		if row == nil {
 			break
 		}
-		bytes, err := codec.EncodeKey(nil, row.Data...)
+		bytes, err := tablecodec.EncodeIndexValues(nil, idxInfo.Columns, row.Data)
 		if err != nil {
 			return 0, nil, errors.Trace(err)
 		}
@@ -202,4 +204,6 @@ type AnalyzeResult struct {
 	Count   int64
 	IsIndex int
 	Err     error
+	// ExtendedStats are the statistics of the column groups built by analyzing the columns.
+	ExtendedStats []*ExtendedStats
 }
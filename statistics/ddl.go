@@ -98,6 +98,10 @@ func (h *Handle) DeleteTableStatsFromKV(id int64) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	_, err = exec.Execute(fmt.Sprintf("delete from mysql.stats_extended where table_id = %d", id))
+	if err != nil {
+		return errors.Trace(err)
+	}
 	_, err = exec.Execute("commit")
 	return errors.Trace(err)
 }
@@ -0,0 +1,237 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// JSONTable is the statistics of a table dumped by SHOW STATS_JSON, it can be loaded by LOAD STATS on another cluster to
+// reproduce the plans. The columns and indices are identified by name, because their IDs differ between clusters.
+type JSONTable struct {
+	DatabaseName  string                    `json:"database_name"`
+	TableName     string                    `json:"table_name"`
+	Columns       map[string]*jsonHistogram `json:"columns"`
+	Indices       map[string]*jsonHistogram `json:"indices"`
+	ExtendedStats []*jsonExtendedStats      `json:"extended_stats"`
+	Count         int64                     `json:"count"`
+	ModifyCount   int64                     `json:"modify_count"`
+	Version       uint64                    `json:"version"`
+}
+
+type jsonHistogram struct {
+	NDV               int64        `json:"ndv"`
+	NullCount         int64        `json:"null_count"`
+	LastUpdateVersion uint64       `json:"last_update_version"`
+	Buckets           []jsonBucket `json:"buckets"`
+}
+
+// jsonBucket is a bucket of the histogram, the bounds of the column buckets are converted to bytes in the same way as
+// they're saved to mysql.stats_buckets.
+type jsonBucket struct {
+	Count      int64  `json:"count"`
+	Repeats    int64  `json:"repeats"`
+	LowerBound []byte `json:"lower_bound"`
+	UpperBound []byte `json:"upper_bound"`
+}
+
+type jsonExtendedStats struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	NDV     int64    `json:"ndv"`
+}
+
+// DumpStatsToJSON dumps the cached statistics of the table.
+func (h *Handle) DumpStatsToJSON(sc *variable.StatementContext, dbName string, tableInfo *model.TableInfo) (*JSONTable, error) {
+	tbl := h.GetTableStats(tableInfo.ID)
+	jsonTbl := &JSONTable{
+		DatabaseName: dbName,
+		TableName:    tableInfo.Name.O,
+		Columns:      make(map[string]*jsonHistogram, len(tbl.Columns)),
+		Indices:      make(map[string]*jsonHistogram, len(tbl.Indices)),
+		Count:        tbl.Count,
+		ModifyCount:  tbl.ModifyCount,
+		Version:      tbl.Version,
+	}
+	for _, col := range tbl.Columns {
+		hg, err := dumpJSONHistogram(sc, &col.Histogram, false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jsonTbl.Columns[col.Info.Name.L] = hg
+	}
+	for _, idx := range tbl.Indices {
+		hg, err := dumpJSONHistogram(sc, &idx.Histogram, true)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jsonTbl.Indices[idx.Info.Name.L] = hg
+	}
+	for _, stats := range tbl.ExtendedStats {
+		jsonStats := &jsonExtendedStats{Name: stats.Name, NDV: stats.NDV}
+		for _, id := range stats.ColIDs {
+			for _, col := range tableInfo.Columns {
+				if col.ID == id {
+					jsonStats.Columns = append(jsonStats.Columns, col.Name.L)
+					break
+				}
+			}
+		}
+		jsonTbl.ExtendedStats = append(jsonTbl.ExtendedStats, jsonStats)
+	}
+	return jsonTbl, nil
+}
+
+func dumpJSONHistogram(sc *variable.StatementContext, hg *Histogram, isIndex bool) (*jsonHistogram, error) {
+	jsonHg := &jsonHistogram{
+		NDV:               hg.NDV,
+		NullCount:         hg.NullCount,
+		LastUpdateVersion: hg.LastUpdateVersion,
+		Buckets:           make([]jsonBucket, 0, len(hg.Buckets)),
+	}
+	for _, bucket := range hg.Buckets {
+		lowerBound, upperBound := bucket.LowerBound, bucket.UpperBound
+		if !isIndex {
+			var err error
+			lowerBound, err = lowerBound.ConvertTo(sc, types.NewFieldType(mysql.TypeBlob))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			upperBound, err = upperBound.ConvertTo(sc, types.NewFieldType(mysql.TypeBlob))
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		jsonHg.Buckets = append(jsonHg.Buckets, jsonBucket{
+			Count:      bucket.Count,
+			Repeats:    bucket.Repeats,
+			LowerBound: lowerBound.GetBytes(),
+			UpperBound: upperBound.GetBytes(),
+		})
+	}
+	return jsonHg, nil
+}
+
+// LoadStatsFromJSON saves the dumped statistics to the table, the ones of the columns and indices that aren't in the
+// table are skipped. The statistics are loaded by the next Update.
+func LoadStatsFromJSON(ctx context.Context, tableInfo *model.TableInfo, jsonTbl *JSONTable) error {
+	sc := ctx.GetSessionVars().StmtCtx
+	for _, col := range tableInfo.Columns {
+		jsonHg, ok := jsonTbl.Columns[col.Name.L]
+		if !ok {
+			continue
+		}
+		hg, err := loadJSONHistogram(sc, col.ID, jsonHg, &col.FieldType)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		err = hg.SaveToStorage(ctx, tableInfo.ID, jsonTbl.Count, 0)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, idx := range tableInfo.Indices {
+		jsonHg, ok := jsonTbl.Indices[idx.Name.L]
+		if !ok {
+			continue
+		}
+		hg, err := loadJSONHistogram(sc, idx.ID, jsonHg, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		err = hg.SaveToStorage(ctx, tableInfo.ID, jsonTbl.Count, 1)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, jsonStats := range jsonTbl.ExtendedStats {
+		stats := &ExtendedStats{Name: jsonStats.Name, NDV: jsonStats.NDV}
+		for _, name := range jsonStats.Columns {
+			for _, col := range tableInfo.Columns {
+				if col.Name.L == name {
+					stats.ColIDs = append(stats.ColIDs, col.ID)
+					break
+				}
+			}
+		}
+		if len(stats.ColIDs) != len(jsonStats.Columns) {
+			continue
+		}
+		err := stats.replaceToStorage(ctx, tableInfo.ID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// loadJSONHistogram builds the histogram from the dumped one, tp is the type of the column, it's nil for an index.
+func loadJSONHistogram(sc *variable.StatementContext, id int64, jsonHg *jsonHistogram, tp *types.FieldType) (*Histogram, error) {
+	hg := &Histogram{
+		ID:                id,
+		NDV:               jsonHg.NDV,
+		NullCount:         jsonHg.NullCount,
+		LastUpdateVersion: jsonHg.LastUpdateVersion,
+		Buckets:           make([]Bucket, 0, len(jsonHg.Buckets)),
+	}
+	for _, bucket := range jsonHg.Buckets {
+		lowerBound, upperBound := types.NewBytesDatum(bucket.LowerBound), types.NewBytesDatum(bucket.UpperBound)
+		if tp != nil {
+			var err error
+			lowerBound, err = lowerBound.ConvertTo(sc, tp)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			upperBound, err = upperBound.ConvertTo(sc, tp)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		hg.Buckets = append(hg.Buckets, Bucket{
+			Count:      bucket.Count,
+			Repeats:    bucket.Repeats,
+			LowerBound: lowerBound,
+			UpperBound: upperBound,
+		})
+	}
+	return hg, nil
+}
+
+// replaceToStorage saves both the definition and the number of distinct values of the extended statistics.
+func (s *ExtendedStats) replaceToStorage(ctx context.Context, tableID int64) error {
+	exec := ctx.(sqlexec.SQLExecutor)
+	_, err := exec.Execute("begin")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	version := ctx.Txn().StartTS()
+	_, err = exec.Execute(fmt.Sprintf("replace into mysql.stats_extended (name, table_id, column_ids, distinct_count, version) values ('%s', %d, '%s', %d, %d)", s.Name, tableID, encodeColumnIDs(s.ColIDs), s.NDV, version))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute(fmt.Sprintf("update mysql.stats_meta set version = %d where table_id = %d", version, tableID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute("commit")
+	return errors.Trace(err)
+}
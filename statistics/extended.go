@@ -0,0 +1,213 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// ExtendedStats is the statistics of a group of columns created by CREATE STATISTICS. The number of distinct values
+// of the group is built by analyze, it tells how much the columns depend on each other, so the selectivity of the
+// equal conditions on them isn't estimated as if they were independent.
+type ExtendedStats struct {
+	Name   string
+	ColIDs []int64
+	// NDV is the number of distinct values of the group, it's 0 before the table is analyzed.
+	NDV int64
+}
+
+func encodeColumnIDs(ids []int64) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(strs, ",")
+}
+
+func decodeColumnIDs(s string) ([]int64, error) {
+	strs := strings.Split(s, ",")
+	ids := make([]int64, 0, len(strs))
+	for _, str := range strs {
+		id, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ExtendedStatsFromStorage loads the extended statistics of the table. The ones on the dropped columns are skipped.
+func ExtendedStatsFromStorage(ctx context.Context, tableInfo *model.TableInfo) ([]*ExtendedStats, error) {
+	sql := fmt.Sprintf("select name, column_ids, distinct_count from mysql.stats_extended where table_id = %d order by name", tableInfo.ID)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	stats := make([]*ExtendedStats, 0, len(rows))
+	for _, row := range rows {
+		ids, err := decodeColumnIDs(row.Data[1].GetString())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !allColumnsExist(tableInfo, ids) {
+			continue
+		}
+		stats = append(stats, &ExtendedStats{Name: row.Data[0].GetString(), ColIDs: ids, NDV: row.Data[2].GetInt64()})
+	}
+	return stats, nil
+}
+
+func allColumnsExist(tableInfo *model.TableInfo, ids []int64) bool {
+	for _, id := range ids {
+		found := false
+		for _, col := range tableInfo.Columns {
+			if col.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func extendedStatsExist(ctx context.Context, tableID int64, name string) (bool, error) {
+	sql := fmt.Sprintf("select 1 from mysql.stats_extended where table_id = %d and name = '%s'", tableID, name)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(rows) > 0, nil
+}
+
+// CreateExtendedStats saves the definition of the extended statistics, it returns false if the statistics of the name
+// already exist on the table. The version of the table stats is updated so the statistics are loaded by the next Update.
+func CreateExtendedStats(ctx context.Context, tableID int64, stats *ExtendedStats) (bool, error) {
+	exist, err := extendedStatsExist(ctx, tableID, stats.Name)
+	if err != nil || exist {
+		return false, errors.Trace(err)
+	}
+	exec := ctx.(sqlexec.RestrictedSQLExecutor)
+	sql := fmt.Sprintf("insert into mysql.stats_extended (name, table_id, column_ids) values ('%s', %d, '%s')", stats.Name, tableID, encodeColumnIDs(stats.ColIDs))
+	_, _, err = exec.ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	_, _, err = exec.ExecRestrictedSQL(ctx, fmt.Sprintf("update mysql.stats_meta set version = %d where table_id = %d", ctx.Txn().StartTS(), tableID))
+	return true, errors.Trace(err)
+}
+
+// DropExtendedStats deletes the extended statistics, it returns false if the statistics of the name don't exist on the
+// table.
+func DropExtendedStats(ctx context.Context, tableID int64, name string) (bool, error) {
+	exist, err := extendedStatsExist(ctx, tableID, name)
+	if err != nil || !exist {
+		return false, errors.Trace(err)
+	}
+	exec := ctx.(sqlexec.RestrictedSQLExecutor)
+	_, _, err = exec.ExecRestrictedSQL(ctx, fmt.Sprintf("delete from mysql.stats_extended where table_id = %d and name = '%s'", tableID, name))
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	_, _, err = exec.ExecRestrictedSQL(ctx, fmt.Sprintf("update mysql.stats_meta set version = %d where table_id = %d", ctx.Txn().StartTS(), tableID))
+	return true, errors.Trace(err)
+}
+
+// SaveToStorage saves the number of distinct values built by analyze.
+func (s *ExtendedStats) SaveToStorage(ctx context.Context, tableID int64) error {
+	exec := ctx.(sqlexec.SQLExecutor)
+	_, err := exec.Execute("begin")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	version := ctx.Txn().StartTS()
+	_, err = exec.Execute(fmt.Sprintf("update mysql.stats_extended set distinct_count = %d, version = %d where table_id = %d and name = '%s'", s.NDV, version, tableID, s.Name))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute(fmt.Sprintf("update mysql.stats_meta set version = %d where table_id = %d", version, tableID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute("commit")
+	return errors.Trace(err)
+}
+
+// pointSelectivity is the selectivity of the equal conditions on a column, ndv is the number of distinct values of the
+// column.
+type pointSelectivity struct {
+	sel float64
+	ndv int64
+}
+
+// adjustByExtendedStats corrects the selectivity computed as if the columns were independent, sels are the
+// selectivities of the equal conditions on the columns, they are multiplied into sel.
+//
+// The dependency degree d of a group is estimated from the number of distinct values: it's 1 if the group has as many
+// distinct values as its most distinct column, which means the other columns are functionally dependent on it, and 0
+// if it has as many as the product of the columns, which means the columns are independent. The selectivity of the
+// group is s_min * Π(d + (1-d) * s_i) for the other columns, which goes from the minimum to the product of them.
+func (t *Table) adjustByExtendedStats(sel float64, sels map[int64]pointSelectivity) float64 {
+	for _, stats := range t.ExtendedStats {
+		if stats.NDV <= 0 {
+			continue
+		}
+		covered := true
+		maxNDV, prodNDV, minSel := float64(0), float64(1), math.MaxFloat64
+		for _, id := range stats.ColIDs {
+			s, ok := sels[id]
+			if !ok || s.sel <= 0 || s.ndv <= 0 {
+				covered = false
+				break
+			}
+			maxNDV = math.Max(maxNDV, float64(s.ndv))
+			prodNDV *= float64(s.ndv)
+			minSel = math.Min(minSel, s.sel)
+		}
+		if !covered {
+			continue
+		}
+		prodNDV = math.Min(prodNDV, float64(t.Count))
+		degree := float64(1)
+		if prodNDV > maxNDV {
+			degree = 1 - math.Log(float64(stats.NDV)/maxNDV)/math.Log(prodNDV/maxNDV)
+			degree = math.Max(math.Min(degree, 1), 0)
+		}
+		groupSel, minUsed := minSel, false
+		for _, id := range stats.ColIDs {
+			s := sels[id].sel
+			sel /= s
+			if s == minSel && !minUsed {
+				minUsed = true
+			} else {
+				groupSel *= degree + (1-degree)*s
+			}
+			// A column is only corrected by one group.
+			delete(sels, id)
+		}
+		sel *= groupSel
+	}
+	return sel
+}
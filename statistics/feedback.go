@@ -0,0 +1,297 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"fmt"
+	"math"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// feedbackErrorRate is the max error rate of an estimation that doesn't adjust the histogram.
+	feedbackErrorRate = 0.2
+	// maxQueryFeedbackCount is the max number of the feedback queued in the handle, the feedback is dropped when the
+	// queue is full.
+	maxQueryFeedbackCount = 1024
+)
+
+// QueryFeedback is the actual row count of a scan on the ranges of a histogram. When the scan finishes, it's
+// compared with the row count estimated by the histogram, and the histogram is adjusted if the estimation is far
+// from the actual row count, so the following queries get better plans before the table is analyzed again.
+type QueryFeedback struct {
+	tableID int64
+	histID  int64
+	isIndex bool
+	// histVersion is the version of the histogram used in the estimation, the feedback is dropped if the histogram
+	// has been updated since.
+	histVersion uint64
+	// ranges are the lower and upper bounds of the scanned ranges in the histogram. The upper bounds of the index
+	// ranges are exclusive, the ones of the column ranges are inclusive.
+	ranges [][2]types.Datum
+	// expected is the estimated row count, factor is the increase factor of the histogram applied to it.
+	expected float64
+	factor   float64
+	actual   int64
+}
+
+// NewQueryFeedbackByIntRanges creates the feedback of a scan on the int handle ranges of a table. It returns nil if
+// the handle column has no histogram.
+func NewQueryFeedbackByIntRanges(sc *variable.StatementContext, t *Table, colID int64, intRanges []types.IntColumnRange) (*QueryFeedback, error) {
+	c := t.Columns[colID]
+	if t.Pseudo || c == nil || len(c.Buckets) == 0 {
+		return nil, nil
+	}
+	expected, err := c.getIntColumnRowCount(sc, intRanges, float64(t.Count))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	q := &QueryFeedback{
+		tableID:     t.TableID,
+		histID:      colID,
+		histVersion: c.LastUpdateVersion,
+		ranges:      make([][2]types.Datum, 0, len(intRanges)),
+		expected:    expected,
+		factor:      1,
+	}
+	for _, rg := range intRanges {
+		q.ranges = append(q.ranges, [2]types.Datum{types.NewIntDatum(rg.LowVal), types.NewIntDatum(rg.HighVal)})
+	}
+	return q, nil
+}
+
+// NewQueryFeedbackByIndexRanges creates the feedback of a scan on the ranges of an index. It returns nil if the index
+// has no histogram.
+func NewQueryFeedbackByIndexRanges(sc *variable.StatementContext, t *Table, idxID int64, indexRanges []*types.IndexRange) (*QueryFeedback, error) {
+	idx := t.Indices[idxID]
+	if t.Pseudo || idx == nil || len(idx.Buckets) == 0 {
+		return nil, nil
+	}
+	factor := idx.getIncreaseFactor(t.Count)
+	if factor <= 0 {
+		factor = 1
+	}
+	expected, err := idx.getRowCount(sc, indexRanges)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	q := &QueryFeedback{
+		tableID:     t.TableID,
+		histID:      idxID,
+		isIndex:     true,
+		histVersion: idx.LastUpdateVersion,
+		ranges:      make([][2]types.Datum, 0, len(indexRanges)),
+		expected:    expected * factor,
+		factor:      factor,
+	}
+	for _, indexRange := range indexRanges {
+		l, r, err := idx.encodeRange(indexRange)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		q.ranges = append(q.ranges, [2]types.Datum{l, r})
+	}
+	return q, nil
+}
+
+// Update adds the count of the scanned rows to the actual row count.
+func (q *QueryFeedback) Update(count int64) {
+	q.actual += count
+}
+
+// adjust distributes the estimation error to the buckets overlapping with the scanned ranges in proportion to their
+// row counts. It returns false if the estimation is accurate enough and the histogram isn't changed.
+func (q *QueryFeedback) adjust(sc *variable.StatementContext, hg *Histogram) (bool, error) {
+	actual := float64(q.actual)
+	diff := actual - q.expected
+	if math.Abs(diff) <= feedbackErrorRate*math.Max(actual, q.expected) {
+		return false, nil
+	}
+	// The estimation is scaled by the increase factor, the error is scaled back to the row count in the histogram.
+	diff /= q.factor
+	counts := make([]float64, len(hg.Buckets))
+	overlapped := make([]int, 0, len(hg.Buckets))
+	total := float64(0)
+	for i, bucket := range hg.Buckets {
+		counts[i] = float64(bucket.Count)
+		if i > 0 {
+			counts[i] -= float64(hg.Buckets[i-1].Count)
+		}
+		ok, err := q.overlaps(sc, bucket)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if ok {
+			overlapped = append(overlapped, i)
+			total += counts[i]
+		}
+	}
+	if len(overlapped) == 0 {
+		return false, nil
+	}
+	for _, i := range overlapped {
+		share := 1 / float64(len(overlapped))
+		if total > 0 {
+			share = counts[i] / total
+		}
+		newCount := math.Max(counts[i]+diff*share, 0)
+		if counts[i] > 0 {
+			hg.Buckets[i].Repeats = int64(float64(hg.Buckets[i].Repeats) * newCount / counts[i])
+		}
+		counts[i] = newCount
+	}
+	sum := int64(0)
+	for i := range hg.Buckets {
+		count := int64(counts[i] + 0.5)
+		if hg.Buckets[i].Repeats > count {
+			hg.Buckets[i].Repeats = count
+		}
+		sum += count
+		hg.Buckets[i].Count = sum
+	}
+	return true, nil
+}
+
+// overlaps checks if the bucket overlaps with any scanned range.
+func (q *QueryFeedback) overlaps(sc *variable.StatementContext, bucket Bucket) (bool, error) {
+	for _, rg := range q.ranges {
+		cmp, err := bucket.UpperBound.CompareDatum(sc, rg[0])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if cmp < 0 {
+			continue
+		}
+		cmp, err = bucket.LowerBound.CompareDatum(sc, rg[1])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if cmp < 0 || (cmp == 0 && !q.isIndex) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// StoreQueryFeedback queues the feedback of a finished scan, it's applied by DumpStatsFeedbackToKV.
+func (h *Handle) StoreQueryFeedback(q *QueryFeedback) {
+	h.feedback.Lock()
+	defer h.feedback.Unlock()
+	if len(h.feedback.items) < maxQueryFeedbackCount {
+		h.feedback.items = append(h.feedback.items, q)
+	}
+}
+
+type histogramKey struct {
+	tableID int64
+	histID  int64
+	isIndex bool
+}
+
+// DumpStatsFeedbackToKV adjusts the histograms by the queued feedback and saves them to KV, they're loaded by the
+// next Update.
+func (h *Handle) DumpStatsFeedbackToKV() {
+	h.feedback.Lock()
+	items := h.feedback.items
+	h.feedback.items = nil
+	h.feedback.Unlock()
+
+	sc := h.ctx.GetSessionVars().StmtCtx
+	hists := make(map[histogramKey]*Histogram)
+	updated := make(map[histogramKey]bool)
+	for _, q := range items {
+		key := histogramKey{tableID: q.tableID, histID: q.histID, isIndex: q.isIndex}
+		hg, ok := hists[key]
+		if !ok {
+			hg = h.copyHistogram(key)
+			hists[key] = hg
+		}
+		if hg == nil || hg.LastUpdateVersion != q.histVersion {
+			continue
+		}
+		adjusted, err := q.adjust(sc, hg)
+		if err != nil {
+			log.Warnf("Error happens when adjusting histogram by feedback, the error message is %s.", err.Error())
+			continue
+		}
+		if adjusted {
+			updated[key] = true
+		}
+	}
+	for key := range updated {
+		isIndex := 0
+		if key.isIndex {
+			isIndex = 1
+		}
+		err := h.dumpFeedbackHistogramToKV(key.tableID, hists[key], isIndex)
+		if err != nil {
+			log.Warnf("Error happens when saving histogram adjusted by feedback, the error message is %s.", err.Error())
+		}
+	}
+}
+
+// copyHistogram returns a copy of the cached histogram to be adjusted, it returns nil if the histogram isn't cached or
+// the statistics of the table are locked.
+func (h *Handle) copyHistogram(key histogramKey) *Histogram {
+	t, ok := h.statsCache.Load().(statsCache)[key.tableID]
+	if !ok || t.Locked {
+		return nil
+	}
+	var hg Histogram
+	if key.isIndex {
+		idx := t.Indices[key.histID]
+		if idx == nil {
+			return nil
+		}
+		hg = idx.Histogram
+	} else {
+		col := t.Columns[key.histID]
+		if col == nil {
+			return nil
+		}
+		hg = col.Histogram
+	}
+	hg.Buckets = append([]Bucket(nil), hg.Buckets...)
+	return &hg
+}
+
+// dumpFeedbackHistogramToKV saves the buckets of the adjusted histogram and updates the versions, the row count and
+// the modify count of the table are kept.
+func (h *Handle) dumpFeedbackHistogramToKV(tableID int64, hg *Histogram, isIndex int) error {
+	exec := h.ctx.(sqlexec.SQLExecutor)
+	_, err := exec.Execute("begin")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	version := h.ctx.Txn().StartTS()
+	_, err = exec.Execute(fmt.Sprintf("update mysql.stats_meta set version = %d where table_id = %d", version, tableID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute(fmt.Sprintf("update mysql.stats_histograms set version = %d where table_id = %d and is_index = %d and hist_id = %d", version, tableID, isIndex, hg.ID))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = hg.saveBucketsToStorage(h.ctx, tableID, isIndex)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute("commit")
+	return errors.Trace(err)
+}
@@ -15,6 +15,7 @@ package statistics
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -49,6 +50,11 @@ type Handle struct {
 	listHead *SessionStatsCollector
 	// We collect the delta map and merge them with globalMap.
 	globalMap tableDeltaMap
+	// feedback queues the feedback of the finished scans, see DumpStatsFeedbackToKV.
+	feedback struct {
+		sync.Mutex
+		items []*QueryFeedback
+	}
 
 	Lease time.Duration
 }
@@ -66,6 +72,7 @@ func (h *Handle) Clear() {
 	}
 	h.listHead = &SessionStatsCollector{mapper: make(tableDeltaMap)}
 	h.globalMap = make(tableDeltaMap)
+	h.feedback.items = nil
 }
 
 // NewHandle creates a Handle for update stats.
@@ -15,16 +15,23 @@ package statistics_test
 
 import (
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -58,6 +65,8 @@ func cleanEnv(c *C, store kv.Storage, do *domain.Domain) {
 	tk.MustExec("truncate table mysql.stats_meta")
 	tk.MustExec("truncate table mysql.stats_histograms")
 	tk.MustExec("truncate table mysql.stats_buckets")
+	tk.MustExec("truncate table mysql.stats_extended")
+	tk.MustExec("truncate table mysql.stats_locked")
 }
 
 func (s *testStatsCacheSuite) TestStatsCache(c *C) {
@@ -343,3 +352,194 @@ func newStoreWithBootstrap() (kv.Storage, *domain.Domain, error) {
 	do, err := tidb.BootstrapSession(store)
 	return store, do, errors.Trace(err)
 }
+
+func (s *testStatsCacheSuite) TestExtendedStats(c *C) {
+	defer cleanEnv(c, s.store, s.do)
+	testKit := testkit.NewTestKit(c, s.store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, b int, c int, index ib(b))")
+	for i := 0; i < 500; i++ {
+		testKit.MustExec(fmt.Sprintf("insert into t values (%d, %d, %d)", i%50, i%50, i%10))
+	}
+	testKit.MustExec("analyze table t")
+	do := s.do
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+
+	selectivity := func(cond string) float64 {
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, "select * from t where "+cond)
+		c.Assert(err, IsNil)
+		err = plan.ResolveName(stmts[0], is, ctx)
+		c.Assert(err, IsNil)
+		p, err := plan.BuildLogicalPlan(ctx, stmts[0], is)
+		c.Assert(err, IsNil)
+		sel := p.Children()[0].(*plan.Selection)
+		ratio, err := do.StatsHandle().GetTableStats(tableInfo.ID).Selectivity(ctx, sel.Conditions)
+		c.Assert(err, IsNil)
+		return ratio
+	}
+	// The columns are estimated as if they were independent without the extended stats.
+	c.Assert(math.Abs(selectivity("a = 1 and b = 1")-0.0004) < 1e-9, IsTrue)
+
+	testKit.MustExec("create statistics s1 on t (a, b)")
+	testKit.MustExec("create statistics s2 on t (a, c)")
+	_, err = testKit.Exec("create statistics s1 on t (a, c)")
+	c.Assert(terror.ErrorEqual(err, executor.ErrStatsExists), IsTrue)
+	testKit.MustExec("create statistics if not exists s1 on t (a, c)")
+	c.Assert(testKit.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	_, err = testKit.Exec("create statistics s3 on t (a, d)")
+	c.Assert(terror.ErrorEqual(err, plan.ErrUnknownColumn), IsTrue)
+	_, err = testKit.Exec("create statistics s3 on t (a, a)")
+	c.Assert(terror.ErrorEqual(err, executor.ErrStatsColumns), IsTrue)
+
+	// The NDVs of the groups are built by analyze.
+	statsTbl := do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.ExtendedStats, HasLen, 2)
+	c.Assert(statsTbl.ExtendedStats[0].NDV, Equals, int64(0))
+	testKit.MustExec("analyze table t")
+	statsTbl = do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.ExtendedStats, HasLen, 2)
+	c.Assert(statsTbl.ExtendedStats[0].NDV, Equals, int64(50))
+	c.Assert(statsTbl.ExtendedStats[1].NDV, Equals, int64(50))
+
+	// b depends on a, so the selectivity is the one of a.
+	c.Assert(math.Abs(selectivity("a = 1 and b = 1")-0.02) < 1e-9, IsTrue)
+	// c depends on a too, but a doesn't depend on c.
+	c.Assert(math.Abs(selectivity("a = 1 and c = 1")-0.02) < 1e-9, IsTrue)
+	// The range conditions are not corrected.
+	c.Assert(math.Abs(selectivity("a = 1 and b < 1")-0.0004) < 1e-9, IsTrue)
+
+	testKit.MustExec("drop statistics s1 on t")
+	_, err = testKit.Exec("drop statistics s1 on t")
+	c.Assert(terror.ErrorEqual(err, executor.ErrStatsNotExists), IsTrue)
+	testKit.MustExec("drop statistics if exists s1 on t")
+	statsTbl = do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.ExtendedStats, HasLen, 1)
+	c.Assert(math.Abs(selectivity("a = 1 and b = 1")-0.0004) < 1e-9, IsTrue)
+}
+
+func (s *testStatsCacheSuite) TestDumpLoadStats(c *C) {
+	defer cleanEnv(c, s.store, s.do)
+	testKit := testkit.NewTestKit(c, s.store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, b varchar(10), c double, index ia(a), index ibc(b, c))")
+	for i := 0; i < 100; i++ {
+		testKit.MustExec(fmt.Sprintf("insert into t values (%d, 'b%d', %d.5)", i, i%10, i%20))
+	}
+	testKit.MustExec("create statistics s1 on t (b, c)")
+	testKit.MustExec("analyze table t")
+	do := s.do
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	statsTbl1 := do.StatsHandle().GetTableStats(tableInfo.ID)
+
+	rows := testKit.MustQuery("show stats_json from t").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][0], Equals, "test")
+	c.Assert(rows[0][1], Equals, "t")
+	jsonStats := rows[0][2].(string)
+
+	testKit.MustExec("drop stats t")
+	c.Assert(do.StatsHandle().GetTableStats(tableInfo.ID).Pseudo, IsTrue)
+	testKit.MustExec(fmt.Sprintf("load stats '%s'", jsonStats))
+	statsTbl2 := do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl2.Pseudo, IsFalse)
+	c.Assert(statsTbl2.Count, Equals, int64(100))
+	assertTableEqual(c, statsTbl1, statsTbl2)
+	c.Assert(statsTbl2.ExtendedStats, HasLen, 1)
+	c.Assert(statsTbl2.ExtendedStats[0].ColIDs, DeepEquals, statsTbl1.ExtendedStats[0].ColIDs)
+	c.Assert(statsTbl2.ExtendedStats[0].NDV, Equals, int64(20))
+
+	_, err = testKit.Exec("load stats '{\"database_name\": \"test\", \"table_name\": \"t1\"}'")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrTableNotExists), IsTrue)
+	_, err = testKit.Exec("load stats 'stats'")
+	c.Assert(terror.ErrorEqual(err, executor.ErrStatsJSON), IsTrue)
+}
+
+func (s *testStatsCacheSuite) TestLockStats(c *C) {
+	defer cleanEnv(c, s.store, s.do)
+	testKit := testkit.NewTestKit(c, s.store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, index ia(a))")
+	testKit.MustExec("insert into t values (1), (2), (3)")
+	testKit.MustExec("analyze table t")
+	do := s.do
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	statsTbl1 := do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl1.Locked, IsFalse)
+
+	testKit.MustExec("lock stats t")
+	statsTbl2 := do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl2.Locked, IsTrue)
+	// The locked statistics are not changed by analyze.
+	testKit.MustExec("insert into t values (4), (5)")
+	testKit.MustExec("analyze table t")
+	c.Assert(testKit.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(1))
+	statsTbl2 = do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl2.Count, Equals, int64(3))
+	assertTableEqual(c, statsTbl1, statsTbl2)
+
+	testKit.MustExec("unlock stats t")
+	c.Assert(do.StatsHandle().GetTableStats(tableInfo.ID).Locked, IsFalse)
+	testKit.MustExec("analyze table t")
+	c.Assert(testKit.Se.GetSessionVars().StmtCtx.WarningCount(), Equals, uint16(0))
+	c.Assert(do.StatsHandle().GetTableStats(tableInfo.ID).Count, Equals, int64(5))
+}
+
+func (s *testStatsCacheSuite) TestFastAnalyze(c *C) {
+	defer cleanEnv(c, s.store, s.do)
+	testKit := testkit.NewTestKit(c, s.store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int primary key, b int, c int, index ib(b))")
+	for i := 0; i < 20; i++ {
+		values := make([]string, 0, 100)
+		for j := i * 100; j < (i+1)*100; j++ {
+			values = append(values, fmt.Sprintf("(%d, %d, %d)", j, j%10, j%20))
+		}
+		testKit.MustExec("insert into t values " + strings.Join(values, ", "))
+	}
+	testKit.MustExec("create statistics s1 on t (b, c)")
+	do := s.do
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	aID, cID, ibID := tableInfo.Columns[0].ID, tableInfo.Columns[2].ID, tableInfo.Indices[0].ID
+
+	// All the rows are read if the table isn't larger than the number of samples.
+	testKit.MustExec("analyze table t with 5000 samples")
+	statsTbl := do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.Count, Equals, int64(2000))
+	c.Assert(statsTbl.Columns[aID].NDV, Equals, int64(2000))
+	c.Assert(statsTbl.Columns[cID].NDV, Equals, int64(20))
+	c.Assert(statsTbl.Indices[ibID].NDV, Equals, int64(10))
+	c.Assert(statsTbl.ExtendedStats[0].NDV, Equals, int64(20))
+
+	// The row count is estimated by the sampled handles, the values of b and c all appear in the samples.
+	testKit.MustExec("analyze table t with 500 samples")
+	statsTbl = do.StatsHandle().GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.Count, Equals, int64(2000))
+	c.Assert(statsTbl.Columns[aID].NDV > 400 && statsTbl.Columns[aID].NDV <= 2000, IsTrue)
+	c.Assert(statsTbl.Columns[cID].NDV, Equals, int64(20))
+	c.Assert(statsTbl.Indices[ibID].NDV, Equals, int64(10))
+	c.Assert(statsTbl.ExtendedStats[0].NDV, Equals, int64(20))
+	hg := statsTbl.Columns[cID].Histogram
+	// The bucket counts are scaled from the samples.
+	c.Assert(math.Abs(float64(hg.Buckets[len(hg.Buckets)-1].Count)-2000) < 20, IsTrue)
+
+	testKit.MustExec("truncate table t")
+	testKit.MustExec("analyze table t with 500 samples")
+	tbl, err = do.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	statsTbl = do.StatsHandle().GetTableStats(tbl.Meta().ID)
+	c.Assert(statsTbl.Pseudo, IsFalse)
+	c.Assert(statsTbl.Count, Equals, int64(0))
+}
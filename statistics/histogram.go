@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/sqlexec"
 	"github.com/pingcap/tidb/util/types"
@@ -75,8 +76,19 @@ func (hg *Histogram) SaveToStorage(ctx context.Context, tableID int64, count int
 	if err != nil {
 		return errors.Trace(err)
 	}
+	err = hg.saveBucketsToStorage(ctx, tableID, isIndex)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = exec.Execute("commit")
+	return errors.Trace(err)
+}
+
+// saveBucketsToStorage replaces the buckets of the histogram in storage, it's called in a transaction.
+func (hg *Histogram) saveBucketsToStorage(ctx context.Context, tableID int64, isIndex int) error {
+	exec := ctx.(sqlexec.SQLExecutor)
 	deleteSQL := fmt.Sprintf("delete from mysql.stats_buckets where table_id = %d and is_index = %d and hist_id = %d", tableID, isIndex, hg.ID)
-	_, err = exec.Execute(deleteSQL)
+	_, err := exec.Execute(deleteSQL)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -103,8 +115,7 @@ func (hg *Histogram) SaveToStorage(ctx context.Context, tableID int64, count int
 			return errors.Trace(err)
 		}
 	}
-	_, err = exec.Execute("commit")
-	return errors.Trace(err)
+	return nil
 }
 
 func histogramFromStorage(ctx context.Context, tableID int64, colID int64, tp *types.FieldType, distinct int64, isIndex int, ver uint64, nullCount int64) (*Histogram, error) {
@@ -442,23 +453,10 @@ func (idx *Index) String() string {
 func (idx *Index) getRowCount(sc *variable.StatementContext, indexRanges []*types.IndexRange) (float64, error) {
 	totalCount := float64(0)
 	for _, indexRange := range indexRanges {
-		indexRange.Align(len(idx.Info.Columns))
-		lb, err := codec.EncodeKey(nil, indexRange.LowVal...)
-		if err != nil {
-			return 0, errors.Trace(err)
-		}
-		if indexRange.LowExclude {
-			lb = append(lb, 0)
-		}
-		rb, err := codec.EncodeKey(nil, indexRange.HighVal...)
+		l, r, err := idx.encodeRange(indexRange)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
-		if !indexRange.HighExclude {
-			rb = append(rb, 0)
-		}
-		l := types.NewBytesDatum(lb)
-		r := types.NewBytesDatum(rb)
 		rowCount, err := idx.betweenRowCount(sc, l, r)
 		if err != nil {
 			return 0, errors.Trace(err)
@@ -470,3 +468,31 @@ func (idx *Index) getRowCount(sc *variable.StatementContext, indexRanges []*type
 	}
 	return totalCount, nil
 }
+
+// encodeRange returns the lower bound and the exclusive upper bound of the range in the histogram.
+func (idx *Index) encodeRange(indexRange *types.IndexRange) (types.Datum, types.Datum, error) {
+	indexRange.Align(len(idx.Info.Columns))
+	if idx.Info.HasDescColumn() {
+		// The range of a descending column is reversed in the encoded values.
+		lb, rb, err := tablecodec.EncodeIndexRange(indexRange, idx.Info.Columns)
+		if err != nil {
+			return types.Datum{}, types.Datum{}, errors.Trace(err)
+		}
+		return types.NewBytesDatum(lb), types.NewBytesDatum(rb), nil
+	}
+	lb, err := codec.EncodeKey(nil, indexRange.LowVal...)
+	if err != nil {
+		return types.Datum{}, types.Datum{}, errors.Trace(err)
+	}
+	if indexRange.LowExclude {
+		lb = append(lb, 0)
+	}
+	rb, err := codec.EncodeKey(nil, indexRange.HighVal...)
+	if err != nil {
+		return types.Datum{}, types.Datum{}, errors.Trace(err)
+	}
+	if !indexRange.HighExclude {
+		rb = append(rb, 0)
+	}
+	return types.NewBytesDatum(lb), types.NewBytesDatum(rb), nil
+}
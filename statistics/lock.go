@@ -0,0 +1,55 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// IsStatsLocked checks if the statistics of the table are locked by LOCK STATS. The locked statistics aren't changed by
+// analyze or the query feedback, so a tuned plan is kept, but they can still be replaced by LOAD STATS.
+func IsStatsLocked(ctx context.Context, tableID int64) (bool, error) {
+	sql := fmt.Sprintf("select 1 from mysql.stats_locked where table_id = %d", tableID)
+	rows, _, err := ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return len(rows) > 0, nil
+}
+
+// LockStats locks the statistics of the table.
+func LockStats(ctx context.Context, tableID int64) error {
+	return errors.Trace(setStatsLocked(ctx, tableID, fmt.Sprintf("replace into mysql.stats_locked (table_id) values (%d)", tableID)))
+}
+
+// UnlockStats unlocks the statistics of the table.
+func UnlockStats(ctx context.Context, tableID int64) error {
+	return errors.Trace(setStatsLocked(ctx, tableID, fmt.Sprintf("delete from mysql.stats_locked where table_id = %d", tableID)))
+}
+
+// setStatsLocked executes the sql that changes the lock, the version of the table stats is updated so the lock is
+// loaded by the next Update.
+func setStatsLocked(ctx context.Context, tableID int64, sql string) error {
+	exec := ctx.(sqlexec.RestrictedSQLExecutor)
+	_, _, err := exec.ExecRestrictedSQL(ctx, sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, _, err = exec.ExecRestrictedSQL(ctx, fmt.Sprintf("update mysql.stats_meta set version = %d where table_id = %d", ctx.Txn().StartTS(), tableID))
+	return errors.Trace(err)
+}
@@ -14,11 +14,13 @@
 package statistics
 
 import (
+	"math"
 	"math/rand"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -71,6 +73,9 @@ type SampleBuilder struct {
 	MaxBucketSize int64
 	MaxSampleSize int64
 	MaxSketchSize int64
+	// ColGroups are the offsets of the columns of the groups in the rows, the primary key is at 0 if it's the handle.
+	// Only the NDVs of the groups are estimated, the collectors of the groups are after the ones of the columns.
+	ColGroups [][]int
 }
 
 // CollectSamplesAndEstimateNDVs collects sample from the result set using Reservoir Sampling algorithm,
@@ -83,12 +88,15 @@ func (s SampleBuilder) CollectSamplesAndEstimateNDVs() ([]*SampleCollector, *Sor
 	if s.PkID != -1 {
 		pkBuilder = NewSortedBuilder(s.Sc, s.MaxBucketSize, s.PkID)
 	}
-	collectors := make([]*SampleCollector, s.ColLen)
+	collectors := make([]*SampleCollector, s.ColLen+len(s.ColGroups))
 	for i := range collectors {
 		collectors[i] = &SampleCollector{
 			MaxSampleSize: s.MaxSampleSize,
 			Sketch:        NewFMSketch(int(s.MaxSketchSize)),
 		}
+		if i >= s.ColLen {
+			collectors[i].MaxSampleSize = 0
+		}
 	}
 	for {
 		row, err := s.RecordSet.Next()
@@ -98,6 +106,20 @@ func (s SampleBuilder) CollectSamplesAndEstimateNDVs() ([]*SampleCollector, *Sor
 		if row == nil {
 			return collectors, pkBuilder, nil
 		}
+		for i, group := range s.ColGroups {
+			vals := make([]types.Datum, 0, len(group))
+			for _, offset := range group {
+				vals = append(vals, row.Data[offset])
+			}
+			key, err := codec.EncodeKey(nil, vals...)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			err = collectors[s.ColLen+i].collect(types.NewBytesDatum(key))
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+		}
 		if s.PkID != -1 {
 			err = pkBuilder.Iterate(row.Data[0])
 			if err != nil {
@@ -113,3 +135,36 @@ func (s SampleBuilder) CollectSamplesAndEstimateNDVs() ([]*SampleCollector, *Sor
 		}
 	}
 }
+
+// EstimateNDVBySamples estimates the number of distinct values of the count rows from the samples by the GEE
+// estimator: sqrt(count/n) * f1 + Σf_i (i >= 2), n is the number of samples and f_i is the number of values appearing
+// i times in the samples. The ratio error of the estimation is bounded by sqrt(count/n).
+// See "Towards Estimation Error Guarantees for Distinct Values" by Charikar et al.
+func EstimateNDVBySamples(samples []types.Datum, count int64) (int64, error) {
+	if len(samples) == 0 {
+		return 0, nil
+	}
+	occurrences := make(map[string]int, len(samples))
+	for _, d := range samples {
+		key, err := codec.EncodeKey(nil, d)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		occurrences[string(key)]++
+	}
+	var f1 int64
+	for _, n := range occurrences {
+		if n == 1 {
+			f1++
+		}
+	}
+	distinct := int64(len(occurrences))
+	ndv := int64(math.Sqrt(float64(count)/float64(len(samples)))*float64(f1)) + distinct - f1
+	if ndv > count {
+		ndv = count
+	}
+	if ndv < distinct {
+		ndv = distinct
+	}
+	return ndv, nil
+}
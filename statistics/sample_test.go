@@ -111,3 +111,27 @@ func (s *testSampleSuite) TestMergeSampleCollector(c *C) {
 	c.Assert(collectors[0].NullCount, Equals, int64(1000))
 	c.Assert(collectors[0].Count, Equals, int64(19000))
 }
+
+func (s *testSampleSuite) TestEstimateNDVBySamples(c *C) {
+	samples := make([]types.Datum, 0, 100)
+	for i := 0; i < 100; i++ {
+		samples = append(samples, types.NewIntDatum(int64(i)))
+	}
+	// All the sampled values are distinct, so the values are likely to be distinct in the other rows too.
+	ndv, err := statistics.EstimateNDVBySamples(samples, 10000)
+	c.Assert(err, IsNil)
+	c.Assert(ndv, Equals, int64(1000))
+	ndv, err = statistics.EstimateNDVBySamples(samples, 400)
+	c.Assert(err, IsNil)
+	c.Assert(ndv, Equals, int64(200))
+	// Every sampled value appears twice, they're likely to be all the values.
+	for i := 0; i < 100; i++ {
+		samples[i].SetInt64(int64(i / 2))
+	}
+	ndv, err = statistics.EstimateNDVBySamples(samples, 10000)
+	c.Assert(err, IsNil)
+	c.Assert(ndv, Equals, int64(50))
+	ndv, err = statistics.EstimateNDVBySamples(nil, 10000)
+	c.Assert(err, IsNil)
+	c.Assert(ndv, Equals, int64(0))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/ranger"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -36,6 +37,8 @@ type exprSet struct {
 	mask int64
 	// This stores ranges we get.
 	ranges []types.Range
+	// colID is the id of the column if the set is of a column or a single column index.
+	colID int64
 }
 
 // The type of the exprSet.
@@ -104,7 +107,7 @@ func (t *Table) Selectivity(ctx context.Context, exprs []expression.Expression)
 			if err != nil {
 				return 0, errors.Trace(err)
 			}
-			sets = append(sets, &exprSet{tp: colType, ID: col.ID, mask: maskCovered, ranges: ranges})
+			sets = append(sets, &exprSet{tp: colType, ID: col.ID, mask: maskCovered, ranges: ranges, colID: col.ID})
 			if mysql.HasPriKeyFlag(colInfo.Info.Flag) {
 				sets[len(sets)-1].tp = pkType
 			}
@@ -118,11 +121,18 @@ func (t *Table) Selectivity(ctx context.Context, exprs []expression.Expression)
 			if err != nil {
 				return 0, errors.Trace(err)
 			}
-			sets = append(sets, &exprSet{tp: indexType, ID: idxInfo.ID, mask: maskCovered, ranges: ranges})
+			set := &exprSet{tp: indexType, ID: idxInfo.ID, mask: maskCovered, ranges: ranges}
+			if len(idxInfo.Info.Columns) == 1 {
+				set.colID = idxCols[0].ID
+			}
+			sets = append(sets, set)
 		}
 	}
 	sets = getUsableSetsByGreedy(sets)
 	ret := 1.0
+	// pointSels are the selectivities of the columns with only equal conditions, they're corrected by the extended
+	// stats of the column groups.
+	pointSels := make(map[int64]pointSelectivity)
 	// Initialize the mask with the full set.
 	mask := (int64(1) << uint(len(exprs))) - 1
 	for _, set := range sets {
@@ -143,7 +153,11 @@ func (t *Table) Selectivity(ctx context.Context, exprs []expression.Expression)
 			return 0, errors.Trace(err)
 		}
 		ret *= rowCount / float64(t.Count)
+		if len(t.ExtendedStats) > 0 && set.colID > 0 && isPointSet(sc, set) {
+			pointSels[set.colID] = pointSelectivity{sel: rowCount / float64(t.Count), ndv: t.setNDV(set)}
+		}
 	}
+	ret = t.adjustByExtendedStats(ret, pointSels)
 	// If there's still conditions which cannot be calculated, we will multiply a selectionFactor.
 	if mask > 0 {
 		ret *= selectionFactor
@@ -151,6 +165,34 @@ func (t *Table) Selectivity(ctx context.Context, exprs []expression.Expression)
 	return ret, nil
 }
 
+// isPointSet checks if the set only has equal conditions.
+func isPointSet(sc *variable.StatementContext, set *exprSet) bool {
+	if len(set.ranges) == 0 {
+		return false
+	}
+	for _, rg := range set.ranges {
+		var indexRange *types.IndexRange
+		if set.tp == indexType {
+			indexRange = rg.Convert2IndexRange()
+		} else {
+			cr := rg.Convert2ColumnRange()
+			indexRange = &types.IndexRange{LowVal: []types.Datum{cr.Low}, HighVal: []types.Datum{cr.High}, LowExclude: cr.LowExcl, HighExclude: cr.HighExcl}
+		}
+		if !indexRange.IsPoint(sc) {
+			return false
+		}
+	}
+	return true
+}
+
+// setNDV returns the number of distinct values of the column or the index of the set.
+func (t *Table) setNDV(set *exprSet) int64 {
+	if set.tp == indexType {
+		return t.Indices[set.ID].NDV
+	}
+	return t.Columns[set.ID].NDV
+}
+
 func getMaskAndRanges(ctx context.Context, exprs []expression.Expression, rangeType int,
 	lengths []int, cols ...*expression.Column) (int64, []types.Range, error) {
 	exprsClone := make([]expression.Expression, 0, len(exprs))
@@ -48,6 +48,10 @@ type Table struct {
 	ModifyCount int64 // Total modify count in a table.
 	Version     uint64
 	Pseudo      bool
+	// ExtendedStats are the statistics of the column groups created by CREATE STATISTICS.
+	ExtendedStats []*ExtendedStats
+	// Locked means the statistics are locked by LOCK STATS, they aren't changed by analyze or the query feedback.
+	Locked bool
 }
 
 func (t *Table) copy() *Table {
@@ -57,6 +61,9 @@ func (t *Table) copy() *Table {
 		Pseudo:  t.Pseudo,
 		Columns: make(map[int64]*Column),
 		Indices: make(map[int64]*Index),
+
+		ExtendedStats: t.ExtendedStats,
+		Locked:        t.Locked,
 	}
 	for id, col := range t.Columns {
 		nt.Columns[id] = col
@@ -139,6 +146,14 @@ func (h *Handle) tableStatsFromStorage(tableInfo *model.TableInfo) (*Table, erro
 			}
 		}
 	}
+	table.ExtendedStats, err = ExtendedStatsFromStorage(h.ctx, tableInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	table.Locked, err = IsStatsLocked(h.ctx, tableInfo.ID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return table, nil
 }
 
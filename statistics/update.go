@@ -174,7 +174,7 @@ func (h *Handle) HandleAutoAnalyze(is infoschema.InfoSchema) error {
 		for _, tbl := range tbls {
 			tblInfo := tbl.Meta()
 			statsTbl := h.GetTableStats(tblInfo.ID)
-			if statsTbl.Pseudo || statsTbl.Count == 0 {
+			if statsTbl.Pseudo || statsTbl.Count == 0 || statsTbl.Locked {
 				continue
 			}
 			tblName := "`" + db + "`.`" + tblInfo.Name.O + "`"
@@ -18,6 +18,7 @@ import (
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -278,3 +279,56 @@ func (s *testStatsUpdateSuite) TestAutoUpdate(c *C) {
 	c.Assert(hg.NDV, Equals, int64(1))
 	c.Assert(len(hg.Buckets), Equals, 1)
 }
+
+func (s *testStatsUpdateSuite) TestQueryFeedback(c *C) {
+	defer cleanEnv(c, s.store, s.do)
+	testKit := testkit.NewTestKit(c, s.store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int primary key, b int, index idx(b))")
+	for i := 0; i < 20; i++ {
+		testKit.MustExec("insert into t values (?, ?)", i, i)
+	}
+	h := s.do.StatsHandle()
+	h.DumpStatsDeltaToKV()
+	testKit.MustExec("analyze table t")
+	// The rows are inserted after the table is analyzed, so the histogram of idx underestimates the rows with b = 5.
+	for i := 20; i < 120; i++ {
+		testKit.MustExec("insert into t values (?, 5)", i)
+	}
+
+	do := s.do
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	idxID := tableInfo.Indices[0].ID
+	h.DumpStatsDeltaToKV()
+	c.Assert(h.Update(is), IsNil)
+
+	sc := new(variable.StatementContext)
+	ranges := func() []*types.IndexRange {
+		return []*types.IndexRange{{LowVal: []types.Datum{types.NewIntDatum(5)}, HighVal: []types.Datum{types.NewIntDatum(5)}}}
+	}
+	before, err := h.GetTableStats(tableInfo.ID).GetRowCountByIndexRanges(sc, idxID, ranges())
+	c.Assert(err, IsNil)
+	c.Assert(before < 10, IsTrue, Commentf("estimated %v rows", before))
+
+	// The scan pushing down a filter or a limit doesn't read all the rows in its ranges, it has no feedback.
+	testKit.MustQuery("select b from t where b = 5 limit 1").Check(testkit.Rows("5"))
+	h.DumpStatsFeedbackToKV()
+	c.Assert(h.Update(is), IsNil)
+	estimated, err := h.GetTableStats(tableInfo.ID).GetRowCountByIndexRanges(sc, idxID, ranges())
+	c.Assert(err, IsNil)
+	c.Assert(estimated, Equals, before)
+
+	rows := testKit.MustQuery("select b from t where b = 5").Rows()
+	c.Assert(rows, HasLen, 101)
+	h.DumpStatsFeedbackToKV()
+	c.Assert(h.Update(is), IsNil)
+	after, err := h.GetTableStats(tableInfo.ID).GetRowCountByIndexRanges(sc, idxID, ranges())
+	c.Assert(err, IsNil)
+	c.Assert(after > before*5, IsTrue, Commentf("estimated %v rows before the feedback, %v after", before, after))
+	statsTbl := h.GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.Count, Equals, int64(120))
+	c.Assert(statsTbl.ModifyCount, Equals, int64(100))
+}
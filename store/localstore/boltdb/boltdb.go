@@ -16,6 +16,7 @@ package boltdb
 import (
 	"os"
 	"path"
+	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/juju/errors"
@@ -30,6 +31,9 @@ var (
 	bucketName = []byte("tidb")
 )
 
+// openTimeout is the time to wait for the file lock, which is held by another process using the database.
+const openTimeout = 5 * time.Second
+
 type db struct {
 	*bolt.DB
 }
@@ -172,11 +176,12 @@ type Driver struct {
 }
 
 // Open opens or creates a local storage database with given path.
+// Every commit is synced to the disk before it returns, so committed data survive a crash.
 func (driver Driver) Open(dbPath string) (engine.DB, error) {
 	base := path.Dir(dbPath)
 	os.MkdirAll(base, 0755)
 
-	d, err := bolt.Open(dbPath, 0600, nil)
+	d, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: openTimeout})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
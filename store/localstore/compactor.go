@@ -106,9 +106,13 @@ func (gc *localstoreCompactor) deleteWorker() {
 			// Batch delete.
 			if cnt == gc.policy.BatchDeleteCnt {
 				log.Debugf("[kv] GC delete commit %d keys", batch.Len())
+				start := time.Now()
 				err := gc.db.Commit(batch)
+				writeHistogram.WithLabelValues("compaction").Observe(time.Since(start).Seconds())
 				if err != nil {
 					log.Error(err)
+				} else {
+					compactionKeysCounter.WithLabelValues("deleted").Add(float64(cnt))
 				}
 				batch = gc.db.NewBatch()
 				cnt = 0
@@ -133,12 +137,15 @@ func (gc *localstoreCompactor) checkExpiredKeysWorker() {
 			}
 			gc.recentKeys = make(map[string]struct{})
 			gc.mu.Unlock()
+			start := time.Now()
 			for k := range m {
 				err := gc.Compact([]byte(k))
 				if err != nil {
 					log.Error(err)
 				}
 			}
+			compactionHistogram.Observe(time.Since(start).Seconds())
+			compactionKeysCounter.WithLabelValues("checked").Add(float64(len(m)))
 		}
 	}
 }
@@ -0,0 +1,64 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/localstore/boltdb"
+	"github.com/pingcap/tidb/store/localstore/engine"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/store/storetest"
+)
+
+var _ = Suite(&storetest.Suite{NewStore: func() (kv.Storage, error) {
+	return Driver{goleveldb.MemoryDriver{}}.Open("memory://conformance_test")
+}})
+
+var _ = Suite(&storetest.Suite{NewStore: newTempStore("goleveldb", goleveldb.Driver{})})
+
+var _ = Suite(&storetest.Suite{NewStore: newTempStore("boltdb", boltdb.Driver{})})
+
+// tempStore is a store in a temporary directory, which is removed when the store is closed.
+type tempStore struct {
+	kv.Storage
+	dir string
+}
+
+func (s *tempStore) Close() error {
+	err := s.Storage.Close()
+	os.RemoveAll(s.dir)
+	return err
+}
+
+// newTempStore returns the function opening a store of the engine in a temporary directory.
+func newTempStore(name string, driver engine.Driver) func() (kv.Storage, error) {
+	return func() (kv.Storage, error) {
+		dir, err := ioutil.TempDir("", "conformance_test")
+		if err != nil {
+			return nil, err
+		}
+		store, err := Driver{driver}.Open(fmt.Sprintf("%s://%s", name, filepath.Join(dir, name)))
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		return &tempStore{Storage: store, dir: dir}, nil
+	}
+}
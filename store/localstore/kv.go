@@ -402,7 +402,9 @@ func (s *dbStore) writeBatch(b engine.Batch) error {
 	if b.Len() == 0 {
 		return nil
 	}
+	start := time.Now()
 	err := s.db.Commit(b)
+	writeHistogram.WithLabelValues("commit").Observe(time.Since(start).Seconds())
 	if err != nil {
 		log.Error(err)
 		return errors.Trace(err)
@@ -19,6 +19,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/distsql/xeval"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -36,7 +37,8 @@ func (rs *localRegion) getGroupKey(ctx *selectContext) ([]byte, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		vals = append(vals, v)
+		// The strings that are equal in their collations are in the same group.
+		vals = append(vals, collate.KeyDatum(v, ctx.eval.ColumnCollator(item.Expr)))
 	}
 	bs, err := codec.EncodeValue(nil, vals...)
 	if err != nil {
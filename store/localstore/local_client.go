@@ -36,6 +36,10 @@ func (c *dbClient) Send(ctx goctx.Context, req *kv.Request) kv.Response {
 }
 
 func (c *dbClient) IsRequestTypeSupported(reqType, subType int64) bool {
+	switch subType {
+	case kv.ReqSubTypeRowFormatV2, kv.ReqSubTypeNewCollation, kv.ReqSubTypeElemsByName, kv.ReqSubTypeDescKey:
+		return true
+	}
 	switch reqType {
 	case kv.ReqTypeSelect, kv.ReqTypeIndex:
 		switch subType {
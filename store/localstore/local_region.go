@@ -16,6 +16,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -565,7 +566,8 @@ func (rs *localRegion) evalTopN(ctx *selectContext, handle int64, values map[int
 		if err != nil {
 			return errors.Trace(err)
 		}
-		newRow.key = append(newRow.key, result)
+		// The strings are sorted by the sort keys of their collations.
+		newRow.key = append(newRow.key, collate.KeyDatum(result, ctx.eval.ColumnCollator(item.Expr)))
 	}
 	if ctx.topnHeap.tryToAddRow(newRow) {
 		for _, col := range columns {
@@ -654,6 +656,7 @@ func (rs *localRegion) setColumnValueToCtx(ctx *selectContext, h int64, row map[
 				return errors.Trace(err)
 			}
 			ctx.eval.Row[colID] = datum
+			ctx.eval.RowFieldTypes[colID] = ft
 		}
 	}
 	return nil
@@ -769,6 +772,16 @@ func (rs *localRegion) getIndexRowFromRange(ctx *selectContext, ran kv.KeyRange,
 		if err1 != nil {
 			return 0, errors.Trace(err1)
 		}
+		if restored := tablecodec.CutIndexRestoredData(it.Value(), len(b) > 0); restored != nil {
+			// The index key has the sort keys of the values, the values are restored from the index value.
+			restoredValues, err1 := tablecodec.CutIndexRestoredValues(restored, len(ids))
+			if err1 != nil {
+				return 0, errors.Trace(err1)
+			}
+			for i, id := range ids {
+				values[id] = restoredValues[i]
+			}
+		}
 		var handle int64
 		if len(b) > 0 {
 			var handleDatum types.Datum
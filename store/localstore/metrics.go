@@ -0,0 +1,50 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localstore
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	writeHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "localstore",
+			Name:      "write_seconds",
+			Help:      "Bucketed histogram of the time of writing batches to the engine.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 18),
+		}, []string{"type"})
+
+	compactionHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "localstore",
+			Name:      "compaction_seconds",
+			Help:      "Bucketed histogram of the time of checking the recently updated keys for outdated versions.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+		})
+
+	compactionKeysCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb",
+			Subsystem: "localstore",
+			Name:      "compaction_keys_total",
+			Help:      "Counter of the keys checked and the outdated versions deleted by compaction.",
+		}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(writeHistogram)
+	prometheus.MustRegister(compactionHistogram)
+	prometheus.MustRegister(compactionKeysCounter)
+}
@@ -28,6 +28,7 @@ var (
 // dbTxn is not thread safe
 type dbTxn struct {
 	us         kv.UnionStore
+	snapshot   *dbSnapshot
 	store      *dbStore // for commit
 	tid        uint64
 	valid      bool
@@ -37,8 +38,10 @@ type dbTxn struct {
 }
 
 func newTxn(s *dbStore, ver kv.Version) *dbTxn {
+	snapshot := newSnapshot(s, ver)
 	txn := &dbTxn{
-		us:         kv.NewUnionStore(newSnapshot(s, ver)),
+		us:         kv.NewUnionStore(snapshot),
+		snapshot:   snapshot,
 		store:      s,
 		tid:        ver.Ver,
 		valid:      true,
@@ -78,6 +81,9 @@ func (txn *dbTxn) Delete(k kv.Key) error {
 
 func (txn *dbTxn) SetOption(opt kv.Option, val interface{}) {
 	txn.us.SetOption(opt, val)
+	if opt == kv.SnapshotTS {
+		txn.snapshot.version = kv.NewVersion(val.(uint64))
+	}
 }
 
 func (txn *dbTxn) DelOption(opt kv.Option) {
@@ -163,6 +169,10 @@ func (txn *dbTxn) Valid() bool {
 	return txn.valid
 }
 
+func (txn *dbTxn) BatchPrefetch(keys []kv.Key) error {
+	return txn.us.BatchPrefetch(keys)
+}
+
 func (txn *dbTxn) Size() int {
 	return txn.us.Size()
 }
@@ -0,0 +1,348 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storetest is the conformance test suite of kv.Storage implementations.
+//
+// A storage registers the suite in its tests with the function opening the store:
+//
+//	var _ = check.Suite(&storetest.Suite{NewStore: newTestStore})
+//
+// Every test writes the keys under its own prefix, so the store needn't be empty and the suite can share
+// the store with other tests.
+package storetest
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/terror"
+)
+
+// Suite is the conformance test suite of a kv.Storage.
+type Suite struct {
+	// NewStore opens the store to test, it is closed when the suite is done.
+	NewStore func() (kv.Storage, error)
+
+	store  kv.Storage
+	prefix string
+}
+
+// SetUpSuite implements check.SetUpSuite interface.
+func (s *Suite) SetUpSuite(c *check.C) {
+	store, err := s.NewStore()
+	c.Assert(err, check.IsNil)
+	s.store = store
+}
+
+// TearDownSuite implements check.TearDownSuite interface.
+func (s *Suite) TearDownSuite(c *check.C) {
+	c.Assert(s.store.Close(), check.IsNil)
+}
+
+// SetUpTest implements check.SetUpTest interface.
+func (s *Suite) SetUpTest(c *check.C) {
+	s.prefix = fmt.Sprintf("storetest_%s_%d_", c.TestName(), time.Now().UnixNano())
+}
+
+func (s *Suite) key(i int) kv.Key {
+	return kv.Key(fmt.Sprintf("%s%04d", s.prefix, i))
+}
+
+func value(i int) []byte {
+	return []byte(fmt.Sprintf("value_%d", i))
+}
+
+func (s *Suite) begin(c *check.C) kv.Transaction {
+	txn, err := s.store.Begin()
+	c.Assert(err, check.IsNil)
+	return txn
+}
+
+// mustSet sets the keys to their values in a transaction.
+func (s *Suite) mustSet(c *check.C, ids ...int) {
+	txn := s.begin(c)
+	for _, i := range ids {
+		c.Assert(txn.Set(s.key(i), value(i)), check.IsNil)
+	}
+	c.Assert(txn.Commit(), check.IsNil)
+}
+
+// checkScan checks the keys of the following entries of the iterator in the prefix of the test.
+func (s *Suite) checkScan(c *check.C, it kv.Iterator, ids ...int) {
+	defer it.Close()
+	var got []int
+	for it.Valid() && it.Key().HasPrefix(kv.Key(s.prefix)) {
+		i, err := strconv.Atoi(string(it.Key()[len(s.prefix):]))
+		c.Assert(err, check.IsNil)
+		c.Assert(it.Value(), check.DeepEquals, value(i))
+		got = append(got, i)
+		c.Assert(it.Next(), check.IsNil)
+	}
+	c.Assert(got, check.DeepEquals, ids)
+}
+
+// TestGetSet checks the values written in a transaction are read by itself and the later transactions.
+func (s *Suite) TestGetSet(c *check.C) {
+	txn := s.begin(c)
+	_, err := txn.Get(s.key(1))
+	c.Assert(kv.IsErrNotFound(err), check.IsTrue)
+	c.Assert(terror.ErrorEqual(txn.Set(s.key(1), nil), kv.ErrCannotSetNilValue), check.IsTrue)
+	c.Assert(txn.Set(s.key(1), value(1)), check.IsNil)
+	val, err := txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(1))
+	c.Assert(txn.Commit(), check.IsNil)
+
+	txn = s.begin(c)
+	val, err = txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(1))
+	// Overwrite the value.
+	c.Assert(txn.Set(s.key(1), value(2)), check.IsNil)
+	c.Assert(txn.Commit(), check.IsNil)
+
+	txn = s.begin(c)
+	val, err = txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(2))
+	c.Assert(txn.Rollback(), check.IsNil)
+}
+
+// TestLargeValue checks a value of a megabyte.
+func (s *Suite) TestLargeValue(c *check.C) {
+	val := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+	txn := s.begin(c)
+	c.Assert(txn.Set(s.key(1), val), check.IsNil)
+	c.Assert(txn.Commit(), check.IsNil)
+
+	txn = s.begin(c)
+	got, err := txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(bytes.Equal(got, val), check.IsTrue)
+	c.Assert(txn.Rollback(), check.IsNil)
+}
+
+// TestDelete checks the deleted keys are not read.
+func (s *Suite) TestDelete(c *check.C) {
+	s.mustSet(c, 1, 2)
+
+	txn := s.begin(c)
+	c.Assert(txn.Delete(s.key(1)), check.IsNil)
+	_, err := txn.Get(s.key(1))
+	c.Assert(kv.IsErrNotFound(err), check.IsTrue)
+	c.Assert(txn.Commit(), check.IsNil)
+
+	txn = s.begin(c)
+	_, err = txn.Get(s.key(1))
+	c.Assert(kv.IsErrNotFound(err), check.IsTrue)
+	val, err := txn.Get(s.key(2))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(2))
+	c.Assert(txn.Rollback(), check.IsNil)
+}
+
+// TestRollback checks the writes of a rolled back transaction are discarded.
+func (s *Suite) TestRollback(c *check.C) {
+	txn := s.begin(c)
+	c.Assert(txn.Set(s.key(1), value(1)), check.IsNil)
+	c.Assert(txn.Rollback(), check.IsNil)
+
+	txn = s.begin(c)
+	_, err := txn.Get(s.key(1))
+	c.Assert(kv.IsErrNotFound(err), check.IsTrue)
+	c.Assert(txn.Rollback(), check.IsNil)
+}
+
+// TestSeek checks the iterators return the keys in order, merging the uncommitted writes of the transaction.
+func (s *Suite) TestSeek(c *check.C) {
+	s.mustSet(c, 7, 3, 5, 1, 9)
+
+	txn := s.begin(c)
+	defer txn.Rollback()
+	it, err := txn.Seek(kv.Key(s.prefix))
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it, 1, 3, 5, 7, 9)
+	it, err = txn.Seek(s.key(4))
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it, 5, 7, 9)
+	it, err = txn.Seek(s.key(5))
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it, 5, 7, 9)
+	it, err = txn.Seek(s.key(10))
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it)
+
+	c.Assert(txn.Set(s.key(4), value(4)), check.IsNil)
+	c.Assert(txn.Delete(s.key(5)), check.IsNil)
+	c.Assert(txn.Set(s.key(10), value(10)), check.IsNil)
+	it, err = txn.Seek(s.key(2))
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it, 3, 4, 7, 9, 10)
+}
+
+// TestSeekManyKeys checks an iterator over more keys than a batch of the scanners.
+func (s *Suite) TestSeekManyKeys(c *check.C) {
+	const count = 1000
+	ids := make([]int, count)
+	txn := s.begin(c)
+	for i := range ids {
+		ids[i] = i
+		c.Assert(txn.Set(s.key(i), value(i)), check.IsNil)
+	}
+	c.Assert(txn.Commit(), check.IsNil)
+
+	snapshot, err := s.store.GetSnapshot(kv.MaxVersion)
+	c.Assert(err, check.IsNil)
+	it, err := snapshot.Seek(kv.Key(s.prefix))
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it, ids...)
+}
+
+// TestSeekReverse checks the reversed iterators. It is skipped if the storage doesn't implement them.
+func (s *Suite) TestSeekReverse(c *check.C) {
+	s.mustSet(c, 1, 3, 5)
+
+	txn := s.begin(c)
+	defer txn.Rollback()
+	c.Assert(txn.Set(s.key(4), value(4)), check.IsNil)
+	it, err := txn.SeekReverse(s.key(5))
+	if terror.ErrorEqual(err, kv.ErrNotImplemented) {
+		c.Skip("SeekReverse is not implemented")
+	}
+	c.Assert(err, check.IsNil)
+	s.checkScan(c, it, 4, 3, 1)
+}
+
+// TestBatchGet checks BatchGet returns the existing keys.
+func (s *Suite) TestBatchGet(c *check.C) {
+	s.mustSet(c, 1, 2, 3)
+
+	snapshot, err := s.store.GetSnapshot(kv.MaxVersion)
+	c.Assert(err, check.IsNil)
+	m, err := snapshot.BatchGet([]kv.Key{s.key(1), s.key(3), s.key(4)})
+	c.Assert(err, check.IsNil)
+	c.Assert(m, check.DeepEquals, map[string][]byte{
+		string(s.key(1)): value(1),
+		string(s.key(3)): value(3),
+	})
+}
+
+// TestSnapshotIsolation checks a transaction and a snapshot only read the data committed before them.
+func (s *Suite) TestSnapshotIsolation(c *check.C) {
+	s.mustSet(c, 1)
+	ver, err := s.store.CurrentVersion()
+	c.Assert(err, check.IsNil)
+	txn := s.begin(c)
+	defer txn.Rollback()
+
+	txn2 := s.begin(c)
+	c.Assert(txn2.Set(s.key(1), value(2)), check.IsNil)
+	c.Assert(txn2.Set(s.key(2), value(2)), check.IsNil)
+	c.Assert(txn2.Commit(), check.IsNil)
+	ver2, err := s.store.CurrentVersion()
+	c.Assert(err, check.IsNil)
+	c.Assert(ver2.Cmp(ver) > 0, check.IsTrue)
+
+	val, err := txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(1))
+	_, err = txn.Get(s.key(2))
+	c.Assert(kv.IsErrNotFound(err), check.IsTrue)
+
+	snapshot, err := s.store.GetSnapshot(ver)
+	c.Assert(err, check.IsNil)
+	val, err = snapshot.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(1))
+
+	snapshot, err = s.store.GetSnapshot(ver2)
+	c.Assert(err, check.IsNil)
+	val, err = snapshot.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(2))
+}
+
+// TestWriteConflict checks a transaction writing a key committed after its start fails.
+func (s *Suite) TestWriteConflict(c *check.C) {
+	s.mustSet(c, 1)
+
+	txn1 := s.begin(c)
+	txn2 := s.begin(c)
+	c.Assert(txn1.Set(s.key(1), value(2)), check.IsNil)
+	c.Assert(txn2.Set(s.key(1), value(3)), check.IsNil)
+	c.Assert(txn1.Commit(), check.IsNil)
+	c.Assert(txn2.Commit(), check.NotNil)
+
+	txn := s.begin(c)
+	val, err := txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(2))
+	c.Assert(txn.Rollback(), check.IsNil)
+}
+
+// TestLockKeys checks a transaction locking a key committed after its start fails.
+func (s *Suite) TestLockKeys(c *check.C) {
+	s.mustSet(c, 1, 2)
+
+	txn1 := s.begin(c)
+	c.Assert(txn1.LockKeys(s.key(1)), check.IsNil)
+	c.Assert(txn1.Set(s.key(2), value(3)), check.IsNil)
+	s.mustSet(c, 1)
+	c.Assert(txn1.Commit(), check.NotNil)
+
+	// The lock doesn't change the value.
+	txn := s.begin(c)
+	c.Assert(txn.LockKeys(s.key(1)), check.IsNil)
+	c.Assert(txn.Set(s.key(2), value(4)), check.IsNil)
+	c.Assert(txn.Commit(), check.IsNil)
+	txn = s.begin(c)
+	val, err := txn.Get(s.key(1))
+	c.Assert(err, check.IsNil)
+	c.Assert(val, check.DeepEquals, value(1))
+	c.Assert(txn.Rollback(), check.IsNil)
+}
+
+// TestConcurrentIncrement checks the concurrent transactions incrementing a counter by retrying on conflicts.
+func (s *Suite) TestConcurrentIncrement(c *check.C) {
+	const (
+		workers = 10
+		count   = 10
+	)
+	key := s.key(0)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				err := kv.RunInNewTxn(s.store, true, func(txn kv.Transaction) error {
+					_, err := kv.IncInt64(txn, key, 1)
+					return err
+				})
+				c.Check(err, check.IsNil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	txn := s.begin(c)
+	n, err := kv.GetInt64(txn, key)
+	c.Assert(err, check.IsNil)
+	c.Assert(n, check.Equals, int64(workers*count))
+	c.Assert(txn.Rollback(), check.IsNil)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/failpoint"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -67,7 +68,9 @@ type twoPhaseCommitter struct {
 	mutations map[string]*pb.Mutation
 	lockTTL   uint64
 	commitTS  uint64
-	mu        struct {
+	// prewritten are the mutations prewritten ahead while the transaction was executing.
+	prewritten map[string]*pb.Mutation
+	mu         struct {
 		sync.RWMutex
 		writtenKeys  [][]byte
 		committed    bool
@@ -87,20 +90,13 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 	)
 	mutations := make(map[string]*pb.Mutation)
 	err := txn.us.WalkBuffer(func(k kv.Key, v []byte) error {
-		if len(v) > 0 {
-			mutations[string(k)] = &pb.Mutation{
-				Op:    pb.Op_Put,
-				Key:   k,
-				Value: v,
-			}
+		mutation := newMutation(k, v)
+		if mutation.Op == pb.Op_Put {
 			putCnt++
 		} else {
-			mutations[string(k)] = &pb.Mutation{
-				Op:  pb.Op_Del,
-				Key: k,
-			}
 			delCnt++
 		}
+		mutations[string(k)] = mutation
 		keys = append(keys, k)
 		entrySize := len(k) + len(v)
 		if entrySize > kv.TxnEntrySizeLimit {
@@ -151,10 +147,39 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 	}, nil
 }
 
+// newMutation returns the mutation of a key in the transaction buffer, an empty value means the key is deleted.
+func newMutation(k kv.Key, v []byte) *pb.Mutation {
+	if len(v) > 0 {
+		return &pb.Mutation{
+			Op:    pb.Op_Put,
+			Key:   k,
+			Value: v,
+		}
+	}
+	return &pb.Mutation{
+		Op:  pb.Op_Del,
+		Key: k,
+	}
+}
+
 func (c *twoPhaseCommitter) primary() []byte {
 	return c.keys[0]
 }
 
+// keysToPrewrite returns the keys that haven't been prewritten ahead.
+func (c *twoPhaseCommitter) keysToPrewrite() [][]byte {
+	if len(c.prewritten) == 0 {
+		return c.keys
+	}
+	keys := make([][]byte, 0, len(c.keys))
+	for _, k := range c.keys {
+		if _, ok := c.prewritten[string(k)]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
 const bytesPerMiB = 1024 * 1024
 
 func txnLockTTL(startTime monotime.Time, txnSize int) uint64 {
@@ -339,6 +364,9 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 		},
 	}
 	for {
+		if _, ok := failpoint.Eval("tikv/prewriteError"); ok {
+			return errors.Errorf("injected prewrite error, tid: %d", c.startTS)
+		}
 		resp, err := c.store.SendReq(bo, req, batch.region, readTimeoutShort)
 		if err != nil {
 			return errors.Trace(err)
@@ -433,6 +461,10 @@ func (c *twoPhaseCommitter) commitSingleBatch(bo *Backoffer, batch batchKeys) er
 	isPrimary := bytes.Equal(batch.keys[0], c.primary())
 
 	resp, err := c.store.SendReq(bo, req, batch.region, readTimeoutShort)
+	if _, ok := failpoint.Eval("tikv/commitError"); ok && err == nil {
+		// The response is lost after the keys are committed.
+		err = errors.Errorf("injected commit error, tid: %d", c.startTS)
+	}
 	if err != nil {
 		if isPrimary {
 			// change the Cause of the error to be returned
@@ -553,7 +585,7 @@ func (c *twoPhaseCommitter) execute() error {
 
 	ctx := goctx.Background()
 	binlogChan := c.prewriteBinlog()
-	err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), c.keys)
+	err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), c.keysToPrewrite())
 	if binlogChan != nil {
 		binlogErr := <-binlogChan
 		if binlogErr != nil {
@@ -0,0 +1,64 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build failpoint
+
+package tikv
+
+import (
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/failpoint"
+)
+
+func (s *testCommitterSuite) TestFailPrewrite(c *C) {
+	defer failpoint.Disable("tikv/prewriteError")
+	failpoint.Enable("tikv/prewriteError", true)
+
+	txn := s.begin(c)
+	err := txn.Set([]byte("a"), []byte("a1"))
+	c.Assert(err, IsNil)
+	err = txn.Commit()
+	c.Assert(err, NotNil)
+
+	failpoint.Disable("tikv/prewriteError")
+	_, err = s.begin(c).Get([]byte("a"))
+	c.Assert(kv.IsErrNotFound(err), IsTrue)
+	s.mustCommit(c, map[string]string{"a": "a2"})
+}
+
+func (s *testCommitterSuite) TestFailCommitPrimary(c *C) {
+	defer failpoint.Disable("tikv/commitError")
+	failpoint.EnableN("tikv/commitError", true, 1)
+
+	// The primary key is committed, but the result is undetermined as the response is lost.
+	txn := s.begin(c)
+	err := txn.Set([]byte("a"), []byte("a1"))
+	c.Assert(err, IsNil)
+	err = txn.Commit()
+	c.Assert(errors.Cause(err), Equals, terror.ErrResultUndetermined)
+	s.checkValues(c, map[string]string{"a": "a1"})
+}
+
+func (s *testCommitterSuite) TestFailRegionError(c *C) {
+	defer failpoint.Disable("tikv/regionError")
+
+	// The requests are retried on the region errors.
+	for i, kind := range []string{"notLeader", "staleEpoch", "serverIsBusy"} {
+		failpoint.EnableN("tikv/regionError", kind, 2)
+		v := string('1' + rune(i))
+		s.mustCommit(c, map[string]string{"a": "a" + v, "b": "b" + v, "c": "c" + v})
+	}
+}
@@ -78,9 +78,16 @@ func (c *CopClient) supportExpr(exprType tipb.ExprType) bool {
 		tipb.ExprType_JsonObject, tipb.ExprType_JsonArray, tipb.ExprType_JsonMerge,
 		tipb.ExprType_JsonSet, tipb.ExprType_JsonInsert, tipb.ExprType_JsonReplace, tipb.ExprType_JsonRemove:
 		return true
+	// time functions, they are only evaluated by the mock store now.
+	case tipb.ExprType_Date, tipb.ExprType_Year, tipb.ExprType_YearWeek, tipb.ExprType_Month,
+		tipb.ExprType_Week, tipb.ExprType_Weekday, tipb.ExprType_WeekOfYear,
+		tipb.ExprType_Day, tipb.ExprType_DayName, tipb.ExprType_DayOfYear, tipb.ExprType_DayOfMonth, tipb.ExprType_DayOfWeek,
+		tipb.ExprType_Hour, tipb.ExprType_Minute, tipb.ExprType_Second, tipb.ExprType_Microsecond:
+		return c.store.mock
 	case kv.ReqSubTypeDesc:
 		return true
-	case kv.ReqSubTypeSignature:
+	case kv.ReqSubTypeSignature, kv.ReqSubTypeRowFormatV2, kv.ReqSubTypeNewCollation, kv.ReqSubTypeElemsByName,
+		kv.ReqSubTypeDescKey:
 		return c.store.mock
 	default:
 		return false
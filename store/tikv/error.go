@@ -24,6 +24,10 @@ var (
 	errInvalidResponse = errors.New("invalid response")
 	// errBodyMissing response body is missing error
 	errBodyMissing = errors.New("response body is missing")
+	// errSplitRegionNotSupported means the regions can't be split and scattered on demand by the servers.
+	errSplitRegionNotSupported = errors.New("splitting and scattering regions are not supported by the tikv and pd servers")
+	// errPrewrittenAheadChanged means a row is written again after it's prewritten ahead by tidb_pipelined_prewrite.
+	errPrewrittenAheadChanged = errors.New("the row is written again after it's prewritten ahead, the transaction is rolled back")
 )
 
 // TiDB decides whether to retry transaction by checking if error message contains
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
@@ -261,6 +262,17 @@ func (w *GCWorker) calculateNewSafePoint(now time.Time) (*time.Time, error) {
 		return nil, errors.Trace(err)
 	}
 	safePoint := now.Add(-*lifeTime)
+	minPinnedTS, err := w.loadMinPinnedSnapshot(oracle.ComposeTS(oracle.GetPhysical(now), 0))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if minPinnedTS != 0 {
+		pinned := time.Unix(0, oracle.ExtractPhysical(minPinnedTS)*int64(time.Millisecond))
+		if pinned.Before(safePoint) {
+			log.Infof("[gc worker] %s the safe point is held back to %v by the pinned snapshot %d", w.uuid, pinned, minPinnedTS)
+			safePoint = pinned
+		}
+	}
 	// We should never decrease safePoint.
 	if lastSafePoint != nil && safePoint.Before(*lastSafePoint) {
 		return nil, nil
@@ -659,6 +671,30 @@ func (w *GCWorker) loadValueFromSysTable(key string) (string, error) {
 	return value, nil
 }
 
+// loadMinPinnedSnapshot returns the min timestamp of the snapshots pinned by 'admin pin snapshot' that haven't
+// expired at now, it returns 0 if there is none. The expired pins are deleted.
+func (w *GCWorker) loadMinPinnedSnapshot(now uint64) (uint64, error) {
+	session := createSession(w.store)
+	defer session.Close()
+
+	_, err := session.Execute(fmt.Sprintf(`DELETE FROM mysql.%s WHERE expire_ts <= %d`, mysql.GCSnapshotPinsTable, now))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	rs, err := session.Execute(fmt.Sprintf(`SELECT min(ts) FROM mysql.%s`, mysql.GCSnapshotPinsTable))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	row, err := rs[0].Next()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if row == nil || row.Data[0].IsNull() {
+		return 0, nil
+	}
+	return row.Data[0].GetUint64(), nil
+}
+
 func (w *GCWorker) saveValueToSysTable(key, value string) error {
 	session := createSession(w.store)
 	defer session.Close()
@@ -14,11 +14,13 @@
 package tikv
 
 import (
+	"fmt"
 	"math"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 )
 
 type testGCWorkerSuite struct {
@@ -102,3 +104,30 @@ func (s *testGCWorkerSuite) TestPrepareGC(c *C) {
 	c.Assert(err, IsNil)
 	s.timeEqual(c, safePoint.Add(time.Minute*30), now, 2*time.Second)
 }
+
+func (s *testGCWorkerSuite) TestPinnedSnapshot(c *C) {
+	now, err := s.gcWorker.getOracleTime()
+	c.Assert(err, IsNil)
+	pinned := now.Add(-time.Hour)
+	pinnedTS := oracle.ComposeTS(oracle.GetPhysical(pinned), 0)
+	expireTS := oracle.ComposeTS(oracle.GetPhysical(now.Add(time.Hour)), 0)
+	se := createSession(s.store)
+	defer se.Close()
+	_, err = se.Execute(fmt.Sprintf("INSERT INTO mysql.gc_snapshot_pins VALUES (%d, %d)", pinnedTS, expireTS))
+	c.Assert(err, IsNil)
+
+	// The safe point is held back by the pinned snapshot.
+	safePoint, err := s.gcWorker.calculateNewSafePoint(now)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, NotNil)
+	s.timeEqual(c, *safePoint, pinned, time.Millisecond)
+
+	// The pin is deleted after it expires.
+	safePoint, err = s.gcWorker.calculateNewSafePoint(now.Add(2 * time.Hour))
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, NotNil)
+	s.timeEqual(c, *safePoint, now.Add(2*time.Hour-gcDefaultLifeTime), time.Millisecond)
+	minPinnedTS, err := s.gcWorker.loadMinPinnedSnapshot(oracle.ComposeTS(oracle.GetPhysical(now), 0))
+	c.Assert(err, IsNil)
+	c.Assert(minPinnedTS, Equals, uint64(0))
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -45,12 +46,12 @@ type Driver struct {
 }
 
 // Open opens or creates an TiKV storage with given path.
-// Path example: tikv://etcd-node1:port,etcd-node2:port?cluster=1&disableGC=false
+// Path example: tikv://etcd-node1:port,etcd-node2:port?cluster=1&disableGC=false&tso=pd&tsoBatch=16
 func (d Driver) Open(path string) (kv.Storage, error) {
 	mc.Lock()
 	defer mc.Unlock()
 
-	etcdAddrs, disableGC, err := parsePath(path)
+	etcdAddrs, opts, err := parsePath(path)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -69,10 +70,22 @@ func (d Driver) Open(path string) (kv.Storage, error) {
 		return store, nil
 	}
 
-	s, err := newTikvStore(uuid, &codecPDClient{pdCli}, newRPCClient(), !disableGC)
+	s, err := newTikvStore(uuid, &codecPDClient{pdCli}, newRPCClient(), !opts.disableGC)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if opts.localTSO {
+		err = s.useLocalOracle()
+	} else {
+		err = s.waitLocalTSO()
+	}
+	if err != nil {
+		s.Close()
+		return nil, errors.Trace(err)
+	}
+	if opts.tsoBatchSize > 1 {
+		s.oracle = oracles.NewBatchOracle(s.oracle, opts.tsoBatchSize, tsoBatchMaxAge)
+	}
 	s.etcdAddrs = etcdAddrs
 	mc.cache[uuid] = s
 	return s, nil
@@ -97,6 +110,9 @@ func (d MockDriver) Open(path string) (kv.Storage, error) {
 // update oracle's lastTS every 2000ms.
 var oracleUpdateInterval = 2000
 
+// tsoBatchMaxAge is how long the start timestamps fetched in a batch are served.
+var tsoBatchMaxAge = 5 * time.Millisecond
+
 type tikvStore struct {
 	clusterID    uint64
 	uuid         string
@@ -109,6 +125,10 @@ type tikvStore struct {
 	etcdAddrs    []string
 	mock         bool
 	enableGC     bool
+	// mockCluster is the cluster of the mock tikv store, the regions are split and scattered in it.
+	mockCluster *mocktikv.Cluster
+	// localTSO is set when the timestamps are allocated locally.
+	localTSO *localTSO
 }
 
 func newTikvStore(uuid string, pdClient pd.Client, client Client, enableGC bool) (*tikvStore, error) {
@@ -229,7 +249,12 @@ func NewMockTikvStore(options ...MockTiKVStoreOption) (kv.Storage, error) {
 		pdCli = opt.pdClientHijack(pdCli)
 	}
 
-	return newTikvStore(uuid, pdCli, client, false)
+	store, err := newTikvStore(uuid, pdCli, client, false)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store.mockCluster = cluster
+	return store, nil
 }
 
 func (s *tikvStore) Begin() (kv.Transaction, error) {
@@ -262,6 +287,9 @@ func (s *tikvStore) Close() error {
 	defer mc.Unlock()
 
 	delete(mc.cache, s.uuid)
+	if s.localTSO != nil {
+		s.localTSO.close()
+	}
 	s.oracle.Close()
 	s.pdClient.Close()
 	if s.gcWorker != nil {
@@ -333,7 +361,16 @@ func ParseEtcdAddr(path string) (etcdAddrs []string, err error) {
 	return
 }
 
-func parsePath(path string) (etcdAddrs []string, disableGC bool, err error) {
+// pathOptions are the options in the query of the tikv path.
+type pathOptions struct {
+	disableGC bool
+	// localTSO is set to allocate the timestamps by the local clock instead of PD.
+	localTSO bool
+	// tsoBatchSize is the number of the start timestamps fetched from PD in a batch.
+	tsoBatchSize int
+}
+
+func parsePath(path string) (etcdAddrs []string, opts pathOptions, err error) {
 	var u *url.URL
 	u, err = url.Parse(path)
 	if err != nil {
@@ -347,12 +384,31 @@ func parsePath(path string) (etcdAddrs []string, disableGC bool, err error) {
 	}
 	switch strings.ToLower(u.Query().Get("disableGC")) {
 	case "true":
-		disableGC = true
+		opts.disableGC = true
 	case "false", "":
 	default:
 		err = errors.New("disableGC flag should be true/false")
 		return
 	}
+	switch strings.ToLower(u.Query().Get("tso")) {
+	case "local":
+		opts.localTSO = true
+	case "pd", "":
+	default:
+		err = errors.New("tso flag should be pd/local")
+		return
+	}
+	if batch := u.Query().Get("tsoBatch"); batch != "" {
+		opts.tsoBatchSize, err = strconv.Atoi(batch)
+		if err != nil || opts.tsoBatchSize <= 0 {
+			err = errors.New("tsoBatch flag should be a positive integer")
+			return
+		}
+		if opts.localTSO && opts.tsoBatchSize > 1 {
+			err = errors.New("tsoBatch flag is only for tso=pd")
+			return
+		}
+	}
 	etcdAddrs = strings.Split(u.Host, ",")
 	return
 }
@@ -0,0 +1,209 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/store/tikv/oracle/oracles"
+	goctx "golang.org/x/net/context"
+)
+
+// The store which allocates the timestamps locally is recorded in the meta data. The record has a lease renewed in
+// the background, its MaxTS is ahead of the local timestamps by the lease. So the store can't be opened by another
+// tidb-server while the timestamps are allocated locally, and when it's opened with tso=pd again, it waits for
+// the timestamps of PD to pass the local ones.
+
+var (
+	// localTSOLease is the lease of the local TSO record.
+	localTSOLease = 10 * time.Second
+	// localTSOMaxWait is the longest time to wait for the timestamps of PD to pass the local ones.
+	localTSOMaxWait = time.Minute
+)
+
+var (
+	errLocalTSOWithServers = errors.New("tso=local is only for a single tidb-server, but other tidb-servers are running")
+	errLocalTSOInUse       = errors.New("the timestamps are allocated locally by another tidb-server with tso=local")
+)
+
+// localTSO renews the local TSO record of the store until it's closed.
+type localTSO struct {
+	store *tikvStore
+	owner string
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// leaseTS returns the timestamp which is ahead of ts by the lease.
+func leaseTS(ts uint64) uint64 {
+	return oracle.ComposeTS(oracle.ExtractPhysical(ts)+int64(localTSOLease/time.Millisecond), 0)
+}
+
+// useLocalOracle replaces the PD oracle with a local oracle, so the timestamps are allocated by the local clock
+// without the round trips to PD. It's only correct if the store is used by a single tidb-server, so it fails if
+// other tidb-servers are registered or the timestamps are allocated locally by another store. The local
+// timestamps start from a timestamp of PD, and they are greater than the ones allocated locally before.
+func (s *tikvStore) useLocalOracle() error {
+	bo := NewBackoffer(tsoMaxBackoff, goctx.Background())
+	pdTS, err := s.getTimestampWithRetry(bo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	owner := fmt.Sprintf("%s-%d", s.uuid, pdTS)
+	var lastMaxTS uint64
+	err = kv.RunInNewTxn(s, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		infos, err1 := t.ListServerInfos()
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		now := oracle.ExtractPhysical(txn.StartTS())
+		for _, info := range infos {
+			if info.Expire > now {
+				return errLocalTSOWithServers
+			}
+		}
+		record, err1 := t.GetLocalTSO()
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if record != nil {
+			if !record.Closed && record.MaxTS > txn.StartTS() {
+				return errLocalTSOInUse
+			}
+			lastMaxTS = record.MaxTS
+		}
+		return t.SetLocalTSO(&model.LocalTSOInfo{Owner: owner, MaxTS: leaseTS(txn.StartTS())})
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The timestamp is fetched after the record is committed, so it's greater than the commit timestamp.
+	startTS, err := s.getTimestampWithRetry(bo)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if startTS < lastMaxTS {
+		startTS = lastMaxTS
+	}
+	s.oracle.Close()
+	s.oracle = oracles.NewLocalOracleFrom(startTS)
+	s.localTSO = &localTSO{
+		store: s,
+		owner: owner,
+		stop:  make(chan struct{}),
+	}
+	s.localTSO.wg.Add(1)
+	go s.localTSO.renewLoop()
+	log.Infof("[kv] use the local timestamp oracle from %d", startTS)
+	return nil
+}
+
+// waitLocalTSO waits for the timestamps of PD to pass the ones allocated locally before, it's called when the store
+// is opened with tso=pd. The record read by the timestamp of PD may be stale, so it's read again after the timestamps
+// pass its MaxTS, and it fails if the record is renewed, i.e. the timestamps are still allocated locally.
+func (s *tikvStore) waitLocalTSO() error {
+	deadline := time.Now().Add(localTSOMaxWait)
+	var lastMaxTS uint64
+	for {
+		var wait time.Duration
+		err := kv.RunInNewTxn(s, true, func(txn kv.Transaction) error {
+			t := meta.NewMeta(txn)
+			record, err1 := t.GetLocalTSO()
+			if err1 != nil || record == nil {
+				return errors.Trace(err1)
+			}
+			if record.MaxTS < txn.StartTS() {
+				return t.RemoveLocalTSO()
+			}
+			if lastMaxTS != 0 && record.MaxTS > lastMaxTS && !record.Closed {
+				return errLocalTSOInUse
+			}
+			lastMaxTS = record.MaxTS
+			wait = time.Duration(oracle.ExtractPhysical(record.MaxTS)-oracle.ExtractPhysical(txn.StartTS())+1) * time.Millisecond
+			return nil
+		})
+		if err != nil || wait == 0 {
+			return errors.Trace(err)
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return errors.Errorf("the timestamps of PD are %v behind the ones allocated locally before", wait)
+		}
+		log.Infof("[kv] wait %v for the timestamps of PD to pass the ones allocated locally", wait)
+		time.Sleep(wait)
+	}
+}
+
+// renew renews the lease of the local TSO record.
+func (l *localTSO) renew() error {
+	err := kv.RunInNewTxn(l.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		record, err := t.GetLocalTSO()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if record == nil || record.Owner != l.owner {
+			return errors.New("the local TSO record is removed by another tidb-server")
+		}
+		record.MaxTS = leaseTS(txn.StartTS())
+		return t.SetLocalTSO(record)
+	})
+	return errors.Trace(err)
+}
+
+func (l *localTSO) renewLoop() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(localTSOLease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			if err := l.renew(); err != nil {
+				log.Errorf("[kv] renew the local TSO record error: %v", errors.ErrorStack(err))
+			}
+		}
+	}
+}
+
+// close stops renewing the lease and marks the record closed, so the store can be opened with tso=pd after the
+// timestamps of PD pass the local ones, or by another tidb-server with tso=local.
+func (l *localTSO) close() {
+	close(l.stop)
+	l.wg.Wait()
+	err := kv.RunInNewTxn(l.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		record, err := t.GetLocalTSO()
+		if err != nil || record == nil || record.Owner != l.owner {
+			return errors.Trace(err)
+		}
+		record.MaxTS = leaseTS(txn.StartTS())
+		record.Closed = true
+		return t.SetLocalTSO(record)
+	})
+	if err != nil {
+		log.Errorf("[kv] close the local TSO record error: %v", errors.ErrorStack(err))
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/tablecodec"
 	goctx "golang.org/x/net/context"
 )
@@ -40,14 +41,17 @@ type Cluster struct {
 	id      uint64
 	stores  map[uint64]*Store
 	regions map[uint64]*Region
+	// placementRules are the groups of the placement rules, they're kept but don't place the peers.
+	placementRules map[string][]*kv.PlacementRule
 }
 
 // NewCluster creates an empty cluster. It needs to be bootstrapped before
 // providing service.
 func NewCluster() *Cluster {
 	return &Cluster{
-		stores:  make(map[uint64]*Store),
-		regions: make(map[uint64]*Region),
+		stores:         make(map[uint64]*Store),
+		regions:        make(map[uint64]*Region),
+		placementRules: make(map[string][]*kv.PlacementRule),
 	}
 }
 
@@ -266,6 +270,26 @@ func (c *Cluster) ChangeLeader(regionID, leaderStoreID uint64) {
 	c.regions[regionID].changeLeader(leaderStoreID)
 }
 
+// SetPlacementRules replaces the placement rules of the group, the group is removed if rules is empty.
+func (c *Cluster) SetPlacementRules(group string, rules []*kv.PlacementRule) {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(rules) == 0 {
+		delete(c.placementRules, group)
+		return
+	}
+	c.placementRules[group] = rules
+}
+
+// GetPlacementRules returns the placement rules of the group.
+func (c *Cluster) GetPlacementRules(group string) []*kv.PlacementRule {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.placementRules[group]
+}
+
 // GiveUpLeader sets the Region's leader to 0. The Region will have no leader
 // before calling ChangeLeader().
 func (c *Cluster) GiveUpLeader(regionID uint64) {
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 )
@@ -220,6 +221,13 @@ func (e *indexScanExec) getRowFromRange(ran kv.KeyRange) (int64, [][]byte, error
 	}
 
 	values, b, err := tablecodec.CutIndexKeyNew(pair.Key, e.colsLen)
+	if restored := tablecodec.CutIndexRestoredData(pair.Value, len(b) > 0); restored != nil {
+		// The index key has the sort keys of the values, the values are restored from the index value.
+		values, err = tablecodec.CutIndexRestoredValues(restored, e.colsLen)
+		if err != nil {
+			return 0, nil, errors.Trace(err)
+		}
+	}
 	var handle int64
 	if len(b) > 0 {
 		var handleDatum types.Datum
@@ -387,25 +395,27 @@ func (e *aggregateExec) getGroupKey() ([]byte, [][]byte, error) {
 	if length == 0 {
 		return nil, nil, nil
 	}
-	bufLen := 0
-	vals := make([]types.Datum, 0, length)
+	var buf []byte
 	row := make([][]byte, 0, length)
 	for _, item := range e.groupByExprs {
 		v, err := item.Eval(e.row)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
-		vals = append(vals, v)
 		b, err := codec.EncodeValue(nil, v)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
-		bufLen += len(b)
 		row = append(row, b)
-	}
-	buf := make([]byte, 0, bufLen)
-	for _, col := range row {
-		buf = append(buf, col...)
+		// The strings that are equal in their collations are in the same group.
+		if collator := collate.GetCollatorByFieldType(item.GetType()); collator != nil {
+			buf, err = codec.EncodeValue(buf, collate.KeyDatum(v, collator))
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+		} else {
+			buf = append(buf, b...)
+		}
 	}
 	return buf, row, nil
 }
@@ -499,10 +509,12 @@ func (e *topNExec) evalTopN(handle int64, value [][]byte) error {
 		return errors.Trace(err)
 	}
 	for i, expr := range e.orderByExprs {
-		newRow.key[i], err = expr.Eval(e.row)
+		v, err := expr.Eval(e.row)
 		if err != nil {
 			return errors.Trace(err)
 		}
+		// The strings are sorted by the sort keys of their collations.
+		newRow.key[i] = collate.KeyDatum(v, collate.GetCollatorByFieldType(expr.GetType()))
 	}
 
 	if e.heap.tryToAddRow(newRow) {
@@ -34,6 +34,9 @@ type Future interface {
 
 const physicalShiftBits = 18
 
+// MaxLogical is the upper bound of the logical part of a ts.
+const MaxLogical = 1 << physicalShiftBits
+
 // ComposeTS creates a ts from physical and logical parts.
 func ComposeTS(physical, logical int64) uint64 {
 	return uint64((physical << physicalShiftBits) + logical)
@@ -44,6 +47,11 @@ func ExtractPhysical(ts uint64) int64 {
 	return int64(ts >> physicalShiftBits)
 }
 
+// ExtractLogical returns a ts's logical part.
+func ExtractLogical(ts uint64) int64 {
+	return int64(ts & (MaxLogical - 1))
+}
+
 // GetPhysical returns physical from an instant time with millisecond precision.
 func GetPhysical(t time.Time) int64 {
 	return t.UnixNano() / int64(time.Millisecond)
@@ -0,0 +1,137 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracles
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	goctx "golang.org/x/net/context"
+)
+
+var _ oracle.Oracle = &batchOracle{}
+
+// batchOracle wraps an Oracle to serve the start timestamps of the transactions from a local buffer. The
+// timestamps of a batch are requested together, they are merged into one round trip by the PD client, and the
+// concurrent sessions take them from the buffer instead of waiting for their own round trips.
+//
+// A buffered timestamp may be a little older than the begin of the transaction, so a transaction may not see
+// the data committed by the other tidb-servers in the last maxAge. The data committed by this tidb-server is
+// always seen: GetTimestamp, which allocates the commit timestamps, never serves from the buffer, and the
+// buffered timestamps not greater than the last one it returns are discarded.
+type batchOracle struct {
+	oracle.Oracle
+	size   int
+	maxAge time.Duration
+
+	mu struct {
+		sync.Mutex
+		buf       []uint64
+		fetchTime time.Time
+		// minTS is the last timestamp returned by GetTimestamp, the buffered timestamps must be greater than it.
+		minTS uint64
+	}
+}
+
+// NewBatchOracle creates an Oracle that fetches the start timestamps from o in batches of size, the fetched
+// timestamps are served for maxAge at most.
+func NewBatchOracle(o oracle.Oracle, size int, maxAge time.Duration) oracle.Oracle {
+	return &batchOracle{
+		Oracle: o,
+		size:   size,
+		maxAge: maxAge,
+	}
+}
+
+// GetTimestamp gets a new timestamp from the wrapped Oracle, it's used for the commit timestamps.
+func (b *batchOracle) GetTimestamp(ctx goctx.Context) (uint64, error) {
+	ts, err := b.Oracle.GetTimestamp(ctx)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	b.mu.Lock()
+	if ts > b.mu.minTS {
+		b.mu.minTS = ts
+	}
+	b.mu.Unlock()
+	return ts, nil
+}
+
+// GetTimestampAsync gets a timestamp from the buffer, it's used for the start timestamps.
+func (b *batchOracle) GetTimestampAsync(ctx goctx.Context) oracle.Future {
+	return &batchFuture{ctx: ctx, b: b}
+}
+
+type batchFuture struct {
+	ctx goctx.Context
+	b   *batchOracle
+}
+
+// Wait implements the oracle.Future interface.
+func (f *batchFuture) Wait() (uint64, error) {
+	return f.b.getBufferedTimestamp(f.ctx)
+}
+
+func (b *batchOracle) getBufferedTimestamp(ctx goctx.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ts, ok := b.popTimestamp(); ok {
+		return ts, nil
+	}
+	// The lock is held during the fetch, the concurrent sessions wait for the batch instead of fetching their own.
+	fetchTime := time.Now()
+	futures := make([]oracle.Future, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		futures = append(futures, b.Oracle.GetTimestampAsync(ctx))
+	}
+	buf := make([]uint64, 0, b.size)
+	for _, future := range futures {
+		ts, err := future.Wait()
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		buf = append(buf, ts)
+	}
+	sort.Sort(uint64Slice(buf))
+	b.mu.buf, b.mu.fetchTime = buf, fetchTime
+	if ts, ok := b.popTimestamp(); ok {
+		return ts, nil
+	}
+	// All the fetched timestamps are older than a commit timestamp allocated during the fetch.
+	return b.Oracle.GetTimestamp(ctx)
+}
+
+// popTimestamp pops the smallest valid timestamp in the buffer, the caller should hold the lock.
+func (b *batchOracle) popTimestamp() (uint64, bool) {
+	if time.Since(b.mu.fetchTime) > b.maxAge {
+		b.mu.buf = nil
+	}
+	for len(b.mu.buf) > 0 {
+		ts := b.mu.buf[0]
+		b.mu.buf = b.mu.buf[1:]
+		if ts > b.mu.minTS {
+			return ts, true
+		}
+	}
+	return 0, false
+}
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,124 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracles
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"golang.org/x/net/context"
+)
+
+// countOracle counts the asynchronous timestamp requests to the wrapped Oracle.
+type countOracle struct {
+	oracle.Oracle
+	asyncCount int32
+}
+
+func (o *countOracle) GetTimestampAsync(ctx context.Context) oracle.Future {
+	atomic.AddInt32(&o.asyncCount, 1)
+	return o.Oracle.GetTimestampAsync(ctx)
+}
+
+func TestBatchOracle(t *testing.T) {
+	inner := &countOracle{Oracle: NewLocalOracle()}
+	b := NewBatchOracle(inner, 4, time.Minute)
+	defer b.Close()
+	ctx := context.Background()
+
+	var lastTS uint64
+	for i := 0; i < 4; i++ {
+		ts, err := b.GetTimestampAsync(ctx).Wait()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts <= lastTS {
+			t.Fatalf("ts %d isn't greater than %d", ts, lastTS)
+		}
+		lastTS = ts
+	}
+	if inner.asyncCount != 4 {
+		t.Fatalf("expect 4 timestamps fetched in a batch, got %d", inner.asyncCount)
+	}
+
+	// The buffered timestamps older than a commit timestamp are discarded.
+	_, err := b.GetTimestampAsync(ctx).Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitTS, err := b.GetTimestamp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := b.GetTimestampAsync(ctx).Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts <= commitTS {
+		t.Fatalf("start ts %d isn't greater than the commit ts %d", ts, commitTS)
+	}
+	if inner.asyncCount != 12 {
+		t.Fatalf("expect 3 batches fetched, got %d timestamps", inner.asyncCount)
+	}
+}
+
+func TestBatchOracleMaxAge(t *testing.T) {
+	inner := &countOracle{Oracle: NewLocalOracle()}
+	b := NewBatchOracle(inner, 4, 10*time.Millisecond)
+	defer b.Close()
+	ctx := context.Background()
+	if _, err := b.GetTimestampAsync(ctx).Wait(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := b.GetTimestampAsync(ctx).Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if inner.asyncCount != 8 {
+		t.Fatalf("expect the expired batch to be refetched, got %d timestamps", inner.asyncCount)
+	}
+}
+
+func TestBatchOracleConcurrent(t *testing.T) {
+	b := NewBatchOracle(NewLocalOracle(), 8, time.Minute)
+	defer b.Close()
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	m := make(map[uint64]struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ts, err := b.GetTimestampAsync(context.Background()).Wait()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				m[ts] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(m) != 1000 {
+		t.Errorf("generated same ts")
+	}
+}
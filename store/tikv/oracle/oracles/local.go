@@ -17,16 +17,22 @@ import (
 	"sync"
 	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	goctx "golang.org/x/net/context"
 )
 
 var _ oracle.Oracle = &localOracle{}
 
+// localOracle allocates the timestamps by the local clock. The timestamps are strictly ascending even if the
+// clock moves backward: the logical part is increased until the clock catches up, and the physical part is
+// borrowed from the future when the logical part is used up.
 type localOracle struct {
 	sync.Mutex
-	lastTimeStampTS uint64
-	n               uint64
+	lastPhysical int64
+	lastLogical  int64
+	// clockBackward is set when the clock is found behind the last timestamp, it's used to log the skew once.
+	clockBackward bool
 }
 
 // NewLocalOracle creates an Oracle that uses local time as data source.
@@ -34,6 +40,15 @@ func NewLocalOracle() oracle.Oracle {
 	return &localOracle{}
 }
 
+// NewLocalOracleFrom creates an Oracle that uses local time as data source, the timestamps are greater than
+// startTS, so they are still ascending after a restart with the clock behind the last allocated timestamp.
+func NewLocalOracleFrom(startTS uint64) oracle.Oracle {
+	return &localOracle{
+		lastPhysical: oracle.ExtractPhysical(startTS),
+		lastLogical:  oracle.ExtractLogical(startTS),
+	}
+}
+
 func (l *localOracle) IsExpired(lockTS uint64, TTL uint64) bool {
 	return oracle.GetPhysical(time.Now()) >= oracle.ExtractPhysical(lockTS)+int64(TTL)
 }
@@ -42,14 +57,21 @@ func (l *localOracle) GetTimestamp(goctx.Context) (uint64, error) {
 	l.Lock()
 	defer l.Unlock()
 	physical := oracle.GetPhysical(time.Now())
-	ts := oracle.ComposeTS(physical, 0)
-	if l.lastTimeStampTS == ts {
-		l.n++
-		return uint64(ts + l.n), nil
+	if physical > l.lastPhysical {
+		l.lastPhysical, l.lastLogical = physical, 0
+		l.clockBackward = false
+		return oracle.ComposeTS(l.lastPhysical, l.lastLogical), nil
+	}
+	if physical < l.lastPhysical && !l.clockBackward {
+		log.Warnf("[oracle] the local clock is %dms behind the last timestamp", l.lastPhysical-physical)
+		l.clockBackward = true
+	}
+	l.lastLogical++
+	if l.lastLogical >= oracle.MaxLogical {
+		l.lastPhysical++
+		l.lastLogical = 0
 	}
-	l.lastTimeStampTS = ts
-	l.n = 0
-	return uint64(ts), nil
+	return oracle.ComposeTS(l.lastPhysical, l.lastLogical), nil
 }
 
 func (l *localOracle) GetTimestampAsync(ctx goctx.Context) oracle.Future {
@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pingcap/tidb/store/tikv/oracle"
 	"golang.org/x/net/context"
 )
 
@@ -51,3 +52,36 @@ func TestIsExpired(t *testing.T) {
 		t.Error("should not expired")
 	}
 }
+
+func TestLocalOracleClockBackward(t *testing.T) {
+	// The clock is an hour behind the start timestamp.
+	physical := oracle.GetPhysical(time.Now().Add(time.Hour))
+	startTS := oracle.ComposeTS(physical, oracle.MaxLogical-2)
+	l := NewLocalOracleFrom(startTS)
+	defer l.Close()
+	expected := []uint64{
+		oracle.ComposeTS(physical, oracle.MaxLogical-1),
+		oracle.ComposeTS(physical+1, 0),
+		oracle.ComposeTS(physical+1, 1),
+	}
+	for _, exp := range expected {
+		ts, err := l.GetTimestamp(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts != exp {
+			t.Fatalf("expect ts %d, got %d", exp, ts)
+		}
+	}
+
+	// The clock is ahead of the start timestamp.
+	startTS = oracle.ComposeTS(oracle.GetPhysical(time.Now().Add(-time.Hour)), 10)
+	l = NewLocalOracleFrom(startTS)
+	ts, err := l.GetTimestamp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oracle.ExtractPhysical(ts) <= oracle.ExtractPhysical(startTS) || oracle.ExtractLogical(ts) != 0 {
+		t.Fatalf("expect ts of the local clock, got %d", ts)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"bytes"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/kv"
+	goctx "golang.org/x/net/context"
+)
+
+var _ kv.PipelinedTransaction = (*tikvTxn)(nil)
+
+// prewritePipeline prewrites the mutations of a transaction in the background while the transaction is executing.
+// The prewrites run one at a time, each prewrites the mutations buffered since the last one. The locks are held
+// until the transaction commits, so they are written with the max TTL, and the primary key is the first key of
+// the first prewrite.
+type prewritePipeline struct {
+	committer *twoPhaseCommitter
+	// dirty are the keys written since the last prewrite, the next prewrite only looks them up in the buffer.
+	dirty []kv.Key
+	// done is closed when the running prewrite finishes.
+	done chan struct{}
+	// err is the error of the prewrites, no more prewrite is started after an error.
+	err error
+}
+
+// PrewriteAhead implements the kv.PipelinedTransaction interface.
+func (txn *tikvTxn) PrewriteAhead() {
+	if !txn.valid {
+		return
+	}
+	p := txn.pipeline
+	first := p == nil
+	if first {
+		p = &prewritePipeline{
+			committer: &twoPhaseCommitter{
+				store:     txn.store,
+				txn:       txn,
+				startTS:   txn.StartTS(),
+				mutations: make(map[string]*pb.Mutation),
+				lockTTL:   maxLockTTL,
+				priority:  getTxnPriority(txn),
+			},
+		}
+		txn.pipeline = p
+	}
+	if p.wait() != nil {
+		return
+	}
+	c := p.committer
+	var keys [][]byte
+	// The keys locked by SELECT ... FOR UPDATE are usually written later, they are only prewritten on commit.
+	addMutation := func(k kv.Key, v []byte) error {
+		if _, ok := c.mutations[string(k)]; ok {
+			return nil
+		}
+		if len(k)+len(v) > kv.TxnEntrySizeLimit {
+			return kv.ErrEntryTooLarge
+		}
+		c.mutations[string(k)] = newMutation(k, v)
+		keys = append(keys, k)
+		return nil
+	}
+	var err error
+	if first {
+		err = txn.us.WalkBuffer(addMutation)
+	} else {
+		for _, k := range p.dirty {
+			var v []byte
+			if v, err = txn.us.GetMemBuffer().Get(k); err != nil {
+				break
+			}
+			if err = addMutation(k, v); err != nil {
+				break
+			}
+		}
+	}
+	p.dirty = p.dirty[:0]
+	if err != nil {
+		p.err = errors.Trace(err)
+		return
+	}
+	if len(keys) == 0 {
+		return
+	}
+	c.keys = append(c.keys, keys...)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, goctx.Background()), keys)
+		if err != nil {
+			log.Debugf("[kv] prewrite ahead failed: %v, tid: %d", err, c.startTS)
+			p.err = errors.Trace(err)
+		}
+	}()
+}
+
+// wait waits for the running prewrite and returns the error of the prewrites.
+func (p *prewritePipeline) wait() error {
+	if p.done != nil {
+		<-p.done
+		p.done = nil
+	}
+	return p.err
+}
+
+// markDirty records that the key is written, so the next prewrite looks it up.
+func (p *prewritePipeline) markDirty(k kv.Key) {
+	p.dirty = append(p.dirty, k.Clone())
+}
+
+// prepareCommit waits for the running prewrite and makes the committer of the transaction skip the prewritten
+// keys. The prewritten mutations can't be changed as the locks are kept if they are prewritten again, so the
+// commit fails if any is changed. The error isn't retryable, as the statements of an explicit transaction may
+// read different rows when they are retried.
+func (p *prewritePipeline) prepareCommit(committer *twoPhaseCommitter) error {
+	if err := p.wait(); err != nil {
+		return errors.Trace(err)
+	}
+	for k, m := range p.committer.mutations {
+		final := committer.mutations[k]
+		if final == nil || final.Op != m.Op || !bytes.Equal(final.Value, m.Value) {
+			return errors.Annotatef(errPrewrittenAheadChanged, "key %q, tid: %d", k, p.committer.startTS)
+		}
+	}
+	if len(p.committer.keys) == 0 {
+		return nil
+	}
+	// The primary key is the one prewritten ahead.
+	primary := p.committer.primary()
+	for i, k := range committer.keys {
+		if bytes.Equal(k, primary) {
+			committer.keys[0], committer.keys[i] = committer.keys[i], committer.keys[0]
+			break
+		}
+	}
+	committer.prewritten = p.committer.mutations
+	p.committer.mu.RLock()
+	committer.mu.writtenKeys = p.committer.mu.writtenKeys
+	p.committer.mu.RUnlock()
+	return nil
+}
+
+// cleanup rolls back the prewritten keys in the background.
+func (p *prewritePipeline) cleanup() {
+	go func() {
+		p.wait()
+		c := p.committer
+		c.mu.RLock()
+		writtenKeys := c.mu.writtenKeys
+		c.mu.RUnlock()
+		err := c.cleanupKeys(NewBackoffer(cleanupMaxBackoff, goctx.Background()), writtenKeys)
+		if err != nil {
+			log.Infof("[kv] cleanup the keys prewritten ahead err: %v, tid: %d", err, c.startTS)
+		}
+	}()
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+)
+
+func (s *testCommitterSuite) TestPrewriteAhead(c *C) {
+	txn := s.begin(c)
+	c.Assert(txn.Set([]byte("a"), []byte("a1")), IsNil)
+	c.Assert(txn.Set([]byte("b"), []byte("b1")), IsNil)
+	txn.PrewriteAhead()
+	c.Assert(txn.Set([]byte("c"), []byte("c1")), IsNil)
+	c.Assert(txn.LockKeys([]byte("d")), IsNil)
+	// Only the keys written since the last prewrite are looked up.
+	c.Assert(txn.pipeline.dirty, DeepEquals, []kv.Key{kv.Key("c")})
+	txn.PrewriteAhead()
+	c.Assert(txn.pipeline.dirty, HasLen, 0)
+	c.Assert(txn.pipeline.wait(), IsNil)
+	c.Assert(s.isKeyLocked(c, []byte("a")), IsTrue)
+	c.Assert(s.isKeyLocked(c, []byte("c")), IsTrue)
+	c.Assert(s.isKeyLocked(c, []byte("d")), IsFalse)
+	// The lock keys are prewritten on commit, the locks of the primary key are held until the commit.
+	c.Assert(txn.Set([]byte("d"), []byte("d1")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"a": "a1", "b": "b1", "c": "c1", "d": "d1"})
+}
+
+func (s *testCommitterSuite) TestPrewriteAheadChanged(c *C) {
+	txn := s.begin(c)
+	c.Assert(txn.Set([]byte("a"), []byte("a1")), IsNil)
+	txn.PrewriteAhead()
+	c.Assert(txn.Set([]byte("a"), []byte("a2")), IsNil)
+	err := txn.Commit()
+	c.Assert(errors.Cause(err), Equals, errPrewrittenAheadChanged)
+	c.Assert(kv.IsRetryableError(err), IsFalse)
+	s.waitUnlocked(c, []byte("a"))
+
+	txn = s.begin(c)
+	c.Assert(txn.Set([]byte("b"), []byte("b1")), IsNil)
+	txn.PrewriteAhead()
+	c.Assert(txn.Rollback(), IsNil)
+	s.waitUnlocked(c, []byte("b"))
+	s.mustCommit(c, map[string]string{"a": "a3", "b": "b3"})
+}
+
+// waitUnlocked waits for the lock of the key to be cleaned up in the background.
+func (s *testCommitterSuite) waitUnlocked(c *C, key []byte) {
+	for i := 0; i < 100 && s.isKeyLocked(c, key); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(s.isKeyLocked(c, key), IsFalse)
+}
@@ -0,0 +1,131 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+var _ kv.PlacementStore = (*tikvStore)(nil)
+
+// pdPlacementRuleTimeout is the timeout of a request to the placement rule API of pd.
+const pdPlacementRuleTimeout = 10 * time.Second
+
+// pdPlacementRule is a placement rule in the API of pd.
+type pdPlacementRule struct {
+	GroupID          string              `json:"group_id"`
+	ID               string              `json:"id"`
+	StartKeyHex      string              `json:"start_key"`
+	EndKeyHex        string              `json:"end_key"`
+	Role             string              `json:"role"`
+	Count            int                 `json:"count"`
+	LabelConstraints []pdLabelConstraint `json:"label_constraints,omitempty"`
+}
+
+type pdLabelConstraint struct {
+	Key    string   `json:"key"`
+	Op     string   `json:"op"`
+	Values []string `json:"values"`
+}
+
+// pdRuleBundle is the rules of a group in the API of pd.
+type pdRuleBundle struct {
+	ID       string             `json:"group_id"`
+	Override bool               `json:"group_override"`
+	Rules    []*pdPlacementRule `json:"rules"`
+}
+
+// SetPlacementRules implements the kv.PlacementStore interface. The rules are set by the placement rule API of pd,
+// the mock tikv store keeps them in the mock cluster.
+func (s *tikvStore) SetPlacementRules(group string, rules []*kv.PlacementRule) error {
+	if s.mockCluster != nil {
+		s.mockCluster.SetPlacementRules(group, rules)
+		return nil
+	}
+
+	method, body := "DELETE", []byte(nil)
+	if len(rules) > 0 {
+		bundle := &pdRuleBundle{ID: group, Override: true}
+		for _, r := range rules {
+			bundle.Rules = append(bundle.Rules, toPDPlacementRule(group, r))
+		}
+		var err error
+		if body, err = json.Marshal(bundle); err != nil {
+			return errors.Trace(err)
+		}
+		method = "POST"
+	}
+	var err error
+	for _, addr := range s.etcdAddrs {
+		url := fmt.Sprintf("http://%s/pd/api/v1/config/placement-rule/%s", addr, group)
+		if err = doPDRequest(method, url, body); err == nil {
+			return nil
+		}
+	}
+	return errors.Trace(err)
+}
+
+func toPDPlacementRule(group string, r *kv.PlacementRule) *pdPlacementRule {
+	// The keys in tikv are encoded, the rules of pd are on the encoded keys.
+	rule := &pdPlacementRule{
+		GroupID:     group,
+		ID:          r.ID,
+		StartKeyHex: hex.EncodeToString(codec.EncodeBytes(nil, r.StartKey)),
+		EndKeyHex:   hex.EncodeToString(codec.EncodeBytes(nil, r.EndKey)),
+		Role:        r.Role,
+		Count:       r.Count,
+	}
+	for _, c := range r.LabelConstraints {
+		op := "in"
+		if c.Exclude {
+			op = "notIn"
+		}
+		rule.LabelConstraints = append(rule.LabelConstraints, pdLabelConstraint{Key: c.Key, Op: op, Values: []string{c.Value}})
+	}
+	return rule
+}
+
+func doPDRequest(method, url string, body []byte) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: pdPlacementRuleTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("%s %s error: %s %s", method, url, resp.Status, msg)
+	}
+	return nil
+}
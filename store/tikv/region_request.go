@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/pingcap/tidb/util/failpoint"
 	goctx "golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -106,6 +107,10 @@ func (s *RegionRequestSender) sendReqToRegion(bo *Backoffer, ctx *RPCContext, re
 	if e := tikvrpc.SetContext(req, ctx.KVCtx); e != nil {
 		return nil, false, errors.Trace(e)
 	}
+	if val, ok := failpoint.Eval("tikv/regionError"); ok {
+		resp, err = tikvrpc.GenRegionErrorResp(req, injectedRegionError(val))
+		return resp, false, errors.Trace(err)
+	}
 	context, cancel := goctx.WithTimeout(bo.ctx, timeout)
 	defer cancel()
 	resp, err = s.client.SendReq(context, ctx.Addr, req)
@@ -195,6 +200,20 @@ func (s *RegionRequestSender) onRegionError(bo *Backoffer, ctx *RPCContext, regi
 	return false, nil
 }
 
+// injectedRegionError returns the region error injected by the failpoint "tikv/regionError", the value of the
+// failpoint is the kind of the error: "notLeader", "staleEpoch" or "serverIsBusy", the default is "notLeader".
+func injectedRegionError(val interface{}) *errorpb.Error {
+	kind, _ := val.(string)
+	switch kind {
+	case "staleEpoch":
+		return &errorpb.Error{StaleEpoch: &errorpb.StaleEpoch{}}
+	case "serverIsBusy":
+		return &errorpb.Error{ServerIsBusy: &errorpb.ServerIsBusy{}}
+	default:
+		return &errorpb.Error{NotLeader: &errorpb.NotLeader{}}
+	}
+}
+
 func pbIsolationLevel(level kv.IsoLevel) kvrpcpb.IsolationLevel {
 	switch level {
 	case kv.RC:
@@ -0,0 +1,76 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikv
+
+import (
+	"bytes"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/tikv/mock-tikv"
+)
+
+var _ kv.SplitableStore = (*tikvStore)(nil)
+
+// SplitRegion implements the kv.SplitableStore interface.
+// The tikv and pd servers don't provide the API to split a region on demand yet, so it only works for the
+// mock tikv store now.
+func (s *tikvStore) SplitRegion(splitKey kv.Key) error {
+	if s.mockCluster == nil {
+		return errors.Trace(errSplitRegionNotSupported)
+	}
+	key := mocktikv.NewMvccKey(splitKey)
+	region, leader := s.mockCluster.GetRegionByKey(key)
+	if region == nil {
+		return errors.Errorf("the region of key %q is not found", splitKey)
+	}
+	if bytes.Equal(region.GetStartKey(), key) {
+		// The key is the start key of the region already.
+		return nil
+	}
+	newRegionID := s.mockCluster.AllocID()
+	peers := region.GetPeers()
+	newPeerIDs := s.mockCluster.AllocIDs(len(peers))
+	var leaderPeerID uint64
+	for i, peer := range peers {
+		if peer.GetId() == leader.GetId() {
+			leaderPeerID = newPeerIDs[i]
+		}
+	}
+	s.mockCluster.Split(region.GetId(), newRegionID, splitKey, newPeerIDs, leaderPeerID)
+	return nil
+}
+
+// ScatterRange implements the kv.SplitableStore interface.
+// The leaders of the regions are transferred to their peers in turn, so they are scattered across the stores.
+func (s *tikvStore) ScatterRange(startKey, endKey kv.Key) error {
+	if s.mockCluster == nil {
+		return errors.Trace(errSplitRegionNotSupported)
+	}
+	start, end := mocktikv.NewMvccKey(startKey), mocktikv.NewMvccKey(endKey)
+	var i int
+	for {
+		region, _ := s.mockCluster.GetRegionByKey(start)
+		if region == nil {
+			return nil
+		}
+		peers := region.GetPeers()
+		s.mockCluster.ChangeLeader(region.GetId(), peers[i%len(peers)].GetId())
+		i++
+		start = region.GetEndKey()
+		if len(start) == 0 || bytes.Compare(start, end) >= 0 {
+			return nil
+		}
+	}
+}
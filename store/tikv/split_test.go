@@ -97,3 +97,56 @@ func (s *testSplitSuite) TestStaleEpoch(c *C) {
 	_, err = txn.Get([]byte("c"))
 	c.Assert(err, IsNil)
 }
+
+func (s *testSplitSuite) TestSplitRegion(c *C) {
+	txn := s.begin(c)
+	c.Assert(txn.Set([]byte("a"), []byte("a")), IsNil)
+	c.Assert(txn.Set([]byte("c"), []byte("c")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	loc, err := s.store.regionCache.LocateKey(s.bo, []byte("a"))
+	c.Assert(err, IsNil)
+
+	c.Assert(s.store.SplitRegion([]byte("b")), IsNil)
+	// Splitting at the start key of a region does nothing.
+	c.Assert(s.store.SplitRegion([]byte("b")), IsNil)
+	c.Assert(s.cluster.GetAllRegions(), HasLen, 2)
+	regionA, _ := s.cluster.GetRegionByKey(mocktikv.NewMvccKey([]byte("a")))
+	regionC, _ := s.cluster.GetRegionByKey(mocktikv.NewMvccKey([]byte("c")))
+	c.Assert(regionA.GetId(), Equals, loc.Region.id)
+	c.Assert(regionC.GetId(), Not(Equals), loc.Region.id)
+	c.Assert(regionC.GetStartKey(), BytesEquals, []byte(mocktikv.NewMvccKey([]byte("b"))))
+
+	// The cached region is stale, but the data can be read still.
+	txn = s.begin(c)
+	v, err := txn.Get([]byte("c"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("c"))
+}
+
+func (s *testSplitSuite) TestScatterRange(c *C) {
+	cluster := mocktikv.NewCluster()
+	storeIDs, _, _, _ := mocktikv.BootstrapWithMultiStores(cluster, 3)
+	store, err := NewMockTikvStore(WithCluster(cluster))
+	c.Assert(err, IsNil)
+	s.store = store.(*tikvStore)
+	for _, key := range []string{"b", "c", "d"} {
+		c.Assert(s.store.SplitRegion([]byte(key)), IsNil)
+	}
+	c.Assert(s.store.ScatterRange([]byte("a"), []byte("d")), IsNil)
+
+	leaderStores := make(map[uint64]bool)
+	for _, key := range []string{"a", "b", "c"} {
+		_, leader := cluster.GetRegionByKey(mocktikv.NewMvccKey([]byte(key)))
+		leaderStores[leader.GetStoreId()] = true
+	}
+	c.Assert(leaderStores, HasLen, len(storeIDs))
+
+	txn := s.begin(c)
+	c.Assert(txn.Set([]byte("a"), []byte("a")), IsNil)
+	c.Assert(txn.Set([]byte("c"), []byte("c")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	txn = s.begin(c)
+	v, err := txn.Get([]byte("c"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("c"))
+}
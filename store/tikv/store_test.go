@@ -25,7 +25,11 @@ import (
 	"github.com/pingcap/pd/pd-client"
 	"github.com/pingcap/tidb"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/pingcap/tidb/store/tikv/oracle/oracles"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	goctx "golang.org/x/net/context"
 )
@@ -43,16 +47,104 @@ func (s *testStoreSuite) SetUpTest(c *C) {
 }
 
 func (s *testStoreSuite) TestParsePath(c *C) {
-	etcdAddrs, disableGC, err := parsePath("tikv://node1:2379,node2:2379")
+	etcdAddrs, opts, err := parsePath("tikv://node1:2379,node2:2379")
 	c.Assert(err, IsNil)
 	c.Assert(etcdAddrs, DeepEquals, []string{"node1:2379", "node2:2379"})
-	c.Assert(disableGC, IsFalse)
+	c.Assert(opts, Equals, pathOptions{})
 
 	_, _, err = parsePath("tikv://node1:2379")
 	c.Assert(err, IsNil)
-	_, disableGC, err = parsePath("tikv://node1:2379?disableGC=true")
+	_, opts, err = parsePath("tikv://node1:2379?disableGC=true")
 	c.Assert(err, IsNil)
-	c.Assert(disableGC, IsTrue)
+	c.Assert(opts.disableGC, IsTrue)
+	_, opts, err = parsePath("tikv://node1:2379?tso=local")
+	c.Assert(err, IsNil)
+	c.Assert(opts.localTSO, IsTrue)
+	_, _, err = parsePath("tikv://node1:2379?tso=etcd")
+	c.Assert(err, NotNil)
+	_, opts, err = parsePath("tikv://node1:2379?tsoBatch=16")
+	c.Assert(err, IsNil)
+	c.Assert(opts.tsoBatchSize, Equals, 16)
+	_, _, err = parsePath("tikv://node1:2379?tsoBatch=0")
+	c.Assert(err, NotNil)
+	_, _, err = parsePath("tikv://node1:2379?tso=local&tsoBatch=16")
+	c.Assert(err, NotNil)
+}
+
+func (s *testStoreSuite) TestLocalOracle(c *C) {
+	defer func(lease time.Duration) {
+		localTSOLease = lease
+	}(localTSOLease)
+	localTSOLease = 100 * time.Millisecond
+	cluster := mocktikv.NewCluster()
+	mocktikv.BootstrapWithSingleStore(cluster)
+	mvccStore := mocktikv.NewMvccStore()
+	newStore := func() *tikvStore {
+		store, err := NewMockTikvStore(WithCluster(cluster), WithMVCCStore(mvccStore))
+		c.Assert(err, IsNil)
+		return store.(*tikvStore)
+	}
+
+	store1 := newStore()
+	bo := NewBackoffer(tsoMaxBackoff, goctx.Background())
+	pdTS, err := store1.getTimestampWithRetry(bo)
+	c.Assert(err, IsNil)
+	c.Assert(store1.useLocalOracle(), IsNil)
+	lastTS := pdTS
+	for i := 0; i < 100; i++ {
+		ts, err := store1.getTimestampWithRetry(bo)
+		c.Assert(err, IsNil)
+		c.Assert(ts, Greater, lastTS)
+		lastTS = ts
+	}
+
+	// The store can't be used by another tidb-server while the timestamps are allocated locally.
+	store2 := newStore()
+	defer store2.Close()
+	c.Assert(errors.Cause(store2.useLocalOracle()), Equals, errLocalTSOInUse)
+	c.Assert(errors.Cause(store2.waitLocalTSO()), Equals, errLocalTSOInUse)
+
+	// After the store is closed, the timestamps of PD pass the local ones.
+	lastTS, err = store1.getTimestampWithRetry(bo)
+	c.Assert(err, IsNil)
+	c.Assert(store1.Close(), IsNil)
+	c.Assert(store2.waitLocalTSO(), IsNil)
+	ts, err := store2.getTimestampWithRetry(bo)
+	c.Assert(err, IsNil)
+	c.Assert(ts, Greater, lastTS)
+	err = kv.RunInNewTxn(store2, false, func(txn kv.Transaction) error {
+		record, err1 := meta.NewMeta(txn).GetLocalTSO()
+		c.Assert(record, IsNil)
+		return err1
+	})
+	c.Assert(err, IsNil)
+
+	// The timestamps can't be allocated locally if other tidb-servers are running.
+	err = kv.RunInNewTxn(store2, true, func(txn kv.Transaction) error {
+		expire := oracle.ExtractPhysical(txn.StartTS()) + int64(time.Minute/time.Millisecond)
+		return meta.NewMeta(txn).SetServerInfo(&model.ServerInfo{ID: 1, Expire: expire})
+	})
+	c.Assert(err, IsNil)
+	c.Assert(errors.Cause(store2.useLocalOracle()), Equals, errLocalTSOWithServers)
+}
+
+func (s *testStoreSuite) TestBatchOracle(c *C) {
+	s.store.oracle = oracles.NewBatchOracle(s.store.oracle, 16, time.Minute)
+	ctx := goctx.Background()
+	for i := 0; i < 20; i++ {
+		startTS, err := s.store.GetOracle().GetTimestampAsync(ctx).Wait()
+		c.Assert(err, IsNil)
+		txn, err := s.store.BeginWithStartTS(startTS)
+		c.Assert(err, IsNil)
+		// The data committed before is seen with the buffered start timestamps.
+		if i > 0 {
+			val, err1 := txn.Get(kv.Key("key"))
+			c.Assert(err1, IsNil)
+			c.Assert(val, BytesEquals, []byte{byte(i - 1)})
+		}
+		c.Assert(txn.Set(kv.Key("key"), []byte{byte(i)}), IsNil)
+		c.Assert(txn.Commit(), IsNil)
+	}
 }
 
 func (s *testStoreSuite) TestOracle(c *C) {
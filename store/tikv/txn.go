@@ -40,6 +40,8 @@ type tikvTxn struct {
 	valid     bool
 	lockKeys  [][]byte
 	dirty     bool
+	// pipeline is set if the transaction is prewritten ahead.
+	pipeline *prewritePipeline
 }
 
 func newTiKVTxn(store *tikvStore) (*tikvTxn, error) {
@@ -82,7 +84,13 @@ func (txn *tikvTxn) Set(k kv.Key, v []byte) error {
 	txnCmdCounter.WithLabelValues("set").Inc()
 
 	txn.dirty = true
-	return txn.us.Set(k, v)
+	if err := txn.us.Set(k, v); err != nil {
+		return errors.Trace(err)
+	}
+	if txn.pipeline != nil {
+		txn.pipeline.markDirty(k)
+	}
+	return nil
 }
 
 func (txn *tikvTxn) String() string {
@@ -110,7 +118,13 @@ func (txn *tikvTxn) Delete(k kv.Key) error {
 	txnCmdCounter.WithLabelValues("delete").Inc()
 
 	txn.dirty = true
-	return txn.us.Delete(k)
+	if err := txn.us.Delete(k); err != nil {
+		return errors.Trace(err)
+	}
+	if txn.pipeline != nil {
+		txn.pipeline.markDirty(k)
+	}
+	return nil
 }
 
 func (txn *tikvTxn) SetOption(opt kv.Option, val interface{}) {
@@ -120,6 +134,8 @@ func (txn *tikvTxn) SetOption(opt kv.Option, val interface{}) {
 		txn.snapshot.isolationLevel = val.(kv.IsoLevel)
 	case kv.Priority:
 		txn.snapshot.priority = kvPriorityToCommandPri(val.(int))
+	case kv.SnapshotTS:
+		txn.snapshot.version = kv.NewVersion(val.(uint64))
 	}
 }
 
@@ -145,7 +161,13 @@ func (txn *tikvTxn) Commit() error {
 	}
 
 	committer, err := newTwoPhaseCommitter(txn)
+	if err == nil && committer != nil && txn.pipeline != nil {
+		err = txn.pipeline.prepareCommit(committer)
+	}
 	if err != nil {
+		if txn.pipeline != nil {
+			txn.pipeline.cleanup()
+		}
 		return errors.Trace(err)
 	}
 	if committer == nil {
@@ -171,6 +193,9 @@ func (txn *tikvTxn) Rollback() error {
 		return kv.ErrInvalidTxn
 	}
 	txn.close()
+	if txn.pipeline != nil {
+		txn.pipeline.cleanup()
+	}
 	log.Infof("[kv] Rollback txn %d", txn.StartTS())
 	txnCmdCounter.WithLabelValues("rollback").Inc()
 
@@ -197,6 +222,14 @@ func (txn *tikvTxn) Valid() bool {
 	return txn.valid
 }
 
+func (txn *tikvTxn) BatchPrefetch(keys []kv.Key) error {
+	txnCmdCounter.WithLabelValues("batch_prefetch").Inc()
+	start := time.Now()
+	defer func() { txnCmdHistogram.WithLabelValues("batch_prefetch").Observe(time.Since(start).Seconds()) }()
+
+	return txn.us.BatchPrefetch(keys)
+}
+
 func (txn *tikvTxn) Len() int {
 	return txn.us.Len()
 }
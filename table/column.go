@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -37,6 +38,8 @@ type Column struct {
 	*model.ColumnInfo
 	// If this column is a generated column, the expression will be stored here.
 	GeneratedExpr ast.ExprNode
+	// If the default value of this column is an expression, the parsed expression is stored here.
+	DefaultExpr ast.ExprNode
 }
 
 // String implements fmt.Stringer interface.
@@ -72,6 +75,7 @@ func ToColumn(col *model.ColumnInfo) *Column {
 	return &Column{
 		col,
 		nil,
+		nil,
 	}
 }
 
@@ -299,9 +303,52 @@ func GetColOriginDefaultValue(ctx context.Context, col *model.ColumnInfo) (types
 
 // GetColDefaultValue gets default value of the column.
 func GetColDefaultValue(ctx context.Context, col *model.ColumnInfo) (types.Datum, error) {
+	if col.DefaultIsExpr {
+		return getColDefaultExprValue(ctx, col, nil)
+	}
 	return getColDefaultValue(ctx, col, col.DefaultValue)
 }
 
+// GetDefaultValue gets default value of the column, the parsed DefaultExpr is used if it's set.
+func (c *Column) GetDefaultValue(ctx context.Context) (types.Datum, error) {
+	if c.DefaultIsExpr {
+		return getColDefaultExprValue(ctx, c.ColumnInfo, c.DefaultExpr)
+	}
+	return getColDefaultValue(ctx, c.ColumnInfo, c.DefaultValue)
+}
+
+// ParseDefaultExpr parses the expression of DEFAULT (expr), which is kept as its text in the default value.
+func ParseDefaultExpr(col *model.ColumnInfo) (ast.ExprNode, error) {
+	exprStr, ok := col.DefaultValue.(string)
+	if !ok {
+		return nil, errGetDefaultFailed.Gen("Field '%s' get default value fail - invalid expression %v",
+			col.Name, col.DefaultValue)
+	}
+	stmts, err := parser.New().Parse("select "+exprStr, mysql.DefaultCharset, mysql.DefaultCollationName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return stmts[0].(*ast.SelectStmt).Fields.Fields[0].Expr, nil
+}
+
+// getColDefaultExprValue evaluates the expression of DEFAULT (expr), the expression is parsed if expr is nil.
+func getColDefaultExprValue(ctx context.Context, col *model.ColumnInfo, expr ast.ExprNode) (types.Datum, error) {
+	if expr == nil {
+		var err error
+		expr, err = ParseDefaultExpr(col)
+		if err != nil {
+			return types.Datum{}, errors.Trace(err)
+		}
+	}
+	value, err := expression.EvalAstExpr(expr, ctx)
+	if err != nil {
+		return types.Datum{}, errGetDefaultFailed.Gen("Field '%s' get default value fail - %s",
+			col.Name, errors.Trace(err))
+	}
+	value, err = CastValue(ctx, value, col)
+	return value, errors.Trace(err)
+}
+
 func getColDefaultValue(ctx context.Context, col *model.ColumnInfo, defaultVal interface{}) (types.Datum, error) {
 	if defaultVal == nil {
 		return getColDefaultValueFromNil(ctx, col)
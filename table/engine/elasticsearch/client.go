@@ -0,0 +1,294 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const (
+	// pageSize is the number of documents or groups fetched by one request.
+	pageSize = 1000
+	// scrollKeepAlive is how long Elasticsearch keeps the scroll context between two requests.
+	scrollKeepAlive = "1m"
+	// requestTimeout is the timeout of a request.
+	requestTimeout = time.Minute
+)
+
+// client sends requests to the REST API of an index.
+type client struct {
+	http      *http.Client
+	baseURL   string
+	user      *url.Userinfo
+	indexName string
+	docType   string
+}
+
+func newClient(baseURL string, user *url.Userinfo, index string) *client {
+	return &client{
+		http:      &http.Client{Timeout: requestTimeout},
+		baseURL:   baseURL,
+		user:      user,
+		indexName: index,
+		docType:   "_doc",
+	}
+}
+
+func (c *client) searchPath() string {
+	return "/" + url.PathEscape(c.indexName) + "/_search"
+}
+
+// do sends the request and decodes the response into result if it is not nil.
+func (c *client) do(method, path string, body interface{}, result interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != nil {
+		password, _ := c.user.Password()
+		req.SetBasicAuth(c.user.Username(), password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return engine.ErrBackendFailed.GenByArgs(Name, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return c.responseError(method, path, resp)
+	}
+	if result == nil {
+		return nil
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	return errors.Trace(dec.Decode(result))
+}
+
+// responseError returns the error of a failed request, with the reason given by Elasticsearch if there is one.
+func (c *client) responseError(method, path string, resp *http.Response) error {
+	msg := resp.Status
+	b, err := ioutil.ReadAll(resp.Body)
+	if err == nil {
+		var e struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(b, &e) == nil && e.Error.Reason != "" {
+			msg = fmt.Sprintf("%s: %s", e.Error.Type, e.Error.Reason)
+		}
+	}
+	return engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("%s %s: %s", method, path, msg))
+}
+
+type searchResult struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			ID     string                 `json:"_id"`
+			Source map[string]interface{} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		All    map[string]interface{} `json:"all"`
+		Groups struct {
+			AfterKey map[string]interface{}   `json:"after_key"`
+			Buckets  []map[string]interface{} `json:"buckets"`
+		} `json:"groups"`
+	} `json:"aggregations"`
+}
+
+func (c *client) search(body map[string]interface{}, scroll bool) (*searchResult, error) {
+	path := c.searchPath()
+	if scroll {
+		path += "?scroll=" + scrollKeepAlive
+	}
+	res := &searchResult{}
+	err := c.do("POST", path, body, res)
+	return res, errors.Trace(err)
+}
+
+func (c *client) scroll(id string) (*searchResult, error) {
+	res := &searchResult{}
+	err := c.do("POST", "/_search/scroll", map[string]interface{}{"scroll": scrollKeepAlive, "scroll_id": id}, res)
+	return res, errors.Trace(err)
+}
+
+func (c *client) clearScroll(id string) error {
+	return errors.Trace(c.do("DELETE", "/_search/scroll", map[string]interface{}{"scroll_id": []string{id}}, nil))
+}
+
+// index writes a document, Elasticsearch generates the id if it is empty.
+// The request waits for the document to be visible to searches.
+func (c *client) index(id string, doc map[string]interface{}) error {
+	path := "/" + url.PathEscape(c.indexName) + "/" + url.PathEscape(c.docType)
+	method := "POST"
+	if id != "" {
+		path += "/" + url.PathEscape(id)
+		method = "PUT"
+	}
+	return errors.Trace(c.do(method, path+"?refresh=wait_for", doc, nil))
+}
+
+// hitIter returns the documents matching a search, it scrolls the search unless the limit fits in one page.
+type hitIter struct {
+	client *client
+	sc     *variable.StatementContext
+	body   map[string]interface{}
+	cols   []*model.ColumnInfo
+	limit  uint64
+
+	started  bool
+	done     bool
+	scrollID string
+	returned uint64
+	res      *searchResult
+}
+
+// Next implements engine.RowIter Next interface.
+func (it *hitIter) Next() ([]types.Datum, error) {
+	if it.limit > 0 && it.returned >= it.limit {
+		return nil, nil
+	}
+	for it.res == nil || len(it.res.Hits.Hits) == 0 {
+		if it.done {
+			return nil, nil
+		}
+		if err := it.fetch(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	hit := it.res.Hits.Hits[0]
+	it.res.Hits.Hits = it.res.Hits.Hits[1:]
+	it.returned++
+	row := make([]types.Datum, 0, len(it.cols))
+	for _, col := range it.cols {
+		var val interface{}
+		if col.Name.L == idColumn {
+			val = hit.ID
+		} else {
+			val = hit.Source[col.Name.O]
+		}
+		d, err := convertValue(it.sc, val, &col.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row = append(row, d)
+	}
+	return row, nil
+}
+
+func (it *hitIter) fetch() error {
+	var err error
+	if !it.started {
+		it.started = true
+		scroll := it.limit == 0 || it.limit > pageSize
+		it.res, err = it.client.search(it.body, scroll)
+	} else {
+		it.res, err = it.client.scroll(it.scrollID)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	it.scrollID = it.res.ScrollID
+	if it.scrollID == "" || len(it.res.Hits.Hits) == 0 {
+		it.done = true
+	}
+	return nil
+}
+
+// Close implements engine.RowIter Close interface.
+func (it *hitIter) Close() error {
+	if it.scrollID == "" {
+		return nil
+	}
+	id := it.scrollID
+	it.scrollID = ""
+	return errors.Trace(it.client.clearScroll(id))
+}
+
+// aggIter returns the buckets of an aggregation, it pages through the groups of a composite aggregation.
+type aggIter struct {
+	client *client
+	sc     *variable.StatementContext
+	body   map[string]interface{}
+	aggs   *aggPlan
+
+	done    bool
+	after   map[string]interface{}
+	buckets []map[string]interface{}
+}
+
+// Next implements engine.RowIter Next interface.
+func (it *aggIter) Next() ([]types.Datum, error) {
+	for len(it.buckets) == 0 {
+		if it.done {
+			return nil, nil
+		}
+		if err := it.fetch(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	b := it.buckets[0]
+	it.buckets = it.buckets[1:]
+	row, err := it.aggs.row(it.sc, b)
+	return row, errors.Trace(err)
+}
+
+func (it *aggIter) fetch() error {
+	it.aggs.addTo(it.body, it.after)
+	res, err := it.client.search(it.body, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(it.aggs.groupBy) == 0 {
+		it.buckets = append(it.buckets, res.Aggregations.All)
+		it.done = true
+		return nil
+	}
+	it.buckets = res.Aggregations.Groups.Buckets
+	it.after = res.Aggregations.Groups.AfterKey
+	if it.after == nil || len(it.buckets) == 0 {
+		it.done = true
+	}
+	return nil
+}
+
+// Close implements engine.RowIter Close interface.
+func (it *aggIter) Close() error {
+	return nil
+}
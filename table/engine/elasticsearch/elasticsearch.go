@@ -0,0 +1,206 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elasticsearch implements the Elasticsearch storage engine.
+//
+// A table created with ENGINE=Elasticsearch CONNECTION='http://[user:password@]host:port/index[/type]'
+// reads and writes the documents of the index. Every column maps to the document field with the same
+// name, except the column named _id, which maps to the document id.
+//
+// Filters on string and numeric columns are translated to term, range, exists and wildcard queries,
+// so string columns should be mapped to keyword fields. Aggregations grouped by columns are translated
+// to composite aggregations, whose buckets are the partial results of the groups. The translated queries
+// need Elasticsearch 7.10 or later.
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Name is the name of the engine.
+const Name = "Elasticsearch"
+
+// idColumn is the name of the column mapped to the document id.
+const idColumn = "_id"
+
+// Engine is the Elasticsearch storage engine.
+type Engine struct{}
+
+// Name implements engine.Engine Name interface.
+func (e Engine) Name() string {
+	return Name
+}
+
+// Open implements engine.Engine Open interface.
+func (e Engine) Open(tblInfo *model.TableInfo) (engine.Source, error) {
+	for name := range tblInfo.EngineOptions {
+		if name != "connection" {
+			return nil, engine.ErrUnknownOption.GenByArgs(name, Name)
+		}
+	}
+	conn := tblInfo.EngineOptions["connection"]
+	u, err := url.Parse(conn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, engine.ErrInvalidOption.GenByArgs(conn, "connection", Name)
+	}
+	path := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if path[0] == "" || len(path) > 2 {
+		return nil, engine.ErrInvalidOption.GenByArgs(conn, "connection", Name)
+	}
+	c := newClient(u.Scheme+"://"+u.Host, u.User, path[0])
+	if len(path) == 2 {
+		c.docType = path[1]
+	}
+	return &source{client: c, tblInfo: tblInfo}, nil
+}
+
+type source struct {
+	client  *client
+	tblInfo *model.TableInfo
+}
+
+// Capabilities implements engine.Source Capabilities interface.
+func (s *source) Capabilities() engine.Capability {
+	return engine.CapInsert | engine.CapPushDownFilter | engine.CapPushDownLimit | engine.CapPushDownAgg
+}
+
+// PushDown implements engine.Source PushDown interface.
+func (s *source) PushDown(conds []expression.Expression) (pushed, remained []expression.Expression) {
+	for _, cond := range conds {
+		if _, ok := s.convertCond(cond); ok {
+			pushed = append(pushed, cond)
+		} else {
+			remained = append(remained, cond)
+		}
+	}
+	return
+}
+
+// CanPushDownAgg implements engine.Source CanPushDownAgg interface.
+func (s *source) CanPushDownAgg(aggFuncs []expression.AggregationFunction, groupBy []expression.Expression) bool {
+	_, ok := s.buildAggs(aggFuncs, groupBy)
+	return ok
+}
+
+// Scan implements engine.Source Scan interface.
+func (s *source) Scan(ctx context.Context, req *engine.ScanRequest) (engine.RowIter, error) {
+	body := s.buildSearch(req)
+	sc := ctx.GetSessionVars().StmtCtx
+	if len(req.AggFuncs) > 0 {
+		aggs, _ := s.buildAggs(req.AggFuncs, req.GroupBy)
+		return &aggIter{client: s.client, sc: sc, body: body, aggs: aggs}, nil
+	}
+	return &hitIter{client: s.client, sc: sc, body: body, cols: req.Columns, limit: req.Limit}, nil
+}
+
+// Insert implements engine.Source Insert interface.
+func (s *source) Insert(ctx context.Context, row []types.Datum) error {
+	doc := make(map[string]interface{}, len(row))
+	var id string
+	for i, col := range s.publicColumns() {
+		if col.Name.L == idColumn {
+			if !row[i].IsNull() {
+				str, err := row[i].ToString()
+				if err != nil {
+					return errors.Trace(err)
+				}
+				id = str
+			}
+			continue
+		}
+		val, err := datumToJSON(row[i])
+		if err != nil {
+			return errors.Trace(err)
+		}
+		doc[col.Name.O] = val
+	}
+	return errors.Trace(s.client.index(id, doc))
+}
+
+// Describe implements engine.Source Describe interface.
+func (s *source) Describe(req *engine.ScanRequest) string {
+	body := s.buildSearch(req)
+	if len(req.AggFuncs) > 0 {
+		aggs, _ := s.buildAggs(req.AggFuncs, req.GroupBy)
+		aggs.addTo(body, nil)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err.Error()
+	}
+	return "POST " + s.client.searchPath() + " " + string(b)
+}
+
+func (s *source) publicColumns() []*model.ColumnInfo {
+	cols := make([]*model.ColumnInfo, 0, len(s.tblInfo.Columns))
+	for _, col := range s.tblInfo.Columns {
+		if col.State == model.StatePublic {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// columnOf returns the column info if expr is a column of the table.
+func (s *source) columnOf(expr expression.Expression) (*model.ColumnInfo, bool) {
+	col, ok := expr.(*expression.Column)
+	if !ok {
+		return nil, false
+	}
+	for _, info := range s.tblInfo.Columns {
+		if info.Name.L == col.ColName.L {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// buildSearch builds the body of the search request without the aggregations.
+func (s *source) buildSearch(req *engine.ScanRequest) map[string]interface{} {
+	body := make(map[string]interface{})
+	var filters []interface{}
+	for _, cond := range req.Conditions {
+		q, _ := s.convertCond(cond)
+		filters = append(filters, q)
+	}
+	if len(filters) == 1 {
+		body["query"] = filters[0]
+	} else if len(filters) > 1 {
+		body["query"] = boolQuery("filter", filters...)
+	}
+	if len(req.AggFuncs) > 0 {
+		body["size"] = 0
+		return body
+	}
+	fields := make([]string, 0, len(req.Columns))
+	for _, col := range req.Columns {
+		if col.Name.L != idColumn {
+			fields = append(fields, col.Name.O)
+		}
+	}
+	body["_source"] = fields
+	body["size"] = pageSize
+	if req.Limit > 0 && req.Limit < pageSize {
+		body["size"] = req.Limit
+	}
+	return body
+}
@@ -0,0 +1,300 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/table/engine/enginetest"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testElasticsearchSuite{})
+
+type testElasticsearchSuite struct{}
+
+// fakeServer records the requests it receives and replies with the queued responses.
+type fakeServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []string
+	responses []string
+}
+
+func newFakeServer(responses ...string) *fakeServer {
+	s := &fakeServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		if len(s.responses) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"type":"not_found","reason":"no response"}}`))
+			return
+		}
+		w.Write([]byte(s.responses[0]))
+		s.responses = s.responses[1:]
+	}))
+	return s
+}
+
+// The helpers shared by the tests of the storage engines.
+var (
+	column       = enginetest.Column
+	newFunc      = enginetest.NewFunc
+	constant     = enginetest.Constant
+	datumsString = enginetest.DatumsString
+)
+
+func newTableInfo(conn string) *model.TableInfo {
+	return enginetest.NewTableInfo(Name, map[string]string{"connection": conn},
+		enginetest.NewColumn("_id", types.NewFieldType(mysql.TypeVarchar)),
+		enginetest.NewColumn("A", types.NewFieldType(mysql.TypeLonglong)),
+		enginetest.NewColumn("b", types.NewFieldType(mysql.TypeVarchar)),
+		enginetest.NewColumn("c", types.NewFieldType(mysql.TypeDouble)),
+	)
+}
+
+func openSource(c *C, conn string) *source {
+	src, err := Engine{}.Open(newTableInfo(conn))
+	c.Assert(err, IsNil)
+	return src.(*source)
+}
+
+func (s *testElasticsearchSuite) TestOpen(c *C) {
+	defer testleak.AfterTest(c)()
+	for _, conn := range []string{"", "localhost:9200/idx", "ftp://localhost/idx", "http://localhost:9200", "http://localhost:9200/a/b/c"} {
+		_, err := Engine{}.Open(newTableInfo(conn))
+		c.Assert(engine.ErrInvalidOption.Equal(err), IsTrue, Commentf("%s", conn))
+	}
+	tblInfo := newTableInfo("http://localhost:9200/idx")
+	tblInfo.EngineOptions["user"] = "root"
+	_, err := Engine{}.Open(tblInfo)
+	c.Assert(engine.ErrUnknownOption.Equal(err), IsTrue)
+
+	src := openSource(c, "https://u:p@localhost:9200/idx/doc")
+	c.Assert(src.client.baseURL, Equals, "https://localhost:9200")
+	c.Assert(src.client.user.Username(), Equals, "u")
+	c.Assert(src.client.indexName, Equals, "idx")
+	c.Assert(src.client.docType, Equals, "doc")
+	c.Assert(openSource(c, "http://localhost:9200/idx/").client.docType, Equals, "_doc")
+}
+
+func (s *testElasticsearchSuite) TestPushDown(c *C) {
+	defer testleak.AfterTest(c)()
+	src := openSource(c, "http://localhost:9200/idx")
+	cols := src.tblInfo.Columns
+	a, b, cc, id := column(cols, "a"), column(cols, "b"), column(cols, "c"), column(cols, "_id")
+	tests := []struct {
+		cond  expression.Expression
+		query string
+	}{
+		{newFunc(c, ast.EQ, a, constant(1)), `{"term":{"A":1}}`},
+		{newFunc(c, ast.GT, constant(1), a), `{"range":{"A":{"lt":1}}}`},
+		{newFunc(c, ast.LE, cc, constant(1.5)), `{"range":{"c":{"lte":1.5}}}`},
+		{newFunc(c, ast.NE, b, constant("x")), `{"bool":{"filter":{"exists":{"field":"b"}},"must_not":{"term":{"b":"x"}}}}`},
+		{newFunc(c, ast.EQ, id, constant("x")), `{"term":{"_id":"x"}}`},
+		{newFunc(c, ast.IsNull, b), `{"bool":{"must_not":[{"exists":{"field":"b"}}]}}`},
+		{newFunc(c, ast.UnaryNot, newFunc(c, ast.IsNull, b)), `{"exists":{"field":"b"}}`},
+		{newFunc(c, ast.Like, b, constant(`a%\%*`), constant(int64('\\'))), `{"wildcard":{"b":{"case_insensitive":true,"value":"a*%\\*"}}}`},
+		{newFunc(c, ast.LogicOr, newFunc(c, ast.EQ, a, constant(1)), newFunc(c, ast.EQ, b, constant("x"))),
+			`{"bool":{"minimum_should_match":1,"should":[{"term":{"A":1}},{"term":{"b":"x"}}]}}`},
+		// Conditions that can't be pushed down.
+		{newFunc(c, ast.EQ, b, constant(1)), ""},
+		{newFunc(c, ast.EQ, a, constant(1.5)), ""},
+		{newFunc(c, ast.EQ, a, b), ""},
+		{newFunc(c, ast.GT, id, constant("x")), ""},
+		{newFunc(c, ast.Like, b, constant("a_"), constant(int64('\\'))), ""},
+		{newFunc(c, ast.UnaryNot, newFunc(c, ast.EQ, a, constant(1))), ""},
+		{newFunc(c, ast.LogicAnd, newFunc(c, ast.EQ, a, constant(1)), newFunc(c, ast.EQ, a, b)), ""},
+	}
+	for _, t := range tests {
+		pushed, remained := src.PushDown([]expression.Expression{t.cond})
+		if t.query == "" {
+			c.Assert(pushed, HasLen, 0, Commentf("%s", t.cond))
+			c.Assert(remained, HasLen, 1)
+			continue
+		}
+		c.Assert(pushed, HasLen, 1, Commentf("%s", t.cond))
+		c.Assert(remained, HasLen, 0)
+		desc := src.Describe(&engine.ScanRequest{Conditions: pushed, Limit: 1})
+		c.Assert(desc, Equals, `POST /idx/_search {"_source":[],"query":`+t.query+`,"size":1}`, Commentf("%s", t.cond))
+	}
+
+	conds := []expression.Expression{newFunc(c, ast.EQ, a, constant(1)), newFunc(c, ast.EQ, b, constant("x"))}
+	desc := src.Describe(&engine.ScanRequest{Columns: src.tblInfo.Columns, Conditions: conds})
+	c.Assert(desc, Equals, `POST /idx/_search {"_source":["A","b","c"],"query":{"bool":{"filter":[{"term":{"A":1}},{"term":{"b":"x"}}]}},"size":1000}`)
+}
+
+func (s *testElasticsearchSuite) TestScan(c *C) {
+	defer testleak.AfterTest(c)()
+	server := newFakeServer(
+		`{"_scroll_id":"s1","hits":{"hits":[{"_id":"1","_source":{"A":1,"b":"x","c":1.5}},{"_id":"2","_source":{"A":"2","b":["y","z"]}}]}}`,
+		`{"_scroll_id":"s2","hits":{"hits":[{"_id":"3","_source":{"b":true}}]}}`,
+		`{"_scroll_id":"s3","hits":{"hits":[]}}`,
+		`{}`,
+	)
+	defer server.Close()
+	src := openSource(c, server.URL+"/idx")
+	ctx := mock.NewContext()
+
+	iter, err := src.Scan(ctx, &engine.ScanRequest{Columns: src.tblInfo.Columns})
+	c.Assert(err, IsNil)
+	var rows []string
+	for {
+		row, err := iter.Next()
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		rows = append(rows, datumsString(row))
+	}
+	c.Assert(iter.Close(), IsNil)
+	c.Assert(rows, DeepEquals, []string{"1 1 x 1.5", "2 2 [\"y\",\"z\"] <nil>", "3 <nil> 1 <nil>"})
+	c.Assert(server.requests, DeepEquals, []string{
+		`POST /idx/_search?scroll=1m {"_source":["A","b","c"],"size":1000}`,
+		`POST /_search/scroll {"scroll":"1m","scroll_id":"s1"}`,
+		`POST /_search/scroll {"scroll":"1m","scroll_id":"s2"}`,
+		`DELETE /_search/scroll {"scroll_id":["s3"]}`,
+	})
+
+	// A small limit is fetched by one request without scrolling.
+	server.requests = nil
+	server.responses = []string{`{"hits":{"hits":[{"_id":"1","_source":{"A":1}},{"_id":"2","_source":{"A":2}}]}}`}
+	iter, err = src.Scan(ctx, &engine.ScanRequest{Columns: src.tblInfo.Columns[1:2], Limit: 1})
+	c.Assert(err, IsNil)
+	row, err := iter.Next()
+	c.Assert(err, IsNil)
+	c.Assert(datumsString(row), Equals, "1")
+	row, err = iter.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row, IsNil)
+	c.Assert(iter.Close(), IsNil)
+	c.Assert(server.requests, DeepEquals, []string{`POST /idx/_search {"_source":["A"],"size":1}`})
+
+	// Errors returned by Elasticsearch.
+	iter, err = src.Scan(ctx, &engine.ScanRequest{Columns: src.tblInfo.Columns})
+	c.Assert(err, IsNil)
+	_, err = iter.Next()
+	c.Assert(engine.ErrBackendFailed.Equal(err), IsTrue)
+	c.Assert(err.Error(), Matches, ".*POST /idx/_search\\?scroll=1m: not_found: no response")
+}
+
+func (s *testElasticsearchSuite) TestAggregation(c *C) {
+	defer testleak.AfterTest(c)()
+	server := newFakeServer(
+		`{"aggregations":{"groups":{"after_key":{"g0":"y"},"buckets":[`+
+			`{"key":{"g0":"x"},"doc_count":3,"f1":{"value":6.0},"n1":{"value":3},"f2":{"value":2.5},"n3":{"value":2},"f3":{"value":1.5}},`+
+			`{"key":{"g0":null},"doc_count":1,"f1":{"value":0.0},"n1":{"value":0},"f2":{"value":null},"n3":{"value":0},"f3":{"value":0}}]}}}`,
+		`{"aggregations":{"groups":{"buckets":[]}}}`,
+		`{"aggregations":{"all":{"doc_count":0,"f0":{"value":0}}}}`,
+	)
+	defer server.Close()
+	src := openSource(c, server.URL+"/idx")
+	cols := src.tblInfo.Columns
+	a, b, cc := column(cols, "a"), column(cols, "b"), column(cols, "c")
+	aggFuncs := []expression.AggregationFunction{
+		expression.NewAggFunction(ast.AggFuncCount, []expression.Expression{constant(1)}, false),
+		expression.NewAggFunction(ast.AggFuncSum, []expression.Expression{a}, false),
+		expression.NewAggFunction(ast.AggFuncMax, []expression.Expression{cc}, false),
+		expression.NewAggFunction(ast.AggFuncAvg, []expression.Expression{cc}, false),
+		expression.NewAggFunction(ast.AggFuncFirstRow, []expression.Expression{b}, false),
+	}
+	groupBy := []expression.Expression{b}
+	c.Assert(src.CanPushDownAgg(aggFuncs, groupBy), IsTrue)
+	c.Assert(src.CanPushDownAgg(aggFuncs, nil), IsFalse)
+	c.Assert(src.CanPushDownAgg(aggFuncs[:1], []expression.Expression{newFunc(c, ast.Plus, a, a)}), IsFalse)
+	c.Assert(src.CanPushDownAgg([]expression.AggregationFunction{
+		expression.NewAggFunction(ast.AggFuncSum, []expression.Expression{b}, false),
+	}, nil), IsFalse)
+	c.Assert(src.CanPushDownAgg([]expression.AggregationFunction{
+		expression.NewAggFunction(ast.AggFuncCount, []expression.Expression{a}, true),
+	}, nil), IsFalse)
+
+	req := &engine.ScanRequest{Columns: src.tblInfo.Columns, AggFuncs: aggFuncs, GroupBy: groupBy}
+	iter, err := src.Scan(mock.NewContext(), req)
+	c.Assert(err, IsNil)
+	var rows []string
+	for {
+		row, err := iter.Next()
+		c.Assert(err, IsNil)
+		if row == nil {
+			break
+		}
+		rows = append(rows, datumsString(row))
+	}
+	c.Assert(iter.Close(), IsNil)
+	c.Assert(rows, DeepEquals, []string{"3 6 2.5 2 1.5 x x", "1 <nil> <nil> 0 <nil> <nil> <nil>"})
+	metrics := `"aggs":{"f1":{"sum":{"field":"A"}},"f2":{"max":{"field":"c"}},"f3":{"sum":{"field":"c"}},` +
+		`"n1":{"value_count":{"field":"A"}},"n3":{"value_count":{"field":"c"}}}`
+	sources := `"sources":[{"g0":{"terms":{"field":"b","missing_bucket":true}}}]`
+	c.Assert(server.requests, DeepEquals, []string{
+		`POST /idx/_search {"aggs":{"groups":{` + metrics + `,"composite":{"size":1000,` + sources + `}}},"size":0}`,
+		`POST /idx/_search {"aggs":{"groups":{` + metrics + `,"composite":{"after":{"g0":"y"},"size":1000,` + sources + `}}},"size":0}`,
+	})
+
+	// Without group by items, the aggregation of all the documents is a filter aggregation.
+	server.requests = nil
+	aggFuncs = []expression.AggregationFunction{expression.NewAggFunction(ast.AggFuncCount, []expression.Expression{a}, false)}
+	req = &engine.ScanRequest{AggFuncs: aggFuncs, Conditions: []expression.Expression{newFunc(c, ast.EQ, a, constant(1))}}
+	c.Assert(src.Describe(req), Equals,
+		`POST /idx/_search {"aggs":{"all":{"aggs":{"f0":{"value_count":{"field":"A"}}},"filter":{"match_all":{}}}},"query":{"term":{"A":1}},"size":0}`)
+	iter, err = src.Scan(mock.NewContext(), req)
+	c.Assert(err, IsNil)
+	row, err := iter.Next()
+	c.Assert(err, IsNil)
+	c.Assert(datumsString(row), Equals, "0")
+	row, err = iter.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row, IsNil)
+}
+
+func (s *testElasticsearchSuite) TestInsert(c *C) {
+	defer testleak.AfterTest(c)()
+	server := newFakeServer(`{"result":"created"}`, `{"result":"created"}`)
+	defer server.Close()
+	src := openSource(c, server.URL+"/idx")
+	ctx := mock.NewContext()
+
+	err := src.Insert(ctx, types.MakeDatums("a/1", 1, "x", nil))
+	c.Assert(err, IsNil)
+	err = src.Insert(ctx, types.MakeDatums(nil, nil, "y", 1.5))
+	c.Assert(err, IsNil)
+	c.Assert(server.requests, DeepEquals, []string{
+		`PUT /idx/_doc/a%2F1?refresh=wait_for {"A":1,"b":"x","c":null}`,
+		`POST /idx/_doc?refresh=wait_for {"A":null,"b":"y","c":1.5}`,
+	})
+
+	err = src.Insert(ctx, types.MakeDatums(nil, 1, "y", 1.5))
+	c.Assert(engine.ErrBackendFailed.Equal(err), IsTrue)
+}
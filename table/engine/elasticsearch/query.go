@@ -0,0 +1,452 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// query is a node of the Elasticsearch query DSL.
+type query map[string]interface{}
+
+func boolQuery(occur string, clauses ...interface{}) query {
+	q := query{occur: clauses}
+	if occur == "should" {
+		q["minimum_should_match"] = 1
+	}
+	return query{"bool": q}
+}
+
+func fieldQuery(tp string, col *model.ColumnInfo, val interface{}) query {
+	return query{tp: query{col.Name.O: val}}
+}
+
+func existsQuery(col *model.ColumnInfo) query {
+	return query{"exists": query{"field": col.Name.O}}
+}
+
+var rangeOps = map[string]string{
+	ast.LT: "lt",
+	ast.LE: "lte",
+	ast.GT: "gt",
+	ast.GE: "gte",
+}
+
+// flippedOps maps a comparison to the one with the arguments swapped.
+var flippedOps = map[string]string{
+	ast.EQ: ast.EQ,
+	ast.NE: ast.NE,
+	ast.LT: ast.GT,
+	ast.LE: ast.GE,
+	ast.GT: ast.LT,
+	ast.GE: ast.LE,
+}
+
+// convertCond translates a filter to a query which matches exactly the documents satisfying it.
+// It returns false if the filter can't be translated.
+func (s *source) convertCond(expr expression.Expression) (query, bool) {
+	f, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return nil, false
+	}
+	args := f.GetArgs()
+	switch f.FuncName.L {
+	case ast.LogicAnd, ast.LogicOr:
+		l, ok := s.convertCond(args[0])
+		if !ok {
+			return nil, false
+		}
+		r, ok := s.convertCond(args[1])
+		if !ok {
+			return nil, false
+		}
+		if f.FuncName.L == ast.LogicAnd {
+			return boolQuery("filter", l, r), true
+		}
+		return boolQuery("should", l, r), true
+	case ast.EQ, ast.NE, ast.LT, ast.LE, ast.GT, ast.GE:
+		op := f.FuncName.L
+		col, ok := s.columnOf(args[0])
+		val := args[1]
+		if !ok {
+			col, ok = s.columnOf(args[1])
+			val, op = args[0], flippedOps[op]
+		}
+		if !ok || (col.Name.L == idColumn && op != ast.EQ) {
+			return nil, false
+		}
+		v, ok := constantValue(col, val)
+		if !ok {
+			return nil, false
+		}
+		switch op {
+		case ast.EQ:
+			return fieldQuery("term", col, v), true
+		case ast.NE:
+			// A NULL field doesn't satisfy the condition either.
+			return query{"bool": query{
+				"filter":   existsQuery(col),
+				"must_not": fieldQuery("term", col, v),
+			}}, true
+		}
+		return fieldQuery("range", col, query{rangeOps[op]: v}), true
+	case ast.IsNull:
+		col, ok := s.columnOf(args[0])
+		if !ok || col.Name.L == idColumn {
+			return nil, false
+		}
+		return boolQuery("must_not", existsQuery(col)), true
+	case ast.UnaryNot:
+		// Only NOT ISNULL is translated, other negations differ from SQL on NULL values.
+		inner, ok := args[0].(*expression.ScalarFunction)
+		if !ok || inner.FuncName.L != ast.IsNull {
+			return nil, false
+		}
+		col, ok := s.columnOf(inner.GetArgs()[0])
+		if !ok || col.Name.L == idColumn {
+			return nil, false
+		}
+		return existsQuery(col), true
+	case ast.Like:
+		return s.convertLike(args)
+	}
+	return nil, false
+}
+
+// convertLike translates LIKE to a case insensitive wildcard query.
+// Patterns with '_' are not translated, because '_' matches a byte in TiDB but a character in Elasticsearch.
+func (s *source) convertLike(args []expression.Expression) (query, bool) {
+	col, ok := s.columnOf(args[0])
+	if !ok || col.Name.L == idColumn || !isStringType(col.Tp) {
+		return nil, false
+	}
+	pattern, ok := args[1].(*expression.Constant)
+	if !ok || pattern.Value.Kind() != types.KindString {
+		return nil, false
+	}
+	escape := byte('\\')
+	if len(args) > 2 {
+		c, ok := args[2].(*expression.Constant)
+		if !ok || c.Value.Kind() != types.KindInt64 {
+			return nil, false
+		}
+		escape = byte(c.Value.GetInt64())
+	}
+	wildcard, ok := likeToWildcard(pattern.Value.GetString(), escape)
+	if !ok {
+		return nil, false
+	}
+	return fieldQuery("wildcard", col, query{"value": wildcard, "case_insensitive": true}), true
+}
+
+func likeToWildcard(pattern string, escape byte) (string, bool) {
+	var buf bytes.Buffer
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == escape && i+1 < len(pattern):
+			i++
+			c = pattern[i]
+			if c != escape && c != '_' && c != '%' {
+				// Invalid escape, the escape is a normal character like in CompilePattern.
+				i--
+				c = escape
+			}
+		case c == '_':
+			return "", false
+		case c == '%':
+			buf.WriteByte('*')
+			continue
+		}
+		if c == '*' || c == '?' || c == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), true
+}
+
+// constantValue returns the JSON value of expr if it is a constant that can be compared with the column
+// in Elasticsearch the way TiDB compares them.
+func constantValue(col *model.ColumnInfo, expr expression.Expression) (interface{}, bool) {
+	c, ok := expr.(*expression.Constant)
+	if !ok {
+		return nil, false
+	}
+	switch c.Value.Kind() {
+	case types.KindString, types.KindBytes:
+		if isStringType(col.Tp) {
+			return c.Value.GetString(), true
+		}
+	case types.KindInt64, types.KindUint64:
+		if isNumericType(col.Tp) {
+			return c.Value.GetValue(), true
+		}
+	case types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+		if isNumericType(col.Tp) && col.FieldType.ToClass() != types.ClassInt {
+			v, err := datumToJSON(c.Value)
+			return v, err == nil
+		}
+	}
+	return nil, false
+}
+
+func isStringType(tp byte) bool {
+	return types.IsTypeChar(tp) || types.IsTypeVarchar(tp) || types.IsTypeBlob(tp)
+}
+
+func isNumericType(tp byte) bool {
+	if tp == mysql.TypeBit || tp == mysql.TypeYear {
+		return false
+	}
+	ft := types.NewFieldType(tp)
+	return ft.ToClass() != types.ClassString
+}
+
+// aggPlan is the translation of a pushed down aggregation.
+type aggPlan struct {
+	groupBy []*model.ColumnInfo
+	funcs   []aggFunc
+}
+
+type aggFunc struct {
+	name string
+	// col is the argument, it is nil for COUNT(*).
+	col *model.ColumnInfo
+	// gbyIdx is the group by column whose value is returned by FIRSTROW.
+	gbyIdx  int
+	retType *types.FieldType
+}
+
+// buildAggs translates the aggregation to a composite aggregation grouped by the columns,
+// or a filter aggregation if there are no group by items. It returns false if it can't be translated.
+func (s *source) buildAggs(aggFuncs []expression.AggregationFunction, groupBy []expression.Expression) (*aggPlan, bool) {
+	p := &aggPlan{}
+	for _, item := range groupBy {
+		col, ok := s.columnOf(item)
+		if !ok || col.Name.L == idColumn || !(isStringType(col.Tp) || isNumericType(col.Tp)) {
+			return nil, false
+		}
+		p.groupBy = append(p.groupBy, col)
+	}
+	for _, af := range aggFuncs {
+		if af.IsDistinct() || len(af.GetArgs()) != 1 {
+			return nil, false
+		}
+		f := aggFunc{name: af.GetName(), retType: af.GetType()}
+		arg := af.GetArgs()[0]
+		col, isCol := s.columnOf(arg)
+		if isCol && col.Name.L == idColumn {
+			return nil, false
+		}
+		switch f.name {
+		case ast.AggFuncCount:
+			if c, ok := arg.(*expression.Constant); ok && !c.Value.IsNull() {
+				break
+			}
+			if !isCol {
+				return nil, false
+			}
+			f.col = col
+		case ast.AggFuncSum, ast.AggFuncAvg, ast.AggFuncMax, ast.AggFuncMin:
+			if !isCol || !isNumericType(col.Tp) {
+				return nil, false
+			}
+			f.col = col
+		case ast.AggFuncFirstRow:
+			if !isCol {
+				return nil, false
+			}
+			f.gbyIdx = -1
+			for i, gby := range p.groupBy {
+				if gby.Name.L == col.Name.L {
+					f.gbyIdx = i
+				}
+			}
+			if f.gbyIdx < 0 {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+		p.funcs = append(p.funcs, f)
+	}
+	return p, true
+}
+
+func metricName(prefix string, i int) string {
+	return fmt.Sprintf("%s%d", prefix, i)
+}
+
+func groupName(i int) string {
+	return fmt.Sprintf("g%d", i)
+}
+
+// addTo adds the aggregations to the search body, after is the key of the last returned group.
+func (p *aggPlan) addTo(body map[string]interface{}, after map[string]interface{}) {
+	metrics := make(query)
+	for i, f := range p.funcs {
+		switch f.name {
+		case ast.AggFuncCount:
+			if f.col != nil {
+				metrics[metricName("f", i)] = query{"value_count": query{"field": f.col.Name.O}}
+			}
+		case ast.AggFuncSum, ast.AggFuncAvg:
+			// The sum of no values is 0 in Elasticsearch, the count tells whether it is NULL.
+			metrics[metricName("f", i)] = query{"sum": query{"field": f.col.Name.O}}
+			metrics[metricName("n", i)] = query{"value_count": query{"field": f.col.Name.O}}
+		case ast.AggFuncMax, ast.AggFuncMin:
+			metrics[metricName("f", i)] = query{f.name: query{"field": f.col.Name.O}}
+		}
+	}
+	var agg query
+	if len(p.groupBy) == 0 {
+		agg = query{"filter": query{"match_all": query{}}}
+	} else {
+		sources := make([]interface{}, 0, len(p.groupBy))
+		for i, col := range p.groupBy {
+			sources = append(sources, query{groupName(i): query{"terms": query{"field": col.Name.O, "missing_bucket": true}}})
+		}
+		composite := query{"size": pageSize, "sources": sources}
+		if after != nil {
+			composite["after"] = after
+		}
+		agg = query{"composite": composite}
+	}
+	if len(metrics) > 0 {
+		agg["aggs"] = metrics
+	}
+	body["aggs"] = query{aggName(p): agg}
+}
+
+func aggName(p *aggPlan) string {
+	if len(p.groupBy) == 0 {
+		return "all"
+	}
+	return "groups"
+}
+
+// row returns the partial result of the group in the bucket.
+func (p *aggPlan) row(sc *variable.StatementContext, b map[string]interface{}) ([]types.Datum, error) {
+	key, _ := b["key"].(map[string]interface{})
+	metric := func(name string) interface{} {
+		m, _ := b[name].(map[string]interface{})
+		return m["value"]
+	}
+	row := make([]types.Datum, 0, len(p.funcs)+len(p.groupBy))
+	count := types.NewFieldType(mysql.TypeLonglong)
+	for i, f := range p.funcs {
+		var vals []interface{}
+		var tps []*types.FieldType
+		switch f.name {
+		case ast.AggFuncCount:
+			if f.col == nil {
+				vals = append(vals, b["doc_count"])
+			} else {
+				vals = append(vals, metric(metricName("f", i)))
+			}
+			tps = append(tps, count)
+		case ast.AggFuncSum, ast.AggFuncAvg:
+			n := metric(metricName("n", i))
+			sum := metric(metricName("f", i))
+			if num, ok := n.(json.Number); ok && num.String() == "0" {
+				sum = nil
+			}
+			if f.name == ast.AggFuncAvg {
+				vals, tps = append(vals, n), append(tps, count)
+			}
+			vals, tps = append(vals, sum), append(tps, f.retType)
+		case ast.AggFuncMax, ast.AggFuncMin:
+			vals, tps = append(vals, metric(metricName("f", i))), append(tps, f.retType)
+		case ast.AggFuncFirstRow:
+			vals, tps = append(vals, key[groupName(f.gbyIdx)]), append(tps, &p.groupBy[f.gbyIdx].FieldType)
+		}
+		for j, val := range vals {
+			d, err := convertValue(sc, val, tps[j])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			row = append(row, d)
+		}
+	}
+	for i, col := range p.groupBy {
+		d, err := convertValue(sc, key[groupName(i)], &col.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row = append(row, d)
+	}
+	return row, nil
+}
+
+// convertValue converts a value decoded from a response to a datum of the field type.
+func convertValue(sc *variable.StatementContext, val interface{}, ft *types.FieldType) (types.Datum, error) {
+	var d types.Datum
+	switch x := val.(type) {
+	case nil:
+		return d, nil
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			d.SetInt64(i)
+		} else if f, err := x.Float64(); err == nil {
+			d.SetFloat64(f)
+		} else {
+			d.SetString(x.String())
+		}
+	case string:
+		d.SetString(x)
+	case bool:
+		if x {
+			d.SetInt64(1)
+		} else {
+			d.SetInt64(0)
+		}
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+		d.SetString(string(b))
+	}
+	d, err := d.ConvertTo(sc, ft)
+	return d, errors.Trace(err)
+}
+
+// datumToJSON returns the value of the datum in a document.
+func datumToJSON(d types.Datum) (interface{}, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return nil, nil
+	case types.KindInt64:
+		return d.GetInt64(), nil
+	case types.KindUint64:
+		return d.GetUint64(), nil
+	case types.KindFloat32, types.KindFloat64:
+		return d.GetFloat64(), nil
+	case types.KindMysqlDecimal:
+		return json.Number(d.GetMysqlDecimal().String()), nil
+	}
+	str, err := d.ToString()
+	return str, errors.Trace(err)
+}
@@ -0,0 +1,189 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package engine defines the interface of external storage engines.
+//
+// A table created with ENGINE=<name>, where <name> is a registered engine,
+// keeps its rows outside of the TiDB key-value store. The planner asks the
+// engine which filters and limits it can evaluate itself, and the executor
+// reads and writes rows through it.
+package engine
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Capability is a bit set of the optional operations supported by a Source.
+type Capability uint
+
+// Capabilities of a Source.
+const (
+	// CapInsert means the source accepts new rows.
+	CapInsert Capability = 1 << iota
+	// CapPushDownFilter means the source can evaluate some filter conditions itself.
+	CapPushDownFilter
+	// CapPushDownLimit means the source can stop after returning a given number of rows.
+	CapPushDownLimit
+	// CapPushDownAgg means the source can compute the partial results of some aggregations itself.
+	CapPushDownAgg
+)
+
+// Has returns whether c contains all the capabilities in other.
+func (c Capability) Has(other Capability) bool {
+	return c&other == other
+}
+
+// Engine is an external storage engine.
+type Engine interface {
+	// Name returns the name used in the ENGINE table option.
+	Name() string
+	// Open returns the Source for the table. It is called every time the schema is loaded,
+	// so it must only validate the table options and must not connect to the backend.
+	Open(tblInfo *model.TableInfo) (Source, error)
+}
+
+// Source gives access to the rows of one external table.
+type Source interface {
+	// Capabilities returns the optional operations supported by the source.
+	Capabilities() Capability
+	// PushDown splits conds into the conditions the source evaluates itself and the remaining ones,
+	// which are evaluated by TiDB. It is only called if the source has CapPushDownFilter.
+	PushDown(conds []expression.Expression) (pushed, remained []expression.Expression)
+	// CanPushDownAgg returns whether the source can compute the aggregate functions grouped by the items.
+	// It is only called if the source has CapPushDownAgg.
+	CanPushDownAgg(aggFuncs []expression.AggregationFunction, groupBy []expression.Expression) bool
+	// Scan starts reading the rows described by req.
+	Scan(ctx context.Context, req *ScanRequest) (RowIter, error)
+	// Insert writes a row which contains all the public columns of the table.
+	// It is only called if the source has CapInsert.
+	Insert(ctx context.Context, row []types.Datum) error
+	// Describe returns the request the source issues for req, it is shown by EXPLAIN.
+	Describe(req *ScanRequest) string
+}
+
+// ScanRequest describes the rows a scan should return.
+type ScanRequest struct {
+	// Columns are the columns to return, in order.
+	Columns []*model.ColumnInfo
+	// Conditions are the filters accepted by Source.PushDown, every returned row must satisfy them.
+	Conditions []expression.Expression
+	// Limit is the maximum number of rows to return, zero means no limit.
+	// It is only set if the source has CapPushDownLimit.
+	Limit uint64
+	// AggFuncs and GroupBy are the aggregation accepted by Source.CanPushDownAgg, which is computed on
+	// the rows satisfying the conditions. If they are set, a returned row holds the partial result of a group:
+	// for every aggregate function, its count if it is COUNT or AVG and its value if it isn't COUNT,
+	// followed by the group by values. A group can be split into several rows.
+	AggFuncs []expression.AggregationFunction
+	GroupBy  []expression.Expression
+}
+
+// RowIter iterates the rows returned by a scan.
+type RowIter interface {
+	// Next returns the next row, or nil if there are no more rows.
+	Next() ([]types.Datum, error)
+	// Close releases the resources held by the iterator.
+	Close() error
+}
+
+// Error codes.
+const (
+	codeUnknownOption     terror.ErrCode = 1
+	codeInvalidOption     terror.ErrCode = 2
+	codeUnsupportedOp     terror.ErrCode = 3
+	codeBackendFailed     terror.ErrCode = 4
+	codeDuplicateRegister terror.ErrCode = 5
+)
+
+var (
+	// ErrUnknownOption is returned when a table option is not known by the engine.
+	ErrUnknownOption = terror.ClassEngine.New(codeUnknownOption, "unknown option '%s' for engine %s")
+	// ErrInvalidOption is returned when a table option has an invalid value.
+	ErrInvalidOption = terror.ClassEngine.New(codeInvalidOption, "invalid value '%s' of option '%s' for engine %s")
+	// ErrUnsupportedOp is returned when the engine doesn't support the operation.
+	ErrUnsupportedOp = terror.ClassEngine.New(codeUnsupportedOp, "engine %s doesn't support %s")
+	// ErrBackendFailed is returned when the backend of the engine returns an error.
+	ErrBackendFailed = terror.ClassEngine.New(codeBackendFailed, "engine %s: %s")
+)
+
+var (
+	mu      sync.RWMutex
+	engines = make(map[string]Engine)
+)
+
+// Register makes an engine available by its name.
+// It panics if an engine with the same name is already registered.
+func Register(e Engine) {
+	mu.Lock()
+	defer mu.Unlock()
+	name := strings.ToLower(e.Name())
+	if _, ok := engines[name]; ok {
+		panic(terror.ClassEngine.New(codeDuplicateRegister, "engine %s is registered twice").GenByArgs(e.Name()))
+	}
+	engines[name] = e
+}
+
+// Lookup returns the engine with the name, the name is case insensitive.
+func Lookup(name string) (Engine, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := engines[strings.ToLower(name)]
+	return e, ok
+}
+
+// Names returns the names of all the registered engines in alphabetical order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for _, e := range engines {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultName is the engine name reported for tables stored in TiDB.
+const DefaultName = "InnoDB"
+
+// NameOf returns the engine name of the table reported by SHOW statements and information_schema.
+func NameOf(tblInfo *model.TableInfo) string {
+	if IsExternal(tblInfo) {
+		return tblInfo.Engine
+	}
+	return DefaultName
+}
+
+// IsExternal returns whether the rows of the table are stored by an external engine.
+func IsExternal(tblInfo *model.TableInfo) bool {
+	return tblInfo != nil && tblInfo.Engine != ""
+}
+
+func init() {
+	engineMySQLErrCodes := map[terror.ErrCode]uint16{
+		codeUnknownOption: mysql.ErrUnknown,
+		codeInvalidOption: mysql.ErrUnknown,
+		codeUnsupportedOp: mysql.ErrNotSupportedYet,
+		codeBackendFailed: mysql.ErrUnknown,
+	}
+	terror.ErrClassToMySQLCodes[terror.ClassEngine] = engineMySQLErrCodes
+}
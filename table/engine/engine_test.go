@@ -0,0 +1,74 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testEngineSuite{})
+
+type testEngineSuite struct{}
+
+type nopEngine struct {
+	name string
+}
+
+func (e *nopEngine) Name() string { return e.name }
+
+func (e *nopEngine) Open(tblInfo *model.TableInfo) (Source, error) {
+	return nil, ErrUnsupportedOp.GenByArgs(e.name, "reading")
+}
+
+func (s *testEngineSuite) TestRegister(c *C) {
+	defer testleak.AfterTest(c)()
+	Register(&nopEngine{name: "NopB"})
+	Register(&nopEngine{name: "NopA"})
+	c.Assert(func() { Register(&nopEngine{name: "nopa"}) }, PanicMatches, ".*registered twice")
+
+	e, ok := Lookup("NOPA")
+	c.Assert(ok, IsTrue)
+	c.Assert(e.Name(), Equals, "NopA")
+	_, ok = Lookup("InnoDB")
+	c.Assert(ok, IsFalse)
+	c.Assert(Names(), DeepEquals, []string{"NopA", "NopB"})
+}
+
+func (s *testEngineSuite) TestNameOf(c *C) {
+	defer testleak.AfterTest(c)()
+	tblInfo := &model.TableInfo{}
+	c.Assert(IsExternal(tblInfo), IsFalse)
+	c.Assert(NameOf(tblInfo), Equals, DefaultName)
+	tblInfo.Engine = "NopA"
+	c.Assert(IsExternal(tblInfo), IsTrue)
+	c.Assert(NameOf(tblInfo), Equals, "NopA")
+}
+
+func (s *testEngineSuite) TestCapability(c *C) {
+	defer testleak.AfterTest(c)()
+	caps := CapInsert | CapPushDownLimit
+	c.Assert(caps.Has(CapInsert), IsTrue)
+	c.Assert(caps.Has(CapInsert|CapPushDownLimit), IsTrue)
+	c.Assert(caps.Has(CapPushDownFilter), IsFalse)
+	c.Assert(caps.Has(CapInsert|CapPushDownFilter), IsFalse)
+}
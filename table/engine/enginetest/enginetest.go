@@ -0,0 +1,115 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package enginetest contains the helpers shared by the tests of the storage engines.
+package enginetest
+
+import (
+	"strings"
+
+	"github.com/pingcap/check"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// NewColumn returns a public column of the type.
+func NewColumn(name string, tp *types.FieldType) *model.ColumnInfo {
+	return &model.ColumnInfo{
+		Name:      model.NewCIStr(name),
+		FieldType: *tp,
+		State:     model.StatePublic,
+	}
+}
+
+// NewTableInfo returns the table info of table t of the engine, the offsets of the columns are set in order.
+func NewTableInfo(engineName string, options map[string]string, cols ...*model.ColumnInfo) *model.TableInfo {
+	for i, col := range cols {
+		col.Offset = i
+	}
+	return &model.TableInfo{
+		Name:          model.NewCIStr("t"),
+		Columns:       cols,
+		Engine:        engineName,
+		EngineOptions: options,
+	}
+}
+
+// Column returns the expression of a column in cols, cols are the columns of the rows which the expression is
+// evaluated on.
+func Column(cols []*model.ColumnInfo, name string) *expression.Column {
+	for i, col := range cols {
+		if col.Name.L == name {
+			return &expression.Column{ColName: col.Name, Position: i, Index: i, RetType: &col.FieldType}
+		}
+	}
+	return nil
+}
+
+// NewFunc returns the scalar function expression of the name.
+func NewFunc(c *check.C, name string, args ...expression.Expression) expression.Expression {
+	f, err := expression.NewFunction(mock.NewContext(), name, types.NewFieldType(mysql.TypeLonglong), args...)
+	c.Assert(err, check.IsNil)
+	return f
+}
+
+// Constant returns the constant expression of the value, the type is inferred from the value.
+func Constant(val interface{}) *expression.Constant {
+	tp := mysql.TypeLonglong
+	switch val.(type) {
+	case string:
+		tp = mysql.TypeVarchar
+	case float64:
+		tp = mysql.TypeDouble
+	case *types.MyDecimal:
+		tp = mysql.TypeNewDecimal
+	}
+	return &expression.Constant{Value: types.NewDatum(val), RetType: types.NewFieldType(tp)}
+}
+
+// DatumsString returns the row as the values separated by spaces, null values are shown as <nil>.
+func DatumsString(row []types.Datum) string {
+	strs := make([]string, 0, len(row))
+	for _, d := range row {
+		if d.IsNull() {
+			strs = append(strs, "<nil>")
+			continue
+		}
+		str, _ := d.ToString()
+		strs = append(strs, str)
+	}
+	return strings.Join(strs, " ")
+}
+
+// ScanAll scans the source and returns the rows as DatumsString, the rows read before an error are returned with it.
+func ScanAll(c *check.C, src engine.Source, req *engine.ScanRequest) ([]string, error) {
+	iter, err := src.Scan(mock.NewContext(), req)
+	c.Assert(err, check.IsNil)
+	defer func() {
+		c.Assert(iter.Close(), check.IsNil)
+	}()
+	var rows []string
+	for {
+		row, err := iter.Next()
+		if err != nil {
+			return rows, err
+		}
+		if row == nil {
+			return rows, nil
+		}
+		rows = append(rows, DatumsString(row))
+	}
+}
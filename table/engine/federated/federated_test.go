@@ -0,0 +1,259 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package federated
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/table/engine/enginetest"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testFederatedSuite{})
+
+// testFederatedSuite runs a TiDB server as the remote server.
+type testFederatedSuite struct {
+	store  kv.Storage
+	server *server.Server
+	addr   string
+	db     *sql.DB
+}
+
+func (s *testFederatedSuite) SetUpSuite(c *C) {
+	log.SetLevel(log.ErrorLevel)
+	store, err := tidb.NewStore("memory://federated")
+	c.Assert(err, IsNil)
+	s.store = store
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	s.addr = ln.Addr().String()
+	ln.Close()
+	s.server, err = server.NewServer(&config.Config{Addr: s.addr, LogLevel: "error"}, server.NewTiDBDriver(store))
+	c.Assert(err, IsNil)
+	go s.server.Run()
+
+	s.db, err = sql.Open("mysql", "root@tcp("+s.addr+")/")
+	c.Assert(err, IsNil)
+	for i := 0; ; i++ {
+		if err = s.db.Ping(); err == nil || i == 50 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(err, IsNil)
+	for _, stmt := range []string{
+		"create database remote",
+		"create table remote.t (id bigint primary key, name varchar(20), price decimal(10,2), created datetime, u bigint unsigned)",
+	} {
+		_, err = s.db.Exec(stmt)
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *testFederatedSuite) TearDownSuite(c *C) {
+	s.db.Close()
+	mu.Lock()
+	for dsn, db := range dbs {
+		db.Close()
+		delete(dbs, dsn)
+	}
+	mu.Unlock()
+	s.server.Close()
+	s.store.Close()
+}
+
+// The helpers shared by the tests of the storage engines.
+var (
+	column       = enginetest.Column
+	newFunc      = enginetest.NewFunc
+	constant     = enginetest.Constant
+	datumsString = enginetest.DatumsString
+)
+
+func newTableInfo(conn string) *model.TableInfo {
+	tblInfo := enginetest.NewTableInfo(Name, map[string]string{"connection": conn},
+		enginetest.NewColumn("id", types.NewFieldType(mysql.TypeLonglong)),
+		enginetest.NewColumn("Name", types.NewFieldType(mysql.TypeVarchar)),
+		enginetest.NewColumn("price", types.NewFieldType(mysql.TypeNewDecimal)),
+		enginetest.NewColumn("created", types.NewFieldType(mysql.TypeDatetime)),
+		enginetest.NewColumn("u", types.NewFieldType(mysql.TypeLonglong)),
+	)
+	tblInfo.Columns[1].Flen = 20
+	tblInfo.Columns[2].Flen, tblInfo.Columns[2].Decimal = 10, 2
+	tblInfo.Columns[4].Flag |= mysql.UnsignedFlag
+	return tblInfo
+}
+
+func (s *testFederatedSuite) openSource(c *C, table string) *source {
+	src, err := Engine{}.Open(newTableInfo(fmt.Sprintf("mysql://root@%s/remote/%s", s.addr, table)))
+	c.Assert(err, IsNil)
+	return src.(*source)
+}
+
+func scanAll(c *C, src *source, req *engine.ScanRequest) []string {
+	rows, err := enginetest.ScanAll(c, src, req)
+	c.Assert(err, IsNil)
+	return rows
+}
+
+func (s *testFederatedSuite) TestOpen(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		conn  string
+		dsn   string
+		table string
+	}{
+		{"mysql://root@127.0.0.1/db/t", "root@tcp(127.0.0.1:3306)/db?timeout=10s", "`db`.`t`"},
+		{"mysql://u:p%40ss@[::1]:4000/d/a%60b", "u:p@ss@tcp([::1]:4000)/d?timeout=10s", "`d`.`a``b`"},
+		{"mysql://root@host/db", "", ""},
+		{"mysql://root@host/db/t/x", "", ""},
+		{"mysql://host/db/t", "", ""},
+		{"mysql://root@host/db/t?tls=true", "", ""},
+		{"http://root@host/db/t", "", ""},
+		{"", "", ""},
+	}
+	for _, t := range tests {
+		src, err := Engine{}.Open(newTableInfo(t.conn))
+		if t.dsn == "" {
+			c.Assert(engine.ErrInvalidOption.Equal(err), IsTrue, Commentf("%s", t.conn))
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Assert(src.(*source).dsn, Equals, t.dsn)
+		c.Assert(src.(*source).table, Equals, t.table)
+	}
+
+	tblInfo := newTableInfo("mysql://root@host/db/t")
+	tblInfo.EngineOptions["location"] = "/tmp"
+	_, err := Engine{}.Open(tblInfo)
+	c.Assert(engine.ErrUnknownOption.Equal(err), IsTrue)
+}
+
+func (s *testFederatedSuite) TestFederated(c *C) {
+	src := s.openSource(c, "t")
+	c.Assert(src.Capabilities(), Equals, engine.CapInsert|engine.CapPushDownFilter|engine.CapPushDownLimit)
+	ctx := mock.NewContext()
+	rows := [][]interface{}{
+		{1, "apple", "1.50", "2017-01-02 10:00:00", 1},
+		{2, "Apple", nil, "2017-01-03 00:00:00", uint64(18446744073709551615)},
+		{3, "b'a\\n", "-2.25", nil, 3},
+		{4, nil, "100", "2017-02-01 00:00:00", nil},
+	}
+	for _, row := range rows {
+		d := types.MakeDatums(row...)
+		d[2], _ = d[2].ConvertTo(ctx.GetSessionVars().StmtCtx, &src.tblInfo.Columns[2].FieldType)
+		d[3], _ = d[3].ConvertTo(ctx.GetSessionVars().StmtCtx, &src.tblInfo.Columns[3].FieldType)
+		c.Assert(src.Insert(ctx, d), IsNil)
+	}
+	err := src.Insert(ctx, types.MakeDatums(1, "dup", nil, nil, nil))
+	c.Assert(engine.ErrBackendFailed.Equal(err), IsTrue)
+
+	cols := src.tblInfo.Columns
+	all := []string{
+		"1 apple 1.50 2017-01-02 10:00:00 1",
+		"2 Apple <nil> 2017-01-03 00:00:00 18446744073709551615",
+		"3 b'a\\n -2.25 <nil> 3",
+		"4 <nil> 100.00 2017-02-01 00:00:00 <nil>",
+	}
+	req := &engine.ScanRequest{Columns: cols}
+	c.Assert(scanAll(c, src, req), DeepEquals, all)
+	c.Assert(src.Describe(req), Equals, "SELECT `id`, `Name`, `price`, `created`, `u` FROM `remote`.`t`")
+
+	id, name, price, created, u := column(cols, "id"), column(cols, "name"), column(cols, "price"), column(cols, "created"), column(cols, "u")
+	feb := &expression.Constant{
+		Value:   types.NewDatum(types.Time{Time: types.FromDate(2017, 2, 1, 0, 0, 0, 0), Type: mysql.TypeDatetime}),
+		RetType: types.NewFieldType(mysql.TypeDatetime),
+	}
+	tests := []struct {
+		cond     expression.Expression
+		describe string
+		rows     []int
+	}{
+		{newFunc(c, ast.EQ, name, constant("apple")), "`Name` = BINARY ?, args:['apple']", []int{1}},
+		{newFunc(c, ast.EQ, name, constant("b'a\\n")), "`Name` = BINARY ?, args:['b''a\\\\n']", []int{3}},
+		{newFunc(c, ast.GT, constant(2), id), "`id` < ?, args:[2]", []int{1}},
+		{newFunc(c, ast.GE, price, constant(types.NewDecFromStringForTest("1.5"))), "`price` >= 1.5", []int{1, 4}},
+		{newFunc(c, ast.LT, created, feb), "`created` < ?, args:['2017-02-01 00:00:00']", []int{1, 2}},
+		{newFunc(c, ast.NullEQ, u, constant(nil)), "", nil},
+		{newFunc(c, ast.UnaryNot, newFunc(c, ast.IsNull, u)), "NOT (`u` IS NULL)", []int{1, 2, 3}},
+		{newFunc(c, ast.LogicOr, newFunc(c, ast.IsNull, name), newFunc(c, ast.NE, id, constant(1))),
+			"(`Name` IS NULL OR `id` <> ?), args:[1]", []int{2, 3, 4}},
+		// LIKE is case insensitive in TiDB, it can't be translated independently of the remote collation.
+		{newFunc(c, ast.Like, name, constant("A%"), constant(int64('\\'))), "", nil},
+		{newFunc(c, ast.EQ, id, constant("1")), "`id` = ?, args:[1]", []int{1}},
+		{newFunc(c, ast.EQ, name, constant(1)), "", nil},
+		{newFunc(c, ast.GT, u, constant(uint64(18446744073709551615))), "", nil},
+		{newFunc(c, ast.EQ, id, price), "", nil},
+		{newFunc(c, ast.LogicAnd, newFunc(c, ast.IsNull, name), newFunc(c, ast.EQ, name, id)), "", nil},
+	}
+	for _, t := range tests {
+		pushed, remained := src.PushDown([]expression.Expression{t.cond})
+		if t.describe == "" {
+			c.Assert(pushed, HasLen, 0, Commentf("%s", t.cond))
+			c.Assert(remained, HasLen, 1)
+			continue
+		}
+		c.Assert(pushed, HasLen, 1, Commentf("%s", t.cond))
+		c.Assert(remained, HasLen, 0)
+		req := &engine.ScanRequest{Columns: cols, Conditions: pushed}
+		c.Assert(src.Describe(req), Equals, "SELECT `id`, `Name`, `price`, `created`, `u` FROM `remote`.`t` WHERE "+t.describe)
+		var expected []string
+		for _, i := range t.rows {
+			expected = append(expected, all[i-1])
+		}
+		c.Assert(scanAll(c, src, req), DeepEquals, expected, Commentf("%s", t.cond))
+	}
+
+	conds := []expression.Expression{
+		newFunc(c, ast.GT, id, constant(1)),
+		newFunc(c, ast.NE, name, constant("x")),
+	}
+	req = &engine.ScanRequest{Columns: []*model.ColumnInfo{cols[1], cols[0]}, Conditions: conds, Limit: 1}
+	c.Assert(src.Describe(req), Equals, "SELECT `Name`, `id` FROM `remote`.`t` WHERE `id` > ? AND `Name` <> BINARY ? LIMIT 1, args:[1, 'x']")
+	c.Assert(scanAll(c, src, req), DeepEquals, []string{"Apple 2"})
+
+	req = &engine.ScanRequest{Limit: 3}
+	c.Assert(src.Describe(req), Equals, "SELECT 1 FROM `remote`.`t` LIMIT 3")
+	c.Assert(scanAll(c, src, req), DeepEquals, []string{"", "", ""})
+
+	missing := s.openSource(c, "missing")
+	_, err = missing.Scan(ctx, &engine.ScanRequest{Columns: cols})
+	c.Assert(engine.ErrBackendFailed.Equal(err), IsTrue)
+	c.Assert(err, ErrorMatches, ".*remote.missing.*doesn't exist.*")
+}
@@ -0,0 +1,201 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package federated
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var compareOps = map[string]string{
+	ast.EQ:     "=",
+	ast.NE:     "<>",
+	ast.LT:     "<",
+	ast.LE:     "<=",
+	ast.GT:     ">",
+	ast.GE:     ">=",
+	ast.NullEQ: "<=>",
+}
+
+// flippedOps maps a comparison to the one with the arguments swapped.
+var flippedOps = map[string]string{
+	ast.EQ:     ast.EQ,
+	ast.NE:     ast.NE,
+	ast.LT:     ast.GT,
+	ast.LE:     ast.GE,
+	ast.GT:     ast.LT,
+	ast.GE:     ast.LE,
+	ast.NullEQ: ast.NullEQ,
+}
+
+// convertCond appends the SQL of a filter to b, which is satisfied by exactly the rows satisfying the filter.
+// It returns false if the filter can't be translated, b is left incomplete in that case.
+func (s *source) convertCond(b *sqlBuilder, expr expression.Expression) bool {
+	f, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	args := f.GetArgs()
+	switch f.FuncName.L {
+	case ast.LogicAnd, ast.LogicOr:
+		b.buf.WriteString("(")
+		if !s.convertCond(b, args[0]) {
+			return false
+		}
+		if f.FuncName.L == ast.LogicAnd {
+			b.buf.WriteString(" AND ")
+		} else {
+			b.buf.WriteString(" OR ")
+		}
+		if !s.convertCond(b, args[1]) {
+			return false
+		}
+		b.buf.WriteString(")")
+		return true
+	case ast.UnaryNot:
+		b.buf.WriteString("NOT (")
+		if !s.convertCond(b, args[0]) {
+			return false
+		}
+		b.buf.WriteString(")")
+		return true
+	case ast.IsNull:
+		col, ok := s.columnOf(args[0])
+		if !ok {
+			return false
+		}
+		b.buf.WriteString(quoteName(col.Name.O) + " IS NULL")
+		return true
+	case ast.EQ, ast.NE, ast.LT, ast.LE, ast.GT, ast.GE, ast.NullEQ:
+		op := f.FuncName.L
+		col, ok := s.columnOf(args[0])
+		val := args[1]
+		if !ok {
+			col, ok = s.columnOf(args[1])
+			val, op = args[0], flippedOps[op]
+		}
+		if !ok {
+			return false
+		}
+		b.buf.WriteString(quoteName(col.Name.O) + " " + compareOps[op] + " ")
+		return b.writeConstant(col, val)
+	}
+	return false
+}
+
+// columnOf returns the column info if expr is a column of the table.
+func (s *source) columnOf(expr expression.Expression) (*model.ColumnInfo, bool) {
+	col, ok := expr.(*expression.Column)
+	if !ok {
+		return nil, false
+	}
+	for _, info := range s.tblInfo.Columns {
+		if info.Name.L == col.ColName.L {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// writeConstant appends a placeholder of expr to b if it is a constant which is compared with the column
+// by the remote server the way TiDB compares them.
+func (b *sqlBuilder) writeConstant(col *model.ColumnInfo, expr expression.Expression) bool {
+	c, ok := expr.(*expression.Constant)
+	if !ok {
+		return false
+	}
+	switch c.Value.Kind() {
+	case types.KindString, types.KindBytes:
+		if !isStringType(col.Tp) {
+			return false
+		}
+		// TiDB compares strings by their bytes, the remote column may have a case insensitive collation.
+		b.buf.WriteString("BINARY ?")
+		b.args = append(b.args, c.Value.GetString())
+		return true
+	case types.KindInt64, types.KindFloat32, types.KindFloat64:
+		if !isNumericType(col.Tp) {
+			return false
+		}
+	case types.KindMysqlDecimal:
+		if !isNumericType(col.Tp) {
+			return false
+		}
+		// A string argument would be compared as a double, the literal is compared as a decimal.
+		b.buf.WriteString(c.Value.GetMysqlDecimal().String())
+		return true
+	case types.KindUint64:
+		// The driver doesn't accept the arguments larger than math.MaxInt64.
+		if !isNumericType(col.Tp) || c.Value.GetUint64() > math.MaxInt64 {
+			return false
+		}
+	case types.KindMysqlTime:
+		if !types.IsTypeTime(col.Tp) {
+			return false
+		}
+	case types.KindMysqlDuration:
+		if col.Tp != mysql.TypeDuration {
+			return false
+		}
+	default:
+		return false
+	}
+	arg, err := datumToArg(c.Value)
+	if err != nil {
+		return false
+	}
+	b.buf.WriteString("?")
+	b.args = append(b.args, arg)
+	return true
+}
+
+func isStringType(tp byte) bool {
+	return types.IsTypeChar(tp) || types.IsTypeVarchar(tp) || types.IsTypeBlob(tp)
+}
+
+func isNumericType(tp byte) bool {
+	if tp == mysql.TypeBit || tp == mysql.TypeYear {
+		return false
+	}
+	ft := types.NewFieldType(tp)
+	return ft.ToClass() != types.ClassString
+}
+
+// datumToArg returns the argument of a statement for a datum.
+func datumToArg(d types.Datum) (interface{}, error) {
+	switch d.Kind() {
+	case types.KindNull:
+		return nil, nil
+	case types.KindInt64:
+		return d.GetInt64(), nil
+	case types.KindUint64:
+		if d.GetUint64() > math.MaxInt64 {
+			return strconv.FormatUint(d.GetUint64(), 10), nil
+		}
+		return int64(d.GetUint64()), nil
+	case types.KindFloat32, types.KindFloat64:
+		return d.GetFloat64(), nil
+	case types.KindBytes:
+		return d.GetBytes(), nil
+	}
+	str, err := d.ToString()
+	return str, errors.Trace(err)
+}
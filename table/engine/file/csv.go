@@ -0,0 +1,73 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// csvNull is the field value of NULL, as written by SELECT ... INTO OUTFILE.
+const csvNull = `\N`
+
+type csvReader struct {
+	it     *fileIter
+	name   string
+	file   io.ReadCloser
+	reader *csv.Reader
+}
+
+func (it *fileIter) openCSV(obj object) (rowReader, error) {
+	f, err := it.storage.open(obj)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	r := csv.NewReader(bufio.NewReader(f))
+	r.FieldsPerRecord = it.numColumns
+	return &csvReader{it: it, name: obj.name, file: f, reader: r}, nil
+}
+
+func (r *csvReader) next() ([]types.Datum, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fileError(r.name, err)
+	}
+	sc := r.it.ctx.GetSessionVars().StmtCtx
+	row := make([]types.Datum, 0, len(r.it.req.Columns))
+	for _, col := range r.it.req.Columns {
+		field := record[col.Offset]
+		if field == csvNull {
+			row = append(row, types.Datum{})
+			continue
+		}
+		d := types.NewStringDatum(field)
+		d, err := d.ConvertTo(sc, &col.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row = append(row, d)
+	}
+	return row, nil
+}
+
+func (r *csvReader) close() error {
+	return errors.Trace(r.file.Close())
+}
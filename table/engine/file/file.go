@@ -0,0 +1,258 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file implements the read-only File storage engine, which queries CSV and Parquet files in place.
+//
+// A table created with ENGINE=File LOCATION='<location>' FORMAT='CSV'|'PARQUET' reads the files at the location.
+// A local location is an absolute path of a file, a directory or a glob pattern, the files are read with the
+// privileges of the tidb-server process. An S3 location is s3://bucket/key for an object, or s3://bucket/prefix/
+// for all the objects under the prefix. The region and an S3 compatible endpoint can be set with the region and
+// endpoint query parameters, the credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables. The files of a directory or a prefix whose names start with '.' or '_'
+// are skipped. FORMAT can be omitted if the location ends with .csv or .parquet.
+//
+// The fields of a CSV record are the columns of the table in order, \N is NULL. The columns of a Parquet file are
+// matched with the table columns by name and only the columns used by a query are read. Filters comparing columns
+// with constants are pushed down to skip Parquet row groups by their statistics. A whole row group is decoded in
+// memory, nested columns are not supported.
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Name is the name of the engine.
+const Name = "File"
+
+// File formats.
+const (
+	formatCSV     = "csv"
+	formatParquet = "parquet"
+)
+
+// Engine is the File storage engine.
+type Engine struct{}
+
+// Name implements engine.Engine Name interface.
+func (e Engine) Name() string {
+	return Name
+}
+
+// Open implements engine.Engine Open interface.
+func (e Engine) Open(tblInfo *model.TableInfo) (engine.Source, error) {
+	for name := range tblInfo.EngineOptions {
+		if name != "location" && name != "format" {
+			return nil, engine.ErrUnknownOption.GenByArgs(name, Name)
+		}
+	}
+	location := tblInfo.EngineOptions["location"]
+	st, err := newStorage(location)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	format, ok := tblInfo.EngineOptions["format"]
+	if !ok {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(st.path())), ".")
+	}
+	format = strings.ToLower(format)
+	if format != formatCSV && format != formatParquet {
+		return nil, engine.ErrInvalidOption.GenByArgs(tblInfo.EngineOptions["format"], "format", Name)
+	}
+	return &source{location: location, format: format, storage: st, numColumns: len(tblInfo.Columns)}, nil
+}
+
+// newStorage returns the storage of the location, it doesn't access the files.
+func newStorage(location string) (storage, error) {
+	if strings.HasPrefix(location, "s3://") {
+		u, err := url.Parse(location)
+		if err != nil || u.Host == "" {
+			return nil, engine.ErrInvalidOption.GenByArgs(location, "location", Name)
+		}
+		st := &s3Storage{bucket: u.Host, key: strings.TrimPrefix(u.Path, "/")}
+		for name, values := range u.Query() {
+			switch name {
+			case "region":
+				st.region = values[0]
+			case "endpoint":
+				st.endpoint = strings.TrimSuffix(values[0], "/")
+			default:
+				return nil, engine.ErrInvalidOption.GenByArgs(location, "location", Name)
+			}
+		}
+		return st, nil
+	}
+	if !filepath.IsAbs(location) {
+		return nil, engine.ErrInvalidOption.GenByArgs(location, "location", Name)
+	}
+	return localStorage(location), nil
+}
+
+type source struct {
+	location   string
+	format     string
+	storage    storage
+	numColumns int
+}
+
+// Capabilities implements engine.Source Capabilities interface.
+func (s *source) Capabilities() engine.Capability {
+	if s.format == formatParquet {
+		return engine.CapPushDownFilter | engine.CapPushDownLimit
+	}
+	return engine.CapPushDownLimit
+}
+
+// PushDown implements engine.Source PushDown interface.
+// The pushed down conditions are also evaluated on every row, the statistics only need to tell the row groups
+// which can't contain matching rows.
+func (s *source) PushDown(conds []expression.Expression) (pushed, remained []expression.Expression) {
+	for _, cond := range conds {
+		if canPrune(cond) {
+			pushed = append(pushed, cond)
+		} else {
+			remained = append(remained, cond)
+		}
+	}
+	return
+}
+
+// CanPushDownAgg implements engine.Source CanPushDownAgg interface.
+func (s *source) CanPushDownAgg(aggFuncs []expression.AggregationFunction, groupBy []expression.Expression) bool {
+	return false
+}
+
+// Scan implements engine.Source Scan interface.
+func (s *source) Scan(ctx context.Context, req *engine.ScanRequest) (engine.RowIter, error) {
+	it := &fileIter{source: s, ctx: ctx, req: req}
+	if s.format == formatParquet {
+		it.open = it.openParquet
+	} else {
+		it.open = it.openCSV
+	}
+	return it, nil
+}
+
+// Insert implements engine.Source Insert interface.
+func (s *source) Insert(ctx context.Context, row []types.Datum) error {
+	return engine.ErrUnsupportedOp.GenByArgs(Name, "INSERT")
+}
+
+// Describe implements engine.Source Describe interface.
+func (s *source) Describe(req *engine.ScanRequest) string {
+	buf := bytes.NewBufferString(fmt.Sprintf("format:%s, location:%s", s.format, s.location))
+	if s.format == formatParquet && len(req.Columns) > 0 {
+		names := make([]string, 0, len(req.Columns))
+		for _, col := range req.Columns {
+			names = append(names, col.Name.O)
+		}
+		buf.WriteString(fmt.Sprintf(", columns:%s", strings.Join(names, ",")))
+	}
+	if len(req.Conditions) > 0 {
+		buf.WriteString(fmt.Sprintf(", filter:%s", expression.ExplainExpressionList(req.Conditions)))
+	}
+	if req.Limit > 0 {
+		buf.WriteString(fmt.Sprintf(", limit:%d", req.Limit))
+	}
+	return buf.String()
+}
+
+// rowReader reads the rows of one file.
+type rowReader interface {
+	// next returns the next row of the file, or nil at the end of the file.
+	next() ([]types.Datum, error)
+	close() error
+}
+
+// fileIter reads the files of the location one after another.
+type fileIter struct {
+	*source
+	ctx  context.Context
+	req  *engine.ScanRequest
+	open func(obj object) (rowReader, error)
+
+	started  bool
+	objects  []object
+	reader   rowReader
+	returned uint64
+}
+
+// Next implements engine.RowIter Next interface.
+func (it *fileIter) Next() ([]types.Datum, error) {
+	if !it.started {
+		it.started = true
+		objects, err := it.storage.list()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		it.objects = objects
+	}
+	for it.req.Limit == 0 || it.returned < it.req.Limit {
+		if it.reader == nil {
+			if len(it.objects) == 0 {
+				return nil, nil
+			}
+			reader, err := it.open(it.objects[0])
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			it.objects = it.objects[1:]
+			it.reader = reader
+		}
+		row, err := it.reader.next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			err = it.reader.close()
+			it.reader = nil
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
+		match, err := expression.EvalBool(it.req.Conditions, row, it.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if match {
+			it.returned++
+			return row, nil
+		}
+	}
+	return nil, nil
+}
+
+// Close implements engine.RowIter Close interface.
+func (it *fileIter) Close() error {
+	if it.reader == nil {
+		return nil
+	}
+	err := it.reader.close()
+	it.reader = nil
+	return errors.Trace(err)
+}
+
+func fileError(name string, err error) error {
+	return engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("%s: %v", name, err))
+}
@@ -0,0 +1,782 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var parquetMagic = []byte("PAR1")
+
+// maxMetadataSize protects the reader from a corrupted footer.
+const maxMetadataSize = 64 << 20
+
+// Physical types.
+const (
+	typeBoolean           = 0
+	typeInt32             = 1
+	typeInt64             = 2
+	typeInt96             = 3
+	typeFloat             = 4
+	typeDouble            = 5
+	typeByteArray         = 6
+	typeFixedLenByteArray = 7
+)
+
+// Converted types, the logical types of the files written before the LogicalType field.
+const (
+	convertedUTF8            = 0
+	convertedDecimal         = 5
+	convertedDate            = 6
+	convertedTimestampMillis = 9
+	convertedTimestampMicros = 10
+	convertedUint8           = 11
+	convertedUint64          = 14
+	convertedJSON            = 19
+)
+
+// Repetition types.
+const (
+	repetitionRequired = 0
+	repetitionOptional = 1
+)
+
+// Encodings.
+const (
+	encodingPlain           = 0
+	encodingPlainDictionary = 2
+	encodingRLE             = 3
+	encodingRLEDictionary   = 8
+)
+
+// Compression codecs.
+const (
+	codecUncompressed = 0
+	codecSnappy       = 1
+	codecGzip         = 2
+)
+
+// Page types.
+const (
+	pageData       = 0
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+// Logical kinds of the values, they decide how a physical value is converted to a datum.
+const (
+	logicalNone = iota
+	logicalString
+	logicalDecimal
+	logicalDate
+	logicalTimestamp
+	logicalUnsigned
+)
+
+// columnType is the type of a leaf column of the schema.
+type columnType struct {
+	physical   int32
+	typeLength int32
+	optional   bool
+	logical    int
+	scale      int32
+	// unitsPerSecond is the unit of a timestamp.
+	unitsPerSecond int64
+	// utc means a timestamp isn't adjusted to UTC, it is shown as it is.
+	utc bool
+}
+
+// leafColumn is a column of the file, the column chunks of a row group are in the order of the leaves.
+type leafColumn struct {
+	name string
+	tp   columnType
+	// nested means the column is in a group, which isn't supported.
+	nested bool
+}
+
+type statistics struct {
+	min, max  []byte
+	hasMinMax bool
+	// deprecated means min and max are the deprecated fields, which are only valid for signed orders.
+	deprecated   bool
+	nullCount    int64
+	hasNullCount bool
+}
+
+type columnChunk struct {
+	codec                int32
+	numValues            int64
+	totalCompressedSize  int64
+	dataPageOffset       int64
+	dictionaryPageOffset int64
+	stats                statistics
+}
+
+type rowGroup struct {
+	columns []columnChunk
+	numRows int64
+}
+
+type fileMetaData struct {
+	leaves    []leafColumn
+	rowGroups []rowGroup
+}
+
+type schemaElement struct {
+	columnType
+	name        string
+	repeated    bool
+	numChildren int32
+}
+
+// readFooter reads the metadata at the end of a Parquet file.
+func readFooter(f io.ReaderAt, size int64) (*fileMetaData, error) {
+	if size < int64(2*len(parquetMagic)+4) {
+		return nil, errMalformed
+	}
+	var tail [8]byte
+	if _, err := f.ReadAt(tail[:], size-8); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !bytes.Equal(tail[4:], parquetMagic) {
+		return nil, errors.New("not a parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail[:4]))
+	if n > maxMetadataSize || n > size-int64(2*len(parquetMagic)+4) {
+		return nil, errMalformed
+	}
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, size-8-n); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return parseFileMetaData(buf)
+}
+
+func parseFileMetaData(buf []byte) (*fileMetaData, error) {
+	r := &thriftReader{buf: buf}
+	meta := &fileMetaData{}
+	var schema []schemaElement
+	r.fields(func(id int16, tp byte) {
+		switch {
+		case id == 2 && tp == thriftList:
+			n, _ := r.listHeader()
+			for i := 0; i < n && r.err == nil; i++ {
+				schema = append(schema, parseSchemaElement(r))
+			}
+		case id == 4 && tp == thriftList:
+			n, _ := r.listHeader()
+			for i := 0; i < n && r.err == nil; i++ {
+				meta.rowGroups = append(meta.rowGroups, parseRowGroup(r))
+			}
+		default:
+			r.skip(tp)
+		}
+	})
+	if r.err != nil {
+		return nil, errors.Trace(r.err)
+	}
+	if len(schema) == 0 {
+		return nil, errMalformed
+	}
+	// The schema is the depth first list of the tree, the first element is the root.
+	var walk func(i int, nested bool) int
+	walk = func(i int, nested bool) int {
+		if i >= len(schema) {
+			return i
+		}
+		el := schema[i]
+		if el.numChildren == 0 {
+			meta.leaves = append(meta.leaves, leafColumn{name: el.name, tp: el.columnType, nested: nested || el.repeated})
+			return i + 1
+		}
+		next := i + 1
+		for j := int32(0); j < el.numChildren; j++ {
+			next = walk(next, nested || i > 0)
+		}
+		return next
+	}
+	if walk(0, false) != len(schema) {
+		return nil, errMalformed
+	}
+	for _, rg := range meta.rowGroups {
+		if len(rg.columns) != len(meta.leaves) {
+			return nil, errMalformed
+		}
+	}
+	return meta, nil
+}
+
+func parseSchemaElement(r *thriftReader) schemaElement {
+	el := schemaElement{columnType: columnType{physical: -1}}
+	converted, repetition := int32(-1), int32(repetitionRequired)
+	r.fields(func(id int16, tp byte) {
+		switch {
+		case id == 1 && tp == thriftI32:
+			el.physical = r.int32()
+		case id == 2 && tp == thriftI32:
+			el.typeLength = r.int32()
+		case id == 3 && tp == thriftI32:
+			repetition = r.int32()
+		case id == 4 && tp == thriftBinary:
+			el.name = r.string()
+		case id == 5 && tp == thriftI32:
+			el.numChildren = r.int32()
+		case id == 6 && tp == thriftI32:
+			converted = r.int32()
+		case id == 7 && tp == thriftI32:
+			el.scale = r.int32()
+		case id == 10 && tp == thriftStruct:
+			parseLogicalType(r, &el.columnType)
+		default:
+			r.skip(tp)
+		}
+	})
+	el.optional = repetition == repetitionOptional
+	el.repeated = repetition > repetitionOptional
+	if el.logical != logicalNone {
+		return el
+	}
+	switch {
+	case converted == convertedUTF8 || converted == convertedJSON:
+		el.logical = logicalString
+	case converted == convertedDecimal:
+		el.logical = logicalDecimal
+	case converted == convertedDate:
+		el.logical = logicalDate
+	case converted == convertedTimestampMillis:
+		el.logical, el.unitsPerSecond = logicalTimestamp, 1000
+	case converted == convertedTimestampMicros:
+		el.logical, el.unitsPerSecond = logicalTimestamp, 1000000
+	case converted >= convertedUint8 && converted <= convertedUint64:
+		el.logical = logicalUnsigned
+	}
+	return el
+}
+
+// parseLogicalType reads the LogicalType union.
+func parseLogicalType(r *thriftReader, t *columnType) {
+	r.fields(func(id int16, tp byte) {
+		switch {
+		case (id == 1 || id == 12) && tp == thriftStruct:
+			// STRING and JSON.
+			t.logical = logicalString
+			r.skip(tp)
+		case id == 5 && tp == thriftStruct:
+			t.logical = logicalDecimal
+			r.fields(func(id int16, tp byte) {
+				if id == 1 && tp == thriftI32 {
+					t.scale = r.int32()
+				} else {
+					r.skip(tp)
+				}
+			})
+		case id == 6 && tp == thriftStruct:
+			t.logical = logicalDate
+			r.skip(tp)
+		case id == 8 && tp == thriftStruct:
+			t.logical = logicalTimestamp
+			t.utc = true
+			r.fields(func(id int16, tp byte) {
+				switch {
+				case id == 1:
+					t.utc = tp != thriftTrue
+				case id == 2 && tp == thriftStruct:
+					r.fields(func(id int16, tp byte) {
+						t.unitsPerSecond = map[int16]int64{1: 1000, 2: 1000000, 3: 1000000000}[id]
+						r.skip(tp)
+					})
+				default:
+					r.skip(tp)
+				}
+			})
+		case id == 10 && tp == thriftStruct:
+			// INTEGER, only the signedness matters.
+			r.fields(func(id int16, tp byte) {
+				if id == 2 && tp == thriftFalse {
+					t.logical = logicalUnsigned
+				}
+				r.skip(tp)
+			})
+		default:
+			r.skip(tp)
+		}
+	})
+}
+
+func parseRowGroup(r *thriftReader) rowGroup {
+	var rg rowGroup
+	r.fields(func(id int16, tp byte) {
+		switch {
+		case id == 1 && tp == thriftList:
+			n, _ := r.listHeader()
+			for i := 0; i < n && r.err == nil; i++ {
+				rg.columns = append(rg.columns, parseColumnChunk(r))
+			}
+		case id == 3 && tp == thriftI64:
+			rg.numRows = r.varint()
+		default:
+			r.skip(tp)
+		}
+	})
+	return rg
+}
+
+func parseColumnChunk(r *thriftReader) columnChunk {
+	var chunk columnChunk
+	r.fields(func(id int16, tp byte) {
+		if id != 3 || tp != thriftStruct {
+			r.skip(tp)
+			return
+		}
+		r.fields(func(id int16, tp byte) {
+			switch {
+			case id == 4 && tp == thriftI32:
+				chunk.codec = r.int32()
+			case id == 5 && tp == thriftI64:
+				chunk.numValues = r.varint()
+			case id == 7 && tp == thriftI64:
+				chunk.totalCompressedSize = r.varint()
+			case id == 9 && tp == thriftI64:
+				chunk.dataPageOffset = r.varint()
+			case id == 11 && tp == thriftI64:
+				chunk.dictionaryPageOffset = r.varint()
+			case id == 12 && tp == thriftStruct:
+				chunk.stats = parseStatistics(r)
+			default:
+				r.skip(tp)
+			}
+		})
+	})
+	return chunk
+}
+
+func parseStatistics(r *thriftReader) statistics {
+	var s statistics
+	var oldMin, oldMax []byte
+	var hasOld bool
+	r.fields(func(id int16, tp byte) {
+		switch {
+		case id == 1 && tp == thriftBinary:
+			oldMax, hasOld = r.binary(), true
+		case id == 2 && tp == thriftBinary:
+			oldMin = r.binary()
+		case id == 3 && tp == thriftI64:
+			s.nullCount, s.hasNullCount = r.varint(), true
+		case id == 5 && tp == thriftBinary:
+			s.max, s.hasMinMax = r.binary(), true
+		case id == 6 && tp == thriftBinary:
+			s.min = r.binary()
+		default:
+			r.skip(tp)
+		}
+	})
+	if !s.hasMinMax && hasOld && oldMin != nil {
+		s.min, s.max, s.hasMinMax, s.deprecated = oldMin, oldMax, true, true
+	}
+	return s
+}
+
+type pageHeader struct {
+	tp             int32
+	compressedSize int32
+	numValues      int32
+	encoding       int32
+	defEncoding    int32
+	// defLength and repLength are the sizes of the uncompressed levels of a v2 data page.
+	defLength    int32
+	repLength    int32
+	isCompressed bool
+}
+
+func parsePageHeader(r *thriftReader) pageHeader {
+	h := pageHeader{isCompressed: true}
+	r.fields(func(id int16, tp byte) {
+		switch {
+		case id == 1 && tp == thriftI32:
+			h.tp = r.int32()
+		case id == 3 && tp == thriftI32:
+			h.compressedSize = r.int32()
+		case (id == 5 || id == 7) && tp == thriftStruct:
+			// The data and the dictionary page headers start with the same fields.
+			r.fields(func(id int16, tp byte) {
+				switch {
+				case id == 1 && tp == thriftI32:
+					h.numValues = r.int32()
+				case id == 2 && tp == thriftI32:
+					h.encoding = r.int32()
+				case id == 3 && tp == thriftI32:
+					h.defEncoding = r.int32()
+				default:
+					r.skip(tp)
+				}
+			})
+		case id == 8 && tp == thriftStruct:
+			r.fields(func(id int16, tp byte) {
+				switch {
+				case id == 1 && tp == thriftI32:
+					h.numValues = r.int32()
+				case id == 4 && tp == thriftI32:
+					h.encoding = r.int32()
+				case id == 5 && tp == thriftI32:
+					h.defLength = r.int32()
+				case id == 6 && tp == thriftI32:
+					h.repLength = r.int32()
+				case id == 7:
+					h.isCompressed = tp == thriftTrue
+				default:
+					r.skip(tp)
+				}
+			})
+		default:
+			r.skip(tp)
+		}
+	})
+	return h
+}
+
+func decompressPage(codec int32, data []byte) ([]byte, error) {
+	switch codec {
+	case codecUncompressed:
+		return data, nil
+	case codecSnappy:
+		b, err := snappy.Decode(nil, data)
+		return b, errors.Trace(err)
+	case codecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		return b, errors.Trace(err)
+	}
+	return nil, errors.Errorf("unsupported compression codec %d", codec)
+}
+
+// columnReader decodes the pages of a column chunk.
+type columnReader struct {
+	tp      columnType
+	codec   int32
+	convert func(types.Datum) (types.Datum, error)
+	dict    []types.Datum
+	values  []types.Datum
+}
+
+// readColumn returns the values of a column chunk converted to the column of the table.
+func readColumn(f io.ReaderAt, leaf leafColumn, chunk columnChunk, numRows int64,
+	convert func(types.Datum) (types.Datum, error)) ([]types.Datum, error) {
+	start := chunk.dataPageOffset
+	if chunk.dictionaryPageOffset > 0 && chunk.dictionaryPageOffset < start {
+		start = chunk.dictionaryPageOffset
+	}
+	if chunk.totalCompressedSize <= 0 || chunk.totalCompressedSize > maxMetadataSize*16 || start < 0 {
+		return nil, errMalformed
+	}
+	buf := make([]byte, chunk.totalCompressedSize)
+	if _, err := f.ReadAt(buf, start); err != nil {
+		return nil, errors.Trace(err)
+	}
+	cr := &columnReader{tp: leaf.tp, codec: chunk.codec, convert: convert, values: make([]types.Datum, 0, numRows)}
+	for int64(len(cr.values)) < numRows {
+		if len(buf) == 0 {
+			return nil, errors.Errorf("column %s has %d values, expected %d", leaf.name, len(cr.values), numRows)
+		}
+		r := &thriftReader{buf: buf}
+		h := parsePageHeader(r)
+		if r.err != nil || h.compressedSize < 0 || int(h.compressedSize) > len(r.buf) {
+			return nil, errMalformed
+		}
+		page := r.buf[:h.compressedSize]
+		buf = r.buf[h.compressedSize:]
+		if err := cr.readPage(h, page); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if int64(len(cr.values)) != numRows {
+		return nil, errors.Errorf("column %s has %d values, expected %d", leaf.name, len(cr.values), numRows)
+	}
+	return cr.values, nil
+}
+
+func (cr *columnReader) readPage(h pageHeader, page []byte) error {
+	switch h.tp {
+	case pageDictionary:
+		data, err := decompressPage(cr.codec, page)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		dict, err := cr.plain(data, int(h.numValues))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cr.dict = dict
+		return nil
+	case pageData:
+		data, err := decompressPage(cr.codec, page)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var defLevels []uint32
+		if cr.tp.optional {
+			if h.defEncoding != encodingRLE {
+				return errors.Errorf("unsupported definition level encoding %d", h.defEncoding)
+			}
+			if len(data) < 4 {
+				return errMalformed
+			}
+			n := binary.LittleEndian.Uint32(data)
+			if uint64(n) > uint64(len(data)-4) {
+				return errMalformed
+			}
+			defLevels, err = decodeHybrid(data[4:4+n], 1, int(h.numValues))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			data = data[4+n:]
+		}
+		return errors.Trace(cr.readValues(h, defLevels, data))
+	case pageDataV2:
+		if h.repLength != 0 {
+			return errors.New("repeated columns are not supported")
+		}
+		if h.defLength < 0 || int(h.defLength) > len(page) {
+			return errMalformed
+		}
+		var defLevels []uint32
+		if cr.tp.optional {
+			var err error
+			defLevels, err = decodeHybrid(page[:h.defLength], 1, int(h.numValues))
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		data := page[h.defLength:]
+		if h.isCompressed {
+			var err error
+			if data, err = decompressPage(cr.codec, data); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return errors.Trace(cr.readValues(h, defLevels, data))
+	}
+	// Index pages are skipped.
+	return nil
+}
+
+// readValues appends the values of a data page, the values of nulls are missing from the data.
+func (cr *columnReader) readValues(h pageHeader, defLevels []uint32, data []byte) error {
+	n := int(h.numValues)
+	if defLevels != nil {
+		n = 0
+		for _, level := range defLevels {
+			n += int(level)
+		}
+	}
+	var values []types.Datum
+	var err error
+	switch {
+	case h.encoding == encodingPlain:
+		values, err = cr.plain(data, n)
+	case h.encoding == encodingPlainDictionary || h.encoding == encodingRLEDictionary:
+		values, err = cr.dictionary(data, n)
+	case h.encoding == encodingRLE && cr.tp.physical == typeBoolean:
+		values, err = cr.rleBooleans(data, n)
+	default:
+		err = errors.Errorf("unsupported encoding %d", h.encoding)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if defLevels == nil {
+		cr.values = append(cr.values, values...)
+		return nil
+	}
+	for _, level := range defLevels {
+		if level == 0 {
+			cr.values = append(cr.values, types.Datum{})
+		} else {
+			cr.values = append(cr.values, values[0])
+			values = values[1:]
+		}
+	}
+	return nil
+}
+
+func (cr *columnReader) dictionary(data []byte, n int) ([]types.Datum, error) {
+	if len(data) == 0 {
+		if n == 0 {
+			return nil, nil
+		}
+		return nil, errMalformed
+	}
+	indexes, err := decodeHybrid(data[1:], int(data[0]), n)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	values := make([]types.Datum, 0, n)
+	for _, i := range indexes {
+		if int(i) >= len(cr.dict) {
+			return nil, errMalformed
+		}
+		values = append(values, cr.dict[i])
+	}
+	return values, nil
+}
+
+func (cr *columnReader) rleBooleans(data []byte, n int) ([]types.Datum, error) {
+	if len(data) < 4 {
+		return nil, errMalformed
+	}
+	size := binary.LittleEndian.Uint32(data)
+	if uint64(size) > uint64(len(data)-4) {
+		return nil, errMalformed
+	}
+	bits, err := decodeHybrid(data[4:4+size], 1, n)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	values := make([]types.Datum, 0, n)
+	for _, b := range bits {
+		d, err := cr.convert(types.NewIntDatum(int64(b)))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		values = append(values, d)
+	}
+	return values, nil
+}
+
+// plain decodes n values in the plain encoding and converts them.
+func (cr *columnReader) plain(data []byte, n int) ([]types.Datum, error) {
+	values := make([]types.Datum, 0, n)
+	for i := 0; i < n; i++ {
+		var raw []byte
+		size := cr.tp.plainSize()
+		switch {
+		case cr.tp.physical == typeBoolean:
+			if i/8 >= len(data) {
+				return nil, errMalformed
+			}
+			raw = []byte{data[i/8] >> uint(i%8) & 1}
+		case cr.tp.physical == typeByteArray:
+			if len(data) < 4 {
+				return nil, errMalformed
+			}
+			size = int(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+			fallthrough
+		default:
+			if size < 0 || size > len(data) {
+				return nil, errMalformed
+			}
+			raw, data = data[:size], data[size:]
+		}
+		d, err := cr.convert(cr.tp.datum(raw))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		values = append(values, d)
+	}
+	return values, nil
+}
+
+// plainSize returns the size of a plain encoded value, or -1 if it isn't fixed.
+func (t *columnType) plainSize() int {
+	switch t.physical {
+	case typeInt32, typeFloat:
+		return 4
+	case typeInt64, typeDouble:
+		return 8
+	case typeInt96:
+		return 12
+	case typeFixedLenByteArray:
+		return int(t.typeLength)
+	}
+	return -1
+}
+
+// decodeHybrid decodes n values of the RLE/bit-packing hybrid encoding.
+func decodeHybrid(data []byte, bitWidth, n int) ([]uint32, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, errMalformed
+	}
+	values := make([]uint32, 0, n)
+	byteWidth := (bitWidth + 7) / 8
+	for len(values) < n {
+		header, k := binary.Uvarint(data)
+		if k <= 0 {
+			return nil, errMalformed
+		}
+		data = data[k:]
+		if header&1 == 0 {
+			// A run of the same value.
+			count := header >> 1
+			if len(data) < byteWidth {
+				return nil, errMalformed
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(data[i]) << uint(8*i)
+			}
+			data = data[byteWidth:]
+			for ; count > 0 && len(values) < n; count-- {
+				values = append(values, v)
+			}
+			continue
+		}
+		// Groups of 8 bit packed values, from the least significant bit.
+		groups := header >> 1
+		if groups*uint64(bitWidth) > uint64(len(data)) {
+			return nil, errMalformed
+		}
+		size := int(groups) * bitWidth
+		packed := data[:size]
+		data = data[size:]
+		for i := 0; i < int(groups)*8 && len(values) < n; i++ {
+			var v uint32
+			for b := 0; b < bitWidth; b++ {
+				bit := i*bitWidth + b
+				v |= uint32(packed[bit/8]>>uint(bit%8)&1) << uint(b)
+			}
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// findLeaf returns the index of the leaf column matching the column of the table by name.
+func findLeaf(meta *fileMetaData, col *model.ColumnInfo) (int, error) {
+	for i, leaf := range meta.leaves {
+		if !strings.EqualFold(leaf.name, col.Name.O) {
+			continue
+		}
+		if leaf.nested {
+			return 0, errors.Errorf("column %s is nested, which is not supported", leaf.name)
+		}
+		if leaf.tp.physical < typeBoolean || leaf.tp.physical > typeFixedLenByteArray {
+			return 0, errors.Errorf("column %s has unsupported type %d", leaf.name, leaf.tp.physical)
+		}
+		return i, nil
+	}
+	return 0, errors.Errorf("column %s is not found", col.Name.O)
+}
@@ -0,0 +1,201 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// julianUnixEpoch is the julian day of 1970-01-01, INT96 timestamps are julian days and nanoseconds of the day.
+const julianUnixEpoch = 2440588
+
+// parquetReader reads the row groups of a Parquet file, it skips the row groups whose statistics
+// show they have no rows satisfying the conditions.
+type parquetReader struct {
+	it     *fileIter
+	name   string
+	file   readerAt
+	meta   *fileMetaData
+	leaves []int
+
+	rowGroup int
+	columns  [][]types.Datum
+	row      int
+	numRows  int
+}
+
+func (it *fileIter) openParquet(obj object) (rowReader, error) {
+	f, err := it.storage.openRandom(obj)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	meta, err := readFooter(f, obj.size)
+	if err != nil {
+		f.Close()
+		return nil, fileError(obj.name, err)
+	}
+	leaves := make([]int, 0, len(it.req.Columns))
+	for _, col := range it.req.Columns {
+		leaf, err := findLeaf(meta, col)
+		if err != nil {
+			f.Close()
+			return nil, fileError(obj.name, err)
+		}
+		leaves = append(leaves, leaf)
+	}
+	return &parquetReader{it: it, name: obj.name, file: f, meta: meta, leaves: leaves}, nil
+}
+
+func (r *parquetReader) next() ([]types.Datum, error) {
+	for r.row >= r.numRows {
+		if r.rowGroup >= len(r.meta.rowGroups) {
+			return nil, nil
+		}
+		rg := r.meta.rowGroups[r.rowGroup]
+		r.rowGroup++
+		if !r.mayMatch(rg) {
+			continue
+		}
+		if err := r.readRowGroup(rg); err != nil {
+			return nil, fileError(r.name, err)
+		}
+	}
+	row := make([]types.Datum, len(r.columns))
+	for i, values := range r.columns {
+		row[i] = values[r.row]
+	}
+	r.row++
+	return row, nil
+}
+
+func (r *parquetReader) readRowGroup(rg rowGroup) error {
+	r.columns = make([][]types.Datum, 0, len(r.leaves))
+	for i, leaf := range r.leaves {
+		values, err := readColumn(r.file, r.meta.leaves[leaf], rg.columns[leaf], rg.numRows, r.converter(r.it.req.Columns[i]))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		r.columns = append(r.columns, values)
+	}
+	r.row, r.numRows = 0, int(rg.numRows)
+	return nil
+}
+
+func (r *parquetReader) converter(col *model.ColumnInfo) func(types.Datum) (types.Datum, error) {
+	sc := r.it.ctx.GetSessionVars().StmtCtx
+	return func(d types.Datum) (types.Datum, error) {
+		d, err := d.ConvertTo(sc, &col.FieldType)
+		return d, errors.Trace(err)
+	}
+}
+
+func (r *parquetReader) close() error {
+	return errors.Trace(r.file.Close())
+}
+
+// datum returns the datum of a plain encoded value.
+func (t *columnType) datum(raw []byte) types.Datum {
+	switch t.physical {
+	case typeBoolean:
+		return types.NewIntDatum(int64(raw[0]))
+	case typeInt32:
+		v := int32(binary.LittleEndian.Uint32(raw))
+		if t.logical == logicalUnsigned {
+			return types.NewUintDatum(uint64(uint32(v)))
+		}
+		return t.intDatum(int64(v))
+	case typeInt64:
+		v := int64(binary.LittleEndian.Uint64(raw))
+		if t.logical == logicalUnsigned {
+			return types.NewUintDatum(uint64(v))
+		}
+		return t.intDatum(v)
+	case typeInt96:
+		nanos := int64(binary.LittleEndian.Uint64(raw))
+		days := int64(binary.LittleEndian.Uint32(raw[8:])) - julianUnixEpoch
+		return timeDatum(time.Unix(days*24*3600, nanos), types.MaxFsp)
+	case typeFloat:
+		return types.NewFloat32Datum(math.Float32frombits(binary.LittleEndian.Uint32(raw)))
+	case typeDouble:
+		return types.NewFloat64Datum(math.Float64frombits(binary.LittleEndian.Uint64(raw)))
+	}
+	if t.logical == logicalDecimal {
+		// The unscaled value in big endian two's complement.
+		v := new(big.Int).SetBytes(raw)
+		if len(raw) > 0 && raw[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(raw))))
+		}
+		return decimalDatum(v, t.scale)
+	}
+	return types.NewBytesDatum(raw)
+}
+
+func (t *columnType) intDatum(v int64) types.Datum {
+	switch t.logical {
+	case logicalDecimal:
+		return decimalDatum(big.NewInt(v), t.scale)
+	case logicalDate:
+		y, m, d := time.Unix(v*24*3600, 0).UTC().Date()
+		return types.NewTimeDatum(types.Time{Time: types.FromDate(y, int(m), d, 0, 0, 0, 0), Type: mysql.TypeDate})
+	case logicalTimestamp:
+		if t.unitsPerSecond <= 0 {
+			break
+		}
+		sec, frac := v/t.unitsPerSecond, v%t.unitsPerSecond
+		if frac < 0 {
+			sec, frac = sec-1, frac+t.unitsPerSecond
+		}
+		tm := time.Unix(sec, frac*(int64(time.Second)/t.unitsPerSecond))
+		if t.utc {
+			tm = tm.UTC()
+		}
+		fsp := types.MaxFsp
+		if t.unitsPerSecond == 1000 {
+			fsp = 3
+		}
+		return timeDatum(tm, fsp)
+	}
+	return types.NewIntDatum(v)
+}
+
+func timeDatum(t time.Time, fsp int) types.Datum {
+	return types.NewTimeDatum(types.Time{Time: types.FromGoTime(t), Type: mysql.TypeDatetime, Fsp: fsp})
+}
+
+func decimalDatum(unscaled *big.Int, scale int32) types.Datum {
+	str := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if len(str) <= int(scale) {
+			str = strings.Repeat("0", int(scale)-len(str)+1) + str
+		}
+		str = str[:len(str)-int(scale)] + "." + str[len(str)-int(scale):]
+	}
+	if unscaled.Sign() < 0 {
+		str = "-" + str
+	}
+	dec := new(types.MyDecimal)
+	if err := dec.FromString([]byte(str)); err != nil {
+		return types.Datum{}
+	}
+	return types.NewDecimalDatum(dec)
+}
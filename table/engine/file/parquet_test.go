@@ -0,0 +1,679 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/snappy"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// thriftWriter encodes the thrift compact protocol for the test files.
+type thriftWriter struct {
+	buf  []byte
+	last []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (w *thriftWriter) field(id int16, tp byte) {
+	last := &w.last[len(w.last)-1]
+	if d := id - *last; d > 0 && d <= 15 {
+		w.buf = append(w.buf, byte(d)<<4|tp)
+	} else {
+		w.buf = append(w.buf, tp)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) binary(id int16, b []byte) {
+	w.field(id, thriftBinary)
+	w.uvarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *thriftWriter) bool(id int16, v bool) {
+	if v {
+		w.field(id, thriftTrue)
+	} else {
+		w.field(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) list(id int16, elem byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|elem)
+	} else {
+		w.buf = append(w.buf, 0xf0|elem)
+		w.uvarint(uint64(n))
+	}
+}
+
+// begin starts a struct field, push starts a struct in a list and end finishes both.
+func (w *thriftWriter) begin(id int16) {
+	w.field(id, thriftStruct)
+	w.push()
+}
+
+func (w *thriftWriter) push() {
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) end() {
+	w.buf = append(w.buf, thriftStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// parquetColumn is a column of a test file, a nil value is NULL.
+type parquetColumn struct {
+	name      string
+	physical  int32
+	converted int32
+	scale     int32
+	optional  bool
+	dict      bool
+	v2        bool
+	codec     int32
+	// deprecatedStats writes the statistics in the deprecated min and max fields.
+	deprecatedStats bool
+	values          []interface{}
+}
+
+func plainValue(v interface{}) []byte {
+	var b [8]byte
+	switch x := v.(type) {
+	case int32:
+		binary.LittleEndian.PutUint32(b[:], uint32(x))
+		return b[:4]
+	case int64:
+		binary.LittleEndian.PutUint64(b[:], uint64(x))
+		return b[:]
+	case float64:
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(x))
+		return b[:]
+	case string:
+		return []byte(x)
+	case []byte:
+		return x
+	}
+	panic(fmt.Sprintf("unexpected value %v", v))
+}
+
+func plainEncode(values []interface{}) []byte {
+	var buf []byte
+	for i, v := range values {
+		switch x := v.(type) {
+		case bool:
+			if i%8 == 0 {
+				buf = append(buf, 0)
+			}
+			if x {
+				buf[len(buf)-1] |= 1 << uint(i%8)
+			}
+		case string, []byte:
+			raw := plainValue(x)
+			var n [4]byte
+			binary.LittleEndian.PutUint32(n[:], uint32(len(raw)))
+			buf = append(buf, n[:]...)
+			buf = append(buf, raw...)
+		default:
+			buf = append(buf, plainValue(x)...)
+		}
+	}
+	return buf
+}
+
+// encodeHybrid encodes a run if all the values are equal, or bit packed groups otherwise.
+func encodeHybrid(values []uint32, bitWidth int) []byte {
+	w := &thriftWriter{}
+	same := len(values) > 0
+	for _, v := range values {
+		same = same && v == values[0]
+	}
+	if same {
+		w.uvarint(uint64(len(values)) << 1)
+		for i := 0; i < (bitWidth+7)/8; i++ {
+			w.buf = append(w.buf, byte(values[0]>>uint(8*i)))
+		}
+		return w.buf
+	}
+	groups := (len(values) + 7) / 8
+	w.uvarint(uint64(groups)<<1 | 1)
+	packed := make([]byte, groups*bitWidth)
+	for i, v := range values {
+		for b := 0; b < bitWidth; b++ {
+			if v>>uint(b)&1 == 1 {
+				bit := i*bitWidth + b
+				packed[bit/8] |= 1 << uint(bit%8)
+			}
+		}
+	}
+	return append(w.buf, packed...)
+}
+
+func compress(codec int32, data []byte) []byte {
+	switch codec {
+	case codecSnappy:
+		return snappy.Encode(nil, data)
+	case codecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(data)
+		w.Close()
+		return buf.Bytes()
+	}
+	return data
+}
+
+func less(a, b interface{}) bool {
+	switch x := a.(type) {
+	case int32:
+		return x < b.(int32)
+	case int64:
+		return x < b.(int64)
+	case float64:
+		return x < b.(float64)
+	case string:
+		return x < b.(string)
+	case bool:
+		return !x && b.(bool)
+	}
+	return false
+}
+
+type chunkMeta struct {
+	offset, dictOffset       int64
+	compressed, uncompressed int64
+	min, max                 interface{}
+	nulls                    int64
+}
+
+// writeChunk appends the pages of the values of a column chunk to buf.
+func writeChunk(buf *bytes.Buffer, col parquetColumn, values []interface{}) chunkMeta {
+	meta := chunkMeta{offset: int64(buf.Len()), dictOffset: -1}
+	var nonNull []interface{}
+	var defLevels []uint32
+	for _, v := range values {
+		if v == nil {
+			meta.nulls++
+			defLevels = append(defLevels, 0)
+			continue
+		}
+		defLevels = append(defLevels, 1)
+		nonNull = append(nonNull, v)
+		if meta.min == nil || less(v, meta.min) {
+			meta.min = v
+		}
+		if meta.max == nil || less(meta.max, v) {
+			meta.max = v
+		}
+	}
+	writePage := func(header func(w *thriftWriter, uncompressed, compressed int), uncompressed, page []byte) {
+		w := newThriftWriter()
+		header(w, len(uncompressed), len(page))
+		w.end()
+		buf.Write(w.buf)
+		buf.Write(page)
+		meta.uncompressed += int64(len(w.buf) + len(uncompressed))
+		meta.compressed += int64(len(w.buf) + len(page))
+	}
+
+	encoding := int32(encodingPlain)
+	data := plainEncode(nonNull)
+	if col.dict {
+		var dict []interface{}
+		index := make(map[interface{}]uint32)
+		var indexes []uint32
+		for _, v := range nonNull {
+			i, ok := index[v]
+			if !ok {
+				i = uint32(len(dict))
+				index[v] = i
+				dict = append(dict, v)
+			}
+			indexes = append(indexes, i)
+		}
+		dictPage := plainEncode(dict)
+		meta.dictOffset = int64(buf.Len())
+		writePage(func(w *thriftWriter, uncompressed, compressed int) {
+			w.i32(1, pageDictionary)
+			w.i32(2, int32(uncompressed))
+			w.i32(3, int32(compressed))
+			w.begin(7)
+			w.i32(1, int32(len(dict)))
+			w.i32(2, encodingPlain)
+			w.end()
+		}, dictPage, compress(col.codec, dictPage))
+		encoding = encodingRLEDictionary
+		data = append([]byte{2}, encodeHybrid(indexes, 2)...)
+	}
+	meta.offset = int64(buf.Len())
+	var levels []byte
+	if col.optional {
+		levels = encodeHybrid(defLevels, 1)
+	}
+	if col.v2 {
+		page := append(append([]byte(nil), levels...), compress(col.codec, data)...)
+		writePage(func(w *thriftWriter, uncompressed, compressed int) {
+			w.i32(1, pageDataV2)
+			w.i32(2, int32(len(levels)+len(data)))
+			w.i32(3, int32(compressed))
+			w.begin(8)
+			w.i32(1, int32(len(values)))
+			w.i32(2, int32(meta.nulls))
+			w.i32(3, int32(len(values)))
+			w.i32(4, encoding)
+			w.i32(5, int32(len(levels)))
+			w.i32(6, 0)
+			w.bool(7, col.codec != codecUncompressed)
+			w.end()
+		}, page, page)
+		return meta
+	}
+	var page []byte
+	if col.optional {
+		var n [4]byte
+		binary.LittleEndian.PutUint32(n[:], uint32(len(levels)))
+		page = append(append(page, n[:]...), levels...)
+	}
+	page = append(page, data...)
+	writePage(func(w *thriftWriter, uncompressed, compressed int) {
+		w.i32(1, pageData)
+		w.i32(2, int32(uncompressed))
+		w.i32(3, int32(compressed))
+		w.begin(5)
+		w.i32(1, int32(len(values)))
+		w.i32(2, encoding)
+		w.i32(3, encodingRLE)
+		w.i32(4, encodingRLE)
+		w.end()
+	}, page, compress(col.codec, page))
+	return meta
+}
+
+// writeParquet returns a Parquet file of the columns, which have the same number of values.
+func writeParquet(cols []parquetColumn, rowGroupSize int) []byte {
+	var buf bytes.Buffer
+	buf.Write(parquetMagic)
+	numRows := len(cols[0].values)
+	var groups [][]chunkMeta
+	for start := 0; start < numRows; start += rowGroupSize {
+		end := start + rowGroupSize
+		if end > numRows {
+			end = numRows
+		}
+		var chunks []chunkMeta
+		for _, col := range cols {
+			chunks = append(chunks, writeChunk(&buf, col, col.values[start:end]))
+		}
+		groups = append(groups, chunks)
+	}
+
+	w := newThriftWriter()
+	w.i32(1, 1)
+	w.list(2, thriftStruct, len(cols)+1)
+	w.push()
+	w.binary(4, []byte("schema"))
+	w.i32(5, int32(len(cols)))
+	w.end()
+	for _, col := range cols {
+		w.push()
+		w.i32(1, col.physical)
+		if col.optional {
+			w.i32(3, repetitionOptional)
+		} else {
+			w.i32(3, repetitionRequired)
+		}
+		w.binary(4, []byte(col.name))
+		if col.converted >= 0 {
+			w.i32(6, col.converted)
+		}
+		if col.scale > 0 {
+			w.i32(7, col.scale)
+			w.i32(8, 10)
+		}
+		w.i32(9, 1) // field_id
+		w.end()
+	}
+	w.i64(3, int64(numRows))
+	w.list(4, thriftStruct, len(groups))
+	for i, chunks := range groups {
+		w.push()
+		w.list(1, thriftStruct, len(chunks))
+		var size int64
+		for j, chunk := range chunks {
+			col := cols[j]
+			size += chunk.uncompressed
+			w.push()
+			w.i64(2, chunk.offset)
+			w.begin(3)
+			w.i32(1, col.physical)
+			w.list(2, thriftI32, 2)
+			w.varint(encodingPlain)
+			w.varint(encodingRLE)
+			w.list(3, thriftBinary, 1)
+			w.uvarint(uint64(len(col.name)))
+			w.buf = append(w.buf, col.name...)
+			w.i32(4, col.codec)
+			w.i64(5, int64(chunkRows(numRows, rowGroupSize, i)))
+			w.i64(6, chunk.uncompressed)
+			w.i64(7, chunk.compressed)
+			w.list(8, thriftStruct, 1)
+			w.push()
+			w.binary(1, []byte("k"))
+			w.end()
+			w.i64(9, chunk.offset)
+			if chunk.dictOffset >= 0 {
+				w.i64(11, chunk.dictOffset)
+			}
+			if _, isBool := chunk.min.(bool); col.scale == 0 && !isBool {
+				w.begin(12)
+				if col.deprecatedStats {
+					if chunk.max != nil {
+						w.binary(1, plainValue(chunk.max))
+						w.binary(2, plainValue(chunk.min))
+					}
+					w.i64(3, chunk.nulls)
+				} else {
+					w.i64(3, chunk.nulls)
+					if chunk.max != nil {
+						w.binary(5, plainValue(chunk.max))
+						w.binary(6, plainValue(chunk.min))
+					}
+				}
+				w.end()
+			}
+			w.end()
+			w.end()
+		}
+		w.i64(2, size)
+		w.i64(3, int64(chunkRows(numRows, rowGroupSize, i)))
+		w.end()
+	}
+	w.list(5, thriftStruct, 1)
+	w.push()
+	w.binary(1, []byte("writer"))
+	w.binary(2, []byte("test"))
+	w.end()
+	w.binary(6, []byte("tidb test"))
+	w.end()
+
+	buf.Write(w.buf)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(w.buf)))
+	buf.Write(n[:])
+	buf.Write(parquetMagic)
+	return buf.Bytes()
+}
+
+func chunkRows(numRows, rowGroupSize, i int) int {
+	if (i+1)*rowGroupSize > numRows {
+		return numRows - i*rowGroupSize
+	}
+	return rowGroupSize
+}
+
+const testDays = 17167 // 2017-01-01
+
+func testParquetColumns() []parquetColumn {
+	ts := []interface{}{}
+	for i := 0; i < 5; i++ {
+		ts = append(ts, int64(1483272000000+i*1500))
+	}
+	return []parquetColumn{
+		{name: "id", physical: typeInt64, converted: -1, values: []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5)}},
+		{name: "name", physical: typeByteArray, converted: convertedUTF8, optional: true, dict: true,
+			values: []interface{}{"apple", nil, "banana", "apple", "cherry"}},
+		{name: "score", physical: typeDouble, converted: -1, optional: true, v2: true, codec: codecSnappy,
+			values: []interface{}{1.5, 2.5, nil, 4.5, 5.5}},
+		{name: "d", physical: typeInt32, converted: convertedDate, deprecatedStats: true,
+			values: []interface{}{int32(testDays), int32(testDays + 1), int32(testDays + 2), int32(testDays + 3), int32(testDays + 4)}},
+		{name: "ts", physical: typeInt64, converted: convertedTimestampMillis, values: ts},
+		{name: "price", physical: typeInt64, converted: convertedDecimal, scale: 2,
+			values: []interface{}{int64(150), int64(-5), int64(12345), int64(0), int64(99)}},
+		{name: "flag", physical: typeBoolean, converted: -1, values: []interface{}{true, false, true, true, false}},
+		{name: "extra", physical: typeInt32, converted: -1, codec: codecGzip,
+			values: []interface{}{int32(0), int32(0), int32(0), int32(0), int32(0)}},
+		// UINT_32 with the deprecated statistics, which are not valid for it.
+		{name: "u", physical: typeInt32, converted: convertedUint8 + 2, deprecatedStats: true,
+			values: []interface{}{int32(-1), int32(1), int32(2), int32(3), int32(4)}},
+		{name: "amount", physical: typeByteArray, converted: convertedDecimal, scale: 3, optional: true,
+			values: []interface{}{[]byte{0xfb, 0x2e}, []byte{0x01}, nil, []byte{0x00, 0x80, 0x00}, []byte{}}},
+	}
+}
+
+func testParquetTable(location string) []testColumnDef {
+	unsigned := types.NewFieldType(mysql.TypeLonglong)
+	unsigned.Flag |= mysql.UnsignedFlag
+	return []testColumnDef{
+		{"id", types.NewFieldType(mysql.TypeLonglong)},
+		{"name", fieldType(mysql.TypeVarchar, 10, 0)},
+		{"score", types.NewFieldType(mysql.TypeDouble)},
+		{"d", types.NewFieldType(mysql.TypeDate)},
+		{"ts", fieldType(mysql.TypeDatetime, 23, 3)},
+		{"price", fieldType(mysql.TypeNewDecimal, 10, 2)},
+		{"flag", types.NewFieldType(mysql.TypeTiny)},
+		{"u", unsigned},
+		{"amount", fieldType(mysql.TypeNewDecimal, 10, 3)},
+	}
+}
+
+// countingStorage counts the reads of parts of the files.
+type countingStorage struct {
+	storage
+	reads int
+}
+
+func (s *countingStorage) openRandom(obj object) (readerAt, error) {
+	r, err := s.storage.openRandom(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &countingReader{readerAt: r, s: s}, nil
+}
+
+type countingReader struct {
+	readerAt
+	s *countingStorage
+}
+
+func (r *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	r.s.reads++
+	return r.readerAt.ReadAt(p, off)
+}
+
+func (s *testFileSuite) TestParquet(c *C) {
+	defer testleak.AfterTest(c)()
+	dir := c.MkDir()
+	name := filepath.Join(dir, "t.parquet")
+	c.Assert(ioutil.WriteFile(name, writeParquet(testParquetColumns(), 3), 0644), IsNil)
+	tblInfo := newTableInfo(map[string]string{"location": name}, testParquetTable(name)...)
+	src, err := Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	c.Assert(src.Capabilities(), Equals, engine.CapPushDownFilter|engine.CapPushDownLimit)
+
+	ts := func(i int) string {
+		return time.Unix(1483272000, int64(i)*1500*int64(time.Millisecond)).Format("2006-01-02 15:04:05.000")
+	}
+	got, err := scanAll(c, src, &engine.ScanRequest{Columns: tblInfo.Columns})
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, []string{
+		"1 apple 1.5 2017-01-01 " + ts(0) + " 1.50 1 4294967295 -1.234",
+		"2 <nil> 2.5 2017-01-02 " + ts(1) + " -0.05 0 1 0.001",
+		"3 banana <nil> 2017-01-03 " + ts(2) + " 123.45 1 2 <nil>",
+		"4 apple 4.5 2017-01-04 " + ts(3) + " 0.00 1 3 32.768",
+		"5 cherry 5.5 2017-01-05 " + ts(4) + " 0.99 0 4 0.000",
+	})
+
+	counting := &countingStorage{storage: src.(*source).storage}
+	src.(*source).storage = counting
+	cols := scanColumns(tblInfo, "name", "id", "score", "d")
+	id, name2, score, d := column(cols, "id"), column(cols, "name"), column(cols, "score"), column(cols, "d")
+	jan4 := &expression.Constant{
+		Value:   types.NewDatum(types.Time{Time: types.FromDate(2017, 1, 4, 0, 0, 0, 0), Type: mysql.TypeDate}),
+		RetType: types.NewFieldType(mysql.TypeDate),
+	}
+	projected := []string{
+		"apple 1 1.5 2017-01-01",
+		"<nil> 2 2.5 2017-01-02",
+		"banana 3 <nil> 2017-01-03",
+		"apple 4 4.5 2017-01-04",
+		"cherry 5 5.5 2017-01-05",
+	}
+	tests := []struct {
+		conds []expression.Expression
+		ids   []int
+		// reads is the number of reads, the footer takes two and a column chunk takes one.
+		reads int
+	}{
+		{nil, []int{1, 2, 3, 4, 5}, 2 + 2*4},
+		{[]expression.Expression{newFunc(c, ast.GT, id, constant(3))}, []int{4, 5}, 2 + 4},
+		{[]expression.Expression{newFunc(c, ast.LE, constant(int64(3)), id)}, []int{3, 4, 5}, 2 + 2*4},
+		{[]expression.Expression{newFunc(c, ast.EQ, name2, constant("zzz"))}, nil, 2},
+		{[]expression.Expression{newFunc(c, ast.NE, name2, constant("apple"))}, []int{3, 5}, 2 + 2*4},
+		{[]expression.Expression{newFunc(c, ast.IsNull, score)}, []int{3}, 2 + 4},
+		{[]expression.Expression{newFunc(c, ast.UnaryNot, newFunc(c, ast.IsNull, score))}, []int{1, 2, 4, 5}, 2 + 2*4},
+		{[]expression.Expression{newFunc(c, ast.GE, d, jan4)}, []int{4, 5}, 2 + 4},
+		{[]expression.Expression{
+			newFunc(c, ast.LogicOr, newFunc(c, ast.LT, id, constant(2)), newFunc(c, ast.GT, score, constant(5.0))),
+			newFunc(c, ast.LT, score, constant(2.0)),
+		}, []int{1}, 2 + 4},
+		{[]expression.Expression{newFunc(c, ast.LogicAnd, newFunc(c, ast.LT, id, constant(2)), newFunc(c, ast.GT, id, constant(4)))}, nil, 2},
+	}
+	for _, t := range tests {
+		pushed, remained := src.PushDown(t.conds)
+		c.Assert(remained, HasLen, 0)
+		counting.reads = 0
+		got, err := scanAll(c, src, &engine.ScanRequest{Columns: cols, Conditions: pushed})
+		c.Assert(err, IsNil)
+		var rows []string
+		for _, id := range t.ids {
+			rows = append(rows, projected[id-1])
+		}
+		c.Assert(got, DeepEquals, rows, Commentf("%v", t.conds))
+		c.Assert(counting.reads, Equals, t.reads, Commentf("%v", t.conds))
+	}
+
+	counting.reads = 0
+	got, err = scanAll(c, src, &engine.ScanRequest{Columns: cols[:1], Limit: 2})
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, []string{"apple", "<nil>"})
+	c.Assert(counting.reads, Equals, 2+1)
+
+	conds := []expression.Expression{
+		newFunc(c, ast.GT, id, constant(3)),
+		newFunc(c, ast.Like, name2, constant("a%"), constant(int64('\\'))),
+		newFunc(c, ast.EQ, id, newFunc(c, ast.Plus, score, constant(1))),
+		newFunc(c, ast.EQ, name2, constant(1)),
+	}
+	pushed, remained := src.PushDown(conds)
+	c.Assert(pushed, HasLen, 1)
+	c.Assert(remained, HasLen, 3)
+	c.Assert(src.Describe(&engine.ScanRequest{Columns: cols[:2], Conditions: pushed, Limit: 10}), Equals,
+		fmt.Sprintf("format:parquet, location:%s, columns:name,id, filter:gt(id, 3), limit:10", name))
+}
+
+func (s *testFileSuite) TestParquetErrors(c *C) {
+	defer testleak.AfterTest(c)()
+	dir := c.MkDir()
+	data := writeParquet(testParquetColumns(), 2)
+	files := map[string][]byte{
+		"csv.parquet":       []byte("1,2,3\n4,5,6\n"),
+		"short.parquet":     []byte("PAR1"),
+		"truncated.parquet": append(append([]byte{}, data[:len(data)-20]...), data[len(data)-8:]...),
+		"ok.parquet":        data,
+	}
+	for name, content := range files {
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, name), content, 0644), IsNil)
+	}
+	tests := []struct {
+		file string
+		col  string
+		err  string
+	}{
+		{"csv.parquet", "id", ".*csv.parquet: not a parquet file"},
+		{"short.parquet", "id", ".*short.parquet: malformed parquet file"},
+		{"truncated.parquet", "id", ".*truncated.parquet: malformed parquet file"},
+		{"ok.parquet", "missing", ".*ok.parquet: column missing is not found"},
+	}
+	for _, t := range tests {
+		tblInfo := newTableInfo(map[string]string{"location": filepath.Join(dir, t.file)}, testColumnDef{t.col, types.NewFieldType(mysql.TypeLong)})
+		src, err := Engine{}.Open(tblInfo)
+		c.Assert(err, IsNil)
+		_, err = scanAll(c, src, &engine.ScanRequest{Columns: tblInfo.Columns})
+		c.Assert(engine.ErrBackendFailed.Equal(err), IsTrue, Commentf("%s", t.file))
+		c.Assert(err, ErrorMatches, t.err)
+	}
+
+	// A value which doesn't fit in the column fails the scan.
+	tblInfo := newTableInfo(map[string]string{"location": filepath.Join(dir, "ok.parquet")}, testColumnDef{"u", types.NewFieldType(mysql.TypeTiny)})
+	src, err := Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	_, err = scanAll(c, src, &engine.ScanRequest{Columns: tblInfo.Columns})
+	c.Assert(err, NotNil)
+}
+
+func (s *testFileSuite) TestHybrid(c *C) {
+	defer testleak.AfterTest(c)()
+	values := []uint32{1, 0, 3, 2, 1, 3, 0, 0, 2}
+	got, err := decodeHybrid(encodeHybrid(values, 2), 2, len(values))
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, values)
+	// A run followed by bit packed values.
+	data := append(encodeHybrid([]uint32{300, 300, 300}, 9), encodeHybrid([]uint32{1, 2}, 9)...)
+	got, err = decodeHybrid(data, 9, 5)
+	c.Assert(err, IsNil)
+	c.Assert(got, DeepEquals, []uint32{300, 300, 300, 1, 2})
+	_, err = decodeHybrid(data[:len(data)-1], 9, 5)
+	c.Assert(err, Equals, errMalformed)
+	_, err = decodeHybrid(data, 33, 5)
+	c.Assert(err, Equals, errMalformed)
+}
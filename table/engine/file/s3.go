@@ -0,0 +1,272 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const (
+	defaultRegion = "us-east-1"
+	s3Timeout     = 60 * time.Second
+	// emptyPayloadHash is the SHA256 of an empty body.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Client sends the requests of the S3 REST API, they are signed with signature version 4 if there are
+// credentials. Without an endpoint the virtual hosted style URL of AWS is used, otherwise the path style
+// URL of the endpoint.
+type s3Client struct {
+	region    string
+	endpoint  string
+	accessKey string
+	secretKey string
+	token     string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func newS3Client(region, endpoint string) *s3Client {
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = defaultRegion
+	}
+	return &s3Client{
+		region:    region,
+		endpoint:  endpoint,
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		client:    &http.Client{Timeout: s3Timeout},
+		now:       time.Now,
+	}
+}
+
+func (c *s3Client) url(bucket, key string, query url.Values) string {
+	var u string
+	if c.endpoint == "" {
+		u = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, c.region, s3Escape(key, false))
+	} else {
+		u = fmt.Sprintf("%s/%s/%s", c.endpoint, bucket, s3Escape(key, false))
+	}
+	if len(query) > 0 {
+		u += "?" + canonicalQuery(query)
+	}
+	return u
+}
+
+func (c *s3Client) do(req *http.Request, name string) (*http.Response, error) {
+	c.sign(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fileError(name, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		var s3Err struct {
+			Code    string
+			Message string
+		}
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+			return nil, fileError(name, errors.Errorf("%s: %s", s3Err.Code, s3Err.Message))
+		}
+		return nil, fileError(name, errors.New(resp.Status))
+	}
+	return resp, nil
+}
+
+func (c *s3Client) head(bucket, key string) (int64, error) {
+	req, err := http.NewRequest("HEAD", c.url(bucket, key, nil), nil)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	resp, err := c.do(req, "s3://"+bucket+"/"+key)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// get returns the body of the object from off, n is the number of bytes to read or -1 for all the remaining ones.
+func (c *s3Client) get(bucket, key string, off, n int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", c.url(bucket, key, nil), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if n >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+n-1))
+	} else if off > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", off))
+	}
+	resp, err := c.do(req, "s3://"+bucket+"/"+key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return resp.Body, nil
+}
+
+// list returns the objects whose keys start with the prefix.
+func (c *s3Client) list(bucket, prefix string) ([]object, error) {
+	var objects []object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequest("GET", c.url(bucket, "", query), nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		name := "s3://" + bucket + "/" + prefix
+		resp, err := c.do(req, name)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		var result struct {
+			Contents []struct {
+				Key  string
+				Size int64
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fileError(name, err)
+		}
+		for _, obj := range result.Contents {
+			objects = append(objects, object{name: obj.Key, size: obj.Size})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// sign adds the signature version 4 authorization header to a request without a body.
+func (c *s3Client) sign(req *http.Request) {
+	if c.accessKey == "" {
+		return
+	}
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+	if c.token != "" {
+		req.Header.Set("x-amz-security-token", c.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders string
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3Escape(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	scope := now.Format("20060102") + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + c.secretKey)
+	for _, part := range []string{now.Format("20060102"), c.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalQuery returns the query string sorted by name, with the values escaped as required by the signature.
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, s3Escape(name, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape escapes every byte except the unreserved characters of RFC 3986, and '/' unless escapeSlash is set.
+func s3Escape(s string, escapeSlash bool) string {
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' ||
+			b == '-' || b == '_' || b == '.' || b == '~' || b == '/' && !escapeSlash {
+			buf = append(buf, b)
+		} else {
+			buf = append(buf, fmt.Sprintf("%%%02X", b)...)
+		}
+	}
+	return string(buf)
+}
@@ -0,0 +1,233 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// flippedOps maps a comparison to the one with swapped arguments.
+var flippedOps = map[string]string{
+	ast.EQ: ast.EQ,
+	ast.NE: ast.NE,
+	ast.LT: ast.GT,
+	ast.LE: ast.GE,
+	ast.GT: ast.LT,
+	ast.GE: ast.LE,
+}
+
+// canPrune returns whether the row groups can be checked against cond with their statistics.
+func canPrune(cond expression.Expression) bool {
+	f, ok := cond.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	args := f.GetArgs()
+	switch f.FuncName.L {
+	case ast.LogicAnd, ast.LogicOr:
+		return canPrune(args[0]) && canPrune(args[1])
+	case ast.IsNull:
+		_, ok := args[0].(*expression.Column)
+		return ok
+	case ast.UnaryNot:
+		g, ok := args[0].(*expression.ScalarFunction)
+		return ok && g.FuncName.L == ast.IsNull && canPrune(g)
+	}
+	_, _, _, ok = comparison(f)
+	return ok
+}
+
+// comparison returns the column, the operator and the constant of a comparison, as if the column is on the left.
+func comparison(f *expression.ScalarFunction) (*expression.Column, string, types.Datum, bool) {
+	op, ok := flippedOps[f.FuncName.L]
+	if !ok {
+		return nil, "", types.Datum{}, false
+	}
+	col, isCol := f.GetArgs()[0].(*expression.Column)
+	c, isConst := f.GetArgs()[1].(*expression.Constant)
+	if isCol && isConst {
+		op = f.FuncName.L
+	} else {
+		col, isCol = f.GetArgs()[1].(*expression.Column)
+		c, isConst = f.GetArgs()[0].(*expression.Constant)
+		if !isCol || !isConst {
+			return nil, "", types.Datum{}, false
+		}
+	}
+	if !comparable(col.RetType, c.Value) {
+		return nil, "", types.Datum{}, false
+	}
+	return col, op, c.Value, true
+}
+
+// comparable returns whether the constant is compared with the values of the column directly, so it can be
+// compared with the bounds of the values.
+func comparable(tp *types.FieldType, d types.Datum) bool {
+	kind := d.Kind()
+	switch {
+	case types.IsTypeTime(tp.Tp):
+		return kind == types.KindMysqlTime
+	case types.IsTypeChar(tp.Tp) || types.IsTypeVarchar(tp.Tp) || types.IsTypeBlob(tp.Tp):
+		return kind == types.KindString || kind == types.KindBytes
+	case tp.Tp == mysql.TypeBit:
+		return false
+	case tp.ToClass() != types.ClassString:
+		return kind == types.KindInt64 || kind == types.KindUint64 || kind == types.KindFloat32 ||
+			kind == types.KindFloat64 || kind == types.KindMysqlDecimal
+	}
+	return false
+}
+
+// columnStats are the statistics of a column in a row group, converted to the type of the table column.
+type columnStats struct {
+	min, max  types.Datum
+	hasMinMax bool
+	// nullCount is -1 if it is unknown.
+	nullCount int64
+	numRows   int64
+}
+
+func (r *parquetReader) columnStats(col *expression.Column, rg rowGroup) columnStats {
+	s := columnStats{nullCount: -1, numRows: rg.numRows}
+	if col.Index < 0 || col.Index >= len(r.leaves) {
+		return s
+	}
+	leaf := r.meta.leaves[r.leaves[col.Index]]
+	st := rg.columns[r.leaves[col.Index]].stats
+	if st.hasNullCount {
+		s.nullCount = st.nullCount
+	}
+	if !st.hasMinMax || st.deprecated && !leaf.tp.signedOrder() || leaf.tp.physical == typeInt96 {
+		return s
+	}
+	convert := r.converter(r.it.req.Columns[col.Index])
+	min, ok1 := leaf.tp.statValue(st.min, convert)
+	max, ok2 := leaf.tp.statValue(st.max, convert)
+	if ok1 && ok2 {
+		s.min, s.max, s.hasMinMax = min, max, true
+	}
+	return s
+}
+
+// signedOrder returns whether the deprecated statistics, which are computed with signed comparisons, are valid.
+func (t *columnType) signedOrder() bool {
+	switch t.physical {
+	case typeInt32, typeInt64:
+		return t.logical != logicalUnsigned
+	case typeFloat, typeDouble:
+		return true
+	}
+	return false
+}
+
+// statValue decodes a value of the statistics, it is plain encoded without the length of a byte array.
+func (t *columnType) statValue(raw []byte, convert func(types.Datum) (types.Datum, error)) (types.Datum, bool) {
+	switch t.physical {
+	case typeBoolean:
+		if len(raw) != 1 {
+			return types.Datum{}, false
+		}
+	case typeByteArray:
+	default:
+		if len(raw) != t.plainSize() {
+			return types.Datum{}, false
+		}
+	}
+	d, err := convert(t.datum(raw))
+	if err != nil || d.IsNull() {
+		return types.Datum{}, false
+	}
+	return d, true
+}
+
+// mayMatch returns whether the row group may contain rows satisfying all the conditions.
+func (r *parquetReader) mayMatch(rg rowGroup) bool {
+	for _, cond := range r.it.req.Conditions {
+		if !r.condMayMatch(cond, rg) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *parquetReader) condMayMatch(cond expression.Expression, rg rowGroup) bool {
+	f, ok := cond.(*expression.ScalarFunction)
+	if !ok {
+		return true
+	}
+	args := f.GetArgs()
+	switch f.FuncName.L {
+	case ast.LogicAnd:
+		return r.condMayMatch(args[0], rg) && r.condMayMatch(args[1], rg)
+	case ast.LogicOr:
+		return r.condMayMatch(args[0], rg) || r.condMayMatch(args[1], rg)
+	case ast.IsNull:
+		col, ok := args[0].(*expression.Column)
+		if !ok {
+			return true
+		}
+		s := r.columnStats(col, rg)
+		return s.nullCount != 0
+	case ast.UnaryNot:
+		g, ok := args[0].(*expression.ScalarFunction)
+		if !ok || g.FuncName.L != ast.IsNull {
+			return true
+		}
+		col, ok := g.GetArgs()[0].(*expression.Column)
+		if !ok {
+			return true
+		}
+		s := r.columnStats(col, rg)
+		return s.nullCount < 0 || s.nullCount < s.numRows
+	}
+	col, op, c, ok := comparison(f)
+	if !ok {
+		return true
+	}
+	s := r.columnStats(col, rg)
+	if s.nullCount == s.numRows {
+		// A comparison with NULL is never true.
+		return false
+	}
+	if !s.hasMinMax {
+		return true
+	}
+	sc := r.it.ctx.GetSessionVars().StmtCtx
+	cmpMin, err := c.CompareDatum(sc, s.min)
+	if err != nil {
+		return true
+	}
+	cmpMax, err := c.CompareDatum(sc, s.max)
+	if err != nil {
+		return true
+	}
+	switch op {
+	case ast.EQ:
+		return cmpMin >= 0 && cmpMax <= 0
+	case ast.NE:
+		return cmpMin != 0 || cmpMax != 0
+	case ast.LT:
+		return cmpMin > 0
+	case ast.LE:
+		return cmpMin >= 0
+	case ast.GT:
+		return cmpMax < 0
+	case ast.GE:
+		return cmpMax <= 0
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// object is a file of a location.
+type object struct {
+	name string
+	size int64
+}
+
+// readerAt reads parts of an object.
+type readerAt interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// storage lists and reads the files of a location.
+type storage interface {
+	// path returns the path of the location, which is used to guess the format.
+	path() string
+	// list returns the files of the location sorted by name.
+	list() ([]object, error)
+	// open returns a reader of the whole file.
+	open(obj object) (io.ReadCloser, error)
+	// openRandom returns a reader of parts of the file.
+	openRandom(obj object) (readerAt, error)
+}
+
+// skipped returns whether a file found in a directory or under a prefix is ignored.
+// Hidden files and the files written by Hadoop jobs, like _SUCCESS, start with '.' or '_'.
+func skipped(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// localStorage reads a local file, the files of a directory or the files matching a glob pattern.
+type localStorage string
+
+func (s localStorage) path() string {
+	return string(s)
+}
+
+func (s localStorage) list() ([]object, error) {
+	name := string(s)
+	if strings.ContainsAny(name, "*?[") {
+		names, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fileError(name, err)
+		}
+		var objects []object
+		for _, name := range names {
+			info, err := os.Stat(name)
+			if err != nil {
+				return nil, fileError(name, err)
+			}
+			if info.Mode().IsRegular() {
+				objects = append(objects, object{name: name, size: info.Size()})
+			}
+		}
+		return objects, nil
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, fileError(name, err)
+	}
+	if !info.IsDir() {
+		return []object{{name: name, size: info.Size()}}, nil
+	}
+	infos, err := ioutil.ReadDir(name)
+	if err != nil {
+		return nil, fileError(name, err)
+	}
+	var objects []object
+	for _, info := range infos {
+		if info.Mode().IsRegular() && !skipped(info.Name()) {
+			objects = append(objects, object{name: filepath.Join(name, info.Name()), size: info.Size()})
+		}
+	}
+	return objects, nil
+}
+
+func (s localStorage) open(obj object) (io.ReadCloser, error) {
+	f, err := os.Open(obj.name)
+	if err != nil {
+		return nil, fileError(obj.name, err)
+	}
+	return f, nil
+}
+
+func (s localStorage) openRandom(obj object) (readerAt, error) {
+	f, err := os.Open(obj.name)
+	if err != nil {
+		return nil, fileError(obj.name, err)
+	}
+	return f, nil
+}
+
+// s3Storage reads an object, or the objects under a prefix if the key ends with '/'.
+type s3Storage struct {
+	bucket   string
+	key      string
+	region   string
+	endpoint string
+}
+
+func (s *s3Storage) path() string {
+	return s.key
+}
+
+func (s *s3Storage) list() ([]object, error) {
+	c := newS3Client(s.region, s.endpoint)
+	if s.key != "" && !strings.HasSuffix(s.key, "/") {
+		size, err := c.head(s.bucket, s.key)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return []object{{name: s.key, size: size}}, nil
+	}
+	all, err := c.list(s.bucket, s.key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var objects []object
+	for _, obj := range all {
+		// Keys ending with '/' are the directory markers created by some tools.
+		if !strings.HasSuffix(obj.name, "/") && !skipped(path.Base(obj.name)) {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].name < objects[j].name })
+	return objects, nil
+}
+
+func (s *s3Storage) open(obj object) (io.ReadCloser, error) {
+	r, err := newS3Client(s.region, s.endpoint).get(s.bucket, obj.name, 0, -1)
+	return r, errors.Trace(err)
+}
+
+func (s *s3Storage) openRandom(obj object) (readerAt, error) {
+	return &s3Object{client: newS3Client(s.region, s.endpoint), bucket: s.bucket, key: obj.name}, nil
+}
+
+// s3Object reads the parts of an object with range requests.
+type s3Object struct {
+	client *s3Client
+	bucket string
+	key    string
+}
+
+func (o *s3Object) ReadAt(p []byte, off int64) (int, error) {
+	r, err := o.client.get(o.bucket, o.key, off, int64(len(p)))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer r.Close()
+	n, err := io.ReadFull(r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (o *s3Object) Close() error {
+	return nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"encoding/binary"
+
+	"github.com/juju/errors"
+)
+
+// Types of the thrift compact protocol.
+const (
+	thriftStop   = 0
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// maxThriftDepth limits the nesting of skipped values.
+const maxThriftDepth = 64
+
+var errMalformed = errors.New("malformed parquet file")
+
+// thriftReader decodes the thrift compact protocol used by the Parquet metadata.
+// It keeps the first error and returns zero values after it.
+type thriftReader struct {
+	buf []byte
+	// read is the number of bytes consumed.
+	read int
+	err  error
+}
+
+func (r *thriftReader) fail() {
+	if r.err == nil {
+		r.err = errMalformed
+	}
+	r.buf = nil
+}
+
+func (r *thriftReader) byte() byte {
+	if len(r.buf) == 0 {
+		r.fail()
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	r.read++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.buf = r.buf[n:]
+	r.read += n
+	return v
+}
+
+// varint reads a zigzag encoded integer.
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) int32() int32 {
+	return int32(r.varint())
+}
+
+func (r *thriftReader) binary() []byte {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)) {
+		r.fail()
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	r.read += int(n)
+	return b
+}
+
+func (r *thriftReader) string() string {
+	return string(r.binary())
+}
+
+// listHeader returns the number of elements and their type.
+func (r *thriftReader) listHeader() (int, byte) {
+	b := r.byte()
+	n := uint64(b >> 4)
+	if n == 15 {
+		n = r.uvarint()
+	}
+	// Every element takes one byte at least.
+	if n > uint64(len(r.buf)) {
+		r.fail()
+		return 0, 0
+	}
+	return int(n), b & 0x0f
+}
+
+// fields calls fn for every field of a struct until the stop field. fn must read or skip the value, the value
+// of a boolean field is its type.
+func (r *thriftReader) fields(fn func(id int16, tp byte)) {
+	var id int16
+	for r.err == nil {
+		b := r.byte()
+		tp := b & 0x0f
+		if tp == thriftStop {
+			return
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+		fn(id, tp)
+	}
+}
+
+func (r *thriftReader) skip(tp byte) {
+	r.skipDepth(tp, 0)
+}
+
+func (r *thriftReader) skipDepth(tp byte, depth int) {
+	if depth > maxThriftDepth {
+		r.fail()
+		return
+	}
+	switch tp {
+	case thriftTrue, thriftFalse:
+	case thriftByte:
+		r.byte()
+	case thriftI16, thriftI32, thriftI64:
+		r.uvarint()
+	case thriftDouble:
+		for i := 0; i < 8; i++ {
+			r.byte()
+		}
+	case thriftBinary:
+		r.binary()
+	case thriftList, thriftSet:
+		n, elem := r.listHeader()
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skipElem(elem, depth+1)
+		}
+	case thriftMap:
+		n := r.uvarint()
+		if n == 0 {
+			return
+		}
+		types := r.byte()
+		for i := uint64(0); i < n && r.err == nil; i++ {
+			r.skipElem(types>>4, depth+1)
+			r.skipElem(types&0x0f, depth+1)
+		}
+	case thriftStruct:
+		r.fields(func(id int16, tp byte) {
+			r.skipDepth(tp, depth+1)
+		})
+	default:
+		r.fail()
+	}
+}
+
+// skipElem skips an element of a list or a map, where a boolean takes one byte.
+func (r *thriftReader) skipElem(tp byte, depth int) {
+	if tp == thriftTrue || tp == thriftFalse {
+		r.byte()
+		return
+	}
+	r.skipDepth(tp, depth)
+}
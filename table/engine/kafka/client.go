@@ -0,0 +1,329 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/table/engine"
+)
+
+const (
+	clientID       = "tidb"
+	dialTimeout    = 10 * time.Second
+	requestTimeout = 30 * time.Second
+	// fetchMaxBytes is the maximum size of the records returned by a fetch request.
+	fetchMaxBytes = 1 << 20
+	// fetchMaxWait is how long the broker waits for new records, it only matters at the end of a partition.
+	fetchMaxWait = 100 * time.Millisecond
+	// maxResponseSize protects the client from a peer which doesn't speak the Kafka protocol.
+	maxResponseSize = 64 << 20
+)
+
+var errorNames = map[int16]string{
+	1:  "OFFSET_OUT_OF_RANGE",
+	3:  "UNKNOWN_TOPIC_OR_PARTITION",
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_FOR_PARTITION",
+	7:  "REQUEST_TIMED_OUT",
+	29: "TOPIC_AUTHORIZATION_FAILED",
+}
+
+func kafkaError(code int16, topic string, partition int32) error {
+	name, ok := errorNames[code]
+	if !ok {
+		name = fmt.Sprintf("error code %d", code)
+	}
+	if partition < 0 {
+		return engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("topic %s: %s", topic, name))
+	}
+	return engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("topic %s partition %d: %s", topic, partition, name))
+}
+
+// brokerConn is a connection to a broker, requests are sent one at a time.
+type brokerConn struct {
+	addr          string
+	conn          net.Conn
+	correlationID int32
+}
+
+func (c *brokerConn) roundTrip(apiKey, version int16, body []byte) (*decoder, error) {
+	c.correlationID++
+	e := &encoder{}
+	e.int32(0) // The size is set below.
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(c.correlationID)
+	e.string(clientID)
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+	if err := c.conn.SetDeadline(time.Now().Add(requestTimeout)); err != nil {
+		return nil, c.error(err)
+	}
+	if _, err := c.conn.Write(e.buf); err != nil {
+		return nil, c.error(err)
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(c.conn, size[:]); err != nil {
+		return nil, c.error(err)
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n < 4 || n > maxResponseSize {
+		return nil, c.error(errMalformed)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.conn, buf); err != nil {
+		return nil, c.error(err)
+	}
+	d := &decoder{buf: buf}
+	if id := d.int32(); id != c.correlationID {
+		return nil, c.error(errors.Errorf("unexpected correlation id %d", id))
+	}
+	return d, nil
+}
+
+func (c *brokerConn) error(err error) error {
+	return engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("broker %s: %v", c.addr, err))
+}
+
+// client reads one topic, it keeps a connection to every broker it talked to.
+type client struct {
+	bootstrap []string
+	topic     string
+	conns     map[string]*brokerConn
+}
+
+func newClient(bootstrap []string, topic string) *client {
+	return &client{bootstrap: bootstrap, topic: topic, conns: make(map[string]*brokerConn)}
+}
+
+func (c *client) conn(addr string) (*brokerConn, error) {
+	if bc, ok := c.conns[addr]; ok {
+		return bc, nil
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, engine.ErrBackendFailed.GenByArgs(Name, err.Error())
+	}
+	bc := &brokerConn{addr: addr, conn: conn}
+	c.conns[addr] = bc
+	return bc, nil
+}
+
+func (c *client) roundTrip(addr string, apiKey, version int16, body []byte) (*decoder, error) {
+	bc, err := c.conn(addr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	d, err := bc.roundTrip(apiKey, version, body)
+	if err != nil {
+		// The connection is in an unknown state after an error.
+		bc.conn.Close()
+		delete(c.conns, addr)
+		return nil, errors.Trace(err)
+	}
+	return d, nil
+}
+
+func (c *client) close() {
+	for addr, bc := range c.conns {
+		bc.conn.Close()
+		delete(c.conns, addr)
+	}
+}
+
+type partitionInfo struct {
+	id     int32
+	leader string
+}
+
+// partitions returns the partitions of the topic sorted by id, it asks the bootstrap brokers in order.
+func (c *client) partitions() ([]partitionInfo, error) {
+	e := &encoder{}
+	e.int32(1)
+	e.string(c.topic)
+	e.bool(false) // allow_auto_topic_creation
+	var lastErr error
+	for _, addr := range c.bootstrap {
+		d, err := c.roundTrip(addr, apiMetadata, metadataVersion, e.buf)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		parts, err := c.decodeMetadata(d)
+		return parts, errors.Trace(err)
+	}
+	return nil, errors.Trace(lastErr)
+}
+
+func (c *client) decodeMetadata(d *decoder) ([]partitionInfo, error) {
+	d.int32() // throttle_time_ms
+	brokers := make(map[int32]string)
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.string() // cluster_id
+	d.int32()  // controller_id
+	var parts []partitionInfo
+	found := false
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		code := d.int16()
+		name := d.string()
+		d.bool() // is_internal
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			partCode := d.int16()
+			id := d.int32()
+			leader := d.int32()
+			for k, l := 0, d.arrayLen(); k < l; k++ {
+				d.int32() // replica
+			}
+			for k, l := 0, d.arrayLen(); k < l; k++ {
+				d.int32() // in-sync replica
+			}
+			if name != c.topic || d.err != nil {
+				continue
+			}
+			addr, ok := brokers[leader]
+			if partCode != 0 || !ok {
+				if partCode == 0 {
+					partCode = 5 // LEADER_NOT_AVAILABLE
+				}
+				return nil, kafkaError(partCode, c.topic, id)
+			}
+			parts = append(parts, partitionInfo{id: id, leader: addr})
+		}
+		if name == c.topic && d.err == nil {
+			if code != 0 {
+				return nil, kafkaError(code, c.topic, -1)
+			}
+			found = true
+		}
+	}
+	if d.err != nil {
+		return nil, errors.Trace(d.err)
+	}
+	if !found {
+		return nil, kafkaError(3, c.topic, -1)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].id < parts[j].id })
+	return parts, nil
+}
+
+// listOffsets returns the offset of every partition for its timestamp, which can be latestTimestamp or
+// earliestTimestamp. For other timestamps the offset is the one of the first message whose timestamp is not
+// before it, or -1 if there is no such message.
+func (c *client) listOffsets(addr string, timestamps map[int32]int64) (map[int32]int64, error) {
+	ids := make([]int, 0, len(timestamps))
+	for id := range timestamps {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	e := &encoder{}
+	e.int32(-1) // replica_id
+	e.int32(1)
+	e.string(c.topic)
+	e.int32(int32(len(ids)))
+	for _, id := range ids {
+		e.int32(int32(id))
+		e.int64(timestamps[int32(id)])
+	}
+	d, err := c.roundTrip(addr, apiListOffsets, listOffsetsVersion, e.buf)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	offsets := make(map[int32]int64, len(timestamps))
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // topic
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			id := d.int32()
+			code := d.int16()
+			d.int64() // timestamp
+			offset := d.int64()
+			if d.err == nil && code != 0 {
+				return nil, kafkaError(code, c.topic, id)
+			}
+			offsets[id] = offset
+		}
+	}
+	if d.err != nil {
+		return nil, errors.Trace(d.err)
+	}
+	for id := range timestamps {
+		if _, ok := offsets[id]; !ok {
+			return nil, kafkaError(3, c.topic, id)
+		}
+	}
+	return offsets, nil
+}
+
+// fetch returns the messages of the partition from the batch containing the offset, and the offset following them.
+func (c *client) fetch(addr string, partition int32, offset int64) ([]message, int64, error) {
+	e := &encoder{}
+	e.int32(-1) // replica_id
+	e.int32(int32(fetchMaxWait / time.Millisecond))
+	e.int32(1) // min_bytes
+	e.int32(fetchMaxBytes)
+	e.int8(0) // isolation_level, READ_UNCOMMITTED
+	e.int32(1)
+	e.string(c.topic)
+	e.int32(1)
+	e.int32(partition)
+	e.int64(offset)
+	e.int32(fetchMaxBytes)
+	d, err := c.roundTrip(addr, apiFetch, fetchVersion, e.buf)
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	d.int32() // throttle_time_ms
+	var records []byte
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		d.string() // topic
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			id := d.int32()
+			code := d.int16()
+			d.int64() // high_watermark
+			d.int64() // last_stable_offset
+			for k, l := 0, d.arrayLen(); k < l; k++ {
+				d.int64() // producer_id
+				d.int64() // first_offset
+			}
+			b := d.bytes()
+			if d.err == nil && code != 0 {
+				return nil, 0, kafkaError(code, c.topic, id)
+			}
+			if id == partition {
+				records = b
+			}
+		}
+	}
+	if d.err != nil {
+		return nil, 0, errors.Trace(d.err)
+	}
+	msgs, next, err := decodeRecordBatches(records)
+	if err != nil {
+		return nil, 0, engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("topic %s partition %d: %v", c.topic, partition, err))
+	}
+	return msgs, next, nil
+}
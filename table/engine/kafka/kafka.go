@@ -0,0 +1,563 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kafka implements the read-only Kafka storage engine.
+//
+// A table created with ENGINE=Kafka CONNECTION='host:port[,host:port...]/topic' reads the messages of the
+// topic. Its columns must be named after the parts of a message: `partition` and `offset` are integers,
+// `timestamp` is an integer in milliseconds or a DATETIME/TIMESTAMP, `key` and `value` are strings.
+// A scan reads every partition up to the end it had when the scan started.
+//
+// Filters comparing `partition`, `offset` and `timestamp` with constants are pushed down, they decide the
+// partitions to read and the offsets the consumer seeks to. Timestamp bounds are looked up in the message
+// index of the brokers, so they assume the timestamps grow with the offsets. Only the record batch format of
+// Kafka 0.11 and later is supported, records of aborted transactions are returned.
+package kafka
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Name is the name of the engine.
+const Name = "Kafka"
+
+// Names of the columns.
+const (
+	colPartition = "partition"
+	colOffset    = "offset"
+	colTimestamp = "timestamp"
+	colKey       = "key"
+	colValue     = "value"
+)
+
+// Engine is the Kafka storage engine.
+type Engine struct{}
+
+// Name implements engine.Engine Name interface.
+func (e Engine) Name() string {
+	return Name
+}
+
+// Open implements engine.Engine Open interface.
+func (e Engine) Open(tblInfo *model.TableInfo) (engine.Source, error) {
+	for name := range tblInfo.EngineOptions {
+		if name != "connection" {
+			return nil, engine.ErrUnknownOption.GenByArgs(name, Name)
+		}
+	}
+	conn := tblInfo.EngineOptions["connection"]
+	slash := strings.LastIndex(conn, "/")
+	if slash <= 0 || slash == len(conn)-1 {
+		return nil, engine.ErrInvalidOption.GenByArgs(conn, "connection", Name)
+	}
+	brokers := strings.Split(conn[:slash], ",")
+	for _, broker := range brokers {
+		if !strings.Contains(broker, ":") {
+			return nil, engine.ErrInvalidOption.GenByArgs(conn, "connection", Name)
+		}
+	}
+	for _, col := range tblInfo.Columns {
+		if !validColumn(col) {
+			return nil, engine.ErrUnsupportedOp.GenByArgs(Name, fmt.Sprintf("column %s %s", col.Name.O, col.FieldType.CompactStr()))
+		}
+	}
+	return &source{brokers: brokers, topic: conn[slash+1:]}, nil
+}
+
+func validColumn(col *model.ColumnInfo) bool {
+	switch col.Name.L {
+	case colPartition, colOffset:
+		return col.FieldType.ToClass() == types.ClassInt
+	case colTimestamp:
+		return col.FieldType.ToClass() == types.ClassInt || col.Tp == mysql.TypeDatetime || col.Tp == mysql.TypeTimestamp
+	case colKey, colValue:
+		return types.IsTypeChar(col.Tp) || types.IsTypeVarchar(col.Tp) || types.IsTypeBlob(col.Tp)
+	}
+	return false
+}
+
+type source struct {
+	brokers []string
+	topic   string
+}
+
+// Capabilities implements engine.Source Capabilities interface.
+func (s *source) Capabilities() engine.Capability {
+	return engine.CapPushDownFilter | engine.CapPushDownLimit
+}
+
+// PushDown implements engine.Source PushDown interface.
+// The pushed down conditions are also evaluated on every message, so the bounds only need to contain the result.
+func (s *source) PushDown(conds []expression.Expression) (pushed, remained []expression.Expression) {
+	b := newBounds()
+	for _, cond := range conds {
+		if b.add(cond) {
+			pushed = append(pushed, cond)
+		} else {
+			remained = append(remained, cond)
+		}
+	}
+	return
+}
+
+// CanPushDownAgg implements engine.Source CanPushDownAgg interface.
+func (s *source) CanPushDownAgg(aggFuncs []expression.AggregationFunction, groupBy []expression.Expression) bool {
+	return false
+}
+
+// Scan implements engine.Source Scan interface.
+func (s *source) Scan(ctx context.Context, req *engine.ScanRequest) (engine.RowIter, error) {
+	b := newBounds()
+	for _, cond := range req.Conditions {
+		b.add(cond)
+	}
+	return &messageIter{ctx: ctx, req: req, bounds: b, client: newClient(s.brokers, s.topic)}, nil
+}
+
+// Insert implements engine.Source Insert interface.
+func (s *source) Insert(ctx context.Context, row []types.Datum) error {
+	return engine.ErrUnsupportedOp.GenByArgs(Name, "INSERT")
+}
+
+// Describe implements engine.Source Describe interface.
+func (s *source) Describe(req *engine.ScanRequest) string {
+	b := newBounds()
+	for _, cond := range req.Conditions {
+		b.add(cond)
+	}
+	buf := bytes.NewBufferString("topic:" + s.topic)
+	if b.partitionSet != nil {
+		ids := b.partitionIDs()
+		strs := make([]string, 0, len(ids))
+		for _, id := range ids {
+			strs = append(strs, fmt.Sprint(id))
+		}
+		buf.WriteString(fmt.Sprintf(", partition in (%s)", strings.Join(strs, ",")))
+	}
+	for _, col := range []struct {
+		name string
+		r    interval
+	}{
+		{colPartition, b.partition},
+		{colOffset, b.offset},
+		{colTimestamp, b.timestamp},
+	} {
+		if col.r != fullInterval {
+			buf.WriteString(", " + col.name + ":" + col.r.String())
+		}
+	}
+	if len(req.Conditions) > 0 {
+		buf.WriteString(fmt.Sprintf(", filter:%s", expression.ExplainExpressionList(req.Conditions)))
+	}
+	if req.Limit > 0 {
+		buf.WriteString(fmt.Sprintf(", limit:%d", req.Limit))
+	}
+	return buf.String()
+}
+
+// interval is a closed interval.
+type interval struct {
+	lo, hi int64
+}
+
+var fullInterval = interval{math.MinInt64, math.MaxInt64}
+
+func (r interval) String() string {
+	lo, hi := "-inf", "+inf"
+	if r.lo != math.MinInt64 {
+		lo = fmt.Sprint(r.lo)
+	}
+	if r.hi != math.MaxInt64 {
+		hi = fmt.Sprint(r.hi)
+	}
+	return "[" + lo + "," + hi + "]"
+}
+
+func (r *interval) narrow(op string, v int64) {
+	switch op {
+	case ast.EQ:
+		r.narrow(ast.GE, v)
+		r.narrow(ast.LE, v)
+	case ast.LT:
+		if v == math.MinInt64 {
+			r.lo, r.hi = 0, -1
+		} else {
+			r.narrow(ast.LE, v-1)
+		}
+	case ast.LE:
+		if v < r.hi {
+			r.hi = v
+		}
+	case ast.GT:
+		if v == math.MaxInt64 {
+			r.lo, r.hi = 0, -1
+		} else {
+			r.narrow(ast.GE, v+1)
+		}
+	case ast.GE:
+		if v > r.lo {
+			r.lo = v
+		}
+	}
+}
+
+// bounds are the partitions, offsets and timestamps of the messages to read.
+type bounds struct {
+	partition interval
+	// partitionSet is the set of partitions to read, nil means all the partitions in the interval.
+	partitionSet map[int64]bool
+	offset       interval
+	timestamp    interval
+}
+
+func newBounds() *bounds {
+	return &bounds{partition: fullInterval, offset: fullInterval, timestamp: fullInterval}
+}
+
+var flippedOps = map[string]string{
+	ast.EQ: ast.EQ,
+	ast.LT: ast.GT,
+	ast.LE: ast.GE,
+	ast.GT: ast.LT,
+	ast.GE: ast.LE,
+}
+
+// add narrows the bounds by the condition, it returns false if the condition isn't a bound of a message column.
+func (b *bounds) add(cond expression.Expression) bool {
+	f, ok := cond.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	if f.FuncName.L == ast.LogicOr {
+		set := make(map[int64]bool)
+		if !collectPartitions(f, set) {
+			return false
+		}
+		if b.partitionSet != nil {
+			for id := range set {
+				if !b.partitionSet[id] {
+					delete(set, id)
+				}
+			}
+		}
+		b.partitionSet = set
+		return true
+	}
+	name, op, v, ok := comparison(f)
+	if !ok {
+		return false
+	}
+	switch name {
+	case colPartition:
+		b.partition.narrow(op, v)
+	case colOffset:
+		b.offset.narrow(op, v)
+	case colTimestamp:
+		b.timestamp.narrow(op, v)
+	}
+	return true
+}
+
+// collectPartitions collects the partitions of a disjunction of `partition` = constant.
+func collectPartitions(expr expression.Expression, set map[int64]bool) bool {
+	f, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return false
+	}
+	if f.FuncName.L == ast.LogicOr {
+		return collectPartitions(f.GetArgs()[0], set) && collectPartitions(f.GetArgs()[1], set)
+	}
+	name, op, v, ok := comparison(f)
+	if !ok || name != colPartition || op != ast.EQ {
+		return false
+	}
+	set[v] = true
+	return true
+}
+
+// comparison returns the column, the operator and the value of a comparison between a column and a constant.
+func comparison(f *expression.ScalarFunction) (string, string, int64, bool) {
+	op, ok := flippedOps[f.FuncName.L]
+	if !ok {
+		return "", "", 0, false
+	}
+	col, isCol := f.GetArgs()[0].(*expression.Column)
+	c, isConst := f.GetArgs()[1].(*expression.Constant)
+	if isCol && isConst {
+		op = f.FuncName.L
+	} else {
+		col, isCol = f.GetArgs()[1].(*expression.Column)
+		c, isConst = f.GetArgs()[0].(*expression.Constant)
+		if !isCol || !isConst {
+			return "", "", 0, false
+		}
+	}
+	switch col.ColName.L {
+	case colPartition, colOffset:
+		if c.Value.Kind() != types.KindInt64 {
+			return "", "", 0, false
+		}
+		return col.ColName.L, op, c.Value.GetInt64(), true
+	case colTimestamp:
+		switch c.Value.Kind() {
+		case types.KindInt64:
+			return colTimestamp, op, c.Value.GetInt64(), true
+		case types.KindMysqlTime:
+			t, err := c.Value.GetMysqlTime().Time.GoTime(time.Local)
+			if err != nil {
+				return "", "", 0, false
+			}
+			// A DATETIME column holds the timestamp rounded to its precision, widen the bounds by a second.
+			ms := t.UnixNano() / int64(time.Millisecond)
+			switch op {
+			case ast.LT, ast.LE:
+				ms += 1000
+			case ast.GT, ast.GE:
+				ms -= 1000
+			case ast.EQ:
+				// The equality becomes a range, it is still evaluated on every message.
+				return colTimestamp, ast.GE, ms - 1000, true
+			}
+			return colTimestamp, op, ms, true
+		}
+	}
+	return "", "", 0, false
+}
+
+func (b *bounds) partitionIDs() []int64 {
+	ids := make([]int64, 0, len(b.partitionSet))
+	for id := range b.partitionSet {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (b *bounds) hasPartition(id int32) bool {
+	if int64(id) < b.partition.lo || int64(id) > b.partition.hi {
+		return false
+	}
+	return b.partitionSet == nil || b.partitionSet[int64(id)]
+}
+
+// partitionRange is the offsets [start, end) of a partition to read.
+type partitionRange struct {
+	partitionInfo
+	start, end int64
+}
+
+// messageIter reads the messages within the bounds partition by partition.
+type messageIter struct {
+	ctx    context.Context
+	req    *engine.ScanRequest
+	bounds *bounds
+	client *client
+
+	started  bool
+	ranges   []partitionRange
+	msgs     []message
+	returned uint64
+}
+
+// Next implements engine.RowIter Next interface.
+func (it *messageIter) Next() ([]types.Datum, error) {
+	if !it.started {
+		it.started = true
+		if err := it.seek(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	for it.req.Limit == 0 || it.returned < it.req.Limit {
+		for len(it.msgs) == 0 {
+			if len(it.ranges) == 0 {
+				return nil, nil
+			}
+			if err := it.fetch(); err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		msg := it.msgs[0]
+		it.msgs = it.msgs[1:]
+		row, err := it.row(msg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		match, err := expression.EvalBool(it.req.Conditions, row, it.ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if match {
+			it.returned++
+			return row, nil
+		}
+	}
+	return nil, nil
+}
+
+// seek finds the offsets to read in every partition within the bounds.
+func (it *messageIter) seek() error {
+	parts, err := it.client.partitions()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	byLeader := make(map[string]map[int32]int64)
+	for _, p := range parts {
+		if !it.bounds.hasPartition(p.id) {
+			continue
+		}
+		if byLeader[p.leader] == nil {
+			byLeader[p.leader] = make(map[int32]int64)
+		}
+		byLeader[p.leader][p.id] = 0
+	}
+	offsets := func(leader string, ts int64) (map[int32]int64, error) {
+		req := make(map[int32]int64, len(byLeader[leader]))
+		for id := range byLeader[leader] {
+			req[id] = ts
+		}
+		return it.client.listOffsets(leader, req)
+	}
+	ranges := make(map[int32]partitionRange)
+	for leader := range byLeader {
+		earliest, err := offsets(leader, earliestTimestamp)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		latest, err := offsets(leader, latestTimestamp)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var tsStart, tsEnd map[int32]int64
+		if ts := it.bounds.timestamp; ts.lo > 0 {
+			if tsStart, err = offsets(leader, ts.lo); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if ts := it.bounds.timestamp; ts.hi >= 0 && ts.hi < math.MaxInt64 {
+			if tsEnd, err = offsets(leader, ts.hi+1); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		for id := range byLeader[leader] {
+			r := partitionRange{partitionInfo: partitionInfo{id: id, leader: leader}, start: earliest[id], end: latest[id]}
+			if it.bounds.offset.lo > r.start {
+				r.start = it.bounds.offset.lo
+			}
+			if it.bounds.offset.hi < r.end-1 {
+				r.end = it.bounds.offset.hi + 1
+			}
+			if tsStart != nil {
+				if off := tsStart[id]; off < 0 {
+					r.start = r.end
+				} else if off > r.start {
+					r.start = off
+				}
+			}
+			if tsEnd != nil {
+				if off := tsEnd[id]; off >= 0 && off < r.end {
+					r.end = off
+				}
+			}
+			if r.start < r.end {
+				ranges[id] = r
+			}
+		}
+	}
+	for _, p := range parts {
+		if r, ok := ranges[p.id]; ok {
+			it.ranges = append(it.ranges, r)
+		}
+	}
+	if it.bounds.timestamp.hi < 0 {
+		it.ranges = nil
+	}
+	return nil
+}
+
+// fetch reads the next messages of the first partition range.
+func (it *messageIter) fetch() error {
+	r := &it.ranges[0]
+	msgs, next, err := it.client.fetch(r.leader, r.id, r.start)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if next <= r.start {
+		return engine.ErrBackendFailed.GenByArgs(Name, fmt.Sprintf("topic %s partition %d: no messages at offset %d", it.client.topic, r.id, r.start))
+	}
+	for _, msg := range msgs {
+		if msg.offset >= r.start && msg.offset < r.end {
+			msg.partition = r.id
+			it.msgs = append(it.msgs, msg)
+		}
+	}
+	r.start = next
+	if r.start >= r.end {
+		it.ranges = it.ranges[1:]
+	}
+	return nil
+}
+
+func (it *messageIter) row(msg message) ([]types.Datum, error) {
+	sc := it.ctx.GetSessionVars().StmtCtx
+	row := make([]types.Datum, 0, len(it.req.Columns))
+	for _, col := range it.req.Columns {
+		var d types.Datum
+		switch col.Name.L {
+		case colPartition:
+			d.SetInt64(int64(msg.partition))
+		case colOffset:
+			d.SetInt64(msg.offset)
+		case colTimestamp:
+			if col.FieldType.ToClass() == types.ClassInt {
+				d.SetInt64(msg.timestamp)
+			} else {
+				t := time.Unix(0, msg.timestamp*int64(time.Millisecond))
+				d.SetMysqlTime(types.Time{Time: types.FromGoTime(t), Type: col.Tp, Fsp: 3})
+			}
+		case colKey:
+			if msg.key != nil {
+				d.SetBytes(msg.key)
+			}
+		case colValue:
+			if msg.value != nil {
+				d.SetBytes(msg.value)
+			}
+		}
+		d, err := d.ConvertTo(sc, &col.FieldType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row = append(row, d)
+	}
+	return row, nil
+}
+
+// Close implements engine.RowIter Close interface.
+func (it *messageIter) Close() error {
+	it.client.close()
+	return nil
+}
@@ -0,0 +1,434 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/table/engine/enginetest"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testKafkaSuite{})
+
+type testKafkaSuite struct{}
+
+// fakeBroker serves one topic whose partitions are stored in memory.
+// A fetch returns the batch containing the offset, every batch holds two messages.
+type fakeBroker struct {
+	ln    net.Listener
+	topic string
+	parts [][]message
+
+	mu      sync.Mutex
+	fetches []string
+}
+
+func newFakeBroker(c *C, topic string, parts [][]message) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	b := &fakeBroker{ln: ln, topic: topic, parts: parts}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) close() {
+	b.ln.Close()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		buf := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return
+		}
+		d := &decoder{buf: buf}
+		apiKey, _, correlationID := d.int16(), d.int16(), d.int32()
+		d.string() // client_id
+		e := &encoder{}
+		e.int32(0)
+		e.int32(correlationID)
+		switch apiKey {
+		case apiMetadata:
+			b.metadata(e)
+		case apiListOffsets:
+			b.listOffsets(d, e)
+		case apiFetch:
+			b.fetch(d, e)
+		}
+		binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+		conn.Write(e.buf)
+	}
+}
+
+func (b *fakeBroker) metadata(e *encoder) {
+	host, port, _ := net.SplitHostPort(b.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	e.int32(0) // throttle_time_ms
+	e.int32(1)
+	e.int32(0)
+	e.string(host)
+	e.int32(int32(p))
+	e.int16(-1) // rack
+	e.int16(-1) // cluster_id
+	e.int32(0)  // controller_id
+	e.int32(1)
+	e.int16(0)
+	e.string(b.topic)
+	e.bool(false)
+	e.int32(int32(len(b.parts)))
+	for i := range b.parts {
+		e.int16(0)
+		e.int32(int32(i))
+		e.int32(0) // leader
+		e.int32(1)
+		e.int32(0)
+		e.int32(1)
+		e.int32(0)
+	}
+}
+
+func (b *fakeBroker) listOffsets(d *decoder, e *encoder) {
+	d.int32() // replica_id
+	d.int32() // topics
+	d.string()
+	n := d.arrayLen()
+	e.int32(1)
+	e.string(b.topic)
+	e.int32(int32(n))
+	for i := 0; i < n; i++ {
+		id, ts := d.int32(), d.int64()
+		msgs := b.parts[id]
+		offset := int64(-1)
+		switch ts {
+		case earliestTimestamp:
+			offset = 0
+		case latestTimestamp:
+			offset = int64(len(msgs))
+		default:
+			for _, msg := range msgs {
+				if msg.timestamp >= ts {
+					offset = msg.offset
+					break
+				}
+			}
+		}
+		e.int32(id)
+		e.int16(0)
+		e.int64(-1)
+		e.int64(offset)
+	}
+}
+
+func (b *fakeBroker) fetch(d *decoder, e *encoder) {
+	d.next(4 + 4 + 4 + 4 + 1) // replica_id, max_wait_ms, min_bytes, max_bytes and isolation_level
+	d.int32()                 // topics
+	d.string()
+	d.int32() // partitions
+	id, offset := d.int32(), d.int64()
+	b.mu.Lock()
+	b.fetches = append(b.fetches, fmt.Sprintf("%d:%d", id, offset))
+	b.mu.Unlock()
+	msgs := b.parts[id]
+	start := offset / 2 * 2
+	end := start + 2
+	if end > int64(len(msgs)) {
+		end = int64(len(msgs))
+	}
+	var records []byte
+	if start < end {
+		records = encodeBatch(msgs[start:end], start%4 == 2)
+	}
+	e.int32(0) // throttle_time_ms
+	e.int32(1)
+	e.string(b.topic)
+	e.int32(1)
+	e.int32(id)
+	e.int16(0)
+	e.int64(int64(len(msgs)))
+	e.int64(int64(len(msgs)))
+	e.int32(-1) // aborted_transactions
+	e.bytes(records)
+}
+
+// encodeBatch encodes the messages with consecutive offsets as a record batch.
+func encodeBatch(msgs []message, compress bool) []byte {
+	rec := &encoder{}
+	for _, msg := range msgs {
+		r := &encoder{}
+		r.int8(0)
+		r.varint(msg.timestamp - msgs[0].timestamp)
+		r.varint(msg.offset - msgs[0].offset)
+		r.varbytes(msg.key)
+		r.varbytes(msg.value)
+		r.varint(1)
+		r.varbytes([]byte("h"))
+		r.varbytes([]byte("v"))
+		rec.varint(int64(len(r.buf)))
+		rec.buf = append(rec.buf, r.buf...)
+	}
+	attrs := int16(compressionNone)
+	if compress {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(rec.buf)
+		w.Close()
+		rec.buf = buf.Bytes()
+		attrs = compressionGzip
+	}
+	body := &encoder{}
+	body.int32(0) // partition_leader_epoch
+	body.int8(2)
+	body.int32(0) // crc
+	body.int16(attrs)
+	body.int32(int32(len(msgs) - 1))
+	body.int64(msgs[0].timestamp)
+	body.int64(msgs[len(msgs)-1].timestamp)
+	body.int64(-1)
+	body.int16(-1)
+	body.int32(-1)
+	body.int32(int32(len(msgs)))
+	body.buf = append(body.buf, rec.buf...)
+	e := &encoder{}
+	e.int64(msgs[0].offset)
+	e.int32(int32(len(body.buf)))
+	e.buf = append(e.buf, body.buf...)
+	return e.buf
+}
+
+func newMessages(n int, prefix string) []message {
+	msgs := make([]message, 0, n)
+	for i := 0; i < n; i++ {
+		msgs = append(msgs, message{
+			offset:    int64(i),
+			timestamp: int64(1000 * (i + 1)),
+			key:       []byte(fmt.Sprintf("%s%d", prefix, i)),
+			value:     []byte(fmt.Sprintf("v%d", i)),
+		})
+	}
+	msgs[0].key = nil
+	return msgs
+}
+
+// The helpers shared by the tests of the storage engines.
+var (
+	column       = enginetest.Column
+	newFunc      = enginetest.NewFunc
+	constant     = enginetest.Constant
+	datumsString = enginetest.DatumsString
+)
+
+func newTableInfo(conn string) *model.TableInfo {
+	return enginetest.NewTableInfo(Name, map[string]string{"connection": conn},
+		enginetest.NewColumn("partition", types.NewFieldType(mysql.TypeLong)),
+		enginetest.NewColumn("offset", types.NewFieldType(mysql.TypeLonglong)),
+		enginetest.NewColumn("timestamp", types.NewFieldType(mysql.TypeLonglong)),
+		enginetest.NewColumn("key", types.NewFieldType(mysql.TypeVarchar)),
+		enginetest.NewColumn("value", types.NewFieldType(mysql.TypeBlob)),
+	)
+}
+
+func (s *testKafkaSuite) TestOpen(c *C) {
+	defer testleak.AfterTest(c)()
+	for _, conn := range []string{"", "localhost:9092", "localhost:9092/", "/topic", "localhost/topic"} {
+		_, err := Engine{}.Open(newTableInfo(conn))
+		c.Assert(engine.ErrInvalidOption.Equal(err), IsTrue, Commentf("%s", conn))
+	}
+	tblInfo := newTableInfo("a:1,b:2/events")
+	src, err := Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	c.Assert(src.(*source).brokers, DeepEquals, []string{"a:1", "b:2"})
+	c.Assert(src.(*source).topic, Equals, "events")
+
+	tblInfo.Columns[2].Tp = mysql.TypeDatetime
+	_, err = Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	tblInfo.Columns[0].Name = model.NewCIStr("p")
+	_, err = Engine{}.Open(tblInfo)
+	c.Assert(engine.ErrUnsupportedOp.Equal(err), IsTrue)
+	tblInfo.Columns[0].Name = model.NewCIStr("partition")
+	tblInfo.Columns[3].Tp = mysql.TypeLong
+	_, err = Engine{}.Open(tblInfo)
+	c.Assert(engine.ErrUnsupportedOp.Equal(err), IsTrue)
+}
+
+func (s *testKafkaSuite) TestScan(c *C) {
+	defer testleak.AfterTest(c)()
+	broker := newFakeBroker(c, "events", [][]message{newMessages(5, "a"), newMessages(3, "b")})
+	defer broker.close()
+	tblInfo := newTableInfo("127.0.0.1:1," + broker.ln.Addr().String() + "/events")
+	src, err := Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	cols := tblInfo.Columns
+	partition, offset, ts := column(cols, "partition"), column(cols, "offset"), column(cols, "timestamp")
+	key := column(cols, "key")
+
+	tests := []struct {
+		conds   []expression.Expression
+		limit   uint64
+		rows    []string
+		fetches []string
+	}{
+		{
+			rows: []string{"0 0 1000 <nil> v0", "0 1 2000 a1 v1", "0 2 3000 a2 v2", "0 3 4000 a3 v3", "0 4 5000 a4 v4",
+				"1 0 1000 <nil> v0", "1 1 2000 b1 v1", "1 2 3000 b2 v2"},
+			fetches: []string{"0:0", "0:2", "0:4", "1:0", "1:2"},
+		},
+		{
+			conds:   []expression.Expression{newFunc(c, ast.EQ, partition, constant(0)), newFunc(c, ast.GT, offset, constant(2))},
+			rows:    []string{"0 3 4000 a3 v3", "0 4 5000 a4 v4"},
+			fetches: []string{"0:3", "0:4"},
+		},
+		{
+			conds: []expression.Expression{
+				newFunc(c, ast.LogicOr, newFunc(c, ast.EQ, partition, constant(1)), newFunc(c, ast.EQ, partition, constant(3))),
+				newFunc(c, ast.LE, constant(2000), ts),
+				newFunc(c, ast.NE, key, constant("b1")),
+			},
+			rows:    []string{"1 2 3000 b2 v2"},
+			fetches: []string{"1:1", "1:2"},
+		},
+		{
+			conds:   []expression.Expression{newFunc(c, ast.LT, ts, constant(3000))},
+			limit:   3,
+			rows:    []string{"0 0 1000 <nil> v0", "0 1 2000 a1 v1", "1 0 1000 <nil> v0"},
+			fetches: []string{"0:0", "1:0"},
+		},
+		{
+			conds: []expression.Expression{newFunc(c, ast.GT, ts, constant(10000))},
+		},
+	}
+	ctx := mock.NewContext()
+	for _, t := range tests {
+		broker.fetches = nil
+		pushed, remained := src.PushDown(t.conds)
+		var pushedKey bool
+		for _, cond := range pushed {
+			if strings.Contains(cond.String(), "key") {
+				pushedKey = true
+			}
+		}
+		c.Assert(pushedKey, IsFalse)
+		req := &engine.ScanRequest{Columns: tblInfo.Columns, Conditions: append(pushed, remained...), Limit: t.limit}
+		iter, err := src.Scan(ctx, req)
+		c.Assert(err, IsNil)
+		var rows []string
+		for {
+			row, err := iter.Next()
+			c.Assert(err, IsNil)
+			if row == nil {
+				break
+			}
+			rows = append(rows, datumsString(row))
+		}
+		c.Assert(iter.Close(), IsNil)
+		c.Assert(rows, DeepEquals, t.rows, Commentf("%v", t.conds))
+		c.Assert(broker.fetches, DeepEquals, t.fetches, Commentf("%v", t.conds))
+	}
+
+	tblInfo = newTableInfo(broker.ln.Addr().String() + "/unknown")
+	src, err = Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	iter, err := src.Scan(ctx, &engine.ScanRequest{Columns: tblInfo.Columns})
+	c.Assert(err, IsNil)
+	_, err = iter.Next()
+	c.Assert(engine.ErrBackendFailed.Equal(err), IsTrue)
+	c.Assert(err.Error(), Matches, ".*topic unknown: UNKNOWN_TOPIC_OR_PARTITION")
+	c.Assert(iter.Close(), IsNil)
+}
+
+func (s *testKafkaSuite) TestDescribe(c *C) {
+	defer testleak.AfterTest(c)()
+	tblInfo := newTableInfo("localhost:9092/events")
+	src, err := Engine{}.Open(tblInfo)
+	c.Assert(err, IsNil)
+	cols := tblInfo.Columns
+	partition, offset, value := column(cols, "partition"), column(cols, "offset"), column(cols, "value")
+	conds := []expression.Expression{
+		newFunc(c, ast.LogicOr, newFunc(c, ast.EQ, partition, constant(2)), newFunc(c, ast.EQ, partition, constant(1))),
+		newFunc(c, ast.GE, offset, constant(10)),
+		newFunc(c, ast.LT, offset, constant(20)),
+		newFunc(c, ast.EQ, value, constant("x")),
+	}
+	pushed, remained := src.PushDown(conds)
+	c.Assert(pushed, HasLen, 3)
+	c.Assert(remained, HasLen, 1)
+	c.Assert(src.Describe(&engine.ScanRequest{Conditions: pushed, Limit: 5}), Equals,
+		"topic:events, partition in (1,2), offset:[10,19], filter:or(eq(partition, 2), eq(partition, 1)), ge(offset, 10), lt(offset, 20), limit:5")
+	c.Assert(src.Describe(&engine.ScanRequest{}), Equals, "topic:events")
+}
+
+func (s *testKafkaSuite) TestDecodeRecordBatches(c *C) {
+	defer testleak.AfterTest(c)()
+	msgs := newMessages(4, "k")
+	data := append(encodeBatch(msgs[:2], false), encodeBatch(msgs[2:], true)...)
+	decoded, next, err := decodeRecordBatches(data)
+	c.Assert(err, IsNil)
+	c.Assert(decoded, DeepEquals, msgs)
+	c.Assert(next, Equals, int64(4))
+
+	// A partial batch at the end is ignored.
+	decoded, next, err = decodeRecordBatches(data[:len(data)-1])
+	c.Assert(err, IsNil)
+	c.Assert(decoded, DeepEquals, msgs[:2])
+	c.Assert(next, Equals, int64(2))
+
+	// Control batches are skipped.
+	control := encodeBatch(msgs[:1], false)
+	control[12+4+1+4+1] |= controlFlag
+	decoded, next, err = decodeRecordBatches(control)
+	c.Assert(err, IsNil)
+	c.Assert(decoded, HasLen, 0)
+	c.Assert(next, Equals, int64(1))
+
+	old := encodeBatch(msgs[:1], false)
+	old[12+4] = 1
+	_, _, err = decodeRecordBatches(old)
+	c.Assert(err, ErrorMatches, "unsupported message format version 1")
+}
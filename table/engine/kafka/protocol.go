@@ -0,0 +1,332 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+
+	"github.com/golang/snappy"
+	"github.com/juju/errors"
+)
+
+// API keys and the versions of the requests sent by the client.
+const (
+	apiFetch       int16 = 1
+	apiListOffsets int16 = 2
+	apiMetadata    int16 = 3
+
+	fetchVersion       int16 = 4
+	listOffsetsVersion int16 = 1
+	metadataVersion    int16 = 4
+)
+
+// Special timestamps of a ListOffsets request.
+const (
+	latestTimestamp   int64 = -1
+	earliestTimestamp int64 = -2
+)
+
+var errMalformed = errors.New("malformed response")
+
+// encoder appends the fields of a request in the Kafka protocol encoding.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+func (e *encoder) int16(v int16) {
+	e.buf = append(e.buf, byte(v>>8), byte(v))
+}
+
+func (e *encoder) int32(v int32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) int64(v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.int8(1)
+	} else {
+		e.int8(0)
+	}
+}
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) bytes(b []byte) {
+	if b == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], v)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// decoder reads the fields of a response, it keeps the first error and returns zero values after it.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = errMalformed
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (d *decoder) int16() int16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *decoder) int32() int32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *decoder) int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *decoder) bool() bool {
+	return d.int8() != 0
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// arrayLen returns the length of an array, a null array is empty.
+func (d *decoder) arrayLen() int {
+	n := int(d.int32())
+	if n < 0 {
+		return 0
+	}
+	if n > len(d.buf) {
+		d.err = errMalformed
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errMalformed
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varbytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// message is a record of a topic partition.
+type message struct {
+	partition int32
+	offset    int64
+	timestamp int64
+	key       []byte
+	value     []byte
+}
+
+// Attributes of a record batch.
+const (
+	compressionMask   = 0x07
+	logAppendTimeFlag = 0x08
+	controlFlag       = 0x20
+)
+
+// Compression codecs of a record batch.
+const (
+	compressionNone   = 0
+	compressionGzip   = 1
+	compressionSnappy = 2
+)
+
+// decodeRecordBatches decodes the record batches of a fetch response.
+// It returns the messages and the offset following the last complete batch, or -1 if there isn't one.
+// Only the record batch format of Kafka 0.11 and later (magic 2) is supported.
+func decodeRecordBatches(data []byte) ([]message, int64, error) {
+	var msgs []message
+	next := int64(-1)
+	for len(data) >= 12 {
+		baseOffset := int64(binary.BigEndian.Uint64(data))
+		length := int(int32(binary.BigEndian.Uint32(data[8:])))
+		if length < 0 || len(data) < 12+length {
+			// A fetch response can end with a partial batch.
+			break
+		}
+		d := &decoder{buf: data[12 : 12+length]}
+		data = data[12+length:]
+		d.int32() // partition leader epoch
+		if magic := d.int8(); d.err == nil && magic != 2 {
+			return nil, 0, errors.Errorf("unsupported message format version %d", magic)
+		}
+		d.int32() // crc
+		attrs := d.int16()
+		lastOffsetDelta := d.int32()
+		firstTimestamp := d.int64()
+		maxTimestamp := d.int64()
+		d.next(8 + 2 + 4) // producer id, producer epoch and base sequence
+		count := int(d.int32())
+		if d.err != nil {
+			return nil, 0, errors.Trace(d.err)
+		}
+		next = baseOffset + int64(lastOffsetDelta) + 1
+		if attrs&controlFlag != 0 {
+			continue
+		}
+		records, err := decompress(int(attrs&compressionMask), d.buf)
+		if err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		rd := &decoder{buf: records}
+		for i := 0; i < count; i++ {
+			rd.varint() // length
+			rd.int8()   // attributes
+			msg := message{timestamp: firstTimestamp + rd.varint()}
+			msg.offset = baseOffset + rd.varint()
+			msg.key = rd.varbytes()
+			msg.value = rd.varbytes()
+			headers := int(rd.varint())
+			for j := 0; j < headers && rd.err == nil; j++ {
+				rd.varbytes()
+				rd.varbytes()
+			}
+			if attrs&logAppendTimeFlag != 0 {
+				msg.timestamp = maxTimestamp
+			}
+			if rd.err != nil {
+				return nil, 0, errors.Trace(rd.err)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, next, nil
+}
+
+// xerialHeader starts the snappy framing used by the Java client.
+var xerialHeader = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+func decompress(codec int, data []byte) ([]byte, error) {
+	switch codec {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		return b, errors.Trace(err)
+	case compressionSnappy:
+		if !bytes.HasPrefix(data, xerialHeader) {
+			b, err := snappy.Decode(nil, data)
+			return b, errors.Trace(err)
+		}
+		// The header is followed by two int32 versions and the size prefixed chunks.
+		if len(data) < len(xerialHeader)+8 {
+			return nil, errMalformed
+		}
+		d := &decoder{buf: data[len(xerialHeader)+8:]}
+		var out []byte
+		for len(d.buf) > 0 && d.err == nil {
+			chunk, err := snappy.Decode(nil, d.bytes())
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			out = append(out, chunk...)
+		}
+		return out, errors.Trace(d.err)
+	}
+	return nil, errors.Errorf("unsupported compression codec %d", codec)
+}
@@ -0,0 +1,118 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Table implements table.Table for a table stored by an external engine.
+// Metadata operations are served by the embedded table, row operations go to the Source.
+type Table struct {
+	table.Table
+
+	engine Engine
+	source Source
+}
+
+// NewTable wraps t, whose rows are stored by the engine of its TableInfo.
+func NewTable(t table.Table, e Engine) (*Table, error) {
+	src, err := e.Open(t.Meta())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &Table{Table: t, engine: e, source: src}, nil
+}
+
+// Engine returns the engine of the table.
+func (t *Table) Engine() Engine {
+	return t.engine
+}
+
+// Source returns the source of the table rows.
+func (t *Table) Source() Source {
+	return t.source
+}
+
+func (t *Table) unsupported(op string) error {
+	return ErrUnsupportedOp.GenByArgs(t.engine.Name(), op)
+}
+
+// IterRecords implements table.Table IterRecords interface.
+// There are no handles in an external table, h is the position of the row in the scan.
+func (t *Table) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.Column, fn table.RecordIterFunc) error {
+	if len(startKey) > 0 {
+		return t.unsupported("seek")
+	}
+	req := &ScanRequest{}
+	for _, col := range cols {
+		req.Columns = append(req.Columns, col.ToInfo())
+	}
+	iter, err := t.source.Scan(ctx, req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer iter.Close()
+	for h := int64(1); ; h++ {
+		row, err := iter.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			return nil
+		}
+		more, err := fn(h, row, cols)
+		if err != nil || !more {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// RowWithCols implements table.Table RowWithCols interface.
+func (t *Table) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	return nil, t.unsupported("reading rows by handle")
+}
+
+// Row implements table.Table Row interface.
+func (t *Table) Row(ctx context.Context, h int64) ([]types.Datum, error) {
+	return nil, t.unsupported("reading rows by handle")
+}
+
+// Seek implements table.Table Seek interface.
+func (t *Table) Seek(ctx context.Context, h int64) (int64, bool, error) {
+	return 0, false, t.unsupported("seek")
+}
+
+// AddRecord implements table.Table AddRecord interface.
+// The row is written to the engine immediately, it is not part of the transaction.
+func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (int64, error) {
+	if !t.source.Capabilities().Has(CapInsert) {
+		return 0, t.unsupported("INSERT")
+	}
+	return 0, errors.Trace(t.source.Insert(ctx, r))
+}
+
+// UpdateRecord implements table.Table UpdateRecord interface.
+func (t *Table) UpdateRecord(ctx context.Context, h int64, currData, newData []types.Datum, touched []bool) error {
+	return t.unsupported("UPDATE")
+}
+
+// RemoveRecord implements table.Table RemoveRecord interface.
+func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
+	return t.unsupported("DELETE")
+}
@@ -55,6 +55,8 @@ var (
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrElemBeingRemoved returns for writing an enum or set element that's being removed from the column.
+	ErrElemBeingRemoved = terror.ClassTable.New(codeElemBeingRemoved, "Data truncated for column '%s', the element is being removed")
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -148,6 +150,7 @@ const (
 	codeColumnCantNull     = 1048
 	codeUnknownColumn      = 1054
 	codeDuplicateColumn    = 1110
+	codeElemBeingRemoved   = 1265
 	codeNoDefaultValue     = 1364
 	codeTruncateWrongValue = 1366
 )
@@ -170,6 +173,7 @@ func init() {
 		codeDuplicateColumn:    mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:     mysql.ErrNoDefaultForField,
 		codeTruncateWrongValue: mysql.ErrTruncatedWrongValueForField,
+		codeElemBeingRemoved:   mysql.WarnDataTruncated,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
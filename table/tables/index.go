@@ -21,9 +21,11 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -84,6 +86,14 @@ func (c *indexIter) Next() (val []types.Datum, h int64, err error) {
 		}
 		val = vv
 	}
+	handleInKey := len(vv) > len(c.idx.idxInfo.Columns)
+	if restored := tablecodec.CutIndexRestoredData(c.it.Value(), handleInKey); restored != nil {
+		// The key has the sort keys of the indexed values, restore the values from the value.
+		val, err = codec.Decode(restored, len(c.idx.idxInfo.Columns))
+		if err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+	}
 	// update new iter to next
 	err = c.it.Next()
 	if err != nil {
@@ -117,6 +127,13 @@ func (c *index) Meta() *model.IndexInfo {
 // GenIndexKey generates storage key for index values. Returned distinct indicates whether the
 // indexed values should be distinct in storage (i.e. whether handle is encoded in the key).
 func (c *index) GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, distinct bool, err error) {
+	key, distinct, _, err = c.genIndexKey(indexedValues, h)
+	return key, distinct, errors.Trace(err)
+}
+
+// genIndexKey generates storage key for index values like GenIndexKey, the returned needRestore
+// indicates whether the indexed values can't be decoded from the key and should be stored in the value.
+func (c *index) genIndexKey(indexedValues []types.Datum, h int64) (key []byte, distinct bool, needRestore bool, err error) {
 	if c.idxInfo.Unique {
 		// See https://dev.mysql.com/doc/refman/5.7/en/create-index.html
 		// A UNIQUE index creates a constraint such that all values in the index must be distinct.
@@ -144,35 +161,85 @@ func (c *index) GenIndexKey(indexedValues []types.Datum, h int64) (key []byte, d
 		}
 	}
 
+	keyValues := indexedValues
+	if collate.NewCollationEnabled() {
+		keyValues, needRestore = c.collationKeyValues(indexedValues)
+	}
 	key = append(key, []byte(c.prefix)...)
 	if distinct {
-		key, err = codec.EncodeKey(key, indexedValues...)
+		key, err = tablecodec.EncodeIndexValues(key, c.idxInfo.Columns, keyValues)
 	} else {
-		key, err = codec.EncodeKey(key, append(indexedValues, types.NewDatum(h))...)
+		key, err = tablecodec.EncodeIndexValues(key, c.idxInfo.Columns, append(keyValues, types.NewDatum(h)))
 	}
 	if err != nil {
-		return nil, false, errors.Trace(err)
+		return nil, false, false, errors.Trace(err)
 	}
 	return
 }
 
+// collationKeyValues returns the values encoded in the index key when the new collation is enabled.
+// The strings are replaced by their sort keys, so the equal strings of the collation have the same key,
+// and the decimals are encoded with the precision and frac of the column, so they are memory-comparable.
+// It returns true if some values are not the same as their key values.
+func (c *index) collationKeyValues(indexedValues []types.Datum) ([]types.Datum, bool) {
+	keyValues := make([]types.Datum, len(indexedValues))
+	needRestore := false
+	for i, v := range indexedValues {
+		keyValues[i] = v
+		ic := c.idxInfo.Columns[i]
+		if ic.Offset < 0 || ic.Offset >= len(c.tblInfo.Columns) {
+			continue
+		}
+		ft := &c.tblInfo.Columns[ic.Offset].FieldType
+		switch v.Kind() {
+		case types.KindString, types.KindBytes:
+			collator := collate.GetCollatorByFieldType(ft)
+			if collator == nil {
+				continue
+			}
+			sortKey := collator.Key(v.GetString())
+			if ic.Length != types.UnspecifiedLength && len(sortKey) > ic.Length {
+				sortKey = sortKey[:ic.Length]
+			}
+			if !bytes.Equal(sortKey, v.GetBytes()) {
+				keyValues[i].SetBytes(sortKey)
+				needRestore = true
+			}
+		case types.KindMysqlDecimal:
+			if ft.Tp == mysql.TypeNewDecimal && ft.Flen != types.UnspecifiedLength && ft.Decimal != types.UnspecifiedLength {
+				keyValues[i].SetLength(ft.Flen)
+				keyValues[i].SetFrac(ft.Decimal)
+			}
+		}
+	}
+	return keyValues, needRestore
+}
+
 // Create creates a new entry in the kvIndex data.
 // If the index is unique and there is an existing entry with the same key,
 // Create will return the existing entry's handle as the first return value, ErrKeyExists as the second return value.
 func (c *index) Create(rm kv.RetrieverMutator, indexedValues []types.Datum, h int64) (int64, error) {
-	key, distinct, err := c.GenIndexKey(indexedValues, h)
+	key, distinct, needRestore, err := c.genIndexKey(indexedValues, h)
 	if err != nil {
 		return 0, errors.Trace(err)
 	}
+	var restoredData []byte
+	if needRestore {
+		// The indexed values are restored from the value, see tablecodec.CutIndexRestoredData.
+		restoredData, err = codec.EncodeValue(nil, indexedValues...)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
 	if !distinct {
 		// non-unique index doesn't need store value, write a '0' to reduce space
-		err = rm.Set(key, []byte{'0'})
+		err = rm.Set(key, append([]byte{'0'}, restoredData...))
 		return 0, errors.Trace(err)
 	}
 
 	value, err := rm.Get(key)
 	if kv.IsErrNotFound(err) {
-		err = rm.Set(key, encodeHandle(h))
+		err = rm.Set(key, append(encodeHandle(h), restoredData...))
 		return 0, errors.Trace(err)
 	}
 	handle, err := decodeHandle(value)
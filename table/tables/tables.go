@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/engine"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/codec"
@@ -87,6 +88,14 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 			}
 			col.GeneratedExpr = expr
 		}
+		if col.DefaultIsExpr {
+			// The expression is parsed once here, not for each row that uses the default value.
+			expr, err := table.ParseDefaultExpr(colInfo)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			col.DefaultExpr = expr
+		}
 		columns = append(columns, col)
 	}
 
@@ -102,6 +111,17 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 	}
 
 	t.meta = tblInfo
+	if engine.IsExternal(tblInfo) {
+		e, ok := engine.Lookup(tblInfo.Engine)
+		if !ok {
+			return nil, table.ErrUnsupportedOp.Gen("unknown storage engine %s of table %s", tblInfo.Engine, tblInfo.Name)
+		}
+		et, err := engine.NewTable(t, e)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return et, nil
+	}
 	return t, nil
 }
 
@@ -248,9 +268,14 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 		} else {
 			value = newData[col.Offset]
 		}
+		if col.State == model.StatePublic && touched[col.Offset] {
+			if err = checkChangingElems(col, value); err != nil {
+				return errors.Trace(err)
+			}
+		}
 		if !t.canSkip(col, value) {
 			colIDs = append(colIDs, col.ID)
-			row = append(row, value)
+			row = append(row, rowValue(col, value))
 		}
 		if shouldWriteBinlog(ctx) && !t.canSkipUpdateBinlog(col, value) {
 			binlogColIDs = append(binlogColIDs, col.ID)
@@ -260,7 +285,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData, newData []ty
 	}
 
 	key := t.RecordKey(h)
-	value, err := tablecodec.EncodeRow(row, colIDs, ctx.GetSessionVars().GetTimeZone())
+	value, err := tablecodec.EncodeRowWithVersion(rowFormatVersion(ctx), row, colIDs, ctx.GetSessionVars().GetTimeZone())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -357,14 +382,17 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		} else {
 			value = r[col.Offset]
 		}
+		if err = checkChangingElems(col, value); err != nil {
+			return 0, errors.Trace(err)
+		}
 		if !t.canSkip(col, value) {
 			colIDs = append(colIDs, col.ID)
-			row = append(row, value)
+			row = append(row, rowValue(col, value))
 		}
 	}
 
 	key := t.RecordKey(recordID)
-	value, err := tablecodec.EncodeRow(row, colIDs, ctx.GetSessionVars().GetTimeZone())
+	value, err := tablecodec.EncodeRowWithVersion(rowFormatVersion(ctx), row, colIDs, ctx.GetSessionVars().GetTimeZone())
 	if err != nil {
 		return 0, errors.Trace(err)
 	}
@@ -735,6 +763,20 @@ func (t *Table) Seek(ctx context.Context, h int64) (int64, bool, error) {
 	return handle, true, nil
 }
 
+// rowFormatVersion returns the format version of the rows written by the session.
+// The binlog rows are always encoded in the version 1, the binlog consumers only read it.
+// The version 1 rows are written if the coprocessor of the store can't decode the version 2 rows.
+func rowFormatVersion(ctx context.Context) tablecodec.RowFormatVersion {
+	ver := tablecodec.RowFormatVersion(ctx.GetSessionVars().RowFormatVersion)
+	if ver == tablecodec.RowFormatV2 {
+		client := ctx.GetClient()
+		if client == nil || !client.IsRequestTypeSupported(kv.ReqTypeDAG, kv.ReqSubTypeRowFormatV2) {
+			return tablecodec.RowFormatV1
+		}
+	}
+	return ver
+}
+
 func shouldWriteBinlog(ctx context.Context) bool {
 	if ctx.GetSessionVars().BinlogClient == nil {
 		return false
@@ -756,13 +798,14 @@ func (t *Table) getMutation(ctx context.Context) *binlog.TableMutation {
 
 // canSkip is for these cases, we can skip the columns in encoded row:
 // 1. the column is included in primary key;
-// 2. the column's default value is null, and the value equals to that;
+// 2. the column's default value is null, and the value equals to that, the original default value must be null
+//    too, or the skipped column is read as the original default value;
 // 3. the column is virtual generated.
 func (t *Table) canSkip(col *table.Column, value types.Datum) bool {
 	if col.IsPKHandleColumn(t.meta) {
 		return true
 	}
-	if col.DefaultValue == nil && value.IsNull() {
+	if col.DefaultValue == nil && value.IsNull() && col.OriginDefaultValue == nil {
 		return true
 	}
 	if col.IsGenerated() && !col.GeneratedStored {
@@ -771,6 +814,23 @@ func (t *Table) canSkip(col *table.Column, value types.Datum) bool {
 	return false
 }
 
+// rowValue returns the value of the column that is stored in the row.
+func rowValue(col *table.Column, value types.Datum) types.Datum {
+	if col.ElemsByName {
+		return tablecodec.ElemsByName(value)
+	}
+	return value
+}
+
+// checkChangingElems checks that the enum or set value doesn't have the elements that are being removed from the
+// column, or the value would be lost when the elements are modified.
+func checkChangingElems(col *table.Column, value types.Datum) error {
+	if col.ChangingElems != nil && !tablecodec.ElemsIn(col.ChangingElems, value) {
+		return table.ErrElemBeingRemoved.GenByArgs(col.Name.O)
+	}
+	return nil
+}
+
 // canSkipUpdateBinlog checks whether the column can be skiped or not.
 func (t *Table) canSkipUpdateBinlog(col *table.Column, value types.Datum) bool {
 	if col.IsGenerated() && !col.GeneratedStored {
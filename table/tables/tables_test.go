@@ -323,3 +323,68 @@ func (ts *testSuite) TestTableFromMeta(c *C) {
 	c.Assert(tb, IsNil)
 	c.Assert(err, NotNil)
 }
+
+func (ts *testSuite) TestDefaultExprFromMeta(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := ts.se.Execute("CREATE TABLE test.default_expr (a int, b int default (1 + 2))")
+	c.Assert(err, IsNil)
+	ctx := ts.se.(context.Context)
+	dom := sessionctx.GetDomain(ctx)
+	tb, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("default_expr"))
+	c.Assert(err, IsNil)
+	cols := tb.Cols()
+	c.Assert(cols[0].DefaultExpr, IsNil)
+	// The default expression is parsed when the table is loaded.
+	c.Assert(cols[1].DefaultExpr, NotNil)
+	v, err := cols[1].GetDefaultValue(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(v.GetInt64(), Equals, int64(3))
+
+	tbInfo := tb.Meta().Clone()
+	tbInfo.Columns[1].DefaultValue = "(1"
+	_, err = tables.TableFromMeta(nil, tbInfo)
+	c.Assert(err, NotNil)
+}
+
+// noRowFormatV2Client is the client of a store whose coprocessor can't decode the version 2 rows.
+type noRowFormatV2Client struct {
+	kv.Client
+}
+
+func (c noRowFormatV2Client) IsRequestTypeSupported(reqType, subType int64) bool {
+	return subType != kv.ReqSubTypeRowFormatV2 && c.Client.IsRequestTypeSupported(reqType, subType)
+}
+
+type noRowFormatV2Context struct {
+	context.Context
+}
+
+func (ctx noRowFormatV2Context) GetClient() kv.Client {
+	return noRowFormatV2Client{ctx.Context.GetClient()}
+}
+
+func (ts *testSuite) TestRowFormatVersion(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := ts.se.Execute("CREATE TABLE test.tRowFormat (a int primary key, b int)")
+	c.Assert(err, IsNil)
+	ctx := ts.se.(context.Context)
+	dom := sessionctx.GetDomain(ctx)
+	tb, err := dom.InfoSchema().TableByName(model.NewCIStr("test"), model.NewCIStr("tRowFormat"))
+	c.Assert(err, IsNil)
+	rowFormat := func(ctx context.Context, h int64) tablecodec.RowFormatVersion {
+		c.Assert(ctx.NewTxn(), IsNil)
+		_, err = tb.AddRecord(ctx, types.MakeDatums(h, h))
+		c.Assert(err, IsNil)
+		val, err := ctx.Txn().Get(tablecodec.EncodeRowKeyWithHandle(tb.Meta().ID, h))
+		c.Assert(err, IsNil)
+		c.Assert(ctx.Txn().Rollback(), IsNil)
+		return tablecodec.GetRowFormatVersion(val)
+	}
+
+	c.Assert(rowFormat(ctx, 1), Equals, tablecodec.RowFormatV1)
+	ctx.GetSessionVars().RowFormatVersion = 2
+	c.Assert(rowFormat(ctx, 1), Equals, tablecodec.RowFormatV2)
+	// The version 1 rows are written if the coprocessor can't decode the version 2 rows.
+	c.Assert(rowFormat(noRowFormatV2Context{ctx}, 1), Equals, tablecodec.RowFormatV1)
+	ctx.GetSessionVars().RowFormatVersion = 1
+}
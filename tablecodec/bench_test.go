@@ -15,6 +15,9 @@ package tablecodec
 
 import (
 	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/util/types"
 )
 
 func BenchmarkEncodeRowKeyWithHandle(b *testing.B) {
@@ -40,3 +43,30 @@ func BenchmarkEncodeRowKeyWithPrefixNex(b *testing.B) {
 		sk.PrefixNext()
 	}
 }
+
+func benchmarkCutWideRow(b *testing.B, ver RowFormatVersion) {
+	row := make([]types.Datum, 100)
+	colIDs := make([]int64, 100)
+	for i := range row {
+		row[i] = types.NewIntDatum(int64(i))
+		colIDs[i] = int64(i + 1)
+	}
+	bs, err := EncodeRowWithVersion(ver, row, colIDs, time.UTC)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cols := map[int64]int{50: 0, 100: 1}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CutRowNew(bs, cols)
+	}
+}
+
+// BenchmarkCutWideRowV1 and BenchmarkCutWideRowV2 cut 2 columns of a row of 100 columns.
+func BenchmarkCutWideRowV1(b *testing.B) {
+	benchmarkCutWideRow(b, RowFormatV1)
+}
+
+func BenchmarkCutWideRowV2(b *testing.B) {
+	benchmarkCutWideRow(b, RowFormatV2)
+}
@@ -0,0 +1,295 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablecodec
+
+import (
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The row format version 2 has a directory of the column IDs and the value offsets before the values,
+// so a column is found by a binary search instead of cutting all the columns before it.
+//
+// Row layout:
+//
+//	codecVerV2 (1 byte) | flags (1 byte) | not null column count (2 bytes) | null column count (2 bytes) |
+//	not null column IDs | null column IDs | end offsets of the not null values | not null values
+//
+// The column IDs are sorted in each group, they take 1 byte each and the offsets 2 bytes each, or 4 bytes
+// both if the row has the rowFlagLarge flag. The integers are little endian. A value has the same encoding
+// as a value of the version 1 row, so the cut values of both versions are decoded in the same way.
+//
+// The version 1 row starts with the flag of the encoded column ID, which is never codecVerV2, so both
+// versions are decoded by the same functions and the tables may have rows of both versions.
+const (
+	codecVerV2      = 128
+	rowFlagLarge    = 1
+	rowV2HeaderSize = 6
+)
+
+// RowFormatVersion is the version of the row format.
+type RowFormatVersion int
+
+// The row format versions.
+const (
+	RowFormatV1 RowFormatVersion = 1
+	RowFormatV2 RowFormatVersion = 2
+)
+
+// EncodeRowWithVersion encodes the row in the row format version ver.
+func EncodeRowWithVersion(ver RowFormatVersion, row []types.Datum, colIDs []int64, loc *time.Location) ([]byte, error) {
+	if ver == RowFormatV2 {
+		return EncodeRowV2(row, colIDs, loc)
+	}
+	return EncodeRow(row, colIDs, loc)
+}
+
+// GetRowFormatVersion returns the row format version of the encoded row.
+func GetRowFormatVersion(b []byte) RowFormatVersion {
+	if len(b) > 0 && b[0] == codecVerV2 {
+		return RowFormatV2
+	}
+	return RowFormatV1
+}
+
+type rowV2Column struct {
+	id    int64
+	value []byte
+}
+
+type rowV2Columns []rowV2Column
+
+func (cols rowV2Columns) Len() int           { return len(cols) }
+func (cols rowV2Columns) Less(i, j int) bool { return cols[i].id < cols[j].id }
+func (cols rowV2Columns) Swap(i, j int)      { cols[i], cols[j] = cols[j], cols[i] }
+
+// EncodeRowV2 encodes the row data and the column IDs in the row format version 2.
+func EncodeRowV2(row []types.Datum, colIDs []int64, loc *time.Location) ([]byte, error) {
+	if len(row) != len(colIDs) {
+		return nil, errors.Errorf("EncodeRow error: data and columnID count not match %d vs %d", len(row), len(colIDs))
+	}
+	var notNullCols, nullCols rowV2Columns
+	large := false
+	dataLen := 0
+	for i, d := range row {
+		id := colIDs[i]
+		if id < 0 || id > 0xFFFFFFFF {
+			return nil, errors.Errorf("EncodeRow error: invalid column ID %d", id)
+		}
+		if id > 0xFF {
+			large = true
+		}
+		if d.IsNull() {
+			nullCols = append(nullCols, rowV2Column{id: id})
+			continue
+		}
+		fd, err := flatten(d, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		value, err := codec.EncodeValue(nil, fd)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		notNullCols = append(notNullCols, rowV2Column{id: id, value: value})
+		dataLen += len(value)
+	}
+	if len(row) > 0xFFFF {
+		return nil, errors.Errorf("EncodeRow error: too many columns %d", len(row))
+	}
+	if dataLen > 0xFFFF {
+		large = true
+	}
+	sort.Sort(notNullCols)
+	sort.Sort(nullCols)
+
+	idSize, offsetSize := 1, 2
+	var flags byte
+	if large {
+		idSize, offsetSize = 4, 4
+		flags |= rowFlagLarge
+	}
+	b := make([]byte, rowV2HeaderSize, rowV2HeaderSize+len(row)*idSize+len(notNullCols)*offsetSize+dataLen)
+	b[0] = codecVerV2
+	b[1] = flags
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(notNullCols)))
+	binary.LittleEndian.PutUint16(b[4:], uint16(len(nullCols)))
+	for _, cols := range []rowV2Columns{notNullCols, nullCols} {
+		for _, col := range cols {
+			if large {
+				b = appendUint32(b, uint32(col.id))
+			} else {
+				b = append(b, byte(col.id))
+			}
+		}
+	}
+	offset := 0
+	for _, col := range notNullCols {
+		offset += len(col.value)
+		if large {
+			b = appendUint32(b, uint32(offset))
+		} else {
+			b = append(b, byte(offset), byte(offset>>8))
+		}
+	}
+	for _, col := range notNullCols {
+		b = append(b, col.value...)
+	}
+	return b, nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// rowV2 is a row of the format version 2 whose directory is parsed.
+type rowV2 struct {
+	large      bool
+	notNullCnt int
+	nullCnt    int
+	notNullIDs []byte
+	nullIDs    []byte
+	offsets    []byte
+	data       []byte
+	idSize     int
+	offsetSize int
+}
+
+func newRowV2(b []byte) (*rowV2, error) {
+	if len(b) < rowV2HeaderSize || b[0] != codecVerV2 {
+		return nil, errors.New("invalid row of format version 2")
+	}
+	r := &rowV2{
+		large:      b[1]&rowFlagLarge > 0,
+		notNullCnt: int(binary.LittleEndian.Uint16(b[2:])),
+		nullCnt:    int(binary.LittleEndian.Uint16(b[4:])),
+		idSize:     1,
+		offsetSize: 2,
+	}
+	if r.large {
+		r.idSize, r.offsetSize = 4, 4
+	}
+	b = b[rowV2HeaderSize:]
+	dirLen := (r.notNullCnt+r.nullCnt)*r.idSize + r.notNullCnt*r.offsetSize
+	if len(b) < dirLen {
+		return nil, errors.New("invalid row of format version 2")
+	}
+	r.notNullIDs = b[:r.notNullCnt*r.idSize]
+	b = b[len(r.notNullIDs):]
+	r.nullIDs = b[:r.nullCnt*r.idSize]
+	b = b[len(r.nullIDs):]
+	r.offsets = b[:r.notNullCnt*r.offsetSize]
+	r.data = b[len(r.offsets):]
+	if r.notNullCnt > 0 && r.endOffset(r.notNullCnt-1) != len(r.data) {
+		return nil, errors.New("invalid row of format version 2")
+	}
+	return r, nil
+}
+
+func (r *rowV2) id(ids []byte, i int) int64 {
+	if r.large {
+		return int64(binary.LittleEndian.Uint32(ids[i*4:]))
+	}
+	return int64(ids[i])
+}
+
+func (r *rowV2) endOffset(i int) int {
+	if r.large {
+		return int(binary.LittleEndian.Uint32(r.offsets[i*4:]))
+	}
+	return int(binary.LittleEndian.Uint16(r.offsets[i*2:]))
+}
+
+// search returns the index of the column id in the sorted ids, or -1 if it is not found.
+func (r *rowV2) search(ids []byte, cnt int, id int64) int {
+	i := sort.Search(cnt, func(i int) bool { return r.id(ids, i) >= id })
+	if i < cnt && r.id(ids, i) == id {
+		return i
+	}
+	return -1
+}
+
+// value returns the encoded value of the column, the value of a null column is codec.NilFlag.
+// It returns false if the row doesn't have the column.
+func (r *rowV2) value(id int64) ([]byte, bool) {
+	if i := r.search(r.notNullIDs, r.notNullCnt, id); i >= 0 {
+		start := 0
+		if i > 0 {
+			start = r.endOffset(i - 1)
+		}
+		return r.data[start:r.endOffset(i)], true
+	}
+	if r.search(r.nullIDs, r.nullCnt, id) >= 0 {
+		return nilValue, true
+	}
+	return nil, false
+}
+
+var nilValue = []byte{codec.NilFlag}
+
+func decodeRowV2(b []byte, cols map[int64]*types.FieldType, loc *time.Location) (map[int64]types.Datum, error) {
+	r, err := newRowV2(b)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if r.notNullCnt+r.nullCnt == 0 {
+		return nil, nil
+	}
+	row := make(map[int64]types.Datum, len(cols))
+	for id, ft := range cols {
+		data, ok := r.value(id)
+		if !ok {
+			continue
+		}
+		v, err := DecodeColumnValue(data, ft, loc)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row[id] = v
+	}
+	return row, nil
+}
+
+func cutRowV2(b []byte, cols map[int64]*types.FieldType) (map[int64][]byte, error) {
+	r, err := newRowV2(b)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make(map[int64][]byte, len(cols))
+	for id := range cols {
+		if data, ok := r.value(id); ok {
+			row[id] = data
+		}
+	}
+	return row, nil
+}
+
+func cutRowNewV2(b []byte, colIDs map[int64]int) ([][]byte, error) {
+	r, err := newRowV2(b)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	row := make([][]byte, len(colIDs))
+	for id, offset := range colIDs {
+		if data, ok := r.value(id); ok {
+			row[offset] = data
+		}
+	}
+	return row, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablecodec
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testTableCodecSuite) TestRowCodecV2(c *C) {
+	defer testleak.AfterTest(c)()
+
+	tests := []struct {
+		cols []*column
+		row  []types.Datum
+	}{
+		// The small row.
+		{
+			cols: []*column{
+				{id: 3, tp: types.NewFieldType(mysql.TypeNewDecimal)},
+				{id: 1, tp: types.NewFieldType(mysql.TypeLonglong)},
+				{id: 5, tp: types.NewFieldType(mysql.TypeVarchar)},
+				{id: 2, tp: types.NewFieldType(mysql.TypeVarchar)},
+				{id: 4, tp: types.NewFieldType(mysql.TypeDatetime)},
+			},
+			row: []types.Datum{
+				types.NewDecimalDatum(types.NewDecFromInt(1)),
+				types.NewIntDatum(100),
+				{},
+				types.NewBytesDatum([]byte("abc")),
+				types.NewTimeDatum(types.Time{Time: types.FromGoTime(time.Date(2017, 1, 2, 3, 4, 5, 0, time.Local)), Type: mysql.TypeDatetime}),
+			},
+		},
+		// The large column ID.
+		{
+			cols: []*column{
+				{id: 1, tp: types.NewFieldType(mysql.TypeLonglong)},
+				{id: 300, tp: types.NewFieldType(mysql.TypeVarchar)},
+				{id: 70000, tp: types.NewFieldType(mysql.TypeLonglong)},
+			},
+			row: []types.Datum{types.NewIntDatum(-1), types.NewStringDatum("x"), {}},
+		},
+		// The large data.
+		{
+			cols: []*column{
+				{id: 1, tp: types.NewFieldType(mysql.TypeBlob)},
+				{id: 2, tp: types.NewFieldType(mysql.TypeLonglong)},
+			},
+			row: []types.Datum{types.NewBytesDatum([]byte(strings.Repeat("a", 70000))), types.NewIntDatum(2)},
+		},
+	}
+	sc := new(variable.StatementContext)
+	for _, t := range tests {
+		colIDs := make([]int64, 0, len(t.cols))
+		colMap := make(map[int64]*types.FieldType, len(t.cols)+1)
+		for _, col := range t.cols {
+			colIDs = append(colIDs, col.id)
+			colMap[col.id] = col.tp
+		}
+		bs, err := EncodeRowWithVersion(RowFormatV2, t.row, colIDs, time.Local)
+		c.Assert(err, IsNil)
+		c.Assert(GetRowFormatVersion(bs), Equals, RowFormatV2)
+
+		// The column not in the row is not decoded.
+		colMap[6] = types.NewFieldType(mysql.TypeLonglong)
+		r, err := DecodeRow(bs, colMap, time.Local)
+		c.Assert(err, IsNil)
+		c.Assert(r, HasLen, len(t.cols))
+		for i, col := range t.cols {
+			v, ok := r[col.id]
+			c.Assert(ok, IsTrue)
+			cmp, err1 := v.CompareDatum(sc, t.row[i])
+			c.Assert(err1, IsNil)
+			c.Assert(cmp, Equals, 0, Commentf("column %d", col.id))
+		}
+
+		// The cut values are the same as the values of the version 1 row.
+		v1, err := EncodeRow(t.row, colIDs, time.Local)
+		c.Assert(err, IsNil)
+		c.Assert(GetRowFormatVersion(v1), Equals, RowFormatV1)
+		cut1, err := CutRow(v1, colMap)
+		c.Assert(err, IsNil)
+		cut2, err := CutRow(bs, colMap)
+		c.Assert(err, IsNil)
+		c.Assert(cut2, HasLen, len(t.cols))
+		for _, col := range t.cols {
+			c.Assert(cut2[col.id], DeepEquals, cut1[col.id], Commentf("column %d", col.id))
+		}
+
+		offsets := make(map[int64]int, len(t.cols))
+		for i, col := range t.cols {
+			offsets[col.id] = i
+		}
+		offsets[6] = len(t.cols)
+		vals, err := CutRowNew(bs, offsets)
+		c.Assert(err, IsNil)
+		c.Assert(vals, HasLen, len(t.cols)+1)
+		for i, col := range t.cols {
+			c.Assert(vals[i], DeepEquals, cut1[col.id], Commentf("column %d", col.id))
+		}
+		c.Assert(vals[len(t.cols)], IsNil)
+	}
+
+	// The empty row.
+	bs, err := EncodeRowV2([]types.Datum{}, []int64{}, time.Local)
+	c.Assert(err, IsNil)
+	r, err := DecodeRow(bs, map[int64]*types.FieldType{1: types.NewFieldType(mysql.TypeLonglong)}, time.Local)
+	c.Assert(err, IsNil)
+	c.Assert(r, IsNil)
+
+	// The invalid rows.
+	_, err = EncodeRowV2([]types.Datum{types.NewIntDatum(1)}, []int64{}, time.Local)
+	c.Assert(err, NotNil)
+	_, err = EncodeRowV2([]types.Datum{types.NewIntDatum(1)}, []int64{-1}, time.Local)
+	c.Assert(err, NotNil)
+	bs, err = EncodeRowV2([]types.Datum{types.NewIntDatum(1)}, []int64{1}, time.Local)
+	c.Assert(err, IsNil)
+	_, err = CutRowNew(bs[:len(bs)-1], map[int64]int{1: 0})
+	c.Assert(err, NotNil)
+	_, err = CutRowNew(bs[:rowV2HeaderSize-1], map[int64]int{1: 0})
+	c.Assert(err, NotNil)
+}
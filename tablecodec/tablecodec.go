@@ -16,10 +16,12 @@ package tablecodec
 import (
 	"bytes"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
@@ -184,6 +186,45 @@ func EncodeRow(row []types.Datum, colIDs []int64, loc *time.Location) ([]byte, e
 	return codec.EncodeValue(nil, values...)
 }
 
+// ElemsByName returns the names of the elements of the enum or set value, it's used to store the values of
+// the column whose elements are being modified. Other values are returned as is.
+func ElemsByName(data types.Datum) types.Datum {
+	switch data.Kind() {
+	case types.KindMysqlEnum:
+		return types.NewBytesDatum([]byte(data.GetMysqlEnum().Name))
+	case types.KindMysqlSet:
+		return types.NewBytesDatum([]byte(data.GetMysqlSet().Name))
+	}
+	return data
+}
+
+// ElemsIn returns whether all the elements of the enum or set value are in the elements.
+func ElemsIn(elems []string, data types.Datum) bool {
+	var names []string
+	switch data.Kind() {
+	case types.KindMysqlEnum:
+		names = []string{data.GetMysqlEnum().Name}
+	case types.KindMysqlSet:
+		names = strings.Split(data.GetMysqlSet().Name, ",")
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, elem := range elems {
+			if strings.EqualFold(elem, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func flatten(data types.Datum, loc *time.Location) (types.Datum, error) {
 	switch data.Kind() {
 	case types.KindMysqlTime:
@@ -228,7 +269,7 @@ func DecodeColumnValue(data []byte, ft *types.FieldType, loc *time.Location) (ty
 }
 
 // DecodeRow decodes a byte slice into datums.
-// Row layout: colID1, value1, colID2, value2, .....
+// Row layout: colID1, value1, colID2, value2, ....., or the layout of the row format version 2.
 func DecodeRow(b []byte, cols map[int64]*types.FieldType, loc *time.Location) (map[int64]types.Datum, error) {
 	if b == nil {
 		return nil, nil
@@ -236,6 +277,9 @@ func DecodeRow(b []byte, cols map[int64]*types.FieldType, loc *time.Location) (m
 	if len(b) == 1 && b[0] == codec.NilFlag {
 		return nil, nil
 	}
+	if b[0] == codecVerV2 {
+		return decodeRowV2(b, cols, loc)
+	}
 	row := make(map[int64]types.Datum, len(cols))
 	cnt := 0
 	var (
@@ -280,7 +324,7 @@ func DecodeRow(b []byte, cols map[int64]*types.FieldType, loc *time.Location) (m
 }
 
 // CutRowNew cuts encoded row into byte slices and return columns' byte slice.
-// Row layout: colID1, value1, colID2, value2, .....
+// Row layout: colID1, value1, colID2, value2, ....., or the layout of the row format version 2.
 func CutRowNew(data []byte, colIDs map[int64]int) ([][]byte, error) {
 	if data == nil {
 		return nil, nil
@@ -288,6 +332,9 @@ func CutRowNew(data []byte, colIDs map[int64]int) ([][]byte, error) {
 	if len(data) == 1 && data[0] == codec.NilFlag {
 		return nil, nil
 	}
+	if data[0] == codecVerV2 {
+		return cutRowNewV2(data, colIDs)
+	}
 
 	var (
 		cnt int
@@ -321,7 +368,7 @@ func CutRowNew(data []byte, colIDs map[int64]int) ([][]byte, error) {
 }
 
 // CutRow cuts encoded row into byte slices and return interested columns' byte slice.
-// Row layout: colID1, value1, colID2, value2, .....
+// Row layout: colID1, value1, colID2, value2, ....., or the layout of the row format version 2.
 func CutRow(data []byte, cols map[int64]*types.FieldType) (map[int64][]byte, error) {
 	if data == nil {
 		return nil, nil
@@ -329,6 +376,9 @@ func CutRow(data []byte, cols map[int64]*types.FieldType) (map[int64][]byte, err
 	if len(data) == 1 && data[0] == codec.NilFlag {
 		return nil, nil
 	}
+	if data[0] == codecVerV2 {
+		return cutRowV2(data, cols)
+	}
 	row := make(map[int64][]byte, len(cols))
 	cnt := 0
 	var (
@@ -393,11 +443,23 @@ func unflatten(datum types.Datum, ft *types.FieldType, loc *time.Location) (type
 		datum.SetValue(dur)
 		return datum, nil
 	case mysql.TypeEnum:
+		if datum.Kind() == types.KindBytes {
+			// The value is stored by name, ignore error deliberately, to read empty enum value.
+			enum, _ := types.ParseEnumName(ft.Elems, string(datum.GetBytes()))
+			datum.SetValue(enum)
+			return datum, nil
+		}
 		// ignore error deliberately, to read empty enum value.
 		enum, _ := types.ParseEnumValue(ft.Elems, datum.GetUint64())
 		datum.SetValue(enum)
 		return datum, nil
 	case mysql.TypeSet:
+		if datum.Kind() == types.KindBytes {
+			// The value is stored by name, ignore error deliberately, to read empty set value.
+			set, _ := types.ParseSetName(ft.Elems, string(datum.GetBytes()))
+			datum.SetValue(set)
+			return datum, nil
+		}
 		set, err := types.ParseSetValue(ft.Elems, datum.GetUint64())
 		if err != nil {
 			return datum, errors.Trace(err)
@@ -421,6 +483,153 @@ func EncodeIndexSeekKey(tableID int64, idxID int64, encodedValue []byte) kv.Key
 	return key
 }
 
+// EncodeIndexValues appends the encoded values of the index columns to b, the values of the descending
+// columns are encoded by codec.EncodeKeyDesc. The values after the index columns, e.g. the handle,
+// are encoded in ascending order.
+func EncodeIndexValues(b []byte, cols []*model.IndexColumn, values []types.Datum) ([]byte, error) {
+	if !hasDescColumn(cols) {
+		return codec.EncodeKey(b, values...)
+	}
+	var err error
+	for i := range values {
+		b, err = encodeIndexValue(b, cols, i, values[i])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return b, nil
+}
+
+func encodeIndexValue(b []byte, cols []*model.IndexColumn, offset int, value types.Datum) ([]byte, error) {
+	if offset < len(cols) && cols[offset].Desc {
+		return codec.EncodeKeyDesc(b, value)
+	}
+	return codec.EncodeKey(b, value)
+}
+
+func hasDescColumn(cols []*model.IndexColumn) bool {
+	for _, col := range cols {
+		if col.Desc {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeIndexRange encodes the index range of the index columns to the encoded values of the start key,
+// which is included in the range, and the end key, which is excluded.
+func EncodeIndexRange(ran *types.IndexRange, cols []*model.IndexColumn) (low, high []byte, err error) {
+	if !hasDescColumn(cols) {
+		low, err = codec.EncodeKey(nil, ran.LowVal...)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if ran.LowExclude {
+			low = []byte(kv.Key(low).PrefixNext())
+		}
+		high, err = codec.EncodeKey(nil, ran.HighVal...)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if !ran.HighExclude {
+			high = []byte(kv.Key(high).PrefixNext())
+		}
+		return low, high, nil
+	}
+
+	// The columns before the first column whose low and high values are different are points.
+	// The range of the rest columns is determined by that column, since the columns after it are
+	// either absent or the NULL and MaxValue paddings of the excluded bounds.
+	k := 0
+	for ; k < len(ran.LowVal) && k < len(ran.HighVal); k++ {
+		same, err1 := sameKeyDatum(ran.LowVal[k], ran.HighVal[k])
+		if err1 != nil {
+			return nil, nil, errors.Trace(err1)
+		}
+		if !same {
+			break
+		}
+		low, err = encodeIndexValue(low, cols, k, ran.LowVal[k])
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	high = append([]byte(nil), low...)
+	if k == len(ran.LowVal) || k == len(ran.HighVal) {
+		if ran.LowExclude {
+			low = []byte(kv.Key(low).PrefixNext())
+		}
+		if !ran.HighExclude {
+			high = []byte(kv.Key(high).PrefixNext())
+		}
+		return low, high, nil
+	}
+	lowVal, lowExclude := ran.LowVal[k], ran.LowExclude
+	if k+1 < len(ran.LowVal) {
+		lowExclude = ran.LowVal[k+1].Kind() == types.KindMaxValue
+	}
+	highVal, highExclude := ran.HighVal[k], ran.HighExclude
+	if k+1 < len(ran.HighVal) {
+		highExclude = ran.HighVal[k+1].IsNull()
+	}
+	if k >= len(cols) || !cols[k].Desc {
+		low, err = appendIndexRangeBound(low, lowVal, lowExclude, false)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		high, err = appendIndexRangeBound(high, highVal, !highExclude, false)
+		return low, high, errors.Trace(err)
+	}
+	// The larger values of a descending column are stored before the smaller ones.
+	low, err = appendIndexRangeBound(low, highVal, highExclude, true)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	high, err = appendIndexRangeBound(high, lowVal, !lowExclude, true)
+	return low, high, errors.Trace(err)
+}
+
+// appendIndexRangeBound appends the encoded bound value of a range to the encoded point values before it.
+// If next is true, the keys that have the prefix of the bound value are before the returned bound.
+func appendIndexRangeBound(b []byte, v types.Datum, next bool, desc bool) ([]byte, error) {
+	var err error
+	if !desc {
+		b, err = codec.EncodeKey(b, v)
+	} else {
+		switch v.Kind() {
+		case types.KindMaxValue:
+			// The MaxValue is before all the values of a descending column.
+			return b, nil
+		case types.KindMinNotNull:
+			// The MinNotNull is between the NULL and the not NULL values, the NULL is the last in a descending column.
+			return codec.EncodeKeyDesc(b, types.Datum{})
+		}
+		b, err = codec.EncodeKeyDesc(b, v)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if next {
+		b = []byte(kv.Key(b).PrefixNext())
+	}
+	return b, nil
+}
+
+func sameKeyDatum(a, b types.Datum) (bool, error) {
+	if a.Kind() != b.Kind() {
+		return false, nil
+	}
+	ka, err := codec.EncodeKey(nil, a)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	kb, err := codec.EncodeKey(nil, b)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return bytes.Equal(ka, kb), nil
+}
+
 // DecodeIndexKey decodes datums from an index key.
 func DecodeIndexKey(key kv.Key) ([]types.Datum, error) {
 	b := key[prefixLen+idLen:]
@@ -461,6 +670,38 @@ func CutIndexKeyNew(key kv.Key, length int) (values [][]byte, b []byte, err erro
 	return
 }
 
+// The index value is the handle if the index key doesn't have the handle, or "0" otherwise. If the indexed values
+// can't be decoded from the index key, e.g. the sort keys of the strings of the "_ci" collations, the encoded
+// indexed values are appended to the index value as the restored data.
+const indexValueHandleLen = 8
+
+// CutIndexRestoredData returns the restored data of the index value, which is nil if the indexed values are
+// decoded from the index key. handleInKey indicates if the handle is encoded in the index key.
+func CutIndexRestoredData(value []byte, handleInKey bool) []byte {
+	handleLen := indexValueHandleLen
+	if handleInKey {
+		handleLen = 1
+	}
+	if len(value) <= handleLen {
+		return nil
+	}
+	return value[handleLen:]
+}
+
+// CutIndexRestoredValues cuts the restored data of an index value into the encoded values of length columns.
+func CutIndexRestoredValues(data []byte, length int) (values [][]byte, err error) {
+	values = make([][]byte, 0, length)
+	for i := 0; i < length; i++ {
+		var val []byte
+		val, data, err = codec.CutOne(data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
 // EncodeTableIndexPrefix encodes index prefix with tableID and idxID.
 func EncodeTableIndexPrefix(tableID, idxID int64) kv.Key {
 	key := make([]byte, 0, prefixLen)
@@ -186,6 +186,41 @@ func (s *testTableCodecSuite) TestTimeCodec(c *C) {
 	}
 }
 
+func (s *testTableCodecSuite) TestElemsByName(c *C) {
+	defer testleak.AfterTest(c)()
+
+	enumTp := types.NewFieldType(mysql.TypeEnum)
+	enumTp.Elems = []string{"a", "b", "c"}
+	setTp := types.NewFieldType(mysql.TypeSet)
+	setTp.Elems = []string{"a", "b", "c"}
+	enum, err := types.ParseEnumName(enumTp.Elems, "b")
+	c.Assert(err, IsNil)
+	set, err := types.ParseSetName(setTp.Elems, "a,c")
+	c.Assert(err, IsNil)
+
+	row := []types.Datum{ElemsByName(types.NewDatum(enum)), ElemsByName(types.NewDatum(set))}
+	c.Assert(row[0].GetString(), Equals, "b")
+	c.Assert(row[1].GetString(), Equals, "a,c")
+	bs, err := EncodeRow(row, []int64{1, 2}, time.Local)
+	c.Assert(err, IsNil)
+
+	// The values are decoded by the names of the new elements.
+	enumTp.Elems = []string{"c", "b"}
+	setTp.Elems = []string{"c", "a"}
+	r, err := DecodeRow(bs, map[int64]*types.FieldType{1: enumTp, 2: setTp}, time.Local)
+	c.Assert(err, IsNil)
+	enumVal, setVal := r[1], r[2]
+	c.Assert(enumVal.GetMysqlEnum(), Equals, types.Enum{Name: "b", Value: 2})
+	c.Assert(setVal.GetMysqlSet(), Equals, types.Set{Name: "c,a", Value: 3})
+
+	// The removed element is read as an empty value.
+	enumTp.Elems = []string{"a"}
+	r, err = DecodeRow(bs, map[int64]*types.FieldType{1: enumTp}, time.Local)
+	c.Assert(err, IsNil)
+	enumVal = r[1]
+	c.Assert(enumVal.GetMysqlEnum(), Equals, types.Enum{})
+}
+
 func (s *testTableCodecSuite) TestCutRow(c *C) {
 	defer testleak.AfterTest(c)()
 
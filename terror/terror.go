@@ -82,6 +82,7 @@ const (
 	ClassGlobal
 	ClassMockTikv
 	ClassJSON
+	ClassEngine
 	// Add more as needed.
 )
 
@@ -107,6 +108,7 @@ var errClz2Str = map[ErrClass]string{
 	ClassTypes:         "types",
 	ClassGlobal:        "global",
 	ClassMockTikv:      "mocktikv",
+	ClassEngine:        "engine",
 }
 
 // String implements fmt.Stringer interface.
@@ -268,17 +270,29 @@ func (e *Error) ToSQLError() *mysql.SQLError {
 var defaultMySQLErrorCode uint16
 
 func (e *Error) getMySQLErrorCode() uint16 {
-	codeMap, ok := ErrClassToMySQLCodes[e.class]
-	if !ok {
-		log.Warnf("Unknown error class: %v", e.class)
-		return defaultMySQLErrorCode
+	if code, ok := ErrClassToMySQLCodes[e.class][e.code]; ok {
+		return code
 	}
-	code, ok := codeMap[e.code]
-	if !ok {
-		log.Warnf("Unknown error class: %v code: %v", e.class, e.code)
-		return defaultMySQLErrorCode
+	// The codes of many errors are the MySQL error codes, they are used as is if they are not mapped.
+	if _, ok := mysql.MySQLErrName[uint16(e.code)]; ok {
+		return uint16(e.code)
+	}
+	log.Warnf("Unknown error class: %v code: %v", e.class, e.code)
+	return defaultMySQLErrorCode
+}
+
+// ToSQLError converts an error to the mysql.SQLError returned to the clients. An *Error is converted by
+// the MySQL error codes of its class, a *mysql.SQLError is returned as is, and the other errors are
+// converted to ErrUnknown.
+func ToSQLError(err error) *mysql.SQLError {
+	switch x := errors.Cause(err).(type) {
+	case *Error:
+		return x.ToSQLError()
+	case *mysql.SQLError:
+		return x
+	default:
+		return mysql.NewErrf(mysql.ErrUnknown, "%s", err.Error())
 	}
-	return code
 }
 
 var (
@@ -20,6 +20,7 @@ import (
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
@@ -156,3 +157,24 @@ func (s *testTErrorSuite) TestErrorEqual(c *C) {
 	c.Assert(ErrorEqual(te1, te3), IsFalse)
 	c.Assert(ErrorEqual(te3, te4), IsFalse)
 }
+
+func (s *testTErrorSuite) TestToSQLError(c *C) {
+	defer testleak.AfterTest(c)()
+	// The code isn't mapped, it is a MySQL error code.
+	dupErr := ClassDomain.New(mysql.ErrDupEntry, "Duplicate entry '%s' for key '%s'")
+	sqlErr := ToSQLError(errors.Trace(dupErr.GenByArgs("1", "PRIMARY")))
+	c.Assert(sqlErr.Code, Equals, uint16(mysql.ErrDupEntry))
+	c.Assert(sqlErr.State, Equals, mysql.MySQLState[mysql.ErrDupEntry])
+	c.Assert(sqlErr.Message, Equals, "Duplicate entry '1' for key 'PRIMARY'")
+
+	sqlErr = ToSQLError(ClassDomain.New(ErrCode(1), "unknown"))
+	c.Assert(sqlErr.Code, Equals, uint16(mysql.ErrUnknown))
+	c.Assert(sqlErr.State, Equals, mysql.DefaultMySQLState)
+
+	origin := mysql.NewErr(mysql.ErrNoDB)
+	c.Assert(ToSQLError(errors.Trace(origin)), Equals, origin)
+
+	sqlErr = ToSQLError(errors.New("plain error"))
+	c.Assert(sqlErr.Code, Equals, uint16(mysql.ErrUnknown))
+	c.Assert(sqlErr.Message, Equals, "plain error")
+}
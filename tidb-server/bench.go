@@ -0,0 +1,553 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb"
+)
+
+// benchWorkload is a workload run by the bench subcommand.
+type benchWorkload interface {
+	// prepare creates the tables of the workload and loads the data, it runs with the given concurrency.
+	prepare(db *sql.DB, threads int) error
+	// event runs a transaction of the workload, it returns the name of the transaction type.
+	event(c *benchConn) (string, error)
+	// cleanup drops the tables of the workload.
+	cleanup(db *sql.DB) error
+}
+
+// benchWorkloads are the workloads by name, the arguments are the number of the tables and the table size for the
+// sysbench workloads, and the number of the warehouses and the customers per district for tpcc, 0 means the default.
+var benchWorkloads = map[string]func(tables, size int) benchWorkload{
+	"oltp_point_select": newSysbench(sysbenchPointSelect),
+	"oltp_read_only":    newSysbench(sysbenchReadOnly),
+	"oltp_read_write":   newSysbench(sysbenchReadWrite),
+	"oltp_write_only":   newSysbench(sysbenchWriteOnly),
+	"oltp_update_index": newSysbench(sysbenchUpdateIndex),
+	"oltp_insert":       newSysbench(sysbenchInsert),
+	"tpcc":              newTPCC,
+	"tpcc_new_order":    newTPCCOnly(tpccNewOrder),
+	"tpcc_payment":      newTPCCOnly(tpccPayment),
+	"tpcc_order_status": newTPCCOnly(tpccOrderStatus),
+	"tpcc_delivery":     newTPCCOnly(tpccDelivery),
+	"tpcc_stock_level":  newTPCCOnly(tpccStockLevel),
+}
+
+// runBench runs the "bench" subcommand, it runs a workload against an embedded store opened by -store and -path,
+// or a remote server by -addr, and reports the throughput and the latencies, e.g.
+//
+//	tidb-server -store goleveldb -path /tmp/bench bench -workload oltp_read_write -threads 8 -time 60s
+//	tidb-server bench -addr 'root@tcp(127.0.0.1:4000)/' -workload tpcc -tables 2 -run=false
+//
+// The returned value is the exit code.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	addr := fs.String("addr", "", "DSN of the remote server, e.g. 'root@tcp(127.0.0.1:4000)/', the store of -store and -path is opened in the process if it's empty")
+	dbName := fs.String("db", "bench", "the database of the tables")
+	workloadName := fs.String("workload", "oltp_read_write", "the workload, one of: "+strings.Join(benchWorkloadNames(), ", "))
+	tables := fs.Int("tables", 0, "the number of the tables for sysbench, or the number of the warehouses for tpcc (default 4 tables or 1 warehouse)")
+	size := fs.Int("size", 0, "the number of the rows per table for sysbench, or the number of the customers per district for tpcc (default 10000 rows or 3000 customers)")
+	threads := fs.Int("threads", 16, "the number of the concurrent connections")
+	duration := fs.Duration("time", time.Minute, "the time to run the workload")
+	interval := fs.Duration("report-interval", 10*time.Second, "the interval of the intermediate reports, set 0 to disable them")
+	prepare := fs.Bool("prepare", true, "create the tables and load the data before running")
+	run := fs.Bool("run", true, "run the workload")
+	cleanup := fs.Bool("cleanup", false, "drop the tables after running")
+	level := fs.String("L", "error", "log level: info, debug, warn, error, fatal")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "the random seed")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	newWorkload, ok := benchWorkloads[*workloadName]
+	if !ok || *threads <= 0 || *tables < 0 || *size < 0 {
+		fs.Usage()
+		return 2
+	}
+	if lvl, err := log.ParseLevel(*level); err == nil {
+		log.SetLevel(lvl)
+	}
+	rand.Seed(*seed)
+
+	var db *sql.DB
+	var err error
+	if *addr == "" {
+		db, err = tidb.Open(fmt.Sprintf("%s://%s/%s", *store, strings.TrimSuffix(*storePath, "/"), *dbName))
+	} else {
+		db, err = openRemoteBenchDB(*addr, *dbName)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open the database error: %v\n", errors.ErrorStack(err))
+		return 1
+	}
+	defer db.Close()
+	db.SetMaxIdleConns(*threads)
+
+	w := newWorkload(*tables, *size)
+	if *prepare {
+		fmt.Printf("preparing %s\n", *workloadName)
+		start := time.Now()
+		if err = w.prepare(db, *threads); err != nil {
+			fmt.Fprintf(os.Stderr, "prepare error: %v\n", errors.ErrorStack(err))
+			return 1
+		}
+		fmt.Printf("prepared in %v\n", time.Since(start))
+	}
+	if *run {
+		fmt.Printf("running %s with %d threads for %v, seed %d\n", *workloadName, *threads, *duration, *seed)
+		r := newBenchRunner(db, w, *threads)
+		r.run(*duration, *interval)
+		r.report()
+	}
+	if *cleanup {
+		if err = w.cleanup(db); err != nil {
+			fmt.Fprintf(os.Stderr, "cleanup error: %v\n", errors.ErrorStack(err))
+			return 1
+		}
+	}
+	return 0
+}
+
+func benchWorkloadNames() []string {
+	names := make([]string, 0, len(benchWorkloads))
+	for name := range benchWorkloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openRemoteBenchDB connects to the remote server and creates the database.
+func openRemoteBenchDB(addr, dbName string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", addr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", dbName))
+	db.Close()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dsn := addr
+	if i := strings.LastIndex(addr, "/"); i >= 0 {
+		dsn = addr[:i+1] + dbName + addr[i+1:]
+	}
+	db, err = sql.Open("mysql", dsn)
+	return db, errors.Trace(err)
+}
+
+// benchConn runs the statements of an event in a transaction or on the connection pool, and counts them.
+type benchConn struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	rand    *rand.Rand
+	queries int
+}
+
+func (c *benchConn) begin() error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.tx = tx
+	return nil
+}
+
+// finish commits the transaction of the event, or rolls it back if the event failed.
+func (c *benchConn) finish(err error) error {
+	if c.tx == nil {
+		return errors.Trace(err)
+	}
+	tx := c.tx
+	c.tx = nil
+	if err != nil {
+		tx.Rollback()
+		return errors.Trace(err)
+	}
+	c.queries++
+	return errors.Trace(tx.Commit())
+}
+
+func (c *benchConn) exec(query string, args ...interface{}) (sql.Result, error) {
+	c.queries++
+	if c.tx != nil {
+		res, err := c.tx.Exec(query, args...)
+		return res, errors.Trace(err)
+	}
+	res, err := c.db.Exec(query, args...)
+	return res, errors.Trace(err)
+}
+
+// query runs the query and discards the rows.
+func (c *benchConn) query(query string, args ...interface{}) error {
+	c.queries++
+	var rows *sql.Rows
+	var err error
+	if c.tx != nil {
+		rows, err = c.tx.Query(query, args...)
+	} else {
+		rows, err = c.db.Query(query, args...)
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return errors.Trace(rows.Err())
+}
+
+// queryRow runs the query and scans the first row into dest.
+func (c *benchConn) queryRow(query string, args []interface{}, dest ...interface{}) error {
+	c.queries++
+	var row *sql.Row
+	if c.tx != nil {
+		row = c.tx.QueryRow(query, args...)
+	} else {
+		row = c.db.QueryRow(query, args...)
+	}
+	return errors.Trace(row.Scan(dest...))
+}
+
+// queryInts runs the query and returns the first column of the rows.
+func (c *benchConn) queryInts(query string, args ...interface{}) ([]int, error) {
+	c.queries++
+	var rows *sql.Rows
+	var err error
+	if c.tx != nil {
+		rows, err = c.tx.Query(query, args...)
+	} else {
+		rows, err = c.db.Query(query, args...)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer rows.Close()
+	var values []int
+	for rows.Next() {
+		var v int
+		if err = rows.Scan(&v); err != nil {
+			return nil, errors.Trace(err)
+		}
+		values = append(values, v)
+	}
+	return values, errors.Trace(rows.Err())
+}
+
+// randInt returns a random number in [min, max].
+func (c *benchConn) randInt(min, max int) int {
+	return min + c.rand.Intn(max-min+1)
+}
+
+// benchStats are the statistics of the events of a transaction type.
+type benchStats struct {
+	events    int64
+	errors    int64
+	latencies benchHistogram
+}
+
+// The buckets of benchHistogram are on the logarithmic scale from benchHistMin to benchHistMax like the ones of
+// sysbench, the ratio of the bounds of a bucket is about 1.02.
+const (
+	benchHistBuckets = 1024
+	benchHistMin     = time.Microsecond
+	benchHistMax     = 100 * time.Second
+)
+
+var benchHistMult = float64(benchHistBuckets-1) / math.Log(float64(benchHistMax)/float64(benchHistMin))
+
+// benchHistogram is the histogram of the latencies, its size is fixed however long the workload runs. The min, max
+// and average latencies are exact, and the percentiles are the upper bounds of the buckets.
+type benchHistogram struct {
+	counts [benchHistBuckets]int64
+	count  int64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+func (h *benchHistogram) add(lat time.Duration) {
+	i := 0
+	if lat > benchHistMin {
+		i = int(math.Log(float64(lat)/float64(benchHistMin)) * benchHistMult)
+		if i >= benchHistBuckets {
+			i = benchHistBuckets - 1
+		}
+	}
+	h.counts[i]++
+	if h.count == 0 || lat < h.min {
+		h.min = lat
+	}
+	if lat > h.max {
+		h.max = lat
+	}
+	h.count++
+	h.sum += lat
+}
+
+func (h *benchHistogram) merge(other *benchHistogram) {
+	if other.count == 0 {
+		return
+	}
+	for i, cnt := range other.counts {
+		h.counts[i] += cnt
+	}
+	if h.count == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.count += other.count
+	h.sum += other.sum
+}
+
+// percentile returns the latency of the nearest rank of the percentile p, it's 0 if there is no latency.
+func (h *benchHistogram) percentile(p int) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := (h.count*int64(p) + 99) / 100
+	var cnt int64
+	for i, c := range h.counts {
+		cnt += c
+		if cnt < rank {
+			continue
+		}
+		upper := time.Duration(float64(benchHistMin) * math.Exp(float64(i+1)/benchHistMult))
+		// The last bucket has the latencies greater than benchHistMax too.
+		if upper > h.max || i == benchHistBuckets-1 {
+			return h.max
+		}
+		if upper < h.min {
+			return h.min
+		}
+		return upper
+	}
+	return h.max
+}
+
+// benchRunner runs the events of a workload concurrently.
+type benchRunner struct {
+	db      *sql.DB
+	w       benchWorkload
+	threads int
+	elapsed time.Duration
+
+	// The counters are updated atomically for the intermediate reports.
+	events  int64
+	queries int64
+	errors  int64
+
+	mu    sync.Mutex
+	stats map[string]*benchStats
+}
+
+func newBenchRunner(db *sql.DB, w benchWorkload, threads int) *benchRunner {
+	return &benchRunner{
+		db:      db,
+		w:       w,
+		threads: threads,
+		stats:   make(map[string]*benchStats),
+	}
+}
+
+func (r *benchRunner) run(duration, interval time.Duration) {
+	start := time.Now()
+	deadline := start.Add(duration)
+	var wg sync.WaitGroup
+	for i := 0; i < r.threads; i++ {
+		wg.Add(1)
+		c := &benchConn{db: r.db, rand: rand.New(rand.NewSource(rand.Int63()))}
+		go func() {
+			defer wg.Done()
+			r.runWorker(c, deadline)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	var lastEvents, lastQueries, lastErrors int64
+	for {
+		select {
+		case <-tick:
+			events, queries, errs := atomic.LoadInt64(&r.events), atomic.LoadInt64(&r.queries), atomic.LoadInt64(&r.errors)
+			secs := interval.Seconds()
+			fmt.Printf("[%4.0fs] thds: %d tps: %.2f qps: %.2f err/s: %.2f\n", time.Since(start).Seconds(), r.threads,
+				float64(events-lastEvents)/secs, float64(queries-lastQueries)/secs, float64(errs-lastErrors)/secs)
+			lastEvents, lastQueries, lastErrors = events, queries, errs
+		case <-done:
+			r.elapsed = time.Since(start)
+			return
+		}
+	}
+}
+
+func (r *benchRunner) runWorker(c *benchConn, deadline time.Time) {
+	stats := make(map[string]*benchStats)
+	for time.Now().Before(deadline) {
+		c.queries = 0
+		start := time.Now()
+		name, err := r.w.event(c)
+		err = c.finish(err)
+		lat := time.Since(start)
+		s, ok := stats[name]
+		if !ok {
+			s = &benchStats{}
+			stats[name] = s
+		}
+		atomic.AddInt64(&r.queries, int64(c.queries))
+		if err != nil {
+			log.Debugf("[bench] %s error: %v", name, err)
+			s.errors++
+			atomic.AddInt64(&r.errors, 1)
+			continue
+		}
+		s.events++
+		s.latencies.add(lat)
+		atomic.AddInt64(&r.events, 1)
+	}
+	r.mu.Lock()
+	for name, s := range stats {
+		total, ok := r.stats[name]
+		if !ok {
+			total = &benchStats{}
+			r.stats[name] = total
+		}
+		total.events += s.events
+		total.errors += s.errors
+		total.latencies.merge(&s.latencies)
+	}
+	r.mu.Unlock()
+}
+
+// report prints the throughput, and the latencies of the transaction types and all the transactions.
+func (r *benchRunner) report() {
+	secs := r.elapsed.Seconds()
+	fmt.Printf("\ntime: %.2fs\n", secs)
+	fmt.Printf("transactions: %d (%.2f per sec.)\n", r.events, float64(r.events)/secs)
+	fmt.Printf("queries: %d (%.2f per sec.)\n", r.queries, float64(r.queries)/secs)
+	fmt.Printf("errors: %d (%.2f per sec.)\n", r.errors, float64(r.errors)/secs)
+	fmt.Printf("\n%-16s %10s %8s %10s %10s %10s %10s %10s\n", "latency (ms)", "count", "errors", "min", "avg", "p95", "p99", "max")
+	all := &benchStats{}
+	names := make([]string, 0, len(r.stats))
+	for name, s := range r.stats {
+		names = append(names, name)
+		all.events += s.events
+		all.errors += s.errors
+		all.latencies.merge(&s.latencies)
+	}
+	sort.Strings(names)
+	if len(names) > 1 {
+		for _, name := range names {
+			r.stats[name].print(name)
+		}
+	}
+	all.print("total")
+}
+
+func (s *benchStats) print(name string) {
+	h := &s.latencies
+	ms := func(d time.Duration) float64 { return d.Seconds() * 1000 }
+	var avg float64
+	if h.count > 0 {
+		avg = ms(h.sum) / float64(h.count)
+	}
+	fmt.Printf("%-16s %10d %8d %10.2f %10.2f %10.2f %10.2f %10.2f\n", name, s.events, s.errors, ms(h.min), avg,
+		ms(h.percentile(95)), ms(h.percentile(99)), ms(h.max))
+}
+
+// loadConcurrently runs the load functions with the given concurrency, and returns the first error.
+func loadConcurrently(threads int, loads []func() error) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	ch := make(chan func() error)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for load := range ch {
+				if err := load(); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, load := range loads {
+		ch <- load
+	}
+	close(ch)
+	wg.Wait()
+	return errors.Trace(firstErr)
+}
+
+// insertBatches inserts the rows generated by row for [0, n) in batches, row returns the values of a row like "(1, 'a')".
+func insertBatches(db *sql.DB, insert string, n int, row func(i int) string) error {
+	const batchSize = 500
+	values := make([]string, 0, batchSize)
+	for i := 0; i < n; i++ {
+		values = append(values, row(i))
+		if len(values) == batchSize || i == n-1 {
+			if _, err := db.Exec(insert + " VALUES " + strings.Join(values, ",")); err != nil {
+				return errors.Trace(err)
+			}
+			values = values[:0]
+		}
+	}
+	return nil
+}
+
+// randString returns a random string of the letters and digits, it's generated by the template like sysbench, a '#'
+// is replaced by a digit and a '@' by a letter.
+func randString(rnd *rand.Rand, template string) string {
+	b := []byte(template)
+	for i, ch := range b {
+		switch ch {
+		case '#':
+			b[i] = byte('0' + rnd.Intn(10))
+		case '@':
+			b[i] = byte('a' + rnd.Intn(26))
+		}
+	}
+	return string(b)
+}
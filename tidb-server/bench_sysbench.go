@@ -0,0 +1,158 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+
+	"github.com/juju/errors"
+)
+
+type sysbenchKind int
+
+// The sysbench OLTP workloads, the statements of the events are the same as the ones of sysbench with the default
+// options.
+const (
+	sysbenchPointSelect sysbenchKind = iota
+	sysbenchReadOnly
+	sysbenchReadWrite
+	sysbenchWriteOnly
+	sysbenchUpdateIndex
+	sysbenchInsert
+)
+
+const (
+	sysbenchPointSelects = 10
+	sysbenchRangeSize    = 100
+	sysbenchCTemplate    = "###########-###########-###########-###########-###########-###########-###########-###########-###########-###########"
+	sysbenchPadTemplate  = "###########-###########-###########-###########-###########"
+)
+
+// sysbench is a sysbench OLTP workload on the tables sbtest1, sbtest2... whose ids are from 1 to size.
+type sysbench struct {
+	kind   sysbenchKind
+	tables int
+	size   int
+}
+
+func newSysbench(kind sysbenchKind) func(tables, size int) benchWorkload {
+	return func(tables, size int) benchWorkload {
+		if tables == 0 {
+			tables = 4
+		}
+		if size == 0 {
+			size = 10000
+		}
+		return &sysbench{kind: kind, tables: tables, size: size}
+	}
+}
+
+func (s *sysbench) prepare(db *sql.DB, threads int) error {
+	loads := make([]func() error, 0, s.tables)
+	for i := 1; i <= s.tables; i++ {
+		table := fmt.Sprintf("sbtest%d", i)
+		loads = append(loads, func() error {
+			_, err := db.Exec("CREATE TABLE " + table + ` (
+  id INT NOT NULL AUTO_INCREMENT,
+  k INT NOT NULL DEFAULT 0,
+  c CHAR(120) NOT NULL DEFAULT '',
+  pad CHAR(60) NOT NULL DEFAULT '',
+  PRIMARY KEY (id),
+  KEY k_1 (k))`)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			rnd := rand.New(rand.NewSource(rand.Int63()))
+			return insertBatches(db, "INSERT INTO "+table+" (id, k, c, pad)", s.size, func(i int) string {
+				return fmt.Sprintf("(%d, %d, '%s', '%s')", i+1, rnd.Intn(s.size)+1,
+					randString(rnd, sysbenchCTemplate), randString(rnd, sysbenchPadTemplate))
+			})
+		})
+	}
+	return errors.Trace(loadConcurrently(threads, loads))
+}
+
+func (s *sysbench) cleanup(db *sql.DB) error {
+	for i := 1; i <= s.tables; i++ {
+		if _, err := db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS sbtest%d", i)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (s *sysbench) event(c *benchConn) (string, error) {
+	table := fmt.Sprintf("sbtest%d", c.randInt(1, s.tables))
+	var err error
+	switch s.kind {
+	case sysbenchPointSelect:
+		err = c.query("SELECT c FROM "+table+" WHERE id = ?", c.randInt(1, s.size))
+	case sysbenchUpdateIndex:
+		_, err = c.exec("UPDATE "+table+" SET k = k + 1 WHERE id = ?", c.randInt(1, s.size))
+	case sysbenchInsert:
+		_, err = c.exec("INSERT INTO "+table+" (k, c, pad) VALUES (?, ?, ?)", c.randInt(1, s.size),
+			randString(c.rand, sysbenchCTemplate), randString(c.rand, sysbenchPadTemplate))
+	default:
+		if err = c.begin(); err != nil {
+			return "transaction", errors.Trace(err)
+		}
+		if s.kind != sysbenchWriteOnly {
+			err = s.reads(c, table)
+		}
+		if err == nil && s.kind != sysbenchReadOnly {
+			err = s.writes(c, table)
+		}
+		return "transaction", errors.Trace(err)
+	}
+	return "event", errors.Trace(err)
+}
+
+func (s *sysbench) reads(c *benchConn, table string) error {
+	for i := 0; i < sysbenchPointSelects; i++ {
+		if err := c.query("SELECT c FROM "+table+" WHERE id = ?", c.randInt(1, s.size)); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, query := range []string{
+		"SELECT c FROM " + table + " WHERE id BETWEEN ? AND ?",
+		"SELECT SUM(k) FROM " + table + " WHERE id BETWEEN ? AND ?",
+		"SELECT c FROM " + table + " WHERE id BETWEEN ? AND ? ORDER BY c",
+		"SELECT DISTINCT c FROM " + table + " WHERE id BETWEEN ? AND ? ORDER BY c",
+	} {
+		start := c.randInt(1, s.size)
+		if err := c.query(query, start, start+sysbenchRangeSize-1); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (s *sysbench) writes(c *benchConn, table string) error {
+	if _, err := c.exec("UPDATE "+table+" SET k = k + 1 WHERE id = ?", c.randInt(1, s.size)); err != nil {
+		return errors.Trace(err)
+	}
+	_, err := c.exec("UPDATE "+table+" SET c = ? WHERE id = ?", randString(c.rand, sysbenchCTemplate), c.randInt(1, s.size))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	id := c.randInt(1, s.size)
+	if _, err = c.exec("DELETE FROM "+table+" WHERE id = ?", id); err != nil {
+		return errors.Trace(err)
+	}
+	_, err = c.exec("INSERT INTO "+table+" (id, k, c, pad) VALUES (?, ?, ?, ?)", id, c.randInt(1, s.size),
+		randString(c.rand, sysbenchCTemplate), randString(c.rand, sysbenchPadTemplate))
+	return errors.Trace(err)
+}
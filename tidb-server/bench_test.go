@@ -0,0 +1,104 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/store/tikv"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testBenchSuite{})
+
+type testBenchSuite struct{}
+
+func (s *testBenchSuite) TestHistogram(c *C) {
+	var h benchHistogram
+	c.Assert(h.percentile(99), Equals, time.Duration(0))
+	var first, second benchHistogram
+	for i := 1; i <= 100; i++ {
+		lat := time.Duration(i) * time.Millisecond
+		h.add(lat)
+		if i%2 == 0 {
+			first.add(lat)
+		} else {
+			second.add(lat)
+		}
+	}
+	c.Assert(h.count, Equals, int64(100))
+	c.Assert(h.min, Equals, time.Millisecond)
+	c.Assert(h.max, Equals, 100*time.Millisecond)
+	c.Assert(h.sum, Equals, 5050*time.Millisecond)
+	// The percentiles are the upper bounds of the buckets of the nearest ranks, they're at most 2% greater.
+	for _, p := range []int{1, 50, 95, 99} {
+		lat := time.Duration(p) * time.Millisecond
+		c.Assert(h.percentile(p), GreaterEqual, lat, Commentf("p%d", p))
+		c.Assert(h.percentile(p), LessEqual, lat+lat/50, Commentf("p%d", p))
+	}
+	c.Assert(h.percentile(100), Equals, 100*time.Millisecond)
+
+	first.merge(&second)
+	c.Assert(first, DeepEquals, h)
+
+	// The latencies out of the range of the buckets are in the first and the last buckets.
+	h = benchHistogram{}
+	h.add(0)
+	h.add(time.Hour)
+	c.Assert(h.counts[0], Equals, int64(1))
+	c.Assert(h.counts[benchHistBuckets-1], Equals, int64(1))
+	c.Assert(h.percentile(50), LessEqual, 2*benchHistMin)
+	c.Assert(h.percentile(99), Equals, time.Hour)
+}
+
+func (s *testBenchSuite) TestNURand(c *C) {
+	w := newTPCC(1, 0).(*tpcc)
+	rnd := rand.New(rand.NewSource(1))
+	counts := make(map[int]int)
+	const n = 100000
+	for i := 0; i < n; i++ {
+		id := w.nuRand(rnd, 1023, w.cID, 1, w.customers)
+		c.Assert(id >= 1 && id <= w.customers, IsTrue, Commentf("id %d", id))
+		counts[id]++
+		num := w.nuRand(rnd, 255, w.cLast, 0, 999)
+		c.Assert(num >= 0 && num <= 999, IsTrue, Commentf("num %d", num))
+	}
+	// The ids are not uniform, some are selected much more often than the average.
+	var max int
+	for _, cnt := range counts {
+		if cnt > max {
+			max = cnt
+		}
+	}
+	c.Assert(max, Greater, 2*n/w.customers)
+	c.Assert(w.lastName(0), Equals, "BARBARBAR")
+	c.Assert(w.lastName(371), Equals, "PRICALLYOUGHT")
+}
+
+func (s *testBenchSuite) TestBenchMockStore(c *C) {
+	c.Assert(tidb.RegisterStore("mocktikv", tikv.MockDriver{}), IsNil)
+	*store, *storePath = "mocktikv", ""
+	args := []string{"-tables", "1", "-size", "30", "-threads", "2", "-time", "500ms", "-report-interval", "0", "-cleanup"}
+	for _, workload := range []string{"oltp_read_write", "oltp_insert", "tpcc"} {
+		c.Assert(runBench(append([]string{"-workload", workload}, args...)), Equals, 0, Commentf("workload %s", workload))
+	}
+	c.Assert(runBench([]string{"-workload", "unknown"}), Equals, 2)
+}
@@ -0,0 +1,546 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+
+	"github.com/juju/errors"
+)
+
+type tpccTxn int
+
+// The TPC-C transaction types.
+const (
+	tpccNewOrder tpccTxn = iota
+	tpccPayment
+	tpccOrderStatus
+	tpccDelivery
+	tpccStockLevel
+)
+
+var tpccTxnNames = []string{"new_order", "payment", "order_status", "delivery", "stock_level"}
+
+// tpccMix is the percentage of each transaction type in the TPC-C mix.
+var tpccMix = []int{45, 43, 4, 4, 4}
+
+const (
+	tpccDistricts = 10
+	// tpccItems is scaled down from 100000 to load fast.
+	tpccItems = 10000
+	// tpccDefaultCustomers is the number of the customers per district, it's also the number of the initial
+	// orders per district.
+	tpccDefaultCustomers = 3000
+	// tpccNewOrderRatio is the ratio of the initial orders that are new orders.
+	tpccNewOrderRatio = 0.3
+)
+
+var tpccSyllables = []string{"BAR", "OUGHT", "ABLE", "PRI", "PRES", "ESE", "ANTI", "CALLY", "ATION", "EING"}
+
+var tpccTables = []string{
+	`CREATE TABLE warehouse (
+  w_id INT NOT NULL,
+  w_name VARCHAR(10),
+  w_tax DECIMAL(4, 4),
+  w_ytd DECIMAL(12, 2),
+  PRIMARY KEY (w_id))`,
+	`CREATE TABLE district (
+  d_w_id INT NOT NULL,
+  d_id INT NOT NULL,
+  d_name VARCHAR(10),
+  d_tax DECIMAL(4, 4),
+  d_ytd DECIMAL(12, 2),
+  d_next_o_id INT,
+  PRIMARY KEY (d_w_id, d_id))`,
+	`CREATE TABLE customer (
+  c_w_id INT NOT NULL,
+  c_d_id INT NOT NULL,
+  c_id INT NOT NULL,
+  c_first VARCHAR(16),
+  c_last VARCHAR(16),
+  c_credit CHAR(2),
+  c_discount DECIMAL(4, 4),
+  c_balance DECIMAL(12, 2),
+  c_ytd_payment DECIMAL(12, 2),
+  c_payment_cnt INT,
+  c_delivery_cnt INT,
+  c_data VARCHAR(500),
+  PRIMARY KEY (c_w_id, c_d_id, c_id),
+  KEY idx_customer (c_w_id, c_d_id, c_last, c_first))`,
+	`CREATE TABLE history (
+  h_c_id INT NOT NULL,
+  h_c_d_id INT NOT NULL,
+  h_c_w_id INT NOT NULL,
+  h_d_id INT NOT NULL,
+  h_w_id INT NOT NULL,
+  h_date DATETIME,
+  h_amount DECIMAL(6, 2),
+  h_data VARCHAR(24))`,
+	`CREATE TABLE item (
+  i_id INT NOT NULL,
+  i_name VARCHAR(24),
+  i_price DECIMAL(5, 2),
+  i_data VARCHAR(50),
+  PRIMARY KEY (i_id))`,
+	`CREATE TABLE stock (
+  s_w_id INT NOT NULL,
+  s_i_id INT NOT NULL,
+  s_quantity INT,
+  s_ytd INT,
+  s_order_cnt INT,
+  s_remote_cnt INT,
+  s_data VARCHAR(50),
+  PRIMARY KEY (s_w_id, s_i_id))`,
+	`CREATE TABLE orders (
+  o_w_id INT NOT NULL,
+  o_d_id INT NOT NULL,
+  o_id INT NOT NULL,
+  o_c_id INT,
+  o_entry_d DATETIME,
+  o_carrier_id INT,
+  o_ol_cnt INT,
+  o_all_local INT,
+  PRIMARY KEY (o_w_id, o_d_id, o_id),
+  KEY idx_order (o_w_id, o_d_id, o_c_id, o_id))`,
+	`CREATE TABLE new_order (
+  no_w_id INT NOT NULL,
+  no_d_id INT NOT NULL,
+  no_o_id INT NOT NULL,
+  PRIMARY KEY (no_w_id, no_d_id, no_o_id))`,
+	`CREATE TABLE order_line (
+  ol_w_id INT NOT NULL,
+  ol_d_id INT NOT NULL,
+  ol_o_id INT NOT NULL,
+  ol_number INT NOT NULL,
+  ol_i_id INT NOT NULL,
+  ol_supply_w_id INT,
+  ol_delivery_d DATETIME,
+  ol_quantity INT,
+  ol_amount DECIMAL(6, 2),
+  ol_dist_info CHAR(24),
+  PRIMARY KEY (ol_w_id, ol_d_id, ol_o_id, ol_number))`,
+}
+
+// tpcc is a TPC-C like workload, the schema and the transactions follow the specification, but the number of the
+// items and customers is scaled down, the think time is skipped, and the new orders are never rolled back.
+type tpcc struct {
+	warehouses int
+	customers  int
+	// only is the only transaction type to run if it's not negative, otherwise the TPC-C mix is run.
+	only tpccTxn
+	// The constants of NURand for the customer last names, the customer ids and the item ids.
+	cLast, cID, iID int
+}
+
+func newTPCC(warehouses, customers int) benchWorkload {
+	if warehouses == 0 {
+		warehouses = 1
+	}
+	if customers == 0 {
+		customers = tpccDefaultCustomers
+	}
+	return &tpcc{
+		warehouses: warehouses,
+		customers:  customers,
+		only:       -1,
+		cLast:      rand.Intn(256),
+		cID:        rand.Intn(1024),
+		iID:        rand.Intn(8192),
+	}
+}
+
+func newTPCCOnly(txn tpccTxn) func(warehouses, customers int) benchWorkload {
+	return func(warehouses, customers int) benchWorkload {
+		w := newTPCC(warehouses, customers).(*tpcc)
+		w.only = txn
+		return w
+	}
+}
+
+func (w *tpcc) prepare(db *sql.DB, threads int) error {
+	for _, create := range tpccTables {
+		if _, err := db.Exec(create); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	rnd := rand.New(rand.NewSource(rand.Int63()))
+	loads := []func() error{func() error {
+		return insertBatches(db, "INSERT INTO item", tpccItems, func(i int) string {
+			return fmt.Sprintf("(%d, '%s', %.2f, '%s')", i+1, randString(rnd, "@@@@@@@@@@@@@@"),
+				1+rnd.Float64()*99, randString(rnd, "@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@"))
+		})
+	}}
+	for wID := 1; wID <= w.warehouses; wID++ {
+		wID := wID
+		loads = append(loads, func() error {
+			return w.loadWarehouse(db, wID)
+		})
+		for dID := 1; dID <= tpccDistricts; dID++ {
+			dID := dID
+			loads = append(loads, func() error {
+				return w.loadDistrict(db, wID, dID)
+			})
+		}
+	}
+	return errors.Trace(loadConcurrently(threads, loads))
+}
+
+func (w *tpcc) loadWarehouse(db *sql.DB, wID int) error {
+	rnd := rand.New(rand.NewSource(rand.Int63()))
+	_, err := db.Exec("INSERT INTO warehouse VALUES (?, ?, ?, ?)", wID, randString(rnd, "@@@@@@@@"), rnd.Float64()*0.2, 300000)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return insertBatches(db, "INSERT INTO stock", tpccItems, func(i int) string {
+		return fmt.Sprintf("(%d, %d, %d, 0, 0, 0, '%s')", wID, i+1, w.randInt(rnd, 10, 100),
+			randString(rnd, "@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@"))
+	})
+}
+
+// loadDistrict loads the district, its customers and their histories, and its orders, the last orders are new.
+func (w *tpcc) loadDistrict(db *sql.DB, wID, dID int) error {
+	rnd := rand.New(rand.NewSource(rand.Int63()))
+	_, err := db.Exec("INSERT INTO district VALUES (?, ?, ?, ?, ?, ?)", wID, dID, randString(rnd, "@@@@@@@@"),
+		rnd.Float64()*0.2, 30000, w.customers+1)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = insertBatches(db, "INSERT INTO customer", w.customers, func(i int) string {
+		credit := "GC"
+		if rnd.Intn(10) == 0 {
+			credit = "BC"
+		}
+		return fmt.Sprintf("(%d, %d, %d, '%s', '%s', '%s', %.4f, -10, 10, 1, 0, '%s')", wID, dID, i+1,
+			randString(rnd, "@@@@@@@@@@"), w.lastName(i%1000), credit, rnd.Float64()*0.5,
+			randString(rnd, "@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@"))
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = insertBatches(db, "INSERT INTO history", w.customers, func(i int) string {
+		return fmt.Sprintf("(%d, %d, %d, %d, %d, NOW(), 10, '%s')", i+1, dID, wID, dID, wID,
+			randString(rnd, "@@@@@@@@@@@@@@@@"))
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// The orders are of the customers in a random permutation.
+	customerIDs := rnd.Perm(w.customers)
+	newOrderStart := w.customers - int(float64(w.customers)*tpccNewOrderRatio) + 1
+	olCnts := make([]int, w.customers)
+	err = insertBatches(db, "INSERT INTO orders", w.customers, func(i int) string {
+		oID := i + 1
+		olCnts[i] = w.randInt(rnd, 5, 15)
+		carrier := "NULL"
+		if oID < newOrderStart {
+			carrier = fmt.Sprint(w.randInt(rnd, 1, 10))
+		}
+		return fmt.Sprintf("(%d, %d, %d, %d, NOW(), %s, %d, 1)", wID, dID, oID, customerIDs[i]+1, carrier, olCnts[i])
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = insertBatches(db, "INSERT INTO new_order", w.customers-newOrderStart+1, func(i int) string {
+		return fmt.Sprintf("(%d, %d, %d)", wID, dID, newOrderStart+i)
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var lines []string
+	for i, cnt := range olCnts {
+		oID := i + 1
+		for n := 1; n <= cnt; n++ {
+			delivery, amount := "NOW()", 0.0
+			if oID >= newOrderStart {
+				delivery, amount = "NULL", 0.01+rnd.Float64()*9999.98
+			}
+			lines = append(lines, fmt.Sprintf("(%d, %d, %d, %d, %d, %d, %s, 5, %.2f, '%s')", wID, dID, oID, n,
+				w.randInt(rnd, 1, tpccItems), wID, delivery, amount, randString(rnd, "@@@@@@@@@@@@@@@@@@@@@@@@")))
+		}
+	}
+	return insertBatches(db, "INSERT INTO order_line", len(lines), func(i int) string {
+		return lines[i]
+	})
+}
+
+func (w *tpcc) cleanup(db *sql.DB) error {
+	for _, table := range []string{"warehouse", "district", "customer", "history", "item", "stock", "orders", "new_order", "order_line"} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (w *tpcc) event(c *benchConn) (string, error) {
+	txn := w.only
+	if txn < 0 {
+		r := c.randInt(1, 100)
+		for i, pct := range tpccMix {
+			if r <= pct {
+				txn = tpccTxn(i)
+				break
+			}
+			r -= pct
+		}
+	}
+	name := tpccTxnNames[txn]
+	if err := c.begin(); err != nil {
+		return name, errors.Trace(err)
+	}
+	wID := c.randInt(1, w.warehouses)
+	var err error
+	switch txn {
+	case tpccNewOrder:
+		err = w.newOrder(c, wID)
+	case tpccPayment:
+		err = w.payment(c, wID)
+	case tpccOrderStatus:
+		err = w.orderStatus(c, wID)
+	case tpccDelivery:
+		err = w.delivery(c, wID)
+	case tpccStockLevel:
+		err = w.stockLevel(c, wID)
+	}
+	return name, errors.Trace(err)
+}
+
+func (w *tpcc) newOrder(c *benchConn, wID int) error {
+	dID := c.randInt(1, tpccDistricts)
+	cID := w.nuRand(c.rand, 1023, w.cID, 1, w.customers)
+	olCnt := c.randInt(5, 15)
+	var wTax, dTax, cDiscount float64
+	var oID int
+	var cLast, cCredit string
+	if err := c.queryRow("SELECT w_tax FROM warehouse WHERE w_id = ?", []interface{}{wID}, &wTax); err != nil {
+		return errors.Trace(err)
+	}
+	err := c.queryRow("SELECT d_tax, d_next_o_id FROM district WHERE d_w_id = ? AND d_id = ? FOR UPDATE",
+		[]interface{}{wID, dID}, &dTax, &oID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err = c.exec("UPDATE district SET d_next_o_id = ? WHERE d_w_id = ? AND d_id = ?", oID+1, wID, dID); err != nil {
+		return errors.Trace(err)
+	}
+	err = c.queryRow("SELECT c_discount, c_last, c_credit FROM customer WHERE c_w_id = ? AND c_d_id = ? AND c_id = ?",
+		[]interface{}{wID, dID, cID}, &cDiscount, &cLast, &cCredit)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// An item is supplied by a remote warehouse in 1% of the order lines.
+	supplyWIDs := make([]int, olCnt)
+	allLocal := 1
+	for i := range supplyWIDs {
+		supplyWIDs[i] = wID
+		if w.warehouses > 1 && c.randInt(1, 100) == 1 {
+			for supplyWIDs[i] == wID {
+				supplyWIDs[i] = c.randInt(1, w.warehouses)
+			}
+			allLocal = 0
+		}
+	}
+	_, err = c.exec("INSERT INTO orders VALUES (?, ?, ?, ?, NOW(), NULL, ?, ?)", wID, dID, oID, cID, olCnt, allLocal)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err = c.exec("INSERT INTO new_order VALUES (?, ?, ?)", wID, dID, oID); err != nil {
+		return errors.Trace(err)
+	}
+	for n := 1; n <= olCnt; n++ {
+		iID := w.nuRand(c.rand, 8191, w.iID, 1, tpccItems)
+		supplyWID := supplyWIDs[n-1]
+		quantity := c.randInt(1, 10)
+		var price float64
+		var iName, iData, sData string
+		var sQuantity int
+		err = c.queryRow("SELECT i_price, i_name, i_data FROM item WHERE i_id = ?", []interface{}{iID}, &price, &iName, &iData)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		err = c.queryRow("SELECT s_quantity, s_data FROM stock WHERE s_w_id = ? AND s_i_id = ? FOR UPDATE",
+			[]interface{}{supplyWID, iID}, &sQuantity, &sData)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if sQuantity >= quantity+10 {
+			sQuantity -= quantity
+		} else {
+			sQuantity += 91 - quantity
+		}
+		remote := 0
+		if supplyWID != wID {
+			remote = 1
+		}
+		_, err = c.exec("UPDATE stock SET s_quantity = ?, s_ytd = s_ytd + ?, s_order_cnt = s_order_cnt + 1, "+
+			"s_remote_cnt = s_remote_cnt + ? WHERE s_w_id = ? AND s_i_id = ?", sQuantity, quantity, remote, supplyWID, iID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		amount := float64(quantity) * price * (1 + wTax + dTax) * (1 - cDiscount)
+		_, err = c.exec("INSERT INTO order_line VALUES (?, ?, ?, ?, ?, ?, NULL, ?, ?, ?)", wID, dID, oID, n, iID,
+			supplyWID, quantity, fmt.Sprintf("%.2f", amount), randString(c.rand, "@@@@@@@@@@@@@@@@@@@@@@@@"))
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (w *tpcc) payment(c *benchConn, wID int) error {
+	dID := c.randInt(1, tpccDistricts)
+	amount := fmt.Sprintf("%.2f", 1+c.rand.Float64()*4999)
+	if _, err := c.exec("UPDATE warehouse SET w_ytd = w_ytd + CAST(? AS DECIMAL(12, 2)) WHERE w_id = ?", amount, wID); err != nil {
+		return errors.Trace(err)
+	}
+	_, err := c.exec("UPDATE district SET d_ytd = d_ytd + CAST(? AS DECIMAL(12, 2)) WHERE d_w_id = ? AND d_id = ?", amount, wID, dID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cID, err := w.selectCustomer(c, wID, dID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var balance float64
+	var credit string
+	err = c.queryRow("SELECT c_balance, c_credit FROM customer WHERE c_w_id = ? AND c_d_id = ? AND c_id = ? FOR UPDATE",
+		[]interface{}{wID, dID, cID}, &balance, &credit)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = c.exec("UPDATE customer SET c_balance = c_balance - CAST(? AS DECIMAL(12, 2)), "+
+		"c_ytd_payment = c_ytd_payment + CAST(? AS DECIMAL(12, 2)), "+
+		"c_payment_cnt = c_payment_cnt + 1 WHERE c_w_id = ? AND c_d_id = ? AND c_id = ?", amount, amount, wID, dID, cID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = c.exec("INSERT INTO history VALUES (?, ?, ?, ?, ?, NOW(), ?, ?)", cID, dID, wID, dID, wID, amount,
+		randString(c.rand, "@@@@@@@@@@@@@@@@"))
+	return errors.Trace(err)
+}
+
+func (w *tpcc) orderStatus(c *benchConn, wID int) error {
+	dID := c.randInt(1, tpccDistricts)
+	cID, err := w.selectCustomer(c, wID, dID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var oID int
+	err = c.queryRow("SELECT o_id FROM orders WHERE o_w_id = ? AND o_d_id = ? AND o_c_id = ? ORDER BY o_id DESC LIMIT 1",
+		[]interface{}{wID, dID, cID}, &oID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	err = c.query("SELECT ol_i_id, ol_supply_w_id, ol_quantity, ol_amount, ol_delivery_d FROM order_line "+
+		"WHERE ol_w_id = ? AND ol_d_id = ? AND ol_o_id = ?", wID, dID, oID)
+	return errors.Trace(err)
+}
+
+// delivery delivers the oldest new order of each district.
+func (w *tpcc) delivery(c *benchConn, wID int) error {
+	carrier := c.randInt(1, 10)
+	for dID := 1; dID <= tpccDistricts; dID++ {
+		var oID, cID int
+		err := c.queryRow("SELECT no_o_id FROM new_order WHERE no_w_id = ? AND no_d_id = ? ORDER BY no_o_id LIMIT 1 FOR UPDATE",
+			[]interface{}{wID, dID}, &oID)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if _, err = c.exec("DELETE FROM new_order WHERE no_w_id = ? AND no_d_id = ? AND no_o_id = ?", wID, dID, oID); err != nil {
+			return errors.Trace(err)
+		}
+		err = c.queryRow("SELECT o_c_id FROM orders WHERE o_w_id = ? AND o_d_id = ? AND o_id = ?",
+			[]interface{}{wID, dID, oID}, &cID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = c.exec("UPDATE orders SET o_carrier_id = ? WHERE o_w_id = ? AND o_d_id = ? AND o_id = ?", carrier, wID, dID, oID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = c.exec("UPDATE order_line SET ol_delivery_d = NOW() WHERE ol_w_id = ? AND ol_d_id = ? AND ol_o_id = ?", wID, dID, oID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var amount sql.NullFloat64
+		err = c.queryRow("SELECT SUM(ol_amount) FROM order_line WHERE ol_w_id = ? AND ol_d_id = ? AND ol_o_id = ?",
+			[]interface{}{wID, dID, oID}, &amount)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		_, err = c.exec("UPDATE customer SET c_balance = c_balance + CAST(? AS DECIMAL(12, 2)), c_delivery_cnt = c_delivery_cnt + 1 "+
+			"WHERE c_w_id = ? AND c_d_id = ? AND c_id = ?", fmt.Sprintf("%.2f", amount.Float64), wID, dID, cID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// stockLevel counts the recently sold items whose stock is below the threshold.
+func (w *tpcc) stockLevel(c *benchConn, wID int) error {
+	dID := c.randInt(1, tpccDistricts)
+	var nextOID int
+	err := c.queryRow("SELECT d_next_o_id FROM district WHERE d_w_id = ? AND d_id = ?", []interface{}{wID, dID}, &nextOID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = c.query("SELECT COUNT(DISTINCT s_i_id) FROM order_line, stock WHERE ol_w_id = ? AND ol_d_id = ? "+
+		"AND ol_o_id >= ? AND ol_o_id < ? AND s_w_id = ? AND s_i_id = ol_i_id AND s_quantity < ?",
+		wID, dID, nextOID-20, nextOID, wID, c.randInt(10, 20))
+	return errors.Trace(err)
+}
+
+// selectCustomer selects a customer by the last name in 60% of the cases, otherwise by the id.
+func (w *tpcc) selectCustomer(c *benchConn, wID, dID int) (int, error) {
+	if c.randInt(1, 100) > 60 {
+		return w.nuRand(c.rand, 1023, w.cID, 1, w.customers), nil
+	}
+	maxName := 999
+	if w.customers <= maxName {
+		maxName = w.customers - 1
+	}
+	last := w.lastName(w.nuRand(c.rand, 255, w.cLast, 0, maxName))
+	ids, err := c.queryInts("SELECT c_id FROM customer WHERE c_w_id = ? AND c_d_id = ? AND c_last = ? ORDER BY c_first",
+		wID, dID, last)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(ids) == 0 {
+		return 0, errors.Errorf("no customer named %s in district %d of warehouse %d", last, dID, wID)
+	}
+	// The customer in the middle of the ones with the same last name is selected.
+	return ids[(len(ids)-1)/2], nil
+}
+
+func (w *tpcc) lastName(num int) string {
+	return tpccSyllables[num/100] + tpccSyllables[num/10%10] + tpccSyllables[num%10]
+}
+
+// nuRand is the non-uniform random function of TPC-C.
+func (w *tpcc) nuRand(rnd *rand.Rand, a, c, x, y int) int {
+	return ((rnd.Intn(a+1)|(x+rnd.Intn(y-x+1)))+c)%(y-x+1) + x
+}
+
+func (w *tpcc) randInt(rnd *rand.Rand, min, max int) int {
+	return min + rnd.Intn(max-min+1)
+}
@@ -16,6 +16,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"os/signal"
@@ -34,9 +35,15 @@ import (
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/privilege/privileges"
 	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/server/rawkv"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
-	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/table/engine"
+	"github.com/pingcap/tidb/table/engine/elasticsearch"
+	"github.com/pingcap/tidb/table/engine/federated"
+	"github.com/pingcap/tidb/table/engine/file"
+	"github.com/pingcap/tidb/table/engine/kafka"
+	tidblogutil "github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/util/systimemon"
 	"github.com/pingcap/tidb/x-server"
@@ -51,11 +58,14 @@ var (
 	store           = flag.String("store", "goleveldb", "registered store name, [memory, goleveldb, boltdb, tikv, mocktikv]")
 	storePath       = flag.String("path", "/tmp/tidb", "tidb storage path")
 	logLevel        = flag.String("L", "info", "log level: info, debug, warn, error, fatal")
+	logFormat       = flag.String("log-format", "text", "log format: text, json, console")
+	moduleLogLevel  = flag.String("module-log-level", "", "comma separated log levels of the modules, e.g. ddl=debug,server=warn")
 	host            = flag.String("host", "0.0.0.0", "tidb server host")
 	port            = flag.String("P", "4000", "tidb server port")
 	xhost           = flag.String("xhost", "0.0.0.0", "tidb x protocol server host")
 	xport           = flag.String("xP", "14000", "tidb x protocol server port")
 	statusPort      = flag.String("status", "10080", "tidb server status port")
+	advertiseAddr   = flag.String("advertise-address", "", "the host registered for the other tidb servers to reach this server, the hostname is used if it's empty and -host is 0.0.0.0")
 	ddlLease        = flag.String("lease", "10s", "schema lease duration, very dangerous to change only if you know what you do")
 	statsLease      = flag.String("statsLease", "3s", "stats lease duration, which inflences the time of analyze and stats load.")
 	socket          = flag.String("socket", "", "The socket file to use for connection.")
@@ -79,6 +89,12 @@ var (
 	sslCAPath       = flag.String("ssl-ca", "", "Path of file that contains list of trusted SSL CAs")
 	sslCertPath     = flag.String("ssl-cert", "", "Path of file that contains X509 certificate in PEM format")
 	sslKeyPath      = flag.String("ssl-key", "", "Path of file that contains X509 key in PEM format")
+	rawKVAddr       = flag.String("rawkv-addr", "", "address of the raw kv gRPC service, leaves it empty will disable the service.")
+	sqlRPCAddr      = flag.String("sql-rpc-addr", "", "address of the SQL gRPC service, leaves it empty will disable the service.")
+	sqlRPCInsecure  = flagBoolean("sql-rpc-insecure", false, "allow the SQL gRPC service without TLS, the passwords are sent in plaintext.")
+	httpSQL         = flagBoolean("http-sql", false, "enable the /sql endpoint on the status port to run SQL over HTTP with the basic authentication")
+	gracefulWait    = flag.Int("graceful-wait-before-shutdown", 0, "seconds to wait with the readiness check failing before the server is closed on exit")
+	configPath      = flag.String("config", "", "path of the JSON config file, its options override the command line options, and it is reloaded on SIGHUP")
 
 	timeJumpBackCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -90,9 +106,12 @@ var (
 )
 
 func main() {
-	tidb.RegisterLocalStore("boltdb", boltdb.Driver{})
 	tidb.RegisterStore("tikv", tikv.Driver{})
 	tidb.RegisterStore("mocktikv", tikv.MockDriver{})
+	engine.Register(elasticsearch.Engine{})
+	engine.Register(kafka.Engine{})
+	engine.Register(file.Engine{})
+	engine.Register(federated.Engine{})
 
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
@@ -101,6 +120,15 @@ func main() {
 		printer.PrintRawTiDBInfo()
 		os.Exit(0)
 	}
+	if flag.NArg() > 0 && flag.Arg(0) == "check-syntax" {
+		os.Exit(checkSyntax(flag.Args()[1:]))
+	}
+	if flag.NArg() > 0 && flag.Arg(0) == "bench" {
+		os.Exit(runBench(flag.Args()[1:]))
+	}
+	if flag.NArg() > 0 && flag.Arg(0) == "upgrade-collation" {
+		os.Exit(upgradeCollation())
+	}
 	if *skipGrantTable && !hasRootPrivilege() {
 		log.Error("TiDB run with skip-grant-table need root privilege.")
 		os.Exit(-1)
@@ -116,7 +144,10 @@ func main() {
 	cfg := config.GetGlobalConfig()
 	cfg.Addr = fmt.Sprintf("%s:%s", *host, *port)
 	cfg.LogLevel = *logLevel
+	cfg.LogFormat = *logFormat
+	cfg.ModuleLogLevel = *moduleLogLevel
 	cfg.StatusAddr = fmt.Sprintf(":%s", *statusPort)
+	cfg.AdvertiseAddress = *advertiseAddr
 	cfg.Socket = *socket
 	cfg.ReportStatus = *reportStatus
 	cfg.Store = *store
@@ -127,6 +158,13 @@ func main() {
 	cfg.SSLCAPath = *sslCAPath
 	cfg.SSLCertPath = *sslCertPath
 	cfg.SSLKeyPath = *sslKeyPath
+	cfg.HTTPSQL = *httpSQL
+	cfg.GracefulWaitBeforeShutdown = *gracefulWait
+	if *configPath != "" {
+		if err := cfg.Load(*configPath); err != nil {
+			log.Fatal(errors.ErrorStack(err))
+		}
+	}
 
 	xcfg := &xserver.Config{
 		Addr:     fmt.Sprintf("%s:%s", *xhost, *xport),
@@ -136,7 +174,8 @@ func main() {
 
 	// set log options
 	logConf := &logutil.LogConfig{
-		Level: *logLevel,
+		Level:  cfg.LogLevel,
+		Format: cfg.LogFormat,
 	}
 	if len(*logFile) > 0 {
 		logConf.File = logutil.FileLogConfig{
@@ -148,6 +187,9 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err = tidblogutil.SetModuleLevels(cfg.ModuleLogLevel); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
 
 	// Make sure the TiDB info is always printed.
 	level := log.GetLevel()
@@ -184,6 +226,8 @@ func main() {
 	if err != nil {
 		log.Fatal(errors.ErrorStack(err))
 	}
+	config.RegisterReloadHook(reloadLogLevel)
+	config.RegisterReloadHook(svr.ReloadTLSCertificates)
 	var xsvr *xserver.Server
 	if *startXServer {
 		xsvr, err = xserver.NewServer(xcfg)
@@ -192,6 +236,37 @@ func main() {
 		}
 	}
 
+	var rawKVSvr *rawkv.Server
+	if *rawKVAddr != "" {
+		l, err := net.Listen("tcp", *rawKVAddr)
+		if err != nil {
+			log.Fatal(errors.ErrorStack(err))
+		}
+		rawKVSvr = rawkv.NewServer(store)
+		go func() {
+			if err := rawKVSvr.Serve(l); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+
+	var sqlRPCSvr *server.SQLRPCServer
+	if *sqlRPCAddr != "" {
+		l, err := net.Listen("tcp", *sqlRPCAddr)
+		if err != nil {
+			log.Fatal(errors.ErrorStack(err))
+		}
+		sqlRPCSvr, err = server.NewSQLRPCServer(svr, *sqlRPCInsecure)
+		if err != nil {
+			log.Fatal(errors.ErrorStack(err))
+		}
+		go func() {
+			if err := sqlRPCSvr.Serve(l); err != nil {
+				log.Error(err)
+			}
+		}()
+	}
+
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
 		syscall.SIGHUP,
@@ -201,10 +276,30 @@ func main() {
 
 	go func() {
 		sig := <-sc
+		for sig == syscall.SIGHUP {
+			log.Infof("Got signal [%d] to reload the config.", sig)
+			if err := config.Reload(); err != nil {
+				log.Errorf("reload config failed: %v", errors.ErrorStack(err))
+			} else {
+				log.Info("config reloaded")
+			}
+			sig = <-sc
+		}
 		log.Infof("Got signal [%d] to exit.", sig)
+		svr.Drain()
+		if wait := config.GetGlobalConfig().GracefulWaitBeforeShutdown; wait > 0 {
+			log.Infof("Wait %d seconds for the load balancers before closing the server.", wait)
+			time.Sleep(time.Duration(wait) * time.Second)
+		}
 		if *startXServer {
 			xsvr.Close() // Should close xserver before server.
 		}
+		if rawKVSvr != nil {
+			rawKVSvr.Close()
+		}
+		if sqlRPCSvr != nil {
+			sqlRPCSvr.Close()
+		}
 		svr.Close()
 	}()
 
@@ -287,6 +382,46 @@ func instanceName() string {
 	return fmt.Sprintf("%s_%s", hostname, *port)
 }
 
+// checkSyntax parses and validates the SQL files, or the standard input if there is no file, and prints the
+// errors to the standard error. It returns the exit code, 1 if any file has an error.
+func checkSyntax(files []string) int {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	code := 0
+	for _, file := range files {
+		var (
+			data []byte
+			err  error
+		)
+		if file == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(file)
+		}
+		if err == nil {
+			_, err = tidb.ParseAndValidate(string(data))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// upgradeCollation upgrades the store of -store and -path to the new collation. The TiDB servers using the store
+// must be stopped before it runs. It returns the exit code, 1 if the store can't be upgraded.
+func upgradeCollation() int {
+	store := createStore()
+	defer store.Close()
+	if err := tidb.UpgradeToNewCollation(store); err != nil {
+		fmt.Fprintf(os.Stderr, "upgrade-collation: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
 // parseLease parses lease argument string.
 func parseLease(lease string) time.Duration {
 	dur, err := time.ParseDuration(lease)
@@ -311,3 +446,20 @@ func flagBoolean(name string, defaultVal bool, usage string) *bool {
 	}
 	return flag.Bool(name, defaultVal, usage)
 }
+
+func reloadLogLevel(oldCfg, newCfg *config.Config) error {
+	if newCfg.ModuleLogLevel != oldCfg.ModuleLogLevel {
+		if err := tidblogutil.SetModuleLevels(newCfg.ModuleLogLevel); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if newCfg.LogLevel == oldCfg.LogLevel {
+		return nil
+	}
+	level, err := log.ParseLevel(newCfg.LogLevel)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	log.SetLevel(level)
+	return nil
+}
@@ -19,6 +19,7 @@ package tidb
 
 import (
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -31,10 +32,14 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/localstore/engine"
 	"github.com/pingcap/tidb/store/localstore/goleveldb"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -126,8 +131,10 @@ func SetStatsLease(lease time.Duration) {
 // Retryable errors are generally refer to temporary errors that are expected to be
 // reinstated by retry, including network interruption, transaction conflicts, and
 // so on.
+// It is the default value of the tidb_retry_limit variable of the new sessions.
 func SetCommitRetryLimit(limit int) {
 	commitRetryLimit = limit
+	variable.SysVars[variable.TiDBRetryLimit].Value = strconv.Itoa(limit)
 }
 
 // Parse parses a query string to raw ast.StmtNode.
@@ -144,6 +151,26 @@ func Parse(ctx context.Context, src string) ([]ast.StmtNode, error) {
 	return stmts, nil
 }
 
+// ParseAndValidate parses the statements and validates them like a new session in the default SQL mode,
+// without a store, so it can check the syntax of the SQL files, e.g. the migration files in the CI pipelines.
+// The error of a statement that fails the validation is annotated with the statement text.
+func ParseAndValidate(sql string) ([]ast.StmtNode, error) {
+	ctx := mock.NewContext()
+	charset, collation := ctx.GetSessionVars().GetCharsetInfo()
+	p := parser.New()
+	p.SetSQLMode(ctx.GetSessionVars().SQLMode)
+	stmts, err := p.Parse(sql, charset, collation)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, stmt := range stmts {
+		if err = plan.Validate(stmt, false, ctx); err != nil {
+			return nil, errors.Annotatef(err, "statement %q", stmt.Text())
+		}
+	}
+	return stmts, nil
+}
+
 // Compile is safe for concurrent use by multiple goroutines.
 func Compile(ctx context.Context, rawStmt ast.StmtNode) (ast.Statement, error) {
 	compiler := executor.Compiler{}
@@ -159,6 +186,9 @@ func runStmt(ctx context.Context, s ast.Statement) (ast.RecordSet, error) {
 	var err error
 	var rs ast.RecordSet
 	se := ctx.(*session)
+	if err = se.refreshReadTS(); err != nil {
+		return nil, errors.Trace(err)
+	}
 	rs, err = s.Exec(ctx)
 	// All the history should be added here.
 	getHistory(ctx).add(0, s, se.sessionVars.StmtCtx)
@@ -169,6 +199,8 @@ func runStmt(ctx context.Context, s ast.Statement) (ast.RecordSet, error) {
 		} else {
 			err = se.CommitTxn()
 		}
+	} else if err == nil && rs == nil {
+		se.prewriteAhead()
 	}
 	return rs, errors.Trace(err)
 }
@@ -235,6 +267,12 @@ func NewStore(path string) (kv.Storage, error) {
 	return newStoreWithRetry(path, defaultMaxRetries)
 }
 
+// GetDomain returns the domain of the store, the domain is created if the store doesn't have one yet.
+func GetDomain(store kv.Storage) (*domain.Domain, error) {
+	dom, err := domap.Get(store)
+	return dom, errors.Trace(err)
+}
+
 func newStoreWithRetry(path string, maxRetries int) (kv.Storage, error) {
 	url, err := url.Parse(path)
 	if err != nil {
@@ -288,7 +326,10 @@ func IsQuery(sql string) bool {
 }
 
 func init() {
-	// Register default memory and goleveldb storage
+	// Register default memory, goleveldb and boltdb storage
 	RegisterLocalStore("memory", goleveldb.MemoryDriver{})
 	RegisterLocalStore("goleveldb", goleveldb.Driver{})
+	RegisterLocalStore("boltdb", boltdb.Driver{})
+
+	variable.RegisterStatistics(retryStats{})
 }
@@ -29,9 +29,12 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/auth"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -229,6 +232,21 @@ func (s *testMainSuite) TestIsQuery(c *C) {
 	}
 }
 
+func (s *testMainSuite) TestParseAndValidate(c *C) {
+	defer testleak.AfterTest(c)()
+	stmts, err := ParseAndValidate("create table t (a int primary key, b varchar(10)); insert into t values (1, 'a');")
+	c.Assert(err, IsNil)
+	c.Assert(stmts, HasLen, 2)
+
+	_, err = ParseAndValidate("create table t (a int, b int unknown)")
+	c.Assert(terror.ErrorEqual(err, parser.ErrParse), IsTrue)
+	_, err = ParseAndValidate("create table t (a int primary key, b int primary key)")
+	c.Assert(terror.ErrorEqual(err, infoschema.ErrMultiplePriKey), IsTrue)
+	c.Assert(err.Error(), Matches, "statement \"create table t .*\": .*")
+	_, err = ParseAndValidate("select ?")
+	c.Assert(terror.ErrorEqual(err, parser.ErrSyntax), IsTrue)
+}
+
 func (s *testMainSuite) TestTrimSQL(c *C) {
 	tbl := []struct {
 		sql    string
@@ -62,6 +62,24 @@ func CheckScrambledPassword(salt, hpwd, auth []byte) bool {
 	return bytes.Equal(hpwd, Sha1Hash(hash))
 }
 
+// ScramblePassword computes the reply of the client with the plaintext password pwd to the salt, it's checked by
+// CheckScrambledPassword. It's used to authenticate the clients which send the plaintext passwords, like the HTTP
+// clients.
+func ScramblePassword(salt []byte, pwd string) []byte {
+	if len(pwd) == 0 {
+		return nil
+	}
+	stage1 := Sha1Hash([]byte(pwd))
+	crypt := sha1.New()
+	crypt.Write(salt)
+	crypt.Write(Sha1Hash(stage1))
+	reply := crypt.Sum(nil)
+	for i := range reply {
+		reply[i] ^= stage1[i]
+	}
+	return reply
+}
+
 // Sha1Hash is an util function to calculate sha1 hash.
 func Sha1Hash(bs []byte) []byte {
 	crypt := sha1.New()
@@ -14,10 +14,16 @@
 package auth
 
 import (
+	"testing"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/util/testleak"
 )
 
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
 var _ = Suite(&testAuthSuite{})
 
 type testAuthSuite struct {
@@ -48,3 +54,11 @@ func (s *testAuthSuite) TestCheckScramble(c *C) {
 	res := CheckScrambledPassword(salt, hpwd, auth)
 	c.Assert(res, IsTrue)
 }
+
+func (s *testAuthSuite) TestScramblePassword(c *C) {
+	defer testleak.AfterTest(c)()
+	salt := []byte{85, 92, 45, 22, 58, 79, 107, 6, 122, 125, 58, 80, 12, 90, 103, 32, 90, 10, 74, 82}
+	c.Assert(ScramblePassword(salt, "abc"), DeepEquals,
+		[]byte{24, 180, 183, 225, 166, 6, 81, 102, 70, 248, 199, 143, 91, 204, 169, 9, 161, 171, 203, 33})
+	c.Assert(ScramblePassword(salt, ""), IsNil)
+}
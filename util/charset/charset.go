@@ -165,6 +165,16 @@ func GetCharsetInfoByID(coID int) (string, string, error) {
 	return "", "", errors.Errorf("Unknown charset id %d", coID)
 }
 
+// GetCollationByName returns the collation of the name.
+func GetCollationByName(name string) (*Collation, error) {
+	for _, collation := range collations {
+		if strings.EqualFold(name, collation.Name) {
+			return collation, nil
+		}
+	}
+	return nil, errors.Errorf("Unknown collation %s", name)
+}
+
 // GetCollations returns a list for all collations.
 func GetCollations() []*Collation {
 	return collations
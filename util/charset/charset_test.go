@@ -92,3 +92,47 @@ func (s *testCharsetSuite) TestGetDefaultCollation(c *C) {
 		testGetDefaultCollation(c, tt.cs, tt.co, tt.succ)
 	}
 }
+
+func (s *testCharsetSuite) TestConvert(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(NeedConvert("latin1"), IsTrue)
+	c.Assert(NeedConvert("ASCII"), IsTrue)
+	c.Assert(NeedConvert("utf8"), IsFalse)
+	c.Assert(NeedConvert("binary"), IsFalse)
+
+	tests := []struct {
+		cs      string
+		utf8    string
+		encoded string
+		ok      bool
+	}{
+		{"latin1", "café", "caf\xe9", true},
+		{"latin1", "€", "\x80", true},
+		{"latin1", "\u0081", "\x81", true},
+		{"latin1", "中é", "?\xe9", false},
+		{"ascii", "abc", "abc", true},
+		{"ascii", "café", "caf?", false},
+		{"utf8", "中é", "中é", true},
+	}
+	for _, tt := range tests {
+		b, ok := EncodeString(tt.cs, []byte(tt.utf8))
+		c.Assert(string(b), Equals, tt.encoded, Commentf("%s %s", tt.cs, tt.utf8))
+		c.Assert(ok, Equals, tt.ok)
+		if tt.ok {
+			str, ok := DecodeString(tt.cs, b)
+			c.Assert(str, Equals, tt.utf8)
+			c.Assert(ok, IsTrue)
+		}
+	}
+	// The bytes that are invalid in ascii are replaced.
+	str, ok := DecodeString("ascii", []byte("caf\xe9"))
+	c.Assert(str, Equals, "caf?")
+	c.Assert(ok, IsFalse)
+	// Every byte is valid in latin1.
+	str, ok = DecodeString("latin1", []byte{0x81, 0xff})
+	c.Assert(str, Equals, "\u0081ÿ")
+	c.Assert(ok, IsTrue)
+	c.Assert(IsValid("latin1", []byte{0x81, 0xff}), IsTrue)
+	c.Assert(IsValid("ascii", []byte("caf\xe9")), IsFalse)
+	c.Assert(IsValid("utf8", []byte("caf\xe9")), IsTrue)
+}
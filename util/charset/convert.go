@@ -0,0 +1,121 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charset
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// singleByteCharset converts between a single byte charset and UTF-8.
+type singleByteCharset struct {
+	decode func(b byte) (rune, bool)
+	encode func(r rune) (byte, bool)
+}
+
+// convertCharsets are the charsets whose strings are converted, the strings of the other supported charsets are
+// stored as UTF-8 or as is.
+var convertCharsets = map[string]singleByteCharset{
+	// MySQL's latin1 is cp1252 with the undefined bytes mapped to the control characters of the same value.
+	CharsetLatin1: {
+		decode: func(b byte) (rune, bool) {
+			if r := charmap.Windows1252.DecodeByte(b); r != utf8.RuneError {
+				return r, true
+			}
+			return rune(b), true
+		},
+		encode: func(r rune) (byte, bool) {
+			if b, ok := charmap.Windows1252.EncodeRune(r); ok {
+				return b, true
+			}
+			if r < 0x100 && charmap.Windows1252.DecodeByte(byte(r)) == utf8.RuneError {
+				return byte(r), true
+			}
+			return 0, false
+		},
+	},
+	CharsetASCII: {
+		decode: func(b byte) (rune, bool) {
+			return rune(b), b < utf8.RuneSelf
+		},
+		encode: func(r rune) (byte, bool) {
+			return byte(r), r < utf8.RuneSelf
+		},
+	},
+}
+
+// NeedConvert returns true if the strings in the charset cs are converted from or to UTF-8.
+func NeedConvert(cs string) bool {
+	_, ok := convertCharsets[strings.ToLower(cs)]
+	return ok
+}
+
+// IsValid returns true if b is valid in the charset cs, the charsets which aren't converted aren't checked.
+func IsValid(cs string, b []byte) bool {
+	c, needConvert := convertCharsets[strings.ToLower(cs)]
+	if !needConvert {
+		return true
+	}
+	for _, ch := range b {
+		if _, valid := c.decode(ch); !valid {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeString converts the UTF-8 string s to the charset cs. Like MySQL, the characters that can't be represented
+// in cs are replaced by '?', and ok is false if any is replaced. s is returned as is if cs isn't converted.
+func EncodeString(cs string, s []byte) (b []byte, ok bool) {
+	c, needConvert := convertCharsets[strings.ToLower(cs)]
+	if !needConvert {
+		return s, true
+	}
+	ok = true
+	b = make([]byte, 0, len(s))
+	for len(s) > 0 {
+		r, size := utf8.DecodeRune(s)
+		s = s[size:]
+		ch, valid := c.encode(r)
+		if r == utf8.RuneError && size == 1 {
+			valid = false
+		}
+		if !valid {
+			ch, ok = '?', false
+		}
+		b = append(b, ch)
+	}
+	return b, ok
+}
+
+// DecodeString converts b in the charset cs to a UTF-8 string. The bytes that are invalid in cs are replaced by '?',
+// and ok is false if any is replaced. b is returned as is if cs isn't converted.
+func DecodeString(cs string, b []byte) (s string, ok bool) {
+	c, needConvert := convertCharsets[strings.ToLower(cs)]
+	if !needConvert {
+		return string(b), true
+	}
+	ok = true
+	buf := make([]byte, 0, len(b))
+	for _, ch := range b {
+		r, valid := c.decode(ch)
+		if !valid {
+			r, ok = '?', false
+		}
+		buf = append(buf, string(r)...)
+	}
+	return string(buf), ok
+}
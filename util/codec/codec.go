@@ -36,6 +36,7 @@ const (
 	varintFlag       byte = 8
 	uvarintFlag      byte = 9
 	jsonFlag         byte = 10
+	descFlag         byte = 11
 	maxFlag          byte = 250
 )
 
@@ -158,6 +159,23 @@ func EncodeKey(b []byte, v ...types.Datum) ([]byte, error) {
 	return encode(b, v, true, false)
 }
 
+// EncodeKeyDesc appends the encoded values to byte slice b like EncodeKey, but the encoded
+// values are in descending order for comparison. Each value is the descFlag followed by the
+// bitwise reversed bytes of EncodeKey, so it's decoded by Decode and cut by CutOne as well.
+func EncodeKeyDesc(b []byte, v ...types.Datum) ([]byte, error) {
+	for i := range v {
+		b = append(b, descFlag)
+		n := len(b)
+		var err error
+		b, err = encode(b, v[i:i+1], true, false)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		reverseBytes(b[n:])
+	}
+	return b, nil
+}
+
 // EncodeValue appends the encoded values to byte slice b, returning the appended
 // slice. It does not guarantee the order for comparison.
 func EncodeValue(b []byte, v ...types.Datum) ([]byte, error) {
@@ -248,6 +266,8 @@ func DecodeOne(b []byte) (remain []byte, d types.Datum, err error) {
 		if err == nil {
 			d.SetMysqlJSON(j)
 		}
+	case descFlag:
+		b, d, err = decodeDesc(b)
 	case NilFlag:
 	default:
 		return b, d, errors.Errorf("invalid encoded key flag %v", flag)
@@ -307,6 +327,8 @@ func peek(b []byte) (length int, err error) {
 		l, err = peekUvarint(b)
 	case jsonFlag:
 		l, err = json.PeekBytesAsJSON(b)
+	case descFlag:
+		l, err = peekDesc(b)
 	default:
 		return 0, errors.Errorf("invalid encoded key flag %v", flag)
 	}
@@ -317,6 +339,88 @@ func peek(b []byte) (length int, err error) {
 	return
 }
 
+// peekDesc peeks the bitwise reversed value encoded by EncodeKeyDesc, b is the bytes after the descFlag. The values
+// are encoded by EncodeKey, so only the flags of the key values are peeked, and they are peeked in place.
+func peekDesc(b []byte) (int, error) {
+	if len(b) < 1 {
+		return 0, errors.New("invalid encoded key")
+	}
+	flag := ^b[0]
+	var (
+		l   int
+		err error
+	)
+	switch flag {
+	case NilFlag:
+	case intFlag, uintFlag, floatFlag, durationFlag:
+		l = 8
+	case bytesFlag:
+		// The reversed marker of a group is its pad count.
+		l, err = peekBytes(b[1:], true)
+	case decimalFlag:
+		// The length of a decimal is determined by its precision and frac.
+		if len(b) < 4 {
+			return 0, errors.New("insufficient bytes to decode value")
+		}
+		l, err = types.DecimalPeak([]byte{^b[1], ^b[2], ^b[3]})
+	default:
+		return 0, errors.Errorf("invalid encoded key flag %v", flag)
+	}
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(b) < 1+l {
+		return 0, errors.New("insufficient bytes to decode value")
+	}
+	return 1 + l, nil
+}
+
+// decodeDesc decodes the bitwise reversed value encoded by EncodeKeyDesc, b is the bytes after the descFlag. Only
+// a decimal is copied to decode, the other values are decoded in place.
+func decodeDesc(b []byte) ([]byte, types.Datum, error) {
+	var d types.Datum
+	l, err := peekDesc(b)
+	if err != nil {
+		return b, d, errors.Trace(err)
+	}
+	flag := ^b[0]
+	data, remain := b[1:l], b[l:]
+	switch flag {
+	case intFlag:
+		var v int64
+		_, v, err = DecodeIntDesc(data)
+		d.SetInt64(v)
+	case uintFlag:
+		var v uint64
+		_, v, err = DecodeUintDesc(data)
+		d.SetUint64(v)
+	case floatFlag:
+		var v float64
+		_, v, err = DecodeFloatDesc(data)
+		d.SetFloat64(v)
+	case durationFlag:
+		var r int64
+		_, r, err = DecodeIntDesc(data)
+		if err == nil {
+			d.SetValue(types.Duration{Duration: time.Duration(r), Fsp: types.MaxFsp})
+		}
+	case bytesFlag:
+		var v []byte
+		_, v, err = DecodeBytesDesc(data)
+		d.SetBytes(v)
+	case decimalFlag:
+		dec := make([]byte, len(data))
+		for i := range data {
+			dec[i] = ^data[i]
+		}
+		_, d, err = DecodeDecimal(dec)
+	}
+	if err != nil {
+		return b, d, errors.Trace(err)
+	}
+	return remain, d, nil
+}
+
 func peekBytes(b []byte, reverse bool) (int, error) {
 	offset := 0
 	for {
@@ -199,6 +199,13 @@ func (s *testCodecSuite) TestCodecKeyCompare(c *C) {
 		c.Assert(err, IsNil)
 
 		c.Assert(bytes.Compare(b1, b2), Equals, t.Expect, Commentf("%v - %v - %v - %v - %v", t.Left, t.Right, b1, b2, t.Expect))
+
+		// The descending keys compare in the reverse order.
+		b1, err = EncodeKeyDesc(nil, t.Left...)
+		c.Assert(err, IsNil)
+		b2, err = EncodeKeyDesc(nil, t.Right...)
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Compare(b1, b2), Equals, -t.Expect, Commentf("%v - %v - %v - %v - %v", t.Left, t.Right, b1, b2, t.Expect))
 	}
 }
 
@@ -804,6 +811,30 @@ func (s *testCodecSuite) TestCut(c *C) {
 		}
 		c.Assert(b, HasLen, 0)
 	}
+	for i, t := range table {
+		comment := Commentf("%d %v", i, t)
+		// The descending keys are cut and decoded as the ascending keys, mixed with them.
+		b, err := EncodeKeyDesc(nil, t.Input...)
+		c.Assert(err, IsNil, comment)
+		b, err = EncodeKey(b, types.NewIntDatum(100))
+		c.Assert(err, IsNil, comment)
+		var d []byte
+		for j, e := range t.Expect {
+			d, b, err = CutOne(b)
+			c.Assert(err, IsNil)
+			ed, err1 := EncodeKeyDesc(nil, e)
+			c.Assert(err1, IsNil)
+			c.Assert(d, DeepEquals, ed, Commentf("%d:%d %#v", i, j, e))
+			_, v, err1 := DecodeOne(d)
+			c.Assert(err1, IsNil)
+			cmp, err1 := v.CompareDatum(nil, e)
+			c.Assert(err1, IsNil)
+			c.Assert(cmp, Equals, 0, Commentf("%d:%d %#v", i, j, e))
+		}
+		vals, err := Decode(b, 1)
+		c.Assert(err, IsNil)
+		c.Assert(vals, DeepEquals, types.MakeDatums(int64(100)))
+	}
 	for i, t := range table {
 		comment := Commentf("%d %v", i, t)
 		b, err := EncodeValue(nil, t.Input...)
@@ -821,6 +852,36 @@ func (s *testCodecSuite) TestCut(c *C) {
 	}
 }
 
+func (s *testCodecSuite) TestDecodeDesc(c *C) {
+	defer testleak.AfterTest(c)()
+	dec := new(types.MyDecimal)
+	err := dec.FromString([]byte("-12.340"))
+	c.Assert(err, IsNil)
+	dur := parseDuration(c, "1 11:11:11")
+	input := types.MakeDatums(int64(-1), "abcdefghijk", nil, dec, uint64(7), dur, float64(3.15), []byte(""))
+	b, err := EncodeKeyDesc(nil, input...)
+	c.Assert(err, IsNil)
+	b, err = EncodeKey(b, types.NewIntDatum(100))
+	c.Assert(err, IsNil)
+	origin := append([]byte(nil), b...)
+
+	vals, err := Decode(b, len(input)+1)
+	c.Assert(err, IsNil)
+	c.Assert(vals, HasLen, len(input)+1)
+	// The key is decoded in place, it must be kept unchanged.
+	c.Assert(b, DeepEquals, origin)
+	for i, e := range input {
+		cmp, err1 := vals[i].CompareDatum(nil, e)
+		c.Assert(err1, IsNil)
+		c.Assert(cmp, Equals, 0, Commentf("%d %#v", i, e))
+	}
+	c.Assert(vals[len(input)].GetInt64(), Equals, int64(100))
+
+	// A truncated key must not be decoded.
+	_, err = Decode(b[:len(b)-12], len(input)+1)
+	c.Assert(err, NotNil)
+}
+
 func (s *testCodecSuite) TestSetRawValues(c *C) {
 	datums := types.MakeDatums(1, "abc", 1.1, []byte("def"))
 	rowData, err := EncodeValue(nil, datums...)
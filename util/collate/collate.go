@@ -0,0 +1,191 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package collate compares the strings by their collations, and generates the memory-comparable sort keys
+// of the strings for the index keys.
+//
+// The collations are applied only if the new collation is enabled, it's enabled when a cluster is bootstrapped,
+// and never changed after that, because the keys of the existing indexes depend on it. Otherwise the strings
+// are compared as binary, as the old versions do.
+//
+// If the new collation is enabled:
+//   - the "binary" collation compares the bytes of the strings;
+//   - the "_bin" collations compare the bytes of the strings without the trailing spaces, so the CHAR values
+//     that are padded with spaces are equal to the unpadded ones, as MySQL's PAD SPACE collations;
+//   - the "_ci" collations compare the strings case-insensitively without the trailing spaces.
+package collate
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Collator compares the strings and generates their sort keys by a collation.
+type Collator interface {
+	// Compare returns an integer comparing the two strings,
+	// the result will be 0 if a == b, -1 if a < b, and +1 if a > b.
+	Compare(a, b string) int
+	// Key returns the sort key of the string. The bytes of the keys compare the same as the strings,
+	// and the keys of the equal strings are the same.
+	Key(str string) []byte
+}
+
+var newCollationEnabled int32
+
+// SetNewCollationEnabled sets if the new collation is enabled.
+func SetNewCollationEnabled(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&newCollationEnabled, 1)
+	} else {
+		atomic.StoreInt32(&newCollationEnabled, 0)
+	}
+}
+
+// NewCollationEnabled returns if the new collation is enabled.
+func NewCollationEnabled() bool {
+	return atomic.LoadInt32(&newCollationEnabled) == 1
+}
+
+var (
+	binCollatorInstance        = &binCollator{}
+	binPaddingCollatorInstance = &binPaddingCollator{}
+	generalCICollatorInstance  = &generalCICollator{}
+)
+
+// GetCollator returns the collator of the collation, it returns the binary collator if the new collation
+// is disabled.
+func GetCollator(collation string) Collator {
+	if !NewCollationEnabled() {
+		return binCollatorInstance
+	}
+	collation = strings.ToLower(collation)
+	switch {
+	case collation == "" || collation == charset.CollationBin:
+		return binCollatorInstance
+	case strings.HasSuffix(collation, "_ci"):
+		return generalCICollatorInstance
+	}
+	return binPaddingCollatorInstance
+}
+
+// GetCollatorByFieldType returns the collator to compare the values of the field type,
+// it returns nil if the values are not compared by the collation.
+func GetCollatorByFieldType(ft *types.FieldType) Collator {
+	if !NewCollationEnabled() || !types.IsNonBinaryStr(ft) {
+		return nil
+	}
+	return GetCollator(ft.Collate)
+}
+
+// CompareDatum compares the two datums, the strings are compared by the collator if it's not nil.
+func CompareDatum(sc *variable.StatementContext, a, b types.Datum, collator Collator) (int, error) {
+	if collator != nil && isStringKind(a.Kind()) && isStringKind(b.Kind()) {
+		return collator.Compare(a.GetString(), b.GetString()), nil
+	}
+	return a.CompareDatum(sc, b)
+}
+
+// KeyDatum returns the datum of the sort key of a string datum, which has the same kind as the string datum.
+// The other datums are returned as they are.
+func KeyDatum(d types.Datum, collator Collator) types.Datum {
+	if collator == nil || !isStringKind(d.Kind()) {
+		return d
+	}
+	key := d
+	if d.Kind() == types.KindString {
+		key.SetString(string(collator.Key(d.GetString())))
+	} else {
+		key.SetBytes(collator.Key(d.GetString()))
+	}
+	return key
+}
+
+func isStringKind(k byte) bool {
+	return k == types.KindString || k == types.KindBytes
+}
+
+func truncateTrailingSpace(str string) string {
+	return strings.TrimRight(str, " ")
+}
+
+type binCollator struct{}
+
+// Compare implements Collator interface.
+func (c *binCollator) Compare(a, b string) int {
+	return types.CompareString(a, b)
+}
+
+// Key implements Collator interface.
+func (c *binCollator) Key(str string) []byte {
+	return []byte(str)
+}
+
+type binPaddingCollator struct{}
+
+// Compare implements Collator interface.
+func (c *binPaddingCollator) Compare(a, b string) int {
+	return types.CompareString(truncateTrailingSpace(a), truncateTrailingSpace(b))
+}
+
+// Key implements Collator interface.
+func (c *binPaddingCollator) Key(str string) []byte {
+	return []byte(truncateTrailingSpace(str))
+}
+
+// generalCICollator compares the strings by the upper case of the runes, the runes out of the
+// Basic Multilingual Plane are all equal to the replacement character, as MySQL's utf8_general_ci.
+type generalCICollator struct{}
+
+// Compare implements Collator interface.
+func (c *generalCICollator) Compare(a, b string) int {
+	a, b = truncateTrailingSpace(a), truncateTrailingSpace(b)
+	for len(a) > 0 && len(b) > 0 {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		wa, wb := generalCIWeight(ra), generalCIWeight(rb)
+		if wa != wb {
+			if wa < wb {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	return types.CompareInt64(int64(len(a)), int64(len(b)))
+}
+
+// Key implements Collator interface.
+// The key is the UTF-8 encoding of the weights, so the bytes of the keys compare the same as the weights.
+func (c *generalCICollator) Key(str string) []byte {
+	str = truncateTrailingSpace(str)
+	key := make([]byte, 0, len(str))
+	var buf [utf8.UTFMax]byte
+	for _, r := range str {
+		n := utf8.EncodeRune(buf[:], generalCIWeight(r))
+		key = append(key, buf[:n]...)
+	}
+	return key
+}
+
+func generalCIWeight(r rune) rune {
+	if r > 0xFFFF {
+		return utf8.RuneError
+	}
+	return unicode.ToUpper(r)
+}
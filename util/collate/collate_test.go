@@ -0,0 +1,111 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collate
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testCollateSuite{})
+
+type testCollateSuite struct{}
+
+func (s *testCollateSuite) TestCompareAndKey(c *C) {
+	defer testleak.AfterTest(c)()
+	SetNewCollationEnabled(true)
+	defer SetNewCollationEnabled(false)
+
+	tests := []struct {
+		collation string
+		a         string
+		b         string
+		cmp       int
+	}{
+		{"binary", "a", "a ", -1},
+		{"binary", "a", "A", 1},
+		{"utf8_bin", "a", "a  ", 0},
+		{"utf8_bin", "a", "A", 1},
+		{"utf8_bin", "a\t", "a", 1},
+		{"utf8_bin", "", " ", 0},
+		{"utf8_general_ci", "a", "A ", 0},
+		{"utf8_general_ci", "abc", "ABD", -1},
+		{"utf8_general_ci", "a", "B", -1},
+		{"utf8_general_ci", "ab", "A", 1},
+		{"UTF8_GENERAL_CI", "ß", "ß", 0},
+		{"utf8_general_ci", "😀", "😃", 0},
+		{"utf8_general_ci", "ä", "Ä", 0},
+		{"utf8_general_ci", "a", "a\t", -1},
+	}
+	for _, t := range tests {
+		collator := GetCollator(t.collation)
+		comment := Commentf("%s %q %q", t.collation, t.a, t.b)
+		c.Assert(collator.Compare(t.a, t.b), Equals, t.cmp, comment)
+		c.Assert(collator.Compare(t.b, t.a), Equals, -t.cmp, comment)
+		// The keys compare the same as the strings.
+		c.Assert(bytes.Compare(collator.Key(t.a), collator.Key(t.b)), Equals, t.cmp, comment)
+	}
+
+	// The strings are compared as binary if the new collation is disabled.
+	SetNewCollationEnabled(false)
+	c.Assert(GetCollator("utf8_general_ci").Compare("a", "A"), Equals, 1)
+	c.Assert(GetCollatorByFieldType(types.NewFieldType(mysql.TypeVarchar)), IsNil)
+}
+
+func (s *testCollateSuite) TestDatum(c *C) {
+	defer testleak.AfterTest(c)()
+	SetNewCollationEnabled(true)
+	defer SetNewCollationEnabled(false)
+
+	ft := types.NewFieldType(mysql.TypeVarchar)
+	ft.Collate = "utf8_general_ci"
+	collator := GetCollatorByFieldType(ft)
+	c.Assert(collator, NotNil)
+	binFt := types.NewFieldType(mysql.TypeVarchar)
+	binFt.Collate = "binary"
+	c.Assert(GetCollatorByFieldType(binFt), IsNil)
+	c.Assert(GetCollatorByFieldType(types.NewFieldType(mysql.TypeLonglong)), IsNil)
+
+	sc := new(variable.StatementContext)
+	cmp, err := CompareDatum(sc, types.NewStringDatum("a"), types.NewBytesDatum([]byte("A")), collator)
+	c.Assert(err, IsNil)
+	c.Assert(cmp, Equals, 0)
+	cmp, err = CompareDatum(sc, types.NewStringDatum("a"), types.NewStringDatum("A"), nil)
+	c.Assert(err, IsNil)
+	c.Assert(cmp, Equals, 1)
+	cmp, err = CompareDatum(sc, types.NewIntDatum(1), types.NewIntDatum(2), collator)
+	c.Assert(err, IsNil)
+	c.Assert(cmp, Equals, -1)
+
+	key := KeyDatum(types.NewStringDatum("ab "), collator)
+	c.Assert(key.Kind(), Equals, types.KindString)
+	c.Assert(key.GetString(), Equals, "AB")
+	key = KeyDatum(types.NewBytesDatum([]byte("ab")), collator)
+	c.Assert(key.Kind(), Equals, types.KindBytes)
+	c.Assert(key.GetBytes(), BytesEquals, []byte("AB"))
+	key = KeyDatum(types.NewIntDatum(1), collator)
+	c.Assert(key.GetInt64(), Equals, int64(1))
+	key = KeyDatum(types.NewStringDatum("ab "), nil)
+	c.Assert(key.GetString(), Equals, "ab ")
+}
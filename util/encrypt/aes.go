@@ -158,6 +158,139 @@ func AESDecryptWithECB(cryptStr, key []byte) ([]byte, error) {
 	return plain, nil
 }
 
+// AESEncryptWithCBC encrypts data using AES with CBC mode.
+func AESEncryptWithCBC(str, key []byte, iv []byte) ([]byte, error) {
+	cb, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	blockSize := cb.BlockSize()
+	data, err := PKCS7Pad(str, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	crypted := make([]byte, len(data))
+	cipher.NewCBCEncrypter(cb, iv).CryptBlocks(crypted, data)
+	return crypted, nil
+}
+
+// AESDecryptWithCBC decrypts data using AES with CBC mode.
+func AESDecryptWithCBC(cryptStr, key []byte, iv []byte) ([]byte, error) {
+	cb, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	blockSize := cb.BlockSize()
+	if len(cryptStr)%blockSize != 0 {
+		return nil, errors.New("Corrupted data")
+	}
+	data := make([]byte, len(cryptStr))
+	cipher.NewCBCDecrypter(cb, iv).CryptBlocks(data, cryptStr)
+	plain, err := PKCS7Unpad(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// AESEncryptWithOFB encrypts data using AES with OFB mode, the data is not padded.
+func AESEncryptWithOFB(str, key []byte, iv []byte) ([]byte, error) {
+	cb, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	crypted := make([]byte, len(str))
+	cipher.NewOFB(cb, iv).XORKeyStream(crypted, str)
+	return crypted, nil
+}
+
+// AESDecryptWithOFB decrypts data using AES with OFB mode.
+func AESDecryptWithOFB(cryptStr, key []byte, iv []byte) ([]byte, error) {
+	// The decryption of OFB is the same as the encryption.
+	return AESEncryptWithOFB(cryptStr, key, iv)
+}
+
+// AESEncryptWithCFB encrypts data using AES with CFB mode of the segment size in bits, which is 1, 8 or 128.
+// The data is not padded.
+func AESEncryptWithCFB(str, key []byte, iv []byte, segmentSize int) ([]byte, error) {
+	return aesCFB(str, key, iv, segmentSize, false)
+}
+
+// AESDecryptWithCFB decrypts data using AES with CFB mode of the segment size in bits, which is 1, 8 or 128.
+func AESDecryptWithCFB(cryptStr, key []byte, iv []byte, segmentSize int) ([]byte, error) {
+	return aesCFB(cryptStr, key, iv, segmentSize, true)
+}
+
+func aesCFB(src, key []byte, iv []byte, segmentSize int, decrypt bool) ([]byte, error) {
+	cb, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dst := make([]byte, len(src))
+	switch segmentSize {
+	case 128:
+		if decrypt {
+			cipher.NewCFBDecrypter(cb, iv).XORKeyStream(dst, src)
+		} else {
+			cipher.NewCFBEncrypter(cb, iv).XORKeyStream(dst, src)
+		}
+	case 8:
+		cfb8(cb, iv, dst, src, decrypt)
+	case 1:
+		cfb1(cb, iv, dst, src, decrypt)
+	default:
+		return nil, errors.Errorf("invalid CFB segment size %d", segmentSize)
+	}
+	return dst, nil
+}
+
+// cfb8 is the CFB mode of 8-bit segments, the shift register is shifted by a byte for each byte.
+// See NIST SP 800-38A 6.3.
+func cfb8(b cipher.Block, iv []byte, dst, src []byte, decrypt bool) {
+	blockSize := b.BlockSize()
+	register := make([]byte, blockSize)
+	copy(register, iv)
+	out := make([]byte, blockSize)
+	for i, v := range src {
+		b.Encrypt(out, register)
+		dst[i] = v ^ out[0]
+		copy(register, register[1:])
+		if decrypt {
+			register[blockSize-1] = v
+		} else {
+			register[blockSize-1] = dst[i]
+		}
+	}
+}
+
+// cfb1 is the CFB mode of 1-bit segments, the bits of each byte are processed from the most significant one.
+// See NIST SP 800-38A 6.3.
+func cfb1(b cipher.Block, iv []byte, dst, src []byte, decrypt bool) {
+	blockSize := b.BlockSize()
+	register := make([]byte, blockSize)
+	copy(register, iv)
+	out := make([]byte, blockSize)
+	for i, v := range src {
+		var result byte
+		for j := 7; j >= 0; j-- {
+			b.Encrypt(out, register)
+			inBit := (v >> uint(j)) & 1
+			outBit := inBit ^ (out[0] >> 7)
+			result |= outBit << uint(j)
+			cipherBit := outBit
+			if decrypt {
+				cipherBit = inBit
+			}
+			// Shift the register left by a bit, and feed the cipher bit.
+			for k := 0; k < blockSize-1; k++ {
+				register[k] = register[k]<<1 | register[k+1]>>7
+			}
+			register[blockSize-1] = register[blockSize-1]<<1 | cipherBit
+		}
+		dst[i] = result
+	}
+}
+
 // DeriveKeyMySQL derives the encryption key from a password in MySQL algorithm.
 // See https://security.stackexchange.com/questions/4863/mysql-aes-encrypt-key-length.
 func DeriveKeyMySQL(key []byte, blockSize int) []byte {
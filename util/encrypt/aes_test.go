@@ -269,6 +269,54 @@ func (s *testEncryptSuite) TestAESDecryptWithECB(c *C) {
 	}
 }
 
+func (s *testEncryptSuite) TestAESWithIV(c *C) {
+	defer testleak.AfterTest(c)()
+	// NIST SP 800-38A F.2.1, F.3.1, F.3.7, F.3.13 and F.4.1.
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	iv, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	in, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d")
+	cfb := func(segmentSize int) (func(str, key, iv []byte) ([]byte, error), func(str, key, iv []byte) ([]byte, error)) {
+		return func(str, key, iv []byte) ([]byte, error) {
+				return AESEncryptWithCFB(str, key, iv, segmentSize)
+			}, func(str, key, iv []byte) ([]byte, error) {
+				return AESDecryptWithCFB(str, key, iv, segmentSize)
+			}
+	}
+	cfb1Enc, cfb1Dec := cfb(1)
+	cfb8Enc, cfb8Dec := cfb(8)
+	cfb128Enc, cfb128Dec := cfb(128)
+	tests := []struct {
+		mode    string
+		encrypt func(str, key, iv []byte) ([]byte, error)
+		decrypt func(str, key, iv []byte) ([]byte, error)
+		in      []byte
+		expect  string
+	}{
+		{"cfb1", cfb1Enc, cfb1Dec, in[:2], "68B3"},
+		{"cfb8", cfb8Enc, cfb8Dec, in, "3B79424C9C0DD436BACE9E0ED4586A4F32B9"},
+		{"cfb128", cfb128Enc, cfb128Dec, in[:16], "3B3FD92EB72DAD20333449F8E83CFB4A"},
+		{"ofb", AESEncryptWithOFB, AESDecryptWithOFB, in[:16], "3B3FD92EB72DAD20333449F8E83CFB4A"},
+		// The padding block is appended after the block of the test vector.
+		{"cbc", AESEncryptWithCBC, AESDecryptWithCBC, in[:16], "7649ABAC8119B246CEE98E9B12E9197D"},
+	}
+	for _, t := range tests {
+		crypted, err := t.encrypt(t.in, key, iv)
+		c.Assert(err, IsNil)
+		c.Assert(toHex(crypted)[:len(t.expect)], Equals, t.expect, Commentf("mode %s", t.mode))
+		plain, err := t.decrypt(crypted, key, iv)
+		c.Assert(err, IsNil)
+		c.Assert(plain, DeepEquals, t.in, Commentf("mode %s", t.mode))
+	}
+
+	crypted, err := AESEncryptWithCBC([]byte("pingcap"), key, iv)
+	c.Assert(err, IsNil)
+	c.Assert(crypted, HasLen, 16)
+	_, err = AESDecryptWithCBC(crypted[:15], key, iv)
+	c.Assert(err, NotNil)
+	_, err = AESEncryptWithCFB([]byte("pingcap"), key, iv, 64)
+	c.Assert(err, NotNil)
+}
+
 func (s *testEncryptSuite) TestDeriveKeyMySQL(c *C) {
 	defer testleak.AfterTest(c)()
 
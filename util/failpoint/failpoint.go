@@ -0,0 +1,140 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build failpoint
+
+// Package failpoint injects faults into the code paths for the chaos tests. The failpoints are only evaluated when
+// the code is built with the tag 'failpoint', otherwise Eval does nothing and is inlined by the compiler.
+//
+// A failpoint is named by "<package>/<name>", e.g. "tikv/commitError", the code at the failpoint injects the fault
+// if Eval returns true, and the value it returns may choose the kind of the fault.
+//
+// The failpoints can be enabled in the tests, or by the environment variable TIDB_FAILPOINTS to run the whole test
+// suite with faults, e.g. TIDB_FAILPOINTS="tikv/regionError=0.05;tikv/commitError=0.01". The random numbers are
+// generated from the seed logged at startup, it can be set by TIDB_FAILPOINT_SEED to reproduce a failed run.
+package failpoint
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type failpoint struct {
+	val interface{}
+	// prob is the probability that the failpoint is triggered when it's evaluated.
+	prob float64
+	// count is the number of the remaining triggers, it's unlimited if it's negative.
+	count int
+}
+
+var failpoints = struct {
+	sync.Mutex
+	m    map[string]*failpoint
+	rand *rand.Rand
+}{
+	m: make(map[string]*failpoint),
+}
+
+func init() {
+	seed := time.Now().UnixNano()
+	if s := os.Getenv("TIDB_FAILPOINT_SEED"); s != "" {
+		var err error
+		seed, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Fatalf("[failpoint] invalid TIDB_FAILPOINT_SEED %q: %v", s, err)
+		}
+	}
+	SetSeed(seed)
+	log.Infof("[failpoint] the random seed is %d", seed)
+
+	if s := os.Getenv("TIDB_FAILPOINTS"); s != "" {
+		for _, fp := range strings.Split(s, ";") {
+			kv := strings.SplitN(fp, "=", 2)
+			prob := 1.0
+			if len(kv) == 2 {
+				var err error
+				prob, err = strconv.ParseFloat(kv[1], 64)
+				if err != nil {
+					log.Fatalf("[failpoint] invalid TIDB_FAILPOINTS %q: %v", s, err)
+				}
+			}
+			EnableRandom(strings.TrimSpace(kv[0]), true, prob)
+			log.Infof("[failpoint] enable %s with the probability %v", kv[0], prob)
+		}
+	}
+}
+
+// SetSeed sets the seed of the random numbers that decide whether the random failpoints are triggered.
+func SetSeed(seed int64) {
+	failpoints.Lock()
+	failpoints.rand = rand.New(rand.NewSource(seed))
+	failpoints.Unlock()
+}
+
+// Enable enables the failpoint, it's triggered every time it's evaluated and returns val.
+func Enable(name string, val interface{}) {
+	enable(name, &failpoint{val: val, prob: 1, count: -1})
+}
+
+// EnableN enables the failpoint to be triggered for the next n evaluations.
+func EnableN(name string, val interface{}, n int) {
+	enable(name, &failpoint{val: val, prob: 1, count: n})
+}
+
+// EnableRandom enables the failpoint to be triggered with the probability prob when it's evaluated.
+func EnableRandom(name string, val interface{}, prob float64) {
+	enable(name, &failpoint{val: val, prob: prob, count: -1})
+}
+
+func enable(name string, fp *failpoint) {
+	failpoints.Lock()
+	failpoints.m[name] = fp
+	failpoints.Unlock()
+}
+
+// Disable disables the failpoint.
+func Disable(name string) {
+	failpoints.Lock()
+	delete(failpoints.m, name)
+	failpoints.Unlock()
+}
+
+// DisableAll disables all the failpoints.
+func DisableAll() {
+	failpoints.Lock()
+	failpoints.m = make(map[string]*failpoint)
+	failpoints.Unlock()
+}
+
+// Eval evaluates the failpoint, it returns the value of the failpoint and true if the failpoint is triggered.
+func Eval(name string) (interface{}, bool) {
+	failpoints.Lock()
+	defer failpoints.Unlock()
+	fp, ok := failpoints.m[name]
+	if !ok || fp.count == 0 {
+		return nil, false
+	}
+	if fp.prob < 1 && failpoints.rand.Float64() >= fp.prob {
+		return nil, false
+	}
+	if fp.count > 0 {
+		fp.count--
+	}
+	return fp.val, true
+}
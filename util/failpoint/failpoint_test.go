@@ -0,0 +1,87 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build failpoint
+
+package failpoint
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testFailpointSuite{})
+
+type testFailpointSuite struct{}
+
+func (s *testFailpointSuite) TearDownTest(c *C) {
+	DisableAll()
+}
+
+func (s *testFailpointSuite) TestEnable(c *C) {
+	_, ok := Eval("test/fp")
+	c.Assert(ok, IsFalse)
+
+	Enable("test/fp", "v")
+	for i := 0; i < 3; i++ {
+		val, ok := Eval("test/fp")
+		c.Assert(ok, IsTrue)
+		c.Assert(val, Equals, "v")
+	}
+	_, ok = Eval("test/other")
+	c.Assert(ok, IsFalse)
+
+	Disable("test/fp")
+	_, ok = Eval("test/fp")
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testFailpointSuite) TestEnableN(c *C) {
+	EnableN("test/fp", true, 2)
+	for i := 0; i < 2; i++ {
+		_, ok := Eval("test/fp")
+		c.Assert(ok, IsTrue)
+	}
+	_, ok := Eval("test/fp")
+	c.Assert(ok, IsFalse)
+}
+
+func (s *testFailpointSuite) TestEnableRandom(c *C) {
+	EnableRandom("test/fp", true, 0.5)
+	eval := func() []bool {
+		results := make([]bool, 100)
+		for i := range results {
+			_, results[i] = Eval("test/fp")
+		}
+		return results
+	}
+	SetSeed(1)
+	results := eval()
+	triggered := 0
+	for _, ok := range results {
+		if ok {
+			triggered++
+		}
+	}
+	c.Assert(triggered, Greater, 0)
+	c.Assert(triggered, Less, len(results))
+
+	// The failpoint is triggered in the same evaluations for the same seed.
+	SetSeed(1)
+	c.Assert(eval(), DeepEquals, results)
+}
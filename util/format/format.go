@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 )
 
 const (
@@ -193,3 +194,41 @@ func OutputFormat(s string) string {
 
 	return buf.String()
 }
+
+// RestoreCtx is the context used to restore the AST nodes to the SQL text.
+type RestoreCtx struct {
+	In io.Writer
+}
+
+// NewRestoreCtx returns a new RestoreCtx writing to in.
+func NewRestoreCtx(in io.Writer) *RestoreCtx {
+	return &RestoreCtx{In: in}
+}
+
+// WriteKeyWord writes the keyword in upper case.
+func (ctx *RestoreCtx) WriteKeyWord(keyWord string) {
+	fmt.Fprint(ctx.In, strings.ToUpper(keyWord))
+}
+
+// WriteName writes the name quoted by backquotes, the backquotes in the name are doubled.
+func (ctx *RestoreCtx) WriteName(name string) {
+	fmt.Fprintf(ctx.In, "`%s`", strings.Replace(name, "`", "``", -1))
+}
+
+// WriteString writes the string quoted by single quotes, the backslashes and the single quotes
+// in the string are escaped.
+func (ctx *RestoreCtx) WriteString(str string) {
+	str = strings.Replace(str, `\`, `\\`, -1)
+	str = strings.Replace(str, `'`, `\'`, -1)
+	fmt.Fprintf(ctx.In, "'%s'", str)
+}
+
+// WritePlain writes the plain text as it is.
+func (ctx *RestoreCtx) WritePlain(plainText string) {
+	fmt.Fprint(ctx.In, plainText)
+}
+
+// WritePlainf writes the plain text formatted by fmt.Sprintf.
+func (ctx *RestoreCtx) WritePlainf(format string, a ...interface{}) {
+	fmt.Fprintf(ctx.In, format, a...)
+}
@@ -58,3 +58,15 @@ z
 	expect = "abc3%e x y z\n "
 	checkFormat(c, f, buf, str, expect)
 }
+
+func (s *testFormatSuite) TestRestoreCtx(c *C) {
+	defer testleak.AfterTest(c)()
+	var sb bytes.Buffer
+	ctx := NewRestoreCtx(&sb)
+	ctx.WriteKeyWord("select")
+	ctx.WritePlain(" ")
+	ctx.WriteName("a`b")
+	ctx.WritePlainf(", %d, ", 1)
+	ctx.WriteString(`it's \`)
+	c.Assert(sb.String(), Equals, "SELECT `a``b`, 1, 'it\\'s \\\\'")
+}
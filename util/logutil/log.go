@@ -0,0 +1,148 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logutil provides the loggers of the modules and the query context fields of the log entries.
+//
+// The log level of a module can be set apart from the global log level, the module logger shares the
+// output, the formatter and the hooks of the standard logger. The query logger adds the connection id,
+// the user, the SQL digest and the start ts of the transaction to the entries of a session.
+package logutil
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+// The modules with their own log levels.
+const (
+	ModuleServer   = "server"
+	ModuleSession  = "session"
+	ModuleExecutor = "executor"
+	ModuleDDL      = "ddl"
+)
+
+// The query context fields of the log entries.
+const (
+	FieldConnID    = "conn"
+	FieldUser      = "user"
+	FieldSQLDigest = "digest"
+	FieldStartTS   = "start_ts"
+	// FieldPlanDigest is the digest of the plan shape in the slow query entries.
+	FieldPlanDigest = "plan_digest"
+)
+
+var (
+	moduleMu      sync.RWMutex
+	moduleLoggers = make(map[string]*log.Logger)
+	// output is shared by the standard logger and the module loggers, a logger only locks its own mutex
+	// to write, so the writes of the loggers are serialized by output.
+	output = &lockedWriter{}
+)
+
+type lockedWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Write implements io.Writer interface.
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	n, err := w.out.Write(p)
+	w.mu.Unlock()
+	return n, err
+}
+
+// SetModuleLevels sets the log levels of the modules from a comma separated list of "module=level" pairs,
+// like "ddl=debug,server=warn". The modules not in the list log at the global log level.
+func SetModuleLevels(levels string) error {
+	loggers := make(map[string]*log.Logger)
+	std := log.StandardLogger()
+	if std.Out != io.Writer(output) {
+		output.mu.Lock()
+		output.out = std.Out
+		output.mu.Unlock()
+		log.SetOutput(output)
+	}
+	for _, item := range strings.Split(levels, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pair := strings.SplitN(item, "=", 2)
+		if len(pair) != 2 {
+			return errors.Errorf("invalid module log level %s", item)
+		}
+		level, err := log.ParseLevel(strings.TrimSpace(pair[1]))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		loggers[strings.ToLower(strings.TrimSpace(pair[0]))] = &log.Logger{
+			Out:       output,
+			Formatter: std.Formatter,
+			Hooks:     std.Hooks,
+			Level:     level,
+		}
+	}
+	moduleMu.Lock()
+	moduleLoggers = loggers
+	moduleMu.Unlock()
+	return nil
+}
+
+// Logger returns the logger of the module.
+func Logger(module string) *log.Logger {
+	moduleMu.RLock()
+	logger, ok := moduleLoggers[module]
+	moduleMu.RUnlock()
+	if ok {
+		return logger
+	}
+	return log.StandardLogger()
+}
+
+// QueryLogger returns the log entry of the module with the query context fields of the session.
+func QueryLogger(module string, vars *variable.SessionVars) *log.Entry {
+	fields := log.Fields{FieldConnID: vars.ConnectionID}
+	if vars.User != nil {
+		fields[FieldUser] = vars.User.String()
+	}
+	if sc := vars.StmtCtx; sc != nil && sc.OriginalSQL != "" {
+		fields[FieldSQLDigest] = lazyDigest{sc}
+	}
+	if vars.TxnCtx != nil && vars.TxnCtx.StartTS != 0 {
+		fields[FieldStartTS] = vars.TxnCtx.StartTS
+	}
+	return Logger(module).WithFields(fields)
+}
+
+// lazyDigest is the statement context, the digest is computed only if the entry is written, and it's cached
+// by the statement context for the other entries.
+type lazyDigest struct {
+	sc *variable.StatementContext
+}
+
+// String implements fmt.Stringer for the text formatters.
+func (d lazyDigest) String() string {
+	return d.sc.SQLDigest()
+}
+
+// MarshalJSON implements json.Marshaler for the JSON formatter.
+func (d lazyDigest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logutil
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/auth"
+	"github.com/pingcap/tidb/util/sqldigest"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testLogSuite{})
+
+type testLogSuite struct{}
+
+func (s *testLogSuite) TestModuleLevels(c *C) {
+	defer testleak.AfterTest(c)()
+	defer SetModuleLevels("")
+
+	c.Assert(SetModuleLevels("ddl=debug, Server=error"), IsNil)
+	c.Assert(Logger(ModuleDDL).Level, Equals, log.DebugLevel)
+	c.Assert(Logger(ModuleServer).Level, Equals, log.ErrorLevel)
+	c.Assert(Logger(ModuleSession), Equals, log.StandardLogger())
+
+	c.Assert(SetModuleLevels("ddl"), NotNil)
+	c.Assert(SetModuleLevels("ddl=verbose"), NotNil)
+	// The levels are kept if the new levels are invalid.
+	c.Assert(Logger(ModuleDDL).Level, Equals, log.DebugLevel)
+
+	c.Assert(SetModuleLevels(""), IsNil)
+	c.Assert(Logger(ModuleDDL), Equals, log.StandardLogger())
+}
+
+// exclusiveWriter counts the writes which overlap with another write.
+type exclusiveWriter struct {
+	writing  int32
+	overlaps int32
+	lines    []string
+}
+
+func (w *exclusiveWriter) Write(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&w.writing, 0, 1) {
+		atomic.AddInt32(&w.overlaps, 1)
+		return len(p), nil
+	}
+	w.lines = append(w.lines, strings.TrimSpace(string(p)))
+	// Let the other writers run to catch them.
+	runtime.Gosched()
+	atomic.StoreInt32(&w.writing, 0)
+	return len(p), nil
+}
+
+func (s *testLogSuite) TestModuleOutput(c *C) {
+	defer testleak.AfterTest(c)()
+	std := log.StandardLogger()
+	origin, level := std.Out, std.Level
+	w := &exclusiveWriter{}
+	log.SetOutput(w)
+	log.SetLevel(log.InfoLevel)
+	defer func() {
+		log.SetOutput(origin)
+		log.SetLevel(level)
+		SetModuleLevels("")
+	}()
+
+	c.Assert(SetModuleLevels("ddl=info,server=info"), IsNil)
+	var wg sync.WaitGroup
+	for _, logger := range []*log.Logger{std, Logger(ModuleDDL), Logger(ModuleServer)} {
+		wg.Add(1)
+		go func(logger *log.Logger) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				logger.Info("message")
+			}
+		}(logger)
+	}
+	wg.Wait()
+	// The loggers write to the same output one by one.
+	c.Assert(atomic.LoadInt32(&w.overlaps), Equals, int32(0))
+	c.Assert(w.lines, HasLen, 600)
+}
+
+func (s *testLogSuite) TestQueryLogger(c *C) {
+	defer testleak.AfterTest(c)()
+	vars := variable.NewSessionVars()
+	vars.ConnectionID = 3
+	entry := QueryLogger(ModuleSession, vars)
+	c.Assert(entry.Data, HasLen, 1)
+	c.Assert(entry.Data[FieldConnID], Equals, uint64(3))
+
+	vars.User = &auth.UserIdentity{Username: "root", Hostname: "localhost"}
+	vars.StmtCtx.OriginalSQL = "select 1"
+	vars.TxnCtx.StartTS = 10
+	entry = QueryLogger(ModuleSession, vars)
+	c.Assert(entry.Data, HasLen, 4)
+	c.Assert(entry.Data[FieldUser], Equals, vars.User.String())
+	// The digest is of the normalized statement, like the digests of the statements in performance_schema.
+	_, digest := sqldigest.NormalizeDigest("SELECT 2")
+	c.Assert(fmt.Sprint(entry.Data[FieldSQLDigest]), Equals, digest)
+	c.Assert(entry.Data[FieldStartTS], Equals, uint64(10))
+}
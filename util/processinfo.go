@@ -15,6 +15,8 @@ package util
 
 import (
 	"time"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
 )
 
 // ProcessInfo is a struct used for show processlist statement.
@@ -27,11 +29,28 @@ type ProcessInfo struct {
 	Time    time.Time
 	State   uint16
 	Info    string
+	// Progress is the progress of the running statement, it's nil if the session is idle.
+	Progress *variable.QueryProgress
 }
 
 // SessionManager is an interface for session manage. Show processlist and
 // kill statement rely on this interface.
 type SessionManager interface {
 	ShowProcessList() []ProcessInfo
-	Kill(connectionID uint64, query bool)
+	Kill(connectionID uint64, query bool) error
+}
+
+// The connection IDs are 32 bits like the thread IDs in the MySQL handshake. The high ServerIDBits bits are the ID
+// of the tidb-server and the low LocalConnIDBits bits are allocated by the tidb-server, so a connection ID identifies
+// the tidb-server in the cluster.
+const (
+	ServerIDBits    = 12
+	LocalConnIDBits = 32 - ServerIDBits
+	MaxServerID     = 1<<ServerIDBits - 1
+	MaxLocalConnID  = 1<<LocalConnIDBits - 1
+)
+
+// ServerIDOfConn returns the ID of the tidb-server which the connection is on.
+func ServerIDOfConn(connectionID uint64) uint32 {
+	return uint32(connectionID >> LocalConnIDBits)
 }
@@ -14,6 +14,8 @@
 package ranger
 
 import (
+	"sort"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
@@ -23,7 +25,7 @@ import (
 
 func buildIndexRange(sc *variable.StatementContext, cols []*expression.Column, lengths []int, inAndEqCount int,
 	accessCondition []expression.Expression) ([]*types.IndexRange, error) {
-	rb := builder{sc: sc}
+	rb := builder{sc: sc, indexKey: true}
 	var ranges []*types.IndexRange
 	for i := 0; i < inAndEqCount; i++ {
 		// Build ranges for equal or in access conditions.
@@ -50,8 +52,8 @@ func buildIndexRange(sc *variable.StatementContext, cols []*expression.Column, l
 		fixPrefixColRange(ranges, lengths)
 	}
 
-	if len(ranges) > 0 && len(ranges[0].LowVal) < len(cols) {
-		for _, ran := range ranges {
+	for _, ran := range ranges {
+		if len(ran.LowVal) < len(cols) {
 			if ran.HighExclude || ran.LowExclude {
 				if ran.HighExclude {
 					ran.HighVal = append(ran.HighVal, types.NewDatum(nil))
@@ -97,11 +99,11 @@ func fixRangeDatum(v *types.Datum, length int) {
 	}
 }
 
-// getEQColOffset judge if the expression is a eq function that one side is constant and another is column.
+// getEQColOffset judge if the expression is a eq or null-safe eq function that one side is constant and another is column.
 // If so, it will return the offset of this column in the slice.
 func getEQColOffset(expr expression.Expression, cols []*expression.Column) int {
 	f, ok := expr.(*expression.ScalarFunction)
-	if !ok || f.FuncName.L != ast.EQ {
+	if !ok || (f.FuncName.L != ast.EQ && f.FuncName.L != ast.NullEQ) {
 		return -1
 	}
 	if c, ok := f.GetArgs()[0].(*expression.Column); ok {
@@ -184,6 +186,223 @@ func detachIndexScanConditions(conditions []expression.Expression, cols []*expre
 	return accessConds, filterConds, accessEqualCount, accessInAndEqCount
 }
 
+// detachCondsAndBuildIndexRange detaches the access conditions of the index columns and builds the index ranges.
+// If there is no range condition of the column after the equal and in conditions, a DNF condition whose items
+// are all access conditions of the rest columns is used, e.g. "(a = 1 and b > 2) or (a = 3 and b = 4)", its ranges
+// are the unions of the ranges of the items.
+func detachCondsAndBuildIndexRange(sc *variable.StatementContext, conditions []expression.Expression, cols []*expression.Column,
+	lengths []int) (ranges []*types.IndexRange, accessConds, filterConds []expression.Expression, err error) {
+	accessConds, filterConds, _, eqAndInCount := detachIndexScanConditions(conditions, cols, lengths)
+	ranges, err = buildIndexRange(sc, cols, lengths, eqAndInCount, accessConds)
+	if err != nil || eqAndInCount != len(accessConds) || eqAndInCount >= len(cols) {
+		return ranges, accessConds, filterConds, errors.Trace(err)
+	}
+	for i, cond := range filterConds {
+		dnfRanges, reserve, ok, err := buildDNFIndexRange(sc, cond, cols[eqAndInCount:], lengths[eqAndInCount:])
+		if err != nil {
+			return nil, nil, nil, errors.Trace(err)
+		}
+		if !ok {
+			continue
+		}
+		if eqAndInCount > 0 {
+			dnfRanges = appendDNFIndexRanges(ranges, dnfRanges)
+		}
+		accessConds = append(accessConds, cond)
+		if !reserve {
+			filterConds = append(filterConds[:i:i], filterConds[i+1:]...)
+		}
+		return dnfRanges, accessConds, filterConds, nil
+	}
+	return ranges, accessConds, filterConds, nil
+}
+
+// buildDNFIndexRange builds the ranges of the DNF condition, it returns false if the condition is not a DNF
+// condition or any item of it isn't an access condition. The returned reserve is true if the ranges contain
+// the values that don't satisfy the condition, so the condition should be reserved in the filter conditions.
+func buildDNFIndexRange(sc *variable.StatementContext, cond expression.Expression, cols []*expression.Column,
+	lengths []int) (ranges []*types.IndexRange, reserve bool, ok bool, err error) {
+	if sf, isFunc := cond.(*expression.ScalarFunction); !isFunc || sf.FuncName.L != ast.LogicOr {
+		return nil, false, false, nil
+	}
+	for _, item := range expression.SplitDNFItems(cond) {
+		itemRanges, accessConds, filterConds, err := detachCondsAndBuildIndexRange(sc, expression.SplitCNFItems(item), cols, lengths)
+		if err != nil {
+			return nil, false, false, errors.Trace(err)
+		}
+		if len(accessConds) == 0 {
+			return nil, false, false, nil
+		}
+		if len(filterConds) > 0 {
+			reserve = true
+		}
+		ranges = append(ranges, itemRanges...)
+	}
+	ranges, ok, err = unionIndexRanges(sc, ranges)
+	return ranges, reserve, ok, errors.Trace(err)
+}
+
+// appendDNFIndexRanges appends the ranges of the DNF condition to the point ranges of the columns before it.
+func appendDNFIndexRanges(pointRanges, dnfRanges []*types.IndexRange) []*types.IndexRange {
+	ranges := make([]*types.IndexRange, 0, len(pointRanges)*len(dnfRanges))
+	for _, pointRan := range pointRanges {
+		for _, dnfRan := range dnfRanges {
+			ran := &types.IndexRange{
+				LowVal:      make([]types.Datum, 0, len(pointRan.LowVal)+len(dnfRan.LowVal)),
+				HighVal:     make([]types.Datum, 0, len(pointRan.HighVal)+len(dnfRan.HighVal)),
+				LowExclude:  dnfRan.LowExclude,
+				HighExclude: dnfRan.HighExclude,
+			}
+			ran.LowVal = append(append(ran.LowVal, pointRan.LowVal...), dnfRan.LowVal...)
+			ran.HighVal = append(append(ran.HighVal, pointRan.HighVal...), dnfRan.HighVal...)
+			ranges = append(ranges, ran)
+		}
+	}
+	return ranges
+}
+
+// unionIndexRanges sorts the ranges and merges the overlapped ones. Each range is the point values of some
+// leading columns and a range of the next column, the overlapped ranges are merged only if one of them
+// contains the other or they have the same point values, otherwise it returns false.
+func unionIndexRanges(sc *variable.StatementContext, ranges []*types.IndexRange) ([]*types.IndexRange, bool, error) {
+	sorter := indexRangeSorter{ranges: ranges, sc: sc}
+	sort.Sort(&sorter)
+	if sorter.err != nil {
+		return nil, false, errors.Trace(sorter.err)
+	}
+	merged := make([]*types.IndexRange, 0, len(ranges))
+	for _, ran := range ranges {
+		if len(merged) == 0 {
+			merged = append(merged, ran)
+			continue
+		}
+		last := merged[len(merged)-1]
+		cmp, err := compareIndexRangeBound(sc, ran.LowVal, lowBoundInf(ran), last.HighVal, highBoundInf(last))
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		if cmp >= 0 {
+			merged = append(merged, ran)
+			continue
+		}
+		cmp, err = compareIndexRangeBound(sc, ran.HighVal, highBoundInf(ran), last.HighVal, highBoundInf(last))
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		if cmp <= 0 {
+			// The range is contained by the last one.
+			continue
+		}
+		same, err := samePointValues(sc, last, ran)
+		if err != nil || !same {
+			return nil, false, errors.Trace(err)
+		}
+		newRan := &types.IndexRange{
+			LowVal:      last.LowVal,
+			LowExclude:  last.LowExclude,
+			HighVal:     ran.HighVal,
+			HighExclude: ran.HighExclude,
+		}
+		n := len(newRan.LowVal)
+		if len(newRan.HighVal) > n {
+			n = len(newRan.HighVal)
+		}
+		newRan.Align(n)
+		merged[len(merged)-1] = newRan
+	}
+	return merged, true, nil
+}
+
+// samePointValues checks if the ranges have the same number of the point values and the values are equal.
+func samePointValues(sc *variable.StatementContext, a, b *types.IndexRange) (bool, error) {
+	n, err := pointValueCount(sc, a)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if m, err := pointValueCount(sc, b); err != nil || m != n || n >= len(a.LowVal) || n >= len(b.LowVal) {
+		return false, errors.Trace(err)
+	}
+	cmp, err := compareIndexRangeBound(sc, a.LowVal[:n], 0, b.LowVal[:n], 0)
+	return cmp == 0, errors.Trace(err)
+}
+
+// pointValueCount returns the number of the leading columns whose low and high values are the same.
+func pointValueCount(sc *variable.StatementContext, ran *types.IndexRange) (int, error) {
+	for i := 0; i < len(ran.LowVal) && i < len(ran.HighVal); i++ {
+		low, high := ran.LowVal[i], ran.HighVal[i]
+		if low.Kind() == types.KindMinNotNull || low.Kind() == types.KindMaxValue || high.Kind() == types.KindMaxValue {
+			return i, nil
+		}
+		cmp, err := low.CompareDatum(sc, high)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if cmp != 0 {
+			return i, nil
+		}
+	}
+	return len(ran.LowVal), nil
+}
+
+// A bound of an index range is its values followed by an infinitesimal, which is -1 if the bound is the
+// included low bound or the excluded high bound, and +1 otherwise.
+func lowBoundInf(ran *types.IndexRange) int {
+	if ran.LowExclude {
+		return 1
+	}
+	return -1
+}
+
+func highBoundInf(ran *types.IndexRange) int {
+	if ran.HighExclude {
+		return -1
+	}
+	return 1
+}
+
+// compareIndexRangeBound compares the bounds of the index ranges in the order of the values.
+func compareIndexRangeBound(sc *variable.StatementContext, a []types.Datum, aInf int, b []types.Datum, bInf int) (int, error) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		cmp, err := a[i].CompareDatum(sc, b[i])
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if cmp != 0 {
+			return cmp, nil
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return aInf, nil
+	case len(a) > len(b):
+		return -bInf, nil
+	}
+	return types.CompareInt64(int64(aInf), int64(bInf)), nil
+}
+
+type indexRangeSorter struct {
+	ranges []*types.IndexRange
+	err    error
+	sc     *variable.StatementContext
+}
+
+func (r *indexRangeSorter) Len() int {
+	return len(r.ranges)
+}
+
+func (r *indexRangeSorter) Less(i, j int) bool {
+	a, b := r.ranges[i], r.ranges[j]
+	cmp, err := compareIndexRangeBound(r.sc, a.LowVal, lowBoundInf(a), b.LowVal, lowBoundInf(b))
+	if err != nil {
+		r.err = err
+	}
+	return cmp < 0
+}
+
+func (r *indexRangeSorter) Swap(i, j int) {
+	r.ranges[i], r.ranges[j] = r.ranges[j], r.ranges[i]
+}
+
 // buildColumnRange builds the range for sampling histogram to calculate the row count.
 func buildColumnRange(conds []expression.Expression, sc *variable.StatementContext, tp *types.FieldType) ([]*types.ColumnRange, error) {
 	if len(conds) == 0 {
@@ -229,9 +448,11 @@ func BuildRange(sc *variable.StatementContext, conds []expression.Expression, ra
 			retRanges = append(retRanges, ran)
 		}
 	} else if rangeType == IndexRangeType {
-		var eqAndInCount int
-		accessConditions, otherConditions, _, eqAndInCount = detachIndexScanConditions(conds, cols, lengths)
-		ranges, err := buildIndexRange(sc, cols, lengths, eqAndInCount, accessConditions)
+		var (
+			ranges []*types.IndexRange
+			err    error
+		)
+		ranges, accessConditions, otherConditions, err = detachCondsAndBuildIndexRange(sc, conds, cols, lengths)
 		if err != nil {
 			return nil, nil, nil, errors.Trace(err)
 		}
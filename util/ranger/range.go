@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -118,6 +119,25 @@ func (r *pointSorter) Swap(i, j int) {
 type builder struct {
 	err error
 	sc  *variable.StatementContext
+	// indexKey indicates if the ranges are built for the index keys, whose strings are the sort keys of
+	// their collations, see the collate package.
+	indexKey bool
+}
+
+// keyCollator returns the collator of the column whose sort keys are in the index keys,
+// it returns nil if the ranges are not built for the index keys.
+func (r *builder) keyCollator(expr expression.Expression) collate.Collator {
+	col, ok := expr.(*expression.Column)
+	if !r.indexKey || !ok {
+		return nil
+	}
+	return collate.GetCollatorByFieldType(col.RetType)
+}
+
+// keyOfValue returns the sort key of the string value compared with the column if the ranges are built
+// for the index keys.
+func (r *builder) keyOfValue(expr expression.Expression, value types.Datum) types.Datum {
+	return collate.KeyDatum(value, r.keyCollator(expr))
 }
 
 func (r *builder) build(expr expression.Expression) []point {
@@ -167,7 +187,7 @@ func (r *builder) buildFormBinOp(expr *expression.ScalarFunction) []point {
 	var value types.Datum
 	var op string
 	if v, ok := expr.GetArgs()[0].(*expression.Constant); ok {
-		value = v.Value
+		value = r.keyOfValue(expr.GetArgs()[1], v.Value)
 		switch expr.FuncName.L {
 		case ast.GE:
 			op = ast.LE
@@ -181,15 +201,19 @@ func (r *builder) buildFormBinOp(expr *expression.ScalarFunction) []point {
 			op = expr.FuncName.L
 		}
 	} else {
-		value = expr.GetArgs()[1].(*expression.Constant).Value
+		value = r.keyOfValue(expr.GetArgs()[0], expr.GetArgs()[1].(*expression.Constant).Value)
 		op = expr.FuncName.L
 	}
 	if value.IsNull() {
+		if op == ast.NullEQ {
+			// "a <=> NULL" is the NULL point.
+			return []point{{start: true}, {}}
+		}
 		return nil
 	}
 
 	switch op {
-	case ast.EQ:
+	case ast.EQ, ast.NullEQ:
 		startPoint := point{value: value, start: true}
 		endPoint := point{value: value}
 		return []point{startPoint, endPoint}
@@ -268,8 +292,9 @@ func (r *builder) newBuildFromIn(expr *expression.ScalarFunction) []point {
 			r.err = ErrUnsupportedType.Gen("expr:%v is not constant", e)
 			return fullRange
 		}
-		startPoint := point{value: types.NewDatum(v.Value.GetValue()), start: true}
-		endPoint := point{value: types.NewDatum(v.Value.GetValue())}
+		value := r.keyOfValue(expr.GetArgs()[0], v.Value)
+		startPoint := point{value: types.NewDatum(value.GetValue()), start: true}
+		endPoint := point{value: types.NewDatum(value.GetValue())}
 		rangePoints = append(rangePoints, startPoint, endPoint)
 	}
 	sorter := pointSorter{points: rangePoints, sc: r.sc}
@@ -343,6 +368,12 @@ func (r *builder) newBuildFromPatternLike(expr *expression.ScalarFunction) []poi
 		}
 		lowValue = append(lowValue, pattern[i])
 	}
+	if collator := r.keyCollator(expr.GetArgs()[0]); collator != nil {
+		// The sort keys of the matched strings start with the key of the prefix, it's not excluded
+		// because the trailing spaces are not in the keys.
+		lowValue = collator.Key(string(lowValue))
+		exclude = false
+	}
 	if len(lowValue) == 0 {
 		return []point{{value: types.MinNotNullDatum(), start: true}, {value: types.MaxValueDatum()}}
 	}
@@ -396,7 +427,7 @@ func (r *builder) buildFromNot(expr *expression.ScalarFunction) []point {
 
 func (r *builder) buildFromScalarFunc(expr *expression.ScalarFunction) []point {
 	switch op := expr.FuncName.L; op {
-	case ast.GE, ast.GT, ast.LT, ast.LE, ast.EQ, ast.NE:
+	case ast.GE, ast.GT, ast.LT, ast.LE, ast.EQ, ast.NE, ast.NullEQ:
 		return r.buildFormBinOp(expr)
 	case ast.LogicAnd:
 		return r.intersection(r.build(expr.GetArgs()[0]), r.build(expr.GetArgs()[1]))
@@ -499,6 +530,10 @@ func (r *builder) convertPoint(point point, tp *types.FieldType) point {
 	if err != nil {
 		r.err = errors.Trace(err)
 	}
+	if r.indexKey {
+		// The values that are not strings are converted to strings of the column.
+		casted = collate.KeyDatum(casted, collate.GetCollatorByFieldType(tp))
+	}
 	valCmpCasted, err := point.value.CompareDatum(r.sc, casted)
 	if err != nil {
 		r.err = errors.Trace(err)
@@ -139,8 +139,9 @@ func (s *testRangerSuite) TestTableRange(c *C) {
 			resultStr: "[(-inf,0] [3,+inf)]",
 		},
 		{
+			// "a < null" is always NULL, so only "a > 0" is matched.
 			exprStr:   "a not between null and 0",
-			resultStr: "[(-inf,+inf)]",
+			resultStr: "[[1,+inf)]",
 		},
 		{
 			exprStr:   "a between 2 and 1",
@@ -231,8 +232,9 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 			inAndEqCnt: 0,
 		},
 		{
+			// The start point is not excluded, because the key of "abc " is "abc" in the index.
 			exprStr:    "a LIKE 'abc_'",
-			resultStr:  "[(abc +inf,abd <nil>)]",
+			resultStr:  "[[abc <nil>,abd <nil>)]",
 			inAndEqCnt: 0,
 		},
 		{
@@ -275,6 +277,57 @@ func (s *testRangerSuite) TestIndexRange(c *C) {
 			resultStr:  `[[a 1,a 1] [a 2,a 2] [a 3,a 3]]`,
 			inAndEqCnt: 2,
 		},
+		{
+			exprStr:    `a = 'a' and b <=> 1`,
+			resultStr:  `[[a 1,a 1]]`,
+			inAndEqCnt: 2,
+		},
+		{
+			exprStr:    `a <=> NULL`,
+			resultStr:  `[[<nil>,<nil>]]`,
+			inAndEqCnt: 1,
+		},
+		{
+			exprStr:    `(a, b) in (('b', 2), ('a', 1))`,
+			resultStr:  `[[a 1,a 1] [b 2,b 2]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a = 'b' and b < 3) or (a = 'a' and b > 1)`,
+			resultStr:  `[(a 1,a +inf] [b -inf,b 3)]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a = 'a' and b = 1) or a = 'a'`,
+			resultStr:  `[[a,a]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a = 'a' and b > 1) or (a = 'a' and b < 0)`,
+			resultStr:  `[[a -inf,a 0) (a 1,a +inf]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a = 'a' and b > 1) or (a = 'a' and b < 5)`,
+			resultStr:  `[[a -inf,a +inf]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a = 'a' and b > 1) or a > 'a'`,
+			resultStr:  `[(a 1,a +inf] (a +inf,+inf +inf]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			exprStr:    `(a >= 'a' and a <= 'c') or (a = 'b' and b = 1)`,
+			resultStr:  `[[a,c]]`,
+			inAndEqCnt: 0,
+		},
+		{
+			// The item "b = 1" is not an access condition.
+			exprStr:    `a = 'a' or b = 1`,
+			resultStr:  `[[<nil>,+inf]]`,
+			inAndEqCnt: 0,
+		},
 	}
 
 	for _, tt := range tests {
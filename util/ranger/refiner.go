@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -44,7 +45,7 @@ func FullIndexRange() []*types.IndexRange {
 // BuildIndexRange will build range of index for PhysicalIndexScan
 func BuildIndexRange(sc *variable.StatementContext, tblInfo *model.TableInfo, index *model.IndexInfo,
 	accessInAndEqCount int, accessCondition []expression.Expression) ([]*types.IndexRange, error) {
-	rb := builder{sc: sc}
+	rb := builder{sc: sc, indexKey: true}
 	var ranges []*types.IndexRange
 	for i := 0; i < accessInAndEqCount; i++ {
 		// Build ranges for equal or in access conditions.
@@ -120,11 +121,11 @@ func refineRangeDatum(v *types.Datum, ic *model.IndexColumn) {
 	}
 }
 
-// getEQFunctionOffset judge if the expression is a eq function like A = 1 where a is an index.
+// getEQFunctionOffset judge if the expression is a eq or null-safe eq function like A = 1 where a is an index.
 // If so, it will return the offset of A in index columns. e.g. for index(C,B,A), A's offset is 2.
 func getEQFunctionOffset(expr expression.Expression, cols []*model.IndexColumn) int {
 	f, ok := expr.(*expression.ScalarFunction)
-	if !ok || f.FuncName.L != ast.EQ {
+	if !ok || (f.FuncName.L != ast.EQ && f.FuncName.L != ast.NullEQ) {
 		return -1
 	}
 	if c, ok := f.GetArgs()[0].(*expression.Column); ok {
@@ -373,7 +374,7 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 	switch scalar.FuncName.L {
 	case ast.LogicOr, ast.LogicAnd:
 		return c.check(scalar.GetArgs()[0]) && c.check(scalar.GetArgs()[1])
-	case ast.EQ, ast.NE, ast.GE, ast.GT, ast.LE, ast.LT:
+	case ast.EQ, ast.NE, ast.GE, ast.GT, ast.LE, ast.LT, ast.NullEQ:
 		if _, ok := scalar.GetArgs()[0].(*expression.Constant); ok {
 			if c.checkColumn(scalar.GetArgs()[1]) {
 				return scalar.FuncName.L != ast.NE || c.length == types.UnspecifiedLength
@@ -387,9 +388,9 @@ func (c *conditionChecker) checkScalarFunction(scalar *expression.ScalarFunction
 	case ast.IsNull, ast.IsTruth, ast.IsFalsity:
 		return c.checkColumn(scalar.GetArgs()[0])
 	case ast.UnaryNot:
-		// TODO: support "not like" and "not in" convert to access conditions.
+		// TODO: support "not like", "not in" and "not <=>" convert to access conditions.
 		if s, ok := scalar.GetArgs()[0].(*expression.ScalarFunction); ok {
-			if s.FuncName.L == ast.In || s.FuncName.L == ast.Like {
+			if s.FuncName.L == ast.In || s.FuncName.L == ast.Like || s.FuncName.L == ast.NullEQ {
 				return false
 			}
 		} else {
@@ -417,6 +418,11 @@ func (c *conditionChecker) checkLikeFunc(scalar *expression.ScalarFunction) bool
 	if !c.checkColumn(scalar.GetArgs()[0]) {
 		return false
 	}
+	// The pattern matches the strings as binary, the range of the sort keys of the collation is
+	// larger than the matched strings.
+	if collate.GetCollatorByFieldType(scalar.GetArgs()[0].GetType()) != nil {
+		c.shouldReserve = true
+	}
 	pattern, ok := scalar.GetArgs()[1].(*expression.Constant)
 	if !ok {
 		return false
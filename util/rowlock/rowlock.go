@@ -0,0 +1,177 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rowlock is the row lock table of the tidb-server.
+//
+// It records the row keys locked by "SELECT .. FOR UPDATE" in the transactions of the server. The locks
+// of the store are still checked when the transactions commit, the table lets "FOR UPDATE NOWAIT" and
+// "FOR UPDATE SKIP LOCKED" find the rows locked by the other transactions without waiting for the commit,
+// and lets the locks and the lock waits be shown in information_schema.
+package rowlock
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pingcap/tidb/kv"
+)
+
+// Owner describes the transaction locking the keys.
+type Owner struct {
+	StartTS   uint64
+	SessionID uint64
+	SQLDigest string
+}
+
+// Lock is a key locked by a transaction.
+type Lock struct {
+	Owner
+	StoreID string
+	Key     kv.Key
+}
+
+// LockWait is a key wanted by a transaction while it is locked by another transaction. The locks of
+// the transactions are optimistic, so the waiting transaction goes on, and is likely to fail at commit
+// if the holding transaction commits first.
+type LockWait struct {
+	Lock
+	HoldingStartTS uint64
+
+	holder kv.Transaction
+}
+
+type lockEntry struct {
+	txn  kv.Transaction
+	lock *Lock
+}
+
+type table struct {
+	sync.Mutex
+	// owners maps the locked keys to the locks.
+	owners map[string]lockEntry
+	// keys maps the transactions to the keys they hold.
+	keys map[kv.Transaction][]string
+	// waits maps the transactions to the keys they wait for.
+	waits map[kv.Transaction][]*LockWait
+}
+
+var locks = &table{
+	owners: make(map[string]lockEntry),
+	keys:   make(map[kv.Transaction][]string),
+	waits:  make(map[kv.Transaction][]*LockWait),
+}
+
+func lockKey(storeID string, key kv.Key) string {
+	return storeID + string(key)
+}
+
+// TryLock locks the keys of a store for the transaction. If any of the keys is held by another
+// transaction, no key is locked and false is returned, unless force is true, then the free keys are
+// locked and a lock wait is recorded for each of the others, which are left to the conflict check of
+// the commit.
+func TryLock(store kv.Storage, txn kv.Transaction, keys []kv.Key, owner Owner, force bool) bool {
+	storeID := store.UUID()
+	locks.Lock()
+	defer locks.Unlock()
+	var (
+		free  []kv.Key
+		waits []*LockWait
+	)
+	for _, key := range keys {
+		entry, ok := locks.owners[lockKey(storeID, key)]
+		if !ok {
+			free = append(free, key)
+		} else if entry.txn != txn {
+			if !force {
+				return false
+			}
+			waits = append(waits, &LockWait{
+				Lock:           Lock{Owner: owner, StoreID: storeID, Key: key},
+				HoldingStartTS: entry.lock.StartTS,
+				holder:         entry.txn,
+			})
+		}
+	}
+	for _, key := range free {
+		k := lockKey(storeID, key)
+		locks.owners[k] = lockEntry{txn: txn, lock: &Lock{Owner: owner, StoreID: storeID, Key: key}}
+		locks.keys[txn] = append(locks.keys[txn], k)
+	}
+	if len(waits) > 0 {
+		locks.waits[txn] = append(locks.waits[txn], waits...)
+	}
+	return true
+}
+
+// Release releases the keys held by the transaction and removes its lock waits and the lock waits
+// for it, it should be called when the transaction is committed or rolled back.
+func Release(txn kv.Transaction) {
+	locks.Lock()
+	defer locks.Unlock()
+	for _, k := range locks.keys[txn] {
+		delete(locks.owners, k)
+	}
+	delete(locks.keys, txn)
+	delete(locks.waits, txn)
+	for waiter, waits := range locks.waits {
+		remains := waits[:0]
+		for _, wait := range waits {
+			if wait.holder != txn {
+				remains = append(remains, wait)
+			}
+		}
+		if len(remains) == 0 {
+			delete(locks.waits, waiter)
+		} else {
+			locks.waits[waiter] = remains
+		}
+	}
+}
+
+// Locks returns the locks of a store, ordered by the keys.
+func Locks(store kv.Storage) []*Lock {
+	storeID := store.UUID()
+	locks.Lock()
+	var result []*Lock
+	for _, entry := range locks.owners {
+		if entry.lock.StoreID == storeID {
+			result = append(result, entry.lock)
+		}
+	}
+	locks.Unlock()
+	sort.Slice(result, func(i, j int) bool { return result[i].Key.Cmp(result[j].Key) < 0 })
+	return result
+}
+
+// LockWaits returns the lock waits of a store, ordered by the keys and the start timestamps of the
+// waiting transactions.
+func LockWaits(store kv.Storage) []*LockWait {
+	storeID := store.UUID()
+	locks.Lock()
+	var result []*LockWait
+	for _, waits := range locks.waits {
+		for _, wait := range waits {
+			if wait.StoreID == storeID {
+				result = append(result, wait)
+			}
+		}
+	}
+	locks.Unlock()
+	sort.Slice(result, func(i, j int) bool {
+		if c := result[i].Key.Cmp(result[j].Key); c != 0 {
+			return c < 0
+		}
+		return result[i].StartTS < result[j].StartTS
+	})
+	return result
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rowlock
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testRowLockSuite{})
+
+type testRowLockSuite struct{}
+
+func (s *testRowLockSuite) TestRowLock(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://rowlock_test")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	txn1, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn1.Rollback()
+	txn2, err := store.Begin()
+	c.Assert(err, IsNil)
+	defer txn2.Rollback()
+	owner1 := Owner{StartTS: 1, SessionID: 1}
+	owner2 := Owner{StartTS: 2, SessionID: 2}
+	k1, k2 := kv.Key("k1"), kv.Key("k2")
+
+	c.Assert(TryLock(store, txn1, []kv.Key{k1}, owner1, false), IsTrue)
+	c.Assert(TryLock(store, txn1, []kv.Key{k1}, owner1, false), IsTrue)
+	c.Assert(TryLock(store, txn2, []kv.Key{k2, k1}, owner2, false), IsFalse)
+	c.Assert(Locks(store), HasLen, 1)
+	c.Assert(LockWaits(store), HasLen, 0)
+
+	c.Assert(TryLock(store, txn2, []kv.Key{k2, k1}, owner2, true), IsTrue)
+	locks := Locks(store)
+	c.Assert(locks, HasLen, 2)
+	c.Assert(locks[0].Key, DeepEquals, k1)
+	c.Assert(locks[0].StartTS, Equals, uint64(1))
+	c.Assert(locks[1].Key, DeepEquals, k2)
+	c.Assert(locks[1].StartTS, Equals, uint64(2))
+	waits := LockWaits(store)
+	c.Assert(waits, HasLen, 1)
+	c.Assert(waits[0].Key, DeepEquals, k1)
+	c.Assert(waits[0].StartTS, Equals, uint64(2))
+	c.Assert(waits[0].HoldingStartTS, Equals, uint64(1))
+
+	Release(txn1)
+	c.Assert(Locks(store), HasLen, 1)
+	c.Assert(LockWaits(store), HasLen, 0)
+	c.Assert(TryLock(store, txn1, []kv.Key{k1}, owner1, false), IsTrue)
+	Release(txn1)
+	Release(txn2)
+	c.Assert(Locks(store), HasLen, 0)
+}
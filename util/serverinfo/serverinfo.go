@@ -0,0 +1,199 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serverinfo implements the registry of the tidb-server instances of a cluster.
+//
+// Each tidb-server registers itself in the meta data of the store when it's started, and removes the
+// registration when it's closed. The registration has a lease renewed in the background, so a crashed
+// tidb-server is expired after the lease, and its registration is removed by the other tidb-servers.
+// Like the user-level locks, the lease is checked by the timestamps of the store.
+//
+// The tidb-servers read the information of each other by the status HTTP API at the registered status addresses,
+// the requests are authenticated by the token registered by the tidb-server which serves them.
+package serverinfo
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+)
+
+var (
+	// Lease is the lease of the registrations, a tidb-server is expired if it's not renewed in the lease.
+	Lease = 10 * time.Second
+	// FetchTimeout is the timeout of reading the information of another tidb-server.
+	FetchTimeout = 5 * time.Second
+)
+
+// TokenHeader is the HTTP header of the token which authenticates the requests of the other tidb-servers.
+const TokenHeader = "X-TiDB-Server-Token"
+
+// NewToken returns a random token for the registration of a tidb-server.
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Trace(err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Authenticated returns if the request is sent by a tidb-server which read the token of this tidb-server from the
+// registry.
+func Authenticated(req *http.Request, token string) bool {
+	got := req.Header.Get(TokenHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// Registry is the registration of a tidb-server.
+type Registry struct {
+	store kv.Storage
+	info  model.ServerInfo
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func physicalNow(txn kv.Transaction) int64 {
+	return oracle.ExtractPhysical(txn.StartTS())
+}
+
+// Register registers the tidb-server in the store, and renews its lease until the registry is closed.
+func Register(store kv.Storage, info *model.ServerInfo) (*Registry, error) {
+	r := &Registry{
+		store: store,
+		info:  *info,
+		stop:  make(chan struct{}),
+	}
+	if err := r.renew(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	r.wg.Add(1)
+	go r.renewLoop()
+	return r, nil
+}
+
+// renew renews the lease of the tidb-server, and removes the expired tidb-servers.
+func (r *Registry) renew() error {
+	err := kv.RunInNewTxn(r.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		infos, err := t.ListServerInfos()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		now := physicalNow(txn)
+		for _, info := range infos {
+			if info.ID == r.info.ID || info.Expire > now {
+				continue
+			}
+			if err = t.RemoveServerInfo(info.ID); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		info := r.info
+		info.Expire = now + int64(Lease/time.Millisecond)
+		return t.SetServerInfo(&info)
+	})
+	return errors.Trace(err)
+}
+
+func (r *Registry) renewLoop() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		if err := r.renew(); err != nil {
+			log.Warnf("[serverinfo] renew the registration of tidb-server %d error %v", r.info.ID, errors.ErrorStack(err))
+		}
+	}
+}
+
+// Close stops renewing the lease and removes the registration of the tidb-server.
+func (r *Registry) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		r.wg.Wait()
+		err = kv.RunInNewTxn(r.store, true, func(txn kv.Transaction) error {
+			return meta.NewMeta(txn).RemoveServerInfo(r.info.ID)
+		})
+	})
+	return errors.Trace(err)
+}
+
+// ListServers returns the registered tidb-servers which aren't expired, ordered by their IDs.
+func ListServers(store kv.Storage) ([]*model.ServerInfo, error) {
+	var infos []*model.ServerInfo
+	err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		all, err := meta.NewMeta(txn).ListServerInfos()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		now := physicalNow(txn)
+		infos = infos[:0]
+		for _, info := range all {
+			if info.Expire > now {
+				infos = append(infos, info)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos, nil
+}
+
+// Fetch reads the information of a tidb-server by its status HTTP API, and decodes the JSON response into v.
+func Fetch(info *model.ServerInfo, path string, v interface{}) error {
+	if info.StatusAddress == "" {
+		return errors.Errorf("tidb-server %d doesn't report its status", info.ID)
+	}
+	url := fmt.Sprintf("http://%s%s", info.StatusAddress, path)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set(TokenHeader, info.Token)
+	client := &http.Client{Timeout: FetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("get %s error: %s %s", url, resp.Status, body)
+	}
+	return errors.Trace(json.NewDecoder(resp.Body).Decode(v))
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverinfo
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testServerInfoSuite{})
+
+type testServerInfoSuite struct{}
+
+func (s *testServerInfoSuite) TestRegister(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://serverinfo_register")
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	r2, err := Register(store, &model.ServerInfo{ID: 2, Address: "127.0.0.1:4002", Version: "v2"})
+	c.Assert(err, IsNil)
+	r1, err := Register(store, &model.ServerInfo{ID: 1, Address: "127.0.0.1:4001", Version: "v1", StartTime: 100})
+	c.Assert(err, IsNil)
+	infos, err := ListServers(store)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 2)
+	c.Assert(infos[0].ID, Equals, uint32(1))
+	c.Assert(infos[0].Address, Equals, "127.0.0.1:4001")
+	c.Assert(infos[0].StartTime, Equals, int64(100))
+	c.Assert(infos[1].ID, Equals, uint32(2))
+
+	c.Assert(r2.Close(), IsNil)
+	c.Assert(r2.Close(), IsNil)
+	infos, err = ListServers(store)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 1)
+	c.Assert(infos[0].ID, Equals, uint32(1))
+	c.Assert(r1.Close(), IsNil)
+	infos, err = ListServers(store)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 0)
+}
+
+func (s *testServerInfoSuite) TestExpire(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://serverinfo_expire")
+	c.Assert(err, IsNil)
+	defer store.Close()
+
+	// The registration of a crashed tidb-server is expired, and removed when another tidb-server renews.
+	err = kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		return meta.NewMeta(txn).SetServerInfo(&model.ServerInfo{ID: 3, Expire: 1})
+	})
+	c.Assert(err, IsNil)
+	infos, err := ListServers(store)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 0)
+
+	oldLease := Lease
+	Lease = 300 * time.Millisecond
+	defer func() { Lease = oldLease }()
+	r, err := Register(store, &model.ServerInfo{ID: 1})
+	c.Assert(err, IsNil)
+	defer r.Close()
+	// The lease is renewed in the background.
+	time.Sleep(2 * Lease)
+	infos, err = ListServers(store)
+	c.Assert(err, IsNil)
+	c.Assert(infos, HasLen, 1)
+	c.Assert(infos[0].ID, Equals, uint32(1))
+	err = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+		all, err1 := meta.NewMeta(txn).ListServerInfos()
+		c.Assert(all, HasLen, 1)
+		return err1
+	})
+	c.Assert(err, IsNil)
+}
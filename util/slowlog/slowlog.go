@@ -0,0 +1,86 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog keeps the recent slow queries of the tidb-server in memory.
+//
+// The slow queries are logged as before, the recent ones are also kept in a bounded buffer, so they can be
+// read by information_schema.SLOW_QUERY, and by CLUSTER_SLOW_QUERY of the other tidb-servers.
+package slowlog
+
+import (
+	"sync"
+	"time"
+)
+
+// Capacity is the max number of the slow queries kept, the oldest one is dropped when it's full.
+var Capacity = 1024
+
+// Entry is a slow query.
+type Entry struct {
+	Time       time.Time
+	ConnID     uint64
+	User       string
+	DB         string
+	QueryTime  time.Duration
+	Query      string
+	PlanDigest string
+}
+
+var entries = struct {
+	sync.Mutex
+	// buf is a ring buffer, start is the position of the oldest entry when it's full.
+	buf   []*Entry
+	start int
+}{}
+
+// Record keeps the slow query.
+func Record(e *Entry) {
+	entries.Lock()
+	defer entries.Unlock()
+	if Capacity <= 0 {
+		return
+	}
+	if len(entries.buf) != Capacity && entries.start != 0 {
+		// The capacity is changed, put the entries in order again.
+		buf := make([]*Entry, 0, len(entries.buf))
+		buf = append(buf, entries.buf[entries.start:]...)
+		entries.buf = append(buf, entries.buf[:entries.start]...)
+		entries.start = 0
+	}
+	if len(entries.buf) > Capacity {
+		entries.buf = entries.buf[len(entries.buf)-Capacity:]
+	}
+	if len(entries.buf) < Capacity {
+		entries.buf = append(entries.buf, e)
+		return
+	}
+	entries.buf[entries.start] = e
+	entries.start = (entries.start + 1) % Capacity
+}
+
+// Entries returns the kept slow queries, from the oldest to the latest.
+func Entries() []*Entry {
+	entries.Lock()
+	defer entries.Unlock()
+	res := make([]*Entry, 0, len(entries.buf))
+	res = append(res, entries.buf[entries.start:]...)
+	return append(res, entries.buf[:entries.start]...)
+}
+
+// Reset drops all the kept slow queries.
+func Reset() {
+	entries.Lock()
+	entries.buf = nil
+	entries.start = 0
+	entries.Unlock()
+}
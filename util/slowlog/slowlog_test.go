@@ -0,0 +1,69 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testSlowLogSuite{})
+
+type testSlowLogSuite struct{}
+
+func (s *testSlowLogSuite) TestRecord(c *C) {
+	defer testleak.AfterTest(c)()
+	oldCapacity := Capacity
+	defer func() {
+		Capacity = oldCapacity
+		Reset()
+	}()
+	Reset()
+	queries := func() []string {
+		var res []string
+		for _, e := range Entries() {
+			res = append(res, e.Query)
+		}
+		return res
+	}
+
+	Capacity = 3
+	c.Assert(Entries(), HasLen, 0)
+	for _, q := range []string{"a", "b", "c", "d", "e"} {
+		Record(&Entry{Query: q})
+	}
+	c.Assert(queries(), DeepEquals, []string{"c", "d", "e"})
+
+	// The latest ones are kept when the capacity is changed.
+	Capacity = 2
+	Record(&Entry{Query: "f"})
+	c.Assert(queries(), DeepEquals, []string{"e", "f"})
+	Capacity = 4
+	Record(&Entry{Query: "g"})
+	Record(&Entry{Query: "h"})
+	Record(&Entry{Query: "i"})
+	c.Assert(queries(), DeepEquals, []string{"f", "g", "h", "i"})
+
+	Capacity = 0
+	Record(&Entry{Query: "j"})
+	c.Assert(queries(), DeepEquals, []string{"f", "g", "h", "i"})
+	Reset()
+	c.Assert(Entries(), HasLen, 0)
+}
@@ -0,0 +1,341 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqldigest normalizes the SQL statements and computes their digests.
+//
+// The statements that differ only in the literals, the whitespaces, the comments and the case of the
+// keywords and the identifiers have the same normalized text and digest, e.g.
+//
+//	SELECT * FROM t WHERE a = 1 AND b IN (1, 2, 3) /* comment */
+//	select * from T where a=2 and b in ('x')
+//
+// are both normalized to
+//
+//	select * from t where a = ? and b in (...)
+//
+// The package does not depend on the parser, so it is cheap enough for the plan cache, the statement
+// statistics and the external tools.
+package sqldigest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pingcap/tidb/util/hack"
+)
+
+// The tokens that replace the literals and the lists of the literals in the normalized text.
+const (
+	literalMark = "?"
+	listMark    = "(...)"
+)
+
+// Normalize returns the normalized text of the statements:
+// the literals are replaced by "?", the lists of the literals, like the IN lists and the VALUES rows,
+// are replaced by a single "(...)", the comments are removed, the keywords and the identifiers that are
+// not quoted are converted to lower case, and the tokens are separated by a single space.
+// The content of the executable comments like /*! ... */ is kept, the optimizer hints are removed.
+func Normalize(sql string) string {
+	normalized, _ := NormalizeParams(sql)
+	return normalized
+}
+
+// NormalizeParams returns the normalized text of the statements and the parameters of the text. The parameters
+// are the source texts of the literals, the parameter markers and the lists of the literals replaced by "?" and
+// "(...)" in the normalized text, in their order. The parentheses of the lists are not included, so the parameter
+// of "IN (1, 2)" is "1, 2".
+func NormalizeParams(sql string) (normalized string, params []string) {
+	tokens := collapseLists(tokenize(sql))
+	var buf bytes.Buffer
+	for i, tok := range tokens {
+		if i > 0 && needSpace(tokens[i-1].text, tok.text) {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(tok.text)
+		switch tok.text {
+		case literalMark:
+			params = append(params, sql[tok.start:tok.end])
+		case listMark:
+			params = append(params, strings.TrimSpace(sql[tok.start+1:tok.end-1]))
+		}
+	}
+	return buf.String(), params
+}
+
+// ParamMarkers returns the offsets of the parameter markers "?" of the statements, the "?" in the quoted
+// strings, the quoted identifiers and the comments are not parameter markers.
+func ParamMarkers(sql string) []int {
+	var offsets []int
+	for _, tok := range tokenize(sql) {
+		if tok.text == literalMark && sql[tok.start:tok.end] == "?" {
+			offsets = append(offsets, tok.start)
+		}
+	}
+	return offsets
+}
+
+// Digest returns the hex encoded SHA-256 of the normalized text.
+func Digest(normalized string) string {
+	sum := sha256.Sum256(hack.Slice(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeDigest returns the normalized text and the digest of the statements.
+func NormalizeDigest(sql string) (normalized, digest string) {
+	normalized = Normalize(sql)
+	return normalized, Digest(normalized)
+}
+
+// token is a token of the normalized text, start and end are its position in the statements.
+type token struct {
+	text       string
+	start, end int
+}
+
+// The operators of more than one character, the longer ones first.
+var multiCharOps = []string{"<=>", ">=", "<=", "<>", "!=", "||", "&&", "<<", ">>", ":=", "->"}
+
+// tokenize splits the statements into the tokens of the normalized text.
+func tokenize(sql string) []token {
+	var tokens []token
+	inSpecialComment := false
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '#' || strings.HasPrefix(sql[i:], "--") && (i+2 == len(sql) || isSpace(sql[i+2])):
+			i = skipLine(sql, i)
+		case strings.HasPrefix(sql[i:], "/*!"):
+			// Keep the content of the executable comment, skip the version like /*!40101.
+			i += 3
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+			inSpecialComment = true
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+		case inSpecialComment && strings.HasPrefix(sql[i:], "*/"):
+			i += 2
+			inSpecialComment = false
+		case c == '\'' || c == '"':
+			end := skipQuoted(sql, i, c)
+			tokens = append(tokens, token{literalMark, i, end})
+			i = end
+		case c == '`':
+			end := skipQuoted(sql, i, c)
+			tokens = append(tokens, token{sql[i:end], i, end})
+			i = end
+		case isDigit(c) || c == '.' && i+1 < len(sql) && isDigit(sql[i+1]) && !afterWord(tokens):
+			end := skipNumber(sql, i)
+			if end < len(sql) && isIdentChar(sql[end]) {
+				// An identifier starts with digits, like 1a.
+				end = skipWord(sql, end)
+				tokens = append(tokens, token{strings.ToLower(sql[i:end]), i, end})
+			} else {
+				tokens = append(tokens, token{literalMark, i, end})
+			}
+			i = end
+		case isIdentChar(c):
+			end := skipWord(sql, i)
+			word := sql[i:end]
+			if end < len(sql) && sql[end] == '\'' && isLiteralPrefix(word) {
+				// The literals like x'1F', b'01', N'str' and _utf8'str'.
+				end = skipQuoted(sql, end, '\'')
+				tokens = append(tokens, token{literalMark, i, end})
+			} else {
+				tokens = append(tokens, token{strings.ToLower(word), i, end})
+			}
+			i = end
+		default:
+			op := sql[i : i+1]
+			for _, o := range multiCharOps {
+				if strings.HasPrefix(sql[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "?" {
+				// The parameter markers are parameters like the literals.
+				tokens = append(tokens, token{literalMark, i, i + 1})
+			} else {
+				tokens = append(tokens, token{op, i, i + len(op)})
+			}
+			i += len(op)
+		}
+	}
+	// The trailing semicolons are not a part of the statement.
+	for len(tokens) > 0 && tokens[len(tokens)-1].text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// collapseLists replaces the IN lists and the VALUES rows of the literals by listMark, and the consecutive
+// rows by a single one, so the lists and the rows of different lengths have the same normalized text.
+// The arguments of the function calls are kept.
+func collapseLists(tokens []token) []token {
+	result := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		n := len(result)
+		if tokens[i].text == "(" && n > 0 {
+			nextRow := n >= 2 && result[n-1].text == "," && result[n-2].text == listMark
+			if end, ok := literalList(tokens, i); ok && (nextRow || isListKeyword(result[n-1].text)) {
+				if nextRow {
+					// The consecutive rows are one list, from the "(" of the first row to the ")" of the last one.
+					result = result[:n-1]
+					result[n-2].end = tokens[end].end
+				} else {
+					result = append(result, token{listMark, tokens[i].start, tokens[end].end})
+				}
+				i = end
+				continue
+			}
+		}
+		result = append(result, tokens[i])
+	}
+	return result
+}
+
+func isListKeyword(tok string) bool {
+	return tok == "in" || tok == "values" || tok == "value"
+}
+
+// literalList checks whether the tokens from the "(" at start is a list of the literals and returns the
+// index of the closing ")".
+func literalList(tokens []token, start int) (int, bool) {
+	expectLiteral := true
+	for i := start + 1; i < len(tokens); i++ {
+		switch text := tokens[i].text; {
+		case text == ")":
+			return i, !expectLiteral
+		case expectLiteral && (text == literalMark || text == "null"):
+			expectLiteral = false
+		case !expectLiteral && text == ",":
+			expectLiteral = true
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+func needSpace(prev, cur string) bool {
+	switch prev {
+	case "(", ".", "@":
+		return false
+	}
+	switch cur {
+	case ")", ",", ".", ";":
+		return false
+	}
+	return true
+}
+
+func afterWord(tokens []token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1].text
+	return isIdentChar(last[0]) || last[0] == '`' || last == ")"
+}
+
+func isLiteralPrefix(word string) bool {
+	switch strings.ToLower(word) {
+	case "x", "b", "n":
+		return true
+	}
+	return word[0] == '_'
+}
+
+func skipLine(sql string, i int) int {
+	end := strings.IndexByte(sql[i:], '\n')
+	if end < 0 {
+		return len(sql)
+	}
+	return i + end + 1
+}
+
+// skipQuoted returns the end of the quoted string from i, the quotes may be escaped by a backslash or
+// doubled, the backticks may only be doubled.
+func skipQuoted(sql string, i int, quote byte) int {
+	for i++; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+func skipNumber(sql string, i int) int {
+	if strings.HasPrefix(sql[i:], "0x") || strings.HasPrefix(sql[i:], "0b") {
+		return skipWord(sql, i)
+	}
+	for i < len(sql) && isDigit(sql[i]) {
+		i++
+	}
+	if i < len(sql) && sql[i] == '.' {
+		i++
+		for i < len(sql) && isDigit(sql[i]) {
+			i++
+		}
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && isDigit(sql[j]) {
+			i = j
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func skipWord(sql string, i int) int {
+	for i < len(sql) && isIdentChar(sql[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_' || c == '$' || c >= 0x80
+}
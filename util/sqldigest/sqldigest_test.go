@@ -0,0 +1,94 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqldigest
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testSQLDigestSuite{})
+
+type testSQLDigestSuite struct{}
+
+func (s *testSQLDigestSuite) TestNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql        string
+		normalized string
+	}{
+		{"SELECT * FROM t WHERE a = 1", "select * from t where a = ?"},
+		{"select  *\n\tfrom T where a=-1.5e3;", "select * from t where a = - ?"},
+		{"select a from t where b = 'x''y' and c = \"z\\\"\"", "select a from t where b = ? and c = ?"},
+		{"select `A b`.c from `A b` # comment", "select `A b`.c from `A b`"},
+		{"select /*+ TIDB_SMJ(t) */ a -- comment\nfrom t", "select a from t"},
+		{"select x'1F', b'01', 0x1F, _utf8'str', N'str'", "select ?, ?, ?, ?, ?"},
+		{"select * from t where a in (1, 2, 3) and b in (?)", "select * from t where a in (...) and b in (...)"},
+		{"insert into t values (1, 'a'), (2, null), (3, 'c')", "insert into t values (...)"},
+		{"insert into t values (1, now())", "insert into t values (?, now ())"},
+		{"select count(*), substr(a, 1, 2) from t1", "select count (*), substr (a, ?, ?) from t1"},
+		{"select @a, @@global.autocommit", "select @a, @@global.autocommit"},
+		{"select a from t where a >= 1 and b<=>2 and c <> .5", "select a from t where a >= ? and b <=> ? and c <> ?"},
+		{"/*!40101 set names utf8 */", "set names utf8"},
+		{"select 1a from t", "select 1a from t"},
+	}
+	for _, t := range tests {
+		c.Assert(Normalize(t.sql), Equals, t.normalized, Commentf("sql %s", t.sql))
+	}
+}
+
+func (s *testSQLDigestSuite) TestDigest(c *C) {
+	defer testleak.AfterTest(c)()
+	normalized, digest := NormalizeDigest("SELECT * FROM t WHERE a IN (1, 2)")
+	c.Assert(normalized, Equals, "select * from t where a in (...)")
+	c.Assert(digest, HasLen, 64)
+	c.Assert(digest, Equals, Digest(normalized))
+
+	_, other := NormalizeDigest("select * from t where a in ('x')   /* comment */")
+	c.Assert(other, Equals, digest)
+	_, other = NormalizeDigest("select * from t where b in (1, 2)")
+	c.Assert(other, Not(Equals), digest)
+}
+
+func (s *testSQLDigestSuite) TestNormalizeParams(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		sql        string
+		normalized string
+		params     []string
+	}{
+		{"select * from t where a = 1 and b = 'x''y'", "select * from t where a = ? and b = ?", []string{"1", "'x''y'"}},
+		{"select * from t where a in ( 1, 2 ) and b = ?", "select * from t where a in (...) and b = ?", []string{"1, 2", "?"}},
+		{"insert into t values (1, 'a'), (2, null)", "insert into t values (...)", []string{"1, 'a'), (2, null"}},
+		{"select x'1F', -1.5e3, _utf8'str' limit 10", "select ?, - ?, ? limit ?", []string{"x'1F'", "1.5e3", "_utf8'str'", "10"}},
+		{"select a from t", "select a from t", nil},
+	}
+	for _, t := range tests {
+		normalized, params := NormalizeParams(t.sql)
+		c.Assert(normalized, Equals, t.normalized, Commentf("sql %s", t.sql))
+		c.Assert(params, DeepEquals, t.params, Commentf("sql %s", t.sql))
+	}
+}
+
+func (s *testSQLDigestSuite) TestParamMarkers(c *C) {
+	defer testleak.AfterTest(c)()
+	c.Assert(ParamMarkers("select ? from t where a = ? and b = '?' and `?` = 1 /* ? */"), DeepEquals, []int{7, 26})
+	c.Assert(ParamMarkers("select a from t"), IsNil)
+}
@@ -295,6 +295,56 @@ func floatStrToIntStr(validFloat string) (string, error) {
 	return validInt, nil
 }
 
+// The floats whose absolute values are not less than expFormatBig or less than expFormatSmall are formatted
+// in the scientific notation like MySQL.
+const (
+	expFormatBig   = 1e15
+	expFormatSmall = 1e-15
+)
+
+// floatDigits is the number of the significant digits of a FLOAT formatted as text, it's FLT_DIG in MySQL.
+const floatDigits = 6
+
+// MaxDoubleStrLen and MaxFloatStrLen are the max lengths of the DOUBLE and FLOAT texts of FormatFloat, which are
+// like -0.0000000000000012345678901234568 and -0.00000000000000123457.
+const (
+	MaxDoubleStrLen = 34
+	MaxFloatStrLen  = 23
+)
+
+// AppendFormatFloat appends the text of the float formatted as MySQL. If prec is -1, the float is formatted in
+// the shortest representation which converts back to the same float, and the big or small floats are formatted
+// in the scientific notation like 1e15 or 1.5e-16. A FLOAT is rounded to 6 significant digits first, as MySQL
+// only keeps the digits a single precision float can represent. Otherwise, it's formatted with prec decimals.
+func AppendFormatFloat(dst []byte, f float64, prec, bitSize int) []byte {
+	if prec == -1 && bitSize == 32 {
+		f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'e', floatDigits-1, 64), 64)
+		bitSize = 64
+	}
+	abs := math.Abs(f)
+	if prec != -1 || abs == 0 || (abs < expFormatBig && abs >= expFormatSmall) {
+		return strconv.AppendFloat(dst, f, 'f', prec, bitSize)
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'e', -1, bitSize)
+	// MySQL writes the exponent without the plus sign and the leading zeros, like 1e15 and 1e-16.
+	i := start + strings.IndexByte(string(dst[start:]), 'e') + 1
+	if dst[i] == '+' {
+		dst = append(dst[:i], dst[i+1:]...)
+	} else {
+		i++
+	}
+	for i+1 < len(dst) && dst[i] == '0' {
+		dst = append(dst[:i], dst[i+1:]...)
+	}
+	return dst
+}
+
+// FormatFloat formats the float as MySQL in the shortest representation, see AppendFormatFloat.
+func FormatFloat(f float64, bitSize int) string {
+	return string(AppendFormatFloat(nil, f, -1, bitSize))
+}
+
 // StrToFloat converts a string to a float64 at the best-effort.
 func StrToFloat(sc *variable.StatementContext, str string) (float64, error) {
 	str = strings.TrimSpace(str)
@@ -424,9 +474,9 @@ func ToString(value interface{}) (string, error) {
 	case uint64:
 		return strconv.FormatUint(uint64(v), 10), nil
 	case float32:
-		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+		return FormatFloat(float64(v), 32), nil
 	case float64:
-		return strconv.FormatFloat(float64(v), 'f', -1, 64), nil
+		return FormatFloat(v, 64), nil
 	case string:
 		return v, nil
 	case []byte:
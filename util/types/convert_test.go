@@ -678,6 +678,43 @@ func (s *testTypeConvertSuite) TestGetValidFloat(c *C) {
 	c.Assert(terror.ErrorEqual(err, ErrOverflow), IsTrue)
 }
 
+func (s *testTypeConvertSuite) TestFormatFloat(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		f       float64
+		prec    int
+		bitSize int
+		expect  string
+	}{
+		{0, -1, 64, "0"},
+		{-0.5, -1, 64, "-0.5"},
+		{0.30000000000000004, -1, 64, "0.30000000000000004"},
+		{123456789012345, -1, 64, "123456789012345"},
+		{1e15, -1, 64, "1e15"},
+		{-1.2345678901234566e17, -1, 64, "-1.2345678901234566e17"},
+		{1e100, -1, 64, "1e100"},
+		{1e-15, -1, 64, "0.000000000000001"},
+		{1.5e-16, -1, 64, "1.5e-16"},
+		{5e-324, -1, 64, "5e-324"},
+		{2.5, 0, 64, "2"},
+		{2.2, 3, 64, "2.200"},
+		{1e20, 2, 64, "100000000000000000000.00"},
+		{float64(float32(1.1)), -1, 32, "1.1"},
+		{float64(float32(3.1415926)), -1, 32, "3.14159"},
+		{float64(float32(1234567)), -1, 32, "1234570"},
+		{float64(float32(-0.000123456789)), -1, 32, "-0.000123457"},
+		{float64(float32(1e20)), -1, 32, "1e20"},
+		{float64(float32(1.2)), 2, 32, "1.20"},
+	}
+	for _, t := range tests {
+		comment := Commentf("%v %d %d", t.f, t.prec, t.bitSize)
+		c.Assert(string(AppendFormatFloat([]byte("x"), t.f, t.prec, t.bitSize)), Equals, "x"+t.expect, comment)
+		if t.prec == -1 {
+			c.Assert(FormatFloat(t.f, t.bitSize), Equals, t.expect, comment)
+		}
+	}
+}
+
 // TestConvertTime tests time related conversion.
 // time conversion is complicated including Date/Datetime/Time/Timestamp etc,
 // Timestamp may involving timezone.
@@ -770,9 +770,9 @@ func (d *Datum) convertToString(sc *variable.StatementContext, target *FieldType
 	case KindUint64:
 		s = strconv.FormatUint(d.GetUint64(), 10)
 	case KindFloat32:
-		s = strconv.FormatFloat(d.GetFloat64(), 'f', -1, 32)
+		s = FormatFloat(d.GetFloat64(), 32)
 	case KindFloat64:
-		s = strconv.FormatFloat(d.GetFloat64(), 'f', -1, 64)
+		s = FormatFloat(d.GetFloat64(), 64)
 	case KindString, KindBytes:
 		s = d.GetString()
 	case KindMysqlTime:
@@ -1462,9 +1462,9 @@ func (d *Datum) ToString() (string, error) {
 	case KindUint64:
 		return strconv.FormatUint(d.GetUint64(), 10), nil
 	case KindFloat32:
-		return strconv.FormatFloat(float64(d.GetFloat32()), 'f', -1, 32), nil
+		return FormatFloat(float64(d.GetFloat32()), 32), nil
 	case KindFloat64:
-		return strconv.FormatFloat(float64(d.GetFloat64()), 'f', -1, 64), nil
+		return FormatFloat(d.GetFloat64(), 64), nil
 	case KindString:
 		return d.GetString(), nil
 	case KindBytes:
@@ -47,6 +47,8 @@ var (
 	ErrCastNegIntAsUnsigned = terror.ClassTypes.New(codeUnknown, msgCastNegIntAsUnsigned)
 	// ErrInvalidDefault is returned when meet a invalid default value.
 	ErrInvalidDefault = terror.ClassTypes.New(codeInvalidDefault, "Invalid default value for '%s'")
+	// ErrAutoConvert is returned when a column type is converted to another type in the DDL.
+	ErrAutoConvert = terror.ClassTypes.New(codeAutoConvert, mysql.MySQLErrName[mysql.ErrAutoConvert])
 )
 
 const (
@@ -64,6 +66,7 @@ const (
 	codeTruncatedWrongValue terror.ErrCode = terror.ErrCode(mysql.ErrTruncatedWrongValue)
 	codeUnknown             terror.ErrCode = terror.ErrCode(mysql.ErrUnknown)
 	codeInvalidDefault      terror.ErrCode = terror.ErrCode(mysql.ErrInvalidDefault)
+	codeAutoConvert         terror.ErrCode = terror.ErrCode(mysql.ErrAutoConvert)
 )
 
 var (
@@ -87,6 +90,7 @@ func init() {
 		codeTruncatedWrongValue: mysql.ErrTruncatedWrongValue,
 		codeUnknown:             mysql.ErrUnknown,
 		codeInvalidDefault:      mysql.ErrInvalidDefault,
+		codeAutoConvert:         mysql.ErrAutoConvert,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTypes] = typesMySQLErrCodes
 }
@@ -230,9 +230,15 @@ func (ft *FieldType) CompactStr() string {
 			}
 			suffix += ")"
 		}
-	case mysql.TypeBit, mysql.TypeShort, mysql.TypeTiny, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong, mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
+	case mysql.TypeBit, mysql.TypeShort, mysql.TypeTiny, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong:
 		// Flen is always shown.
 		suffix = fmt.Sprintf("(%d)", displayFlen)
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
+		// Flen is always shown, CHAR(0) and VARCHAR(0) are valid types.
+		if ft.Flen == 0 {
+			displayFlen = 0
+		}
+		suffix = fmt.Sprintf("(%d)", displayFlen)
 	}
 	return ts + suffix
 }
@@ -88,6 +88,8 @@ func (s *testFieldTypeSuite) TestFieldType(c *C) {
 	ft.Charset = charset.CollationBin
 	ft.Flag |= mysql.BinaryFlag
 	c.Assert(ft.String(), Equals, "binary(1)")
+	ft.Flen = 0
+	c.Assert(ft.String(), Equals, "binary(0)")
 
 	ft = NewFieldType(mysql.TypeEnum)
 	ft.Elems = []string{"a", "b"}
@@ -1770,11 +1770,16 @@ func ParseTimeFromInt64(num int64) (Time, error) {
 // according to layout.
 // See http://dev.mysql.com/doc/refman/5.7/en/date-and-time-functions.html#function_date-format
 func (t Time) DateFormat(layout string) (string, error) {
+	return t.DateFormatWithLocale(layout, mysql.LocaleENUS)
+}
+
+// DateFormatWithLocale is like DateFormat, but the names of the months and the days are in the locale.
+func (t Time) DateFormatWithLocale(layout string, loc *mysql.Locale) (string, error) {
 	var buf bytes.Buffer
 	inPatternMatch := false
 	for _, b := range layout {
 		if inPatternMatch {
-			if err := t.convertDateFormat(b, &buf); err != nil {
+			if err := t.convertDateFormat(b, loc, &buf); err != nil {
 				return "", errors.Trace(err)
 			}
 			inPatternMatch = false
@@ -1791,25 +1796,25 @@ func (t Time) DateFormat(layout string) (string, error) {
 	return buf.String(), nil
 }
 
-var abbrevWeekdayName = []string{
-	"Sun", "Mon", "Tue",
-	"Wed", "Thu", "Fri", "Sat",
+// weekdayIndex returns the index of the weekday in the day names of the locales, which start from Monday.
+func weekdayIndex(weekday gotime.Weekday) int {
+	return (int(weekday) + 6) % 7
 }
 
-func (t Time) convertDateFormat(b rune, buf *bytes.Buffer) error {
+func (t Time) convertDateFormat(b rune, loc *mysql.Locale, buf *bytes.Buffer) error {
 	switch b {
 	case 'b':
 		m := t.Time.Month()
 		if m == 0 || m > 12 {
 			return errors.Trace(ErrInvalidTimeFormat)
 		}
-		buf.WriteString(MonthNames[m-1][:3])
+		buf.WriteString(loc.AbbrevMonthNames[m-1])
 	case 'M':
 		m := t.Time.Month()
 		if m == 0 || m > 12 {
 			return errors.Trace(ErrInvalidTimeFormat)
 		}
-		buf.WriteString(MonthNames[m-1])
+		buf.WriteString(loc.MonthNames[m-1])
 	case 'm':
 		fmt.Fprintf(buf, "%02d", t.Time.Month())
 	case 'c':
@@ -1880,10 +1885,9 @@ func (t Time) convertDateFormat(b rune, buf *bytes.Buffer) error {
 		_, w := t.Time.YearWeek(3)
 		fmt.Fprintf(buf, "%02d", w)
 	case 'a':
-		weekday := t.Time.Weekday()
-		buf.WriteString(abbrevWeekdayName[weekday])
+		buf.WriteString(loc.AbbrevDayNames[weekdayIndex(t.Time.Weekday())])
 	case 'W':
-		buf.WriteString(t.Time.Weekday().String())
+		buf.WriteString(loc.DayNames[weekdayIndex(t.Time.Weekday())])
 	case 'w':
 		fmt.Fprintf(buf, "%d", t.Time.Weekday())
 	case 'X':
@@ -0,0 +1,324 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package userlock implements the user-level locks of GET_LOCK and RELEASE_LOCK.
+//
+// The locks are saved in the meta data of the store, so they are exclusive across all the tidb-servers
+// of a cluster. A lock is held by a connection with a lease, the tidb-server renews the leases of the
+// locks held by its connections in the background, so the locks held by a crashed tidb-server are
+// expired after the lease and can be acquired by the other connections. The lease is checked by the
+// timestamps of the store, it doesn't depend on the clocks of the tidb-servers.
+//
+// The locks are reentrant, a connection can acquire a lock several times, and it's released when it's
+// released as many times. The lock waits are not checked for deadlocks, they are broken by the timeouts.
+package userlock
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/tikv/oracle"
+	"github.com/twinj/uuid"
+)
+
+var (
+	// Lease is the lease of the locks, a lock is expired if it's not renewed in the lease.
+	Lease = 10 * time.Second
+	// WaitInterval is the interval to retry acquiring a lock held by another connection.
+	WaitInterval = 50 * time.Millisecond
+)
+
+// serverID identifies the tidb-server in the locks.
+var serverID = uuid.NewV4().String()
+
+// errLockHeld means the lock is held by another connection.
+var errLockHeld = errors.New("user-level lock is held by another connection")
+
+type heldLock struct {
+	connID uint64
+	count  int64
+}
+
+// Manager manages the user-level locks held by the connections of the tidb-server on a store.
+type Manager struct {
+	store kv.Storage
+
+	mu sync.Mutex
+	// held maps the names of the locks held by the connections of the tidb-server to their holders.
+	held map[string]*heldLock
+	// stopRenew stops renewing the leases of the held locks, it's nil if no lock is held.
+	stopRenew chan struct{}
+}
+
+var managers = struct {
+	sync.Mutex
+	m map[string]*Manager
+}{m: make(map[string]*Manager)}
+
+// GetManager returns the lock manager of a store.
+func GetManager(store kv.Storage) *Manager {
+	managers.Lock()
+	defer managers.Unlock()
+	m, ok := managers.m[store.UUID()]
+	if !ok {
+		m = &Manager{store: store, held: make(map[string]*heldLock)}
+		managers.m[store.UUID()] = m
+	}
+	return m
+}
+
+func physicalNow(txn kv.Transaction) int64 {
+	return oracle.ExtractPhysical(txn.StartTS())
+}
+
+func isHeld(lock *model.UserLock, now int64) bool {
+	return lock != nil && lock.Expire > now
+}
+
+// GetLock acquires the lock for the connection. It waits for the lock until the timeout if it's held by
+// another connection, and waits forever if the timeout is negative. It returns false if the lock can't be
+// acquired before the timeout.
+func (m *Manager) GetLock(name string, connID uint64, timeout time.Duration) (bool, error) {
+	m.mu.Lock()
+	if h, ok := m.held[name]; ok && h.connID == connID {
+		h.count++
+		m.mu.Unlock()
+		return true, nil
+	}
+	m.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := m.tryLock(name, connID)
+		if err == nil {
+			return true, nil
+		}
+		if errors.Cause(err) != errLockHeld {
+			return false, errors.Trace(err)
+		}
+		wait := WaitInterval
+		if timeout >= 0 {
+			remains := deadline.Sub(time.Now())
+			if remains <= 0 {
+				return false, nil
+			}
+			if remains < wait {
+				wait = remains
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+func (m *Manager) tryLock(name string, connID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// The lock held by another connection of the tidb-server is held until it's released.
+	if _, ok := m.held[name]; ok {
+		return errLockHeld
+	}
+	err := kv.RunInNewTxn(m.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		lock, err := t.GetUserLock(name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		now := physicalNow(txn)
+		if isHeld(lock, now) {
+			return errLockHeld
+		}
+		return t.SetUserLock(&model.UserLock{
+			Name:         name,
+			ServerID:     serverID,
+			ConnectionID: connID,
+			Expire:       now + int64(Lease/time.Millisecond),
+		})
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	m.held[name] = &heldLock{connID: connID, count: 1}
+	if m.stopRenew == nil {
+		m.stopRenew = make(chan struct{})
+		go m.renewLoop(m.stopRenew)
+	}
+	return nil
+}
+
+// stopRenewIfIdle stops renewing the leases if no lock is held, m.mu must be held.
+func (m *Manager) stopRenewIfIdle() {
+	if len(m.held) == 0 && m.stopRenew != nil {
+		close(m.stopRenew)
+		m.stopRenew = nil
+	}
+}
+
+// ReleaseLock releases the lock held by the connection. It returns false if the lock is held by another
+// connection, and returns false and nil if the lock isn't held by any connection.
+func (m *Manager) ReleaseLock(name string, connID uint64) (released bool, isNull bool, err error) {
+	m.mu.Lock()
+	h, ok := m.held[name]
+	if ok && h.connID == connID {
+		defer m.mu.Unlock()
+		h.count--
+		if h.count == 0 {
+			delete(m.held, name)
+			m.stopRenewIfIdle()
+			err = m.removeLocks([]string{name})
+		}
+		return true, false, errors.Trace(err)
+	}
+	m.mu.Unlock()
+
+	lock, err := m.getLock(name)
+	if err != nil {
+		return false, true, errors.Trace(err)
+	}
+	return false, lock == nil, nil
+}
+
+// ReleaseAll releases all the locks held by the connection, and returns the number of the released locks,
+// a lock acquired several times is counted as many times. It's called by RELEASE_ALL_LOCKS and when the
+// connection is closed.
+func (m *Manager) ReleaseAll(connID uint64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var (
+		count int64
+		names []string
+	)
+	for name, h := range m.held {
+		if h.connID == connID {
+			count += h.count
+			names = append(names, name)
+			delete(m.held, name)
+		}
+	}
+	if len(names) == 0 {
+		return 0, nil
+	}
+	m.stopRenewIfIdle()
+	return count, errors.Trace(m.removeLocks(names))
+}
+
+// IsUsedLock returns the connection ID holding the lock, it returns false if the lock is free.
+func (m *Manager) IsUsedLock(name string) (uint64, bool, error) {
+	m.mu.Lock()
+	h, ok := m.held[name]
+	m.mu.Unlock()
+	if ok {
+		return h.connID, true, nil
+	}
+	lock, err := m.getLock(name)
+	if err != nil || lock == nil {
+		return 0, false, errors.Trace(err)
+	}
+	return lock.ConnectionID, true, nil
+}
+
+// getLock returns the lock if it's held by any connection.
+func (m *Manager) getLock(name string) (*model.UserLock, error) {
+	var lock *model.UserLock
+	err := kv.RunInNewTxn(m.store, false, func(txn kv.Transaction) error {
+		var err error
+		lock, err = meta.NewMeta(txn).GetUserLock(name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !isHeld(lock, physicalNow(txn)) {
+			lock = nil
+		}
+		return nil
+	})
+	return lock, errors.Trace(err)
+}
+
+// removeLocks removes the locks held by the tidb-server from the store, the locks which are expired and
+// acquired by the other tidb-servers are kept.
+func (m *Manager) removeLocks(names []string) error {
+	err := kv.RunInNewTxn(m.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		for _, name := range names {
+			lock, err := t.GetUserLock(name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if lock == nil || lock.ServerID != serverID {
+				continue
+			}
+			if err = t.RemoveUserLock(name); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return errors.Trace(err)
+}
+
+// renewLoop renews the leases of the held locks until it's stopped.
+func (m *Manager) renewLoop(stop chan struct{}) {
+	ticker := time.NewTicker(Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		m.mu.Lock()
+		if err := m.renewLocks(); err != nil {
+			log.Warnf("[userlock] renew the leases of the user-level locks error %v", errors.ErrorStack(err))
+		}
+		m.mu.Unlock()
+	}
+}
+
+// renewLocks renews the leases of the held locks. A lock is lost if it's expired and acquired by another
+// connection, then it's not held any more.
+func (m *Manager) renewLocks() error {
+	var lost []string
+	err := kv.RunInNewTxn(m.store, true, func(txn kv.Transaction) error {
+		lost = lost[:0]
+		t := meta.NewMeta(txn)
+		now := physicalNow(txn)
+		for name, h := range m.held {
+			lock, err := t.GetUserLock(name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if lock == nil || lock.ServerID != serverID || lock.ConnectionID != h.connID {
+				lost = append(lost, name)
+				continue
+			}
+			lock.Expire = now + int64(Lease/time.Millisecond)
+			if err = t.SetUserLock(lock); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, name := range lost {
+		log.Warnf("[userlock] the user-level lock %s held by connection %d is lost", name, m.held[name].connID)
+		delete(m.held, name)
+	}
+	m.stopRenewIfIdle()
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userlock
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/store/localstore"
+	"github.com/pingcap/tidb/store/localstore/goleveldb"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testUserLockSuite{})
+
+type testUserLockSuite struct{}
+
+func (s *testUserLockSuite) TestGetLock(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://userlock_get_lock")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	m := GetManager(store)
+	c.Assert(GetManager(store), Equals, m)
+
+	ok, err := m.GetLock("a", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = m.GetLock("a", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	start := time.Now()
+	ok, err = m.GetLock("a", 2, 100*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(time.Since(start) >= 100*time.Millisecond, IsTrue)
+	connID, used, err := m.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsTrue)
+	c.Assert(connID, Equals, uint64(1))
+
+	released, isNull, err := m.ReleaseLock("a", 2)
+	c.Assert(err, IsNil)
+	c.Assert(released || isNull, IsFalse)
+	released, isNull, err = m.ReleaseLock("b", 2)
+	c.Assert(err, IsNil)
+	c.Assert(released, IsFalse)
+	c.Assert(isNull, IsTrue)
+
+	// The lock is released when it's released as many times as it's acquired.
+	released, _, err = m.ReleaseLock("a", 1)
+	c.Assert(err, IsNil)
+	c.Assert(released, IsTrue)
+	_, used, err = m.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsTrue)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		m.ReleaseLock("a", 1)
+	}()
+	ok, err = m.GetLock("a", 2, -1)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	ok, err = m.GetLock("b", 2, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	count, err := m.ReleaseAll(1)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(0))
+	count, err = m.ReleaseAll(2)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(2))
+	_, used, err = m.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsFalse)
+}
+
+func (s *testUserLockSuite) TestLease(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := localstore.Driver{Driver: goleveldb.MemoryDriver{}}.Open("memory://userlock_lease")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	m := GetManager(store)
+	defer func(lease time.Duration) {
+		Lease = lease
+	}(Lease)
+	Lease = 200 * time.Millisecond
+
+	// The lock held by another tidb-server can be acquired after it's expired.
+	setLock := func(name string) {
+		err := kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+			return meta.NewMeta(txn).SetUserLock(&model.UserLock{
+				Name:         name,
+				ServerID:     "another",
+				ConnectionID: 10,
+				Expire:       physicalNow(txn) + int64(Lease/time.Millisecond),
+			})
+		})
+		c.Assert(err, IsNil)
+	}
+	setLock("a")
+	connID, used, err := m.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsTrue)
+	c.Assert(connID, Equals, uint64(10))
+	ok, err := m.GetLock("a", 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	ok, err = m.GetLock("a", 1, time.Second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+
+	// The held lock is renewed.
+	time.Sleep(2 * Lease)
+	lock, err := m.getLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(lock, NotNil)
+	c.Assert(lock.ConnectionID, Equals, uint64(1))
+
+	// The lock acquired by another tidb-server is lost.
+	setLock("a")
+	m.mu.Lock()
+	err = m.renewLocks()
+	c.Assert(m.held, HasLen, 0)
+	c.Assert(m.stopRenew, IsNil)
+	m.mu.Unlock()
+	c.Assert(err, IsNil)
+	released, isNull, err := m.ReleaseLock("a", 1)
+	c.Assert(err, IsNil)
+	c.Assert(released || isNull, IsFalse)
+}